@@ -133,66 +133,82 @@ func (abi ABI) UnpackIntoMap(v map[string]interface{}, name string, data []byte)
 	return args.UnpackIntoMap(v, data)
 }
 
-// UnmarshalJSON implements json.Unmarshaler interface.
-func (abi *ABI) UnmarshalJSON(data []byte) error {
-	var fields []struct {
-		Type    string
-		Name    string
-		Inputs  []Argument
-		Outputs []Argument
+// field is a single entry of a JSON ABI definition.
+type field struct {
+	Type    string
+	Name    string
+	Inputs  []Argument
+	Outputs []Argument
 
-		// Status indicator which can be: "pure", "view",
-		// "nonpayable" or "payable".
-		StateMutability string
+	// Status indicator which can be: "pure", "view",
+	// "nonpayable" or "payable".
+	StateMutability string
 
-		// Deprecated Status indicators, but removed in v0.6.0.
-		Constant bool // True if function is either pure or view
-		Payable  bool // True if function is payable
+	// Deprecated Status indicators, but removed in v0.6.0.
+	Constant bool // True if function is either pure or view
+	Payable  bool // True if function is payable
 
-		// Event relevant indicator represents the event is
-		// declared as anonymous.
-		Anonymous bool
-	}
+	// Event relevant indicator represents the event is
+	// declared as anonymous.
+	Anonymous bool
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (abi *ABI) UnmarshalJSON(data []byte) error {
+	var fields []field
 	if err := json.Unmarshal(data, &fields); err != nil {
 		return err
 	}
+	abi.init()
+	for _, field := range fields {
+		if err := abi.add(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// init resets the method, event and error sets of the ABI.
+func (abi *ABI) init() {
 	abi.Methods = make(map[string]Method)
 	abi.Events = make(map[string]Event)
 	abi.Errors = make(map[string]Error)
-	for _, field := range fields {
-		switch field.Type {
-		case "constructor":
-			abi.Constructor = NewMethod("", "", Constructor, field.StateMutability, field.Constant, field.Payable, field.Inputs, nil)
-		case "function":
-			name := ResolveNameConflict(field.Name, func(s string) bool { _, ok := abi.Methods[s]; return ok })
-			abi.Methods[name] = NewMethod(name, field.Name, Function, field.StateMutability, field.Constant, field.Payable, field.Inputs, field.Outputs)
-		case "fallback":
-			// New introduced function type in v0.6.0, check more detail
-			// here https://solidity.readthedocs.io/en/v0.6.0/contracts.html#fallback-function
-			if abi.HasFallback() {
-				return errors.New("only single fallback is allowed")
-			}
-			abi.Fallback = NewMethod("", "", Fallback, field.StateMutability, field.Constant, field.Payable, nil, nil)
-		case "receive":
-			// New introduced function type in v0.6.0, check more detail
-			// here https://solidity.readthedocs.io/en/v0.6.0/contracts.html#fallback-function
-			if abi.HasReceive() {
-				return errors.New("only single receive is allowed")
-			}
-			if field.StateMutability != "payable" {
-				return errors.New("the statemutability of receive can only be payable")
-			}
-			abi.Receive = NewMethod("", "", Receive, field.StateMutability, field.Constant, field.Payable, nil, nil)
-		case "event":
-			name := ResolveNameConflict(field.Name, func(s string) bool { _, ok := abi.Events[s]; return ok })
-			abi.Events[name] = NewEvent(name, field.Name, field.Anonymous, field.Inputs)
-		case "error":
-			// Errors cannot be overloaded or overridden but are inherited,
-			// no need to resolve the name conflict here.
-			abi.Errors[field.Name] = NewError(field.Name, field.Inputs)
-		default:
-			return fmt.Errorf("abi: could not recognize type %v of field %v", field.Type, field.Name)
+}
+
+// add registers a single entry of a JSON ABI definition.
+func (abi *ABI) add(field field) error {
+	switch field.Type {
+	case "constructor":
+		abi.Constructor = NewMethod("", "", Constructor, field.StateMutability, field.Constant, field.Payable, field.Inputs, nil)
+	case "function":
+		name := ResolveNameConflict(field.Name, func(s string) bool { _, ok := abi.Methods[s]; return ok })
+		abi.Methods[name] = NewMethod(name, field.Name, Function, field.StateMutability, field.Constant, field.Payable, field.Inputs, field.Outputs)
+	case "fallback":
+		// New introduced function type in v0.6.0, check more detail
+		// here https://solidity.readthedocs.io/en/v0.6.0/contracts.html#fallback-function
+		if abi.HasFallback() {
+			return errors.New("only single fallback is allowed")
+		}
+		abi.Fallback = NewMethod("", "", Fallback, field.StateMutability, field.Constant, field.Payable, nil, nil)
+	case "receive":
+		// New introduced function type in v0.6.0, check more detail
+		// here https://solidity.readthedocs.io/en/v0.6.0/contracts.html#fallback-function
+		if abi.HasReceive() {
+			return errors.New("only single receive is allowed")
+		}
+		if field.StateMutability != "payable" {
+			return errors.New("the statemutability of receive can only be payable")
 		}
+		abi.Receive = NewMethod("", "", Receive, field.StateMutability, field.Constant, field.Payable, nil, nil)
+	case "event":
+		name := ResolveNameConflict(field.Name, func(s string) bool { _, ok := abi.Events[s]; return ok })
+		abi.Events[name] = NewEvent(name, field.Name, field.Anonymous, field.Inputs)
+	case "error":
+		// Errors cannot be overloaded or overridden but are inherited,
+		// no need to resolve the name conflict here.
+		abi.Errors[field.Name] = NewError(field.Name, field.Inputs)
+	default:
+		return fmt.Errorf("abi: could not recognize type %v of field %v", field.Type, field.Name)
 	}
 	return nil
 }
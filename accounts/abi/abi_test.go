@@ -1192,3 +1192,51 @@ func TestUnpackRevert(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorsUnpackError(t *testing.T) {
+	t.Parallel()
+
+	const jsondata = `[{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}]`
+	contract, err := JSON(strings.NewReader(jsondata))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := NewErrors(&contract)
+
+	// Custom errors defined in the ABI
+	custom := contract.Errors["InsufficientBalance"]
+	data, err := custom.Inputs.Pack(big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, args, err := errs.UnpackError(append(custom.ID[:4:4], data...))
+	if err != nil {
+		t.Fatalf("failed to unpack custom error: %v", err)
+	}
+	if name != "InsufficientBalance" || len(args) != 2 || args[0].(*big.Int).Int64() != 1 || args[1].(*big.Int).Int64() != 2 {
+		t.Fatalf("custom error mismatch: %s%v", name, args)
+	}
+	// Builtin revert reasons
+	name, args, err = errs.UnpackError(common.Hex2Bytes("08c379a00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000d72657665727420726561736f6e00000000000000000000000000000000000000"))
+	if err != nil {
+		t.Fatalf("failed to unpack revert reason: %v", err)
+	}
+	if name != "Error" || len(args) != 1 || args[0] != "revert reason" {
+		t.Fatalf("revert reason mismatch: %s%v", name, args)
+	}
+	// Builtin panics
+	name, args, err = errs.UnpackError(common.Hex2Bytes("4e487b710000000000000000000000000000000000000000000000000000000000000011"))
+	if err != nil {
+		t.Fatalf("failed to unpack panic: %v", err)
+	}
+	if name != "Panic" || len(args) != 1 || args[0].(*big.Int).Int64() != 0x11 {
+		t.Fatalf("panic mismatch: %s%v", name, args)
+	}
+	// Unknown selectors and junk
+	if _, _, err := errs.UnpackError(common.Hex2Bytes("deadbeef")); !errors.Is(err, ErrUnknownError) {
+		t.Fatalf("unknown selector error mismatch: have %v, want %v", err, ErrUnknownError)
+	}
+	if _, _, err := errs.UnpackError([]byte{0x01}); err == nil {
+		t.Fatalf("short revert data unpacked")
+	}
+}
@@ -98,6 +98,24 @@ type ContractTransactor interface {
 	SendTransaction(ctx context.Context, tx *types.Transaction) error
 }
 
+// FeeHistoryReader defines the method needed to derive dynamic fee defaults from
+// the fees paid in recent blocks. Transact will try to discover this interface when
+// no priority fee is specified, falling back to SuggestGasTipCap otherwise.
+type FeeHistoryReader interface {
+	// FeeHistory retrieves the base fees and the requested percentiles of the
+	// priority fees paid in the given number of blocks up to lastBlock.
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// BlobTransactor defines the method needed to submit blob transactions. Transact
+// will try to discover this interface when a sidecar is attached to the options,
+// returning ErrNoBlobTransport if the backend does not support it.
+type BlobTransactor interface {
+	// SendBlobTransaction injects the transaction into the pending pool for
+	// execution, along with the blobs, commitments and proofs it refers to.
+	SendBlobTransaction(ctx context.Context, tx *types.Transaction, sidecar *BlobSidecar) error
+}
+
 // ContractFilterer defines the methods needed to access log events using one-off
 // queries or continuous event subscriptions.
 type ContractFilterer interface {
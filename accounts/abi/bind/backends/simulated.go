@@ -30,13 +30,18 @@ import (
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/common/hexutil"
 	"github.com/gorievm/go-gori/common/math"
+	"github.com/gorievm/go-gori/consensus"
+	"github.com/gorievm/go-gori/consensus/beacon"
 	"github.com/gorievm/go-gori/consensus/ethash"
+	"github.com/gorievm/go-gori/consensus/misc/eip4844"
 	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/bloombits"
+	"github.com/gorievm/go-gori/core/filtermaps"
 	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/core/state"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/crypto/kzg4844"
 	"github.com/gorievm/go-gori/eth/filters"
 	"github.com/gorievm/go-gori/ethdb"
 	"github.com/gorievm/go-gori/event"
@@ -48,6 +53,9 @@ import (
 // This nil assignment ensures at compile time that SimulatedBackend implements bind.ContractBackend.
 var _ bind.ContractBackend = (*SimulatedBackend)(nil)
 
+// This nil assignment ensures at compile time that SimulatedBackend implements bind.BlobTransactor.
+var _ bind.BlobTransactor = (*SimulatedBackend)(nil)
+
 var (
 	errBlockNumberUnsupported  = errors.New("simulatedBackend cannot access blocks other than the latest block")
 	errBlockDoesNotExist       = errors.New("block does not exist in blockchain")
@@ -67,28 +75,69 @@ type SimulatedBackend struct {
 	pendingBlock    *types.Block   // Currently pending block that will be imported on request
 	pendingState    *state.StateDB // Currently pending state that will be the active on request
 	pendingReceipts types.Receipts // Currently receipts for the pending block
+	pendingOffset   int64          // Seconds added to the default timestamp of the pending block
+	automine        bool           // Whether to commit a block after each sent transaction
+
+	minerLock sync.Mutex     // Lock protecting the interval miner lifecycle
+	minerQuit chan struct{}  // Quit channel of the interval miner, nil if not running
+	minerWg   sync.WaitGroup // Wait group to wait for the interval miner to stop
 
 	events       *filters.EventSystem  // for filtering log events live
 	filterSystem *filters.FilterSystem // for filtering database logs
 
+	snapshots    map[int]*simulatedSnapshot // Snapshots taken that can be reverted to
+	nextSnapshot int                        // Identifier of the next snapshot to take
+
+	sidecars map[common.Hash]*bind.BlobSidecar // Blob sidecars of sent transactions, keyed by transaction hash
+
 	config *params.ChainConfig
+	engine consensus.Engine
+}
+
+// simulatedSnapshot is the chain head and pending block recorded by Snapshot.
+type simulatedSnapshot struct {
+	head            *types.Block
+	pendingBlock    *types.Block
+	pendingState    *state.StateDB
+	pendingReceipts types.Receipts
+	pendingOffset   int64
 }
 
 // NewSimulatedBackendWithDatabase creates a new binding backend based on the given database
 // and uses a simulated blockchain for testing purposes.
 // A simulated backend always uses chainID 1337.
 func NewSimulatedBackendWithDatabase(database ethdb.Database, alloc core.GenesisAlloc, gasLimit uint64) *SimulatedBackend {
+	return NewSimulatedBackendWithConfig(database, params.AllEthashProtocolChanges, alloc, gasLimit)
+}
+
+// NewSimulatedBackendWithConfig creates a new binding backend based on the given
+// database and chain configuration, using a simulated blockchain for testing purposes.
+//
+// Chains with a zero terminal total difficulty are simulated as merged from the
+// genesis block on, allowing Shanghai and Cancun to be enabled. The latter adds
+// support for blob transactions via SendBlobTransaction. Note, every block of a
+// merged chain becomes the head once committed, including the ones on a Fork-ed
+// side chain.
+func NewSimulatedBackendWithConfig(database ethdb.Database, config *params.ChainConfig, alloc core.GenesisAlloc, gasLimit uint64) *SimulatedBackend {
 	genesis := core.Genesis{
-		Config:   params.AllEthashProtocolChanges,
+		Config:   config,
 		GasLimit: gasLimit,
 		Alloc:    alloc,
 	}
-	blockchain, _ := core.NewBlockChain(database, nil, &genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	var engine consensus.Engine = ethash.NewFaker()
+	if ttd := config.TerminalTotalDifficulty; ttd != nil && ttd.Sign() == 0 {
+		genesis.Difficulty = new(big.Int)
+		engine = beacon.NewFaker()
+	}
+	blockchain, _ := core.NewBlockChain(database, nil, &genesis, nil, engine, vm.Config{}, nil, nil)
 
 	backend := &SimulatedBackend{
 		database:   database,
 		blockchain: blockchain,
+		snapshots:  make(map[int]*simulatedSnapshot),
+		sidecars:   make(map[common.Hash]*bind.BlobSidecar),
 		config:     genesis.Config,
+		engine:     engine,
 	}
 
 	filterBackend := &filterBackend{database, blockchain, backend}
@@ -109,8 +158,10 @@ func NewSimulatedBackend(alloc core.GenesisAlloc, gasLimit uint64) *SimulatedBac
 	return NewSimulatedBackendWithDatabase(rawdb.NewMemoryDatabase(), alloc, gasLimit)
 }
 
-// Close terminates the underlying blockchain's update loop.
+// Close terminates the interval miner, if running, and the underlying blockchain's
+// update loop.
 func (b *SimulatedBackend) Close() error {
+	b.SetIntervalMining(0)
 	b.blockchain.Stop()
 	return nil
 }
@@ -121,6 +172,10 @@ func (b *SimulatedBackend) Commit() common.Hash {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	return b.commit()
+}
+
+func (b *SimulatedBackend) commit() common.Hash {
 	if _, err := b.blockchain.InsertChain([]*types.Block{b.pendingBlock}); err != nil {
 		panic(err) // This cannot happen unless the simulator is wrong, fail in that case
 	}
@@ -141,12 +196,16 @@ func (b *SimulatedBackend) Rollback() {
 	header := b.blockchain.CurrentBlock()
 	block := b.blockchain.GetBlock(header.Hash(), header.Number.Uint64())
 
+	for _, tx := range b.pendingBlock.Transactions() {
+		delete(b.sidecars, tx.Hash())
+	}
 	b.rollback(block)
 }
 
 func (b *SimulatedBackend) rollback(parent *types.Block) {
-	blocks, _ := core.GenerateChain(b.config, parent, ethash.NewFaker(), b.database, 1, func(int, *core.BlockGen) {})
+	blocks, _ := core.GenerateChain(b.config, parent, b.engine, b.database, 1, func(int, *core.BlockGen) {})
 
+	b.pendingOffset = 0
 	b.pendingBlock = blocks[0]
 	b.pendingState, _ = state.New(b.pendingBlock.Root(), b.blockchain.StateCache(), nil)
 }
@@ -178,6 +237,66 @@ func (b *SimulatedBackend) Fork(ctx context.Context, parent common.Hash) error {
 	return nil
 }
 
+// Snapshot records the current chain head along with the pending transactions,
+// returning an identifier that can be passed to Revert to return to this point.
+// Snapshots can be nested, reverting to one discards all taken after it.
+func (b *SimulatedBackend) Snapshot() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	header := b.blockchain.CurrentBlock()
+	id := b.nextSnapshot
+	b.nextSnapshot++
+
+	b.snapshots[id] = &simulatedSnapshot{
+		head:            b.blockchain.GetBlock(header.Hash(), header.Number.Uint64()),
+		pendingBlock:    b.pendingBlock,
+		pendingState:    b.pendingState.Copy(),
+		pendingReceipts: b.pendingReceipts,
+		pendingOffset:   b.pendingOffset,
+	}
+	return id
+}
+
+// Revert rewinds the chain to the head recorded by the given snapshot, dropping
+// all blocks committed since, and restores the pending transactions of that time.
+// The snapshot and all later ones are consumed, so a new snapshot needs to be
+// taken to revert to the same point again.
+func (b *SimulatedBackend) Revert(id int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snap, ok := b.snapshots[id]
+	if !ok {
+		return fmt.Errorf("unknown snapshot %d", id)
+	}
+	if !b.blockchain.HasState(snap.head.Root()) {
+		return fmt.Errorf("state of snapshot %d no longer available", id)
+	}
+	for sid := range b.snapshots {
+		if sid >= id {
+			delete(b.snapshots, sid)
+		}
+	}
+	// Rewind the canonical chain, then switch to the snapshot's branch in case
+	// a side chain was made canonical since
+	if head := b.blockchain.CurrentBlock(); head.Hash() != snap.head.Hash() {
+		if err := b.blockchain.SetHead(snap.head.NumberU64()); err != nil {
+			return err
+		}
+		if b.blockchain.CurrentBlock().Hash() != snap.head.Hash() {
+			if _, err := b.blockchain.SetCanonical(snap.head); err != nil {
+				return err
+			}
+		}
+	}
+	b.pendingBlock = snap.pendingBlock
+	b.pendingState = snap.pendingState.Copy()
+	b.pendingReceipts = snap.pendingReceipts
+	b.pendingOffset = snap.pendingOffset
+	return nil
+}
+
 // stateByBlockNumber retrieves a state by a given blocknumber.
 func (b *SimulatedBackend) stateByBlockNumber(ctx context.Context, blockNumber *big.Int) (*state.StateDB, error) {
 	if blockNumber == nil || blockNumber.Cmp(b.blockchain.CurrentBlock().Number) == 0 {
@@ -674,10 +793,95 @@ func (b *SimulatedBackend) callContract(ctx context.Context, call ethereum.CallM
 }
 
 // SendTransaction updates the pending block to include the given transaction.
+// Blob transactions need to be sent along with their sidecar via SendBlobTransaction.
 func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	if tx.Type() == types.BlobTxType {
+		return errors.New("blob transaction without sidecar")
+	}
+	return b.sendTransaction(ctx, tx)
+}
+
+// SendBlobTransaction implements bind.BlobTransactor, updating the pending block
+// to include the given blob transaction. The sidecar is verified against the blob
+// hashes of the transaction and retained for retrieval via BlobSidecars.
+func (b *SimulatedBackend) SendBlobTransaction(ctx context.Context, tx *types.Transaction, sidecar *bind.BlobSidecar) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.config.IsCancun(b.pendingBlock.Number(), b.pendingBlock.Time()) {
+		return errors.New("blob transactions not supported before Cancun")
+	}
+	if tx.Type() != types.BlobTxType {
+		return fmt.Errorf("invalid transaction type %d, want blob transaction", tx.Type())
+	}
+	if err := verifyBlobSidecar(tx, sidecar); err != nil {
+		return fmt.Errorf("invalid blob sidecar: %v", err)
+	}
+	if used := *b.pendingBlock.BlobGasUsed() + tx.BlobGas(); used > params.BlobTxMaxBlobGasPerBlock {
+		return fmt.Errorf("pending block blob gas exceeded: have %d, max %d", used, params.BlobTxMaxBlobGasPerBlock)
+	}
+	if fee := eip4844.CalcBlobFee(*b.pendingBlock.ExcessBlobGas()); tx.BlobGasFeeCapIntCmp(fee) < 0 {
+		return fmt.Errorf("blob fee cap too low: have %v, want %v", tx.BlobGasFeeCap(), fee)
+	}
+	b.sidecars[tx.Hash()] = sidecar
+	if err := b.sendTransaction(ctx, tx); err != nil {
+		delete(b.sidecars, tx.Hash())
+		return err
+	}
+	return nil
+}
+
+// verifyBlobSidecar checks that the sidecar matches the blob hashes of the
+// transaction and that the KZG proofs of its blobs are valid.
+func verifyBlobSidecar(tx *types.Transaction, sidecar *bind.BlobSidecar) error {
+	if sidecar == nil {
+		return errors.New("missing sidecar")
+	}
+	hashes := tx.BlobHashes()
+	if len(sidecar.Blobs) != len(hashes) || len(sidecar.Commitments) != len(hashes) || len(sidecar.Proofs) != len(hashes) {
+		return fmt.Errorf("sidecar size mismatch: %d blobs, %d commitments and %d proofs for %d hashes", len(sidecar.Blobs), len(sidecar.Commitments), len(sidecar.Proofs), len(hashes))
+	}
+	for i, hash := range sidecar.BlobHashes() {
+		if hash != hashes[i] {
+			return fmt.Errorf("blob %d: commitment hash mismatch: have %x, want %x", i, hash, hashes[i])
+		}
+		if err := kzg4844.VerifyBlobProof(sidecar.Blobs[i], sidecar.Commitments[i], sidecar.Proofs[i]); err != nil {
+			return fmt.Errorf("blob %d: invalid proof: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// BlobSidecars returns the sidecars of the blob transactions included in the
+// block with the given hash, in the order of the transactions.
+func (b *SimulatedBackend) BlobSidecars(ctx context.Context, blockHash common.Hash) ([]*bind.BlobSidecar, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	block, err := b.blockByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	var sidecars []*bind.BlobSidecar
+	for _, tx := range block.Transactions() {
+		if tx.Type() != types.BlobTxType {
+			continue
+		}
+		sidecar, ok := b.sidecars[tx.Hash()]
+		if !ok {
+			return nil, fmt.Errorf("missing sidecar of transaction %x", tx.Hash())
+		}
+		sidecars = append(sidecars, sidecar)
+	}
+	return sidecars, nil
+}
+
+// sendTransaction adds the transaction to the pending block. The caller must
+// hold the lock of the backend.
+func (b *SimulatedBackend) sendTransaction(ctx context.Context, tx *types.Transaction) error {
 	// Get the last block
 	block, err := b.blockByHash(ctx, b.pendingBlock.ParentHash())
 	if err != nil {
@@ -694,7 +898,10 @@ func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transa
 		return fmt.Errorf("invalid transaction nonce: got %d, want %d", tx.Nonce(), nonce)
 	}
 	// Include tx in chain
-	blocks, receipts := core.GenerateChain(b.config, block, ethash.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
+	blocks, receipts := core.GenerateChain(b.config, block, b.engine, b.database, 1, func(number int, block *core.BlockGen) {
+		if b.pendingOffset != 0 {
+			block.OffsetTime(b.pendingOffset)
+		}
 		for _, tx := range b.pendingBlock.Transactions() {
 			block.AddTxWithChain(b.blockchain, tx)
 		}
@@ -705,6 +912,10 @@ func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transa
 	b.pendingBlock = blocks[0]
 	b.pendingState, _ = state.New(b.pendingBlock.Root(), stateDB.Database(), nil)
 	b.pendingReceipts = receipts[0]
+
+	if b.automine {
+		b.commit()
+	}
 	return nil
 }
 
@@ -818,17 +1029,95 @@ func (b *SimulatedBackend) AdjustTime(adjustment time.Duration) error {
 		return errors.New("could not find parent")
 	}
 
-	blocks, _ := core.GenerateChain(b.config, block, ethash.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
+	blocks, _ := core.GenerateChain(b.config, block, b.engine, b.database, 1, func(number int, block *core.BlockGen) {
 		block.OffsetTime(int64(adjustment.Seconds()))
 	})
 	stateDB, _ := b.blockchain.State()
 
+	b.pendingOffset = 0
 	b.pendingBlock = blocks[0]
 	b.pendingState, _ = state.New(b.pendingBlock.Root(), stateDB.Database(), nil)
 
 	return nil
 }
 
+// SetNextBlockTimestamp sets the timestamp of the pending block, which needs to
+// be after the one of its parent. Blocks committed afterwards continue from it.
+// It can only be called on empty blocks, but unlike with AdjustTime, the timestamp
+// is retained when transactions are added to the pending block afterwards.
+func (b *SimulatedBackend) SetNextBlockTimestamp(timestamp uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.pendingBlock.Transactions()) != 0 {
+		return errors.New("could not set timestamp of non-empty block")
+	}
+	block := b.blockchain.GetBlockByHash(b.pendingBlock.ParentHash())
+	if block == nil {
+		return errors.New("could not find parent")
+	}
+	if timestamp <= block.Time() {
+		return fmt.Errorf("timestamp %d not after parent timestamp %d", timestamp, block.Time())
+	}
+	var offset int64
+	blocks, _ := core.GenerateChain(b.config, block, b.engine, b.database, 1, func(number int, block *core.BlockGen) {
+		offset = int64(timestamp) - int64(block.Timestamp())
+		block.OffsetTime(offset)
+	})
+	stateDB, _ := b.blockchain.State()
+
+	b.pendingOffset = offset
+	b.pendingBlock = blocks[0]
+	b.pendingState, _ = state.New(b.pendingBlock.Root(), stateDB.Database(), nil)
+
+	return nil
+}
+
+// SetAutomine toggles whether a block is committed after each sent transaction,
+// so that transactions are mined immediately without calling Commit.
+func (b *SimulatedBackend) SetAutomine(enabled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.automine = enabled
+}
+
+// SetIntervalMining starts committing a block at the given interval, including
+// whatever transactions are pending at the time. A non-positive interval stops
+// interval mining.
+func (b *SimulatedBackend) SetIntervalMining(interval time.Duration) {
+	b.minerLock.Lock()
+	defer b.minerLock.Unlock()
+
+	if b.minerQuit != nil {
+		close(b.minerQuit)
+		b.minerWg.Wait()
+		b.minerQuit = nil
+	}
+	if interval <= 0 {
+		return
+	}
+	quit := make(chan struct{})
+	b.minerQuit = quit
+
+	b.minerWg.Add(1)
+	go func() {
+		defer b.minerWg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				b.Commit()
+			case <-quit:
+				return
+			}
+		}
+	}()
+}
+
 // Blockchain returns the underlying blockchain.
 func (b *SimulatedBackend) Blockchain() *core.BlockChain {
 	return b.blockchain
@@ -900,6 +1189,10 @@ func (fb *filterBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.
 	return nullSubscription()
 }
 
+func (fb *filterBackend) SubscribeDropTxsEvent(ch chan<- core.DropTxsEvent) event.Subscription {
+	return nullSubscription()
+}
+
 func (fb *filterBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return fb.bc.SubscribeChainEvent(ch)
 }
@@ -922,6 +1215,10 @@ func (fb *filterBackend) ServiceFilter(ctx context.Context, ms *bloombits.Matche
 	panic("not supported")
 }
 
+func (fb *filterBackend) LogIndex() *filtermaps.FilterMaps { return nil }
+
+func (fb *filterBackend) HistoryPruningCutoff() uint64 { return fb.bc.HistoryPruningCutoff() }
+
 func (fb *filterBackend) ChainConfig() *params.ChainConfig {
 	panic("not supported")
 }
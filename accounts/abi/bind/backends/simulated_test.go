@@ -31,9 +31,12 @@ import (
 	"github.com/gorievm/go-gori/accounts/abi"
 	"github.com/gorievm/go-gori/accounts/abi/bind"
 	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/consensus/misc/eip4844"
 	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/crypto/kzg4844"
 	"github.com/gorievm/go-gori/params"
 )
 
@@ -1398,3 +1401,232 @@ func TestAdjustTimeAfterFork(t *testing.T) {
 		t.Errorf("failed to build block on fork")
 	}
 }
+
+func TestSnapshotRevert(t *testing.T) {
+	testAddr := crypto.PubkeyToAddress(testKey.PublicKey)
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+
+	var (
+		ctx       = context.Background()
+		recipient = common.Address{0x1}
+		nonce     = uint64(0)
+	)
+	transfer := func() {
+		head, _ := sim.HeaderByNumber(ctx, nil)
+		gasPrice := new(big.Int).Add(head.BaseFee, big.NewInt(1))
+
+		tx, _ := types.SignTx(types.NewTransaction(nonce, recipient, big.NewInt(1), params.TxGas, gasPrice, nil), types.HomesteadSigner{}, testKey)
+		if err := sim.SendTransaction(ctx, tx); err != nil {
+			t.Fatalf("could not add tx to pending block: %v", err)
+		}
+		nonce++
+	}
+	balance := func() uint64 {
+		bal, err := sim.BalanceAt(ctx, recipient, nil)
+		if err != nil {
+			t.Fatalf("could not get balance: %v", err)
+		}
+		return bal.Uint64()
+	}
+	transfer()
+	sim.Commit()
+
+	// Snapshot the committed block, then with a pending transfer on top
+	clean := sim.Snapshot()
+	head := sim.blockchain.CurrentBlock()
+	transfer()
+	pending := sim.Snapshot()
+
+	for i := 0; i < 3; i++ {
+		sim.Commit()
+	}
+	if have := sim.blockchain.CurrentBlock().Number.Uint64(); have != head.Number.Uint64()+3 {
+		t.Fatalf("chain length mismatch: have %d, want %d", have, head.Number.Uint64()+3)
+	}
+	// Reverting to the pending snapshot rewinds the chain but keeps the transfer
+	if err := sim.Revert(pending); err != nil {
+		t.Fatalf("failed to revert to pending snapshot: %v", err)
+	}
+	if have := sim.blockchain.CurrentBlock().Hash(); have != head.Hash() {
+		t.Fatalf("head mismatch after revert: have %x, want %x", have, head.Hash())
+	}
+	if have := len(sim.pendingBlock.Transactions()); have != 1 {
+		t.Fatalf("pending transaction count mismatch: have %d, want 1", have)
+	}
+	sim.Commit()
+	if have := balance(); have != 2 {
+		t.Fatalf("balance mismatch: have %d, want 2", have)
+	}
+	// The pending snapshot was consumed, the clean one is still available
+	if err := sim.Revert(pending); err == nil {
+		t.Fatalf("reverted to consumed snapshot")
+	}
+	if err := sim.Revert(clean); err != nil {
+		t.Fatalf("failed to revert to clean snapshot: %v", err)
+	}
+	if have := len(sim.pendingBlock.Transactions()); have != 0 {
+		t.Fatalf("pending transaction count mismatch: have %d, want 0", have)
+	}
+	if have := balance(); have != 1 {
+		t.Fatalf("balance mismatch: have %d, want 1", have)
+	}
+}
+
+func TestSetNextBlockTimestamp(t *testing.T) {
+	testAddr := crypto.PubkeyToAddress(testKey.PublicKey)
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+
+	parent := sim.blockchain.CurrentBlock()
+	if err := sim.SetNextBlockTimestamp(parent.Time); err == nil {
+		t.Fatalf("accepted timestamp equal to the parent's")
+	}
+	want := parent.Time + 1000
+	if err := sim.SetNextBlockTimestamp(want); err != nil {
+		t.Fatalf("failed to set timestamp: %v", err)
+	}
+	// The timestamp must survive transactions being added to the pending block
+	head, _ := sim.HeaderByNumber(context.Background(), nil)
+	gasPrice := new(big.Int).Add(head.BaseFee, big.NewInt(1))
+	tx, _ := types.SignTx(types.NewTransaction(0, testAddr, big.NewInt(1), params.TxGas, gasPrice, nil), types.HomesteadSigner{}, testKey)
+	if err := sim.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("could not add tx to pending block: %v", err)
+	}
+	if err := sim.SetNextBlockTimestamp(want + 1); err == nil {
+		t.Fatalf("set timestamp of non-empty block")
+	}
+	sim.Commit()
+	if have := sim.blockchain.CurrentBlock().Time; have != want {
+		t.Fatalf("block timestamp mismatch: have %d, want %d", have, want)
+	}
+	// Subsequent blocks continue from the set timestamp
+	sim.Commit()
+	if have := sim.blockchain.CurrentBlock().Time; have <= want {
+		t.Fatalf("block timestamp not after the set one: have %d, want > %d", have, want)
+	}
+}
+
+func TestAutomine(t *testing.T) {
+	testAddr := crypto.PubkeyToAddress(testKey.PublicKey)
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+
+	sim.SetAutomine(true)
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		head, _ := sim.HeaderByNumber(context.Background(), nil)
+		gasPrice := new(big.Int).Add(head.BaseFee, big.NewInt(1))
+		tx, _ := types.SignTx(types.NewTransaction(nonce, testAddr, big.NewInt(1), params.TxGas, gasPrice, nil), types.HomesteadSigner{}, testKey)
+		if err := sim.SendTransaction(context.Background(), tx); err != nil {
+			t.Fatalf("tx %d: could not send: %v", nonce, err)
+		}
+		if receipt, err := sim.TransactionReceipt(context.Background(), tx.Hash()); err != nil || receipt == nil {
+			t.Fatalf("tx %d: not mined: %v", nonce, err)
+		}
+	}
+	if have := sim.blockchain.CurrentBlock().Number.Uint64(); have != 3 {
+		t.Fatalf("chain length mismatch: have %d, want 3", have)
+	}
+	// Once disabled, transactions remain pending until committed
+	sim.SetAutomine(false)
+	head, _ := sim.HeaderByNumber(context.Background(), nil)
+	gasPrice := new(big.Int).Add(head.BaseFee, big.NewInt(1))
+	tx, _ := types.SignTx(types.NewTransaction(3, testAddr, big.NewInt(1), params.TxGas, gasPrice, nil), types.HomesteadSigner{}, testKey)
+	if err := sim.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("could not send: %v", err)
+	}
+	if have := sim.blockchain.CurrentBlock().Number.Uint64(); have != 3 {
+		t.Fatalf("chain length mismatch: have %d, want 3", have)
+	}
+}
+
+func TestIntervalMining(t *testing.T) {
+	testAddr := crypto.PubkeyToAddress(testKey.PublicKey)
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+
+	sim.SetIntervalMining(10 * time.Millisecond)
+	deadline := time.Now().Add(5 * time.Second)
+	for sim.blockchain.CurrentBlock().Number.Uint64() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("no blocks mined in interval mode")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	sim.SetIntervalMining(0)
+	number := sim.blockchain.CurrentBlock().Number.Uint64()
+	time.Sleep(50 * time.Millisecond)
+	if have := sim.blockchain.CurrentBlock().Number.Uint64(); have != number {
+		t.Fatalf("blocks mined after stopping: have %d, want %d", have, number)
+	}
+}
+
+func TestBlobTransactions(t *testing.T) {
+	testAddr := crypto.PubkeyToAddress(testKey.PublicKey)
+	config := *params.AllDevChainProtocolChanges
+	config.CancunTime = new(uint64)
+
+	sim := NewSimulatedBackendWithConfig(rawdb.NewMemoryDatabase(), &config, core.GenesisAlloc{
+		testAddr: {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))},
+	}, 10_000_000)
+	defer sim.Close()
+
+	blobs := make([]kzg4844.Blob, params.BlobTxMaxBlobGasPerBlock/params.BlobTxBlobGasPerBlob)
+	for i := range blobs {
+		blobs[i][0] = byte(i)
+	}
+	sidecar, err := bind.NewBlobSidecar(blobs)
+	if err != nil {
+		t.Fatalf("failed to create sidecar: %v", err)
+	}
+	opts, _ := bind.NewKeyedTransactorWithChainID(testKey, big.NewInt(1337))
+	opts.Sidecar = sidecar
+	opts.GasLimit = params.TxGas
+
+	contract := bind.NewBoundContract(common.Address{0xaa}, abi.ABI{}, sim, sim, sim)
+	tx, err := contract.RawTransact(opts, nil)
+	if err != nil {
+		t.Fatalf("failed to send blob transaction: %v", err)
+	}
+	// Blob transactions are rejected without a matching sidecar
+	if err := sim.SendTransaction(context.Background(), tx); err == nil {
+		t.Fatal("blob transaction accepted without sidecar")
+	}
+	other, _ := bind.NewBlobSidecar([]kzg4844.Blob{{}})
+	if err := sim.SendBlobTransaction(context.Background(), tx, other); err == nil {
+		t.Fatal("blob transaction accepted with mismatching sidecar")
+	}
+	hash := sim.Commit()
+
+	receipt, err := sim.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful || receipt.BlockHash != hash {
+		t.Fatalf("blob transaction not included successfully: status %d, block %x", receipt.Status, receipt.BlockHash)
+	}
+	block, _ := sim.BlockByHash(context.Background(), hash)
+	if have, want := *block.BlobGasUsed(), tx.BlobGas(); have != want {
+		t.Fatalf("blob gas used mismatch: have %d, want %d", have, want)
+	}
+	sidecars, err := sim.BlobSidecars(context.Background(), hash)
+	if err != nil {
+		t.Fatalf("failed to retrieve sidecars: %v", err)
+	}
+	if len(sidecars) != 1 || !reflect.DeepEqual(sidecars[0], sidecar) {
+		t.Fatalf("sidecars mismatch: have %d, want 1", len(sidecars))
+	}
+	// Blocks above the blob gas target raise the blob fee of their successors
+	sim.Commit()
+	head, _ := sim.HeaderByNumber(context.Background(), nil)
+	if want := eip4844.CalcExcessBlobGas(0, tx.BlobGas()); *head.ExcessBlobGas != want || want == 0 {
+		t.Fatalf("excess blob gas mismatch: have %d, want %d", *head.ExcessBlobGas, want)
+	}
+	// Blob transactions are rejected before Cancun
+	legacy := simTestBackend(testAddr)
+	defer legacy.Close()
+
+	if err := legacy.SendBlobTransaction(context.Background(), tx, sidecar); err == nil {
+		t.Fatal("blob transaction accepted before cancun")
+	}
+}
@@ -27,12 +27,26 @@ import (
 	"github.com/gorievm/go-gori"
 	"github.com/gorievm/go-gori/accounts/abi"
 	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/consensus/misc/eip4844"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/crypto"
 	"github.com/gorievm/go-gori/event"
+	"github.com/holiman/uint256"
+	"golang.org/x/exp/slices"
 )
 
-const basefeeWiggleMultiplier = 2
+const (
+	basefeeWiggleMultiplier = 2
+
+	// feeHistoryBlocks is the number of recent blocks whose priority fees are
+	// considered when deriving the default tip of a dynamic fee transaction.
+	feeHistoryBlocks = 20
+
+	// feeHistoryPercentile is the percentile of the priority fees paid within a
+	// block that is considered representative for it.
+	feeHistoryPercentile = 60
+)
 
 var (
 	errNoEventSignature       = errors.New("no event signature")
@@ -64,6 +78,9 @@ type TransactOpts struct {
 	GasTipCap *big.Int // Gas priority fee cap to use for the 1559 transaction execution (nil = gas price oracle)
 	GasLimit  uint64   // Gas limit to set for the transaction execution (0 = estimate)
 
+	BlobFeeCap *big.Int     // Blob gas fee cap to use for the 4844 transaction execution (nil = blob fee oracle)
+	Sidecar    *BlobSidecar // Blobs to attach, turning the transaction into a 4844 one (nil = no blobs)
+
 	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
 
 	NoSend bool // Do all transact steps but do not send the transaction
@@ -117,18 +134,83 @@ type BoundContract struct {
 	caller     ContractCaller     // Read interface to interact with the blockchain
 	transactor ContractTransactor // Write interface to interact with the blockchain
 	filterer   ContractFilterer   // Event filtering to interact with the blockchain
+	errors     *abi.Errors        // Registry of custom errors to decode reverts with
 }
 
 // NewBoundContract creates a low level contract interface through which calls
 // and transactions may be made through.
-func NewBoundContract(address common.Address, abi abi.ABI, caller ContractCaller, transactor ContractTransactor, filterer ContractFilterer) *BoundContract {
+func NewBoundContract(address common.Address, contractABI abi.ABI, caller ContractCaller, transactor ContractTransactor, filterer ContractFilterer) *BoundContract {
 	return &BoundContract{
 		address:    address,
-		abi:        abi,
+		abi:        contractABI,
 		caller:     caller,
 		transactor: transactor,
 		filterer:   filterer,
+		errors:     abi.NewErrors(&contractABI),
+	}
+}
+
+// RevertError is returned by contract calls and gas estimations that reverted
+// with an error known to the contract's ABI, either a custom Solidity error or
+// one of the builtin Error(string) and Panic(uint256) errors.
+type RevertError struct {
+	Name string        // Name of the error the contract reverted with
+	Args []interface{} // Decoded arguments of the error
+	Data []byte        // Raw revert data returned by the contract
+
+	err error // Original error returned by the backend
+}
+
+// Error implements error, formatting the decoded error like a Solidity call.
+func (e *RevertError) Error() string {
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = fmt.Sprintf("%v", arg)
+	}
+	return fmt.Sprintf("execution reverted: %s(%s)", e.Name, strings.Join(args, ", "))
+}
+
+// Unwrap returns the original error returned by the backend.
+func (e *RevertError) Unwrap() error {
+	return e.err
+}
+
+// unpackError attempts to decode the revert data carried by an error returned
+// by the backend into one of the contract's known errors. If the error carries
+// no revert data or it cannot be matched, the original error is returned.
+func (c *BoundContract) unpackError(err error) error {
+	var de interface{ ErrorData() interface{} }
+	if !errors.As(err, &de) {
+		return err
+	}
+	var data []byte
+	switch v := de.ErrorData().(type) {
+	case string:
+		blob, derr := hexutil.Decode(v)
+		if derr != nil {
+			return err
+		}
+		data = blob
+	case []byte:
+		data = v
+	default:
+		return err
+	}
+	name, args, uerr := c.errors.UnpackError(data)
+	if uerr != nil {
+		return err
 	}
+	return &RevertError{Name: name, Args: args, Data: data, err: err}
+}
+
+// unpackRevert decodes the revert data of a call that failed within a batch into
+// one of the contract's known errors, falling back to ErrCallReverted.
+func (c *BoundContract) unpackRevert(data []byte) error {
+	name, args, err := c.errors.UnpackError(data)
+	if err != nil {
+		return ErrCallReverted
+	}
+	return &RevertError{Name: name, Args: args, Data: data, err: ErrCallReverted}
 }
 
 // DeployContract deploys a contract onto the Ori blockchain and binds the
@@ -179,7 +261,7 @@ func (c *BoundContract) Call(opts *CallOpts, results *[]interface{}, method stri
 		}
 		output, err = pb.PendingCallContract(ctx, msg)
 		if err != nil {
-			return err
+			return c.unpackError(err)
 		}
 		if len(output) == 0 {
 			// Make sure we have a contract to operate on, and bail out otherwise.
@@ -192,7 +274,7 @@ func (c *BoundContract) Call(opts *CallOpts, results *[]interface{}, method stri
 	} else {
 		output, err = c.caller.CallContract(ctx, msg, opts.BlockNumber)
 		if err != nil {
-			return err
+			return c.unpackError(err)
 		}
 		if len(output) == 0 {
 			// Make sure we have a contract to operate on, and bail out otherwise.
@@ -203,7 +285,13 @@ func (c *BoundContract) Call(opts *CallOpts, results *[]interface{}, method stri
 			}
 		}
 	}
+	return c.unpack(results, method, output)
+}
 
+// unpack decodes the output of a contract method call into results. If results
+// is empty, it is filled with the decoded values, otherwise the output is decoded
+// into its first element.
+func (c *BoundContract) unpack(results *[]interface{}, method string, output []byte) error {
 	if len(*results) == 0 {
 		res, err := c.abi.Unpack(method, output)
 		*results = res
@@ -247,30 +335,14 @@ func (c *BoundContract) createDynamicTx(opts *TransactOpts, contract *common.Add
 	if value == nil {
 		value = new(big.Int)
 	}
-	// Estimate TipCap
-	gasTipCap := opts.GasTipCap
-	if gasTipCap == nil {
-		tip, err := c.transactor.SuggestGasTipCap(ensureContext(opts.Context))
-		if err != nil {
-			return nil, err
-		}
-		gasTipCap = tip
-	}
-	// Estimate FeeCap
-	gasFeeCap := opts.GasFeeCap
-	if gasFeeCap == nil {
-		gasFeeCap = new(big.Int).Add(
-			gasTipCap,
-			new(big.Int).Mul(head.BaseFee, big.NewInt(basefeeWiggleMultiplier)),
-		)
-	}
-	if gasFeeCap.Cmp(gasTipCap) < 0 {
-		return nil, fmt.Errorf("maxFeePerGas (%v) < maxPriorityFeePerGas (%v)", gasFeeCap, gasTipCap)
+	// Estimate TipCap and FeeCap
+	gasTipCap, gasFeeCap, err := c.suggestDynamicFees(opts, head)
+	if err != nil {
+		return nil, err
 	}
 	// Estimate GasLimit
 	gasLimit := opts.GasLimit
 	if opts.GasLimit == 0 {
-		var err error
 		gasLimit, err = c.estimateGasLimit(opts, contract, input, nil, gasTipCap, gasFeeCap, value)
 		if err != nil {
 			return nil, err
@@ -293,6 +365,135 @@ func (c *BoundContract) createDynamicTx(opts *TransactOpts, contract *common.Add
 	return types.NewTx(baseTx), nil
 }
 
+func (c *BoundContract) createBlobTx(opts *TransactOpts, contract *common.Address, input []byte, head *types.Header) (*types.Transaction, error) {
+	if contract == nil {
+		return nil, errors.New("blob transactions cannot deploy contracts")
+	}
+	if err := opts.Sidecar.validate(); err != nil {
+		return nil, err
+	}
+	// Normalize value
+	value := opts.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	// Estimate TipCap and FeeCap
+	gasTipCap, gasFeeCap, err := c.suggestDynamicFees(opts, head)
+	if err != nil {
+		return nil, err
+	}
+	// Estimate BlobFeeCap
+	blobFeeCap := opts.BlobFeeCap
+	if blobFeeCap == nil {
+		if head.ExcessBlobGas == nil {
+			return nil, errors.New("blob sidecar specified but cancun is not active yet")
+		}
+		blobFeeCap = new(big.Int).Mul(eip4844.CalcBlobFee(*head.ExcessBlobGas), big.NewInt(basefeeWiggleMultiplier))
+	}
+	// Estimate GasLimit
+	gasLimit := opts.GasLimit
+	if opts.GasLimit == 0 {
+		gasLimit, err = c.estimateGasLimit(opts, contract, input, nil, gasTipCap, gasFeeCap, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// create the transaction
+	nonce, err := c.getNonce(opts)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		tip, tipOverflow    = uint256.FromBig(gasTipCap)
+		fee, feeOverflow    = uint256.FromBig(gasFeeCap)
+		blob, blobOverflow  = uint256.FromBig(blobFeeCap)
+		amount, valOverflow = uint256.FromBig(value)
+	)
+	if tipOverflow || feeOverflow || blobOverflow || valOverflow {
+		return nil, errors.New("blob transaction fee or value exceeds 256 bits")
+	}
+	baseTx := &types.BlobTx{
+		ChainID:    new(uint256.Int), // filled in by the signer
+		To:         *contract,
+		Nonce:      nonce,
+		GasTipCap:  tip,
+		GasFeeCap:  fee,
+		Gas:        gasLimit,
+		Value:      amount,
+		Data:       input,
+		BlobFeeCap: blob,
+		BlobHashes: opts.Sidecar.BlobHashes(),
+	}
+	return types.NewTx(baseTx), nil
+}
+
+// suggestDynamicFees returns the priority fee and fee cap to use for a dynamic fee
+// transaction, deriving any values not specified in the options. If the transactor
+// can report the fee history, the tip is the median of the priority fees paid in
+// recent blocks, otherwise the transactor's own suggestion is used.
+func (c *BoundContract) suggestDynamicFees(opts *TransactOpts, head *types.Header) (*big.Int, *big.Int, error) {
+	var (
+		gasTipCap = opts.GasTipCap
+		gasFeeCap = opts.GasFeeCap
+		baseFee   *big.Int
+	)
+	if head != nil {
+		baseFee = head.BaseFee
+	}
+	if gasTipCap == nil {
+		tip, next := c.feeHistory(ensureContext(opts.Context))
+		if tip == nil {
+			var err error
+			if tip, err = c.transactor.SuggestGasTipCap(ensureContext(opts.Context)); err != nil {
+				return nil, nil, err
+			}
+		}
+		if next != nil && (baseFee == nil || next.Cmp(baseFee) > 0) {
+			baseFee = next
+		}
+		gasTipCap = tip
+	}
+	if gasFeeCap == nil {
+		gasFeeCap = new(big.Int).Add(
+			gasTipCap,
+			new(big.Int).Mul(baseFee, big.NewInt(basefeeWiggleMultiplier)),
+		)
+	}
+	if gasFeeCap.Cmp(gasTipCap) < 0 {
+		return nil, nil, fmt.Errorf("maxFeePerGas (%v) < maxPriorityFeePerGas (%v)", gasFeeCap, gasTipCap)
+	}
+	return gasTipCap, gasFeeCap, nil
+}
+
+// feeHistory derives a priority fee from the fees paid in recent blocks, and also
+// returns the base fee of the next block. Nil values are returned if the transactor
+// does not support fee history queries, or the history holds no rewards.
+func (c *BoundContract) feeHistory(ctx context.Context) (*big.Int, *big.Int) {
+	reader, ok := c.transactor.(FeeHistoryReader)
+	if !ok {
+		return nil, nil
+	}
+	history, err := reader.FeeHistory(ctx, feeHistoryBlocks, nil, []float64{feeHistoryPercentile})
+	if err != nil || history == nil {
+		return nil, nil
+	}
+	var next *big.Int
+	if len(history.BaseFee) > 0 {
+		next = history.BaseFee[len(history.BaseFee)-1]
+	}
+	var rewards []*big.Int
+	for _, reward := range history.Reward {
+		if len(reward) > 0 && reward[0] != nil {
+			rewards = append(rewards, reward[0])
+		}
+	}
+	if len(rewards) == 0 {
+		return nil, next
+	}
+	slices.SortFunc(rewards, func(a, b *big.Int) int { return a.Cmp(b) })
+	return rewards[len(rewards)/2], next
+}
+
 func (c *BoundContract) createLegacyTx(opts *TransactOpts, contract *common.Address, input []byte) (*types.Transaction, error) {
 	if opts.GasFeeCap != nil || opts.GasTipCap != nil {
 		return nil, errors.New("maxFeePerGas or maxPriorityFeePerGas specified but london is not active yet")
@@ -354,7 +555,11 @@ func (c *BoundContract) estimateGasLimit(opts *TransactOpts, contract *common.Ad
 		Value:     value,
 		Data:      input,
 	}
-	return c.transactor.EstimateGas(ensureContext(opts.Context), msg)
+	gas, err := c.transactor.EstimateGas(ensureContext(opts.Context), msg)
+	if err != nil {
+		return 0, c.unpackError(err)
+	}
+	return gas, nil
 }
 
 func (c *BoundContract) getNonce(opts *TransactOpts) (uint64, error) {
@@ -376,7 +581,20 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 		rawTx *types.Transaction
 		err   error
 	)
-	if opts.GasPrice != nil {
+	if opts.Sidecar != nil {
+		if opts.GasPrice != nil {
+			return nil, errors.New("gasPrice specified for blob transaction")
+		}
+		// Blob transactions always need the head for the blob and base fees
+		head, errHead := c.transactor.HeaderByNumber(ensureContext(opts.Context), nil)
+		if errHead != nil {
+			return nil, errHead
+		}
+		if head.BaseFee == nil {
+			return nil, errors.New("blob sidecar specified but london is not active yet")
+		}
+		rawTx, err = c.createBlobTx(opts, contract, input, head)
+	} else if opts.GasPrice != nil {
 		rawTx, err = c.createLegacyTx(opts, contract, input)
 	} else if opts.GasFeeCap != nil && opts.GasTipCap != nil {
 		rawTx, err = c.createDynamicTx(opts, contract, input, nil)
@@ -405,6 +623,16 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 	if opts.NoSend {
 		return signedTx, nil
 	}
+	if opts.Sidecar != nil {
+		bt, ok := c.transactor.(BlobTransactor)
+		if !ok {
+			return nil, ErrNoBlobTransport
+		}
+		if err := bt.SendBlobTransaction(ensureContext(opts.Context), signedTx, opts.Sidecar); err != nil {
+			return nil, err
+		}
+		return signedTx, nil
+	}
 	if err := c.transactor.SendTransaction(ensureContext(opts.Context), signedTx); err != nil {
 		return nil, err
 	}
@@ -31,6 +31,7 @@ import (
 	"github.com/gorievm/go-gori/common/hexutil"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/crypto/kzg4844"
 	"github.com/gorievm/go-gori/rlp"
 	"github.com/stretchr/testify/assert"
 )
@@ -346,6 +347,95 @@ func TestTransactGasFee(t *testing.T) {
 	assert.True(mt.suggestGasPriceCalled)
 }
 
+// mockFeeHistoryTransactor is a transactor also reporting the fee history and
+// accepting blob transactions along with their sidecars.
+type mockFeeHistoryTransactor struct {
+	mockTransactor
+	excessBlobGas *uint64
+	history       *ethereum.FeeHistory
+	sidecar       *bind.BlobSidecar
+}
+
+func (mt *mockFeeHistoryTransactor) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{BaseFee: mt.baseFee, ExcessBlobGas: mt.excessBlobGas}, nil
+}
+
+func (mt *mockFeeHistoryTransactor) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return mt.history, nil
+}
+
+func (mt *mockFeeHistoryTransactor) SendBlobTransaction(ctx context.Context, tx *types.Transaction, sidecar *bind.BlobSidecar) error {
+	mt.sidecar = sidecar
+	return nil
+}
+
+func TestTransactFeeHistory(t *testing.T) {
+	assert := assert.New(t)
+
+	// The tip is the median of the recent rewards, the fee cap is derived from
+	// the base fee of the next block if it exceeds the head's
+	mt := &mockFeeHistoryTransactor{
+		mockTransactor: mockTransactor{baseFee: big.NewInt(100), gasTipCap: big.NewInt(5)},
+		history: &ethereum.FeeHistory{
+			Reward:  [][]*big.Int{{big.NewInt(3)}, {big.NewInt(9)}, {big.NewInt(7)}},
+			BaseFee: []*big.Int{big.NewInt(90), big.NewInt(95), big.NewInt(100), big.NewInt(110)},
+		},
+	}
+	bc := bind.NewBoundContract(common.Address{}, abi.ABI{}, nil, mt, nil)
+	tx, err := bc.Transact(&bind.TransactOpts{Signer: mockSign}, "")
+	assert.Nil(err)
+	assert.Equal(big.NewInt(7), tx.GasTipCap())
+	assert.Equal(big.NewInt(227), tx.GasFeeCap())
+	assert.False(mt.suggestGasTipCapCalled)
+
+	// Without any rewards in the history, the transactor's suggestion is used
+	mt.history = &ethereum.FeeHistory{Reward: [][]*big.Int{{}, {}}, BaseFee: []*big.Int{big.NewInt(100)}}
+	tx, err = bc.Transact(&bind.TransactOpts{Signer: mockSign}, "")
+	assert.Nil(err)
+	assert.Equal(big.NewInt(5), tx.GasTipCap())
+	assert.Equal(big.NewInt(205), tx.GasFeeCap())
+	assert.True(mt.suggestGasTipCapCalled)
+}
+
+func TestTransactBlobTx(t *testing.T) {
+	assert := assert.New(t)
+
+	sidecar, err := bind.NewBlobSidecar([]kzg4844.Blob{{}})
+	assert.Nil(err)
+
+	// Blob transactions cannot be created before cancun without a blob fee cap
+	mt := &mockFeeHistoryTransactor{
+		mockTransactor: mockTransactor{baseFee: big.NewInt(100), gasTipCap: big.NewInt(5)},
+	}
+	bc := bind.NewBoundContract(common.Address{0x1}, abi.ABI{}, nil, mt, nil)
+	_, err = bc.Transact(&bind.TransactOpts{Signer: mockSign, Sidecar: sidecar}, "")
+	assert.NotNil(err)
+
+	// Once cancun is active, the blob fee cap defaults to twice the blob fee
+	excess := uint64(0)
+	mt.excessBlobGas = &excess
+
+	tx, err := bc.Transact(&bind.TransactOpts{Signer: mockSign, Sidecar: sidecar}, "")
+	assert.Nil(err)
+	assert.Equal(uint8(types.BlobTxType), tx.Type())
+	assert.Equal(common.Address{0x1}, *tx.To())
+	assert.Equal(big.NewInt(5), tx.GasTipCap())
+	assert.Equal(big.NewInt(205), tx.GasFeeCap())
+	assert.Equal(big.NewInt(2), tx.BlobGasFeeCap())
+	assert.Equal(sidecar.BlobHashes(), tx.BlobHashes())
+	assert.Equal(sidecar, mt.sidecar)
+
+	// An explicit blob fee cap overrides the default
+	tx, err = bc.Transact(&bind.TransactOpts{Signer: mockSign, Sidecar: sidecar, BlobFeeCap: big.NewInt(10)}, "")
+	assert.Nil(err)
+	assert.Equal(big.NewInt(10), tx.BlobGasFeeCap())
+
+	// Blobs cannot be sent through transactors without sidecar support
+	bc = bind.NewBoundContract(common.Address{0x1}, abi.ABI{}, nil, &mt.mockTransactor, nil)
+	_, err = bc.Transact(&bind.TransactOpts{Signer: mockSign, Sidecar: sidecar, BlobFeeCap: big.NewInt(10)}, "")
+	assert.ErrorIs(err, bind.ErrNoBlobTransport)
+}
+
 func unpackAndCheck(t *testing.T, bc *bind.BoundContract, expected map[string]interface{}, mockLog types.Log) {
 	received := make(map[string]interface{})
 	if err := bc.UnpackLogIntoMap(received, "received", mockLog); err != nil {
@@ -512,3 +602,49 @@ func TestCrashers(t *testing.T) {
 	abi.JSON(strings.NewReader(`[{"inputs":[{"type":"tuple[]","components":[{"type":"bool","name":"----"}]}]}]`))
 	abi.JSON(strings.NewReader(`[{"inputs":[{"type":"tuple[]","components":[{"type":"bool","name":"foo.Bar"}]}]}]`))
 }
+
+type dataError struct {
+	data interface{}
+}
+
+func (e *dataError) Error() string          { return "execution reverted" }
+func (e *dataError) ErrorData() interface{} { return e.data }
+
+// Tests that contract call reverts carrying custom error data are decoded into
+// the matching ABI errors.
+func TestCallRevertError(t *testing.T) {
+	const jsondata = `[{"type":"function","name":"transfer","inputs":[],"outputs":[]},{"type":"error","name":"Unauthorized","inputs":[{"name":"caller","type":"address"}]}]`
+	contract, err := abi.JSON(strings.NewReader(jsondata))
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller := common.HexToAddress("0x0102030405060708090a0b0c0d0e0f1011121314")
+	unauthorized := contract.Errors["Unauthorized"]
+	args, err := unauthorized.Inputs.Pack(caller)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revert := append(unauthorized.ID[:4:4], args...)
+
+	// Known custom errors are decoded
+	backendErr := &dataError{data: hexutil.Encode(revert)}
+	bc := bind.NewBoundContract(common.Address{}, contract, &mockCaller{callContractErr: backendErr}, nil, nil)
+
+	err = bc.Call(nil, nil, "transfer")
+	var revertErr *bind.RevertError
+	if !errors.As(err, &revertErr) {
+		t.Fatalf("expected revert error, got %v", err)
+	}
+	if revertErr.Name != "Unauthorized" || len(revertErr.Args) != 1 || revertErr.Args[0] != caller {
+		t.Fatalf("revert error mismatch: %v", revertErr)
+	}
+	if !errors.Is(err, backendErr) {
+		t.Fatalf("revert error does not wrap the backend error")
+	}
+	// Unknown revert data is passed through untouched
+	backendErr = &dataError{data: "0xdeadbeef"}
+	bc = bind.NewBoundContract(common.Address{}, contract, &mockCaller{callContractErr: backendErr}, nil, nil)
+	if err := bc.Call(nil, nil, "transfer"); err != backendErr {
+		t.Fatalf("unknown revert error mismatch: have %v, want %v", err, backendErr)
+	}
+}
@@ -37,7 +37,8 @@ import (
 type Lang int
 
 const (
-	LangGo Lang = iota
+	LangGo   Lang = iota // Go bindings with per-role wrappers and sessions
+	LangGoV2             // Generics based Go bindings with context first methods
 )
 
 func isKeyWord(arg string) bool {
@@ -291,7 +292,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 		return "", err
 	}
 	// For Go bindings pass the code through gofmt to clean it up
-	if lang == LangGo {
+	if lang == LangGo || lang == LangGoV2 {
 		code, err := format.Source(buffer.Bytes())
 		if err != nil {
 			return "", fmt.Errorf("%v\n%s", err, buffer)
@@ -305,7 +306,8 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 // bindType is a set of type binders that convert Solidity types to some supported
 // programming language types.
 var bindType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
-	LangGo: bindTypeGo,
+	LangGo:   bindTypeGo,
+	LangGoV2: bindTypeGo,
 }
 
 // bindBasicTypeGo converts basic solidity types(except array, slice and tuple) to Go ones.
@@ -351,7 +353,8 @@ func bindTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
 // bindTopicType is a set of type binders that convert Solidity types to some
 // supported programming language topic types.
 var bindTopicType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
-	LangGo: bindTopicTypeGo,
+	LangGo:   bindTopicTypeGo,
+	LangGoV2: bindTopicTypeGo,
 }
 
 // bindTopicTypeGo converts a Solidity topic type to a Go one. It is almost the same
@@ -374,7 +377,8 @@ func bindTopicTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
 // bindStructType is a set of type binders that convert Solidity tuple types to some supported
 // programming language struct definition.
 var bindStructType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
-	LangGo: bindStructTypeGo,
+	LangGo:   bindStructTypeGo,
+	LangGoV2: bindStructTypeGo,
 }
 
 // bindStructTypeGo converts a Solidity tuple type to a Go one and records the mapping
@@ -426,7 +430,8 @@ func bindStructTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
 // namedType is a set of functions that transform language specific types to
 // named versions that may be used inside method names.
 var namedType = map[Lang]func(string, abi.Type) string{
-	LangGo: func(string, abi.Type) string { panic("this shouldn't be needed") },
+	LangGo:   func(string, abi.Type) string { panic("this shouldn't be needed") },
+	LangGoV2: func(string, abi.Type) string { panic("this shouldn't be needed") },
 }
 
 // alias returns an alias of the given string based on the aliasing rules
@@ -441,7 +446,8 @@ func alias(aliases map[string]string, n string) string {
 // methodNormalizer is a name transformer that modifies Solidity method names to
 // conform to target language naming conventions.
 var methodNormalizer = map[Lang]func(string) string{
-	LangGo: abi.ToCamelCase,
+	LangGo:   abi.ToCamelCase,
+	LangGoV2: abi.ToCamelCase,
 }
 
 // capitalise makes a camel-case string which starts with an upper case character.
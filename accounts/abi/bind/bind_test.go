@@ -2064,6 +2064,108 @@ var bindTests = []struct {
 	},
 }
 
+// bindTestsV2 are the test cases for the generics based bindings. Each of them
+// reuses the compiled contract of a legacy test case, binding it under a new name.
+var bindTestsV2 = []struct {
+	name    string
+	source  string // Name of the bindTests case to take the ABI and bytecode from
+	imports string
+	tester  string
+}{
+	{
+		`GenericTupler`,
+		`Tupler`,
+		`
+			"context"
+			"math/big"
+
+			"github.com/gorievm/go-gori/accounts/abi/bind"
+			"github.com/gorievm/go-gori/accounts/abi/bind/backends"
+			"github.com/gorievm/go-gori/core"
+			"github.com/gorievm/go-gori/crypto"
+		`,
+		`
+			// Generate a new random account and a funded simulator
+			key, _ := crypto.GenerateKey()
+			auth, _ := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+
+			sim := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(10000000000000000)}}, 10000000)
+			defer sim.Close()
+
+			// Deploy a tuple tester contract and execute a typed call on it
+			ctx := context.Background()
+			_, _, tupler, err := DeployGenericTupler(ctx, auth, sim)
+			if err != nil {
+				t.Fatalf("Failed to deploy tupler contract: %v", err)
+			}
+			sim.Commit()
+
+			var res GenericTuplerTupleOutput
+			if res, err = tupler.Tuple(ctx, nil); err != nil {
+				t.Fatalf("Failed to call structure retriever: %v", err)
+			} else if res.A != "Hi" || res.B.Cmp(big.NewInt(1)) != 0 {
+				t.Fatalf("Retrieved value mismatch: have %v/%v, want %v/%v", res.A, res.B, "Hi", 1)
+			}
+		`,
+	},
+	{
+		`GenericEventer`,
+		`Eventer`,
+		`
+			"context"
+			"math/big"
+
+			"github.com/gorievm/go-gori/accounts/abi/bind"
+			"github.com/gorievm/go-gori/accounts/abi/bind/backends"
+			"github.com/gorievm/go-gori/common"
+			"github.com/gorievm/go-gori/core"
+			"github.com/gorievm/go-gori/crypto"
+		`,
+		`
+			// Generate a new random account and a funded simulator
+			key, _ := crypto.GenerateKey()
+			auth, _ := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+
+			sim := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(10000000000000000)}}, 10000000)
+			defer sim.Close()
+
+			// Deploy an eventer contract and raise a few events
+			ctx := context.Background()
+			_, _, eventer, err := DeployGenericEventer(ctx, auth, sim)
+			if err != nil {
+				t.Fatalf("Failed to deploy eventer contract: %v", err)
+			}
+			sim.Commit()
+
+			for i := 1; i <= 3; i++ {
+				if _, err := eventer.RaiseSimpleEvent(ctx, auth, common.Address{byte(i)}, [32]byte{byte(i)}, true, big.NewInt(int64(i))); err != nil {
+					t.Fatalf("event %d: raise failed: %v", i, err)
+				}
+			}
+			sim.Commit()
+
+			// Filter for a subset of the events and ensure the typed iterator works
+			it, err := eventer.FilterSimpleEvent(ctx, nil, []common.Address{{1}, {3}}, nil, nil)
+			if err != nil {
+				t.Fatalf("failed to filter for simple events: %v", err)
+			}
+			defer it.Close()
+
+			var values []uint64
+			for it.Next() {
+				var event *GenericEventerSimpleEvent = it.Event
+				values = append(values, event.Value.Uint64())
+			}
+			if err := it.Error(); err != nil {
+				t.Fatalf("simple event iteration failed: %v", err)
+			}
+			if len(values) != 2 || values[0] != 1 || values[1] != 3 {
+				t.Fatalf("simple event values mismatch: have %v, want [1 3]", values)
+			}
+		`,
+	},
+}
+
 // Tests that packages generated by the binder can be successfully compiled and
 // the requested tester run against it.
 func TestGolangBindings(t *testing.T) {
@@ -2114,6 +2216,42 @@ func TestGolangBindings(t *testing.T) {
 			}
 		})
 	}
+	// Generate the test suite for all the generics based bindings
+	for i, tt := range bindTestsV2 {
+		t.Run(tt.name, func(t *testing.T) {
+			var source = -1
+			for j := range bindTests {
+				if bindTests[j].name == tt.source {
+					source = j
+				}
+			}
+			if source < 0 {
+				t.Fatalf("test %d: unknown source contract %q", i, tt.source)
+			}
+			bind, err := Bind([]string{tt.name}, bindTests[source].abi, bindTests[source].bytecode, nil, "bindtest", LangGoV2, nil, nil)
+			if err != nil {
+				t.Fatalf("test %d: failed to generate binding: %v", i, err)
+			}
+			if err = os.WriteFile(filepath.Join(pkg, strings.ToLower(tt.name)+".go"), []byte(bind), 0600); err != nil {
+				t.Fatalf("test %d: failed to write binding: %v", i, err)
+			}
+			code := fmt.Sprintf(`
+			package bindtest
+
+			import (
+				"testing"
+				%s
+			)
+
+			func Test%s(t *testing.T) {
+				%s
+			}
+		`, tt.imports, tt.name, tt.tester)
+			if err := os.WriteFile(filepath.Join(pkg, strings.ToLower(tt.name)+"_test.go"), []byte(code), 0600); err != nil {
+				t.Fatalf("test %d: failed to write tests: %v", i, err)
+			}
+		})
+	}
 	// Convert the package to go modules and use the current source for go-ethereum
 	moder := exec.Command(gocmd, "mod", "init", "bindtest")
 	moder.Dir = pkg
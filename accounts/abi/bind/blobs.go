@@ -0,0 +1,95 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/crypto/kzg4844"
+	"github.com/gorievm/go-gori/params"
+)
+
+// ErrNoBlobTransport is returned by Transact if blobs are attached to a transaction,
+// but the transactor cannot submit them alongside it.
+var ErrNoBlobTransport = errors.New("backend cannot transmit blob sidecars")
+
+// maxBlobsPerTransaction is the maximum number of blobs a single transaction may
+// carry, limited by the blob gas available in a block.
+const maxBlobsPerTransaction = params.BlobTxMaxBlobGasPerBlock / params.BlobTxBlobGasPerBlob
+
+// BlobSidecar is the collection of blobs attached to an EIP-4844 transaction,
+// along with their KZG commitments and proofs. Only the versioned hashes of the
+// commitments are part of the transaction itself.
+type BlobSidecar struct {
+	Blobs       []kzg4844.Blob
+	Commitments []kzg4844.Commitment
+	Proofs      []kzg4844.Proof
+}
+
+// NewBlobSidecar creates a sidecar out of the given blobs, computing the KZG
+// commitment and proof of each.
+func NewBlobSidecar(blobs []kzg4844.Blob) (*BlobSidecar, error) {
+	sidecar := &BlobSidecar{
+		Blobs:       blobs,
+		Commitments: make([]kzg4844.Commitment, len(blobs)),
+		Proofs:      make([]kzg4844.Proof, len(blobs)),
+	}
+	for i, blob := range blobs {
+		commitment, err := kzg4844.BlobToCommitment(blob)
+		if err != nil {
+			return nil, fmt.Errorf("blob %d: failed to compute commitment: %v", i, err)
+		}
+		proof, err := kzg4844.ComputeBlobProof(blob, commitment)
+		if err != nil {
+			return nil, fmt.Errorf("blob %d: failed to compute proof: %v", i, err)
+		}
+		sidecar.Commitments[i], sidecar.Proofs[i] = commitment, proof
+	}
+	return sidecar, nil
+}
+
+// validate checks that the sidecar is well formed. The KZG proofs are not
+// verified, that is left to the node accepting the transaction.
+func (sc *BlobSidecar) validate() error {
+	switch {
+	case len(sc.Blobs) == 0:
+		return errors.New("blob sidecar without blobs")
+	case len(sc.Blobs) > maxBlobsPerTransaction:
+		return fmt.Errorf("too many blobs: have %d, max %d", len(sc.Blobs), maxBlobsPerTransaction)
+	case len(sc.Commitments) != len(sc.Blobs):
+		return fmt.Errorf("invalid number of %d blob commitments compared to %d blobs", len(sc.Commitments), len(sc.Blobs))
+	case len(sc.Proofs) != len(sc.Blobs):
+		return fmt.Errorf("invalid number of %d blob proofs compared to %d blobs", len(sc.Proofs), len(sc.Blobs))
+	}
+	return nil
+}
+
+// BlobHashes returns the versioned hashes of the blob commitments, which are to
+// be included in the transaction carrying the sidecar.
+func (sc *BlobSidecar) BlobHashes() []common.Hash {
+	hashes := make([]common.Hash, len(sc.Commitments))
+	for i, commitment := range sc.Commitments {
+		hash := sha256.Sum256(commitment[:])
+
+		hashes[i][0] = params.BlobTxHashVersion
+		copy(hashes[i][1:], hash[1:])
+	}
+	return hashes
+}
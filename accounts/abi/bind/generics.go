@@ -0,0 +1,185 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"context"
+
+	"github.com/gorievm/go-gori"
+	"github.com/gorievm/go-gori/accounts/abi"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/event"
+)
+
+// This file contains the runtime support for the generics based bindings (the
+// LangGoV2 output of the code generator). The generated code is context first
+// and fully typed, delegating the untyped plumbing to the helpers below.
+
+// Convert converts a single value unpacked by the abi package into the type
+// requested by the caller.
+func Convert[T any](v interface{}) T {
+	return *abi.ConvertType(v, new(T)).(*T)
+}
+
+// CallOptsWithContext returns a copy of the call options with the context
+// replaced by the given one. Nil options are replaced by the defaults.
+func CallOptsWithContext(ctx context.Context, opts *CallOpts) *CallOpts {
+	var cpy CallOpts
+	if opts != nil {
+		cpy = *opts
+	}
+	cpy.Context = ctx
+	return &cpy
+}
+
+// TransactOptsWithContext returns a copy of the transaction options with the
+// context replaced by the given one.
+func TransactOptsWithContext(ctx context.Context, opts *TransactOpts) *TransactOpts {
+	cpy := *opts
+	cpy.Context = ctx
+	return &cpy
+}
+
+// FilterOptsWithContext returns a copy of the filter options with the context
+// replaced by the given one. Nil options are replaced by the defaults.
+func FilterOptsWithContext(ctx context.Context, opts *FilterOpts) *FilterOpts {
+	var cpy FilterOpts
+	if opts != nil {
+		cpy = *opts
+	}
+	cpy.Context = ctx
+	return &cpy
+}
+
+// WatchOptsWithContext returns a copy of the watch options with the context
+// replaced by the given one. Nil options are replaced by the defaults.
+func WatchOptsWithContext(ctx context.Context, opts *WatchOpts) *WatchOpts {
+	var cpy WatchOpts
+	if opts != nil {
+		cpy = *opts
+	}
+	cpy.Context = ctx
+	return &cpy
+}
+
+// EventIterator is returned from FilterEvents and is used to iterate over the
+// raw logs and unpacked data of a contract event of type T.
+type EventIterator[T any] struct {
+	Event *T // Event containing the contract specifics and raw log
+
+	parse func(types.Log) (*T, error) // Typed parser to unpack event data with
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *EventIterator[T]) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			return it.unpack(log)
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		return it.unpack(log)
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// unpack parses a log into the current event, returning whether it succeeded.
+func (it *EventIterator[T]) unpack(log types.Log) bool {
+	event, err := it.parse(log)
+	if err != nil {
+		it.fail = err
+		return false
+	}
+	it.Event = event
+	return true
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *EventIterator[T]) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *EventIterator[T]) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FilterEvents retrieves the past logs of the named contract event matching the
+// given topic queries, returning a typed iterator over them.
+func FilterEvents[T any](ctx context.Context, c *BoundContract, opts *FilterOpts, name string, parse func(types.Log) (*T, error), query ...[]interface{}) (*EventIterator[T], error) {
+	logs, sub, err := c.FilterLogs(FilterOptsWithContext(ctx, opts), name, query...)
+	if err != nil {
+		return nil, err
+	}
+	return &EventIterator[T]{parse: parse, logs: logs, sub: sub}, nil
+}
+
+// WatchEvents subscribes to future logs of the named contract event matching the
+// given topic queries, delivering the typed events into the sink.
+func WatchEvents[T any](ctx context.Context, c *BoundContract, opts *WatchOpts, name string, parse func(types.Log) (*T, error), sink chan<- *T, query ...[]interface{}) (event.Subscription, error) {
+	logs, sub, err := c.WatchLogs(WatchOptsWithContext(ctx, opts), name, query...)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event, err := parse(log)
+				if err != nil {
+					return err
+				}
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"errors"
+
+	"github.com/gorievm/go-gori"
+	"github.com/gorievm/go-gori/accounts/abi"
+	"github.com/gorievm/go-gori/common"
+)
+
+// ErrCallReverted is returned for an aggregated call that reverted without any
+// revert data known to the called contract's ABI.
+var ErrCallReverted = errors.New("aggregated call reverted")
+
+// Multicall3Address is the address the Multicall3 aggregator contract is deployed
+// at on most chains, using a keyless deployment transaction.
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// Multicall3MetaData contains the subset of the Multicall3 ABI used to aggregate
+// read-only calls.
+var Multicall3MetaData = &MetaData{
+	ABI: `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`,
+}
+
+// multicall3Call is the Go representation of the Multicall3.Call3 struct.
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicall3Result is the Go representation of the Multicall3.Result struct.
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// multicallCall is a single contract call queued in a Multicall.
+type multicallCall struct {
+	contract *BoundContract
+	method   string
+	results  *[]interface{}
+	input    []byte
+}
+
+// Multicall batches read-only calls against one or more contracts, executing
+// them in a single eth_call through a Multicall3 aggregator contract.
+type Multicall struct {
+	caller  ContractCaller
+	address common.Address
+	calls   []*multicallCall
+}
+
+// NewMulticall creates a batch of calls to be aggregated by the Multicall3
+// contract deployed at the given address, usually Multicall3Address.
+func NewMulticall(caller ContractCaller, address common.Address) *Multicall {
+	return &Multicall{
+		caller:  caller,
+		address: address,
+	}
+}
+
+// Add queues a call of the given contract method. The results are unpacked into
+// results the same way as BoundContract.Call does once the batch is executed.
+// The parameters are packed immediately, so encoding errors are returned here.
+func (m *Multicall) Add(c *BoundContract, results *[]interface{}, method string, params ...interface{}) error {
+	input, err := c.abi.Pack(method, params...)
+	if err != nil {
+		return err
+	}
+	if results == nil {
+		results = new([]interface{})
+	}
+	m.calls = append(m.calls, &multicallCall{
+		contract: c,
+		method:   method,
+		results:  results,
+		input:    input,
+	})
+	return nil
+}
+
+// Len returns the number of calls queued in the batch.
+func (m *Multicall) Len() int {
+	return len(m.calls)
+}
+
+// Call executes all queued calls in a single eth_call and unpacks their results.
+// The returned slice holds the outcome of each call in the order they were added,
+// nil if it succeeded. A non-nil error means the batch as a whole failed. The
+// queue is reset either way.
+func (m *Multicall) Call(opts *CallOpts) ([]error, error) {
+	calls := m.calls
+	m.calls = nil
+
+	if len(calls) == 0 {
+		return nil, nil
+	}
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(CallOpts)
+	}
+	aggregator, err := Multicall3MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	args := make([]multicall3Call, len(calls))
+	for i, call := range calls {
+		args[i] = multicall3Call{Target: call.contract.address, AllowFailure: true, CallData: call.input}
+	}
+	input, err := aggregator.Pack("aggregate3", args)
+	if err != nil {
+		return nil, err
+	}
+	// Execute the aggregated call against the requested state
+	var (
+		msg    = ethereum.CallMsg{From: opts.From, To: &m.address, Data: input}
+		ctx    = ensureContext(opts.Context)
+		code   []byte
+		output []byte
+	)
+	if opts.Pending {
+		pb, ok := m.caller.(PendingContractCaller)
+		if !ok {
+			return nil, ErrNoPendingState
+		}
+		if output, err = pb.PendingCallContract(ctx, msg); err != nil {
+			return nil, err
+		}
+		if len(output) == 0 {
+			// Make sure we have an aggregator to operate on, and bail out otherwise.
+			if code, err = pb.PendingCodeAt(ctx, m.address); err != nil {
+				return nil, err
+			} else if len(code) == 0 {
+				return nil, ErrNoCode
+			}
+		}
+	} else {
+		if output, err = m.caller.CallContract(ctx, msg, opts.BlockNumber); err != nil {
+			return nil, err
+		}
+		if len(output) == 0 {
+			// Make sure we have an aggregator to operate on, and bail out otherwise.
+			if code, err = m.caller.CodeAt(ctx, m.address, opts.BlockNumber); err != nil {
+				return nil, err
+			} else if len(code) == 0 {
+				return nil, ErrNoCode
+			}
+		}
+	}
+	// Split the aggregated output and unpack the individual results
+	out, err := aggregator.Unpack("aggregate3", output)
+	if err != nil {
+		return nil, err
+	}
+	results := *abi.ConvertType(out[0], new([]multicall3Result)).(*[]multicall3Result)
+	if len(results) != len(calls) {
+		return nil, errors.New("aggregated result count mismatch")
+	}
+	errs := make([]error, len(calls))
+	for i, call := range calls {
+		if !results[i].Success {
+			errs[i] = call.contract.unpackRevert(results[i].ReturnData)
+			continue
+		}
+		errs[i] = call.contract.unpack(call.results, call.method, results[i].ReturnData)
+	}
+	return errs, nil
+}
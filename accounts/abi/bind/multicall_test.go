@@ -0,0 +1,161 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/gorievm/go-gori"
+	"github.com/gorievm/go-gori/accounts/abi"
+	"github.com/gorievm/go-gori/accounts/abi/bind"
+	"github.com/gorievm/go-gori/common"
+)
+
+const multicallTestABI = `[
+	{"type":"function","name":"balance","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"error","name":"Unknown","inputs":[{"name":"owner","type":"address"}]}
+]`
+
+type aggregatedCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type aggregatedResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// mockAggregator is a ContractCaller emulating the Multicall3 contract, serving
+// the balance method of the test contracts from a fixed set of balances.
+type mockAggregator struct {
+	t        *testing.T
+	abi      abi.ABI
+	balances map[common.Address]map[common.Address]*big.Int
+	calls    int
+}
+
+func (ma *mockAggregator) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x1}, nil
+}
+
+func (ma *mockAggregator) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	ma.calls++
+
+	aggregator, _ := bind.Multicall3MetaData.GetAbi()
+	if *call.To != bind.Multicall3Address {
+		ma.t.Fatalf("aggregator address mismatch: have %x, want %x", *call.To, bind.Multicall3Address)
+	}
+	in, err := aggregator.Methods["aggregate3"].Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		ma.t.Fatalf("failed to unpack aggregated calls: %v", err)
+	}
+	calls := *abi.ConvertType(in[0], new([]aggregatedCall)).(*[]aggregatedCall)
+
+	results := make([]aggregatedResult, len(calls))
+	for i, call := range calls {
+		args, err := ma.abi.Methods["balance"].Inputs.Unpack(call.CallData[4:])
+		if err != nil {
+			ma.t.Fatalf("failed to unpack call %d: %v", i, err)
+		}
+		owner := args[0].(common.Address)
+		if balance, ok := ma.balances[call.Target][owner]; ok {
+			data, _ := ma.abi.Methods["balance"].Outputs.Pack(balance)
+			results[i] = aggregatedResult{Success: true, ReturnData: data}
+		} else {
+			data, _ := ma.abi.Errors["Unknown"].Inputs.Pack(owner)
+			id := ma.abi.Errors["Unknown"].ID
+			results[i] = aggregatedResult{ReturnData: append(id[:4:4], data...)}
+		}
+	}
+	return aggregator.Methods["aggregate3"].Outputs.Pack(results)
+}
+
+// Tests that calls against multiple contracts are aggregated into a single
+// backend call, with results and failures routed back to the individual calls.
+func TestMulticall(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := abi.JSON(strings.NewReader(multicallTestABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		tokenA = common.Address{0xa}
+		tokenB = common.Address{0xb}
+		alice  = common.Address{0x1}
+		bob    = common.Address{0x2}
+	)
+	backend := &mockAggregator{
+		t:   t,
+		abi: parsed,
+		balances: map[common.Address]map[common.Address]*big.Int{
+			tokenA: {alice: big.NewInt(1), bob: big.NewInt(2)},
+			tokenB: {alice: big.NewInt(3)},
+		},
+	}
+	var (
+		contractA = bind.NewBoundContract(tokenA, parsed, backend, nil, nil)
+		contractB = bind.NewBoundContract(tokenB, parsed, backend, nil, nil)
+		batch     = bind.NewMulticall(backend, bind.Multicall3Address)
+		results   = make([][]interface{}, 4)
+	)
+	for i, call := range []struct {
+		contract *bind.BoundContract
+		owner    common.Address
+	}{{contractA, alice}, {contractA, bob}, {contractB, alice}, {contractB, bob}} {
+		if err := batch.Add(call.contract, &results[i], "balance", call.owner); err != nil {
+			t.Fatalf("call %d: failed to queue: %v", i, err)
+		}
+	}
+	if batch.Len() != 4 {
+		t.Fatalf("queued call count mismatch: have %d, want %d", batch.Len(), 4)
+	}
+	errs, err := batch.Call(nil)
+	if err != nil {
+		t.Fatalf("failed to execute batch: %v", err)
+	}
+	if backend.calls != 1 {
+		t.Fatalf("backend call count mismatch: have %d, want %d", backend.calls, 1)
+	}
+	for i, want := range []int64{1, 2, 3} {
+		if errs[i] != nil {
+			t.Fatalf("call %d: unexpected failure: %v", i, errs[i])
+		}
+		if have := results[i][0].(*big.Int); have.Int64() != want {
+			t.Fatalf("call %d: result mismatch: have %v, want %d", i, have, want)
+		}
+	}
+	var revert *bind.RevertError
+	if !errors.As(errs[3], &revert) {
+		t.Fatalf("failed call error type mismatch: have %T, want *bind.RevertError", errs[3])
+	}
+	if revert.Name != "Unknown" || revert.Args[0] != bob {
+		t.Fatalf("failed call error mismatch: have %v", revert)
+	}
+	if !errors.Is(errs[3], bind.ErrCallReverted) {
+		t.Fatalf("failed call error does not wrap %v", bind.ErrCallReverted)
+	}
+	if batch.Len() != 0 {
+		t.Fatalf("batch not reset after execution")
+	}
+}
@@ -75,7 +75,8 @@ type tmplStruct struct {
 // tmplSource is language to template mapping containing all the supported
 // programming languages the package can generate to.
 var tmplSource = map[Lang]string{
-	LangGo: tmplSourceGo,
+	LangGo:   tmplSourceGo,
+	LangGoV2: tmplSourceGoV2,
 }
 
 // tmplSourceGo is the Go source template that the generated Go contract binding
@@ -569,3 +570,208 @@ var (
  	{{end}}
 {{end}}
 `
+
+// tmplSourceGoV2 is the Go source template that the generics based Go contract
+// binding is based on. Compared to tmplSourceGo, all operations take a context
+// as their first parameter, call results are returned fully typed and events
+// are iterated with the generic bind.EventIterator.
+const tmplSourceGoV2 = `
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package {{.Package}}
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/gorievm/go-gori/accounts/abi"
+	"github.com/gorievm/go-gori/accounts/abi/bind"
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = context.Background
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = abi.ConvertType
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+{{$structs := .Structs}}
+{{range $structs}}
+	// {{.Name}} is an auto generated low-level Go binding around an user-defined struct.
+	type {{.Name}} struct {
+	{{range $field := .Fields}}
+	{{$field.Name}} {{$field.Type}}{{end}}
+	}
+{{end}}
+
+{{range $contract := .Contracts}}
+	// {{.Type}}MetaData contains all meta data concerning the {{.Type}} contract.
+	var {{.Type}}MetaData = &bind.MetaData{
+		ABI: "{{.InputABI}}",
+		{{if $contract.FuncSigs -}}
+		Sigs: map[string]string{
+			{{range $strsig, $binsig := .FuncSigs}}"{{$binsig}}": "{{$strsig}}",
+			{{end}}
+		},
+		{{end -}}
+		{{if .InputBin -}}
+		Bin: "0x{{.InputBin}}",
+		{{end}}
+	}
+
+	// {{.Type}} is an auto generated, generics based Go binding around an Ori contract.
+	type {{.Type}} struct {
+		contract *bind.BoundContract // Generic contract wrapper for the low level calls
+	}
+
+	// New{{.Type}} creates a new instance of {{.Type}}, bound to a specific deployed contract.
+	func New{{.Type}}(address common.Address, backend bind.ContractBackend) (*{{.Type}}, error) {
+		parsed, err := {{.Type}}MetaData.GetAbi()
+		if err != nil {
+			return nil, err
+		}
+		return &{{.Type}}{contract: bind.NewBoundContract(address, *parsed, backend, backend, backend)}, nil
+	}
+
+	{{if .InputBin}}
+		// Deploy{{.Type}} deploys a new Ori contract, binding an instance of {{.Type}} to it.
+		func Deploy{{.Type}}(ctx context.Context, auth *bind.TransactOpts, backend bind.ContractBackend {{range .Constructor.Inputs}}, {{.Name}} {{bindtype .Type $structs}}{{end}}) (common.Address, *types.Transaction, *{{.Type}}, error) {
+			parsed, err := {{.Type}}MetaData.GetAbi()
+			if err != nil {
+				return common.Address{}, nil, nil, err
+			}
+			if parsed == nil {
+				return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+			}
+			bin := {{.Type}}MetaData.Bin
+			{{range $pattern, $name := .Libraries}}
+				{{decapitalise $name}}Addr, _, _, err := Deploy{{capitalise $name}}(ctx, auth, backend)
+				if err != nil {
+					return common.Address{}, nil, nil, err
+				}
+				bin = strings.ReplaceAll(bin, "__${{$pattern}}$__", {{decapitalise $name}}Addr.String()[2:])
+			{{end}}
+			address, tx, contract, err := bind.DeployContract(bind.TransactOptsWithContext(ctx, auth), *parsed, common.FromHex(bin), backend {{range .Constructor.Inputs}}, {{.Name}}{{end}})
+			if err != nil {
+				return common.Address{}, nil, nil, err
+			}
+			return address, tx, &{{.Type}}{contract: contract}, nil
+		}
+	{{end}}
+
+	{{range .Calls}}
+		{{if .Structured}}
+			// {{$contract.Type}}{{.Normalized.Name}}Output is the typed result of the {{.Normalized.Name}} call.
+			type {{$contract.Type}}{{.Normalized.Name}}Output struct { {{range .Normalized.Outputs}}
+				{{.Name}} {{bindtype .Type $structs}}{{end}}
+			}
+		{{end}}
+
+		// {{.Normalized.Name}} is a free data retrieval call binding the contract method 0x{{printf "%x" .Original.ID}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) {{.Normalized.Name}}(ctx context.Context, opts *bind.CallOpts {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type $structs}} {{end}}) ({{if .Structured}}{{$contract.Type}}{{.Normalized.Name}}Output,{{else}}{{range .Normalized.Outputs}}{{bindtype .Type $structs}},{{end}}{{end}} error) {
+			var out []interface{}
+			err := _{{$contract.Type}}.contract.Call(bind.CallOptsWithContext(ctx, opts), &out, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+			{{if .Structured}}
+			if err != nil {
+				return {{$contract.Type}}{{.Normalized.Name}}Output{}, err
+			}
+			return {{$contract.Type}}{{.Normalized.Name}}Output{ {{range $i, $t := .Normalized.Outputs}}
+				{{.Name}}: bind.Convert[{{bindtype .Type $structs}}](out[{{$i}}]),{{end}}
+			}, nil
+			{{else}}
+			if err != nil {
+				return {{range $i, $_ := .Normalized.Outputs}}*new({{bindtype .Type $structs}}), {{end}} err
+			}
+			return {{range $i, $t := .Normalized.Outputs}}bind.Convert[{{bindtype .Type $structs}}](out[{{$i}}]), {{end}} nil
+			{{end}}
+		}
+	{{end}}
+
+	{{range .Transacts}}
+		// {{.Normalized.Name}} is a paid mutator transaction binding the contract method 0x{{printf "%x" .Original.ID}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) {{.Normalized.Name}}(ctx context.Context, opts *bind.TransactOpts {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type $structs}} {{end}}) (*types.Transaction, error) {
+			return _{{$contract.Type}}.contract.Transact(bind.TransactOptsWithContext(ctx, opts), "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+		}
+	{{end}}
+
+	{{if .Fallback}}
+		// Fallback is a paid mutator transaction binding the contract fallback function.
+		//
+		// Solidity: {{.Fallback.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Fallback(ctx context.Context, opts *bind.TransactOpts, calldata []byte) (*types.Transaction, error) {
+			return _{{$contract.Type}}.contract.RawTransact(bind.TransactOptsWithContext(ctx, opts), calldata)
+		}
+	{{end}}
+
+	{{if .Receive}}
+		// Receive is a paid mutator transaction binding the contract receive function.
+		//
+		// Solidity: {{.Receive.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Receive(ctx context.Context, opts *bind.TransactOpts) (*types.Transaction, error) {
+			return _{{$contract.Type}}.contract.RawTransact(bind.TransactOptsWithContext(ctx, opts), nil) // calldata is disallowed for receive function
+		}
+	{{end}}
+
+	{{range .Events}}
+		// {{$contract.Type}}{{.Normalized.Name}} represents a {{.Normalized.Name}} event raised by the {{$contract.Type}} contract.
+		type {{$contract.Type}}{{.Normalized.Name}} struct { {{range .Normalized.Inputs}}
+			{{capitalise .Name}} {{if .Indexed}}{{bindtopictype .Type $structs}}{{else}}{{bindtype .Type $structs}}{{end}}; {{end}}
+			Raw types.Log // Blockchain specific contextual infos
+		}
+
+		// Filter{{.Normalized.Name}} is a free log retrieval operation binding the contract event 0x{{printf "%x" .Original.ID}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Filter{{.Normalized.Name}}(ctx context.Context, opts *bind.FilterOpts{{range .Normalized.Inputs}}{{if .Indexed}}, {{.Name}} []{{bindtype .Type $structs}}{{end}}{{end}}) (*bind.EventIterator[{{$contract.Type}}{{.Normalized.Name}}], error) {
+			{{range .Normalized.Inputs}}
+			{{if .Indexed}}var {{.Name}}Rule []interface{}
+			for _, {{.Name}}Item := range {{.Name}} {
+				{{.Name}}Rule = append({{.Name}}Rule, {{.Name}}Item)
+			}{{end}}{{end}}
+
+			return bind.FilterEvents(ctx, _{{$contract.Type}}.contract, opts, "{{.Original.Name}}", _{{$contract.Type}}.Parse{{.Normalized.Name}}{{range .Normalized.Inputs}}{{if .Indexed}}, {{.Name}}Rule{{end}}{{end}})
+		}
+
+		// Watch{{.Normalized.Name}} is a free log subscription operation binding the contract event 0x{{printf "%x" .Original.ID}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Watch{{.Normalized.Name}}(ctx context.Context, opts *bind.WatchOpts, sink chan<- *{{$contract.Type}}{{.Normalized.Name}}{{range .Normalized.Inputs}}{{if .Indexed}}, {{.Name}} []{{bindtype .Type $structs}}{{end}}{{end}}) (event.Subscription, error) {
+			{{range .Normalized.Inputs}}
+			{{if .Indexed}}var {{.Name}}Rule []interface{}
+			for _, {{.Name}}Item := range {{.Name}} {
+				{{.Name}}Rule = append({{.Name}}Rule, {{.Name}}Item)
+			}{{end}}{{end}}
+
+			return bind.WatchEvents(ctx, _{{$contract.Type}}.contract, opts, "{{.Original.Name}}", _{{$contract.Type}}.Parse{{.Normalized.Name}}, sink{{range .Normalized.Inputs}}{{if .Indexed}}, {{.Name}}Rule{{end}}{{end}})
+		}
+
+		// Parse{{.Normalized.Name}} is a log parse operation binding the contract event 0x{{printf "%x" .Original.ID}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Parse{{.Normalized.Name}}(log types.Log) (*{{$contract.Type}}{{.Normalized.Name}}, error) {
+			event := new({{$contract.Type}}{{.Normalized.Name}})
+			if err := _{{$contract.Type}}.contract.UnpackLog(event, "{{.Original.Name}}", log); err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return event, nil
+		}
+	{{end}}
+{{end}}
+`
@@ -91,3 +91,90 @@ func (e *Error) Unpack(data []byte) (interface{}, error) {
 	}
 	return e.Inputs.Unpack(data[4:])
 }
+
+// ErrUnknownError is returned when revert data doesn't match any error known to
+// an error registry.
+var ErrUnknownError = errors.New("unknown error selector")
+
+var (
+	// builtinError is the error Solidity uses for revert(string) and require
+	// statements with a reason.
+	builtinError = newBuiltinError("Error", "reason", "string")
+
+	// builtinPanic is the error Solidity uses for failed assertions, arithmetic
+	// overflows and similar internal failures.
+	builtinPanic = newBuiltinError("Panic", "code", "uint256")
+)
+
+// newBuiltinError creates a single argument error definition for one of the
+// errors implicitly defined by Solidity.
+func newBuiltinError(name string, arg string, kind string) Error {
+	typ, err := NewType(kind, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return NewError(name, Arguments{{Name: arg, Type: typ}})
+}
+
+// Errors is a registry of Solidity errors indexed by their 4-byte selectors.
+// It can be used to match the return data of a reverted call against the custom
+// errors of one or more contracts, along with the builtin Error(string) and
+// Panic(uint256) errors.
+type Errors struct {
+	byID map[[4]byte]Error
+}
+
+// NewErrors creates an error registry containing the builtin errors and all the
+// custom errors defined in the given contract ABIs.
+func NewErrors(abis ...*ABI) *Errors {
+	errs := &Errors{byID: make(map[[4]byte]Error)}
+	errs.Register(builtinError, builtinPanic)
+	for _, abi := range abis {
+		for _, e := range abi.Errors {
+			errs.Register(e)
+		}
+	}
+	return errs
+}
+
+// Register adds the given errors to the registry, overwriting any previously
+// registered error with the same selector.
+func (errs *Errors) Register(errors ...Error) {
+	for _, e := range errors {
+		var id [4]byte
+		copy(id[:], e.ID[:4])
+		errs.byID[id] = e
+	}
+}
+
+// Lookup retrieves the error matching the selector of the given revert data.
+func (errs *Errors) Lookup(data []byte) (*Error, bool) {
+	if len(data) < 4 {
+		return nil, false
+	}
+	var id [4]byte
+	copy(id[:], data[:4])
+
+	e, ok := errs.byID[id]
+	if !ok {
+		return nil, false
+	}
+	return &e, true
+}
+
+// UnpackError matches the given revert data against the registered errors and
+// returns the name of the matching error along with its decoded arguments.
+func (errs *Errors) UnpackError(data []byte) (string, []interface{}, error) {
+	e, ok := errs.Lookup(data)
+	if !ok {
+		if len(data) < 4 {
+			return "", nil, errors.New("invalid data for unpacking")
+		}
+		return "", nil, fmt.Errorf("%w: %#x", ErrUnknownError, data[:4])
+	}
+	args, err := e.Inputs.Unpack(data[4:])
+	if err != nil {
+		return "", nil, err
+	}
+	return e.Name, args, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package abi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// errNoABI is returned by JSONStream if a contract artifact has no abi field.
+var errNoABI = errors.New("abi: no abi field in artifact")
+
+// JSONStream parses a JSON ABI definition from the reader without buffering it
+// in full. Entries are decoded and registered one by one, so the memory used is
+// bounded by the largest entry instead of the whole definition.
+//
+// Besides a plain ABI array, the reader may also contain a contract artifact
+// (e.g. a solc, Hardhat or Foundry output), which is a JSON object holding the
+// ABI in its "abi" field. All other fields of the artifact are skipped.
+func JSONStream(reader io.Reader) (ABI, error) {
+	dec := json.NewDecoder(reader)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return ABI{}, err
+	}
+	switch tok {
+	case json.Delim('['):
+	case json.Delim('{'):
+		if err := seekABI(dec); err != nil {
+			return ABI{}, err
+		}
+	default:
+		return ABI{}, fmt.Errorf("abi: unexpected token %v, want array or object", tok)
+	}
+	var abi ABI
+	abi.init()
+	for dec.More() {
+		var field field
+		if err := dec.Decode(&field); err != nil {
+			return ABI{}, err
+		}
+		if err := abi.add(field); err != nil {
+			return ABI{}, err
+		}
+	}
+	if _, err := dec.Token(); err != nil { // closing ]
+		return ABI{}, err
+	}
+	return abi, nil
+}
+
+// seekABI advances the decoder positioned within an artifact object past the
+// opening bracket of its abi field, skipping all preceding fields.
+func seekABI(dec *json.Decoder) error {
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key != "abi" {
+			if err := skipValue(dec); err != nil {
+				return err
+			}
+			continue
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok != json.Delim('[') {
+			return fmt.Errorf("abi: unexpected token %v in abi field, want array", tok)
+		}
+		return nil
+	}
+	return errNoABI
+}
+
+// skipValue consumes the next JSON value from the decoder, tracking nesting
+// instead of decoding it into memory.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('['), json.Delim('{'):
+			depth++
+		case json.Delim(']'), json.Delim('}'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package abi
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests that the streaming parser produces the same ABI as the buffered one.
+func TestJSONStream(t *testing.T) {
+	t.Parallel()
+
+	want, err := JSON(strings.NewReader(jsondata))
+	if err != nil {
+		t.Fatal(err)
+	}
+	have, err := JSONStream(strings.NewReader(jsondata))
+	if err != nil {
+		t.Fatalf("failed to stream abi: %v", err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("streamed abi mismatch:\nhave %+v\nwant %+v", have, want)
+	}
+	// Embedded into an artifact, surrounded by fields to skip
+	artifact := `{"contractName":"Test","metadata":{"nested":[1,{"abi":[]}]},"abi":` + jsondata + `,"bytecode":"0x00"}`
+	if have, err = JSONStream(strings.NewReader(artifact)); err != nil {
+		t.Fatalf("failed to stream artifact: %v", err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("streamed artifact abi mismatch:\nhave %+v\nwant %+v", have, want)
+	}
+}
+
+func TestJSONStreamErrors(t *testing.T) {
+	t.Parallel()
+
+	for i, input := range []string{
+		``,
+		`"abi"`,
+		`{"bytecode":"0x00"}`,
+		`{"abi":{}}`,
+		`[{"type":"function","name":"a"}`,
+		`[{"type":"unknown","name":"a"}]`,
+		`[{"type":"receive","stateMutability":"nonpayable"}]`,
+	} {
+		if _, err := JSONStream(strings.NewReader(input)); err == nil {
+			t.Errorf("test %d: expected error for %q", i, input)
+		}
+	}
+}
+
+// largeABI generates an ABI definition with the given number of methods and
+// events, each taking a handful of arguments.
+func largeABI(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprintf(&buf, `{"type":"function","name":"method%d","stateMutability":"view","inputs":[{"name":"a","type":"address"},{"name":"b","type":"uint256"},{"name":"c","type":"bytes32[]"}],"outputs":[{"name":"","type":"uint256"}]},`, i)
+		fmt.Fprintf(&buf, `{"type":"event","name":"Event%d","anonymous":false,"inputs":[{"indexed":true,"name":"a","type":"address"},{"indexed":false,"name":"b","type":"uint256"}]}`, i)
+	}
+	buf.WriteString("]")
+	return buf.Bytes()
+}
+
+func BenchmarkJSON(b *testing.B) {
+	blob := largeABI(2000)
+	b.SetBytes(int64(len(blob)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := JSON(bytes.NewReader(blob)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkJSONStream(b *testing.B) {
+	blob := largeABI(2000)
+	b.SetBytes(int64(len(blob)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := JSONStream(bytes.NewReader(blob)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -20,6 +20,7 @@ package accounts
 import (
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/gorievm/go-gori"
 	"github.com/gorievm/go-gori/common"
@@ -40,6 +41,7 @@ const (
 	MimetypeTypedData         = "data/typed"
 	MimetypeClique            = "application/x-clique-header"
 	MimetypeTextPlain         = "text/plain"
+	MimetypeAuthorization     = "application/x-authorization"
 )
 
 // Wallet represents a software or hardware wallet that might contain one or more
@@ -224,3 +226,14 @@ type WalletEvent struct {
 	Wallet Wallet          // Wallet instance arrived or departed
 	Kind   WalletEventType // Event type that happened in the system
 }
+
+// WalletStatus is a snapshot of the health of a wallet, as last observed by the
+// account manager. It is emitted whenever the reported state of a wallet changes.
+type WalletStatus struct {
+	URL       URL       // Wallet the status belongs to
+	Status    string    // Textual status reported by the wallet (e.g. locked, unlocked, device state)
+	Failure   error     // Failure currently reported by the wallet, nil if healthy
+	LastError error     // Most recent non-nil failure observed for the wallet
+	Dropped   bool      // Whether the wallet departed and will not be tracked further
+	Updated   time.Time // Time when the status was observed
+}
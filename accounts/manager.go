@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/event"
@@ -29,6 +30,10 @@ import (
 // the manager will buffer in its channel.
 const managerSubBufferSize = 50
 
+// walletStatusRefresh is the interval at which the manager polls the wallets for
+// status changes to notify status subscribers of.
+const walletStatusRefresh = 3 * time.Second
+
 // Config contains the settings of the global account manager.
 //
 // TODO(rjl493456442, karalabe, holiman): Get rid of this when account management
@@ -56,6 +61,10 @@ type Manager struct {
 
 	feed event.Feed // Wallet feed notifying of arrivals/departures
 
+	statusFeed event.Feed           // Wallet feed notifying of health status changes
+	statuses   map[URL]WalletStatus // Last observed status of each tracked wallet
+	statusLock sync.RWMutex         // Lock protecting the status cache
+
 	quit chan chan error
 	term chan struct{} // Channel is closed upon termination of the update loop
 	lock sync.RWMutex
@@ -84,6 +93,7 @@ func NewManager(config *Config, backends ...Backend) *Manager {
 		updates:     updates,
 		newBackends: make(chan newBackendEvent),
 		wallets:     wallets,
+		statuses:    make(map[URL]WalletStatus),
 		quit:        make(chan chan error),
 		term:        make(chan struct{}),
 	}
@@ -92,6 +102,7 @@ func NewManager(config *Config, backends ...Backend) *Manager {
 		am.backends[kind] = append(am.backends[kind], backend)
 	}
 	go am.update()
+	go am.statusLoop()
 
 	return am
 }
@@ -241,6 +252,115 @@ func (am *Manager) Subscribe(sink chan<- WalletEvent) event.Subscription {
 	return am.feed.Subscribe(sink)
 }
 
+// SubscribeStatus creates an async subscription to receive notifications when the
+// health status of any tracked wallet changes (e.g. locked/unlocked, device busy
+// or disconnected). Dropped wallets are reported once with the Dropped flag set.
+func (am *Manager) SubscribeStatus(sink chan<- WalletStatus) event.Subscription {
+	return am.statusFeed.Subscribe(sink)
+}
+
+// Statuses returns the last observed health status of all tracked wallets, sorted
+// by wallet URL.
+func (am *Manager) Statuses() []WalletStatus {
+	am.statusLock.RLock()
+	defer am.statusLock.RUnlock()
+
+	statuses := make([]WalletStatus, 0, len(am.statuses))
+	for _, status := range am.statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].URL.Cmp(statuses[j].URL) < 0
+	})
+	return statuses
+}
+
+// statusLoop periodically polls the tracked wallets for their status, also doing
+// so whenever a wallet event is fired, and notifies status subscribers of any
+// changes. Polling happens outside of the update loop since hardware wallets may
+// block on device communication.
+func (am *Manager) statusLoop() {
+	events := make(chan WalletEvent, managerSubBufferSize)
+	sub := am.feed.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	ticker := time.NewTicker(walletStatusRefresh)
+	defer ticker.Stop()
+
+	am.refreshStatuses()
+	for {
+		select {
+		case <-events:
+			am.refreshStatuses()
+		case <-ticker.C:
+			am.refreshStatuses()
+		case <-am.term:
+			return
+		}
+	}
+}
+
+// refreshStatuses polls all tracked wallets for their current status, updates
+// the status cache and notifies subscribers of any changes.
+func (am *Manager) refreshStatuses() {
+	var (
+		wallets = am.Wallets()
+		changes []WalletStatus
+		live    = make(map[URL]struct{}, len(wallets))
+	)
+	for _, wallet := range wallets {
+		status, failure := wallet.Status()
+
+		url := wallet.URL()
+		live[url] = struct{}{}
+
+		am.statusLock.Lock()
+		prev, known := am.statuses[url]
+		if known && prev.Status == status && errorString(prev.Failure) == errorString(failure) {
+			am.statusLock.Unlock()
+			continue
+		}
+		next := WalletStatus{
+			URL:       url,
+			Status:    status,
+			Failure:   failure,
+			LastError: prev.LastError,
+			Updated:   time.Now(),
+		}
+		if failure != nil {
+			next.LastError = failure
+		}
+		am.statuses[url] = next
+		am.statusLock.Unlock()
+
+		changes = append(changes, next)
+	}
+	// Report and forget any wallets that departed since the last refresh
+	am.statusLock.Lock()
+	for url, prev := range am.statuses {
+		if _, ok := live[url]; ok {
+			continue
+		}
+		delete(am.statuses, url)
+
+		prev.Dropped, prev.Updated = true, time.Now()
+		changes = append(changes, prev)
+	}
+	am.statusLock.Unlock()
+
+	for _, status := range changes {
+		am.statusFeed.Send(status)
+	}
+}
+
+// errorString returns the message of an error, or an empty string for nil errors.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
 // merge is a sorted analogue of append for wallets, where the ordering of the
 // origin list is preserved by inserting new wallets at the correct position.
 //
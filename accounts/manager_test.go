@@ -0,0 +1,154 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package accounts
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorievm/go-gori"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/event"
+)
+
+// testWallet is a mock wallet whose status can be changed by the tests.
+type testWallet struct {
+	url     URL
+	status  string
+	failure error
+	lock    sync.Mutex
+}
+
+func (w *testWallet) setStatus(status string, failure error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.status, w.failure = status, failure
+}
+
+func (w *testWallet) URL() URL { return w.url }
+func (w *testWallet) Status() (string, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.status, w.failure
+}
+func (w *testWallet) Open(passphrase string) error                           { return nil }
+func (w *testWallet) Close() error                                           { return nil }
+func (w *testWallet) Accounts() []Account                                    { return nil }
+func (w *testWallet) Contains(account Account) bool                          { return false }
+func (w *testWallet) Derive(DerivationPath, bool) (Account, error)           { return Account{}, ErrNotSupported }
+func (w *testWallet) SelfDerive([]DerivationPath, ethereum.ChainStateReader) {}
+func (w *testWallet) SignData(Account, string, []byte) ([]byte, error) {
+	return nil, ErrNotSupported
+}
+func (w *testWallet) SignDataWithPassphrase(Account, string, string, []byte) ([]byte, error) {
+	return nil, ErrNotSupported
+}
+func (w *testWallet) SignText(Account, []byte) ([]byte, error) { return nil, ErrNotSupported }
+func (w *testWallet) SignTextWithPassphrase(Account, string, []byte) ([]byte, error) {
+	return nil, ErrNotSupported
+}
+func (w *testWallet) SignTx(Account, *types.Transaction, *big.Int) (*types.Transaction, error) {
+	return nil, ErrNotSupported
+}
+func (w *testWallet) SignTxWithPassphrase(Account, string, *types.Transaction, *big.Int) (*types.Transaction, error) {
+	return nil, ErrNotSupported
+}
+
+// testBackend is a mock backend holding a fixed set of wallets that can be
+// dropped by the tests.
+type testBackend struct {
+	wallets []Wallet
+	feed    event.Feed
+}
+
+func (b *testBackend) Wallets() []Wallet { return b.wallets }
+func (b *testBackend) Subscribe(sink chan<- WalletEvent) event.Subscription {
+	return b.feed.Subscribe(sink)
+}
+
+func waitStatus(t *testing.T, ch <-chan WalletStatus) WalletStatus {
+	t.Helper()
+	select {
+	case status := <-ch:
+		return status
+	case <-time.After(2 * walletStatusRefresh):
+		t.Fatalf("timed out waiting for wallet status")
+	}
+	return WalletStatus{}
+}
+
+// Tests that the manager reports wallet health changes through the status feed.
+func TestManagerStatusFeed(t *testing.T) {
+	wallet := &testWallet{url: URL{Scheme: "test", Path: "wallet"}, status: "Locked"}
+	backend := &testBackend{wallets: []Wallet{wallet}}
+
+	am := NewManager(&Config{}, backend)
+	defer am.Close()
+
+	statuses := make(chan WalletStatus, 8)
+	sub := am.SubscribeStatus(statuses)
+	defer sub.Unsubscribe()
+
+	// Fire a wallet event to force a refresh and wait until the initial status
+	// is cached, whether or not it was emitted before we subscribed.
+	backend.feed.Send(WalletEvent{Wallet: wallet, Kind: WalletOpened})
+	deadline := time.Now().Add(2 * walletStatusRefresh)
+	for len(am.Statuses()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("initial wallet status not cached")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if have := am.Statuses()[0].Status; have != "Locked" {
+		t.Fatalf("initial status mismatch: have %q, want %q", have, "Locked")
+	}
+	// Drain anything emitted before the status changes
+	for len(statuses) > 0 {
+		<-statuses
+	}
+	// Report a device failure and ensure it's surfaced
+	failure := errors.New("device disconnected")
+	wallet.setStatus("Offline", failure)
+	backend.feed.Send(WalletEvent{Wallet: wallet, Kind: WalletOpened})
+
+	status := waitStatus(t, statuses)
+	if status.Status != "Offline" || status.Failure != failure || status.LastError != failure {
+		t.Fatalf("failure status mismatch: %+v", status)
+	}
+	// Recover and ensure the last error is retained
+	wallet.setStatus("Unlocked", nil)
+	backend.feed.Send(WalletEvent{Wallet: wallet, Kind: WalletOpened})
+
+	status = waitStatus(t, statuses)
+	if status.Status != "Unlocked" || status.Failure != nil || status.LastError != failure {
+		t.Fatalf("recovered status mismatch: %+v", status)
+	}
+	// Drop the wallet and ensure the departure is reported
+	backend.wallets = nil
+	backend.feed.Send(WalletEvent{Wallet: wallet, Kind: WalletDropped})
+
+	status = waitStatus(t, statuses)
+	if !status.Dropped || status.URL != wallet.url {
+		t.Fatalf("dropped status mismatch: %+v", status)
+	}
+	if len(am.Statuses()) != 0 {
+		t.Fatalf("dropped wallet still tracked: %v", am.Statuses())
+	}
+}
@@ -52,6 +52,7 @@ const (
 	ledgerOpRetrieveAddress  ledgerOpcode = 0x02 // Returns the public key and Ori address for a given BIP 32 path
 	ledgerOpSignTransaction  ledgerOpcode = 0x04 // Signs an Ori transaction after having the user validate the parameters
 	ledgerOpGetConfiguration ledgerOpcode = 0x06 // Returns specific wallet application configuration
+	ledgerOpProvideToken     ledgerOpcode = 0x0a // Provides the signed metadata of an ERC-20 token to display
 	ledgerOpSignTypedMessage ledgerOpcode = 0x0c // Signs an Ori message following the EIP 712 specification
 
 	ledgerP1DirectlyFetchAddress    ledgerParam1 = 0x00 // Return address directly from the wallet
@@ -170,6 +171,12 @@ func (w *ledgerDriver) SignTx(path accounts.DerivationPath, tx *types.Transactio
 		//lint:ignore ST1005 brand name displayed on the console
 		return common.Address{}, nil, fmt.Errorf("Ledger v%d.%d.%d doesn't support signing this transaction, please update to v1.0.3 at least", w.version[0], w.version[1], w.version[2])
 	}
+	// If the transaction is a transfer of a known token, let the device display it
+	if token, _, _ := tokenTransfer(tx, chainID); token != nil && len(token.LedgerSignature) > 0 {
+		if err := w.ledgerProvideToken(token); err != nil {
+			w.log.Warn("Failed to provide token information", "token", token.Symbol, "err", err)
+		}
+	}
 	// All infos gathered and metadata checks out, request signing
 	return w.ledgerSign(path, tx, chainID)
 }
@@ -287,6 +294,41 @@ func (w *ledgerDriver) ledgerDerive(derivationPath []uint32) (common.Address, er
 	return address, nil
 }
 
+// ledgerProvideToken sends the metadata of an ERC-20 token to the Ledger wallet,
+// signed by Ledger, so that a subsequent transfer of the token is displayed in
+// token units. The device keeps the information until the next signing.
+//
+// The token provisioning protocol is defined as follows:
+//
+//	CLA | INS | P1 | P2 | Lc       | Le
+//	----+-----+----+----+----------+---
+//	 E0 | 0A  | 00 | 00 | variable | 00
+//
+// Where the input is:
+//
+//	Description                          | Length
+//	-------------------------------------+----------
+//	Length of the token ticker           | 1 byte
+//	Token ticker                         | variable
+//	Token contract address               | 20 bytes
+//	Number of decimals (big endian)      | 4 bytes
+//	Chain ID (big endian)                | 4 bytes
+//	Token information signature (DER)    | variable
+//
+// With no output data.
+func (w *ledgerDriver) ledgerProvideToken(token *Token) error {
+	payload := make([]byte, 0, 1+len(token.Symbol)+common.AddressLength+8+len(token.LedgerSignature))
+	payload = append(payload, byte(len(token.Symbol)))
+	payload = append(payload, token.Symbol...)
+	payload = append(payload, token.Address.Bytes()...)
+	payload = binary.BigEndian.AppendUint32(payload, token.Decimals)
+	payload = binary.BigEndian.AppendUint32(payload, uint32(token.ChainID))
+	payload = append(payload, token.LedgerSignature...)
+
+	_, err := w.ledgerExchange(ledgerOpProvideToken, 0, 0, payload)
+	return err
+}
+
 // ledgerSign sends the transaction to the Ledger wallet, and waits for the user
 // to confirm or deny the transaction.
 //
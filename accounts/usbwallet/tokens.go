@@ -0,0 +1,152 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package usbwallet
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/core/types"
+)
+
+// embeddedTokens is the default list of well known ERC-20 tokens, which can be
+// extended or overridden via LoadTokens.
+//
+//go:embed tokens.json
+var embeddedTokens []byte
+
+// erc20TransferSelector is the method identifier of the ERC-20 transfer call,
+// the first 4 bytes of keccak256("transfer(address,uint256)").
+var erc20TransferSelector = []byte{0xa9, 0x05, 0x9c, 0xbb}
+
+// Token is the metadata of an ERC-20 token, along with the definitions signed
+// by the device vendors that allow hardware wallets to display transfers of the
+// token in token units instead of raw calldata.
+type Token struct {
+	ChainID  uint64         `json:"chainId"`
+	Address  common.Address `json:"address"`
+	Symbol   string         `json:"symbol"`
+	Decimals uint32         `json:"decimals"`
+
+	LedgerSignature  hexutil.Bytes `json:"ledgerSignature,omitempty"`  // Ledger signature over the token information
+	TrezorDefinition hexutil.Bytes `json:"trezorDefinition,omitempty"` // Encoded and signed Trezor token definition
+}
+
+// FormatAmount renders an amount of base units of the token in token units,
+// e.g. "12.5 USDC".
+func (t *Token) FormatAmount(amount *big.Int) string {
+	digits := amount.String()
+	if t.Decimals == 0 {
+		return fmt.Sprintf("%s %s", digits, t.Symbol)
+	}
+	if pad := int(t.Decimals) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	whole, frac := digits[:len(digits)-int(t.Decimals)], strings.TrimRight(digits[len(digits)-int(t.Decimals):], "0")
+	if frac == "" {
+		return fmt.Sprintf("%s %s", whole, t.Symbol)
+	}
+	return fmt.Sprintf("%s.%s %s", whole, frac, t.Symbol)
+}
+
+// tokenKey identifies a token contract across chains.
+type tokenKey struct {
+	chain   uint64
+	address common.Address
+}
+
+var (
+	tokens     = make(map[tokenKey]*Token) // Known tokens, keyed by chain and contract
+	tokensLock sync.RWMutex                // Lock protecting the known tokens
+)
+
+func init() {
+	if err := loadTokens(bytes.NewReader(embeddedTokens)); err != nil {
+		panic(fmt.Sprintf("invalid embedded token list: %v", err))
+	}
+}
+
+// LoadTokens reads a JSON token list in the format of the embedded one from the
+// given file, adding its tokens to the known ones and overriding the entries of
+// already known tokens, e.g. to supply updated vendor signed definitions.
+func LoadTokens(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return loadTokens(f)
+}
+
+// loadTokens parses a JSON token list and adds its tokens to the known ones.
+func loadTokens(r io.Reader) error {
+	var list []*Token
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return err
+	}
+	for i, token := range list {
+		if token.ChainID == 0 || token.Symbol == "" {
+			return fmt.Errorf("token %d: missing chain ID or symbol", i)
+		}
+		if len(token.Symbol) > 255 {
+			return fmt.Errorf("token %d: symbol too long", i)
+		}
+	}
+	tokensLock.Lock()
+	defer tokensLock.Unlock()
+
+	for _, token := range list {
+		tokens[tokenKey{token.ChainID, token.Address}] = token
+	}
+	return nil
+}
+
+// tokenTransfer checks whether a transaction is an ERC-20 transfer of a known
+// token, returning the token along with the recipient and the amount of the
+// transfer if so.
+func tokenTransfer(tx *types.Transaction, chainID *big.Int) (*Token, common.Address, *big.Int) {
+	// Transfers need a known chain and a well formed call to a token contract
+	if chainID == nil || !chainID.IsUint64() || tx.To() == nil || tx.Value().Sign() != 0 {
+		return nil, common.Address{}, nil
+	}
+	data := tx.Data()
+	if len(data) != 4+2*32 || !bytes.Equal(data[:4], erc20TransferSelector) {
+		return nil, common.Address{}, nil
+	}
+	// Reject recipients with dirty upper bytes, the contract would not accept them
+	if !bytes.Equal(data[4:4+12], make([]byte, 12)) {
+		return nil, common.Address{}, nil
+	}
+	tokensLock.RLock()
+	token := tokens[tokenKey{chainID.Uint64(), *tx.To()}]
+	tokensLock.RUnlock()
+
+	if token == nil {
+		return nil, common.Address{}, nil
+	}
+	return token, common.BytesToAddress(data[4+12 : 4+32]), new(big.Int).SetBytes(data[4+32:])
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package usbwallet
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/gorievm/go-gori/accounts/usbwallet/trezor"
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+)
+
+// Tests that token amounts are rendered in token units.
+func TestTokenFormatAmount(t *testing.T) {
+	tests := []struct {
+		decimals uint32
+		amount   int64
+		want     string
+	}{
+		{0, 12, "12 TKN"},
+		{2, 1250, "12.5 TKN"},
+		{2, 1200, "12 TKN"},
+		{6, 1, "0.000001 TKN"},
+		{6, 0, "0 TKN"},
+		{18, 1_500_000_000_000_000_000, "1.5 TKN"},
+	}
+	for i, tt := range tests {
+		token := &Token{Symbol: "TKN", Decimals: tt.decimals}
+		if have := token.FormatAmount(big.NewInt(tt.amount)); have != tt.want {
+			t.Errorf("test %d: amount mismatch: have %q, want %q", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that transfers of known tokens are detected, including ones added via
+// an updated token list.
+func TestTokenTransfer(t *testing.T) {
+	contract := common.HexToAddress("0x00000000000000000000000000000000000c0ffe")
+	if err := loadTokens(strings.NewReader(`[{"chainId": 1337, "address": "0x00000000000000000000000000000000000c0ffe", "symbol": "GORI-USD", "decimals": 2, "trezorDefinition": "0x0102"}]`)); err != nil {
+		t.Fatalf("failed to load tokens: %v", err)
+	}
+	recipient := common.Address{0xaa}
+	transfer := append(append(common.CopyBytes(erc20TransferSelector), common.LeftPadBytes(recipient.Bytes(), 32)...), common.LeftPadBytes(big.NewInt(1250).Bytes(), 32)...)
+
+	tx := types.NewTransaction(0, contract, new(big.Int), 100000, big.NewInt(1), transfer)
+	token, to, amount := tokenTransfer(tx, big.NewInt(1337))
+	if token == nil {
+		t.Fatalf("token transfer not detected")
+	}
+	if to != recipient || token.FormatAmount(amount) != "12.5 GORI-USD" {
+		t.Errorf("transfer mismatch: have %s to %x, want 12.5 GORI-USD to %x", token.FormatAmount(amount), to, recipient)
+	}
+	// Transfers on other chains or of unknown contracts should be ignored
+	if token, _, _ := tokenTransfer(tx, big.NewInt(1)); token != nil {
+		t.Errorf("transfer detected on wrong chain")
+	}
+	if token, _, _ := tokenTransfer(types.NewTransaction(0, common.Address{0x01}, new(big.Int), 100000, big.NewInt(1), transfer), big.NewInt(1337)); token != nil {
+		t.Errorf("transfer of unknown token detected")
+	}
+	// The definition of the token should be attached to Trezor requests
+	request := new(trezor.EthereumSignTx)
+	setTrezorTokenDefinition(request, token.TrezorDefinition)
+	blob, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatalf("failed to encode request: %v", err)
+	}
+	if want := []byte{0x62, 0x04, 0x12, 0x02, 0x01, 0x02}; !bytes.Equal(blob, want) {
+		t.Errorf("encoded request mismatch: have %x, want %x", blob, want)
+	}
+}
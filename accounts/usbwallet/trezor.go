@@ -235,6 +235,9 @@ func (w *trezorDriver) trezorSign(derivationPath []uint32, tx *types.Transaction
 		id := uint32(chainID.Int64())
 		request.ChainId = &id
 	}
+	if token, _, _ := tokenTransfer(tx, chainID); token != nil && len(token.TrezorDefinition) > 0 {
+		setTrezorTokenDefinition(request, token.TrezorDefinition)
+	}
 	// Send the initiation message and stream content until a signature is returned
 	response := new(trezor.EthereumTxRequest)
 	if _, err := w.trezorExchange(request, response); err != nil {
@@ -276,6 +279,22 @@ func (w *trezorDriver) trezorSign(derivationPath []uint32, tx *types.Transaction
 	return sender, signed, nil
 }
 
+// setTrezorTokenDefinition attaches a signed token definition to a signing
+// request, so that newer firmwares display the transfer in token units. Older
+// firmwares ignore it.
+//
+// The definitions field (EthereumSignTx.definitions = 12, containing
+// EthereumDefinitions.encoded_token = 2) postdates the bundled protocol schema,
+// so it's encoded by hand (tag, length, payload) into the unknown fields of the
+// message.
+func setTrezorTokenDefinition(request *trezor.EthereumSignTx, definition []byte) {
+	definitions := append([]byte{2<<3 | 2}, binary.AppendUvarint(nil, uint64(len(definition)))...)
+	definitions = append(definitions, definition...)
+
+	field := append([]byte{12<<3 | 2}, binary.AppendUvarint(nil, uint64(len(definitions)))...)
+	request.XXX_unrecognized = append(field, definitions...)
+}
+
 // trezorExchange performs a data exchange with the Trezor wallet, sending it a
 // message and retrieving the response. If multiple responses are possible, the
 // method will also return the index of the destination object used.
@@ -598,6 +598,9 @@ func (w *wallet) SignTx(account accounts.Account, tx *types.Transaction, chainID
 	if !ok {
 		return nil, accounts.ErrUnknownAccount
 	}
+	if token, to, amount := tokenTransfer(tx, chainID); token != nil {
+		w.log.Info("Requesting token transfer signature", "amount", token.FormatAmount(amount), "to", to, "token", token.Address)
+	}
 	// All infos gathered and metadata checks out, request signing
 	<-w.commsLock
 	defer func() { w.commsLock <- struct{}{} }()
@@ -18,9 +18,12 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -51,6 +54,10 @@ var (
 		Name:  "combined-json",
 		Usage: "Path to the combined-json file generated by compiler, - for STDIN",
 	}
+	artifactFlag = &cli.StringSliceFlag{
+		Name:  "artifact",
+		Usage: "Foundry or Hardhat artifact file, or directory of artifacts, to bind (can be repeated)",
+	}
 	excFlag = &cli.StringFlag{
 		Name:  "exc",
 		Usage: "Comma separated types to exclude from binding",
@@ -72,6 +79,10 @@ var (
 		Name:  "alias",
 		Usage: "Comma separated aliases for function and event renaming, e.g. original1=alias1, original2=alias2",
 	}
+	v2Flag = &cli.BoolFlag{
+		Name:  "v2",
+		Usage: "Generates generics based, context first bindings (go only)",
+	}
 )
 
 var app = flags.NewApp("Ori ABI wrapper code generator")
@@ -83,17 +94,19 @@ func init() {
 		binFlag,
 		typeFlag,
 		jsonFlag,
+		artifactFlag,
 		excFlag,
 		pkgFlag,
 		outFlag,
 		langFlag,
 		aliasFlag,
+		v2Flag,
 	}
 	app.Action = abigen
 }
 
 func abigen(c *cli.Context) error {
-	utils.CheckExclusive(c, abiFlag, jsonFlag) // Only one source can be selected.
+	utils.CheckExclusive(c, abiFlag, jsonFlag, artifactFlag) // Only one source can be selected.
 
 	if c.String(pkgFlag.Name) == "" {
 		utils.Fatalf("No destination package specified (--pkg)")
@@ -102,6 +115,9 @@ func abigen(c *cli.Context) error {
 	switch c.String(langFlag.Name) {
 	case "go":
 		lang = bind.LangGo
+		if c.Bool(v2Flag.Name) {
+			lang = bind.LangGoV2
+		}
 	default:
 		utils.Fatalf("Unsupported destination language \"%s\" (--lang)", c.String(langFlag.Name))
 	}
@@ -176,6 +192,11 @@ func abigen(c *cli.Context) error {
 			if err != nil {
 				utils.Fatalf("Failed to read contract information from json output: %v", err)
 			}
+		} else if c.IsSet(artifactFlag.Name) {
+			var err error
+			if contracts, err = loadArtifacts(c.StringSlice(artifactFlag.Name)); err != nil {
+				utils.Fatalf("Failed to read contract artifacts: %v", err)
+			}
 		}
 		// Gather all non-excluded contract for binding
 		for name, contract := range contracts {
@@ -231,6 +252,54 @@ func abigen(c *cli.Context) error {
 	return nil
 }
 
+// loadArtifacts parses the Foundry or Hardhat artifacts at the given paths,
+// recursing into directories and skipping any non-artifact files in them.
+func loadArtifacts(paths []string) (map[string]*compiler.Contract, error) {
+	contracts := make(map[string]*compiler.Contract)
+	load := func(path string, explicit bool) error {
+		blob, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fallback := strings.TrimSuffix(filepath.Base(path), ".json")
+		name, contract, err := compiler.ParseArtifact(blob, fallback)
+		if err != nil {
+			if !explicit && (errors.Is(err, compiler.ErrNotArtifact) || errors.As(err, new(*json.SyntaxError))) {
+				return nil
+			}
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		contracts[name] = contract
+		return nil
+	}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if err := load(path, true); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		err = filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			// Hardhat debug files and Foundry build-info files are not artifacts
+			if filepath.Ext(path) != ".json" || strings.HasSuffix(path, ".dbg.json") {
+				return nil
+			}
+			return load(path, false)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return contracts, nil
+}
+
 func main() {
 	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
 
@@ -49,6 +49,7 @@ import (
 	"github.com/gorievm/go-gori/params"
 	"github.com/gorievm/go-gori/rlp"
 	"github.com/gorievm/go-gori/rpc"
+	"github.com/gorievm/go-gori/signer/audit"
 	"github.com/gorievm/go-gori/signer/core"
 	"github.com/gorievm/go-gori/signer/core/apitypes"
 	"github.com/gorievm/go-gori/signer/fourbyte"
@@ -122,16 +123,41 @@ var (
 		Usage: "File used to emit audit logs. Set to \"\" to disable",
 		Value: "audit.log",
 	}
+	auditSinkFlag = &cli.StringFlag{
+		Name:  "audit.sink",
+		Usage: "Destination for structured (JSON) audit records of approval decisions: a file path, syslog://[host:port] or an http(s):// URL",
+	}
 	ruleFlag = &cli.StringFlag{
 		Name:  "rules",
 		Usage: "Path to the rule file to auto-authorize requests with",
 	}
+	rulesRPCFlag = &cli.StringFlag{
+		Name:  "rules.rpc",
+		Usage: "RPC endpoint the rules may query the chain state (balances, nonces, code) from",
+	}
+	rulesCallsFlag = &cli.StringFlag{
+		Name:  "rules.calls",
+		Usage: "Comma separated list of contract calls the rules may execute via eth_call, as address or address:selector",
+	}
 	stdiouiFlag = &cli.BoolFlag{
 		Name: "stdio-ui",
 		Usage: "Use STDIN/STDOUT as a channel for an external UI. " +
 			"This means that an STDIN/STDOUT is used for RPC-communication with a e.g. a graphical user " +
 			"interface, and can be used when Clef is started by an external process.",
 	}
+	remoteUIFlag = &cli.StringFlag{
+		Name:  "ui.remote",
+		Usage: "WebSocket, HTTP or IPC endpoint of a remote approval service to use as the UI-channel, e.g. for unattended operation",
+	}
+	remoteUIJWTSecretFlag = &cli.StringFlag{
+		Name:  "ui.remote.jwtsecret",
+		Usage: "Path to a hex encoded 32 byte secret used to authenticate to the remote UI (JWT)",
+	}
+	remoteUITimeoutFlag = &cli.DurationFlag{
+		Name:  "ui.remote.timeout",
+		Usage: "Time the remote UI is given to answer a request before it is considered rejected",
+		Value: core.DefaultRemoteUITimeout,
+	}
 	testFlag = &cli.BoolFlag{
 		Name:  "stdio-ui-test",
 		Usage: "Mechanism to test interface between Clef and UI. Requires 'stdio-ui'.",
@@ -280,8 +306,14 @@ func init() {
 		signerSecretFlag,
 		customDBFlag,
 		auditLogFlag,
+		auditSinkFlag,
 		ruleFlag,
+		rulesRPCFlag,
+		rulesCallsFlag,
 		stdiouiFlag,
+		remoteUIFlag,
+		remoteUIJWTSecretFlag,
+		remoteUITimeoutFlag,
 		testFlag,
 		advancedMode,
 		acceptFlag,
@@ -620,6 +652,28 @@ func ipcEndpoint(ipcPath, datadir string) string {
 	return ipcPath
 }
 
+// dialRemoteUI connects to a remote approval service, authenticating with the
+// JWT secret stored in the given file, if any.
+func dialRemoteUI(endpoint string, secretFile string) (*rpc.Client, error) {
+	var opts []rpc.ClientOption
+	if secretFile != "" {
+		blob, err := os.ReadFile(secretFile)
+		if err != nil {
+			return nil, err
+		}
+		secret := common.FromHex(strings.TrimSpace(string(blob)))
+		if len(secret) != 32 {
+			return nil, fmt.Errorf("invalid JWT secret length %d, want 32", len(secret))
+		}
+		var jwtSecret [32]byte
+		copy(jwtSecret[:], secret)
+		opts = append(opts, rpc.WithHTTPAuth(node.NewJWTAuth(jwtSecret)))
+	} else {
+		log.Warn("Remote UI connection is not authenticated")
+	}
+	return rpc.DialOptions(context.Background(), endpoint, opts...)
+}
+
 func signer(c *cli.Context) error {
 	// If we have some unrecognized command, bail out
 	if c.NArg() > 0 {
@@ -634,6 +688,13 @@ func signer(c *cli.Context) error {
 	if c.Bool(stdiouiFlag.Name) {
 		log.Info("Using stdin/stdout as UI-channel")
 		ui = core.NewStdIOUI()
+	} else if endpoint := c.String(remoteUIFlag.Name); endpoint != "" {
+		client, err := dialRemoteUI(endpoint, c.String(remoteUIJWTSecretFlag.Name))
+		if err != nil {
+			utils.Fatalf("Failed to connect to remote UI: %v", err)
+		}
+		log.Info("Using remote service as UI-channel", "endpoint", endpoint)
+		ui = core.NewRemoteUI(client, c.Duration(remoteUITimeoutFlag.Name))
 	} else {
 		log.Info("Using CLI as UI-channel")
 		ui = core.NewCommandlineUI()
@@ -684,6 +745,22 @@ func signer(c *cli.Context) error {
 					if err != nil {
 						utils.Fatalf(err.Error())
 					}
+					if endpoint := c.String(rulesRPCFlag.Name); endpoint != "" {
+						var calls []rules.CallFilter
+						for _, spec := range utils.SplitAndTrim(c.String(rulesCallsFlag.Name)) {
+							filter, err := rules.ParseCallFilter(spec)
+							if err != nil {
+								utils.Fatalf("Invalid rule call filter: %v", err)
+							}
+							calls = append(calls, filter)
+						}
+						client, err := rpc.Dial(endpoint)
+						if err != nil {
+							utils.Fatalf("Failed to connect to rules chain backend: %v", err)
+						}
+						ruleEngine.SetChainBackend(client, calls)
+						log.Info("Rule chain state access configured", "endpoint", endpoint, "calls", len(calls))
+					}
 					ruleEngine.Init(string(ruleJS))
 					ui = ruleEngine
 					log.Info("Rule engine configured", "file", c.String(ruleFlag.Name))
@@ -702,6 +779,16 @@ func signer(c *cli.Context) error {
 	log.Info("Starting signer", "chainid", chainId, "keystore", ksLoc,
 		"light-kdf", lightKdf, "advanced", advanced)
 	am := core.StartClefAccountManager(ksLoc, nousb, lightKdf, scpath)
+	// Structured audit records of all approval decisions
+	if spec := c.String(auditSinkFlag.Name); spec != "" {
+		sink, err := audit.NewSink(spec)
+		if err != nil {
+			utils.Fatalf("Failed to open audit sink: %v", err)
+		}
+		defer sink.Close()
+		ui = audit.NewAuditor(ui, sink)
+		log.Info("Audit sink configured", "sink", spec)
+	}
 	apiImpl := core.NewSignerAPI(am, chainId, nousb, ui, db, advanced, pwStorage)
 
 	// Establish the bidirectional communication, by creating a new UI backend and registering
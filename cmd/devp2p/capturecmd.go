@@ -0,0 +1,236 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/p2p/capture"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	captureCommand = &cli.Command{
+		Name:  "capture",
+		Usage: "Inspect message captures recorded with --p2p.capture",
+		Subcommands: []*cli.Command{
+			captureDumpCommand,
+			captureStatsCommand,
+		},
+	}
+	captureDumpCommand = &cli.Command{
+		Name:      "dump",
+		Usage:     "Prints the captured messages matching the filters",
+		ArgsUsage: "<file|dir>...",
+		Action:    captureDump,
+		Flags: []cli.Flag{
+			capturePeerFlag,
+			captureProtocolFlag,
+			captureMessageFlag,
+			captureInboundFlag,
+			captureOutboundFlag,
+			captureSinceFlag,
+			captureUntilFlag,
+			capturePayloadFlag,
+		},
+	}
+	captureStatsCommand = &cli.Command{
+		Name:      "stats",
+		Usage:     "Shows message counts and sizes of the captured messages matching the filters",
+		ArgsUsage: "<file|dir>...",
+		Action:    captureStats,
+		Flags: []cli.Flag{
+			capturePeerFlag,
+			captureProtocolFlag,
+			captureMessageFlag,
+			captureInboundFlag,
+			captureOutboundFlag,
+			captureSinceFlag,
+			captureUntilFlag,
+		},
+	}
+)
+
+var (
+	capturePeerFlag = &cli.StringFlag{
+		Name:  "peer",
+		Usage: "Only show messages of peers whose node ID starts with the given hex prefix",
+	}
+	captureProtocolFlag = &cli.StringFlag{
+		Name:  "protocol",
+		Usage: "Only show messages of the given protocol (e.g. p2p, eth, snap)",
+	}
+	captureMessageFlag = &cli.StringSliceFlag{
+		Name:  "msg",
+		Usage: "Only show messages with the given names or hex codes (e.g. BlockHeaders, 0x04)",
+	}
+	captureInboundFlag = &cli.BoolFlag{
+		Name:  "inbound",
+		Usage: "Only show messages received from peers",
+	}
+	captureOutboundFlag = &cli.BoolFlag{
+		Name:  "outbound",
+		Usage: "Only show messages sent to peers",
+	}
+	captureSinceFlag = &cli.StringFlag{
+		Name:  "since",
+		Usage: "Only show messages captured after the given time (RFC3339)",
+	}
+	captureUntilFlag = &cli.StringFlag{
+		Name:  "until",
+		Usage: "Only show messages captured before the given time (RFC3339)",
+	}
+	capturePayloadFlag = &cli.BoolFlag{
+		Name:  "payload",
+		Usage: "Print the raw RLP payloads of the messages",
+	}
+)
+
+func captureDump(ctx *cli.Context) error {
+	return iterateCapture(ctx, func(rec *capture.Record) {
+		fmt.Println(rec)
+		if ctx.Bool(capturePayloadFlag.Name) {
+			fmt.Printf("    %x\n", rec.Payload)
+		}
+	})
+}
+
+func captureStats(ctx *cli.Context) error {
+	type stat struct {
+		count int
+		size  uint64
+	}
+	var (
+		stats = make(map[string]*stat)
+		peers = make(map[string]struct{})
+		first time.Time
+		last  time.Time
+	)
+	err := iterateCapture(ctx, func(rec *capture.Record) {
+		key := fmt.Sprintf("%s %s/%d %s", rec.Direction(), rec.Protocol, rec.Version, rec.Name())
+		if stats[key] == nil {
+			stats[key] = new(stat)
+		}
+		stats[key].count++
+		stats[key].size += uint64(rec.Size)
+
+		peers[rec.Peer.String()] = struct{}{}
+		if first.IsZero() {
+			first = rec.Timestamp()
+		}
+		last = rec.Timestamp()
+	})
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(stats))
+	for key := range stats {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Printf("Capture spans %v over %d peers.\n", last.Sub(first), len(peers))
+	for _, key := range keys {
+		fmt.Printf("%-50s count=%-8d size=%v\n", key, stats[key].count, common.StorageSize(stats[key].size))
+	}
+	return nil
+}
+
+// iterateCapture runs the callback for every record in the capture files given
+// as arguments which matches the filters set via flags.
+func iterateCapture(ctx *cli.Context, callback func(rec *capture.Record)) error {
+	if ctx.NArg() < 1 {
+		return errors.New("need capture files or directories as arguments")
+	}
+	filter, err := makeCaptureFilter(ctx)
+	if err != nil {
+		return err
+	}
+	var files []string
+	for _, path := range ctx.Args().Slice() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		dirFiles, err := capture.Files(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, dirFiles...)
+	}
+	for _, file := range files {
+		r, err := capture.Open(file)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		for {
+			rec, err := r.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				fmt.Fprintf(os.Stderr, "%s: truncated record at end of file\n", file)
+				break
+			}
+			if err != nil {
+				r.Close()
+				return fmt.Errorf("%s: %v", file, err)
+			}
+			if filter.Match(rec) {
+				callback(rec)
+			}
+		}
+		r.Close()
+	}
+	return nil
+}
+
+// makeCaptureFilter creates a capture filter from the flags of the command.
+func makeCaptureFilter(ctx *cli.Context) (*capture.Filter, error) {
+	filter := &capture.Filter{
+		Peer:     ctx.String(capturePeerFlag.Name),
+		Protocol: ctx.String(captureProtocolFlag.Name),
+		Messages: ctx.StringSlice(captureMessageFlag.Name),
+		Inbound:  ctx.Bool(captureInboundFlag.Name),
+		Outbound: ctx.Bool(captureOutboundFlag.Name),
+	}
+	if filter.Inbound && filter.Outbound {
+		return nil, fmt.Errorf("--%s and --%s are mutually exclusive", captureInboundFlag.Name, captureOutboundFlag.Name)
+	}
+	var err error
+	if since := ctx.String(captureSinceFlag.Name); since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return nil, fmt.Errorf("invalid --%s: %v", captureSinceFlag.Name, err)
+		}
+	}
+	if until := ctx.String(captureUntilFlag.Name); until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return nil, fmt.Errorf("invalid --%s: %v", captureUntilFlag.Name, err)
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gorievm/go-gori/accounts/keystore"
+	"github.com/gorievm/go-gori/cmd/utils"
+	"github.com/gorievm/go-gori/core"
+	"github.com/urfave/cli/v2"
+)
+
+var commandSignOverride = &cli.Command{
+	Name:      "signoverride",
+	Usage:     "sign a fork override file",
+	ArgsUsage: "<keyfile> <overridefile>",
+	Description: `
+Sign a fork override file with a keyfile. The signature is written into the
+override file, which can then be distributed to the nodes trusting the key via
+--override.signers. Nodes only accept an override with a higher sequence number
+than the one they follow.
+`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() != 2 {
+			utils.Fatalf("Need a keyfile and an override file")
+		}
+		keyfilepath, overridepath := ctx.Args().Get(0), ctx.Args().Get(1)
+
+		override, err := core.LoadForkOverride(overridepath)
+		if err != nil {
+			utils.Fatalf("Failed to load the override: %v", err)
+		}
+		// Load the keyfile.
+		keyjson, err := os.ReadFile(keyfilepath)
+		if err != nil {
+			utils.Fatalf("Failed to read the keyfile at '%s': %v", keyfilepath, err)
+		}
+		// Decrypt key with passphrase.
+		passphrase := getPassphrase(ctx, false)
+		key, err := keystore.DecryptKey(keyjson, passphrase)
+		if err != nil {
+			utils.Fatalf("Error decrypting key: %v", err)
+		}
+		if err := override.Sign(key.PrivateKey); err != nil {
+			utils.Fatalf("Failed to sign override: %v", err)
+		}
+		blob, err := json.MarshalIndent(override, "", "  ")
+		if err != nil {
+			utils.Fatalf("Failed to encode override: %v", err)
+		}
+		if err := os.WriteFile(overridepath, append(blob, '\n'), 0644); err != nil {
+			utils.Fatalf("Failed to write override: %v", err)
+		}
+		fmt.Println("Signed override", override.Hash().Hex(), "with", key.Address.Hex())
+		return nil
+	},
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/gorievm/go-gori/core/state/snapshot"
 	"github.com/gorievm/go-gori/crypto"
 	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/ethdb/s3"
 	"github.com/gorievm/go-gori/internal/flags"
 	"github.com/gorievm/go-gori/log"
 	"github.com/gorievm/go-gori/trie"
@@ -67,6 +68,7 @@ Remove blockchain and state databases`,
 			dbDumpFreezerIndex,
 			dbImportCmd,
 			dbExportCmd,
+			dbPublishAncientsCmd,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
 		},
@@ -184,6 +186,19 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabasePathFlags),
 		Description: "Exports the specified chain data to an RLP encoded stream, optionally gzip-compressed.",
 	}
+	dbPublishAncientsCmd = &cli.Command{
+		Action:    publishAncients,
+		Name:      "publish-ancients",
+		Usage:     "Publishes the ancient chain data to an S3-compatible bucket",
+		ArgsUsage: "<bucket-url>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `This command uploads the ancient chain data not yet published to the bucket
+at the given path-style URL (e.g. https://s3.example.com/bucket/prefix), so that
+nodes started with --datadir.ancient.remote can share it. The credentials are
+taken from the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables.`,
+	}
 	dbMetadataCmd = &cli.Command{
 		Action: showMetaData,
 		Name:   "metadata",
@@ -690,6 +705,29 @@ func exportChaindata(ctx *cli.Context) error {
 	return utils.ExportChaindata(ctx.Args().Get(1), kind, exporter(db), stop)
 }
 
+func publishAncients(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	store, err := s3.New(s3.ConfigFromEnv(ctx.Args().Get(0)))
+	if err != nil {
+		return err
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	start := time.Now()
+	items, err := rawdb.PublishChainAncients(db, store)
+	if err != nil {
+		return err
+	}
+	log.Info("Published ancient chain data", "items", items, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
 func showMetaData(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
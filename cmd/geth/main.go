@@ -36,6 +36,7 @@ import (
 	"github.com/gorievm/go-gori/internal/debug"
 	"github.com/gorievm/go-gori/internal/ethapi"
 	"github.com/gorievm/go-gori/internal/flags"
+	"github.com/gorievm/go-gori/internal/service"
 	"github.com/gorievm/go-gori/log"
 	"github.com/gorievm/go-gori/metrics"
 	"github.com/gorievm/go-gori/node"
@@ -64,30 +65,50 @@ var (
 		utils.ExternalSignerFlag,
 		utils.NoUSBFlag,
 		utils.USBFlag,
+		utils.USBTokensFlag,
 		utils.SmartCardDaemonPathFlag,
 		utils.OverrideCancun,
 		utils.OverrideVerkle,
+		utils.OverrideScheduleFlag,
+		utils.OverrideSignersFlag,
 		utils.EnablePersonal,
 		utils.TxPoolLocalsFlag,
 		utils.TxPoolNoLocalsFlag,
+		utils.TxPoolPriorityFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolRejournalFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
+		utils.TxPoolPriceBumpsFlag,
+		utils.TxPoolStrictNonceFlag,
 		utils.TxPoolAccountSlotsFlag,
 		utils.TxPoolGlobalSlotsFlag,
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolTxLifetimeFlag,
+		utils.TxPoolLocalTxLifetimeFlag,
+		utils.TxPoolOverflowFlag,
+		utils.TxPoolOverflowCapFlag,
+		utils.TxPoolForensicsFlag,
+		utils.TxPoolForensicsDirFlag,
+		utils.TxPoolForensicsRetentionFlag,
 		utils.BlobPoolDataDirFlag,
 		utils.BlobPoolDataCapFlag,
 		utils.BlobPoolPriceBumpFlag,
 		utils.SyncModeFlag,
 		utils.SyncTargetFlag,
+		utils.SyncCheckpointFlag,
+		utils.ShutdownTimeoutFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
+		utils.LogHistoryFlag,
+		utils.LogNoHistoryFlag,
+		utils.AddressIndexFlag,
+		utils.AddressIndexLimitFlag,
+		utils.HistoryCutoffFlag,
 		utils.LightServeFlag,
 		utils.LightIngressFlag,
 		utils.LightEgressFlag,
@@ -108,6 +129,9 @@ var (
 		utils.CacheNoPrefetchFlag,
 		utils.CachePreimagesFlag,
 		utils.CacheLogSizeFlag,
+		utils.WitnessHistoryFlag,
+		utils.SnapshotRateFlag,
+		utils.StateHealFlag,
 		utils.FDLimitFlag,
 		utils.CryptoKZGFlag,
 		utils.ListenPortFlag,
@@ -127,6 +151,7 @@ var (
 		utils.DiscoveryV5Flag,
 		utils.LegacyDiscoveryV5Flag,
 		utils.NetrestrictFlag,
+		utils.P2PCaptureFlag,
 		utils.NodeKeyFileFlag,
 		utils.NodeKeyHexFlag,
 		utils.DNSDiscoveryFlag,
@@ -134,14 +159,20 @@ var (
 		utils.DeveloperGasLimitFlag,
 		utils.DeveloperPeriodFlag,
 		utils.VMEnableDebugFlag,
+		utils.VMParallelFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
+		utils.ExporterURLFlag,
+		utils.ExporterTopicFlag,
+		utils.ExporterLogAddressesFlag,
+		utils.ExporterLogTopicsFlag,
 		utils.NoCompactionFlag,
 		utils.GpoBlocksFlag,
 		utils.GpoPercentileFlag,
 		utils.GpoMaxGasPriceFlag,
 		utils.GpoIgnoreGasPriceFlag,
 		configFileFlag,
+		utils.ProfileFlag,
 	}, utils.NetworkFlags, utils.DatabasePathFlags)
 
 	rpcFlags = []cli.Flag{
@@ -153,6 +184,7 @@ var (
 		utils.AuthPortFlag,
 		utils.AuthVirtualHostsFlag,
 		utils.JWTSecretFlag,
+		utils.AuthValidationFlag,
 		utils.HTTPVirtualHostsFlag,
 		utils.GraphQLEnabledFlag,
 		utils.GraphQLCORSDomainFlag,
@@ -170,10 +202,18 @@ var (
 		utils.InsecureUnlockAllowedFlag,
 		utils.RPCGlobalGasCapFlag,
 		utils.RPCGlobalEVMTimeoutFlag,
+		utils.RPCWarmQuotaFlag,
+		utils.RPCLogResultLimitFlag,
+		utils.RPCLogRangeLimitFlag,
 		utils.RPCGlobalTxFeeCapFlag,
+		utils.RPCExtendedReceiptsFlag,
 		utils.AllowUnprotectedTxs,
 		utils.BatchRequestLimit,
 		utils.BatchResponseMaxSize,
+		utils.RPCSpoolThresholdFlag,
+		utils.RPCSpoolQuotaFlag,
+		utils.GethCompatFlag,
+		utils.GethCompatStrictFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -245,7 +285,10 @@ func init() {
 	app.Before = func(ctx *cli.Context) error {
 		maxprocs.Set() // Automatically set GOMAXPROCS to match Linux container CPU quota.
 		flags.MigrateGlobalFlags(ctx)
-		return debug.Setup(ctx)
+		if err := debug.Setup(ctx); err != nil {
+			return err
+		}
+		return applyProfile(ctx)
 	}
 	app.After = func(ctx *cli.Context) error {
 		debug.Exit()
@@ -325,6 +368,11 @@ func gori(ctx *cli.Context) error {
 		return fmt.Errorf("invalid command: %q", args[0])
 	}
 
+	if err := service.Start(clientIdentifier); err != nil {
+		log.Warn("Failed to connect to service manager", "err", err)
+	}
+	defer service.Stopped()
+
 	prepare(ctx)
 	stack, backend := makeFullNode(ctx)
 	defer stack.Close()
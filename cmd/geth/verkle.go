@@ -19,12 +19,14 @@ package main
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 
 	"github.com/gorievm/go-gori/cmd/utils"
 	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/internal/flags"
 	"github.com/gorievm/go-gori/log"
@@ -61,6 +63,19 @@ This command takes a root commitment and attempts to rebuild the tree.
 gori verkle dump <state-root> <key 1> [<key 2> ...]
 This command will produce a dot file representing the tree, rooted at <root>.
 in which key1, key2, ... are expanded.
+ `,
+			},
+			{
+				Name:      "genesis",
+				Usage:     "Convert a genesis specification to a verkle genesis",
+				ArgsUsage: "<genesisPath>",
+				Action:    convertGenesis,
+				Description: `
+gori verkle genesis <genesisPath>
+This command schedules the verkle fork at the genesis timestamp of the given
+specification, so that the state is held in a verkle tree from genesis on. The
+converted specification is printed to stdout, the verkle state root and hash of
+the resulting genesis block are logged.
  `,
 			},
 		},
@@ -210,3 +225,38 @@ func expandVerkle(ctx *cli.Context) error {
 	}
 	return nil
 }
+
+func convertGenesis(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("need genesis.json file as the only argument")
+	}
+	file, err := os.Open(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to read genesis file: %v", err)
+	}
+	defer file.Close()
+
+	genesis := new(core.Genesis)
+	if err := json.NewDecoder(file).Decode(genesis); err != nil {
+		utils.Fatalf("invalid genesis file: %v", err)
+	}
+	if genesis.Config == nil {
+		return errors.New("genesis has no chain config")
+	}
+	if !genesis.IsVerkle() {
+		timestamp := genesis.Timestamp
+		genesis.Config.VerkleTime = &timestamp
+		if !genesis.IsVerkle() {
+			return errors.New("verkle requires london to be active at genesis")
+		}
+	}
+	if err := genesis.Config.CheckConfigForkOrder(); err != nil {
+		return err
+	}
+	block := genesis.ToBlock()
+	log.Info("Converted genesis to verkle", "root", block.Root(), "hash", block.Hash())
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(genesis)
+}
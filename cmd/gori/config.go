@@ -35,6 +35,7 @@ import (
 	"github.com/gorievm/go-gori/eth/catalyst"
 	"github.com/gorievm/go-gori/eth/downloader"
 	"github.com/gorievm/go-gori/eth/ethconfig"
+	"github.com/gorievm/go-gori/exporter"
 	"github.com/gorievm/go-gori/internal/ethapi"
 	"github.com/gorievm/go-gori/internal/flags"
 	"github.com/gorievm/go-gori/internal/version"
@@ -92,7 +93,9 @@ type goriConfig struct {
 	Eth      ethconfig.Config
 	Node     node.Config
 	Ethstats ethstatsConfig
+	Exporter exporter.Config
 	Metrics  metrics.Config
+	Profiles map[string]*utils.Profile `toml:",omitempty"`
 }
 
 func loadConfig(file string, cfg *goriConfig) error {
@@ -126,9 +129,10 @@ func defaultNodeConfig() node.Config {
 func loadBaseConfig(ctx *cli.Context) goriConfig {
 	// Load defaults.
 	cfg := goriConfig{
-		Eth:     ethconfig.Defaults,
-		Node:    defaultNodeConfig(),
-		Metrics: metrics.DefaultConfig,
+		Eth:      ethconfig.Defaults,
+		Node:     defaultNodeConfig(),
+		Exporter: exporter.DefaultConfig,
+		Metrics:  metrics.DefaultConfig,
 	}
 
 	// Load config file.
@@ -143,6 +147,28 @@ func loadBaseConfig(ctx *cli.Context) goriConfig {
 	return cfg
 }
 
+// applyProfile applies the settings of the profile selected via --profile from
+// the config file, before any of the affected flags are consumed.
+func applyProfile(ctx *cli.Context) error {
+	name := ctx.String(utils.ProfileFlag.Name)
+	if name == "" {
+		return nil
+	}
+	file := ctx.String(configFileFlag.Name)
+	if file == "" {
+		return fmt.Errorf("--%s requires profiles defined in a --%s file", utils.ProfileFlag.Name, configFileFlag.Name)
+	}
+	var cfg goriConfig
+	if err := loadConfig(file, &cfg); err != nil {
+		return err
+	}
+	profile, ok := cfg.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not defined in %s", name, file)
+	}
+	return utils.ApplyProfile(ctx, name, profile)
+}
+
 // makeConfigNode loads gori configuration and creates a blank node instance.
 func makeConfigNode(ctx *cli.Context) (*node.Node, goriConfig) {
 	cfg := loadBaseConfig(ctx)
@@ -159,6 +185,7 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, goriConfig) {
 	if ctx.IsSet(utils.EthStatsURLFlag.Name) {
 		cfg.Ethstats.URL = ctx.String(utils.EthStatsURLFlag.Name)
 	}
+	utils.SetExporterConfig(ctx, &cfg.Exporter)
 	applyMetricConfig(ctx, &cfg)
 
 	return stack, cfg
@@ -177,9 +204,17 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	}
 	backend, eth := utils.RegisterEthService(stack, &cfg.Eth)
 
+	// Ensure the data directory of the selected profile holds the right chain
+	if name := ctx.String(utils.ProfileFlag.Name); name != "" {
+		utils.CheckProfileGenesis(stack, name, cfg.Profiles[name], backend.ChainDb())
+	}
+
 	// Configure log filter RPC API.
 	filterSystem := utils.RegisterFilterAPI(stack, backend, &cfg.Eth)
 
+	// Configure the geth API compatibility layer if requested.
+	utils.RegisterGethCompat(ctx, stack)
+
 	// Configure GraphQL if requested.
 	if ctx.IsSet(utils.GraphQLEnabledFlag.Name) {
 		utils.RegisterGraphQLService(stack, backend, filterSystem, &cfg.Node)
@@ -190,6 +225,11 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 		utils.RegisterEthStatsService(stack, backend, cfg.Ethstats.URL)
 	}
 
+	// Add the chain event exporter if requested.
+	if cfg.Exporter.URL != "" {
+		utils.RegisterExporterService(stack, backend, &cfg.Exporter)
+	}
+
 	// Configure full-sync tester service if requested
 	if ctx.IsSet(utils.SyncTargetFlag.Name) && cfg.Eth.SyncMode == downloader.FullSync {
 		utils.RegisterFullSyncTester(stack, eth, ctx.Path(utils.SyncTargetFlag.Name))
@@ -209,6 +249,9 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 		if err != nil {
 			utils.Fatalf("failed to register catalyst service: %v", err)
 		}
+		if ctx.Bool(utils.AuthValidationFlag.Name) {
+			catalyst.RegisterValidationAPI(stack, eth)
+		}
 	}
 	return stack, backend
 }
@@ -327,6 +370,12 @@ func setAccountManagerBackends(conf *node.Config, am *accounts.Manager, keydir s
 	// accounts in both externally and locally, plus very racey.
 	am.AddBackend(keystore.NewKeyStore(keydir, scryptN, scryptP))
 	if conf.USB {
+		// Load any token definitions for previewing transfers on the devices
+		if conf.USBTokens != "" {
+			if err := usbwallet.LoadTokens(conf.USBTokens); err != nil {
+				log.Warn("Failed to load hardware wallet token list", "path", conf.USBTokens, "err", err)
+			}
+		}
 		// Start a USB hub for Ledger hardware wallets
 		if ledgerhub, err := usbwallet.NewLedgerHub(); err != nil {
 			log.Warn(fmt.Sprintf("Failed to start Ledger hub, disabling: %v", err))
@@ -0,0 +1,218 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// loadgen generates transaction load against a node, either a remote one over
+// RPC or an in-process dev node, reporting the inclusion latencies.
+package main
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/eth/ethconfig"
+	"github.com/gorievm/go-gori/internal/debug"
+	"github.com/gorievm/go-gori/internal/flags"
+	"github.com/gorievm/go-gori/loadgen"
+	"github.com/gorievm/go-gori/node/builder"
+	"github.com/gorievm/go-gori/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	rpcFlag = &cli.StringFlag{
+		Name:  "rpc",
+		Usage: "RPC endpoint of the node to load (empty = start an in-process dev node)",
+	}
+	faucetFlag = &cli.StringFlag{
+		Name:  "faucet",
+		Usage: "Hex private key of the account funding the senders (required with --rpc)",
+	}
+	periodFlag = &cli.Uint64Flag{
+		Name:  "dev.period",
+		Usage: "Block period of the in-process dev node (0 = mine on demand)",
+		Value: 1,
+	}
+	gasLimitFlag = &cli.Uint64Flag{
+		Name:  "dev.gaslimit",
+		Usage: "Block gas limit of the in-process dev node",
+		Value: 30_000_000,
+	}
+	rateFlag = &cli.Float64Flag{
+		Name:  "rate",
+		Usage: "Transactions to submit per second",
+		Value: loadgen.DefaultConfig.Rate,
+	}
+	durationFlag = &cli.DurationFlag{
+		Name:  "duration",
+		Usage: "Time to keep submitting transactions for",
+		Value: loadgen.DefaultConfig.Duration,
+	}
+	timeoutFlag = &cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "Time to wait for the inclusion of the submitted transactions",
+		Value: loadgen.DefaultConfig.Timeout,
+	}
+	accountsFlag = &cli.IntFlag{
+		Name:  "accounts",
+		Usage: "Number of sender accounts to fund from the faucet",
+		Value: loadgen.DefaultConfig.Accounts,
+	}
+	workersFlag = &cli.IntFlag{
+		Name:  "workers",
+		Usage: "Number of concurrent transaction submitters",
+		Value: loadgen.DefaultConfig.Workers,
+	}
+	fundingFlag = &cli.StringFlag{
+		Name:  "funding",
+		Usage: "Amount in wei to fund each sender account with",
+		Value: loadgen.DefaultConfig.Funding.String(),
+	}
+	mixFlag = &cli.StringFlag{
+		Name:  "mix",
+		Usage: "Relative weights of the transaction kinds (transfer, erc20, deploy, blob)",
+		Value: "transfer=70,erc20=20,deploy=10",
+	}
+)
+
+var app = flags.NewApp("transaction load generator")
+
+func init() {
+	app.Flags = append([]cli.Flag{
+		rpcFlag,
+		faucetFlag,
+		periodFlag,
+		gasLimitFlag,
+		rateFlag,
+		durationFlag,
+		timeoutFlag,
+		accountsFlag,
+		workersFlag,
+		fundingFlag,
+		mixFlag,
+	}, debug.Flags...)
+	app.Before = func(ctx *cli.Context) error {
+		flags.MigrateGlobalFlags(ctx)
+		return debug.Setup(ctx)
+	}
+	app.After = func(ctx *cli.Context) error {
+		debug.Exit()
+		return nil
+	}
+	app.Action = generate
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// generate runs the load generator with the configured settings.
+func generate(ctx *cli.Context) error {
+	mix, err := loadgen.ParseMix(ctx.String(mixFlag.Name))
+	if err != nil {
+		return err
+	}
+	funding, ok := new(big.Int).SetString(ctx.String(fundingFlag.Name), 10)
+	if !ok {
+		return fmt.Errorf("invalid funding amount %q", ctx.String(fundingFlag.Name))
+	}
+	config := loadgen.Config{
+		Rate:     ctx.Float64(rateFlag.Name),
+		Duration: ctx.Duration(durationFlag.Name),
+		Timeout:  ctx.Duration(timeoutFlag.Name),
+		Accounts: ctx.Int(accountsFlag.Name),
+		Workers:  ctx.Int(workersFlag.Name),
+		Funding:  funding,
+		Mix:      mix,
+		Poll:     loadgen.DefaultConfig.Poll,
+	}
+	// Connect to the node to load, starting an in-process one if none given
+	var (
+		client *rpc.Client
+		faucet *ecdsa.PrivateKey
+	)
+	if url := ctx.String(rpcFlag.Name); url != "" {
+		if !ctx.IsSet(faucetFlag.Name) {
+			return errors.New("--faucet is required with --rpc")
+		}
+		if faucet, err = crypto.HexToECDSA(strings.TrimPrefix(ctx.String(faucetFlag.Name), "0x")); err != nil {
+			return fmt.Errorf("invalid faucet key: %v", err)
+		}
+		if client, err = rpc.DialContext(ctx.Context, url); err != nil {
+			return err
+		}
+	} else {
+		if mix[loadgen.KindBlob] > 0 {
+			return errors.New("blob transactions need a Cancun network, use --rpc")
+		}
+		if faucet, err = crypto.GenerateKey(); err != nil {
+			return err
+		}
+		stack, err := startDevNode(faucet, ctx.Uint64(periodFlag.Name), ctx.Uint64(gasLimitFlag.Name))
+		if err != nil {
+			return err
+		}
+		defer stack.Close()
+		client = stack.Attach()
+	}
+	defer client.Close()
+
+	gen, err := loadgen.New(loadgen.NewClient(client), faucet, config)
+	if err != nil {
+		return err
+	}
+	runCtx, cancel := signal.NotifyContext(ctx.Context, os.Interrupt)
+	defer cancel()
+
+	report, err := gen.Run(runCtx)
+	if err != nil {
+		return err
+	}
+	report.Write(os.Stdout)
+	return nil
+}
+
+// startDevNode starts an in-process dev node with the faucet pre-funded, sealing
+// blocks with the given period.
+func startDevNode(faucet *ecdsa.PrivateKey, period uint64, gasLimit uint64) (*builder.Node, error) {
+	stack, err := builder.New().
+		WithName("loadgen").
+		WithChain(core.DeveloperGenesisBlock(gasLimit, crypto.PubkeyToAddress(faucet.PublicKey))).
+		WithEthConfig(func(config *ethconfig.Config) {
+			config.Miner.GasCeil = gasLimit
+			config.TrieTimeout = time.Minute
+		}).
+		WithDev(period).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	if err := stack.Start(); err != nil {
+		stack.Close()
+		return nil, err
+	}
+	return stack, nil
+}
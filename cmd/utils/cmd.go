@@ -38,6 +38,7 @@ import (
 	"github.com/gorievm/go-gori/eth/ethconfig"
 	"github.com/gorievm/go-gori/ethdb"
 	"github.com/gorievm/go-gori/internal/debug"
+	"github.com/gorievm/go-gori/internal/service"
 	"github.com/gorievm/go-gori/log"
 	"github.com/gorievm/go-gori/node"
 	"github.com/gorievm/go-gori/rlp"
@@ -72,9 +73,21 @@ func StartNode(ctx *cli.Context, stack *node.Node, isConsole bool) {
 	if err := stack.Start(); err != nil {
 		Fatalf("Error starting protocol stack: %v", err)
 	}
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+
+	// Report readiness to the service manager, treating its stop requests as
+	// termination signals.
+	err := service.Ready(func() {
+		select {
+		case sigc <- syscall.SIGTERM:
+		default:
+		}
+	})
+	if err != nil {
+		log.Warn("Failed to notify service manager", "err", err)
+	}
 	go func() {
-		sigc := make(chan os.Signal, 1)
-		signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
 		defer signal.Stop(sigc)
 
 		minFreeDiskSpace := 2 * ethconfig.Defaults.TrieDirtyCache // Default 2 * 256Mb
@@ -89,6 +102,7 @@ func StartNode(ctx *cli.Context, stack *node.Node, isConsole bool) {
 
 		shutdown := func() {
 			log.Info("Got interrupt, shutting down...")
+			service.Stopping()
 			go stack.Close()
 			for i := 10; i > 0; i-- {
 				<-sigc
@@ -42,6 +42,8 @@ import (
 	"github.com/gorievm/go-gori/common/hexutil"
 	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/txpool"
+	"github.com/gorievm/go-gori/core/txpool/blobpool"
 	"github.com/gorievm/go-gori/core/txpool/legacypool"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/core/vm"
@@ -57,9 +59,11 @@ import (
 	"github.com/gorievm/go-gori/ethdb"
 	"github.com/gorievm/go-gori/ethdb/remotedb"
 	"github.com/gorievm/go-gori/ethstats"
+	"github.com/gorievm/go-gori/exporter"
 	"github.com/gorievm/go-gori/graphql"
 	"github.com/gorievm/go-gori/internal/ethapi"
 	"github.com/gorievm/go-gori/internal/flags"
+	"github.com/gorievm/go-gori/internal/gethcompat"
 	"github.com/gorievm/go-gori/les"
 	"github.com/gorievm/go-gori/log"
 	"github.com/gorievm/go-gori/metrics"
@@ -105,11 +109,48 @@ var (
 		Value:    node.DefaultConfig.DBEngine,
 		Category: flags.EthCategory,
 	}
+	DBSyncFlag = &cli.StringFlag{
+		Name:     "db.sync",
+		Usage:    "When to sync database writes to disk ('always', 'interval' or 'onclose'; relaxed policies can lose recent writes on machine crashes)",
+		Category: flags.EthCategory,
+	}
+	DBSyncIntervalFlag = &cli.DurationFlag{
+		Name:     "db.sync.interval",
+		Usage:    "Time between database syncs with the 'interval' sync policy",
+		Value:    ethdb.DefaultSyncInterval,
+		Category: flags.EthCategory,
+	}
+	DBGroupCommitFlag = &cli.DurationFlag{
+		Name:     "db.groupcommit",
+		Usage:    "Time to collect concurrent database writes into a single synced commit with the 'always' sync policy (0 = disabled)",
+		Category: flags.EthCategory,
+	}
 	AncientFlag = &flags.DirectoryFlag{
 		Name:     "datadir.ancient",
 		Usage:    "Root directory for ancient data (default = inside chaindata)",
 		Category: flags.EthCategory,
 	}
+	AncientZstdFlag = &cli.BoolFlag{
+		Name:     "datadir.ancient.zstd",
+		Usage:    "Compress newly created ancient data tables with zstd instead of snappy",
+		Category: flags.EthCategory,
+	}
+	AncientRemoteFlag = &cli.StringFlag{
+		Name:     "datadir.ancient.remote",
+		Usage:    "Path-style URL of an S3-compatible bucket serving published ancient chain data instead of the local freezer (credentials from AWS_* environment variables)",
+		Category: flags.EthCategory,
+	}
+	AncientRemoteCacheFlag = &cli.IntFlag{
+		Name:     "datadir.ancient.remote.cache",
+		Usage:    "Size in megabytes of the local cache of remote ancient chain data",
+		Value:    node.DefaultConfig.DBRemoteCache,
+		Category: flags.EthCategory,
+	}
+	AncientVolumeFlag = &cli.StringSliceFlag{
+		Name:     "datadir.ancient.volume",
+		Usage:    "Additional directory to place ancient data files on, as path[:limit in GB] (may be repeated, filled in order)",
+		Category: flags.EthCategory,
+	}
 	MinFreeDiskSpaceFlag = &flags.DirectoryFlag{
 		Name:     "datadir.minfreedisk",
 		Usage:    "Minimum free disk space in MB, once reached triggers auto shut down (default = --cache.gc converted to MB, 0 = disabled)",
@@ -125,6 +166,11 @@ var (
 		Usage:    "Enable monitoring and management of USB hardware wallets",
 		Category: flags.AccountCategory,
 	}
+	USBTokensFlag = &cli.StringFlag{
+		Name:     "usb.tokens",
+		Usage:    "Path to a JSON token list extending the built-in one for hardware wallet transfer previews",
+		Category: flags.AccountCategory,
+	}
 	SmartCardDaemonPathFlag = &cli.StringFlag{
 		Name:     "pcscdpath",
 		Usage:    "Path to the smartcard daemon (pcscd) socket file",
@@ -242,6 +288,32 @@ var (
 		Value:    ethconfig.Defaults.TxLookupLimit,
 		Category: flags.EthCategory,
 	}
+	LogHistoryFlag = &cli.Uint64Flag{
+		Name:     "history.logs",
+		Usage:    "Number of recent blocks to maintain log search index for (default = about one year, 0 = entire chain)",
+		Value:    ethconfig.Defaults.LogHistory,
+		Category: flags.EthCategory,
+	}
+	LogNoHistoryFlag = &cli.BoolFlag{
+		Name:     "history.logs.disable",
+		Usage:    "Do not maintain the log search index, keep serving log queries from the bloom bits index",
+		Category: flags.EthCategory,
+	}
+	AddressIndexFlag = &cli.BoolFlag{
+		Name:     "addressindex",
+		Usage:    "Enables indexing transactions by the addresses they are sent by or to",
+		Category: flags.EthCategory,
+	}
+	AddressIndexLimitFlag = &cli.Uint64Flag{
+		Name:     "addressindex.limit",
+		Usage:    "Number of recent blocks to maintain the address index for (0 = entire chain)",
+		Category: flags.EthCategory,
+	}
+	HistoryCutoffFlag = &cli.Uint64Flag{
+		Name:     "history.cutoff",
+		Usage:    "Block number below which block bodies and receipts are pruned from the ancient store (0 = keep entire history)",
+		Category: flags.EthCategory,
+	}
 	LightKDFFlag = &cli.BoolFlag{
 		Name:     "lightkdf",
 		Usage:    "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -268,6 +340,16 @@ var (
 		Usage:    "Manually specify the Verkle fork timestamp, overriding the bundled setting",
 		Category: flags.EthCategory,
 	}
+	OverrideScheduleFlag = &cli.StringFlag{
+		Name:     "override.schedule",
+		Usage:    "Signed fork override file watched for forks to activate without a binary upgrade",
+		Category: flags.EthCategory,
+	}
+	OverrideSignersFlag = &cli.StringFlag{
+		Name:     "override.signers",
+		Usage:    "Comma separated accounts trusted to sign fork override files",
+		Category: flags.EthCategory,
+	}
 	// Light server and client settings
 	LightServeFlag = &cli.IntFlag{
 		Name:     "light.serve",
@@ -314,6 +396,11 @@ var (
 		Usage:    "Disables price exemptions for locally submitted transactions",
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolPriorityFlag = &cli.StringFlag{
+		Name:     "txpool.priority",
+		Usage:    "Comma separated accounts exempt from the global pool limits and eviction",
+		Category: flags.TxPoolCategory,
+	}
 	TxPoolJournalFlag = &cli.StringFlag{
 		Name:     "txpool.journal",
 		Usage:    "Disk journal for local transaction to survive node restarts",
@@ -338,6 +425,16 @@ var (
 		Value:    ethconfig.Defaults.TxPool.PriceBump,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolPriceBumpsFlag = &cli.StringFlag{
+		Name:     "txpool.pricebumps",
+		Usage:    "Comma separated price bump percentages overriding --txpool.pricebump per transaction type (e.g. dynamicfee=15,setcode=20)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolStrictNonceFlag = &cli.BoolFlag{
+		Name:     "txpool.strictnonce",
+		Usage:    "Reject transaction replacements, keeping the first transaction seen for a nonce (applies to the blob pool too)",
+		Category: flags.TxPoolCategory,
+	}
 	TxPoolAccountSlotsFlag = &cli.Uint64Flag{
 		Name:     "txpool.accountslots",
 		Usage:    "Minimum number of executable transaction slots guaranteed per account",
@@ -368,6 +465,45 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Lifetime,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolTxLifetimeFlag = &cli.DurationFlag{
+		Name:     "txpool.txlifetime",
+		Usage:    "Maximum amount of time remote transactions are pooled, pending or queued (0 = unlimited)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolLocalTxLifetimeFlag = &cli.DurationFlag{
+		Name:     "txpool.localtxlifetime",
+		Usage:    "Maximum amount of time local transactions are pooled, pending or queued (0 = unlimited)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolOverflowFlag = &cli.StringFlag{
+		Name:     "txpool.overflow",
+		Usage:    "Directory to park future transactions not fitting into the pool in (disabled if empty)",
+		Value:    ethconfig.Defaults.TxPool.Overflow,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolOverflowCapFlag = &cli.Uint64Flag{
+		Name:     "txpool.overflowcap",
+		Usage:    "Disk space to allocate for parked future transactions in bytes",
+		Value:    ethconfig.Defaults.TxPool.OverflowCap,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolForensicsFlag = &cli.BoolFlag{
+		Name:     "txpool.forensics",
+		Usage:    "Snapshot the transaction pool to disk when anomalies are detected",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolForensicsDirFlag = &cli.StringFlag{
+		Name:     "txpool.forensics.dir",
+		Usage:    "Directory to store transaction pool snapshots in",
+		Value:    ethconfig.Defaults.TxPoolForensics.Dir,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolForensicsRetentionFlag = &cli.IntFlag{
+		Name:     "txpool.forensics.retention",
+		Usage:    "Number of most recent transaction pool snapshots to retain",
+		Value:    ethconfig.Defaults.TxPoolForensics.Retention,
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -434,6 +570,21 @@ var (
 		Category: flags.PerfCategory,
 		Value:    ethconfig.Defaults.FilterLogCacheSize,
 	}
+	WitnessHistoryFlag = &cli.IntFlag{
+		Name:     "witness.history",
+		Usage:    "Number of recent blocks to retain the execution witnesses of, served via debug_executionWitness (0 = disabled)",
+		Category: flags.MiscCategory,
+	}
+	SnapshotRateFlag = &cli.IntFlag{
+		Name:     "snapshot.rate",
+		Usage:    "Megabytes of snapshot data permitted to generate per second in the background (0 = unlimited)",
+		Category: flags.EthCategory,
+	}
+	StateHealFlag = &cli.BoolFlag{
+		Name:     "state.heal",
+		Usage:    "Repair trie nodes found missing or corrupt on read by retrieving them from snap peers",
+		Category: flags.EthCategory,
+	}
 	FDLimitFlag = &cli.IntFlag{
 		Name:     "fdlimit",
 		Usage:    "Raise the open file descriptor resource limit (default = system fd limit)",
@@ -518,6 +669,11 @@ var (
 		Usage:    "Record information useful for VM and contract debugging",
 		Category: flags.VMCategory,
 	}
+	VMParallelFlag = &cli.BoolFlag{
+		Name:     "vm.parallel",
+		Usage:    "Execute block transactions speculatively in parallel on import (experimental)",
+		Category: flags.VMCategory,
+	}
 
 	// API options.
 	RPCGlobalGasCapFlag = &cli.Uint64Flag{
@@ -532,12 +688,35 @@ var (
 		Value:    ethconfig.Defaults.RPCEVMTimeout,
 		Category: flags.APICategory,
 	}
+	RPCWarmQuotaFlag = &cli.Uint64Flag{
+		Name:     "rpc.warmquota",
+		Usage:    "Sets the number of accounts and storage slots per second that can be pre-warmed via gori_warm (0=disabled)",
+		Value:    ethconfig.Defaults.RPCWarmQuota,
+		Category: flags.APICategory,
+	}
+	RPCLogResultLimitFlag = &cli.IntFlag{
+		Name:     "rpc.getlogs.maxresults",
+		Usage:    "Sets the maximum number of logs returned by eth_getLogs, larger results need to be paginated via eth_getLogsPage (0=infinite)",
+		Value:    ethconfig.Defaults.RPCLogResultLimit,
+		Category: flags.APICategory,
+	}
+	RPCLogRangeLimitFlag = &cli.Uint64Flag{
+		Name:     "rpc.getlogs.maxblocks",
+		Usage:    "Sets the maximum number of blocks searched by eth_getLogs or a page of eth_getLogsPage (0=infinite)",
+		Value:    ethconfig.Defaults.RPCLogRangeLimit,
+		Category: flags.APICategory,
+	}
 	RPCGlobalTxFeeCapFlag = &cli.Float64Flag{
 		Name:     "rpc.txfeecap",
 		Usage:    "Sets a cap on transaction fee (in ether) that can be sent via the RPC APIs (0 = no cap)",
 		Value:    ethconfig.Defaults.RPCTxFeeCap,
 		Category: flags.APICategory,
 	}
+	RPCExtendedReceiptsFlag = &cli.BoolFlag{
+		Name:     "rpc.extendedreceipts",
+		Usage:    "Records the fee components of transactions (refund, access list savings, tip, blob fee) and includes them in RPC receipts",
+		Category: flags.APICategory,
+	}
 	// Authenticated RPC HTTP settings
 	AuthListenFlag = &cli.StringFlag{
 		Name:     "authrpc.addr",
@@ -562,6 +741,11 @@ var (
 		Usage:    "Path to a JWT secret to use for authenticated RPC endpoints",
 		Category: flags.APICategory,
 	}
+	AuthValidationFlag = &cli.BoolFlag{
+		Name:     "authrpc.validation",
+		Usage:    "Enable the payload validation API (validation namespace) on the authenticated RPC endpoint",
+		Category: flags.APICategory,
+	}
 
 	// Logging and debug settings
 	EthStatsURLFlag = &cli.StringFlag{
@@ -569,6 +753,27 @@ var (
 		Usage:    "Reporting URL of a ethstats service (nodename:secret@host:port)",
 		Category: flags.MetricsCategory,
 	}
+	ExporterURLFlag = &cli.StringFlag{
+		Name:     "exporter.url",
+		Usage:    "Broker endpoint to export chain events to (nats://host:port or kafka+http(s)://restproxy:port)",
+		Category: flags.MetricsCategory,
+	}
+	ExporterTopicFlag = &cli.StringFlag{
+		Name:     "exporter.topic",
+		Usage:    "Topic (Kafka) or subject (NATS) prefix of the exported chain events",
+		Value:    exporter.DefaultConfig.Topic,
+		Category: flags.MetricsCategory,
+	}
+	ExporterLogAddressesFlag = &cli.StringFlag{
+		Name:     "exporter.logs.addresses",
+		Usage:    "Comma separated contract addresses to export the logs of (default = all)",
+		Category: flags.MetricsCategory,
+	}
+	ExporterLogTopicsFlag = &cli.StringFlag{
+		Name:     "exporter.logs.topics",
+		Usage:    "Comma separated event signature hashes to export the logs of (default = all)",
+		Category: flags.MetricsCategory,
+	}
 	NoCompactionFlag = &cli.BoolFlag{
 		Name:     "nocompaction",
 		Usage:    "Disables db compaction after import",
@@ -576,12 +781,23 @@ var (
 	}
 
 	// MISC settings
+	ShutdownTimeoutFlag = &cli.DurationFlag{
+		Name:     "shutdown.timeout",
+		Usage:    "Maximum time a single service is given to stop before the shutdown moves on without it",
+		Value:    node.DefaultShutdownTimeout,
+		Category: flags.MiscCategory,
+	}
 	SyncTargetFlag = &cli.PathFlag{
 		Name:      "synctarget",
 		Usage:     `File for containing the hex-encoded block-rlp as sync target(dev feature)`,
 		TakesFile: true,
 		Category:  flags.MiscCategory,
 	}
+	SyncCheckpointFlag = &cli.StringFlag{
+		Name:     "synccheckpoint",
+		Usage:    `Trusted block to sync backward from without a consensus client, as "<number>:<hash>"`,
+		Category: flags.EthCategory,
+	}
 
 	// RPC settings
 	IPCDisabledFlag = &cli.BoolFlag{
@@ -635,6 +851,16 @@ var (
 		Value:    "",
 		Category: flags.APICategory,
 	}
+	GethCompatFlag = &cli.BoolFlag{
+		Name:     "rpc.gethcompat",
+		Usage:    "Enable the geth API compatibility layer (gethcompat namespace)",
+		Category: flags.APICategory,
+	}
+	GethCompatStrictFlag = &cli.BoolFlag{
+		Name:     "rpc.gethcompat.strict",
+		Usage:    "Report calls to geth methods unavailable on an endpoint as divergences (implies --rpc.gethcompat)",
+		Category: flags.APICategory,
+	}
 	GraphQLEnabledFlag = &cli.BoolFlag{
 		Name:     "graphql",
 		Usage:    "Enable GraphQL on the HTTP-RPC server. Note that GraphQL can only be started if an HTTP server is started as well.",
@@ -714,6 +940,18 @@ var (
 		Value:    node.DefaultConfig.BatchResponseMaxSize,
 		Category: flags.APICategory,
 	}
+	RPCSpoolThresholdFlag = &cli.IntFlag{
+		Name:     "rpc.spool-threshold",
+		Usage:    "Size in bytes above which log and trace results are spooled to disk and streamed (0 = disabled)",
+		Value:    node.DefaultConfig.RPCSpoolThreshold,
+		Category: flags.APICategory,
+	}
+	RPCSpoolQuotaFlag = &cli.IntFlag{
+		Name:     "rpc.spool-quota",
+		Usage:    "Maximum number of bytes of spooled results kept on disk",
+		Value:    node.DefaultConfig.RPCSpoolQuota,
+		Category: flags.APICategory,
+	}
 	EnablePersonal = &cli.BoolFlag{
 		Name:     "rpc.enabledeprecatedpersonal",
 		Usage:    "Enables the (deprecated) personal namespace",
@@ -784,6 +1022,11 @@ var (
 		Usage:    "Restricts network communication to the given IP networks (CIDR masks)",
 		Category: flags.NetworkingCategory,
 	}
+	P2PCaptureFlag = &cli.StringFlag{
+		Name:     "p2p.capture",
+		Usage:    "Records all messages exchanged with peers into rotating capture files in the given directory (debugging)",
+		Category: flags.NetworkingCategory,
+	}
 	DNSDiscoveryFlag = &cli.StringFlag{
 		Name:     "discovery.dns",
 		Usage:    "Sets DNS discovery entry points (use \"\" to disable DNS)",
@@ -945,8 +1188,15 @@ var (
 	DatabasePathFlags = []cli.Flag{
 		DataDirFlag,
 		AncientFlag,
+		AncientZstdFlag,
+		AncientVolumeFlag,
+		AncientRemoteFlag,
+		AncientRemoteCacheFlag,
 		RemoteDBFlag,
 		HttpHeaderFlag,
+		DBSyncFlag,
+		DBSyncIntervalFlag,
+		DBGroupCommitFlag,
 	}
 )
 
@@ -1147,6 +1397,13 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BatchResponseMaxSize.Name) {
 		cfg.BatchResponseMaxSize = ctx.Int(BatchResponseMaxSize.Name)
 	}
+
+	if ctx.IsSet(RPCSpoolThresholdFlag.Name) {
+		cfg.RPCSpoolThreshold = ctx.Int(RPCSpoolThresholdFlag.Name)
+	}
+	if ctx.IsSet(RPCSpoolQuotaFlag.Name) {
+		cfg.RPCSpoolQuota = ctx.Int(RPCSpoolQuotaFlag.Name)
+	}
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1373,6 +1630,9 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		}
 		cfg.NetRestrict = list
 	}
+	if ctx.IsSet(P2PCaptureFlag.Name) {
+		cfg.CaptureDir = ctx.String(P2PCaptureFlag.Name)
+	}
 
 	if ctx.Bool(DeveloperFlag.Name) {
 		// --dev mode can't use p2p networking.
@@ -1422,9 +1682,15 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(USBFlag.Name) {
 		cfg.USB = ctx.Bool(USBFlag.Name)
 	}
+	if ctx.IsSet(USBTokensFlag.Name) {
+		cfg.USBTokens = ctx.String(USBTokensFlag.Name)
+	}
 	if ctx.IsSet(InsecureUnlockAllowedFlag.Name) {
 		cfg.InsecureUnlockAllowed = ctx.Bool(InsecureUnlockAllowedFlag.Name)
 	}
+	if ctx.IsSet(ShutdownTimeoutFlag.Name) {
+		cfg.ShutdownTimeout = ctx.Duration(ShutdownTimeoutFlag.Name)
+	}
 	if ctx.IsSet(DBEngineFlag.Name) {
 		dbEngine := ctx.String(DBEngineFlag.Name)
 		if dbEngine != "leveldb" && dbEngine != "pebble" {
@@ -1433,6 +1699,53 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		log.Info(fmt.Sprintf("Using %s as db engine", dbEngine))
 		cfg.DBEngine = dbEngine
 	}
+	if ctx.IsSet(DBSyncFlag.Name) {
+		cfg.DBWrites.Sync = ethdb.SyncPolicy(ctx.String(DBSyncFlag.Name))
+	}
+	if ctx.IsSet(DBSyncIntervalFlag.Name) {
+		cfg.DBWrites.SyncInterval = ctx.Duration(DBSyncIntervalFlag.Name)
+	}
+	if ctx.IsSet(DBGroupCommitFlag.Name) {
+		cfg.DBWrites.GroupCommit = ctx.Duration(DBGroupCommitFlag.Name)
+	}
+	if err := cfg.DBWrites.Validate(); err != nil {
+		Fatalf("Invalid database write settings: %v", err)
+	}
+	if ctx.IsSet(AncientZstdFlag.Name) {
+		cfg.DBFreezer.Zstd = ctx.Bool(AncientZstdFlag.Name)
+	}
+	if ctx.IsSet(AncientVolumeFlag.Name) {
+		cfg.DBFreezer.Volumes = nil
+		for _, spec := range ctx.StringSlice(AncientVolumeFlag.Name) {
+			volume, err := parseFreezerVolume(spec)
+			if err != nil {
+				Fatalf("Invalid ancient volume %q: %v", spec, err)
+			}
+			cfg.DBFreezer.Volumes = append(cfg.DBFreezer.Volumes, volume)
+		}
+	}
+	if ctx.IsSet(AncientRemoteFlag.Name) {
+		cfg.DBRemoteAncients = ctx.String(AncientRemoteFlag.Name)
+	}
+	if ctx.IsSet(AncientRemoteCacheFlag.Name) {
+		cfg.DBRemoteCache = ctx.Int(AncientRemoteCacheFlag.Name)
+	}
+}
+
+// parseFreezerVolume parses an ancient volume given as path[:limit], with the
+// limit in gigabytes.
+func parseFreezerVolume(spec string) (rawdb.FreezerVolume, error) {
+	volume := rawdb.FreezerVolume{Path: spec}
+	if i := strings.LastIndex(spec, ":"); i >= 0 {
+		// Only treat numeric suffixes as limit, paths may contain colons too
+		if limit, err := strconv.ParseUint(spec[i+1:], 10, 64); err == nil {
+			volume.Path, volume.Limit = spec[:i], limit*1024*1024*1024
+		}
+	}
+	if volume.Path == "" {
+		return volume, errors.New("empty path")
+	}
+	return volume, nil
 }
 
 func setSmartCard(ctx *cli.Context, cfg *node.Config) {
@@ -1502,6 +1815,16 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolNoLocalsFlag.Name) {
 		cfg.NoLocals = ctx.Bool(TxPoolNoLocalsFlag.Name)
 	}
+	if ctx.IsSet(TxPoolPriorityFlag.Name) {
+		priority := strings.Split(ctx.String(TxPoolPriorityFlag.Name), ",")
+		for _, account := range priority {
+			if trimmed := strings.TrimSpace(account); !common.IsHexAddress(trimmed) {
+				Fatalf("Invalid account in --txpool.priority: %s", trimmed)
+			} else {
+				cfg.Priority = append(cfg.Priority, common.HexToAddress(trimmed))
+			}
+		}
+	}
 	if ctx.IsSet(TxPoolJournalFlag.Name) {
 		cfg.Journal = ctx.String(TxPoolJournalFlag.Name)
 	}
@@ -1514,6 +1837,27 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolPriceBumpFlag.Name) {
 		cfg.PriceBump = ctx.Uint64(TxPoolPriceBumpFlag.Name)
 	}
+	if ctx.IsSet(TxPoolPriceBumpsFlag.Name) {
+		cfg.PriceBumps = make(map[byte]uint64)
+		for _, spec := range strings.Split(ctx.String(TxPoolPriceBumpsFlag.Name), ",") {
+			name, value, ok := strings.Cut(strings.TrimSpace(spec), "=")
+			if !ok {
+				Fatalf("Invalid price bump in --%s: %q, want type=percentage", TxPoolPriceBumpsFlag.Name, spec)
+			}
+			typ, ok := txTypes[name]
+			if !ok {
+				Fatalf("Unknown transaction type in --%s: %q", TxPoolPriceBumpsFlag.Name, name)
+			}
+			bump, err := strconv.ParseUint(value, 10, 64)
+			if err != nil || bump < 1 {
+				Fatalf("Invalid price bump in --%s: %q", TxPoolPriceBumpsFlag.Name, value)
+			}
+			cfg.PriceBumps[typ] = bump
+		}
+	}
+	if ctx.IsSet(TxPoolStrictNonceFlag.Name) {
+		cfg.StrictNonce = ctx.Bool(TxPoolStrictNonceFlag.Name)
+	}
 	if ctx.IsSet(TxPoolAccountSlotsFlag.Name) {
 		cfg.AccountSlots = ctx.Uint64(TxPoolAccountSlotsFlag.Name)
 	}
@@ -1529,6 +1873,54 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.Duration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.IsSet(TxPoolTxLifetimeFlag.Name) {
+		cfg.TxLifetime = ctx.Duration(TxPoolTxLifetimeFlag.Name)
+	}
+	if ctx.IsSet(TxPoolLocalTxLifetimeFlag.Name) {
+		cfg.LocalTxLifetime = ctx.Duration(TxPoolLocalTxLifetimeFlag.Name)
+	}
+	if ctx.IsSet(TxPoolOverflowFlag.Name) {
+		cfg.Overflow = ctx.String(TxPoolOverflowFlag.Name)
+	}
+	if ctx.IsSet(TxPoolOverflowCapFlag.Name) {
+		cfg.OverflowCap = ctx.Uint64(TxPoolOverflowCapFlag.Name)
+	}
+}
+
+// txTypes maps the transaction type names accepted by --txpool.pricebumps to
+// their type identifiers. Blob transactions are configured via the blob pool.
+var txTypes = map[string]byte{
+	"legacy":     types.LegacyTxType,
+	"accesslist": types.AccessListTxType,
+	"dynamicfee": types.DynamicFeeTxType,
+	"setcode":    types.SetCodeTxType,
+}
+
+func setBlobPool(ctx *cli.Context, cfg *blobpool.Config) {
+	if ctx.IsSet(BlobPoolDataDirFlag.Name) {
+		cfg.Datadir = ctx.String(BlobPoolDataDirFlag.Name)
+	}
+	if ctx.IsSet(BlobPoolDataCapFlag.Name) {
+		cfg.Datacap = ctx.Uint64(BlobPoolDataCapFlag.Name)
+	}
+	if ctx.IsSet(BlobPoolPriceBumpFlag.Name) {
+		cfg.PriceBump = ctx.Uint64(BlobPoolPriceBumpFlag.Name)
+	}
+	if ctx.IsSet(TxPoolStrictNonceFlag.Name) {
+		cfg.StrictNonce = ctx.Bool(TxPoolStrictNonceFlag.Name)
+	}
+}
+
+func setTxPoolForensics(ctx *cli.Context, cfg *txpool.ForensicsConfig) {
+	if ctx.IsSet(TxPoolForensicsFlag.Name) {
+		cfg.Enabled = ctx.Bool(TxPoolForensicsFlag.Name)
+	}
+	if ctx.IsSet(TxPoolForensicsDirFlag.Name) {
+		cfg.Dir = ctx.String(TxPoolForensicsDirFlag.Name)
+	}
+	if ctx.IsSet(TxPoolForensicsRetentionFlag.Name) {
+		cfg.Retention = ctx.Int(TxPoolForensicsRetentionFlag.Name)
+	}
 }
 
 func setMiner(ctx *cli.Context, cfg *miner.Config) {
@@ -1618,6 +2010,36 @@ func CheckExclusive(ctx *cli.Context, args ...interface{}) {
 	}
 }
 
+// SetExporterConfig applies chain event exporter related command line flags to
+// the config.
+func SetExporterConfig(ctx *cli.Context, cfg *exporter.Config) {
+	if ctx.IsSet(ExporterURLFlag.Name) {
+		cfg.URL = ctx.String(ExporterURLFlag.Name)
+	}
+	if ctx.IsSet(ExporterTopicFlag.Name) {
+		cfg.Topic = ctx.String(ExporterTopicFlag.Name)
+	}
+	if ctx.IsSet(ExporterLogAddressesFlag.Name) {
+		cfg.LogAddresses = nil
+		for _, addr := range SplitAndTrim(ctx.String(ExporterLogAddressesFlag.Name)) {
+			if !common.IsHexAddress(addr) {
+				Fatalf("Invalid exporter log address %q", addr)
+			}
+			cfg.LogAddresses = append(cfg.LogAddresses, common.HexToAddress(addr))
+		}
+	}
+	if ctx.IsSet(ExporterLogTopicsFlag.Name) {
+		cfg.LogTopics = nil
+		for _, topic := range SplitAndTrim(ctx.String(ExporterLogTopicsFlag.Name)) {
+			hash := common.FromHex(topic)
+			if len(hash) != common.HashLength {
+				Fatalf("Invalid exporter log topic %q", topic)
+			}
+			cfg.LogTopics = append(cfg.LogTopics, common.BytesToHash(hash))
+		}
+	}
+}
+
 // SetEthConfig applies eth-related command line flags to the config.
 func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	// Avoid conflicting network flags
@@ -1634,6 +2056,8 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setEtherbase(ctx, cfg)
 	setGPO(ctx, &cfg.GPO, ctx.String(SyncModeFlag.Name) == "light")
 	setTxPool(ctx, &cfg.TxPool)
+	setBlobPool(ctx, &cfg.BlobPool)
+	setTxPoolForensics(ctx, &cfg.TxPoolForensics)
 	setMiner(ctx, &cfg.Miner)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
@@ -1661,6 +2085,13 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(SyncModeFlag.Name) {
 		cfg.SyncMode = *flags.GlobalTextMarshaler(ctx, SyncModeFlag.Name).(*downloader.SyncMode)
 	}
+	if ctx.IsSet(SyncCheckpointFlag.Name) {
+		checkpoint := new(downloader.Checkpoint)
+		if err := checkpoint.UnmarshalText([]byte(ctx.String(SyncCheckpointFlag.Name))); err != nil {
+			Fatalf("Invalid sync checkpoint: %v", err)
+		}
+		cfg.SyncCheckpoint = checkpoint
+	}
 	if ctx.IsSet(NetworkIdFlag.Name) {
 		cfg.NetworkId = ctx.Uint64(NetworkIdFlag.Name)
 	}
@@ -1690,6 +2121,21 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(TxLookupLimitFlag.Name) {
 		cfg.TxLookupLimit = ctx.Uint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.IsSet(LogHistoryFlag.Name) {
+		cfg.LogHistory = ctx.Uint64(LogHistoryFlag.Name)
+	}
+	if ctx.IsSet(LogNoHistoryFlag.Name) {
+		cfg.LogNoHistory = ctx.Bool(LogNoHistoryFlag.Name)
+	}
+	if ctx.IsSet(AddressIndexFlag.Name) {
+		cfg.AddressIndex = ctx.Bool(AddressIndexFlag.Name)
+	}
+	if ctx.IsSet(AddressIndexLimitFlag.Name) {
+		cfg.AddressIndexLimit = ctx.Uint64(AddressIndexLimitFlag.Name)
+	}
+	if ctx.IsSet(HistoryCutoffFlag.Name) {
+		cfg.HistoryCutoff = ctx.Uint64(HistoryCutoffFlag.Name)
+	}
 	if ctx.IsSet(CacheFlag.Name) || ctx.IsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.Int(CacheFlag.Name) * ctx.Int(CacheTrieFlag.Name) / 100
 	}
@@ -1702,6 +2148,15 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(CacheLogSizeFlag.Name) {
 		cfg.FilterLogCacheSize = ctx.Int(CacheLogSizeFlag.Name)
 	}
+	if ctx.IsSet(WitnessHistoryFlag.Name) {
+		cfg.WitnessHistory = ctx.Int(WitnessHistoryFlag.Name)
+	}
+	if ctx.IsSet(SnapshotRateFlag.Name) {
+		cfg.SnapshotRate = ctx.Int(SnapshotRateFlag.Name)
+	}
+	if ctx.IsSet(StateHealFlag.Name) {
+		cfg.StateHeal = ctx.Bool(StateHealFlag.Name)
+	}
 	if !ctx.Bool(SnapshotFlag.Name) {
 		// If snap-sync is requested, this flag is also required
 		if cfg.SyncMode == downloader.SnapSync {
@@ -1718,6 +2173,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.Bool(VMEnableDebugFlag.Name)
 	}
+	if ctx.IsSet(VMParallelFlag.Name) {
+		cfg.ParallelExecution = ctx.Bool(VMParallelFlag.Name)
+	}
 
 	if ctx.IsSet(RPCGlobalGasCapFlag.Name) {
 		cfg.RPCGasCap = ctx.Uint64(RPCGlobalGasCapFlag.Name)
@@ -1730,9 +2188,33 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalEVMTimeoutFlag.Name) {
 		cfg.RPCEVMTimeout = ctx.Duration(RPCGlobalEVMTimeoutFlag.Name)
 	}
+	if ctx.IsSet(RPCWarmQuotaFlag.Name) {
+		cfg.RPCWarmQuota = ctx.Uint64(RPCWarmQuotaFlag.Name)
+	}
+	if ctx.IsSet(RPCLogResultLimitFlag.Name) {
+		cfg.RPCLogResultLimit = ctx.Int(RPCLogResultLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCLogRangeLimitFlag.Name) {
+		cfg.RPCLogRangeLimit = ctx.Uint64(RPCLogRangeLimitFlag.Name)
+	}
 	if ctx.IsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.Float64(RPCGlobalTxFeeCapFlag.Name)
 	}
+	if ctx.IsSet(RPCExtendedReceiptsFlag.Name) {
+		cfg.RPCExtendedReceipts = ctx.Bool(RPCExtendedReceiptsFlag.Name)
+	}
+	if ctx.IsSet(OverrideScheduleFlag.Name) {
+		cfg.ForkOverrideFile = ctx.String(OverrideScheduleFlag.Name)
+	}
+	if ctx.IsSet(OverrideSignersFlag.Name) {
+		for _, account := range strings.Split(ctx.String(OverrideSignersFlag.Name), ",") {
+			if trimmed := strings.TrimSpace(account); !common.IsHexAddress(trimmed) {
+				Fatalf("Invalid account in --override.signers: %s", trimmed)
+			} else {
+				cfg.ForkOverrideSigners = append(cfg.ForkOverrideSigners, common.HexToAddress(trimmed))
+			}
+		}
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.EthDiscoveryURLs, cfg.SnapDiscoveryURLs = []string{}, []string{}
 	} else if ctx.IsSet(DNSDiscoveryFlag.Name) {
@@ -1871,7 +2353,7 @@ func RegisterEthService(stack *node.Node, cfg *ethconfig.Config) (ethapi.Backend
 		if err != nil {
 			Fatalf("Failed to register the Ori service: %v", err)
 		}
-		stack.RegisterAPIs(tracers.APIs(backend.ApiBackend))
+		stack.RegisterAPIs(tracers.APIs(backend.ApiBackend, stack.RPCSpool()))
 		return backend.ApiBackend, nil
 	}
 	backend, err := eth.New(stack, cfg)
@@ -1884,7 +2366,7 @@ func RegisterEthService(stack *node.Node, cfg *ethconfig.Config) (ethapi.Backend
 			Fatalf("Failed to create the LES server: %v", err)
 		}
 	}
-	stack.RegisterAPIs(tracers.APIs(backend.APIBackend))
+	stack.RegisterAPIs(tracers.APIs(backend.APIBackend, stack.RPCSpool()))
 	return backend.APIBackend, backend
 }
 
@@ -1895,6 +2377,13 @@ func RegisterEthStatsService(stack *node.Node, backend ethapi.Backend, url strin
 	}
 }
 
+// RegisterExporterService configures the chain event exporter and adds it to the node.
+func RegisterExporterService(stack *node.Node, backend ethapi.Backend, cfg *exporter.Config) {
+	if err := exporter.New(stack, backend, cfg); err != nil {
+		Fatalf("Failed to register the chain event exporter: %v", err)
+	}
+}
+
 // RegisterGraphQLService adds the GraphQL API to the node.
 func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSystem *filters.FilterSystem, cfg *node.Config) {
 	err := graphql.New(stack, backend, filterSystem, cfg.GraphQLCors, cfg.GraphQLVirtualHosts)
@@ -1903,11 +2392,22 @@ func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSyst
 	}
 }
 
+// RegisterGethCompat adds the geth API compatibility layer to the node if requested.
+func RegisterGethCompat(ctx *cli.Context, stack *node.Node) {
+	if !ctx.Bool(GethCompatFlag.Name) && !ctx.Bool(GethCompatStrictFlag.Name) {
+		return
+	}
+	gethcompat.Register(stack, ctx.Bool(GethCompatStrictFlag.Name))
+}
+
 // RegisterFilterAPI adds the eth log filtering RPC API to the node.
 func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconfig.Config) *filters.FilterSystem {
 	isLightClient := ethcfg.SyncMode == downloader.LightSync
 	filterSystem := filters.NewFilterSystem(backend, filters.Config{
-		LogCacheSize: ethcfg.FilterLogCacheSize,
+		LogCacheSize:   ethcfg.FilterLogCacheSize,
+		LogResultLimit: ethcfg.RPCLogResultLimit,
+		LogRangeLimit:  ethcfg.RPCLogRangeLimit,
+		Spool:          stack.RPCSpool(),
 	})
 	stack.RegisterAPIs([]rpc.API{{
 		Namespace: "eth",
@@ -0,0 +1,198 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/internal/flags"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/node"
+	"github.com/gorievm/go-gori/params"
+	"github.com/urfave/cli/v2"
+)
+
+// profileMarker is the name of the file within the data directory recording the
+// profile and chain the directory was last used with.
+const profileMarker = "PROFILE"
+
+// ProfileFlag selects a named profile from the config file.
+var ProfileFlag = &cli.StringFlag{
+	Name:     "profile",
+	Usage:    "Named profile from the config file to run with (own datadir, ports, bootnodes and network)",
+	Category: flags.EthCategory,
+}
+
+// Profile is a named set of settings for running a node on a particular chain,
+// allowing to keep the data of multiple chains apart and to switch between them
+// with a single flag. Settings explicitly set via flags take precedence.
+type Profile struct {
+	Network   string      `toml:",omitempty"` // Built-in network (mainnet, goerli, sepolia), empty for custom chains
+	NetworkId uint64      `toml:",omitempty"` // Network identifier of custom chains
+	DataDir   string      // Data directory of the profile
+	Port      int         `toml:",omitempty"` // Network listening port
+	HTTPPort  int         `toml:",omitempty"` // HTTP-RPC server listening port
+	WSPort    int         `toml:",omitempty"` // WS-RPC server listening port
+	AuthPort  int         `toml:",omitempty"` // Authenticated RPC server listening port
+	Bootnodes []string    `toml:",omitempty"` // Bootstrap nodes of the network
+	Genesis   common.Hash `toml:",omitempty"` // Expected genesis hash, required for custom chains to be guarded
+}
+
+// profileNetworks maps the built-in networks selectable by profiles to their
+// flags and genesis hashes.
+var profileNetworks = map[string]struct {
+	flag    cli.Flag
+	genesis common.Hash
+}{
+	"mainnet": {MainnetFlag, params.MainnetGenesisHash},
+	"goerli":  {GoerliFlag, params.GoerliGenesisHash},
+	"sepolia": {SepoliaFlag, params.SepoliaGenesisHash},
+}
+
+// genesis returns the genesis hash expected by the profile, or the zero hash if
+// none is known.
+func (p *Profile) genesis() common.Hash {
+	if p.Genesis != (common.Hash{}) {
+		return p.Genesis
+	}
+	if network, ok := profileNetworks[p.Network]; ok {
+		return network.genesis
+	}
+	return common.Hash{}
+}
+
+// profileRecord is the content of the profile marker within a data directory.
+type profileRecord struct {
+	Name    string      `json:"name"`
+	Genesis common.Hash `json:"genesis"`
+}
+
+// ApplyProfile applies the settings of a profile as if they were set via their
+// command line flags, unless those flags are explicitly set. It needs to run
+// before any of the affected flags are consumed.
+func ApplyProfile(ctx *cli.Context, name string, profile *Profile) error {
+	if profile.DataDir == "" {
+		return fmt.Errorf("profile %q: missing data directory", name)
+	}
+	if profile.Network != "" {
+		network, ok := profileNetworks[profile.Network]
+		if !ok {
+			return fmt.Errorf("profile %q: unknown network %q", name, profile.Network)
+		}
+		for _, flag := range NetworkFlags {
+			if flag != network.flag && ctx.Bool(flag.Names()[0]) {
+				return fmt.Errorf("profile %q: network %q conflicts with --%s", name, profile.Network, flag.Names()[0])
+			}
+		}
+		setProfileFlag(ctx, network.flag, "true")
+	}
+	if profile.Genesis != (common.Hash{}) && profile.Network != "" && profile.Genesis != profileNetworks[profile.Network].genesis {
+		return fmt.Errorf("profile %q: genesis %x doesn't belong to network %q", name, profile.Genesis, profile.Network)
+	}
+	setProfileFlag(ctx, DataDirFlag, profile.DataDir)
+	if profile.NetworkId != 0 {
+		setProfileFlag(ctx, NetworkIdFlag, strconv.FormatUint(profile.NetworkId, 10))
+	}
+	if profile.Port != 0 {
+		setProfileFlag(ctx, ListenPortFlag, strconv.Itoa(profile.Port))
+	}
+	if profile.HTTPPort != 0 {
+		setProfileFlag(ctx, HTTPPortFlag, strconv.Itoa(profile.HTTPPort))
+	}
+	if profile.WSPort != 0 {
+		setProfileFlag(ctx, WSPortFlag, strconv.Itoa(profile.WSPort))
+	}
+	if profile.AuthPort != 0 {
+		setProfileFlag(ctx, AuthPortFlag, strconv.Itoa(profile.AuthPort))
+	}
+	if len(profile.Bootnodes) > 0 {
+		setProfileFlag(ctx, BootnodesFlag, strings.Join(profile.Bootnodes, ","))
+	}
+	// Refuse to run if the data directory was last used with another chain, no
+	// need to even open the database for that
+	record, err := readProfileRecord(filepath.Join(ctx.String(DataDirFlag.Name), profileMarker))
+	if err != nil {
+		return err
+	}
+	if record != nil {
+		if want := profile.genesis(); want != (common.Hash{}) && record.Genesis != want {
+			return fmt.Errorf("profile %q: data directory %s holds chain %x of profile %q, want %x", name, ctx.String(DataDirFlag.Name), record.Genesis, record.Name, want)
+		}
+	}
+	log.Info("Using configuration profile", "name", name, "datadir", ctx.String(DataDirFlag.Name))
+	return nil
+}
+
+// setProfileFlag sets a flag to the value configured by a profile, unless it was
+// explicitly set by the user. Flags not supported by the current command are
+// silently ignored.
+func setProfileFlag(ctx *cli.Context, flag cli.Flag, value string) {
+	if ctx.IsSet(flag.Names()[0]) {
+		return
+	}
+	ctx.Set(flag.Names()[0], value)
+}
+
+// CheckProfileGenesis ensures that the chain database opened for a profile holds
+// the chain expected by it, and records the chain in the data directory to guard
+// against other profiles opening it later.
+func CheckProfileGenesis(stack *node.Node, name string, profile *Profile, db ethdb.Database) {
+	have := rawdb.ReadCanonicalHash(db, 0)
+	if want := profile.genesis(); want != (common.Hash{}) && have != want {
+		Fatalf("Profile %q: data directory %s holds chain with genesis %x, want %x (initialize custom chains with the init command first)", name, stack.DataDir(), have, want)
+	}
+	path := filepath.Join(stack.DataDir(), profileMarker)
+	record, err := readProfileRecord(path)
+	if err != nil {
+		Fatalf("Profile %q: %v", name, err)
+	}
+	if record != nil && record.Genesis != have {
+		Fatalf("Profile %q: data directory %s was used with chain %x of profile %q, now holds %x", name, stack.DataDir(), record.Genesis, record.Name, have)
+	}
+	if record == nil || record.Name != name {
+		blob, _ := json.Marshal(&profileRecord{Name: name, Genesis: have})
+		if err := os.WriteFile(path, blob, 0600); err != nil {
+			log.Warn("Failed to record profile in data directory", "path", path, "err", err)
+		}
+	}
+}
+
+// readProfileRecord reads the profile marker of a data directory, returning nil
+// if there's none yet.
+func readProfileRecord(path string) (*profileRecord, error) {
+	blob, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	record := new(profileRecord)
+	if err := json.Unmarshal(blob, record); err != nil {
+		return nil, fmt.Errorf("invalid profile marker %s: %v", path, err)
+	}
+	return record, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorievm/go-gori/params"
+	"github.com/urfave/cli/v2"
+)
+
+// runWithProfile applies a profile on top of the given command line arguments,
+// returning the resulting context for inspection.
+func runWithProfile(t *testing.T, profile *Profile, args ...string) (*cli.Context, error) {
+	t.Helper()
+
+	var (
+		result *cli.Context
+		err    error
+	)
+	app := &cli.App{
+		Flags: []cli.Flag{DataDirFlag, MainnetFlag, GoerliFlag, SepoliaFlag, NetworkIdFlag, ListenPortFlag, HTTPPortFlag, WSPortFlag, AuthPortFlag, BootnodesFlag},
+		Action: func(ctx *cli.Context) error {
+			result, err = ctx, ApplyProfile(ctx, "test", profile)
+			return nil
+		},
+	}
+	if err := app.Run(append([]string{"gori"}, args...)); err != nil {
+		t.Fatalf("failed to run app: %v", err)
+	}
+	return result, err
+}
+
+// Tests that profiles are applied as flag defaults, not overriding flags set
+// explicitly on the command line.
+func TestProfileApply(t *testing.T) {
+	profile := &Profile{
+		Network:   "sepolia",
+		DataDir:   t.TempDir(),
+		Port:      30304,
+		HTTPPort:  8546,
+		Bootnodes: []string{"enode://a", "enode://b"},
+	}
+	ctx, err := runWithProfile(t, profile, "--http.port", "9000")
+	if err != nil {
+		t.Fatalf("failed to apply profile: %v", err)
+	}
+	if !ctx.Bool(SepoliaFlag.Name) {
+		t.Errorf("network not selected")
+	}
+	if have := ctx.String(DataDirFlag.Name); have != profile.DataDir {
+		t.Errorf("datadir mismatch: have %s, want %s", have, profile.DataDir)
+	}
+	if have := ctx.Int(ListenPortFlag.Name); have != 30304 {
+		t.Errorf("port mismatch: have %d, want %d", have, 30304)
+	}
+	if have := ctx.Int(HTTPPortFlag.Name); have != 9000 {
+		t.Errorf("explicit http port overridden: have %d, want %d", have, 9000)
+	}
+	if have := ctx.String(BootnodesFlag.Name); have != "enode://a,enode://b" {
+		t.Errorf("bootnodes mismatch: have %s", have)
+	}
+	// Conflicting networks and inconsistent profiles should be rejected
+	if _, err := runWithProfile(t, profile, "--goerli"); err == nil || !strings.Contains(err.Error(), "conflicts") {
+		t.Errorf("conflicting network error mismatch: have %v", err)
+	}
+	if _, err := runWithProfile(t, &Profile{Network: "sepolia"}); err == nil {
+		t.Errorf("profile without datadir accepted")
+	}
+	if _, err := runWithProfile(t, &Profile{Network: "sepolia", DataDir: t.TempDir(), Genesis: params.MainnetGenesisHash}); err == nil {
+		t.Errorf("profile with foreign genesis accepted")
+	}
+}
+
+// Tests that profiles refuse to use a data directory last used with another
+// chain.
+func TestProfileDatadirGuard(t *testing.T) {
+	datadir := t.TempDir()
+
+	blob, _ := json.Marshal(&profileRecord{Name: "main", Genesis: params.MainnetGenesisHash})
+	if err := os.WriteFile(filepath.Join(datadir, profileMarker), blob, 0600); err != nil {
+		t.Fatalf("failed to write profile marker: %v", err)
+	}
+	if _, err := runWithProfile(t, &Profile{Network: "mainnet", DataDir: datadir}); err != nil {
+		t.Errorf("failed to apply profile of the same chain: %v", err)
+	}
+	if _, err := runWithProfile(t, &Profile{Network: "sepolia", DataDir: datadir}); err == nil || !strings.Contains(err.Error(), `profile "main"`) {
+		t.Errorf("foreign chain error mismatch: have %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package compiler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gorievm/go-gori/crypto"
+)
+
+// ErrNotArtifact is returned if a JSON file does not look like a contract
+// artifact, e.g. a Hardhat debug file or a Foundry build-info file.
+var ErrNotArtifact = errors.New("not a contract artifact")
+
+// linkReferences maps source files to library names to the positions of their
+// address placeholders within the bytecode.
+type linkReferences map[string]map[string][]struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// foundryBytecode is the bytecode section of a Foundry artifact.
+type foundryBytecode struct {
+	Object         string         `json:"object"`
+	LinkReferences linkReferences `json:"linkReferences"`
+}
+
+// artifact is the union of the Foundry (out/<Source>.sol/<Name>.json) and the
+// Hardhat (hh-sol-artifact-1) contract artifact formats.
+type artifact struct {
+	Abi json.RawMessage `json:"abi"`
+
+	// Hardhat specific fields
+	ContractName           string          `json:"contractName"`
+	SourceName             string          `json:"sourceName"`
+	LinkReferences         linkReferences  `json:"linkReferences"`
+	DeployedLinkReferences linkReferences  `json:"deployedLinkReferences"`
+	Bytecode               json.RawMessage `json:"bytecode"`
+	DeployedBytecode       json.RawMessage `json:"deployedBytecode"`
+
+	// Foundry specific fields
+	MethodIdentifiers map[string]string `json:"methodIdentifiers"`
+	Metadata          json.RawMessage   `json:"metadata"`
+}
+
+// ParseArtifact parses a Foundry or Hardhat contract artifact into a Contract,
+// returning it along with its fully qualified name <solFilePath>:<type>. If the
+// artifact does not carry its name (older Foundry versions without metadata),
+// the given fallback type name is used.
+//
+// Library placeholders in the bytecode are normalized to the __$<hash>$__ format
+// derived from the fully qualified library name, irrespective of the format the
+// compiler used, based on the link references of the artifact.
+func ParseArtifact(data []byte, fallback string) (string, *Contract, error) {
+	var art artifact
+	if err := json.Unmarshal(data, &art); err != nil {
+		return "", nil, err
+	}
+	if len(art.Abi) == 0 || len(art.Bytecode) == 0 {
+		return "", nil, ErrNotArtifact
+	}
+	var abi interface{}
+	if err := json.Unmarshal(art.Abi, &abi); err != nil {
+		return "", nil, fmt.Errorf("invalid abi definition: %v", err)
+	}
+	// Extract the bytecodes in either the Hardhat (plain string) or the Foundry
+	// (object with embedded link references) format
+	code, links, err := parseArtifactBytecode(art.Bytecode, art.LinkReferences)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid bytecode: %v", err)
+	}
+	var runtime string
+	if len(art.DeployedBytecode) > 0 {
+		if runtime, _, err = parseArtifactBytecode(art.DeployedBytecode, art.DeployedLinkReferences); err != nil {
+			return "", nil, fmt.Errorf("invalid deployed bytecode: %v", err)
+		}
+	}
+	if code, err = linkPlaceholders(code, links); err != nil {
+		return "", nil, err
+	}
+	// Resolve the fully qualified name of the contract
+	name := fallback
+	if art.ContractName != "" {
+		name = art.SourceName + ":" + art.ContractName
+	} else if source, target := art.compilationTarget(); target != "" {
+		name = source + ":" + target
+	}
+	var metadata string
+	if len(art.Metadata) > 0 {
+		metadata = string(art.Metadata)
+	}
+	return name, &Contract{
+		Code:        code,
+		RuntimeCode: runtime,
+		Hashes:      art.MethodIdentifiers,
+		Info: ContractInfo{
+			Language:      "Solidity",
+			AbiDefinition: abi,
+			Metadata:      metadata,
+		},
+	}, nil
+}
+
+// compilationTarget extracts the source file and contract name from the solc
+// metadata embedded into Foundry artifacts.
+func (art *artifact) compilationTarget() (string, string) {
+	var metadata struct {
+		Settings struct {
+			CompilationTarget map[string]string `json:"compilationTarget"`
+		} `json:"settings"`
+	}
+	if err := json.Unmarshal(art.Metadata, &metadata); err != nil {
+		return "", ""
+	}
+	for source, name := range metadata.Settings.CompilationTarget {
+		return source, name
+	}
+	return "", ""
+}
+
+// parseArtifactBytecode decodes a bytecode field of an artifact, which is either
+// a plain hex string or a Foundry object carrying its own link references.
+func parseArtifactBytecode(raw json.RawMessage, links linkReferences) (string, linkReferences, error) {
+	var code string
+	if err := json.Unmarshal(raw, &code); err == nil {
+		return ensureHexPrefix(code), links, nil
+	}
+	var object foundryBytecode
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return "", nil, err
+	}
+	return ensureHexPrefix(object.Object), object.LinkReferences, nil
+}
+
+// linkPlaceholders rewrites the library placeholders at the positions given by
+// the link references into the __$<hash>$__ format used by abigen.
+func linkPlaceholders(code string, links linkReferences) (string, error) {
+	bin := []byte(code)
+	for source, libs := range links {
+		for lib, refs := range libs {
+			placeholder := "__$" + crypto.Keccak256Hash([]byte(source + ":" + lib)).String()[2:36] + "$__"
+			for _, ref := range refs {
+				start := 2 + 2*ref.Start // skip the 0x prefix, offsets are in bytes
+				if ref.Length != 20 || start+len(placeholder) > len(bin) {
+					return "", fmt.Errorf("invalid link reference for %s:%s at %d", source, lib, ref.Start)
+				}
+				copy(bin[start:], placeholder)
+			}
+		}
+	}
+	return string(bin), nil
+}
+
+func ensureHexPrefix(code string) string {
+	if code != "" && !strings.HasPrefix(code, "0x") {
+		return "0x" + code
+	}
+	return code
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gorievm/go-gori/crypto"
+)
+
+// The bytecode of a contract calling into a library, with the placeholder of the
+// library at byte offset 2, in the legacy solc placeholder format.
+var artifactTestCode = "0x6073" + "__Lib" + strings.Repeat("_", 35) + "6000"
+
+func TestParseArtifact(t *testing.T) {
+	placeholder := "__$" + crypto.Keccak256Hash([]byte("src/Lib.sol:Lib")).String()[2:36] + "$__"
+	linked := "0x6073" + placeholder + "6000"
+
+	tests := []struct {
+		name     string
+		artifact string
+		fqname   string
+	}{
+		{
+			name: "hardhat",
+			artifact: `{
+				"_format": "hh-sol-artifact-1",
+				"contractName": "Token",
+				"sourceName": "contracts/Token.sol",
+				"abi": [{"type":"function","name":"total","inputs":[],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"}],
+				"bytecode": "` + artifactTestCode + `",
+				"deployedBytecode": "0x6000",
+				"linkReferences": {"src/Lib.sol": {"Lib": [{"length": 20, "start": 2}]}},
+				"deployedLinkReferences": {}
+			}`,
+			fqname: "contracts/Token.sol:Token",
+		},
+		{
+			name: "foundry",
+			artifact: `{
+				"abi": [{"type":"function","name":"total","inputs":[],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"}],
+				"bytecode": {"object": "` + artifactTestCode + `", "linkReferences": {"src/Lib.sol": {"Lib": [{"start": 2, "length": 20}]}}},
+				"deployedBytecode": {"object": "0x6000", "linkReferences": {}},
+				"methodIdentifiers": {"total()": "2ddbd13a"},
+				"metadata": {"settings": {"compilationTarget": {"src/Token.sol": "Token"}}}
+			}`,
+			fqname: "src/Token.sol:Token",
+		},
+		{
+			name: "foundry-without-metadata",
+			artifact: `{
+				"abi": [],
+				"bytecode": {"object": "` + artifactTestCode + `", "linkReferences": {"src/Lib.sol": {"Lib": [{"start": 2, "length": 20}]}}}
+			}`,
+			fqname: "Token",
+		},
+	}
+	for _, tt := range tests {
+		name, contract, err := ParseArtifact([]byte(tt.artifact), "Token")
+		if err != nil {
+			t.Fatalf("%s: failed to parse artifact: %v", tt.name, err)
+		}
+		if name != tt.fqname {
+			t.Errorf("%s: name mismatch: have %s, want %s", tt.name, name, tt.fqname)
+		}
+		if contract.Code != linked {
+			t.Errorf("%s: linked code mismatch: have %s, want %s", tt.name, contract.Code, linked)
+		}
+	}
+}
+
+func TestParseArtifactInvalid(t *testing.T) {
+	// Foundry build-info files and Hardhat debug files are not artifacts
+	for _, blob := range []string{`{"id": "1", "input": {}, "output": {}}`, `{"_format": "hh-sol-dbg-1", "buildInfo": "x.json"}`} {
+		if _, _, err := ParseArtifact([]byte(blob), ""); err != ErrNotArtifact {
+			t.Errorf("non-artifact error mismatch: have %v, want %v", err, ErrNotArtifact)
+		}
+	}
+	// Link references pointing outside the bytecode must be rejected
+	blob := `{"abi": [], "bytecode": {"object": "0x6000", "linkReferences": {"src/Lib.sol": {"Lib": [{"start": 2, "length": 20}]}}}}`
+	if _, _, err := ParseArtifact([]byte(blob), ""); err == nil || !strings.Contains(err.Error(), "invalid link reference") {
+		t.Errorf("out of bounds link reference error mismatch: have %v", err)
+	}
+}
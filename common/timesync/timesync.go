@@ -0,0 +1,194 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package timesync estimates the drift of the local system clock from the
+// timestamps reported by remote peers and, optionally, by an NTP server.
+//
+// The estimate is used to warn users about skewed clocks and to relax the
+// tolerance for blocks which appear to be from the future only because the
+// local clock is running behind the network.
+package timesync
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/metrics"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	sampleLimit     = 64               // Maximum number of peer samples to track
+	minSamples      = 8                // Minimum number of peer samples needed for an estimate
+	ntpValidity     = time.Hour        // Time after which an NTP measurement is considered stale
+	warnThreshold   = 10 * time.Second // Estimated drift above which the user is warned
+	warnCooldown    = 10 * time.Minute // Minimum amount of time to pass before repeating a warning
+	maxCompensation = time.Minute      // Maximum extension of the future block tolerance
+)
+
+var driftGauge = metrics.NewRegisteredGauge("system/clock/drift", nil)
+
+// Estimator tracks clock samples from a bounded set of sources and estimates
+// the drift of the local clock as the median of the measured offsets. A recent
+// NTP measurement, if available, takes precedence over the peer samples.
+//
+// Drifts are positive if the local clock is ahead of the reference.
+type Estimator struct {
+	samples map[string]time.Duration // Latest measured offset by source
+	order   []string                 // Sources in insertion order for eviction
+
+	ntpDrift time.Duration // Drift measured against an NTP server
+	ntpTime  time.Time     // Time of the last NTP measurement
+	warnTime time.Time     // Time of the last drift warning
+
+	now  func() time.Time // Local clock, replaceable for testing
+	lock sync.RWMutex
+}
+
+// NewEstimator creates an empty clock drift estimator.
+func NewEstimator() *Estimator {
+	return &Estimator{
+		samples: make(map[string]time.Duration),
+		now:     time.Now,
+	}
+}
+
+// AddSample records the current time reported by the given source. Only the
+// latest sample of each source is retained, the oldest source being evicted if
+// the sample limit is exceeded.
+func (e *Estimator) AddSample(source string, remote time.Time) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if _, ok := e.samples[source]; ok {
+		for i, id := range e.order {
+			if id == source {
+				e.order = append(e.order[:i], e.order[i+1:]...)
+				break
+			}
+		}
+	} else if len(e.order) >= sampleLimit {
+		delete(e.samples, e.order[0])
+		e.order = e.order[1:]
+	}
+	e.samples[source] = e.now().Sub(remote)
+	e.order = append(e.order, source)
+
+	e.update()
+}
+
+// SetNTPDrift records the drift measured against an NTP server.
+func (e *Estimator) SetNTPDrift(drift time.Duration) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.ntpDrift, e.ntpTime = drift, e.now()
+	e.update()
+}
+
+// Drift returns the estimated drift of the local clock, and whether enough
+// information is available for an estimate.
+func (e *Estimator) Drift() (time.Duration, bool) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	return e.drift()
+}
+
+// drift is the lockless version of Drift.
+func (e *Estimator) drift() (time.Duration, bool) {
+	if !e.ntpTime.IsZero() && e.now().Sub(e.ntpTime) < ntpValidity {
+		return e.ntpDrift, true
+	}
+	if len(e.samples) < minSamples {
+		return 0, false
+	}
+	drifts := make([]time.Duration, 0, len(e.samples))
+	for _, drift := range e.samples {
+		drifts = append(drifts, drift)
+	}
+	slices.Sort(drifts)
+	return drifts[len(drifts)/2], true
+}
+
+// update reports the current estimate and warns the user if the local clock
+// seems to be off. The caller must hold the write lock.
+func (e *Estimator) update() {
+	drift, ok := e.drift()
+	if !ok {
+		return
+	}
+	driftGauge.Update(int64(drift))
+
+	if drift > -warnThreshold && drift < warnThreshold {
+		return
+	}
+	if e.now().Sub(e.warnTime) < warnCooldown {
+		return
+	}
+	e.warnTime = e.now()
+	if drift < 0 {
+		log.Warn("System clock seems to be behind the network, future block tolerance extended", "drift", drift, "samples", len(e.samples), "extension", min(-drift, maxCompensation))
+	} else {
+		log.Warn("System clock seems to be ahead of the network", "drift", drift, "samples", len(e.samples))
+	}
+	log.Warn("Please enable network time synchronisation in system settings.")
+}
+
+// FutureTolerance extends the given tolerance for blocks from the future by the
+// amount the local clock is estimated to be behind, capped at a maximum.
+func (e *Estimator) FutureTolerance(tolerance time.Duration) time.Duration {
+	drift, ok := e.Drift()
+	if !ok || drift >= 0 {
+		return tolerance
+	}
+	return tolerance + min(-drift, maxCompensation)
+}
+
+// min returns the smaller of two durations.
+func min(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// DefaultEstimator is the process wide clock drift estimator.
+var DefaultEstimator = NewEstimator()
+
+// AddSample records the current time reported by the given source in the
+// default estimator.
+func AddSample(source string, remote time.Time) {
+	DefaultEstimator.AddSample(source, remote)
+}
+
+// SetNTPDrift records the drift measured against an NTP server in the default
+// estimator.
+func SetNTPDrift(drift time.Duration) {
+	DefaultEstimator.SetNTPDrift(drift)
+}
+
+// Drift returns the local clock drift estimated by the default estimator.
+func Drift() (time.Duration, bool) {
+	return DefaultEstimator.Drift()
+}
+
+// FutureTolerance extends the given future block tolerance according to the
+// default estimator.
+func FutureTolerance(tolerance time.Duration) time.Duration {
+	return DefaultEstimator.FutureTolerance(tolerance)
+}
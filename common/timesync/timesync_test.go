@@ -0,0 +1,128 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package timesync
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Tests that the drift is estimated as the median of the peer samples, which
+// tolerates a minority of peers with bogus clocks.
+func TestEstimatorMedian(t *testing.T) {
+	var (
+		now = time.Unix(1700000000, 0)
+		est = NewEstimator()
+	)
+	est.now = func() time.Time { return now }
+
+	for i := 0; i < minSamples-1; i++ {
+		est.AddSample(fmt.Sprintf("peer-%d", i), now.Add(30*time.Second))
+	}
+	if _, ok := est.Drift(); ok {
+		t.Fatalf("estimate available with too few samples")
+	}
+	if have := est.FutureTolerance(15 * time.Second); have != 15*time.Second {
+		t.Fatalf("tolerance mismatch without estimate: have %v, want %v", have, 15*time.Second)
+	}
+	est.AddSample("liar-1", now.Add(-time.Hour))
+	est.AddSample("liar-2", now.Add(time.Hour))
+
+	drift, ok := est.Drift()
+	if !ok {
+		t.Fatalf("no estimate with enough samples")
+	}
+	if drift != -30*time.Second {
+		t.Fatalf("drift mismatch: have %v, want %v", drift, -30*time.Second)
+	}
+	if have := est.FutureTolerance(15 * time.Second); have != 45*time.Second {
+		t.Fatalf("tolerance mismatch: have %v, want %v", have, 45*time.Second)
+	}
+}
+
+// Tests that the tolerance is only extended if the local clock is behind, and
+// at most by the maximum compensation.
+func TestEstimatorTolerance(t *testing.T) {
+	var (
+		now = time.Unix(1700000000, 0)
+		est = NewEstimator()
+	)
+	est.now = func() time.Time { return now }
+
+	tests := []struct {
+		drift time.Duration
+		want  time.Duration
+	}{
+		{0, 15 * time.Second},
+		{time.Hour, 15 * time.Second},
+		{-10 * time.Second, 25 * time.Second},
+		{-time.Hour, 15*time.Second + maxCompensation},
+	}
+	for i, tt := range tests {
+		est.SetNTPDrift(tt.drift)
+		if have := est.FutureTolerance(15 * time.Second); have != tt.want {
+			t.Errorf("test %d: tolerance mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that recent NTP measurements take precedence over peer samples and that
+// stale ones are ignored.
+func TestEstimatorNTP(t *testing.T) {
+	var (
+		now = time.Unix(1700000000, 0)
+		est = NewEstimator()
+	)
+	est.now = func() time.Time { return now }
+
+	for i := 0; i < minSamples; i++ {
+		est.AddSample(fmt.Sprintf("peer-%d", i), now.Add(-5*time.Second))
+	}
+	est.SetNTPDrift(-20 * time.Second)
+	if drift, _ := est.Drift(); drift != -20*time.Second {
+		t.Fatalf("drift mismatch with NTP: have %v, want %v", drift, -20*time.Second)
+	}
+	now = now.Add(ntpValidity)
+	if drift, _ := est.Drift(); drift != 5*time.Second {
+		t.Fatalf("drift mismatch with stale NTP: have %v, want %v", drift, 5*time.Second)
+	}
+}
+
+// Tests that only the latest sample per source is kept and that the number of
+// tracked sources is bounded.
+func TestEstimatorEviction(t *testing.T) {
+	var (
+		now = time.Unix(1700000000, 0)
+		est = NewEstimator()
+	)
+	est.now = func() time.Time { return now }
+
+	for i := 0; i < 2*sampleLimit; i++ {
+		est.AddSample(fmt.Sprintf("peer-%d", i), now)
+		est.AddSample(fmt.Sprintf("peer-%d", i), now)
+	}
+	if len(est.samples) != sampleLimit || len(est.order) != sampleLimit {
+		t.Fatalf("sample count mismatch: have %d/%d, want %d", len(est.samples), len(est.order), sampleLimit)
+	}
+	if _, ok := est.samples["peer-0"]; ok {
+		t.Fatalf("oldest source not evicted")
+	}
+	if _, ok := est.samples[fmt.Sprintf("peer-%d", 2*sampleLimit-1)]; !ok {
+		t.Fatalf("newest source missing")
+	}
+}
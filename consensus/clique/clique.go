@@ -31,6 +31,7 @@ import (
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/common/hexutil"
 	lru "github.com/gorievm/go-gori/common/lru"
+	"github.com/gorievm/go-gori/common/timesync"
 	"github.com/gorievm/go-gori/consensus"
 	"github.com/gorievm/go-gori/consensus/misc"
 	"github.com/gorievm/go-gori/consensus/misc/eip1559"
@@ -251,7 +252,7 @@ func (c *Clique) verifyHeader(chain consensus.ChainHeaderReader, header *types.H
 	number := header.Number.Uint64()
 
 	// Don't waste time checking blocks from the future
-	if header.Time > uint64(time.Now().Unix()) {
+	if header.Time > uint64(time.Now().Unix()+int64(timesync.FutureTolerance(0)/time.Second)) {
 		return consensus.ErrFutureBlock
 	}
 	// Checkpoint blocks need to enforce zero beneficiary
@@ -565,20 +566,38 @@ func (c *Clique) Prepare(chain consensus.ChainHeaderReader, header *types.Header
 	return nil
 }
 
-// Finalize implements consensus.Engine. There is no post-transaction
-// consensus rules in clique, do nothing here.
+// Finalize implements consensus.Engine. There are no block rewards in clique,
+// unless the chain configures a block reward schedule paying the signer.
 func (c *Clique) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, withdrawals []*types.Withdrawal) {
-	// No block rewards in PoA, so the state remains as is
+	era := chain.Config().RewardEra(header.Number)
+	if era == nil {
+		return
+	}
+	// The seal was verified along with the header, so the signer is recoverable
+	signer, err := ecrecover(header, c.signatures)
+	if err != nil {
+		log.Error("Failed to recover clique signer for block reward", "number", header.Number, "err", err)
+		return
+	}
+	accumulateRewards(state, era, signer)
 }
 
 // FinalizeAndAssemble implements consensus.Engine, ensuring no uncles are set,
-// nor block rewards given, and returns the final block.
+// paying the block reward to the local signer if configured, and returns the
+// final block.
 func (c *Clique) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt, withdrawals []*types.Withdrawal) (*types.Block, error) {
 	if len(withdrawals) > 0 {
 		return nil, errors.New("clique does not support withdrawals")
 	}
-	// Finalize block
-	c.Finalize(chain, header, state, txs, uncles, nil)
+	// Finalize block, the header is not sealed yet so the reward goes to the
+	// signer which is going to seal it
+	if era := chain.Config().RewardEra(header.Number); era != nil {
+		c.lock.RLock()
+		signer := c.signer
+		c.lock.RUnlock()
+
+		accumulateRewards(state, era, signer)
+	}
 
 	// Assign the final state root to header.
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
@@ -587,6 +606,12 @@ func (c *Clique) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *
 	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil)), nil
 }
 
+// accumulateRewards credits the signer of a block with the block reward of the
+// era, less the shares of its treasury recipients.
+func accumulateRewards(state *state.StateDB, era *params.RewardEra, signer common.Address) {
+	state.AddBalance(signer, misc.PayRewardShares(state, era))
+}
+
 // Authorize injects a private key into the consensus engine to mint new blocks
 // with.
 func (c *Clique) Authorize(signer common.Address, signFn SignerFn) {
@@ -123,3 +123,60 @@ func TestSealHash(t *testing.T) {
 		t.Errorf("have %x, want %x", have, want)
 	}
 }
+
+// Tests that the signer of a block is paid the block reward of the chain's
+// reward schedule, less the share of its treasury recipients.
+func TestScheduledBlockRewards(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		treasury = common.HexToAddress("0x000000000000000000000000000000000000beef")
+		engine   = New(params.AllCliqueProtocolChanges.Clique, rawdb.NewMemoryDatabase())
+		config   = *params.AllCliqueProtocolChanges
+	)
+	config.BlockRewards = []params.RewardEra{
+		{Block: 2, Reward: big.NewInt(1000), Recipients: []params.RewardRecipient{{Address: treasury, Share: 2500}}},
+		{Block: 3, Reward: big.NewInt(0)},
+	}
+	genspec := &core.Genesis{
+		Config:    &config,
+		ExtraData: make([]byte, extraVanity+common.AddressLength+extraSeal),
+		BaseFee:   big.NewInt(params.InitialBaseFee),
+	}
+	copy(genspec.ExtraData[extraVanity:], addr[:])
+
+	// Generate a batch of blocks rewarding the local signer, then seal them
+	engine.Authorize(addr, nil)
+	_, blocks, _ := core.GenerateChainWithGenesis(genspec, engine, 3, func(i int, block *core.BlockGen) {
+		block.SetDifficulty(diffInTurn)
+	})
+	for i, block := range blocks {
+		header := block.Header()
+		if i > 0 {
+			header.ParentHash = blocks[i-1].Hash()
+		}
+		header.Extra = make([]byte, extraVanity+extraSeal)
+		header.Difficulty = diffInTurn
+
+		sig, _ := crypto.Sign(SealHash(header).Bytes(), key)
+		copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+		blocks[i] = block.WithSeal(header)
+	}
+	// Import the blocks, rewarding the signer recovered from the seals
+	chain, _ := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genspec, nil, New(config.Clique, rawdb.NewMemoryDatabase()), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	state, err := chain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance := state.GetBalance(addr); balance.Cmp(big.NewInt(750)) != 0 {
+		t.Errorf("signer balance mismatch: have %v, want %v", balance, 750)
+	}
+	if balance := state.GetBalance(treasury); balance.Cmp(big.NewInt(250)) != 0 {
+		t.Errorf("treasury balance mismatch: have %v, want %v", balance, 250)
+	}
+}
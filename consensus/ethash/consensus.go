@@ -25,6 +25,7 @@ import (
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/common/math"
+	"github.com/gorievm/go-gori/common/timesync"
 	"github.com/gorievm/go-gori/consensus"
 	"github.com/gorievm/go-gori/consensus/misc"
 	"github.com/gorievm/go-gori/consensus/misc/eip1559"
@@ -225,7 +226,8 @@ func (ethash *Ethash) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 	}
 	// Verify the header's timestamp
 	if !uncle {
-		if header.Time > uint64(unixNow+allowedFutureBlockTimeSeconds) {
+		allowed := timesync.FutureTolerance(time.Duration(allowedFutureBlockTimeSeconds) * time.Second)
+		if header.Time > uint64(unixNow+int64(allowed/time.Second)) {
 			return consensus.ErrFutureBlock
 		}
 	}
@@ -546,7 +548,8 @@ var (
 
 // AccumulateRewards credits the coinbase of the given block with the mining
 // reward. The total reward consists of the static block reward and rewards for
-// included uncles. The coinbase of each uncle block is also rewarded.
+// included uncles. The coinbase of each uncle block is also rewarded. Chains with
+// a block reward schedule replace the static block reward by that of the era.
 func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, uncles []*types.Header) {
 	// Select the correct block reward based on chain progression
 	blockReward := FrontierBlockReward
@@ -556,8 +559,13 @@ func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header
 	if config.IsConstantinople(header.Number) {
 		blockReward = ConstantinopleBlockReward
 	}
-	// Accumulate the rewards for the miner and any included uncles
+	// Accumulate the rewards for the miner and any included uncles, paying out
+	// the treasury shares of the chain's reward schedule first if configured
 	reward := new(big.Int).Set(blockReward)
+	if era := config.RewardEra(header.Number); era != nil {
+		blockReward = era.Reward
+		reward = misc.PayRewardShares(state, era)
+	}
 	r := new(big.Int)
 	for _, uncle := range uncles {
 		r.Add(uncle.Number, big8)
@@ -129,3 +129,34 @@ func TestCalcBaseFee(t *testing.T) {
 		}
 	}
 }
+
+// TestCalcBaseFeeCustom checks the base fee calculation with custom fee market
+// parameters.
+func TestCalcBaseFeeCustom(t *testing.T) {
+	elasticity, denominator := uint64(4), uint64(50)
+	config := config()
+	config.BaseFeeElasticity = &elasticity
+	config.BaseFeeDenominator = &denominator
+
+	tests := []struct {
+		parentBaseFee   int64
+		parentGasLimit  uint64
+		parentGasUsed   uint64
+		expectedBaseFee int64
+	}{
+		{params.InitialBaseFee, 20000000, 5000000, params.InitialBaseFee}, // usage == target
+		{params.InitialBaseFee, 20000000, 0, 980000000},                   // empty block
+		{params.InitialBaseFee, 20000000, 20000000, 1060000000},           // full block
+	}
+	for i, test := range tests {
+		parent := &types.Header{
+			Number:   common.Big32,
+			GasLimit: test.parentGasLimit,
+			GasUsed:  test.parentGasUsed,
+			BaseFee:  big.NewInt(test.parentBaseFee),
+		}
+		if have, want := CalcBaseFee(config, parent), big.NewInt(test.expectedBaseFee); have.Cmp(want) != 0 {
+			t.Errorf("test %d: have %d  want %d, ", i, have, want)
+		}
+	}
+}
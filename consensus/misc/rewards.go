@@ -0,0 +1,43 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"math/big"
+
+	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/params"
+)
+
+// PayRewardShares credits the treasury recipients of a block reward era with
+// their shares of the block reward, returning what remains for the author of
+// the block.
+func PayRewardShares(state *state.StateDB, era *params.RewardEra) *big.Int {
+	var (
+		remainder = new(big.Int).Set(era.Reward)
+		denom     = big.NewInt(params.RewardShareDenominator)
+	)
+	for _, r := range era.Recipients {
+		share := new(big.Int).SetUint64(r.Share)
+		share.Mul(share, era.Reward)
+		share.Div(share, denom)
+
+		state.AddBalance(r.Address, share)
+		remainder.Sub(remainder, share)
+	}
+	return remainder
+}
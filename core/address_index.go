@@ -0,0 +1,226 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/log"
+)
+
+// txAddresses returns the distinct addresses touched by a transaction at the
+// top level: the sender and either the recipient or the created contract.
+func txAddresses(signer types.Signer, tx *types.Transaction) []common.Address {
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil // Unreachable for canonical blocks
+	}
+	to := tx.To()
+	if to == nil {
+		created := crypto.CreateAddress(from, tx.Nonce())
+		to = &created
+	}
+	if *to == from {
+		return []common.Address{from}
+	}
+	return []common.Address{from, *to}
+}
+
+// writeAddressIndex adds the transactions of a block to the address index.
+func (bc *BlockChain) writeAddressIndex(db ethdb.KeyValueWriter, block *types.Block) {
+	signer := types.MakeSigner(bc.chainConfig, block.Number(), block.Time())
+	for i, tx := range block.Transactions() {
+		entry := rawdb.AddressTxEntry{BlockNumber: block.NumberU64(), Index: uint32(i), Hash: tx.Hash()}
+		for _, addr := range txAddresses(signer, tx) {
+			rawdb.WriteAddressTxEntry(db, addr, entry)
+		}
+	}
+}
+
+// deleteAddressIndex removes the transactions of a block from the address index.
+func (bc *BlockChain) deleteAddressIndex(db ethdb.KeyValueWriter, block *types.Block) {
+	signer := types.MakeSigner(bc.chainConfig, block.Number(), block.Time())
+	for i, tx := range block.Transactions() {
+		for _, addr := range txAddresses(signer, tx) {
+			rawdb.DeleteAddressTxEntry(db, addr, block.NumberU64(), uint32(i))
+		}
+	}
+}
+
+// indexAddresses adds the canonical blocks in [from, to) to the address index.
+// Blocks are processed in reverse order, so that the tail can be moved down
+// periodically and an interrupted backfill resumes where it left off.
+func (bc *BlockChain) indexAddresses(from, to uint64) {
+	var (
+		batch  = bc.db.NewBatch()
+		start  = time.Now()
+		logged = start.Add(-7 * time.Second)
+		tail   = to
+	)
+	for tail > from {
+		select {
+		case <-bc.quit:
+			return
+		default:
+		}
+		block := rawdb.ReadBlock(bc.db, rawdb.ReadCanonicalHash(bc.db, tail-1), tail-1)
+		if block == nil {
+			log.Error("Missing block for address indexing", "number", tail-1)
+			return
+		}
+		bc.writeAddressIndex(batch, block)
+		tail--
+
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			rawdb.WriteAddressIndexTail(batch, tail)
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed writing address index", "err", err)
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing transactions by address", "blocks", to-tail, "total", to-from, "tail", tail, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	rawdb.WriteAddressIndexTail(batch, tail)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed writing address index", "err", err)
+	}
+	log.Debug("Indexed transactions by address", "blocks", to-from, "tail", tail, "elapsed", common.PrettyDuration(time.Since(start)))
+}
+
+// unindexAddresses removes the canonical blocks in [from, to) from the address
+// index, moving the tail up as it goes.
+func (bc *BlockChain) unindexAddresses(from, to uint64) {
+	var (
+		batch  = bc.db.NewBatch()
+		start  = time.Now()
+		logged = start.Add(-7 * time.Second)
+		tail   = from
+	)
+	for tail < to {
+		select {
+		case <-bc.quit:
+			return
+		default:
+		}
+		block := rawdb.ReadBlock(bc.db, rawdb.ReadCanonicalHash(bc.db, tail), tail)
+		if block == nil {
+			log.Error("Missing block for address unindexing", "number", tail)
+			return
+		}
+		bc.deleteAddressIndex(batch, block)
+		tail++
+
+		// A batch counts the size of deletion as '1', so flush by block count.
+		if (tail-from)%1000 == 0 {
+			rawdb.WriteAddressIndexTail(batch, tail)
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed writing address index", "err", err)
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Unindexing transactions by address", "blocks", tail-from, "total", to-from, "tail", tail, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	rawdb.WriteAddressIndexTail(batch, tail)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed writing address index", "err", err)
+	}
+	log.Debug("Unindexed transactions by address", "blocks", to-from, "tail", tail, "elapsed", common.PrettyDuration(time.Since(start)))
+}
+
+// updateAddressIndex backfills or prunes the address index so that it covers
+// the configured number of recent blocks below head.
+func (bc *BlockChain) updateAddressIndex(tail *uint64, head uint64, done chan struct{}) {
+	defer close(done)
+
+	from := uint64(0)
+	if limit := bc.cacheConfig.AddressIndexLimit; limit != 0 && head >= limit {
+		from = head - limit + 1
+	}
+	// The expired history can't be indexed, nor unindexed block by block.
+	cutoff := bc.HistoryPruningCutoff()
+	if from < cutoff {
+		from = cutoff
+	}
+	if tail != nil && *tail < cutoff {
+		rawdb.PruneAddressTxEntries(bc.db, cutoff)
+		rawdb.WriteAddressIndexTail(bc.db, cutoff)
+		tail = &cutoff
+	}
+	switch {
+	case tail == nil:
+		// The index was never built, backfill it all the way.
+		bc.indexAddresses(from, head+1)
+	case from < *tail:
+		// The limit was raised or the chain was rewound below the tail,
+		// extend the index downwards.
+		end := *tail
+		if end > head+1 {
+			end = head + 1
+		}
+		bc.indexAddresses(from, end)
+	case from > *tail:
+		// The tail fell out of the retention window, prune it.
+		bc.unindexAddresses(*tail, from)
+	}
+}
+
+// maintainAddressIndex is responsible for the construction and pruning of the
+// address index. New blocks are indexed during import, this routine backfills
+// the history the index is missing and prunes the blocks falling out of the
+// `addressindex.limit` window.
+func (bc *BlockChain) maintainAddressIndex() {
+	defer bc.wg.Done()
+
+	var (
+		done   chan struct{}                  // Non-nil if background backfill or pruning is active.
+		headCh = make(chan ChainHeadEvent, 1) // Buffered to avoid locking up the event feed
+	)
+	sub := bc.SubscribeChainHeadEvent(headCh)
+	if sub == nil {
+		return
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case head := <-headCh:
+			if done == nil {
+				done = make(chan struct{})
+				go bc.updateAddressIndex(rawdb.ReadAddressIndexTail(bc.db), head.Block.NumberU64(), done)
+			}
+		case <-done:
+			done = nil
+		case <-bc.quit:
+			if done != nil {
+				log.Info("Waiting background address indexer to exit")
+				<-done
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/consensus/ethash"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/params"
+)
+
+// Tests that the address index is backfilled and pruned to the configured
+// number of recent blocks.
+func TestAddressIndexBackfill(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		funds   = big.NewInt(100000000000000000)
+		gspec   = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   GenesisAlloc{address: {Balance: funds}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer    = types.LatestSigner(gspec.Config)
+		recipient = common.Address{0xaa}
+		created   = crypto.CreateAddress(address, 0)
+	)
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 128, func(i int, block *BlockGen) {
+		var tx *types.Transaction
+		if i == 0 {
+			tx = types.NewContractCreation(block.TxNonce(address), nil, 100000, block.header.BaseFee, []byte{0x00})
+		} else {
+			tx = types.NewTransaction(block.TxNonce(address), recipient, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil)
+		}
+		tx, err := types.SignTx(tx, signer, key)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	check := func(chain *BlockChain, tail uint64) {
+		t.Helper()
+
+		if stored := rawdb.ReadAddressIndexTail(chain.db); stored == nil || *stored != tail {
+			t.Fatalf("address index tail mismatch: have %v, want %d", stored, tail)
+		}
+		from := tail
+		if from == 0 {
+			from = 1 // genesis has no transactions
+		}
+		sent := rawdb.ReadAddressTxEntries(chain.db, address, 0, 1000)
+		if len(sent) != int(128-from+1) || sent[0].BlockNumber != from {
+			t.Fatalf("sender entries mismatch: have %d from %d, want %d from %d", len(sent), sent[0].BlockNumber, 128-from+1, from)
+		}
+		for _, entry := range sent {
+			block := blocks[entry.BlockNumber-1]
+			if entry.Index != 0 || entry.Hash != block.Transactions()[0].Hash() {
+				t.Fatalf("entry of block %d mismatch: have %d/%x", entry.BlockNumber, entry.Index, entry.Hash)
+			}
+		}
+		if have := chain.AddressTransactions(address, 0, 1000); len(have) != len(sent) {
+			t.Fatalf("canonical entries mismatch: have %d, want %d", len(have), len(sent))
+		}
+		if have := len(rawdb.ReadAddressTxEntries(chain.db, created, 0, 1000)); (from == 1) != (have == 1) {
+			t.Fatalf("contract creation entries mismatch: have %d, tail %d", have, tail)
+		}
+	}
+	db, _ := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	defer db.Close()
+
+	rawdb.WriteAncientBlocks(db, append([]*types.Block{gspec.ToBlock()}, blocks...), append([]types.Receipts{{}}, receipts...), big.NewInt(0))
+	for _, limit := range []uint64{0, 64 /* drop stale */, 32 /* shorten history */, 64 /* extend history */, 0 /* restore all */} {
+		config := *defaultCacheConfig
+		config.AddressIndex, config.AddressIndexLimit = true, limit
+
+		chain, err := NewBlockChain(db, &config, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create tester chain: %v", err)
+		}
+		chain.updateAddressIndex(rawdb.ReadAddressIndexTail(db), 128, make(chan struct{}))
+
+		var tail uint64
+		if limit != 0 {
+			tail = 128 - limit + 1
+		}
+		check(chain, tail)
+		chain.Stop()
+	}
+	// Disabling the index drops its progress so it is rebuilt when re-enabled
+	chain, err := NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	chain.Stop()
+	if tail := rawdb.ReadAddressIndexTail(db); tail != nil {
+		t.Fatalf("address index tail retained while disabled: %d", *tail)
+	}
+}
+
+// Tests that the address index follows the canonical chain across reorgs.
+func TestAddressIndexReorg(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		funds   = big.NewInt(100000000000000000)
+		gspec   = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   GenesisAlloc{address: {Balance: funds}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+		first  = common.Address{0xaa}
+		second = common.Address{0xbb}
+	)
+	generate := func(n int, recipient common.Address) []*types.Block {
+		_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), n, func(i int, block *BlockGen) {
+			tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), recipient, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil), signer, key)
+			if err != nil {
+				panic(err)
+			}
+			block.AddTx(tx)
+		})
+		return blocks
+	}
+	config := *defaultCacheConfig
+	config.AddressIndex = true
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), &config, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(generate(8, first)); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if have := len(chain.AddressTransactions(first, 0, 100)); have != 8 {
+		t.Fatalf("recipient entries mismatch: have %d, want 8", have)
+	}
+	if have := len(chain.AddressTransactions(address, 0, 100)); have != 8 {
+		t.Fatalf("sender entries mismatch: have %d, want 8", have)
+	}
+	// Reorg to a longer chain paying a different recipient
+	fork := generate(10, second)
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	if have := len(chain.AddressTransactions(first, 0, 100)); have != 0 {
+		t.Fatalf("reorged out entries retained: %d", have)
+	}
+	if have := len(chain.AddressTransactions(second, 0, 100)); have != 10 {
+		t.Fatalf("recipient entries mismatch: have %d, want 10", have)
+	}
+	sent := chain.AddressTransactions(address, 0, 100)
+	if len(sent) != 10 {
+		t.Fatalf("sender entries mismatch: have %d, want 10", len(sent))
+	}
+	for i, entry := range sent {
+		if entry.Hash != fork[i].Transactions()[0].Hash() {
+			t.Fatalf("entry %d mismatch: have %x, want %x", i, entry.Hash, fork[i].Transactions()[0].Hash())
+		}
+	}
+	// Pages never split the transactions of a block
+	if page := chain.AddressTransactions(address, 3, 2); len(page) != 2 || page[0].BlockNumber != 3 || page[1].BlockNumber != 4 {
+		t.Fatalf("page mismatch: %v", page)
+	}
+}
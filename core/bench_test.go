@@ -20,6 +20,7 @@ import (
 	"crypto/ecdsa"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/common/math"
@@ -69,6 +70,21 @@ func BenchmarkInsertChain_ring1000_diskdb(b *testing.B) {
 	benchInsertChain(b, true, genTxRing(1000))
 }
 
+// BenchmarkReplayChain_contracts replays a recorded range of blocks full of
+// contract calls into a fresh chain, measuring block processing dominated by
+// EVM execution.
+func BenchmarkReplayChain_contracts(b *testing.B) {
+	gspec := &Genesis{
+		Config:   params.TestChainConfig,
+		GasLimit: 30_000_000,
+		Alloc: GenesisAlloc{
+			benchRootAddr:     {Balance: benchRootFunds},
+			benchContractAddr: {Code: benchContractCode, Balance: common.Big0},
+		},
+	}
+	benchReplayChain(b, gspec, 16, genContractCalls(100))
+}
+
 var (
 	// This is the content of the genesis block used by the benchmarks.
 	benchRootKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
@@ -83,7 +99,7 @@ func genValueTx(nbytes int) func(int, *BlockGen) {
 	return func(i int, gen *BlockGen) {
 		toaddr := common.Address{}
 		data := make([]byte, nbytes)
-		gas, _ := IntrinsicGas(data, nil, false, false, false, false)
+		gas, _ := IntrinsicGas(data, nil, nil, false, false, false, false, nil)
 		signer := types.MakeSigner(gen.config, big.NewInt(int64(i)), gen.header.Time)
 		gasPrice := big.NewInt(0)
 		if gen.header.BaseFee != nil {
@@ -158,6 +174,65 @@ func genTxRing(naccounts int) func(int, *BlockGen) {
 	}
 }
 
+var (
+	// benchContractAddr is the address of the contract called by the replay
+	// benchmarks.
+	benchContractAddr = common.HexToAddress("0xc0de")
+
+	// benchContractCode is a loop mixing stack shuffling, arithmetic, memory
+	// and hashing, storing the result once done, similar to compiled code.
+	benchContractCode = []byte{
+		byte(vm.PUSH1), 0x00, // [acc]
+		byte(vm.PUSH2), 0x04, 0x00, // [acc, n]
+		byte(vm.JUMPDEST),    // [acc, n]
+		byte(vm.DUP1),        // [acc, n, n]
+		byte(vm.SWAP2),       // [n, n, acc]
+		byte(vm.ADD),         // [n, acc+n]
+		byte(vm.SWAP1),       // [acc, n]
+		byte(vm.PUSH1), 0x01, // [acc, n, 1]
+		byte(vm.SWAP1),       // [acc, 1, n]
+		byte(vm.SUB),         // [acc, n-1]
+		byte(vm.DUP1),        // [acc, n, n]
+		byte(vm.PUSH1), 0x00, // [acc, n, n, 0]
+		byte(vm.MSTORE),      // [acc, n]
+		byte(vm.PUSH1), 0x20, // [acc, n, 32]
+		byte(vm.PUSH1), 0x00, // [acc, n, 32, 0]
+		byte(vm.KECCAK256),   // [acc, n, hash]
+		byte(vm.DUP3),        // [acc, n, hash, acc]
+		byte(vm.XOR),         // [acc, n, acc^hash]
+		byte(vm.SWAP2),       // [acc^hash, n, acc]
+		byte(vm.POP),         // [acc, n]
+		byte(vm.DUP1),        // [acc, n, n]
+		byte(vm.PUSH1), 0x05, // [acc, n, n, loop]
+		byte(vm.JUMPI),       // [acc, n]
+		byte(vm.POP),         // [acc]
+		byte(vm.PUSH1), 0x00, // [acc, 0]
+		byte(vm.SSTORE), // []
+		byte(vm.STOP),
+	}
+)
+
+// genContractCalls returns a block generator that includes n calls to the
+// benchmark contract in each block.
+func genContractCalls(n int) func(int, *BlockGen) {
+	return func(i int, gen *BlockGen) {
+		signer := types.MakeSigner(gen.config, big.NewInt(int64(i)), gen.header.Time)
+		gasPrice := big.NewInt(0)
+		if gen.header.BaseFee != nil {
+			gasPrice = gen.header.BaseFee
+		}
+		for j := 0; j < n; j++ {
+			tx, _ := types.SignNewTx(benchRootKey, signer, &types.LegacyTx{
+				Nonce:    gen.TxNonce(benchRootAddr),
+				To:       &benchContractAddr,
+				Gas:      250_000,
+				GasPrice: gasPrice,
+			})
+			gen.AddTx(tx)
+		}
+	}
+}
+
 // genUncles generates blocks with two uncle headers.
 func genUncles(i int, gen *BlockGen) {
 	if i >= 7 {
@@ -324,3 +399,34 @@ func benchReadChain(b *testing.B, full bool, count uint64) {
 		db.Close()
 	}
 }
+
+// benchReplayChain generates a range of blocks once, then replays it into a
+// fresh chain on every iteration, reporting the gas processed per second.
+func benchReplayChain(b *testing.B, gspec *Genesis, n int, gen func(int, *BlockGen)) {
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), n, gen)
+
+	var gas uint64
+	for _, block := range blocks {
+		gas += block.GasUsed()
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var elapsed time.Duration
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+		if err != nil {
+			b.Fatalf("failed to create chain: %v", err)
+		}
+		start := time.Now()
+		b.StartTimer()
+		if i, err := chain.InsertChain(blocks); err != nil {
+			b.Fatalf("insert error (block %d): %v", i, err)
+		}
+		b.StopTimer()
+		elapsed += time.Since(start)
+		chain.Stop()
+	}
+	b.ReportMetric(float64(gas)*float64(b.N)/elapsed.Seconds()/1e6, "mgas/s")
+}
@@ -125,6 +125,11 @@ func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateD
 	if receiptSha != header.ReceiptHash {
 		return fmt.Errorf("invalid receipt root hash (remote: %x local: %x)", header.ReceiptHash, receiptSha)
 	}
+	// Gori does not define any execution layer requests, so the request set
+	// committed to after Prague must be the empty one.
+	if header.RequestsHash != nil && *header.RequestsHash != types.EmptyRequestsHash {
+		return fmt.Errorf("invalid requests hash (remote: %x local: %x)", *header.RequestsHash, types.EmptyRequestsHash)
+	}
 	// Validate the state root against the received state root and throw
 	// an error if they don't match.
 	if root := statedb.IntermediateRoot(v.config.IsEIP158(header.Number)); header.Root != root {
@@ -32,6 +32,7 @@ import (
 	"github.com/gorievm/go-gori/common/lru"
 	"github.com/gorievm/go-gori/common/mclock"
 	"github.com/gorievm/go-gori/common/prque"
+	"github.com/gorievm/go-gori/common/timesync"
 	"github.com/gorievm/go-gori/consensus"
 	"github.com/gorievm/go-gori/consensus/misc/eip4844"
 	"github.com/gorievm/go-gori/core/rawdb"
@@ -78,6 +79,9 @@ var (
 	blockValidationTimer = metrics.NewRegisteredTimer("chain/validation", nil)
 	blockExecutionTimer  = metrics.NewRegisteredTimer("chain/execution", nil)
 	blockWriteTimer      = metrics.NewRegisteredTimer("chain/write", nil)
+	blockStateReadTimer  = metrics.NewRegisteredTimer("chain/stateread", nil)
+	blockTrieHashTimer   = metrics.NewRegisteredTimer("chain/triehash", nil)
+	blockCommitTimer     = metrics.NewRegisteredTimer("chain/commit", nil)
 
 	blockReorgMeter     = metrics.NewRegisteredMeter("chain/reorg/executes", nil)
 	blockReorgAddMeter  = metrics.NewRegisteredMeter("chain/reorg/add", nil)
@@ -86,6 +90,8 @@ var (
 	blockPrefetchExecuteTimer   = metrics.NewRegisteredTimer("chain/prefetch/executes", nil)
 	blockPrefetchInterruptMeter = metrics.NewRegisteredMeter("chain/prefetch/interrupts", nil)
 
+	blockWitnessSizeGauge = metrics.NewRegisteredGauge("chain/witness/size", nil)
+
 	errInsertionInterrupted = errors.New("insertion is interrupted")
 	errChainStopped         = errors.New("blockchain is stopped")
 	errInvalidOldChain      = errors.New("invalid old chain")
@@ -101,7 +107,9 @@ const (
 	maxTimeFutureBlocks = 30
 	TriesInMemory       = 128
 
-	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
+	// BlockChainVersion is the database schema version. Databases of older versions
+	// are upgraded by the schema migrations registered in rawdb, versions without a
+	// migration force a resync from scratch.
 	//
 	// Changelog:
 	//
@@ -140,6 +148,16 @@ type CacheConfig struct {
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
 	SnapshotWait    bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
+	SnapshotRate    int  // Megabytes of snapshot data permitted to generate per second (0 = unlimited)
+
+	WitnessHistory    int  // Number of recent blocks to retain execution witnesses for (0 = disabled)
+	ReceiptAccounting bool // Whether to store the accounting information of receipts
+	ParallelExecution bool // Whether to execute block transactions speculatively in parallel
+
+	AddressIndex      bool   // Whether to index the canonical transactions by the addresses they touch
+	AddressIndexLimit uint64 // Number of recent blocks to keep in the address index (0 = entire chain)
+
+	HistoryCutoff uint64 // Block number below which block bodies and receipts are pruned (0 = keep entire history)
 }
 
 // defaultCacheConfig are the default caching values if none are specified by the
@@ -191,6 +209,7 @@ type BlockChain struct {
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
+	reorgFeed     event.Feed
 	logsFeed      event.Feed
 	blockProcFeed event.Feed
 	scope         event.SubscriptionScope
@@ -214,10 +233,18 @@ type BlockChain struct {
 	// future blocks are blocks added for later processing
 	futureBlocks *lru.Cache[common.Hash, *types.Block]
 
+	// witnesses are the execution witnesses of recently imported blocks, only
+	// collected if enabled via the cache config
+	witnesses *lru.Cache[common.Hash, *state.ExecutionWitness]
+
+	// importStats is the timing breakdown of recently imported blocks
+	importStats importStatsLog
+
 	wg            sync.WaitGroup //
 	quit          chan struct{}  // shutdown signal, closed in Stop.
 	stopping      atomic.Bool    // false if chain is running, true when stopped
 	procInterrupt atomic.Bool    // interrupt signaler for block processing
+	flushAborted  atomic.Bool    // whether Stop should skip persisting the recent state
 
 	engine     consensus.Engine
 	validator  Validator // Block and state validator interface
@@ -225,6 +252,11 @@ type BlockChain struct {
 	processor  Processor // Block transaction processor interface
 	forker     *ForkChoice
 	vmConfig   vm.Config
+
+	hooks     []*namedHooks // Block hooks run within the import pipeline
+	hooksLock sync.RWMutex  // Lock protecting the registered hooks
+
+	forkOverride atomic.Pointer[ForkOverride] // Signed fork override in effect, if any
 }
 
 // NewBlockChain returns a fully initialised block chain using information
@@ -234,10 +266,20 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 	if cacheConfig == nil {
 		cacheConfig = defaultCacheConfig
 	}
-	// Open trie database with provided config
+	// Open trie database with provided config. The state is held by a verkle
+	// tree if the chain is configured to run verkle from genesis on, snapshots
+	// are not supported in that case.
+	isVerkle := IsVerkleAtGenesis(db, genesis)
+	if isVerkle && cacheConfig.SnapshotLimit > 0 {
+		log.Warn("Snapshots are not supported by verkle state, disabling")
+		config := *cacheConfig
+		config.SnapshotLimit = 0
+		cacheConfig = &config
+	}
 	triedb := trie.NewDatabaseWithConfig(db, &trie.Config{
 		Cache:     cacheConfig.TrieCleanLimit,
 		Preimages: cacheConfig.Preimages,
+		IsVerkle:  isVerkle,
 	})
 	// Setup the genesis block, commit the provided genesis specification
 	// to database if the genesis block is not present yet, or load the
@@ -246,6 +288,9 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
 	}
+	if chainConfig.VerkleTime != nil && !isVerkle {
+		log.Warn("Verkle transition of an existing merkle state is not supported", "verkletime", *chainConfig.VerkleTime)
+	}
 	log.Info("")
 	log.Info(strings.Repeat("-", 153))
 	for _, line := range strings.Split(chainConfig.Description(), "\n") {
@@ -271,6 +316,13 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 		engine:        engine,
 		vmConfig:      vmConfig,
 	}
+	if cacheConfig.WitnessHistory > 0 {
+		bc.witnesses = lru.NewCache[common.Hash, *state.ExecutionWitness](cacheConfig.WitnessHistory)
+	}
+	if overrides != nil && overrides.ForkScheduling {
+		chainConfig.EnableForkScheduling()
+		bc.restoreForkOverride()
+	}
 	bc.flushInterval.Store(int64(cacheConfig.TrieTimeLimit))
 	bc.forker = NewForkChoice(bc, shouldPreserve)
 	bc.stateCache = state.NewDatabaseWithNodeDB(bc.db, bc.triedb)
@@ -401,6 +453,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 			Recovery:   recover,
 			NoBuild:    bc.cacheConfig.SnapshotNoBuild,
 			AsyncBuild: !bc.cacheConfig.SnapshotWait,
+			BuildRate:  bc.cacheConfig.SnapshotRate,
 		}
 		bc.snaps, _ = snapshot.New(snapconfig, bc.db, bc.triedb, head.Root)
 	}
@@ -426,6 +479,22 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 		bc.wg.Add(1)
 		go bc.maintainTxIndex()
 	}
+	// Start the address indexer if required. If it's disabled, drop the tail
+	// marker since blocks imported meanwhile won't be indexed, so the index is
+	// rebuilt from scratch once re-enabled.
+	if bc.cacheConfig.AddressIndex {
+		bc.wg.Add(1)
+		go bc.maintainAddressIndex()
+	} else if rawdb.ReadAddressIndexTail(bc.db) != nil {
+		log.Warn("Address index disabled, discarding its progress")
+		rawdb.DeleteAddressIndexTail(bc.db)
+	}
+	// Start the history expiry if required.
+	if bc.cacheConfig.HistoryCutoff > 0 {
+		log.Info("Enabled chain history expiry", "cutoff", bc.cacheConfig.HistoryCutoff)
+		bc.wg.Add(1)
+		go bc.maintainHistory()
+	}
 	return bc, nil
 }
 
@@ -724,6 +793,7 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, time uint64, root common.Ha
 			// removed in the hc.SetHead function.
 			rawdb.DeleteBody(db, hash, num)
 			rawdb.DeleteReceipts(db, hash, num)
+			rawdb.DeleteReceiptAccounting(db, hash, num)
 		}
 		// Todo(rjl493456442) txlookup, bloombits, etc
 	}
@@ -880,6 +950,9 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	rawdb.WriteHeadFastBlockHash(batch, block.Hash())
 	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
 	rawdb.WriteTxLookupEntriesByBlock(batch, block)
+	if bc.cacheConfig.AddressIndex {
+		bc.writeAddressIndex(batch, block)
+	}
 	rawdb.WriteHeadBlockHash(batch, block.Hash())
 
 	// Flush the whole batch into the disk, exit the node if failed
@@ -947,6 +1020,10 @@ func (bc *BlockChain) Stop() {
 		triedb := bc.triedb
 
 		for _, offset := range []uint64{0, 1, TriesInMemory - 1} {
+			if bc.flushAborted.Load() {
+				log.Warn("Skipping cached state flush, recent blocks will be reprocessed")
+				break
+			}
 			if number := bc.CurrentBlock().Number.Uint64(); number > offset {
 				recent := bc.GetBlockByNumber(number - offset)
 
@@ -956,7 +1033,7 @@ func (bc *BlockChain) Stop() {
 				}
 			}
 		}
-		if snapBase != (common.Hash{}) {
+		if snapBase != (common.Hash{}) && !bc.flushAborted.Load() {
 			log.Info("Writing snapshot state to disk", "root", snapBase)
 			if err := triedb.Commit(snapBase, true); err != nil {
 				log.Error("Failed to commit recent state trie", "err", err)
@@ -983,6 +1060,13 @@ func (bc *BlockChain) StopInsert() {
 	bc.procInterrupt.Store(true)
 }
 
+// AbortStateFlush makes a running or future Stop skip writing the cached recent
+// state tries to disk. The database stays consistent, but the blocks since the
+// last persisted state need to be reprocessed on the next startup.
+func (bc *BlockChain) AbortStateFlush() {
+	bc.flushAborted.Store(true)
+}
+
 // insertStopped returns true after StopInsert has been called.
 func (bc *BlockChain) insertStopped() bool {
 	return bc.procInterrupt.Load()
@@ -1130,6 +1214,9 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 			} else if rawdb.ReadTxIndexTail(bc.db) != nil {
 				rawdb.WriteTxLookupEntriesByBlock(batch, block)
 			}
+			if limit := bc.cacheConfig.AddressIndexLimit; bc.cacheConfig.AddressIndex && (limit == 0 || ancientLimit <= limit || block.NumberU64() >= ancientLimit-limit) {
+				bc.writeAddressIndex(batch, block)
+			}
 			stats.processed++
 
 			if batch.ValueSize() > ethdb.IdealBatchSize || i == len(blockChain)-1 {
@@ -1212,6 +1299,9 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 			rawdb.WriteBody(batch, block.Hash(), block.NumberU64(), block.Body())
 			rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receiptChain[i])
 			rawdb.WriteTxLookupEntriesByBlock(batch, block) // Always write tx indices for live blocks, we assume they are needed
+			if bc.cacheConfig.AddressIndex {
+				bc.writeAddressIndex(batch, block)
+			}
 
 			// Write everything belongs to the blocks into the database. So that
 			// we can ensure all components of body is completed(body, receipts,
@@ -1294,6 +1384,7 @@ func (bc *BlockChain) writeBlockWithoutState(block *types.Block, td *big.Int) (e
 	batch := bc.db.NewBatch()
 	rawdb.WriteTd(batch, block.Hash(), block.NumberU64(), td)
 	rawdb.WriteBlock(batch, block)
+	bc.hc.writeSkip(batch, block.Hash(), block.Header(), block.Header(), nil)
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
 	}
@@ -1331,7 +1422,11 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	blockBatch := bc.db.NewBatch()
 	rawdb.WriteTd(blockBatch, block.Hash(), block.NumberU64(), externTd)
 	rawdb.WriteBlock(blockBatch, block)
+	bc.hc.writeSkip(blockBatch, block.Hash(), block.Header(), block.Header(), nil)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
+	if bc.cacheConfig.ReceiptAccounting {
+		rawdb.WriteReceiptAccounting(blockBatch, block.Hash(), block.NumberU64(), receipts)
+	}
 	rawdb.WritePreimages(blockBatch, state.Preimages())
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
@@ -1461,7 +1556,7 @@ func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types
 // TODO after the transition, the future block shouldn't be kept. Because
 // it's not checked in the Geth side anymore.
 func (bc *BlockChain) addFutureBlock(block *types.Block) error {
-	max := uint64(time.Now().Unix() + maxTimeFutureBlocks)
+	max := uint64(time.Now().Unix() + int64(timesync.FutureTolerance(maxTimeFutureBlocks*time.Second)/time.Second))
 	if block.Time() > max {
 		return fmt.Errorf("future block timestamp %v > allowed %v", block.Time(), max)
 	}
@@ -1712,6 +1807,13 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 		statedb.StartPrefetcher("chain")
 		activeState = statedb
 
+		// Record the state accesses if execution witnesses are collected
+		var witness *state.Witness
+		if bc.witnesses != nil {
+			witness = state.NewWitness()
+			statedb.StartWitness(witness)
+		}
+
 		// If we have a followup block, run that against the current state to pre-cache
 		// transactions and probabilistically some of the account/storage trie nodes.
 		var followupInterrupt atomic.Bool
@@ -1734,7 +1836,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 		pstart := time.Now()
 		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
 		if err != nil {
-			bc.reportBlock(block, receipts, err)
+			bc.reportBadBlock(block, parent, statedb, receipts, err)
 			followupInterrupt.Store(true)
 			return it.index, err
 		}
@@ -1742,13 +1844,30 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 
 		vstart := time.Now()
 		if err := bc.validator.ValidateState(block, statedb, receipts, usedGas); err != nil {
-			bc.reportBlock(block, receipts, err)
+			bc.reportBadBlock(block, parent, statedb, receipts, err)
 			followupInterrupt.Store(true)
 			return it.index, err
 		}
 		vtime := time.Since(vstart)
 		proctime := time.Since(start) // processing + validation
 
+		// Assemble the execution witness against the pre-state before committing
+		if witness != nil {
+			if w, err := bc.buildWitness(witness, parent); err != nil {
+				log.Warn("Failed to build execution witness", "number", block.Number(), "hash", block.Hash(), "err", err)
+			} else {
+				bc.witnesses.Add(block.Hash(), w)
+				blockWitnessSizeGauge.Update(int64(w.Size()))
+			}
+		}
+
+		// Give the registered hooks a chance to reject the block before writing it
+		if err := bc.runPreInsertHooks(block, receipts, statedb); err != nil {
+			log.Error("Block rejected by import hook", "number", block.Number(), "hash", block.Hash(), "err", err)
+			followupInterrupt.Store(true)
+			return it.index, err
+		}
+
 		// Update the metrics touched during block processing and validation
 		accountReadTimer.Update(statedb.AccountReads)                   // Account reads are complete(in processing)
 		storageReadTimer.Update(statedb.StorageReads)                   // Storage reads are complete(in processing)
@@ -1786,9 +1905,20 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 		snapshotCommitTimer.Update(statedb.SnapshotCommits) // Snapshot commits are complete, we can mark them
 		triedbCommitTimer.Update(statedb.TrieDBCommits)     // Trie database commits are complete, we can mark them
 
-		blockWriteTimer.Update(time.Since(wstart) - statedb.AccountCommits - statedb.StorageCommits - statedb.SnapshotCommits - statedb.TrieDBCommits)
+		wtime := time.Since(wstart)
+		blockWriteTimer.Update(wtime - statedb.AccountCommits - statedb.StorageCommits - statedb.SnapshotCommits - statedb.TrieDBCommits)
 		blockInsertTimer.UpdateSince(start)
 
+		// Record the timing breakdown of the import for diagnostics
+		importStats := newBlockImportStats(block, statedb, ptime, vtime, wtime, time.Since(start))
+		blockStateReadTimer.Update(importStats.StateRead)
+		blockTrieHashTimer.Update(importStats.TrieHash)
+		blockCommitTimer.Update(importStats.Commit)
+		bc.importStats.add(importStats)
+
+		// Notify the registered hooks of the written block
+		bc.runPostInsertHooks(block, receipts, statedb, status == CanonStatTy)
+
 		// Report the import stats before returning the various results
 		stats.processed++
 		stats.usedGas += usedGas
@@ -2139,6 +2269,17 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Block) error {
 		// rewind the canonical chain to a lower point.
 		log.Error("Impossible reorg, please file an issue", "oldnum", oldBlock.Number(), "oldhash", oldBlock.Hash(), "oldblocks", len(oldChain), "newnum", newBlock.Number(), "newhash", newBlock.Hash(), "newblocks", len(newChain))
 	}
+	// Drop the address index entries of the old chain before the new chain is
+	// written, as both are keyed by block number and may share positions.
+	if bc.cacheConfig.AddressIndex && len(oldChain) > 0 {
+		batch := bc.db.NewBatch()
+		for _, block := range oldChain {
+			bc.deleteAddressIndex(batch, block)
+		}
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to delete stale address indexes", "err", err)
+		}
+	}
 	// Insert the new chain(except the head block(reverse order)),
 	// taking care of the proper incremental order.
 	for i := len(newChain) - 1; i >= 1; i-- {
@@ -2181,22 +2322,40 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Block) error {
 	// high, so the events are sent in batches of size around 512.
 
 	// Deleted logs + blocks:
-	var deletedLogs []*types.Log
+	var (
+		deletedLogs []*types.Log
+		removedLogs = make([]int, 0, len(oldChain))
+		replaced    = make(map[common.Hash]common.Hash, len(oldChain))
+	)
+	adopted := make(map[uint64]common.Hash, len(newChain))
+	for _, block := range newChain {
+		adopted[block.NumberU64()] = block.Hash()
+	}
+	for _, block := range oldChain {
+		if hash, ok := adopted[block.NumberU64()]; ok {
+			replaced[block.Hash()] = hash
+		}
+	}
+	removedEvent := func(logs []*types.Log) RemovedLogsEvent {
+		return RemovedLogsEvent{Logs: logs, Depth: uint64(len(oldChain)), Replaced: replaced}
+	}
 	for i := len(oldChain) - 1; i >= 0; i-- {
 		// Also send event for blocks removed from the canon chain.
 		bc.chainSideFeed.Send(ChainSideEvent{Block: oldChain[i]})
 
 		// Collect deleted logs for notification
-		if logs := bc.collectLogs(oldChain[i], true); len(logs) > 0 {
+		logs := bc.collectLogs(oldChain[i], true)
+		if len(logs) > 0 {
 			deletedLogs = append(deletedLogs, logs...)
 		}
+		removedLogs = append(removedLogs, len(logs))
 		if len(deletedLogs) > 512 {
-			bc.rmLogsFeed.Send(RemovedLogsEvent{deletedLogs})
+			bc.rmLogsFeed.Send(removedEvent(deletedLogs))
 			deletedLogs = nil
 		}
 	}
 	if len(deletedLogs) > 0 {
-		bc.rmLogsFeed.Send(RemovedLogsEvent{deletedLogs})
+		bc.rmLogsFeed.Send(removedEvent(deletedLogs))
 	}
 
 	// New logs:
@@ -2213,6 +2372,28 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Block) error {
 	if len(rebirthLogs) > 0 {
 		bc.logsFeed.Send(rebirthLogs)
 	}
+	// Announce the replaced segments of the chain in full
+	if len(oldChain) > 0 {
+		event := ReorgEvent{
+			Ancestor:    commonBlock.Header(),
+			Dropped:     make([]*types.Block, 0, len(oldChain)),
+			Adopted:     make([]*types.Block, 0, len(newChain)),
+			RemovedLogs: removedLogs,
+		}
+		for i := len(oldChain) - 1; i >= 0; i-- {
+			event.Dropped = append(event.Dropped, oldChain[i])
+		}
+		var adoptedTxs []common.Hash
+		for i := len(newChain) - 1; i >= 0; i-- {
+			event.Adopted = append(event.Adopted, newChain[i])
+			for _, tx := range newChain[i].Transactions() {
+				adoptedTxs = append(adoptedTxs, tx.Hash())
+			}
+		}
+		event.DroppedTxs = types.HashDifference(deletedTxs, adoptedTxs)
+		event.AdoptedTxs = types.HashDifference(adoptedTxs, deletedTxs)
+		bc.reorgFeed.Send(event)
+	}
 	return nil
 }
 
@@ -2336,37 +2517,49 @@ func (bc *BlockChain) skipBlock(err error, it *insertIterator) bool {
 func (bc *BlockChain) indexBlocks(tail *uint64, head uint64, done chan struct{}) {
 	defer func() { close(done) }()
 
+	// Determine the first block to be indexed. The transactions of the expired
+	// history can't be indexed, their bodies are gone.
+	from := uint64(0)
+	if bc.txLookupLimit != 0 && head >= bc.txLookupLimit {
+		from = head - bc.txLookupLimit + 1
+	}
+	cutoff := bc.HistoryPruningCutoff()
+	if from < cutoff {
+		from = cutoff
+	}
 	// The tail flag is not existent, it means the node is just initialized
 	// and all blocks(may from ancient store) are not indexed yet.
 	if tail == nil {
-		from := uint64(0)
-		if bc.txLookupLimit != 0 && head >= bc.txLookupLimit {
-			from = head - bc.txLookupLimit + 1
+		if from <= head {
+			rawdb.IndexTransactions(bc.db, from, head+1, bc.quit)
 		}
-		rawdb.IndexTransactions(bc.db, from, head+1, bc.quit)
 		return
 	}
-	// The tail flag is existent, but the whole chain is required to be indexed.
-	if bc.txLookupLimit == 0 || head < bc.txLookupLimit {
-		if *tail > 0 {
-			// It can happen when chain is rewound to a historical point which
-			// is even lower than the indexes tail, recap the indexing target
-			// to new head to avoid reading non-existent block bodies.
-			end := *tail
-			if end > head+1 {
-				end = head + 1
-			}
-			rawdb.IndexTransactions(bc.db, 0, end, bc.quit)
+	// The indices of the expired history can't be unindexed block by block,
+	// drop them by iterating the index instead.
+	if *tail < cutoff {
+		if !rawdb.PruneTxLookupEntries(bc.db, cutoff, bc.quit) {
+			return
 		}
-		return
+		rawdb.WriteTxIndexTail(bc.db, cutoff)
+		tail = &cutoff
 	}
 	// Update the transaction index to the new chain state
-	if head-bc.txLookupLimit+1 < *tail {
-		// Reindex a part of missing indices and rewind index tail to HEAD-limit
-		rawdb.IndexTransactions(bc.db, head-bc.txLookupLimit+1, *tail, bc.quit)
-	} else {
-		// Unindex a part of stale indices and forward index tail to HEAD-limit
-		rawdb.UnindexTransactions(bc.db, *tail, head-bc.txLookupLimit+1, bc.quit)
+	if from < *tail {
+		// Reindex a part of missing indices and rewind index tail to the first
+		// block. The chain might have been rewound to a historical point which
+		// is even lower than the indexes tail, recap the indexing target to
+		// the head to avoid reading non-existent block bodies.
+		end := *tail
+		if end > head+1 {
+			end = head + 1
+		}
+		if from < end {
+			rawdb.IndexTransactions(bc.db, from, end, bc.quit)
+		}
+	} else if from > *tail {
+		// Unindex a part of stale indices and forward index tail to the first block
+		rawdb.UnindexTransactions(bc.db, *tail, from, bc.quit)
 	}
 }
 
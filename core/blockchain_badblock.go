@@ -0,0 +1,108 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/rlp"
+)
+
+// BadBlockReport holds the diagnostics of a block which failed processing or
+// validation, allowing to reproduce the failure offline.
+type BadBlockReport struct {
+	Error    string                  `json:"error"`
+	Receipts []*types.Receipt        `json:"receipts"`  // Receipts of the processed transactions, consensus fields only
+	Diff     *state.StateDiff        `json:"stateDiff"` // State changes made before the failure
+	Witness  *state.ExecutionWitness `json:"witness"`   // Pre-state accessed by the block, nil if unavailable
+}
+
+// badBlockReportRLP is the database encoding of a bad block report.
+type badBlockReportRLP struct {
+	Error    string
+	Receipts []*types.ReceiptForStorage
+	Diff     *state.StateDiff
+	Witness  *state.ExecutionWitness `rlp:"nil"`
+}
+
+// reportBadBlock logs a bad block error after executing it, persisting the
+// block along with the state changes and the execution witness of the block.
+func (bc *BlockChain) reportBadBlock(block *types.Block, parent *types.Header, statedb *state.StateDB, receipts types.Receipts, err error) {
+	bc.reportBlock(block, receipts, err)
+
+	// Assemble the report, the processing might have been aborted halfway so
+	// hash the state mutations to gather them.
+	statedb.IntermediateRoot(bc.chainConfig.IsEIP158(block.Number()))
+	report := &badBlockReportRLP{
+		Error:    err.Error(),
+		Receipts: make([]*types.ReceiptForStorage, len(receipts)),
+		Diff:     statedb.Diff(),
+	}
+	for i, receipt := range receipts {
+		report.Receipts[i] = (*types.ReceiptForStorage)(receipt)
+	}
+	// Use the pre-state accesses if they were recorded, otherwise re-execute
+	// the block to record them.
+	witness := statedb.Witness()
+	if witness == nil {
+		if prestate, err := bc.StateAt(parent.Root); err == nil {
+			witness = state.NewWitness()
+			prestate.StartWitness(witness)
+			bc.processor.Process(block, prestate, bc.vmConfig)
+		}
+	}
+	if witness != nil {
+		if w, err := bc.buildWitness(witness, parent); err != nil {
+			log.Warn("Failed to build bad block witness", "number", block.Number(), "hash", block.Hash(), "err", err)
+		} else {
+			report.Witness = w
+		}
+	}
+	blob, err := rlp.EncodeToBytes(report)
+	if err != nil {
+		log.Error("Failed to encode bad block report", "number", block.Number(), "hash", block.Hash(), "err", err)
+		return
+	}
+	rawdb.WriteBadBlockReport(bc.db, block.Hash(), blob)
+}
+
+// BadBlockReport retrieves the diagnostics report of the bad block with the
+// given hash, nil if there's none.
+func (bc *BlockChain) BadBlockReport(hash common.Hash) *BadBlockReport {
+	blob := rawdb.ReadBadBlockReport(bc.db, hash)
+	if len(blob) == 0 {
+		return nil
+	}
+	var dec badBlockReportRLP
+	if err := rlp.DecodeBytes(blob, &dec); err != nil {
+		log.Error("Invalid bad block report", "hash", hash, "err", err)
+		return nil
+	}
+	report := &BadBlockReport{
+		Error:    dec.Error,
+		Receipts: make([]*types.Receipt, len(dec.Receipts)),
+		Diff:     dec.Diff,
+		Witness:  dec.Witness,
+	}
+	for i, receipt := range dec.Receipts {
+		report.Receipts[i] = (*types.Receipt)(receipt)
+	}
+	return report
+}
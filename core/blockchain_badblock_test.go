@@ -0,0 +1,90 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/consensus/ethash"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/params"
+)
+
+func TestBadBlockReport(t *testing.T) {
+	var (
+		key, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address   = crypto.PubkeyToAddress(key.PublicKey)
+		recipient = common.Address{0xaa}
+		gspec     = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(100000000000000000)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 2, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), recipient, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil), signer, key)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	// Corrupt the state root of the last block
+	header := blocks[1].Header()
+	header.Root = common.Hash{0x01}
+	bad := types.NewBlockWithHeader(header).WithBody(blocks[1].Transactions(), nil)
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(types.Blocks{blocks[0], bad}); err == nil {
+		t.Fatal("bad block imported")
+	}
+	if report := chain.BadBlockReport(blocks[0].Hash()); report != nil {
+		t.Fatal("report of a valid block")
+	}
+	report := chain.BadBlockReport(bad.Hash())
+	if report == nil {
+		t.Fatal("missing bad block report")
+	}
+	if report.Error == "" {
+		t.Error("missing error in the report")
+	}
+	if len(report.Receipts) != 1 || report.Receipts[0].CumulativeGasUsed != params.TxGas {
+		t.Errorf("receipts mismatch: %v", report.Receipts)
+	}
+	// The sender, the recipient and the coinbase are changed by the block
+	changed := make(map[common.Address]bool)
+	for _, account := range report.Diff.Accounts {
+		changed[account.Address] = true
+	}
+	for _, addr := range []common.Address{address, recipient, bad.Coinbase()} {
+		if !changed[addr] {
+			t.Errorf("account %x missing from the state diff", addr)
+		}
+	}
+	if report.Witness == nil || len(report.Witness.State) == 0 || len(report.Witness.Headers) == 0 {
+		t.Fatalf("missing execution witness: %v", report.Witness)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/types"
+)
+
+// importStatsLimit is the number of recently imported blocks to retain the
+// timing breakdown for.
+const importStatsLimit = 256
+
+// BlockImportStats is the timing breakdown of a single block import. All the
+// durations are reported in nanoseconds.
+type BlockImportStats struct {
+	Number  uint64      `json:"number"`
+	Hash    common.Hash `json:"hash"`
+	Txs     int         `json:"txs"`
+	GasUsed uint64      `json:"gasUsed"`
+
+	Execution  time.Duration `json:"execution"`  // EVM processing, excluding state reads
+	StateRead  time.Duration `json:"stateRead"`  // Account and storage reads, from snapshot or tries
+	Validation time.Duration `json:"validation"` // Block validation, excluding trie updates and hashing
+	TrieUpdate time.Duration `json:"trieUpdate"` // Account and storage trie updates
+	TrieHash   time.Duration `json:"trieHash"`   // Account and storage trie hashing
+	Commit     time.Duration `json:"commit"`     // Account, storage trie and trie database commits
+	Snapshot   time.Duration `json:"snapshot"`   // Snapshot tree update
+	Write      time.Duration `json:"write"`      // Block, receipts and chain head writes
+	Total      time.Duration `json:"total"`      // Overall import time, including the above
+}
+
+// newBlockImportStats assembles the timing breakdown of a block import from the
+// measured processing, validation and write times, along with the state access
+// times gathered by the state database.
+func newBlockImportStats(block *types.Block, statedb *state.StateDB, ptime, vtime, wtime, total time.Duration) *BlockImportStats {
+	var (
+		stateRead  = statedb.AccountReads + statedb.StorageReads + statedb.SnapshotAccountReads + statedb.SnapshotStorageReads
+		trieUpdate = statedb.AccountUpdates + statedb.StorageUpdates
+		trieHash   = statedb.AccountHashes + statedb.StorageHashes
+		commit     = statedb.AccountCommits + statedb.StorageCommits + statedb.TrieDBCommits
+	)
+	return &BlockImportStats{
+		Number:     block.NumberU64(),
+		Hash:       block.Hash(),
+		Txs:        len(block.Transactions()),
+		GasUsed:    block.GasUsed(),
+		Execution:  ptime - stateRead,
+		StateRead:  stateRead,
+		Validation: vtime - (trieUpdate + trieHash),
+		TrieUpdate: trieUpdate,
+		TrieHash:   trieHash,
+		Commit:     commit,
+		Snapshot:   statedb.SnapshotCommits,
+		Write:      wtime - commit - statedb.SnapshotCommits,
+		Total:      total,
+	}
+}
+
+// importStatsLog is a bounded log of the timing breakdowns of the recently
+// imported blocks, oldest first.
+type importStatsLog struct {
+	stats []*BlockImportStats
+	lock  sync.RWMutex
+}
+
+// add appends the stats of a newly imported block, dropping the oldest entry
+// if the log is full.
+func (l *importStatsLog) add(stats *BlockImportStats) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if len(l.stats) >= importStatsLimit {
+		copy(l.stats, l.stats[1:])
+		l.stats = l.stats[:len(l.stats)-1]
+	}
+	l.stats = append(l.stats, stats)
+}
+
+// ImportStats retrieves the timing breakdown of importing the block with the
+// given hash. Nil is returned if the block is not among the recently imported
+// ones.
+func (bc *BlockChain) ImportStats(hash common.Hash) *BlockImportStats {
+	bc.importStats.lock.RLock()
+	defer bc.importStats.lock.RUnlock()
+
+	for i := len(bc.importStats.stats) - 1; i >= 0; i-- {
+		if stats := bc.importStats.stats[i]; stats.Hash == hash {
+			return stats
+		}
+	}
+	return nil
+}
+
+// RecentImportStats retrieves the timing breakdowns of the last count imported
+// blocks, newest first.
+func (bc *BlockChain) RecentImportStats(count int) []*BlockImportStats {
+	bc.importStats.lock.RLock()
+	defer bc.importStats.lock.RUnlock()
+
+	if count > len(bc.importStats.stats) {
+		count = len(bc.importStats.stats)
+	}
+	recent := make([]*BlockImportStats, 0, count)
+	for i := len(bc.importStats.stats) - 1; i >= len(bc.importStats.stats)-count; i-- {
+		recent = append(recent, bc.importStats.stats[i])
+	}
+	return recent
+}
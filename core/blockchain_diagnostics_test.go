@@ -0,0 +1,88 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/consensus/ethash"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/params"
+)
+
+// Tests that the timing breakdown of imported blocks is recorded and retained
+// for the recent blocks only.
+func TestBlockImportStats(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(100000000000000000)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), importStatsLimit+8, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{0xaa}, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil), signer, key)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// The oldest imports are dropped from the log
+	if stats := chain.ImportStats(blocks[0].Hash()); stats != nil {
+		t.Fatalf("stats of block %d retained beyond the limit", blocks[0].NumberU64())
+	}
+	head := blocks[len(blocks)-1]
+	stats := chain.ImportStats(head.Hash())
+	if stats == nil {
+		t.Fatal("missing stats of the head block")
+	}
+	if stats.Number != head.NumberU64() || stats.Txs != 1 || stats.GasUsed != params.TxGas {
+		t.Fatalf("stats mismatch: number %d txs %d gas %d", stats.Number, stats.Txs, stats.GasUsed)
+	}
+	if stats.Total <= 0 || stats.Total < stats.Execution+stats.Commit {
+		t.Fatalf("inconsistent timings: total %v execution %v commit %v", stats.Total, stats.Execution, stats.Commit)
+	}
+	// The recent stats are returned newest first
+	recent := chain.RecentImportStats(3)
+	if len(recent) != 3 {
+		t.Fatalf("recent stats count mismatch: have %d, want 3", len(recent))
+	}
+	for i, stats := range recent {
+		if want := head.NumberU64() - uint64(i); stats.Number != want {
+			t.Errorf("recent stats %d: number mismatch, have %d, want %d", i, stats.Number, want)
+		}
+	}
+	if recent := chain.RecentImportStats(2 * importStatsLimit); len(recent) != importStatsLimit {
+		t.Fatalf("retained stats count mismatch: have %d, want %d", len(recent), importStatsLimit)
+	}
+}
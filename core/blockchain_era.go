@@ -0,0 +1,246 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/trie"
+)
+
+// era1ImportBatch is the number of blocks imported from era1 files at once.
+const era1ImportBatch = 1024
+
+// ExportEra1 writes the canonical blocks from first to last into era1 files in
+// dir, one file per epoch of rawdb.Era1MaxBlocks blocks, and returns the paths of
+// the written files. Only finalized history can be exported, that is the blocks
+// up to the finalized one, or up to the last frozen one on chains without
+// finality.
+func (bc *BlockChain) ExportEra1(dir string, network string, first, last uint64) ([]string, error) {
+	if first > last {
+		return nil, fmt.Errorf("invalid export range [%d, %d]", first, last)
+	}
+	limit, ok := bc.finalizedHistory()
+	if !ok || last > limit {
+		return nil, fmt.Errorf("block #%d not finalized", last)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for start := first; start <= last; {
+		var (
+			epoch = start / rawdb.Era1MaxBlocks
+			end   = (epoch+1)*rawdb.Era1MaxBlocks - 1
+		)
+		if end > last {
+			end = last
+		}
+		path, err := bc.exportEra1(dir, network, epoch, start, end)
+		if err != nil {
+			return paths, err
+		}
+		log.Info("Exported era1 file", "path", path, "first", start, "last", end)
+		paths = append(paths, path)
+		start = end + 1
+	}
+	return paths, nil
+}
+
+// finalizedHistory returns the number of the last block of the immutable history
+// of the chain, if any.
+func (bc *BlockChain) finalizedHistory() (uint64, bool) {
+	if final := bc.CurrentFinalBlock(); final != nil {
+		return final.Number.Uint64(), true
+	}
+	if frozen, err := bc.db.Ancients(); err == nil && frozen > 0 {
+		return frozen - 1, true
+	}
+	return 0, false
+}
+
+// exportEra1 writes the blocks from first to last of an epoch into an era1 file
+// named after its accumulator root.
+func (bc *BlockChain) exportEra1(dir string, network string, epoch, first, last uint64) (string, error) {
+	f, err := os.CreateTemp(dir, "export-*.era1")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+	var (
+		w       = bufio.NewWriter(f)
+		builder = rawdb.NewEra1Builder(w)
+	)
+	for number := first; number <= last; number++ {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			return "", fmt.Errorf("block #%d missing", number)
+		}
+		receipts := bc.GetReceiptsByHash(block.Hash())
+		if receipts == nil && len(block.Transactions()) > 0 {
+			return "", fmt.Errorf("receipts of block #%d missing", number)
+		}
+		td := bc.GetTd(block.Hash(), number)
+		if td == nil {
+			return "", fmt.Errorf("total difficulty of block #%d missing", number)
+		}
+		if err := builder.Add(block, receipts, td); err != nil {
+			return "", err
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		return "", err
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	if err := f.Sync(); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, rawdb.Era1Filename(network, epoch, root))
+	if err := os.Rename(f.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ImportEra1 imports the history archived in the given era1 files, which must be
+// ordered and continue the local chain. The blocks are not executed, only their
+// headers are verified along with their bodies and receipts, so a node can be
+// bootstrapped with the history and sync the state afterwards. Blocks already
+// present in the local chain are skipped.
+func (bc *BlockChain) ImportEra1(paths []string) error {
+	for _, path := range paths {
+		if err := bc.importEra1(path); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+	}
+	return nil
+}
+
+// importEra1 imports the blocks of a single era1 file.
+func (bc *BlockChain) importEra1(path string) error {
+	era, err := rawdb.OpenEra1(path)
+	if err != nil {
+		return err
+	}
+	defer era.Close()
+
+	if err := era.Verify(); err != nil {
+		return err
+	}
+	var (
+		blocks   = make(types.Blocks, 0, era1ImportBatch)
+		receipts = make([]types.Receipts, 0, era1ImportBatch)
+		tds      = make([]*big.Int, 0, era1ImportBatch)
+	)
+	for number := era.Start(); number < era.Start()+era.Count(); number++ {
+		block, err := era.ReadBlock(number)
+		if err != nil {
+			return err
+		}
+		if number <= bc.CurrentSnapBlock().Number.Uint64() {
+			if hash := rawdb.ReadCanonicalHash(bc.db, number); hash != block.Hash() {
+				return fmt.Errorf("block #%d conflicts with local chain: have %x, archived %x", number, hash, block.Hash())
+			}
+			continue
+		}
+		blockReceipts, err := era.ReadReceipts(number)
+		if err != nil {
+			return err
+		}
+		td, err := era.ReadTd(number)
+		if err != nil {
+			return err
+		}
+		if err := verifyArchivedBlock(block, blockReceipts); err != nil {
+			return err
+		}
+		blocks, receipts, tds = append(blocks, block), append(receipts, blockReceipts), append(tds, td)
+		if len(blocks) == era1ImportBatch {
+			if err := bc.importArchivedBlocks(blocks, receipts, tds); err != nil {
+				return err
+			}
+			blocks, receipts, tds = blocks[:0], receipts[:0], tds[:0]
+		}
+	}
+	if len(blocks) > 0 {
+		return bc.importArchivedBlocks(blocks, receipts, tds)
+	}
+	return nil
+}
+
+// importArchivedBlocks inserts a batch of archived blocks along with their
+// receipts, writing them into the ancient store if available.
+func (bc *BlockChain) importArchivedBlocks(blocks types.Blocks, receipts []types.Receipts, tds []*big.Int) error {
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	if _, err := bc.InsertHeaderChain(headers); err != nil {
+		return err
+	}
+	for i, block := range blocks {
+		if td := bc.GetTd(block.Hash(), block.NumberU64()); td == nil || td.Cmp(tds[i]) != 0 {
+			return fmt.Errorf("total difficulty mismatch of block #%d: have %v, archived %v", block.NumberU64(), td, tds[i])
+		}
+	}
+	var ancientLimit uint64
+	if _, err := bc.db.Ancients(); err == nil {
+		ancientLimit = blocks[len(blocks)-1].NumberU64()
+	}
+	if _, err := bc.InsertReceiptChain(blocks, receipts, ancientLimit); err != nil {
+		return err
+	}
+	if head := bc.CurrentSnapBlock(); head.Hash() != blocks[len(blocks)-1].Hash() {
+		return errors.New("archived blocks not canonical")
+	}
+	return nil
+}
+
+// verifyArchivedBlock checks that the body and receipts of an archived block
+// match its header.
+func verifyArchivedBlock(block *types.Block, receipts types.Receipts) error {
+	header := block.Header()
+	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
+		return fmt.Errorf("transaction root mismatch of block #%d: have %x, want %x", header.Number, hash, header.TxHash)
+	}
+	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+		return fmt.Errorf("uncle root mismatch of block #%d: have %x, want %x", header.Number, hash, header.UncleHash)
+	}
+	if header.WithdrawalsHash != nil {
+		if hash := types.DeriveSha(types.Withdrawals(block.Withdrawals()), trie.NewStackTrie(nil)); hash != *header.WithdrawalsHash {
+			return fmt.Errorf("withdrawals root mismatch of block #%d: have %x, want %x", header.Number, hash, *header.WithdrawalsHash)
+		}
+	}
+	if hash := types.DeriveSha(receipts, trie.NewStackTrie(nil)); hash != header.ReceiptHash {
+		return fmt.Errorf("receipt root mismatch of block #%d: have %x, want %x", header.Number, hash, header.ReceiptHash)
+	}
+	return nil
+}
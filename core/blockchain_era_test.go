@@ -0,0 +1,120 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/consensus/ethash"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/params"
+)
+
+// Tests that finalized history can be exported into era1 files and imported
+// into a fresh node without executing it.
+func TestEra1ExportImport(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 32, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), common.Address{0x01}, big.NewInt(1), params.TxGas, gen.header.BaseFee, nil), signer, key)
+		gen.AddTx(tx)
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	dir := t.TempDir()
+	if _, err := chain.ExportEra1(dir, "test", 0, 20); err == nil {
+		t.Fatal("exported history without finality")
+	}
+	chain.SetFinalized(blocks[19].Header())
+	if _, err := chain.ExportEra1(dir, "test", 0, 21); err == nil {
+		t.Fatal("exported history beyond the finalized block")
+	}
+	paths, err := chain.ExportEra1(dir, "test", 0, 20)
+	if err != nil {
+		t.Fatalf("failed to export history: %v", err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("wrong number of era1 files: have %d, want 1", len(paths))
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("leftover files in export directory: %v", files)
+	}
+	era, err := rawdb.OpenEra1(paths[0])
+	if err != nil {
+		t.Fatalf("failed to open era1 file: %v", err)
+	}
+	root, _ := era.Accumulator()
+	era.Close()
+	if want := filepath.Join(dir, rawdb.Era1Filename("test", 0, root)); paths[0] != want {
+		t.Fatalf("era1 file name mismatch: have %s, want %s", paths[0], want)
+	}
+	// Import the history into a fresh node with an ancient store
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	imported, err := NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer imported.Stop()
+
+	if err := imported.ImportEra1(paths); err != nil {
+		t.Fatalf("failed to import history: %v", err)
+	}
+	if head := imported.CurrentSnapBlock(); head.Hash() != blocks[19].Hash() {
+		t.Fatalf("snap head mismatch: have #%d [%x], want #%d [%x]", head.Number, head.Hash(), blocks[19].Number(), blocks[19].Hash())
+	}
+	if frozen, _ := db.Ancients(); frozen != 21 {
+		t.Fatalf("ancient store size mismatch: have %d, want %d", frozen, 21)
+	}
+	for _, block := range blocks[:20] {
+		if have := imported.GetBlockByNumber(block.NumberU64()); have == nil || have.Hash() != block.Hash() {
+			t.Fatalf("block #%d not imported", block.NumberU64())
+		}
+		if have, want := imported.GetReceiptsByHash(block.Hash()), chain.GetReceiptsByHash(block.Hash()); len(have) != len(want) || have[0].TxHash != want[0].TxHash {
+			t.Fatalf("receipts of block #%d not imported", block.NumberU64())
+		}
+	}
+	// Importing the same history again is a no-op
+	if err := imported.ImportEra1(paths); err != nil {
+		t.Fatalf("failed to reimport history: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/metrics"
+)
+
+var (
+	blockHookPreTimer  = metrics.NewRegisteredTimer("chain/hooks/pre", nil)
+	blockHookPostTimer = metrics.NewRegisteredTimer("chain/hooks/post", nil)
+)
+
+// HookState is a read-only view of the state a block was executed into, handed
+// to the block hooks.
+type HookState interface {
+	Exist(addr common.Address) bool
+	GetBalance(addr common.Address) *big.Int
+	GetNonce(addr common.Address) uint64
+	GetCode(addr common.Address) []byte
+	GetCodeHash(addr common.Address) common.Hash
+	GetState(addr common.Address, key common.Hash) common.Hash
+}
+
+// BlockHooks are callbacks run by the chain within its import pipeline, allowing
+// indexers and chain specific logic to act on imported blocks without modifying
+// the chain itself. Hooks only run for blocks executed by the local node, they
+// are not invoked for blocks inserted without execution, e.g. during snap sync.
+//
+// Hooks run synchronously on the import path, slow hooks slow down the import.
+type BlockHooks struct {
+	// PreInsert is invoked after a block is executed and validated, but before
+	// it is written into the database. Returning an error rejects the block and
+	// aborts the import.
+	PreInsert func(block *types.Block, receipts types.Receipts, state HookState) error
+
+	// PostInsert is invoked after a block and its state are written into the
+	// database, along with whether the block became the new canonical head.
+	PostInsert func(block *types.Block, receipts types.Receipts, state HookState, canonical bool)
+}
+
+// namedHooks is a set of block hooks along with the name they were registered
+// with, used for error reporting.
+type namedHooks struct {
+	name  string
+	hooks *BlockHooks
+}
+
+// RegisterHooks adds a set of block hooks to the import pipeline of the chain,
+// run after the previously registered ones. The returned function removes the
+// hooks again.
+func (bc *BlockChain) RegisterHooks(name string, hooks *BlockHooks) func() {
+	bc.hooksLock.Lock()
+	defer bc.hooksLock.Unlock()
+
+	entry := &namedHooks{name: name, hooks: hooks}
+	bc.hooks = append(bc.hooks, entry)
+	log.Info("Registered block import hooks", "name", name)
+
+	return func() {
+		bc.hooksLock.Lock()
+		defer bc.hooksLock.Unlock()
+
+		for i, have := range bc.hooks {
+			if have == entry {
+				bc.hooks = append(bc.hooks[:i:i], bc.hooks[i+1:]...)
+				log.Info("Unregistered block import hooks", "name", name)
+				return
+			}
+		}
+	}
+}
+
+// runPreInsertHooks runs the pre-insert hooks for an executed block, stopping at
+// the first one rejecting the block.
+func (bc *BlockChain) runPreInsertHooks(block *types.Block, receipts types.Receipts, state HookState) error {
+	bc.hooksLock.RLock()
+	defer bc.hooksLock.RUnlock()
+
+	if len(bc.hooks) == 0 {
+		return nil
+	}
+	defer func(start time.Time) { blockHookPreTimer.UpdateSince(start) }(time.Now())
+
+	for _, entry := range bc.hooks {
+		if entry.hooks.PreInsert == nil {
+			continue
+		}
+		if err := entry.hooks.PreInsert(block, receipts, state); err != nil {
+			return fmt.Errorf("block rejected by %s hook: %w", entry.name, err)
+		}
+	}
+	return nil
+}
+
+// runPostInsertHooks runs the post-insert hooks for a written block.
+func (bc *BlockChain) runPostInsertHooks(block *types.Block, receipts types.Receipts, state HookState, canonical bool) {
+	bc.hooksLock.RLock()
+	defer bc.hooksLock.RUnlock()
+
+	if len(bc.hooks) == 0 {
+		return
+	}
+	defer func(start time.Time) { blockHookPostTimer.UpdateSince(start) }(time.Now())
+
+	for _, entry := range bc.hooks {
+		if entry.hooks.PostInsert != nil {
+			entry.hooks.PostInsert(block, receipts, state, canonical)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/consensus/ethash"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/params"
+)
+
+// Tests that block hooks are run on import with the receipts and post-state of
+// the blocks, and that pre-insert hooks can reject blocks.
+func TestBlockHooks(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   GenesisAlloc{address: {Balance: big.NewInt(params.Ether)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer    = types.LatestSigner(gspec.Config)
+		recipient = common.Address{0xaa}
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 4, func(i int, block *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(address), recipient, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil), signer, key)
+		block.AddTx(tx)
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	var (
+		pre      []uint64
+		post     []uint64
+		balances []int64
+		reject   = errors.New("rejected")
+	)
+	unregister := chain.RegisterHooks("test", &BlockHooks{
+		PreInsert: func(block *types.Block, receipts types.Receipts, state HookState) error {
+			if len(receipts) != len(block.Transactions()) {
+				t.Errorf("block %d: receipt count mismatch: have %d, want %d", block.NumberU64(), len(receipts), len(block.Transactions()))
+			}
+			if block.NumberU64() == 3 {
+				return reject
+			}
+			pre = append(pre, block.NumberU64())
+			return nil
+		},
+		PostInsert: func(block *types.Block, receipts types.Receipts, state HookState, canonical bool) {
+			if !canonical {
+				t.Errorf("block %d: not reported canonical", block.NumberU64())
+			}
+			post = append(post, block.NumberU64())
+			balances = append(balances, state.GetBalance(recipient).Int64())
+		},
+	})
+	if n, err := chain.InsertChain(blocks); !errors.Is(err, reject) || n != 2 {
+		t.Fatalf("rejected import mismatch: have %d/%v, want 2/%v", n, err, reject)
+	}
+	if head := chain.CurrentBlock().Number.Uint64(); head != 2 {
+		t.Errorf("head mismatch: have %d, want 2", head)
+	}
+	if len(pre) != 2 || len(post) != 2 || balances[0] != 1000 || balances[1] != 2000 {
+		t.Errorf("hook invocations mismatch: pre %v, post %v, balances %v", pre, post, balances)
+	}
+	// Unregistered hooks should not be invoked anymore
+	unregister()
+	if _, err := chain.InsertChain(blocks[2:]); err != nil {
+		t.Fatalf("failed to import blocks without hooks: %v", err)
+	}
+	if len(post) != 2 {
+		t.Errorf("unregistered hook invoked: %v", post)
+	}
+}
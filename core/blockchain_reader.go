@@ -225,10 +225,24 @@ func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 	if receipts == nil {
 		return nil
 	}
+	if bc.cacheConfig.ReceiptAccounting {
+		rawdb.ReadReceiptAccounting(bc.db, hash, *number, receipts)
+	}
 	bc.receiptsCache.Add(hash, receipts)
 	return receipts
 }
 
+// ExecutionWitness retrieves the execution witness recorded while importing the
+// block with the given hash. Nil is returned if witness collection is disabled
+// or the block is not among the recently imported ones.
+func (bc *BlockChain) ExecutionWitness(hash common.Hash) *state.ExecutionWitness {
+	if bc.witnesses == nil {
+		return nil
+	}
+	witness, _ := bc.witnesses.Get(hash)
+	return witness
+}
+
 // GetUnclesInChain retrieves all the uncles from a given block backwards until
 // a specific distance is reached.
 func (bc *BlockChain) GetUnclesInChain(block *types.Block, length int) []*types.Header {
@@ -270,6 +284,40 @@ func (bc *BlockChain) GetTransactionLookup(hash common.Hash) *rawdb.LegacyTxLook
 	return lookup
 }
 
+// AddressTransactions retrieves the locations of the canonical transactions sent
+// by or to the given address, starting at block number from. At least limit
+// entries are returned if available, but the transactions of the last block are
+// never split, so the next page can be requested from the following block.
+func (bc *BlockChain) AddressTransactions(address common.Address, from uint64, limit int) []rawdb.AddressTxEntry {
+	var (
+		entries []rawdb.AddressTxEntry
+		number  uint64
+		body    *types.Body
+	)
+	if limit <= 0 {
+		return nil
+	}
+	rawdb.IterateAddressTxEntries(bc.db, address, from, func(entry rawdb.AddressTxEntry) bool {
+		if len(entries) >= limit && entry.BlockNumber != entries[len(entries)-1].BlockNumber {
+			return false
+		}
+		// Entries of blocks reorged out or rewound by a SetHead may linger
+		// until overwritten, only return the ones matching the canonical chain.
+		if body == nil || number != entry.BlockNumber {
+			number, body = entry.BlockNumber, nil
+			if hash := bc.GetCanonicalHash(number); hash != (common.Hash{}) {
+				body = bc.GetBody(hash)
+			}
+		}
+		if body == nil || int(entry.Index) >= len(body.Transactions) || body.Transactions[entry.Index].Hash() != entry.Hash {
+			return true
+		}
+		entries = append(entries, entry)
+		return true
+	})
+	return entries
+}
+
 // GetTd retrieves a block's total difficulty in the canonical chain from the
 // database by hash and number, caching it if found.
 func (bc *BlockChain) GetTd(hash common.Hash, number uint64) *big.Int {
@@ -401,6 +449,11 @@ func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Su
 	return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
 }
 
+// SubscribeReorgEvent registers a subscription of ReorgEvent.
+func (bc *BlockChain) SubscribeReorgEvent(ch chan<- ReorgEvent) event.Subscription {
+	return bc.scope.Track(bc.reorgFeed.Subscribe(ch))
+}
+
 // SubscribeLogsEvent registers a subscription of []*types.Log.
 func (bc *BlockChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return bc.scope.Track(bc.logsFeed.Subscribe(ch))
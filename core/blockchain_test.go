@@ -17,11 +17,13 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -39,7 +41,9 @@ import (
 	"github.com/gorievm/go-gori/eth/tracers/logger"
 	"github.com/gorievm/go-gori/ethdb"
 	"github.com/gorievm/go-gori/params"
+	"github.com/gorievm/go-gori/rlp"
 	"github.com/gorievm/go-gori/trie"
+	"github.com/holiman/uint256"
 )
 
 // So we can deterministically seed different blockchains
@@ -1124,6 +1128,11 @@ func TestLogReorgs(t *testing.T) {
 		ev := <-rmLogsCh
 		if len(ev.Logs) == 0 {
 			t.Error("expected logs")
+		} else if replacing := ev.Replaced[ev.Logs[0].BlockHash]; replacing != chain[1].Hash() {
+			t.Errorf("wrong replacing block: have %x, want %x", replacing, chain[1].Hash())
+		}
+		if ev.Depth != 1 {
+			t.Errorf("wrong reorg depth: have %d, want 1", ev.Depth)
 		}
 		close(done)
 	}()
@@ -1390,6 +1399,106 @@ done:
 	}
 }
 
+// Tests that a reorg posts the full dropped and adopted segments of the chain,
+// along with the transactions and logs they affect.
+func TestReorgEvent(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		logger  = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				addr1:  {Balance: big.NewInt(10000000000000000)},
+				logger: {Balance: common.Big0, Code: []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG0)}},
+			},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	blockchain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer blockchain.Stop()
+
+	// The original chain emits a log in every block, the replacement shares the
+	// transaction of its first block. The original blocks are slightly harder to
+	// avoid a tie of the total difficulty at the same length.
+	_, chain, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 3, func(i int, gen *BlockGen) {
+		gen.OffsetTime(-9)
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), logger, new(big.Int), 100000, gen.header.BaseFee, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	_, replacement, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 4, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(common.Address{1})
+		if i == 0 {
+			gen.AddTx(chain[0].Transactions()[0])
+			return
+		}
+		tx, _ := types.SignTx(types.NewContractCreation(gen.TxNonce(addr1), new(big.Int), 100000, gen.header.BaseFee, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	reorgCh := make(chan ReorgEvent, 1)
+	sub := blockchain.SubscribeReorgEvent(reorgCh)
+	defer sub.Unsubscribe()
+
+	if _, err := blockchain.InsertChain(replacement); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	var ev ReorgEvent
+	select {
+	case ev = <-reorgCh:
+	case <-time.After(time.Second):
+		t.Fatal("no reorg event posted")
+	}
+	if ev.Ancestor.Hash() != blockchain.Genesis().Hash() {
+		t.Errorf("ancestor mismatch: have %x, want %x", ev.Ancestor.Hash(), blockchain.Genesis().Hash())
+	}
+	hashes := func(blocks []*types.Block) []common.Hash {
+		var hashes []common.Hash
+		for _, block := range blocks {
+			hashes = append(hashes, block.Hash())
+		}
+		return hashes
+	}
+	if have, want := hashes(ev.Dropped), hashes(chain); !reflect.DeepEqual(have, want) {
+		t.Errorf("dropped blocks mismatch: have %x, want %x", have, want)
+	}
+	if have, want := hashes(ev.Adopted), hashes(replacement); !reflect.DeepEqual(have, want) {
+		t.Errorf("adopted blocks mismatch: have %x, want %x", have, want)
+	}
+	txs := func(blocks []*types.Block) map[common.Hash]bool {
+		txs := make(map[common.Hash]bool)
+		for _, block := range blocks {
+			for _, tx := range block.Transactions() {
+				txs[tx.Hash()] = true
+			}
+		}
+		return txs
+	}
+	set := func(hashes []common.Hash) map[common.Hash]bool {
+		set := make(map[common.Hash]bool)
+		for _, hash := range hashes {
+			set[hash] = true
+		}
+		return set
+	}
+	if have, want := set(ev.DroppedTxs), txs(chain[1:]); !reflect.DeepEqual(have, want) {
+		t.Errorf("dropped transactions mismatch: have %v, want %v", have, want)
+	}
+	if have, want := set(ev.AdoptedTxs), txs(replacement[1:]); !reflect.DeepEqual(have, want) {
+		t.Errorf("adopted transactions mismatch: have %v, want %v", have, want)
+	}
+	if have, want := ev.RemovedLogs, []int{1, 1, 1}; !reflect.DeepEqual(have, want) {
+		t.Errorf("removed logs mismatch: have %v, want %v", have, want)
+	}
+	select {
+	case ev := <-reorgCh:
+		t.Errorf("unexpected reorg event: %v", ev)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
 // Tests if the canonical block can be fetched from the database during chain insertion.
 func TestCanonicalBlockRetrieval(t *testing.T) {
 	_, gspec, blockchain, err := newCanonical(ethash.NewFaker(), 0, true)
@@ -3506,6 +3615,85 @@ func TestEIP2718Transition(t *testing.T) {
 	}
 }
 
+// Tests that the gas refund and the access list savings of transactions are
+// recorded along their receipts if requested.
+func TestReceiptAccounting(t *testing.T) {
+	var (
+		bb     = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		cc     = common.HexToAddress("0x000000000000000000000000000000000000cccc")
+		dd     = common.HexToAddress("0x000000000000000000000000000000000000dddd")
+		engine = ethash.NewFaker()
+
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				address: {Balance: big.NewInt(1000000000000000)},
+				// The address 0xBBBB reads the balance of 0xDDDD, sloads 0x01
+				// and clears 0x00
+				bb: {
+					Code: []byte{
+						byte(vm.PUSH2), 0xdd, 0xdd, byte(vm.BALANCE), byte(vm.POP),
+						byte(vm.PUSH1), 0x01, byte(vm.SLOAD), byte(vm.POP),
+						byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+					},
+					Storage: map[common.Hash]common.Hash{{}: {0x01}},
+					Balance: big.NewInt(0),
+				},
+			},
+		}
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 1, func(i int, b *BlockGen) {
+		tx, _ := types.SignNewTx(key, types.LatestSigner(gspec.Config), &types.AccessListTx{
+			ChainID:  gspec.Config.ChainID,
+			Nonce:    0,
+			To:       &bb,
+			Gas:      100000,
+			GasPrice: b.header.BaseFee,
+			AccessList: types.AccessList{
+				{Address: bb, StorageKeys: []common.Hash{{}, common.HexToHash("0x01")}},
+				{Address: cc},
+				{Address: dd},
+			},
+		})
+		b.AddTx(tx)
+	})
+	for _, record := range []bool{false, true} {
+		chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), &CacheConfig{
+			TrieCleanLimit:    256,
+			TrieDirtyLimit:    256,
+			TrieTimeLimit:     5 * time.Minute,
+			SnapshotLimit:     256,
+			ReceiptAccounting: record,
+		}, gspec, nil, engine, vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create tester chain: %v", err)
+		}
+		if n, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+		}
+		receipts := chain.GetReceiptsByHash(blocks[0].Hash())
+		chain.Stop()
+
+		if !record {
+			if receipts[0].Accounting != nil {
+				t.Errorf("accounting recorded when disabled: %+v", receipts[0].Accounting)
+			}
+			continue
+		}
+		// The address of 0xBBBB is warm as the destination, 0xCCCC is never
+		// accessed, so only 0xDDDD and the two slots save gas.
+		want := types.ReceiptAccounting{
+			GasRefund:          params.SstoreClearsScheduleRefundEIP3529,
+			AccessListGasSaved: params.ColdAccountAccessCostEIP2929 + 2*params.ColdSloadCostEIP2929 - 3*params.WarmStorageReadCostEIP2929,
+		}
+		if have := receipts[0].Accounting; have == nil || *have != want {
+			t.Errorf("accounting mismatch: have %+v, want %+v", have, want)
+		}
+	}
+}
+
 // TestEIP1559Transition tests the following:
 //
 //  1. A transaction whose gasFeeCap is greater than the baseFee is valid.
@@ -3653,6 +3841,63 @@ func TestEIP1559Transition(t *testing.T) {
 	}
 }
 
+// Tests that the base fee is paid to the fee sink of the chain instead of being
+// burnt if one is configured.
+func TestFeeSink(t *testing.T) {
+	var (
+		aa     = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		sink   = common.HexToAddress("0x000000000000000000000000000000000000fee5")
+		engine = ethash.NewFaker()
+
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		funds  = big.NewInt(params.Ether)
+		config = *params.AllEthashProtocolChanges
+		gspec  = &Genesis{
+			Config: &config,
+			Alloc:  GenesisAlloc{addr: {Balance: funds}},
+		}
+	)
+	config.BerlinBlock = common.Big0
+	config.LondonBlock = common.Big0
+	config.FeeSink = &sink
+	signer := types.LatestSigner(gspec.Config)
+
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 1, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{1})
+		tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   gspec.Config.ChainID,
+			Nonce:     0,
+			To:        &aa,
+			Gas:       30000,
+			GasFeeCap: newGwei(5),
+			GasTipCap: big.NewInt(2),
+		})
+		b.AddTx(tx)
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	block := chain.GetBlockByNumber(1)
+	state, _ := chain.State()
+
+	// The sink receives the base fee, the sender still pays base fee and tip
+	expected := new(big.Int).SetUint64(block.GasUsed() * block.BaseFee().Uint64())
+	if actual := state.GetBalance(sink); actual.Cmp(expected) != 0 {
+		t.Fatalf("fee sink balance incorrect: expected %d, got %d", expected, actual)
+	}
+	expected = new(big.Int).SetUint64(block.GasUsed() * (block.Transactions()[0].GasTipCap().Uint64() + block.BaseFee().Uint64()))
+	if actual := new(big.Int).Sub(funds, state.GetBalance(addr)); actual.Cmp(expected) != 0 {
+		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
+	}
+}
+
 // Tests the scenario the chain is requested to another point with the missing state.
 // It expects the state is recovered and all relevant chain markers are set correctly.
 func TestSetCanonical(t *testing.T) {
@@ -4341,3 +4586,288 @@ func TestEIP3651(t *testing.T) {
 		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
 	}
 }
+
+// Tests that EIP-7702 set code transactions install the authorized delegations
+// and that calls to a delegated account run the delegate's code.
+func TestEIP7702(t *testing.T) {
+	var (
+		aa     = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		bb     = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		engine = beacon.NewFaker()
+
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		funds   = new(big.Int).Mul(common.Big1, big.NewInt(params.Ether))
+		config  = *params.AllEthashProtocolChanges
+		gspec   = &Genesis{
+			Config: &config,
+			Alloc: GenesisAlloc{
+				addr1: {Balance: funds},
+				addr2: {Balance: funds},
+				// The address 0xAAAA stores 0x42 into slot 0x42
+				aa: {
+					Code: []byte{
+						byte(vm.PUSH1), 0x42,
+						byte(vm.DUP1),
+						byte(vm.SSTORE),
+					},
+					Nonce:   0,
+					Balance: big.NewInt(0),
+				},
+				bb: {
+					Code:    []byte{byte(vm.STOP)},
+					Nonce:   0,
+					Balance: big.NewInt(0),
+				},
+			},
+		}
+	)
+	gspec.Config.BerlinBlock = common.Big0
+	gspec.Config.LondonBlock = common.Big0
+	gspec.Config.TerminalTotalDifficulty = common.Big0
+	gspec.Config.TerminalTotalDifficultyPassed = true
+	gspec.Config.ShanghaiTime = u64(0)
+	gspec.Config.CancunTime = u64(0)
+	gspec.Config.PragueTime = u64(0)
+	signer := types.LatestSigner(gspec.Config)
+
+	// The sender delegates itself to 0xBBBB (its nonce is bumped before the
+	// authorizations are applied, hence 1), the other account to 0xAAAA.
+	auth1, _ := types.SignSetCode(key1, types.SetCodeAuthorization{
+		ChainID: *uint256.MustFromBig(gspec.Config.ChainID),
+		Address: bb,
+		Nonce:   1,
+	})
+	auth2, _ := types.SignSetCode(key2, types.SetCodeAuthorization{
+		Address: aa,
+		Nonce:   0,
+	})
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 1, func(i int, b *BlockGen) {
+		b.SetCoinbase(aa)
+		txdata := &types.SetCodeTx{
+			ChainID:   uint256.MustFromBig(gspec.Config.ChainID),
+			Nonce:     0,
+			To:        addr2,
+			Gas:       500000,
+			GasFeeCap: uint256.MustFromBig(newGwei(5)),
+			GasTipCap: uint256.NewInt(2),
+			AuthList:  []types.SetCodeAuthorization{auth1, auth2},
+		}
+		tx := types.MustSignNewTx(key1, signer, txdata)
+		b.AddTx(tx)
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	state, _ := chain.State()
+
+	// Verify delegation designations were deployed.
+	if code, want := state.GetCode(addr1), types.AddressToDelegation(bb); !bytes.Equal(code, want) {
+		t.Fatalf("addr1 code incorrect: got %x, want %x", code, want)
+	}
+	if code, want := state.GetCode(addr2), types.AddressToDelegation(aa); !bytes.Equal(code, want) {
+		t.Fatalf("addr2 code incorrect: got %x, want %x", code, want)
+	}
+	// Verify the authorizations bumped the nonces.
+	if nonce := state.GetNonce(addr1); nonce != 2 {
+		t.Fatalf("addr1 nonce incorrect: got %d, want %d", nonce, 2)
+	}
+	if nonce := state.GetNonce(addr2); nonce != 1 {
+		t.Fatalf("addr2 nonce incorrect: got %d, want %d", nonce, 1)
+	}
+	// Verify the delegated code ran in the context of addr2.
+	if have, want := state.GetState(addr2, common.BigToHash(big.NewInt(0x42))), common.BigToHash(big.NewInt(0x42)); have != want {
+		t.Fatalf("addr2 storage wrong: have %x, want %x", have, want)
+	}
+}
+
+// Tests that execution witnesses are recorded for the configured number of
+// recently imported blocks.
+func TestExecutionWitnessHistory(t *testing.T) {
+	var (
+		aa     = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		engine = ethash.NewFaker()
+
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		funds   = big.NewInt(1000000000000000)
+		code    = []byte{byte(vm.PUSH1), 0x1, byte(vm.SLOAD), byte(vm.STOP)}
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				address: {Balance: funds},
+				aa:      {Balance: big.NewInt(0), Code: code, Storage: map[common.Hash]common.Hash{common.BytesToHash([]byte{1}): common.BytesToHash([]byte{2})}},
+			},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 3, func(i int, b *BlockGen) {
+		tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{
+			Nonce:    uint64(i),
+			GasPrice: b.header.BaseFee,
+			Gas:      100000,
+			To:       &aa,
+		})
+		b.AddTx(tx)
+	})
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.WitnessHistory = 2
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), &cacheConfig, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert into chain: %v", err)
+	}
+	if witness := chain.ExecutionWitness(blocks[0].Hash()); witness != nil {
+		t.Fatalf("witness of block #1 retained beyond history")
+	}
+	for _, block := range blocks[1:] {
+		witness := chain.ExecutionWitness(block.Hash())
+		if witness == nil {
+			t.Fatalf("witness of block #%d missing", block.NumberU64())
+		}
+		if len(witness.Codes) != 1 || !bytes.Equal(witness.Codes[0], code) {
+			t.Fatalf("witness of block #%d codes mismatch: have %x, want [%x]", block.NumberU64(), witness.Codes, code)
+		}
+		if len(witness.State) == 0 {
+			t.Fatalf("witness of block #%d has no trie nodes", block.NumberU64())
+		}
+	}
+}
+
+// Tests that execution witnesses include the headers of the ancestors accessed via
+// BLOCKHASH, and that they can be built on demand for any block with state.
+func TestBuildExecutionWitness(t *testing.T) {
+	var (
+		aa     = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		engine = ethash.NewFaker()
+
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		code    = []byte{byte(vm.PUSH1), 0x1, byte(vm.BLOCKHASH), byte(vm.POP), byte(vm.STOP)} // blockhash(1)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				address: {Balance: big.NewInt(1000000000000000)},
+				aa:      {Balance: big.NewInt(0), Code: code},
+			},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 5, func(i int, b *BlockGen) {
+		if i == 4 {
+			tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{
+				Nonce:    b.TxNonce(address),
+				GasPrice: b.header.BaseFee,
+				Gas:      100000,
+				To:       &aa,
+			})
+			b.AddTx(tx)
+		}
+	})
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.WitnessHistory = 1
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), &cacheConfig, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert into chain: %v", err)
+	}
+	// The witness of the last block should link its parent back to block #1
+	recorded := chain.ExecutionWitness(blocks[4].Hash())
+	if recorded == nil {
+		t.Fatalf("witness of block #5 missing")
+	}
+	if len(recorded.Headers) != 4 {
+		t.Fatalf("header count mismatch: have %d, want %d", len(recorded.Headers), 4)
+	}
+	for i, enc := range recorded.Headers {
+		var header types.Header
+		if err := rlp.DecodeBytes(enc, &header); err != nil {
+			t.Fatalf("header %d: failed to decode: %v", i, err)
+		}
+		if want := blocks[3-i].Hash(); header.Hash() != want {
+			t.Errorf("header %d: hash mismatch: have %x, want %x", i, header.Hash(), want)
+		}
+	}
+	// Witnesses built on demand should match the recorded ones
+	built, err := chain.BuildExecutionWitness(blocks[4])
+	if err != nil {
+		t.Fatalf("failed to build witness: %v", err)
+	}
+	if !reflect.DeepEqual(built, recorded) {
+		t.Errorf("built witness mismatch:\nhave %v\nwant %v", built, recorded)
+	}
+	// Blocks not accessing any ancestors should only include the parent header
+	built, err = chain.BuildExecutionWitness(blocks[2])
+	if err != nil {
+		t.Fatalf("failed to build witness: %v", err)
+	}
+	if len(built.Headers) != 1 {
+		t.Errorf("header count mismatch: have %d, want %d", len(built.Headers), 1)
+	}
+}
+
+// Tests that aborting the state flush of a stopping chain leaves the database
+// consistent, rewinding the head to the last persisted state on restart.
+func TestAbortStateFlush(t *testing.T) {
+	testAbortStateFlush(t, false)
+	testAbortStateFlush(t, true)
+}
+
+func testAbortStateFlush(t *testing.T, abort bool) {
+	var (
+		engine  = ethash.NewFaker()
+		db      = rawdb.NewMemoryDatabase()
+		genesis = &Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	_, blocks, _ := GenerateChainWithGenesis(genesis, engine, 8, func(i int, b *BlockGen) { b.SetCoinbase(common.Address{1}) })
+
+	chain, err := NewBlockChain(db, nil, genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if abort {
+		chain.AbortStateFlush()
+	}
+	chain.Stop()
+
+	chain, err = NewBlockChain(db, nil, genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to reopen tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	head := chain.CurrentBlock()
+	if !chain.HasState(head.Root) {
+		t.Fatalf("abort %v: head state missing after restart", abort)
+	}
+	want := blocks[len(blocks)-1].NumberU64()
+	if abort {
+		want = 0
+	}
+	if head.Number.Uint64() != want {
+		t.Fatalf("abort %v: head mismatch: have %d, want %d", abort, head.Number, want)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/gorievm/go-gori/consensus"
+	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/types"
+)
+
+// ValidateBlock fully validates a block on top of its parent as if it was being
+// imported, without writing anything into the database or otherwise affecting
+// the chain. The block is executed on a throwaway copy of the parent state, that
+// is returned along with the receipts and gas used for further inspection.
+//
+// Blocks already known to the chain are rejected with ErrKnownBlock.
+func (bc *BlockChain) ValidateBlock(block *types.Block) (*state.StateDB, types.Receipts, uint64, error) {
+	if block.NumberU64() == 0 {
+		return nil, nil, 0, ErrKnownBlock
+	}
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, nil, 0, consensus.ErrUnknownAncestor
+	}
+	if !bc.HasState(parent.Root) {
+		return nil, nil, 0, consensus.ErrPrunedAncestor
+	}
+	if err := bc.engine.VerifyHeader(bc, block.Header()); err != nil {
+		return nil, nil, 0, err
+	}
+	if err := bc.validator.ValidateBody(block); err != nil {
+		return nil, nil, 0, err
+	}
+	statedb, err := bc.StateAt(parent.Root)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	receipts, _, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if err := bc.validator.ValidateState(block, statedb, receipts, usedGas); err != nil {
+		return nil, nil, 0, err
+	}
+	return statedb, receipts, usedGas, nil
+}
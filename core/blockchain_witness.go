@@ -0,0 +1,83 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/consensus"
+	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/rlp"
+)
+
+// BuildExecutionWitness re-executes a block on top of its parent state, building
+// the execution witness needed to statelessly verify it. The state of the parent
+// block needs to be available.
+func (bc *BlockChain) BuildExecutionWitness(block *types.Block) (*state.ExecutionWitness, error) {
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis block has no execution witness")
+	}
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	statedb, err := bc.StateAt(parent.Root)
+	if err != nil {
+		return nil, err
+	}
+	witness := state.NewWitness()
+	statedb.StartWitness(witness)
+
+	if _, _, _, err := bc.processor.Process(block, statedb, bc.vmConfig); err != nil {
+		return nil, err
+	}
+	return bc.buildWitness(witness, parent)
+}
+
+// buildWitness assembles the execution witness of the accesses recorded while
+// executing a block on top of the given parent, proving them against the parent
+// state and attaching the headers of the accessed ancestors.
+func (bc *BlockChain) buildWitness(witness *state.Witness, parent *types.Header) (*state.ExecutionWitness, error) {
+	w, err := witness.Build(bc.stateCache, parent.Root)
+	if err != nil {
+		return nil, err
+	}
+	// Include the headers from the parent back to the oldest accessed ancestor,
+	// the parent always being needed to verify the pre-state root
+	oldest := parent.Number.Uint64()
+	if numbers := witness.BlockHashes(); len(numbers) > 0 && numbers[0] < oldest {
+		oldest = numbers[0]
+	}
+	for header := parent; ; {
+		enc, err := rlp.EncodeToBytes(header)
+		if err != nil {
+			return nil, err
+		}
+		w.Headers = append(w.Headers, hexutil.Bytes(enc))
+		if header.Number.Uint64() <= oldest {
+			break
+		}
+		number := header.Number.Uint64() - 1
+		if header = bc.GetHeader(header.ParentHash, number); header == nil {
+			return nil, fmt.Errorf("missing ancestor header #%d", number)
+		}
+	}
+	return w, nil
+}
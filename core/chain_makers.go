@@ -24,6 +24,7 @@ import (
 	"github.com/gorievm/go-gori/consensus"
 	"github.com/gorievm/go-gori/consensus/misc"
 	"github.com/gorievm/go-gori/consensus/misc/eip1559"
+	"github.com/gorievm/go-gori/consensus/misc/eip4844"
 	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/core/state"
 	"github.com/gorievm/go-gori/core/types"
@@ -50,6 +51,7 @@ type BlockGen struct {
 
 	config *params.ChainConfig
 	engine consensus.Engine
+	reader *fakeChainReader
 }
 
 // SetCoinbase sets the coinbase of the generated block.
@@ -87,24 +89,52 @@ func (b *BlockGen) SetPoS() {
 	b.header.Difficulty = new(big.Int)
 }
 
+// SetRandom sets the randomness (PREVRANDAO) of the generated PoS block, which
+// is carried in the mix digest field of the header.
+func (b *BlockGen) SetRandom(random common.Hash) {
+	b.header.MixDigest = random
+}
+
+// SetParentBeaconRoot sets the parent beacon root field of the generated
+// block and stores it in the beacon roots contract.
+func (b *BlockGen) SetParentBeaconRoot(root common.Hash) {
+	b.header.ParentBeaconRoot = &root
+	blockContext := NewEVMBlockContext(b.header, b.reader, &b.header.Coinbase)
+	vmenv := vm.NewEVM(blockContext, vm.TxContext{}, b.statedb, b.config, vm.Config{})
+	ProcessBeaconBlockRoot(root, vmenv, b.statedb)
+}
+
+// Difficulty returns the difficulty of the block being generated.
+func (b *BlockGen) Difficulty() *big.Int {
+	return new(big.Int).Set(b.header.Difficulty)
+}
+
 // addTx adds a transaction to the generated block. If no coinbase has
 // been set, the block's coinbase is set to the zero address.
 //
 // There are a few options can be passed as well in order to run some
 // customized rules.
-// - bc:       enables the ability to query historical block hashes for BLOCKHASH
-// - vmConfig: extends the flexibility for customizing evm rules, e.g. enable extra EIPs
+//   - bc:       enables the ability to query historical block hashes for BLOCKHASH
+//     beyond the blocks known to the generator
+//   - vmConfig: extends the flexibility for customizing evm rules, e.g. enable extra EIPs
 func (b *BlockGen) addTx(bc *BlockChain, vmConfig vm.Config, tx *types.Transaction) {
 	if b.gasPool == nil {
 		b.SetCoinbase(common.Address{})
 	}
+	var chain ChainContext = b.reader
+	if bc != nil {
+		chain = bc
+	}
 	b.statedb.SetTxContext(tx.Hash(), len(b.txs))
-	receipt, err := ApplyTransaction(b.config, bc, &b.header.Coinbase, b.gasPool, b.statedb, b.header, tx, &b.header.GasUsed, vmConfig)
+	receipt, err := ApplyTransaction(b.config, chain, &b.header.Coinbase, b.gasPool, b.statedb, b.header, tx, &b.header.GasUsed, vmConfig)
 	if err != nil {
 		panic(err)
 	}
 	b.txs = append(b.txs, tx)
 	b.receipts = append(b.receipts, receipt)
+	if b.header.BlobGasUsed != nil {
+		*b.header.BlobGasUsed += tx.BlobGas()
+	}
 }
 
 // AddTx adds a transaction to the generated block. If no coinbase has
@@ -113,8 +143,8 @@ func (b *BlockGen) addTx(bc *BlockChain, vmConfig vm.Config, tx *types.Transacti
 // AddTx panics if the transaction cannot be executed. In addition to
 // the protocol-imposed limitations (gas limit, etc.), there are some
 // further limitations on the content of transactions that can be
-// added. Notably, contract code relying on the BLOCKHASH instruction
-// will panic during execution.
+// added. Notably, the BLOCKHASH instruction only resolves the hashes of
+// the blocks known to the generator, returning zero for any other.
 func (b *BlockGen) AddTx(tx *types.Transaction) {
 	b.addTx(nil, vm.Config{}, tx)
 }
@@ -176,6 +206,42 @@ func (b *BlockGen) AddUncheckedReceipt(receipt *types.Receipt) {
 	b.receipts = append(b.receipts, receipt)
 }
 
+// ExcessBlobGas returns the EIP-4844 excess blob gas of the block being
+// generated, or zero before Cancun.
+func (b *BlockGen) ExcessBlobGas() uint64 {
+	if b.header.ExcessBlobGas == nil {
+		return 0
+	}
+	return *b.header.ExcessBlobGas
+}
+
+// BlobGasUsed returns the EIP-4844 blob gas consumed by the transactions added
+// to the block so far, or zero before Cancun.
+func (b *BlockGen) BlobGasUsed() uint64 {
+	if b.header.BlobGasUsed == nil {
+		return 0
+	}
+	return *b.header.BlobGasUsed
+}
+
+// ApplySystemCall executes a call to the given contract from the system address,
+// the way the protocol itself invokes system contracts outside of transactions.
+// The call neither consumes block gas nor produces a receipt. It returns the
+// output of the call and panics if the execution fails.
+func (b *BlockGen) ApplySystemCall(to common.Address, input []byte) []byte {
+	var (
+		context = NewEVMBlockContext(b.header, b.reader, &b.header.Coinbase)
+		vmenv   = vm.NewEVM(context, vm.TxContext{Origin: params.SystemAddress, GasPrice: new(big.Int)}, b.statedb, b.config, vm.Config{})
+	)
+	b.statedb.AddAddressToAccessList(to)
+	ret, _, err := vmenv.Call(vm.AccountRef(params.SystemAddress), to, input, 30_000_000, new(big.Int))
+	if err != nil {
+		panic(fmt.Sprintf("system call to %x failed: %v", to, err))
+	}
+	b.statedb.Finalise(true)
+	return ret
+}
+
 // TxNonce returns the next valid transaction nonce for the
 // account at addr. It panics if the account does not exist.
 func (b *BlockGen) TxNonce(addr common.Address) uint64 {
@@ -197,8 +263,7 @@ func (b *BlockGen) AddUncle(h *types.Header) {
 			break
 		}
 	}
-	chainreader := &fakeChainReader{config: b.config}
-	h.Difficulty = b.engine.CalcDifficulty(chainreader, b.header.Time, parent)
+	h.Difficulty = b.engine.CalcDifficulty(b.reader, b.header.Time, parent)
 
 	// The gas limit and price should be derived from the parent
 	h.GasLimit = parent.GasLimit
@@ -221,21 +286,20 @@ func (b *BlockGen) AddWithdrawal(w *types.Withdrawal) uint64 {
 	return cpy.Index
 }
 
-// nextWithdrawalIndex computes the index of the next withdrawal.
+// nextWithdrawalIndex computes the index of the next withdrawal, continuing
+// from the last withdrawal among the known ancestors of the block.
 func (b *BlockGen) nextWithdrawalIndex() uint64 {
 	if len(b.withdrawals) != 0 {
 		return b.withdrawals[len(b.withdrawals)-1].Index + 1
 	}
-	for i := b.i - 1; i >= 0; i-- {
-		if wd := b.chain[i].Withdrawals(); len(wd) != 0 {
+	for block := b.parent; block != nil; {
+		if wd := block.Withdrawals(); len(wd) != 0 {
 			return wd[len(wd)-1].Index + 1
 		}
-		if i == 0 {
-			// Correctly set the index if no parent had withdrawals.
-			if wd := b.parent.Withdrawals(); len(wd) != 0 {
-				return wd[len(wd)-1].Index + 1
-			}
+		if block.NumberU64() == 0 {
+			break
 		}
+		block = b.reader.GetBlock(block.ParentHash(), block.NumberU64()-1)
 	}
 	return 0
 }
@@ -261,8 +325,7 @@ func (b *BlockGen) OffsetTime(seconds int64) {
 	if b.header.Time <= b.parent.Header().Time {
 		panic("block time out of range")
 	}
-	chainreader := &fakeChainReader{config: b.config}
-	b.header.Difficulty = b.engine.CalcDifficulty(chainreader, b.header.Time, b.parent.Header())
+	b.header.Difficulty = b.engine.CalcDifficulty(b.reader, b.header.Time, b.parent.Header())
 }
 
 // GenerateChain creates a chain of n blocks. The first block's
@@ -277,16 +340,35 @@ func (b *BlockGen) OffsetTime(seconds int64) {
 // Blocks created by GenerateChain do not contain valid proof of work
 // values. Inserting them into BlockChain requires use of FakePow or
 // a similar non-validating proof of work implementation.
+//
+// To build block trees with side chains branching off at arbitrary
+// ancestors, use a ChainMaker instead.
 func GenerateChain(config *params.ChainConfig, parent *types.Block, engine consensus.Engine, db ethdb.Database, n int, gen func(int, *BlockGen)) ([]*types.Block, []types.Receipts) {
 	if config == nil {
 		config = params.TestChainConfig
 	}
+	chainreader := &fakeChainReader{config: config, blocks: make(map[common.Hash]*types.Block)}
+	chainreader.blocks[parent.Hash()] = parent
+
+	return generateChain(chainreader, parent, engine, db, n, gen)
+}
+
+// generateChain creates a chain of n blocks on top of parent, tracking all the
+// generated blocks in the given chain reader.
+func generateChain(chainreader *fakeChainReader, parent *types.Block, engine consensus.Engine, db ethdb.Database, n int, gen func(int, *BlockGen)) ([]*types.Block, []types.Receipts) {
+	config := chainreader.config
+
 	blocks, receipts := make(types.Blocks, n), make([]types.Receipts, n)
-	chainreader := &fakeChainReader{config: config}
 	genblock := func(i int, parent *types.Block, statedb *state.StateDB) (*types.Block, types.Receipts) {
-		b := &BlockGen{i: i, chain: blocks, parent: parent, statedb: statedb, config: config, engine: engine}
+		b := &BlockGen{i: i, chain: blocks, parent: parent, statedb: statedb, config: config, engine: engine, reader: chainreader}
 		b.header = makeHeader(chainreader, parent, statedb, b.engine)
 
+		// Store the parent beacon root in the system contract before any transaction
+		if beaconRoot := b.header.ParentBeaconRoot; beaconRoot != nil {
+			blockContext := NewEVMBlockContext(b.header, chainreader, &b.header.Coinbase)
+			vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, config, vm.Config{})
+			ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
+		}
 		// Set the difficulty for clique block. The chain maker doesn't have access
 		// to a chain, so the difficulty will be left unset (nil). Set it here to the
 		// correct value.
@@ -342,6 +424,10 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		blocks[i] = block
 		receipts[i] = receipt
 		parent = block
+
+		if block != nil {
+			chainreader.blocks[block.Hash()] = block
+		}
 	}
 	return blocks, receipts
 }
@@ -359,6 +445,74 @@ func GenerateChainWithGenesis(genesis *Genesis, engine consensus.Engine, n int,
 	return db, blocks, receipts
 }
 
+// ChainMaker generates trees of blocks for testing. It keeps track of all the
+// blocks generated so far, allowing side chains to be branched off at any of
+// them. The BLOCKHASH instruction and the withdrawal indices are resolved along
+// the ancestry of each generated block.
+type ChainMaker struct {
+	genesis *types.Block
+	engine  consensus.Engine
+	db      ethdb.Database
+	reader  *fakeChainReader
+}
+
+// NewChainMaker commits the genesis specification into a fresh in-memory
+// database and creates a generator for the blocks on top of it.
+func NewChainMaker(genesis *Genesis, engine consensus.Engine) *ChainMaker {
+	db := rawdb.NewMemoryDatabase()
+	block, err := genesis.Commit(db, trie.NewDatabase(db))
+	if err != nil {
+		panic(err)
+	}
+	config := genesis.Config
+	if config == nil {
+		config = params.AllEthashProtocolChanges
+	}
+	return &ChainMaker{
+		genesis: block,
+		engine:  engine,
+		db:      db,
+		reader: &fakeChainReader{
+			config: config,
+			blocks: map[common.Hash]*types.Block{block.Hash(): block},
+		},
+	}
+}
+
+// Genesis returns the genesis block of the generated block tree.
+func (cm *ChainMaker) Genesis() *types.Block {
+	return cm.genesis
+}
+
+// Database returns the database holding the states of the generated blocks.
+func (cm *ChainMaker) Database() ethdb.Database {
+	return cm.db
+}
+
+// Extend generates n blocks on top of the given parent, which must be the
+// genesis or a previously generated block. See GenerateChain for the details
+// of the generator function.
+func (cm *ChainMaker) Extend(parent *types.Block, n int, gen func(int, *BlockGen)) ([]*types.Block, []types.Receipts) {
+	if cm.reader.blocks[parent.Hash()] == nil {
+		panic(fmt.Sprintf("unknown parent block %d [%x]", parent.NumberU64(), parent.Hash()))
+	}
+	return generateChain(cm.reader, parent, cm.engine, cm.db, n, gen)
+}
+
+// Chain returns the blocks leading from the genesis (exclusive) to the given
+// head (inclusive), e.g. to insert a side chain into a BlockChain.
+func (cm *ChainMaker) Chain(head *types.Block) []*types.Block {
+	var blocks []*types.Block
+	for block := head; block != nil && block.NumberU64() > 0; {
+		blocks = append(blocks, block)
+		block = cm.reader.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	}
+	for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+		blocks[i], blocks[j] = blocks[j], blocks[i]
+	}
+	return blocks
+}
+
 func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.StateDB, engine consensus.Engine) *types.Header {
 	var time uint64
 	if parent.Time() == 0 {
@@ -387,6 +541,18 @@ func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.S
 			header.GasLimit = CalcGasLimit(parentGasLimit, parentGasLimit)
 		}
 	}
+	if chain.Config().IsCancun(header.Number, header.Time) {
+		var excessBlobGas uint64
+		if chain.Config().IsCancun(parent.Number(), parent.Time()) {
+			excessBlobGas = eip4844.CalcExcessBlobGas(*parent.ExcessBlobGas(), *parent.BlobGasUsed())
+		}
+		header.ExcessBlobGas = &excessBlobGas
+		header.BlobGasUsed = new(uint64)
+		header.ParentBeaconRoot = new(common.Hash)
+	}
+	if chain.Config().IsPrague(header.Number, header.Time) {
+		header.RequestsHash = &types.EmptyRequestsHash
+	}
 	return header
 }
 
@@ -428,6 +594,7 @@ func makeBlockChainWithGenesis(genesis *Genesis, n int, engine consensus.Engine,
 
 type fakeChainReader struct {
 	config *params.ChainConfig
+	blocks map[common.Hash]*types.Block // Blocks known to the generator, if any
 }
 
 // Config returns the chain configuration.
@@ -435,9 +602,34 @@ func (cr *fakeChainReader) Config() *params.ChainConfig {
 	return cr.config
 }
 
-func (cr *fakeChainReader) CurrentHeader() *types.Header                            { return nil }
-func (cr *fakeChainReader) GetHeaderByNumber(number uint64) *types.Header           { return nil }
-func (cr *fakeChainReader) GetHeaderByHash(hash common.Hash) *types.Header          { return nil }
-func (cr *fakeChainReader) GetHeader(hash common.Hash, number uint64) *types.Header { return nil }
-func (cr *fakeChainReader) GetBlock(hash common.Hash, number uint64) *types.Block   { return nil }
-func (cr *fakeChainReader) GetTd(hash common.Hash, number uint64) *big.Int          { return nil }
+// Engine returns no consensus engine, the block authors are always explicitly
+// passed by the generator.
+func (cr *fakeChainReader) Engine() consensus.Engine { return nil }
+
+func (cr *fakeChainReader) CurrentHeader() *types.Header                   { return nil }
+func (cr *fakeChainReader) GetHeaderByNumber(number uint64) *types.Header  { return nil }
+func (cr *fakeChainReader) GetTd(hash common.Hash, number uint64) *big.Int { return nil }
+
+// GetHeaderByHash retrieves a block header known to the generator.
+func (cr *fakeChainReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	if block := cr.blocks[hash]; block != nil {
+		return block.Header()
+	}
+	return nil
+}
+
+// GetHeader retrieves a block header known to the generator.
+func (cr *fakeChainReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if block := cr.GetBlock(hash, number); block != nil {
+		return block.Header()
+	}
+	return nil
+}
+
+// GetBlock retrieves a block known to the generator.
+func (cr *fakeChainReader) GetBlock(hash common.Hash, number uint64) *types.Block {
+	if block := cr.blocks[hash]; block != nil && block.NumberU64() == number {
+		return block
+	}
+	return nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/gorievm/go-gori/consensus/beacon"
 	"github.com/gorievm/go-gori/consensus/ethash"
 	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/state"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/core/vm"
 	"github.com/gorievm/go-gori/crypto"
@@ -137,6 +138,114 @@ func TestGenerateWithdrawalChain(t *testing.T) {
 	}
 }
 
+func TestChainMakerSideChains(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("9c647b8b7c4e7c3490668fb6c11473619db80c93704c70893d3813af4090c39c")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		hasher   = common.Address{0xaa} // stores BLOCKHASH(NUMBER-1) into slot 0
+		recorder = common.Address{0xbb} // stores CALLER into slot 0
+		config   = *params.AllEthashProtocolChanges
+		gspec    = &Genesis{
+			Config: &config,
+			Alloc: GenesisAlloc{
+				address:  {Balance: big.NewInt(params.Ether)},
+				hasher:   {Balance: common.Big0, Code: common.Hex2Bytes("600143034060005500")},
+				recorder: {Balance: common.Big0, Code: common.Hex2Bytes("3360005500")},
+			},
+			BaseFee:    big.NewInt(params.InitialBaseFee),
+			Difficulty: common.Big0,
+			GasLimit:   5_000_000,
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	config.TerminalTotalDifficultyPassed = true
+	config.TerminalTotalDifficulty = common.Big0
+	config.ShanghaiTime = u64(0)
+	config.CancunTime = u64(0)
+
+	cm := NewChainMaker(gspec, beacon.NewFaker())
+
+	// Generate the canonical chain with some withdrawals
+	main, _ := cm.Extend(cm.Genesis(), 4, func(i int, gen *BlockGen) {
+		if i == 1 {
+			gen.AddWithdrawal(&types.Withdrawal{Validator: 1, Address: common.Address{0xee}, Amount: 1})
+			gen.AddWithdrawal(&types.Withdrawal{Validator: 2, Address: common.Address{0xee}, Amount: 1})
+		}
+	})
+	// Branch off a side chain at the second block, exercising the post-merge
+	// header fields, the system calls and the BLOCKHASH resolution.
+	random := common.Hash{0x01}
+	side, _ := cm.Extend(main[1], 3, func(i int, gen *BlockGen) {
+		gen.SetRandom(random)
+		if gen.Difficulty().Sign() != 0 {
+			t.Errorf("side block %d: non-zero difficulty %v", i, gen.Difficulty())
+		}
+		if gen.ExcessBlobGas() != 0 || gen.BlobGasUsed() != 0 {
+			t.Errorf("side block %d: unexpected blob gas", i)
+		}
+		if i == 0 {
+			if index := gen.AddWithdrawal(&types.Withdrawal{Validator: 3, Address: common.Address{0xee}, Amount: 1}); index != 2 {
+				t.Errorf("withdrawal index mismatch: have %d, want 2", index)
+			}
+			gen.ApplySystemCall(recorder, nil)
+		}
+		if i == 2 {
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(address), hasher, common.Big0, 50_000, gen.BaseFee(), nil), signer, key)
+			gen.AddTx(tx)
+		}
+	})
+	if side[0].MixDigest() != random {
+		t.Errorf("random mismatch: have %x, want %x", side[0].MixDigest(), random)
+	}
+	if side[0].ExcessBlobGas() == nil || side[0].BlobGasUsed() == nil {
+		t.Error("missing blob gas fields")
+	}
+	// The system call and BLOCKHASH should have been executed
+	statedb, err := state.New(side[0].Root(), state.NewDatabase(cm.Database()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := statedb.GetState(recorder, common.Hash{}); have != common.BytesToHash(params.SystemAddress.Bytes()) {
+		t.Errorf("system call sender mismatch: have %x, want %x", have, params.SystemAddress)
+	}
+	statedb, err = state.New(side[2].Root(), state.NewDatabase(cm.Database()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := statedb.GetState(hasher, common.Hash{}); have != side[1].Hash() {
+		t.Errorf("blockhash mismatch: have %x, want %x", have, side[1].Hash())
+	}
+	// The side chain should lead back to the genesis through the canonical chain
+	chain := cm.Chain(side[2])
+	if len(chain) != 5 || chain[0] != main[0] || chain[1] != main[1] || chain[2] != side[0] {
+		t.Fatalf("side chain ancestry mismatch")
+	}
+	// Import both chains, running all the validation rules. The system call is
+	// not part of the block processing, so only import the blocks without it.
+	blockchain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, beacon.NewFaker(), vm.Config{}, nil, nil)
+	defer blockchain.Stop()
+
+	if i, err := blockchain.InsertChain(main); err != nil {
+		t.Fatalf("failed to import canonical block %d: %v", main[i].NumberU64(), err)
+	}
+	sideTwo, _ := cm.Extend(main[1], 2, func(i int, gen *BlockGen) {
+		gen.SetRandom(random)
+		if i == 1 {
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(address), hasher, common.Big0, 50_000, gen.BaseFee(), nil), signer, key)
+			gen.AddTx(tx)
+		}
+	})
+	if i, err := blockchain.InsertChain(sideTwo); err != nil {
+		t.Fatalf("failed to import side block %d: %v", sideTwo[i].NumberU64(), err)
+	}
+	if _, err := blockchain.SetCanonical(sideTwo[1]); err != nil {
+		t.Fatalf("failed to set side chain canonical: %v", err)
+	}
+	if head := blockchain.CurrentBlock(); head.Hash() != sideTwo[1].Hash() {
+		t.Fatalf("head mismatch: have %x, want %x", head.Hash(), sideTwo[1].Hash())
+	}
+}
+
 func ExampleGenerateChain() {
 	var (
 		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
@@ -24,11 +24,57 @@ import (
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// TxDropReason is the reason of a transaction leaving the transaction pool.
+type TxDropReason uint8
+
+const (
+	TxDropReplaced    TxDropReason = iota // Replaced by a transaction with the same nonce
+	TxDropUnderpriced                     // Discarded in favour of better paying transactions
+	TxDropNonceTooLow                     // Nonce used up by a transaction unknown to the pool
+	TxDropEvicted                         // Evicted due to pool limits or account inactivity
+	TxDropIncluded                        // Included in the canonical chain
+	TxDropInvalid                         // Invalidated by the chain state (balance, gas limit)
+	TxDropExpired                         // Exceeded the maximum lifetime of transactions in the pool
+)
+
+// String implements fmt.Stringer, returning the reason code used over RPC.
+func (r TxDropReason) String() string {
+	switch r {
+	case TxDropReplaced:
+		return "replaced"
+	case TxDropUnderpriced:
+		return "underpriced"
+	case TxDropNonceTooLow:
+		return "nonce-too-low"
+	case TxDropEvicted:
+		return "evicted"
+	case TxDropIncluded:
+		return "included"
+	case TxDropInvalid:
+		return "invalid"
+	case TxDropExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// DropTxsEvent is posted when a batch of transactions leave the transaction pool.
+type DropTxsEvent struct {
+	Txs    []*types.Transaction
+	Reason TxDropReason
+}
+
 // NewMinedBlockEvent is posted when a block has been imported.
 type NewMinedBlockEvent struct{ Block *types.Block }
 
-// RemovedLogsEvent is posted when a reorg happens
-type RemovedLogsEvent struct{ Logs []*types.Log }
+// RemovedLogsEvent is posted when a reorg happens, carrying the logs of the
+// dropped blocks along with the context of the reorg.
+type RemovedLogsEvent struct {
+	Logs     []*types.Log
+	Depth    uint64                      // Number of blocks dropped from the canonical chain
+	Replaced map[common.Hash]common.Hash // Block taking the place of each dropped block, if any
+}
 
 type ChainEvent struct {
 	Block *types.Block
@@ -41,3 +87,15 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// ReorgEvent is posted when a segment of the canonical chain is replaced by the
+// blocks of another fork. Both segments are ordered by ascending block number.
+// The event is sent before the new head is announced by ChainHeadEvent.
+type ReorgEvent struct {
+	Ancestor    *types.Header  // Common ancestor of the dropped and adopted segments
+	Dropped     []*types.Block // Blocks removed from the canonical chain
+	Adopted     []*types.Block // Blocks added to the canonical chain, up to the new head
+	DroppedTxs  []common.Hash  // Transactions of the dropped blocks not included by the adopted ones
+	AdoptedTxs  []common.Hash  // Transactions of the adopted blocks not included by the dropped ones
+	RemovedLogs []int          // Number of logs removed with each of the dropped blocks
+}
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"math"
 	"math/big"
 
 	"github.com/gorievm/go-gori/common"
@@ -79,6 +80,13 @@ func NewEVMTxContext(msg *Message) vm.TxContext {
 	}
 }
 
+// ancestorRetriever is implemented by chain contexts able to look up far header
+// ancestors without walking the headers one by one.
+type ancestorRetriever interface {
+	// GetAncestor retrieves the Nth ancestor of a given block.
+	GetAncestor(hash common.Hash, number, ancestor uint64, maxNonCanonical *uint64) (common.Hash, uint64)
+}
+
 // GetHashFn returns a GetHashFunc which retrieves header hashes by number
 func GetHashFn(ref *types.Header, chain ChainContext) func(n uint64) common.Hash {
 	// Cache will initially contain [refHash.parent],
@@ -98,6 +106,19 @@ func GetHashFn(ref *types.Header, chain ChainContext) func(n uint64) common.Hash
 		if idx := ref.Number.Uint64() - n - 1; idx < uint64(len(cache)) {
 			return cache[idx]
 		}
+		// If the chain maintains the header skip index, look up the ancestor
+		// directly from the parent instead of iterating
+		if ancestors, ok := chain.(ancestorRetriever); ok {
+			var (
+				parent       = ref.Number.Uint64() - 1
+				maxNonCanon  = uint64(math.MaxUint64)
+				hash, number = ancestors.GetAncestor(ref.ParentHash, parent, parent-n, &maxNonCanon)
+			)
+			if hash != (common.Hash{}) && number == n {
+				return hash
+			}
+			return common.Hash{}
+		}
 		// No luck in the cache, but we can start iterating from the last element we already know
 		lastKnownHash := cache[len(cache)-1]
 		lastKnownNumber := ref.Number.Uint64() - uint64(len(cache))
@@ -0,0 +1,207 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package filtermaps implements a log index based on filter maps.
+//
+// The log values, addresses and topics, of all canonical logs are assigned
+// consecutive positions. Positions are grouped into maps, each map being a table
+// of rows where every log value adds a column entry to a row chosen by hashing
+// the value. The column encodes the position of the value along with a few bits
+// of a hash of the value and its position, so a row lookup yields the positions
+// of the searched value with a bounded rate of false positives. Matching the
+// address and topics of a log requires their entries to line up at consecutive
+// positions, which filters the false positives further.
+package filtermaps
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sync"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/event"
+)
+
+// Params defines the dimensions of the filter maps.
+type Params struct {
+	LogValuesPerMap uint // log2 of the number of log value positions in a map
+	LogMapHeight    uint // log2 of the number of rows in a map
+	LogMapWidth     uint // log2 of the number of columns in a row
+}
+
+// DefaultParams are the filter map dimensions used on live networks. A row holds
+// 16 entries on average and a false positive needs 8 hash bits to collide.
+var DefaultParams = Params{
+	LogValuesPerMap: 16,
+	LogMapHeight:    12,
+	LogMapWidth:     24,
+}
+
+// blockchain is the chain the log index is built from.
+type blockchain interface {
+	CurrentBlock() *types.Header
+	GetCanonicalHash(number uint64) common.Hash
+	GetReceiptsByHash(hash common.Hash) types.Receipts
+	HistoryPruningCutoff() uint64
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// FilterMaps maintains the log index of the canonical chain, covering either the
+// entire chain or a configured number of recent blocks.
+type FilterMaps struct {
+	db      ethdb.Database
+	chain   blockchain
+	params  Params
+	history uint64 // Number of recent blocks to index, 0 for the entire chain
+
+	lock        sync.RWMutex
+	initialized bool                  // Whether the range below is valid
+	fmr         rawdb.FilterMapsRange // Committed range of the index
+
+	synced     chan struct{} // Closed once the index first caught up with the chain
+	syncedOnce sync.Once
+	closeCh    chan struct{}
+	closeWg    sync.WaitGroup
+}
+
+// New creates a log index over the given chain. The index is maintained in the
+// background after Start is called.
+func New(db ethdb.Database, chain blockchain, params Params, history uint64) *FilterMaps {
+	f := &FilterMaps{
+		db:      db,
+		chain:   chain,
+		params:  params,
+		history: history,
+		synced:  make(chan struct{}),
+		closeCh: make(chan struct{}),
+	}
+	if fmr := rawdb.ReadFilterMapsRange(db); fmr != nil && f.compatible(fmr) {
+		f.fmr, f.initialized = *fmr, true
+	}
+	return f
+}
+
+// Start launches the background indexer.
+func (f *FilterMaps) Start() {
+	f.closeWg.Add(1)
+	go f.updateLoop()
+}
+
+// Stop terminates the background indexer.
+func (f *FilterMaps) Stop() {
+	close(f.closeCh)
+	f.closeWg.Wait()
+}
+
+// Synced returns a channel which is closed once the index covers the configured
+// range of the chain for the first time.
+func (f *FilterMaps) Synced() <-chan struct{} {
+	return f.synced
+}
+
+// IndexedRange returns the range of blocks covered by the index, or false if no
+// block is indexed yet.
+func (f *FilterMaps) IndexedRange() (first, last uint64, ok bool) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	if !f.initialized || f.fmr.Next == f.fmr.Tail {
+		return 0, 0, false
+	}
+	return f.fmr.Tail, f.fmr.Next - 1, true
+}
+
+// compatible returns whether the stored index was built with the parameters of
+// the log index.
+func (f *FilterMaps) compatible(fmr *rawdb.FilterMapsRange) bool {
+	return fmr.LogValuesPerMap == f.params.LogValuesPerMap &&
+		fmr.LogMapHeight == f.params.LogMapHeight &&
+		fmr.LogMapWidth == f.params.LogMapWidth
+}
+
+// valuesPerMap returns the number of log value positions in a map.
+func (f *FilterMaps) valuesPerMap() uint64 {
+	return 1 << f.params.LogValuesPerMap
+}
+
+// mapIndex returns the index of the map holding a log value position.
+func (f *FilterMaps) mapIndex(lvIndex uint64) uint32 {
+	return uint32(lvIndex >> f.params.LogValuesPerMap)
+}
+
+// rowIndex returns the row of a map a log value is stored in.
+func (f *FilterMaps) rowIndex(value common.Hash, mapIndex uint32) uint32 {
+	var enc [common.HashLength + 4]byte
+	copy(enc[:], value[:])
+	binary.BigEndian.PutUint32(enc[common.HashLength:], mapIndex)
+	hash := crypto.Keccak256(enc[:])
+	return binary.BigEndian.Uint32(hash) & (1<<f.params.LogMapHeight - 1)
+}
+
+// columnIndex returns the column entry of a log value at the given position.
+// The upper bits are the position within the map, the lower ones are taken from
+// a hash of the value and the position.
+func (f *FilterMaps) columnIndex(value common.Hash, lvIndex uint64) uint32 {
+	var enc [common.HashLength + 8]byte
+	copy(enc[:], value[:])
+	binary.BigEndian.PutUint64(enc[common.HashLength:], lvIndex)
+	hasher := fnv.New64a()
+	hasher.Write(enc[:])
+
+	shift := f.params.LogMapWidth - f.params.LogValuesPerMap
+	position := uint32(lvIndex & (f.valuesPerMap() - 1))
+	return position<<shift | uint32(hasher.Sum64())&(1<<shift-1)
+}
+
+// columnPosition returns the log value position a column entry was added at.
+func (f *FilterMaps) columnPosition(mapIndex uint32, column uint32) uint64 {
+	return uint64(mapIndex)<<f.params.LogValuesPerMap | uint64(column>>(f.params.LogMapWidth-f.params.LogValuesPerMap))
+}
+
+// placeLog returns the position of the first value of a log with the given
+// number of values, the next free position being lvIndex. Logs never span maps,
+// if it doesn't fit into the current one the log starts the next map.
+func (f *FilterMaps) placeLog(lvIndex uint64, values int) uint64 {
+	if offset := lvIndex & (f.valuesPerMap() - 1); offset+uint64(values) > f.valuesPerMap() {
+		return lvIndex - offset + f.valuesPerMap()
+	}
+	return lvIndex
+}
+
+// addressValue is the log value of an address.
+func addressValue(address common.Address) common.Hash {
+	return crypto.Keccak256Hash(address[:])
+}
+
+// topicValue is the log value of a topic.
+func topicValue(topic common.Hash) common.Hash {
+	return crypto.Keccak256Hash(topic[:])
+}
+
+// logValues returns the values of a log in the order of their positions.
+func logValues(log *types.Log) []common.Hash {
+	values := make([]common.Hash, 0, 1+len(log.Topics))
+	values = append(values, addressValue(log.Address))
+	for _, topic := range log.Topics {
+		values = append(values, topicValue(topic))
+	}
+	return values
+}
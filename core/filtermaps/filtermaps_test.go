@@ -0,0 +1,356 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filtermaps
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/event"
+)
+
+// testParams are small filter maps, so that tests span many maps.
+var testParams = Params{
+	LogValuesPerMap: 4,
+	LogMapHeight:    2,
+	LogMapWidth:     8,
+}
+
+var (
+	testAddresses = []common.Address{{0x01}, {0x02}, {0x03}, {0x04}}
+	testTopics    = []common.Hash{{0x11}, {0x12}, {0x13}, {0x14}, {0x15}, {0x16}}
+)
+
+// testChain is a canonical chain of random logs.
+type testChain struct {
+	rand     *rand.Rand
+	feed     event.Feed
+	hashes   []common.Hash
+	receipts map[common.Hash]types.Receipts
+	cutoff   uint64
+}
+
+func newTestChain(seed int64) *testChain {
+	return &testChain{
+		rand:     rand.New(rand.NewSource(seed)),
+		receipts: make(map[common.Hash]types.Receipts),
+	}
+}
+
+// addBlocks appends blocks with random logs to the chain after dropping the
+// blocks from the given number onwards.
+func (c *testChain) addBlocks(from, count int) {
+	c.hashes = c.hashes[:from]
+	for i := 0; i < count; i++ {
+		var (
+			number = uint64(len(c.hashes))
+			hash   common.Hash
+		)
+		c.rand.Read(hash[:])
+
+		receipts := types.Receipts{}
+		for j := c.rand.Intn(4); j > 0; j-- {
+			receipt := new(types.Receipt)
+			for k := c.rand.Intn(3); k > 0; k-- {
+				log := &types.Log{
+					Address:     testAddresses[c.rand.Intn(len(testAddresses))],
+					BlockNumber: number,
+					BlockHash:   hash,
+				}
+				for t := c.rand.Intn(4); t > 0; t-- {
+					log.Topics = append(log.Topics, testTopics[c.rand.Intn(len(testTopics))])
+				}
+				receipt.Logs = append(receipt.Logs, log)
+			}
+			receipts = append(receipts, receipt)
+		}
+		c.hashes = append(c.hashes, hash)
+		c.receipts[hash] = receipts
+	}
+}
+
+func (c *testChain) CurrentBlock() *types.Header {
+	return &types.Header{Number: big.NewInt(int64(len(c.hashes) - 1))}
+}
+
+func (c *testChain) GetCanonicalHash(number uint64) common.Hash {
+	if number >= uint64(len(c.hashes)) {
+		return common.Hash{}
+	}
+	return c.hashes[number]
+}
+
+func (c *testChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
+	return c.receipts[hash]
+}
+
+func (c *testChain) HistoryPruningCutoff() uint64 {
+	return c.cutoff
+}
+
+func (c *testChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+// logs returns the logs of the canonical blocks [first, last] matching the
+// filter criteria.
+func (c *testChain) logs(first, last uint64, addresses []common.Address, topics [][]common.Hash) []*types.Log {
+	var matches []*types.Log
+	for number := first; number <= last; number++ {
+		for _, receipt := range c.receipts[c.hashes[number]] {
+			for _, log := range receipt.Logs {
+				if matchLog(log, addresses, topics) {
+					matches = append(matches, log)
+				}
+			}
+		}
+	}
+	return matches
+}
+
+func matchLog(log *types.Log, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 {
+		var found bool
+		for _, address := range addresses {
+			found = found || log.Address == address
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(topics) > len(log.Topics) {
+		return false
+	}
+	for i, sub := range topics {
+		found := len(sub) == 0
+		for _, topic := range sub {
+			found = found || log.Topics[i] == topic
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// update synchronously brings the index in line with the test chain.
+func (f *FilterMaps) updateForTesting() {
+	f.update(f.chain.CurrentBlock(), make(chan struct{}))
+}
+
+// checkMatches runs random queries against the index and checks that all the
+// matching logs are found in order.
+func checkMatches(t *testing.T, f *FilterMaps, chain *testChain) {
+	t.Helper()
+
+	first, last, ok := f.IndexedRange()
+	if !ok {
+		t.Fatal("index is empty")
+	}
+	var falsePositives, total int
+	for i := 0; i < 200; i++ {
+		var (
+			from      = first + uint64(chain.rand.Intn(int(last-first+1)))
+			to        = from + uint64(chain.rand.Intn(int(last-from+1)))
+			addresses []common.Address
+			topics    [][]common.Hash
+		)
+		if chain.rand.Intn(2) == 0 {
+			addresses = []common.Address{testAddresses[chain.rand.Intn(len(testAddresses))]}
+		}
+		for j := chain.rand.Intn(3); j > 0; j-- {
+			var sub []common.Hash
+			if chain.rand.Intn(3) != 0 {
+				sub = []common.Hash{testTopics[chain.rand.Intn(len(testTopics))]}
+			}
+			topics = append(topics, sub)
+		}
+		found, err := f.PotentialMatches(context.Background(), from, to, addresses, topics)
+		if err == ErrMatchAll {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		var matches []*types.Log
+		for _, log := range found {
+			if matchLog(log, addresses, topics) {
+				matches = append(matches, log)
+			}
+		}
+		want := chain.logs(from, to, addresses, topics)
+		if len(matches) != len(want) {
+			t.Fatalf("query %d-%d %v %v: have %d matches, want %d", from, to, addresses, topics, len(matches), len(want))
+		}
+		for j := range want {
+			if matches[j] != want[j] {
+				t.Fatalf("query %d-%d: match %d mismatch", from, to, j)
+			}
+		}
+		falsePositives += len(found) - len(matches)
+		total += len(found)
+	}
+	if total == 0 {
+		t.Fatal("no logs matched")
+	}
+	if falsePositives*2 > total {
+		t.Errorf("too many false positives: %d of %d", falsePositives, total)
+	}
+}
+
+func TestIndexMatches(t *testing.T) {
+	chain := newTestChain(1)
+	chain.addBlocks(0, 300)
+
+	f := New(rawdb.NewMemoryDatabase(), chain, testParams, 0)
+	f.updateForTesting()
+	if first, last, _ := f.IndexedRange(); first != 0 || last != 299 {
+		t.Fatalf("indexed range mismatch: have %d-%d, want 0-299", first, last)
+	}
+	select {
+	case <-f.Synced():
+	default:
+		t.Fatal("index not marked synced")
+	}
+	checkMatches(t, f, chain)
+
+	// Extend the chain and check the new blocks are indexed
+	chain.addBlocks(300, 50)
+	f.updateForTesting()
+	if _, last, _ := f.IndexedRange(); last != 349 {
+		t.Fatalf("indexed head mismatch: have %d, want 349", last)
+	}
+	checkMatches(t, f, chain)
+}
+
+func TestIndexReorg(t *testing.T) {
+	chain := newTestChain(2)
+	chain.addBlocks(0, 200)
+
+	db := rawdb.NewMemoryDatabase()
+	f := New(db, chain, testParams, 0)
+	f.updateForTesting()
+
+	// Replace the head of the chain with a longer and a shorter fork
+	for _, fork := range [][2]int{{150, 80}, {120, 20}} {
+		chain.addBlocks(fork[0], fork[1])
+		f.updateForTesting()
+
+		if _, last, _ := f.IndexedRange(); last != uint64(fork[0]+fork[1]-1) {
+			t.Fatalf("indexed head mismatch: have %d, want %d", last, fork[0]+fork[1]-1)
+		}
+		checkMatches(t, f, chain)
+	}
+	// Reopening the index resumes from the stored range
+	f = New(db, chain, testParams, 0)
+	if _, last, ok := f.IndexedRange(); !ok || last != 139 {
+		t.Fatalf("reopened index head mismatch: have %d, want 139", last)
+	}
+	checkMatches(t, f, chain)
+}
+
+func TestIndexHistory(t *testing.T) {
+	chain := newTestChain(3)
+	chain.addBlocks(0, 200)
+
+	db := rawdb.NewMemoryDatabase()
+	f := New(db, chain, testParams, 100)
+	f.updateForTesting()
+	if first, last, _ := f.IndexedRange(); first != 100 || last != 199 {
+		t.Fatalf("indexed range mismatch: have %d-%d, want 100-199", first, last)
+	}
+	checkMatches(t, f, chain)
+
+	// Advance the head, the tail has to follow
+	chain.addBlocks(200, 150)
+	f.updateForTesting()
+	if first, last, _ := f.IndexedRange(); first != 250 || last != 349 {
+		t.Fatalf("indexed range mismatch: have %d-%d, want 250-349", first, last)
+	}
+	checkMatches(t, f, chain)
+
+	if _, _, ok := rawdb.ReadFilterMapBlock(db, 249); ok {
+		t.Fatal("pruned block still indexed")
+	}
+	tailLv, _, _ := rawdb.ReadFilterMapBlock(db, 250)
+	if tailMap := f.mapIndex(tailLv); tailMap > 0 {
+		if rows := rawdb.ReadFilterMapRows(db, tailMap-1); len(rows) != 0 {
+			t.Fatalf("pruned map %d still present", tailMap-1)
+		}
+	}
+	// Queries out of the range are rejected
+	if _, err := f.PotentialMatches(context.Background(), 100, 300, testAddresses[:1], nil); err != ErrNotIndexed {
+		t.Fatalf("wrong error for unindexed range: have %v, want %v", err, ErrNotIndexed)
+	}
+	// Extending the history rebuilds the index
+	f = New(db, chain, testParams, 0)
+	f.updateForTesting()
+	if first, last, _ := f.IndexedRange(); first != 0 || last != 349 {
+		t.Fatalf("indexed range mismatch: have %d-%d, want 0-349", first, last)
+	}
+	checkMatches(t, f, chain)
+}
+
+// Tests that the expired history is left out of the index.
+func TestIndexHistoryCutoff(t *testing.T) {
+	chain := newTestChain(5)
+	chain.addBlocks(0, 200)
+
+	db := rawdb.NewMemoryDatabase()
+	f := New(db, chain, testParams, 0)
+	f.updateForTesting()
+
+	// Expire the history below block 120, dropping the receipts
+	chain.cutoff = 120
+	for _, hash := range chain.hashes[:120] {
+		delete(chain.receipts, hash)
+	}
+	chain.addBlocks(200, 10)
+	f.updateForTesting()
+	if first, last, _ := f.IndexedRange(); first != 120 || last != 209 {
+		t.Fatalf("indexed range mismatch: have %d-%d, want 120-209", first, last)
+	}
+	checkMatches(t, f, chain)
+
+	// Rebuilding the index starts at the cutoff
+	f = New(rawdb.NewMemoryDatabase(), chain, testParams, 0)
+	f.updateForTesting()
+	if first, last, _ := f.IndexedRange(); first != 120 || last != 209 {
+		t.Fatalf("rebuilt range mismatch: have %d-%d, want 120-209", first, last)
+	}
+	checkMatches(t, f, chain)
+}
+
+func TestIndexBackground(t *testing.T) {
+	chain := newTestChain(4)
+	chain.addBlocks(0, 100)
+
+	f := New(rawdb.NewMemoryDatabase(), chain, testParams, 0)
+	f.Start()
+	defer f.Stop()
+
+	<-f.Synced()
+	if _, last, _ := f.IndexedRange(); last != 99 {
+		t.Fatalf("indexed head mismatch: have %d, want 99", last)
+	}
+}
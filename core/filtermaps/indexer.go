@@ -0,0 +1,309 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filtermaps
+
+import (
+	"math"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/log"
+)
+
+// maxBatchBlocks is the maximum number of blocks indexed between two commits
+// of the index range.
+const maxBatchBlocks = 1024
+
+// updateLoop keeps the index in sync with the chain head. The index is updated
+// in a background routine so that head events are always consumed promptly.
+func (f *FilterMaps) updateLoop() {
+	defer f.closeWg.Done()
+
+	var (
+		headCh = make(chan core.ChainHeadEvent, 1) // Buffered to avoid locking up the event feed
+		head   = f.chain.CurrentBlock()
+		done   chan struct{} // Non-nil if background update routine is active
+		dirty  = true        // Whether the head changed since the last update was started
+	)
+	sub := f.chain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	for {
+		if done == nil && dirty && head != nil {
+			done, dirty = make(chan struct{}), false
+			go f.update(head, done)
+		}
+		select {
+		case ev := <-headCh:
+			head, dirty = ev.Block.Header(), true
+		case <-done:
+			done = nil
+		case <-f.closeCh:
+			if done != nil {
+				log.Info("Waiting background log indexer to exit")
+				<-done
+			}
+			return
+		}
+	}
+}
+
+// closing returns whether the log index is being stopped.
+func (f *FilterMaps) closing() bool {
+	select {
+	case <-f.closeCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// update brings the index in line with the given chain head: stale blocks are
+// rolled back after a reorg, blocks falling out of the history window pruned
+// and new blocks indexed.
+func (f *FilterMaps) update(head *types.Header, done chan struct{}) {
+	defer close(done)
+
+	number := head.Number.Uint64()
+	tail := uint64(0)
+	if f.history != 0 && number+1 > f.history {
+		tail = number + 1 - f.history
+	}
+	// The receipts of the expired history are gone, it can't be indexed.
+	if cutoff := f.chain.HistoryPruningCutoff(); tail < cutoff {
+		tail = cutoff
+	}
+	f.lock.RLock()
+	fmr, initialized := f.fmr, f.initialized
+	f.lock.RUnlock()
+
+	// Rebuild the index from scratch if it doesn't exist yet or can't be
+	// extended downwards to the requested history.
+	if !initialized || tail < fmr.Tail {
+		fmr = f.reset(tail)
+	}
+	// Roll back the blocks which are not canonical anymore.
+	next := fmr.Next
+	if next > number+1 {
+		next = number + 1
+	}
+	for next > fmr.Tail {
+		if _, hash, _ := rawdb.ReadFilterMapBlock(f.db, next-1); hash == f.chain.GetCanonicalHash(next-1) {
+			break
+		}
+		next--
+	}
+	if next < fmr.Next {
+		fmr = f.rollback(fmr, next)
+	}
+	// Prune the blocks falling out of the history window.
+	if tail > fmr.Tail {
+		if tail >= fmr.Next {
+			fmr = f.reset(tail)
+		} else {
+			fmr = f.pruneTail(fmr, tail)
+		}
+	}
+	// Index the new blocks.
+	if !f.extend(fmr, number) {
+		return
+	}
+	f.syncedOnce.Do(func() { close(f.synced) })
+}
+
+// commit atomically writes the batch along with the new index range, and makes
+// the range visible to the matcher.
+func (f *FilterMaps) commit(batch ethdb.Batch, fmr rawdb.FilterMapsRange) {
+	rawdb.WriteFilterMapsRange(batch, &fmr)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write log index", "err", err)
+	}
+	f.fmr, f.initialized = fmr, true
+}
+
+// reset discards the whole index and starts over with an empty range at the
+// given tail block.
+func (f *FilterMaps) reset(tail uint64) rawdb.FilterMapsRange {
+	// Make the index unavailable before deleting the data so the matcher never
+	// sees a partially deleted index.
+	f.lock.Lock()
+	f.initialized = false
+	rawdb.DeleteFilterMapsRange(f.db)
+	f.lock.Unlock()
+
+	rawdb.DeleteFilterMaps(f.db, 0, math.MaxUint32)
+	rawdb.DeleteFilterMapBlocks(f.db, 0, math.MaxUint64)
+
+	fmr := rawdb.FilterMapsRange{
+		LogValuesPerMap: f.params.LogValuesPerMap,
+		LogMapHeight:    f.params.LogMapHeight,
+		LogMapWidth:     f.params.LogMapWidth,
+		Tail:            tail,
+		Next:            tail,
+	}
+	f.commit(f.db.NewBatch(), fmr)
+	log.Info("Initialized log index", "tail", tail)
+	return fmr
+}
+
+// rollback removes the blocks from next onwards from the index.
+func (f *FilterMaps) rollback(fmr rawdb.FilterMapsRange, next uint64) rawdb.FilterMapsRange {
+	var (
+		oldNext  = fmr.Next
+		oldLv    = fmr.NextLvIndex
+		lvIndex  uint64
+		headHash common.Hash
+	)
+	lvIndex, _, _ = rawdb.ReadFilterMapBlock(f.db, next)
+	if next > fmr.Tail {
+		_, headHash, _ = rawdb.ReadFilterMapBlock(f.db, next-1)
+	}
+	fmr.Next, fmr.NextLvIndex, fmr.HeadHash = next, lvIndex, headHash
+	f.commit(f.db.NewBatch(), fmr)
+
+	// Delete the entries of the removed blocks. Rows are truncated on load too,
+	// so an interruption here is healed when the positions are reused.
+	rawdb.DeleteFilterMapBlocks(f.db, next, oldNext)
+	if oldLv > lvIndex {
+		first, last := f.mapIndex(lvIndex), f.mapIndex(oldLv-1)
+		batch := f.db.NewBatch()
+		for row, columns := range rawdb.ReadFilterMapRows(f.db, first) {
+			rawdb.WriteFilterMapRow(batch, first, row, f.truncateRow(first, columns, lvIndex))
+		}
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to write log index", "err", err)
+		}
+		if last > first {
+			rawdb.DeleteFilterMaps(f.db, first+1, last+1)
+		}
+	}
+	log.Debug("Rolled back log index", "from", oldNext, "to", next)
+	return fmr
+}
+
+// pruneTail removes the blocks below tail from the index.
+func (f *FilterMaps) pruneTail(fmr rawdb.FilterMapsRange, tail uint64) rawdb.FilterMapsRange {
+	oldTail, oldLv := fmr.Tail, fmr.TailLvIndex
+
+	lvIndex, _, _ := rawdb.ReadFilterMapBlock(f.db, tail)
+	fmr.Tail, fmr.TailLvIndex = tail, lvIndex
+	f.commit(f.db.NewBatch(), fmr)
+
+	// Drop the block pointers and the maps entirely below the new tail, the
+	// entries of the pruned blocks in the tail map are skipped by the matcher.
+	rawdb.DeleteFilterMapBlocks(f.db, oldTail, tail)
+	if first, last := f.mapIndex(oldLv), f.mapIndex(lvIndex); last > first {
+		rawdb.DeleteFilterMaps(f.db, first, last)
+	}
+	return fmr
+}
+
+// truncateRow drops the column entries of a row added at or after the given log
+// value position.
+func (f *FilterMaps) truncateRow(mapIndex uint32, row []uint32, lvIndex uint64) []uint32 {
+	for i, column := range row {
+		if f.columnPosition(mapIndex, column) >= lvIndex {
+			return row[:i]
+		}
+	}
+	return row
+}
+
+// extend indexes the canonical blocks after the range up to and including head.
+// It returns false if the indexing was interrupted or blocks are not available.
+func (f *FilterMaps) extend(fmr rawdb.FilterMapsRange, head uint64) bool {
+	var (
+		batch  = f.db.NewBatch()
+		rows   = make(map[uint64][]uint32) // Rows modified in the batch, keyed by map and row index
+		blocks int
+
+		start  = time.Now()
+		logged = start.Add(-7 * time.Second)
+		from   = fmr.Next
+	)
+	flush := func() {
+		for key, row := range rows {
+			rawdb.WriteFilterMapRow(batch, uint32(key>>32), uint32(key), row)
+		}
+		f.commit(batch, fmr)
+		batch.Reset()
+		rows, blocks = make(map[uint64][]uint32), 0
+	}
+	for fmr.Next <= head {
+		if f.closing() {
+			flush()
+			return false
+		}
+		hash := f.chain.GetCanonicalHash(fmr.Next)
+		if hash == (common.Hash{}) {
+			break // Reorged below the head while indexing, retry with the new head
+		}
+		receipts := f.chain.GetReceiptsByHash(hash)
+		if receipts == nil {
+			log.Debug("Receipts unavailable for log indexing", "number", fmr.Next, "hash", hash)
+			break
+		}
+		rawdb.WriteFilterMapBlock(batch, fmr.Next, fmr.NextLvIndex, hash)
+		for _, receipt := range receipts {
+			for _, l := range receipt.Logs {
+				values := logValues(l)
+				lvIndex := f.placeLog(fmr.NextLvIndex, len(values))
+				for i, value := range values {
+					f.addValue(rows, value, lvIndex+uint64(i))
+				}
+				fmr.NextLvIndex = lvIndex + uint64(len(values))
+			}
+		}
+		fmr.Next, fmr.HeadHash = fmr.Next+1, hash
+
+		if blocks++; blocks >= maxBatchBlocks || batch.ValueSize()+4*len(rows) > ethdb.IdealBatchSize {
+			flush()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing logs", "blocks", fmr.Next-from, "head", head, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	flush()
+	if fmr.Next-from > 1 {
+		log.Debug("Indexed logs", "blocks", fmr.Next-from, "next", fmr.Next, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return fmr.Next > head
+}
+
+// addValue adds a log value at the given position to the rows of the batch.
+func (f *FilterMaps) addValue(rows map[uint64][]uint32, value common.Hash, lvIndex uint64) {
+	var (
+		mapIndex = f.mapIndex(lvIndex)
+		rowIndex = f.rowIndex(value, mapIndex)
+		key      = uint64(mapIndex)<<32 | uint64(rowIndex)
+	)
+	row, ok := rows[key]
+	if !ok {
+		// Drop any leftovers of an interrupted rollback on first use
+		row = f.truncateRow(mapIndex, rawdb.ReadFilterMapRow(f.db, mapIndex, rowIndex), lvIndex)
+	}
+	rows[key] = append(row, f.columnIndex(value, lvIndex))
+}
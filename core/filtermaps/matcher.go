@@ -0,0 +1,252 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filtermaps
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+)
+
+var (
+	// ErrMatchAll is returned if a filter has neither addresses nor topics, in
+	// which case the index is of no use.
+	ErrMatchAll = errors.New("filter matches all logs")
+
+	// ErrNotIndexed is returned if the requested block range is not covered by
+	// the log index.
+	ErrNotIndexed = errors.New("block range not indexed")
+)
+
+// constraint is a set of values one of which has to be present at a fixed
+// offset from the first position of a matching log.
+type constraint struct {
+	offset uint64
+	values []common.Hash
+}
+
+// PotentialMatches returns the logs of the blocks [first, last] which may match
+// the given addresses and topics, in the order they appear in the chain. The
+// result is a superset of the matching logs, callers have to filter out the
+// false positives.
+func (f *FilterMaps) PotentialMatches(ctx context.Context, first, last uint64, addresses []common.Address, topics [][]common.Hash) ([]*types.Log, error) {
+	var constraints []constraint
+	if len(addresses) > 0 {
+		c := constraint{offset: 0}
+		for _, address := range addresses {
+			c.values = append(c.values, addressValue(address))
+		}
+		constraints = append(constraints, c)
+	}
+	for i, sub := range topics {
+		if len(sub) == 0 {
+			continue // wildcard
+		}
+		c := constraint{offset: uint64(1 + i)}
+		for _, topic := range sub {
+			c.values = append(c.values, topicValue(topic))
+		}
+		constraints = append(constraints, c)
+	}
+	if len(constraints) == 0 {
+		return nil, ErrMatchAll
+	}
+	// Resolve the log value range of the requested blocks
+	f.lock.RLock()
+	fmr, initialized := f.fmr, f.initialized
+	f.lock.RUnlock()
+
+	if !initialized || first < fmr.Tail || last >= fmr.Next || first > last {
+		return nil, ErrNotIndexed
+	}
+	firstLv, _, _ := rawdb.ReadFilterMapBlock(f.db, first)
+	endLv := fmr.NextLvIndex
+	if last+1 < fmr.Next {
+		endLv, _, _ = rawdb.ReadFilterMapBlock(f.db, last+1)
+	}
+	if firstLv >= endLv {
+		return nil, nil // no logs in range
+	}
+	var logs []*types.Log
+	for m := f.mapIndex(firstLv); m <= f.mapIndex(endLv-1); m++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		matches, err := f.mapMatches(m, constraints, firstLv, endLv)
+		if err != nil {
+			return nil, err
+		}
+		found, err := f.resolveMatches(matches)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, found...)
+	}
+	return logs, nil
+}
+
+// mapMatches returns the positions of the potentially matching logs in a map,
+// limited to the range [firstLv, endLv).
+func (f *FilterMaps) mapMatches(mapIndex uint32, constraints []constraint, firstLv, endLv uint64) ([]uint64, error) {
+	// Hold the lock while reading the rows, so the range can't be rolled back or
+	// pruned in between.
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	if !f.initialized {
+		return nil, ErrNotIndexed
+	}
+	if firstLv < f.fmr.TailLvIndex {
+		firstLv = f.fmr.TailLvIndex
+	}
+	if endLv > f.fmr.NextLvIndex {
+		endLv = f.fmr.NextLvIndex
+	}
+	var matches []uint64
+	for i, c := range constraints {
+		var positions []uint64
+		for _, value := range c.values {
+			for _, column := range rawdb.ReadFilterMapRow(f.db, mapIndex, f.rowIndex(value, mapIndex)) {
+				lvIndex := f.columnPosition(mapIndex, column)
+				if lvIndex < firstLv+c.offset || lvIndex >= endLv {
+					continue
+				}
+				if f.columnIndex(value, lvIndex) == column {
+					positions = append(positions, lvIndex-c.offset)
+				}
+			}
+		}
+		positions = sortUnique(positions)
+		if i == 0 {
+			matches = positions
+		} else {
+			matches = intersect(matches, positions)
+		}
+		if len(matches) == 0 {
+			return nil, nil
+		}
+	}
+	return matches, nil
+}
+
+// resolveMatches returns the logs starting at the given sorted positions.
+func (f *FilterMaps) resolveMatches(matches []uint64) ([]*types.Log, error) {
+	var logs []*types.Log
+	for len(matches) > 0 {
+		number, lvIndex, hash, err := f.findBlock(matches[0])
+		if err != nil {
+			return nil, err
+		}
+		// Skip the block if it was reorged since the positions were read
+		if f.chain.GetCanonicalHash(number) != hash {
+			for len(matches) > 0 && matches[0] < f.blockEnd(number) {
+				matches = matches[1:]
+			}
+			continue
+		}
+		receipts := f.chain.GetReceiptsByHash(hash)
+		if receipts == nil {
+			return nil, errors.New("receipts unavailable for indexed block")
+		}
+		for _, receipt := range receipts {
+			for _, l := range receipt.Logs {
+				values := 1 + len(l.Topics)
+				lvIndex = f.placeLog(lvIndex, values)
+				for len(matches) > 0 && matches[0] < lvIndex {
+					matches = matches[1:] // false positive between logs
+				}
+				if len(matches) > 0 && matches[0] == lvIndex {
+					logs = append(logs, l)
+					matches = matches[1:]
+				}
+				lvIndex += uint64(values)
+			}
+		}
+		// Drop the positions of the block not belonging to any log
+		for len(matches) > 0 && matches[0] < lvIndex {
+			matches = matches[1:]
+		}
+	}
+	return logs, nil
+}
+
+// findBlock returns the number, first log value position and hash of the block
+// containing the given log value position.
+func (f *FilterMaps) findBlock(lvIndex uint64) (uint64, uint64, common.Hash, error) {
+	f.lock.RLock()
+	tail, next := f.fmr.Tail, f.fmr.Next
+	f.lock.RUnlock()
+
+	// Find the first block starting after the position, the one before it holds
+	// the position.
+	n := sort.Search(int(next-tail), func(i int) bool {
+		first, _, ok := rawdb.ReadFilterMapBlock(f.db, tail+uint64(i))
+		return !ok || first > lvIndex
+	})
+	if n == 0 {
+		return 0, 0, common.Hash{}, ErrNotIndexed
+	}
+	number := tail + uint64(n) - 1
+	first, hash, ok := rawdb.ReadFilterMapBlock(f.db, number)
+	if !ok {
+		return 0, 0, common.Hash{}, ErrNotIndexed
+	}
+	return number, first, hash, nil
+}
+
+// blockEnd returns the position following the log values of a block.
+func (f *FilterMaps) blockEnd(number uint64) uint64 {
+	if lvIndex, _, ok := rawdb.ReadFilterMapBlock(f.db, number+1); ok {
+		return lvIndex
+	}
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.fmr.NextLvIndex
+}
+
+// sortUnique sorts a list of positions and removes the duplicates.
+func sortUnique(list []uint64) []uint64 {
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	out := list[:0]
+	for _, v := range list {
+		if len(out) == 0 || v != out[len(out)-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// intersect returns the positions present in both sorted lists.
+func intersect(a, b []uint64) []uint64 {
+	var out []uint64
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case a[0] > b[0]:
+			b = b[1:]
+		default:
+			out = append(out, a[0])
+			a, b = a[1:], b[1:]
+		}
+	}
+	return out
+}
@@ -113,14 +113,21 @@ func NewIDWithChain(chain Blockchain) ID {
 // NewFilter creates a filter that returns if a fork ID should be rejected or not
 // based on the local chain's status.
 func NewFilter(chain Blockchain) Filter {
-	return newFilter(
-		chain.Config(),
-		chain.Genesis().Hash(),
-		func() (uint64, uint64) {
+	var (
+		config = chain.Config()
+		headfn = func() (uint64, uint64) {
 			head := chain.CurrentHeader()
 			return head.Number.Uint64(), head.Time
-		},
+		}
 	)
+	// If forks may be scheduled at runtime, rebuild the filter on every check so
+	// that the schedule in effect is always used.
+	if config.ForkSchedulingEnabled() {
+		return func(id ID) error {
+			return newFilter(config, chain.Genesis().Hash(), headfn)(id)
+		}
+	}
+	return newFilter(config, chain.Genesis().Hash(), headfn)
 }
 
 // NewStaticFilter creates a filter at block zero.
@@ -270,6 +277,14 @@ func gatherForks(config *params.ChainConfig) ([]uint64, []uint64) {
 			}
 		}
 	}
+	// Forks scheduled at runtime are never configured statically, add them too
+	for _, time := range config.ScheduledForks() {
+		forksByTime = append(forksByTime, time)
+	}
+	// Gori forks are kept in a list of their own, add them too
+	for _, fork := range config.GoriForks {
+		forksByTime = append(forksByTime, fork.Time)
+	}
 	slices.Sort(forksByBlock)
 	slices.Sort(forksByTime)
 
@@ -382,3 +382,68 @@ func TestEncoding(t *testing.T) {
 		}
 	}
 }
+
+// Tests that forks scheduled at runtime are included in the fork ID just like
+// statically configured ones.
+func TestScheduledForks(t *testing.T) {
+	const cancun = 1710338135
+
+	static := *params.MainnetChainConfig
+	static.CancunTime, static.PragueTime, static.VerkleTime, static.EOFTime = nil, nil, nil, nil
+
+	scheduled := static
+	scheduled.EnableForkScheduling()
+	if err := scheduled.ScheduleForks(map[string]uint64{"cancun": cancun}, *static.ShanghaiTime); err != nil {
+		t.Fatalf("failed to schedule fork: %v", err)
+	}
+	cancunTime := uint64(cancun)
+	static.CancunTime = &cancunTime
+
+	for _, time := range []uint64{*static.ShanghaiTime, cancun - 1, cancun, cancun + 1} {
+		want := NewID(&static, params.MainnetGenesisHash, 20000000, time)
+		if have := NewID(&scheduled, params.MainnetGenesisHash, 20000000, time); have != want {
+			t.Errorf("time %d: fork ID mismatch: have %x, want %x", time, have, want)
+		}
+	}
+	if id := NewID(&scheduled, params.MainnetGenesisHash, 20000000, cancun-1); id.Next != cancun {
+		t.Errorf("scheduled fork not announced: next %d, want %d", id.Next, cancun)
+	}
+}
+
+// Tests that Gori forks are part of the fork ID, so that peers with mismatching
+// Gori fork schedules are rejected by the filter.
+func TestGoriForks(t *testing.T) {
+	const gori = 1720000000
+
+	plain := *params.MainnetChainConfig
+	plain.CancunTime, plain.PragueTime, plain.VerkleTime, plain.EOFTime = nil, nil, nil, nil
+
+	forked := plain
+	forked.GoriForks = []params.GoriFork{{Name: "alpha", Time: gori}}
+
+	// Before the fork, the IDs only differ in the announced next fork
+	before, plainBefore := NewID(&forked, params.MainnetGenesisHash, 20000000, gori-1), NewID(&plain, params.MainnetGenesisHash, 20000000, gori-1)
+	if before.Hash != plainBefore.Hash || before.Next != gori || plainBefore.Next != 0 {
+		t.Fatalf("gori fork not announced: have %v, plain %v", before, plainBefore)
+	}
+	// After the fork, the checksums diverge
+	after := NewID(&forked, params.MainnetGenesisHash, 20000000, gori)
+	if after.Hash == before.Hash || after.Next != 0 {
+		t.Fatalf("gori fork not included in checksum: have %v", after)
+	}
+	// A forked node must reject a peer unaware of the fork once it's active,
+	// and the unaware node must reject the forked one announcing it
+	forkedFilter := newFilter(&forked, params.MainnetGenesisHash, func() (uint64, uint64) { return 20000000, gori })
+	if err := forkedFilter(NewID(&plain, params.MainnetGenesisHash, 20000000, gori)); err == nil {
+		t.Errorf("forked node accepted unaware peer")
+	}
+	plainFilter := newFilter(&plain, params.MainnetGenesisHash, func() (uint64, uint64) { return 20000000, gori })
+	if err := plainFilter(after); err == nil {
+		t.Errorf("unaware node accepted forked peer")
+	}
+	// Before the fork, both are still compatible
+	forkedFilter = newFilter(&forked, params.MainnetGenesisHash, func() (uint64, uint64) { return 20000000, gori - 1 })
+	if err := forkedFilter(plainBefore); err != nil {
+		t.Errorf("forked node rejected peer before the fork: %v", err)
+	}
+}
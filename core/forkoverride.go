@@ -0,0 +1,181 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/rlp"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	// ErrUntrustedForkOverride is returned if a fork override is not signed by
+	// any of the trusted signers.
+	ErrUntrustedForkOverride = errors.New("fork override signed by untrusted key")
+
+	// errForkOverrideChainID is returned if a fork override is meant for a
+	// different chain.
+	errForkOverrideChainID = errors.New("fork override chain ID mismatch")
+
+	// errForkOverrideEmpty is returned if a fork override schedules no forks.
+	errForkOverrideEmpty = errors.New("fork override schedules no forks")
+
+	// errForkOverrideStale is returned if a fork override does not supersede the
+	// one in effect, preventing older schedules from being replayed.
+	errForkOverrideStale = errors.New("stale fork override")
+)
+
+// ForkOverride is a signed schedule of timestamp based forks. It allows the
+// operators of private networks to activate forks on running nodes without a
+// binary upgrade, by distributing an override file signed by a trusted key.
+//
+// Each new override must carry a higher sequence number than the one in effect,
+// so that a previously signed schedule cannot be replayed over a newer one.
+type ForkOverride struct {
+	ChainID   *big.Int          `json:"chainId"`
+	Sequence  uint64            `json:"sequence"`
+	Forks     map[string]uint64 `json:"forks"`
+	Signature hexutil.Bytes     `json:"signature"`
+}
+
+// LoadForkOverride reads a JSON encoded fork override from a file.
+func LoadForkOverride(path string) (*ForkOverride, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	override := new(ForkOverride)
+	if err := json.Unmarshal(blob, override); err != nil {
+		return nil, fmt.Errorf("invalid fork override %s: %v", path, err)
+	}
+	return override, nil
+}
+
+// Hash returns the hash of the override content, which is what gets signed.
+func (o *ForkOverride) Hash() common.Hash {
+	type fork struct {
+		Name string
+		Time uint64
+	}
+	forks := make([]fork, 0, len(o.Forks))
+	for name, time := range o.Forks {
+		forks = append(forks, fork{name, time})
+	}
+	sort.Slice(forks, func(i, j int) bool { return forks[i].Name < forks[j].Name })
+
+	blob, _ := rlp.EncodeToBytes([]interface{}{o.ChainID, o.Sequence, forks})
+	return crypto.Keccak256Hash(blob)
+}
+
+// Sign signs the override with the given key.
+func (o *ForkOverride) Sign(key *ecdsa.PrivateKey) error {
+	hash := o.Hash()
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		return err
+	}
+	o.Signature = sig
+	return nil
+}
+
+// Signer recovers the address of the key which signed the override.
+func (o *ForkOverride) Signer() (common.Address, error) {
+	if len(o.Signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid fork override signature length %d", len(o.Signature))
+	}
+	hash := o.Hash()
+	pub, err := crypto.SigToPub(hash[:], o.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// ForkOverride returns the signed fork override in effect on the chain, or nil
+// if none was applied.
+func (bc *BlockChain) ForkOverride() *ForkOverride {
+	return bc.forkOverride.Load()
+}
+
+// ApplyForkOverride verifies that the override was signed by one of the trusted
+// signers and schedules its forks on the live chain config. The override must
+// have a higher sequence number than the one in effect. Forks must activate
+// after the current head, except for those already scheduled by a previous
+// override at the same time.
+func (bc *BlockChain) ApplyForkOverride(override *ForkOverride, signers []common.Address) error {
+	signer, err := override.Signer()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(signers, signer) {
+		return fmt.Errorf("%w: %v", ErrUntrustedForkOverride, signer)
+	}
+	if override.ChainID == nil || override.ChainID.Cmp(bc.chainConfig.ChainID) != 0 {
+		return fmt.Errorf("%w: have %v, want %v", errForkOverrideChainID, override.ChainID, bc.chainConfig.ChainID)
+	}
+	if active := bc.forkOverride.Load(); active != nil && override.Sequence <= active.Sequence {
+		return fmt.Errorf("%w: sequence %d, in effect %d", errForkOverrideStale, override.Sequence, active.Sequence)
+	}
+	if len(override.Forks) == 0 {
+		return errForkOverrideEmpty
+	}
+	if err := bc.chainConfig.ScheduleForks(override.Forks, bc.CurrentHeader().Time); err != nil {
+		return err
+	}
+	blob, err := json.Marshal(override)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteForkOverride(bc.db, blob)
+	bc.forkOverride.Store(override)
+
+	log.Info("Scheduled forks from signed override", "signer", signer, "sequence", override.Sequence, "hash", override.Hash(), "forks", override.Forks)
+	return nil
+}
+
+// restoreForkOverride reschedules the forks of the last override applied to the
+// chain. The override was verified when it was first applied, so it is trusted
+// and its forks may lie before the current head.
+func (bc *BlockChain) restoreForkOverride() {
+	blob := rawdb.ReadForkOverride(bc.db)
+	if len(blob) == 0 {
+		return
+	}
+	override := new(ForkOverride)
+	if err := json.Unmarshal(blob, override); err != nil {
+		log.Error("Failed to decode stored fork override", "err", err)
+		return
+	}
+	if err := bc.chainConfig.ScheduleForks(override.Forks, 0); err != nil {
+		log.Error("Failed to restore fork override", "hash", override.Hash(), "err", err)
+		return
+	}
+	bc.forkOverride.Store(override)
+	log.Info("Restored signed fork override", "sequence", override.Sequence, "hash", override.Hash(), "forks", override.Forks)
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/consensus/ethash"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/params"
+)
+
+// Tests that signed fork overrides are verified before being scheduled on the
+// live chain config, and that they are restored after a restart.
+func TestForkOverride(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		other, _ = crypto.GenerateKey()
+		signers  = []common.Address{crypto.PubkeyToAddress(key.PublicKey)}
+		db       = rawdb.NewMemoryDatabase()
+	)
+	config := *params.AllEthashProtocolChanges
+	config.ShanghaiTime = new(uint64)
+	config.CancunTime, config.PragueTime, config.VerkleTime, config.EOFTime = nil, nil, nil, nil
+
+	gspec := &Genesis{Config: &config, BaseFee: big.NewInt(params.InitialBaseFee)}
+	chain, err := NewBlockChain(db, nil, gspec, &ChainOverrides{ForkScheduling: true}, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	override := &ForkOverride{
+		ChainID: config.ChainID,
+		Forks:   map[string]uint64{"cancun": 1000},
+	}
+	if err := chain.ApplyForkOverride(override, signers); err == nil {
+		t.Fatal("unsigned override accepted")
+	}
+	override.Sign(other)
+	if err := chain.ApplyForkOverride(override, signers); !errors.Is(err, ErrUntrustedForkOverride) {
+		t.Fatalf("wrong error for untrusted signer: have %v, want %v", err, ErrUntrustedForkOverride)
+	}
+	wrongChain := &ForkOverride{ChainID: big.NewInt(1), Forks: override.Forks}
+	wrongChain.Sign(key)
+	if err := chain.ApplyForkOverride(wrongChain, signers); !errors.Is(err, errForkOverrideChainID) {
+		t.Fatalf("wrong error for foreign chain: have %v, want %v", err, errForkOverrideChainID)
+	}
+	override.Sign(key)
+	if err := chain.ApplyForkOverride(override, signers); err != nil {
+		t.Fatalf("failed to apply override: %v", err)
+	}
+	if !chain.Config().IsCancun(common.Big0, 1000) || chain.Config().IsCancun(common.Big0, 999) {
+		t.Fatal("override not scheduled")
+	}
+	if chain.ForkOverride() != override {
+		t.Fatal("applied override not tracked")
+	}
+	// Ensure older schedules can't be replayed over the one in effect
+	newer := &ForkOverride{
+		ChainID:  config.ChainID,
+		Sequence: 1,
+		Forks:    map[string]uint64{"cancun": 1000, "prague": 2000},
+	}
+	newer.Sign(key)
+	if err := chain.ApplyForkOverride(newer, signers); err != nil {
+		t.Fatalf("failed to apply newer override: %v", err)
+	}
+	if err := chain.ApplyForkOverride(override, signers); !errors.Is(err, errForkOverrideStale) {
+		t.Fatalf("wrong error for replayed override: have %v, want %v", err, errForkOverrideStale)
+	}
+	if chain.ForkOverride() != newer || !chain.Config().IsPrague(common.Big0, 2000) {
+		t.Fatal("replayed override replaced the newer one")
+	}
+	override = newer
+	chain.Stop()
+
+	// Reopen the chain and ensure the override is restored
+	chain, err = NewBlockChain(db, nil, nil, &ChainOverrides{ForkScheduling: true}, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if restored := chain.ForkOverride(); restored == nil || restored.Hash() != override.Hash() {
+		t.Fatalf("override not restored: %v", restored)
+	}
+	if !chain.Config().IsCancun(common.Big0, 1000) {
+		t.Fatal("restored override not scheduled")
+	}
+}
@@ -17,13 +17,16 @@
 package core
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/common/hexutil"
@@ -31,6 +34,7 @@ import (
 	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/core/state"
 	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/core/vm"
 	"github.com/gorievm/go-gori/crypto"
 	"github.com/gorievm/go-gori/ethdb"
 	"github.com/gorievm/go-gori/log"
@@ -57,6 +61,11 @@ type Genesis struct {
 	Coinbase   common.Address      `json:"coinbase"`
 	Alloc      GenesisAlloc        `json:"alloc"      gencodec:"required"`
 
+	// AllocFile is the path of an external allocation file, in the format of
+	// the alloc field, which is streamed into the genesis state instead of being
+	// held in memory. Its accounts take precedence over the ones in Alloc.
+	AllocFile string `json:"allocFile,omitempty"`
+
 	// These fields are used for consensus tests. Please don't use them
 	// in actual genesis blocks.
 	Number        uint64      `json:"number"`
@@ -120,53 +129,143 @@ func (ga *GenesisAlloc) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// deriveHash computes the state root according to the genesis specification.
-func (ga *GenesisAlloc) deriveHash() (common.Hash, error) {
-	// Create an ephemeral in-memory database for computing hash,
-	// all the derived states will be discarded to not pollute disk.
-	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+const (
+	// genesisCommitInterval is the number of accounts streamed from a genesis
+	// allocation file after which the state is committed, bounding the memory
+	// held by dirty state objects.
+	genesisCommitInterval = 10000
+
+	// genesisDirtyLimit is the memory allowance of dirty trie nodes while
+	// streaming a genesis allocation file into a hash based trie database.
+	genesisDirtyLimit = 256 * 1024 * 1024
+)
+
+// write applies the genesis allocation to an empty state in the given database
+// and returns the committed state root. The accounts of the external allocation
+// file, if any, are streamed in first and take precedence over the inline ones.
+func (ga *GenesisAlloc) write(db state.Database, allocFile string) (common.Hash, error) {
 	statedb, err := state.New(types.EmptyRootHash, db, nil)
 	if err != nil {
 		return common.Hash{}, err
 	}
+	if allocFile != "" {
+		var (
+			accounts int
+			start    = time.Now()
+			logged   = time.Now()
+		)
+		err := iterateAllocFile(allocFile, func(addr common.Address, account *GenesisAccount) error {
+			applyGenesisAccount(statedb, addr, account)
+
+			if accounts++; accounts%genesisCommitInterval != 0 {
+				return nil
+			}
+			root, err := statedb.Commit(0, false)
+			if err != nil {
+				return err
+			}
+			if db.TrieDB().Scheme() == rawdb.HashScheme {
+				if err := db.TrieDB().Cap(genesisDirtyLimit); err != nil {
+					return err
+				}
+			}
+			if statedb, err = state.New(root, db, nil); err != nil {
+				return err
+			}
+			if time.Since(logged) > 8*time.Second {
+				log.Info("Importing genesis allocation", "accounts", accounts, "elapsed", common.PrettyDuration(time.Since(start)))
+				logged = time.Now()
+			}
+			return nil
+		})
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to import genesis allocation file: %v", err)
+		}
+		log.Info("Imported genesis allocation", "accounts", accounts, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
 	for addr, account := range *ga {
-		statedb.AddBalance(addr, account.Balance)
-		statedb.SetCode(addr, account.Code)
-		statedb.SetNonce(addr, account.Nonce)
-		for key, value := range account.Storage {
-			statedb.SetState(addr, key, value)
+		if allocFile != "" && statedb.Exist(addr) {
+			continue // overridden by the allocation file
 		}
+		applyGenesisAccount(statedb, addr, &account)
 	}
 	return statedb.Commit(0, false)
 }
 
-// flush is very similar with deriveHash, but the main difference is
-// all the generated states will be persisted into the given database.
-// Also, the genesis state specification will be flushed as well.
-func (ga *GenesisAlloc) flush(db ethdb.Database, triedb *trie.Database, blockhash common.Hash) error {
-	statedb, err := state.New(types.EmptyRootHash, state.NewDatabaseWithNodeDB(db, triedb), nil)
+// applyGenesisAccount adds a genesis account to the state.
+func applyGenesisAccount(statedb *state.StateDB, addr common.Address, account *GenesisAccount) {
+	statedb.AddBalance(addr, account.Balance)
+	statedb.SetCode(addr, account.Code)
+	statedb.SetNonce(addr, account.Nonce)
+	for key, value := range account.Storage {
+		statedb.SetState(addr, key, value)
+	}
+}
+
+// iterateAllocFile streams the accounts of a genesis allocation file, which has
+// the format of the alloc field of the genesis specification, without loading
+// the whole allocation into memory.
+func iterateAllocFile(path string, fn func(common.Address, *GenesisAccount) error) error {
+	file, err := os.Open(path)
 	if err != nil {
 		return err
 	}
-	for addr, account := range *ga {
-		statedb.AddBalance(addr, account.Balance)
-		statedb.SetCode(addr, account.Code)
-		statedb.SetNonce(addr, account.Nonce)
-		for key, value := range account.Storage {
-			statedb.SetState(addr, key, value)
+	defer file.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(file))
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return errors.New("allocation is not a JSON object")
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var addr common.UnprefixedAddress
+		if err := addr.UnmarshalText([]byte(tok.(string))); err != nil {
+			return fmt.Errorf("invalid address %q: %v", tok, err)
+		}
+		var account GenesisAccount
+		if err := dec.Decode(&account); err != nil {
+			return fmt.Errorf("invalid account %x: %v", addr, err)
+		}
+		if err := fn(common.Address(addr), &account); err != nil {
+			return err
 		}
 	}
-	root, err := statedb.Commit(0, false)
+	_, err = dec.Token()
+	return err
+}
+
+// deriveHash computes the state root according to the genesis specification.
+// The state is hashed as a verkle tree if isVerkle is set, otherwise as MPT.
+func (ga *GenesisAlloc) deriveHash(isVerkle bool, allocFile string) (common.Hash, error) {
+	// Create an ephemeral in-memory database for computing hash,
+	// all the derived states will be discarded to not pollute disk.
+	db := state.NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{IsVerkle: isVerkle})
+	return ga.write(db, allocFile)
+}
+
+// flush is very similar with deriveHash, but the main difference is
+// all the generated states will be persisted into the given database.
+func (ga *GenesisAlloc) flush(db ethdb.Database, triedb *trie.Database, allocFile string) (common.Hash, error) {
+	root, err := ga.write(state.NewDatabaseWithNodeDB(db, triedb), allocFile)
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 	// Commit newly generated states into disk if it's not empty.
 	if root != types.EmptyRootHash {
 		if err := triedb.Commit(root, true); err != nil {
-			return err
+			return common.Hash{}, err
 		}
 	}
-	// Marshal the genesis state specification and persist.
+	return root, nil
+}
+
+// writeSpec persists the genesis state specification.
+func (ga *GenesisAlloc) writeSpec(db ethdb.Database, blockhash common.Hash) error {
 	blob, err := json.Marshal(ga)
 	if err != nil {
 		return err
@@ -205,7 +304,10 @@ func CommitGenesisState(db ethdb.Database, triedb *trie.Database, blockhash comm
 			return errors.New("not found")
 		}
 	}
-	return alloc.flush(db, triedb, blockhash)
+	if _, err := alloc.flush(db, triedb, ""); err != nil {
+		return err
+	}
+	return alloc.writeSpec(db, blockhash)
 }
 
 // GenesisAccount is an account in the state of the genesis block.
@@ -274,6 +376,10 @@ func (e *GenesisMismatchError) Error() string {
 type ChainOverrides struct {
 	OverrideCancun *uint64
 	OverrideVerkle *uint64
+
+	// ForkScheduling permits timestamp forks to be scheduled on the live chain
+	// config by signed fork overrides.
+	ForkScheduling bool
 }
 
 // SetupGenesisBlock writes or updates the genesis block in db.
@@ -355,6 +461,21 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, triedb *trie.Database, gen
 	if err := newcfg.CheckConfigForkOrder(); err != nil {
 		return newcfg, common.Hash{}, err
 	}
+	if err := newcfg.CheckResourceLimits(); err != nil {
+		return newcfg, common.Hash{}, err
+	}
+	if err := newcfg.CheckGasOverrides(); err != nil {
+		return newcfg, common.Hash{}, err
+	}
+	if err := newcfg.CheckFeeMarket(); err != nil {
+		return newcfg, common.Hash{}, err
+	}
+	if err := newcfg.CheckBlockRewards(); err != nil {
+		return newcfg, common.Hash{}, err
+	}
+	if err := vm.CheckPrecompiles(newcfg); err != nil {
+		return newcfg, common.Hash{}, err
+	}
 	storedcfg := rawdb.ReadChainConfig(db, stored)
 	if storedcfg == nil {
 		log.Warn("Found genesis block without chain config")
@@ -388,6 +509,22 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, triedb *trie.Database, gen
 	return newcfg, stored, nil
 }
 
+// IsVerkleAtGenesis reports whether the chain state is stored in a verkle tree
+// from genesis on, according to the chain config already stored in the database
+// or, for an empty database, to the provided genesis specification.
+func IsVerkleAtGenesis(db ethdb.Database, genesis *Genesis) bool {
+	stored := rawdb.ReadCanonicalHash(db, 0)
+	if stored == (common.Hash{}) {
+		return genesis != nil && genesis.IsVerkle()
+	}
+	config := rawdb.ReadChainConfig(db, stored)
+	header := rawdb.ReadHeader(db, stored, 0)
+	if config == nil || header == nil {
+		return genesis != nil && genesis.IsVerkle()
+	}
+	return config.IsVerkle(header.Number, header.Time)
+}
+
 // LoadChainConfig loads the stored chain config if it is already present in
 // database, otherwise, return the config in the provided genesis specification.
 func LoadChainConfig(db ethdb.Database, genesis *Genesis) (*params.ChainConfig, error) {
@@ -436,12 +573,24 @@ func (g *Genesis) configOrDefault(ghash common.Hash) *params.ChainConfig {
 	}
 }
 
+// IsVerkle indicates whether the state is already stored in a verkle tree at
+// genesis time.
+func (g *Genesis) IsVerkle() bool {
+	return g.Config != nil && g.Config.IsVerkle(new(big.Int).SetUint64(g.Number), g.Timestamp)
+}
+
 // ToBlock returns the genesis block according to genesis specification.
 func (g *Genesis) ToBlock() *types.Block {
-	root, err := g.Alloc.deriveHash()
+	root, err := g.Alloc.deriveHash(g.IsVerkle(), g.AllocFile)
 	if err != nil {
 		panic(err)
 	}
+	return g.toBlockWithRoot(root)
+}
+
+// toBlockWithRoot returns the genesis block according to genesis specification
+// with the given state root.
+func (g *Genesis) toBlockWithRoot(root common.Hash) *types.Block {
 	head := &types.Header{
 		Number:     new(big.Int).SetUint64(g.Number),
 		Nonce:      types.EncodeNonce(g.Nonce),
@@ -485,6 +634,12 @@ func (g *Genesis) ToBlock() *types.Block {
 			if head.BlobGasUsed == nil {
 				head.BlobGasUsed = new(uint64)
 			}
+			// The genesis block has no parent, so its parent beacon block root
+			// is always the zero hash.
+			head.ParentBeaconRoot = new(common.Hash)
+		}
+		if conf.IsPrague(num, g.Timestamp) {
+			head.RequestsHash = &types.EmptyRequestsHash
 		}
 	}
 	return types.NewBlock(head, nil, nil, nil, trie.NewStackTrie(nil)).WithWithdrawals(withdrawals)
@@ -493,8 +648,7 @@ func (g *Genesis) ToBlock() *types.Block {
 // Commit writes the block and state of a genesis specification to the database.
 // The block is committed as the canonical head block.
 func (g *Genesis) Commit(db ethdb.Database, triedb *trie.Database) (*types.Block, error) {
-	block := g.ToBlock()
-	if block.Number().Sign() != 0 {
+	if g.Number != 0 {
 		return nil, errors.New("can't commit genesis block with number > 0")
 	}
 	config := g.Config
@@ -504,15 +658,45 @@ func (g *Genesis) Commit(db ethdb.Database, triedb *trie.Database) (*types.Block
 	if err := config.CheckConfigForkOrder(); err != nil {
 		return nil, err
 	}
-	if config.Clique != nil && len(block.Extra()) < 32+crypto.SignatureLength {
+	if err := config.CheckResourceLimits(); err != nil {
+		return nil, err
+	}
+	if err := config.CheckGasOverrides(); err != nil {
+		return nil, err
+	}
+	if err := config.CheckFeeMarket(); err != nil {
+		return nil, err
+	}
+	if err := config.CheckBlockRewards(); err != nil {
+		return nil, err
+	}
+	if err := vm.CheckPrecompiles(config); err != nil {
+		return nil, err
+	}
+	if config.Clique != nil && len(g.ExtraData) < 32+crypto.SignatureLength {
 		return nil, errors.New("can't start clique chain without signers")
 	}
+	if g.IsVerkle() != triedb.IsVerkle() {
+		return nil, errors.New("genesis state tree type mismatches the trie database")
+	}
 	// All the checks has passed, flush the states derived from the genesis
 	// specification as well as the specification itself into the provided
-	// database.
-	if err := g.Alloc.flush(db, triedb, block.Hash()); err != nil {
+	// database. The state is flushed first to avoid deriving it twice, which
+	// is expensive for large allocation files.
+	root, err := g.Alloc.flush(db, triedb, g.AllocFile)
+	if err != nil {
 		return nil, err
 	}
+	block := g.toBlockWithRoot(root)
+	if g.AllocFile == "" {
+		if err := g.Alloc.writeSpec(db, block.Hash()); err != nil {
+			return nil, err
+		}
+	} else {
+		// The specification can't be persisted without loading the whole
+		// allocation, the state can't be regenerated from it later on.
+		log.Warn("Genesis state specification not persisted", "allocFile", g.AllocFile)
+	}
 	rawdb.WriteTd(db, block.Hash(), block.NumberU64(), block.Difficulty())
 	rawdb.WriteBlock(db, block)
 	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), nil)
@@ -529,7 +713,7 @@ func (g *Genesis) Commit(db ethdb.Database, triedb *trie.Database) (*types.Block
 // Note the state changes will be committed in hash-based scheme, use Commit
 // if path-scheme is preferred.
 func (g *Genesis) MustCommit(db ethdb.Database) *types.Block {
-	block, err := g.Commit(db, trie.NewDatabase(db))
+	block, err := g.Commit(db, trie.NewDatabaseWithConfig(db, &trie.Config{IsVerkle: g.IsVerkle()}))
 	if err != nil {
 		panic(err)
 	}
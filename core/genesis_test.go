@@ -19,6 +19,8 @@ package core
 import (
 	"encoding/json"
 	"math/big"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -26,6 +28,7 @@ import (
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/consensus/ethash"
 	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/state"
 	"github.com/gorievm/go-gori/core/vm"
 	"github.com/gorievm/go-gori/ethdb"
 	"github.com/gorievm/go-gori/params"
@@ -219,7 +222,7 @@ func TestReadWriteGenesisAlloc(t *testing.T) {
 			{1}: {Balance: big.NewInt(1), Storage: map[common.Hash]common.Hash{{1}: {1}}},
 			{2}: {Balance: big.NewInt(2), Storage: map[common.Hash]common.Hash{{2}: {2}}},
 		}
-		hash, _ = alloc.deriveHash()
+		hash, _ = alloc.deriveHash(false, "")
 	)
 	blob, _ := json.Marshal(alloc)
 	rawdb.WriteGenesisStateSpec(db, hash, blob)
@@ -242,3 +245,57 @@ func TestReadWriteGenesisAlloc(t *testing.T) {
 		}
 	}
 }
+
+func TestGenesisAllocFile(t *testing.T) {
+	// Create a large allocation, exceeding the commit interval, along with an
+	// inline allocation partially overridden by it
+	var (
+		inline = GenesisAlloc{
+			{0x01}:                             {Balance: big.NewInt(1)},
+			common.BigToAddress(big.NewInt(2)): {Balance: big.NewInt(2), Code: []byte{0x01}},
+		}
+		file   = make(GenesisAlloc)
+		merged = make(GenesisAlloc)
+	)
+	for i := 0; i < genesisCommitInterval+10; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i + 2)))
+		file[addr] = GenesisAccount{
+			Balance: big.NewInt(int64(i)),
+			Nonce:   uint64(i),
+			Storage: map[common.Hash]common.Hash{{0x01}: common.BigToHash(big.NewInt(int64(i + 1)))},
+		}
+		merged[addr] = file[addr]
+	}
+	merged[common.Address{0x01}] = inline[common.Address{0x01}]
+
+	blob, err := json.Marshal(file)
+	if err != nil {
+		t.Fatalf("failed to encode allocation: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "alloc.json")
+	if err := os.WriteFile(path, blob, 0644); err != nil {
+		t.Fatalf("failed to write allocation: %v", err)
+	}
+	// Commit the streamed genesis and check it matches the in-memory one
+	streamed := &Genesis{Config: params.AllEthashProtocolChanges, Alloc: inline, AllocFile: path}
+	want := (&Genesis{Config: params.AllEthashProtocolChanges, Alloc: merged}).ToBlock()
+
+	if have := streamed.ToBlock(); have.Hash() != want.Hash() {
+		t.Fatalf("derived genesis mismatch: have %x, want %x", have.Hash(), want.Hash())
+	}
+	db := rawdb.NewMemoryDatabase()
+	block, err := streamed.Commit(db, trie.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("failed to commit genesis: %v", err)
+	}
+	if block.Hash() != want.Hash() {
+		t.Fatalf("committed genesis mismatch: have %x, want %x", block.Hash(), want.Hash())
+	}
+	statedb, err := state.New(block.Root(), state.NewDatabase(db), nil)
+	if err != nil {
+		t.Fatalf("failed to open genesis state: %v", err)
+	}
+	if have := statedb.GetCode(common.BigToAddress(big.NewInt(2))); len(have) != 0 {
+		t.Fatalf("inline account not overridden, code %x", have)
+	}
+}
@@ -214,6 +214,7 @@ func (hc *HeaderChain) WriteHeaders(headers []*types.Header) (int, error) {
 		inserted    []rawdb.NumberHash      // Ephemeral lookup of number/hash for the chain
 		parentKnown = true                  // Set to true to force hc.HasHeader check the first iteration
 		batch       = hc.chainDb.NewBatch()
+		hashes      = make([]common.Hash, 0, len(headers)) // Hashes of the batch, not yet readable from the database
 	)
 	for i, header := range headers {
 		var hash common.Hash
@@ -237,11 +238,13 @@ func (hc *HeaderChain) WriteHeaders(headers []*types.Header) (int, error) {
 			hc.tdCache.Add(hash, new(big.Int).Set(newTD))
 
 			rawdb.WriteHeader(batch, header)
+			hc.writeSkip(batch, hash, header, headers[0], hashes)
 			inserted = append(inserted, rawdb.NumberHash{Number: number, Hash: hash})
 			hc.headerCache.Add(hash, header)
 			hc.numberCache.Add(hash, number)
 		}
 		parentKnown = alreadyKnown
+		hashes = append(hashes, hash)
 	}
 	// Skip the slow disk write of all headers if interrupted.
 	if hc.procInterrupt() {
@@ -255,6 +258,31 @@ func (hc *HeaderChain) WriteHeaders(headers []*types.Header) (int, error) {
 	return len(inserted), nil
 }
 
+// writeSkip stores the skip index entry of a header. The header's ancestry must
+// either be in the database or be part of the contiguous batch of headers, given
+// by its first header and the hashes of the ones preceding the header.
+func (hc *HeaderChain) writeSkip(db ethdb.KeyValueWriter, hash common.Hash, header *types.Header, first *types.Header, hashes []common.Hash) {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return
+	}
+	var (
+		height = rawdb.SkipHeight(number)
+		start  = first.Number.Uint64()
+		skip   common.Hash
+	)
+	if height >= start && height-start < uint64(len(hashes)) {
+		skip = hashes[height-start]
+	} else if height < start {
+		skip = rawdb.ReadAncestor(hc.chainDb, first.ParentHash, start-1, height)
+	}
+	if skip == (common.Hash{}) {
+		// Ancestry unknown, lookups will fall back to the parent links
+		return
+	}
+	rawdb.WriteHeaderSkip(db, hash, number, header.ParentHash, skip)
+}
+
 // writeHeadersAndSetHead writes a batch of block headers and applies the last
 // header as the chain head if the fork choicer says it's ok to update the chain.
 // Note: This method is not concurrent-safe with inserting blocks simultaneously
@@ -380,7 +408,7 @@ func (hc *HeaderChain) InsertHeaderChain(chain []*types.Header, start time.Time,
 
 // GetAncestor retrieves the Nth ancestor of a given block. It assumes that either the given block or
 // a close ancestor of it is canonical. maxNonCanonical points to a downwards counter limiting the
-// number of blocks to be individually checked before we reach the canonical chain.
+// number of non-canonical hops along the header skip index before we reach the canonical chain.
 //
 // Note: ancestor == 0 returns the same block, 1 returns its parent and so on.
 func (hc *HeaderChain) GetAncestor(hash common.Hash, number, ancestor uint64, maxNonCanonical *uint64) (common.Hash, uint64) {
@@ -394,25 +422,24 @@ func (hc *HeaderChain) GetAncestor(hash common.Hash, number, ancestor uint64, ma
 		}
 		return common.Hash{}, 0
 	}
-	for ancestor != 0 {
+	target := number - ancestor
+	for number > target {
 		if rawdb.ReadCanonicalHash(hc.chainDb, number) == hash {
-			ancestorHash := rawdb.ReadCanonicalHash(hc.chainDb, number-ancestor)
+			ancestorHash := rawdb.ReadCanonicalHash(hc.chainDb, target)
 			if rawdb.ReadCanonicalHash(hc.chainDb, number) == hash {
-				number -= ancestor
-				return ancestorHash, number
+				return ancestorHash, target
 			}
 		}
 		if *maxNonCanonical == 0 {
 			return common.Hash{}, 0
 		}
 		*maxNonCanonical--
-		ancestor--
-		header := hc.GetHeader(hash, number)
-		if header == nil {
+
+		// Hop towards the target along the skip index, or the parent if the
+		// header was not indexed
+		if hash, number = rawdb.ReadAncestorStep(hc.chainDb, hash, number, target); hash == (common.Hash{}) {
 			return common.Hash{}, 0
 		}
-		hash = header.ParentHash
-		number--
 	}
 	return hash, number
 }
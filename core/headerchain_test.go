@@ -114,3 +114,47 @@ func TestHeaderInsertion(t *testing.T) {
 	// And B becomes even longer
 	testInsert(t, hc, chainB[107:128], CanonStatTy, nil, forker)
 }
+
+// Tests that ancestors of side chain headers are resolved along the header skip
+// index, needing far less non-canonical hops than the distance to the ancestor.
+func TestHeaderSideChainAncestor(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		gspec = &Genesis{BaseFee: big.NewInt(params.InitialBaseFee), Config: params.AllEthashProtocolChanges}
+	)
+	gspec.Commit(db, trie.NewDatabase(db))
+	hc, err := NewHeaderChain(db, gspec.Config, ethash.NewFaker(), func() bool { return false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	// chain A: G->A1->A2...A64, chain B: G->A1->B1...B512 on a lower difficulty
+	genDb, chainA := makeHeaderChainWithGenesis(gspec, 64, ethash.NewFaker(), 10)
+	chainB := makeHeaderChain(gspec.Config, chainA[0], 512, ethash.NewFaker(), genDb, 11)
+
+	forker := NewForkChoice(hc, nil)
+	testInsert(t, hc, chainA, CanonStatTy, nil, forker)
+	for i := 0; i < len(chainB); i += 128 {
+		if _, err := hc.WriteHeaders(chainB[i : i+128]); err != nil {
+			t.Fatalf("failed to write side chain: %v", err)
+		}
+	}
+	if hc.CurrentHeader().Hash() != chainA[len(chainA)-1].Hash() {
+		t.Fatalf("side chain became canonical")
+	}
+	head := chainB[len(chainB)-1]
+	for _, ancestor := range []uint64{2, 100, 256, 511} {
+		var (
+			maxNonCanonical = uint64(64)
+			want            = chainB[len(chainB)-1-int(ancestor)]
+		)
+		hash, number := hc.GetAncestor(head.Hash(), head.Number.Uint64(), ancestor, &maxNonCanonical)
+		if hash != want.Hash() || number != want.Number.Uint64() {
+			t.Errorf("ancestor %d: mismatch: have %d/%x, want %d/%x", ancestor, number, hash, want.Number, want.Hash())
+		}
+	}
+	// The fork point itself is reached via the canonical chain
+	maxNonCanonical := uint64(64)
+	if hash, _ := hc.GetAncestor(head.Hash(), head.Number.Uint64(), head.Number.Uint64()-1, &maxNonCanonical); hash != chainA[0].Hash() {
+		t.Errorf("fork point mismatch: have %x, want %x", hash, chainA[0].Hash())
+	}
+}
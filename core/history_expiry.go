@@ -0,0 +1,83 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/log"
+)
+
+// HistoryPruningCutoff returns the number of the first block whose body and
+// receipts are retained, 0 if the entire chain history is available.
+func (bc *BlockChain) HistoryPruningCutoff() uint64 {
+	cutoff := bc.cacheConfig.HistoryCutoff
+	if tail, err := bc.db.Tail(); err == nil && tail > cutoff {
+		// The history was pruned further with an earlier configuration.
+		cutoff = tail
+	}
+	return cutoff
+}
+
+// pruneHistory drops the bodies and receipts of the blocks below the history
+// cutoff from the ancient store. Blocks which are not frozen yet are pruned once
+// they are moved into the ancient store.
+func (bc *BlockChain) pruneHistory() {
+	frozen, err := bc.db.Ancients()
+	if err != nil {
+		return // No ancient store
+	}
+	target := bc.cacheConfig.HistoryCutoff
+	if target > frozen {
+		target = frozen
+	}
+	tail, err := bc.db.Tail()
+	if err != nil || tail >= target {
+		return
+	}
+	start := time.Now()
+	if _, err := bc.db.TruncateTail(target); err != nil {
+		log.Error("Failed to prune chain history", "tail", tail, "target", target, "err", err)
+		return
+	}
+	log.Info("Pruned chain history", "from", tail, "to", target, "elapsed", common.PrettyDuration(time.Since(start)))
+}
+
+// maintainHistory is responsible for the expiry of the chain history below the
+// configured cutoff. Headers are retained, only block bodies and receipts are
+// dropped. The indexers derived from them are capped at the cutoff themselves.
+func (bc *BlockChain) maintainHistory() {
+	defer bc.wg.Done()
+
+	headCh := make(chan ChainHeadEvent, 1) // Buffered to avoid locking up the event feed
+	sub := bc.SubscribeChainHeadEvent(headCh)
+	if sub == nil {
+		return
+	}
+	defer sub.Unsubscribe()
+
+	bc.pruneHistory()
+	for {
+		select {
+		case <-headCh:
+			bc.pruneHistory()
+		case <-bc.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/consensus/ethash"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/params"
+)
+
+// Tests that the bodies and receipts below the history cutoff are dropped along
+// with the indices derived from them, while the headers are retained.
+func TestHistoryExpiry(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		funds   = big.NewInt(100000000000000000)
+		gspec   = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   GenesisAlloc{address: {Balance: funds}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 128, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{0xaa}, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil), signer, key)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	db, _ := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	defer db.Close()
+
+	rawdb.WriteAncientBlocks(db, append([]*types.Block{gspec.ToBlock()}, blocks...), append([]types.Receipts{{}}, receipts...), big.NewInt(0))
+	rawdb.IndexTransactions(db, 0, 129, nil)
+
+	// Index the entire chain by address before enabling the expiry
+	config := *defaultCacheConfig
+	config.AddressIndex = true
+
+	chain, err := NewBlockChain(db, &config, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	chain.updateAddressIndex(nil, 128, make(chan struct{}))
+	chain.Stop()
+
+	config.HistoryCutoff = 64
+	chain, err = NewBlockChain(db, &config, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	chain.pruneHistory()
+	if cutoff := chain.HistoryPruningCutoff(); cutoff != 64 {
+		t.Fatalf("cutoff mismatch: have %d, want 64", cutoff)
+	}
+	for _, block := range blocks {
+		number, hash := block.NumberU64(), block.Hash()
+		if chain.GetHeader(hash, number) == nil {
+			t.Fatalf("header %d pruned", number)
+		}
+		expired := number < 64
+		if have := chain.GetBody(hash) == nil; have != expired {
+			t.Fatalf("body %d availability mismatch: missing %v, want %v", number, have, expired)
+		}
+		if have := chain.GetReceiptsByHash(hash) == nil; have != expired {
+			t.Fatalf("receipts %d availability mismatch: missing %v, want %v", number, have, expired)
+		}
+	}
+	// The indices of the expired history are dropped
+	chain.txLookupLimit = 0
+	chain.indexBlocks(rawdb.ReadTxIndexTail(db), 128, make(chan struct{}))
+	if tail := rawdb.ReadTxIndexTail(db); tail == nil || *tail != 64 {
+		t.Fatalf("tx index tail mismatch: have %v, want 64", tail)
+	}
+	for _, block := range blocks {
+		lookup := rawdb.ReadTxLookupEntry(db, block.Transactions()[0].Hash())
+		if expired := block.NumberU64() < 64; (lookup == nil) != expired {
+			t.Fatalf("tx lookup of block %d mismatch: have %v, expired %v", block.NumberU64(), lookup, expired)
+		}
+	}
+	chain.updateAddressIndex(rawdb.ReadAddressIndexTail(db), 128, make(chan struct{}))
+	if tail := rawdb.ReadAddressIndexTail(db); tail == nil || *tail != 64 {
+		t.Fatalf("address index tail mismatch: have %v, want 64", tail)
+	}
+	if entries := rawdb.ReadAddressTxEntries(db, address, 0, 1000); len(entries) != 65 || entries[0].BlockNumber != 64 {
+		t.Fatalf("address index mismatch: have %d entries", len(entries))
+	}
+}
@@ -278,6 +278,33 @@ func WriteTxIndexTail(db ethdb.KeyValueWriter, number uint64) {
 	}
 }
 
+// ReadAddressIndexTail retrieves the number of oldest block whose transactions
+// have been indexed by address.
+func ReadAddressIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(addressIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteAddressIndexTail stores the number of oldest block whose transactions
+// have been indexed by address into database.
+func WriteAddressIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(addressIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the address index tail", "err", err)
+	}
+}
+
+// DeleteAddressIndexTail removes the address index tail, marking the index as
+// not built.
+func DeleteAddressIndexTail(db ethdb.KeyValueWriter) {
+	if err := db.Delete(addressIndexTailKey); err != nil {
+		log.Crit("Failed to delete the address index tail", "err", err)
+	}
+}
+
 // ReadFastTxLookupLimit retrieves the tx lookup limit used in fast sync.
 func ReadFastTxLookupLimit(db ethdb.KeyValueReader) *uint64 {
 	data, _ := db.Get(fastTxLookupLimitKey)
@@ -423,6 +450,7 @@ func deleteHeaderWithoutNumber(db ethdb.KeyValueWriter, hash common.Hash, number
 	if err := db.Delete(headerKey(number, hash)); err != nil {
 		log.Crit("Failed to delete header", "err", err)
 	}
+	DeleteHeaderSkip(db, hash, number)
 }
 
 // isCanon is an internal utility method, to check whether the given number/hash
@@ -681,6 +709,56 @@ func DeleteReceipts(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadReceiptAccounting retrieves the accounting information recorded while
+// executing the transactions of a block and attaches it to the receipts. It
+// returns false if no accounting information was recorded for the block.
+func ReadReceiptAccounting(db ethdb.KeyValueReader, hash common.Hash, number uint64, receipts types.Receipts) bool {
+	data, _ := db.Get(receiptAccountingKey(number, hash))
+	if len(data) == 0 {
+		return false
+	}
+	var accounting []*types.ReceiptAccounting
+	if err := rlp.DecodeBytes(data, &accounting); err != nil {
+		log.Error("Invalid receipt accounting RLP", "hash", hash, "err", err)
+		return false
+	}
+	if len(accounting) != len(receipts) {
+		log.Error("Receipt accounting mismatch", "hash", hash, "have", len(accounting), "want", len(receipts))
+		return false
+	}
+	for i, receipt := range receipts {
+		receipt.Accounting = accounting[i]
+	}
+	return true
+}
+
+// WriteReceiptAccounting stores the accounting information of the receipts of
+// a block. Nothing is stored if any of the receipts is missing it.
+func WriteReceiptAccounting(db ethdb.KeyValueWriter, hash common.Hash, number uint64, receipts types.Receipts) {
+	accounting := make([]*types.ReceiptAccounting, len(receipts))
+	for i, receipt := range receipts {
+		if receipt.Accounting == nil {
+			return
+		}
+		accounting[i] = receipt.Accounting
+	}
+	bytes, err := rlp.EncodeToBytes(accounting)
+	if err != nil {
+		log.Crit("Failed to encode receipt accounting", "err", err)
+	}
+	if err := db.Put(receiptAccountingKey(number, hash), bytes); err != nil {
+		log.Crit("Failed to store receipt accounting", "err", err)
+	}
+}
+
+// DeleteReceiptAccounting removes the receipt accounting information associated
+// with a block hash.
+func DeleteReceiptAccounting(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(receiptAccountingKey(number, hash)); err != nil {
+		log.Crit("Failed to delete receipt accounting", "err", err)
+	}
+}
+
 // storedReceiptRLP is the storage encoding of a receipt.
 // Re-definition in core/types/receipt.go.
 // TODO: Re-use the existing definition.
@@ -822,6 +900,7 @@ func writeAncientBlock(op ethdb.AncientWriteOp, block *types.Block, header *type
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
+	DeleteReceiptAccounting(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
@@ -907,6 +986,11 @@ func WriteBadBlock(db ethdb.KeyValueStore, block *types.Block) {
 		return -a.Header.Number.Cmp(b.Header.Number)
 	})
 	if len(badBlocks) > badBlockToKeep {
+		for _, dropped := range badBlocks[badBlockToKeep:] {
+			if err := db.Delete(badBlockReportKey(dropped.Header.Hash())); err != nil {
+				log.Crit("Failed to delete bad block report", "err", err)
+			}
+		}
 		badBlocks = badBlocks[:badBlockToKeep]
 	}
 	data, err := rlp.EncodeToBytes(badBlocks)
@@ -918,13 +1002,41 @@ func WriteBadBlock(db ethdb.KeyValueStore, block *types.Block) {
 	}
 }
 
-// DeleteBadBlocks deletes all the bad blocks from the database
-func DeleteBadBlocks(db ethdb.KeyValueWriter) {
+// DeleteBadBlocks deletes all the bad blocks along with their reports from the
+// database.
+func DeleteBadBlocks(db ethdb.KeyValueStore) {
+	var badBlocks []*badBlock
+	if blob, err := db.Get(badBlockKey); err == nil {
+		if err := rlp.DecodeBytes(blob, &badBlocks); err != nil {
+			log.Warn("Failed to decode old bad blocks", "error", err)
+		}
+	}
+	for _, b := range badBlocks {
+		if err := db.Delete(badBlockReportKey(b.Header.Hash())); err != nil {
+			log.Crit("Failed to delete bad block report", "err", err)
+		}
+	}
 	if err := db.Delete(badBlockKey); err != nil {
 		log.Crit("Failed to delete bad blocks", "err", err)
 	}
 }
 
+// ReadBadBlockReport retrieves the diagnostics report of the bad block with the
+// given hash, the content is opaque to the database.
+func ReadBadBlockReport(db ethdb.KeyValueReader, hash common.Hash) []byte {
+	data, _ := db.Get(badBlockReportKey(hash))
+	return data
+}
+
+// WriteBadBlockReport stores the diagnostics report of a bad block. The report
+// is deleted along with the bad block once it's dropped from the list, thus it
+// has to be written after the bad block itself.
+func WriteBadBlockReport(db ethdb.KeyValueWriter, hash common.Hash, report []byte) {
+	if err := db.Put(badBlockReportKey(hash), report); err != nil {
+		log.Crit("Failed to store bad block report", "err", err)
+	}
+}
+
 // FindCommonAncestor returns the last common ancestor of two block headers
 func FindCommonAncestor(db ethdb.Reader, a, b *types.Header) *types.Header {
 	for bn := b.Number.Uint64(); a.Number.Uint64() > bn; {
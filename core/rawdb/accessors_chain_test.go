@@ -227,6 +227,10 @@ func TestBadBlockStorage(t *testing.T) {
 	if len(badBlocks) != 2 {
 		t.Fatalf("Failed to load all bad blocks")
 	}
+	WriteBadBlockReport(db, block.Hash(), []byte("report"))
+	if report := ReadBadBlockReport(db, block.Hash()); string(report) != "report" {
+		t.Fatalf("Retrieved bad block report mismatch: have %q, want %q", report, "report")
+	}
 
 	// Write a bunch of bad blocks, all the blocks are should sorted
 	// in reverse order. The extra blocks should be truncated.
@@ -250,12 +254,22 @@ func TestBadBlockStorage(t *testing.T) {
 		}
 	}
 
+	// The reports of the truncated blocks should be gone
+	if report := ReadBadBlockReport(db, block.Hash()); report != nil {
+		t.Fatalf("Report of the truncated bad block retained")
+	}
+	reported := badBlocks[0].Hash()
+	WriteBadBlockReport(db, reported, []byte("report"))
+
 	// Delete all bad blocks
 	DeleteBadBlocks(db)
 	badBlocks = ReadAllBadBlocks(db)
 	if len(badBlocks) != 0 {
 		t.Fatalf("Failed to delete bad blocks")
 	}
+	if report := ReadBadBlockReport(db, reported); report != nil {
+		t.Fatalf("Failed to delete bad block reports")
+	}
 }
 
 // Tests block total difficulty storage and retrieval operations.
@@ -18,7 +18,9 @@ package rawdb
 
 import (
 	"bytes"
+	"encoding/binary"
 	"math/big"
+	"time"
 
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/core/types"
@@ -93,6 +95,149 @@ func DeleteTxLookupEntries(db ethdb.KeyValueWriter, hashes []common.Hash) {
 	}
 }
 
+// PruneTxLookupEntries removes the lookup entries of all transactions included
+// in blocks below the given number. The entries are found by iterating the index
+// instead of the block bodies, so it also works on expired history. It returns
+// false if it was interrupted.
+func PruneTxLookupEntries(db ethdb.Database, number uint64, interrupt chan struct{}) bool {
+	it := db.NewIterator(txLookupPrefix, nil)
+	defer it.Release()
+
+	var (
+		batch   = db.NewBatch()
+		start   = time.Now()
+		logged  = start
+		deleted int
+	)
+	for it.Next() {
+		if len(it.Key()) != len(txLookupPrefix)+common.HashLength {
+			continue
+		}
+		entry := ReadTxLookupEntry(db, common.BytesToHash(it.Key()[len(txLookupPrefix):]))
+		if entry == nil || *entry >= number {
+			continue
+		}
+		batch.Delete(it.Key())
+		deleted++
+
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to prune transaction lookup entries", "err", err)
+			}
+			batch.Reset()
+
+			select {
+			case <-interrupt:
+				return false
+			default:
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning transaction indices", "deleted", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		log.Crit("Failed to iterate transaction lookup entries", "err", err)
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to prune transaction lookup entries", "err", err)
+	}
+	log.Debug("Pruned transaction indices", "number", number, "deleted", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+	return true
+}
+
+// AddressTxEntry is the location of a transaction touching an address.
+type AddressTxEntry struct {
+	BlockNumber uint64
+	Index       uint32
+	Hash        common.Hash
+}
+
+// WriteAddressTxEntry stores the location of a transaction under an address it
+// touches, enabling address based transaction lookups.
+func WriteAddressTxEntry(db ethdb.KeyValueWriter, address common.Address, entry AddressTxEntry) {
+	if err := db.Put(addressTxKey(address, entry.BlockNumber, entry.Index), entry.Hash.Bytes()); err != nil {
+		log.Crit("Failed to store address transaction entry", "err", err)
+	}
+}
+
+// DeleteAddressTxEntry removes the location of a transaction from the index of
+// an address.
+func DeleteAddressTxEntry(db ethdb.KeyValueWriter, address common.Address, number uint64, index uint32) {
+	if err := db.Delete(addressTxKey(address, number, index)); err != nil {
+		log.Crit("Failed to delete address transaction entry", "err", err)
+	}
+}
+
+// IterateAddressTxEntries calls fn with the transaction locations of an address
+// in ascending order, starting at block number from, until fn returns false.
+func IterateAddressTxEntries(db ethdb.Iteratee, address common.Address, from uint64, fn func(AddressTxEntry) bool) {
+	prefix := addressTxKeyPrefix(address)
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8+4 || len(it.Value()) != common.HashLength {
+			continue
+		}
+		entry := AddressTxEntry{
+			BlockNumber: binary.BigEndian.Uint64(key[len(prefix):]),
+			Index:       binary.BigEndian.Uint32(key[len(prefix)+8:]),
+			Hash:        common.BytesToHash(it.Value()),
+		}
+		if !fn(entry) {
+			return
+		}
+	}
+}
+
+// PruneAddressTxEntries removes the locations of all transactions included in
+// blocks below the given number from the address index.
+func PruneAddressTxEntries(db ethdb.Database, number uint64) {
+	it := db.NewIterator(addressTxPrefix, nil)
+	defer it.Release()
+
+	batch := db.NewBatch()
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(addressTxPrefix)+common.AddressLength+8+4 {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(addressTxPrefix)+common.AddressLength:]) >= number {
+			continue
+		}
+		batch.Delete(key)
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to prune address index", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		log.Crit("Failed to iterate address index", "err", err)
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to prune address index", "err", err)
+	}
+}
+
+// ReadAddressTxEntries retrieves at most limit transaction locations of an
+// address in ascending order, starting at block number from.
+func ReadAddressTxEntries(db ethdb.Iteratee, address common.Address, from uint64, limit int) []AddressTxEntry {
+	var entries []AddressTxEntry
+	if limit <= 0 {
+		return nil
+	}
+	IterateAddressTxEntries(db, address, from, func(entry AddressTxEntry) bool {
+		entries = append(entries, entry)
+		return len(entries) < limit
+	})
+	return entries
+}
+
 // ReadTransaction retrieves a specific transaction from the database, along with
 // its added positional metadata.
 func ReadTransaction(db ethdb.Reader, hash common.Hash) (*types.Transaction, common.Hash, uint64, uint64) {
@@ -19,6 +19,7 @@ package rawdb
 import (
 	"bytes"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/gorievm/go-gori/common"
@@ -154,3 +155,48 @@ func TestDeleteBloomBits(t *testing.T) {
 	check(1, 1, params.MainnetGenesisHash, true)
 	check(1, 1, params.SepoliaGenesisHash, true)
 }
+
+// Tests that address transaction entries can be stored, iterated and deleted.
+func TestAddressTxEntries(t *testing.T) {
+	var (
+		db    = NewMemoryDatabase()
+		addr  = common.Address{0x01}
+		other = common.Address{0x02}
+	)
+	var entries []AddressTxEntry
+	for number := uint64(1); number <= 3; number++ {
+		for index := uint32(0); index < 2; index++ {
+			entry := AddressTxEntry{BlockNumber: number, Index: index, Hash: common.Hash{byte(number), byte(index)}}
+			entries = append(entries, entry)
+			WriteAddressTxEntry(db, addr, entry)
+		}
+	}
+	WriteAddressTxEntry(db, other, AddressTxEntry{BlockNumber: 2, Hash: common.Hash{0xff}})
+
+	if have := ReadAddressTxEntries(db, addr, 0, 100); !reflect.DeepEqual(have, entries) {
+		t.Fatalf("entries mismatch: have %v, want %v", have, entries)
+	}
+	if have := ReadAddressTxEntries(db, addr, 2, 3); !reflect.DeepEqual(have, entries[2:5]) {
+		t.Fatalf("ranged entries mismatch: have %v, want %v", have, entries[2:5])
+	}
+	if have := ReadAddressTxEntries(db, other, 0, 100); len(have) != 1 || have[0].Hash != (common.Hash{0xff}) {
+		t.Fatalf("entries of other address mismatch: have %v", have)
+	}
+	DeleteAddressTxEntry(db, addr, 2, 0)
+	want := append(append([]AddressTxEntry{}, entries[:2]...), entries[3:]...)
+	if have := ReadAddressTxEntries(db, addr, 0, 100); !reflect.DeepEqual(have, want) {
+		t.Fatalf("entries mismatch after deletion: have %v, want %v", have, want)
+	}
+	// Check the index tail marker
+	if tail := ReadAddressIndexTail(db); tail != nil {
+		t.Fatalf("unexpected tail: %d", *tail)
+	}
+	WriteAddressIndexTail(db, 42)
+	if tail := ReadAddressIndexTail(db); tail == nil || *tail != 42 {
+		t.Fatalf("tail mismatch: have %v, want 42", tail)
+	}
+	DeleteAddressIndexTail(db)
+	if tail := ReadAddressIndexTail(db); tail != nil {
+		t.Fatalf("tail not deleted: %d", *tail)
+	}
+}
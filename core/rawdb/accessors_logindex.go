@@ -0,0 +1,226 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/rlp"
+)
+
+// FilterMapsRange is the persisted state of the log index. The index covers the
+// canonical blocks [Tail, Next) whose log values occupy the positions
+// [TailLvIndex, NextLvIndex) of the filter maps.
+type FilterMapsRange struct {
+	LogValuesPerMap uint // Parameters the maps were built with
+	LogMapHeight    uint
+	LogMapWidth     uint
+
+	Tail, Next               uint64
+	TailLvIndex, NextLvIndex uint64
+	HeadHash                 common.Hash // Hash of block Next-1, zero if the range is empty
+}
+
+// ReadFilterMapsRange retrieves the range covered by the log index.
+func ReadFilterMapsRange(db ethdb.KeyValueReader) *FilterMapsRange {
+	data, _ := db.Get(filterMapsRangeKey)
+	if len(data) == 0 {
+		return nil
+	}
+	var fmr FilterMapsRange
+	if err := rlp.DecodeBytes(data, &fmr); err != nil {
+		log.Error("Invalid log index range", "err", err)
+		return nil
+	}
+	return &fmr
+}
+
+// WriteFilterMapsRange stores the range covered by the log index.
+func WriteFilterMapsRange(db ethdb.KeyValueWriter, fmr *FilterMapsRange) {
+	data, err := rlp.EncodeToBytes(fmr)
+	if err != nil {
+		log.Crit("Failed to encode log index range", "err", err)
+	}
+	if err := db.Put(filterMapsRangeKey, data); err != nil {
+		log.Crit("Failed to store log index range", "err", err)
+	}
+}
+
+// DeleteFilterMapsRange removes the range of the log index, marking it as not
+// built.
+func DeleteFilterMapsRange(db ethdb.KeyValueWriter) {
+	if err := db.Delete(filterMapsRangeKey); err != nil {
+		log.Crit("Failed to delete log index range", "err", err)
+	}
+}
+
+// ReadFilterMapRow retrieves the column indices stored in a row of a filter map.
+func ReadFilterMapRow(db ethdb.KeyValueReader, mapIndex, rowIndex uint32) []uint32 {
+	data, _ := db.Get(filterMapRowKey(mapIndex, rowIndex))
+	return decodeFilterMapRow(data)
+}
+
+// WriteFilterMapRow stores the column indices of a filter map row, deleting the
+// row if it's empty.
+func WriteFilterMapRow(db ethdb.KeyValueWriter, mapIndex, rowIndex uint32, row []uint32) {
+	if len(row) == 0 {
+		if err := db.Delete(filterMapRowKey(mapIndex, rowIndex)); err != nil {
+			log.Crit("Failed to delete filter map row", "err", err)
+		}
+		return
+	}
+	data := make([]byte, 0, 4*len(row))
+	for _, column := range row {
+		data = binary.BigEndian.AppendUint32(data, column)
+	}
+	if err := db.Put(filterMapRowKey(mapIndex, rowIndex), data); err != nil {
+		log.Crit("Failed to store filter map row", "err", err)
+	}
+}
+
+// ReadFilterMapRows retrieves all the non-empty rows of a filter map.
+func ReadFilterMapRows(db ethdb.Iteratee, mapIndex uint32) map[uint32][]uint32 {
+	prefix := binary.BigEndian.AppendUint32(append([]byte{}, filterMapRowPrefix...), mapIndex)
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	rows := make(map[uint32][]uint32)
+	for it.Next() {
+		if len(it.Key()) != len(prefix)+4 {
+			continue
+		}
+		rows[binary.BigEndian.Uint32(it.Key()[len(prefix):])] = decodeFilterMapRow(it.Value())
+	}
+	return rows
+}
+
+// DeleteFilterMaps removes all rows of the filter maps in the given range. The
+// from is included while to is excluded.
+func DeleteFilterMaps(db ethdb.Database, from, to uint32) {
+	start, end := filterMapRowKey(from, 0), filterMapRowKey(to, 0)
+	it := db.NewIterator(nil, start)
+	defer it.Release()
+
+	for it.Next() {
+		if bytes.Compare(it.Key(), end) >= 0 {
+			break
+		}
+		if len(it.Key()) != len(filterMapRowPrefix)+8 {
+			continue
+		}
+		db.Delete(it.Key())
+	}
+	if it.Error() != nil {
+		log.Crit("Failed to delete filter maps", "err", it.Error())
+	}
+}
+
+func decodeFilterMapRow(data []byte) []uint32 {
+	if len(data)%4 != 0 {
+		log.Error("Invalid filter map row", "len", len(data))
+		return nil
+	}
+	row := make([]uint32, len(data)/4)
+	for i := range row {
+		row[i] = binary.BigEndian.Uint32(data[4*i:])
+	}
+	return row
+}
+
+// ReadFilterMapBlock retrieves the position of the first log value of a block in
+// the log index, along with the hash of the indexed block.
+func ReadFilterMapBlock(db ethdb.KeyValueReader, number uint64) (uint64, common.Hash, bool) {
+	data, _ := db.Get(filterMapBlockKey(number))
+	if len(data) != 8+common.HashLength {
+		return 0, common.Hash{}, false
+	}
+	return binary.BigEndian.Uint64(data), common.BytesToHash(data[8:]), true
+}
+
+// WriteFilterMapBlock stores the position of the first log value of a block in
+// the log index, along with the hash of the indexed block.
+func WriteFilterMapBlock(db ethdb.KeyValueWriter, number uint64, lvIndex uint64, hash common.Hash) {
+	data := binary.BigEndian.AppendUint64(make([]byte, 0, 8+common.HashLength), lvIndex)
+	if err := db.Put(filterMapBlockKey(number), append(data, hash.Bytes()...)); err != nil {
+		log.Crit("Failed to store filter map block pointer", "err", err)
+	}
+}
+
+// DeleteFilterMapBlocks removes the log index positions of the blocks in the
+// given range. The from is included while to is excluded.
+func DeleteFilterMapBlocks(db ethdb.Database, from, to uint64) {
+	start, end := filterMapBlockKey(from), filterMapBlockKey(to)
+	it := db.NewIterator(nil, start)
+	defer it.Release()
+
+	for it.Next() {
+		if bytes.Compare(it.Key(), end) >= 0 {
+			break
+		}
+		if len(it.Key()) != len(filterMapBlockPrefix)+8 {
+			continue
+		}
+		db.Delete(it.Key())
+	}
+	if it.Error() != nil {
+		log.Crit("Failed to delete filter map block pointers", "err", it.Error())
+	}
+}
+
+// ReadBloomBitsRetired retrieves whether the bloom bits index was superseded by
+// the log index.
+func ReadBloomBitsRetired(db ethdb.KeyValueReader) bool {
+	retired, _ := db.Has(bloomBitsRetiredKey)
+	return retired
+}
+
+// WriteBloomBitsRetired stores the flag marking the bloom bits index superseded.
+func WriteBloomBitsRetired(db ethdb.KeyValueWriter) {
+	if err := db.Put(bloomBitsRetiredKey, []byte{1}); err != nil {
+		log.Crit("Failed to store bloom bits retired flag", "err", err)
+	}
+}
+
+// DeleteBloomBitsRetired deletes the flag marking the bloom bits index superseded.
+func DeleteBloomBitsRetired(db ethdb.KeyValueWriter) {
+	if err := db.Delete(bloomBitsRetiredKey); err != nil {
+		log.Crit("Failed to remove bloom bits retired flag", "err", err)
+	}
+}
+
+// DeleteBloomBitsIndex removes all bloom bits vectors along with the progress of
+// the bloom bits chain indexer.
+func DeleteBloomBitsIndex(db ethdb.Database) {
+	for _, prefix := range [][]byte{bloomBitsPrefix, BloomBitsIndexPrefix} {
+		it := db.NewIterator(prefix, nil)
+		for it.Next() {
+			if bytes.Equal(prefix, bloomBitsPrefix) && len(it.Key()) != len(bloomBitsPrefix)+2+8+common.HashLength {
+				continue
+			}
+			db.Delete(it.Key())
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			log.Crit("Failed to delete bloom bits", "err", err)
+		}
+	}
+}
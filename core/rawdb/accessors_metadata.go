@@ -53,6 +53,49 @@ func WriteDatabaseVersion(db ethdb.KeyValueWriter, version uint64) {
 	}
 }
 
+// migrationProgress is the persisted state of an interrupted schema migration.
+type migrationProgress struct {
+	Version uint64 // Schema version the migration upgrades to
+	Marker  []byte // Migration specific marker to resume from
+}
+
+// ReadMigrationProgress retrieves the marker to resume the schema migration to
+// the given version from, or nil if the migration wasn't started yet.
+func ReadMigrationProgress(db ethdb.KeyValueReader, version uint64) []byte {
+	enc, _ := db.Get(databaseMigrationKey)
+	if len(enc) == 0 {
+		return nil
+	}
+	var progress migrationProgress
+	if err := rlp.DecodeBytes(enc, &progress); err != nil {
+		log.Error("Invalid database migration progress", "err", err)
+		return nil
+	}
+	if progress.Version != version {
+		return nil
+	}
+	return progress.Marker
+}
+
+// WriteMigrationProgress stores the marker to resume the schema migration to the
+// given version from.
+func WriteMigrationProgress(db ethdb.KeyValueWriter, version uint64, marker []byte) {
+	enc, err := rlp.EncodeToBytes(&migrationProgress{Version: version, Marker: marker})
+	if err != nil {
+		log.Crit("Failed to encode database migration progress", "err", err)
+	}
+	if err := db.Put(databaseMigrationKey, enc); err != nil {
+		log.Crit("Failed to store database migration progress", "err", err)
+	}
+}
+
+// DeleteMigrationProgress removes the progress of a finished schema migration.
+func DeleteMigrationProgress(db ethdb.KeyValueWriter) {
+	if err := db.Delete(databaseMigrationKey); err != nil {
+		log.Crit("Failed to remove database migration progress", "err", err)
+	}
+}
+
 // ReadChainConfig retrieves the consensus settings based on the given genesis hash.
 func ReadChainConfig(db ethdb.KeyValueReader, hash common.Hash) *params.ChainConfig {
 	data, _ := db.Get(configKey(hash))
@@ -100,6 +143,7 @@ func WriteGenesisStateSpec(db ethdb.KeyValueWriter, blockhash common.Hash, data
 type crashList struct {
 	Discarded uint64   // how many ucs have we deleted
 	Recent    []uint64 // unix timestamps of 10 latest unclean shutdowns
+	Seen      uint64   `rlp:"optional"` // how many ucs were known at the last startup
 }
 
 const crashesToKeep = 10
@@ -108,17 +152,25 @@ const crashesToKeep = 10
 // the previous data
 // - a list of timestamps
 // - a count of how many old unclean-shutdowns have been discarded
-func PushUncleanShutdownMarker(db ethdb.KeyValueStore) ([]uint64, uint64, error) {
+// - whether the run preceding this one ended with an unclean shutdown
+func PushUncleanShutdownMarker(db ethdb.KeyValueStore) ([]uint64, uint64, bool, error) {
 	var uncleanShutdowns crashList
 	// Read old data
 	if data, err := db.Get(uncleanShutdownKey); err != nil {
 		log.Warn("Error reading unclean shutdown markers", "error", err)
 	} else if err := rlp.DecodeBytes(data, &uncleanShutdowns); err != nil {
-		return nil, 0, err
+		return nil, 0, false, err
 	}
 	var discarded = uncleanShutdowns.Discarded
 	var previous = make([]uint64, len(uncleanShutdowns.Recent))
 	copy(previous, uncleanShutdowns.Recent)
+
+	// A clean shutdown pops its own marker, so any growth since the last startup
+	// means the last run was not shut down cleanly.
+	var total = discarded + uint64(len(previous))
+	var unclean = total > uncleanShutdowns.Seen
+	uncleanShutdowns.Seen = total
+
 	// Add a new (but cap it)
 	uncleanShutdowns.Recent = append(uncleanShutdowns.Recent, uint64(time.Now().Unix()))
 	if count := len(uncleanShutdowns.Recent); count > crashesToKeep+1 {
@@ -130,9 +182,9 @@ func PushUncleanShutdownMarker(db ethdb.KeyValueStore) ([]uint64, uint64, error)
 	data, _ := rlp.EncodeToBytes(uncleanShutdowns)
 	if err := db.Put(uncleanShutdownKey, data); err != nil {
 		log.Warn("Failed to write unclean-shutdown marker", "err", err)
-		return nil, 0, err
+		return nil, 0, false, err
 	}
-	return previous, discarded, nil
+	return previous, discarded, unclean, nil
 }
 
 // PopUncleanShutdownMarker removes the last unclean shutdown marker
@@ -187,3 +239,33 @@ func WriteTransitionStatus(db ethdb.KeyValueWriter, data []byte) {
 		log.Crit("Failed to store the eth2 transition status", "err", err)
 	}
 }
+
+// ReadExporterCheckpoint retrieves the position of the last block delivered by
+// the chain event exporter from the database.
+func ReadExporterCheckpoint(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(exporterCheckpointKey)
+	return data
+}
+
+// WriteExporterCheckpoint stores the position of the last block delivered by the
+// chain event exporter to the database.
+func WriteExporterCheckpoint(db ethdb.KeyValueWriter, data []byte) {
+	if err := db.Put(exporterCheckpointKey, data); err != nil {
+		log.Crit("Failed to store the exporter checkpoint", "err", err)
+	}
+}
+
+// ReadForkOverride retrieves the last signed fork override applied to the chain
+// config from the database.
+func ReadForkOverride(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(forkOverrideKey)
+	return data
+}
+
+// WriteForkOverride stores the last signed fork override applied to the chain
+// config to the database.
+func WriteForkOverride(db ethdb.KeyValueWriter, data []byte) {
+	if err := db.Put(forkOverrideKey, data); err != nil {
+		log.Crit("Failed to store the fork override", "err", err)
+	}
+}
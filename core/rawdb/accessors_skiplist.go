@@ -0,0 +1,150 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/log"
+)
+
+// The header skip index stores for every header, next to its parent, a pointer
+// to one of its far ancestors. The ancestor heights are chosen so that any other
+// ancestor can be reached in O(log n) hops, regardless of whether the header is
+// on the canonical chain or on a side chain. Canonical ancestors are resolved in
+// a single lookup via the canonical number to hash mapping instead.
+
+// invertLowestOne clears the lowest set bit of n.
+func invertLowestOne(n uint64) uint64 {
+	return n & (n - 1)
+}
+
+// SkipHeight returns the height of the ancestor the skip index entry of a header
+// at the given height points to.
+func SkipHeight(number uint64) uint64 {
+	if number < 2 {
+		return 0
+	}
+	// Odd heights point further back than even heights, which would otherwise
+	// only ever skip by powers of two from their closest even ancestor.
+	if number&1 == 1 {
+		return invertLowestOne(invertLowestOne(number-1)) + 1
+	}
+	return invertLowestOne(number)
+}
+
+// ReadHeaderSkip retrieves the parent and skip ancestor hashes of a header from
+// the skip index. The ok flag is false if the header is not indexed.
+func ReadHeaderSkip(db ethdb.KeyValueReader, hash common.Hash, number uint64) (parent common.Hash, skip common.Hash, ok bool) {
+	data, _ := db.Get(headerSkipKey(number, hash))
+	if len(data) != 2*common.HashLength {
+		return common.Hash{}, common.Hash{}, false
+	}
+	return common.BytesToHash(data[:common.HashLength]), common.BytesToHash(data[common.HashLength:]), true
+}
+
+// WriteHeaderSkip stores the parent and skip ancestor hashes of a header into
+// the skip index.
+func WriteHeaderSkip(db ethdb.KeyValueWriter, hash common.Hash, number uint64, parent common.Hash, skip common.Hash) {
+	data := make([]byte, 0, 2*common.HashLength)
+	data = append(data, parent.Bytes()...)
+	data = append(data, skip.Bytes()...)
+	if err := db.Put(headerSkipKey(number, hash), data); err != nil {
+		log.Crit("Failed to store header skip index", "err", err)
+	}
+}
+
+// DeleteHeaderSkip removes the skip index entry of a header.
+func DeleteHeaderSkip(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(headerSkipKey(number, hash)); err != nil {
+		log.Crit("Failed to delete header skip index", "err", err)
+	}
+}
+
+// ReadAncestorStep moves from a header towards its ancestor at the target height,
+// returning the hash and number of the header reached with a single hop. The hop
+// follows the skip index if it doesn't overshoot the target, falling back to the
+// parent otherwise or if the header is not indexed. An empty hash is returned if
+// the header is unknown.
+//
+// Note, the number must be higher than the target.
+func ReadAncestorStep(db ethdb.Reader, hash common.Hash, number uint64, target uint64) (common.Hash, uint64) {
+	parent, skip, ok := ReadHeaderSkip(db, hash, number)
+	if !ok {
+		header := ReadHeader(db, hash, number)
+		if header == nil {
+			return common.Hash{}, 0
+		}
+		return header.ParentHash, number - 1
+	}
+	// Take the skip pointer unless it overshoots the target or the parent's skip
+	// pointer would get closer to it, mirroring the walk in Bitcoin Core.
+	var (
+		height     = SkipHeight(number)
+		parentSkip = SkipHeight(number - 1)
+	)
+	if height == target || (height > target && !(parentSkip+2 < height && parentSkip >= target)) {
+		return skip, height
+	}
+	return parent, number - 1
+}
+
+// ReadAncestor retrieves the hash of the ancestor at the target height of the
+// given header, which may be on a side chain. An empty hash is returned if the
+// target is above the header or the ancestry is not known.
+func ReadAncestor(db ethdb.Reader, hash common.Hash, number uint64, target uint64) common.Hash {
+	if target > number {
+		return common.Hash{}
+	}
+	for number > target {
+		// Once on the canonical chain, jump straight to the target
+		if ReadCanonicalHash(db, number) == hash {
+			return ReadCanonicalHash(db, target)
+		}
+		if hash, number = ReadAncestorStep(db, hash, number, target); hash == (common.Hash{}) {
+			return common.Hash{}
+		}
+	}
+	return hash
+}
+
+// ReadCanonicalAncestor retrieves the highest ancestor of the given header that
+// is part of the canonical chain, i.e. the point where the header's chain forks
+// off from the canonical one. It returns the header itself if it is canonical.
+// An empty hash is returned if the ancestry is not known.
+func ReadCanonicalAncestor(db ethdb.Reader, hash common.Hash, number uint64) (common.Hash, uint64) {
+	if ReadCanonicalHash(db, number) == hash {
+		return hash, number
+	}
+	// Binary search the fork point, the ancestors are canonical up to it and
+	// non-canonical after it. The genesis is assumed to be shared.
+	lo, hi := uint64(0), number
+	for lo+1 < hi {
+		mid := lo + (hi-lo)/2
+
+		ancestor := ReadAncestor(db, hash, number, mid)
+		if ancestor == (common.Hash{}) {
+			return common.Hash{}, 0
+		}
+		if ReadCanonicalHash(db, mid) == ancestor {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return ReadCanonicalHash(db, lo), lo
+}
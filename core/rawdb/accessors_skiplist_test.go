@@ -0,0 +1,132 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/ethdb"
+)
+
+// Tests that the skip heights always point backwards, and that they match the
+// ones of Bitcoin Core.
+func TestSkipHeight(t *testing.T) {
+	for n := uint64(1); n < 4096; n++ {
+		if skip := SkipHeight(n); skip >= n {
+			t.Fatalf("height %d: skip height %d not below", n, skip)
+		}
+	}
+	tests := map[uint64]uint64{0: 0, 1: 0, 2: 0, 3: 1, 4: 0, 6: 4, 7: 1, 12: 8, 14: 12, 100: 96, 101: 65}
+	for n, want := range tests {
+		if have := SkipHeight(n); have != want {
+			t.Errorf("height %d: skip height mismatch: have %d, want %d", n, have, want)
+		}
+	}
+}
+
+// writeSkipChain writes a chain of headers on top of the given parent along with
+// their skip index entries.
+func writeSkipChain(db ethdb.Database, parent *types.Header, n int, extra byte) []*types.Header {
+	headers := make([]*types.Header, 0, n)
+	for i := 0; i < n; i++ {
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			Extra:      []byte{extra},
+		}
+		number := header.Number.Uint64()
+		WriteHeader(db, header)
+		WriteHeaderSkip(db, header.Hash(), number, header.ParentHash, ReadAncestor(db, header.ParentHash, number-1, SkipHeight(number)))
+
+		headers = append(headers, header)
+		parent = header
+	}
+	return headers
+}
+
+// Tests that ancestors are correctly resolved via the skip index, both on the
+// canonical chain and on side chains, and that the fork point of a side chain
+// is found.
+func TestAncestorLookup(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	genesis := &types.Header{Number: big.NewInt(0)}
+	WriteHeader(db, genesis)
+	WriteCanonicalHash(db, genesis.Hash(), 0)
+
+	canon := append([]*types.Header{genesis}, writeSkipChain(db, genesis, 300, 0)...)
+	for _, header := range canon {
+		WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+	}
+	side := append(append([]*types.Header{}, canon[:101]...), writeSkipChain(db, canon[100], 400, 1)...)
+
+	// Drop a few index entries, the lookups should fall back to the parents
+	for _, number := range []int{150, 256, 384} {
+		DeleteHeaderSkip(db, side[number].Hash(), uint64(number))
+	}
+	for _, chain := range [][]*types.Header{canon, side} {
+		head := chain[len(chain)-1]
+		for target := 0; target < len(chain); target++ {
+			if have, want := ReadAncestor(db, head.Hash(), head.Number.Uint64(), uint64(target)), chain[target].Hash(); have != want {
+				t.Errorf("head %d, target %d: ancestor mismatch: have %x, want %x", head.Number, target, have, want)
+			}
+		}
+		if hash := ReadAncestor(db, head.Hash(), head.Number.Uint64(), head.Number.Uint64()+1); hash != (common.Hash{}) {
+			t.Errorf("head %d: ancestor above head found: %x", head.Number, hash)
+		}
+	}
+	// Verify that the fork point of the side chain is found
+	for _, number := range []int{101, 102, 250, 500} {
+		hash, fork := ReadCanonicalAncestor(db, side[number].Hash(), uint64(number))
+		if fork != 100 || hash != canon[100].Hash() {
+			t.Errorf("side block %d: fork point mismatch: have %d/%x, want %d/%x", number, fork, hash, 100, canon[100].Hash())
+		}
+	}
+	if hash, number := ReadCanonicalAncestor(db, canon[200].Hash(), 200); number != 200 || hash != canon[200].Hash() {
+		t.Errorf("canonical block: fork point mismatch: have %d/%x, want %d/%x", number, hash, 200, canon[200].Hash())
+	}
+}
+
+// Tests that far ancestors of a side chain are reached in a logarithmic number
+// of hops along the skip index.
+func TestAncestorStepCount(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	genesis := &types.Header{Number: big.NewInt(0)}
+	WriteHeader(db, genesis)
+	chain := append([]*types.Header{genesis}, writeSkipChain(db, genesis, 4096, 0)...)
+
+	var (
+		head   = chain[len(chain)-1]
+		hash   = head.Hash()
+		number = head.Number.Uint64()
+		hops   int
+	)
+	for number > 1 {
+		hash, number = ReadAncestorStep(db, hash, number, 1)
+		hops++
+	}
+	if hash != chain[1].Hash() {
+		t.Fatalf("ancestor mismatch: have %x, want %x", hash, chain[1].Hash())
+	}
+	if hops > 64 {
+		t.Errorf("too many hops: have %d, want <= 64", hops)
+	}
+}
@@ -46,6 +46,13 @@ var chainFreezerNoSnappy = map[string]bool{
 	ChainFreezerDifficultyTable: true,
 }
 
+// chainFreezerPrunable are the ancient-tables dropped by history expiry. Headers,
+// hashes and difficulties are retained to keep the chain verifiable.
+var chainFreezerPrunable = map[string]bool{
+	ChainFreezerBodiesTable:  true,
+	ChainFreezerReceiptTable: true,
+}
+
 const (
 	// stateHistoryTableSize defines the maximum size of freezer data files.
 	stateHistoryTableSize = 2 * 1000 * 1000 * 1000
@@ -68,8 +75,9 @@ var stateHistoryFreezerNoSnappy = map[string]bool{
 
 // The list of identifiers of ancient stores.
 var (
-	chainFreezerName = "chain" // the folder name of chain segment ancient store.
-	stateFreezerName = "state" // the folder name of reverse diff ancient store.
+	chainFreezerName  = "chain"  // the folder name of chain segment ancient store.
+	stateFreezerName  = "state"  // the folder name of reverse diff ancient store.
+	remoteFreezerName = "remote" // the folder name of the remote ancient store cache.
 )
 
 // freezers the collections of all builtin freezers.
@@ -52,8 +52,8 @@ type chainFreezer struct {
 }
 
 // newChainFreezer initializes the freezer for ancient chain data.
-func newChainFreezer(datadir string, namespace string, readonly bool) (*chainFreezer, error) {
-	freezer, err := NewChainFreezer(datadir, namespace, readonly)
+func newChainFreezer(datadir string, namespace string, readonly bool, opts FreezerOptions) (*chainFreezer, error) {
+	freezer, err := NewChainFreezer(datadir, namespace, readonly, opts)
 	if err != nil {
 		return nil, err
 	}
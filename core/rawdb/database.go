@@ -66,6 +66,9 @@ func (frdb *freezerdb) Close() error {
 // a freeze cycle completes, without having to sleep for a minute to trigger the
 // automatic background run.
 func (frdb *freezerdb) Freeze(threshold uint64) error {
+	if _, ok := frdb.AncientStore.(*chainFreezer); !ok {
+		return errNotSupported
+	}
 	if frdb.AncientStore.(*chainFreezer).readonly {
 		return errReadOnly
 	}
@@ -198,12 +201,63 @@ func resolveChainFreezerDir(ancient string) string {
 // storage. The passed ancient indicates the path of root ancient directory
 // where the chain freezer can be opened.
 func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace string, readonly bool) (ethdb.Database, error) {
+	return newDatabaseWithFreezer(db, ancient, namespace, readonly, FreezerOptions{})
+}
+
+// newDatabaseWithFreezer creates a high level database on top of a given key-
+// value data store with a chain freezer storing its tables as configured by opts.
+func newDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace string, readonly bool, opts FreezerOptions) (ethdb.Database, error) {
 	// Create the idle freezer instance
-	frdb, err := newChainFreezer(resolveChainFreezerDir(ancient), namespace, readonly)
+	frdb, err := newChainFreezer(resolveChainFreezerDir(ancient), namespace, readonly, opts)
 	if err != nil {
 		printChainMetadata(db)
 		return nil, err
 	}
+	if err := validateFreezer(db, frdb); err != nil {
+		return nil, err
+	}
+	// Freezer is consistent with the key-value database, permit combining the two
+	if !frdb.readonly {
+		frdb.wg.Add(1)
+		go func() {
+			frdb.freeze(db)
+			frdb.wg.Done()
+		}()
+	}
+	return &freezerdb{
+		ancientRoot:   ancient,
+		KeyValueStore: db,
+		AncientStore:  frdb,
+	}, nil
+}
+
+// NewDatabaseWithRemoteFreezer creates a high level database on top of a given
+// key-value data store, serving the ancient chain data published to an object
+// store. Fetched ancient data is cached below the passed ancient directory, up
+// to cacheLimit bytes. The published data is read-only, so no chain segments are
+// moved out of the key-value store.
+func NewDatabaseWithRemoteFreezer(db ethdb.KeyValueStore, ancient string, store ethdb.ObjectStore, cacheLimit uint64) (ethdb.Database, error) {
+	var cacheDir string
+	if ancient != "" {
+		cacheDir = filepath.Join(ancient, remoteFreezerName)
+	}
+	frdb, err := newRemoteFreezer(store, cacheDir, cacheLimit)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateFreezer(db, frdb); err != nil {
+		return nil, err
+	}
+	return &freezerdb{
+		ancientRoot:   ancient,
+		KeyValueStore: db,
+		AncientStore:  frdb,
+	}, nil
+}
+
+// validateFreezer checks that the ancient store holds data of the same chain as
+// the key-value store, and that the two are contiguous.
+func validateFreezer(db ethdb.KeyValueStore, frdb ethdb.AncientReader) error {
 	// Since the freezer can be stored separately from the user's key-value database,
 	// there's a fairly high probability that the user requests invalid combinations
 	// of the freezer and database. Ensure that we don't shoot ourselves in the foot
@@ -234,10 +288,10 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace st
 			frgenesis, err := frdb.Ancient(ChainFreezerHashTable, 0)
 			if err != nil {
 				printChainMetadata(db)
-				return nil, fmt.Errorf("failed to retrieve genesis from ancient %v", err)
+				return fmt.Errorf("failed to retrieve genesis from ancient %v", err)
 			} else if !bytes.Equal(kvgenesis, frgenesis) {
 				printChainMetadata(db)
-				return nil, fmt.Errorf("genesis mismatch: %#x (leveldb) != %#x (ancients)", kvgenesis, frgenesis)
+				return fmt.Errorf("genesis mismatch: %#x (leveldb) != %#x (ancients)", kvgenesis, frgenesis)
 			}
 			// Key-value store and freezer belong to the same network. Ensure that they
 			// are contiguous, otherwise we might end up with a non-functional freezer.
@@ -255,7 +309,7 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace st
 					}
 					// We are about to exit on error. Print database metdata beore exiting
 					printChainMetadata(db)
-					return nil, fmt.Errorf("gap in the chain between ancients [0 - #%d] and leveldb [#%d - #%d] ",
+					return fmt.Errorf("gap in the chain between ancients [0 - #%d] and leveldb [#%d - #%d] ",
 						frozen-1, number, head)
 				}
 				// Database contains only older data than the freezer, this happens if the
@@ -274,7 +328,7 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace st
 				// didn't freeze anything yet.
 				if kvblob, _ := db.Get(headerHashKey(1)); len(kvblob) == 0 {
 					printChainMetadata(db)
-					return nil, errors.New("ancient chain segments already extracted, please set --datadir.ancient to the correct path")
+					return errors.New("ancient chain segments already extracted, please set --datadir.ancient to the correct path")
 				}
 				// Block #1 is still in the database, we're allowed to init a new freezer
 			}
@@ -282,19 +336,7 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace st
 			// freezer.
 		}
 	}
-	// Freezer is consistent with the key-value database, permit combining the two
-	if !frdb.readonly {
-		frdb.wg.Add(1)
-		go func() {
-			frdb.freeze(db)
-			frdb.wg.Done()
-		}()
-	}
-	return &freezerdb{
-		ancientRoot:   ancient,
-		KeyValueStore: db,
-		AncientStore:  frdb,
-	}, nil
+	return nil
 }
 
 // NewMemoryDatabase creates an ephemeral in-memory key-value database without a
@@ -313,7 +355,13 @@ func NewMemoryDatabaseWithCap(size int) ethdb.Database {
 // NewLevelDBDatabase creates a persistent key-value database without a freezer
 // moving immutable chain segments into cold storage.
 func NewLevelDBDatabase(file string, cache int, handles int, namespace string, readonly bool) (ethdb.Database, error) {
-	db, err := leveldb.New(file, cache, handles, namespace, readonly)
+	return newLevelDBDatabase(file, cache, handles, namespace, readonly, ethdb.WriteConfig{})
+}
+
+// newLevelDBDatabase creates a persistent key-value database without a freezer,
+// applying the given durability settings to the writes.
+func newLevelDBDatabase(file string, cache int, handles int, namespace string, readonly bool, writes ethdb.WriteConfig) (ethdb.Database, error) {
+	db, err := leveldb.New(file, cache, handles, namespace, readonly, writes)
 	if err != nil {
 		return nil, err
 	}
@@ -352,6 +400,12 @@ type OpenOptions struct {
 	Cache             int    // the capacity(in megabytes) of the data caching
 	Handles           int    // number of files to be open simultaneously
 	ReadOnly          bool
+
+	Writes  ethdb.WriteConfig // durability settings of the key-value store writes
+	Freezer FreezerOptions    // storage settings of the chain freezer tables
+
+	RemoteAncients ethdb.ObjectStore // object store serving published ancients instead of the chain freezer
+	RemoteCache    uint64            // size of the local cache of remote ancients in bytes
 }
 
 // openKeyValueDatabase opens a disk-based key-value database, e.g. leveldb or pebble.
@@ -365,6 +419,9 @@ func openKeyValueDatabase(o OpenOptions) (ethdb.Database, error) {
 	if len(o.Type) != 0 && o.Type != dbLeveldb && o.Type != dbPebble {
 		return nil, fmt.Errorf("unknown db.engine %v", o.Type)
 	}
+	if err := o.Writes.Validate(); err != nil {
+		return nil, err
+	}
 	// Retrieve any pre-existing database's type and use that or the requested one
 	// as long as there's no conflict between the two types
 	existingDb := hasPreexistingDb(o.Directory)
@@ -374,23 +431,23 @@ func openKeyValueDatabase(o OpenOptions) (ethdb.Database, error) {
 	if o.Type == dbPebble || existingDb == dbPebble {
 		if PebbleEnabled {
 			log.Info("Using pebble as the backing database")
-			return NewPebbleDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly)
+			return newPebbleDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly, o.Writes)
 		} else {
 			return nil, errors.New("db.engine 'pebble' not supported on this platform")
 		}
 	}
 	if o.Type == dbLeveldb || existingDb == dbLeveldb {
 		log.Info("Using leveldb as the backing database")
-		return NewLevelDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly)
+		return newLevelDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly, o.Writes)
 	}
 	// No pre-existing database, no user-requested one either. Default to Pebble
 	// on supported platforms and LevelDB on anything else.
 	if PebbleEnabled {
 		log.Info("Defaulting to pebble as the backing database")
-		return NewPebbleDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly)
+		return newPebbleDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly, o.Writes)
 	} else {
 		log.Info("Defaulting to leveldb as the backing database")
-		return NewLevelDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly)
+		return newLevelDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly, o.Writes)
 	}
 }
 
@@ -404,10 +461,18 @@ func Open(o OpenOptions) (ethdb.Database, error) {
 	if err != nil {
 		return nil, err
 	}
+	if o.RemoteAncients != nil {
+		frdb, err := NewDatabaseWithRemoteFreezer(kvdb, o.AncientsDirectory, o.RemoteAncients, o.RemoteCache)
+		if err != nil {
+			kvdb.Close()
+			return nil, err
+		}
+		return frdb, nil
+	}
 	if len(o.AncientsDirectory) == 0 {
 		return kvdb, nil
 	}
-	frdb, err := NewDatabaseWithFreezer(kvdb, o.AncientsDirectory, o.Namespace, o.ReadOnly)
+	frdb, err := newDatabaseWithFreezer(kvdb, o.AncientsDirectory, o.Namespace, o.ReadOnly, o.Freezer)
 	if err != nil {
 		kvdb.Close()
 		return nil, err
@@ -460,12 +525,16 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		headers         stat
 		bodies          stat
 		receipts        stat
+		accounting      stat
 		tds             stat
+		skips           stat
 		numHashPairings stat
 		hashNumPairings stat
 		tries           stat
 		codes           stat
 		txLookups       stat
+		addressTxs      stat
+		logIndex        stat
 		accountSnaps    stat
 		storageSnaps    stat
 		preimages       stat
@@ -498,8 +567,12 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			bodies.Add(size)
 		case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == (len(blockReceiptsPrefix)+8+common.HashLength):
 			receipts.Add(size)
+		case bytes.HasPrefix(key, receiptAccountingPrefix) && len(key) == (len(receiptAccountingPrefix)+8+common.HashLength):
+			accounting.Add(size)
 		case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerTDSuffix):
 			tds.Add(size)
+		case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerSkipSuffix) && len(key) == (len(headerPrefix)+8+common.HashLength+len(headerSkipSuffix)):
+			skips.Add(size)
 		case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerHashSuffix):
 			numHashPairings.Add(size)
 		case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == (len(headerNumberPrefix)+common.HashLength):
@@ -510,6 +583,12 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			codes.Add(size)
 		case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
 			txLookups.Add(size)
+		case bytes.HasPrefix(key, addressTxPrefix) && len(key) == (len(addressTxPrefix)+common.AddressLength+8+4):
+			addressTxs.Add(size)
+		case bytes.HasPrefix(key, filterMapRowPrefix) && len(key) == (len(filterMapRowPrefix)+8):
+			logIndex.Add(size)
+		case bytes.HasPrefix(key, filterMapBlockPrefix) && len(key) == (len(filterMapBlockPrefix)+8):
+			logIndex.Add(size)
 		case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == (len(SnapshotAccountPrefix)+common.HashLength):
 			accountSnaps.Add(size)
 		case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == (len(SnapshotStoragePrefix)+2*common.HashLength):
@@ -520,6 +599,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			metadata.Add(size)
 		case bytes.HasPrefix(key, genesisPrefix) && len(key) == (len(genesisPrefix)+common.HashLength):
 			metadata.Add(size)
+		case bytes.HasPrefix(key, badBlockReportPrefix) && len(key) == (len(badBlockReportPrefix)+common.HashLength):
+			metadata.Add(size)
 		case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == (len(bloomBitsPrefix)+10+common.HashLength):
 			bloomBits.Add(size)
 		case bytes.HasPrefix(key, BloomBitsIndexPrefix):
@@ -543,6 +624,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 				lastPivotKey, fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
 				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
 				uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
+				exporterCheckpointKey, forkOverrideKey, databaseMigrationKey, addressIndexTailKey,
+				filterMapsRangeKey, bloomBitsRetiredKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -565,10 +648,14 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Headers", headers.Size(), headers.Count()},
 		{"Key-Value store", "Bodies", bodies.Size(), bodies.Count()},
 		{"Key-Value store", "Receipt lists", receipts.Size(), receipts.Count()},
+		{"Key-Value store", "Receipt accounting", accounting.Size(), accounting.Count()},
 		{"Key-Value store", "Difficulties", tds.Size(), tds.Count()},
+		{"Key-Value store", "Header skip index", skips.Size(), skips.Count()},
 		{"Key-Value store", "Block number->hash", numHashPairings.Size(), numHashPairings.Count()},
 		{"Key-Value store", "Block hash->number", hashNumPairings.Size(), hashNumPairings.Count()},
 		{"Key-Value store", "Transaction index", txLookups.Size(), txLookups.Count()},
+		{"Key-Value store", "Address transaction index", addressTxs.Size(), addressTxs.Count()},
+		{"Key-Value store", "Log index", logIndex.Size(), logIndex.Count()},
 		{"Key-Value store", "Bloombit index", bloomBits.Size(), bloomBits.Count()},
 		{"Key-Value store", "Contract codes", codes.Size(), codes.Count()},
 		{"Key-Value store", "Trie nodes", tries.Size(), tries.Count()},
@@ -640,6 +727,7 @@ func ReadChainMetadata(db ethdb.KeyValueStore) [][]string {
 		{"snapshotRecoveryNumber", pp(ReadSnapshotRecoveryNumber(db))},
 		{"snapshotRoot", fmt.Sprintf("%v", ReadSnapshotRoot(db))},
 		{"txIndexTail", pp(ReadTxIndexTail(db))},
+		{"addressIndexTail", pp(ReadAddressIndexTail(db))},
 		{"fastTxLookupLimit", pp(ReadFastTxLookupLimit(db))},
 	}
 	if b := ReadSkeletonSyncStatus(db); b != nil {
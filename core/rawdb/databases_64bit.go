@@ -29,7 +29,13 @@ const PebbleEnabled = true
 // NewPebbleDBDatabase creates a persistent key-value database without a freezer
 // moving immutable chain segments into cold storage.
 func NewPebbleDBDatabase(file string, cache int, handles int, namespace string, readonly bool) (ethdb.Database, error) {
-	db, err := pebble.New(file, cache, handles, namespace, readonly)
+	return newPebbleDBDatabase(file, cache, handles, namespace, readonly, ethdb.WriteConfig{})
+}
+
+// newPebbleDBDatabase creates a persistent key-value database without a freezer,
+// applying the given durability settings to the writes.
+func newPebbleDBDatabase(file string, cache int, handles int, namespace string, readonly bool, writes ethdb.WriteConfig) (ethdb.Database, error) {
+	db, err := pebble.New(file, cache, handles, namespace, readonly, writes)
 	if err != nil {
 		return nil, err
 	}
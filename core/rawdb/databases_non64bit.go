@@ -32,3 +32,9 @@ const PebbleEnabled = false
 func NewPebbleDBDatabase(file string, cache int, handles int, namespace string, readonly bool) (ethdb.Database, error) {
 	return nil, errors.New("pebble is not supported on this platform")
 }
+
+// newPebbleDBDatabase creates a persistent key-value database without a freezer,
+// applying the given durability settings to the writes.
+func newPebbleDBDatabase(file string, cache int, handles int, namespace string, readonly bool, writes ethdb.WriteConfig) (ethdb.Database, error) {
+	return nil, errors.New("pebble is not supported on this platform")
+}
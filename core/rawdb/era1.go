@@ -0,0 +1,490 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/golang/snappy"
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/rlp"
+)
+
+// Era1 files archive the history of the chain in the e2store format, each record
+// consisting of an 8 byte header (type, length, reserved) and its data. A file
+// starts with a version record, followed by a tuple of records for every block
+// (header, body, receipts, total difficulty), the accumulator of the blocks and
+// an index of the block tuples.
+const (
+	era1TypeVersion     uint16 = 0x3265
+	era1TypeHeader      uint16 = 0x03
+	era1TypeBody        uint16 = 0x04
+	era1TypeReceipts    uint16 = 0x05
+	era1TypeTD          uint16 = 0x06
+	era1TypeAccumulator uint16 = 0x07
+	era1TypeBlockIndex  uint16 = 0x3266
+
+	e2storeHeaderSize = 8
+)
+
+// Era1MaxBlocks is the number of blocks in an era1 epoch, the most blocks a single
+// era1 file can hold.
+const Era1MaxBlocks = 8192
+
+var (
+	errEra1Full     = errors.New("era1 file full")
+	errEra1Empty    = errors.New("era1 file empty")
+	errEra1Finished = errors.New("era1 file already finalized")
+)
+
+// Era1Filename returns the canonical name of the era1 file of a network's epoch
+// with the given accumulator root.
+func Era1Filename(network string, epoch uint64, root common.Hash) string {
+	return fmt.Sprintf("%s-%05d-%x.era1", network, epoch, root[:4])
+}
+
+// ComputeEra1Accumulator computes the accumulator root of an era1 file, being the
+// SSZ hash tree root of the list of header records (block hash, total difficulty)
+// of its blocks.
+func ComputeEra1Accumulator(hashes []common.Hash, tds []*big.Int) (common.Hash, error) {
+	if len(hashes) != len(tds) {
+		return common.Hash{}, fmt.Errorf("mismatching accumulator input: %d hashes, %d tds", len(hashes), len(tds))
+	}
+	if len(hashes) > Era1MaxBlocks {
+		return common.Hash{}, fmt.Errorf("too many accumulator records: have %d, max %d", len(hashes), Era1MaxBlocks)
+	}
+	layer := make([][32]byte, len(hashes))
+	for i := range hashes {
+		td, err := era1EncodeTd(tds[i])
+		if err != nil {
+			return common.Hash{}, err
+		}
+		layer[i] = sha256.Sum256(append(hashes[i].Bytes(), td...))
+	}
+	// Merkleize the records up to the depth of the maximum list length, padding
+	// with the roots of empty subtrees
+	var zero [32]byte
+	for width := 1; width < Era1MaxBlocks; width <<= 1 {
+		next := make([][32]byte, (len(layer)+1)/2)
+		for i := range next {
+			right := zero
+			if 2*i+1 < len(layer) {
+				right = layer[2*i+1]
+			}
+			next[i] = sha256.Sum256(append(layer[2*i][:], right[:]...))
+		}
+		layer, zero = next, sha256.Sum256(append(zero[:], zero[:]...))
+	}
+	root := zero
+	if len(layer) > 0 {
+		root = layer[0]
+	}
+	// Mix in the length of the list
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(hashes)))
+	return sha256.Sum256(append(root[:], length[:]...)), nil
+}
+
+// era1EncodeTd encodes a total difficulty as a 32 byte little endian integer.
+func era1EncodeTd(td *big.Int) ([]byte, error) {
+	if td.Sign() < 0 || td.BitLen() > 256 {
+		return nil, fmt.Errorf("total difficulty %v out of range", td)
+	}
+	enc := td.FillBytes(make([]byte, 32))
+	for i, j := 0, len(enc)-1; i < j; i, j = i+1, j-1 {
+		enc[i], enc[j] = enc[j], enc[i]
+	}
+	return enc, nil
+}
+
+// era1DecodeTd decodes a 32 byte little endian total difficulty.
+func era1DecodeTd(enc []byte) (*big.Int, error) {
+	if len(enc) != 32 {
+		return nil, fmt.Errorf("invalid total difficulty length %d", len(enc))
+	}
+	be := make([]byte, 32)
+	for i := range enc {
+		be[31-i] = enc[i]
+	}
+	return new(big.Int).SetBytes(be), nil
+}
+
+// Era1Builder writes blocks into an era1 file.
+type Era1Builder struct {
+	w       io.Writer
+	written uint64 // Number of bytes written so far
+
+	start   uint64        // Number of the first block
+	indexes []uint64      // Offsets of the block tuples
+	hashes  []common.Hash // Block hashes for the accumulator
+	tds     []*big.Int    // Total difficulties for the accumulator
+	done    bool          // Whether the file was finalized
+}
+
+// NewEra1Builder creates a builder writing an era1 file into w.
+func NewEra1Builder(w io.Writer) *Era1Builder {
+	return &Era1Builder{w: w}
+}
+
+// Add appends a block along with its receipts and total difficulty to the file.
+// Blocks must be added in ascending order without gaps.
+func (b *Era1Builder) Add(block *types.Block, receipts types.Receipts, td *big.Int) error {
+	if b.done {
+		return errEra1Finished
+	}
+	if len(b.indexes) >= Era1MaxBlocks {
+		return errEra1Full
+	}
+	if len(b.indexes) == 0 {
+		if err := b.write(era1TypeVersion, nil); err != nil {
+			return err
+		}
+		b.start = block.NumberU64()
+	} else if want := b.start + uint64(len(b.indexes)); block.NumberU64() != want {
+		return fmt.Errorf("non contiguous era1 block: have #%d, want #%d", block.NumberU64(), want)
+	}
+	tdEnc, err := era1EncodeTd(td)
+	if err != nil {
+		return err
+	}
+	b.indexes = append(b.indexes, b.written)
+	b.hashes = append(b.hashes, block.Hash())
+	b.tds = append(b.tds, new(big.Int).Set(td))
+
+	if err := b.writeCompressed(era1TypeHeader, block.Header()); err != nil {
+		return err
+	}
+	if err := b.writeCompressed(era1TypeBody, block.Body()); err != nil {
+		return err
+	}
+	if err := b.writeCompressed(era1TypeReceipts, receipts); err != nil {
+		return err
+	}
+	return b.write(era1TypeTD, tdEnc)
+}
+
+// Finalize writes the accumulator and the block index, completing the file. The
+// accumulator root is returned.
+func (b *Era1Builder) Finalize() (common.Hash, error) {
+	if b.done {
+		return common.Hash{}, errEra1Finished
+	}
+	if len(b.indexes) == 0 {
+		return common.Hash{}, errEra1Empty
+	}
+	root, err := ComputeEra1Accumulator(b.hashes, b.tds)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := b.write(era1TypeAccumulator, root[:]); err != nil {
+		return common.Hash{}, err
+	}
+	// The index holds the number of the first block, the offsets of the block
+	// tuples relative to the index record and the number of blocks
+	var (
+		base  = b.written
+		count = len(b.indexes)
+		index = make([]byte, 16+8*count)
+	)
+	binary.LittleEndian.PutUint64(index, b.start)
+	for i, offset := range b.indexes {
+		binary.LittleEndian.PutUint64(index[8+8*i:], uint64(int64(offset)-int64(base)))
+	}
+	binary.LittleEndian.PutUint64(index[8+8*count:], uint64(count))
+	if err := b.write(era1TypeBlockIndex, index); err != nil {
+		return common.Hash{}, err
+	}
+	b.done = true
+	return root, nil
+}
+
+// writeCompressed writes the snappy framed RLP encoding of val as a record.
+func (b *Era1Builder) writeCompressed(typ uint16, val interface{}) error {
+	var (
+		buf = new(bytes.Buffer)
+		w   = snappy.NewBufferedWriter(buf)
+	)
+	if err := rlp.Encode(w, val); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return b.write(typ, buf.Bytes())
+}
+
+// write writes an e2store record.
+func (b *Era1Builder) write(typ uint16, data []byte) error {
+	header := make([]byte, e2storeHeaderSize)
+	binary.LittleEndian.PutUint16(header, typ)
+	binary.LittleEndian.PutUint32(header[2:], uint32(len(data)))
+
+	n, err := b.w.Write(append(header, data...))
+	b.written += uint64(n)
+	return err
+}
+
+// Era1 reads the blocks archived in an era1 file.
+type Era1 struct {
+	r      io.ReaderAt
+	closer io.Closer
+	size   int64
+
+	start uint64 // Number of the first block
+	count uint64 // Number of blocks in the file
+	index int64  // Offset of the block index record
+}
+
+// OpenEra1 opens the era1 file at the given path.
+func OpenEra1(path string) (*Era1, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	e, err := NewEra1(f, stat.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	e.closer = f
+	return e, nil
+}
+
+// NewEra1 creates a reader of the era1 file of the given size held by r.
+func NewEra1(r io.ReaderAt, size int64) (*Era1, error) {
+	e := &Era1{r: r, size: size}
+	typ, data, err := e.record(0)
+	if err != nil {
+		return nil, err
+	}
+	if typ != era1TypeVersion || len(data) != 0 {
+		return nil, fmt.Errorf("invalid era1 version record: type %#x, length %d", typ, len(data))
+	}
+	// Locate the block index by the block count at the end of the file
+	if size < 3*e2storeHeaderSize+16 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	var buf [8]byte
+	if _, err := r.ReadAt(buf[:], size-8); err != nil {
+		return nil, err
+	}
+	e.count = binary.LittleEndian.Uint64(buf[:])
+	if e.count == 0 || e.count > Era1MaxBlocks {
+		return nil, fmt.Errorf("invalid era1 block count %d", e.count)
+	}
+	e.index = size - e2storeHeaderSize - 16 - 8*int64(e.count)
+	typ, data, err = e.record(e.index)
+	if err != nil {
+		return nil, err
+	}
+	if typ != era1TypeBlockIndex || len(data) != 16+8*int(e.count) {
+		return nil, fmt.Errorf("invalid era1 block index record: type %#x, length %d", typ, len(data))
+	}
+	e.start = binary.LittleEndian.Uint64(data)
+	return e, nil
+}
+
+// Close closes the underlying file if opened by OpenEra1.
+func (e *Era1) Close() error {
+	if e.closer == nil {
+		return nil
+	}
+	return e.closer.Close()
+}
+
+// Start returns the number of the first block in the file.
+func (e *Era1) Start() uint64 {
+	return e.start
+}
+
+// Count returns the number of blocks in the file.
+func (e *Era1) Count() uint64 {
+	return e.count
+}
+
+// Accumulator returns the accumulator root stored in the file.
+func (e *Era1) Accumulator() (common.Hash, error) {
+	typ, data, err := e.record(e.index - e2storeHeaderSize - common.HashLength)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if typ != era1TypeAccumulator || len(data) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid era1 accumulator record: type %#x, length %d", typ, len(data))
+	}
+	return common.BytesToHash(data), nil
+}
+
+// Verify recomputes the accumulator from the headers and total difficulties in
+// the file, checking it against the stored one.
+func (e *Era1) Verify() error {
+	var (
+		hashes = make([]common.Hash, 0, e.count)
+		tds    = make([]*big.Int, 0, e.count)
+	)
+	for number := e.start; number < e.start+e.count; number++ {
+		header, err := e.ReadHeader(number)
+		if err != nil {
+			return err
+		}
+		td, err := e.ReadTd(number)
+		if err != nil {
+			return err
+		}
+		hashes, tds = append(hashes, header.Hash()), append(tds, td)
+	}
+	have, err := ComputeEra1Accumulator(hashes, tds)
+	if err != nil {
+		return err
+	}
+	want, err := e.Accumulator()
+	if err != nil {
+		return err
+	}
+	if have != want {
+		return fmt.Errorf("era1 accumulator mismatch: have %x, want %x", have, want)
+	}
+	return nil
+}
+
+// ReadHeader reads the header of the given block.
+func (e *Era1) ReadHeader(number uint64) (*types.Header, error) {
+	header := new(types.Header)
+	if err := e.readCompressed(number, era1TypeHeader, header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// ReadBlock reads the given block.
+func (e *Era1) ReadBlock(number uint64) (*types.Block, error) {
+	header, err := e.ReadHeader(number)
+	if err != nil {
+		return nil, err
+	}
+	body := new(types.Body)
+	if err := e.readCompressed(number, era1TypeBody, body); err != nil {
+		return nil, err
+	}
+	return types.NewBlockWithHeader(header).WithBody(body.Transactions, body.Uncles).WithWithdrawals(body.Withdrawals), nil
+}
+
+// ReadReceipts reads the receipts of the given block.
+func (e *Era1) ReadReceipts(number uint64) (types.Receipts, error) {
+	var receipts types.Receipts
+	if err := e.readCompressed(number, era1TypeReceipts, &receipts); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// ReadTd reads the total difficulty of the given block.
+func (e *Era1) ReadTd(number uint64) (*big.Int, error) {
+	data, err := e.blockRecord(number, era1TypeTD)
+	if err != nil {
+		return nil, err
+	}
+	return era1DecodeTd(data)
+}
+
+// readCompressed decodes the snappy framed RLP record of a block into val.
+func (e *Era1) readCompressed(number uint64, typ uint16, val interface{}) error {
+	data, err := e.blockRecord(number, typ)
+	if err != nil {
+		return err
+	}
+	return rlp.Decode(snappy.NewReader(bytes.NewReader(data)), val)
+}
+
+// blockRecord returns the data of the record of the given type in the tuple of
+// a block.
+func (e *Era1) blockRecord(number uint64, typ uint16) ([]byte, error) {
+	if number < e.start || number >= e.start+e.count {
+		return nil, fmt.Errorf("block #%d not in era1 file [%d, %d]", number, e.start, e.start+e.count-1)
+	}
+	var buf [8]byte
+	if _, err := e.r.ReadAt(buf[:], e.index+e2storeHeaderSize+8+8*int64(number-e.start)); err != nil {
+		return nil, err
+	}
+	offset := e.index + int64(binary.LittleEndian.Uint64(buf[:]))
+
+	// Skip the records preceding the requested one in the tuple
+	for want := era1TypeHeader; ; want++ {
+		if want == typ {
+			have, data, err := e.record(offset)
+			if err != nil {
+				return nil, err
+			}
+			if have != typ {
+				return nil, fmt.Errorf("invalid era1 record of block #%d: have type %#x, want %#x", number, have, typ)
+			}
+			return data, nil
+		}
+		have, length, err := e.recordHeader(offset)
+		if err != nil {
+			return nil, err
+		}
+		if have != want {
+			return nil, fmt.Errorf("invalid era1 record of block #%d: have type %#x, want %#x", number, have, want)
+		}
+		offset += e2storeHeaderSize + int64(length)
+	}
+}
+
+// recordHeader reads the type and data length of the e2store record at offset.
+func (e *Era1) recordHeader(offset int64) (uint16, uint32, error) {
+	if offset < 0 || offset+e2storeHeaderSize > e.size {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	header := make([]byte, e2storeHeaderSize)
+	if _, err := e.r.ReadAt(header, offset); err != nil {
+		return 0, 0, err
+	}
+	if reserved := binary.LittleEndian.Uint16(header[6:]); reserved != 0 {
+		return 0, 0, fmt.Errorf("invalid e2store record at %d: reserved bytes %#x", offset, reserved)
+	}
+	length := binary.LittleEndian.Uint32(header[2:])
+	if offset+e2storeHeaderSize+int64(length) > e.size {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	return binary.LittleEndian.Uint16(header), length, nil
+}
+
+// record reads the type and data of the e2store record at offset.
+func (e *Era1) record(offset int64) (uint16, []byte, error) {
+	typ, length, err := e.recordHeader(offset)
+	if err != nil {
+		return 0, nil, err
+	}
+	data := make([]byte, length)
+	if _, err := e.r.ReadAt(data, offset+e2storeHeaderSize); err != nil {
+		return 0, nil, err
+	}
+	return typ, data, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/rlp"
+)
+
+// makeEra1Blocks creates a contiguous run of blocks with a transaction and a
+// receipt each, along with their total difficulties.
+func makeEra1Blocks(first uint64, n int) ([]*types.Block, []types.Receipts, []*big.Int) {
+	var (
+		blocks   []*types.Block
+		receipts []types.Receipts
+		tds      []*big.Int
+		td       = new(big.Int)
+		parent   common.Hash
+	)
+	for i := 0; i < n; i++ {
+		number := first + uint64(i)
+		tx := types.NewTransaction(number, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), []byte{byte(i)})
+		receipt := &types.Receipt{
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: 21000,
+			Logs:              []*types.Log{{Address: common.Address{0x02}, Data: []byte{byte(i)}}},
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     new(big.Int).SetUint64(number),
+			Difficulty: big.NewInt(131072),
+			GasLimit:   8000000,
+			GasUsed:    21000,
+		}
+		block := types.NewBlock(header, []*types.Transaction{tx}, nil, []*types.Receipt{receipt}, newTestHasher())
+		parent = block.Hash()
+		td = new(big.Int).Add(td, header.Difficulty)
+
+		blocks = append(blocks, block)
+		receipts = append(receipts, types.Receipts{receipt})
+		tds = append(tds, td)
+	}
+	return blocks, receipts, tds
+}
+
+func TestEra1RoundTrip(t *testing.T) {
+	blocks, receipts, tds := makeEra1Blocks(100, 16)
+
+	buf := new(bytes.Buffer)
+	builder := NewEra1Builder(buf)
+	for i, block := range blocks {
+		if err := builder.Add(block, receipts[i], tds[i]); err != nil {
+			t.Fatalf("failed to add block #%d: %v", block.NumberU64(), err)
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		t.Fatalf("failed to finalize: %v", err)
+	}
+	hashes := make([]common.Hash, len(blocks))
+	for i, block := range blocks {
+		hashes[i] = block.Hash()
+	}
+	if want, _ := ComputeEra1Accumulator(hashes, tds); root != want {
+		t.Fatalf("accumulator mismatch: have %x, want %x", root, want)
+	}
+	era, err := NewEra1(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to open era1: %v", err)
+	}
+	if era.Start() != 100 || era.Count() != 16 {
+		t.Fatalf("range mismatch: have [%d, +%d], want [100, +16]", era.Start(), era.Count())
+	}
+	if stored, err := era.Accumulator(); err != nil || stored != root {
+		t.Fatalf("stored accumulator mismatch: have %x (%v), want %x", stored, err, root)
+	}
+	if err := era.Verify(); err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	// Read back the blocks in random order
+	for _, i := range []int{15, 0, 7, 8, 1} {
+		number := blocks[i].NumberU64()
+		block, err := era.ReadBlock(number)
+		if err != nil {
+			t.Fatalf("failed to read block #%d: %v", number, err)
+		}
+		if block.Hash() != blocks[i].Hash() || block.Transactions()[0].Hash() != blocks[i].Transactions()[0].Hash() {
+			t.Errorf("block #%d mismatch", number)
+		}
+		have, err := era.ReadReceipts(number)
+		if err != nil {
+			t.Fatalf("failed to read receipts #%d: %v", number, err)
+		}
+		haveEnc, _ := rlp.EncodeToBytes(have)
+		wantEnc, _ := rlp.EncodeToBytes(receipts[i])
+		if !bytes.Equal(haveEnc, wantEnc) {
+			t.Errorf("receipts #%d mismatch", number)
+		}
+		if td, err := era.ReadTd(number); err != nil || td.Cmp(tds[i]) != 0 {
+			t.Errorf("td #%d mismatch: have %v (%v), want %v", number, td, err, tds[i])
+		}
+	}
+	if _, err := era.ReadBlock(99); err == nil {
+		t.Error("read block before the file")
+	}
+	if _, err := era.ReadBlock(116); err == nil {
+		t.Error("read block after the file")
+	}
+}
+
+func TestEra1Builder(t *testing.T) {
+	blocks, receipts, tds := makeEra1Blocks(0, 3)
+
+	builder := NewEra1Builder(new(bytes.Buffer))
+	if _, err := builder.Finalize(); err != errEra1Empty {
+		t.Fatalf("wrong error finalizing empty file: have %v, want %v", err, errEra1Empty)
+	}
+	if err := builder.Add(blocks[0], receipts[0], tds[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.Add(blocks[2], receipts[2], tds[2]); err == nil {
+		t.Fatal("added non contiguous block")
+	}
+	if _, err := builder.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.Add(blocks[1], receipts[1], tds[1]); err != errEra1Finished {
+		t.Fatalf("wrong error adding to finalized file: have %v, want %v", err, errEra1Finished)
+	}
+}
+
+func TestEra1Corruption(t *testing.T) {
+	blocks, receipts, tds := makeEra1Blocks(0, 4)
+
+	buf := new(bytes.Buffer)
+	builder := NewEra1Builder(buf)
+	for i, block := range blocks {
+		builder.Add(block, receipts[i], tds[i])
+	}
+	builder.Finalize()
+	valid := buf.Bytes()
+
+	// Tamper with the total difficulty of the last block, which directly precedes
+	// the accumulator record
+	blob := common.CopyBytes(valid)
+	offset := len(blob) - (8 + 16 + 8*len(blocks)) - (8 + 32) - 32
+	blob[offset]++
+
+	era, err := NewEra1(bytes.NewReader(blob), int64(len(blob)))
+	if err != nil {
+		t.Fatalf("failed to open era1: %v", err)
+	}
+	if err := era.Verify(); err == nil {
+		t.Error("verified tampered era1")
+	}
+	// Truncated files must be rejected
+	for _, size := range []int{0, 8, len(valid) / 2, len(valid) - 1} {
+		if _, err := NewEra1(bytes.NewReader(valid[:size]), int64(size)); err == nil {
+			t.Errorf("opened era1 truncated to %d bytes", size)
+		}
+	}
+}
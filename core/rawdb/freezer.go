@@ -63,7 +63,7 @@ const freezerTableSize = 2 * 1000 * 1000 * 1000
 //     of Geth, and thus also GC overhead.
 type Freezer struct {
 	frozen atomic.Uint64 // Number of blocks already frozen
-	tail   atomic.Uint64 // Number of the first stored item in the freezer
+	tail   atomic.Uint64 // Number of the first stored item in the prunable tables
 
 	// This lock synchronizes writers and the truncate operation, as well as
 	// the "atomic" (batched) read operations.
@@ -72,14 +72,16 @@ type Freezer struct {
 
 	readonly     bool
 	tables       map[string]*freezerTable // Data tables for storing everything
+	prunable     map[string]bool          // Tables whose tail can be truncated, nil for all
 	instanceLock *flock.Flock             // File-system lock to prevent double opens
 	closeOnce    sync.Once
 }
 
 // NewChainFreezer is a small utility method around NewFreezer that sets the
 // default parameters for the chain storage.
-func NewChainFreezer(datadir string, namespace string, readonly bool) (*Freezer, error) {
-	return NewFreezer(datadir, namespace, readonly, freezerTableSize, chainFreezerNoSnappy)
+func NewChainFreezer(datadir string, namespace string, readonly bool, opts FreezerOptions) (*Freezer, error) {
+	opts.prunable = chainFreezerPrunable
+	return NewFreezerWithOptions(datadir, namespace, readonly, freezerTableSize, chainFreezerNoSnappy, opts)
 }
 
 // NewFreezer creates a freezer instance for maintaining immutable ordered
@@ -88,6 +90,12 @@ func NewChainFreezer(datadir string, namespace string, readonly bool) (*Freezer,
 // The 'tables' argument defines the data tables. If the value of a map
 // entry is true, snappy compression is disabled for the table.
 func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize uint32, tables map[string]bool) (*Freezer, error) {
+	return NewFreezerWithOptions(datadir, namespace, readonly, maxTableSize, tables, FreezerOptions{})
+}
+
+// NewFreezerWithOptions creates a freezer instance like NewFreezer, storing the
+// data of the tables as configured by opts.
+func NewFreezerWithOptions(datadir string, namespace string, readonly bool, maxTableSize uint32, tables map[string]bool, opts FreezerOptions) (*Freezer, error) {
 	// Create the initial freezer object
 	var (
 		readMeter  = metrics.NewRegisteredMeter(namespace+"ancient/read", nil)
@@ -113,16 +121,24 @@ func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 	} else if !locked {
 		return nil, errors.New("locking failed")
 	}
+	// Load the locations of the data files stored outside the freezer directory
+	volumes, err := newFreezerVolumes(datadir, opts.Volumes, maxTableSize, readonly)
+	if err != nil {
+		lock.Unlock()
+		return nil, err
+	}
 	// Open all the supported data tables
 	freezer := &Freezer{
 		readonly:     readonly,
 		tables:       make(map[string]*freezerTable),
+		prunable:     opts.prunable,
 		instanceLock: lock,
 	}
 
 	// Create the tables.
 	for name, disableSnappy := range tables {
-		table, err := newTable(datadir, name, readMeter, writeMeter, sizeGauge, maxTableSize, disableSnappy, readonly)
+		config := freezerTableConfig{noCompression: disableSnappy, zstd: opts.Zstd, volumes: volumes}
+		table, err := openTable(datadir, name, readMeter, writeMeter, sizeGauge, maxTableSize, readonly, config)
 		if err != nil {
 			for _, table := range freezer.tables {
 				table.Close()
@@ -132,7 +148,6 @@ func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 		}
 		freezer.tables[name] = table
 	}
-	var err error
 	if freezer.readonly {
 		// In readonly mode only validate, don't truncate.
 		// validate also sets `freezer.frozen`.
@@ -213,7 +228,8 @@ func (f *Freezer) Ancients() (uint64, error) {
 	return f.frozen.Load(), nil
 }
 
-// Tail returns the number of first stored item in the freezer.
+// Tail returns the number of first stored item in the freezer. If only some of
+// the tables are prunable, it is the first item stored in those.
 func (f *Freezer) Tail() (uint64, error) {
 	return f.tail.Load(), nil
 }
@@ -297,6 +313,7 @@ func (f *Freezer) TruncateHead(items uint64) (uint64, error) {
 }
 
 // TruncateTail discards any recent data below the provided threshold number.
+// Only the prunable tables are truncated.
 func (f *Freezer) TruncateTail(tail uint64) (uint64, error) {
 	if f.readonly {
 		return 0, errReadOnly
@@ -308,7 +325,10 @@ func (f *Freezer) TruncateTail(tail uint64) (uint64, error) {
 	if old >= tail {
 		return old, nil
 	}
-	for _, table := range f.tables {
+	for kind, table := range f.tables {
+		if !f.isPrunable(kind) {
+			continue
+		}
 		if err := table.truncateTail(tail); err != nil {
 			return 0, err
 		}
@@ -317,6 +337,11 @@ func (f *Freezer) TruncateTail(tail uint64) (uint64, error) {
 	return old, nil
 }
 
+// isPrunable returns whether the tail of the given table can be truncated.
+func (f *Freezer) isPrunable(kind string) bool {
+	return f.prunable == nil || f.prunable[kind]
+}
+
 // Sync flushes all data tables to disk.
 func (f *Freezer) Sync() error {
 	var errs []error
@@ -331,21 +356,22 @@ func (f *Freezer) Sync() error {
 	return nil
 }
 
-// validate checks that every table has the same boundary.
+// validate checks that every table has the same boundary. The tails are only
+// compared among the prunable and among the retained tables.
 // Used instead of `repair` in readonly mode.
 func (f *Freezer) validate() error {
 	if len(f.tables) == 0 {
 		return nil
 	}
 	var (
-		head uint64
-		tail uint64
-		name string
+		head  uint64
+		name  string
+		tails = make(map[bool]uint64)
+		names = make(map[bool]string)
 	)
 	// Hack to get boundary of any table
 	for kind, table := range f.tables {
 		head = table.items.Load()
-		tail = table.itemHidden.Load()
 		name = kind
 		break
 	}
@@ -354,41 +380,45 @@ func (f *Freezer) validate() error {
 		if head != table.items.Load() {
 			return fmt.Errorf("freezer tables %s and %s have differing head: %d != %d", kind, name, table.items.Load(), head)
 		}
-		if tail != table.itemHidden.Load() {
-			return fmt.Errorf("freezer tables %s and %s have differing tail: %d != %d", kind, name, table.itemHidden.Load(), tail)
+		prunable, hidden := f.isPrunable(kind), table.itemHidden.Load()
+		if tail, ok := tails[prunable]; !ok {
+			tails[prunable], names[prunable] = hidden, kind
+		} else if tail != hidden {
+			return fmt.Errorf("freezer tables %s and %s have differing tail: %d != %d", kind, names[prunable], hidden, tail)
 		}
 	}
 	f.frozen.Store(head)
-	f.tail.Store(tail)
+	f.tail.Store(tails[true])
 	return nil
 }
 
-// repair truncates all data tables to the same length.
+// repair truncates all data tables to the same length. The tails are aligned
+// separately among the prunable and among the retained tables.
 func (f *Freezer) repair() error {
 	var (
-		head = uint64(math.MaxUint64)
-		tail = uint64(0)
+		head  = uint64(math.MaxUint64)
+		tails = make(map[bool]uint64)
 	)
-	for _, table := range f.tables {
+	for kind, table := range f.tables {
 		items := table.items.Load()
 		if head > items {
 			head = items
 		}
-		hidden := table.itemHidden.Load()
-		if hidden > tail {
-			tail = hidden
+		prunable, hidden := f.isPrunable(kind), table.itemHidden.Load()
+		if hidden > tails[prunable] {
+			tails[prunable] = hidden
 		}
 	}
-	for _, table := range f.tables {
+	for kind, table := range f.tables {
 		if err := table.truncateHead(head); err != nil {
 			return err
 		}
-		if err := table.truncateTail(tail); err != nil {
+		if err := table.truncateTail(tails[f.isPrunable(kind)]); err != nil {
 			return err
 		}
 	}
 	f.frozen.Store(head)
-	f.tail.Store(tail)
+	f.tail.Store(tails[true])
 	return nil
 }
 
@@ -444,7 +474,8 @@ func (f *Freezer) MigrateTable(kind string, convert convertLegacyFn) error {
 	// Set up new dir for the migrated table, the content of which
 	// we'll at the end move over to the ancients dir.
 	migrationPath := filepath.Join(ancientsPath, "migration")
-	newTable, err := newFreezerTable(migrationPath, kind, table.noCompression, false)
+	config := freezerTableConfig{noCompression: table.noCompression, zstd: table.zstd}
+	newTable, err := openTable(migrationPath, kind, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, freezerTableSize, false, config)
 	if err != nil {
 		return err
 	}
@@ -84,7 +84,7 @@ func (batch *freezerBatch) commit() (item uint64, writeSize int64, err error) {
 type freezerTableBatch struct {
 	t *freezerTable
 
-	sb          *snappyBuffer
+	compressor  itemCompressor
 	encBuffer   writeBuffer
 	dataBuffer  []byte
 	indexBuffer []byte
@@ -95,8 +95,11 @@ type freezerTableBatch struct {
 // newBatch creates a new batch for the freezer table.
 func (t *freezerTable) newBatch() *freezerTableBatch {
 	batch := &freezerTableBatch{t: t}
-	if !t.noCompression {
-		batch.sb = new(snappyBuffer)
+	switch {
+	case t.zstd:
+		batch.compressor = new(zstdBuffer)
+	case !t.noCompression:
+		batch.compressor = new(snappyBuffer)
 	}
 	batch.reset()
 	return batch
@@ -124,8 +127,8 @@ func (batch *freezerTableBatch) Append(item uint64, data interface{}) error {
 		return err
 	}
 	encItem := batch.encBuffer.data
-	if batch.sb != nil {
-		encItem = batch.sb.compress(encItem)
+	if batch.compressor != nil {
+		encItem = batch.compressor.compress(encItem)
 	}
 	return batch.appendItem(encItem)
 }
@@ -139,8 +142,8 @@ func (batch *freezerTableBatch) AppendRaw(item uint64, blob []byte) error {
 	}
 
 	encItem := blob
-	if batch.sb != nil {
-		encItem = batch.sb.compress(blob)
+	if batch.compressor != nil {
+		encItem = batch.compressor.compress(blob)
 	}
 	return batch.appendItem(encItem)
 }
@@ -0,0 +1,80 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// itemCompressor compresses the items appended to a freezer table, reusing its
+// output buffer across items.
+type itemCompressor interface {
+	compress(data []byte) []byte
+}
+
+// zstdBuffer writes zstd frames holding a single item, and can be reused.
+type zstdBuffer struct {
+	dst []byte
+}
+
+// compress zstd-compresses the data.
+func (z *zstdBuffer) compress(data []byte) []byte {
+	z.dst = zstdEncoder().EncodeAll(data, z.dst[:0])
+	return z.dst
+}
+
+var (
+	zstdOnce sync.Once
+	zstdEnc  *zstd.Encoder
+	zstdDec  *zstd.Decoder
+)
+
+// initZstd creates the zstd encoder and decoder shared by all freezer tables,
+// both being safe for concurrent use.
+func initZstd() {
+	var err error
+	if zstdEnc, err = zstd.NewWriter(nil); err != nil {
+		panic(err)
+	}
+	if zstdDec, err = zstd.NewReader(nil); err != nil {
+		panic(err)
+	}
+}
+
+// zstdEncoder returns the shared zstd encoder.
+func zstdEncoder() *zstd.Encoder {
+	zstdOnce.Do(initZstd)
+	return zstdEnc
+}
+
+// zstdDecode decompresses a zstd compressed item.
+func zstdDecode(item []byte) ([]byte, error) {
+	zstdOnce.Do(initZstd)
+	return zstdDec.DecodeAll(item, nil)
+}
+
+// zstdDecodedLen returns the decompressed size of a zstd compressed item as
+// stored in its frame header, or the compressed size if not available.
+func zstdDecodedLen(item []byte) int {
+	var header zstd.Header
+	if err := header.Decode(item); err != nil || !header.HasFCS {
+		return len(item)
+	}
+	return int(header.FrameContentSize)
+}
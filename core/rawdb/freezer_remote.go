@@ -0,0 +1,558 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/lru"
+	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/log"
+)
+
+const (
+	// remoteManifestKey is the object describing the published ancient data.
+	remoteManifestKey = "MANIFEST"
+
+	// remoteSegmentItems is the number of items per object of published chain data.
+	remoteSegmentItems = 2048
+
+	// remoteChunkSize is the granularity in which objects are fetched and cached.
+	remoteChunkSize = 1024 * 1024
+
+	// remoteIndexCacheItems is the number of segment indexes kept in memory.
+	remoteIndexCacheItems = 256
+)
+
+// remoteManifest describes ancient data published to an object store. Items are
+// stored in segments of a fixed number of items per table, each segment being an
+// immutable object. Only complete segments are published, the manifest is updated
+// after all tables of a segment have been uploaded.
+type remoteManifest struct {
+	Tail         uint64                 `json:"tail"`         // Number of the first published item
+	Items        uint64                 `json:"items"`        // Number of the item after the last published one
+	SegmentItems uint64                 `json:"segmentItems"` // Number of items per segment
+	Tables       map[string]remoteTable `json:"tables"`       // Published tables
+}
+
+// remoteTable describes a table of published ancient data.
+type remoteTable struct {
+	NoSnappy bool   `json:"noSnappy"` // Whether items are stored uncompressed
+	Size     uint64 `json:"size"`     // Total size of the stored items
+}
+
+// remoteSegmentKey returns the object name of a segment of the given table.
+func remoteSegmentKey(kind string, segment uint64) string {
+	return fmt.Sprintf("%s/%010d", kind, segment)
+}
+
+// encodeRemoteSegment encodes the items of a segment. A segment starts with the
+// big endian offsets of the items and the end of the last one, relative to the
+// end of the offsets, followed by the items.
+func encodeRemoteSegment(items [][]byte) []byte {
+	var (
+		header = 8 * (len(items) + 1)
+		offset uint64
+	)
+	for _, item := range items {
+		offset += uint64(len(item))
+	}
+	blob := make([]byte, header, header+int(offset))
+
+	offset = 0
+	for i, item := range items {
+		binary.BigEndian.PutUint64(blob[8*i:], offset)
+		offset += uint64(len(item))
+		blob = append(blob, item...)
+	}
+	binary.BigEndian.PutUint64(blob[8*len(items):], offset)
+	return blob
+}
+
+// remoteFreezer is a read-only ancient store serving data published to an object
+// store. Fetched data is cached locally, so that the same history can be shared
+// by multiple nodes without each of them downloading all of it.
+type remoteFreezer struct {
+	manifest remoteManifest
+	cache    *remoteCache
+	indexes  *lru.Cache[string, []uint64] // Item offsets of recently used segments
+}
+
+// newRemoteFreezer opens the ancient data published to the object store. Fetched
+// data is cached in the given directory, up to limit bytes. The local cache is
+// disabled if the directory is empty.
+func newRemoteFreezer(store ethdb.ObjectStore, cacheDir string, cacheLimit uint64) (*remoteFreezer, error) {
+	blob, err := store.Get(remoteManifestKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve remote ancient manifest: %w", err)
+	}
+	var manifest remoteManifest
+	if err := json.Unmarshal(blob, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid remote ancient manifest: %v", err)
+	}
+	if manifest.SegmentItems == 0 || manifest.Items < manifest.Tail || (manifest.Items-manifest.Tail)%manifest.SegmentItems != 0 {
+		return nil, fmt.Errorf("invalid remote ancient range [%d, %d) with segment size %d", manifest.Tail, manifest.Items, manifest.SegmentItems)
+	}
+	cache, err := newRemoteCache(store, cacheDir, cacheLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteFreezer{
+		manifest: manifest,
+		cache:    cache,
+		indexes:  lru.NewCache[string, []uint64](remoteIndexCacheItems),
+	}, nil
+}
+
+// index returns the item offsets of the given segment.
+func (f *remoteFreezer) index(kind string, segment uint64) ([]uint64, error) {
+	key := remoteSegmentKey(kind, segment)
+	if offsets, ok := f.indexes.Get(key); ok {
+		return offsets, nil
+	}
+	size := 8 * (f.manifest.SegmentItems + 1)
+	blob, err := f.cache.read(key, 0, size)
+	if err != nil {
+		return nil, err
+	}
+	offsets := make([]uint64, f.manifest.SegmentItems+1)
+	for i := range offsets {
+		offsets[i] = binary.BigEndian.Uint64(blob[8*i:]) + size
+		if i > 0 && offsets[i] < offsets[i-1] {
+			return nil, fmt.Errorf("corrupt remote segment %s", key)
+		}
+	}
+	f.indexes.Add(key, offsets)
+	return offsets, nil
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists.
+func (f *remoteFreezer) HasAncient(kind string, number uint64) (bool, error) {
+	if _, ok := f.manifest.Tables[kind]; ok {
+		return number >= f.manifest.Tail && number < f.manifest.Items, nil
+	}
+	return false, nil
+}
+
+// Ancient retrieves an ancient binary blob from the object store.
+func (f *remoteFreezer) Ancient(kind string, number uint64) ([]byte, error) {
+	items, err := f.AncientRange(kind, number, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+// AncientRange retrieves multiple items in sequence, starting from the index 'start'.
+// It will return
+//   - at most 'count' items,
+//   - if maxBytes is specified: at least 1 item (even if exceeding the maxByteSize),
+//     but will otherwise return as many items as fit into maxByteSize.
+//   - if maxBytes is not specified, 'count' items will be returned if they are present.
+func (f *remoteFreezer) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
+	table, ok := f.manifest.Tables[kind]
+	if !ok {
+		return nil, errUnknownTable
+	}
+	if start < f.manifest.Tail || start >= f.manifest.Items || count == 0 {
+		return nil, errOutOfBounds
+	}
+	if start+count > f.manifest.Items {
+		count = f.manifest.Items - start
+	}
+	var (
+		output [][]byte
+		size   uint64
+	)
+	for count > 0 {
+		// Collect the stored items from the segment holding the next one. As the
+		// stored size doesn't exceed the decompressed one, the stored size limits
+		// the amount of data to fetch.
+		var (
+			segment = (start - f.manifest.Tail) / f.manifest.SegmentItems
+			first   = (start - f.manifest.Tail) % f.manifest.SegmentItems
+			last    = first
+		)
+		offsets, err := f.index(kind, segment)
+		if err != nil {
+			return nil, err
+		}
+		for last < f.manifest.SegmentItems && last-first < count {
+			if last > first && maxBytes != 0 && size+offsets[last+1]-offsets[first] > maxBytes {
+				break
+			}
+			last++
+		}
+		blob, err := f.cache.read(remoteSegmentKey(kind, segment), offsets[first], offsets[last]-offsets[first])
+		if err != nil {
+			return nil, err
+		}
+		for i := first; i < last; i++ {
+			item := blob[offsets[i]-offsets[first] : offsets[i+1]-offsets[first]]
+			if !table.NoSnappy {
+				if item, err = snappy.Decode(nil, item); err != nil {
+					return nil, err
+				}
+			}
+			if len(output) > 0 && maxBytes != 0 && size+uint64(len(item)) > maxBytes {
+				return output, nil
+			}
+			output = append(output, item)
+			size += uint64(len(item))
+		}
+		if last < f.manifest.SegmentItems && last-first < count {
+			return output, nil // Byte limit reached
+		}
+		start += last - first
+		count -= last - first
+	}
+	return output, nil
+}
+
+// Ancients returns the number of the item after the last published one.
+func (f *remoteFreezer) Ancients() (uint64, error) {
+	return f.manifest.Items, nil
+}
+
+// Tail returns the number of the first published item.
+func (f *remoteFreezer) Tail() (uint64, error) {
+	return f.manifest.Tail, nil
+}
+
+// AncientSize returns the stored size of the specified table.
+func (f *remoteFreezer) AncientSize(kind string) (uint64, error) {
+	if table, ok := f.manifest.Tables[kind]; ok {
+		return table.Size, nil
+	}
+	return 0, errUnknownTable
+}
+
+// ReadAncients runs the given read operation. Published data is immutable, so no
+// further synchronization is necessary.
+func (f *remoteFreezer) ReadAncients(fn func(ethdb.AncientReaderOp) error) (err error) {
+	return fn(f)
+}
+
+// ModifyAncients is not supported, data can only be added by publishing it.
+func (f *remoteFreezer) ModifyAncients(func(ethdb.AncientWriteOp) error) (int64, error) {
+	return 0, errReadOnly
+}
+
+// TruncateHead is not supported, published data is immutable.
+func (f *remoteFreezer) TruncateHead(items uint64) (uint64, error) {
+	return 0, errReadOnly
+}
+
+// TruncateTail is not supported, published data is immutable.
+func (f *remoteFreezer) TruncateTail(tail uint64) (uint64, error) {
+	return 0, errReadOnly
+}
+
+// Sync is a noop, there's no local data to flush.
+func (f *remoteFreezer) Sync() error {
+	return nil
+}
+
+// MigrateTable is not supported, published data is immutable.
+func (f *remoteFreezer) MigrateTable(kind string, convert convertLegacyFn) error {
+	return errReadOnly
+}
+
+// Close implements io.Closer.
+func (f *remoteFreezer) Close() error {
+	return nil
+}
+
+// remoteCache is a read-through cache of object store data on the local disk.
+// Objects are fetched and cached in fixed size chunks, the least recently used
+// chunks being evicted once the cache exceeds its size limit. Only immutable
+// objects may be accessed through the cache.
+type remoteCache struct {
+	store ethdb.ObjectStore
+	dir   string // Directory of the cached chunks, empty if disabled
+	limit uint64 // Maximum total size of the cached chunks
+
+	chunks lru.BasicLRU[string, uint64] // Sizes of the cached chunks
+	size   uint64                       // Total size of the cached chunks
+	lock   sync.Mutex
+}
+
+// newRemoteCache opens the chunk cache in the given directory, picking up the
+// chunks cached in previous runs.
+func newRemoteCache(store ethdb.ObjectStore, dir string, limit uint64) (*remoteCache, error) {
+	c := &remoteCache{
+		store:  store,
+		dir:    dir,
+		limit:  limit,
+		chunks: lru.NewBasicLRU[string, uint64](int(limit/remoteChunkSize) + 1),
+	}
+	if dir == "" {
+		return c, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !entry.Type().IsRegular() {
+			continue
+		}
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			os.Remove(filepath.Join(dir, entry.Name()))
+			continue
+		}
+		c.add(entry.Name(), uint64(info.Size()))
+	}
+	return c, nil
+}
+
+// read retrieves length bytes of the object at the given offset.
+func (c *remoteCache) read(key string, offset, length uint64) ([]byte, error) {
+	var blob []byte
+	if c.dir == "" {
+		var err error
+		if blob, err = c.store.GetRange(key, offset, length); err != nil {
+			return nil, err
+		}
+	} else {
+		blob = make([]byte, 0, length)
+		for chunk := offset / remoteChunkSize; uint64(len(blob)) < length; chunk++ {
+			data, err := c.chunk(key, chunk)
+			if err != nil {
+				return nil, err
+			}
+			var (
+				start = chunk * remoteChunkSize
+				from  = offset + uint64(len(blob)) - start
+				to    = from + length - uint64(len(blob))
+			)
+			if to > uint64(len(data)) {
+				to = uint64(len(data))
+			}
+			if from < to {
+				blob = append(blob, data[from:to]...)
+			}
+			if uint64(len(data)) < remoteChunkSize {
+				break // End of object
+			}
+		}
+	}
+	if uint64(len(blob)) < length {
+		return nil, fmt.Errorf("remote object %s truncated: have %d bytes at %d, want %d", key, len(blob), offset, length)
+	}
+	return blob, nil
+}
+
+// chunk retrieves a chunk of an object, from the local cache if available.
+func (c *remoteCache) chunk(key string, chunk uint64) ([]byte, error) {
+	name := strings.ReplaceAll(key, "/", "-") + "." + strconv.FormatUint(chunk, 10)
+	path := filepath.Join(c.dir, name)
+
+	c.lock.Lock()
+	_, cached := c.chunks.Get(name)
+	c.lock.Unlock()
+
+	if cached {
+		blob, err := os.ReadFile(path)
+		if err == nil {
+			return blob, nil
+		}
+		// Concurrently evicted chunks are simply fetched again
+		if !os.IsNotExist(err) {
+			log.Warn("Failed to read cached ancient chunk", "chunk", name, "err", err)
+		}
+	}
+	blob, err := c.store.GetRange(key, chunk*remoteChunkSize, remoteChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	// Write the chunk atomically, concurrent fetches of it store the same data
+	if err := os.WriteFile(path+".tmp", blob, 0644); err != nil {
+		log.Warn("Failed to cache ancient chunk", "chunk", name, "err", err)
+		return blob, nil
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		log.Warn("Failed to cache ancient chunk", "chunk", name, "err", err)
+		return blob, nil
+	}
+	c.lock.Lock()
+	c.add(name, uint64(len(blob)))
+	c.lock.Unlock()
+	return blob, nil
+}
+
+// add tracks a cached chunk, evicting the least recently used chunks if the cache
+// grows beyond its limit. The caller must hold the lock.
+func (c *remoteCache) add(name string, size uint64) {
+	if old, ok := c.chunks.Peek(name); ok {
+		c.size -= old
+	}
+	c.chunks.Add(name, size)
+	c.size += size
+
+	for c.size > c.limit {
+		evict, evictSize, ok := c.chunks.RemoveOldest()
+		if !ok {
+			break
+		}
+		os.Remove(filepath.Join(c.dir, evict))
+		c.size -= evictSize
+	}
+}
+
+// PublishChainAncients uploads the complete segments of ancient chain data not yet
+// published to the object store, returning the number of published items. The
+// published data can be served by nodes opening their database with the object
+// store as remote ancient store.
+func PublishChainAncients(db ethdb.AncientReader, store ethdb.ObjectStore) (uint64, error) {
+	return publishAncients(db, store, chainFreezerNoSnappy, remoteSegmentItems)
+}
+
+// publishAncients uploads the complete segments of ancient data of the given
+// tables not yet published to the object store.
+func publishAncients(db ethdb.AncientReader, store ethdb.ObjectStore, tables map[string]bool, segmentItems uint64) (uint64, error) {
+	tail, err := db.Tail()
+	if err != nil {
+		return 0, err
+	}
+	items, err := db.Ancients()
+	if err != nil {
+		return 0, err
+	}
+	// Continue an existing publication, as long as it was of the same data
+	manifest := remoteManifest{
+		Tail:         tail,
+		Items:        tail,
+		SegmentItems: segmentItems,
+		Tables:       make(map[string]remoteTable),
+	}
+	for kind, noSnappy := range tables {
+		manifest.Tables[kind] = remoteTable{NoSnappy: noSnappy}
+	}
+	if _, err := store.Get(remoteManifestKey); err == nil {
+		remote, err := newRemoteFreezer(store, "", 0)
+		if err != nil {
+			return 0, err
+		}
+		if err := checkPublishedAncients(db, remote, tables, segmentItems); err != nil {
+			return 0, err
+		}
+		manifest = remote.manifest
+	} else if !errors.Is(err, ethdb.ErrObjectNotFound) {
+		return 0, err
+	}
+	var (
+		published = manifest.Items
+		start     = time.Now()
+		logged    = time.Now()
+	)
+	for manifest.Items+manifest.SegmentItems <= items {
+		segment := (manifest.Items - manifest.Tail) / manifest.SegmentItems
+		for kind, noSnappy := range tables {
+			blobs, err := db.AncientRange(kind, manifest.Items, manifest.SegmentItems, 0)
+			if err != nil {
+				return manifest.Items - published, err
+			}
+			if uint64(len(blobs)) != manifest.SegmentItems {
+				return manifest.Items - published, fmt.Errorf("incomplete segment of %s: have %d items, want %d", kind, len(blobs), manifest.SegmentItems)
+			}
+			if !noSnappy {
+				for i, blob := range blobs {
+					blobs[i] = snappy.Encode(nil, blob)
+				}
+			}
+			blob := encodeRemoteSegment(blobs)
+			if err := store.Put(remoteSegmentKey(kind, segment), blob); err != nil {
+				return manifest.Items - published, err
+			}
+			table := manifest.Tables[kind]
+			table.Size += uint64(len(blob)) - 8*(manifest.SegmentItems+1)
+			manifest.Tables[kind] = table
+		}
+		manifest.Items += manifest.SegmentItems
+
+		blob, err := json.Marshal(manifest)
+		if err != nil {
+			return manifest.Items - published, err
+		}
+		if err := store.Put(remoteManifestKey, blob); err != nil {
+			return manifest.Items - published, err
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Publishing ancient data", "items", manifest.Items, "target", items, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	return manifest.Items - published, nil
+}
+
+// checkPublishedAncients ensures that local ancient data extends the data already
+// published to an object store.
+func checkPublishedAncients(db ethdb.AncientReader, remote *remoteFreezer, tables map[string]bool, segmentItems uint64) error {
+	manifest := remote.manifest
+	if manifest.SegmentItems != segmentItems {
+		return fmt.Errorf("published segment size mismatch: have %d, want %d", manifest.SegmentItems, segmentItems)
+	}
+	if len(manifest.Tables) != len(tables) {
+		return errors.New("published tables mismatch")
+	}
+	for kind, noSnappy := range tables {
+		if table, ok := manifest.Tables[kind]; !ok || table.NoSnappy != noSnappy {
+			return fmt.Errorf("published table %s mismatch", kind)
+		}
+	}
+	tail, err := db.Tail()
+	if err != nil {
+		return err
+	}
+	if tail > manifest.Items {
+		return fmt.Errorf("gap between published ancients [%d, %d) and local tail %d", manifest.Tail, manifest.Items, tail)
+	}
+	// Compare the last published items to detect publishing a different chain
+	if manifest.Items == manifest.Tail || tail == manifest.Items {
+		return nil
+	}
+	for kind := range tables {
+		local, err := db.Ancient(kind, manifest.Items-1)
+		if err != nil {
+			return err
+		}
+		published, err := remote.Ancient(kind, manifest.Items-1)
+		if err != nil {
+			return err
+		}
+		if string(local) != string(published) {
+			return fmt.Errorf("published %s item %d mismatch", kind, manifest.Items-1)
+		}
+	}
+	return nil
+}
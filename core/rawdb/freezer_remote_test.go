@@ -0,0 +1,246 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/ethdb"
+)
+
+// memoryObjectStore is an in-memory object store counting the fetches.
+type memoryObjectStore struct {
+	objects map[string][]byte
+	fetches int
+	lock    sync.Mutex
+}
+
+func newMemoryObjectStore() *memoryObjectStore {
+	return &memoryObjectStore{objects: make(map[string][]byte)}
+}
+
+func (s *memoryObjectStore) Get(key string) ([]byte, error) {
+	return s.GetRange(key, 0, 1<<62)
+}
+
+func (s *memoryObjectStore) GetRange(key string, offset, length uint64) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.fetches++
+	blob, ok := s.objects[key]
+	if !ok {
+		return nil, ethdb.ErrObjectNotFound
+	}
+	if offset >= uint64(len(blob)) {
+		return nil, nil
+	}
+	if end := uint64(len(blob)); offset+length > end {
+		length = end - offset
+	}
+	return common.CopyBytes(blob[offset : offset+length]), nil
+}
+
+func (s *memoryObjectStore) Put(key string, data []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.objects[key] = common.CopyBytes(data)
+	return nil
+}
+
+func TestRemoteFreezer(t *testing.T) {
+	t.Parallel()
+
+	tables := map[string]bool{"raw": true, "snappy": false}
+	f, _ := newFreezerForTesting(t, tables)
+	defer f.Close()
+	appendTestItems(t, f, 0, 10, 300)
+
+	// Only complete segments are published
+	store := newMemoryObjectStore()
+	published, err := publishAncients(f, store, tables, 4)
+	if err != nil {
+		t.Fatal("publish failed:", err)
+	}
+	if published != 8 {
+		t.Fatalf("wrong number of published items: have %d, want 8", published)
+	}
+	remote, err := newRemoteFreezer(store, t.TempDir(), 1024*1024*1024)
+	if err != nil {
+		t.Fatal("can't open remote freezer:", err)
+	}
+	if items, _ := remote.Ancients(); items != 8 {
+		t.Fatalf("wrong number of remote items: have %d, want 8", items)
+	}
+	for kind := range tables {
+		for i := 0; i < 8; i++ {
+			blob, err := remote.Ancient(kind, uint64(i))
+			if err != nil {
+				t.Fatalf("failed to retrieve %s item %d: %v", kind, i, err)
+			}
+			if !bytes.Equal(blob, getChunk(300, i)) {
+				t.Fatalf("%s item %d mismatch", kind, i)
+			}
+		}
+		// Ranges span segments and respect the byte limit
+		items, err := remote.AncientRange(kind, 2, 5, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 5 || !bytes.Equal(items[4], getChunk(300, 6)) {
+			t.Fatalf("wrong %s range across segments: %d items", kind, len(items))
+		}
+		if items, _ = remote.AncientRange(kind, 2, 5, 700); len(items) != 2 {
+			t.Fatalf("wrong %s range with byte limit: have %d items, want 2", kind, len(items))
+		}
+		if items, _ = remote.AncientRange(kind, 6, 10, 0); len(items) != 2 {
+			t.Fatalf("wrong %s range beyond head: have %d items, want 2", kind, len(items))
+		}
+		if _, err := remote.Ancient(kind, 8); !errors.Is(err, errOutOfBounds) {
+			t.Fatalf("wrong error for unpublished item: %v", err)
+		}
+	}
+	if _, err := remote.Ancient("missing", 0); !errors.Is(err, errUnknownTable) {
+		t.Fatalf("wrong error for unknown table: %v", err)
+	}
+	if _, err := remote.TruncateHead(0); !errors.Is(err, errReadOnly) {
+		t.Fatalf("wrong error for truncation: %v", err)
+	}
+	// Publishing again only uploads the new segments
+	appendTestItems(t, f, 10, 13, 300)
+	if published, err = publishAncients(f, store, tables, 4); err != nil {
+		t.Fatal("republish failed:", err)
+	}
+	if published != 4 {
+		t.Fatalf("wrong number of republished items: have %d, want 4", published)
+	}
+	// Different data must not be published on top
+	other, _ := newFreezerForTesting(t, tables)
+	defer other.Close()
+	appendTestItems(t, other, 0, 16, 200)
+	if _, err := publishAncients(other, store, tables, 4); err == nil {
+		t.Fatal("published mismatching data")
+	}
+}
+
+func TestRemoteFreezerCache(t *testing.T) {
+	t.Parallel()
+
+	tables := map[string]bool{"raw": true}
+	f, _ := newFreezerForTesting(t, tables)
+	defer f.Close()
+	appendTestItems(t, f, 0, 8, 300)
+
+	store := newMemoryObjectStore()
+	if _, err := publishAncients(f, store, tables, 4); err != nil {
+		t.Fatal("publish failed:", err)
+	}
+	// Reads are served from the local cache once fetched, also after reopening
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		remote, err := newRemoteFreezer(store, dir, 1024*1024*1024)
+		if err != nil {
+			t.Fatal("can't open remote freezer:", err)
+		}
+		if i == 0 {
+			if _, err := remote.AncientRange("raw", 0, 8, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		fetches := store.fetches
+		items, err := remote.AncientRange("raw", 0, 8, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 8 || !bytes.Equal(items[7], getChunk(300, 7)) {
+			t.Fatal("wrong cached items")
+		}
+		if store.fetches != fetches {
+			t.Fatalf("run %d: cached items fetched again", i)
+		}
+	}
+	// Chunks beyond the cache limit are evicted
+	dir = t.TempDir()
+	remote, err := newRemoteFreezer(store, dir, 1000)
+	if err != nil {
+		t.Fatal("can't open remote freezer:", err)
+	}
+	if _, err := remote.AncientRange("raw", 0, 8, 0); err != nil {
+		t.Fatal(err)
+	}
+	var size int64
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		info, _ := entry.Info()
+		size += info.Size()
+	}
+	if size > 1000 {
+		t.Fatalf("cache exceeds limit: %d bytes", size)
+	}
+}
+
+func TestRemoteFreezerDatabase(t *testing.T) {
+	t.Parallel()
+
+	// Write some blocks to a local freezer and publish them
+	db, err := NewDatabaseWithFreezer(NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatal("can't open freezer database:", err)
+	}
+	defer db.Close()
+
+	blocks := makeTestBlocks(8, 2)
+	if _, err := WriteAncientBlocks(db, blocks, makeTestReceipts(8, 2), big.NewInt(100)); err != nil {
+		t.Fatal("failed to write ancient blocks:", err)
+	}
+	store := newMemoryObjectStore()
+	if _, err := publishAncients(db, store, chainFreezerNoSnappy, 4); err != nil {
+		t.Fatal("publish failed:", err)
+	}
+	// Serve the blocks from the object store
+	remote, err := NewDatabaseWithRemoteFreezer(NewMemoryDatabase(), t.TempDir(), store, 1024*1024)
+	if err != nil {
+		t.Fatal("can't open remote database:", err)
+	}
+	defer remote.Close()
+
+	for _, block := range blocks {
+		if hash := ReadCanonicalHash(remote, block.NumberU64()); hash != block.Hash() {
+			t.Fatalf("block %d: wrong canonical hash %x", block.NumberU64(), hash)
+		}
+		have := ReadBlock(remote, block.Hash(), block.NumberU64())
+		if have == nil || have.Hash() != block.Hash() || len(have.Transactions()) != 2 {
+			t.Fatalf("block %d: wrong remote block", block.NumberU64())
+		}
+	}
+	if err := remote.(*freezerdb).Freeze(0); !errors.Is(err, errNotSupported) {
+		t.Fatalf("wrong error for freezing: %v", err)
+	}
+	// A key-value store of a different chain is rejected
+	kvdb := NewMemoryDatabase()
+	WriteCanonicalHash(kvdb, blocks[1].Hash(), 0)
+	if _, err := NewDatabaseWithRemoteFreezer(kvdb, "", store, 0); err == nil {
+		t.Fatal("opened remote ancients of a different chain")
+	}
+}
@@ -99,6 +99,7 @@ type freezerTable struct {
 	itemHidden atomic.Uint64
 
 	noCompression bool // if true, disables snappy compression. Note: does not work retroactively
+	zstd          bool // if true, compresses with zstd instead of snappy. Note: does not work retroactively
 	readonly      bool
 	maxFileSize   uint32 // Max file size for data-files
 	name          string
@@ -111,6 +112,8 @@ type freezerTable struct {
 	headId uint32              // number of the currently active head file
 	tailId uint32              // number of the earliest file
 
+	volumes *freezerVolumes // Locations of the data files, nil if all in path
+
 	headBytes  int64         // Number of bytes written to the head file
 	readMeter  metrics.Meter // Meter for measuring the effective amount of data read
 	writeMeter metrics.Meter // Meter for measuring the effective amount of data written
@@ -129,14 +132,39 @@ func newFreezerTable(path, name string, disableSnappy, readonly bool) (*freezerT
 // non-existent. Both files are truncated to the shortest common length to ensure
 // they don't go out of sync.
 func newTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, maxFilesize uint32, noCompression, readonly bool) (*freezerTable, error) {
+	return openTable(path, name, readMeter, writeMeter, sizeGauge, maxFilesize, readonly, freezerTableConfig{noCompression: noCompression})
+}
+
+// freezerTableConfig contains the storage settings of a freezer table.
+type freezerTableConfig struct {
+	noCompression bool            // Disables the compression of items
+	zstd          bool            // Compresses a newly created table with zstd instead of snappy
+	volumes       *freezerVolumes // Locations of the data files, nil to keep them with the index
+}
+
+// openTable opens a freezer table with the given storage settings, creating the
+// data and index files if they are non-existent.
+func openTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, maxFilesize uint32, readonly bool, config freezerTableConfig) (*freezerTable, error) {
 	// Ensure the containing directory exists and open the indexEntry file
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, err
 	}
+	// Existing tables keep the compression they were created with
+	zstd := !config.noCompression && config.zstd
+	if !config.noCompression {
+		if _, err := os.Stat(filepath.Join(path, fmt.Sprintf("%s.cidx", name))); err == nil {
+			zstd = false
+		} else if _, err := os.Stat(filepath.Join(path, fmt.Sprintf("%s.zidx", name))); err == nil {
+			zstd = true
+		}
+	}
 	var idxName string
-	if noCompression {
+	switch {
+	case config.noCompression:
 		idxName = fmt.Sprintf("%s.ridx", name) // raw index file
-	} else {
+	case zstd:
+		idxName = fmt.Sprintf("%s.zidx", name) // zstd compressed index file
+	default:
 		idxName = fmt.Sprintf("%s.cidx", name) // compressed index file
 	}
 	var (
@@ -175,9 +203,11 @@ func newTable(path string, name string, readMeter metrics.Meter, writeMeter metr
 		name:          name,
 		path:          path,
 		logger:        log.New("database", path, "table", name),
-		noCompression: noCompression,
+		noCompression: config.noCompression,
+		zstd:          zstd,
 		readonly:      readonly,
 		maxFileSize:   maxFilesize,
+		volumes:       config.volumes,
 	}
 	if err := tab.repair(); err != nil {
 		tab.Close()
@@ -594,12 +624,21 @@ func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error
 	var exist bool
 	if f, exist = t.files[num]; !exist {
 		var name string
-		if t.noCompression {
+		switch {
+		case t.noCompression:
 			name = fmt.Sprintf("%s.%04d.rdat", t.name, num)
-		} else {
+		case t.zstd:
+			name = fmt.Sprintf("%s.%04d.zdat", t.name, num)
+		default:
 			name = fmt.Sprintf("%s.%04d.cdat", t.name, num)
 		}
-		f, err = opener(filepath.Join(t.path, name))
+		path := filepath.Join(t.path, name)
+		if t.volumes != nil {
+			if path, err = t.volumes.path(name, !t.readonly); err != nil {
+				return nil, err
+			}
+		}
+		f, err = opener(path)
 		if err != nil {
 			return nil, err
 		}
@@ -608,6 +647,16 @@ func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error
 	return f, err
 }
 
+// removeFile deletes a released data file, dropping it from the volumes.
+func (t *freezerTable) removeFile(f *os.File) {
+	os.Remove(f.Name())
+	if t.volumes != nil {
+		if err := t.volumes.remove(filepath.Base(f.Name())); err != nil {
+			t.logger.Error("Failed to update freezer manifest", "file", f.Name(), "err", err)
+		}
+	}
+}
+
 // releaseFile closes a file, and removes it from the open file cache.
 // Assumes that the caller holds the write lock
 func (t *freezerTable) releaseFile(num uint32) {
@@ -624,7 +673,7 @@ func (t *freezerTable) releaseFilesAfter(num uint32, remove bool) {
 			delete(t.files, fnum)
 			f.Close()
 			if remove {
-				os.Remove(f.Name())
+				t.removeFile(f)
 			}
 		}
 	}
@@ -637,7 +686,7 @@ func (t *freezerTable) releaseFilesBefore(num uint32, remove bool) {
 			delete(t.files, fnum)
 			f.Close()
 			if remove {
-				os.Remove(f.Name())
+				t.removeFile(f)
 			}
 		}
 	}
@@ -709,19 +758,29 @@ func (t *freezerTable) RetrieveItems(start, count, maxBytes uint64) ([][]byte, e
 		item := diskData[offset : offset+diskSize]
 		offset += diskSize
 		decompressedSize := diskSize
-		if !t.noCompression {
+		switch {
+		case t.zstd:
+			decompressedSize = zstdDecodedLen(item)
+		case !t.noCompression:
 			decompressedSize, _ = snappy.DecodedLen(item)
 		}
 		if i > 0 && maxBytes != 0 && uint64(outputSize+decompressedSize) > maxBytes {
 			break
 		}
-		if !t.noCompression {
+		switch {
+		case t.zstd:
+			data, err := zstdDecode(item)
+			if err != nil {
+				return nil, err
+			}
+			output = append(output, data)
+		case !t.noCompression:
 			data, err := snappy.Decode(nil, item)
 			if err != nil {
 				return nil, err
 			}
 			output = append(output, data)
-		} else {
+		default:
 			output = append(output, item)
 		}
 		outputSize += decompressedSize
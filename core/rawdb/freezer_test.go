@@ -283,6 +283,63 @@ func TestFreezerReadonlyValidate(t *testing.T) {
 	}
 }
 
+// Tests that only the prunable tables are truncated at the tail, and that the
+// differing tails survive reopening the freezer.
+func TestFreezerPrunableTables(t *testing.T) {
+	var (
+		tables = map[string]bool{"a": true, "b": true}
+		opts   = FreezerOptions{prunable: map[string]bool{"b": true}}
+		dir    = t.TempDir()
+	)
+	f, err := NewFreezerWithOptions(dir, "", false, 2049, tables, opts)
+	if err != nil {
+		t.Fatal("can't open freezer", err)
+	}
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < 10; i++ {
+			if err := op.AppendRaw("a", i, getChunk(100, int(i))); err != nil {
+				return err
+			}
+			if err := op.AppendRaw("b", i, getChunk(100, int(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = f.TruncateTail(5)
+	require.NoError(t, err)
+
+	check := func(f *Freezer) {
+		t.Helper()
+
+		if tail, _ := f.Tail(); tail != 5 {
+			t.Fatalf("tail mismatch: have %d, want 5", tail)
+		}
+		if _, err := f.Ancient("a", 0); err != nil {
+			t.Fatalf("retained item pruned: %v", err)
+		}
+		if _, err := f.Ancient("b", 4); err == nil {
+			t.Fatal("pruned item retained")
+		}
+		if blob, err := f.Ancient("b", 5); err != nil || !bytes.Equal(blob, getChunk(100, 5)) {
+			t.Fatalf("item above tail mismatch: %x, %v", blob, err)
+		}
+	}
+	check(f)
+	require.NoError(t, f.Close())
+
+	// Reopen the freezer, repairing and validating it
+	for _, readonly := range []bool{false, true} {
+		f, err := NewFreezerWithOptions(dir, "", readonly, 2049, tables, opts)
+		if err != nil {
+			t.Fatalf("can't reopen freezer (readonly %v): %v", readonly, err)
+		}
+		check(f)
+		require.NoError(t, f.Close())
+	}
+}
+
 func newFreezerForTesting(t *testing.T, tables map[string]bool) (*Freezer, string) {
 	t.Helper()
 
@@ -0,0 +1,193 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// freezerManifestName is the name of the manifest recording the data files of a
+// freezer stored outside of its directory.
+const freezerManifestName = "VOLUMES"
+
+// FreezerVolume is an additional directory to store the data files of freezer
+// tables in, typically on a separate disk. The index and metadata files of the
+// tables always remain in the freezer directory.
+type FreezerVolume struct {
+	Path  string // Directory of the volume
+	Limit uint64 // Maximum number of bytes to place on the volume (0 = unlimited)
+}
+
+// FreezerOptions configures how a freezer stores the data of its tables.
+type FreezerOptions struct {
+	// Zstd compresses newly created tables with zstd instead of snappy. Existing
+	// tables keep the compression they were created with.
+	Zstd bool
+
+	// Volumes are the directories to place new data files on, in the order of
+	// preference. Files are placed on the first volume with room left for a full
+	// data file, or in the freezer directory if there is none.
+	Volumes []FreezerVolume
+
+	// prunable are the tables whose tail can be truncated, all of them if nil.
+	// The tail of the other tables stays in place.
+	prunable map[string]bool
+}
+
+// freezerManifest is the persisted form of the data file locations.
+type freezerManifest struct {
+	Files map[string]string `json:"files"` // Data file name to directory
+}
+
+// freezerVolumes tracks the directories holding the data files of a freezer.
+// The location of every file placed on a volume is persisted in a manifest, so
+// files are found even if the volumes are reconfigured later on.
+type freezerVolumes struct {
+	datadir     string          // Freezer directory, holding files not in the manifest
+	volumes     []FreezerVolume // Volumes to place new data files on
+	maxFileSize uint64          // Maximum size of a data file
+	readonly    bool
+
+	files map[string]string // Data file name to directory, for files on volumes
+	lock  sync.Mutex
+}
+
+// newFreezerVolumes loads the manifest of the freezer in datadir. The data files
+// of each freezer are kept in a subdirectory of the volumes named after the
+// freezer directory, so volumes can be shared between freezers.
+func newFreezerVolumes(datadir string, volumes []FreezerVolume, maxFileSize uint32, readonly bool) (*freezerVolumes, error) {
+	v := &freezerVolumes{
+		datadir:     datadir,
+		maxFileSize: uint64(maxFileSize),
+		readonly:    readonly,
+		files:       make(map[string]string),
+	}
+	for _, volume := range volumes {
+		if volume.Path == "" {
+			return nil, errors.New("empty freezer volume path")
+		}
+		if volume.Limit != 0 && volume.Limit < v.maxFileSize {
+			return nil, fmt.Errorf("freezer volume %s limit %d below data file size %d", volume.Path, volume.Limit, maxFileSize)
+		}
+		path, err := filepath.Abs(filepath.Join(volume.Path, filepath.Base(datadir)))
+		if err != nil {
+			return nil, err
+		}
+		v.volumes = append(v.volumes, FreezerVolume{Path: path, Limit: volume.Limit})
+	}
+	blob, err := os.ReadFile(filepath.Join(datadir, freezerManifestName))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return v, nil
+	case err != nil:
+		return nil, err
+	}
+	var manifest freezerManifest
+	if err := json.Unmarshal(blob, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid freezer manifest: %v", err)
+	}
+	for name, dir := range manifest.Files {
+		v.files[name] = dir
+	}
+	return v, nil
+}
+
+// path returns the path of the named data file. Files not yet existing are placed
+// on a volume if create is set, otherwise they are located in the freezer directory.
+func (v *freezerVolumes) path(name string, create bool) (string, error) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if dir, ok := v.files[name]; ok {
+		return filepath.Join(dir, name), nil
+	}
+	local := filepath.Join(v.datadir, name)
+	if !create || v.readonly || len(v.volumes) == 0 {
+		return local, nil
+	}
+	if _, err := os.Stat(local); err == nil {
+		return local, nil
+	}
+	for _, volume := range v.volumes {
+		if volume.Limit != 0 && uint64(v.count(volume.Path)+1)*v.maxFileSize > volume.Limit {
+			continue
+		}
+		if err := os.MkdirAll(volume.Path, 0755); err != nil {
+			return "", err
+		}
+		v.files[name] = volume.Path
+		if err := v.save(); err != nil {
+			delete(v.files, name)
+			return "", err
+		}
+		return filepath.Join(volume.Path, name), nil
+	}
+	return local, nil
+}
+
+// remove drops a deleted data file from the manifest.
+func (v *freezerVolumes) remove(name string) error {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if _, ok := v.files[name]; !ok || v.readonly {
+		return nil
+	}
+	delete(v.files, name)
+	return v.save()
+}
+
+// count returns the number of data files placed in the given directory.
+func (v *freezerVolumes) count(dir string) int {
+	var n int
+	for _, d := range v.files {
+		if d == dir {
+			n++
+		}
+	}
+	return n
+}
+
+// save atomically persists the manifest.
+func (v *freezerVolumes) save() error {
+	blob, err := json.MarshalIndent(freezerManifest{Files: v.files}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(v.datadir, freezerManifestName)
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(blob); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
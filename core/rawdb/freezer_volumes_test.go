@@ -0,0 +1,225 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorievm/go-gori/ethdb"
+)
+
+// appendTestItems appends compressible items [from, to) to all tables.
+func appendTestItems(t *testing.T, f *Freezer, from, to int, size int) {
+	t.Helper()
+
+	_, err := f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := from; i < to; i++ {
+			for kind := range f.tables {
+				if err := op.AppendRaw(kind, uint64(i), getChunk(size, i)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("append failed:", err)
+	}
+}
+
+// checkTestItems verifies that the items [from, to) of the given table match
+// the ones written by appendTestItems.
+func checkTestItems(t *testing.T, f *Freezer, kind string, from, to int, size int) {
+	t.Helper()
+
+	for i := from; i < to; i++ {
+		blob, err := f.Ancient(kind, uint64(i))
+		if err != nil {
+			t.Fatalf("failed to retrieve item %d: %v", i, err)
+		}
+		if !bytes.Equal(blob, getChunk(size, i)) {
+			t.Fatalf("item %d mismatch: have %x", i, blob)
+		}
+	}
+}
+
+func TestFreezerZstd(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tables := map[string]bool{"zstd": false}
+
+	f, err := NewFreezerWithOptions(dir, "", false, 2049, tables, FreezerOptions{Zstd: true})
+	if err != nil {
+		t.Fatal("can't open freezer:", err)
+	}
+	appendTestItems(t, f, 0, 100, 1024)
+	checkTestItems(t, f, "zstd", 0, 100, 1024)
+
+	// Compressed items are well below the raw size, but the byte limit of range
+	// reads applies to the decompressed items.
+	if size, _ := f.AncientSize("zstd"); size >= 100*1024 {
+		t.Fatalf("items not compressed: size %d", size)
+	}
+	items, err := f.AncientRange("zstd", 0, 100, 10*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 10 {
+		t.Fatalf("wrong number of items in limited range: have %d, want 10", len(items))
+	}
+	f.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, "zstd.zidx")); err != nil {
+		t.Fatal("zstd index missing:", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "zstd.0000.zdat")); err != nil {
+		t.Fatal("zstd data file missing:", err)
+	}
+	// Reopen without zstd, the table must stay zstd compressed
+	f, err = NewFreezer(dir, "", false, 2049, tables)
+	if err != nil {
+		t.Fatal("can't reopen freezer:", err)
+	}
+	defer f.Close()
+
+	appendTestItems(t, f, 100, 120, 1024)
+	checkTestItems(t, f, "zstd", 0, 120, 1024)
+	if _, err := os.Stat(filepath.Join(dir, "zstd.cidx")); !os.IsNotExist(err) {
+		t.Fatal("snappy index created for zstd table")
+	}
+}
+
+func TestFreezerZstdExistingSnappy(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tables := map[string]bool{"snappy": false, "raw": true}
+
+	f, err := NewFreezer(dir, "", false, 2049, tables)
+	if err != nil {
+		t.Fatal("can't open freezer:", err)
+	}
+	appendTestItems(t, f, 0, 10, 256)
+	f.Close()
+
+	// Enabling zstd must neither convert existing nor uncompressed tables
+	f, err = NewFreezerWithOptions(dir, "", false, 2049, tables, FreezerOptions{Zstd: true})
+	if err != nil {
+		t.Fatal("can't reopen freezer:", err)
+	}
+	defer f.Close()
+
+	appendTestItems(t, f, 10, 20, 256)
+	checkTestItems(t, f, "snappy", 0, 20, 256)
+	checkTestItems(t, f, "raw", 0, 20, 256)
+
+	for _, name := range []string{"snappy.zidx", "raw.zidx"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Fatalf("unexpected zstd index %s", name)
+		}
+	}
+}
+
+func TestFreezerVolumes(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir    = filepath.Join(t.TempDir(), "chain")
+		vol1   = t.TempDir()
+		vol2   = t.TempDir()
+		tables = map[string]bool{"test": true}
+		opts   = FreezerOptions{Volumes: []FreezerVolume{
+			{Path: vol1, Limit: 200},
+			{Path: vol2},
+		}}
+	)
+	// Two items per data file, the first volume takes two files
+	f, err := NewFreezerWithOptions(dir, "", false, 100, tables, opts)
+	if err != nil {
+		t.Fatal("can't open freezer:", err)
+	}
+	appendTestItems(t, f, 0, 10, 50)
+	checkTestItems(t, f, "test", 0, 10, 50)
+	f.Close()
+
+	locations := map[int]string{0: vol1, 1: vol1, 2: vol2, 3: vol2, 4: vol2}
+	for num, vol := range locations {
+		name := fmt.Sprintf("test.%04d.rdat", num)
+		if _, err := os.Stat(filepath.Join(vol, "chain", name)); err != nil {
+			t.Fatalf("data file %s not on volume %s: %v", name, vol, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Fatalf("data file %s in freezer directory", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "test.ridx")); err != nil {
+		t.Fatal("index not in freezer directory:", err)
+	}
+	// Reopen without volumes, the manifest must locate the files
+	f, err = NewFreezer(dir, "", false, 100, tables)
+	if err != nil {
+		t.Fatal("can't reopen freezer:", err)
+	}
+	checkTestItems(t, f, "test", 0, 10, 50)
+
+	// Files created without volumes stay in the freezer directory
+	appendTestItems(t, f, 10, 12, 50)
+	if _, err := os.Stat(filepath.Join(dir, "test.0005.rdat")); err != nil {
+		t.Fatal("new data file not in freezer directory:", err)
+	}
+	// Truncation removes the files from the volumes and the manifest
+	if _, err := f.TruncateHead(5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.TruncateTail(4); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	for num := range locations {
+		name := fmt.Sprintf("test.%04d.rdat", num)
+		_, err := os.Stat(filepath.Join(locations[num], "chain", name))
+		if exist := err == nil; exist != (num == 2) {
+			t.Fatalf("data file %s existence mismatch: have %v, want %v", name, exist, num == 2)
+		}
+	}
+	volumes, err := newFreezerVolumes(dir, nil, 100, true)
+	if err != nil {
+		t.Fatal("can't load manifest:", err)
+	}
+	if len(volumes.files) != 1 || volumes.files["test.0002.rdat"] != filepath.Join(vol2, "chain") {
+		t.Fatalf("wrong manifest after truncation: %v", volumes.files)
+	}
+	// Reopen with volumes, the remaining items must still be readable
+	f, err = NewFreezerWithOptions(dir, "", false, 100, tables, opts)
+	if err != nil {
+		t.Fatal("can't reopen freezer:", err)
+	}
+	defer f.Close()
+
+	checkTestItems(t, f, "test", 4, 5, 50)
+	appendTestItems(t, f, 5, 8, 50)
+	checkTestItems(t, f, "test", 4, 8, 50)
+	if _, err := os.Stat(filepath.Join(vol1, "chain", "test.0003.rdat")); err != nil {
+		t.Fatal("data file not placed on freed volume:", err)
+	}
+}
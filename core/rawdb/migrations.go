@@ -0,0 +1,139 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/log"
+)
+
+// Migration is a step of the database schema, upgrading a database from the
+// previous version to Version.
+type Migration struct {
+	Version uint64 // Schema version of the database after the migration
+	Name    string // Short description of the migration for logging
+
+	// Run performs the migration. Long running migrations should regularly persist
+	// their progress, from which they are resumed if the node is interrupted.
+	Run func(db ethdb.Database, progress *MigrationProgress) error
+}
+
+// schemaMigrations is the registry of the schema migrations, in the order of the
+// versions they upgrade to. A database can only be upgraded if there's a
+// migration for every version between its own and the supported one.
+//
+// Versions up to 8 were introduced before migrations, databases using them have
+// to be resynced.
+var schemaMigrations []Migration
+
+// MigrationProgress tracks the progress of a running schema migration.
+type MigrationProgress struct {
+	db        ethdb.KeyValueWriter
+	migration *Migration
+	marker    []byte
+
+	start  time.Time
+	logged time.Time
+}
+
+// Marker returns the marker persisted by an interrupted run of the migration, or
+// nil if the migration starts from scratch.
+func (p *MigrationProgress) Marker() []byte {
+	return p.marker
+}
+
+// Checkpoint atomically writes the batch of migrated data together with the
+// marker to resume the migration from. The batch may be nil to only update the
+// marker.
+func (p *MigrationProgress) Checkpoint(batch ethdb.Batch, marker []byte) error {
+	if batch == nil {
+		WriteMigrationProgress(p.db, p.migration.Version, marker)
+	} else {
+		WriteMigrationProgress(batch, p.migration.Version, marker)
+		if err := batch.Write(); err != nil {
+			return err
+		}
+	}
+	p.marker = marker
+
+	if time.Since(p.logged) > 8*time.Second {
+		log.Info("Migrating database schema", "version", p.migration.Version, "migration", p.migration.Name, "marker", fmt.Sprintf("%x", marker), "elapsed", common.PrettyDuration(time.Since(p.start)))
+		p.logged = time.Now()
+	}
+	return nil
+}
+
+// MigrateSchema upgrades the database schema to the given version, running the
+// registered migrations in order. Fresh databases are initialized to the version
+// directly, databases of newer or unsupported versions are rejected.
+func MigrateSchema(db ethdb.Database, version uint64) error {
+	return migrateSchema(db, version, schemaMigrations)
+}
+
+// migrateSchema upgrades the database schema to the given version with the given
+// ordered migrations.
+func migrateSchema(db ethdb.Database, version uint64, migrations []Migration) error {
+	current := ReadDatabaseVersion(db)
+	if current == nil {
+		WriteDatabaseVersion(db, version)
+		return nil
+	}
+	if *current > version {
+		return fmt.Errorf("database version is v%d, only up to v%d is supported", *current, version)
+	}
+	for *current < version {
+		var migration *Migration
+		for i := range migrations {
+			if migrations[i].Version == *current+1 {
+				migration = &migrations[i]
+				break
+			}
+		}
+		if migration == nil {
+			return fmt.Errorf("no migration from database version v%d to v%d, resync required", *current, *current+1)
+		}
+		progress := &MigrationProgress{
+			db:        db,
+			migration: migration,
+			marker:    ReadMigrationProgress(db, migration.Version),
+			start:     time.Now(),
+			logged:    time.Now(),
+		}
+		if progress.marker != nil {
+			log.Info("Resuming database schema migration", "version", migration.Version, "migration", migration.Name, "marker", fmt.Sprintf("%x", progress.marker))
+		} else {
+			log.Info("Starting database schema migration", "version", migration.Version, "migration", migration.Name)
+		}
+		if err := migration.Run(db, progress); err != nil {
+			return fmt.Errorf("database migration to v%d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+		// Bump the version and drop the progress marker atomically
+		batch := db.NewBatch()
+		WriteDatabaseVersion(batch, migration.Version)
+		DeleteMigrationProgress(batch)
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		log.Info("Migrated database schema", "version", migration.Version, "migration", migration.Name, "elapsed", common.PrettyDuration(time.Since(progress.start)))
+		*current = migration.Version
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/gorievm/go-gori/ethdb"
+)
+
+func TestSchemaMigrationsOrdered(t *testing.T) {
+	for i := 1; i < len(schemaMigrations); i++ {
+		if schemaMigrations[i].Version != schemaMigrations[i-1].Version+1 {
+			t.Errorf("migration %q to v%d doesn't follow v%d", schemaMigrations[i].Name, schemaMigrations[i].Version, schemaMigrations[i-1].Version)
+		}
+	}
+}
+
+func TestMigrateSchemaVersions(t *testing.T) {
+	// Fresh databases are initialized without migrations
+	db := NewMemoryDatabase()
+	if err := migrateSchema(db, 10, nil); err != nil {
+		t.Fatal("failed to initialize fresh database:", err)
+	}
+	if version := ReadDatabaseVersion(db); version == nil || *version != 10 {
+		t.Fatalf("wrong version of fresh database: %v", version)
+	}
+	// Newer databases are rejected
+	if err := migrateSchema(db, 9, nil); err == nil {
+		t.Fatal("accepted newer database")
+	}
+	// Older databases without a migration path are rejected
+	WriteDatabaseVersion(db, 7)
+	migrations := []Migration{{Version: 9, Name: "noop", Run: func(ethdb.Database, *MigrationProgress) error { return nil }}}
+	if err := migrateSchema(db, 9, migrations); err == nil {
+		t.Fatal("migrated database without migration to v8")
+	}
+	if version := ReadDatabaseVersion(db); *version != 7 {
+		t.Fatalf("version changed by failed migration: v%d", *version)
+	}
+	// Migrations run in order up to the requested version
+	var ran []uint64
+	migrations = nil
+	for v := uint64(8); v <= 11; v++ {
+		v := v
+		migrations = append(migrations, Migration{Version: v, Name: "test", Run: func(ethdb.Database, *MigrationProgress) error {
+			ran = append(ran, v)
+			return nil
+		}})
+	}
+	if err := migrateSchema(db, 10, migrations); err != nil {
+		t.Fatal("migration failed:", err)
+	}
+	if len(ran) != 3 || ran[0] != 8 || ran[1] != 9 || ran[2] != 10 {
+		t.Fatalf("wrong migrations run: %v", ran)
+	}
+	if version := ReadDatabaseVersion(db); *version != 10 {
+		t.Fatalf("wrong version after migration: v%d", *version)
+	}
+}
+
+func TestMigrateSchemaResume(t *testing.T) {
+	db := NewMemoryDatabase()
+	WriteDatabaseVersion(db, 8)
+
+	// The migration copies numbered items, failing on the first run midway
+	var (
+		failAt  = uint64(5)
+		resumes []uint64
+	)
+	migrations := []Migration{{
+		Version: 9,
+		Name:    "copy items",
+		Run: func(db ethdb.Database, progress *MigrationProgress) error {
+			var next uint64
+			if marker := progress.Marker(); marker != nil {
+				next = binary.BigEndian.Uint64(marker)
+			}
+			resumes = append(resumes, next)
+			for ; next < 10; next++ {
+				if next == failAt {
+					return errors.New("interrupted")
+				}
+				batch := db.NewBatch()
+				batch.Put([]byte{'m', byte(next)}, []byte{byte(next)})
+				if err := progress.Checkpoint(batch, binary.BigEndian.AppendUint64(nil, next+1)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}}
+	if err := migrateSchema(db, 9, migrations); err == nil {
+		t.Fatal("interrupted migration succeeded")
+	}
+	if version := ReadDatabaseVersion(db); *version != 8 {
+		t.Fatalf("version bumped by interrupted migration: v%d", *version)
+	}
+	if marker := ReadMigrationProgress(db, 9); binary.BigEndian.Uint64(marker) != 5 {
+		t.Fatalf("wrong persisted progress: %x", marker)
+	}
+	// Progress of other migrations is not resumed from
+	if marker := ReadMigrationProgress(db, 10); marker != nil {
+		t.Fatalf("progress of other migration returned: %x", marker)
+	}
+	failAt = 10
+	if err := migrateSchema(db, 9, migrations); err != nil {
+		t.Fatal("resumed migration failed:", err)
+	}
+	if len(resumes) != 2 || resumes[0] != 0 || resumes[1] != 5 {
+		t.Fatalf("wrong resume points: %v", resumes)
+	}
+	for i := 0; i < 10; i++ {
+		if val, err := db.Get([]byte{'m', byte(i)}); err != nil || val[0] != byte(i) {
+			t.Fatalf("item %d not migrated: %v", i, err)
+		}
+	}
+	if version := ReadDatabaseVersion(db); *version != 9 {
+		t.Fatalf("wrong version after migration: v%d", *version)
+	}
+	if has, _ := db.Has(databaseMigrationKey); has {
+		t.Fatal("migration progress not removed")
+	}
+}
@@ -31,6 +31,9 @@ var (
 	// databaseVersionKey tracks the current database version.
 	databaseVersionKey = []byte("DatabaseVersion")
 
+	// databaseMigrationKey tracks the progress of an interrupted schema migration.
+	databaseMigrationKey = []byte("DatabaseMigration")
+
 	// headHeaderKey tracks the latest known header's hash.
 	headHeaderKey = []byte("LastHeader")
 
@@ -79,29 +82,55 @@ var (
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
+	// addressIndexTailKey tracks the oldest block whose transactions have been
+	// indexed by address.
+	addressIndexTailKey = []byte("TransactionAddressIndexTail")
+
+	// filterMapsRangeKey tracks the block range covered by the log index.
+	filterMapsRangeKey = []byte("FilterMapsRange")
+
+	// bloomBitsRetiredKey flags that the bloom bits index was superseded by the
+	// log index and its data deleted.
+	bloomBitsRetiredKey = []byte("BloomBitsRetired")
+
 	// fastTxLookupLimitKey tracks the transaction lookup limit during fast sync.
 	fastTxLookupLimitKey = []byte("FastTransactionLookupLimit")
 
 	// badBlockKey tracks the list of bad blocks seen by local
 	badBlockKey = []byte("InvalidBlock")
 
+	// badBlockReportPrefix + hash -> diagnostics report of a bad block
+	badBlockReportPrefix = []byte("InvalidBlockReport-")
+
 	// uncleanShutdownKey tracks the list of local crashes
 	uncleanShutdownKey = []byte("unclean-shutdown") // config prefix for the db
 
 	// transitionStatusKey tracks the eth2 transition status.
 	transitionStatusKey = []byte("eth2-transition")
 
+	// exporterCheckpointKey tracks the last block delivered by the chain event exporter.
+	exporterCheckpointKey = []byte("ExporterCheckpoint")
+
+	// forkOverrideKey tracks the last signed fork override applied to the chain config.
+	forkOverrideKey = []byte("ForkOverride")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
 	headerHashSuffix   = []byte("n") // headerPrefix + num (uint64 big endian) + headerHashSuffix -> hash
+	headerSkipSuffix   = []byte("s") // headerPrefix + num (uint64 big endian) + hash + headerSkipSuffix -> parent hash + skip-list ancestor hash
 	headerNumberPrefix = []byte("H") // headerNumberPrefix + hash -> num (uint64 big endian)
 
 	blockBodyPrefix     = []byte("b") // blockBodyPrefix + num (uint64 big endian) + hash -> block body
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
 
+	receiptAccountingPrefix = []byte("x") // receiptAccountingPrefix + num (uint64 big endian) + hash -> receipt accounting
+
 	txLookupPrefix        = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
+	addressTxPrefix       = []byte("X") // addressTxPrefix + address + num (uint64 big endian) + index (uint32 big endian) -> transaction hash
 	bloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
+	filterMapRowPrefix    = []byte("M") // filterMapRowPrefix + map index (uint32 big endian) + row index (uint32 big endian) -> filter map row
+	filterMapBlockPrefix  = []byte("p") // filterMapBlockPrefix + num (uint64 big endian) -> first log value index + block hash
 	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
 	CodePrefix            = []byte("c") // CodePrefix + code hash -> account code
@@ -163,6 +192,11 @@ func headerTDKey(number uint64, hash common.Hash) []byte {
 	return append(headerKey(number, hash), headerTDSuffix...)
 }
 
+// headerSkipKey = headerPrefix + num (uint64 big endian) + hash + headerSkipSuffix
+func headerSkipKey(number uint64, hash common.Hash) []byte {
+	return append(headerKey(number, hash), headerSkipSuffix...)
+}
+
 // headerHashKey = headerPrefix + num (uint64 big endian) + headerHashSuffix
 func headerHashKey(number uint64) []byte {
 	return append(append(headerPrefix, encodeBlockNumber(number)...), headerHashSuffix...)
@@ -183,11 +217,38 @@ func blockReceiptsKey(number uint64, hash common.Hash) []byte {
 	return append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
 
+// receiptAccountingKey = receiptAccountingPrefix + num (uint64 big endian) + hash
+func receiptAccountingKey(number uint64, hash common.Hash) []byte {
+	return append(append(receiptAccountingPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
 // txLookupKey = txLookupPrefix + hash
 func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
 }
 
+// addressTxKeyPrefix = addressTxPrefix + address
+func addressTxKeyPrefix(address common.Address) []byte {
+	return append(addressTxPrefix, address.Bytes()...)
+}
+
+// addressTxKey = addressTxPrefix + address + num (uint64 big endian) + index (uint32 big endian)
+func addressTxKey(address common.Address, number uint64, index uint32) []byte {
+	key := append(addressTxKeyPrefix(address), encodeBlockNumber(number)...)
+	return binary.BigEndian.AppendUint32(key, index)
+}
+
+// filterMapRowKey = filterMapRowPrefix + map index (uint32 big endian) + row index (uint32 big endian)
+func filterMapRowKey(mapIndex, rowIndex uint32) []byte {
+	key := binary.BigEndian.AppendUint32(append([]byte{}, filterMapRowPrefix...), mapIndex)
+	return binary.BigEndian.AppendUint32(key, rowIndex)
+}
+
+// filterMapBlockKey = filterMapBlockPrefix + num (uint64 big endian)
+func filterMapBlockKey(number uint64) []byte {
+	return append(filterMapBlockPrefix, encodeBlockNumber(number)...)
+}
+
 // accountSnapshotKey = SnapshotAccountPrefix + hash
 func accountSnapshotKey(hash common.Hash) []byte {
 	return append(SnapshotAccountPrefix, hash.Bytes()...)
@@ -247,6 +308,11 @@ func genesisStateSpecKey(hash common.Hash) []byte {
 	return append(genesisPrefix, hash.Bytes()...)
 }
 
+// badBlockReportKey = badBlockReportPrefix + hash
+func badBlockReportKey(hash common.Hash) []byte {
+	return append(badBlockReportPrefix, hash.Bytes()...)
+}
+
 // stateIDKey = stateIDPrefix + root (32 bytes)
 func stateIDKey(root common.Hash) []byte {
 	return append(stateIDPrefix, root.Bytes()...)
@@ -18,8 +18,27 @@ package core
 
 import (
 	"runtime"
+	"sync"
 
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/lru"
 	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/metrics"
+)
+
+// senderCacheSize is the number of recovered transaction senders to retain,
+// allowing different instances of the same transaction (e.g. one in the pool
+// and one in an imported block) to only be recovered once.
+const senderCacheSize = 32768
+
+// senderSyncThreshold is the minimum number of transactions to recover on the
+// background threads when waiting for the result. Smaller batches are recovered
+// on the calling thread, avoiding the scheduling overhead.
+const senderSyncThreshold = 16
+
+var (
+	senderCacheHitMeter  = metrics.NewRegisteredMeter("core/senders/cache/hit", nil)
+	senderCacheMissMeter = metrics.NewRegisteredMeter("core/senders/cache/miss", nil)
 )
 
 // SenderCacher is a concurrent transaction sender recoverer and cacher.
@@ -35,6 +54,14 @@ type txSenderCacherRequest struct {
 	signer types.Signer
 	txs    []*types.Transaction
 	inc    int
+	done   *sync.WaitGroup // Optional notifier for synchronous recoveries
+}
+
+// cachedSender is a recovered transaction sender along with the signer which
+// derived it.
+type cachedSender struct {
+	signer types.Signer
+	from   common.Address
 }
 
 // txSenderCacher is a helper structure to concurrently ecrecover transaction
@@ -42,6 +69,7 @@ type txSenderCacherRequest struct {
 type txSenderCacher struct {
 	threads int
 	tasks   chan *txSenderCacherRequest
+	senders *lru.Cache[common.Hash, cachedSender] // Recently recovered senders, shared across callers
 }
 
 // newTxSenderCacher creates a new transaction sender background cacher and starts
@@ -50,6 +78,7 @@ func newTxSenderCacher(threads int) *txSenderCacher {
 	cacher := &txSenderCacher{
 		tasks:   make(chan *txSenderCacherRequest, threads),
 		threads: threads,
+		senders: lru.NewCache[common.Hash, cachedSender](senderCacheSize),
 	}
 	for i := 0; i < threads; i++ {
 		go cacher.cache()
@@ -62,31 +91,73 @@ func newTxSenderCacher(threads int) *txSenderCacher {
 func (cacher *txSenderCacher) cache() {
 	for task := range cacher.tasks {
 		for i := 0; i < len(task.txs); i += task.inc {
-			types.Sender(task.signer, task.txs[i])
+			cacher.recover(task.signer, task.txs[i])
+		}
+		if task.done != nil {
+			task.done.Done()
 		}
 	}
 }
 
-// Recover recovers the senders from a batch of transactions and caches them
-// back into the same data structures. There is no validation being done, nor
-// any reaction to invalid signatures. That is up to calling code later.
-func (cacher *txSenderCacher) Recover(signer types.Signer, txs []*types.Transaction) {
-	// If there's nothing to recover, abort
-	if len(txs) == 0 {
+// recover derives the sender of a single transaction, using the shared cache of
+// recently recovered senders if possible.
+func (cacher *txSenderCacher) recover(signer types.Signer, tx *types.Transaction) {
+	hash := tx.Hash()
+	if cached, ok := cacher.senders.Get(hash); ok && cached.signer.Equal(signer) {
+		types.SetSender(signer, tx, cached.from)
+		senderCacheHitMeter.Mark(1)
 		return
 	}
+	senderCacheMissMeter.Mark(1)
+	if from, err := types.Sender(signer, tx); err == nil {
+		cacher.senders.Add(hash, cachedSender{signer: signer, from: from})
+	}
+}
+
+// schedule splits up the recovery of a batch of transactions between the
+// background threads.
+func (cacher *txSenderCacher) schedule(signer types.Signer, txs []*types.Transaction, done *sync.WaitGroup) {
 	// Ensure we have meaningful task sizes and schedule the recoveries
 	tasks := cacher.threads
 	if len(txs) < tasks*4 {
 		tasks = (len(txs) + 3) / 4
 	}
+	if done != nil {
+		done.Add(tasks)
+	}
 	for i := 0; i < tasks; i++ {
 		cacher.tasks <- &txSenderCacherRequest{
 			signer: signer,
 			txs:    txs[i:],
 			inc:    tasks,
+			done:   done,
+		}
+	}
+}
+
+// Recover recovers the senders from a batch of transactions and caches them
+// back into the same data structures. There is no validation being done, nor
+// any reaction to invalid signatures. That is up to calling code later.
+func (cacher *txSenderCacher) Recover(signer types.Signer, txs []*types.Transaction) {
+	// If there's nothing to recover, abort
+	if len(txs) == 0 {
+		return
+	}
+	cacher.schedule(signer, txs, nil)
+}
+
+// RecoverSync is like Recover, but waits until the senders of all the given
+// transactions are recovered. Small batches are recovered inline.
+func (cacher *txSenderCacher) RecoverSync(signer types.Signer, txs []*types.Transaction) {
+	if len(txs) < senderSyncThreshold {
+		for _, tx := range txs {
+			cacher.recover(signer, tx)
 		}
+		return
 	}
+	var done sync.WaitGroup
+	cacher.schedule(signer, txs, &done)
+	done.Wait()
 }
 
 // RecoverFromBlocks recovers the senders from a batch of blocks and caches them
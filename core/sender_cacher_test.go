@@ -0,0 +1,165 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"runtime"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/params"
+	"github.com/gorievm/go-gori/rlp"
+)
+
+// makeSenderTxs creates a batch of transactions signed by a set of keys.
+func makeSenderTxs(n int) ([]*types.Transaction, []*ecdsa.PrivateKey) {
+	var (
+		signer = types.LatestSigner(params.TestChainConfig)
+		keys   = make([]*ecdsa.PrivateKey, 8)
+		txs    = make([]*types.Transaction, n)
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	for i := range txs {
+		txs[i] = types.MustSignNewTx(keys[i%len(keys)], signer, &types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			Nonce:     uint64(i),
+			To:        &common.Address{0x01},
+			Gas:       params.TxGas,
+			GasFeeCap: big.NewInt(params.InitialBaseFee),
+			GasTipCap: big.NewInt(1),
+		})
+	}
+	return txs, keys
+}
+
+// copyTxs creates fresh instances of the given transactions, without any of the
+// senders cached.
+func copyTxs(txs []*types.Transaction) []*types.Transaction {
+	copies := make([]*types.Transaction, len(txs))
+	for i, tx := range txs {
+		blob, _ := tx.MarshalBinary()
+		copies[i] = new(types.Transaction)
+		copies[i].UnmarshalBinary(blob)
+	}
+	return copies
+}
+
+// Tests that synchronous recoveries derive the correct senders, and that the
+// senders are shared between different instances of the same transactions.
+func TestSenderCacherRecoverSync(t *testing.T) {
+	var (
+		signer    = types.LatestSigner(params.TestChainConfig)
+		cacher    = newTxSenderCacher(4)
+		txs, keys = makeSenderTxs(100)
+	)
+	for _, batch := range [][]*types.Transaction{txs[:senderSyncThreshold-1], txs} {
+		cacher.RecoverSync(signer, batch)
+		for i, tx := range batch {
+			if want := crypto.PubkeyToAddress(keys[i%len(keys)].PublicKey); !cachedSenderIs(tx, want) {
+				t.Fatalf("tx %d: sender not cached", i)
+			}
+		}
+	}
+	// Recovering fresh copies should be served from the shared cache
+	copies := copyTxs(txs)
+	for _, tx := range copies {
+		if _, ok := cacher.senders.Get(tx.Hash()); !ok {
+			t.Fatalf("tx %x: sender missing from shared cache", tx.Hash())
+		}
+	}
+	cacher.RecoverSync(signer, copies)
+	for i, tx := range copies {
+		if want := crypto.PubkeyToAddress(keys[i%len(keys)].PublicKey); !cachedSenderIs(tx, want) {
+			t.Fatalf("copy %d: sender not cached", i)
+		}
+	}
+	// Transactions with invalid signatures must not be cached
+	blob, _ := rlp.EncodeToBytes([]interface{}{uint64(0), big.NewInt(1), params.TxGas, common.Address{0x01}, big.NewInt(0), []byte{}, big.NewInt(27), big.NewInt(0), big.NewInt(0)})
+	invalid := new(types.Transaction)
+	if err := rlp.DecodeBytes(blob, invalid); err != nil {
+		t.Fatalf("failed to decode invalid transaction: %v", err)
+	}
+	cacher.RecoverSync(signer, []*types.Transaction{invalid})
+	if _, ok := cacher.senders.Get(invalid.Hash()); ok {
+		t.Errorf("invalid transaction cached")
+	}
+}
+
+// cachedSenderIs checks whether the sender derived for a transaction matches the
+// expected one.
+func cachedSenderIs(tx *types.Transaction, want common.Address) bool {
+	have, err := types.Sender(types.LatestSigner(params.TestChainConfig), tx)
+	return err == nil && have == want
+}
+
+func BenchmarkSenderRecoverySerial(b *testing.B) {
+	signer := types.LatestSigner(params.TestChainConfig)
+	txs, _ := makeSenderTxs(1024)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		batch := copyTxs(txs)
+		b.StartTimer()
+
+		for _, tx := range batch {
+			types.Sender(signer, tx)
+		}
+	}
+}
+
+func BenchmarkSenderRecoveryParallel(b *testing.B) {
+	var (
+		signer = types.LatestSigner(params.TestChainConfig)
+		cacher = newTxSenderCacher(runtime.NumCPU())
+	)
+	txs, _ := makeSenderTxs(1024)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		batch := copyTxs(txs)
+		cacher.senders.Purge()
+		b.StartTimer()
+
+		cacher.RecoverSync(signer, batch)
+	}
+}
+
+func BenchmarkSenderRecoveryCached(b *testing.B) {
+	var (
+		signer = types.LatestSigner(params.TestChainConfig)
+		cacher = newTxSenderCacher(runtime.NumCPU())
+	)
+	txs, _ := makeSenderTxs(1024)
+	cacher.RecoverSync(signer, txs)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		batch := copyTxs(txs)
+		b.StartTimer()
+
+		cacher.RecoverSync(signer, batch)
+	}
+}
@@ -134,3 +134,9 @@ func (al *accessList) DeleteSlot(address common.Address, slot common.Hash) {
 func (al *accessList) DeleteAddress(address common.Address) {
 	delete(al.addresses, address)
 }
+
+// prewarmedSlot is a storage slot pre-warmed by the access list of a transaction.
+type prewarmedSlot struct {
+	addr common.Address
+	slot common.Hash
+}
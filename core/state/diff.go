@@ -0,0 +1,95 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
+	"golang.org/x/exp/slices"
+)
+
+// StateDiff is the set of state changes made on top of the pre-state, in the
+// encoding used by the snapshot: accounts are in slim RLP format, storage slots
+// are keyed by the hash of their key and hold the RLP encoding of the prefix
+// zero trimmed value. Empty values denote absent entries.
+type StateDiff struct {
+	Accounts []*AccountDiff `json:"accounts"`
+}
+
+// AccountDiff is the change of a single account.
+type AccountDiff struct {
+	Address common.Address `json:"address"`
+	Prev    hexutil.Bytes  `json:"prev"`
+	Post    hexutil.Bytes  `json:"post"`
+	Storage []*SlotDiff    `json:"storage,omitempty"`
+}
+
+// SlotDiff is the change of a single storage slot.
+type SlotDiff struct {
+	Hash common.Hash   `json:"hash"`
+	Prev hexutil.Bytes `json:"prev"`
+	Post hexutil.Bytes `json:"post"`
+}
+
+// Diff returns the changes made to the pre-state so far. The mutations have
+// to be hashed via IntermediateRoot beforehand, the storage slots wiped by the
+// destruction of accounts are not included.
+func (s *StateDB) Diff() *StateDiff {
+	addrs := make(map[common.Address]struct{}, len(s.stateObjectsDirty)+len(s.stateObjectsDestruct))
+	for addr := range s.stateObjectsDirty {
+		addrs[addr] = struct{}{}
+	}
+	for addr := range s.stateObjectsDestruct {
+		addrs[addr] = struct{}{}
+	}
+	diff := new(StateDiff)
+	for addr := range addrs {
+		account := &AccountDiff{Address: addr}
+
+		// The original account is the one destructed first, if any
+		obj := s.stateObjects[addr]
+		if prev, destructed := s.stateObjectsDestruct[addr]; destructed {
+			if prev != nil {
+				account.Prev = types.SlimAccountRLP(*prev)
+			}
+		} else if obj != nil && obj.origin != nil {
+			account.Prev = types.SlimAccountRLP(*obj.origin)
+		}
+		if obj != nil && !obj.deleted {
+			account.Post = types.SlimAccountRLP(obj.data)
+		}
+		post := s.storages[crypto.Keccak256Hash(addr[:])]
+		for hash, prev := range s.storagesOrigin[addr] {
+			account.Storage = append(account.Storage, &SlotDiff{Hash: hash, Prev: prev, Post: post[hash]})
+		}
+		slices.SortFunc(account.Storage, func(a, b *SlotDiff) int {
+			return bytes.Compare(a.Hash[:], b.Hash[:])
+		})
+		if bytes.Equal(account.Prev, account.Post) && len(account.Storage) == 0 {
+			continue
+		}
+		diff.Accounts = append(diff.Accounts, account)
+	}
+	slices.SortFunc(diff.Accounts, func(a, b *AccountDiff) int {
+		return bytes.Compare(a.Address[:], b.Address[:])
+	})
+	return diff
+}
@@ -124,9 +124,23 @@ func (d iterativeDump) OnRoot(root common.Hash) {
 	}{root})
 }
 
-// DumpToCollector iterates the state according to the given options and inserts
-// the items into a collector for aggregation or serialization.
-func (s *StateDB) DumpToCollector(c DumpCollector, conf *DumpConfig) (nextKey []byte) {
+// DumpAccountFn is invoked for each account visited by a streaming dump. The
+// address is nil if its preimage is unknown. Returning an error aborts the dump.
+type DumpAccountFn func(addr *common.Address, account DumpAccount) error
+
+// DumpStorageFn is invoked for each storage slot of the account last passed to
+// the DumpAccountFn. Returning an error aborts the dump.
+type DumpStorageFn func(addr *common.Address, key common.Hash, value []byte) error
+
+// StreamDump iterates the state according to the given options, passing each
+// account to onAccount followed by its storage slots to onStorage, without
+// retaining any of them. The storage is not iterated if it's skipped by the
+// options or onStorage is nil.
+//
+// The hashed key of the next account to dump is returned if the iteration was
+// capped by the options, it can be used as the start of a subsequent dump to
+// resume. Nil is returned if the whole state was iterated.
+func (s *StateDB) StreamDump(conf *DumpConfig, onAccount DumpAccountFn, onStorage DumpStorageFn) ([]byte, error) {
 	// Sanitize the input to allow nil configs
 	if conf == nil {
 		conf = new(DumpConfig)
@@ -138,17 +152,16 @@ func (s *StateDB) DumpToCollector(c DumpCollector, conf *DumpConfig) (nextKey []
 		logged           = time.Now()
 	)
 	log.Info("Trie dumping started", "root", s.trie.Hash())
-	c.OnRoot(s.trie.Hash())
 
 	trieIt, err := s.trie.NodeIterator(conf.Start)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 	it := trie.NewIterator(trieIt)
 	for it.Next() {
 		var data types.StateAccount
 		if err := rlp.DecodeBytes(it.Value, &data); err != nil {
-			panic(err)
+			return nil, fmt.Errorf("failed to decode account %x: %v", it.Key, err)
 		}
 		account := DumpAccount{
 			Balance:   data.Balance.String(),
@@ -175,29 +188,14 @@ func (s *StateDB) DumpToCollector(c DumpCollector, conf *DumpConfig) (nextKey []
 		if !conf.SkipCode {
 			account.Code = obj.Code()
 		}
-		if !conf.SkipStorage {
-			account.Storage = make(map[common.Hash]string)
-			tr, err := obj.getTrie()
-			if err != nil {
-				log.Error("Failed to load storage trie", "err", err)
-				continue
-			}
-			trieIt, err := tr.NodeIterator(nil)
-			if err != nil {
-				log.Error("Failed to create trie iterator", "err", err)
-				continue
-			}
-			storageIt := trie.NewIterator(trieIt)
-			for storageIt.Next() {
-				_, content, _, err := rlp.Split(storageIt.Value)
-				if err != nil {
-					log.Error("Failed to decode the value returned by iterator", "error", err)
-					continue
-				}
-				account.Storage[common.BytesToHash(s.trie.GetKey(storageIt.Key))] = common.Bytes2Hex(content)
+		if err := onAccount(address, account); err != nil {
+			return nil, err
+		}
+		if !conf.SkipStorage && onStorage != nil {
+			if err := s.streamStorage(obj, address, onStorage); err != nil {
+				return nil, err
 			}
 		}
-		c.OnAccount(address, account)
 		accounts++
 		if time.Since(logged) > 8*time.Second {
 			log.Info("Trie dumping in progress", "at", it.Key, "accounts", accounts,
@@ -206,17 +204,84 @@ func (s *StateDB) DumpToCollector(c DumpCollector, conf *DumpConfig) (nextKey []
 		}
 		if conf.Max > 0 && accounts >= conf.Max {
 			if it.Next() {
-				nextKey = it.Key
+				return it.Key, nil
 			}
 			break
 		}
 	}
+	if it.Err != nil {
+		return nil, it.Err
+	}
 	if missingPreimages > 0 {
 		log.Warn("Dump incomplete due to missing preimages", "missing", missingPreimages)
 	}
 	log.Info("Trie dumping complete", "accounts", accounts,
 		"elapsed", common.PrettyDuration(time.Since(start)))
 
+	return nil, nil
+}
+
+// streamStorage iterates the storage slots of the given account, passing each
+// of them to the callback.
+func (s *StateDB) streamStorage(obj *stateObject, addr *common.Address, onStorage DumpStorageFn) error {
+	tr, err := obj.getTrie()
+	if err != nil {
+		log.Error("Failed to load storage trie", "err", err)
+		return nil
+	}
+	trieIt, err := tr.NodeIterator(nil)
+	if err != nil {
+		log.Error("Failed to create trie iterator", "err", err)
+		return nil
+	}
+	storageIt := trie.NewIterator(trieIt)
+	for storageIt.Next() {
+		_, content, _, err := rlp.Split(storageIt.Value)
+		if err != nil {
+			log.Error("Failed to decode the value returned by iterator", "error", err)
+			continue
+		}
+		if err := onStorage(addr, common.BytesToHash(s.trie.GetKey(storageIt.Key)), content); err != nil {
+			return err
+		}
+	}
+	return storageIt.Err
+}
+
+// DumpToCollector iterates the state according to the given options and inserts
+// the items into a collector for aggregation or serialization.
+func (s *StateDB) DumpToCollector(c DumpCollector, conf *DumpConfig) (nextKey []byte) {
+	c.OnRoot(s.trie.Hash())
+
+	// The collectors expect the accounts along with their storage, hold the
+	// last account back until all its slots are gathered.
+	var (
+		pending     *DumpAccount
+		pendingAddr *common.Address
+	)
+	flush := func() {
+		if pending != nil {
+			c.OnAccount(pendingAddr, *pending)
+			pending, pendingAddr = nil, nil
+		}
+	}
+	onAccount := func(addr *common.Address, account DumpAccount) error {
+		flush()
+		if conf == nil || !conf.SkipStorage {
+			account.Storage = make(map[common.Hash]string)
+		}
+		pending, pendingAddr = &account, addr
+		return nil
+	}
+	onStorage := func(addr *common.Address, key common.Hash, value []byte) error {
+		pending.Storage[key] = common.Bytes2Hex(value)
+		return nil
+	}
+	nextKey, err := s.StreamDump(conf, onAccount, onStorage)
+	if err != nil {
+		log.Error("Failed to dump state", "err", err)
+	}
+	flush()
 	return nextKey
 }
 
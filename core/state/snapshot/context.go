@@ -72,19 +72,62 @@ func (gs *generatorStats) Log(msg string, root common.Hash, marker []byte) {
 		"elapsed", common.PrettyDuration(time.Since(gs.start)),
 	}...)
 	// Calculate the estimated indexing time based on current stats
-	if len(marker) > 0 {
-		if done := binary.BigEndian.Uint64(marker[:8]) - gs.origin; done > 0 {
-			left := math.MaxUint64 - binary.BigEndian.Uint64(marker[:8])
-
-			speed := done/uint64(time.Since(gs.start)/time.Millisecond+1) + 1 // +1s to avoid division by zero
-			ctx = append(ctx, []interface{}{
-				"eta", common.PrettyDuration(time.Duration(left/speed) * time.Millisecond),
-			}...)
-		}
+	if eta, ok := gs.eta(marker); ok {
+		ctx = append(ctx, []interface{}{"eta", common.PrettyDuration(eta)}...)
 	}
 	log.Info(msg, ctx...)
 }
 
+// eta estimates the remaining generation time based on the position of the
+// marker within the key space and the speed observed so far. False is returned
+// if there's not enough progress yet to extrapolate from.
+func (gs *generatorStats) eta(marker []byte) (time.Duration, bool) {
+	if len(marker) == 0 {
+		return 0, false
+	}
+	done := binary.BigEndian.Uint64(marker[:8]) - gs.origin
+	if done == 0 {
+		return 0, false
+	}
+	left := math.MaxUint64 - binary.BigEndian.Uint64(marker[:8])
+
+	speed := done/uint64(time.Since(gs.start)/time.Millisecond+1) + 1 // +1s to avoid division by zero
+	return time.Duration(left/speed) * time.Millisecond, true
+}
+
+// progress assembles a point-in-time progress report from the internally
+// maintained statistics.
+func (gs *generatorStats) progress(root common.Hash, marker []byte) *GeneratorProgress {
+	eta, _ := gs.eta(marker)
+	return &GeneratorProgress{
+		Root:     root,
+		Marker:   common.CopyBytes(marker),
+		Accounts: gs.accounts,
+		Slots:    gs.slots,
+		Dangling: gs.dangling,
+		Storage:  gs.storage,
+		Start:    gs.start,
+		Elapsed:  time.Since(gs.start),
+		ETA:      eta,
+		Done:     marker == nil,
+	}
+}
+
+// GeneratorProgress is a snapshot of the background generation's statistics,
+// published periodically by the generator for external inspection.
+type GeneratorProgress struct {
+	Root     common.Hash        // Root of the disk layer being generated
+	Marker   []byte             // Position the generation has reached (nil if done)
+	Accounts uint64             // Number of accounts indexed(generated or recovered)
+	Slots    uint64             // Number of storage slots indexed(generated or recovered)
+	Dangling uint64             // Number of dangling storage slots
+	Storage  common.StorageSize // Total account and storage slot size(generation or recovery)
+	Start    time.Time          // Timestamp when generation started
+	Elapsed  time.Duration      // Time spent generating up to the report
+	ETA      time.Duration      // Estimated time left until the generation is done
+	Done     bool               // Whether the generation has finished
+}
+
 // generatorContext carries a few global values to be shared by all generation functions.
 type generatorContext struct {
 	stats   *generatorStats     // Generation statistic collection
@@ -93,15 +136,20 @@ type generatorContext struct {
 	storage *holdableIterator   // Iterator of storage snapshot data
 	batch   ethdb.Batch         // Database batch for writing batch data atomically
 	logged  time.Time           // The timestamp when last generation progress was displayed
+
+	throttled time.Time          // The timestamp when the rate limiting was started
+	baseline  common.StorageSize // Total processed data size when the rate limiting was started
 }
 
 // newGeneratorContext initializes the context for generation.
 func newGeneratorContext(stats *generatorStats, db ethdb.KeyValueStore, accMarker []byte, storageMarker []byte) *generatorContext {
 	ctx := &generatorContext{
-		stats:  stats,
-		db:     db,
-		batch:  db.NewBatch(),
-		logged: time.Now(),
+		stats:     stats,
+		db:        db,
+		batch:     db.NewBatch(),
+		logged:    time.Now(),
+		throttled: time.Now(),
+		baseline:  stats.storage,
 	}
 	ctx.openIterator(snapAccount, accMarker)
 	ctx.openIterator(snapStorage, storageMarker)
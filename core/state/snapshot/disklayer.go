@@ -41,6 +41,8 @@ type diskLayer struct {
 	genMarker  []byte                    // Marker for the state that's indexed during initial layer generation
 	genPending chan struct{}             // Notification channel when generation is done (test synchronicity)
 	genAbort   chan chan *generatorStats // Notification channel to abort generating the snapshot in this layer
+	genRate    uint64                    // Maximum data size (bytes) indexed per second during generation, 0 = unlimited
+	genStats   *GeneratorProgress        // Latest progress report published by the generator
 
 	lock sync.RWMutex
 }
@@ -54,8 +54,9 @@ var (
 
 // generateSnapshot regenerates a brand new snapshot based on an existing state
 // database and head block asynchronously. The snapshot is returned immediately
-// and generation is continued in the background until done.
-func generateSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int, root common.Hash) *diskLayer {
+// and generation is continued in the background until done. The rate limits the
+// amount of data (bytes) indexed per second, zero meaning unlimited.
+func generateSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int, rate uint64, root common.Hash) *diskLayer {
 	// Create a new disk layer with an initialized state marker at zero
 	var (
 		stats     = &generatorStats{start: time.Now()}
@@ -75,6 +76,7 @@ func generateSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache i
 		genMarker:  genMarker,
 		genPending: make(chan struct{}),
 		genAbort:   make(chan chan *generatorStats),
+		genRate:    rate,
 	}
 	go base.generate(stats)
 	log.Debug("Start snapshot generation", "root", root)
@@ -495,6 +497,7 @@ func (dl *diskLayer) checkAndFlush(ctx *generatorContext, current []byte) error
 
 		dl.lock.Lock()
 		dl.genMarker = current
+		dl.genStats = ctx.stats.progress(dl.root, current)
 		dl.lock.Unlock()
 
 		if abort != nil {
@@ -504,6 +507,13 @@ func (dl *diskLayer) checkAndFlush(ctx *generatorContext, current []byte) error
 		// Don't hold the iterators too long, release them to let compactor works
 		ctx.reopenIterator(snapAccount)
 		ctx.reopenIterator(snapStorage)
+
+		// If the generation is rate limited, hold off until the indexed data
+		// falls back within the allowance, not to starve block processing.
+		if abort = dl.throttle(ctx); abort != nil {
+			ctx.stats.Log("Aborting state snapshot generation", dl.root, current)
+			return newAbortErr(abort) // bubble up an error for interruption
+		}
 	}
 	if time.Since(ctx.logged) > 8*time.Second {
 		ctx.stats.Log("Generating state snapshot", dl.root, current)
@@ -512,6 +522,35 @@ func (dl *diskLayer) checkAndFlush(ctx *generatorContext, current []byte) error
 	return nil
 }
 
+// throttle blocks the generator if the data indexed since the rate limiting was
+// started exceeds the configured allowance. The wait is cut short if an abort
+// signal arrives, in which case the signal is returned. The batch is expected
+// to be flushed already, as the generation may be interrupted here.
+func (dl *diskLayer) throttle(ctx *generatorContext) chan *generatorStats {
+	if dl.genRate == 0 {
+		return nil
+	}
+	indexed := uint64(ctx.stats.storage - ctx.baseline)
+	allowed := time.Duration(float64(indexed) / float64(dl.genRate) * float64(time.Second))
+
+	wait := allowed - time.Since(ctx.throttled)
+	if wait <= 0 {
+		return nil
+	}
+	start := time.Now()
+	defer func() { snapThrottleCounter.Inc(time.Since(start).Nanoseconds()) }()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case abort := <-dl.genAbort:
+		return abort
+	}
+}
+
 // generateStorages generates the missing storage slots of the specific contract.
 // It's supposed to restart the generation from the given origin position.
 func generateStorages(ctx *generatorContext, dl *diskLayer, stateRoot common.Hash, account common.Hash, storageRoot common.Hash, storeMarker []byte) error {
@@ -671,6 +710,10 @@ func (dl *diskLayer) generate(stats *generatorStats) {
 	}
 	stats.Log("Resuming state snapshot generation", dl.root, dl.genMarker)
 
+	dl.lock.Lock()
+	dl.genStats = stats.progress(dl.root, dl.genMarker)
+	dl.lock.Unlock()
+
 	// Initialize the global generator context. The snapshot iterators are
 	// opened at the interrupted position because the assumption is held
 	// that all the snapshot data are generated correctly before the marker.
@@ -712,6 +755,7 @@ func (dl *diskLayer) generate(stats *generatorStats) {
 
 	dl.lock.Lock()
 	dl.genMarker = nil
+	dl.genStats = stats.progress(dl.root, nil)
 	close(dl.genPending)
 	dl.lock.Unlock()
 
@@ -210,7 +210,7 @@ func (t *testHelper) Commit() common.Hash {
 
 func (t *testHelper) CommitAndGenerate() (common.Hash, *diskLayer) {
 	root := t.Commit()
-	snap := generateSnapshot(t.diskdb, t.triedb, 16, root)
+	snap := generateSnapshot(t.diskdb, t.triedb, 16, 0, root)
 	return root, snap
 }
 
@@ -395,7 +395,7 @@ func TestGenerateCorruptAccountTrie(t *testing.T) {
 	helper.triedb.Commit(root, false)
 	helper.diskdb.Delete(common.HexToHash("0x65145f923027566669a1ae5ccac66f945b55ff6eaeb17d2ea8e048b7d381f2d7").Bytes())
 
-	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root)
+	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, 0, root)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -430,7 +430,7 @@ func TestGenerateMissingStorageTrie(t *testing.T) {
 	// Delete a storage trie root and ensure the generator chokes
 	helper.diskdb.Delete(stRoot.Bytes())
 
-	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root)
+	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, 0, root)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -464,7 +464,7 @@ func TestGenerateCorruptStorageTrie(t *testing.T) {
 	// Delete a storage trie leaf and ensure the generator chokes
 	helper.diskdb.Delete(common.HexToHash("0x18a0f4d79cff4459642dd7604f303886ad9d77c30cf3d7d7cedb3a693ab6d371").Bytes())
 
-	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root)
+	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, 0, root)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -523,7 +523,7 @@ func TestGenerateWithExtraAccounts(t *testing.T) {
 	if data := rawdb.ReadStorageSnapshot(helper.diskdb, hashData([]byte("acc-2")), hashData([]byte("b-key-1"))); data == nil {
 		t.Fatalf("expected snap storage to exist")
 	}
-	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root)
+	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, 0, root)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -874,3 +874,87 @@ func TestGenerateBrokenSnapshotWithDanglingStorage(t *testing.T) {
 	snap.genAbort <- stop
 	<-stop
 }
+
+// Tests that the generator publishes its progress, both while running and
+// after it finished.
+func TestGenerationProgress(t *testing.T) {
+	var helper = newHelper()
+	stRoot := helper.makeStorageTrie(common.Hash{}, []string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"}, false)
+
+	helper.addTrieAccount("acc-1", &types.StateAccount{Balance: big.NewInt(1), Root: stRoot, CodeHash: types.EmptyCodeHash.Bytes()})
+	helper.addTrieAccount("acc-2", &types.StateAccount{Balance: big.NewInt(2), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash.Bytes()})
+	helper.makeStorageTrie(hashData([]byte("acc-1")), []string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"}, true)
+
+	root, snap := helper.CommitAndGenerate()
+	select {
+	case <-snap.genPending:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Snapshot generation failed")
+	}
+	tree := &Tree{layers: map[common.Hash]snapshot{root: snap}}
+
+	progress := tree.GeneratorProgress()
+	if progress == nil {
+		t.Fatal("missing generation progress")
+	}
+	if !progress.Done || progress.Marker != nil {
+		t.Errorf("generation not reported complete: done %v, marker %x", progress.Done, progress.Marker)
+	}
+	if progress.Root != root {
+		t.Errorf("root mismatch: have %x, want %x", progress.Root, root)
+	}
+	if progress.Accounts != 2 || progress.Slots != 3 {
+		t.Errorf("counters mismatch: have %d/%d, want 2/3", progress.Accounts, progress.Slots)
+	}
+	// Signal abortion to the generator and wait for it to tear down
+	stop := make(chan *generatorStats)
+	snap.genAbort <- stop
+	<-stop
+}
+
+// Tests that the generator is held back if it indexes data faster than the
+// configured rate, and that it can still be aborted while waiting.
+func TestGenerationThrottle(t *testing.T) {
+	dl := &diskLayer{
+		genAbort: make(chan chan *generatorStats),
+		genRate:  1024 * 1024,
+	}
+	ctx := &generatorContext{stats: &generatorStats{}, throttled: time.Now()}
+
+	// Nothing indexed yet, there should be no wait at all
+	if abort := dl.throttle(ctx); abort != nil {
+		t.Fatal("unexpected abort")
+	}
+	// Index 200ms worth of data and check that the generator is held back
+	ctx.stats.storage = common.StorageSize(dl.genRate / 5)
+
+	start := time.Now()
+	if abort := dl.throttle(ctx); abort != nil {
+		t.Fatal("unexpected abort")
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("generator not throttled: waited %v", elapsed)
+	}
+	// Index a lot more and ensure the wait is interrupted by an abort
+	ctx.stats.storage = common.StorageSize(dl.genRate * 60)
+
+	stop := make(chan *generatorStats)
+	go func() { dl.genAbort <- stop }()
+
+	done := make(chan chan *generatorStats)
+	go func() { done <- dl.throttle(ctx) }()
+
+	select {
+	case abort := <-done:
+		if abort != stop {
+			t.Fatal("abort signal not returned")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("throttled generator not aborted")
+	}
+	// Disabled rate limiting should never wait
+	dl.genRate = 0
+	if abort := dl.throttle(ctx); abort != nil {
+		t.Fatal("unexpected abort")
+	}
+}
@@ -154,6 +154,15 @@ type Config struct {
 	Recovery   bool // Indicator that the snapshots is in the recovery mode
 	NoBuild    bool // Indicator that the snapshots generation is disallowed
 	AsyncBuild bool // The snapshot generation is allowed to be constructed asynchronously
+	BuildRate  int  // Megabytes of snapshot data permitted to index per second during generation (0 = unlimited)
+}
+
+// buildRate returns the generation rate limit in bytes per second.
+func (c Config) buildRate() uint64 {
+	if c.BuildRate <= 0 {
+		return 0
+	}
+	return uint64(c.BuildRate) * 1024 * 1024
 }
 
 // Tree is an Ori state snapshot tree. It consists of one persistent base
@@ -201,7 +210,7 @@ func New(config Config, diskdb ethdb.KeyValueStore, triedb *trie.Database, root
 		layers: make(map[common.Hash]snapshot),
 	}
 	// Attempt to load a previously persisted snapshot and rebuild one if failed
-	head, disabled, err := loadSnapshot(diskdb, triedb, root, config.CacheSize, config.Recovery, config.NoBuild)
+	head, disabled, err := loadSnapshot(diskdb, triedb, root, config.CacheSize, config.buildRate(), config.Recovery, config.NoBuild)
 	if disabled {
 		log.Warn("Snapshot maintenance disabled (syncing)")
 		return snap, nil
@@ -642,6 +651,8 @@ func diffToDisk(bottom *diffLayer) *diskLayer {
 		triedb:     base.triedb,
 		genMarker:  base.genMarker,
 		genPending: base.genPending,
+		genRate:    base.genRate,
+		genStats:   base.genStats,
 	}
 	// If snapshot generation hasn't finished yet, port over all the starts and
 	// continue where the previous round left off.
@@ -737,7 +748,7 @@ func (t *Tree) Rebuild(root common.Hash) {
 	// generator will run a wiper first if there's not one running right now.
 	log.Info("Rebuilding state snapshot")
 	t.layers = map[common.Hash]snapshot{
-		root: generateSnapshot(t.diskdb, t.triedb, t.config.CacheSize, root),
+		root: generateSnapshot(t.diskdb, t.triedb, t.config.CacheSize, t.config.buildRate(), root),
 	}
 }
 
@@ -845,6 +856,30 @@ func (t *Tree) generating() (bool, error) {
 	return layer.genMarker != nil, nil
 }
 
+// GeneratorProgress returns the latest progress report of the background snapshot
+// generation, or nil if there's no disk layer or the generation hasn't reported
+// anything yet (e.g. the snapshot was already complete when loaded).
+func (t *Tree) GeneratorProgress() *GeneratorProgress {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	layer := t.disklayer()
+	if layer == nil {
+		return nil
+	}
+	layer.lock.RLock()
+	defer layer.lock.RUnlock()
+
+	if layer.genStats == nil {
+		if layer.genMarker == nil {
+			return &GeneratorProgress{Root: layer.root, Done: true}
+		}
+		return nil
+	}
+	progress := *layer.genStats
+	return &progress
+}
+
 // DiskRoot is a external helper function to return the disk layer root.
 func (t *Tree) DiskRoot() common.Hash {
 	t.lock.Lock()
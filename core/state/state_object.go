@@ -145,7 +145,7 @@ func (s *stateObject) getTrie() (Trie, error) {
 			s.trie = s.db.prefetcher.trie(s.addrHash, s.data.Root)
 		}
 		if s.trie == nil {
-			tr, err := s.db.db.OpenStorageTrie(s.db.originalRoot, s.address, s.data.Root)
+			tr, err := s.db.db.OpenStorageTrie(s.db.originalRoot, s.address, s.data.Root, s.db.trie)
 			if err != nil {
 				return nil, err
 			}
@@ -184,6 +184,9 @@ func (s *stateObject) GetCommittedState(key common.Hash) common.Hash {
 	if _, destructed := s.db.stateObjectsDestruct[s.address]; destructed {
 		return common.Hash{}
 	}
+	if s.db.witness != nil {
+		s.db.witness.addSlot(s.address, key)
+	}
 	// If no live objects are available, attempt to use snapshots
 	var (
 		enc   []byte
@@ -366,6 +369,11 @@ func (s *stateObject) updateRoot() {
 	if tr == nil {
 		return
 	}
+	// The storage slots of verkle states live in the account tree, which
+	// is hashed along with the accounts.
+	if s.db.isVerkle() {
+		return
+	}
 	// Track the amount of time wasted on hashing the storage trie
 	if metrics.EnabledExpensive {
 		defer func(start time.Time) { s.db.StorageHashes += time.Since(start) }(time.Now())
@@ -379,8 +387,9 @@ func (s *stateObject) commit() (*trienode.NodeSet, error) {
 	if err != nil {
 		return nil, err
 	}
-	// If nothing changed, don't bother with committing anything
-	if tr == nil {
+	// If nothing changed, don't bother with committing anything. The storage
+	// slots of verkle states are committed along with the account tree.
+	if tr == nil || s.db.isVerkle() {
 		s.origin = s.data.Copy()
 		return nil, nil
 	}
@@ -472,6 +481,9 @@ func (s *stateObject) Code() []byte {
 	if bytes.Equal(s.CodeHash(), types.EmptyCodeHash.Bytes()) {
 		return nil
 	}
+	if s.db.witness != nil {
+		s.db.witness.addCode(s.address, common.BytesToHash(s.CodeHash()))
+	}
 	code, err := s.db.db.ContractCode(s.address, common.BytesToHash(s.CodeHash()))
 	if err != nil {
 		s.db.setError(fmt.Errorf("can't load code hash %x: %v", s.CodeHash(), err))
@@ -490,6 +502,9 @@ func (s *stateObject) CodeSize() int {
 	if bytes.Equal(s.CodeHash(), types.EmptyCodeHash.Bytes()) {
 		return 0
 	}
+	if s.db.witness != nil {
+		s.db.witness.addCode(s.address, common.BytesToHash(s.CodeHash()))
+	}
 	size, err := s.db.db.ContractCodeSize(s.address, common.BytesToHash(s.CodeHash()))
 	if err != nil {
 		s.db.setError(fmt.Errorf("can't load code size %x: %v", s.CodeHash(), err))
@@ -19,6 +19,7 @@ package state
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -132,6 +133,77 @@ func TestIterativeDump(t *testing.T) {
 	}
 }
 
+func TestStreamDump(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	tdb := NewDatabaseWithConfig(db, &trie.Config{Preimages: true})
+	sdb, _ := New(types.EmptyRootHash, tdb, nil)
+
+	// generate a few accounts, one of them with storage
+	for i := byte(0); i < 10; i++ {
+		sdb.AddBalance(common.BytesToAddress([]byte{i}), big.NewInt(int64(i)+1))
+	}
+	sdb.SetState(common.BytesToAddress([]byte{0x03}), common.Hash{0x01}, common.Hash{0x02})
+	root, _ := sdb.Commit(0, false)
+	sdb, _ = New(root, tdb, nil)
+
+	// page through the state and check every account is visited exactly once
+	var (
+		seen  = make(map[common.Address]bool)
+		slots int
+		start []byte
+		pages int
+	)
+	for {
+		onAccount := func(addr *common.Address, account DumpAccount) error {
+			if seen[*addr] {
+				t.Fatalf("account %x visited twice", *addr)
+			}
+			seen[*addr] = true
+			return nil
+		}
+		onStorage := func(addr *common.Address, key common.Hash, value []byte) error {
+			if *addr != common.BytesToAddress([]byte{0x03}) {
+				t.Fatalf("unexpected storage owner %x", *addr)
+			}
+			slots++
+			return nil
+		}
+		next, err := sdb.StreamDump(&DumpConfig{Start: start, Max: 3}, onAccount, onStorage)
+		if err != nil {
+			t.Fatalf("failed to dump state: %v", err)
+		}
+		pages++
+		if next == nil {
+			break
+		}
+		start = next
+	}
+	if len(seen) != 10 {
+		t.Fatalf("accounts mismatch: have %d, want %d", len(seen), 10)
+	}
+	if slots != 1 {
+		t.Fatalf("storage slots mismatch: have %d, want %d", slots, 1)
+	}
+	if pages != 4 {
+		t.Fatalf("pages mismatch: have %d, want %d", pages, 4)
+	}
+	// check that a failing callback aborts the dump
+	var (
+		visited int
+		errStop = errors.New("stop")
+	)
+	_, err := sdb.StreamDump(nil, func(addr *common.Address, account DumpAccount) error {
+		visited++
+		return errStop
+	}, nil)
+	if err != errStop {
+		t.Fatalf("error mismatch: have %v, want %v", err, errStop)
+	}
+	if visited != 1 {
+		t.Fatalf("visited accounts mismatch: have %d, want %d", visited, 1)
+	}
+}
+
 func TestNull(t *testing.T) {
 	s := newStateEnv()
 	address := common.HexToAddress("0x823140710bf13990e4500136726d8b55")
@@ -68,6 +68,7 @@ func (n *proofList) Delete(key []byte) error {
 type StateDB struct {
 	db         Database
 	prefetcher *triePrefetcher
+	witness    *Witness // Recorder of the accessed pre-state, nil if disabled
 	trie       Trie
 	hasher     crypto.KeccakState
 	snaps      *snapshot.Tree    // Nil if snapshot is not available
@@ -115,6 +116,12 @@ type StateDB struct {
 	// Per-transaction access list
 	accessList *accessList
 
+	// Entries of the transaction access list not accessed yet, which would have
+	// been cold without it, and the gas saved by the ones already accessed
+	prewarmedAddrs     map[common.Address]struct{}
+	prewarmedSlots     map[prewarmedSlot]struct{}
+	accessListGasSaved uint64
+
 	// Transient storage
 	transientStorage transientStorage
 
@@ -176,6 +183,26 @@ func New(root common.Hash, db Database, snaps *snapshot.Tree) (*StateDB, error)
 	return sdb, nil
 }
 
+// StartWitness enables recording all accounts, storage slots and contract codes
+// read from the pre-state into the given witness. Copies of the state do not
+// inherit the recorder.
+func (s *StateDB) StartWitness(w *Witness) {
+	s.witness = w
+}
+
+// Witness returns the recorder of the accessed pre-state, or nil if recording is
+// not enabled.
+func (s *StateDB) Witness() *Witness {
+	return s.witness
+}
+
+// isVerkle reports whether the state is backed by a verkle tree, in which case
+// the storage slots are held by the account trie instead of separate tries.
+func (s *StateDB) isVerkle() bool {
+	_, ok := s.trie.(*trie.VerkleTrie)
+	return ok
+}
+
 // StartPrefetcher initializes a new trie prefetcher to pull in nodes from the
 // state trie concurrently while the state is mutated so that when we reach the
 // commit phase, most of the needed data is already hot.
@@ -397,6 +424,46 @@ func (s *StateDB) StorageTrie(addr common.Address) (Trie, error) {
 	return cpy.getTrie()
 }
 
+// StorageIterator returns an iterator over the storage slots of an account in
+// the order of their hashed keys, positioned at the given hash. The iterator
+// yields the RLP encoded slot values and is nil for non-existent accounts.
+//
+// The flat snapshot is iterated if it covers the state and the account has no
+// uncommitted storage changes, otherwise the storage trie is, which works with
+// both the hash and the path based trie databases.
+func (s *StateDB) StorageIterator(addr common.Address, seek common.Hash) (snapshot.StorageIterator, error) {
+	stateObject := s.getStateObject(addr)
+	if stateObject == nil {
+		return nil, nil
+	}
+	_, destructed := s.stateObjectsDestruct[addr]
+	if s.snap != nil && !destructed && len(stateObject.pendingStorage) == 0 && len(stateObject.dirtyStorage) == 0 {
+		if it, err := s.snaps.StorageIterator(s.originalRoot, stateObject.addrHash, seek); err == nil {
+			return it, nil
+		}
+	}
+	tr, err := s.StorageTrie(addr)
+	if err != nil {
+		return nil, err
+	}
+	nodeIt, err := tr.NodeIterator(seek.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return &trieStorageIterator{it: trie.NewIterator(nodeIt)}, nil
+}
+
+// trieStorageIterator wraps a storage trie iterator into a snapshot.StorageIterator.
+type trieStorageIterator struct {
+	it *trie.Iterator
+}
+
+func (it *trieStorageIterator) Next() bool        { return it.it.Next() }
+func (it *trieStorageIterator) Error() error      { return it.it.Err }
+func (it *trieStorageIterator) Hash() common.Hash { return common.BytesToHash(it.it.Key) }
+func (it *trieStorageIterator) Slot() []byte      { return it.it.Value }
+func (it *trieStorageIterator) Release()          {}
+
 func (s *StateDB) HasSelfDestructed(addr common.Address) bool {
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
@@ -599,6 +666,9 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 	if obj := s.stateObjects[addr]; obj != nil {
 		return obj
 	}
+	if s.witness != nil {
+		s.witness.addAccount(addr)
+	}
 	// If no live objects are available, attempt to use snapshots
 	var data *types.StateAccount
 	if s.snap != nil {
@@ -852,6 +922,15 @@ func (s *StateDB) Copy() *StateDB {
 	// empty lists, so we do it anyway to not blow up if we ever decide copy them
 	// in the middle of a transaction.
 	state.accessList = s.accessList.Copy()
+	state.prewarmedAddrs = make(map[common.Address]struct{}, len(s.prewarmedAddrs))
+	for addr := range s.prewarmedAddrs {
+		state.prewarmedAddrs[addr] = struct{}{}
+	}
+	state.prewarmedSlots = make(map[prewarmedSlot]struct{}, len(s.prewarmedSlots))
+	for slot := range s.prewarmedSlots {
+		state.prewarmedSlots[slot] = struct{}{}
+	}
+	state.accessListGasSaved = s.accessListGasSaved
 	state.transientStorage = s.transientStorage.Copy()
 
 	// If there's a prefetcher running, make an inactive copy of it that can
@@ -1026,7 +1105,7 @@ func (s *StateDB) clearJournalAndRefund() {
 // slots inside as deleted.
 func (s *StateDB) deleteStorage(addr common.Address, addrHash common.Hash, root common.Hash) (bool, map[common.Hash][]byte, *trienode.NodeSet, error) {
 	start := time.Now()
-	tr, err := s.db.OpenStorageTrie(s.originalRoot, addr, root)
+	tr, err := s.db.OpenStorageTrie(s.originalRoot, addr, root, s.trie)
 	if err != nil {
 		return false, nil, nil, fmt.Errorf("failed to open storage trie, err: %w", err)
 	}
@@ -1119,8 +1198,9 @@ func (s *StateDB) handleDestruction(nodes *trienode.MergedNodeSet) (map[common.A
 		// It can overwrite the data in s.accountsOrigin set by 'updateStateObject'.
 		s.accountsOrigin[addr] = types.SlimAccountRLP(*prev) // case (c) or (d)
 
-		// Short circuit if the storage was empty.
-		if prev.Root == types.EmptyRootHash {
+		// Short circuit if the storage was empty. Storage wiping is not
+		// supported by verkle states, the slots are left in the tree.
+		if prev.Root == types.EmptyRootHash || s.isVerkle() {
 			continue
 		}
 		// Remove storage slots belong to the account.
@@ -1330,7 +1410,28 @@ func (s *StateDB) Prepare(rules params.Rules, sender, coinbase common.Address, d
 		if rules.IsShanghai { // EIP-3651: warm coinbase
 			al.AddAddress(coinbase)
 		}
+		// Track the entries only warm due to the transaction access list
+		s.prewarmedAddrs = make(map[common.Address]struct{})
+		s.prewarmedSlots = make(map[prewarmedSlot]struct{})
+		for _, el := range list {
+			s.prewarmedAddrs[el.Address] = struct{}{}
+			for _, key := range el.StorageKeys {
+				s.prewarmedSlots[prewarmedSlot{el.Address, key}] = struct{}{}
+			}
+		}
+		delete(s.prewarmedAddrs, sender)
+		if dst != nil {
+			delete(s.prewarmedAddrs, *dst)
+		}
+		for _, addr := range precompiles {
+			delete(s.prewarmedAddrs, addr)
+		}
+		if rules.IsShanghai {
+			delete(s.prewarmedAddrs, coinbase)
+		}
 	}
+	s.accessListGasSaved = 0
+
 	// Reset transient storage at the beginning of transaction execution
 	s.transientStorage = newTransientStorage()
 }
@@ -1362,14 +1463,31 @@ func (s *StateDB) AddSlotToAccessList(addr common.Address, slot common.Hash) {
 
 // AddressInAccessList returns true if the given address is in the access list.
 func (s *StateDB) AddressInAccessList(addr common.Address) bool {
+	if _, ok := s.prewarmedAddrs[addr]; ok {
+		delete(s.prewarmedAddrs, addr)
+		s.accessListGasSaved += params.ColdAccountAccessCostEIP2929 - params.WarmStorageReadCostEIP2929
+	}
 	return s.accessList.ContainsAddress(addr)
 }
 
 // SlotInAccessList returns true if the given (address, slot)-tuple is in the access list.
 func (s *StateDB) SlotInAccessList(addr common.Address, slot common.Hash) (addressPresent bool, slotPresent bool) {
+	if _, ok := s.prewarmedSlots[prewarmedSlot{addr, slot}]; ok {
+		delete(s.prewarmedSlots, prewarmedSlot{addr, slot})
+		s.accessListGasSaved += params.ColdSloadCostEIP2929 - params.WarmStorageReadCostEIP2929
+	}
 	return s.accessList.Contains(addr, slot)
 }
 
+// AccessListGasSaved returns the gas saved within the current transaction by
+// accessing entries pre-warmed by its access list, which would have been cold
+// without it. Every entry is counted once, at its first access, at the savings
+// of an account or storage read. The intrinsic cost of the access list is not
+// deducted.
+func (s *StateDB) AccessListGasSaved() uint64 {
+	return s.accessListGasSaved
+}
+
 // convertAccountSet converts a provided account set from address keyed to hash keyed.
 func (s *StateDB) convertAccountSet(set map[common.Address]*types.StateAccount) map[common.Hash]struct{} {
 	ret := make(map[common.Hash]struct{}, len(set))
@@ -35,6 +35,7 @@ import (
 	"github.com/gorievm/go-gori/core/state/snapshot"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/rlp"
 	"github.com/gorievm/go-gori/trie"
 )
 
@@ -1070,3 +1071,75 @@ func TestResetObject(t *testing.T) {
 		t.Fatalf("Unexpected storage slot value %v", slot)
 	}
 }
+
+func TestStorageIterator(t *testing.T) {
+	var (
+		disk     = rawdb.NewMemoryDatabase()
+		tdb      = trie.NewDatabase(disk)
+		db       = NewDatabaseWithNodeDB(disk, tdb)
+		snaps, _ = snapshot.New(snapshot.Config{CacheSize: 10}, disk, tdb, types.EmptyRootHash)
+		state, _ = New(types.EmptyRootHash, db, snaps)
+		addr     = common.HexToAddress("0x1")
+	)
+	state.SetBalance(addr, big.NewInt(1))
+	for i := byte(1); i <= 3; i++ {
+		state.SetState(addr, common.Hash{i}, common.Hash{i})
+	}
+	root, _ := state.Commit(0, true)
+
+	iterate := func(state *StateDB, seek common.Hash) map[common.Hash]common.Hash {
+		it, err := state.StorageIterator(addr, seek)
+		if err != nil {
+			t.Fatalf("failed to create iterator: %v", err)
+		}
+		defer it.Release()
+
+		var (
+			slots = make(map[common.Hash]common.Hash)
+			last  common.Hash
+		)
+		for it.Next() {
+			if bytes.Compare(it.Hash().Bytes(), last.Bytes()) <= 0 {
+				t.Fatalf("slots out of order: %x after %x", it.Hash(), last)
+			}
+			_, content, _, _ := rlp.Split(it.Slot())
+			slots[it.Hash()], last = common.BytesToHash(content), it.Hash()
+		}
+		if err := it.Error(); err != nil {
+			t.Fatalf("iteration failed: %v", err)
+		}
+		return slots
+	}
+	// Committed storage is iterated from the snapshot
+	state, _ = New(root, db, snaps)
+	if it, _ := state.StorageIterator(addr, common.Hash{}); reflect.TypeOf(it) == reflect.TypeOf(&trieStorageIterator{}) {
+		t.Fatalf("snapshot not used for committed storage")
+	}
+	slots := iterate(state, common.Hash{})
+	for i := byte(1); i <= 3; i++ {
+		if have := slots[crypto.Keccak256Hash(common.Hash{i}.Bytes())]; have != (common.Hash{i}) {
+			t.Fatalf("slot %d mismatch: have %x, want %x", i, have, common.Hash{i})
+		}
+	}
+	// Uncommitted changes are taken into account by falling back to the trie
+	state.SetState(addr, common.Hash{1}, common.Hash{0xff})
+	state.SetState(addr, common.Hash{2}, common.Hash{})
+
+	slots = iterate(state, common.Hash{})
+	if len(slots) != 2 || slots[crypto.Keccak256Hash(common.Hash{1}.Bytes())] != (common.Hash{0xff}) {
+		t.Fatalf("uncommitted storage mismatch: %v", slots)
+	}
+	// Iteration starts at the seek position
+	var seek common.Hash
+	for hash := range slots {
+		if bytes.Compare(hash[:], seek[:]) > 0 {
+			seek = hash
+		}
+	}
+	if slots = iterate(state, seek); len(slots) != 1 {
+		t.Fatalf("seeked storage size mismatch: have %d, want 1", len(slots))
+	}
+	if it, err := state.StorageIterator(common.HexToAddress("0x2"), common.Hash{}); it != nil || err != nil {
+		t.Fatalf("iterator of missing account: %v, %v", it, err)
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package stateroot computes the state root of externally sourced account and
+// storage datasets, following the exact same trie rules as the chain state.
+package stateroot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/math"
+	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/ethdb/memorydb"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/rlp"
+	"github.com/gorievm/go-gori/trie"
+)
+
+var (
+	accountPrefix = []byte("a") // accountPrefix + account hash -> account RLP
+	storagePrefix = []byte("s") // storagePrefix + account hash + slot hash -> slot RLP
+)
+
+// errOrphanStorage is returned if storage slots were fed for an account which
+// is missing from the dataset.
+var errOrphanStorage = errors.New("storage slots of unknown account")
+
+// Progress is a snapshot of the amount of data fed into a builder and of the
+// progress of its root computation.
+type Progress struct {
+	Accounts uint64      `json:"accounts"` // Number of account updates fed
+	Slots    uint64      `json:"slots"`    // Number of storage slot updates fed
+	Hashing  bool        `json:"hashing"`  // Whether a root computation is running
+	Hashed   uint64      `json:"hashed"`   // Number of accounts hashed by the last computation
+	Current  common.Hash `json:"current"`  // Last account hashed by the last computation
+	Root     common.Hash `json:"root"`     // Result of the last completed computation
+}
+
+// Builder collects accounts and storage slots fed in arbitrary order and
+// computes the root hash of the state trie they make up.
+//
+// The fed data is staged sorted by hashed key in a scratch key-value store, so
+// the memory requirements of the builder are determined by the store used and
+// not by the size of the dataset.
+type Builder struct {
+	db   ethdb.KeyValueStore // Scratch store to stage the fed data in
+	lock sync.Mutex          // Lock serializing updates and root computations
+
+	progress Progress   // Statistics about the fed data and root computation
+	statLock sync.Mutex // Lock protecting the progress statistics
+}
+
+// New creates a builder staging its data in the given key-value store, which is
+// expected to be empty. If no store is given, the data is staged in memory.
+func New(db ethdb.KeyValueStore) *Builder {
+	if db == nil {
+		db = memorydb.New()
+	}
+	return &Builder{db: db}
+}
+
+// UpdateAccount inserts or overwrites an account. The storage root of the
+// account is only used if no storage slots are fed for it.
+func (b *Builder) UpdateAccount(addr common.Address, account *types.StateAccount) error {
+	return b.UpdateAccountHash(crypto.Keccak256Hash(addr.Bytes()), account)
+}
+
+// UpdateAccountHash inserts or overwrites an account identified by the hash of
+// its address.
+func (b *Builder) UpdateAccountHash(hash common.Hash, account *types.StateAccount) error {
+	acc := types.StateAccount{
+		Nonce:    account.Nonce,
+		Balance:  account.Balance,
+		Root:     account.Root,
+		CodeHash: account.CodeHash,
+	}
+	if acc.Balance == nil {
+		acc.Balance = new(big.Int)
+	}
+	if acc.Root == (common.Hash{}) {
+		acc.Root = types.EmptyRootHash
+	}
+	if len(acc.CodeHash) == 0 {
+		acc.CodeHash = types.EmptyCodeHash.Bytes()
+	}
+	blob, err := rlp.EncodeToBytes(&acc)
+	if err != nil {
+		return err
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := b.db.Put(append(accountPrefix, hash.Bytes()...), blob); err != nil {
+		return err
+	}
+	b.statLock.Lock()
+	b.progress.Accounts++
+	b.statLock.Unlock()
+	return nil
+}
+
+// UpdateStorage inserts, overwrites or deletes (if the value is zero) a storage
+// slot of an account.
+func (b *Builder) UpdateStorage(addr common.Address, slot common.Hash, value common.Hash) error {
+	return b.UpdateStorageHash(crypto.Keccak256Hash(addr.Bytes()), crypto.Keccak256Hash(slot.Bytes()), value.Bytes())
+}
+
+// UpdateStorageHash inserts, overwrites or deletes (if the value is zero) a
+// storage slot identified by the hashes of the account address and slot key.
+func (b *Builder) UpdateStorageHash(account common.Hash, slot common.Hash, value []byte) error {
+	if len(value) > common.HashLength {
+		return fmt.Errorf("storage value too long: %d bytes", len(value))
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	key := append(append(storagePrefix, account.Bytes()...), slot.Bytes()...)
+	if value = common.TrimLeftZeroes(value); len(value) == 0 {
+		if err := b.db.Delete(key); err != nil {
+			return err
+		}
+	} else {
+		blob, _ := rlp.EncodeToBytes(value)
+		if err := b.db.Put(key, blob); err != nil {
+			return err
+		}
+	}
+	b.statLock.Lock()
+	b.progress.Slots++
+	b.statLock.Unlock()
+	return nil
+}
+
+// UpdateDump inserts an account in the format produced by the state dumps,
+// along with its code and storage slots. Accounts without an address are
+// identified by their hashed key, in which case their storage keys are also
+// expected to be hashed.
+func (b *Builder) UpdateDump(dump *state.DumpAccount) error {
+	var hash common.Hash
+	switch {
+	case dump.Address != nil:
+		hash = crypto.Keccak256Hash(dump.Address.Bytes())
+	case len(dump.SecureKey) == common.HashLength:
+		hash = common.BytesToHash(dump.SecureKey)
+	default:
+		return errors.New("account without address or key")
+	}
+	balance, ok := math.ParseBig256(dump.Balance)
+	if !ok {
+		return fmt.Errorf("invalid balance %q", dump.Balance)
+	}
+	if len(dump.Root) != 0 && len(dump.Root) != common.HashLength {
+		return fmt.Errorf("invalid storage root length: %d", len(dump.Root))
+	}
+	if len(dump.CodeHash) != 0 && len(dump.CodeHash) != common.HashLength {
+		return fmt.Errorf("invalid code hash length: %d", len(dump.CodeHash))
+	}
+	codeHash := dump.CodeHash
+	if len(codeHash) == 0 && len(dump.Code) != 0 {
+		codeHash = crypto.Keccak256(dump.Code)
+	}
+	account := &types.StateAccount{
+		Nonce:    dump.Nonce,
+		Balance:  balance,
+		Root:     common.BytesToHash(dump.Root),
+		CodeHash: codeHash,
+	}
+	if err := b.UpdateAccountHash(hash, account); err != nil {
+		return err
+	}
+	for key, value := range dump.Storage {
+		slot := key
+		if dump.Address != nil {
+			slot = crypto.Keccak256Hash(key.Bytes())
+		}
+		if err := b.UpdateStorageHash(hash, slot, common.FromHex(value)); err != nil {
+			return fmt.Errorf("slot %x: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Progress returns the current statistics of the builder. It may be called
+// while a root computation is running.
+func (b *Builder) Progress() Progress {
+	b.statLock.Lock()
+	defer b.statLock.Unlock()
+
+	return b.progress
+}
+
+// Hash computes the state root of all the data fed so far. The builder may be
+// fed further data after, the staged data is retained.
+func (b *Builder) Hash() (common.Hash, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.statLock.Lock()
+	b.progress.Hashing, b.progress.Hashed, b.progress.Current, b.progress.Root = true, 0, common.Hash{}, common.Hash{}
+	b.statLock.Unlock()
+
+	root, err := b.hash()
+
+	b.statLock.Lock()
+	b.progress.Hashing = false
+	if err == nil {
+		b.progress.Root = root
+	}
+	b.statLock.Unlock()
+	return root, err
+}
+
+// hash iterates the staged accounts and storage slots in lockstep, both being
+// sorted by account hash, and feeds them into stack tries.
+func (b *Builder) hash() (common.Hash, error) {
+	var (
+		accIt   = b.db.NewIterator(accountPrefix, nil)
+		slotIt  = b.db.NewIterator(storagePrefix, nil)
+		more    = slotIt.Next()
+		accTrie = trie.NewStackTrie(nil)
+
+		start  = time.Now()
+		logged = time.Now()
+		hashed uint64
+	)
+	defer accIt.Release()
+	defer slotIt.Release()
+
+	for accIt.Next() {
+		hash := accIt.Key()[len(accountPrefix):]
+
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(accIt.Value(), &account); err != nil {
+			return common.Hash{}, fmt.Errorf("account %x: %w", hash, err)
+		}
+		// Compute the storage root from the slots of the account, if any
+		var slots *trie.StackTrie
+		for ; more; more = slotIt.Next() {
+			key := slotIt.Key()[len(storagePrefix):]
+
+			cmp := bytes.Compare(key[:common.HashLength], hash)
+			if cmp < 0 {
+				return common.Hash{}, fmt.Errorf("%w: %x", errOrphanStorage, key[:common.HashLength])
+			}
+			if cmp > 0 {
+				break
+			}
+			if slots == nil {
+				slots = trie.NewStackTrie(nil)
+			}
+			slots.Update(key[common.HashLength:], slotIt.Value())
+		}
+		if slots != nil {
+			account.Root = slots.Hash()
+		}
+		blob, err := rlp.EncodeToBytes(&account)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		accTrie.Update(hash, blob)
+		hashed++
+
+		b.statLock.Lock()
+		b.progress.Hashed, b.progress.Current = hashed, common.BytesToHash(hash)
+		b.statLock.Unlock()
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Computing state root", "at", common.BytesToHash(hash), "accounts", hashed, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := accIt.Error(); err != nil {
+		return common.Hash{}, err
+	}
+	if err := slotIt.Error(); err != nil {
+		return common.Hash{}, err
+	}
+	if more {
+		return common.Hash{}, fmt.Errorf("%w: %x", errOrphanStorage, slotIt.Key()[len(storagePrefix):len(storagePrefix)+common.HashLength])
+	}
+	root := accTrie.Hash()
+	log.Debug("Computed state root", "root", root, "accounts", hashed, "elapsed", common.PrettyDuration(time.Since(start)))
+	return root, nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stateroot
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/trie"
+)
+
+// makeState creates a randomized state with a mix of plain accounts and
+// contracts, returning the state and its root hash.
+func makeState(t *testing.T) (*state.StateDB, common.Hash) {
+	sdb := state.NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Preimages: true})
+	statedb, _ := state.New(types.EmptyRootHash, sdb, nil)
+
+	for i := 0; i < 200; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i + 1)))
+		statedb.SetNonce(addr, uint64(i))
+		statedb.SetBalance(addr, big.NewInt(int64(i)*1000+1))
+		if i%5 == 0 {
+			statedb.SetCode(addr, []byte{byte(i), 0x60, 0x00})
+			for j := 0; j < i; j++ {
+				statedb.SetState(addr, common.BigToHash(big.NewInt(int64(j))), common.BigToHash(big.NewInt(int64(i*j+1))))
+			}
+		}
+	}
+	root, err := statedb.Commit(0, false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := sdb.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	statedb, _ = state.New(root, sdb, nil)
+	return statedb, root
+}
+
+// Tests that feeding a state dump in random order, with overwrites and deleted
+// slots sprinkled in, results in the same root as the state it was dumped from.
+func TestBuilderMatchesState(t *testing.T) {
+	statedb, root := makeState(t)
+	dump := statedb.RawDump(&state.DumpConfig{})
+	if len(dump.Accounts) != 200 {
+		t.Fatalf("dumped account count mismatch: have %d, want %d", len(dump.Accounts), 200)
+	}
+	var accounts []state.DumpAccount
+	for addr, account := range dump.Accounts {
+		addr := addr
+		account.Address = &addr
+		accounts = append(accounts, account)
+	}
+	rand.Shuffle(len(accounts), func(i, j int) { accounts[i], accounts[j] = accounts[j], accounts[i] })
+
+	b := New(nil)
+
+	// Feed some bogus data to be overwritten or deleted later
+	bogus := common.BigToAddress(big.NewInt(5))
+	b.UpdateAccount(bogus, &types.StateAccount{Nonce: 100, Balance: big.NewInt(100)})
+	b.UpdateStorage(bogus, common.Hash{0xff}, common.Hash{0x01})
+	b.UpdateStorage(bogus, common.Hash{0xff}, common.Hash{})
+
+	for _, account := range accounts {
+		if err := b.UpdateDump(&account); err != nil {
+			t.Fatalf("failed to feed account %x: %v", account.Address, err)
+		}
+	}
+	have, err := b.Hash()
+	if err != nil {
+		t.Fatalf("failed to compute root: %v", err)
+	}
+	if have != root {
+		t.Fatalf("root mismatch: have %x, want %x", have, root)
+	}
+	progress := b.Progress()
+	if progress.Hashing || progress.Hashed != 200 || progress.Root != root {
+		t.Errorf("progress mismatch: have %+v", progress)
+	}
+	if progress.Accounts != 201 {
+		t.Errorf("fed account count mismatch: have %d, want %d", progress.Accounts, 201)
+	}
+}
+
+// Tests that accounts fed with their storage roots, instead of their slots,
+// hash to the same root.
+func TestBuilderStorageRoots(t *testing.T) {
+	statedb, root := makeState(t)
+
+	b := New(nil)
+	statedb.DumpToCollector(collector(func(addr *common.Address, account state.DumpAccount) {
+		account.Address, account.Storage = addr, nil
+		if err := b.UpdateDump(&account); err != nil {
+			t.Fatalf("failed to feed account %x: %v", addr, err)
+		}
+	}), &state.DumpConfig{SkipStorage: true})
+
+	if have, err := b.Hash(); err != nil || have != root {
+		t.Fatalf("root mismatch: have %x/%v, want %x", have, err, root)
+	}
+}
+
+// Tests that storage slots without an account are reported.
+func TestBuilderOrphanStorage(t *testing.T) {
+	b := New(nil)
+	b.UpdateAccount(common.Address{0x01}, &types.StateAccount{Nonce: 1})
+	b.UpdateStorage(common.Address{0x02}, common.Hash{0x01}, common.Hash{0x01})
+
+	if _, err := b.Hash(); !errors.Is(err, errOrphanStorage) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errOrphanStorage)
+	}
+	// Feeding the missing account should fix the dataset
+	b.UpdateAccount(common.Address{0x02}, &types.StateAccount{Nonce: 1})
+	if _, err := b.Hash(); err != nil {
+		t.Fatalf("failed to compute root: %v", err)
+	}
+	// Sanity check that the empty builder produces the empty root
+	if root, _ := New(nil).Hash(); root != types.EmptyRootHash {
+		t.Errorf("empty root mismatch: have %x, want %x", root, types.EmptyRootHash)
+	}
+}
+
+// collector is a state.DumpCollector invoking a callback on each account.
+type collector func(addr *common.Address, account state.DumpAccount)
+
+func (c collector) OnRoot(common.Hash) {}
+
+func (c collector) OnAccount(addr *common.Address, account state.DumpAccount) {
+	c(addr, account)
+}
@@ -0,0 +1,192 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
+	"golang.org/x/exp/slices"
+)
+
+// Witness records the accounts, storage slots and contract codes accessed while
+// executing on top of a state, from which the execution witness proving them
+// against the pre-state root can be built. The hashes of ancestor blocks accessed
+// by the execution are recorded too, if reported by the executor.
+type Witness struct {
+	accounts map[common.Address]struct{}
+	slots    map[common.Address]map[common.Hash]struct{}
+	codes    map[common.Hash]common.Address
+	blocks   map[uint64]struct{}
+}
+
+// NewWitness creates an empty witness recorder.
+func NewWitness() *Witness {
+	return &Witness{
+		accounts: make(map[common.Address]struct{}),
+		slots:    make(map[common.Address]map[common.Hash]struct{}),
+		codes:    make(map[common.Hash]common.Address),
+		blocks:   make(map[uint64]struct{}),
+	}
+}
+
+// addAccount records an account read from the pre-state.
+func (w *Witness) addAccount(addr common.Address) {
+	w.accounts[addr] = struct{}{}
+}
+
+// addSlot records a storage slot read from the pre-state.
+func (w *Witness) addSlot(addr common.Address, key common.Hash) {
+	slots, ok := w.slots[addr]
+	if !ok {
+		slots = make(map[common.Hash]struct{})
+		w.slots[addr] = slots
+	}
+	slots[key] = struct{}{}
+}
+
+// addCode records a contract code read from the database.
+func (w *Witness) addCode(addr common.Address, codeHash common.Hash) {
+	w.codes[codeHash] = addr
+}
+
+// AddBlockHash records the hash of an ancestor block accessed during execution.
+func (w *Witness) AddBlockHash(number uint64) {
+	w.blocks[number] = struct{}{}
+}
+
+// BlockHashes returns the numbers of the ancestor blocks whose hashes were
+// accessed during execution, in ascending order.
+func (w *Witness) BlockHashes() []uint64 {
+	numbers := make([]uint64, 0, len(w.blocks))
+	for number := range w.blocks {
+		numbers = append(numbers, number)
+	}
+	slices.Sort(numbers)
+	return numbers
+}
+
+// ExecutionWitness is the collection of trie nodes, contract codes and headers
+// needed to re-execute a block on top of its pre-state root without access to the
+// full state. The trie nodes consist of the Merkle proofs of all accessed accounts
+// and storage slots, which also prove the absence of missing ones. The headers
+// link the parent block back to the oldest ancestor whose hash was accessed.
+type ExecutionWitness struct {
+	State   []hexutil.Bytes `json:"state"`   // RLP encoded trie nodes
+	Codes   []hexutil.Bytes `json:"codes"`   // Contract codes
+	Keys    []hexutil.Bytes `json:"keys"`    // Accessed addresses and address||slot pairs
+	Headers []hexutil.Bytes `json:"headers"` // RLP encoded ancestor headers, parent first
+}
+
+// Size returns the total byte size of the nodes and codes in the witness.
+func (w *ExecutionWitness) Size() int {
+	var size int
+	for _, node := range w.State {
+		size += len(node)
+	}
+	for _, code := range w.Codes {
+		size += len(code)
+	}
+	for _, header := range w.Headers {
+		size += len(header)
+	}
+	return size
+}
+
+// nodeSet collects the trie nodes written by Prove, deduplicated by hash.
+type nodeSet map[string][]byte
+
+func (set nodeSet) Put(key []byte, value []byte) error {
+	set[string(key)] = common.CopyBytes(value)
+	return nil
+}
+
+func (set nodeSet) Delete(key []byte) error {
+	delete(set, string(key))
+	return nil
+}
+
+// Build proves all recorded accesses against the state with the given root and
+// assembles the execution witness. The output is sorted to be deterministic.
+func (w *Witness) Build(db Database, root common.Hash) (*ExecutionWitness, error) {
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		nodes = make(nodeSet)
+		keys  [][]byte
+	)
+	// Prove all accessed accounts, including the ones owning accessed slots
+	for addr := range w.slots {
+		w.accounts[addr] = struct{}{}
+	}
+	for addr := range w.accounts {
+		if err := tr.Prove(crypto.Keccak256(addr.Bytes()), nodes); err != nil {
+			return nil, fmt.Errorf("failed to prove account %x: %v", addr, err)
+		}
+		keys = append(keys, common.CopyBytes(addr.Bytes()))
+	}
+	// Prove all accessed storage slots of accounts existing in the pre-state
+	for addr, slots := range w.slots {
+		for key := range slots {
+			keys = append(keys, append(common.CopyBytes(addr.Bytes()), key.Bytes()...))
+		}
+		acc, err := tr.GetAccount(addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve account %x: %v", addr, err)
+		}
+		if acc == nil || acc.Root == types.EmptyRootHash {
+			continue // absence proven by the account proof
+		}
+		st, err := db.OpenStorageTrie(root, addr, acc.Root, tr)
+		if err != nil {
+			return nil, err
+		}
+		for key := range slots {
+			if err := st.Prove(crypto.Keccak256(key.Bytes()), nodes); err != nil {
+				return nil, fmt.Errorf("failed to prove slot %x of account %x: %v", key, addr, err)
+			}
+		}
+	}
+	witness := &ExecutionWitness{
+		State: make([]hexutil.Bytes, 0, len(nodes)),
+		Codes: make([]hexutil.Bytes, 0, len(w.codes)),
+		Keys:  make([]hexutil.Bytes, 0, len(keys)),
+	}
+	for _, node := range nodes {
+		witness.State = append(witness.State, node)
+	}
+	for hash, addr := range w.codes {
+		code, err := db.ContractCode(addr, hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve code %x: %v", hash, err)
+		}
+		witness.Codes = append(witness.Codes, code)
+	}
+	for _, key := range keys {
+		witness.Keys = append(witness.Keys, key)
+	}
+	for _, list := range [][]hexutil.Bytes{witness.State, witness.Codes, witness.Keys} {
+		slices.SortFunc(list, func(a, b hexutil.Bytes) int { return bytes.Compare(a, b) })
+	}
+	return witness, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/ethdb/memorydb"
+	"github.com/gorievm/go-gori/rlp"
+	"github.com/gorievm/go-gori/trie"
+)
+
+// Tests that the execution witness proves all accessed state against the
+// pre-state root, including the absence of missing accounts and slots.
+func TestExecutionWitness(t *testing.T) {
+	var (
+		db      = NewDatabase(rawdb.NewMemoryDatabase())
+		state   *StateDB
+		alice   = common.HexToAddress("0xa1")
+		bob     = common.HexToAddress("0xb0b")
+		missing = common.HexToAddress("0xdead")
+		slot    = common.HexToHash("0x01")
+		unset   = common.HexToHash("0x02")
+		code    = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+	)
+	state, _ = New(types.EmptyRootHash, db, nil)
+	state.SetBalance(alice, big.NewInt(1))
+	state.SetBalance(bob, big.NewInt(2))
+	state.SetCode(bob, code)
+	state.SetState(bob, slot, common.HexToHash("0x42"))
+	for i := byte(0); i < 16; i++ {
+		state.SetBalance(common.BytesToAddress([]byte{0xff, i}), big.NewInt(int64(i)+1))
+	}
+	root, err := state.Commit(0, false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	// Access a mix of existing and missing state on top of the committed root
+	witness := NewWitness()
+	state, _ = New(root, db, nil)
+	state.StartWitness(witness)
+
+	state.GetBalance(alice)
+	state.GetBalance(missing)
+	state.GetCode(bob)
+	state.GetState(bob, slot)
+	state.GetState(bob, unset)
+	state.SetBalance(alice, big.NewInt(100)) // modifications must not change the witness
+
+	result, err := witness.Build(db, root)
+	if err != nil {
+		t.Fatalf("failed to build witness: %v", err)
+	}
+	if len(result.Codes) != 1 || !bytes.Equal(result.Codes[0], code) {
+		t.Fatalf("witness codes mismatch: have %x, want [%x]", result.Codes, code)
+	}
+	if len(result.Keys) != 5 {
+		t.Fatalf("witness keys mismatch: have %d, want %d", len(result.Keys), 5)
+	}
+	if result.Size() == 0 {
+		t.Fatal("empty witness size")
+	}
+	// Verify all accessed state with the witness nodes only
+	proofs := memorydb.New()
+	for _, node := range result.State {
+		proofs.Put(crypto.Keccak256(node), node)
+	}
+	for addr, exists := range map[common.Address]bool{alice: true, bob: true, missing: false} {
+		blob, err := trie.VerifyProof(root, crypto.Keccak256(addr.Bytes()), proofs)
+		if err != nil {
+			t.Fatalf("failed to verify account %x: %v", addr, err)
+		}
+		if exists != (blob != nil) {
+			t.Fatalf("account %x existence mismatch: have %v, want %v", addr, blob != nil, exists)
+		}
+	}
+	blob, _ := trie.VerifyProof(root, crypto.Keccak256(bob.Bytes()), proofs)
+	var account types.StateAccount
+	if err := rlp.DecodeBytes(blob, &account); err != nil {
+		t.Fatalf("failed to decode account: %v", err)
+	}
+	for key, exists := range map[common.Hash]bool{slot: true, unset: false} {
+		blob, err := trie.VerifyProof(account.Root, crypto.Keccak256(key.Bytes()), proofs)
+		if err != nil {
+			t.Fatalf("failed to verify slot %x: %v", key, err)
+		}
+		if exists != (blob != nil) {
+			t.Fatalf("slot %x existence mismatch: have %v, want %v", key, blob != nil, exists)
+		}
+	}
+}
@@ -0,0 +1,411 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/metrics"
+)
+
+// minParallelTxs is the minimum number of transactions a block needs to contain
+// for parallel execution to be attempted. Below it, the overhead of speculating
+// outweighs any gains.
+const minParallelTxs = 4
+
+var (
+	parallelSpeculatedMeter  = metrics.NewRegisteredMeter("chain/parallel/speculated", nil)
+	parallelReexecutedMeter  = metrics.NewRegisteredMeter("chain/parallel/reexecuted", nil)
+	parallelUnsupportedMeter = metrics.NewRegisteredMeter("chain/parallel/unsupported", nil)
+)
+
+// storageKey identifies a storage slot of an account.
+type storageKey struct {
+	addr common.Address
+	slot common.Hash
+}
+
+// balanceDelta is a balance increase of an account that did not depend on the
+// balance of the account itself (e.g. fee payments to the coinbase).
+type balanceDelta struct {
+	addr   common.Address
+	amount *big.Int
+}
+
+// accessTracker wraps a state database, recording the accounts and storage slots
+// a transaction reads and writes through it, so that speculative executions can
+// be checked for conflicts against the transactions preceding them.
+//
+// Balance increases are tracked as deltas instead of writes, as they commute with
+// each other: every transaction paying fees to the coinbase would conflict with
+// all others otherwise.
+type accessTracker struct {
+	*state.StateDB
+
+	reads      map[common.Address]struct{} // Accounts whose fields were read
+	writes     map[common.Address]struct{} // Accounts whose fields were overwritten
+	codes      map[common.Address]struct{} // Accounts whose code was overwritten
+	slotReads  map[storageKey]struct{}     // Storage slots read
+	slotWrites map[storageKey]struct{}     // Storage slots overwritten
+
+	deltas    []balanceDelta // Balance increases not reverted (yet)
+	snapshots map[int]int    // Number of deltas at each state snapshot
+
+	unsupported bool // Whether an operation not mergeable from a speculation was done
+}
+
+// newAccessTracker creates a tracker recording the accesses to the given state.
+func newAccessTracker(statedb *state.StateDB) *accessTracker {
+	return &accessTracker{
+		StateDB:    statedb,
+		reads:      make(map[common.Address]struct{}),
+		writes:     make(map[common.Address]struct{}),
+		codes:      make(map[common.Address]struct{}),
+		slotReads:  make(map[storageKey]struct{}),
+		slotWrites: make(map[storageKey]struct{}),
+		snapshots:  make(map[int]int),
+	}
+}
+
+func (t *accessTracker) Exist(addr common.Address) bool {
+	t.reads[addr] = struct{}{}
+	return t.StateDB.Exist(addr)
+}
+
+func (t *accessTracker) Empty(addr common.Address) bool {
+	t.reads[addr] = struct{}{}
+	return t.StateDB.Empty(addr)
+}
+
+func (t *accessTracker) GetBalance(addr common.Address) *big.Int {
+	t.reads[addr] = struct{}{}
+	return t.StateDB.GetBalance(addr)
+}
+
+func (t *accessTracker) GetNonce(addr common.Address) uint64 {
+	t.reads[addr] = struct{}{}
+	return t.StateDB.GetNonce(addr)
+}
+
+func (t *accessTracker) GetCode(addr common.Address) []byte {
+	t.reads[addr] = struct{}{}
+	return t.StateDB.GetCode(addr)
+}
+
+func (t *accessTracker) GetCodeSize(addr common.Address) int {
+	t.reads[addr] = struct{}{}
+	return t.StateDB.GetCodeSize(addr)
+}
+
+func (t *accessTracker) GetCodeHash(addr common.Address) common.Hash {
+	t.reads[addr] = struct{}{}
+	return t.StateDB.GetCodeHash(addr)
+}
+
+func (t *accessTracker) HasSelfDestructed(addr common.Address) bool {
+	t.reads[addr] = struct{}{}
+	return t.StateDB.HasSelfDestructed(addr)
+}
+
+func (t *accessTracker) GetState(addr common.Address, slot common.Hash) common.Hash {
+	t.slotReads[storageKey{addr, slot}] = struct{}{}
+	return t.StateDB.GetState(addr, slot)
+}
+
+func (t *accessTracker) GetCommittedState(addr common.Address, slot common.Hash) common.Hash {
+	t.slotReads[storageKey{addr, slot}] = struct{}{}
+	return t.StateDB.GetCommittedState(addr, slot)
+}
+
+func (t *accessTracker) AddBalance(addr common.Address, amount *big.Int) {
+	// Zero value transfers only touch empty accounts (to be deleted), they leave
+	// other accounts alone. Don't report them as writes, otherwise all calls to
+	// the same contract would conflict.
+	if amount.Sign() == 0 && !t.StateDB.Empty(addr) {
+		t.reads[addr] = struct{}{}
+		t.StateDB.AddBalance(addr, amount)
+		return
+	}
+	t.deltas = append(t.deltas, balanceDelta{addr: addr, amount: new(big.Int).Set(amount)})
+	t.StateDB.AddBalance(addr, amount)
+}
+
+func (t *accessTracker) SubBalance(addr common.Address, amount *big.Int) {
+	t.reads[addr] = struct{}{}
+	t.writes[addr] = struct{}{}
+	t.StateDB.SubBalance(addr, amount)
+}
+
+func (t *accessTracker) SetNonce(addr common.Address, nonce uint64) {
+	t.writes[addr] = struct{}{}
+	t.StateDB.SetNonce(addr, nonce)
+}
+
+func (t *accessTracker) SetCode(addr common.Address, code []byte) {
+	t.writes[addr] = struct{}{}
+	t.codes[addr] = struct{}{}
+	t.StateDB.SetCode(addr, code)
+}
+
+func (t *accessTracker) SetState(addr common.Address, slot, value common.Hash) {
+	t.slotWrites[storageKey{addr, slot}] = struct{}{}
+	t.StateDB.SetState(addr, slot, value)
+}
+
+func (t *accessTracker) CreateAccount(addr common.Address) {
+	// Recreating an existing account wipes its storage, which can't be replayed
+	// from the slots written by the speculation.
+	if t.StateDB.Exist(addr) {
+		t.unsupported = true
+	}
+	t.reads[addr] = struct{}{}
+	t.writes[addr] = struct{}{}
+	t.StateDB.CreateAccount(addr)
+}
+
+func (t *accessTracker) SelfDestruct(addr common.Address) {
+	t.reads[addr] = struct{}{}
+	t.writes[addr] = struct{}{}
+	t.unsupported = true
+	t.StateDB.SelfDestruct(addr)
+}
+
+func (t *accessTracker) Selfdestruct6780(addr common.Address) {
+	t.reads[addr] = struct{}{}
+	t.writes[addr] = struct{}{}
+	t.unsupported = true
+	t.StateDB.Selfdestruct6780(addr)
+}
+
+func (t *accessTracker) Snapshot() int {
+	id := t.StateDB.Snapshot()
+	t.snapshots[id] = len(t.deltas)
+	return id
+}
+
+func (t *accessTracker) RevertToSnapshot(id int) {
+	t.deltas = t.deltas[:t.snapshots[id]]
+	t.StateDB.RevertToSnapshot(id)
+}
+
+// conflicts reports whether the transaction accessed any of the accounts or slots
+// written since the state it was executed on.
+func (t *accessTracker) conflicts(accounts map[common.Address]struct{}, slots map[storageKey]struct{}) bool {
+	for addr := range t.reads {
+		if _, ok := accounts[addr]; ok {
+			return true
+		}
+	}
+	for addr := range t.writes {
+		if _, ok := accounts[addr]; ok {
+			return true
+		}
+	}
+	for key := range t.slotReads {
+		if _, ok := slots[key]; ok {
+			return true
+		}
+	}
+	for key := range t.slotWrites {
+		if _, ok := slots[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// record adds the accounts and slots written by the transaction to the given
+// write sets.
+func (t *accessTracker) record(accounts map[common.Address]struct{}, slots map[storageKey]struct{}) {
+	for addr := range t.writes {
+		accounts[addr] = struct{}{}
+	}
+	for _, delta := range t.deltas {
+		accounts[delta.addr] = struct{}{}
+	}
+	for key := range t.slotWrites {
+		slots[key] = struct{}{}
+	}
+}
+
+// merge replays the writes and logs of a speculatively executed transaction onto
+// the given state. The transaction must not conflict with the changes made to the
+// state since the one it was executed on.
+func (t *accessTracker) merge(statedb *state.StateDB, txHash common.Hash) {
+	for addr := range t.codes {
+		statedb.SetCode(addr, t.StateDB.GetCode(addr))
+	}
+	for addr := range t.writes {
+		statedb.SetBalance(addr, t.StateDB.GetBalance(addr))
+		statedb.SetNonce(addr, t.StateDB.GetNonce(addr))
+	}
+	for _, delta := range t.deltas {
+		if _, ok := t.writes[delta.addr]; !ok {
+			statedb.AddBalance(delta.addr, delta.amount)
+		}
+	}
+	for key := range t.slotWrites {
+		statedb.SetState(key.addr, key.slot, t.StateDB.GetState(key.addr, key.slot))
+	}
+	for _, log := range t.StateDB.GetLogs(txHash, 0, common.Hash{}) {
+		statedb.AddLog(&types.Log{Address: log.Address, Topics: log.Topics, Data: log.Data})
+	}
+}
+
+// speculation is the outcome of executing a transaction on the pre-state of its
+// block, independent of the transactions preceding it.
+type speculation struct {
+	msg     *Message
+	result  *ExecutionResult
+	tracker *accessTracker
+	err     error
+}
+
+// parallelizable reports whether the transactions of a block may be executed in
+// parallel. Tracing, preimage and witness recording need to observe the serial
+// execution, and pre-Byzantium receipts need the intermediate roots.
+func (p *StateProcessor) parallelizable(block *types.Block, statedb *state.StateDB, cfg vm.Config) bool {
+	if p.bc == nil || !p.bc.cacheConfig.ParallelExecution {
+		return false
+	}
+	if len(block.Transactions()) < minParallelTxs || !p.config.IsByzantium(block.Number()) {
+		return false
+	}
+	return cfg.Tracer == nil && !cfg.EnablePreimageRecording && statedb.Witness() == nil
+}
+
+// executeParallel executes the transactions of a block speculatively on worker
+// goroutines against the pre-state of the block, then merges their results in
+// order. Transactions accessing state written by the ones preceding them since,
+// or doing operations that can't be merged, are re-executed serially instead.
+func (p *StateProcessor) executeParallel(block *types.Block, statedb *state.StateDB, cfg vm.Config, gp *GasPool, usedGas *uint64) (types.Receipts, []*types.Log, error) {
+	var (
+		header      = block.Header()
+		blockHash   = block.Hash()
+		blockNumber = block.Number()
+		txs         = block.Transactions()
+		signer      = types.MakeSigner(p.config, header.Number, header.Time)
+
+		base     = statedb.Copy()
+		baseLock sync.Mutex // Copying a state is not thread safe
+		results  = make([]chan *speculation, len(txs))
+		jobs     = make(chan int, len(txs))
+		abort    atomic.Bool
+		pend     sync.WaitGroup
+	)
+	base.StopPrefetcher()
+	for i := range txs {
+		results[i] = make(chan *speculation, 1)
+		jobs <- i
+	}
+	close(jobs)
+
+	for n := 0; n < runtime.NumCPU(); n++ {
+		pend.Add(1)
+		go func() {
+			defer pend.Done()
+
+			evm := vm.NewEVM(NewEVMBlockContext(header, p.bc, nil), vm.TxContext{}, nil, p.config, cfg)
+			for i := range jobs {
+				if abort.Load() {
+					return
+				}
+				baseLock.Lock()
+				state := base.Copy()
+				baseLock.Unlock()
+
+				results[i] <- speculate(txs[i], i, header, signer, state, evm)
+			}
+		}()
+	}
+	defer func() {
+		abort.Store(true)
+		pend.Wait()
+	}()
+
+	// Merge the speculative results in order, re-executing any conflicting ones
+	var (
+		receipts = make(types.Receipts, 0, len(txs))
+		allLogs  []*types.Log
+		accounts = make(map[common.Address]struct{})
+		slots    = make(map[storageKey]struct{})
+		vmenv    = vm.NewEVM(NewEVMBlockContext(header, p.bc, nil), vm.TxContext{}, statedb, p.config, cfg)
+	)
+	for i, tx := range txs {
+		spec := <-results[i]
+		statedb.SetTxContext(tx.Hash(), i)
+
+		if spec.err == nil && !spec.tracker.unsupported && !spec.tracker.conflicts(accounts, slots) && gp.Gas() >= spec.msg.GasLimit {
+			spec.tracker.merge(statedb, tx.Hash())
+			gp.SubGas(spec.result.UsedGas)
+			parallelSpeculatedMeter.Mark(1)
+		} else {
+			if spec.tracker != nil && spec.tracker.unsupported {
+				parallelUnsupportedMeter.Mark(1)
+			}
+			msg, err := TransactionToMessage(tx, signer, header.BaseFee)
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			tracker := newAccessTracker(statedb)
+			vmenv.Reset(NewEVMTxContext(msg), tracker)
+
+			result, err := ApplyMessage(vmenv, msg, gp)
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			spec = &speculation{msg: msg, result: result, tracker: tracker}
+			parallelReexecutedMeter.Mark(1)
+		}
+		statedb.Finalise(true)
+		spec.tracker.record(accounts, slots)
+
+		*usedGas += spec.result.UsedGas
+		receipt := newReceipt(tx, spec.msg, spec.result, statedb, nil, *usedGas, blockNumber, blockHash)
+		receipts = append(receipts, receipt)
+		allLogs = append(allLogs, receipt.Logs...)
+	}
+	return receipts, allLogs, nil
+}
+
+// speculate executes a transaction on a private copy of the pre-state of its
+// block, tracking the state it accesses.
+func speculate(tx *types.Transaction, index int, header *types.Header, signer types.Signer, statedb *state.StateDB, evm *vm.EVM) *speculation {
+	msg, err := TransactionToMessage(tx, signer, header.BaseFee)
+	if err != nil {
+		return &speculation{err: err}
+	}
+	statedb.SetTxContext(tx.Hash(), index)
+
+	tracker := newAccessTracker(statedb)
+	evm.Reset(NewEVMTxContext(msg), tracker)
+
+	result, err := ApplyMessage(evm, msg, new(GasPool).AddGas(header.GasLimit))
+	if err != nil {
+		return &speculation{err: err}
+	}
+	statedb.Finalise(true)
+	return &speculation{msg: msg, result: result, tracker: tracker}
+}
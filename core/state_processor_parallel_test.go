@@ -0,0 +1,204 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/consensus/ethash"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/params"
+)
+
+var (
+	// parallelStorer stores the block number into the slot of the caller
+	parallelStorer     = common.Address{0x57}
+	parallelStorerCode = common.Hex2Bytes("43335500")
+
+	// parallelCounter increments slot 0 and emits a log
+	parallelCounter     = common.Address{0xc0}
+	parallelCounterCode = common.Hex2Bytes("60005460010160005560006000a000")
+)
+
+// newParallelTestGenesis creates a genesis with a number of funded accounts and
+// the contracts used by the parallel execution tests.
+func newParallelTestGenesis(n int) (*Genesis, []*ecdsa.PrivateKey) {
+	var (
+		keys  = make([]*ecdsa.PrivateKey, n)
+		alloc = GenesisAlloc{
+			parallelStorer:  {Code: parallelStorerCode, Balance: common.Big0},
+			parallelCounter: {Code: parallelCounterCode, Balance: common.Big0},
+		}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		alloc[crypto.PubkeyToAddress(keys[i].PublicKey)] = GenesisAccount{Balance: big.NewInt(params.Ether)}
+	}
+	return &Genesis{Config: params.TestChainConfig, Alloc: alloc, BaseFee: big.NewInt(params.InitialBaseFee)}, keys
+}
+
+// Tests that blocks mixing independent and conflicting transactions import with
+// parallel execution enabled, producing the same state and receipts as serially.
+func TestParallelExecution(t *testing.T) {
+	gspec, keys := newParallelTestGenesis(8)
+	signer := types.LatestSigner(gspec.Config)
+
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 4, func(i int, block *BlockGen) {
+		send := func(key *ecdsa.PrivateKey, to *common.Address, value int64, data []byte) {
+			from := crypto.PubkeyToAddress(key.PublicKey)
+			tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+				ChainID:   gspec.Config.ChainID,
+				Nonce:     block.TxNonce(from),
+				To:        to,
+				Value:     big.NewInt(value),
+				Gas:       100000,
+				GasFeeCap: new(big.Int).Mul(block.header.BaseFee, big.NewInt(2)),
+				GasTipCap: big.NewInt(int64(i + 1)),
+				Data:      data,
+			})
+			block.AddTx(tx)
+		}
+		// Independent storage writes of separate senders
+		for _, key := range keys[:6] {
+			send(key, &parallelStorer, 0, nil)
+		}
+		// Transfers to fresh accounts and conflicting counter increments
+		send(keys[6], &common.Address{byte(i), 0x01}, 1000, nil)
+		send(keys[6], &parallelCounter, 0, nil)
+		send(keys[7], &parallelCounter, 0, nil)
+		send(keys[0], &parallelCounter, 0, nil)
+
+		// Contract creation deploying the counter code
+		send(keys[7], nil, 0, deployCode(parallelCounterCode))
+	})
+	serial, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer serial.Stop()
+
+	config := *defaultCacheConfig
+	config.ParallelExecution = true
+	parallel, _ := NewBlockChain(rawdb.NewMemoryDatabase(), &config, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer parallel.Stop()
+
+	if _, err := serial.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import blocks serially: %v", err)
+	}
+	if _, err := parallel.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import blocks in parallel: %v", err)
+	}
+	for _, block := range blocks {
+		have := parallel.GetReceiptsByHash(block.Hash())
+		want := serial.GetReceiptsByHash(block.Hash())
+		if len(have) != len(want) {
+			t.Fatalf("block %d: receipt count mismatch: have %d, want %d", block.NumberU64(), len(have), len(want))
+		}
+		for i := range want {
+			if len(have[i].Logs) != len(want[i].Logs) {
+				t.Fatalf("block %d, tx %d: log count mismatch: have %d, want %d", block.NumberU64(), i, len(have[i].Logs), len(want[i].Logs))
+			}
+			for j := range want[i].Logs {
+				if have[i].Logs[j].Index != want[i].Logs[j].Index {
+					t.Errorf("block %d, tx %d: log index mismatch: have %d, want %d", block.NumberU64(), i, have[i].Logs[j].Index, want[i].Logs[j].Index)
+				}
+			}
+		}
+	}
+	state, _ := parallel.State()
+	if have := state.GetState(parallelCounter, common.Hash{}); have != common.BigToHash(big.NewInt(12)) {
+		t.Errorf("counter mismatch: have %x, want %d", have, 12)
+	}
+}
+
+// deployCode wraps runtime code into a minimal contract constructor returning it.
+func deployCode(code []byte) []byte {
+	// PUSH1 len, DUP1, PUSH1 12, PUSH1 0, CODECOPY, PUSH1 0, RETURN
+	return append([]byte{0x60, byte(len(code)), 0x80, 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3, 0x00}, code...)
+}
+
+// Tests that speculative executions are reported conflicting exactly when they
+// access state written by the transactions preceding them.
+func TestParallelConflicts(t *testing.T) {
+	gspec, keys := newParallelTestGenesis(3)
+
+	db := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(db)
+	statedb, _ := state.New(genesis.Root(), state.NewDatabase(db), nil)
+
+	var (
+		coinbase = common.Address{0xcb}
+		header   = &types.Header{
+			ParentHash: genesis.Hash(),
+			Number:     big.NewInt(1),
+			GasLimit:   genesis.GasLimit(),
+			BaseFee:    big.NewInt(params.InitialBaseFee),
+			Difficulty: big.NewInt(1),
+			Coinbase:   coinbase,
+		}
+		signer = types.LatestSigner(gspec.Config)
+		evm    = vm.NewEVM(NewEVMBlockContext(header, nil, &coinbase), vm.TxContext{}, nil, gspec.Config, vm.Config{})
+	)
+	call := func(key *ecdsa.PrivateKey, nonce uint64, to common.Address, value int64) *types.Transaction {
+		tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   gspec.Config.ChainID,
+			Nonce:     nonce,
+			To:        &to,
+			Value:     big.NewInt(value),
+			Gas:       100000,
+			GasFeeCap: big.NewInt(2 * params.InitialBaseFee),
+			GasTipCap: big.NewInt(1),
+		})
+		return tx
+	}
+	tests := []struct {
+		prev, next *types.Transaction
+		conflict   bool
+	}{
+		// Distinct senders writing distinct slots of the same contract
+		{call(keys[0], 0, parallelStorer, 0), call(keys[1], 0, parallelStorer, 0), false},
+		// Distinct senders incrementing the same counter
+		{call(keys[0], 0, parallelCounter, 0), call(keys[1], 0, parallelCounter, 0), true},
+		// Same sender
+		{call(keys[0], 0, parallelStorer, 0), call(keys[0], 1, parallelStorer, 0), true},
+		// Transfer to the sender of the next
+		{call(keys[0], 0, crypto.PubkeyToAddress(keys[2].PublicKey), 1000), call(keys[2], 0, parallelStorer, 0), true},
+	}
+	for i, tt := range tests {
+		var (
+			prev     = speculate(tt.prev, 0, header, signer, statedb.Copy(), evm)
+			next     = speculate(tt.next, 1, header, signer, statedb.Copy(), evm)
+			accounts = make(map[common.Address]struct{})
+			slots    = make(map[storageKey]struct{})
+		)
+		if prev.err != nil {
+			t.Fatalf("test %d: failed to speculate: %v", i, prev.err)
+		}
+		prev.tracker.record(accounts, slots)
+		if _, ok := accounts[coinbase]; !ok {
+			t.Errorf("test %d: coinbase fee not recorded", i)
+		}
+		// Failing speculations (e.g. nonce gaps) are re-executed, same as conflicts
+		if conflict := next.err != nil || next.tracker.conflicts(accounts, slots); conflict != tt.conflict {
+			t.Errorf("test %d: conflict mismatch: have %v, want %v", i, conflict, tt.conflict)
+		}
+	}
+}
@@ -359,7 +359,7 @@ func TestStateProcessorErrors(t *testing.T) {
 func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Transactions, config *params.ChainConfig) *types.Block {
 	difficulty := big.NewInt(0)
 	if !config.TerminalTotalDifficultyPassed {
-		difficulty = engine.CalcDifficulty(&fakeChainReader{config}, parent.Time()+10, &types.Header{
+		difficulty = engine.CalcDifficulty(&fakeChainReader{config: config}, parent.Time()+10, &types.Header{
 			Number:     parent.Number(),
 			Time:       parent.Time(),
 			Difficulty: parent.Difficulty(),
@@ -410,6 +410,9 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 		used := uint64(nBlobs * params.BlobTxBlobGasPerBlob)
 		header.ExcessBlobGas = &excess
 		header.BlobGasUsed = &used
+
+		beaconRoot := common.HexToHash("0xbeac00")
+		header.ParentBeaconRoot = &beaconRoot
 	}
 	// Assemble and return the final block for sealing
 	if config.IsShanghai(header.Number, header.Time) {
@@ -417,3 +420,65 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 	}
 	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil))
 }
+
+// Tests that the parent beacon root of cancun blocks is stored in the beacon
+// roots contract before the transactions are executed, and that the resulting
+// blocks are accepted on import.
+func TestProcessBeaconBlockRoot(t *testing.T) {
+	var (
+		config = &params.ChainConfig{
+			ChainID:                       big.NewInt(1),
+			HomesteadBlock:                big.NewInt(0),
+			EIP150Block:                   big.NewInt(0),
+			EIP155Block:                   big.NewInt(0),
+			EIP158Block:                   big.NewInt(0),
+			ByzantiumBlock:                big.NewInt(0),
+			ConstantinopleBlock:           big.NewInt(0),
+			PetersburgBlock:               big.NewInt(0),
+			IstanbulBlock:                 big.NewInt(0),
+			MuirGlacierBlock:              big.NewInt(0),
+			BerlinBlock:                   big.NewInt(0),
+			LondonBlock:                   big.NewInt(0),
+			Ethash:                        new(params.EthashConfig),
+			TerminalTotalDifficulty:       big.NewInt(0),
+			TerminalTotalDifficultyPassed: true,
+			ShanghaiTime:                  new(uint64),
+			CancunTime:                    new(uint64),
+		}
+		gspec = &Genesis{
+			Config: config,
+			Alloc: GenesisAlloc{
+				params.BeaconRootsStorageAddress: {Code: params.BeaconRootsCode, Balance: common.Big0},
+			},
+		}
+		engine = beacon.New(ethash.NewFaker())
+		root   = common.HexToHash("0x4242")
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 1, func(i int, b *BlockGen) {
+		b.SetPoS()
+		b.SetParentBeaconRoot(root)
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	statedb, err := chain.State()
+	if err != nil {
+		t.Fatalf("failed to retrieve state: %v", err)
+	}
+	var (
+		timeIndex = common.BigToHash(new(big.Int).SetUint64(blocks[0].Time() % 8191))
+		rootIndex = common.BigToHash(new(big.Int).SetUint64(blocks[0].Time()%8191 + 8191))
+	)
+	if have, want := statedb.GetState(params.BeaconRootsStorageAddress, timeIndex), common.BigToHash(new(big.Int).SetUint64(blocks[0].Time())); have != want {
+		t.Fatalf("timestamp mismatch: have %x, want %x", have, want)
+	}
+	if have := statedb.GetState(params.BeaconRootsStorageAddress, rootIndex); have != root {
+		t.Fatalf("beacon root mismatch: have %x, want %x", have, root)
+	}
+}
@@ -318,6 +318,7 @@ type BlobPool struct {
 	evict  *evictHeap                       // Heap of cheapest accounts for eviction when full
 
 	eventFeed  event.Feed              // Event feed to send out new tx events on pool inclusion
+	dropFeed   event.Feed              // Event feed to send out dropped tx events on pool removal
 	eventScope event.SubscriptionScope // Event scope to track and mass unsubscribe on termination
 
 	lock sync.RWMutex // Mutex protecting the pool during reorg handling
@@ -754,6 +755,10 @@ func (p *BlobPool) Reset(oldHead, newHead *types.Header) {
 	p.head = newHead
 	p.state = statedb
 
+	// Pick up any fork scheduled on the live chain config since the last reset,
+	// such as those activated by a signed fork override
+	p.signer = types.LatestSigner(p.chain.Config())
+
 	// Run the reorg between the old and new head and figure out which accounts
 	// need to be rechecked and which transactions need to be readded
 	if reinject, inclusions := p.reorg(oldHead, newHead); reinject != nil {
@@ -1065,6 +1070,7 @@ func (p *BlobPool) validateTx(tx *types.Transaction, blobs []kzg4844.Blob, commi
 			}
 			return nil
 		},
+		Policies: p.config.Policies,
 	}
 	if err := txpool.ValidateTransactionWithState(tx, p.signer, stateOpts); err != nil {
 		return err
@@ -1078,30 +1084,15 @@ func (p *BlobPool) validateTx(tx *types.Transaction, blobs []kzg4844.Blob, commi
 	if uint64(len(p.index[from])) > tx.Nonce()-next {
 		// Account can support the replacement, but the price bump must also be met
 		prev := p.index[from][int(tx.Nonce()-next)]
-		switch {
-		case tx.GasFeeCapIntCmp(prev.execFeeCap.ToBig()) <= 0:
-			return fmt.Errorf("%w: new tx gas fee cap %v <= %v queued", txpool.ErrReplaceUnderpriced, tx.GasFeeCap(), prev.execFeeCap)
-		case tx.GasTipCapIntCmp(prev.execTipCap.ToBig()) <= 0:
-			return fmt.Errorf("%w: new tx gas tip cap %v <= %v queued", txpool.ErrReplaceUnderpriced, tx.GasTipCap(), prev.execTipCap)
-		case tx.BlobGasFeeCapIntCmp(prev.blobFeeCap.ToBig()) <= 0:
-			return fmt.Errorf("%w: new tx blob gas fee cap %v <= %v queued", txpool.ErrReplaceUnderpriced, tx.BlobGasFeeCap(), prev.blobFeeCap)
-		}
-		var (
-			multiplier = uint256.NewInt(100 + p.config.PriceBump)
-			onehundred = uint256.NewInt(100)
-
-			minGasFeeCap     = new(uint256.Int).Div(new(uint256.Int).Mul(multiplier, prev.execFeeCap), onehundred)
-			minGasTipCap     = new(uint256.Int).Div(new(uint256.Int).Mul(multiplier, prev.execTipCap), onehundred)
-			minBlobGasFeeCap = new(uint256.Int).Div(new(uint256.Int).Mul(multiplier, prev.blobFeeCap), onehundred)
-		)
-		switch {
-		case tx.GasFeeCapIntCmp(minGasFeeCap.ToBig()) < 0:
-			return fmt.Errorf("%w: new tx gas fee cap %v <= %v queued + %d%% replacement penalty", txpool.ErrReplaceUnderpriced, tx.GasFeeCap(), prev.execFeeCap, p.config.PriceBump)
-		case tx.GasTipCapIntCmp(minGasTipCap.ToBig()) < 0:
-			return fmt.Errorf("%w: new tx gas tip cap %v <= %v queued + %d%% replacement penalty", txpool.ErrReplaceUnderpriced, tx.GasTipCap(), prev.execTipCap, p.config.PriceBump)
-		case tx.BlobGasFeeCapIntCmp(minBlobGasFeeCap.ToBig()) < 0:
-			return fmt.Errorf("%w: new tx blob gas fee cap %v <= %v queued + %d%% replacement penalty", txpool.ErrReplaceUnderpriced, tx.BlobGasFeeCap(), prev.blobFeeCap, p.config.PriceBump)
+		policy := txpool.ReplacementPolicy{
+			PriceBump:   p.config.PriceBump,
+			StrictNonce: p.config.StrictNonce,
 		}
+		return policy.Check(txpool.ReplacementFees{
+			GasFeeCap:     prev.execFeeCap.ToBig(),
+			GasTipCap:     prev.execTipCap.ToBig(),
+			BlobGasFeeCap: prev.blobFeeCap.ToBig(),
+		}, tx)
 	}
 	return nil
 }
@@ -1475,6 +1466,12 @@ func (p *BlobPool) SubscribeTransactions(ch chan<- core.NewTxsEvent) event.Subsc
 	return p.eventScope.Track(p.eventFeed.Subscribe(ch))
 }
 
+// SubscribeDropTransactions registers a subscription of DropTxsEvent and
+// starts sending event to the given channel.
+func (p *BlobPool) SubscribeDropTransactions(ch chan<- core.DropTxsEvent) event.Subscription {
+	return p.eventScope.Track(p.dropFeed.Subscribe(ch))
+}
+
 // Nonce returns the next nonce of an account, with all transactions executable
 // by the pool already applied on top.
 func (p *BlobPool) Nonce(addr common.Address) uint64 {
@@ -17,14 +17,18 @@
 package blobpool
 
 import (
+	"github.com/gorievm/go-gori/core/txpool"
 	"github.com/gorievm/go-gori/log"
 )
 
 // Config are the configuration parameters of the blob transaction pool.
 type Config struct {
-	Datadir   string // Data directory containing the currently executable blobs
-	Datacap   uint64 // Soft-cap of database storage (hard cap is larger due to overhead)
-	PriceBump uint64 // Minimum price bump percentage to replace an already existing nonce
+	Datadir     string // Data directory containing the currently executable blobs
+	Datacap     uint64 // Soft-cap of database storage (hard cap is larger due to overhead)
+	PriceBump   uint64 // Minimum price bump percentage to replace an already existing nonce
+	StrictNonce bool   `toml:",omitempty"` // Whether to reject replacements, keeping the first transaction seen for a nonce
+
+	Policies []txpool.AdmissionPolicy `toml:"-"` // Custom rules transactions must satisfy to enter the pool
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
@@ -0,0 +1,249 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/metrics"
+)
+
+const (
+	// MaxBundleSize is the maximum number of transactions in a single bundle.
+	MaxBundleSize = 16
+
+	// maxBundles is the maximum number of bundles tracked by the pool, to bound
+	// the work the miner spends on trying to include them.
+	maxBundles = 256
+
+	// bundleLifetime is the number of blocks a bundle without an explicit block
+	// range is kept around, waiting for inclusion.
+	bundleLifetime = 25
+)
+
+var (
+	// ErrBundleEmpty is returned if a bundle without transactions is submitted.
+	ErrBundleEmpty = errors.New("empty bundle")
+
+	// ErrBundleTooLarge is returned if a bundle contains more transactions than
+	// allowed by MaxBundleSize.
+	ErrBundleTooLarge = errors.New("bundle too large")
+
+	// ErrBundleRange is returned if a bundle's block range is invalid or already
+	// in the past.
+	ErrBundleRange = errors.New("invalid bundle block range")
+
+	// ErrBundlePoolFull is returned if the bundle limit of the pool is reached.
+	ErrBundlePoolFull = errors.New("bundle pool full")
+)
+
+var (
+	bundleAddMeter      = metrics.NewRegisteredMeter("txpool/bundles/add", nil)
+	bundleIncludedMeter = metrics.NewRegisteredMeter("txpool/bundles/included", nil)
+	bundleExpiredMeter  = metrics.NewRegisteredMeter("txpool/bundles/expired", nil)
+	bundleGauge         = metrics.NewRegisteredGauge("txpool/bundles", nil)
+)
+
+// Bundle is an ordered group of transactions that are only to be included into
+// a block all together, back to back in the given order, or not at all.
+//
+// Bundled transactions are kept apart from the subpools: they are not gossiped,
+// not promoted individually and only handed over to the miner as a whole.
+type Bundle struct {
+	Txs      []*types.Transaction // Transactions to include, in execution order
+	MinBlock uint64               // First block the bundle may be included in (0 = next block)
+	MaxBlock uint64               // Last block the bundle may be included in (0 = until expired)
+
+	hash    common.Hash // Cached identifier of the bundle
+	expires uint64      // Last block the bundle is kept around for
+	time    time.Time   // Time the bundle was submitted
+}
+
+// Hash returns the identifier of the bundle, the hash of the concatenation of
+// the hashes of its transactions.
+func (b *Bundle) Hash() common.Hash {
+	if b.hash == (common.Hash{}) {
+		hashes := make([]byte, 0, len(b.Txs)*common.HashLength)
+		for _, tx := range b.Txs {
+			hashes = append(hashes, tx.Hash().Bytes()...)
+		}
+		b.hash = crypto.Keccak256Hash(hashes)
+	}
+	return b.hash
+}
+
+// Gas returns the total gas limit of the bundled transactions.
+func (b *Bundle) Gas() uint64 {
+	var gas uint64
+	for _, tx := range b.Txs {
+		gas += tx.Gas()
+	}
+	return gas
+}
+
+// validate checks the stateless validity of a bundle submitted on top of the
+// given head block.
+func (b *Bundle) validate(head *types.Header) error {
+	if len(b.Txs) == 0 {
+		return ErrBundleEmpty
+	}
+	if len(b.Txs) > MaxBundleSize {
+		return fmt.Errorf("%w: %d transactions, max %d", ErrBundleTooLarge, len(b.Txs), MaxBundleSize)
+	}
+	seen := make(map[common.Hash]struct{}, len(b.Txs))
+	for i, tx := range b.Txs {
+		// Blob transactions need their sidecars tracked by the blob pool, they
+		// can't be bundled for now
+		if tx.Type() == types.BlobTxType {
+			return fmt.Errorf("tx %d: %w: blob transactions can't be bundled", i, core.ErrTxTypeNotSupported)
+		}
+		if _, ok := seen[tx.Hash()]; ok {
+			return fmt.Errorf("tx %d: %w: duplicate transaction", i, ErrAlreadyKnown)
+		}
+		seen[tx.Hash()] = struct{}{}
+
+		if _, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err != nil {
+			return fmt.Errorf("tx %d: %w", i, ErrInvalidSender)
+		}
+	}
+	if gas := b.Gas(); gas > head.GasLimit {
+		return fmt.Errorf("%w: bundle gas %d, block gas limit %d", ErrGasLimit, gas, head.GasLimit)
+	}
+	next := head.Number.Uint64() + 1
+	if b.MaxBlock != 0 && (b.MaxBlock < next || b.MaxBlock < b.MinBlock) {
+		return fmt.Errorf("%w: [%d, %d], next block %d", ErrBundleRange, b.MinBlock, b.MaxBlock, next)
+	}
+	return nil
+}
+
+// bundleSet tracks the bundles awaiting inclusion, dropping them once included
+// or expired.
+type bundleSet struct {
+	head    *types.Header        // Current head of the chain the bundles are tracked on
+	bundles []*Bundle            // Bundles in submission order
+	known   map[common.Hash]bool // Identifiers of the tracked bundles
+	lock    sync.RWMutex
+}
+
+// newBundleSet creates an empty bundle set tracking bundles on top of the given
+// head block.
+func newBundleSet(head *types.Header) *bundleSet {
+	return &bundleSet{
+		head:  head,
+		known: make(map[common.Hash]bool),
+	}
+}
+
+// add validates and inserts a bundle into the set.
+func (s *bundleSet) add(bundle *Bundle) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := bundle.validate(s.head); err != nil {
+		return err
+	}
+	hash := bundle.Hash()
+	if s.known[hash] {
+		return ErrAlreadyKnown
+	}
+	if len(s.bundles) >= maxBundles {
+		return ErrBundlePoolFull
+	}
+	bundle.expires = bundle.MaxBlock
+	if bundle.expires == 0 {
+		bundle.expires = s.head.Number.Uint64() + bundleLifetime
+		if bundle.MinBlock > s.head.Number.Uint64() {
+			bundle.expires = bundle.MinBlock + bundleLifetime - 1
+		}
+	}
+	bundle.time = time.Now()
+
+	s.bundles = append(s.bundles, bundle)
+	s.known[hash] = true
+
+	bundleAddMeter.Mark(1)
+	bundleGauge.Update(int64(len(s.bundles)))
+	log.Debug("Added transaction bundle", "hash", hash, "txs", len(bundle.Txs), "min", bundle.MinBlock, "max", bundle.MaxBlock)
+	return nil
+}
+
+// pending returns the bundles that may be included into the block with the
+// given number, in submission order.
+func (s *bundleSet) pending(number uint64) []*Bundle {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var bundles []*Bundle
+	for _, bundle := range s.bundles {
+		if bundle.MinBlock <= number && number <= bundle.expires {
+			bundles = append(bundles, bundle)
+		}
+	}
+	return bundles
+}
+
+// reset updates the head of the set, dropping all the bundles which had any of
+// their transactions included in the new head block or which expired.
+func (s *bundleSet) reset(block *types.Block) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.head = block.Header()
+	if len(s.bundles) == 0 {
+		return
+	}
+	var (
+		number   = block.NumberU64()
+		included = make(map[common.Hash]struct{}, len(block.Transactions()))
+		kept     = s.bundles[:0]
+	)
+	for _, tx := range block.Transactions() {
+		included[tx.Hash()] = struct{}{}
+	}
+bundles:
+	for _, bundle := range s.bundles {
+		// Any included transaction invalidates the nonces of the entire bundle,
+		// even if it was included outside of it
+		for _, tx := range bundle.Txs {
+			if _, ok := included[tx.Hash()]; ok {
+				bundleIncludedMeter.Mark(1)
+				delete(s.known, bundle.Hash())
+				continue bundles
+			}
+		}
+		if bundle.expires <= number {
+			log.Debug("Dropping expired transaction bundle", "hash", bundle.Hash(), "expires", bundle.expires, "age", common.PrettyDuration(time.Since(bundle.time)))
+			bundleExpiredMeter.Mark(1)
+			delete(s.known, bundle.Hash())
+			continue
+		}
+		kept = append(kept, bundle)
+	}
+	for i := len(kept); i < len(s.bundles); i++ {
+		s.bundles[i] = nil
+	}
+	s.bundles = kept
+	bundleGauge.Update(int64(len(s.bundles)))
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/params"
+	"github.com/gorievm/go-gori/trie"
+)
+
+// makeBundleTxs creates a batch of signed transfers with consecutive nonces.
+func makeBundleTxs(nonce uint64, n int) []*types.Transaction {
+	var (
+		key, _ = crypto.GenerateKey()
+		signer = types.LatestSigner(params.TestChainConfig)
+		to     = common.Address{0x01}
+	)
+
+	txs := make([]*types.Transaction, n)
+	for i := range txs {
+		txs[i] = types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			Nonce:     nonce + uint64(i),
+			To:        &to,
+			Gas:       params.TxGas,
+			GasFeeCap: big.NewInt(params.InitialBaseFee),
+			GasTipCap: big.NewInt(1),
+		})
+	}
+	return txs
+}
+
+// Tests that invalid bundles are rejected by the bundle set.
+func TestBundleValidation(t *testing.T) {
+	head := &types.Header{Number: big.NewInt(10), GasLimit: 5 * params.TxGas}
+	txs := makeBundleTxs(0, 6)
+
+	unsigned := types.NewTx(&types.LegacyTx{Nonce: 0, Gas: params.TxGas, GasPrice: big.NewInt(1), V: big.NewInt(27), R: new(big.Int), S: new(big.Int)})
+	blob := types.NewTx(&types.BlobTx{Gas: params.TxGas})
+
+	tests := []struct {
+		bundle *Bundle
+		err    error
+	}{
+		{&Bundle{}, ErrBundleEmpty},
+		{&Bundle{Txs: makeBundleTxs(0, MaxBundleSize+1)}, ErrBundleTooLarge},
+		{&Bundle{Txs: []*types.Transaction{txs[0], txs[0]}}, ErrAlreadyKnown},
+		{&Bundle{Txs: []*types.Transaction{txs[0], unsigned}}, ErrInvalidSender},
+		{&Bundle{Txs: []*types.Transaction{blob}}, core.ErrTxTypeNotSupported},
+		{&Bundle{Txs: txs}, ErrGasLimit},
+		{&Bundle{Txs: txs[:1], MaxBlock: 10}, ErrBundleRange},
+		{&Bundle{Txs: txs[:1], MinBlock: 15, MaxBlock: 14}, ErrBundleRange},
+		{&Bundle{Txs: txs[:5], MaxBlock: 11}, nil},
+	}
+	for i, tt := range tests {
+		set := newBundleSet(head)
+		if err := set.add(tt.bundle); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	// Duplicate bundles should be rejected too
+	set := newBundleSet(head)
+	if err := set.add(&Bundle{Txs: txs[:2]}); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	if err := set.add(&Bundle{Txs: txs[:2]}); !errors.Is(err, ErrAlreadyKnown) {
+		t.Errorf("duplicate bundle error mismatch: have %v, want %v", err, ErrAlreadyKnown)
+	}
+}
+
+// Tests that bundles are only handed out within their block range, and that they
+// are dropped once included or expired.
+func TestBundleLifecycle(t *testing.T) {
+	head := &types.Header{Number: big.NewInt(10), GasLimit: params.GenesisGasLimit}
+	set := newBundleSet(head)
+
+	var (
+		ranged   = &Bundle{Txs: makeBundleTxs(0, 2), MinBlock: 12, MaxBlock: 13}
+		included = &Bundle{Txs: makeBundleTxs(0, 2)}
+		expiring = &Bundle{Txs: makeBundleTxs(0, 2)}
+	)
+	for _, bundle := range []*Bundle{ranged, included, expiring} {
+		if err := set.add(bundle); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+	}
+	check := func(number uint64, want ...*Bundle) {
+		t.Helper()
+
+		have := set.pending(number)
+		if len(have) != len(want) {
+			t.Fatalf("block %d: bundle count mismatch: have %d, want %d", number, len(have), len(want))
+		}
+		for i := range have {
+			if have[i] != want[i] {
+				t.Errorf("block %d: bundle %d mismatch: have %x, want %x", number, i, have[i].Hash(), want[i].Hash())
+			}
+		}
+	}
+	check(11, included, expiring)
+	check(12, ranged, included, expiring)
+	check(14, included, expiring)
+
+	// Include the second transaction of a bundle only, it should still drop
+	block := types.NewBlock(&types.Header{Number: big.NewInt(11)}, types.Transactions{included.Txs[1]}, nil, nil, trie.NewStackTrie(nil))
+	set.reset(block)
+	check(12, ranged, expiring)
+
+	// Move past the block range and the lifetime of the remaining bundles
+	set.reset(types.NewBlock(&types.Header{Number: big.NewInt(13)}, nil, nil, nil, trie.NewStackTrie(nil)))
+	check(13, expiring)
+
+	set.reset(types.NewBlock(&types.Header{Number: big.NewInt(10 + bundleLifetime)}, nil, nil, nil, trie.NewStackTrie(nil)))
+	check(10 + bundleLifetime + 1)
+	if len(set.known) != 0 {
+		t.Errorf("leftover bundle identifiers: %d", len(set.known))
+	}
+}
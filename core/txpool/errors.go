@@ -34,6 +34,10 @@ var (
 	// with a different one without the required price bump.
 	ErrReplaceUnderpriced = errors.New("replacement transaction underpriced")
 
+	// ErrReplaceDisabled is returned if a transaction is attempted to be replaced
+	// while the pool is configured to keep the first transaction seen for a nonce.
+	ErrReplaceDisabled = errors.New("transaction replacement disabled")
+
 	// ErrAccountLimitExceeded is returned if a transaction would exceed the number
 	// allowed by a pool for a single account.
 	ErrAccountLimitExceeded = errors.New("account limit exceeded")
@@ -54,4 +58,8 @@ var (
 	// ErrFutureReplacePending is returned if a future transaction replaces a pending
 	// transaction. Future transactions should only be able to replace other future transactions.
 	ErrFutureReplacePending = errors.New("future transaction tries to replace pending")
+
+	// ErrPolicyRejected is returned if a transaction is refused by one of the
+	// admission policies configured by the node operator.
+	ErrPolicyRejected = errors.New("rejected by admission policy")
 )
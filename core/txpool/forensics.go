@@ -0,0 +1,437 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/event"
+	"github.com/gorievm/go-gori/log"
+)
+
+const (
+	// snapshotPrefix and snapshotSuffix delimit the file names of the pool
+	// snapshots, which embed the creation time and the anomaly detected.
+	snapshotPrefix = "pool-"
+	snapshotSuffix = ".json"
+
+	// dropChanSize is the size of channel listening to DropTxsEvent.
+	dropChanSize = 1024
+)
+
+// errSnapshotNotFound is returned if a requested pool snapshot doesn't exist.
+var errSnapshotNotFound = errors.New("snapshot not found")
+
+// ForensicsConfig are the configuration parameters of the transaction pool
+// forensics, snapshotting the pool to disk when anomalies are detected.
+type ForensicsConfig struct {
+	Enabled   bool   // Whether to monitor the pool for anomalies
+	Dir       string // Directory to store the pool snapshots in
+	Retention int    // Number of most recent snapshots to retain
+
+	Window   time.Duration // Time window the anomaly counters are measured over
+	Cooldown time.Duration // Minimum time between two consecutive snapshots
+
+	EvictionLimit uint64 // Number of evictions within a window deemed a mass eviction
+	InvalidLimit  uint64 // Number of rejected transactions within a window deemed a flood
+	FeeSpikeRatio uint64 // Growth of the median pending tip between windows deemed a spike
+}
+
+// DefaultForensicsConfig contains the default configurations for the transaction
+// pool forensics.
+var DefaultForensicsConfig = ForensicsConfig{
+	Dir:       "txpool-snapshots",
+	Retention: 16,
+
+	Window:   time.Minute,
+	Cooldown: 10 * time.Minute,
+
+	EvictionLimit: 2048,
+	InvalidLimit:  4096,
+	FeeSpikeRatio: 4,
+}
+
+// sanitize checks the provided user configurations and changes anything that's
+// unreasonable or unworkable.
+func (config *ForensicsConfig) sanitize() ForensicsConfig {
+	conf := *config
+	if conf.Retention < 1 {
+		log.Warn("Sanitizing invalid txpool snapshot retention", "provided", conf.Retention, "updated", DefaultForensicsConfig.Retention)
+		conf.Retention = DefaultForensicsConfig.Retention
+	}
+	if conf.Window < time.Second {
+		log.Warn("Sanitizing invalid txpool anomaly window", "provided", conf.Window, "updated", DefaultForensicsConfig.Window)
+		conf.Window = DefaultForensicsConfig.Window
+	}
+	if conf.EvictionLimit < 1 {
+		log.Warn("Sanitizing invalid txpool eviction limit", "provided", conf.EvictionLimit, "updated", DefaultForensicsConfig.EvictionLimit)
+		conf.EvictionLimit = DefaultForensicsConfig.EvictionLimit
+	}
+	if conf.InvalidLimit < 1 {
+		log.Warn("Sanitizing invalid txpool invalid limit", "provided", conf.InvalidLimit, "updated", DefaultForensicsConfig.InvalidLimit)
+		conf.InvalidLimit = DefaultForensicsConfig.InvalidLimit
+	}
+	if conf.FeeSpikeRatio < 2 {
+		log.Warn("Sanitizing invalid txpool fee spike ratio", "provided", conf.FeeSpikeRatio, "updated", DefaultForensicsConfig.FeeSpikeRatio)
+		conf.FeeSpikeRatio = DefaultForensicsConfig.FeeSpikeRatio
+	}
+	return conf
+}
+
+// PoolSnapshot is the content of the transaction pool along with the statistics
+// leading up to it, captured when an anomaly was detected.
+type PoolSnapshot struct {
+	Time      time.Time                        `json:"time"`
+	Reason    string                           `json:"reason"`
+	Pending   map[common.Address][]*SnapshotTx `json:"pending"`
+	Queued    map[common.Address][]*SnapshotTx `json:"queued"`
+	Admission AdmissionStats                   `json:"admission"` // Admission statistics since startup
+	Invalid   uint64                           `json:"invalid"`   // Transactions rejected within the window
+	Drops     map[string]uint64                `json:"drops"`     // Transactions dropped within the window
+	MedianTip *hexutil.Big                     `json:"medianTip"`
+	PrevTip   *hexutil.Big                     `json:"prevMedianTip"`
+}
+
+// SnapshotTx is the summary of a pooled transaction stored in a snapshot.
+type SnapshotTx struct {
+	Hash      common.Hash    `json:"hash"`
+	Type      hexutil.Uint64 `json:"type"`
+	Nonce     hexutil.Uint64 `json:"nonce"`
+	GasFeeCap *hexutil.Big   `json:"maxFeePerGas"`
+	GasTipCap *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	Gas       hexutil.Uint64 `json:"gas"`
+	Size      hexutil.Uint64 `json:"size"`
+}
+
+// SnapshotInfo is the metadata of a pool snapshot stored on disk.
+type SnapshotInfo struct {
+	ID     string    `json:"id"`
+	Time   time.Time `json:"time"`
+	Reason string    `json:"reason"`
+	Size   uint64    `json:"size"`
+}
+
+// forensicsBackend is the part of the transaction pool the forensics monitor
+// needs access to. Exists to allow mocking the pool out of tests.
+type forensicsBackend interface {
+	Content() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction)
+	AdmissionStats() AdmissionStats
+	SubscribeDropTxsEvent(ch chan<- core.DropTxsEvent) event.Subscription
+}
+
+// Forensics monitors the transaction pool for anomalies - mass evictions, fee
+// spikes and invalid transaction floods - and snapshots the pool contents and
+// admission statistics to disk when one is detected, aiding the postmortem of
+// mempool incidents.
+type Forensics struct {
+	config ForensicsConfig
+	pool   forensicsBackend
+
+	drops    map[core.TxDropReason]uint64 // Transactions dropped within the current window
+	stats    AdmissionStats               // Admission statistics at the start of the window
+	tip      *big.Int                     // Median pending tip at the start of the window
+	snapshot time.Time                    // Time of the last snapshot taken
+
+	lock sync.Mutex // Lock serializing the snapshot writes and reads
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewForensics creates the forensics monitor for the given transaction pool and
+// starts watching it.
+func NewForensics(config ForensicsConfig, pool *TxPool) (*Forensics, error) {
+	f, err := newForensics(config, pool)
+	if err != nil {
+		return nil, err
+	}
+	f.wg.Add(1)
+	go f.loop()
+	return f, nil
+}
+
+// newForensics creates the forensics monitor without starting it.
+func newForensics(config ForensicsConfig, pool forensicsBackend) (*Forensics, error) {
+	config = (&config).sanitize()
+	if err := os.MkdirAll(config.Dir, 0700); err != nil {
+		return nil, err
+	}
+	f := &Forensics{
+		config: config,
+		pool:   pool,
+		drops:  make(map[core.TxDropReason]uint64),
+		stats:  pool.AdmissionStats(),
+		quit:   make(chan struct{}),
+	}
+	pending, _ := pool.Content()
+	f.tip = medianTip(pending)
+	return f, nil
+}
+
+// Close stops monitoring the transaction pool.
+func (f *Forensics) Close() {
+	close(f.quit)
+	f.wg.Wait()
+}
+
+// loop counts the dropped transactions and evaluates the anomaly counters at
+// the end of every window.
+func (f *Forensics) loop() {
+	defer f.wg.Done()
+
+	drops := make(chan core.DropTxsEvent, dropChanSize)
+	sub := f.pool.SubscribeDropTxsEvent(drops)
+	defer sub.Unsubscribe()
+
+	ticker := time.NewTicker(f.config.Window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev := <-drops:
+			f.drops[ev.Reason] += uint64(len(ev.Txs))
+
+		case <-ticker.C:
+			f.check(time.Now())
+
+		case <-sub.Err():
+			return
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+// check evaluates the anomaly counters of the window ending at the given time,
+// snapshots the pool if any of them exceed their limits and starts a new window.
+func (f *Forensics) check(now time.Time) {
+	var (
+		pending, queued = f.pool.Content()
+		stats           = f.pool.AdmissionStats()
+		tip             = medianTip(pending)
+		invalid         = rejections(stats) - rejections(f.stats)
+
+		anomalies []string
+	)
+	if f.drops[core.TxDropEvicted]+f.drops[core.TxDropUnderpriced] >= f.config.EvictionLimit {
+		anomalies = append(anomalies, "mass-eviction")
+	}
+	if invalid >= f.config.InvalidLimit {
+		anomalies = append(anomalies, "invalid-flood")
+	}
+	if f.tip.Sign() > 0 && tip.Cmp(new(big.Int).Mul(f.tip, new(big.Int).SetUint64(f.config.FeeSpikeRatio))) >= 0 {
+		anomalies = append(anomalies, "fee-spike")
+	}
+	if len(anomalies) > 0 {
+		if now.Sub(f.snapshot) < f.config.Cooldown {
+			log.Debug("Transaction pool anomaly detected in cooldown", "anomalies", anomalies)
+		} else {
+			snap := &PoolSnapshot{
+				Time:      now,
+				Reason:    strings.Join(anomalies, "+"),
+				Pending:   summarize(pending),
+				Queued:    summarize(queued),
+				Admission: stats,
+				Invalid:   invalid,
+				Drops:     make(map[string]uint64, len(f.drops)),
+				MedianTip: (*hexutil.Big)(tip),
+				PrevTip:   (*hexutil.Big)(f.tip),
+			}
+			for reason, count := range f.drops {
+				snap.Drops[reason.String()] = count
+			}
+			if id, err := f.store(snap); err != nil {
+				log.Error("Failed to snapshot transaction pool", "anomalies", anomalies, "err", err)
+			} else {
+				log.Warn("Transaction pool anomaly detected", "anomalies", anomalies, "snapshot", id)
+				f.snapshot = now
+			}
+		}
+	}
+	f.drops = make(map[core.TxDropReason]uint64)
+	f.stats, f.tip = stats, tip
+}
+
+// store writes a snapshot to disk and deletes the oldest ones beyond the
+// retention limit.
+func (f *Forensics) store(snap *PoolSnapshot) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	blob, err := json.Marshal(snap)
+	if err != nil {
+		return "", err
+	}
+	var (
+		id   = fmt.Sprintf("%s%d-%s", snapshotPrefix, snap.Time.UnixNano(), snap.Reason)
+		path = filepath.Join(f.config.Dir, id+snapshotSuffix)
+	)
+	// Write the snapshot atomically, so that a crash can't leave a corrupt one
+	if err := os.WriteFile(path+".tmp", blob, 0600); err != nil {
+		return "", err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return "", err
+	}
+	infos, err := f.list()
+	if err != nil {
+		return "", err
+	}
+	for len(infos) > f.config.Retention {
+		if err := os.Remove(filepath.Join(f.config.Dir, infos[0].ID+snapshotSuffix)); err != nil {
+			return "", err
+		}
+		infos = infos[1:]
+	}
+	return id, nil
+}
+
+// Snapshots returns the metadata of the retained pool snapshots, oldest first.
+func (f *Forensics) Snapshots() ([]SnapshotInfo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.list()
+}
+
+// list returns the metadata of the snapshots on disk sorted by creation time.
+// The caller must hold the lock.
+func (f *Forensics) list() ([]SnapshotInfo, error) {
+	entries, err := os.ReadDir(f.config.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var infos []SnapshotInfo
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), snapshotSuffix) {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), snapshotSuffix)
+
+		created, reason, ok := parseSnapshotID(id)
+		if !ok {
+			continue
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, SnapshotInfo{ID: id, Time: created, Reason: reason, Size: uint64(fi.Size())})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Time.Before(infos[j].Time) })
+	return infos, nil
+}
+
+// Snapshot loads the pool snapshot with the given identifier from disk.
+func (f *Forensics) Snapshot(id string) (*PoolSnapshot, error) {
+	if _, _, ok := parseSnapshotID(id); !ok {
+		return nil, errSnapshotNotFound
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	blob, err := os.ReadFile(filepath.Join(f.config.Dir, id+snapshotSuffix))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errSnapshotNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	snap := new(PoolSnapshot)
+	if err := json.Unmarshal(blob, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// parseSnapshotID splits a snapshot identifier into its creation time and the
+// anomalies it was taken for. Identifiers not created by the pool, including
+// any attempting to escape the snapshot directory, are rejected.
+func parseSnapshotID(id string) (time.Time, string, bool) {
+	if !strings.HasPrefix(id, snapshotPrefix) || strings.ContainsAny(id, `/\.`) {
+		return time.Time{}, "", false
+	}
+	nanos, reason, ok := strings.Cut(strings.TrimPrefix(id, snapshotPrefix), "-")
+	if !ok {
+		return time.Time{}, "", false
+	}
+	created, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return time.Unix(0, created), reason, true
+}
+
+// rejections returns the number of transactions rejected by the pool, not
+// counting the resubmissions of already pooled ones.
+func rejections(stats AdmissionStats) uint64 {
+	var count uint64
+	for reason, rejected := range stats.Rejected {
+		if reason != ErrAlreadyKnown.Error() {
+			count += rejected
+		}
+	}
+	return count
+}
+
+// medianTip returns the median tip cap of the given transactions, or zero if
+// there are none.
+func medianTip(txs map[common.Address][]*types.Transaction) *big.Int {
+	var tips []*big.Int
+	for _, list := range txs {
+		for _, tx := range list {
+			tips = append(tips, tx.GasTipCap())
+		}
+	}
+	if len(tips) == 0 {
+		return new(big.Int)
+	}
+	sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+	return new(big.Int).Set(tips[len(tips)/2])
+}
+
+// summarize converts the transactions of the pool into their snapshot summaries.
+func summarize(txs map[common.Address][]*types.Transaction) map[common.Address][]*SnapshotTx {
+	summaries := make(map[common.Address][]*SnapshotTx, len(txs))
+	for addr, list := range txs {
+		for _, tx := range list {
+			summaries[addr] = append(summaries[addr], &SnapshotTx{
+				Hash:      tx.Hash(),
+				Type:      hexutil.Uint64(tx.Type()),
+				Nonce:     hexutil.Uint64(tx.Nonce()),
+				GasFeeCap: (*hexutil.Big)(tx.GasFeeCap()),
+				GasTipCap: (*hexutil.Big)(tx.GasTipCap()),
+				Gas:       hexutil.Uint64(tx.Gas()),
+				Size:      hexutil.Uint64(tx.Size()),
+			})
+		}
+	}
+	return summaries
+}
@@ -0,0 +1,137 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/event"
+)
+
+// testForensicsPool is a mock transaction pool exposing preset contents and
+// admission statistics.
+type testForensicsPool struct {
+	pending map[common.Address][]*types.Transaction
+	stats   AdmissionStats
+	feed    event.Feed
+}
+
+func (p *testForensicsPool) Content() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
+	return p.pending, nil
+}
+
+func (p *testForensicsPool) AdmissionStats() AdmissionStats {
+	stats := AdmissionStats{Accepted: p.stats.Accepted, Rejected: make(map[string]uint64)}
+	for reason, count := range p.stats.Rejected {
+		stats.Rejected[reason] = count
+	}
+	return stats
+}
+
+func (p *testForensicsPool) SubscribeDropTxsEvent(ch chan<- core.DropTxsEvent) event.Subscription {
+	return p.feed.Subscribe(ch)
+}
+
+// setTip replaces the pending transactions with a single one with the given tip.
+func (p *testForensicsPool) setTip(tip int64) {
+	tx := types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(tip), GasFeeCap: big.NewInt(tip), Gas: 21000})
+	p.pending = map[common.Address][]*types.Transaction{{0x01}: {tx}}
+}
+
+func TestForensicsAnomalies(t *testing.T) {
+	pool := &testForensicsPool{stats: AdmissionStats{Rejected: make(map[string]uint64)}}
+	pool.setTip(1)
+
+	f, err := newForensics(ForensicsConfig{
+		Dir:           t.TempDir(),
+		Retention:     2,
+		Window:        time.Minute,
+		Cooldown:      time.Minute,
+		EvictionLimit: 10,
+		InvalidLimit:  10,
+		FeeSpikeRatio: 4,
+	}, pool)
+	if err != nil {
+		t.Fatalf("failed to create forensics: %v", err)
+	}
+	now := time.Now()
+
+	// Normal activity should not be snapshotted
+	f.drops[core.TxDropEvicted] = 5
+	pool.stats.Rejected[ErrAlreadyKnown.Error()] = 100
+	pool.stats.Rejected[ErrUnderpriced.Error()] = 5
+	pool.setTip(2)
+	f.check(now)
+
+	if infos, _ := f.Snapshots(); len(infos) != 0 {
+		t.Fatalf("snapshot taken without anomaly: %v", infos)
+	}
+	// Each anomaly should be snapshotted, respecting the cooldown
+	tests := []struct {
+		prepare func()
+		reason  string
+	}{
+		{func() { f.drops[core.TxDropEvicted], f.drops[core.TxDropUnderpriced] = 5, 5 }, "mass-eviction"},
+		{func() { pool.stats.Rejected[ErrUnderpriced.Error()] += 10 }, "invalid-flood"},
+		{func() { pool.setTip(8) }, "fee-spike"},
+	}
+	for i, tt := range tests {
+		tt.prepare()
+		now = now.Add(time.Minute)
+		f.check(now)
+
+		snap, err := f.Snapshots()
+		if err != nil {
+			t.Fatalf("test %d: failed to list snapshots: %v", i, err)
+		}
+		if len(snap) == 0 || snap[len(snap)-1].Reason != tt.reason {
+			t.Fatalf("test %d: snapshot mismatch: have %v, want reason %s", i, snap, tt.reason)
+		}
+		// Anomalies within the cooldown should not be snapshotted
+		tt.prepare()
+		f.check(now.Add(time.Second))
+
+		if again, _ := f.Snapshots(); len(again) != len(snap) {
+			t.Fatalf("test %d: snapshot taken in cooldown", i)
+		}
+	}
+	// Only the most recent snapshots should be retained and loadable
+	infos, _ := f.Snapshots()
+	if len(infos) != 2 || infos[0].Reason != "invalid-flood" || infos[1].Reason != "fee-spike" {
+		t.Fatalf("retained snapshots mismatch: %v", infos)
+	}
+	snap, err := f.Snapshot(infos[1].ID)
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %v", err)
+	}
+	if snap.MedianTip.ToInt().Int64() != 8 || len(snap.Pending[common.Address{0x01}]) != 1 {
+		t.Errorf("snapshot content mismatch: %+v", snap)
+	}
+	if snap.Admission.Rejected[ErrUnderpriced.Error()] != 25 {
+		t.Errorf("snapshot admission stats mismatch: %v", snap.Admission)
+	}
+	for _, id := range []string{"pool-1-x", "../" + infos[1].ID, "other"} {
+		if _, err := f.Snapshot(id); err != errSnapshotNotFound {
+			t.Errorf("snapshot %q: error mismatch: have %v, want %v", id, err, errSnapshotNotFound)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/gorievm/go-gori/core/types"
+)
+
+// NonceGap is an inclusive range of nonces missing from the pool, preventing
+// the queued transactions following it from becoming executable.
+type NonceGap struct {
+	From uint64
+	To   uint64
+}
+
+// SenderInspection is the summary of the transactions pooled from a single
+// sender, allowing to diagnose stuck transactions without dumping the whole
+// pool content.
+type SenderInspection struct {
+	Pending []*types.Transaction // Executable transactions, sorted by nonce
+	Queued  []*types.Transaction // Non-executable transactions, sorted by nonce
+
+	Nonce uint64     // Next nonce following the pending transactions
+	Gaps  []NonceGap // Nonce ranges missing before the queued transactions
+
+	PendingCost *big.Int // Maximum cost the pending transactions can charge the sender
+	QueuedCost  *big.Int // Maximum cost the queued transactions can charge the sender
+}
+
+// NewSenderInspection summarizes the pending and queued transactions of a
+// sender. The nonce is the next one the sender may use if it has no pending
+// transactions, otherwise it's derived from the pending ones.
+func NewSenderInspection(nonce uint64, pending []*types.Transaction, queued []*types.Transaction) *SenderInspection {
+	insp := &SenderInspection{
+		Pending:     sortByNonce(pending),
+		Queued:      sortByNonce(queued),
+		PendingCost: new(big.Int),
+		QueuedCost:  new(big.Int),
+	}
+	for _, tx := range insp.Pending {
+		insp.PendingCost.Add(insp.PendingCost, tx.Cost())
+	}
+	if len(insp.Pending) > 0 {
+		nonce = insp.Pending[len(insp.Pending)-1].Nonce() + 1
+	}
+	insp.Nonce = nonce
+
+	for _, tx := range insp.Queued {
+		insp.QueuedCost.Add(insp.QueuedCost, tx.Cost())
+		if tx.Nonce() > nonce {
+			insp.Gaps = append(insp.Gaps, NonceGap{From: nonce, To: tx.Nonce() - 1})
+		}
+		if tx.Nonce() >= nonce {
+			nonce = tx.Nonce() + 1
+		}
+	}
+	return insp
+}
+
+// sortByNonce returns a copy of the transactions sorted by nonce.
+func sortByNonce(txs []*types.Transaction) []*types.Transaction {
+	sorted := make([]*types.Transaction, len(txs))
+	copy(sorted, txs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Nonce() < sorted[j].Nonce() })
+	return sorted
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/gorievm/go-gori/core/types"
+)
+
+// inspectTx creates a transaction with the given nonce, costing 21000 + value.
+func inspectTx(nonce uint64, value int64) *types.Transaction {
+	return types.NewTx(&types.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(1), Gas: 21000, Value: big.NewInt(value)})
+}
+
+func TestSenderInspection(t *testing.T) {
+	tests := []struct {
+		nonce   uint64
+		pending []*types.Transaction
+		queued  []*types.Transaction
+
+		next    uint64
+		gaps    []NonceGap
+		pcost   int64
+		qcost   int64
+		pnonces []uint64
+	}{
+		// Empty sender should report the account nonce
+		{nonce: 3, next: 3},
+		// Pending transactions should advance the nonce
+		{
+			pending: []*types.Transaction{inspectTx(6, 1), inspectTx(5, 0)},
+			next:    7, pcost: 42001, pnonces: []uint64{5, 6},
+		},
+		// Gaps before and between queued transactions should be reported
+		{
+			nonce:  2,
+			queued: []*types.Transaction{inspectTx(9, 0), inspectTx(4, 0), inspectTx(5, 2)},
+			next:   2, qcost: 63002,
+			gaps: []NonceGap{{From: 2, To: 3}, {From: 6, To: 8}},
+		},
+		// Queued transactions following the pending ones should have no gap
+		{
+			pending: []*types.Transaction{inspectTx(0, 0)},
+			queued:  []*types.Transaction{inspectTx(1, 0), inspectTx(3, 0)},
+			next:    1, pcost: 21000, qcost: 42000, pnonces: []uint64{0},
+			gaps: []NonceGap{{From: 2, To: 2}},
+		},
+	}
+	for i, tt := range tests {
+		insp := NewSenderInspection(tt.nonce, tt.pending, tt.queued)
+		if insp.Nonce != tt.next {
+			t.Errorf("test %d: nonce mismatch: have %d, want %d", i, insp.Nonce, tt.next)
+		}
+		if !reflect.DeepEqual(insp.Gaps, tt.gaps) {
+			t.Errorf("test %d: gaps mismatch: have %v, want %v", i, insp.Gaps, tt.gaps)
+		}
+		if insp.PendingCost.Int64() != tt.pcost || insp.QueuedCost.Int64() != tt.qcost {
+			t.Errorf("test %d: cost mismatch: have %v/%v, want %d/%d", i, insp.PendingCost, insp.QueuedCost, tt.pcost, tt.qcost)
+		}
+		for j, tx := range insp.Pending {
+			if tx.Nonce() != tt.pnonces[j] {
+				t.Errorf("test %d: pending tx %d nonce mismatch: have %d, want %d", i, j, tx.Nonce(), tt.pnonces[j])
+			}
+		}
+	}
+}
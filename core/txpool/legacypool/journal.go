@@ -17,21 +17,45 @@
 package legacypool
 
 import (
+	"bufio"
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"time"
 
+	"github.com/golang/snappy"
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/log"
 	"github.com/gorievm/go-gori/rlp"
 )
 
+// journalVersion is the current version of the journal format. Version 1 is the
+// original headerless stream of raw transaction RLPs.
+const journalVersion = 2
+
+// journalMagic is the prefix of all versioned journals, followed by a version
+// byte. It can't be mistaken for the start of a legacy journal, which always
+// begins with an RLP string or list prefix.
+var journalMagic = []byte("txjournal")
+
 // errNoActiveJournal is returned if a transaction is attempted to be inserted
 // into the journal, but no such file is currently open.
 var errNoActiveJournal = errors.New("no active journal")
 
+// journalEntry is a single transaction record in a versioned journal. The
+// transaction is kept encoded, so that entries of types unknown to this node
+// can be skipped without corrupting the rest of the journal.
+type journalEntry struct {
+	Type  uint8        // Type of the transaction
+	Time  uint64       // Unix time the transaction was first seen by the pool
+	Local bool         // Whether the transaction is to be reinjected as a local one
+	Tx    rlp.RawValue // RLP encoded transaction
+}
+
 // devNull is a WriteCloser that just discards anything written into it. Its
 // goal is to allow the transaction journal to write into a fake journal when
 // loading transactions on startup without printing warnings due to no file
@@ -41,6 +65,33 @@ type devNull struct{}
 func (*devNull) Write(p []byte) (n int, err error) { return len(p), nil }
 func (*devNull) Close() error                      { return nil }
 
+// journalSink is a snappy compressed output stream into a journal file, which
+// is flushed after every write to persist journaled transactions right away.
+type journalSink struct {
+	file   *os.File
+	writer *snappy.Writer
+}
+
+func newJournalSink(file *os.File) *journalSink {
+	return &journalSink{file: file, writer: snappy.NewBufferedWriter(file)}
+}
+
+func (s *journalSink) Write(p []byte) (int, error) {
+	n, err := s.writer.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, s.writer.Flush()
+}
+
+func (s *journalSink) Close() error {
+	if err := s.writer.Close(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
 // journal is a rotating log of transactions with the aim of storing locally
 // created transactions to allow non-executed ones to survive node restarts.
 type journal struct {
@@ -56,8 +107,9 @@ func newTxJournal(path string) *journal {
 }
 
 // load parses a transaction journal dump from disk, loading its contents into
-// the specified pool.
-func (journal *journal) load(add func([]*types.Transaction) []error) error {
+// the specified pool. Legacy journals are still accepted, they are converted to
+// the current format by the first rotation.
+func (journal *journal) load(add func(txs []*types.Transaction, local bool) []error) error {
 	// Open the journal for loading any past transactions
 	input, err := os.Open(journal.path)
 	if errors.Is(err, fs.ErrNotExist) {
@@ -73,15 +125,39 @@ func (journal *journal) load(add func([]*types.Transaction) []error) error {
 	journal.writer = new(devNull)
 	defer func() { journal.writer = nil }()
 
+	// Detect the format of the journal and set up the entry decoder
+	var (
+		reader = bufio.NewReader(input)
+		next   func() (*types.Transaction, bool, error)
+	)
+	header, err := reader.Peek(len(journalMagic) + 1)
+	switch {
+	case err == nil && bytes.Equal(header[:len(journalMagic)], journalMagic):
+		if version := header[len(journalMagic)]; version != journalVersion {
+			return fmt.Errorf("unsupported journal version %d", version)
+		}
+		reader.Discard(len(header))
+		next = newJournalDecoder(rlp.NewStream(snappy.NewReader(reader), 0))
+
+	case err == nil || err == io.EOF:
+		log.Info("Migrating legacy transaction journal", "path", journal.path)
+		stream := rlp.NewStream(reader, 0)
+		next = func() (*types.Transaction, bool, error) {
+			tx := new(types.Transaction)
+			return tx, true, stream.Decode(tx)
+		}
+
+	default:
+		return err
+	}
 	// Inject all transactions from the journal into the pool
-	stream := rlp.NewStream(input, 0)
-	total, dropped := 0, 0
+	total, dropped, skipped := 0, 0, 0
 
 	// Create a method to load a limited batch of transactions and bump the
 	// appropriate progress counters. Then use this method to load all the
 	// journaled transactions in small-ish batches.
-	loadBatch := func(txs types.Transactions) {
-		for _, err := range add(txs) {
+	loadBatch := func(txs types.Transactions, local bool) {
+		for _, err := range add(txs, local) {
 			if err != nil {
 				log.Debug("Failed to add journaled transaction", "err", err)
 				dropped++
@@ -91,38 +167,89 @@ func (journal *journal) load(add func([]*types.Transaction) []error) error {
 	var (
 		failure error
 		batch   types.Transactions
+		local   bool
 	)
 	for {
 		// Parse the next transaction and terminate on error
-		tx := new(types.Transaction)
-		if err = stream.Decode(tx); err != nil {
+		tx, isLocal, err := next()
+		if err != nil {
+			if errors.Is(err, errUnsupportedEntry) {
+				skipped++
+				continue
+			}
 			if err != io.EOF {
 				failure = err
 			}
 			if batch.Len() > 0 {
-				loadBatch(batch)
+				loadBatch(batch, local)
 			}
 			break
 		}
-		// New transaction parsed, queue up for later, import if threshold is reached
+		// New transaction parsed, queue up for later, import if threshold is
+		// reached or the locality changes
 		total++
 
+		if batch.Len() > 0 && isLocal != local {
+			loadBatch(batch, local)
+			batch = batch[:0]
+		}
+		local = isLocal
 		if batch = append(batch, tx); batch.Len() > 1024 {
-			loadBatch(batch)
+			loadBatch(batch, local)
 			batch = batch[:0]
 		}
 	}
-	log.Info("Loaded local transaction journal", "transactions", total, "dropped", dropped)
+	log.Info("Loaded local transaction journal", "transactions", total, "dropped", dropped, "unsupported", skipped)
 
 	return failure
 }
 
+// errUnsupportedEntry is returned by the journal decoder for the transactions
+// of types unknown to this node, which are skipped.
+var errUnsupportedEntry = errors.New("unsupported journal entry")
+
+// newJournalDecoder creates an iterator over the entries of a versioned journal,
+// restoring the first seen time of the transactions.
+func newJournalDecoder(stream *rlp.Stream) func() (*types.Transaction, bool, error) {
+	return func() (*types.Transaction, bool, error) {
+		var entry journalEntry
+		if err := stream.Decode(&entry); err != nil {
+			return nil, false, err
+		}
+		tx := new(types.Transaction)
+		if err := rlp.DecodeBytes(entry.Tx, tx); err != nil {
+			log.Debug("Skipping undecodable journaled transaction", "type", entry.Type, "err", err)
+			return nil, false, errUnsupportedEntry
+		}
+		tx.SetTime(time.Unix(int64(entry.Time), 0))
+		return tx, entry.Local, nil
+	}
+}
+
+// encodeJournalEntry creates the journal record of a transaction.
+func encodeJournalEntry(tx *types.Transaction, local bool) ([]byte, error) {
+	blob, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(&journalEntry{
+		Type:  tx.Type(),
+		Time:  uint64(tx.Time().Unix()),
+		Local: local,
+		Tx:    blob,
+	})
+}
+
 // insert adds the specified transaction to the local disk journal.
 func (journal *journal) insert(tx *types.Transaction) error {
 	if journal.writer == nil {
 		return errNoActiveJournal
 	}
-	if err := rlp.Encode(journal.writer, tx); err != nil {
+	entry, err := encodeJournalEntry(tx, true)
+	if err != nil {
+		return err
+	}
+	if _, err := journal.writer.Write(entry); err != nil {
 		return err
 	}
 	return nil
@@ -143,16 +270,31 @@ func (journal *journal) rotate(all map[common.Address]types.Transactions) error
 	if err != nil {
 		return err
 	}
-	journaled := 0
+	if _, err = replacement.Write(append(append([]byte{}, journalMagic...), journalVersion)); err != nil {
+		replacement.Close()
+		return err
+	}
+	var (
+		writer    = snappy.NewBufferedWriter(replacement)
+		journaled = 0
+	)
 	for _, txs := range all {
 		for _, tx := range txs {
-			if err = rlp.Encode(replacement, tx); err != nil {
+			entry, err := encodeJournalEntry(tx, true)
+			if err == nil {
+				_, err = writer.Write(entry)
+			}
+			if err != nil {
 				replacement.Close()
 				return err
 			}
 		}
 		journaled += len(txs)
 	}
+	if err = writer.Close(); err != nil {
+		replacement.Close()
+		return err
+	}
 	replacement.Close()
 
 	// Replace the live journal with the newly generated one
@@ -163,7 +305,7 @@ func (journal *journal) rotate(all map[common.Address]types.Transactions) error
 	if err != nil {
 		return err
 	}
-	journal.writer = sink
+	journal.writer = newJournalSink(sink)
 	log.Info("Regenerated local transaction journal", "transactions", journaled, "accounts", len(all))
 
 	return nil
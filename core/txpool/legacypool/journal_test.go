@@ -0,0 +1,166 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/rlp"
+)
+
+// loadJournal loads all the transactions from a journal, also returning their
+// locality flags.
+func loadJournal(t *testing.T, journal *journal) ([]*types.Transaction, []bool) {
+	t.Helper()
+
+	var (
+		txs    []*types.Transaction
+		locals []bool
+	)
+	err := journal.load(func(batch []*types.Transaction, local bool) []error {
+		for range batch {
+			locals = append(locals, local)
+		}
+		txs = append(txs, batch...)
+		return make([]error, len(batch))
+	})
+	if err != nil {
+		t.Fatalf("failed to load journal: %v", err)
+	}
+	return txs, locals
+}
+
+// Tests that legacy journals are loaded and converted into the versioned format
+// on rotation, retaining the transactions and their first seen times.
+func TestJournalMigration(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	txs := []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(1), key),
+		dynamicFeeTx(1, 100000, big.NewInt(2), big.NewInt(1), key),
+	}
+	path := filepath.Join(t.TempDir(), "transactions.rlp")
+
+	var legacy bytes.Buffer
+	for _, tx := range txs {
+		rlp.Encode(&legacy, tx)
+	}
+	if err := os.WriteFile(path, legacy.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write legacy journal: %v", err)
+	}
+	journal := newTxJournal(path)
+	loaded, locals := loadJournal(t, journal)
+	if len(loaded) != len(txs) {
+		t.Fatalf("legacy transaction count mismatch: have %d, want %d", len(loaded), len(txs))
+	}
+	for i, tx := range loaded {
+		if tx.Hash() != txs[i].Hash() || !locals[i] {
+			t.Errorf("legacy transaction %d mismatch: have %x/%v, want %x/true", i, tx.Hash(), locals[i], txs[i].Hash())
+		}
+	}
+	// Rotate the journal and add an extra transaction, then reload it
+	seen := time.Now().Add(-time.Hour)
+	for _, tx := range loaded {
+		tx.SetTime(seen)
+	}
+	if err := journal.rotate(map[common.Address]types.Transactions{{}: loaded}); err != nil {
+		t.Fatalf("failed to rotate journal: %v", err)
+	}
+	extra := pricedTransaction(2, 100000, big.NewInt(1), key)
+	extra.SetTime(seen)
+	if err := journal.insert(extra); err != nil {
+		t.Fatalf("failed to journal transaction: %v", err)
+	}
+	if err := journal.close(); err != nil {
+		t.Fatalf("failed to close journal: %v", err)
+	}
+	blob, _ := os.ReadFile(path)
+	if !bytes.HasPrefix(blob, append(append([]byte{}, journalMagic...), journalVersion)) {
+		t.Fatalf("journal not migrated: header %x", blob[:len(journalMagic)+1])
+	}
+	loaded, _ = loadJournal(t, newTxJournal(path))
+	if len(loaded) != len(txs)+1 {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(loaded), len(txs)+1)
+	}
+	for i, tx := range loaded {
+		want := extra
+		if i < len(txs) {
+			want = txs[i]
+		}
+		if tx.Hash() != want.Hash() {
+			t.Errorf("transaction %d mismatch: have %x, want %x", i, tx.Hash(), want.Hash())
+		}
+		if tx.Time().Unix() != seen.Unix() {
+			t.Errorf("transaction %d time mismatch: have %v, want %v", i, tx.Time(), seen)
+		}
+	}
+}
+
+// Tests that journal entries of unsupported transaction types are skipped, and
+// the rest of the journal is still loaded.
+func TestJournalUnsupportedEntries(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	var (
+		first  = pricedTransaction(0, 100000, big.NewInt(1), key)
+		second = pricedTransaction(1, 100000, big.NewInt(1), key)
+	)
+	path := filepath.Join(t.TempDir(), "transactions.rlp")
+
+	var buf bytes.Buffer
+	buf.Write(journalMagic)
+	buf.WriteByte(journalVersion)
+
+	writer := snappy.NewBufferedWriter(&buf)
+	for _, entry := range []*journalEntry{
+		{Type: first.Type(), Local: true, Tx: mustEncode(first)},
+		{Type: 0x7f, Local: true, Tx: rlp.AppendUint64(nil, 0x7f)},
+		{Type: second.Type(), Local: false, Tx: mustEncode(second)},
+	} {
+		rlp.Encode(writer, entry)
+	}
+	writer.Close()
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write journal: %v", err)
+	}
+	loaded, locals := loadJournal(t, newTxJournal(path))
+	if len(loaded) != 2 {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(loaded), 2)
+	}
+	if loaded[0].Hash() != first.Hash() || !locals[0] {
+		t.Errorf("first transaction mismatch: have %x/%v, want %x/true", loaded[0].Hash(), locals[0], first.Hash())
+	}
+	if loaded[1].Hash() != second.Hash() || locals[1] {
+		t.Errorf("second transaction mismatch: have %x/%v, want %x/false", loaded[1].Hash(), locals[1], second.Hash())
+	}
+}
+
+func mustEncode(tx *types.Transaction) []byte {
+	blob, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		panic(err)
+	}
+	return blob
+}
@@ -61,6 +61,16 @@ var (
 	// ErrTxPoolOverflow is returned if the transaction pool is full and can't accept
 	// another remote transaction.
 	ErrTxPoolOverflow = errors.New("txpool is full")
+
+	// ErrInflightTxLimitReached is returned when the maximum number of in-flight
+	// transactions is reached for specific accounts, namely the ones with a code
+	// delegation (EIP-7702) installed or pending in the pool.
+	ErrInflightTxLimitReached = errors.New("in-flight transaction limit reached for delegated accounts")
+
+	// ErrAuthorityReserved is returned if a transaction has an authorization
+	// signed by an address which already has in-flight transactions known to the
+	// pool.
+	ErrAuthorityReserved = errors.New("authority already reserved")
 )
 
 var (
@@ -82,6 +92,10 @@ var (
 	queuedNofundsMeter   = metrics.NewRegisteredMeter("txpool/queued/nofunds", nil)   // Dropped due to out-of-funds
 	queuedEvictionMeter  = metrics.NewRegisteredMeter("txpool/queued/eviction", nil)  // Dropped due to lifetime
 
+	// expiredMeter counts the transactions dropped for exceeding their maximum
+	// lifetime, pending or queued.
+	expiredMeter = metrics.NewRegisteredMeter("txpool/expired", nil)
+
 	// General tx metrics
 	knownTxMeter       = metrics.NewRegisteredMeter("txpool/known", nil)
 	validTxMeter       = metrics.NewRegisteredMeter("txpool/valid", nil)
@@ -103,6 +117,11 @@ var (
 	localGauge   = metrics.NewRegisteredGauge("txpool/local", nil)
 	slotsGauge   = metrics.NewRegisteredGauge("txpool/slots", nil)
 
+	// priorityPendingGauge and priorityQueuedGauge track the occupancy of the
+	// priority lane, the transactions of the configured priority senders.
+	priorityPendingGauge = metrics.NewRegisteredGauge("txpool/priority/pending", nil)
+	priorityQueuedGauge  = metrics.NewRegisteredGauge("txpool/priority/queued", nil)
+
 	reheapTimer = metrics.NewRegisteredTimer("txpool/reheap", nil)
 )
 
@@ -126,18 +145,28 @@ type BlockChain interface {
 type Config struct {
 	Locals    []common.Address // Addresses that should be treated by default as local
 	NoLocals  bool             // Whether local transaction handling should be disabled
+	Priority  []common.Address `toml:",omitempty"` // Senders exempt from the global limits and from eviction
 	Journal   string           // Journal of local transactions to survive node restarts
 	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
 
-	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
-	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
+	PriceLimit  uint64          // Minimum gas price to enforce for acceptance into the pool
+	PriceBump   uint64          // Minimum price bump percentage to replace an already existing transaction (nonce)
+	PriceBumps  map[byte]uint64 `toml:",omitempty"` // Minimum price bump percentages overriding PriceBump per transaction type
+	StrictNonce bool            `toml:",omitempty"` // Whether to reject replacements, keeping the first transaction seen for a nonce
 
 	AccountSlots uint64 // Number of executable transaction slots guaranteed per account
 	GlobalSlots  uint64 // Maximum number of executable transaction slots for all accounts
 	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
-	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+	Lifetime        time.Duration // Maximum amount of time non-executable transaction are queued
+	TxLifetime      time.Duration // Maximum amount of time remote transactions are pooled, pending or queued (0 = unlimited)
+	LocalTxLifetime time.Duration // Maximum amount of time local transactions are pooled, pending or queued (0 = unlimited)
+
+	Overflow    string // Directory to park future transactions not fitting into the pool (empty = disabled)
+	OverflowCap uint64 // Maximum size in bytes of the transactions parked on disk
+
+	Policies []txpool.AdmissionPolicy `toml:"-"` // Custom rules transactions must satisfy to enter the pool
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
@@ -154,6 +183,8 @@ var DefaultConfig = Config{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	OverflowCap: 64 * 1024 * 1024,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -192,6 +223,18 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultConfig.Lifetime)
 		conf.Lifetime = DefaultConfig.Lifetime
 	}
+	if conf.TxLifetime < 0 {
+		log.Warn("Sanitizing invalid txpool transaction lifetime", "provided", conf.TxLifetime, "updated", 0)
+		conf.TxLifetime = 0
+	}
+	if conf.LocalTxLifetime < 0 {
+		log.Warn("Sanitizing invalid txpool local transaction lifetime", "provided", conf.LocalTxLifetime, "updated", 0)
+		conf.LocalTxLifetime = 0
+	}
+	if conf.Overflow != "" && conf.OverflowCap < txMaxSize {
+		log.Warn("Sanitizing invalid txpool overflow cap", "provided", conf.OverflowCap, "updated", DefaultConfig.OverflowCap)
+		conf.OverflowCap = DefaultConfig.OverflowCap
+	}
 	return conf
 }
 
@@ -204,20 +247,25 @@ func (config *Config) sanitize() Config {
 // two states over time as they are received and processed.
 type LegacyPool struct {
 	config      Config
+	replacement txpool.ReplacementPolicy // Rules for replacing pooled transactions, assembled from the config
 	chainconfig *params.ChainConfig
 	chain       BlockChain
 	gasTip      atomic.Pointer[big.Int]
 	txFeed      event.Feed
+	dropFeed    event.Feed
 	scope       event.SubscriptionScope
-	signer      types.Signer
+	signer      atomic.Pointer[types.Signer] // Signer of the latest fork scheduled on the chain config
 	mu          sync.RWMutex
 
 	currentHead   atomic.Pointer[types.Header] // Current head of the blockchain
 	currentState  *state.StateDB               // Current state in the blockchain head
 	pendingNonces *noncer                      // Pending state tracking virtual nonces
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *journal    // Journal of local transaction to back up to disk
+	locals   *accountSet // Set of local transaction to exempt from eviction rules
+	priority *accountSet // Set of privileged senders exempt from global limits and eviction
+	journal  *journal    // Journal of local transaction to back up to disk
+
+	overflow *overflow // Disk tier for future transactions not fitting into the pool
 
 	reserve txpool.AddressReserver       // Address reserver to ensure exclusivity across subpools
 	pending map[common.Address]*list     // All currently processable transactions
@@ -235,6 +283,9 @@ type LegacyPool struct {
 	initDoneCh      chan struct{}  // is closed once the pool is initialized (for tests)
 
 	changesSinceReorg int // A counter for how many drops we've performed in-between reorg.
+
+	dropped  []core.DropTxsEvent      // Transactions removed from the pool, pending announcement
+	included map[common.Hash]struct{} // Transactions included in the blocks of the running reset
 }
 
 type txpoolResetRequest struct {
@@ -252,7 +303,6 @@ func New(config Config, chain BlockChain) *LegacyPool {
 		config:          config,
 		chain:           chain,
 		chainconfig:     chain.Config(),
-		signer:          types.LatestSigner(chain.Config()),
 		pending:         make(map[common.Address]*list),
 		queue:           make(map[common.Address]*list),
 		beats:           make(map[common.Address]time.Time),
@@ -264,12 +314,25 @@ func New(config Config, chain BlockChain) *LegacyPool {
 		reorgShutdownCh: make(chan struct{}),
 		initDoneCh:      make(chan struct{}),
 	}
-	pool.locals = newAccountSet(pool.signer)
+	signer := types.LatestSigner(chain.Config())
+	pool.signer.Store(&signer)
+
+	pool.locals = newAccountSet(pool.currentSigner())
 	for _, addr := range config.Locals {
 		log.Info("Setting new local account", "address", addr)
 		pool.locals.add(addr)
 	}
+	pool.priority = newAccountSet(pool.currentSigner())
+	for _, addr := range config.Priority {
+		log.Info("Setting new priority account", "address", addr)
+		pool.priority.add(addr)
+	}
 	pool.priced = newPricedList(pool.all)
+	pool.replacement = txpool.ReplacementPolicy{
+		PriceBump:   config.PriceBump,
+		TypeBumps:   config.PriceBumps,
+		StrictNonce: config.StrictNonce,
+	}
 
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
@@ -296,6 +359,15 @@ func (pool *LegacyPool) Init(gasTip *big.Int, head *types.Header, reserve txpool
 	// Set the address reserver to request exclusive access to pooled accounts
 	pool.reserve = reserve
 
+	// Open the overflow tier before anything could be parked into it
+	if pool.config.Overflow != "" {
+		overflow, err := newOverflow(pool.config.Overflow, pool.config.OverflowCap, pool.currentSigner())
+		if err != nil {
+			return err
+		}
+		pool.overflow = overflow
+	}
+
 	// Set the basic pool parameters
 	pool.gasTip.Store(gasTip)
 	pool.reset(nil, head)
@@ -307,7 +379,13 @@ func (pool *LegacyPool) Init(gasTip *big.Int, head *types.Header, reserve txpool
 
 	// If local transactions and journaling is enabled, load from disk
 	if pool.journal != nil {
-		if err := pool.journal.load(pool.addLocals); err != nil {
+		add := func(txs []*types.Transaction, local bool) []error {
+			if local {
+				return pool.addLocals(txs)
+			}
+			return pool.addRemotes(txs)
+		}
+		if err := pool.journal.load(add); err != nil {
 			log.Warn("Failed to load transaction journal", "err", err)
 		}
 		if err := pool.journal.rotate(pool.local()); err != nil {
@@ -361,8 +439,8 @@ func (pool *LegacyPool) loop() {
 		case <-evict.C:
 			pool.mu.Lock()
 			for addr := range pool.queue {
-				// Skip local transactions from the eviction mechanism
-				if pool.locals.contains(addr) {
+				// Skip local and priority transactions from the eviction mechanism
+				if pool.locals.contains(addr) || pool.priority.contains(addr) {
 					continue
 				}
 				// Any non-locals old enough should be removed
@@ -371,11 +449,16 @@ func (pool *LegacyPool) loop() {
 					for _, tx := range list {
 						pool.removeTx(tx.Hash(), true, true)
 					}
+					pool.queueDropEvent(core.TxDropEvicted, list...)
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
 			}
+			pool.expire()
+			drops := pool.takeDropEvents()
 			pool.mu.Unlock()
 
+			pool.sendDropEvents(drops)
+
 		// Handle local transaction journal rotation
 		case <-journal.C:
 			if pool.journal != nil {
@@ -389,6 +472,45 @@ func (pool *LegacyPool) loop() {
 	}
 }
 
+// expire removes the transactions pooled for longer than the maximum lifetime
+// configured for local or remote transactions, announcing them as expired. The
+// executable transactions following an expired pending one are moved back into
+// the queue until the nonce gap is filled again. Priority senders never expire.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) expire() {
+	var (
+		locals  = pool.config.LocalTxLifetime
+		remotes = pool.config.TxLifetime
+		expired []*types.Transaction
+	)
+	if locals == 0 && remotes == 0 {
+		return
+	}
+	pool.all.Range(func(hash common.Hash, tx *types.Transaction, local bool) bool {
+		if pool.priority.containsTx(tx) {
+			return true
+		}
+		lifetime := remotes
+		if local {
+			lifetime = locals
+		}
+		if time.Since(tx.Time()) > lifetime {
+			expired = append(expired, tx)
+		}
+		return true
+	}, locals > 0, remotes > 0)
+
+	for _, tx := range expired {
+		pool.removeTx(tx.Hash(), true, true)
+	}
+	if len(expired) > 0 {
+		pool.queueDropEvent(core.TxDropExpired, expired...)
+		expiredMeter.Mark(int64(len(expired)))
+		log.Debug("Expired stale transactions", "count", len(expired))
+	}
+}
+
 // Close terminates the transaction pool.
 func (pool *LegacyPool) Close() error {
 	// Unsubscribe all subscriptions registered from txpool
@@ -401,6 +523,11 @@ func (pool *LegacyPool) Close() error {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.overflow != nil {
+		if err := pool.overflow.Close(); err != nil {
+			log.Error("Failed to close transaction overflow", "err", err)
+		}
+	}
 	log.Info("Transaction pool stopped")
 	return nil
 }
@@ -418,11 +545,16 @@ func (pool *LegacyPool) SubscribeTransactions(ch chan<- core.NewTxsEvent) event.
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeDropTransactions registers a subscription of DropTxsEvent and
+// starts sending event to the given channel.
+func (pool *LegacyPool) SubscribeDropTransactions(ch chan<- core.DropTxsEvent) event.Subscription {
+	return pool.scope.Track(pool.dropFeed.Subscribe(ch))
+}
+
 // SetGasTip updates the minimum gas tip required by the transaction pool for a
 // new transaction, and drops all transactions below this threshold.
 func (pool *LegacyPool) SetGasTip(tip *big.Int) {
 	pool.mu.Lock()
-	defer pool.mu.Unlock()
 
 	old := pool.gasTip.Load()
 	pool.gasTip.Store(new(big.Int).Set(tip))
@@ -435,7 +567,12 @@ func (pool *LegacyPool) SetGasTip(tip *big.Int) {
 			pool.removeTx(tx.Hash(), false, true)
 		}
 		pool.priced.Removed(len(drop))
+		pool.queueDropEvent(core.TxDropUnderpriced, drop...)
 	}
+	drops := pool.takeDropEvents()
+	pool.mu.Unlock()
+
+	pool.sendDropEvents(drops)
 	log.Info("Legacy pool tip threshold updated", "tip", tip)
 }
 
@@ -581,14 +718,15 @@ func (pool *LegacyPool) validateTxBasics(tx *types.Transaction, local bool) erro
 		Accept: 0 |
 			1<<types.LegacyTxType |
 			1<<types.AccessListTxType |
-			1<<types.DynamicFeeTxType,
+			1<<types.DynamicFeeTxType |
+			1<<types.SetCodeTxType,
 		MaxSize: txMaxSize,
 		MinTip:  pool.gasTip.Load(),
 	}
 	if local {
 		opts.MinTip = new(big.Int)
 	}
-	if err := txpool.ValidateTransaction(tx, nil, nil, nil, pool.currentHead.Load(), pool.signer, opts); err != nil {
+	if err := txpool.ValidateTransaction(tx, nil, nil, nil, pool.currentHead.Load(), pool.currentSigner(), opts); err != nil {
 		return err
 	}
 	return nil
@@ -625,13 +763,62 @@ func (pool *LegacyPool) validateTx(tx *types.Transaction, local bool) error {
 			}
 			return nil
 		},
+		Policies: pool.config.Policies,
 	}
-	if err := txpool.ValidateTransactionWithState(tx, pool.signer, opts); err != nil {
+	if err := txpool.ValidateTransactionWithState(tx, pool.currentSigner(), opts); err != nil {
 		return err
 	}
+	return pool.validateAuth(tx)
+}
+
+// validateAuth verifies that the transaction complies with the code delegation
+// restrictions brought by the EIP-7702 set code transaction type. As the nonce
+// of a delegated account may be bumped by any transaction in the pool that its
+// owner authorized, or its balance drained by the delegated code, only a single
+// in-flight transaction is tracked for such accounts to bound the invalidations.
+func (pool *LegacyPool) validateAuth(tx *types.Transaction) error {
+	from, _ := types.Sender(pool.currentSigner(), tx) // already validated
+
+	// Allow at most one in-flight tx for delegated accounts or those with a
+	// pending authorization.
+	if pool.isDelegated(from) || pool.all.hasAuth(from) {
+		var (
+			count  int
+			exists bool
+		)
+		if pending := pool.pending[from]; pending != nil {
+			count += pending.Len()
+			exists = pending.Contains(tx.Nonce())
+		}
+		if queue := pool.queue[from]; queue != nil {
+			count += queue.Len()
+			exists = exists || queue.Contains(tx.Nonce())
+		}
+		// Replacing the existing in-flight transaction is still permitted
+		if count >= 1 && !exists {
+			return ErrInflightTxLimitReached
+		}
+	}
+	// Authorities cannot conflict with any pending or queued transactions,
+	// otherwise the authorization would invalidate their nonces.
+	for _, auth := range tx.SetCodeAuthorities() {
+		if auth == from {
+			continue
+		}
+		if pool.pending[auth] != nil || pool.queue[auth] != nil {
+			return ErrAuthorityReserved
+		}
+	}
 	return nil
 }
 
+// isDelegated reports whether the account has a code delegation installed in
+// the current state.
+func (pool *LegacyPool) isDelegated(addr common.Address) bool {
+	_, ok := types.ParseDelegation(pool.currentState.GetCode(addr))
+	return ok
+}
+
 // add validates a transaction and inserts it into the non-executable queue for later
 // pending promotion and execution. If the transaction is a replacement for an already
 // pending or queued one, it overwrites the previous transaction if its price is higher.
@@ -639,6 +826,9 @@ func (pool *LegacyPool) validateTx(tx *types.Transaction, local bool) error {
 // If a newly added transaction is marked as local, its sending account will be
 // be added to the allowlist, preventing any associated transaction from being dropped
 // out of the pool due to pricing constraints.
+//
+// Transactions of priority senders are admitted regardless of the global limits
+// and, same as locals, are never discarded to make room for others.
 func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, err error) {
 	// If the transaction is already known, discard it
 	hash := tx.Hash()
@@ -651,6 +841,11 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 	// the sender is marked as local previously, treat it as the local transaction.
 	isLocal := local || pool.locals.containsTx(tx)
 
+	// Priority transactions are tracked outside of the priced list, just like
+	// local ones, so that they can't be picked for eviction.
+	isPriority := pool.priority.containsTx(tx)
+	exempt := isLocal || isPriority
+
 	// If the transaction fails basic validation, discard it
 	if err := pool.validateTx(tx, isLocal); err != nil {
 		log.Trace("Discarding invalid transaction", "hash", hash, "err", err)
@@ -658,7 +853,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 		return false, err
 	}
 	// already validated by this point
-	from, _ := types.Sender(pool.signer, tx)
+	from, _ := types.Sender(pool.currentSigner(), tx)
 
 	// If the address is not yet known, request exclusivity to track the account
 	// only by this subpool until all transactions are evicted
@@ -683,9 +878,12 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 		}()
 	}
 	// If the transaction pool is full, discard underpriced transactions
-	if uint64(pool.all.Slots()+numSlots(tx)) > pool.config.GlobalSlots+pool.config.GlobalQueue {
+	if !isPriority && uint64(pool.all.Slots()+numSlots(tx)) > pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
 		if !isLocal && pool.priced.Underpriced(tx) {
+			if pool.park(from, tx) {
+				return false, errOverflowed
+			}
 			log.Trace("Discarding underpriced transaction", "hash", hash, "gasTipCap", tx.GasTipCap(), "gasFeeCap", tx.GasFeeCap())
 			underpricedTxMeter.Mark(1)
 			return false, txpool.ErrUnderpriced
@@ -707,6 +905,9 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 
 		// Special case, we still can't make the room for the new remote one.
 		if !isLocal && !success {
+			if pool.park(from, tx) {
+				return false, errOverflowed
+			}
 			log.Trace("Discarding overflown transaction", "hash", hash)
 			overflowedTxMeter.Mark(1)
 			return false, ErrTxPoolOverflow
@@ -716,7 +917,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 		if !isLocal && pool.isGapped(from, tx) {
 			var replacesPending bool
 			for _, dropTx := range drop {
-				dropSender, _ := types.Sender(pool.signer, dropTx)
+				dropSender, _ := types.Sender(pool.currentSigner(), dropTx)
 				if list := pool.pending[dropSender]; list != nil && list.Contains(dropTx.Nonce()) {
 					replacesPending = true
 					break
@@ -727,6 +928,9 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 				for _, dropTx := range drop {
 					pool.priced.Put(dropTx, false)
 				}
+				if pool.park(from, tx) {
+					return false, errOverflowed
+				}
 				log.Trace("Discarding future transaction replacing pending tx", "hash", hash)
 				return false, txpool.ErrFutureReplacePending
 			}
@@ -737,8 +941,9 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "gasTipCap", tx.GasTipCap(), "gasFeeCap", tx.GasFeeCap())
 			underpricedTxMeter.Mark(1)
 
-			sender, _ := types.Sender(pool.signer, tx)
+			sender, _ := types.Sender(pool.currentSigner(), tx)
 			dropped := pool.removeTx(tx.Hash(), false, sender != from) // Don't unreserve the sender of the tx being added if last from the acc
+			pool.queueDropEvent(core.TxDropUnderpriced, tx)
 
 			pool.changesSinceReorg += dropped
 		}
@@ -747,19 +952,20 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 	// Try to replace an existing transaction in the pending pool
 	if list := pool.pending[from]; list != nil && list.Contains(tx.Nonce()) {
 		// Nonce already pending, check if required price bump is met
-		inserted, old := list.Add(tx, pool.config.PriceBump)
-		if !inserted {
+		old, err := list.Add(tx, &pool.replacement)
+		if err != nil {
 			pendingDiscardMeter.Mark(1)
-			return false, txpool.ErrReplaceUnderpriced
+			return false, err
 		}
 		// New transaction is better, replace old one
 		if old != nil {
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed(1)
+			pool.queueDropEvent(core.TxDropReplaced, old)
 			pendingReplaceMeter.Mark(1)
 		}
-		pool.all.Add(tx, isLocal)
-		pool.priced.Put(tx, isLocal)
+		pool.all.Add(tx, exempt)
+		pool.priced.Put(tx, exempt)
 		pool.journalTx(from, tx)
 		pool.queueTxEvent(tx)
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
@@ -769,7 +975,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 		return old != nil, nil
 	}
 	// New transaction isn't replacing a pending one, push into queue
-	replaced, err = pool.enqueueTx(hash, tx, isLocal, true)
+	replaced, err = pool.enqueueTx(hash, tx, exempt, true)
 	if err != nil {
 		return false, err
 	}
@@ -817,20 +1023,21 @@ func (pool *LegacyPool) isGapped(from common.Address, tx *types.Transaction) boo
 // Note, this method assumes the pool lock is held!
 func (pool *LegacyPool) enqueueTx(hash common.Hash, tx *types.Transaction, local bool, addAll bool) (bool, error) {
 	// Try to insert the transaction into the future queue
-	from, _ := types.Sender(pool.signer, tx) // already validated
+	from, _ := types.Sender(pool.currentSigner(), tx) // already validated
 	if pool.queue[from] == nil {
 		pool.queue[from] = newList(false)
 	}
-	inserted, old := pool.queue[from].Add(tx, pool.config.PriceBump)
-	if !inserted {
+	old, err := pool.queue[from].Add(tx, &pool.replacement)
+	if err != nil {
 		// An older transaction was better, discard this
 		queuedDiscardMeter.Mark(1)
-		return false, txpool.ErrReplaceUnderpriced
+		return false, err
 	}
 	// Discard any previous transaction and mark this
 	if old != nil {
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
+		pool.queueDropEvent(core.TxDropReplaced, old)
 		queuedReplaceMeter.Mark(1)
 	} else {
 		// Nothing was replaced, bump the queued counter
@@ -875,11 +1082,12 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 	}
 	list := pool.pending[addr]
 
-	inserted, old := list.Add(tx, pool.config.PriceBump)
-	if !inserted {
+	old, err := list.Add(tx, &pool.replacement)
+	if err != nil {
 		// An older transaction was better, discard this
 		pool.all.Remove(hash)
 		pool.priced.Removed(1)
+		pool.queueDropEvent(core.TxDropReplaced, tx)
 		pendingDiscardMeter.Mark(1)
 		return false
 	}
@@ -887,6 +1095,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 	if old != nil {
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
+		pool.queueDropEvent(core.TxDropReplaced, old)
 		pendingReplaceMeter.Mark(1)
 	} else {
 		// Nothing was replaced, bump the pending counter
@@ -959,8 +1168,9 @@ func (pool *LegacyPool) addRemoteSync(tx *types.Transaction) error {
 func (pool *LegacyPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 	// Filter out known ones without obtaining the pool lock or recovering signatures
 	var (
-		errs = make([]error, len(txs))
-		news = make([]*types.Transaction, 0, len(txs))
+		errs    = make([]error, len(txs))
+		unknown = make([]*types.Transaction, 0, len(txs))
+		news    = make([]*types.Transaction, 0, len(txs))
 	)
 	for i, tx := range txs {
 		// If the transaction is known, pre-set the error slot
@@ -969,6 +1179,16 @@ func (pool *LegacyPool) addTxs(txs []*types.Transaction, local, sync bool) []err
 			knownTxMeter.Mark(1)
 			continue
 		}
+		unknown = append(unknown, tx)
+	}
+	// Recover the senders of large batches (e.g. the journal on startup) on all
+	// cores instead of one by one during validation
+	core.SenderCacher.RecoverSync(pool.currentSigner(), unknown)
+
+	for i, tx := range txs {
+		if errs[i] != nil {
+			continue
+		}
 		// Exclude transactions with basic errors, e.g invalid signatures and
 		// insufficient intrinsic gas as soon as possible and cache senders
 		// in transactions before obtaining lock
@@ -1008,10 +1228,13 @@ func (pool *LegacyPool) addTxs(txs []*types.Transaction, local, sync bool) []err
 // addTxsLocked attempts to queue a batch of transactions if they are valid.
 // The transaction pool lock must be held.
 func (pool *LegacyPool) addTxsLocked(txs []*types.Transaction, local bool) ([]error, *accountSet) {
-	dirty := newAccountSet(pool.signer)
+	dirty := newAccountSet(pool.currentSigner())
 	errs := make([]error, len(txs))
 	for i, tx := range txs {
 		replaced, err := pool.add(tx, local)
+		if err == errOverflowed {
+			continue // parked on disk, nothing to promote
+		}
 		errs[i] = err
 		if err == nil && !replaced {
 			dirty.addTx(tx)
@@ -1028,7 +1251,7 @@ func (pool *LegacyPool) Status(hash common.Hash) txpool.TxStatus {
 	if tx == nil {
 		return txpool.TxStatusUnknown
 	}
-	from, _ := types.Sender(pool.signer, tx) // already validated
+	from, _ := types.Sender(pool.currentSigner(), tx) // already validated
 
 	pool.mu.RLock()
 	defer pool.mu.RUnlock()
@@ -1076,7 +1299,7 @@ func (pool *LegacyPool) removeTx(hash common.Hash, outofbound bool, unreserve bo
 	if tx == nil {
 		return 0
 	}
-	addr, _ := types.Sender(pool.signer, tx) // already validated during insertion
+	addr, _ := types.Sender(pool.currentSigner(), tx) // already validated during insertion
 
 	// If after deletion there are no more transactions belonging to this account,
 	// relinquish the address reservation. It's a bit convoluted do this, via a
@@ -1163,6 +1386,57 @@ func (pool *LegacyPool) queueTxEvent(tx *types.Transaction) {
 	}
 }
 
+// queueDropEvent records transactions leaving the pool, to be announced once
+// the pool lock is released.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) queueDropEvent(reason core.TxDropReason, txs ...*types.Transaction) {
+	if len(txs) == 0 {
+		return
+	}
+	// Merge consecutive drops of the same reason into a single event
+	if n := len(pool.dropped); n > 0 && pool.dropped[n-1].Reason == reason {
+		pool.dropped[n-1].Txs = append(pool.dropped[n-1].Txs, txs...)
+		return
+	}
+	pool.dropped = append(pool.dropped, core.DropTxsEvent{
+		Txs:    append([]*types.Transaction(nil), txs...),
+		Reason: reason,
+	})
+}
+
+// queueStaleEvent records transactions removed due to their nonces being used
+// up, telling apart the ones included in the blocks the pool was reset to from
+// the ones superseded by transactions unknown to the pool.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) queueStaleEvent(txs []*types.Transaction) {
+	for _, tx := range txs {
+		if _, ok := pool.included[tx.Hash()]; ok {
+			pool.queueDropEvent(core.TxDropIncluded, tx)
+		} else {
+			pool.queueDropEvent(core.TxDropNonceTooLow, tx)
+		}
+	}
+}
+
+// takeDropEvents retrieves and clears the drop events pending announcement.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) takeDropEvents() []core.DropTxsEvent {
+	drops := pool.dropped
+	pool.dropped = nil
+	return drops
+}
+
+// sendDropEvents announces previously taken drop events. It must be called
+// without holding the pool lock, as the feed blocks on slow subscribers.
+func (pool *LegacyPool) sendDropEvents(drops []core.DropTxsEvent) {
+	for _, ev := range drops {
+		pool.dropFeed.Send(ev)
+	}
+}
+
 // scheduleReorgLoop schedules runs of reset and promoteExecutables. Code above should not
 // call those methods directly, but request them being run using requestReset and
 // requestPromoteExecutables instead.
@@ -1215,7 +1489,7 @@ func (pool *LegacyPool) scheduleReorgLoop() {
 		case tx := <-pool.queueTxEventCh:
 			// Queue up the event, but don't schedule a reorg. It's up to the caller to
 			// request one later if they want the events sent.
-			addr, _ := types.Sender(pool.signer, tx)
+			addr, _ := types.Sender(pool.currentSigner(), tx)
 			if _, ok := queuedEvents[addr]; !ok {
 				queuedEvents[addr] = newSortedMap()
 			}
@@ -1275,6 +1549,7 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 	// because of another transaction (e.g. higher gas price).
 	if reset != nil {
 		pool.demoteUnexecutables()
+		pool.included = nil
 		if reset.newHead != nil {
 			if pool.chainconfig.IsLondon(new(big.Int).Add(reset.newHead.Number, big.NewInt(1))) {
 				pendingBaseFee := eip1559.CalcBaseFee(pool.chainconfig, reset.newHead)
@@ -1290,18 +1565,25 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 			nonces[addr] = highestPending.Nonce() + 1
 		}
 		pool.pendingNonces.setAll(nonces)
+
+		// Slots were freed up by the new block, pull back parked transactions
+		if pool.overflow != nil {
+			promoted = append(promoted, pool.promoteExecutables(pool.restoreOverflow())...)
+		}
 	}
 	// Ensure pool.queue and pool.pending sizes stay within the configured limits.
 	pool.truncatePending()
 	pool.truncateQueue()
+	pool.updatePriorityGauges()
 
 	dropBetweenReorgHistogram.Update(int64(pool.changesSinceReorg))
 	pool.changesSinceReorg = 0 // Reset change counter
+	drops := pool.takeDropEvents()
 	pool.mu.Unlock()
 
 	// Notify subsystems for newly added transactions
 	for _, tx := range promoted {
-		addr, _ := types.Sender(pool.signer, tx)
+		addr, _ := types.Sender(pool.currentSigner(), tx)
 		if _, ok := events[addr]; !ok {
 			events[addr] = newSortedMap()
 		}
@@ -1314,6 +1596,7 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 		}
 		pool.txFeed.Send(core.NewTxsEvent{Txs: txs})
 	}
+	pool.sendDropEvents(drops)
 }
 
 // reset retrieves the current state of the blockchain and ensures the content
@@ -1393,8 +1676,14 @@ func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 					}
 				}
 				reinject = lost
+				pool.included = txHashSet(included)
 			}
 		}
+	} else if newHead != nil {
+		// Track the transactions of the new head to report them as included
+		if block := pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64()); block != nil {
+			pool.included = txHashSet(block.Transactions())
+		}
 	}
 	// Initialize the internal state to the current head
 	if newHead == nil {
@@ -1409,12 +1698,38 @@ func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 	pool.currentState = statedb
 	pool.pendingNonces = newNoncer(statedb)
 
+	// Pick up any fork scheduled on the live chain config since the last reset,
+	// such as those activated by a signed fork override
+	pool.updateSigner()
+
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
-	core.SenderCacher.Recover(pool.signer, reinject)
+	core.SenderCacher.RecoverSync(pool.currentSigner(), reinject)
 	pool.addTxsLocked(reinject, false)
 }
 
+// currentSigner returns the signer of the latest fork scheduled on the chain
+// config, used to recover the senders of the pooled transactions.
+func (pool *LegacyPool) currentSigner() types.Signer {
+	return *pool.signer.Load()
+}
+
+// updateSigner switches the pool over to the signer of the latest fork scheduled
+// on the chain config, if it changed. The method assumes the pool lock is held.
+func (pool *LegacyPool) updateSigner() {
+	signer := types.LatestSigner(pool.chainconfig)
+	if signer.Equal(pool.currentSigner()) {
+		return
+	}
+	pool.signer.Store(&signer)
+	pool.locals.signer = signer
+	pool.priority.signer = signer
+	if pool.overflow != nil {
+		pool.overflow.signer = signer
+	}
+	log.Debug("Switched transaction pool to the latest scheduled signer")
+}
+
 // promoteExecutables moves transactions that have become processable from the
 // future queue to the set of pending transactions. During this process, all
 // invalidated transactions (low nonce, low balance) are deleted.
@@ -1435,6 +1750,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 			hash := tx.Hash()
 			pool.all.Remove(hash)
 		}
+		pool.queueStaleEvent(forwards)
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Drop all transactions that are too costly (low balance or out of gas)
 		drops, _ := list.Filter(pool.currentState.GetBalance(addr), gasLimit)
@@ -1442,6 +1758,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 			hash := tx.Hash()
 			pool.all.Remove(hash)
 		}
+		pool.queueDropEvent(core.TxDropInvalid, drops...)
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
 
@@ -1465,6 +1782,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 				pool.all.Remove(hash)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
+			pool.queueDropEvent(core.TxDropEvicted, caps...)
 			queuedRateLimitMeter.Mark(int64(len(caps)))
 		}
 		// Mark all the items dropped as removed
@@ -1490,7 +1808,11 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 // equal number for all for accounts with many pending transactions.
 func (pool *LegacyPool) truncatePending() {
 	pending := uint64(0)
-	for _, list := range pool.pending {
+	for addr, list := range pool.pending {
+		// Priority senders don't take up any of the global slots
+		if pool.priority.contains(addr) {
+			continue
+		}
 		pending += uint64(list.Len())
 	}
 	if pending <= pool.config.GlobalSlots {
@@ -1502,7 +1824,7 @@ func (pool *LegacyPool) truncatePending() {
 	spammers := prque.New[int64, common.Address](nil)
 	for addr, list := range pool.pending {
 		// Only evict transactions from high rollers
-		if !pool.locals.contains(addr) && uint64(list.Len()) > pool.config.AccountSlots {
+		if !pool.locals.contains(addr) && !pool.priority.contains(addr) && uint64(list.Len()) > pool.config.AccountSlots {
 			spammers.Push(addr, int64(list.Len()))
 		}
 	}
@@ -1534,6 +1856,7 @@ func (pool *LegacyPool) truncatePending() {
 						log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 					}
 					pool.priced.Removed(len(caps))
+					pool.queueDropEvent(core.TxDropEvicted, caps...)
 					pendingGauge.Dec(int64(len(caps)))
 					if pool.locals.contains(offenders[i]) {
 						localGauge.Dec(int64(len(caps)))
@@ -1561,6 +1884,7 @@ func (pool *LegacyPool) truncatePending() {
 					log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 				}
 				pool.priced.Removed(len(caps))
+				pool.queueDropEvent(core.TxDropEvicted, caps...)
 				pendingGauge.Dec(int64(len(caps)))
 				if pool.locals.contains(addr) {
 					localGauge.Dec(int64(len(caps)))
@@ -1575,7 +1899,11 @@ func (pool *LegacyPool) truncatePending() {
 // truncateQueue drops the oldest transactions in the queue if the pool is above the global queue limit.
 func (pool *LegacyPool) truncateQueue() {
 	queued := uint64(0)
-	for _, list := range pool.queue {
+	for addr, list := range pool.queue {
+		// Priority senders don't take up any of the global slots
+		if pool.priority.contains(addr) {
+			continue
+		}
 		queued += uint64(list.Len())
 	}
 	if queued <= pool.config.GlobalQueue {
@@ -1585,7 +1913,7 @@ func (pool *LegacyPool) truncateQueue() {
 	// Sort all accounts with queued transactions by heartbeat
 	addresses := make(addressesByHeartbeat, 0, len(pool.queue))
 	for addr := range pool.queue {
-		if !pool.locals.contains(addr) { // don't drop locals
+		if !pool.locals.contains(addr) && !pool.priority.contains(addr) { // don't drop locals or priority senders
 			addresses = append(addresses, addressByHeartbeat{addr, pool.beats[addr]})
 		}
 	}
@@ -1600,9 +1928,11 @@ func (pool *LegacyPool) truncateQueue() {
 
 		// Drop all transactions if they are less than the overflow
 		if size := uint64(list.Len()); size <= drop {
-			for _, tx := range list.Flatten() {
+			txs := list.Flatten()
+			for _, tx := range txs {
 				pool.removeTx(tx.Hash(), true, true)
 			}
+			pool.evictQueued(txs)
 			drop -= size
 			queuedRateLimitMeter.Mark(int64(size))
 			continue
@@ -1611,12 +1941,96 @@ func (pool *LegacyPool) truncateQueue() {
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
 			pool.removeTx(txs[i].Hash(), true, true)
+			pool.evictQueued(txs[i : i+1])
 			drop--
 			queuedRateLimitMeter.Mark(1)
 		}
 	}
 }
 
+// updatePriorityGauges recounts the pending and queued transactions of the
+// priority senders.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) updatePriorityGauges() {
+	var pending, queued int
+	for addr := range pool.priority.accounts {
+		if list := pool.pending[addr]; list != nil {
+			pending += list.Len()
+		}
+		if list := pool.queue[addr]; list != nil {
+			queued += list.Len()
+		}
+	}
+	priorityPendingGauge.Update(int64(pending))
+	priorityQueuedGauge.Update(int64(queued))
+}
+
+// evictQueued drops future transactions removed from the pool due to its limits.
+// If the overflow tier is enabled they are parked on disk instead, and only the
+// ones not fitting there are dropped for good.
+func (pool *LegacyPool) evictQueued(txs []*types.Transaction) {
+	if pool.overflow == nil {
+		pool.queueDropEvent(core.TxDropEvicted, txs...)
+		return
+	}
+	pool.queueDropEvent(core.TxDropEvicted, pool.overflow.park(txs)...)
+}
+
+// park moves a new future transaction that does not fit into the pool into the
+// overflow tier, reporting whether it was accepted there.
+func (pool *LegacyPool) park(from common.Address, tx *types.Transaction) bool {
+	if pool.overflow == nil || !pool.isGapped(from, tx) {
+		return false
+	}
+	var dropped []*types.Transaction
+	for _, drop := range pool.overflow.park([]*types.Transaction{tx}) {
+		if drop != tx {
+			dropped = append(dropped, drop)
+		}
+	}
+	pool.queueDropEvent(core.TxDropEvicted, dropped...)
+	return pool.overflow.has(tx.Hash())
+}
+
+// restoreOverflow reinjects parked transactions into the pool for as long as
+// there is room for them without evicting anything, returning the accounts of
+// the restored transactions.
+func (pool *LegacyPool) restoreOverflow() []common.Address {
+	if pool.overflow.len() == 0 {
+		return nil
+	}
+	var queued uint64
+	for _, list := range pool.queue {
+		queued += uint64(list.Len())
+	}
+	var (
+		hashes   = pool.overflow.candidates(pool.pendingNonces.get, pool.currentState.GetNonce, pool.config.Lifetime)
+		accounts = newAccountSet(pool.currentSigner())
+	)
+	for _, hash := range hashes {
+		if queued >= pool.config.GlobalQueue || uint64(pool.all.Slots()) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
+			break
+		}
+		tx := pool.overflow.take(hash)
+		if tx == nil {
+			continue
+		}
+		if _, err := pool.add(tx, false); err != nil {
+			if err != errOverflowed {
+				log.Trace("Dropping overflowed transaction", "hash", hash, "err", err)
+			}
+			continue
+		}
+		accounts.addTx(tx)
+		queued++
+	}
+	if restored := len(accounts.accounts); restored > 0 {
+		log.Debug("Restored overflowed transactions", "accounts", restored, "parked", pool.overflow.len())
+	}
+	return accounts.flatten()
+}
+
 // demoteUnexecutables removes invalid and processed transactions from the pools
 // executable/pending queue and any subsequent transactions that become unexecutable
 // are moved back into the future queue.
@@ -1637,6 +2051,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 			pool.all.Remove(hash)
 			log.Trace("Removed old pending transaction", "hash", hash)
 		}
+		pool.queueStaleEvent(olds)
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
 		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), gasLimit)
 		for _, tx := range drops {
@@ -1644,6 +2059,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
 			pool.all.Remove(hash)
 		}
+		pool.queueDropEvent(core.TxDropInvalid, drops...)
 		pendingNofundsMeter.Mark(int64(len(drops)))
 
 		for _, tx := range invalids {
@@ -1679,6 +2095,15 @@ func (pool *LegacyPool) demoteUnexecutables() {
 	}
 }
 
+// txHashSet returns the set of hashes of the given transactions.
+func txHashSet(txs types.Transactions) map[common.Hash]struct{} {
+	set := make(map[common.Hash]struct{}, len(txs))
+	for _, tx := range txs {
+		set[tx.Hash()] = struct{}{}
+	}
+	return set
+}
+
 // addressByHeartbeat is an account address tagged with its last activity timestamp.
 type addressByHeartbeat struct {
 	address   common.Address
@@ -1778,6 +2203,7 @@ type lookup struct {
 	lock    sync.RWMutex
 	locals  map[common.Hash]*types.Transaction
 	remotes map[common.Hash]*types.Transaction
+	auths   map[common.Address][]common.Hash // All accounts with a pooled authorization
 }
 
 // newLookup returns a new lookup structure.
@@ -1785,6 +2211,7 @@ func newLookup() *lookup {
 	return &lookup{
 		locals:  make(map[common.Hash]*types.Transaction),
 		remotes: make(map[common.Hash]*types.Transaction),
+		auths:   make(map[common.Address][]common.Hash),
 	}
 }
 
@@ -1883,6 +2310,7 @@ func (t *lookup) Add(tx *types.Transaction, local bool) {
 	} else {
 		t.remotes[tx.Hash()] = tx
 	}
+	t.addAuthorities(tx)
 }
 
 // Remove removes a transaction from the lookup.
@@ -1901,10 +2329,48 @@ func (t *lookup) Remove(hash common.Hash) {
 	t.slots -= numSlots(tx)
 	slotsGauge.Update(int64(t.slots))
 
+	t.removeAuthorities(tx)
 	delete(t.locals, hash)
 	delete(t.remotes, hash)
 }
 
+// hasAuth reports whether the lookup holds any transaction carrying a code
+// delegation authorized by the given address.
+func (t *lookup) hasAuth(addr common.Address) bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return len(t.auths[addr]) > 0
+}
+
+// addAuthorities tracks the authorities of a set code transaction. The lock
+// must be held by the caller.
+func (t *lookup) addAuthorities(tx *types.Transaction) {
+	for _, addr := range tx.SetCodeAuthorities() {
+		t.auths[addr] = append(t.auths[addr], tx.Hash())
+	}
+}
+
+// removeAuthorities stops tracking the authorities of a set code transaction.
+// The lock must be held by the caller.
+func (t *lookup) removeAuthorities(tx *types.Transaction) {
+	hash := tx.Hash()
+	for _, addr := range tx.SetCodeAuthorities() {
+		list := t.auths[addr]
+		for i, h := range list {
+			if h == hash {
+				list = append(list[:i], list[i+1:]...)
+				break
+			}
+		}
+		if len(list) == 0 {
+			delete(t.auths, addr)
+		} else {
+			t.auths[addr] = list
+		}
+	}
+}
+
 // RemoteToLocals migrates the transactions belongs to the given locals to locals
 // set. The assumption is held the locals set is thread-safe to be used.
 func (t *lookup) RemoteToLocals(locals *accountSet) int {
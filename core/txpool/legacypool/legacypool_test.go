@@ -17,6 +17,7 @@
 package legacypool
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	crand "crypto/rand"
 	"errors"
@@ -39,6 +40,7 @@ import (
 	"github.com/gorievm/go-gori/event"
 	"github.com/gorievm/go-gori/params"
 	"github.com/gorievm/go-gori/trie"
+	"github.com/holiman/uint256"
 )
 
 var (
@@ -233,6 +235,31 @@ func validateEvents(events chan core.NewTxsEvent, count int) error {
 	return nil
 }
 
+// validateDropEvent checks that the next event fired on the pool's drop feed
+// reports the given transactions with the expected reason.
+func validateDropEvent(drops chan core.DropTxsEvent, reason core.TxDropReason, txs ...*types.Transaction) error {
+	select {
+	case ev := <-drops:
+		if ev.Reason != reason {
+			return fmt.Errorf("drop reason mismatch: have %v, want %v", ev.Reason, reason)
+		}
+		if len(ev.Txs) != len(txs) {
+			return fmt.Errorf("dropped transaction count mismatch: have %d, want %d", len(ev.Txs), len(txs))
+		}
+		// Transactions may be dropped in arbitrary order, compare them as sets
+		want := txHashSet(txs)
+		for _, tx := range ev.Txs {
+			if _, ok := want[tx.Hash()]; !ok {
+				return fmt.Errorf("unexpected dropped transaction %x", tx.Hash())
+			}
+		}
+		return nil
+
+	case <-time.After(time.Second):
+		return fmt.Errorf("%v drop event not fired", reason)
+	}
+}
+
 func deriveSender(tx *types.Transaction) (common.Address, error) {
 	return types.Sender(types.HomesteadSigner{}, tx)
 }
@@ -307,6 +334,39 @@ func TestStateChangeDuringReset(t *testing.T) {
 	}
 }
 
+// Tests that forks scheduled on the live chain config, such as by a signed fork
+// override, switch a running pool over to the new signer on the next reset.
+func TestForkScheduleSignerUpdate(t *testing.T) {
+	t.Parallel()
+
+	config := *params.AllEthashProtocolChanges
+	config.ShanghaiTime = new(uint64)
+	config.CancunTime, config.PragueTime, config.VerkleTime, config.EOFTime = nil, nil, nil, nil
+	config.EnableForkScheduling()
+
+	pool, _ := setupPoolWithConfig(&config)
+	defer pool.Close()
+
+	london := types.NewLondonSigner(config.ChainID)
+	if signer := pool.currentSigner(); !signer.Equal(london) {
+		t.Fatalf("wrong initial signer: have %T, want %T", signer, london)
+	}
+	if err := config.ScheduleForks(map[string]uint64{"cancun": 1000, "prague": 2000}, 0); err != nil {
+		t.Fatalf("failed to schedule forks: %v", err)
+	}
+	<-pool.requestReset(nil, nil)
+
+	prague := types.NewPragueSigner(config.ChainID)
+	if signer := pool.currentSigner(); !signer.Equal(prague) {
+		t.Fatalf("wrong signer after override: have %T, want %T", signer, prague)
+	}
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+	if !pool.locals.signer.Equal(prague) || !pool.priority.signer.Equal(prague) {
+		t.Fatal("account sets not switched to the new signer")
+	}
+}
+
 func testAddBalance(pool *LegacyPool, addr common.Address, amount *big.Int) {
 	pool.mu.Lock()
 	pool.currentState.AddBalance(addr, amount)
@@ -369,7 +429,7 @@ func TestQueue(t *testing.T) {
 	<-pool.requestReset(nil, nil)
 
 	pool.enqueueTx(tx.Hash(), tx, false, true)
-	<-pool.requestPromoteExecutables(newAccountSet(pool.signer, from))
+	<-pool.requestPromoteExecutables(newAccountSet(pool.currentSigner(), from))
 	if len(pool.pending) != 1 {
 		t.Error("expected valid txs to be 1 is", len(pool.pending))
 	}
@@ -379,7 +439,7 @@ func TestQueue(t *testing.T) {
 	testSetNonce(pool, from, 2)
 	pool.enqueueTx(tx.Hash(), tx, false, true)
 
-	<-pool.requestPromoteExecutables(newAccountSet(pool.signer, from))
+	<-pool.requestPromoteExecutables(newAccountSet(pool.currentSigner(), from))
 	if _, ok := pool.pending[from].txs.items[tx.Nonce()]; ok {
 		t.Error("expected transaction to be in tx pool")
 	}
@@ -2299,6 +2359,408 @@ func TestReplacementDynamicFee(t *testing.T) {
 	}
 }
 
+// Tests that the replacement policy applies the price bumps configured per
+// transaction type and rejects all replacements in strict nonce mode.
+func TestReplacementPolicy(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(eip1559Config, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.PriceBump = 10
+	config.PriceBumps = map[byte]uint64{types.DynamicFeeTxType: 50}
+
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	key, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	// Legacy transactions are subject to the default price bump
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(100), key)); err != nil {
+		t.Fatalf("failed to add original legacy transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(110), key)); err != nil {
+		t.Fatalf("failed to replace legacy transaction: %v", err)
+	}
+	// Dynamic fee transactions are subject to their own price bump
+	if err := pool.addRemoteSync(dynamicFeeTx(1, 100000, big.NewInt(100), big.NewInt(100), key)); err != nil {
+		t.Fatalf("failed to add original dynamic fee transaction: %v", err)
+	}
+	if err := pool.addRemote(dynamicFeeTx(1, 100000, big.NewInt(149), big.NewInt(149), key)); err != txpool.ErrReplaceUnderpriced {
+		t.Fatalf("dynamic fee transaction replacement error mismatch: have %v, want %v", err, txpool.ErrReplaceUnderpriced)
+	}
+	if err := pool.addRemoteSync(dynamicFeeTx(1, 100000, big.NewInt(150), big.NewInt(150), key)); err != nil {
+		t.Fatalf("failed to replace dynamic fee transaction: %v", err)
+	}
+	// Strict nonce mode rejects any replacement, pending or queued
+	pool.replacement.StrictNonce = true
+
+	if err := pool.addRemote(dynamicFeeTx(1, 100000, big.NewInt(1000), big.NewInt(1000), key)); err != txpool.ErrReplaceDisabled {
+		t.Fatalf("pending replacement error mismatch: have %v, want %v", err, txpool.ErrReplaceDisabled)
+	}
+	if err := pool.addRemoteSync(dynamicFeeTx(3, 100000, big.NewInt(100), big.NewInt(100), key)); err != nil {
+		t.Fatalf("failed to add queued transaction: %v", err)
+	}
+	if err := pool.addRemote(dynamicFeeTx(3, 100000, big.NewInt(1000), big.NewInt(1000), key)); err != txpool.ErrReplaceDisabled {
+		t.Fatalf("queued replacement error mismatch: have %v, want %v", err, txpool.ErrReplaceDisabled)
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d pending %d queued, want 2 pending 1 queued", pending, queued)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// headChain is a test blockchain serving a fixed block as the new chain head.
+type headChain struct {
+	*testBlockChain
+	head *types.Block
+}
+
+func (c *headChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return c.head
+}
+
+// Tests that transactions leaving the pool are announced on the drop feed with
+// the reason of their removal.
+func TestDropEvents(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	drops := make(chan core.DropTxsEvent, 32)
+	sub := pool.SubscribeDropTransactions(drops)
+	defer sub.Unsubscribe()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+
+	// Replacing a transaction should drop the old one
+	txs := []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(1), key),
+		pricedTransaction(1, 100000, big.NewInt(1), key),
+		pricedTransaction(2, 100000, big.NewInt(1), key),
+	}
+	if errs := pool.addRemotesSync(txs); errs[0] != nil || errs[1] != nil || errs[2] != nil {
+		t.Fatalf("failed to add transactions: %v", errs)
+	}
+	replacement := pricedTransaction(0, 100000, big.NewInt(2), key)
+	if err := pool.addRemoteSync(replacement); err != nil {
+		t.Fatalf("failed to replace transaction: %v", err)
+	}
+	if err := validateDropEvent(drops, core.TxDropReplaced, txs[0]); err != nil {
+		t.Fatalf("replacement: %v", err)
+	}
+	// Raising the minimum tip should drop the cheap transactions
+	pool.SetGasTip(big.NewInt(2))
+	if err := validateDropEvent(drops, core.TxDropUnderpriced, txs[1], txs[2]); err != nil {
+		t.Fatalf("tip increase: %v", err)
+	}
+	// Transactions made stale by a new block should be reported as included if
+	// they are part of it, or as having a used up nonce otherwise
+	txs = []*types.Transaction{
+		pricedTransaction(1, 100000, big.NewInt(2), key),
+		pricedTransaction(2, 100000, big.NewInt(2), key),
+	}
+	if errs := pool.addRemotesSync(txs); errs[0] != nil || errs[1] != nil {
+		t.Fatalf("failed to add transactions: %v", errs)
+	}
+	head := types.NewBlockWithHeader(&types.Header{
+		Number:   big.NewInt(1),
+		GasLimit: 10000000,
+		GasUsed:  5000000,
+		BaseFee:  big.NewInt(1),
+	}).WithBody([]*types.Transaction{txs[0]}, nil)
+
+	pool.mu.Lock()
+	pool.chain = &headChain{testBlockChain: pool.chain.(*testBlockChain), head: head}
+	pool.currentState.SetNonce(from, 2)
+	pool.mu.Unlock()
+
+	<-pool.requestReset(nil, head.Header())
+	if err := validateDropEvent(drops, core.TxDropNonceTooLow, replacement); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if err := validateDropEvent(drops, core.TxDropIncluded, txs[0]); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	// Transactions the sender can't afford anymore should be dropped as invalid
+	pool.mu.Lock()
+	pool.currentState.SetBalance(from, common.Big0)
+	pool.mu.Unlock()
+
+	<-pool.requestReset(nil, nil)
+	if err := validateDropEvent(drops, core.TxDropInvalid, txs[1]); err != nil {
+		t.Fatalf("balance drop: %v", err)
+	}
+	// Transactions exceeding the account queue limit should be evicted
+	testAddBalance(pool, from, big.NewInt(1000000000))
+	pool.mu.Lock()
+	pool.config.AccountQueue = 1
+	pool.mu.Unlock()
+
+	txs = []*types.Transaction{
+		pricedTransaction(4, 100000, big.NewInt(2), key),
+		pricedTransaction(5, 100000, big.NewInt(2), key),
+	}
+	if errs := pool.addRemotesSync(txs); errs[0] != nil || errs[1] != nil {
+		t.Fatalf("failed to add transactions: %v", errs)
+	}
+	if err := validateDropEvent(drops, core.TxDropEvicted, txs[1]); err != nil {
+		t.Fatalf("queue limit: %v", err)
+	}
+	select {
+	case ev := <-drops:
+		t.Fatalf("unexpected drop event: %v %v", ev.Reason, ev.Txs)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that transactions exceeding their maximum lifetime are expired, pending
+// or queued, with separate lifetimes for locals and remotes.
+func TestTransactionExpiry(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.TxLifetime = time.Hour
+	config.LocalTxLifetime = 2 * time.Hour
+
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	drops := make(chan core.DropTxsEvent, 32)
+	sub := pool.SubscribeDropTransactions(drops)
+	defer sub.Unsubscribe()
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+
+	// Add a mix of fresh and stale transactions, pending and queued
+	aged := func(tx *types.Transaction, age time.Duration) *types.Transaction {
+		tx.SetTime(time.Now().Add(-age))
+		return tx
+	}
+	locals := []*types.Transaction{
+		aged(pricedTransaction(0, 100000, big.NewInt(1), local), 90*time.Minute),
+		aged(pricedTransaction(1, 100000, big.NewInt(1), local), 3*time.Hour),
+	}
+	remotes := []*types.Transaction{
+		aged(pricedTransaction(0, 100000, big.NewInt(1), remote), 90*time.Minute),
+		pricedTransaction(1, 100000, big.NewInt(1), remote),
+		aged(pricedTransaction(3, 100000, big.NewInt(1), remote), 90*time.Minute),
+	}
+	for _, err := range pool.addLocals(locals) {
+		if err != nil {
+			t.Fatalf("failed to add local transaction: %v", err)
+		}
+	}
+	for _, err := range pool.addRemotesSync(remotes) {
+		if err != nil {
+			t.Fatalf("failed to add remote transaction: %v", err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 4 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d pending %d queued, want 4 pending 1 queued", pending, queued)
+	}
+	// Expire the stale transactions and ensure they are announced
+	pool.mu.Lock()
+	pool.expire()
+	events := pool.takeDropEvents()
+	pool.mu.Unlock()
+	pool.sendDropEvents(events)
+
+	if err := validateDropEvent(drops, core.TxDropExpired, locals[1], remotes[0], remotes[2]); err != nil {
+		t.Fatalf("expiry: %v", err)
+	}
+	// The fresh remote transaction should be moved back into the queue
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d pending %d queued, want 1 pending 1 queued", pending, queued)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the transactions of priority senders are admitted into a full pool
+// regardless of their price, don't count against the global limits and are
+// exempt from eviction.
+func TestPriorityLane(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	priority, _ := crypto.GenerateKey()
+
+	config := testTxPoolConfig
+	config.GlobalSlots = config.AccountSlots * 2
+	config.GlobalQueue = 1
+	config.TxLifetime = time.Hour
+	config.Priority = []common.Address{crypto.PubkeyToAddress(priority.PublicKey)}
+
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	// Fill up the pool with well priced remote transactions
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+	testAddBalance(pool, crypto.PubkeyToAddress(priority.PublicKey), big.NewInt(1000000000))
+
+	var remotes types.Transactions
+	for _, key := range keys {
+		for j := uint64(0); j < config.AccountSlots; j++ {
+			remotes = append(remotes, pricedTransaction(j, 100000, big.NewInt(10), key))
+		}
+	}
+	pool.addRemotesSync(remotes)
+
+	if slots := uint64(pool.all.Slots()); slots < config.GlobalSlots+config.GlobalQueue {
+		t.Fatalf("pool not full: have %d slots, want %d", slots, config.GlobalSlots+config.GlobalQueue)
+	}
+	remoteCount := pool.all.Count()
+
+	// Add cheap priority transactions, both executable and gapped, which would
+	// be rejected as underpriced if submitted by anyone else
+	var txs types.Transactions
+	for i := uint64(0); i < config.AccountSlots; i++ {
+		txs = append(txs, pricedTransaction(i, 100000, big.NewInt(1), priority))
+	}
+	stale := pricedTransaction(config.AccountSlots+1, 100000, big.NewInt(1), priority)
+	stale.SetTime(time.Now().Add(-2 * time.Hour))
+	txs = append(txs, stale)
+	for i, err := range pool.addRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("priority transaction %d rejected: %v", i, err)
+		}
+	}
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(1), keys[0])); err == nil {
+		t.Fatalf("cheap remote transaction accepted into full pool")
+	}
+	// Expire and evict the stale transactions, priority ones should stay
+	pool.mu.Lock()
+	pool.expire()
+	pool.mu.Unlock()
+
+	from := crypto.PubkeyToAddress(priority.PublicKey)
+	if pending, queued := pool.ContentFrom(from); len(pending) != int(config.AccountSlots) || len(queued) != 1 {
+		t.Fatalf("priority lane mismatch: have %d pending %d queued, want %d pending 1 queued", len(pending), len(queued), config.AccountSlots)
+	}
+	// The priority lane should not have displaced any remote transactions
+	if count := pool.all.Count() - len(txs); count != remoteCount {
+		t.Fatalf("remote transaction count mismatch: have %d, want %d", count, remoteCount)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that future transactions evicted due to the pool limits are parked in
+// the overflow tier, survive restarts and are reinjected as slots free up.
+func TestOverflow(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.GlobalQueue = 4
+	config.Overflow = t.TempDir()
+
+	pool := New(config, blockchain)
+	if err := pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock(), makeAddressReserver()); err != nil {
+		t.Fatalf("failed to init pool: %v", err)
+	}
+	drops := make(chan core.DropTxsEvent, 32)
+	sub := pool.SubscribeDropTransactions(drops)
+
+	// Queue more future transactions than the pool can hold
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+
+		var txs []*types.Transaction
+		for nonce := uint64(1); nonce <= 4; nonce++ {
+			txs = append(txs, transaction(nonce, 100000, keys[i]))
+		}
+		pool.addRemotesSync(txs)
+	}
+	if _, queued := pool.Stats(); queued != 4 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 4)
+	}
+	if parked := pool.overflow.len(); parked != 8 {
+		t.Fatalf("parked transactions mismatched: have %d, want %d", parked, 8)
+	}
+	select {
+	case ev := <-drops:
+		t.Fatalf("unexpected drop event: %v %v", ev.Reason, ev.Txs)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	sub.Unsubscribe()
+	pool.Close()
+
+	// Restart the pool and ensure the parked transactions are reloaded
+	pool = New(config, blockchain)
+	if err := pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock(), makeAddressReserver()); err != nil {
+		t.Fatalf("failed to reinit pool: %v", err)
+	}
+	defer pool.Close()
+
+	if parked := pool.overflow.len(); parked != 8 {
+		t.Fatalf("reloaded transactions mismatched: have %d, want %d", parked, 8)
+	}
+	// Fill the nonce gaps and ensure the parked transactions are promoted in
+	// batches not exceeding the queue limit
+	for _, key := range keys {
+		if err := pool.addRemoteSync(transaction(0, 100000, key)); err != nil {
+			t.Fatalf("failed to add gap filler: %v", err)
+		}
+	}
+	for i, want := range []int{4, 0} {
+		<-pool.requestReset(nil, nil)
+		if parked := pool.overflow.len(); parked != want {
+			t.Fatalf("reset %d: parked transactions mismatched: have %d, want %d", i, parked, want)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 11 || queued != 0 {
+		t.Fatalf("pool content mismatched: have %d/%d, want %d/%d", pending, queued, 11, 0)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Ensure the oldest parked transactions are dropped above the size limit
+	key, _ := crypto.GenerateKey()
+	txs := []*types.Transaction{transaction(1, 100000, key), transaction(2, 100000, key), transaction(3, 100000, key)}
+
+	pool.overflow.park(txs[:2])
+	pool.overflow.limit = pool.overflow.size
+
+	if dropped := pool.overflow.park(txs[2:]); len(dropped) != 1 || dropped[0].Hash() != txs[0].Hash() {
+		t.Fatalf("dropped transactions mismatched: have %v, want %v", dropped, txs[:1])
+	}
+}
+
 // Tests that local transactions are journaled to disk, but remote transactions
 // get discarded between restarts.
 func TestJournaling(t *testing.T)         { testJournaling(t, false) }
@@ -2470,6 +2932,66 @@ func TestStatusCheck(t *testing.T) {
 	}
 }
 
+// Tests that the configured admission policies are consulted with the sender
+// and the pool state before accepting transactions.
+func TestAdmissionPolicies(t *testing.T) {
+	t.Parallel()
+
+	var (
+		blocked = []byte{0xde, 0xad, 0xbe, 0xef}
+		allowed = common.Address{0xaa}
+		denied  = common.Address{0xbb}
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(allowed, []byte{0x00})
+	statedb.SetCode(denied, []byte{0x00})
+	blockchain := newTestBlockChain(params.TestChainConfig, 10000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.Policies = []txpool.AdmissionPolicy{
+		// Reject transactions calling a blocked selector
+		txpool.AdmissionPolicyFunc(func(tx *types.Transaction, from common.Address, state txpool.StateReader) error {
+			if bytes.HasPrefix(tx.Data(), blocked) {
+				return errors.New("blocked selector")
+			}
+			return nil
+		}),
+		// Only allow calls into contracts on the allowlist
+		txpool.AdmissionPolicyFunc(func(tx *types.Transaction, from common.Address, state txpool.StateReader) error {
+			if to := tx.To(); to != nil && *to != allowed && state.GetCodeSize(*to) > 0 {
+				return fmt.Errorf("contract %x not allowed", *to)
+			}
+			return nil
+		}),
+	}
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	key, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000000))
+
+	tests := []struct {
+		to   common.Address
+		data []byte
+		err  error
+	}{
+		{to: common.Address{0x01}, data: nil, err: nil},
+		{to: allowed, data: []byte{0x01, 0x02, 0x03, 0x04}, err: nil},
+		{to: allowed, data: append(blocked, 0x01), err: txpool.ErrPolicyRejected},
+		{to: denied, data: nil, err: txpool.ErrPolicyRejected},
+	}
+	for i, tt := range tests {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), tt.to, common.Big0, 100000, big.NewInt(1), tt.data), types.HomesteadSigner{}, key)
+		if err := pool.addRemoteSync(tx); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	if pending, _ := pool.Stats(); pending != 2 {
+		t.Fatalf("pending transactions mismatch: have %d, want %d", pending, 2)
+	}
+}
+
 // Test the transaction slots consumption is computed correctly
 func TestSlotCount(t *testing.T) {
 	t.Parallel()
@@ -2488,6 +3010,120 @@ func TestSlotCount(t *testing.T) {
 	}
 }
 
+func setCodeTx(nonce uint64, key *ecdsa.PrivateKey, auths []types.SetCodeAuthorization) *types.Transaction {
+	return pricedSetCodeTx(nonce, 250000, uint256.NewInt(1000), uint256.NewInt(1), key, auths)
+}
+
+func pricedSetCodeTx(nonce uint64, gaslimit uint64, gasFee, tip *uint256.Int, key *ecdsa.PrivateKey, auths []types.SetCodeAuthorization) *types.Transaction {
+	tx, _ := types.SignNewTx(key, types.LatestSignerForChainID(params.TestChainConfig.ChainID), &types.SetCodeTx{
+		ChainID:   uint256.MustFromBig(params.TestChainConfig.ChainID),
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: gasFee,
+		Gas:       gaslimit,
+		To:        common.Address{},
+		Value:     uint256.NewInt(100),
+		AuthList:  auths,
+	})
+	return tx
+}
+
+func signAuth(t *testing.T, key *ecdsa.PrivateKey, nonce uint64) types.SetCodeAuthorization {
+	t.Helper()
+
+	auth, err := types.SignSetCode(key, types.SetCodeAuthorization{
+		ChainID: *uint256.MustFromBig(params.TestChainConfig.ChainID),
+		Address: common.HexToAddress("0xaaaa"),
+		Nonce:   nonce,
+	})
+	if err != nil {
+		t.Fatalf("failed to sign authorization: %v", err)
+	}
+	return auth
+}
+
+// Tests that set code transactions are only accepted after Prague, and that the
+// pool enforces the in-flight limits on accounts with (pending) delegations.
+func TestSetCodeTransactions(t *testing.T) {
+	t.Parallel()
+
+	// Set code transactions should be rejected before Prague
+	pool, key := setupPool()
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	if err := pool.addRemoteSync(setCodeTx(0, key, []types.SetCodeAuthorization{signAuth(t, key, 1)})); !errors.Is(err, core.ErrTxTypeNotSupported) {
+		t.Fatalf("pre-prague set code tx error mismatch: have %v, want %v", err, core.ErrTxTypeNotSupported)
+	}
+	// Create a Prague pool and fund a few accounts
+	config := *eip1559Config
+	config.ShanghaiTime = new(uint64)
+	config.CancunTime = new(uint64)
+	config.PragueTime = new(uint64)
+
+	pool, _ = setupPoolWithConfig(&config)
+	defer pool.Close()
+
+	var (
+		keyA, _ = crypto.GenerateKey()
+		keyB, _ = crypto.GenerateKey()
+		keyC, _ = crypto.GenerateKey()
+		keyD, _ = crypto.GenerateKey()
+		addrB   = crypto.PubkeyToAddress(keyB.PublicKey)
+		addrD   = crypto.PubkeyToAddress(keyD.PublicKey)
+	)
+	for _, key := range []*ecdsa.PrivateKey{keyA, keyB, keyC, keyD} {
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	}
+	// Empty authorization lists are invalid
+	if err := pool.addRemoteSync(setCodeTx(0, keyA, []types.SetCodeAuthorization{})); !errors.Is(err, core.ErrEmptyAuthList) {
+		t.Fatalf("empty auth list error mismatch: have %v, want %v", err, core.ErrEmptyAuthList)
+	}
+	// A authorizes a delegation for B, which may only have a single tx in flight
+	delegation := setCodeTx(0, keyA, []types.SetCodeAuthorization{signAuth(t, keyB, 0)})
+	if err := pool.addRemoteSync(delegation); err != nil {
+		t.Fatalf("failed to add set code tx: %v", err)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(1000), keyB)); err != nil {
+		t.Fatalf("failed to add tx from pending authority: %v", err)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(1, 100000, big.NewInt(1000), keyB)); !errors.Is(err, ErrInflightTxLimitReached) {
+		t.Fatalf("second in-flight tx error mismatch: have %v, want %v", err, ErrInflightTxLimitReached)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(2000), keyB)); err != nil {
+		t.Fatalf("failed to replace in-flight tx: %v", err)
+	}
+	// Authorities with pooled transactions cannot be delegated
+	if err := pool.addRemoteSync(setCodeTx(1, keyA, []types.SetCodeAuthorization{signAuth(t, keyB, 1)})); !errors.Is(err, ErrAuthorityReserved) {
+		t.Fatalf("reserved authority error mismatch: have %v, want %v", err, ErrAuthorityReserved)
+	}
+	// Accounts delegated in state may only have a single tx in flight
+	pool.mu.Lock()
+	pool.currentState.SetCode(addrD, types.AddressToDelegation(common.HexToAddress("0xaaaa")))
+	pool.mu.Unlock()
+
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(1000), keyD)); err != nil {
+		t.Fatalf("failed to add tx from delegated account: %v", err)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(1, 100000, big.NewInt(1000), keyD)); !errors.Is(err, ErrInflightTxLimitReached) {
+		t.Fatalf("delegated in-flight tx error mismatch: have %v, want %v", err, ErrInflightTxLimitReached)
+	}
+	// Dropping the set code transaction should lift the limits on B
+	pool.mu.Lock()
+	pool.removeTx(delegation.Hash(), true, true)
+	pool.mu.Unlock()
+
+	if pool.all.hasAuth(addrB) {
+		t.Fatalf("authority still tracked after removal")
+	}
+	if err := pool.addRemoteSync(pricedTransaction(1, 100000, big.NewInt(1000), keyB)); err != nil {
+		t.Fatalf("failed to add tx after authorization removal: %v", err)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Benchmarks the speed of validating the contents of the pending queue of the
 // transaction pool.
 func BenchmarkPendingDemotion100(b *testing.B)   { benchmarkPendingDemotion(b, 100) }
@@ -21,6 +21,7 @@ import (
 	"math/rand"
 	"testing"
 
+	"github.com/gorievm/go-gori/core/txpool"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/crypto"
 )
@@ -38,7 +39,7 @@ func TestStrictListAdd(t *testing.T) {
 	// Insert the transactions in a random order
 	list := newList(true)
 	for _, v := range rand.Perm(len(txs)) {
-		list.Add(txs[v], DefaultConfig.PriceBump)
+		list.Add(txs[v], &txpool.ReplacementPolicy{PriceBump: DefaultConfig.PriceBump})
 	}
 	// Verify internal state
 	if len(list.txs.items) != len(txs) {
@@ -65,7 +66,7 @@ func BenchmarkListAdd(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		list := newList(true)
 		for _, v := range rand.Perm(len(txs)) {
-			list.Add(txs[v], DefaultConfig.PriceBump)
+			list.Add(txs[v], &txpool.ReplacementPolicy{PriceBump: DefaultConfig.PriceBump})
 			list.Filter(priceLimit, DefaultConfig.PriceBump)
 		}
 	}
@@ -0,0 +1,356 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/metrics"
+	"github.com/gorievm/go-gori/rlp"
+	"github.com/holiman/billy"
+)
+
+// overflowMinSlot is the smallest shelf size of the overflow store. Shelves are
+// doubled in size until the largest permitted transaction fits.
+const overflowMinSlot = 256
+
+var (
+	// errOverflowed is returned internally by add if a transaction could not fit
+	// into the pool and was moved into the overflow tier instead.
+	errOverflowed = errors.New("transaction overflowed to disk")
+
+	overflowParkMeter    = metrics.NewRegisteredMeter("txpool/overflow/park", nil)
+	overflowRestoreMeter = metrics.NewRegisteredMeter("txpool/overflow/restore", nil)
+	overflowDropMeter    = metrics.NewRegisteredMeter("txpool/overflow/drop", nil)
+	overflowSizeGauge    = metrics.NewRegisteredGauge("txpool/overflow/size", nil)
+)
+
+// overflowTx is the on-disk representation of a parked transaction.
+type overflowTx struct {
+	Time uint64             // Unix time the transaction was parked at
+	Tx   *types.Transaction // Transaction waiting for room in the pool
+}
+
+// overflowEntry is the in-memory metadata of a parked transaction.
+type overflowEntry struct {
+	id    uint64         // Datastore id of the parked transaction
+	hash  common.Hash    // Hash of the parked transaction
+	from  common.Address // Sender of the parked transaction
+	nonce uint64         // Nonce of the parked transaction
+	size  uint64         // Encoded size of the parked transaction
+	time  time.Time      // Time the transaction was parked at
+}
+
+// overflow is an indexed on-disk store of future transactions that did not fit
+// into the memory pool, waiting to be reinjected once slots free up. It allows
+// smaller nodes to survive bursts of traffic without losing valid transactions.
+//
+// The overflow is not thread safe, it is guarded by the pool lock.
+type overflow struct {
+	store  billy.Database // Persistent data store for the parked transactions
+	signer types.Signer   // Signer to recover the senders of the parked transactions
+	limit  uint64         // Maximum cumulative size of the parked transactions
+
+	index    map[common.Hash]*overflowEntry               // Parked transactions by hash
+	accounts map[common.Address]map[uint64]*overflowEntry // Parked transactions by sender and nonce
+	order    []common.Hash                                // Parking order of the transactions, might contain removed ones
+	size     uint64                                       // Cumulative size of the parked transactions
+}
+
+// newOverflowSlotter creates the shelf sizes for the overflow store, doubling
+// from the minimum until the largest permitted transaction fits.
+func newOverflowSlotter() func() (uint32, bool) {
+	slotsize := uint32(overflowMinSlot / 2)
+
+	return func() (size uint32, done bool) {
+		slotsize *= 2
+		return slotsize, slotsize >= 2*txMaxSize
+	}
+}
+
+// newOverflow opens and indexes a set of parked transactions, deleting anything
+// unprocessable and the oldest ones exceeding the size limit.
+func newOverflow(datadir string, limit uint64, signer types.Signer) (*overflow, error) {
+	o := &overflow{
+		signer:   signer,
+		limit:    limit,
+		index:    make(map[common.Hash]*overflowEntry),
+		accounts: make(map[common.Address]map[uint64]*overflowEntry),
+	}
+	var fails []uint64
+	index := func(id uint64, size uint32, data []byte) {
+		if o.parseTx(id, data) != nil {
+			fails = append(fails, id)
+		}
+	}
+	store, err := billy.Open(billy.Options{Path: datadir}, newOverflowSlotter(), index)
+	if err != nil {
+		return nil, err
+	}
+	o.store = store
+
+	if len(fails) > 0 {
+		log.Warn("Dropping invalid overflowed transactions", "ids", fails)
+		for _, id := range fails {
+			if err := o.store.Delete(id); err != nil {
+				o.Close()
+				return nil, err
+			}
+		}
+	}
+	// Disk iteration order is arbitrary, restore the parking order
+	sort.Slice(o.order, func(i, j int) bool {
+		return o.index[o.order[i]].time.Before(o.index[o.order[j]].time)
+	})
+	o.shrink()
+
+	if len(o.index) > 0 {
+		log.Info("Loaded overflowed transactions", "count", len(o.index), "size", common.StorageSize(o.size))
+	}
+	return o, nil
+}
+
+// Close closes down the underlying persistent store.
+func (o *overflow) Close() error {
+	return o.store.Close()
+}
+
+// parseTx is a callback method on overflow creation that gets called for each
+// parked transaction on disk to create the in-memory metadata index.
+func (o *overflow) parseTx(id uint64, data []byte) error {
+	item := new(overflowTx)
+	if err := rlp.DecodeBytes(data, item); err != nil {
+		log.Error("Failed to decode overflowed transaction", "id", id, "err", err)
+		return err
+	}
+	from, err := types.Sender(o.signer, item.Tx)
+	if err != nil {
+		log.Error("Failed to recover overflowed transaction sender", "id", id, "err", err)
+		return err
+	}
+	if _, ok := o.index[item.Tx.Hash()]; ok {
+		log.Error("Dropping duplicate overflowed transaction", "hash", item.Tx.Hash(), "id", id)
+		return errors.New("duplicate transaction")
+	}
+	if _, ok := o.accounts[from][item.Tx.Nonce()]; ok {
+		log.Error("Dropping nonce-colliding overflowed transaction", "from", from, "nonce", item.Tx.Nonce(), "id", id)
+		return errors.New("duplicate nonce")
+	}
+	o.track(&overflowEntry{
+		id:    id,
+		hash:  item.Tx.Hash(),
+		from:  from,
+		nonce: item.Tx.Nonce(),
+		size:  uint64(len(data)),
+		time:  time.Unix(int64(item.Time), 0),
+	})
+	return nil
+}
+
+// track inserts a parked transaction into the in-memory indices.
+func (o *overflow) track(entry *overflowEntry) {
+	o.index[entry.hash] = entry
+	if _, ok := o.accounts[entry.from]; !ok {
+		o.accounts[entry.from] = make(map[uint64]*overflowEntry)
+	}
+	o.accounts[entry.from][entry.nonce] = entry
+	o.order = append(o.order, entry.hash)
+	o.size += entry.size
+	overflowSizeGauge.Update(int64(o.size))
+}
+
+// has reports whether a transaction is parked in the overflow.
+func (o *overflow) has(hash common.Hash) bool {
+	_, ok := o.index[hash]
+	return ok
+}
+
+// len returns the number of parked transactions.
+func (o *overflow) len() int {
+	return len(o.index)
+}
+
+// park moves a batch of transactions into the overflow. If the size limit is
+// exceeded, the oldest transactions are dropped for good and returned. A parked
+// transaction replaces an older one with the same sender and nonce only if it
+// pays a higher fee cap.
+func (o *overflow) park(txs []*types.Transaction) []*types.Transaction {
+	var dropped []*types.Transaction
+	for _, tx := range txs {
+		if _, ok := o.index[tx.Hash()]; ok {
+			continue
+		}
+		from, _ := types.Sender(o.signer, tx) // already validated
+		if old := o.accounts[from][tx.Nonce()]; old != nil {
+			prev := o.get(old.hash)
+			if prev != nil && prev.GasFeeCapCmp(tx) >= 0 {
+				dropped = append(dropped, tx)
+				continue
+			}
+			o.remove(old.hash)
+			if prev != nil {
+				dropped = append(dropped, prev)
+			}
+		}
+		now := time.Now()
+		blob, err := rlp.EncodeToBytes(&overflowTx{Time: uint64(now.Unix()), Tx: tx})
+		if err != nil {
+			log.Error("Failed to encode overflowed transaction", "hash", tx.Hash(), "err", err)
+			dropped = append(dropped, tx)
+			continue
+		}
+		id, err := o.store.Put(blob)
+		if err != nil {
+			log.Error("Failed to write overflowed transaction", "hash", tx.Hash(), "err", err)
+			dropped = append(dropped, tx)
+			continue
+		}
+		o.track(&overflowEntry{
+			id:    id,
+			hash:  tx.Hash(),
+			from:  from,
+			nonce: tx.Nonce(),
+			size:  uint64(len(blob)),
+			time:  now,
+		})
+		overflowParkMeter.Mark(1)
+	}
+	dropped = append(dropped, o.shrink()...)
+	overflowDropMeter.Mark(int64(len(dropped)))
+	return dropped
+}
+
+// shrink drops the oldest parked transactions until the size limit is satisfied,
+// returning the dropped ones.
+func (o *overflow) shrink() []*types.Transaction {
+	var dropped []*types.Transaction
+	for o.size > o.limit && len(o.order) > 0 {
+		hash := o.order[0]
+		o.order = o.order[1:]
+
+		if _, ok := o.index[hash]; !ok {
+			continue
+		}
+		if tx := o.get(hash); tx != nil {
+			dropped = append(dropped, tx)
+		}
+		o.remove(hash)
+	}
+	return dropped
+}
+
+// get retrieves a parked transaction from disk.
+func (o *overflow) get(hash common.Hash) *types.Transaction {
+	entry, ok := o.index[hash]
+	if !ok {
+		return nil
+	}
+	data, err := o.store.Get(entry.id)
+	if err != nil {
+		log.Error("Failed to read overflowed transaction", "hash", hash, "id", entry.id, "err", err)
+		return nil
+	}
+	item := new(overflowTx)
+	if err := rlp.DecodeBytes(data, item); err != nil {
+		log.Error("Failed to decode overflowed transaction", "hash", hash, "id", entry.id, "err", err)
+		return nil
+	}
+	return item.Tx
+}
+
+// remove deletes a parked transaction from the overflow.
+func (o *overflow) remove(hash common.Hash) {
+	entry, ok := o.index[hash]
+	if !ok {
+		return
+	}
+	if err := o.store.Delete(entry.id); err != nil {
+		log.Error("Failed to delete overflowed transaction", "hash", hash, "id", entry.id, "err", err)
+	}
+	delete(o.index, hash)
+	delete(o.accounts[entry.from], entry.nonce)
+	if len(o.accounts[entry.from]) == 0 {
+		delete(o.accounts, entry.from)
+	}
+	o.size -= entry.size
+	overflowSizeGauge.Update(int64(o.size))
+
+	// Compact the parking order if it accumulated too many removed entries
+	if len(o.order) > 2*len(o.index)+64 {
+		order := make([]common.Hash, 0, len(o.index))
+		for _, hash := range o.order {
+			if _, ok := o.index[hash]; ok {
+				order = append(order, hash)
+			}
+		}
+		o.order = order
+	}
+}
+
+// take removes a parked transaction from the overflow and returns it.
+func (o *overflow) take(hash common.Hash) *types.Transaction {
+	tx := o.get(hash)
+	o.remove(hash)
+	if tx != nil {
+		overflowRestoreMeter.Mark(1)
+	}
+	return tx
+}
+
+// candidates returns the hashes of the parked transactions to reinject into the
+// pool, in order of preference. First come the transactions continuing the nonce
+// sequence of their senders, as they are immediately executable, followed by the
+// rest in parking order. Transactions with nonces already used up or parked for
+// longer than the lifetime are dropped from the overflow.
+func (o *overflow) candidates(nonce func(common.Address) uint64, stale func(common.Address) uint64, lifetime time.Duration) []common.Hash {
+	var (
+		executable []common.Hash
+		picked     = make(map[common.Hash]struct{})
+		deadline   = time.Now().Add(-lifetime)
+	)
+	for from, txs := range o.accounts {
+		var (
+			next  = nonce(from)
+			first = stale(from)
+		)
+		for n, entry := range txs {
+			if n < first || entry.time.Before(deadline) {
+				o.remove(entry.hash)
+			}
+		}
+		for entry := txs[next]; entry != nil; entry = txs[next] {
+			executable = append(executable, entry.hash)
+			picked[entry.hash] = struct{}{}
+			next++
+		}
+	}
+	hashes := executable
+	for _, hash := range o.order {
+		if _, ok := o.index[hash]; !ok {
+			continue
+		}
+		if _, ok := picked[hash]; !ok {
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes
+}
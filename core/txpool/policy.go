@@ -0,0 +1,58 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"math/big"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+)
+
+// StateReader is a read-only view of the chain state a transaction is validated
+// against by the pool.
+type StateReader interface {
+	GetBalance(addr common.Address) *big.Int
+	GetNonce(addr common.Address) uint64
+	GetCode(addr common.Address) []byte
+	GetCodeHash(addr common.Address) common.Hash
+	GetCodeSize(addr common.Address) int
+	GetState(addr common.Address, key common.Hash) common.Hash
+}
+
+// AdmissionPolicy is a custom rule a transaction has to satisfy to be admitted
+// into the pool, on top of the consensus and resource limit checks. Policies
+// allow operators to enforce local rules (e.g. calldata blocklists, per-sender
+// gas limits or contract allowlists) without modifying the pools themselves.
+//
+// Policies are invoked with the pool lock held, after the consensus, nonce and
+// balance checks passed. They must be fast, must not retain the state and must
+// not call back into the pool.
+type AdmissionPolicy interface {
+	// Admit returns an error if the transaction from the given sender must be
+	// rejected by the pool.
+	Admit(tx *types.Transaction, from common.Address, state StateReader) error
+}
+
+// AdmissionPolicyFunc is an adapter to allow the use of ordinary functions as
+// admission policies.
+type AdmissionPolicyFunc func(tx *types.Transaction, from common.Address, state StateReader) error
+
+// Admit implements AdmissionPolicy, calling f(tx, from, state).
+func (f AdmissionPolicyFunc) Admit(tx *types.Transaction, from common.Address, state StateReader) error {
+	return f(tx, from, state)
+}
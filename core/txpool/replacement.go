@@ -0,0 +1,106 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gorievm/go-gori/core/types"
+)
+
+// ReplacementPolicy are the rules a transaction has to satisfy to replace an
+// already pooled one with the same sender and nonce.
+type ReplacementPolicy struct {
+	PriceBump   uint64          // Minimum price bump percentage, unless overridden for the transaction type
+	TypeBumps   map[byte]uint64 // Minimum price bump percentages overriding the default for specific transaction types
+	StrictNonce bool            // Whether to reject all replacements, keeping the first transaction seen for a nonce
+}
+
+// ReplacementFees are the fee caps of a pooled transaction that a replacement
+// needs to outbid.
+type ReplacementFees struct {
+	GasFeeCap     *big.Int
+	GasTipCap     *big.Int
+	BlobGasFeeCap *big.Int // nil for non-blob transactions
+}
+
+// Bump returns the minimum price bump percentage needed by a transaction of the
+// given type to replace a pooled one.
+func (p *ReplacementPolicy) Bump(txType byte) uint64 {
+	if bump, ok := p.TypeBumps[txType]; ok {
+		return bump
+	}
+	return p.PriceBump
+}
+
+// Replace returns an error if tx is not allowed to replace the pooled old one.
+// Contrary to Check, the returned error is the bare ErrReplaceDisabled or
+// ErrReplaceUnderpriced without the offending fee details.
+func (p *ReplacementPolicy) Replace(old, tx *types.Transaction) error {
+	err := p.Check(ReplacementFees{
+		GasFeeCap:     old.GasFeeCap(),
+		GasTipCap:     old.GasTipCap(),
+		BlobGasFeeCap: old.BlobGasFeeCap(),
+	}, tx)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrReplaceDisabled):
+		return ErrReplaceDisabled
+	default:
+		return ErrReplaceUnderpriced
+	}
+}
+
+// Check returns an error if tx is not allowed to replace a pooled transaction
+// with the given fee caps.
+//
+// Both the new fee caps need to be strictly higher than the old ones as well as
+// meet the percentage threshold to ensure that the policy is also enforced for
+// low (Wei-level) gas price replacements.
+func (p *ReplacementPolicy) Check(old ReplacementFees, tx *types.Transaction) error {
+	if p.StrictNonce {
+		return ErrReplaceDisabled
+	}
+	var (
+		bump       = p.Bump(tx.Type())
+		multiplier = new(big.Int).SetUint64(100 + bump)
+		onehundred = big.NewInt(100)
+	)
+	threshold := func(old *big.Int) *big.Int {
+		return new(big.Int).Div(new(big.Int).Mul(multiplier, old), onehundred)
+	}
+	switch {
+	case tx.GasFeeCapIntCmp(old.GasFeeCap) <= 0:
+		return fmt.Errorf("%w: new tx gas fee cap %v <= %v queued", ErrReplaceUnderpriced, tx.GasFeeCap(), old.GasFeeCap)
+	case tx.GasTipCapIntCmp(old.GasTipCap) <= 0:
+		return fmt.Errorf("%w: new tx gas tip cap %v <= %v queued", ErrReplaceUnderpriced, tx.GasTipCap(), old.GasTipCap)
+	case old.BlobGasFeeCap != nil && tx.BlobGasFeeCapIntCmp(old.BlobGasFeeCap) <= 0:
+		return fmt.Errorf("%w: new tx blob gas fee cap %v <= %v queued", ErrReplaceUnderpriced, tx.BlobGasFeeCap(), old.BlobGasFeeCap)
+	}
+	switch {
+	case tx.GasFeeCapIntCmp(threshold(old.GasFeeCap)) < 0:
+		return fmt.Errorf("%w: new tx gas fee cap %v <= %v queued + %d%% replacement penalty", ErrReplaceUnderpriced, tx.GasFeeCap(), old.GasFeeCap, bump)
+	case tx.GasTipCapIntCmp(threshold(old.GasTipCap)) < 0:
+		return fmt.Errorf("%w: new tx gas tip cap %v <= %v queued + %d%% replacement penalty", ErrReplaceUnderpriced, tx.GasTipCap(), old.GasTipCap, bump)
+	case old.BlobGasFeeCap != nil && tx.BlobGasFeeCapIntCmp(threshold(old.BlobGasFeeCap)) < 0:
+		return fmt.Errorf("%w: new tx blob gas fee cap %v <= %v queued + %d%% replacement penalty", ErrReplaceUnderpriced, tx.BlobGasFeeCap(), old.BlobGasFeeCap, bump)
+	}
+	return nil
+}
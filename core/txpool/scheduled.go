@@ -0,0 +1,210 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/metrics"
+)
+
+const (
+	// maxScheduled is the maximum number of transactions held back by the pool
+	// until their inclusion conditions are met.
+	maxScheduled = 1024
+
+	// maxScheduleBlocks is the maximum number of blocks a transaction may be
+	// held back for.
+	maxScheduleBlocks = 7 * 7200
+
+	// maxScheduleDelay is the maximum amount of time a transaction may be held
+	// back for.
+	maxScheduleDelay = 7 * 24 * time.Hour
+
+	// scheduleRecheckInterval is the time interval to check the timestamp
+	// conditions of the held back transactions at, between blocks.
+	scheduleRecheckInterval = time.Second
+)
+
+var (
+	// ErrConditionRange is returned if a transaction's inclusion conditions
+	// are inconsistent or can't be satisfied anymore.
+	ErrConditionRange = errors.New("invalid transaction conditions")
+
+	// ErrConditionTooFar is returned if a transaction's inclusion conditions
+	// would hold it back for longer than the pool is willing to.
+	ErrConditionTooFar = errors.New("transaction conditions too far in the future")
+
+	// ErrSchedulePoolFull is returned if the limit of transactions held back by
+	// the pool is reached.
+	ErrSchedulePoolFull = errors.New("scheduled transaction pool full")
+)
+
+var (
+	scheduledAddMeter      = metrics.NewRegisteredMeter("txpool/scheduled/add", nil)
+	scheduledPromotedMeter = metrics.NewRegisteredMeter("txpool/scheduled/promoted", nil)
+	scheduledExpiredMeter  = metrics.NewRegisteredMeter("txpool/scheduled/expired", nil)
+	scheduledGauge         = metrics.NewRegisteredGauge("txpool/scheduled", nil)
+)
+
+// Condition restricts the blocks a transaction may be included in. Zero fields
+// are not restricting.
+type Condition struct {
+	MinBlock     uint64 // First block number the transaction may be included in
+	MaxBlock     uint64 // Last block number the transaction may be included in
+	MinTimestamp uint64 // Earliest block timestamp the transaction may be included at
+	MaxTimestamp uint64 // Latest block timestamp the transaction may be included at
+}
+
+// validate checks the consistency of the conditions on top of the given head
+// block, also ensuring they are not too far in the future.
+func (c *Condition) validate(head *types.Header, now uint64) error {
+	if (c.MaxBlock != 0 && c.MaxBlock < c.MinBlock) || (c.MaxTimestamp != 0 && c.MaxTimestamp < c.MinTimestamp) {
+		return fmt.Errorf("%w: block range [%d, %d], timestamp range [%d, %d]", ErrConditionRange, c.MinBlock, c.MaxBlock, c.MinTimestamp, c.MaxTimestamp)
+	}
+	next := head.Number.Uint64() + 1
+	if c.expired(next, now) {
+		return fmt.Errorf("%w: expired at block %d, time %d", ErrConditionRange, next, now)
+	}
+	if c.MinBlock > next+maxScheduleBlocks {
+		return fmt.Errorf("%w: min block %d, next block %d", ErrConditionTooFar, c.MinBlock, next)
+	}
+	if c.MinTimestamp > now+uint64(maxScheduleDelay/time.Second) {
+		return fmt.Errorf("%w: min timestamp %d, now %d", ErrConditionTooFar, c.MinTimestamp, now)
+	}
+	return nil
+}
+
+// ready returns whether the transaction may be included in the block with the
+// given number, built at the given time.
+func (c *Condition) ready(number uint64, now uint64) bool {
+	return number >= c.MinBlock && now >= c.MinTimestamp
+}
+
+// expired returns whether the transaction may not be included anymore in the
+// block with the given number or any later one.
+func (c *Condition) expired(number uint64, now uint64) bool {
+	return (c.MaxBlock != 0 && number > c.MaxBlock) || (c.MaxTimestamp != 0 && now > c.MaxTimestamp)
+}
+
+// scheduledTx is a transaction held back until its inclusion conditions are met.
+type scheduledTx struct {
+	tx   *Transaction
+	cond Condition
+	time time.Time // Time the transaction was scheduled
+}
+
+// scheduleSet tracks the transactions held back until their conditions are met,
+// handing them over to the subpools once they become includable.
+//
+// Scheduled transactions are not gossiped and are not persisted across restarts.
+type scheduleSet struct {
+	head *types.Header                // Current head of the chain the conditions are checked against
+	txs  map[common.Hash]*scheduledTx // Transactions held back, keyed by hash
+	lock sync.Mutex
+}
+
+// newScheduleSet creates an empty schedule set tracking conditions on top of
+// the given head block.
+func newScheduleSet(head *types.Header) *scheduleSet {
+	return &scheduleSet{
+		head: head,
+		txs:  make(map[common.Hash]*scheduledTx),
+	}
+}
+
+// add validates the conditions of a transaction and holds it back if they are
+// not yet met, otherwise it reports the transaction as ready for the subpools.
+func (s *scheduleSet) add(tx *Transaction, cond Condition, now time.Time) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	unix := uint64(now.Unix())
+	if err := cond.validate(s.head, unix); err != nil {
+		return false, err
+	}
+	if cond.ready(s.head.Number.Uint64()+1, unix) {
+		return true, nil
+	}
+	hash := tx.Tx.Hash()
+	if _, ok := s.txs[hash]; ok {
+		return false, ErrAlreadyKnown
+	}
+	if len(s.txs) >= maxScheduled {
+		return false, ErrSchedulePoolFull
+	}
+	s.txs[hash] = &scheduledTx{tx: tx, cond: cond, time: now}
+
+	scheduledAddMeter.Mark(1)
+	scheduledGauge.Update(int64(len(s.txs)))
+	log.Debug("Scheduled conditional transaction", "hash", hash, "minblock", cond.MinBlock, "mintime", cond.MinTimestamp)
+	return false, nil
+}
+
+// reset updates the head of the set, against which conditions are checked.
+func (s *scheduleSet) reset(head *types.Header) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.head = head
+}
+
+// promote removes and returns all the transactions which became includable in
+// the block following the current head, dropping the expired ones.
+func (s *scheduleSet) promote(now time.Time) []*Transaction {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.txs) == 0 {
+		return nil
+	}
+	var (
+		next  = s.head.Number.Uint64() + 1
+		unix  = uint64(now.Unix())
+		ready []*Transaction
+	)
+	for hash, stx := range s.txs {
+		switch {
+		case stx.cond.expired(next, unix):
+			log.Debug("Dropping expired conditional transaction", "hash", hash, "age", common.PrettyDuration(now.Sub(stx.time)))
+			scheduledExpiredMeter.Mark(1)
+			delete(s.txs, hash)
+
+		case stx.cond.ready(next, unix):
+			ready = append(ready, stx.tx)
+			delete(s.txs, hash)
+		}
+	}
+	scheduledPromotedMeter.Mark(int64(len(ready)))
+	scheduledGauge.Update(int64(len(s.txs)))
+	return ready
+}
+
+// has returns whether a transaction is held back in the set.
+func (s *scheduleSet) has(hash common.Hash) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, ok := s.txs[hash]
+	return ok
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gorievm/go-gori/core/types"
+)
+
+// Tests that invalid or far future conditions are rejected, and that already
+// satisfied ones are reported as ready without holding the transaction back.
+func TestScheduleValidation(t *testing.T) {
+	var (
+		head = &types.Header{Number: big.NewInt(10)}
+		now  = time.Unix(1000, 0)
+		tx   = &Transaction{Tx: makeBundleTxs(0, 1)[0]}
+	)
+	tests := []struct {
+		cond  Condition
+		ready bool
+		err   error
+	}{
+		{Condition{}, true, nil},
+		{Condition{MinBlock: 11}, true, nil},
+		{Condition{MinTimestamp: 1000}, true, nil},
+		{Condition{MinBlock: 12}, false, nil},
+		{Condition{MinTimestamp: 1001}, false, nil},
+		{Condition{MinBlock: 12, MaxBlock: 11}, false, ErrConditionRange},
+		{Condition{MinTimestamp: 1002, MaxTimestamp: 1001}, false, ErrConditionRange},
+		{Condition{MaxBlock: 10}, false, ErrConditionRange},
+		{Condition{MaxTimestamp: 999}, false, ErrConditionRange},
+		{Condition{MinBlock: 11 + maxScheduleBlocks + 1}, false, ErrConditionTooFar},
+		{Condition{MinTimestamp: 1000 + uint64(maxScheduleDelay/time.Second) + 1}, false, ErrConditionTooFar},
+	}
+	for i, tt := range tests {
+		set := newScheduleSet(head)
+		ready, err := set.add(tx, tt.cond, now)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+		if ready != tt.ready {
+			t.Errorf("test %d: readiness mismatch: have %v, want %v", i, ready, tt.ready)
+		}
+		if held := set.has(tx.Tx.Hash()); held != (err == nil && !ready) {
+			t.Errorf("test %d: held back mismatch: have %v", i, held)
+		}
+	}
+}
+
+// Tests that held back transactions are promoted once their block or timestamp
+// conditions are met, and dropped if they expire before.
+func TestScheduleLifecycle(t *testing.T) {
+	var (
+		now = time.Unix(1000, 0)
+		set = newScheduleSet(&types.Header{Number: big.NewInt(10)})
+		txs = makeBundleTxs(0, 4)
+	)
+	conds := []Condition{
+		{MinBlock: 13},                     // promoted at head 12
+		{MinTimestamp: 1010},               // promoted after 10 seconds
+		{MinBlock: 14, MaxTimestamp: 1005}, // expires before its block
+		{MinBlock: 20},                     // held back throughout
+	}
+	for i, cond := range conds {
+		if ready, err := set.add(&Transaction{Tx: txs[i]}, cond, now); ready || err != nil {
+			t.Fatalf("tx %d: failed to schedule: ready %v, err %v", i, ready, err)
+		}
+	}
+	if _, err := set.add(&Transaction{Tx: txs[0]}, conds[0], now); !errors.Is(err, ErrAlreadyKnown) {
+		t.Errorf("duplicate error mismatch: have %v, want %v", err, ErrAlreadyKnown)
+	}
+	check := func(promoted []*Transaction, want ...int) {
+		t.Helper()
+		if len(promoted) != len(want) {
+			t.Fatalf("promoted count mismatch: have %d, want %d", len(promoted), len(want))
+		}
+		for _, idx := range want {
+			var found bool
+			for _, tx := range promoted {
+				found = found || tx.Tx == txs[idx]
+			}
+			if !found {
+				t.Errorf("tx %d not promoted", idx)
+			}
+		}
+	}
+	check(set.promote(now))
+
+	set.reset(&types.Header{Number: big.NewInt(12)})
+	check(set.promote(now), 0)
+
+	check(set.promote(now.Add(10*time.Second)), 1)
+	if set.has(txs[2].Hash()) {
+		t.Errorf("expired transaction still held back")
+	}
+	set.reset(&types.Header{Number: big.NewInt(13)})
+	check(set.promote(now.Add(20 * time.Second)))
+
+	if !set.has(txs[3].Hash()) {
+		t.Errorf("future transaction dropped")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"time"
 
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/core"
@@ -49,6 +50,10 @@ var (
 	reservationsGaugeName = "txpool/reservations"
 )
 
+// stopTimeout is the time the pool is given to wait for the conditional
+// transactions being promoted before its subpools are torn down.
+const stopTimeout = 10 * time.Second
+
 // BlockChain defines the minimal set of methods needed to back a tx pool with
 // a chain. Exists to allow mocking the live chain out of tests.
 type BlockChain interface {
@@ -65,13 +70,27 @@ type BlockChain interface {
 // They exit the pool when they are included in the blockchain or evicted due to
 // resource constraints.
 type TxPool struct {
-	subpools []SubPool // List of subpools for specialized transaction handling
+	subpools  []SubPool    // List of subpools for specialized transaction handling
+	bundles   *bundleSet   // Transaction bundles awaiting inclusion as a whole
+	scheduled *scheduleSet // Conditional transactions held back until includable
 
 	reservations map[common.Address]SubPool // Map with the account to pool reservations
 	reserveLock  sync.Mutex                 // Lock protecting the account reservations
 
-	subs event.SubscriptionScope // Subscription scope to unscubscribe all on shutdown
-	quit chan chan error         // Quit channel to tear down the head updater
+	stats     AdmissionStats // Admission statistics since the pool was started
+	statsLock sync.Mutex     // Lock protecting the admission statistics
+
+	subs      event.SubscriptionScope // Subscription scope to unscubscribe all on shutdown
+	quit      chan chan error         // Quit channel to tear down the head updater
+	abort     chan struct{}           // Closed on force stop to stop waiting for promotions
+	abortOnce sync.Once               // Ensures the abort channel is only closed once
+}
+
+// AdmissionStats are the counters of the transactions accepted and rejected by
+// the pool, the latter grouped by the root cause of the rejection.
+type AdmissionStats struct {
+	Accepted uint64            `json:"accepted"`
+	Rejected map[string]uint64 `json:"rejected"`
 }
 
 // New creates a new transaction pool to gather, sort and filter inbound
@@ -84,8 +103,12 @@ func New(gasTip *big.Int, chain BlockChain, subpools []SubPool) (*TxPool, error)
 
 	pool := &TxPool{
 		subpools:     subpools,
+		bundles:      newBundleSet(head),
+		scheduled:    newScheduleSet(head),
 		reservations: make(map[common.Address]SubPool),
+		stats:        AdmissionStats{Rejected: make(map[string]uint64)},
 		quit:         make(chan chan error),
+		abort:        make(chan struct{}),
 	}
 	for i, subpool := range subpools {
 		if err := subpool.Init(gasTip, head, pool.reserver(i, subpool)); err != nil {
@@ -168,6 +191,19 @@ func (p *TxPool) Close() error {
 	return nil
 }
 
+// StopTimeout returns the time Close may take before its shutdown should be
+// escalated via ForceStop.
+func (p *TxPool) StopTimeout() time.Duration {
+	return stopTimeout
+}
+
+// ForceStop makes a running or future Close stop waiting for the conditional
+// transactions being promoted, dropping the ones not yet inserted. It does not
+// block.
+func (p *TxPool) ForceStop() {
+	p.abortOnce.Do(func() { close(p.abort) })
+}
+
 // loop is the transaction pool's main event loop, waiting for and reacting to
 // outside blockchain events as well as for various reporting and transaction
 // eviction events.
@@ -189,6 +225,24 @@ func (p *TxPool) loop(head *types.Header, chain BlockChain) {
 		resetBusy = make(chan struct{}, 1) // Allow 1 reset to run concurrently
 		resetDone = make(chan *types.Header)
 	)
+	// Periodically check the timestamp conditions of held back transactions
+	var (
+		schedule  = time.NewTicker(scheduleRecheckInterval)
+		promoting sync.WaitGroup
+	)
+	defer schedule.Stop()
+
+	promote := func() {
+		if txs := p.scheduled.promote(time.Now()); len(txs) > 0 {
+			// Subpool insertions might block on a running reset, don't hold up
+			// the head events meanwhile
+			promoting.Add(1)
+			go func() {
+				defer promoting.Done()
+				p.addScheduled(txs)
+			}()
+		}
+	}
 	var errc chan error
 	for errc == nil {
 		// Something interesting might have happened, run a reset if there is
@@ -216,6 +270,16 @@ func (p *TxPool) loop(head *types.Header, chain BlockChain) {
 			// Chain moved forward, store the head for later consumption
 			newHead = event.Block.Header()
 
+			// Bundles don't need any state, drop the included ones right away
+			p.bundles.reset(event.Block)
+
+			// Hand over the conditional transactions includable in the next block
+			p.scheduled.reset(newHead)
+			promote()
+
+		case <-schedule.C:
+			promote()
+
 		case head := <-resetDone:
 			// Previous reset finished, update the old head and allow a new reset
 			oldHead = head
@@ -225,7 +289,19 @@ func (p *TxPool) loop(head *types.Header, chain BlockChain) {
 			// Termination requested, break out on the next loop round
 		}
 	}
-	// Notify the closer of termination (no error possible for now)
+	// Wait for any scheduled insertions before the subpools are torn down, unless
+	// the shutdown is forced, then notify the closer of termination (no error
+	// possible for now)
+	promoted := make(chan struct{})
+	go func() {
+		promoting.Wait()
+		close(promoted)
+	}()
+	select {
+	case <-promoted:
+	case <-p.abort:
+		log.Warn("Dropping conditional transactions being promoted")
+	}
 	errc <- nil
 }
 
@@ -301,9 +377,86 @@ func (p *TxPool) Add(txs []*Transaction, local bool, sync bool) []error {
 		errs[i] = errsets[split][0]
 		errsets[split] = errsets[split][1:]
 	}
+	p.trackAdmissions(errs)
 	return errs
 }
 
+// AddBundle inserts an ordered bundle of transactions into the pool, which are
+// to be included all together or not at all. The bundle is only validated
+// statelessly, its transactions are executed when the miner attempts to include
+// them.
+func (p *TxPool) AddBundle(bundle *Bundle) error {
+	return p.bundles.add(bundle)
+}
+
+// AddConditional inserts a transaction into the pool which may only be included
+// into blocks satisfying the given conditions. If the conditions are not met by
+// the next block yet, the transaction is held back and only handed over to the
+// subpools when they are.
+func (p *TxPool) AddConditional(tx *Transaction, cond Condition) error {
+	ready, err := p.scheduled.add(tx, cond, time.Now())
+	if err != nil || !ready {
+		return err
+	}
+	return p.Add([]*Transaction{tx}, true, false)[0]
+}
+
+// addScheduled inserts the conditional transactions which became includable
+// into the subpools.
+func (p *TxPool) addScheduled(txs []*Transaction) {
+	select {
+	case <-p.abort:
+		return
+	default:
+	}
+	for i, err := range p.Add(txs, true, false) {
+		if err != nil {
+			log.Debug("Failed to promote conditional transaction", "hash", txs[i].Tx.Hash(), "err", err)
+		}
+	}
+}
+
+// Bundles retrieves the bundles that may be included into the block with the
+// given number, in submission order.
+func (p *TxPool) Bundles(number uint64) []*Bundle {
+	return p.bundles.pending(number)
+}
+
+// trackAdmissions updates the admission statistics with the results of adding
+// a batch of transactions.
+func (p *TxPool) trackAdmissions(errs []error) {
+	p.statsLock.Lock()
+	defer p.statsLock.Unlock()
+
+	for _, err := range errs {
+		if err == nil {
+			p.stats.Accepted++
+			continue
+		}
+		// Errors are usually wrapped with transaction specifics, group them by
+		// the underlying error instead
+		for inner := errors.Unwrap(err); inner != nil; inner = errors.Unwrap(err) {
+			err = inner
+		}
+		p.stats.Rejected[err.Error()]++
+	}
+}
+
+// AdmissionStats returns a copy of the admission statistics of the pool.
+func (p *TxPool) AdmissionStats() AdmissionStats {
+	p.statsLock.Lock()
+	defer p.statsLock.Unlock()
+
+	stats := AdmissionStats{
+		Accepted: p.stats.Accepted,
+		Rejected: make(map[string]uint64, len(p.stats.Rejected)),
+	}
+	for reason, count := range p.stats.Rejected {
+		stats.Rejected[reason] = count
+	}
+	return stats
+}
+
 // Pending retrieves all currently processable transactions, grouped by origin
 // account and sorted by nonce.
 func (p *TxPool) Pending(enforceTips bool) map[common.Address][]*LazyTransaction {
@@ -326,6 +479,16 @@ func (p *TxPool) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscrip
 	return p.subs.Track(event.JoinSubscriptions(subs...))
 }
 
+// SubscribeDropTxsEvent registers a subscription of DropTxsEvent and starts
+// sending events to the given channel.
+func (p *TxPool) SubscribeDropTxsEvent(ch chan<- core.DropTxsEvent) event.Subscription {
+	subs := make([]event.Subscription, len(p.subpools))
+	for i, subpool := range p.subpools {
+		subs[i] = subpool.SubscribeDropTransactions(ch)
+	}
+	return p.subs.Track(event.JoinSubscriptions(subs...))
+}
+
 // Nonce returns the next nonce of an account, with all transactions executable
 // by the pool already applied on top.
 func (p *TxPool) Nonce(addr common.Address) uint64 {
@@ -386,6 +549,18 @@ func (p *TxPool) ContentFrom(addr common.Address) ([]*types.Transaction, []*type
 	return []*types.Transaction{}, []*types.Transaction{}
 }
 
+// InspectFrom summarizes the transactions of a single sender, retrieving only
+// the content of the subpool the sender is assigned to.
+func (p *TxPool) InspectFrom(addr common.Address) *SenderInspection {
+	pending, queued := p.ContentFrom(addr)
+
+	var nonce uint64
+	if len(pending) == 0 {
+		nonce = p.Nonce(addr)
+	}
+	return NewSenderInspection(nonce, pending, queued)
+}
+
 // Locals retrieves the accounts currently considered local by the pool.
 func (p *TxPool) Locals() []common.Address {
 	// Retrieve the locals from each subpool and deduplicate them
@@ -411,5 +586,8 @@ func (p *TxPool) Status(hash common.Hash) TxStatus {
 			return status
 		}
 	}
+	if p.scheduled.has(hash) {
+		return TxStatusQueued
+	}
 	return TxStatusUnknown
 }
@@ -0,0 +1,111 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/event"
+)
+
+// testChain is a mock chain feeding head events to the transaction pool.
+type testChain struct {
+	head *types.Header
+	feed event.Feed
+}
+
+func (c *testChain) CurrentBlock() *types.Header { return c.head }
+
+func (c *testChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+// stuckSubPool is a mock subpool accepting all transactions, whose insertions
+// block until released.
+type stuckSubPool struct {
+	SubPool
+
+	adding  chan struct{}
+	release chan struct{}
+}
+
+func (p *stuckSubPool) Filter(tx *types.Transaction) bool { return true }
+
+func (p *stuckSubPool) Init(gasTip *big.Int, head *types.Header, reserve AddressReserver) error {
+	return nil
+}
+
+func (p *stuckSubPool) Close() error { return nil }
+
+func (p *stuckSubPool) Reset(oldHead, newHead *types.Header) {}
+
+func (p *stuckSubPool) Add(txs []*Transaction, local bool, sync bool) []error {
+	p.adding <- struct{}{}
+	<-p.release
+	return make([]error, len(txs))
+}
+
+// Tests that closing the pool waits for the conditional transactions being
+// promoted, unless the shutdown is forced.
+func TestForceStopAbandonsPromotions(t *testing.T) {
+	var (
+		chain   = &testChain{head: &types.Header{Number: big.NewInt(10)}}
+		subpool = &stuckSubPool{adding: make(chan struct{}), release: make(chan struct{})}
+	)
+	defer close(subpool.release)
+
+	pool, err := New(big.NewInt(1), chain, []SubPool{subpool})
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	tx := &Transaction{Tx: makeBundleTxs(0, 1)[0]}
+	if err := pool.AddConditional(tx, Condition{MinBlock: 12}); err != nil {
+		t.Fatalf("failed to schedule transaction: %v", err)
+	}
+	// Make the transaction includable and wait for its insertion to get stuck
+	head := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(11)})
+	for chain.feed.Send(core.ChainHeadEvent{Block: head}) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case <-subpool.adding:
+	case <-time.After(time.Second):
+		t.Fatalf("conditional transaction not promoted")
+	}
+	closed := make(chan error, 1)
+	go func() { closed <- pool.Close() }()
+
+	select {
+	case <-closed:
+		t.Fatalf("pool closed with a promotion in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+	pool.ForceStop()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("failed to close pool: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("pool not closed after force stop")
+	}
+}
@@ -18,6 +18,7 @@
 package types
 
 import (
+	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -90,6 +91,12 @@ type Header struct {
 
 	// ExcessBlobGas was added by EIP-4844 and is ignored in legacy headers.
 	ExcessBlobGas *uint64 `json:"excessBlobGas" rlp:"optional"`
+
+	// ParentBeaconRoot was added by EIP-4788 and is ignored in legacy headers.
+	ParentBeaconRoot *common.Hash `json:"parentBeaconBlockRoot" rlp:"optional"`
+
+	// RequestsHash was added by EIP-7685 and is ignored in legacy headers.
+	RequestsHash *common.Hash `json:"requestsHash" rlp:"optional"`
 }
 
 // field type overrides for gencodec
@@ -297,6 +304,14 @@ func CopyHeader(h *Header) *Header {
 		cpy.BlobGasUsed = new(uint64)
 		*cpy.BlobGasUsed = *h.BlobGasUsed
 	}
+	if h.ParentBeaconRoot != nil {
+		cpy.ParentBeaconRoot = new(common.Hash)
+		*cpy.ParentBeaconRoot = *h.ParentBeaconRoot
+	}
+	if h.RequestsHash != nil {
+		cpy.RequestsHash = new(common.Hash)
+		*cpy.RequestsHash = *h.RequestsHash
+	}
 	return &cpy
 }
 
@@ -394,6 +409,24 @@ func (b *Block) BlobGasUsed() *uint64 {
 	return blobGasUsed
 }
 
+func (b *Block) BeaconRoot() *common.Hash {
+	var beaconRoot *common.Hash
+	if b.header.ParentBeaconRoot != nil {
+		beaconRoot = new(common.Hash)
+		*beaconRoot = *b.header.ParentBeaconRoot
+	}
+	return beaconRoot
+}
+
+func (b *Block) RequestsHash() *common.Hash {
+	var requestsHash *common.Hash
+	if b.header.RequestsHash != nil {
+		requestsHash = new(common.Hash)
+		*requestsHash = *b.header.RequestsHash
+	}
+	return requestsHash
+}
+
 // Size returns the true RLP encoded storage size of the block, either by encoding
 // and returning it, or returning a previously cached value.
 func (b *Block) Size() uint64 {
@@ -426,6 +459,26 @@ func CalcUncleHash(uncles []*Header) common.Hash {
 	return rlpHash(uncles)
 }
 
+// CalcRequestsHash creates the EIP-7685 commitment to the given execution layer
+// requests, each prefixed with its request type. Requests without any data
+// beyond their type are left out of the commitment.
+func CalcRequestsHash(requests [][]byte) common.Hash {
+	var (
+		outer = sha256.New()
+		inner = sha256.New()
+		buf   common.Hash
+	)
+	for _, request := range requests {
+		if len(request) > 1 {
+			inner.Reset()
+			inner.Write(request)
+			outer.Write(inner.Sum(buf[:0]))
+		}
+	}
+	outer.Sum(buf[:0])
+	return buf
+}
+
 // NewBlockWithHeader creates a block with the given header data. The
 // header data is copied, changes to header and to the field values
 // will not affect the block.
@@ -0,0 +1,147 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package canonical implements a stable, documented encoding of block headers,
+// transactions and receipts for archival and cross-language interchange.
+//
+// Unlike the RPC encodings, which evolve together with the APIs serving them,
+// the canonical encoding only ever gains new optional fields and is guaranteed
+// to round-trip: decoding a canonical document and encoding the result again
+// yields the exact same bytes, and the decoded object has the same consensus
+// encoding (and thus hash) as the original.
+//
+// Two equivalent representations are supported, sharing the same field names:
+//
+// JSON documents are objects with lexicographically sorted keys and no
+// insignificant whitespace. Integers are encoded as decimal strings without
+// leading zeroes (e.g. "1024") to avoid precision loss in JavaScript and other
+// languages with floating point numbers. Binary data (hashes, addresses, byte
+// blobs) is encoded as lowercase hex strings with a 0x prefix. Absent optional
+// fields are omitted rather than set to null.
+//
+// CBOR documents follow the core deterministic encoding requirements of RFC 8949
+// (section 4.2.1): definite lengths, shortest-form integer arguments and map keys
+// sorted by the bytewise order of their encodings. Integers that fit into 64 bits
+// are encoded as unsigned integers (major type 0), larger ones as bignums (tag 2).
+// Binary data is encoded as byte strings (major type 2).
+//
+// Only consensus fields are encoded. Derived metadata, such as the block hash of
+// a receipt or the sender of a transaction, is not part of the encoding as it
+// can be recomputed from the consensus data.
+//
+// Decoders are strict: unknown fields, malformed values and non-canonical
+// encodings are all rejected.
+package canonical
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/gorievm/go-gori/core/types"
+)
+
+// ErrNotCanonical is returned if a document decodes successfully but is not in
+// canonical form (e.g. unsorted keys, extra whitespace or non-shortest integers).
+var ErrNotCanonical = errors.New("non-canonical encoding")
+
+// format is one of the concrete representations of the canonical encoding.
+type format interface {
+	marshal(v map[string]interface{}) ([]byte, error)
+	unmarshal(data []byte) (map[string]interface{}, error)
+}
+
+// decode parses a document in the given format, converts it into a typed object
+// and ensures that the input was in canonical form.
+func decode[T any](f format, data []byte, convert func(map[string]interface{}) (T, error), encode func(T) map[string]interface{}) (T, error) {
+	var nilT T
+
+	obj, err := f.unmarshal(data)
+	if err != nil {
+		return nilT, err
+	}
+	val, err := convert(obj)
+	if err != nil {
+		return nilT, err
+	}
+	blob, err := f.marshal(encode(val))
+	if err != nil {
+		return nilT, err
+	}
+	if !bytes.Equal(blob, data) {
+		return nilT, ErrNotCanonical
+	}
+	return val, nil
+}
+
+// MarshalHeaderJSON encodes a block header into canonical JSON.
+func MarshalHeaderJSON(h *types.Header) ([]byte, error) {
+	return jsonFormat{}.marshal(encodeHeader(h))
+}
+
+// UnmarshalHeaderJSON decodes a block header from canonical JSON.
+func UnmarshalHeaderJSON(data []byte) (*types.Header, error) {
+	return decode(jsonFormat{}, data, decodeHeader, encodeHeader)
+}
+
+// MarshalHeaderCBOR encodes a block header into canonical CBOR.
+func MarshalHeaderCBOR(h *types.Header) ([]byte, error) {
+	return cborFormat{}.marshal(encodeHeader(h))
+}
+
+// UnmarshalHeaderCBOR decodes a block header from canonical CBOR.
+func UnmarshalHeaderCBOR(data []byte) (*types.Header, error) {
+	return decode(cborFormat{}, data, decodeHeader, encodeHeader)
+}
+
+// MarshalTransactionJSON encodes a transaction into canonical JSON.
+func MarshalTransactionJSON(tx *types.Transaction) ([]byte, error) {
+	return jsonFormat{}.marshal(encodeTransaction(tx))
+}
+
+// UnmarshalTransactionJSON decodes a transaction from canonical JSON.
+func UnmarshalTransactionJSON(data []byte) (*types.Transaction, error) {
+	return decode(jsonFormat{}, data, decodeTransaction, encodeTransaction)
+}
+
+// MarshalTransactionCBOR encodes a transaction into canonical CBOR.
+func MarshalTransactionCBOR(tx *types.Transaction) ([]byte, error) {
+	return cborFormat{}.marshal(encodeTransaction(tx))
+}
+
+// UnmarshalTransactionCBOR decodes a transaction from canonical CBOR.
+func UnmarshalTransactionCBOR(data []byte) (*types.Transaction, error) {
+	return decode(cborFormat{}, data, decodeTransaction, encodeTransaction)
+}
+
+// MarshalReceiptJSON encodes the consensus fields of a receipt into canonical JSON.
+func MarshalReceiptJSON(r *types.Receipt) ([]byte, error) {
+	return jsonFormat{}.marshal(encodeReceipt(r))
+}
+
+// UnmarshalReceiptJSON decodes the consensus fields of a receipt from canonical JSON.
+func UnmarshalReceiptJSON(data []byte) (*types.Receipt, error) {
+	return decode(jsonFormat{}, data, decodeReceipt, encodeReceipt)
+}
+
+// MarshalReceiptCBOR encodes the consensus fields of a receipt into canonical CBOR.
+func MarshalReceiptCBOR(r *types.Receipt) ([]byte, error) {
+	return cborFormat{}.marshal(encodeReceipt(r))
+}
+
+// UnmarshalReceiptCBOR decodes the consensus fields of a receipt from canonical CBOR.
+func UnmarshalReceiptCBOR(data []byte) (*types.Receipt, error) {
+	return decode(cborFormat{}, data, decodeReceipt, encodeReceipt)
+}
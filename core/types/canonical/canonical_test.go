@@ -0,0 +1,338 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package canonical
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/params"
+	"github.com/holiman/uint256"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testTo     = common.HexToAddress("0x095e7baea6a6c7c4c2dfeb977efac326af552d87")
+)
+
+// testHeaders returns a set of headers spanning all the optional header fields.
+func testHeaders() map[string]*types.Header {
+	blobGasUsed, excessBlobGas := uint64(131072), uint64(0)
+	withdrawalsHash := common.HexToHash("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+	legacy := &types.Header{
+		ParentHash:  common.HexToHash("0x1e77d8f1267348b516ebc4f4da1e2aa59f85f0cbd853949500ffac8bfc38ba14"),
+		UncleHash:   types.EmptyUncleHash,
+		Coinbase:    common.HexToAddress("0x8888f1f195afa192cfee860698584c030f4c9db1"),
+		Root:        common.HexToHash("0xef1552a40b7165c3cd773806b9e0c165b75356e0314bf0706f279c729f51e017"),
+		TxHash:      types.EmptyTxsHash,
+		ReceiptHash: types.EmptyReceiptsHash,
+		Difficulty:  big.NewInt(131072),
+		Number:      big.NewInt(1),
+		GasLimit:    3141592,
+		GasUsed:     21000,
+		Time:        1426516743,
+		Extra:       []byte("gori"),
+		MixDigest:   common.HexToHash("0xbd4472abb6659ebe3ee06ee4d7b72a00a9f4d001caca51342001075469aff498"),
+		Nonce:       types.EncodeNonce(0xa13a5a8c8f2bb1c4),
+	}
+	cancun := types.CopyHeader(legacy)
+	cancun.Difficulty = new(big.Int)
+	cancun.Nonce = types.BlockNonce{}
+	cancun.BaseFee = big.NewInt(params.InitialBaseFee)
+	cancun.WithdrawalsHash = &withdrawalsHash
+	cancun.BlobGasUsed = &blobGasUsed
+	cancun.ExcessBlobGas = &excessBlobGas
+
+	return map[string]*types.Header{"header_legacy": legacy, "header_cancun": cancun}
+}
+
+// testTransactions returns a signed transaction of each supported type.
+func testTransactions(t *testing.T) map[string]*types.Transaction {
+	var (
+		chainID    = big.NewInt(1)
+		signer     = types.NewCancunSigner(chainID)
+		accessList = types.AccessList{{Address: testTo, StorageKeys: []common.Hash{{0x01}, {0x02}}}}
+	)
+	txs := map[string]types.TxData{
+		"tx_legacy": &types.LegacyTx{
+			Nonce:    0,
+			GasPrice: big.NewInt(params.GWei),
+			Gas:      21000,
+			To:       &testTo,
+			Value:    big.NewInt(1),
+		},
+		"tx_create": &types.LegacyTx{
+			Nonce:    1,
+			GasPrice: big.NewInt(params.GWei),
+			Gas:      100000,
+			Data:     common.FromHex("0x6080604052"),
+		},
+		"tx_accesslist": &types.AccessListTx{
+			ChainID:    chainID,
+			Nonce:      2,
+			GasPrice:   big.NewInt(params.GWei),
+			Gas:        50000,
+			To:         &testTo,
+			Value:      new(big.Int).Lsh(big.NewInt(1), 80), // Exceeds 64 bits
+			AccessList: accessList,
+		},
+		"tx_dynamicfee": &types.DynamicFeeTx{
+			ChainID:    chainID,
+			Nonce:      3,
+			GasTipCap:  big.NewInt(params.GWei),
+			GasFeeCap:  big.NewInt(10 * params.GWei),
+			Gas:        50000,
+			To:         &testTo,
+			Value:      big.NewInt(0),
+			Data:       []byte{0xde, 0xad, 0xbe, 0xef},
+			AccessList: types.AccessList{},
+		},
+		"tx_blob": &types.BlobTx{
+			ChainID:    uint256.NewInt(1),
+			Nonce:      4,
+			GasTipCap:  uint256.NewInt(params.GWei),
+			GasFeeCap:  uint256.NewInt(10 * params.GWei),
+			Gas:        21000,
+			To:         testTo,
+			Value:      uint256.NewInt(0),
+			BlobFeeCap: uint256.NewInt(params.GWei),
+			BlobHashes: []common.Hash{common.HexToHash("0x01a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8")},
+		},
+	}
+	signed := make(map[string]*types.Transaction, len(txs))
+	for name, inner := range txs {
+		tx, err := types.SignNewTx(testKey, signer, inner)
+		if err != nil {
+			t.Fatalf("%s: failed to sign transaction: %v", name, err)
+		}
+		signed[name] = tx
+	}
+	return signed
+}
+
+// testReceipts returns a post-Byzantium and a pre-Byzantium receipt.
+func testReceipts() map[string]*types.Receipt {
+	logs := []*types.Log{
+		{
+			Address: testTo,
+			Topics:  []common.Hash{common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")},
+			Data:    common.FromHex("0x0000000000000000000000000000000000000000000000000000000000000001"),
+		},
+		{
+			Address: testTo,
+			Topics:  []common.Hash{},
+			Data:    []byte{},
+		},
+	}
+	status := &types.Receipt{
+		Type:              types.DynamicFeeTxType,
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 52000,
+		Logs:              logs,
+	}
+	status.Bloom = types.CreateBloom(types.Receipts{status})
+
+	root := &types.Receipt{
+		Type:              types.LegacyTxType,
+		PostState:         common.HexToHash("0xef1552a40b7165c3cd773806b9e0c165b75356e0314bf0706f279c729f51e017").Bytes(),
+		CumulativeGasUsed: 21000,
+		Logs:              []*types.Log{},
+	}
+	return map[string]*types.Receipt{"receipt_status": status, "receipt_root": root}
+}
+
+// checkGolden compares an encoding against the golden file of the given name.
+func checkGolden(t *testing.T, name string, have []byte) {
+	t.Helper()
+
+	want, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("%s: failed to read golden file: %v", name, err)
+	}
+	want = bytes.TrimSpace(want)
+	if strings.HasSuffix(name, ".cbor") {
+		want, err = hex.DecodeString(string(want))
+		if err != nil {
+			t.Fatalf("%s: invalid golden hex: %v", name, err)
+		}
+	}
+	if !bytes.Equal(have, want) {
+		t.Errorf("%s: golden mismatch\nhave %x\nwant %x", name, have, want)
+	}
+}
+
+func TestHeaderEncoding(t *testing.T) {
+	for name, header := range testHeaders() {
+		blob, err := MarshalHeaderJSON(header)
+		if err != nil {
+			t.Fatalf("%s: failed to encode json: %v", name, err)
+		}
+		checkGolden(t, name+".json", blob)
+
+		dec, err := UnmarshalHeaderJSON(blob)
+		if err != nil {
+			t.Fatalf("%s: failed to decode json: %v", name, err)
+		}
+		if dec.Hash() != header.Hash() {
+			t.Errorf("%s: json round trip hash mismatch: have %x, want %x", name, dec.Hash(), header.Hash())
+		}
+		blob, err = MarshalHeaderCBOR(header)
+		if err != nil {
+			t.Fatalf("%s: failed to encode cbor: %v", name, err)
+		}
+		checkGolden(t, name+".cbor", blob)
+
+		if dec, err = UnmarshalHeaderCBOR(blob); err != nil {
+			t.Fatalf("%s: failed to decode cbor: %v", name, err)
+		}
+		if dec.Hash() != header.Hash() {
+			t.Errorf("%s: cbor round trip hash mismatch: have %x, want %x", name, dec.Hash(), header.Hash())
+		}
+	}
+}
+
+func TestTransactionEncoding(t *testing.T) {
+	for name, tx := range testTransactions(t) {
+		blob, err := MarshalTransactionJSON(tx)
+		if err != nil {
+			t.Fatalf("%s: failed to encode json: %v", name, err)
+		}
+		checkGolden(t, name+".json", blob)
+
+		dec, err := UnmarshalTransactionJSON(blob)
+		if err != nil {
+			t.Fatalf("%s: failed to decode json: %v", name, err)
+		}
+		if dec.Hash() != tx.Hash() {
+			t.Errorf("%s: json round trip hash mismatch: have %x, want %x", name, dec.Hash(), tx.Hash())
+		}
+		blob, err = MarshalTransactionCBOR(tx)
+		if err != nil {
+			t.Fatalf("%s: failed to encode cbor: %v", name, err)
+		}
+		checkGolden(t, name+".cbor", blob)
+
+		if dec, err = UnmarshalTransactionCBOR(blob); err != nil {
+			t.Fatalf("%s: failed to decode cbor: %v", name, err)
+		}
+		if dec.Hash() != tx.Hash() {
+			t.Errorf("%s: cbor round trip hash mismatch: have %x, want %x", name, dec.Hash(), tx.Hash())
+		}
+	}
+}
+
+func TestReceiptEncoding(t *testing.T) {
+	for name, receipt := range testReceipts() {
+		want, _ := receipt.MarshalBinary()
+
+		blob, err := MarshalReceiptJSON(receipt)
+		if err != nil {
+			t.Fatalf("%s: failed to encode json: %v", name, err)
+		}
+		checkGolden(t, name+".json", blob)
+
+		dec, err := UnmarshalReceiptJSON(blob)
+		if err != nil {
+			t.Fatalf("%s: failed to decode json: %v", name, err)
+		}
+		if have, _ := dec.MarshalBinary(); !bytes.Equal(have, want) {
+			t.Errorf("%s: json round trip consensus mismatch: have %x, want %x", name, have, want)
+		}
+		blob, err = MarshalReceiptCBOR(receipt)
+		if err != nil {
+			t.Fatalf("%s: failed to encode cbor: %v", name, err)
+		}
+		checkGolden(t, name+".cbor", blob)
+
+		if dec, err = UnmarshalReceiptCBOR(blob); err != nil {
+			t.Fatalf("%s: failed to decode cbor: %v", name, err)
+		}
+		if have, _ := dec.MarshalBinary(); !bytes.Equal(have, want) {
+			t.Errorf("%s: cbor round trip consensus mismatch: have %x, want %x", name, have, want)
+		}
+	}
+}
+
+// Tests that documents which are valid but not in canonical form are rejected.
+func TestNonCanonicalRejection(t *testing.T) {
+	header := testHeaders()["header_legacy"]
+	blob, _ := MarshalHeaderJSON(header)
+
+	tests := map[string]string{
+		"whitespace":  strings.Replace(string(blob), `,"gasUsed"`, `, "gasUsed"`, 1),
+		"uppercase":   strings.Replace(string(blob), `"miner":"0x8888f1f195afa192cfee860698584c030f4c9db1"`, `"miner":"0x8888F1F195AFA192CFEE860698584C030F4C9DB1"`, 1),
+		"leadingzero": strings.Replace(string(blob), `"gasUsed":"21000"`, `"gasUsed":"021000"`, 1),
+	}
+	for name, doc := range tests {
+		if doc == string(blob) {
+			t.Fatalf("%s: test document not modified", name)
+		}
+		if _, err := UnmarshalHeaderJSON([]byte(doc)); !errors.Is(err, ErrNotCanonical) {
+			t.Errorf("%s: error mismatch: have %v, want %v", name, err, ErrNotCanonical)
+		}
+	}
+	// Non-shortest integer arguments in CBOR
+	blob, _ = MarshalReceiptCBOR(testReceipts()["receipt_root"])
+	idx := bytes.Index(blob, []byte("type"))
+	if idx < 0 || blob[idx+4] != 0x00 {
+		t.Fatalf("receipt type not found in cbor encoding")
+	}
+	long := append(append(common.CopyBytes(blob[:idx+4]), 0x18, 0x00), blob[idx+5:]...)
+	if _, err := UnmarshalReceiptCBOR(long); !errors.Is(err, ErrNotCanonical) {
+		t.Errorf("non-shortest cbor error mismatch: have %v, want %v", err, ErrNotCanonical)
+	}
+}
+
+// Tests that malformed documents are rejected.
+func TestMalformedRejection(t *testing.T) {
+	tx := testTransactions(t)["tx_legacy"]
+	blob, _ := MarshalTransactionJSON(tx)
+
+	tests := map[string]string{
+		"unknown field":  strings.Replace(string(blob), `{`, `{"chainId":"1",`, 1),
+		"missing field":  strings.Replace(string(blob), `"gas":"21000",`, ``, 1),
+		"invalid number": strings.Replace(string(blob), `"gas":"21000"`, `"gas":"0x5208"`, 1),
+		"invalid type":   strings.Replace(string(blob), `"gas":"21000"`, `"gas":21000`, 1),
+		"short address":  strings.Replace(string(blob), `"to":"0x095e7baea6a6c7c4c2dfeb977efac326af552d87"`, `"to":"0x095e7b"`, 1),
+		"unknown type":   strings.Replace(string(blob), `"type":"0"`, `"type":"9"`, 1),
+	}
+	for name, doc := range tests {
+		if doc == string(blob) {
+			t.Fatalf("%s: test document not modified", name)
+		}
+		if _, err := UnmarshalTransactionJSON([]byte(doc)); err == nil || errors.Is(err, ErrNotCanonical) {
+			t.Errorf("%s: expected decoding error, got %v", name, err)
+		}
+	}
+	blob, _ = MarshalTransactionCBOR(tx)
+	if _, err := UnmarshalTransactionCBOR(blob[:len(blob)-1]); err == nil {
+		t.Errorf("truncated cbor document decoded")
+	}
+	if _, err := UnmarshalTransactionCBOR(append(blob, 0x00)); err == nil {
+		t.Errorf("cbor document with trailing data decoded")
+	}
+}
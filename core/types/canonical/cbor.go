@@ -0,0 +1,292 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package canonical
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/gorievm/go-gori/common"
+)
+
+// CBOR major types used by the canonical encoding.
+const (
+	cborUint   = 0
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborBignum = 2 // Tag number of unsigned bignums
+)
+
+// maxCBORDepth is the maximum nesting of arrays and maps accepted by the decoder.
+const maxCBORDepth = 16
+
+var (
+	errCBORTruncated  = errors.New("truncated cbor data")
+	errCBORTrailing   = errors.New("trailing cbor data")
+	errCBORIndefinite = errors.New("indefinite length cbor items not supported")
+	errCBORTooDeep    = errors.New("cbor nesting too deep")
+)
+
+// cborFormat is the canonical CBOR representation.
+type cborFormat struct{}
+
+// marshal implements format, encoding the document with the core deterministic
+// encoding rules of RFC 8949.
+func (cborFormat) marshal(v map[string]interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := encodeCBOR(buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshal implements format, decoding a single top level CBOR map.
+func (cborFormat) unmarshal(data []byte) (map[string]interface{}, error) {
+	dec := &cborDecoder{data: data}
+	v, err := dec.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if dec.pos != len(data) {
+		return nil, errCBORTrailing
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cbor document is %T, want map", v)
+	}
+	return obj, nil
+}
+
+// writeCBORHead writes the initial byte and shortest-form argument of an item.
+func writeCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	switch {
+	case n < 24:
+		buf.WriteByte(major<<5 | byte(n))
+	case n <= 0xff:
+		buf.WriteByte(major<<5 | 24)
+		buf.WriteByte(byte(n))
+	case n <= 0xffff:
+		buf.WriteByte(major<<5 | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n <= 0xffffffff:
+		buf.WriteByte(major<<5 | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		buf.WriteByte(major<<5 | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, n))
+	}
+}
+
+// encodeCBOR encodes a value of the intermediate document model.
+func encodeCBOR(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case uint64:
+		writeCBORHead(buf, cborUint, v)
+	case *big.Int:
+		if v.Sign() < 0 {
+			return fmt.Errorf("negative integer %v", v)
+		}
+		if v.IsUint64() {
+			writeCBORHead(buf, cborUint, v.Uint64())
+			return nil
+		}
+		blob := v.Bytes()
+		writeCBORHead(buf, cborTag, cborBignum)
+		writeCBORHead(buf, cborBytes, uint64(len(blob)))
+		buf.Write(blob)
+	case []byte:
+		writeCBORHead(buf, cborBytes, uint64(len(v)))
+		buf.Write(v)
+	case string:
+		writeCBORHead(buf, cborText, uint64(len(v)))
+		buf.WriteString(v)
+	case []interface{}:
+		writeCBORHead(buf, cborArray, uint64(len(v)))
+		for _, item := range v {
+			if err := encodeCBOR(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		// Deterministic encoding requires keys sorted by their encoded form
+		type entry struct {
+			key  []byte
+			name string
+		}
+		entries := make([]entry, 0, len(v))
+		for name := range v {
+			key := new(bytes.Buffer)
+			encodeCBOR(key, name)
+			entries = append(entries, entry{key.Bytes(), name})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i].key, entries[j].key) < 0
+		})
+		writeCBORHead(buf, cborMap, uint64(len(v)))
+		for _, e := range entries {
+			buf.Write(e.key)
+			if err := encodeCBOR(buf, v[e.name]); err != nil {
+				return fmt.Errorf("%s: %v", e.name, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported value type %T", v)
+	}
+	return nil
+}
+
+// cborDecoder is a minimal decoder for the subset of CBOR used by the canonical
+// encoding. Canonical form itself is verified by re-encoding the decoded value.
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// head reads the initial byte and argument of the next item.
+func (d *cborDecoder) head() (byte, uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, errCBORTruncated
+	}
+	initial := d.data[d.pos]
+	d.pos++
+
+	major, info := initial>>5, initial&0x1f
+	if info < 24 {
+		return major, uint64(info), nil
+	}
+	if info > 27 {
+		return 0, 0, errCBORIndefinite
+	}
+	size := 1 << (info - 24)
+	if len(d.data)-d.pos < size {
+		return 0, 0, errCBORTruncated
+	}
+	var n uint64
+	for _, b := range d.data[d.pos : d.pos+size] {
+		n = n<<8 | uint64(b)
+	}
+	d.pos += size
+	return major, n, nil
+}
+
+// payload reads the next n bytes of the input.
+func (d *cborDecoder) payload(n uint64) ([]byte, error) {
+	if uint64(len(d.data)-d.pos) < n {
+		return nil, errCBORTruncated
+	}
+	blob := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return blob, nil
+}
+
+// decode reads the next item into the intermediate document model.
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxCBORDepth {
+		return nil, errCBORTooDeep
+	}
+	major, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUint:
+		return n, nil
+
+	case cborBytes:
+		blob, err := d.payload(n)
+		if err != nil {
+			return nil, err
+		}
+		return common.CopyBytes(blob), nil
+
+	case cborText:
+		blob, err := d.payload(n)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(blob) {
+			return nil, errors.New("invalid utf8 in cbor text")
+		}
+		return string(blob), nil
+
+	case cborArray:
+		// Every item is at least one byte, bail out early on junk lengths
+		if n > uint64(len(d.data)-d.pos) {
+			return nil, errCBORTruncated
+		}
+		list := make([]interface{}, 0, n)
+		for i := uint64(0); i < n; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+
+	case cborMap:
+		if n > uint64(len(d.data)-d.pos)/2 {
+			return nil, errCBORTruncated
+		}
+		obj := make(map[string]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("cbor map key is %T, want string", key)
+			}
+			if _, ok := obj[name]; ok {
+				return nil, fmt.Errorf("duplicate cbor map key %q", name)
+			}
+			if obj[name], err = d.decode(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return obj, nil
+
+	case cborTag:
+		if n != cborBignum {
+			return nil, fmt.Errorf("unsupported cbor tag %d", n)
+		}
+		major, size, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if major != cborBytes {
+			return nil, errors.New("cbor bignum content is not a byte string")
+		}
+		blob, err := d.payload(size)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetBytes(blob), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported cbor major type %d", major)
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package canonical
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/holiman/uint256"
+)
+
+// fields is a typed reader over a decoded document. Leaves may either be JSON
+// strings or native CBOR values; the accessors accept both. The first error
+// encountered is retained and all subsequent reads become noops.
+type fields struct {
+	obj  map[string]interface{}
+	used map[string]struct{}
+	err  error
+}
+
+// newFields creates a typed reader over a decoded document.
+func newFields(obj map[string]interface{}) *fields {
+	return &fields{obj: obj, used: make(map[string]struct{}, len(obj))}
+}
+
+// fail records the first error encountered while reading a field.
+func (f *fields) fail(key string, format string, args ...interface{}) {
+	if f.err == nil {
+		f.err = fmt.Errorf("field %q: %s", key, fmt.Sprintf(format, args...))
+	}
+}
+
+// has returns whether the optional field is present in the document.
+func (f *fields) has(key string) bool {
+	_, ok := f.obj[key]
+	return ok
+}
+
+// get retrieves a mandatory field from the document.
+func (f *fields) get(key string) (interface{}, bool) {
+	if f.err != nil {
+		return nil, false
+	}
+	v, ok := f.obj[key]
+	if !ok {
+		f.fail(key, "missing")
+		return nil, false
+	}
+	f.used[key] = struct{}{}
+	return v, true
+}
+
+// uint64 reads an integer field that must fit into 64 bits.
+func (f *fields) uint64(key string) uint64 {
+	v, ok := f.get(key)
+	if !ok {
+		return 0
+	}
+	switch v := v.(type) {
+	case uint64:
+		return v
+	case string:
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			f.fail(key, "invalid integer %q", v)
+		}
+		return n
+	default:
+		f.fail(key, "unexpected type %T", v)
+		return 0
+	}
+}
+
+// big reads an arbitrary precision unsigned integer field.
+func (f *fields) big(key string) *big.Int {
+	v, ok := f.get(key)
+	if !ok {
+		return nil
+	}
+	switch v := v.(type) {
+	case uint64:
+		return new(big.Int).SetUint64(v)
+	case *big.Int:
+		return v
+	case string:
+		n, ok := new(big.Int).SetString(v, 10)
+		if !ok || n.Sign() < 0 {
+			f.fail(key, "invalid integer %q", v)
+			return nil
+		}
+		return n
+	default:
+		f.fail(key, "unexpected type %T", v)
+		return nil
+	}
+}
+
+// uint256 reads an unsigned integer field that must fit into 256 bits.
+func (f *fields) uint256(key string) *uint256.Int {
+	n := f.big(key)
+	if n == nil {
+		return nil
+	}
+	v, overflow := uint256.FromBig(n)
+	if overflow {
+		f.fail(key, "integer overflows 256 bits")
+		return nil
+	}
+	return v
+}
+
+// bytes reads a binary field.
+func (f *fields) bytes(key string) []byte {
+	v, ok := f.get(key)
+	if !ok {
+		return nil
+	}
+	return f.toBytes(key, v)
+}
+
+// toBytes converts a decoded leaf value into binary data.
+func (f *fields) toBytes(key string, v interface{}) []byte {
+	switch v := v.(type) {
+	case []byte:
+		return v
+	case string:
+		blob, err := hexutil.Decode(v)
+		if err != nil {
+			f.fail(key, "invalid hex %q: %v", v, err)
+		}
+		return blob
+	default:
+		f.fail(key, "unexpected type %T", v)
+		return nil
+	}
+}
+
+// fixed reads a binary field of an exact length.
+func (f *fields) fixed(key string, size int) []byte {
+	blob := f.bytes(key)
+	if f.err == nil && len(blob) != size {
+		f.fail(key, "invalid length %d, want %d", len(blob), size)
+	}
+	return blob
+}
+
+// hash reads a 32 byte hash field.
+func (f *fields) hash(key string) common.Hash {
+	return common.BytesToHash(f.fixed(key, common.HashLength))
+}
+
+// address reads a 20 byte address field.
+func (f *fields) address(key string) common.Address {
+	return common.BytesToAddress(f.fixed(key, common.AddressLength))
+}
+
+// hashes reads a list of 32 byte hashes.
+func (f *fields) hashes(key string) []common.Hash {
+	items := f.list(key)
+	hashes := make([]common.Hash, 0, len(items))
+	for _, item := range items {
+		blob := f.toBytes(key, item)
+		if f.err == nil && len(blob) != common.HashLength {
+			f.fail(key, "invalid hash length %d", len(blob))
+		}
+		hashes = append(hashes, common.BytesToHash(blob))
+	}
+	return hashes
+}
+
+// list reads an array field.
+func (f *fields) list(key string) []interface{} {
+	v, ok := f.get(key)
+	if !ok {
+		return nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		f.fail(key, "unexpected type %T", v)
+	}
+	return list
+}
+
+// objects reads an array of objects, invoking the callback with a typed reader
+// for each of them.
+func (f *fields) objects(key string, fn func(*fields)) {
+	for i, item := range f.list(key) {
+		if f.err != nil {
+			return
+		}
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			f.fail(key, "item %d: unexpected type %T", i, item)
+			return
+		}
+		sub := newFields(obj)
+		fn(sub)
+		if err := sub.finish(); err != nil {
+			f.fail(key, "item %d: %v", i, err)
+		}
+	}
+}
+
+// finish returns the first error encountered, or an error if the document has
+// fields that were never read.
+func (f *fields) finish() error {
+	if f.err != nil {
+		return f.err
+	}
+	var unknown []string
+	for key := range f.obj {
+		if _, ok := f.used[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown fields %v", unknown)
+	}
+	return nil
+}
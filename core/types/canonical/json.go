@@ -0,0 +1,88 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package canonical
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/gorievm/go-gori/common/hexutil"
+)
+
+// jsonFormat is the canonical JSON representation.
+type jsonFormat struct{}
+
+// marshal implements format, converting all integers to decimal strings and all
+// binary data to hex strings. The standard library sorts map keys and emits no
+// insignificant whitespace, which is exactly the canonical form.
+func (jsonFormat) marshal(v map[string]interface{}) ([]byte, error) {
+	conv, err := toJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(conv)
+}
+
+// unmarshal implements format, returning an untyped document whose leaves are
+// all strings. The typed accessors of the field reader take care of the parsing.
+func (jsonFormat) unmarshal(data []byte) (map[string]interface{}, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// toJSON converts a value of the intermediate document model into its canonical
+// JSON representation.
+func toJSON(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case *big.Int:
+		if v.Sign() < 0 {
+			return nil, fmt.Errorf("negative integer %v", v)
+		}
+		return v.String(), nil
+	case []byte:
+		return hexutil.Encode(v), nil
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			conv, err := toJSON(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = conv
+		}
+		return list, nil
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			conv, err := toJSON(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", key, err)
+			}
+			obj[key] = conv
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+}
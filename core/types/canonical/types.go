@@ -0,0 +1,368 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package canonical
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+)
+
+// bigOrZero returns the given integer, or zero if it's nil.
+func bigOrZero(n *big.Int) *big.Int {
+	if n == nil {
+		return new(big.Int)
+	}
+	return n
+}
+
+// encodeHeader converts a block header into the intermediate document model.
+//
+//	parentHash, sha3Uncles, stateRoot,
+//	transactionsRoot, receiptsRoot, mixHash: 32 bytes
+//	miner:                                   20 bytes
+//	logsBloom:                               256 bytes
+//	nonce:                                   8 bytes
+//	extraData:                               bytes
+//	difficulty, number, gasLimit,
+//	gasUsed, timestamp:                      integer
+//	baseFeePerGas:                           integer, optional (London)
+//	withdrawalsRoot:                         32 bytes, optional (Shanghai)
+//	blobGasUsed, excessBlobGas:              integer, optional (Cancun)
+//	parentBeaconBlockRoot:                   32 bytes, optional (Cancun)
+//	requestsHash:                            32 bytes, optional (Prague)
+func encodeHeader(h *types.Header) map[string]interface{} {
+	obj := map[string]interface{}{
+		"parentHash":       h.ParentHash.Bytes(),
+		"sha3Uncles":       h.UncleHash.Bytes(),
+		"miner":            h.Coinbase.Bytes(),
+		"stateRoot":        h.Root.Bytes(),
+		"transactionsRoot": h.TxHash.Bytes(),
+		"receiptsRoot":     h.ReceiptHash.Bytes(),
+		"logsBloom":        h.Bloom.Bytes(),
+		"difficulty":       bigOrZero(h.Difficulty),
+		"number":           bigOrZero(h.Number),
+		"gasLimit":         h.GasLimit,
+		"gasUsed":          h.GasUsed,
+		"timestamp":        h.Time,
+		"extraData":        h.Extra,
+		"mixHash":          h.MixDigest.Bytes(),
+		"nonce":            h.Nonce[:],
+	}
+	if h.BaseFee != nil {
+		obj["baseFeePerGas"] = h.BaseFee
+	}
+	if h.WithdrawalsHash != nil {
+		obj["withdrawalsRoot"] = h.WithdrawalsHash.Bytes()
+	}
+	if h.BlobGasUsed != nil {
+		obj["blobGasUsed"] = *h.BlobGasUsed
+	}
+	if h.ExcessBlobGas != nil {
+		obj["excessBlobGas"] = *h.ExcessBlobGas
+	}
+	if h.ParentBeaconRoot != nil {
+		obj["parentBeaconBlockRoot"] = h.ParentBeaconRoot.Bytes()
+	}
+	if h.RequestsHash != nil {
+		obj["requestsHash"] = h.RequestsHash.Bytes()
+	}
+	return obj
+}
+
+// decodeHeader converts a decoded document into a block header.
+func decodeHeader(obj map[string]interface{}) (*types.Header, error) {
+	f := newFields(obj)
+	h := &types.Header{
+		ParentHash:  f.hash("parentHash"),
+		UncleHash:   f.hash("sha3Uncles"),
+		Coinbase:    f.address("miner"),
+		Root:        f.hash("stateRoot"),
+		TxHash:      f.hash("transactionsRoot"),
+		ReceiptHash: f.hash("receiptsRoot"),
+		Bloom:       types.BytesToBloom(f.fixed("logsBloom", types.BloomByteLength)),
+		Difficulty:  f.big("difficulty"),
+		Number:      f.big("number"),
+		GasLimit:    f.uint64("gasLimit"),
+		GasUsed:     f.uint64("gasUsed"),
+		Time:        f.uint64("timestamp"),
+		Extra:       f.bytes("extraData"),
+		MixDigest:   f.hash("mixHash"),
+		Nonce:       types.EncodeNonce(new(big.Int).SetBytes(f.fixed("nonce", 8)).Uint64()),
+	}
+	if f.has("baseFeePerGas") {
+		h.BaseFee = f.big("baseFeePerGas")
+	}
+	if f.has("withdrawalsRoot") {
+		hash := f.hash("withdrawalsRoot")
+		h.WithdrawalsHash = &hash
+	}
+	if f.has("blobGasUsed") {
+		used := f.uint64("blobGasUsed")
+		h.BlobGasUsed = &used
+	}
+	if f.has("excessBlobGas") {
+		excess := f.uint64("excessBlobGas")
+		h.ExcessBlobGas = &excess
+	}
+	if f.has("parentBeaconBlockRoot") {
+		root := f.hash("parentBeaconBlockRoot")
+		h.ParentBeaconRoot = &root
+	}
+	if f.has("requestsHash") {
+		hash := f.hash("requestsHash")
+		h.RequestsHash = &hash
+	}
+	if err := f.finish(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// encodeAccessList converts an access list into the intermediate document model.
+func encodeAccessList(list types.AccessList) []interface{} {
+	items := make([]interface{}, 0, len(list))
+	for _, tuple := range list {
+		keys := make([]interface{}, 0, len(tuple.StorageKeys))
+		for _, key := range tuple.StorageKeys {
+			keys = append(keys, key.Bytes())
+		}
+		items = append(items, map[string]interface{}{
+			"address":     tuple.Address.Bytes(),
+			"storageKeys": keys,
+		})
+	}
+	return items
+}
+
+// decodeAccessList reads an access list field.
+func decodeAccessList(f *fields, key string) types.AccessList {
+	list := make(types.AccessList, 0)
+	f.objects(key, func(tuple *fields) {
+		list = append(list, types.AccessTuple{
+			Address:     tuple.address("address"),
+			StorageKeys: tuple.hashes("storageKeys"),
+		})
+	})
+	return list
+}
+
+// encodeTransaction converts a transaction into the intermediate document model.
+//
+//	type, nonce, gas, value, v, r, s: integer
+//	to:                               20 bytes, omitted for contract creations
+//	input:                            bytes
+//	gasPrice:                         integer (legacy and access list txs)
+//	chainId, accessList:              integer, list (typed txs)
+//	maxPriorityFeePerGas,
+//	maxFeePerGas:                     integer (dynamic fee and blob txs)
+//	maxFeePerBlobGas,
+//	blobVersionedHashes:              integer, list of 32 bytes (blob txs)
+func encodeTransaction(tx *types.Transaction) map[string]interface{} {
+	v, r, s := tx.RawSignatureValues()
+	obj := map[string]interface{}{
+		"type":  uint64(tx.Type()),
+		"nonce": tx.Nonce(),
+		"gas":   tx.Gas(),
+		"value": tx.Value(),
+		"input": tx.Data(),
+		"v":     bigOrZero(v),
+		"r":     bigOrZero(r),
+		"s":     bigOrZero(s),
+	}
+	if to := tx.To(); to != nil {
+		obj["to"] = to.Bytes()
+	}
+	switch tx.Type() {
+	case types.LegacyTxType:
+		obj["gasPrice"] = tx.GasPrice()
+
+	case types.AccessListTxType:
+		obj["chainId"] = tx.ChainId()
+		obj["gasPrice"] = tx.GasPrice()
+		obj["accessList"] = encodeAccessList(tx.AccessList())
+
+	case types.DynamicFeeTxType, types.BlobTxType:
+		obj["chainId"] = tx.ChainId()
+		obj["maxPriorityFeePerGas"] = tx.GasTipCap()
+		obj["maxFeePerGas"] = tx.GasFeeCap()
+		obj["accessList"] = encodeAccessList(tx.AccessList())
+
+		if tx.Type() == types.BlobTxType {
+			hashes := make([]interface{}, 0, len(tx.BlobHashes()))
+			for _, hash := range tx.BlobHashes() {
+				hashes = append(hashes, hash.Bytes())
+			}
+			obj["maxFeePerBlobGas"] = tx.BlobGasFeeCap()
+			obj["blobVersionedHashes"] = hashes
+		}
+	}
+	return obj
+}
+
+// decodeTransaction converts a decoded document into a transaction.
+func decodeTransaction(obj map[string]interface{}) (*types.Transaction, error) {
+	f := newFields(obj)
+
+	var to *common.Address
+	if f.has("to") {
+		addr := f.address("to")
+		to = &addr
+	}
+	var inner types.TxData
+	switch kind := f.uint64("type"); kind {
+	case types.LegacyTxType:
+		inner = &types.LegacyTx{
+			Nonce:    f.uint64("nonce"),
+			GasPrice: f.big("gasPrice"),
+			Gas:      f.uint64("gas"),
+			To:       to,
+			Value:    f.big("value"),
+			Data:     f.bytes("input"),
+			V:        f.big("v"),
+			R:        f.big("r"),
+			S:        f.big("s"),
+		}
+	case types.AccessListTxType:
+		inner = &types.AccessListTx{
+			ChainID:    f.big("chainId"),
+			Nonce:      f.uint64("nonce"),
+			GasPrice:   f.big("gasPrice"),
+			Gas:        f.uint64("gas"),
+			To:         to,
+			Value:      f.big("value"),
+			Data:       f.bytes("input"),
+			AccessList: decodeAccessList(f, "accessList"),
+			V:          f.big("v"),
+			R:          f.big("r"),
+			S:          f.big("s"),
+		}
+	case types.DynamicFeeTxType:
+		inner = &types.DynamicFeeTx{
+			ChainID:    f.big("chainId"),
+			Nonce:      f.uint64("nonce"),
+			GasTipCap:  f.big("maxPriorityFeePerGas"),
+			GasFeeCap:  f.big("maxFeePerGas"),
+			Gas:        f.uint64("gas"),
+			To:         to,
+			Value:      f.big("value"),
+			Data:       f.bytes("input"),
+			AccessList: decodeAccessList(f, "accessList"),
+			V:          f.big("v"),
+			R:          f.big("r"),
+			S:          f.big("s"),
+		}
+	case types.BlobTxType:
+		if to == nil {
+			return nil, fmt.Errorf("field %q: missing", "to")
+		}
+		inner = &types.BlobTx{
+			ChainID:    f.uint256("chainId"),
+			Nonce:      f.uint64("nonce"),
+			GasTipCap:  f.uint256("maxPriorityFeePerGas"),
+			GasFeeCap:  f.uint256("maxFeePerGas"),
+			Gas:        f.uint64("gas"),
+			To:         *to,
+			Value:      f.uint256("value"),
+			Data:       f.bytes("input"),
+			AccessList: decodeAccessList(f, "accessList"),
+			BlobFeeCap: f.uint256("maxFeePerBlobGas"),
+			BlobHashes: f.hashes("blobVersionedHashes"),
+			V:          f.uint256("v"),
+			R:          f.uint256("r"),
+			S:          f.uint256("s"),
+		}
+	default:
+		if f.err == nil {
+			return nil, fmt.Errorf("%w: %d", types.ErrTxTypeNotSupported, kind)
+		}
+	}
+	if err := f.finish(); err != nil {
+		return nil, err
+	}
+	return types.NewTx(inner), nil
+}
+
+// encodeReceipt converts the consensus fields of a receipt into the intermediate
+// document model.
+//
+//	type, cumulativeGasUsed: integer
+//	status:                  integer, omitted for pre-Byzantium receipts
+//	root:                    bytes, only for pre-Byzantium receipts
+//	logsBloom:               256 bytes
+//	logs:                    list of {address: 20 bytes, topics: list of 32 bytes, data: bytes}
+func encodeReceipt(r *types.Receipt) map[string]interface{} {
+	logs := make([]interface{}, 0, len(r.Logs))
+	for _, log := range r.Logs {
+		topics := make([]interface{}, 0, len(log.Topics))
+		for _, topic := range log.Topics {
+			topics = append(topics, topic.Bytes())
+		}
+		logs = append(logs, map[string]interface{}{
+			"address": log.Address.Bytes(),
+			"topics":  topics,
+			"data":    log.Data,
+		})
+	}
+	obj := map[string]interface{}{
+		"type":              uint64(r.Type),
+		"cumulativeGasUsed": r.CumulativeGasUsed,
+		"logsBloom":         r.Bloom.Bytes(),
+		"logs":              logs,
+	}
+	if len(r.PostState) > 0 {
+		obj["root"] = r.PostState
+	} else {
+		obj["status"] = r.Status
+	}
+	return obj
+}
+
+// decodeReceipt converts a decoded document into a receipt with only the
+// consensus fields populated.
+func decodeReceipt(obj map[string]interface{}) (*types.Receipt, error) {
+	f := newFields(obj)
+	r := &types.Receipt{
+		CumulativeGasUsed: f.uint64("cumulativeGasUsed"),
+		Bloom:             types.BytesToBloom(f.fixed("logsBloom", types.BloomByteLength)),
+		Logs:              make([]*types.Log, 0),
+	}
+	kind := f.uint64("type")
+	if kind > 0xff {
+		return nil, fmt.Errorf("%w: %d", types.ErrTxTypeNotSupported, kind)
+	}
+	r.Type = uint8(kind)
+
+	if f.has("root") {
+		r.PostState = f.bytes("root")
+	} else {
+		r.Status = f.uint64("status")
+	}
+	f.objects("logs", func(log *fields) {
+		r.Logs = append(r.Logs, &types.Log{
+			Address: log.address("address"),
+			Topics:  log.hashes("topics"),
+			Data:    log.bytes("data"),
+		})
+	})
+	if err := f.finish(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
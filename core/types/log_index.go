@@ -0,0 +1,165 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/gorievm/go-gori/common"
+)
+
+// IndexedLogs is a list of logs indexed by emitting contract and event signature
+// (the first topic of ABI events), allowing repeated filtering of the same logs
+// without scanning all of them. The index is built on first use.
+//
+// The logs must not be modified after the index is created.
+type IndexedLogs struct {
+	logs []*Log
+
+	once      sync.Once
+	addresses map[common.Address][]int // Positions of the logs emitted by each contract
+	events    map[common.Hash][]int    // Positions of the logs of each event signature
+}
+
+// NewIndexedLogs creates an index over the given logs.
+func NewIndexedLogs(logs []*Log) *IndexedLogs {
+	return &IndexedLogs{logs: logs}
+}
+
+// build creates the lookup tables of the index.
+func (ix *IndexedLogs) build() {
+	ix.addresses = make(map[common.Address][]int)
+	ix.events = make(map[common.Hash][]int)
+	for i, log := range ix.logs {
+		ix.addresses[log.Address] = append(ix.addresses[log.Address], i)
+		if len(log.Topics) > 0 {
+			ix.events[log.Topics[0]] = append(ix.events[log.Topics[0]], i)
+		}
+	}
+}
+
+// Logs returns all the indexed logs.
+func (ix *IndexedLogs) Logs() []*Log {
+	return ix.logs
+}
+
+// Events returns the logs with the given event signature.
+func (ix *IndexedLogs) Events(signature common.Hash) []*Log {
+	ix.once.Do(ix.build)
+	return ix.collect(ix.events[signature])
+}
+
+// Filter returns the logs emitted by any of the given contracts and matching
+// the topic criteria, in their original order. An empty address list or topic
+// position matches anything, the same way as the log filters.
+func (ix *IndexedLogs) Filter(addresses []common.Address, topics [][]common.Hash) []*Log {
+	ix.once.Do(ix.build)
+
+	// Narrow down the candidates by the more selective of the indexed criteria
+	var (
+		candidates []int
+		narrowed   bool
+	)
+	if len(addresses) > 0 {
+		candidates, narrowed = lookup(ix.addresses, addresses), true
+	}
+	if len(topics) > 0 && len(topics[0]) > 0 {
+		if events := lookup(ix.events, topics[0]); !narrowed || len(events) < len(candidates) {
+			candidates, narrowed = events, true
+		}
+	}
+	if !narrowed {
+		candidates = make([]int, len(ix.logs))
+		for i := range candidates {
+			candidates[i] = i
+		}
+	}
+	var matches []*Log
+	for _, i := range candidates {
+		if matchLog(ix.logs[i], addresses, topics) {
+			matches = append(matches, ix.logs[i])
+		}
+	}
+	return matches
+}
+
+// lookup gathers the positions of the logs belonging to any of the given keys
+// of a lookup table, in ascending order without duplicates.
+func lookup[K comparable](table map[K][]int, keys []K) []int {
+	if len(keys) == 1 {
+		return table[keys[0]]
+	}
+	var positions []int
+	for _, key := range keys {
+		positions = append(positions, table[key]...)
+	}
+	sort.Ints(positions)
+
+	unique := positions[:0]
+	for _, pos := range positions {
+		if len(unique) == 0 || pos != unique[len(unique)-1] {
+			unique = append(unique, pos)
+		}
+	}
+	return unique
+}
+
+// collect returns the logs at the given positions.
+func (ix *IndexedLogs) collect(positions []int) []*Log {
+	if len(positions) == 0 {
+		return nil
+	}
+	logs := make([]*Log, len(positions))
+	for i, pos := range positions {
+		logs[i] = ix.logs[pos]
+	}
+	return logs
+}
+
+// matchLog reports whether the log is emitted by any of the given contracts and
+// matches the topic criteria.
+func matchLog(log *Log, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 {
+		var found bool
+		for _, addr := range addresses {
+			if log.Address == addr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(topics) > len(log.Topics) {
+		return false
+	}
+	for i, sub := range topics {
+		match := len(sub) == 0 // empty rule set == wildcard
+		for _, topic := range sub {
+			if log.Topics[i] == topic {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
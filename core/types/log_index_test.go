@@ -0,0 +1,90 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+)
+
+func TestIndexedLogsFilter(t *testing.T) {
+	var (
+		addresses = []common.Address{{0x01}, {0x02}, {0x03}}
+		topics    = []common.Hash{{0x0a}, {0x0b}, {0x0c}}
+		logs      []*Log
+	)
+	for i := 0; i < 200; i++ {
+		log := &Log{Address: addresses[rand.Intn(len(addresses))], Index: uint(i)}
+		for j := rand.Intn(3); j > 0; j-- {
+			log.Topics = append(log.Topics, topics[rand.Intn(len(topics))])
+		}
+		logs = append(logs, log)
+	}
+	ix := NewIndexedLogs(logs)
+
+	// Cross check the index against filtering all the logs
+	criteria := []struct {
+		addresses []common.Address
+		topics    [][]common.Hash
+	}{
+		{nil, nil},
+		{addresses[:1], nil},
+		{addresses[:2], nil},
+		{[]common.Address{addresses[0], addresses[0]}, nil},
+		{[]common.Address{{0xff}}, nil},
+		{nil, [][]common.Hash{{topics[0]}}},
+		{nil, [][]common.Hash{topics[:2]}},
+		{nil, [][]common.Hash{nil, {topics[1]}}},
+		{addresses[1:], [][]common.Hash{{topics[2]}, {topics[0], topics[1]}}},
+		{addresses[:1], [][]common.Hash{{{0xff}}}},
+	}
+	for i, c := range criteria {
+		var want []*Log
+		for _, log := range logs {
+			if matchLog(log, c.addresses, c.topics) {
+				want = append(want, log)
+			}
+		}
+		if have := ix.Filter(c.addresses, c.topics); !reflect.DeepEqual(have, want) {
+			t.Errorf("criteria %d: filtered logs mismatch: have %d logs, want %d", i, len(have), len(want))
+		}
+	}
+	// Check the event lookup
+	var want []*Log
+	for _, log := range logs {
+		if len(log.Topics) > 0 && log.Topics[0] == topics[1] {
+			want = append(want, log)
+		}
+	}
+	if have := ix.Events(topics[1]); !reflect.DeepEqual(have, want) {
+		t.Errorf("event logs mismatch: have %d logs, want %d", len(have), len(want))
+	}
+}
+
+func TestReceiptIndexedLogs(t *testing.T) {
+	receipt := &Receipt{Logs: []*Log{{Address: common.Address{0x01}, Topics: []common.Hash{{0x0a}}}}}
+	ix := receipt.IndexedLogs()
+	if receipt.IndexedLogs() != ix {
+		t.Fatalf("indexed logs not cached")
+	}
+	if logs := ix.Events(common.Hash{0x0a}); len(logs) != 1 || logs[0] != receipt.Logs[0] {
+		t.Fatalf("event logs mismatch: have %v", logs)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"math/big"
+	"sync/atomic"
 	"unsafe"
 
 	"github.com/gorievm/go-gori/common"
@@ -66,11 +67,37 @@ type Receipt struct {
 	BlobGasUsed       uint64         `json:"blobGasUsed,omitempty"`
 	BlobGasPrice      *big.Int       `json:"blobGasPrice,omitempty"`
 
+	// Accounting information: These fields are only recorded by gori during the
+	// execution of a transaction if configured to, as they can't be derived later.
+	Accounting *ReceiptAccounting `json:"-"`
+
 	// Inclusion information: These fields provide information about the inclusion of the
 	// transaction corresponding to this receipt.
 	BlockHash        common.Hash `json:"blockHash,omitempty"`
 	BlockNumber      *big.Int    `json:"blockNumber,omitempty"`
 	TransactionIndex uint        `json:"transactionIndex"`
+
+	// caches
+	indexedLogs atomic.Value
+}
+
+// IndexedLogs returns the logs of the receipt indexed by emitting contract and
+// event signature. The index is created on first use and cached, the logs must
+// not be modified afterwards.
+func (r *Receipt) IndexedLogs() *IndexedLogs {
+	if ix := r.indexedLogs.Load(); ix != nil {
+		return ix.(*IndexedLogs)
+	}
+	ix := NewIndexedLogs(r.Logs)
+	r.indexedLogs.Store(ix)
+	return ix
+}
+
+// ReceiptAccounting contains the fee components of a transaction which are only
+// known during its execution.
+type ReceiptAccounting struct {
+	GasRefund          uint64 // Gas refunded to the sender (e.g. for clearing storage), capped by EIP-3529
+	AccessListGasSaved uint64 // Gas saved by accessing entries pre-warmed by the access list
 }
 
 type receiptMarshaling struct {
@@ -198,7 +225,7 @@ func (r *Receipt) decodeTyped(b []byte) error {
 		return errShortTypedReceipt
 	}
 	switch b[0] {
-	case DynamicFeeTxType, AccessListTxType, BlobTxType:
+	case DynamicFeeTxType, AccessListTxType, BlobTxType, SetCodeTxType:
 		var data receiptRLP
 		err := rlp.DecodeBytes(b[1:], &data)
 		if err != nil {
@@ -306,7 +333,7 @@ func (rs Receipts) EncodeIndex(i int, w *bytes.Buffer) {
 	}
 	w.WriteByte(r.Type)
 	switch r.Type {
-	case AccessListTxType, DynamicFeeTxType, BlobTxType:
+	case AccessListTxType, DynamicFeeTxType, BlobTxType, SetCodeTxType:
 		rlp.Encode(w, data)
 	default:
 		// For unsupported types, write nothing. Since this is for
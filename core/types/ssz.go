@@ -0,0 +1,454 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/holiman/uint256"
+)
+
+// This file implements SSZ (Simple Serialize) encoding of the chain data types,
+// following the consensus layer conventions: integers are little endian, hashes
+// and addresses are fixed byte vectors and transactions are opaque byte lists
+// of their canonical (EIP-2718) encoding. As SSZ has no notion of optional
+// fields, the fork-dependent header and block fields are encoded as lists with
+// at most one element.
+//
+// Only serialization is provided, SSZ merkleization is not needed by any of the
+// current users.
+
+const (
+	sszOffsetSize = 4 // Size of an offset of a variable length field
+
+	sszMaxTransactions     = 1 << 20 // Maximum number of transactions in a block
+	sszMaxTransactionBytes = 1 << 30 // Maximum size of a single transaction
+	sszMaxUncles           = 16      // Maximum number of uncles in a block
+	sszWithdrawalSize      = 44      // Encoded size of a withdrawal
+)
+
+var (
+	errSSZShort         = errors.New("ssz: input too short")
+	errSSZOffset        = errors.New("ssz: invalid offset")
+	errSSZListSize      = errors.New("ssz: invalid list size")
+	errSSZTooManyItems  = errors.New("ssz: too many list items")
+	errSSZMissingNumber = errors.New("ssz: header number missing")
+)
+
+// sszField is a single field of an SSZ container, either of fixed size or
+// variable size. The latter are referenced by offset from the fixed part.
+type sszField struct {
+	data    []byte
+	dynamic bool
+}
+
+func sszFixed(data []byte) sszField   { return sszField{data: data} }
+func sszDynamic(data []byte) sszField { return sszField{data: data, dynamic: true} }
+
+// sszEncodeContainer serializes the given fields as an SSZ container.
+func sszEncodeContainer(fields ...sszField) []byte {
+	var fixed, size int
+	for _, field := range fields {
+		if field.dynamic {
+			fixed += sszOffsetSize
+		} else {
+			fixed += len(field.data)
+		}
+		size += len(field.data)
+	}
+	out := make([]byte, 0, fixed+size)
+	offset := fixed
+	for _, field := range fields {
+		if field.dynamic {
+			out = binary.LittleEndian.AppendUint32(out, uint32(offset))
+			offset += len(field.data)
+		} else {
+			out = append(out, field.data...)
+		}
+	}
+	for _, field := range fields {
+		if field.dynamic {
+			out = append(out, field.data...)
+		}
+	}
+	return out
+}
+
+// sszDecodeContainer splits an SSZ container into its fields. The layout is
+// described by the sizes of the fields, where zero denotes a variable length.
+func sszDecodeContainer(data []byte, sizes ...int) ([][]byte, error) {
+	var fixed int
+	for _, size := range sizes {
+		if size == 0 {
+			fixed += sszOffsetSize
+		} else {
+			fixed += size
+		}
+	}
+	if len(data) < fixed {
+		return nil, errSSZShort
+	}
+	var (
+		fields  = make([][]byte, len(sizes))
+		offsets []int // Indices of the variable length fields
+		starts  []int // Start offsets of the variable length fields
+		pos     int
+	)
+	for i, size := range sizes {
+		if size != 0 {
+			fields[i] = data[pos : pos+size]
+			pos += size
+			continue
+		}
+		start := int(binary.LittleEndian.Uint32(data[pos:]))
+		if (len(starts) == 0 && start != fixed) || (len(starts) > 0 && start < starts[len(starts)-1]) || start > len(data) {
+			return nil, errSSZOffset
+		}
+		offsets, starts = append(offsets, i), append(starts, start)
+		pos += sszOffsetSize
+	}
+	if len(starts) == 0 && len(data) != fixed {
+		return nil, errSSZOffset
+	}
+	for i, field := range offsets {
+		end := len(data)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		fields[field] = data[starts[i]:end]
+	}
+	return fields, nil
+}
+
+// sszEncodeList serializes a list of variable length items.
+func sszEncodeList(items [][]byte) []byte {
+	fields := make([]sszField, len(items))
+	for i, item := range items {
+		fields[i] = sszDynamic(item)
+	}
+	return sszEncodeContainer(fields...)
+}
+
+// sszDecodeList splits a list of variable length items, allowing at most limit
+// number of them.
+func sszDecodeList(data []byte, limit int) ([][]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if len(data) < sszOffsetSize {
+		return nil, errSSZShort
+	}
+	first := int(binary.LittleEndian.Uint32(data))
+	if first == 0 || first%sszOffsetSize != 0 {
+		return nil, errSSZOffset
+	}
+	count := first / sszOffsetSize
+	if count > limit {
+		return nil, errSSZTooManyItems
+	}
+	return sszDecodeContainer(data, make([]int, count)...)
+}
+
+// sszEncodeUint64 serializes a uint64 as little endian.
+func sszEncodeUint64(v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(nil, v)
+}
+
+// sszEncodeUint256 serializes a big integer as a little endian uint256. A nil
+// integer is encoded as zero.
+func sszEncodeUint256(v *big.Int) ([]byte, error) {
+	var u uint256.Int
+	if v != nil {
+		if v.Sign() < 0 {
+			return nil, fmt.Errorf("ssz: negative integer %v", v)
+		}
+		if u.SetFromBig(v) {
+			return nil, fmt.Errorf("ssz: integer %v overflows uint256", v)
+		}
+	}
+	out := make([]byte, 0, 32)
+	for _, word := range u {
+		out = binary.LittleEndian.AppendUint64(out, word)
+	}
+	return out, nil
+}
+
+// sszDecodeUint256 deserializes a little endian uint256 into a big integer.
+func sszDecodeUint256(data []byte) *big.Int {
+	var u uint256.Int
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	return u.ToBig()
+}
+
+// sszDecodeOptional deserializes a list of at most one fixed size item.
+func sszDecodeOptional(data []byte, size int) ([]byte, error) {
+	switch len(data) {
+	case 0:
+		return nil, nil
+	case size:
+		return data, nil
+	default:
+		return nil, errSSZListSize
+	}
+}
+
+// MarshalSSZ returns the SSZ encoding of the header.
+func (h *Header) MarshalSSZ() ([]byte, error) {
+	if h.Number == nil {
+		return nil, errSSZMissingNumber
+	}
+	if !h.Number.IsUint64() {
+		return nil, fmt.Errorf("ssz: header number %v overflows uint64", h.Number)
+	}
+	difficulty, err := sszEncodeUint256(h.Difficulty)
+	if err != nil {
+		return nil, err
+	}
+	var baseFee, withdrawalsHash, blobGasUsed, excessBlobGas, beaconRoot, requestsHash []byte
+	if h.BaseFee != nil {
+		if baseFee, err = sszEncodeUint256(h.BaseFee); err != nil {
+			return nil, err
+		}
+	}
+	if h.WithdrawalsHash != nil {
+		withdrawalsHash = h.WithdrawalsHash.Bytes()
+	}
+	if h.BlobGasUsed != nil {
+		blobGasUsed = sszEncodeUint64(*h.BlobGasUsed)
+	}
+	if h.ExcessBlobGas != nil {
+		excessBlobGas = sszEncodeUint64(*h.ExcessBlobGas)
+	}
+	if h.ParentBeaconRoot != nil {
+		beaconRoot = h.ParentBeaconRoot.Bytes()
+	}
+	if h.RequestsHash != nil {
+		requestsHash = h.RequestsHash.Bytes()
+	}
+	return sszEncodeContainer(
+		sszFixed(h.ParentHash[:]),
+		sszFixed(h.UncleHash[:]),
+		sszFixed(h.Coinbase[:]),
+		sszFixed(h.Root[:]),
+		sszFixed(h.TxHash[:]),
+		sszFixed(h.ReceiptHash[:]),
+		sszFixed(h.Bloom[:]),
+		sszFixed(difficulty),
+		sszFixed(sszEncodeUint64(h.Number.Uint64())),
+		sszFixed(sszEncodeUint64(h.GasLimit)),
+		sszFixed(sszEncodeUint64(h.GasUsed)),
+		sszFixed(sszEncodeUint64(h.Time)),
+		sszDynamic(h.Extra),
+		sszFixed(h.MixDigest[:]),
+		sszFixed(h.Nonce[:]),
+		sszDynamic(baseFee),
+		sszDynamic(withdrawalsHash),
+		sszDynamic(blobGasUsed),
+		sszDynamic(excessBlobGas),
+		sszDynamic(beaconRoot),
+		sszDynamic(requestsHash),
+	), nil
+}
+
+// UnmarshalSSZ decodes the SSZ encoding of a header.
+func (h *Header) UnmarshalSSZ(data []byte) error {
+	fields, err := sszDecodeContainer(data,
+		common.HashLength, common.HashLength, common.AddressLength, common.HashLength,
+		common.HashLength, common.HashLength, BloomByteLength, 32, 8, 8, 8, 8, 0,
+		common.HashLength, 8, 0, 0, 0, 0, 0, 0,
+	)
+	if err != nil {
+		return err
+	}
+	baseFee, err := sszDecodeOptional(fields[15], 32)
+	if err != nil {
+		return err
+	}
+	withdrawalsHash, err := sszDecodeOptional(fields[16], common.HashLength)
+	if err != nil {
+		return err
+	}
+	blobGasUsed, err := sszDecodeOptional(fields[17], 8)
+	if err != nil {
+		return err
+	}
+	excessBlobGas, err := sszDecodeOptional(fields[18], 8)
+	if err != nil {
+		return err
+	}
+	beaconRoot, err := sszDecodeOptional(fields[19], common.HashLength)
+	if err != nil {
+		return err
+	}
+	requestsHash, err := sszDecodeOptional(fields[20], common.HashLength)
+	if err != nil {
+		return err
+	}
+	*h = Header{
+		ParentHash:  common.BytesToHash(fields[0]),
+		UncleHash:   common.BytesToHash(fields[1]),
+		Coinbase:    common.BytesToAddress(fields[2]),
+		Root:        common.BytesToHash(fields[3]),
+		TxHash:      common.BytesToHash(fields[4]),
+		ReceiptHash: common.BytesToHash(fields[5]),
+		Bloom:       BytesToBloom(fields[6]),
+		Difficulty:  sszDecodeUint256(fields[7]),
+		Number:      new(big.Int).SetUint64(binary.LittleEndian.Uint64(fields[8])),
+		GasLimit:    binary.LittleEndian.Uint64(fields[9]),
+		GasUsed:     binary.LittleEndian.Uint64(fields[10]),
+		Time:        binary.LittleEndian.Uint64(fields[11]),
+		Extra:       common.CopyBytes(fields[12]),
+		MixDigest:   common.BytesToHash(fields[13]),
+		Nonce:       EncodeNonce(binary.BigEndian.Uint64(fields[14])),
+	}
+	if baseFee != nil {
+		h.BaseFee = sszDecodeUint256(baseFee)
+	}
+	if withdrawalsHash != nil {
+		hash := common.BytesToHash(withdrawalsHash)
+		h.WithdrawalsHash = &hash
+	}
+	if blobGasUsed != nil {
+		used := binary.LittleEndian.Uint64(blobGasUsed)
+		h.BlobGasUsed = &used
+	}
+	if excessBlobGas != nil {
+		excess := binary.LittleEndian.Uint64(excessBlobGas)
+		h.ExcessBlobGas = &excess
+	}
+	if beaconRoot != nil {
+		root := common.BytesToHash(beaconRoot)
+		h.ParentBeaconRoot = &root
+	}
+	if requestsHash != nil {
+		hash := common.BytesToHash(requestsHash)
+		h.RequestsHash = &hash
+	}
+	return nil
+}
+
+// MarshalSSZ returns the SSZ encoding of the transaction, which is the opaque
+// canonical encoding also used by the consensus layer.
+func (tx *Transaction) MarshalSSZ() ([]byte, error) {
+	return tx.MarshalBinary()
+}
+
+// UnmarshalSSZ decodes the SSZ encoding of a transaction.
+func (tx *Transaction) UnmarshalSSZ(data []byte) error {
+	if len(data) > sszMaxTransactionBytes {
+		return errSSZTooManyItems
+	}
+	return tx.UnmarshalBinary(data)
+}
+
+// MarshalSSZ returns the SSZ encoding of the block.
+func (b *Block) MarshalSSZ() ([]byte, error) {
+	header, err := b.header.MarshalSSZ()
+	if err != nil {
+		return nil, err
+	}
+	txs := make([][]byte, len(b.transactions))
+	for i, tx := range b.transactions {
+		if txs[i], err = tx.MarshalSSZ(); err != nil {
+			return nil, err
+		}
+	}
+	uncles := make([][]byte, len(b.uncles))
+	for i, uncle := range b.uncles {
+		if uncles[i], err = uncle.MarshalSSZ(); err != nil {
+			return nil, err
+		}
+	}
+	var withdrawals []byte
+	if b.withdrawals != nil {
+		list := make([]byte, 0, len(b.withdrawals)*sszWithdrawalSize)
+		for _, w := range b.withdrawals {
+			list = binary.LittleEndian.AppendUint64(list, w.Index)
+			list = binary.LittleEndian.AppendUint64(list, w.Validator)
+			list = append(list, w.Address[:]...)
+			list = binary.LittleEndian.AppendUint64(list, w.Amount)
+		}
+		withdrawals = sszEncodeList([][]byte{list})
+	}
+	return sszEncodeContainer(
+		sszDynamic(header),
+		sszDynamic(sszEncodeList(txs)),
+		sszDynamic(sszEncodeList(uncles)),
+		sszDynamic(withdrawals),
+	), nil
+}
+
+// UnmarshalSSZ decodes the SSZ encoding of a block.
+func (b *Block) UnmarshalSSZ(data []byte) error {
+	fields, err := sszDecodeContainer(data, 0, 0, 0, 0)
+	if err != nil {
+		return err
+	}
+	header := new(Header)
+	if err := header.UnmarshalSSZ(fields[0]); err != nil {
+		return err
+	}
+	items, err := sszDecodeList(fields[1], sszMaxTransactions)
+	if err != nil {
+		return err
+	}
+	txs := make(Transactions, len(items))
+	for i, item := range items {
+		txs[i] = new(Transaction)
+		if err := txs[i].UnmarshalSSZ(item); err != nil {
+			return fmt.Errorf("ssz: invalid transaction %d: %v", i, err)
+		}
+	}
+	if items, err = sszDecodeList(fields[2], sszMaxUncles); err != nil {
+		return err
+	}
+	var uncles []*Header
+	for i, item := range items {
+		uncle := new(Header)
+		if err := uncle.UnmarshalSSZ(item); err != nil {
+			return fmt.Errorf("ssz: invalid uncle %d: %v", i, err)
+		}
+		uncles = append(uncles, uncle)
+	}
+	if items, err = sszDecodeList(fields[3], 1); err != nil {
+		return err
+	}
+	var withdrawals Withdrawals
+	if len(items) == 1 {
+		list := items[0]
+		if len(list)%sszWithdrawalSize != 0 {
+			return errSSZListSize
+		}
+		withdrawals = make(Withdrawals, 0, len(list)/sszWithdrawalSize)
+		for ; len(list) > 0; list = list[sszWithdrawalSize:] {
+			withdrawals = append(withdrawals, &Withdrawal{
+				Index:     binary.LittleEndian.Uint64(list),
+				Validator: binary.LittleEndian.Uint64(list[8:]),
+				Address:   common.BytesToAddress(list[16:36]),
+				Amount:    binary.LittleEndian.Uint64(list[36:]),
+			})
+		}
+	}
+	b.header, b.uncles, b.transactions, b.withdrawals = header, uncles, txs, withdrawals
+	return nil
+}
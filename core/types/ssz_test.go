@@ -0,0 +1,132 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/internal/blocktest"
+	"github.com/gorievm/go-gori/rlp"
+)
+
+// checkBlockSSZ encodes the block as SSZ, decodes it back and checks that the
+// result is identical to the original.
+func checkBlockSSZ(t *testing.T, block *Block) {
+	t.Helper()
+
+	enc, err := block.MarshalSSZ()
+	if err != nil {
+		t.Fatalf("failed to encode block: %v", err)
+	}
+	dec := new(Block)
+	if err := dec.UnmarshalSSZ(enc); err != nil {
+		t.Fatalf("failed to decode block: %v", err)
+	}
+	if dec.Hash() != block.Hash() {
+		t.Fatalf("block hash mismatch: have %x, want %x", dec.Hash(), block.Hash())
+	}
+	have, _ := rlp.EncodeToBytes(dec)
+	want, _ := rlp.EncodeToBytes(block)
+	if !bytes.Equal(have, want) {
+		t.Fatalf("block content mismatch:\nhave %x\nwant %x", have, want)
+	}
+	reenc, err := dec.MarshalSSZ()
+	if err != nil {
+		t.Fatalf("failed to re-encode block: %v", err)
+	}
+	if !bytes.Equal(reenc, enc) {
+		t.Fatalf("encoding not canonical:\nhave %x\nwant %x", reenc, enc)
+	}
+}
+
+func TestBlockSSZ(t *testing.T) {
+	// Legacy block with transactions and uncles
+	checkBlockSSZ(t, makeBenchBlock())
+
+	// Cancun block with all the optional fields and withdrawals
+	var (
+		withdrawalsHash = common.Hash{0x01}
+		blobGasUsed     = uint64(131072)
+		excessBlobGas   = uint64(0)
+		beaconRoot      = common.Hash{0x06}
+	)
+	header := &Header{
+		ParentHash:       common.Hash{0x02},
+		Coinbase:         common.Address{0x03},
+		Difficulty:       new(big.Int),
+		Number:           big.NewInt(1024),
+		GasLimit:         30_000_000,
+		Time:             1710338135,
+		Extra:            []byte("ssz"),
+		BaseFee:          big.NewInt(7),
+		WithdrawalsHash:  &withdrawalsHash,
+		BlobGasUsed:      &blobGasUsed,
+		ExcessBlobGas:    &excessBlobGas,
+		ParentBeaconRoot: &beaconRoot,
+	}
+	withdrawals := []*Withdrawal{
+		{Index: 1, Validator: 2, Address: common.Address{0x04}, Amount: 3},
+		{Index: 2, Validator: 5, Address: common.Address{0x05}, Amount: 6},
+	}
+	checkBlockSSZ(t, NewBlockWithWithdrawals(header, nil, nil, nil, withdrawals, blocktest.NewHasher()))
+
+	// Shanghai block with an empty withdrawal list, which must not be confused
+	// with a missing one
+	header.BlobGasUsed, header.ExcessBlobGas, header.ParentBeaconRoot = nil, nil, nil
+	block := NewBlockWithWithdrawals(header, nil, nil, nil, []*Withdrawal{}, blocktest.NewHasher())
+	checkBlockSSZ(t, block)
+
+	enc, _ := block.MarshalSSZ()
+	dec := new(Block)
+	if err := dec.UnmarshalSSZ(enc); err != nil {
+		t.Fatalf("failed to decode block: %v", err)
+	}
+	if dec.Withdrawals() == nil {
+		t.Fatalf("empty withdrawals decoded as missing")
+	}
+}
+
+func TestHeaderSSZInvalid(t *testing.T) {
+	header := &Header{
+		Difficulty: big.NewInt(1),
+		Number:     big.NewInt(1),
+		Extra:      []byte("extra"),
+		BaseFee:    big.NewInt(1),
+	}
+	enc, err := header.MarshalSSZ()
+	if err != nil {
+		t.Fatalf("failed to encode header: %v", err)
+	}
+	// Truncated inputs must be rejected
+	for i := 0; i < len(enc)-len(header.Extra)-32; i++ {
+		if err := new(Header).UnmarshalSSZ(enc[:i]); err == nil {
+			t.Fatalf("truncated header of length %d decoded", i)
+		}
+	}
+	// Values exceeding the SSZ types must be rejected
+	header.Number = new(big.Int).Lsh(big.NewInt(1), 64)
+	if _, err := header.MarshalSSZ(); err == nil {
+		t.Fatalf("overflowing number encoded")
+	}
+	header.Number, header.Difficulty = big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), 256)
+	if _, err := header.MarshalSSZ(); err == nil {
+		t.Fatalf("overflowing difficulty encoded")
+	}
+}
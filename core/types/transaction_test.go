@@ -29,6 +29,7 @@ import (
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/crypto"
 	"github.com/gorievm/go-gori/rlp"
+	"github.com/holiman/uint256"
 )
 
 // The values in those tests are from the Transaction Tests
@@ -344,6 +345,71 @@ func TestTransactionCoding(t *testing.T) {
 	}
 }
 
+// Tests that EIP-7702 set code transactions survive the binary and JSON
+// encodings, and that both the sender and the authorities can be recovered.
+func TestSetCodeTransactionCoding(t *testing.T) {
+	var (
+		key, _     = crypto.GenerateKey()
+		authKey, _ = crypto.GenerateKey()
+		signer     = NewPragueSigner(common.Big1)
+		sender     = crypto.PubkeyToAddress(key.PublicKey)
+		authority  = crypto.PubkeyToAddress(authKey.PublicKey)
+		delegate   = common.HexToAddress("0x0000000000000000000000000000000000000aaa")
+	)
+	auth, err := SignSetCode(authKey, SetCodeAuthorization{
+		ChainID: *uint256.NewInt(1),
+		Address: delegate,
+		Nonce:   7,
+	})
+	if err != nil {
+		t.Fatalf("could not sign authorization: %v", err)
+	}
+	tx, err := SignNewTx(key, signer, &SetCodeTx{
+		ChainID:    uint256.NewInt(1),
+		Nonce:      1,
+		GasTipCap:  uint256.NewInt(1),
+		GasFeeCap:  uint256.NewInt(10),
+		Gas:        123457,
+		To:         delegate,
+		Value:      uint256.NewInt(0),
+		Data:       []byte("abcdef"),
+		AccessList: AccessList{{Address: delegate, StorageKeys: []common.Hash{{0}}}},
+		AuthList:   []SetCodeAuthorization{auth},
+	})
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	for name, codec := range map[string]func(*Transaction) (*Transaction, error){
+		"rlp":  encodeDecodeBinary,
+		"json": encodeDecodeJSON,
+	} {
+		parsed, err := codec(tx)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := assertEqual(parsed, tx); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(parsed.SetCodeAuthorizations(), tx.SetCodeAuthorizations()) {
+			t.Fatalf("%s: authorization list mismatch", name)
+		}
+		if from, err := Sender(signer, parsed); err != nil || from != sender {
+			t.Fatalf("%s: sender mismatch: have %v (%v), want %v", name, from, err, sender)
+		}
+		if have := parsed.SetCodeAuthorities(); len(have) != 1 || have[0] != authority {
+			t.Fatalf("%s: authorities mismatch: have %v, want [%v]", name, have, authority)
+		}
+	}
+	// Older signers must reject the transaction type
+	if _, err := Sender(NewCancunSigner(common.Big1), tx); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("cancun signer error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	// Delegation designators should round trip
+	if addr, ok := ParseDelegation(AddressToDelegation(delegate)); !ok || addr != delegate {
+		t.Fatalf("delegation mismatch: have %v (%v), want %v", addr, ok, delegate)
+	}
+}
+
 func encodeDecodeJSON(tx *Transaction) (*Transaction, error) {
 	data, err := json.Marshal(tx)
 	if err != nil {
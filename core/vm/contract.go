@@ -53,6 +53,9 @@ type Contract struct {
 	jumpdests map[common.Hash]bitvec // Aggregated result of JUMPDEST analysis.
 	analysis  bitvec                 // Locally cached result of JUMPDEST analysis
 
+	fusions map[common.Hash][]fusedOp // Aggregated result of instruction fusion analysis.
+	fused   []fusedOp                 // Locally cached result of instruction fusion analysis
+
 	Code     []byte
 	CodeHash common.Hash
 	CodeAddr *common.Address
@@ -60,6 +63,11 @@ type Contract struct {
 
 	Gas   uint64
 	value *big.Int
+
+	// EOF execution state, unused when running legacy code
+	container   *Container
+	section     uint64
+	returnStack []*returnContext
 }
 
 // NewContract returns a new contract environment for the execution of EVM.
@@ -67,10 +75,12 @@ func NewContract(caller ContractRef, object ContractRef, value *big.Int, gas uin
 	c := &Contract{CallerAddress: caller.Address(), caller: caller, self: object}
 
 	if parent, ok := caller.(*Contract); ok {
-		// Reuse JUMPDEST analysis from parent context if available.
+		// Reuse JUMPDEST and fusion analysis from parent context if available.
 		c.jumpdests = parent.jumpdests
+		c.fusions = parent.fusions
 	} else {
 		c.jumpdests = make(map[common.Hash]bitvec)
+		c.fusions = make(map[common.Hash][]fusedOp)
 	}
 
 	// Gas should be a pointer so it can safely be reduced through the run
@@ -129,6 +139,25 @@ func (c *Contract) isCode(udest uint64) bool {
 	return c.analysis.codeSegment(udest)
 }
 
+// fusedOps returns the fused instruction sequences of the code, caching the
+// analysis in the parent context for regular contracts like isCode does.
+func (c *Contract) fusedOps() []fusedOp {
+	if c.fused != nil {
+		return c.fused
+	}
+	if c.CodeHash != (common.Hash{}) {
+		fused, exist := c.fusions[c.CodeHash]
+		if !exist {
+			fused = fusionAnalysis(c.Code)
+			c.fusions[c.CodeHash] = fused
+		}
+		c.fused = fused
+		return fused
+	}
+	c.fused = fusionAnalysis(c.Code)
+	return c.fused
+}
+
 // AsDelegate sets the contract to be a delegate call and returns the current
 // contract (for chaining calls)
 func (c *Contract) AsDelegate() *Contract {
@@ -0,0 +1,282 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	offsetVersion   = 2
+	offsetTypesKind = 3
+	offsetCodeKind  = 6
+
+	kindTypes = 1
+	kindCode  = 2
+	kindData  = 3
+
+	eof1Version = 1
+
+	maxInputItems        = 127
+	maxOutputItems       = 127
+	maxStackHeight       = 1023
+	maxCodeSections      = 1024
+	maxReturnStackDepth  = 1024
+	nonReturningFunction = 0x80
+)
+
+var eofMagic = []byte{0xef, 0x00}
+
+var (
+	errInvalidMagic           = errors.New("invalid magic")
+	errInvalidVersion         = errors.New("invalid version")
+	errMissingTypeHeader      = errors.New("missing type header")
+	errInvalidTypeSize        = errors.New("invalid type section size")
+	errMissingCodeHeader      = errors.New("missing code header")
+	errInvalidCodeHeader      = errors.New("invalid code header")
+	errInvalidCodeSize        = errors.New("invalid code size")
+	errMissingDataHeader      = errors.New("missing data header")
+	errMissingTerminator      = errors.New("missing header terminator")
+	errInvalidContainerSize   = errors.New("invalid container size")
+	errInvalidSection0Type    = errors.New("invalid section 0 type, input and output should be zero and non-returning (0x80)")
+	errTooManyInputs          = errors.New("invalid type content, too many inputs")
+	errTooManyOutputs         = errors.New("invalid type content, too many outputs")
+	errTooLargeMaxStackHeight = errors.New("invalid type content, max stack height exceeds limit")
+)
+
+// HasEOFMagic returns whether the code starts with the EOF magic bytes.
+func HasEOFMagic(code []byte) bool {
+	return len(code) >= len(eofMagic) && bytes.Equal(code[:len(eofMagic)], eofMagic)
+}
+
+// isEOFVersion1 returns whether the code is an EOF container of version 1.
+func isEOFVersion1(code []byte) bool {
+	return HasEOFMagic(code) && len(code) > offsetVersion && code[offsetVersion] == eof1Version
+}
+
+// Container is an EOF container object.
+type Container struct {
+	types []*functionMetadata
+	code  [][]byte
+	data  []byte
+}
+
+// functionMetadata is an EOF function signature.
+type functionMetadata struct {
+	inputs         uint8
+	outputs        uint8
+	maxStackHeight uint16
+}
+
+// MarshalBinary encodes an EOF container into binary format.
+func (c *Container) MarshalBinary() []byte {
+	// Build EOF prefix.
+	b := make([]byte, 2)
+	copy(b, eofMagic)
+	b = append(b, eof1Version)
+
+	// Write section headers.
+	b = append(b, kindTypes)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.types)*4))
+	b = append(b, kindCode)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.code)))
+	for _, code := range c.code {
+		b = binary.BigEndian.AppendUint16(b, uint16(len(code)))
+	}
+	b = append(b, kindData)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.data)))
+	b = append(b, 0) // terminator
+
+	// Write section contents.
+	for _, ty := range c.types {
+		b = append(b, []byte{ty.inputs, ty.outputs, byte(ty.maxStackHeight >> 8), byte(ty.maxStackHeight & 0x00ff)}...)
+	}
+	for _, code := range c.code {
+		b = append(b, code...)
+	}
+	b = append(b, c.data...)
+
+	return b
+}
+
+// UnmarshalBinary decodes an EOF container. Only the container format is checked,
+// the code sections are validated separately by ValidateCode.
+func (c *Container) UnmarshalBinary(b []byte) error {
+	if !HasEOFMagic(b) {
+		return fmt.Errorf("%w: want %x", errInvalidMagic, eofMagic)
+	}
+	if len(b) < 14 {
+		return io.ErrUnexpectedEOF
+	}
+	if !isEOFVersion1(b) {
+		return fmt.Errorf("%w: have %d, want %d", errInvalidVersion, b[offsetVersion], eof1Version)
+	}
+	// Parse type section header.
+	kind, typesSize, err := parseSection(b, offsetTypesKind)
+	if err != nil {
+		return err
+	}
+	if kind != kindTypes {
+		return fmt.Errorf("%w: found section kind %x instead", errMissingTypeHeader, kind)
+	}
+	if typesSize < 4 || typesSize%4 != 0 {
+		return fmt.Errorf("%w: type section size must be divisible by 4, have %d", errInvalidTypeSize, typesSize)
+	}
+	if typesSize/4 > maxCodeSections {
+		return fmt.Errorf("%w: type section must not exceed 4*%d, have %d", errInvalidTypeSize, maxCodeSections, typesSize)
+	}
+	// Parse code section header.
+	kind, codeSizes, err := parseSectionList(b, offsetCodeKind)
+	if err != nil {
+		return err
+	}
+	if kind != kindCode {
+		return fmt.Errorf("%w: found section kind %x instead", errMissingCodeHeader, kind)
+	}
+	if len(codeSizes) != typesSize/4 {
+		return fmt.Errorf("%w: mismatch of code sections count and type signatures, types %d, code %d", errInvalidCodeSize, typesSize/4, len(codeSizes))
+	}
+	// Parse data section header.
+	offsetDataKind := offsetCodeKind + 3 + 2*len(codeSizes)
+	kind, dataSize, err := parseSection(b, offsetDataKind)
+	if err != nil {
+		return err
+	}
+	if kind != kindData {
+		return fmt.Errorf("%w: found section %x instead", errMissingDataHeader, kind)
+	}
+	// Check for terminator.
+	offsetTerminator := offsetDataKind + 3
+	if len(b) <= offsetTerminator {
+		return io.ErrUnexpectedEOF
+	}
+	if b[offsetTerminator] != 0 {
+		return fmt.Errorf("%w: have %x", errMissingTerminator, b[offsetTerminator])
+	}
+	// Verify overall container size.
+	expectedSize := offsetTerminator + typesSize + dataSize + 1
+	for _, size := range codeSizes {
+		expectedSize += size
+	}
+	if len(b) != expectedSize {
+		return fmt.Errorf("%w: have %d, want %d", errInvalidContainerSize, len(b), expectedSize)
+	}
+	// Parse types section.
+	idx := offsetTerminator + 1
+	types := make([]*functionMetadata, 0, typesSize/4)
+	for i := 0; i < typesSize/4; i++ {
+		sig := &functionMetadata{
+			inputs:         b[idx+i*4],
+			outputs:        b[idx+i*4+1],
+			maxStackHeight: binary.BigEndian.Uint16(b[idx+i*4+2:]),
+		}
+		if sig.inputs > maxInputItems {
+			return fmt.Errorf("%w for section %d: have %d", errTooManyInputs, i, sig.inputs)
+		}
+		if sig.outputs > maxOutputItems && sig.outputs != nonReturningFunction {
+			return fmt.Errorf("%w for section %d: have %d", errTooManyOutputs, i, sig.outputs)
+		}
+		if sig.maxStackHeight > maxStackHeight {
+			return fmt.Errorf("%w for section %d: have %d", errTooLargeMaxStackHeight, i, sig.maxStackHeight)
+		}
+		types = append(types, sig)
+	}
+	if types[0].inputs != 0 || types[0].outputs != nonReturningFunction {
+		return fmt.Errorf("%w: have %d, %d", errInvalidSection0Type, types[0].inputs, types[0].outputs)
+	}
+	c.types = types
+
+	// Parse code sections.
+	idx += typesSize
+	code := make([][]byte, len(codeSizes))
+	for i, size := range codeSizes {
+		if size == 0 {
+			return fmt.Errorf("%w for section %d: size must not be 0", errInvalidCodeSize, i)
+		}
+		code[i] = b[idx : idx+size]
+		idx += size
+	}
+	c.code = code
+
+	// Parse data section.
+	c.data = b[idx : idx+dataSize]
+
+	return nil
+}
+
+// ValidateCode validates each code section of the container against the EOF v1
+// rule set.
+func (c *Container) ValidateCode(jt *JumpTable) error {
+	for i, code := range c.code {
+		if err := validateCode(code, i, c.types, jt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseSection decodes a (kind, size) pair from an EOF header.
+func parseSection(b []byte, idx int) (kind, size int, err error) {
+	if idx+3 > len(b) {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	kind = int(b[idx])
+	size = int(binary.BigEndian.Uint16(b[idx+1:]))
+	return kind, size, nil
+}
+
+// parseSectionList decodes a (kind, len, []codeSize) section list from an EOF
+// header.
+func parseSectionList(b []byte, idx int) (kind int, list []int, err error) {
+	if idx >= len(b) {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	kind = int(b[idx])
+	list, err = parseList(b, idx+1)
+	if err != nil {
+		return 0, nil, err
+	}
+	return kind, list, nil
+}
+
+// parseList decodes a list of uint16 values prefixed by their count.
+func parseList(b []byte, idx int) ([]int, error) {
+	if len(b) < idx+2 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	count := int(binary.BigEndian.Uint16(b[idx:]))
+	if count == 0 || count > maxCodeSections {
+		return nil, fmt.Errorf("%w: have %d code sections", errInvalidCodeHeader, count)
+	}
+	if len(b) <= idx+2+count*2 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	list := make([]int, count)
+	for i := 0; i < count; i++ {
+		list[i] = int(binary.BigEndian.Uint16(b[idx+2+2*i:]))
+	}
+	return list, nil
+}
+
+// parseInt16 returns the int16 located at b[0:2].
+func parseInt16(b []byte) int {
+	return int(int16(b[1]) | int16(b[0])<<8)
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+
+	"github.com/gorievm/go-gori/params"
+)
+
+// returnContext is a frame of the EOF return stack, recording where execution
+// resumes once the called function returns.
+type returnContext struct {
+	section uint64
+	pc      uint64
+}
+
+// setSection switches execution to the given code section of the container.
+func (c *Contract) setSection(section uint64) {
+	c.section = section
+	c.Code = c.container.code[section]
+}
+
+// opRjump implements the RJUMP instruction (EIP-4200).
+func opRjump(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	offset := parseInt16(scope.Contract.Code[*pc+1:])
+
+	// The offset is relative to the end of the immediate, the interpreter loop
+	// increments pc after the instruction.
+	*pc = uint64(int64(*pc+3)+int64(offset)) - 1
+	return nil, nil
+}
+
+// opRjumpi implements the RJUMPI instruction (EIP-4200).
+func opRjumpi(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	condition := scope.Stack.pop()
+	if condition.IsZero() {
+		// Not branching, just skip over immediate argument.
+		*pc += 2
+		return nil, nil
+	}
+	return opRjump(pc, interpreter, scope)
+}
+
+// opRjumpv implements the RJUMPV instruction (EIP-4200).
+func opRjumpv(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		code     = scope.Contract.Code
+		maxIndex = uint64(code[*pc+1])
+		end      = *pc + 2 + 2*(maxIndex+1)
+		caseVal  = scope.Stack.pop()
+	)
+	if index, overflow := caseVal.Uint64WithOverflow(); !overflow && index <= maxIndex {
+		offset := parseInt16(code[*pc+2+2*index:])
+		*pc = uint64(int64(end)+int64(offset)) - 1
+	} else {
+		// Index out-of-bounds, don't branch, just skip over immediate argument.
+		*pc = end - 1
+	}
+	return nil, nil
+}
+
+// opCallf implements the CALLF instruction (EIP-4750).
+func opCallf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		idx = binary.BigEndian.Uint16(scope.Contract.Code[*pc+1:])
+		typ = scope.Contract.container.types[idx]
+	)
+	if scope.Stack.len()+int(typ.maxStackHeight)-int(typ.inputs) > int(params.StackLimit) {
+		return nil, &ErrStackOverflow{stackLen: scope.Stack.len(), limit: int(params.StackLimit)}
+	}
+	if len(scope.Contract.returnStack) >= maxReturnStackDepth {
+		return nil, ErrReturnStackExceeded
+	}
+	scope.Contract.returnStack = append(scope.Contract.returnStack, &returnContext{section: scope.Contract.section, pc: *pc + 3})
+	scope.Contract.setSection(uint64(idx))
+
+	*pc = ^uint64(0) // wraps to 0 when the interpreter loop increments it
+	return nil, nil
+}
+
+// opRetf implements the RETF instruction (EIP-4750).
+func opRetf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	last := len(scope.Contract.returnStack) - 1
+	ret := scope.Contract.returnStack[last]
+	scope.Contract.returnStack = scope.Contract.returnStack[:last]
+	scope.Contract.setSection(ret.section)
+
+	*pc = ret.pc - 1
+	return nil, nil
+}
+
+// opJumpf implements the JUMPF instruction, a tail call which transfers control
+// to another function without growing the return stack.
+func opJumpf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		idx = binary.BigEndian.Uint16(scope.Contract.Code[*pc+1:])
+		typ = scope.Contract.container.types[idx]
+	)
+	if scope.Stack.len()+int(typ.maxStackHeight)-int(typ.inputs) > int(params.StackLimit) {
+		return nil, &ErrStackOverflow{stackLen: scope.Stack.len(), limit: int(params.StackLimit)}
+	}
+	scope.Contract.setSection(uint64(idx))
+
+	*pc = ^uint64(0) // wraps to 0 when the interpreter loop increments it
+	return nil, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+)
+
+func TestEOFMarshaling(t *testing.T) {
+	for i, test := range []struct {
+		want Container
+		err  error
+	}{
+		{
+			want: Container{
+				types: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+				code:  [][]byte{common.Hex2Bytes("604200")},
+				data:  []byte{0x01, 0x02, 0x03},
+			},
+		},
+		{
+			want: Container{
+				types: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+				code:  [][]byte{common.Hex2Bytes("604200")},
+				data:  []byte{},
+			},
+		},
+		{
+			want: Container{
+				types: []*functionMetadata{
+					{inputs: 0, outputs: 0x80, maxStackHeight: 1},
+					{inputs: 2, outputs: 3, maxStackHeight: 4},
+					{inputs: 1, outputs: 1, maxStackHeight: 1},
+				},
+				code: [][]byte{
+					common.Hex2Bytes("604200"),
+					common.Hex2Bytes("6042604200"),
+					common.Hex2Bytes("00"),
+				},
+				data: []byte{},
+			},
+		},
+	} {
+		var (
+			b   = test.want.MarshalBinary()
+			got Container
+		)
+		t.Logf("b: %#x", b)
+		if err := got.UnmarshalBinary(b); err != nil && err != test.err {
+			t.Fatalf("test %d: got error \"%v\", want \"%v\"", i, err, test.err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Fatalf("test %d: got %+v, want %+v", i, got, test.want)
+		}
+	}
+}
+
+func TestEOFParseErrors(t *testing.T) {
+	valid := (&Container{
+		types: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+		code:  [][]byte{common.Hex2Bytes("604200")},
+		data:  []byte{0x01},
+	}).MarshalBinary()
+
+	mutate := func(f func(b []byte) []byte) []byte {
+		b := common.CopyBytes(valid)
+		return f(b)
+	}
+	for i, test := range []struct {
+		code []byte
+		want error
+	}{
+		{mutate(func(b []byte) []byte { b[1] = 0x01; return b }), errInvalidMagic},
+		{mutate(func(b []byte) []byte { b[2] = 0x02; return b }), errInvalidVersion},
+		{mutate(func(b []byte) []byte { b[3] = 0x02; return b }), errMissingTypeHeader},
+		{mutate(func(b []byte) []byte { b[5] = 0x03; return b }), errInvalidTypeSize},
+		{mutate(func(b []byte) []byte { b[6] = 0x01; return b }), errMissingCodeHeader},
+		{mutate(func(b []byte) []byte { b[8] = 0x00; return b }), errInvalidCodeHeader},
+		{mutate(func(b []byte) []byte { b[11] = 0x04; return b }), errMissingDataHeader},
+		{mutate(func(b []byte) []byte { b[14] = 0x01; return b }), errMissingTerminator},
+		{mutate(func(b []byte) []byte { return append(b, 0x00) }), errInvalidContainerSize},
+		{mutate(func(b []byte) []byte { b[16] = 0x00; return b }), errInvalidSection0Type},
+		{mutate(func(b []byte) []byte { b[17] = 0x04; return b }), errTooLargeMaxStackHeight},
+		{valid[:10], io.ErrUnexpectedEOF},
+	} {
+		var c Container
+		if err := c.UnmarshalBinary(test.code); !errors.Is(err, test.want) {
+			t.Errorf("test %d: have error %v, want %v", i, err, test.want)
+		}
+	}
+}
+
+func FuzzEOFUnmarshal(f *testing.F) {
+	f.Add(common.Hex2Bytes("ef000101000402000100030300010000800001604200ff"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var c Container
+		if err := c.UnmarshalBinary(data); err != nil {
+			return
+		}
+		if have := c.MarshalBinary(); string(have) != string(data) {
+			t.Fatalf("roundtrip mismatch: have %x, want %x", have, data)
+		}
+	})
+}
@@ -0,0 +1,248 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/gorievm/go-gori/params"
+)
+
+var (
+	errUndefinedInstruction   = errors.New("undefined instruction")
+	errTruncatedImmediate     = errors.New("truncated immediate")
+	errInvalidSectionArgument = errors.New("invalid section argument")
+	errInvalidCallArgument    = errors.New("callf into non-returning section")
+	errInvalidJumpfTarget     = errors.New("jumpf into section returning more items than the caller")
+	errInvalidRetf            = errors.New("retf in non-returning section")
+	errInvalidJumpDest        = errors.New("invalid jump destination")
+	errInvalidCodeTermination = errors.New("invalid code termination")
+	errUnreachableCode        = errors.New("unreachable code")
+	errInvalidMaxStackHeight  = errors.New("invalid max stack height")
+	errConflictingStack       = errors.New("conflicting stack height")
+	errInvalidOutputs         = errors.New("invalid number of outputs")
+	errEOFStackUnderflow      = errors.New("stack underflow")
+	errEOFStackOverflow       = errors.New("stack overflow")
+)
+
+// terminals are the instructions that may end a code section.
+var terminals = map[OpCode]bool{
+	STOP:    true,
+	RETURN:  true,
+	REVERT:  true,
+	INVALID: true,
+	RJUMP:   true,
+	RETF:    true,
+	JUMPF:   true,
+}
+
+// validateCode validates the code section at the given index against the EOF v1
+// rules: only defined instructions with complete immediates, relative jumps into
+// instruction boundaries, valid function references, a terminating last
+// instruction and a consistent stack height on every path (EIP-5450).
+func validateCode(code []byte, section int, metadata []*functionMetadata, jt *JumpTable) error {
+	var (
+		i       = 0
+		count   = 0
+		op      OpCode
+		imms    = make(bitvec, len(code)/8+1+4)
+		targets []int
+	)
+	for i < len(code) {
+		count++
+		op = OpCode(code[i])
+		if jt[op].undefined {
+			return fmt.Errorf("%w: op %s, pos %d", errUndefinedInstruction, op, i)
+		}
+		size := 0
+		switch {
+		case op >= PUSH1 && op <= PUSH32:
+			size = int(op - PUSH0)
+		case op == RJUMP || op == RJUMPI || op == CALLF || op == JUMPF:
+			size = 2
+		case op == RJUMPV:
+			if i+1 >= len(code) {
+				return fmt.Errorf("%w: op %s, pos %d", errTruncatedImmediate, op, i)
+			}
+			size = 1 + 2*(int(code[i+1])+1)
+		}
+		if size > 0 && i+size >= len(code) {
+			return fmt.Errorf("%w: op %s, pos %d", errTruncatedImmediate, op, i)
+		}
+		for j := 1; j <= size; j++ {
+			imms.set1(uint64(i + j))
+		}
+		switch op {
+		case RJUMP, RJUMPI:
+			targets = append(targets, i+3+parseInt16(code[i+1:]))
+		case RJUMPV:
+			cases := int(code[i+1]) + 1
+			for j := 0; j < cases; j++ {
+				targets = append(targets, i+size+1+parseInt16(code[i+2+2*j:]))
+			}
+		case CALLF:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg >= len(metadata) {
+				return fmt.Errorf("%w: arg %d, last %d, pos %d", errInvalidSectionArgument, arg, len(metadata), i)
+			}
+			if metadata[arg].outputs == nonReturningFunction {
+				return fmt.Errorf("%w: section %d, pos %d", errInvalidCallArgument, arg, i)
+			}
+		case JUMPF:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg >= len(metadata) {
+				return fmt.Errorf("%w: arg %d, last %d, pos %d", errInvalidSectionArgument, arg, len(metadata), i)
+			}
+			if target := metadata[arg].outputs; target != nonReturningFunction {
+				if current := metadata[section].outputs; current == nonReturningFunction || target > current {
+					return fmt.Errorf("%w: section %d, pos %d", errInvalidJumpfTarget, arg, i)
+				}
+			}
+		case RETF:
+			if metadata[section].outputs == nonReturningFunction {
+				return fmt.Errorf("%w: pos %d", errInvalidRetf, i)
+			}
+		}
+		i += size + 1
+	}
+	// Code sections may not "fall through" and require proper termination.
+	if !terminals[op] {
+		return fmt.Errorf("%w: end with %s, pos %d", errInvalidCodeTermination, op, i)
+	}
+	for _, dest := range targets {
+		if dest < 0 || dest >= len(code) || !imms.codeSegment(uint64(dest)) {
+			return fmt.Errorf("%w: dest %d", errInvalidJumpDest, dest)
+		}
+	}
+	return validateControlFlow(code, section, metadata, jt, count)
+}
+
+// validateControlFlow walks every execution path of the code section, checking
+// that each instruction is reached with the same stack height on all paths, that
+// no instruction underflows or overflows the stack and that the maximum height
+// matches the one declared in the type section.
+func validateControlFlow(code []byte, section int, metadata []*functionMetadata, jt *JumpTable, count int) error {
+	type item struct {
+		pos    int
+		height int
+	}
+	var (
+		heights   = make(map[int]int)
+		worklist  = []item{{0, int(metadata[section].inputs)}}
+		maxHeight = int(metadata[section].inputs)
+	)
+	for 0 < len(worklist) {
+		var (
+			idx    = len(worklist) - 1
+			pos    = worklist[idx].pos
+			height = worklist[idx].height
+		)
+		worklist = worklist[:idx]
+
+	outer:
+		for pos < len(code) {
+			op := OpCode(code[pos])
+
+			// Check if pos has already been visited; if so, the stack heights should be the same.
+			if want, ok := heights[pos]; ok {
+				if height != want {
+					return fmt.Errorf("%w: have %d, want %d, pos %d", errConflictingStack, height, want, pos)
+				}
+				// Already visited this path and stack height matches.
+				break
+			}
+			heights[pos] = height
+
+			// Validate height for current op and update as needed.
+			if want, have := jt[op].minStack, height; want > have {
+				return fmt.Errorf("%w: op %s, have %d, want %d, pos %d", errEOFStackUnderflow, op, have, want, pos)
+			}
+			if limit, have := jt[op].maxStack, height; have > limit {
+				return fmt.Errorf("%w: op %s, have %d, limit %d, pos %d", errEOFStackOverflow, op, have, limit, pos)
+			}
+			height += int(params.StackLimit) - jt[op].maxStack
+
+			switch {
+			case op == CALLF:
+				arg := binary.BigEndian.Uint16(code[pos+1:])
+				target := metadata[arg]
+				if want, have := int(target.inputs), height; want > have {
+					return fmt.Errorf("%w: op %s, have %d, want %d, pos %d", errEOFStackUnderflow, op, have, want, pos)
+				}
+				if have, limit := height+int(target.maxStackHeight)-int(target.inputs), int(params.StackLimit); have > limit {
+					return fmt.Errorf("%w: op %s, have %d, limit %d, pos %d", errEOFStackOverflow, op, have, limit, pos)
+				}
+				height = height - int(target.inputs) + int(target.outputs)
+				pos += 3
+			case op == RETF:
+				if have, want := height, int(metadata[section].outputs); have != want {
+					return fmt.Errorf("%w: have %d, want %d, pos %d", errInvalidOutputs, have, want, pos)
+				}
+				break outer
+			case op == JUMPF:
+				arg := binary.BigEndian.Uint16(code[pos+1:])
+				target := metadata[arg]
+				if have, limit := height+int(target.maxStackHeight)-int(target.inputs), int(params.StackLimit); have > limit {
+					return fmt.Errorf("%w: op %s, have %d, limit %d, pos %d", errEOFStackOverflow, op, have, limit, pos)
+				}
+				if target.outputs == nonReturningFunction {
+					if want, have := int(target.inputs), height; want > have {
+						return fmt.Errorf("%w: op %s, have %d, want %d, pos %d", errEOFStackUnderflow, op, have, want, pos)
+					}
+				} else {
+					// The target returns to our caller, so the stack must leave
+					// exactly the outputs of the current section behind.
+					want := int(metadata[section].outputs) + int(target.inputs) - int(target.outputs)
+					if height != want {
+						return fmt.Errorf("%w: have %d, want %d, pos %d", errInvalidOutputs, height, want, pos)
+					}
+				}
+				break outer
+			case op == RJUMP:
+				pos += 3 + parseInt16(code[pos+1:])
+			case op == RJUMPI:
+				worklist = append(worklist, item{pos: pos + 3 + parseInt16(code[pos+1:]), height: height})
+				pos += 3
+			case op == RJUMPV:
+				cases := int(code[pos+1]) + 1
+				end := pos + 2 + 2*cases
+				for i := 0; i < cases; i++ {
+					worklist = append(worklist, item{pos: end + parseInt16(code[pos+2+2*i:]), height: height})
+				}
+				pos = end
+			case op >= PUSH1 && op <= PUSH32:
+				pos += 1 + int(op-PUSH0)
+			case terminals[op]:
+				break outer
+			default:
+				pos++
+			}
+			if height > maxHeight {
+				maxHeight = height
+			}
+		}
+	}
+	if maxHeight != int(metadata[section].maxStackHeight) {
+		return fmt.Errorf("%w: have %d, want %d", errInvalidMaxStackHeight, maxHeight, metadata[section].maxStackHeight)
+	}
+	if count != len(heights) {
+		return fmt.Errorf("%w: reached %d of %d instructions", errUnreachableCode, len(heights), count)
+	}
+	return nil
+}
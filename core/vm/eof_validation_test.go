@@ -0,0 +1,160 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateCode(t *testing.T) {
+	jt := cancunInstructionSet
+	enableEOF(&jt)
+
+	var (
+		main      = &functionMetadata{inputs: 0, outputs: nonReturningFunction}
+		withStack = func(md *functionMetadata, height uint16) *functionMetadata {
+			cpy := *md
+			cpy.maxStackHeight = height
+			return &cpy
+		}
+	)
+	for i, test := range []struct {
+		code     []byte
+		section  int
+		metadata []*functionMetadata
+		err      error
+	}{
+		{
+			code:     []byte{byte(STOP)},
+			metadata: []*functionMetadata{main},
+		},
+		{
+			code:     []byte{byte(PUSH1), 0x01, byte(POP), byte(STOP)},
+			metadata: []*functionMetadata{withStack(main, 1)},
+		},
+		{
+			code:     []byte{byte(PUSH1), 0x01, byte(STOP)},
+			metadata: []*functionMetadata{main},
+			err:      errInvalidMaxStackHeight,
+		},
+		{
+			code:     []byte{byte(PUSH0), byte(JUMP)},
+			metadata: []*functionMetadata{withStack(main, 1)},
+			err:      errUndefinedInstruction,
+		},
+		{
+			code:     []byte{byte(CALLDATALOAD), byte(STOP)},
+			metadata: []*functionMetadata{main},
+			err:      errEOFStackUnderflow,
+		},
+		{
+			code:     []byte{byte(PUSH1)},
+			metadata: []*functionMetadata{main},
+			err:      errTruncatedImmediate,
+		},
+		{
+			code:     []byte{byte(PUSH0), byte(POP)},
+			metadata: []*functionMetadata{withStack(main, 1)},
+			err:      errInvalidCodeTermination,
+		},
+		{
+			code:     []byte{byte(PUSH0), byte(RJUMPI), 0x00, 0x01, byte(STOP), byte(STOP)},
+			metadata: []*functionMetadata{withStack(main, 1)},
+		},
+		{
+			code:     []byte{byte(RJUMP), 0x00, 0x01, byte(STOP), byte(STOP)},
+			metadata: []*functionMetadata{main},
+			err:      errUnreachableCode,
+		},
+		{
+			code:     []byte{byte(PUSH0), byte(RJUMPI), 0xff, 0xfe, byte(STOP)},
+			metadata: []*functionMetadata{withStack(main, 1)},
+			err:      errInvalidJumpDest,
+		},
+		{
+			code:     []byte{byte(PUSH0), byte(RJUMP), 0xff, 0xfc},
+			metadata: []*functionMetadata{withStack(main, 1)},
+			err:      errConflictingStack,
+		},
+		{
+			code:     []byte{byte(PUSH0), byte(RJUMPV), 0x01, 0x00, 0x00, 0x00, 0x01, byte(STOP), byte(STOP)},
+			metadata: []*functionMetadata{withStack(main, 1)},
+		},
+		{
+			code:    []byte{byte(PUSH1), 0x02, byte(CALLF), 0x00, 0x01, byte(POP), byte(STOP)},
+			section: 0,
+			metadata: []*functionMetadata{
+				withStack(main, 1),
+				{inputs: 1, outputs: 1, maxStackHeight: 2},
+			},
+		},
+		{
+			code:    []byte{byte(DUP1), byte(ADD), byte(RETF)},
+			section: 1,
+			metadata: []*functionMetadata{
+				withStack(main, 1),
+				{inputs: 1, outputs: 1, maxStackHeight: 2},
+			},
+		},
+		{
+			code:    []byte{byte(DUP1), byte(RETF)},
+			section: 1,
+			metadata: []*functionMetadata{
+				withStack(main, 1),
+				{inputs: 1, outputs: 1, maxStackHeight: 2},
+			},
+			err: errInvalidOutputs,
+		},
+		{
+			code:    []byte{byte(CALLF), 0x00, 0x01, byte(STOP)},
+			section: 0,
+			metadata: []*functionMetadata{
+				main,
+				{inputs: 0, outputs: nonReturningFunction},
+			},
+			err: errInvalidCallArgument,
+		},
+		{
+			code:    []byte{byte(CALLF), 0x00, 0x02, byte(STOP)},
+			section: 0,
+			metadata: []*functionMetadata{
+				main,
+				{inputs: 0, outputs: 0},
+			},
+			err: errInvalidSectionArgument,
+		},
+		{
+			code:     []byte{byte(RETF)},
+			metadata: []*functionMetadata{main},
+			err:      errInvalidRetf,
+		},
+		{
+			code:    []byte{byte(JUMPF), 0x00, 0x01},
+			section: 0,
+			metadata: []*functionMetadata{
+				main,
+				{inputs: 0, outputs: nonReturningFunction},
+			},
+		},
+	} {
+		err := validateCode(test.code, test.section, test.metadata, &jt)
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d (%x): unexpected error (want: %v, got: %v)", i, test.code, test.err, err)
+		}
+	}
+}
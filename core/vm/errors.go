@@ -37,6 +37,11 @@ var (
 	ErrGasUintOverflow          = errors.New("gas uint64 overflow")
 	ErrInvalidCode              = errors.New("invalid code: must not begin with 0xef")
 	ErrNonceUintOverflow        = errors.New("nonce uint64 overflow")
+	ErrMemoryLimitExceeded      = errors.New("max memory size exceeded")
+	ErrInvalidEOF               = errors.New("invalid eof code")
+	ErrInvalidEOFInitcode       = errors.New("invalid eof initcode")
+	ErrLegacyCode               = errors.New("eof initcode must deploy eof code")
+	ErrReturnStackExceeded      = errors.New("return stack limit reached")
 
 	// errStopToken is an internal token indicating interpreter loop termination,
 	// never returned to outside callers.
@@ -0,0 +1,170 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import "github.com/holiman/uint256"
+
+// fusedOp identifies a sequence of two instructions which the interpreter executes
+// in a single step of its loop. Fusion is purely an execution strategy: gas, stack
+// validation and errors are identical to running the instructions one by one.
+type fusedOp byte
+
+const (
+	fuseNone      fusedOp = iota
+	fusePushJump          // PUSH1/PUSH2 followed by JUMP
+	fusePushJumpi         // PUSH1/PUSH2 followed by JUMPI
+	fuseDupSwap           // DUPn followed by SWAPm
+	fuseSwapPop           // SWAPn followed by POP
+)
+
+// fusedOps are the instructions taking part in fused sequences.
+var fusedOps = func() []OpCode {
+	ops := []OpCode{PUSH1, PUSH2, JUMP, JUMPI, POP}
+	for op := OpCode(DUP1); op <= DUP16; op++ {
+		ops = append(ops, op)
+	}
+	for op := OpCode(SWAP1); op <= SWAP16; op++ {
+		ops = append(ops, op)
+	}
+	return ops
+}()
+
+// canFuse reports whether the instruction set allows fusion, that is whether all
+// instructions taking part in fused sequences only have a constant gas cost.
+func canFuse(jt *JumpTable) bool {
+	for _, op := range fusedOps {
+		if jt[op].dynamicGas != nil || jt[op].memorySize != nil || jt[op].undefined {
+			return false
+		}
+	}
+	return true
+}
+
+// fusion returns the fused sequence formed by the instructions a and b.
+func fusion(a, b OpCode) fusedOp {
+	switch {
+	case (a == PUSH1 || a == PUSH2) && b == JUMP:
+		return fusePushJump
+	case (a == PUSH1 || a == PUSH2) && b == JUMPI:
+		return fusePushJumpi
+	case a >= DUP1 && a <= DUP16 && b >= SWAP1 && b <= SWAP16:
+		return fuseDupSwap
+	case a >= SWAP1 && a <= SWAP16 && b == POP:
+		return fuseSwapPop
+	}
+	return fuseNone
+}
+
+// fusionAnalysis marks the start of every fused sequence in the code. The second
+// instruction of a sequence is never marked as part of push data, so jumping into
+// the middle of a sequence executes the remaining instruction on its own.
+func fusionAnalysis(code []byte) []fusedOp {
+	fused := make([]fusedOp, len(code))
+	for pc := 0; pc < len(code); {
+		op := OpCode(code[pc])
+		next := pc + 1
+		if op >= PUSH1 && op <= PUSH32 {
+			next += int(op - PUSH1 + 1)
+		}
+		if next < len(code) {
+			fused[pc] = fusion(op, OpCode(code[next]))
+		}
+		pc = next
+	}
+	return fused
+}
+
+// checkFused runs the stack validation and constant gas charge which the
+// interpreter loop performs before executing an instruction.
+func checkFused(operation *operation, stack *Stack, contract *Contract) error {
+	if sLen := stack.len(); sLen < operation.minStack {
+		return &ErrStackUnderflow{stackLen: sLen, required: operation.minStack}
+	} else if sLen > operation.maxStack {
+		return &ErrStackOverflow{stackLen: sLen, limit: operation.maxStack}
+	}
+	if !contract.UseGas(operation.constantGas) {
+		return ErrOutOfGas
+	}
+	return nil
+}
+
+// runFused executes the fused sequence starting at pc, leaving pc at the last
+// instruction executed like the instruction functions do.
+func (in *EVMInterpreter) runFused(kind fusedOp, pc *uint64, scope *ScopeContext) error {
+	var (
+		stack    = scope.Stack
+		contract = scope.Contract
+		code     = contract.Code
+		first    = OpCode(code[*pc])
+	)
+	if err := checkFused(in.table[first], stack, contract); err != nil {
+		return err
+	}
+	switch kind {
+	case fusePushJump, fusePushJumpi:
+		var (
+			size = uint64(first - PUSH1 + 1)
+			next = *pc + 1 + size
+			dest uint256.Int
+		)
+		dest.SetBytes(code[*pc+1 : next])
+
+		// The pushed destination is popped right away, so it only needs to
+		// be accounted for in the stack validation of the jump.
+		stack.push(&dest)
+		err := checkFused(in.table[code[next]], stack, contract)
+		stack.pop()
+		if err != nil {
+			return err
+		}
+		if in.evm.abort.Load() {
+			return errStopToken
+		}
+		if kind == fusePushJumpi {
+			cond := stack.pop()
+			if cond.IsZero() {
+				*pc = next
+				return nil
+			}
+		}
+		if !contract.validJumpdest(&dest) {
+			return ErrInvalidJump
+		}
+		*pc = dest.Uint64() - 1
+		return nil
+
+	case fuseDupSwap:
+		stack.dup(int(first-DUP1) + 1)
+		second := OpCode(code[*pc+1])
+		if err := checkFused(in.table[second], stack, contract); err != nil {
+			return err
+		}
+		stack.swap(int(second-SWAP1) + 2)
+		*pc++
+		return nil
+
+	case fuseSwapPop:
+		stack.swap(int(first-SWAP1) + 2)
+		if err := checkFused(in.table[POP], stack, contract); err != nil {
+			return err
+		}
+		stack.pop()
+		*pc++
+		return nil
+	}
+	panic("unknown fused operation")
+}
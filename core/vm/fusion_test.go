@@ -0,0 +1,139 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/params"
+)
+
+func TestFusionAnalysis(t *testing.T) {
+	code := []byte{
+		byte(PUSH2), byte(JUMP), byte(JUMP), // push data looking like fusable ops
+		byte(JUMPI),
+		byte(DUP3), byte(SWAP2),
+		byte(SWAP1), byte(POP),
+		byte(PUSH1), 0x00, byte(JUMP),
+		byte(PUSH2), 0x00, // truncated push
+	}
+	want := make([]fusedOp, len(code))
+	want[0] = fusePushJumpi
+	want[4] = fuseDupSwap
+	want[6] = fuseSwapPop
+	want[8] = fusePushJump
+
+	have := fusionAnalysis(code)
+	for pc := range code {
+		if have[pc] != want[pc] {
+			t.Errorf("pc %d: fused op mismatch: have %d, want %d", pc, have[pc], want[pc])
+		}
+	}
+}
+
+// TestFusedExecution checks that executing fused instruction sequences yields
+// the same result, gas usage and error as running them one by one, including
+// when running out of gas halfway through a sequence.
+func TestFusedExecution(t *testing.T) {
+	full := make([]byte, 0, 2*1024)
+	for i := 0; i < 1023; i++ {
+		full = append(full, byte(PUSH1), 0x01)
+	}
+	tests := map[string][]byte{
+		"jump": {
+			byte(PUSH1), 0x04, byte(JUMP), byte(INVALID),
+			byte(JUMPDEST), byte(PUSH1), 0x2a, byte(PUSH1), 0x00, byte(MSTORE),
+			byte(PUSH1), 0x20, byte(PUSH1), 0x00, byte(RETURN),
+		},
+		"jump-invalid": {byte(PUSH1), 0x03, byte(JUMP), byte(STOP)},
+		"jump-into-push": {
+			byte(PUSH2), 0x00, 0x04, byte(JUMP), byte(PUSH1), byte(JUMPDEST), byte(STOP),
+		},
+		"jumpi-taken": {
+			byte(PUSH1), 0x01, byte(PUSH2), 0x00, 0x07, byte(JUMPI), byte(INVALID), byte(JUMPDEST), byte(STOP),
+		},
+		"jumpi-not-taken": {
+			byte(PUSH1), 0x00, byte(PUSH2), 0x00, 0x07, byte(JUMPI), byte(STOP), byte(JUMPDEST), byte(INVALID),
+		},
+		"jumpi-underflow": {byte(PUSH1), 0x05, byte(JUMPI), byte(STOP)},
+		"jump-stack-overflow": append(append([]byte{}, full...),
+			byte(PUSH1), 0x01, byte(PUSH1), 0x00, byte(JUMP),
+		),
+		"dup-swap": {
+			byte(PUSH1), 0x01, byte(PUSH1), 0x02, byte(PUSH1), 0x03,
+			byte(DUP3), byte(SWAP3), byte(DUP1), byte(SWAP1),
+			byte(ADD), byte(ADD), byte(ADD), byte(ADD), byte(PUSH1), 0x00, byte(MSTORE),
+			byte(PUSH1), 0x20, byte(PUSH1), 0x00, byte(RETURN),
+		},
+		"dup-underflow":  {byte(PUSH1), 0x01, byte(DUP2), byte(SWAP1)},
+		"swap-underflow": {byte(PUSH1), 0x01, byte(DUP1), byte(SWAP2)},
+		"dup-overflow":   append(append([]byte{}, full...), byte(DUP1), byte(DUP1), byte(SWAP1)),
+		"swap-pop": {
+			byte(PUSH1), 0x01, byte(PUSH1), 0x02, byte(SWAP1), byte(POP),
+			byte(PUSH1), 0x00, byte(MSTORE), byte(PUSH1), 0x20, byte(PUSH1), 0x00, byte(RETURN),
+		},
+		"countdown": {
+			byte(PUSH1), 0x10, byte(JUMPDEST),
+			byte(PUSH1), 0x01, byte(SWAP1), byte(SUB),
+			byte(DUP1), byte(DUP2), byte(SWAP1), byte(POP),
+			byte(PUSH2), 0x00, 0x02, byte(JUMPI),
+		},
+	}
+	for name, code := range tests {
+		// Run with enough gas and with every amount of gas running out halfway
+		for gas := uint64(0); gas <= 1000; gas++ {
+			if gas == 1000 {
+				gas = 1_000_000
+			}
+			ret1, left1, err1 := runFusion(code, gas, false)
+			ret2, left2, err2 := runFusion(code, gas, true)
+			if string(ret1) != string(ret2) || left1 != left2 || !sameError(err1, err2) {
+				t.Fatalf("%s: gas %d: fused execution mismatch: have (%x, %d, %v), want (%x, %d, %v)",
+					name, gas, ret2, left2, err2, ret1, left1, err1)
+			}
+		}
+	}
+}
+
+func sameError(a, b error) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Error() == b.Error()
+}
+
+// runFusion executes code with or without instruction fusion.
+func runFusion(code []byte, gas uint64, fuse bool) ([]byte, uint64, error) {
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	addr := common.HexToAddress("0xc0de")
+	statedb.SetCode(addr, code)
+
+	vmctx := BlockContext{
+		BlockNumber: big.NewInt(0),
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+	}
+	evm := NewEVM(vmctx, TxContext{}, statedb, params.TestChainConfig, Config{})
+	evm.interpreter.fuse = fuse
+
+	return evm.Call(AccountRef(common.Address{}), addr, nil, gas, new(big.Int))
+}
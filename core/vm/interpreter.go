@@ -41,14 +41,17 @@ type ScopeContext struct {
 
 // EVMInterpreter represents an EVM interpreter
 type EVMInterpreter struct {
-	evm   *EVM
-	table *JumpTable
+	evm      *EVM
+	table    *JumpTable
+	eofTable *JumpTable // instruction set of EOF containers, nil before the EOF fork
 
 	hasher    crypto.KeccakState // Keccak256 hasher instance shared across opcodes
 	hasherBuf common.Hash        // Keccak256 hasher result array shared aross opcodes
 
-	readOnly   bool   // Whether to throw on stateful modifications
-	returnData []byte // Last CALL's return data for subsequent reuse
+	readOnly    bool   // Whether to throw on stateful modifications
+	returnData  []byte // Last CALL's return data for subsequent reuse
+	memoryLimit uint64 // Maximum memory size of a call frame, zero if unlimited
+	fuse        bool   // Whether common instruction sequences are executed fused
 }
 
 // NewEVMInterpreter returns a new instance of the Interpreter.
@@ -56,6 +59,8 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 	// If jump table was not initialised we set the default one.
 	var table *JumpTable
 	switch {
+	case evm.chainRules.IsPrague:
+		table = &pragueInstructionSet
 	case evm.chainRules.IsCancun:
 		table = &cancunInstructionSet
 	case evm.chainRules.IsShanghai:
@@ -95,7 +100,36 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 		}
 	}
 	evm.Config.ExtraEips = extraEips
-	return &EVMInterpreter{evm: evm, table: table}
+
+	// Apply the create cost of the chain's gas overrides, if any
+	if cost, ok := evm.chainConfig.GasOverrides.CreateCost(); ok {
+		if len(evm.Config.ExtraEips) == 0 {
+			table = copyJumpTable(table)
+		}
+		for _, op := range []OpCode{CREATE, CREATE2} {
+			if !table[op].undefined {
+				table[op].constantGas = cost
+			}
+		}
+	}
+
+	interpreter := &EVMInterpreter{evm: evm, table: table, memoryLimit: evm.chainConfig.MemoryLimit(), fuse: canFuse(table)}
+	if evm.chainRules.IsEOF {
+		// EOF operations are replaced, never modified, so a shallow copy suffices
+		eofTable := *table
+		enableEOF(&eofTable)
+		interpreter.eofTable = &eofTable
+	}
+	return interpreter
+}
+
+// validateEOF checks that code is a valid EOF container.
+func (in *EVMInterpreter) validateEOF(code []byte) error {
+	var container Container
+	if err := container.UnmarshalBinary(code); err != nil {
+		return err
+	}
+	return container.ValidateCode(in.eofTable)
 }
 
 // Run loops and evaluates the contract's code with the given input data and returns
@@ -105,7 +139,7 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 // considered a revert-and-consume-all-gas operation except for
 // ErrExecutionReverted which means revert-and-keep-gas-left.
 func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (ret []byte, err error) {
-	// Increment the call depth which is restricted to 1024
+	// Increment the call depth which is restricted by the chain config (1024 by default)
 	in.evm.depth++
 	defer func() { in.evm.depth-- }()
 
@@ -145,12 +179,35 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		logged  bool   // deferred EVMLogger should ignore already logged steps
 		res     []byte // result of the opcode execution function
 		debug   = in.evm.Config.Tracer != nil
+		table   = in.table
+		fused   []fusedOp // fused instruction sequences, nil if executing one by one
 	)
+	// Execute EOF containers section by section against the EOF instruction set.
+	// Code with the magic that fails to parse predates the fork and runs as legacy
+	// code, failing on the leading 0xEF.
+	if in.eofTable != nil && HasEOFMagic(contract.Code) {
+		container := new(Container)
+		if err := container.UnmarshalBinary(contract.Code); err == nil {
+			code := contract.Code
+			defer func() { contract.Code = code }()
+
+			table = in.eofTable
+			contract.container = container
+			contract.setSection(0)
+		}
+	}
+	// Tracers observe every instruction, so fused sequences are only executed
+	// as a unit when not tracing. EOF code runs section by section and is never
+	// fused.
+	if in.fuse && !debug && contract.container == nil {
+		fused = contract.fusedOps()
+	}
 	// Don't move this deferred function, it's placed before the capturestate-deferred method,
-	// so that it get's executed _after_: the capturestate needs the stacks before
-	// they are returned to the pools
+	// so that it get's executed _after_: the capturestate needs the stacks and memory
+	// before they are returned to the pools
 	defer func() {
 		returnStack(stack)
+		mem.Free()
 	}()
 	contract.Input = input
 
@@ -174,10 +231,22 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 			// Capture pre-execution values for tracing.
 			logged, pcCopy, gasCopy = false, pc, contract.Gas
 		}
+		// Execute fused instruction sequences in one go, skipping the dispatch
+		// of their second instruction.
+		if pc < uint64(len(fused)) && fused[pc] != fuseNone {
+			if err = in.runFused(fused[pc], &pc, callContext); err != nil {
+				if err == errStopToken {
+					break
+				}
+				return nil, err
+			}
+			pc++
+			continue
+		}
 		// Get the operation from the jump table and validate the stack to ensure there are
 		// enough stack items available to perform the operation.
 		op = contract.GetOp(pc)
-		operation := in.table[op]
+		operation := table[op]
 		cost = operation.constantGas // For tracing
 		// Validate stack
 		if sLen := stack.len(); sLen < operation.minStack {
@@ -185,9 +254,11 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		} else if sLen > operation.maxStack {
 			return nil, &ErrStackOverflow{stackLen: sLen, limit: operation.maxStack}
 		}
-		if !contract.UseGas(cost) {
+		// Charge the constant gas inline, saving a call on every instruction
+		if contract.Gas < cost {
 			return nil, ErrOutOfGas
 		}
+		contract.Gas -= cost
 		if operation.dynamicGas != nil {
 			// All ops with a dynamic memory usage also has a dynamic gas cost.
 			var memorySize uint64
@@ -205,6 +276,10 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 				if memorySize, overflow = math.SafeMul(toWordSize(memSize), 32); overflow {
 					return nil, ErrGasUintOverflow
 				}
+				// Refuse to expand the memory beyond the chain's ceiling, if any
+				if in.memoryLimit != 0 && memorySize > in.memoryLimit {
+					return nil, ErrMemoryLimitExceeded
+				}
 			}
 			// Consume the gas and return an error if not enough gas is available.
 			// cost is explicitly set so that the capture state defer method can get the proper cost
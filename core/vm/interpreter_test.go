@@ -75,3 +75,77 @@ func TestLoopInterrupt(t *testing.T) {
 		}
 	}
 }
+
+func TestCallDepthLimit(t *testing.T) {
+	// Recursive self call, counting the executed frames in slot 0:
+	// sstore(0, sload(0)+1) call(gas, address, 0, 0, 0, 0, 0)
+	code := common.Hex2Bytes("600054600101600055600080808080305af100")
+	address := common.BytesToAddress([]byte("contract"))
+
+	for _, limit := range []uint64{0, 1, 8, params.CallCreateDepth + 100} {
+		config := *params.AllEthashProtocolChanges
+		want := params.CallCreateDepth + 1
+		if limit != 0 {
+			config.MaxCallDepth = &limit
+			want = limit + 1
+		}
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.CreateAccount(address)
+		statedb.SetCode(address, code)
+		statedb.Finalise(true)
+
+		vmctx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: new(big.Int),
+		}
+		evm := NewEVM(vmctx, TxContext{}, statedb, &config, Config{})
+		if _, _, err := evm.Call(AccountRef(common.Address{}), address, nil, 1<<60, new(big.Int)); err != nil {
+			t.Fatalf("limit %d: call failed: %v", limit, err)
+		}
+		if have := statedb.GetState(address, common.Hash{}).Big().Uint64(); have != want {
+			t.Errorf("limit %d: executed frames mismatch: have %d, want %d", limit, have, want)
+		}
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	address := common.BytesToAddress([]byte("contract"))
+	limit := uint64(1024)
+
+	tests := []struct {
+		code string
+		err  error
+	}{
+		// mstore(992, 1): expands the memory up to the limit
+		{"60016103e05200", nil},
+		// mstore(1024, 1): expands the memory beyond the limit
+		{"60016104005200", ErrMemoryLimitExceeded},
+		// calldatacopy(0, 0, 1025): expands the memory beyond the limit
+		{"610401600060003700", ErrMemoryLimitExceeded},
+	}
+	for i, tt := range tests {
+		config := *params.AllEthashProtocolChanges
+		config.MaxMemorySize = &limit
+
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.CreateAccount(address)
+		statedb.SetCode(address, common.Hex2Bytes(tt.code))
+		statedb.Finalise(true)
+
+		vmctx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: new(big.Int),
+		}
+		evm := NewEVM(vmctx, TxContext{}, statedb, &config, Config{})
+		_, gas, err := evm.Call(AccountRef(common.Address{}), address, nil, 100000, new(big.Int))
+		if err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+		// Exceeding the memory limit should consume all gas
+		if tt.err != nil && gas != 0 {
+			t.Errorf("test %d: gas left after failure: %d", i, gas)
+		}
+	}
+}
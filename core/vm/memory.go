@@ -17,9 +17,21 @@
 package vm
 
 import (
+	"sync"
+
 	"github.com/holiman/uint256"
 )
 
+var memoryPool = sync.Pool{
+	New: func() interface{} {
+		return &Memory{}
+	},
+}
+
+// maxPooledMemory is the size of the largest memory returned to the pool, larger
+// ones are left to the garbage collector to avoid pinning their allocation.
+const maxPooledMemory = 16 * 1024
+
 // Memory implements a simple memory model for the ethereum virtual machine.
 type Memory struct {
 	store       []byte
@@ -28,7 +40,18 @@ type Memory struct {
 
 // NewMemory returns a new memory model.
 func NewMemory() *Memory {
-	return &Memory{}
+	return memoryPool.Get().(*Memory)
+}
+
+// Free returns the memory to the pool. It must not be used afterwards, nor may any
+// slices obtained from GetPtr or Data be retained.
+func (m *Memory) Free() {
+	if cap(m.store) > maxPooledMemory {
+		return
+	}
+	m.store = m.store[:0]
+	m.lastGasCost = 0
+	memoryPool.Put(m)
 }
 
 // Set sets offset + size to value
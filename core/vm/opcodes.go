@@ -208,6 +208,16 @@ const (
 	LOG4
 )
 
+// 0xe0 range - EOF control flow.
+const (
+	RJUMP  OpCode = 0xe0
+	RJUMPI OpCode = 0xe1
+	RJUMPV OpCode = 0xe2
+	CALLF  OpCode = 0xe3
+	RETF   OpCode = 0xe4
+	JUMPF  OpCode = 0xe5
+)
+
 // 0xf0 range - closures.
 const (
 	CREATE       OpCode = 0xf0
@@ -383,6 +393,14 @@ var opCodeToString = map[OpCode]string{
 	LOG3: "LOG3",
 	LOG4: "LOG4",
 
+	// 0xe0 range - EOF control flow.
+	RJUMP:  "RJUMP",
+	RJUMPI: "RJUMPI",
+	RJUMPV: "RJUMPV",
+	CALLF:  "CALLF",
+	RETF:   "RETF",
+	JUMPF:  "JUMPF",
+
 	// 0xf0 range - closures.
 	CREATE:       "CREATE",
 	CALL:         "CALL",
@@ -546,6 +564,12 @@ var stringToOp = map[string]OpCode{
 	"LOG2":           LOG2,
 	"LOG3":           LOG3,
 	"LOG4":           LOG4,
+	"RJUMP":          RJUMP,
+	"RJUMPI":         RJUMPI,
+	"RJUMPV":         RJUMPV,
+	"CALLF":          CALLF,
+	"RETF":           RETF,
+	"JUMPF":          JUMPF,
 	"CREATE":         CREATE,
 	"CREATE2":        CREATE2,
 	"CALL":           CALL,
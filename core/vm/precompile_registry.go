@@ -0,0 +1,146 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/params"
+)
+
+// ErrUnknownPrecompile is returned if a chain config schedules a precompile whose
+// implementation was not registered.
+var ErrUnknownPrecompile = errors.New("unknown precompile")
+
+// PrecompiledContracts maps addresses to the precompiled contracts living there.
+type PrecompiledContracts map[common.Address]PrecompiledContract
+
+var (
+	// precompileRegistry holds the implementations of the precompiles which chain
+	// configs can schedule at custom addresses, keyed by name.
+	precompileRegistry = map[string]PrecompiledContract{
+		"bls12381G1Add":      &bls12381G1Add{},
+		"bls12381G1MultiExp": &bls12381G1MultiExp{},
+		"bls12381G2Add":      &bls12381G2Add{},
+		"bls12381G2MultiExp": &bls12381G2MultiExp{},
+		"bls12381Pairing":    &bls12381Pairing{},
+		"bls12381MapG1":      &bls12381MapG1{},
+		"bls12381MapG2":      &bls12381MapG2{},
+		"p256Verify":         &p256Verify{},
+	}
+	precompileRegistryLock sync.RWMutex
+)
+
+// RegisterPrecompile makes a precompiled contract implementation available under
+// the given name, so that chain configs can activate it at an address. It is meant
+// to be called by embedding applications during initialization, before any chain
+// referencing the precompile is loaded.
+func RegisterPrecompile(name string, contract PrecompiledContract) error {
+	precompileRegistryLock.Lock()
+	defer precompileRegistryLock.Unlock()
+
+	if _, ok := precompileRegistry[name]; ok {
+		return fmt.Errorf("precompile %q already registered", name)
+	}
+	precompileRegistry[name] = contract
+	return nil
+}
+
+// registeredPrecompile retrieves a precompile implementation by name.
+func registeredPrecompile(name string) (PrecompiledContract, bool) {
+	precompileRegistryLock.RLock()
+	defer precompileRegistryLock.RUnlock()
+
+	contract, ok := precompileRegistry[name]
+	return contract, ok
+}
+
+// CheckPrecompiles verifies that the custom precompiles scheduled by the chain
+// config are well formed and that all their implementations are registered.
+func CheckPrecompiles(config *params.ChainConfig) error {
+	if err := config.CheckPrecompiles(); err != nil {
+		return err
+	}
+	for _, p := range config.Precompiles {
+		if _, ok := registeredPrecompile(p.Name); !ok {
+			return fmt.Errorf("%w %q at %v", ErrUnknownPrecompile, p.Name, p.Address)
+		}
+	}
+	return nil
+}
+
+// standardPrecompiles returns the standard set of precompiles of the fork the
+// rules belong to, along with their addresses.
+func standardPrecompiles(rules params.Rules) (PrecompiledContracts, []common.Address) {
+	switch {
+	case rules.IsPrague:
+		return PrecompiledContractsPrague, PrecompiledAddressesPrague
+	case rules.IsCancun:
+		return PrecompiledContractsCancun, PrecompiledAddressesCancun
+	case rules.IsBerlin:
+		return PrecompiledContractsBerlin, PrecompiledAddressesBerlin
+	case rules.IsIstanbul:
+		return PrecompiledContractsIstanbul, PrecompiledAddressesIstanbul
+	case rules.IsByzantium:
+		return PrecompiledContractsByzantium, PrecompiledAddressesByzantium
+	default:
+		return PrecompiledContractsHomestead, PrecompiledAddressesHomestead
+	}
+}
+
+// ActivePrecompiledContracts returns the precompiles enabled with the current
+// configuration: the standard set of the fork, extended or overridden by the
+// custom precompiles active in the chain config.
+func ActivePrecompiledContracts(rules params.Rules) PrecompiledContracts {
+	standard, _ := standardPrecompiles(rules)
+	if len(rules.Precompiles) == 0 {
+		return standard
+	}
+	active := make(PrecompiledContracts, len(standard)+len(rules.Precompiles))
+	for addr, contract := range standard {
+		active[addr] = contract
+	}
+	// Unknown implementations are rejected when the chain config is loaded, so
+	// all of them are expected to be present.
+	for addr, name := range rules.Precompiles {
+		if contract, ok := registeredPrecompile(name); ok {
+			active[addr] = contract
+		}
+	}
+	return active
+}
+
+// ActivePrecompiles returns the addresses of the precompiles enabled with the
+// current configuration.
+func ActivePrecompiles(rules params.Rules) []common.Address {
+	standard, addrs := standardPrecompiles(rules)
+	if len(rules.Precompiles) == 0 {
+		return addrs
+	}
+	custom := make([]common.Address, 0, len(rules.Precompiles))
+	for addr := range rules.Precompiles {
+		if _, ok := standard[addr]; !ok {
+			custom = append(custom, addr)
+		}
+	}
+	sort.Slice(custom, func(i, j int) bool { return custom[i].Cmp(custom[j]) < 0 })
+	return append(append([]common.Address{}, addrs...), custom...)
+}
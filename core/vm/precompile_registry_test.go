@@ -0,0 +1,172 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/params"
+)
+
+// echoPrecompile is a custom precompile returning its input.
+type echoPrecompile struct{}
+
+func (echoPrecompile) RequiredGas(input []byte) uint64  { return 10 }
+func (echoPrecompile) Run(input []byte) ([]byte, error) { return input, nil }
+
+func TestCustomPrecompiles(t *testing.T) {
+	if err := RegisterPrecompile("test-echo", echoPrecompile{}); err != nil {
+		t.Fatalf("failed to register precompile: %v", err)
+	}
+	if err := RegisterPrecompile("test-echo", echoPrecompile{}); err == nil {
+		t.Fatal("duplicate precompile registered")
+	}
+	var (
+		echo     = common.HexToAddress("0x0100")
+		override = common.BytesToAddress([]byte{2}) // sha256
+		config   = *params.AllEthashProtocolChanges
+	)
+	config.Precompiles = []params.PrecompileConfig{
+		{Address: echo, Name: "test-echo", Block: big.NewInt(10)},
+		{Address: override, Name: "test-echo", Block: big.NewInt(20)},
+	}
+	if err := CheckPrecompiles(&config); err != nil {
+		t.Fatalf("valid precompiles rejected: %v", err)
+	}
+	unknown := config
+	unknown.Precompiles = []params.PrecompileConfig{{Address: echo, Name: "test-missing"}}
+	if err := CheckPrecompiles(&unknown); !errors.Is(err, ErrUnknownPrecompile) {
+		t.Fatalf("wrong error for unknown precompile: have %v, want %v", err, ErrUnknownPrecompile)
+	}
+	// Check the activation of the precompiles and their addresses
+	for _, tt := range []struct {
+		block    int64
+		echo     bool
+		override bool
+	}{
+		{9, false, false},
+		{10, true, false},
+		{20, true, true},
+	} {
+		rules := config.Rules(big.NewInt(tt.block), false, 0)
+		addrs := ActivePrecompiles(rules)
+		if have := contains(addrs, echo); have != tt.echo {
+			t.Errorf("block %d: echo precompile address listed %v, want %v", tt.block, have, tt.echo)
+		}
+		want := len(PrecompiledAddressesBerlin)
+		if tt.echo {
+			want++ // The sha256 override doesn't add an address
+		}
+		if len(addrs) != want {
+			t.Errorf("block %d: wrong number of precompile addresses: have %d, want %d", tt.block, len(addrs), want)
+		}
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		vmctx := BlockContext{
+			BlockNumber: big.NewInt(tt.block),
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		}
+		evm := NewEVM(vmctx, TxContext{}, statedb, &config, Config{})
+
+		input := []byte("hello")
+		ret, _, err := evm.Call(AccountRef(common.Address{}), echo, input, 100000, new(big.Int))
+		if err != nil {
+			t.Fatalf("block %d: call failed: %v", tt.block, err)
+		}
+		if have := bytes.Equal(ret, input); have != tt.echo {
+			t.Errorf("block %d: echo precompile active %v, want %v", tt.block, have, tt.echo)
+		}
+		ret, _, err = evm.Call(AccountRef(common.Address{}), override, input, 100000, new(big.Int))
+		if err != nil {
+			t.Fatalf("block %d: call failed: %v", tt.block, err)
+		}
+		if have := bytes.Equal(ret, input); have != tt.override {
+			t.Errorf("block %d: sha256 override active %v, want %v", tt.block, have, tt.override)
+		}
+	}
+}
+
+func contains(addrs []common.Address, addr common.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPraguePrecompiles(t *testing.T) {
+	var (
+		cancun = uint64(0)
+		prague = uint64(100)
+		config = *params.AllEthashProtocolChanges
+	)
+	config.ShanghaiTime = &cancun
+	config.CancunTime = &cancun
+	config.PragueTime = &prague
+
+	bls := map[byte]string{
+		0x0b: "blsG1Add",
+		0x0c: "blsG1MultiExp",
+		0x0d: "blsG2Add",
+		0x0e: "blsG2MultiExp",
+		0x0f: "blsPairing",
+		0x10: "blsMapG1",
+		0x11: "blsMapG2",
+	}
+	// Before Prague the BLS precompiles must not be active
+	rules := config.Rules(big.NewInt(0), true, prague-1)
+	for id := range bls {
+		if _, ok := ActivePrecompiledContracts(rules)[common.BytesToAddress([]byte{id})]; ok {
+			t.Errorf("precompile %#x active before Prague", id)
+		}
+	}
+	if have, want := len(ActivePrecompiles(rules)), len(PrecompiledAddressesCancun); have != want {
+		t.Errorf("wrong number of Cancun precompiles: have %d, want %d", have, want)
+	}
+	// From Prague on they must be active at their EIP-2537 addresses
+	rules = config.Rules(big.NewInt(0), true, prague)
+	if have, want := len(ActivePrecompiles(rules)), len(PrecompiledAddressesCancun)+len(bls); have != want {
+		t.Errorf("wrong number of Prague precompiles: have %d, want %d", have, want)
+	}
+	for id, name := range bls {
+		p, ok := ActivePrecompiledContracts(rules)[common.BytesToAddress([]byte{id})]
+		if !ok {
+			t.Errorf("precompile %#x not active in Prague", id)
+			continue
+		}
+		tests, err := loadJson(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		test := tests[0]
+		in := common.Hex2Bytes(test.Input)
+		res, _, err := RunPrecompiledContract(p, in, p.RequiredGas(in))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if common.Bytes2Hex(res) != test.Expected {
+			t.Errorf("%s: wrong result: have %x, want %s", name, res, test.Expected)
+		}
+	}
+}
@@ -17,6 +17,8 @@
 package runtime
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
@@ -469,6 +471,20 @@ func BenchmarkSimpleLoop(b *testing.B) {
 		byte(vm.JUMP),
 	}
 
+	countdownLoop := []byte{
+		byte(vm.PUSH4), 0xff, 0xff, 0xff, 0xff, // [ count ]
+		byte(vm.JUMPDEST), // [ count ]
+		byte(vm.PUSH1), 1, // [ count, 1 ]
+		byte(vm.SWAP1),             // [ 1, count ]
+		byte(vm.SUB),               // [ count-1 ]
+		byte(vm.DUP1),              // [ count-1, count-1 ]
+		byte(vm.DUP2),              // [ count-1, count-1, count-1 ]
+		byte(vm.SWAP1),             // [ count-1, count-1, count-1 ]
+		byte(vm.POP),               // [ count-1, count-1 ]
+		byte(vm.PUSH2), 0x00, 0x05, // jumpdestination
+		byte(vm.JUMPI),
+	}
+
 	//tracer := logger.NewJSONLogger(nil, os.Stdout)
 	//Execute(loopingCode, nil, &Config{
 	//	EVMConfig: vm.Config{
@@ -482,6 +498,7 @@ func BenchmarkSimpleLoop(b *testing.B) {
 	benchmarkNonModifyingCode(100000000, callInexistant, "call-nonexist-100M", "", b)
 	benchmarkNonModifyingCode(100000000, callEOA, "call-EOA-100M", "", b)
 	benchmarkNonModifyingCode(100000000, callRevertingContractWithInput, "call-reverting-100M", "", b)
+	benchmarkNonModifyingCode(100000000, countdownLoop, "countdown-100M", "", b)
 
 	//benchmarkNonModifyingCode(10000000, staticCallIdentity, "staticcall-identity-10M", b)
 	//benchmarkNonModifyingCode(10000000, loopingCode, "loop-10M", b)
@@ -912,3 +929,107 @@ func BenchmarkTracerStepVsCallFrame(b *testing.B) {
 	benchmarkNonModifyingCode(10000000, code, "tracer-step-10M", stepTracer, b)
 	benchmarkNonModifyingCode(10000000, code, "tracer-call-frame-10M", callFrameTracer, b)
 }
+
+func TestEOFExecution(t *testing.T) {
+	// Container with two code sections: the first calls the second, which doubles
+	// its input, and returns the result.
+	container := common.FromHex("ef0001010008020002000b000303000000008000020101000260" +
+		"15e300015f5260205ff38001e4")
+
+	eofConfig := func() *Config {
+		shanghai, eof := uint64(0), uint64(0)
+		return &Config{
+			ChainConfig: &params.ChainConfig{
+				ChainID:             big.NewInt(1),
+				HomesteadBlock:      new(big.Int),
+				EIP150Block:         new(big.Int),
+				EIP155Block:         new(big.Int),
+				EIP158Block:         new(big.Int),
+				ByzantiumBlock:      new(big.Int),
+				ConstantinopleBlock: new(big.Int),
+				PetersburgBlock:     new(big.Int),
+				IstanbulBlock:       new(big.Int),
+				MuirGlacierBlock:    new(big.Int),
+				BerlinBlock:         new(big.Int),
+				LondonBlock:         new(big.Int),
+				ShanghaiTime:        &shanghai,
+				EOFTime:             &eof,
+			},
+		}
+	}
+	ret, _, err := Execute(container, nil, eofConfig())
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if num := new(big.Int).SetBytes(ret); num.Cmp(big.NewInt(0x2a)) != 0 {
+		t.Errorf("Expected 42, got %v", num)
+	}
+	// Before the fork, the container is plain invalid code
+	if _, _, err := Execute(container, nil, nil); err == nil {
+		t.Error("expected EOF code to fail before the fork")
+	}
+
+	// Deploy the container from legacy initcode
+	deployer := func(code []byte) []byte {
+		return append([]byte{
+			byte(vm.PUSH1), byte(len(code)),
+			byte(vm.PUSH1), 10,
+			byte(vm.PUSH0),
+			byte(vm.CODECOPY),
+			byte(vm.PUSH1), byte(len(code)),
+			byte(vm.PUSH0),
+			byte(vm.RETURN),
+		}, code...)
+	}
+	cfg := eofConfig()
+	_, address, _, err := Create(deployer(container), cfg)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if code := cfg.State.GetCode(address); !bytes.Equal(code, container) {
+		t.Fatalf("wrong deployed code: have %x, want %x", code, container)
+	}
+	// Invalid containers must be rejected on deployment
+	invalid := common.CopyBytes(container)
+	invalid[len(invalid)-1] = byte(vm.STOP)
+	invalid[len(invalid)-3] = byte(vm.POP)
+	if _, _, _, err := Create(deployer(invalid), eofConfig()); !errors.Is(err, vm.ErrInvalidEOF) {
+		t.Fatalf("wrong error: have %v, want %v", err, vm.ErrInvalidEOF)
+	}
+}
+
+func TestGasOverrides(t *testing.T) {
+	code := []byte{
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.POP), // cold SLOAD
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), // set warm slot
+		byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.CREATE), // empty CREATE
+	}
+	gasUsed := func(overrides *params.GasOverrides) uint64 {
+		cfg := new(Config)
+		setDefaults(cfg)
+		chainConfig := *cfg.ChainConfig
+		chainConfig.GasOverrides = overrides
+		if err := chainConfig.CheckGasOverrides(); err != nil {
+			t.Fatal(err)
+		}
+		cfg.ChainConfig = &chainConfig
+		cfg.State, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+		address := common.HexToAddress("0xc0de")
+		cfg.State.SetCode(address, code)
+		_, left, err := Call(address, nil, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg.GasLimit - left
+	}
+	var (
+		cold, set, create = uint64(3000), uint64(40000), uint64(50000)
+		base              = gasUsed(nil)
+		overridden        = gasUsed(&params.GasOverrides{ColdSload: &cold, SstoreSet: &set, Create: &create})
+		want              = base + (cold - params.ColdSloadCostEIP2929) + (set - params.SstoreSetGasEIP2200) + (create - params.CreateGas)
+	)
+	if overridden != want {
+		t.Fatalf("wrong gas used with overrides: have %d, want %d (default %d)", overridden, want, base)
+	}
+}
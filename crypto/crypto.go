@@ -115,6 +115,19 @@ func CreateAddress2(b common.Address, salt [32]byte, inithash []byte) common.Add
 	return common.BytesToAddress(Keccak256([]byte{0xff}, b.Bytes(), salt[:], inithash)[12:])
 }
 
+// create3ProxyCodeHash is the hash of the init code of the minimal proxy used by
+// the CREATE3 pattern (0x67363d3d37363d34f03d5260086018f3), which deploys the
+// code passed in its calldata via CREATE.
+var create3ProxyCodeHash = common.HexToHash("0x21c35dbe1b344a2488cf3321d6ce542f8e9f305544ff09e4993a62319a497c1f")
+
+// CreateAddress3 creates an ethereum address given the address bytes and a salt,
+// following the CREATE3 pattern: a proxy is deployed via CREATE2 with the salt,
+// which then deploys the contract via CREATE as its first transaction. The address
+// is thus independent of the contract's init code.
+func CreateAddress3(b common.Address, salt [32]byte) common.Address {
+	return CreateAddress(CreateAddress2(b, salt, create3ProxyCodeHash[:]), 1)
+}
+
 // ToECDSA creates a private key with the given D value.
 func ToECDSA(d []byte) (*ecdsa.PrivateKey, error) {
 	return toECDSA(d, true)
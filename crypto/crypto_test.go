@@ -145,6 +145,41 @@ func TestNewContractAddress(t *testing.T) {
 	checkAddr(t, common.HexToAddress("c9ddedf451bc62ce88bf9292afb13df35b670699"), caddr2)
 }
 
+func TestNewContractAddress2(t *testing.T) {
+	// Test vectors from EIP-1014
+	tests := []struct {
+		deployer string
+		salt     string
+		code     string
+		want     string
+	}{
+		{"0x0000000000000000000000000000000000000000", "0x0000000000000000000000000000000000000000000000000000000000000000", "0x00", "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x000000000000000000000000feed000000000000000000000000000000000000", "0x00", "0xD04116cDd17beBE565EB2422F2497E06cC1C9833"},
+		{"0x00000000000000000000000000000000deadbeef", "0x00000000000000000000000000000000000000000000000000000000cafebabe", "0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef", "0x1d8bfDC5D46DC4f61D6b6115972536eBE6A8854C"},
+		{"0x0000000000000000000000000000000000000000", "0x0000000000000000000000000000000000000000000000000000000000000000", "0x", "0xE33C0C7F7df4809055C3ebA6c09CFe4BaF1BD9e0"},
+	}
+	for i, tt := range tests {
+		have := CreateAddress2(common.HexToAddress(tt.deployer), common.HexToHash(tt.salt), Keccak256(common.FromHex(tt.code)))
+		if want := common.HexToAddress(tt.want); have != want {
+			t.Errorf("test %d: address mismatch: have %x, want %x", i, have, want)
+		}
+	}
+}
+
+func TestNewContractAddress3(t *testing.T) {
+	if have := Keccak256Hash(common.FromHex("0x67363d3d37363d34f03d5260086018f3")); have != create3ProxyCodeHash {
+		t.Fatalf("proxy code hash mismatch: have %x, want %x", have, create3ProxyCodeHash)
+	}
+	var (
+		deployer = common.HexToAddress(testAddrHex)
+		salt     = common.HexToHash("0x01")
+		proxy    = CreateAddress2(deployer, salt, create3ProxyCodeHash[:])
+	)
+	if have, want := CreateAddress3(deployer, salt), CreateAddress(proxy, 1); have != want {
+		t.Fatalf("address mismatch: have %x, want %x", have, want)
+	}
+}
+
 func TestLoadECDSA(t *testing.T) {
 	tests := []struct {
 		input string
@@ -0,0 +1,60 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package secp256r1
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("hello"))
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y := key.PublicKey.X, key.PublicKey.Y
+
+	if !Verify(hash[:], r, s, x, y) {
+		t.Fatal("valid signature rejected")
+	}
+	other := sha256.Sum256([]byte("world"))
+	if Verify(other[:], r, s, x, y) {
+		t.Error("signature of other message accepted")
+	}
+	if Verify(hash[:], r, s, x, new(big.Int).Add(y, big.NewInt(1))) {
+		t.Error("public key off the curve accepted")
+	}
+	if Verify(hash[:], r, s, new(big.Int), new(big.Int)) {
+		t.Error("point at infinity accepted")
+	}
+	n := elliptic.P256().Params().N
+	if Verify(hash[:], new(big.Int).Add(r, n), s, x, y) {
+		t.Error("out of range r accepted")
+	}
+	if Verify(hash[:], r, new(big.Int), x, y) {
+		t.Error("zero s accepted")
+	}
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/eth/ethconfig"
 	"github.com/gorievm/go-gori/rlp"
 )
 
@@ -137,3 +138,17 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	}
 	return true, nil
 }
+
+// TxPropagation returns the transaction propagation policy in effect.
+func (api *AdminAPI) TxPropagation() ethconfig.TxPropagationConfig {
+	return api.eth.handler.TxPropagation()
+}
+
+// SetTxPropagation replaces the transaction propagation policy: the number of
+// peers transactions are sent to directly, the size above which they are only
+// announced and the per-peer send rate. The change applies to the connected
+// peers too, but is not persisted across restarts.
+func (api *AdminAPI) SetTxPropagation(policy ethconfig.TxPropagationConfig) bool {
+	api.eth.handler.SetTxPropagation(policy)
+	return true
+}
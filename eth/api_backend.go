@@ -28,6 +28,7 @@ import (
 	"github.com/gorievm/go-gori/consensus"
 	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/bloombits"
+	"github.com/gorievm/go-gori/core/filtermaps"
 	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/core/state"
 	"github.com/gorievm/go-gori/core/txpool"
@@ -155,11 +156,32 @@ func (b *EthAPIBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumbe
 		}
 		return b.eth.blockchain.GetBlock(header.Hash(), header.Number.Uint64()), nil
 	}
-	return b.eth.blockchain.GetBlockByNumber(uint64(number)), nil
+	block := b.eth.blockchain.GetBlockByNumber(uint64(number))
+	if block == nil && uint64(number) < b.HistoryPruningCutoff() {
+		return nil, &core.PrunedHistoryError{}
+	}
+	return block, nil
 }
 
 func (b *EthAPIBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
-	return b.eth.blockchain.GetBlockByHash(hash), nil
+	block := b.eth.blockchain.GetBlockByHash(hash)
+	if block == nil && b.isPruned(hash) {
+		return nil, &core.PrunedHistoryError{}
+	}
+	return block, nil
+}
+
+// isPruned returns whether the block with the given hash is known, but its body
+// and receipts were dropped by history expiry.
+func (b *EthAPIBackend) isPruned(hash common.Hash) bool {
+	number := rawdb.ReadHeaderNumber(b.eth.chainDb, hash)
+	return number != nil && *number < b.HistoryPruningCutoff()
+}
+
+// HistoryPruningCutoff returns the number of the first block whose body and
+// receipts are available.
+func (b *EthAPIBackend) HistoryPruningCutoff() uint64 {
+	return b.eth.blockchain.HistoryPruningCutoff()
 }
 
 // GetBody returns body of a block. It does not resolve special block numbers.
@@ -170,6 +192,9 @@ func (b *EthAPIBackend) GetBody(ctx context.Context, hash common.Hash, number rp
 	if body := b.eth.blockchain.GetBody(hash); body != nil {
 		return body, nil
 	}
+	if uint64(number) < b.HistoryPruningCutoff() {
+		return nil, &core.PrunedHistoryError{}
+	}
 	return nil, errors.New("block body not found")
 }
 
@@ -187,6 +212,9 @@ func (b *EthAPIBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash r
 		}
 		block := b.eth.blockchain.GetBlock(hash, header.Number.Uint64())
 		if block == nil {
+			if header.Number.Uint64() < b.HistoryPruningCutoff() {
+				return nil, &core.PrunedHistoryError{}
+			}
 			return nil, errors.New("header found, but block body is missing")
 		}
 		return block, nil
@@ -241,11 +269,19 @@ func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockN
 }
 
 func (b *EthAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
-	return b.eth.blockchain.GetReceiptsByHash(hash), nil
+	receipts := b.eth.blockchain.GetReceiptsByHash(hash)
+	if receipts == nil && b.isPruned(hash) {
+		return nil, &core.PrunedHistoryError{}
+	}
+	return receipts, nil
 }
 
 func (b *EthAPIBackend) GetLogs(ctx context.Context, hash common.Hash, number uint64) ([][]*types.Log, error) {
-	return rawdb.ReadLogs(b.eth.chainDb, hash, number, b.ChainConfig()), nil
+	logs := rawdb.ReadLogs(b.eth.chainDb, hash, number, b.ChainConfig())
+	if logs == nil && number < b.HistoryPruningCutoff() {
+		return nil, &core.PrunedHistoryError{}
+	}
+	return logs, nil
 }
 
 func (b *EthAPIBackend) GetTd(ctx context.Context, hash common.Hash) *big.Int {
@@ -297,6 +333,14 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.eth.txPool.Add([]*txpool.Transaction{{Tx: signedTx}}, true, false)[0]
 }
 
+func (b *EthAPIBackend) SendBundle(ctx context.Context, bundle *txpool.Bundle) error {
+	return b.eth.txPool.AddBundle(bundle)
+}
+
+func (b *EthAPIBackend) SendConditionalTx(ctx context.Context, signedTx *types.Transaction, cond txpool.Condition) error {
+	return b.eth.txPool.AddConditional(&txpool.Transaction{Tx: signedTx}, cond)
+}
+
 func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending := b.eth.txPool.Pending(false)
 	var txs types.Transactions
@@ -338,6 +382,10 @@ func (b *EthAPIBackend) TxPoolContentFrom(addr common.Address) ([]*types.Transac
 	return b.eth.txPool.ContentFrom(addr)
 }
 
+func (b *EthAPIBackend) TxPoolInspectFrom(ctx context.Context, addr common.Address) (*txpool.SenderInspection, error) {
+	return b.eth.txPool.InspectFrom(addr), nil
+}
+
 func (b *EthAPIBackend) TxPool() *txpool.TxPool {
 	return b.eth.txPool
 }
@@ -346,6 +394,10 @@ func (b *EthAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.S
 	return b.eth.txPool.SubscribeNewTxsEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeDropTxsEvent(ch chan<- core.DropTxsEvent) event.Subscription {
+	return b.eth.txPool.SubscribeDropTxsEvent(ch)
+}
+
 func (b *EthAPIBackend) SyncProgress() ethereum.SyncProgress {
 	return b.eth.Downloader().Progress()
 }
@@ -386,12 +438,24 @@ func (b *EthAPIBackend) RPCEVMTimeout() time.Duration {
 	return b.eth.config.RPCEVMTimeout
 }
 
+func (b *EthAPIBackend) RPCWarmQuota() uint64 {
+	return b.eth.config.RPCWarmQuota
+}
+
 func (b *EthAPIBackend) RPCTxFeeCap() float64 {
 	return b.eth.config.RPCTxFeeCap
 }
 
+func (b *EthAPIBackend) RPCExtendedReceipts() bool {
+	return b.eth.config.RPCExtendedReceipts
+}
+
 func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
-	sections, _, _ := b.eth.bloomIndexer.Sections()
+	indexer := b.eth.BloomIndexer()
+	if indexer == nil {
+		return params.BloomBitsBlocks, 0 // Superseded by the log index
+	}
+	sections, _, _ := indexer.Sections()
 	return params.BloomBitsBlocks, sections
 }
 
@@ -401,6 +465,10 @@ func (b *EthAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.Ma
 	}
 }
 
+func (b *EthAPIBackend) LogIndex() *filtermaps.FilterMaps {
+	return b.eth.logIndex
+}
+
 func (b *EthAPIBackend) Engine() consensus.Engine {
 	return b.eth.engine
 }
@@ -18,14 +18,19 @@ package eth
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"os"
 	"time"
 
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/txpool"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/internal/ethapi"
 	"github.com/gorievm/go-gori/log"
@@ -128,11 +133,63 @@ func (api *DebugAPI) GetBadBlocks(ctx context.Context) ([]*BadBlockArgs, error)
 	return results, nil
 }
 
+// BadBlockExport is the content of a bad block export, holding everything
+// needed to reproduce the failure of the block offline.
+type BadBlockExport struct {
+	Hash   common.Hash          `json:"hash"`
+	RLP    hexutil.Bytes        `json:"rlp"`
+	Report *core.BadBlockReport `json:"report"`
+}
+
+// GetBadBlockReport returns the diagnostics recorded when the bad block with
+// the given hash was rejected: the error, the receipts and state changes made
+// before the failure and, if available, the execution witness of the block.
+func (api *DebugAPI) GetBadBlockReport(hash common.Hash) (*core.BadBlockReport, error) {
+	if rawdb.ReadBadBlock(api.eth.chainDb, hash) == nil {
+		return nil, fmt.Errorf("bad block %#x not found", hash)
+	}
+	report := api.eth.blockchain.BadBlockReport(hash)
+	if report == nil {
+		return nil, fmt.Errorf("no report for bad block %#x", hash)
+	}
+	return report, nil
+}
+
+// ExportBadBlock writes the bad block with the given hash along with its report
+// into a file in the temporary directory, returning the path of the file.
+func (api *DebugAPI) ExportBadBlock(hash common.Hash) (string, error) {
+	block := rawdb.ReadBadBlock(api.eth.chainDb, hash)
+	if block == nil {
+		return "", fmt.Errorf("bad block %#x not found", hash)
+	}
+	enc, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return "", err
+	}
+	export := &BadBlockExport{
+		Hash:   hash,
+		RLP:    enc,
+		Report: api.eth.blockchain.BadBlockReport(hash),
+	}
+	file, err := os.CreateTemp(os.TempDir(), fmt.Sprintf("badblock_%#x-", hash.Bytes()[:4]))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := json.NewEncoder(file).Encode(export); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
 // AccountRangeMaxResults is the maximum number of results to be returned per call
 const AccountRangeMaxResults = 256
 
-// AccountRange enumerates all accounts in the given block and start point in paging request
-func (api *DebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, start hexutil.Bytes, maxResults int, nocode, nostorage, incompletes bool) (state.IteratorDump, error) {
+// stateAt returns the state of the given block, the pending state is served by
+// the miner.
+func (api *DebugAPI) stateAt(blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, error) {
 	var stateDb *state.StateDB
 	var err error
 
@@ -143,7 +200,7 @@ func (api *DebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, start hex
 			// the miner and operate on those
 			_, stateDb = api.eth.miner.Pending()
 			if stateDb == nil {
-				return state.IteratorDump{}, errors.New("pending state is not available")
+				return nil, errors.New("pending state is not available")
 			}
 		} else {
 			var header *types.Header
@@ -157,31 +214,40 @@ func (api *DebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, start hex
 			default:
 				block := api.eth.blockchain.GetBlockByNumber(uint64(number))
 				if block == nil {
-					return state.IteratorDump{}, fmt.Errorf("block #%d not found", number)
+					return nil, fmt.Errorf("block #%d not found", number)
 				}
 				header = block.Header()
 			}
 			if header == nil {
-				return state.IteratorDump{}, fmt.Errorf("block #%d not found", number)
+				return nil, fmt.Errorf("block #%d not found", number)
 			}
 			stateDb, err = api.eth.BlockChain().StateAt(header.Root)
 			if err != nil {
-				return state.IteratorDump{}, err
+				return nil, err
 			}
 		}
 	} else if hash, ok := blockNrOrHash.Hash(); ok {
 		block := api.eth.blockchain.GetBlockByHash(hash)
 		if block == nil {
-			return state.IteratorDump{}, fmt.Errorf("block %s not found", hash.Hex())
+			return nil, fmt.Errorf("block %s not found", hash.Hex())
 		}
 		stateDb, err = api.eth.BlockChain().StateAt(block.Root())
 		if err != nil {
-			return state.IteratorDump{}, err
+			return nil, err
 		}
 	} else {
-		return state.IteratorDump{}, errors.New("either block number or block hash must be specified")
+		return nil, errors.New("either block number or block hash must be specified")
 	}
 
+	return stateDb, nil
+}
+
+// AccountRange enumerates all accounts in the given block and start point in paging request
+func (api *DebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, start hexutil.Bytes, maxResults int, nocode, nostorage, incompletes bool) (state.IteratorDump, error) {
+	stateDb, err := api.stateAt(blockNrOrHash)
+	if err != nil {
+		return state.IteratorDump{}, err
+	}
 	opts := &state.DumpConfig{
 		SkipCode:          nocode,
 		SkipStorage:       nostorage,
@@ -195,6 +261,104 @@ func (api *DebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, start hex
 	return stateDb.IteratorDump(opts), nil
 }
 
+// stateDumpBatch is the maximum number of storage slots delivered in a single
+// state dump notification.
+const stateDumpBatch = 1024
+
+// StateDumpEntry is a single notification of a debug_subscribe("dumpState")
+// subscription. Every account is delivered on its own, followed by its storage
+// slots in batches. The final notification has Done set along with the key to
+// resume the dump from, if it was capped.
+type StateDumpEntry struct {
+	Address *common.Address        `json:"address,omitempty"`
+	Account *state.DumpAccount     `json:"account,omitempty"`
+	Storage map[common.Hash]string `json:"storage,omitempty"`
+	Done    bool                   `json:"done,omitempty"`
+	Next    hexutil.Bytes          `json:"next,omitempty"`
+}
+
+// DumpState streams the accounts of the given block starting at the given
+// hashed key, without accumulating the state in memory. Unlike AccountRange,
+// the number of accounts is not capped unless maxResults is positive, making
+// it suitable for exporting the entire state.
+func (api *DebugAPI) DumpState(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, start hexutil.Bytes, maxResults int, nocode, nostorage bool) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	stateDb, err := api.stateAt(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	opts := &state.DumpConfig{
+		SkipCode:    nocode,
+		SkipStorage: nostorage,
+		Start:       start,
+	}
+	if maxResults > 0 {
+		opts.Max = uint64(maxResults)
+	}
+	sub := notifier.CreateSubscription()
+
+	go func() {
+		var (
+			storage map[common.Hash]string
+			owner   *common.Address
+		)
+
+		// notify delivers an entry to the subscriber, aborting the dump if
+		// the subscription was torn down.
+		notify := func(entry *StateDumpEntry) error {
+			select {
+			case err := <-sub.Err():
+				if err == nil {
+					err = errors.New("subscription closed")
+				}
+				return err
+			case <-notifier.Closed():
+				return errors.New("connection closed")
+			default:
+			}
+			return notifier.Notify(sub.ID, entry)
+		}
+		flush := func() error {
+			if len(storage) == 0 {
+				return nil
+			}
+			batch := storage
+			storage = nil
+			return notify(&StateDumpEntry{Address: owner, Storage: batch})
+		}
+		onAccount := func(addr *common.Address, account state.DumpAccount) error {
+			if err := flush(); err != nil {
+				return err
+			}
+			account.Address = addr
+			return notify(&StateDumpEntry{Address: addr, Account: &account})
+		}
+		onStorage := func(addr *common.Address, key common.Hash, value []byte) error {
+			if storage == nil {
+				storage, owner = make(map[common.Hash]string), addr
+			}
+			storage[key] = common.Bytes2Hex(value)
+			if len(storage) >= stateDumpBatch {
+				return flush()
+			}
+			return nil
+		}
+		next, err := stateDb.StreamDump(opts, onAccount, onStorage)
+		if err == nil {
+			err = flush()
+		}
+		if err != nil {
+			log.Debug("State dump aborted", "err", err)
+			return
+		}
+		notify(&StateDumpEntry{Done: true, Next: next})
+	}()
+	return sub, nil
+}
+
 // StorageRangeResult is the result of a debug_storageRangeAt API call.
 type StorageRangeResult struct {
 	Storage storageMap   `json:"storage"`
@@ -226,41 +390,45 @@ func (api *DebugAPI) StorageRangeAt(ctx context.Context, blockNrOrHash rpc.Block
 	}
 	defer release()
 
-	st, err := statedb.StorageTrie(contractAddress)
+	return storageRangeAt(statedb, contractAddress, keyStart, maxResult)
+}
+
+func storageRangeAt(statedb *state.StateDB, addr common.Address, start []byte, maxResult int) (StorageRangeResult, error) {
+	var seek common.Hash
+	copy(seek[:], start)
+
+	it, err := statedb.StorageIterator(addr, seek)
 	if err != nil {
 		return StorageRangeResult{}, err
 	}
-	if st == nil {
-		return StorageRangeResult{}, fmt.Errorf("account %x doesn't exist", contractAddress)
+	if it == nil {
+		return StorageRangeResult{}, fmt.Errorf("account %x doesn't exist", addr)
 	}
-	return storageRangeAt(st, keyStart, maxResult)
-}
+	defer it.Release()
 
-func storageRangeAt(st state.Trie, start []byte, maxResult int) (StorageRangeResult, error) {
-	trieIt, err := st.NodeIterator(start)
+	st, err := statedb.StorageTrie(addr)
 	if err != nil {
 		return StorageRangeResult{}, err
 	}
-	it := trie.NewIterator(trieIt)
 	result := StorageRangeResult{Storage: storageMap{}}
 	for i := 0; i < maxResult && it.Next(); i++ {
-		_, content, _, err := rlp.Split(it.Value)
+		_, content, _, err := rlp.Split(it.Slot())
 		if err != nil {
 			return StorageRangeResult{}, err
 		}
 		e := storageEntry{Value: common.BytesToHash(content)}
-		if preimage := st.GetKey(it.Key); preimage != nil {
+		if preimage := st.GetKey(it.Hash().Bytes()); preimage != nil {
 			preimage := common.BytesToHash(preimage)
 			e.Key = &preimage
 		}
-		result.Storage[common.BytesToHash(it.Key)] = e
+		result.Storage[it.Hash()] = e
 	}
 	// Add the 'next key' so clients can continue downloading.
 	if it.Next() {
-		next := common.BytesToHash(it.Key)
+		next := it.Hash()
 		result.NextKey = &next
 	}
-	return result, nil
+	return result, it.Error()
 }
 
 // GetModifiedAccountsByNumber returns all accounts that have changed between the
@@ -432,3 +600,97 @@ func (api *DebugAPI) SetTrieFlushInterval(interval string) error {
 func (api *DebugAPI) GetTrieFlushInterval() string {
 	return api.eth.blockchain.GetTrieFlushInterval().String()
 }
+
+// ExecutionWitness returns the execution witness of the block with the given
+// hash, consisting of the trie nodes, contract codes and ancestor headers needed
+// to statelessly re-execute it on top of its parent state. Witnesses recorded on
+// import are served directly, others are built by re-executing the block, which
+// requires the state of its parent to be available.
+func (api *DebugAPI) ExecutionWitness(blockHash common.Hash) (*state.ExecutionWitness, error) {
+	if witness := api.eth.blockchain.ExecutionWitness(blockHash); witness != nil {
+		return witness, nil
+	}
+	block := api.eth.blockchain.GetBlockByHash(blockHash)
+	if block == nil {
+		return nil, fmt.Errorf("block %#x not found", blockHash)
+	}
+	return api.eth.blockchain.BuildExecutionWitness(block)
+}
+
+// BlockImportStats returns the timing breakdown of importing the block with the
+// given hash, split into execution, state reads, validation, trie updates and
+// hashing, commits, snapshot update and write. Only the recently imported blocks
+// are retained.
+func (api *DebugAPI) BlockImportStats(blockHash common.Hash) (*core.BlockImportStats, error) {
+	if stats := api.eth.blockchain.ImportStats(blockHash); stats != nil {
+		return stats, nil
+	}
+	return nil, fmt.Errorf("no import stats for block %#x", blockHash)
+}
+
+// RecentImportStats returns the timing breakdowns of the last count imported
+// blocks, newest first. All the retained breakdowns are returned if the count
+// is not specified.
+func (api *DebugAPI) RecentImportStats(count *int) []*core.BlockImportStats {
+	n := math.MaxInt32
+	if count != nil {
+		n = *count
+	}
+	return api.eth.blockchain.RecentImportStats(n)
+}
+
+// SnapshotGenerationResult is the progress report of the background state
+// snapshot generation.
+type SnapshotGenerationResult struct {
+	Root     common.Hash    `json:"root"`
+	Done     bool           `json:"done"`
+	Marker   hexutil.Bytes  `json:"marker"`
+	Accounts hexutil.Uint64 `json:"accounts"`
+	Slots    hexutil.Uint64 `json:"slots"`
+	Dangling hexutil.Uint64 `json:"dangling"`
+	Storage  hexutil.Uint64 `json:"storage"`
+	Elapsed  time.Duration  `json:"elapsed"`
+	ETA      time.Duration  `json:"eta"`
+}
+
+// SnapshotGeneration returns the progress of the background state snapshot
+// generation: the number of accounts and slots indexed so far, the position
+// reached and the estimated time left until it's done.
+func (api *DebugAPI) SnapshotGeneration() (*SnapshotGenerationResult, error) {
+	snaps := api.eth.blockchain.Snapshots()
+	if snaps == nil {
+		return nil, errors.New("snapshots are disabled")
+	}
+	progress := snaps.GeneratorProgress()
+	if progress == nil {
+		return nil, errors.New("snapshot generation progress unavailable")
+	}
+	return &SnapshotGenerationResult{
+		Root:     progress.Root,
+		Done:     progress.Done,
+		Marker:   progress.Marker,
+		Accounts: hexutil.Uint64(progress.Accounts),
+		Slots:    hexutil.Uint64(progress.Slots),
+		Dangling: hexutil.Uint64(progress.Dangling),
+		Storage:  hexutil.Uint64(progress.Storage),
+		Elapsed:  progress.Elapsed,
+		ETA:      progress.ETA,
+	}, nil
+}
+
+// PoolSnapshots returns the metadata of the transaction pool snapshots taken
+// when anomalies were detected, oldest first.
+func (api *DebugAPI) PoolSnapshots() ([]txpool.SnapshotInfo, error) {
+	if api.eth.forensics == nil {
+		return nil, errors.New("transaction pool forensics is disabled")
+	}
+	return api.eth.forensics.Snapshots()
+}
+
+// PoolSnapshot returns the transaction pool snapshot with the given identifier.
+func (api *DebugAPI) PoolSnapshot(id string) (*txpool.PoolSnapshot, error) {
+	if api.eth.forensics == nil {
+		return nil, errors.New("transaction pool forensics is disabled")
+	}
+	return api.eth.forensics.Snapshot(id)
+}
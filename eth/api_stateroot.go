@@ -0,0 +1,157 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/state/stateroot"
+	"github.com/gorievm/go-gori/rpc"
+)
+
+const (
+	// maxStateRootSessions is the maximum number of concurrent state root
+	// computations, each one staging its dataset in memory.
+	maxStateRootSessions = 4
+
+	// stateRootSessionTimeout is the time after which an idle state root
+	// computation is discarded.
+	stateRootSessionTimeout = 30 * time.Minute
+)
+
+var (
+	errStateRootSessions = errors.New("too many state root computations")
+	errStateRootUnknown  = errors.New("unknown state root computation")
+)
+
+// stateRootSession is a state root computation fed over RPC.
+type stateRootSession struct {
+	builder *stateroot.Builder
+	used    time.Time
+}
+
+// StateRootAPI allows external systems to validate account and storage datasets
+// (e.g. backups or L2 states) by computing their state roots with the node's
+// own trie hashing. Datasets are streamed in batches of accounts in the format
+// of the state dumps.
+type StateRootAPI struct {
+	sessions map[rpc.ID]*stateRootSession
+	lock     sync.Mutex
+}
+
+// NewStateRootAPI creates a new state root computation API.
+func NewStateRootAPI() *StateRootAPI {
+	return &StateRootAPI{
+		sessions: make(map[rpc.ID]*stateRootSession),
+	}
+}
+
+// session retrieves a state root computation by its identifier, discarding
+// the idle ones.
+func (api *StateRootAPI) session(id rpc.ID) (*stateroot.Builder, error) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	api.expire()
+	session, ok := api.sessions[id]
+	if !ok {
+		return nil, errStateRootUnknown
+	}
+	session.used = time.Now()
+	return session.builder, nil
+}
+
+// expire drops the state root computations idle for too long.
+//
+// Note, this method assumes the lock is held!
+func (api *StateRootAPI) expire() {
+	for id, session := range api.sessions {
+		if time.Since(session.used) > stateRootSessionTimeout {
+			delete(api.sessions, id)
+		}
+	}
+}
+
+// NewStateRoot starts a new state root computation, returning its identifier.
+func (api *StateRootAPI) NewStateRoot() (rpc.ID, error) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	api.expire()
+	if len(api.sessions) >= maxStateRootSessions {
+		return "", errStateRootSessions
+	}
+	id := rpc.NewID()
+	api.sessions[id] = &stateRootSession{
+		builder: stateroot.New(nil),
+		used:    time.Now(),
+	}
+	return id, nil
+}
+
+// FeedStateRoot adds a batch of accounts to a state root computation. Accounts
+// may be fed in any order and repeatedly, later ones overwriting earlier ones.
+// Storage slots with zero values are deleted.
+func (api *StateRootAPI) FeedStateRoot(id rpc.ID, accounts []state.DumpAccount) error {
+	builder, err := api.session(id)
+	if err != nil {
+		return err
+	}
+	for i := range accounts {
+		if err := builder.UpdateDump(&accounts[i]); err != nil {
+			return fmt.Errorf("account %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// StateRootProgress returns the amount of data fed into a state root
+// computation and the progress of its hashing.
+func (api *StateRootAPI) StateRootProgress(id rpc.ID) (stateroot.Progress, error) {
+	builder, err := api.session(id)
+	if err != nil {
+		return stateroot.Progress{}, err
+	}
+	return builder.Progress(), nil
+}
+
+// ComputeStateRoot computes the state root of all the accounts fed so far. The
+// computation can be fed further accounts afterwards.
+func (api *StateRootAPI) ComputeStateRoot(id rpc.ID) (common.Hash, error) {
+	builder, err := api.session(id)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return builder.Hash()
+}
+
+// DiscardStateRoot drops a state root computation, releasing its resources.
+func (api *StateRootAPI) DiscardStateRoot(id rpc.ID) error {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	if _, ok := api.sessions[id]; !ok {
+		return errStateRootUnknown
+	}
+	delete(api.sessions, id)
+	return nil
+}
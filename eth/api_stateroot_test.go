@@ -0,0 +1,81 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/types"
+)
+
+// Tests that the state root of a dataset fed in batches matches the one of
+// the state it was dumped from.
+func TestStateRootAPI(t *testing.T) {
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	for i := byte(1); i <= 10; i++ {
+		addr := common.Address{i}
+		statedb.SetBalance(addr, big.NewInt(int64(i)))
+		statedb.SetState(addr, common.Hash{i}, common.BytesToHash([]byte{i}))
+	}
+	root := statedb.IntermediateRoot(false)
+
+	api := NewStateRootAPI()
+	id, err := api.NewStateRoot()
+	if err != nil {
+		t.Fatalf("failed to start computation: %v", err)
+	}
+	for i := byte(1); i <= 10; i += 5 {
+		var batch []state.DumpAccount
+		for j := i; j < i+5; j++ {
+			addr := common.Address{j}
+			batch = append(batch, state.DumpAccount{
+				Balance: big.NewInt(int64(j)).String(),
+				Address: &addr,
+				Storage: map[common.Hash]string{{j}: common.Bytes2Hex([]byte{j})},
+			})
+		}
+		if err := api.FeedStateRoot(id, batch); err != nil {
+			t.Fatalf("failed to feed batch: %v", err)
+		}
+	}
+	if progress, _ := api.StateRootProgress(id); progress.Accounts != 10 || progress.Slots != 10 {
+		t.Errorf("progress mismatch: have %d accounts %d slots, want 10 accounts 10 slots", progress.Accounts, progress.Slots)
+	}
+	if have, err := api.ComputeStateRoot(id); err != nil || have != root {
+		t.Fatalf("root mismatch: have %x/%v, want %x", have, err, root)
+	}
+	if err := api.DiscardStateRoot(id); err != nil {
+		t.Fatalf("failed to discard computation: %v", err)
+	}
+	if _, err := api.ComputeStateRoot(id); !errors.Is(err, errStateRootUnknown) {
+		t.Errorf("discarded computation error mismatch: have %v, want %v", err, errStateRootUnknown)
+	}
+	// Ensure the number of concurrent computations is capped
+	for i := 0; i < maxStateRootSessions; i++ {
+		if _, err := api.NewStateRoot(); err != nil {
+			t.Fatalf("failed to start computation %d: %v", i, err)
+		}
+	}
+	if _, err := api.NewStateRoot(); !errors.Is(err, errStateRootSessions) {
+		t.Errorf("session limit error mismatch: have %v, want %v", err, errStateRootSessions)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"math/big"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/gorievm/go-gori/accounts"
 	"github.com/gorievm/go-gori/common"
@@ -32,6 +33,7 @@ import (
 	"github.com/gorievm/go-gori/consensus/clique"
 	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/bloombits"
+	"github.com/gorievm/go-gori/core/filtermaps"
 	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/core/state/pruner"
 	"github.com/gorievm/go-gori/core/txpool"
@@ -57,8 +59,17 @@ import (
 	"github.com/gorievm/go-gori/params"
 	"github.com/gorievm/go-gori/rlp"
 	"github.com/gorievm/go-gori/rpc"
+	"github.com/gorievm/go-gori/trie"
 )
 
+// uncleanShutdownCheckDepth is the number of recent canonical blocks verified on
+// startup after an unclean shutdown.
+const uncleanShutdownCheckDepth = 128
+
+// chainStopTimeout is the time the chain is given on shutdown to flush the cached
+// recent state to disk, which may take minutes on a large state.
+const chainStopTimeout = 5 * time.Minute
+
 // Config contains the configuration options of the ETH protocol.
 // Deprecated: use ethconfig.Config instead.
 type Config = ethconfig.Config
@@ -68,9 +79,11 @@ type Ori struct {
 	config *ethconfig.Config
 
 	// Handlers
-	txPool *txpool.TxPool
+	txPool    *txpool.TxPool
+	forensics *txpool.Forensics // Transaction pool anomaly snapshotter, nil if disabled
 
 	blockchain         *core.BlockChain
+	forkOverrides      *forkOverrideWatcher // Signed fork override watcher, nil if disabled
 	handler            *handler
 	ethDialCandidates  enode.Iterator
 	snapDialCandidates enode.Iterator
@@ -84,9 +97,13 @@ type Ori struct {
 	accountManager *accounts.Manager
 
 	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
-	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
+	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports, nil once retired
+	bloomLock         sync.Mutex                     // Protects the bloom indexer from being retired concurrently
+	bloomRetireWg     sync.WaitGroup
 	closeBloomHandler chan struct{}
 
+	logIndex *filtermaps.FilterMaps // Log index superseding the bloom bits, nil if disabled
+
 	APIBackend *EthAPIBackend
 
 	miner     *miner.Miner
@@ -157,7 +174,6 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ori, error) {
 		gasPrice:          config.Miner.GasPrice,
 		etherbase:         config.Miner.Etherbase,
 		bloomRequests:     make(chan chan *bloombits.Retrieval),
-		bloomIndexer:      core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
 		p2pServer:         stack.Server(),
 		shutdownTracker:   shutdowncheck.NewShutdownTracker(chainDb),
 	}
@@ -170,13 +186,8 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ori, error) {
 	log.Info("Initialising Ori protocol", "network", config.NetworkId, "dbversion", dbVer)
 
 	if !config.SkipBcVersionCheck {
-		if bcVersion != nil && *bcVersion > core.BlockChainVersion {
-			return nil, fmt.Errorf("database version is v%d, Geth %s only supports v%d", *bcVersion, params.VersionWithMeta, core.BlockChainVersion)
-		} else if bcVersion == nil || *bcVersion < core.BlockChainVersion {
-			if bcVersion != nil { // only print warning on upgrade, not on init
-				log.Warn("Upgrade blockchain database version", "from", dbVer, "to", core.BlockChainVersion)
-			}
-			rawdb.WriteDatabaseVersion(chainDb, core.BlockChainVersion)
+		if err := rawdb.MigrateSchema(chainDb, core.BlockChainVersion); err != nil {
+			return nil, fmt.Errorf("Geth %s: %w", params.VersionWithMeta, err)
 		}
 	}
 	var (
@@ -190,7 +201,14 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ori, error) {
 			TrieDirtyDisabled:   config.NoPruning,
 			TrieTimeLimit:       config.TrieTimeout,
 			SnapshotLimit:       config.SnapshotCache,
+			SnapshotRate:        config.SnapshotRate,
 			Preimages:           config.Preimages,
+			WitnessHistory:      config.WitnessHistory,
+			ReceiptAccounting:   config.RPCExtendedReceipts,
+			ParallelExecution:   config.ParallelExecution,
+			AddressIndex:        config.AddressIndex,
+			AddressIndexLimit:   config.AddressIndexLimit,
+			HistoryCutoff:       config.HistoryCutoff,
 		}
 	)
 	// Override the chain config with provided settings.
@@ -201,11 +219,36 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ori, error) {
 	if config.OverrideVerkle != nil {
 		overrides.OverrideVerkle = config.OverrideVerkle
 	}
+	if config.ForkOverrideFile != "" {
+		if len(config.ForkOverrideSigners) == 0 {
+			return nil, errors.New("fork override file requires trusted signers")
+		}
+		overrides.ForkScheduling = true
+	}
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, config.Genesis, &overrides, eth.engine, vmConfig, eth.shouldPreserve, &config.TxLookupLimit)
 	if err != nil {
 		return nil, err
 	}
-	eth.bloomIndexer.Start(eth.blockchain)
+	// Set up the log index. The bloom bits index is still maintained until the
+	// log index caught up with the chain, except if light clients are served as
+	// the bloom trie is derived from it.
+	if !config.LogNoHistory {
+		eth.logIndex = filtermaps.New(chainDb, eth.blockchain, filtermaps.DefaultParams, config.LogHistory)
+	}
+	if eth.retireBloomBits() && rawdb.ReadBloomBitsRetired(chainDb) {
+		log.Info("Bloom bits index superseded by the log index")
+	} else {
+		if rawdb.ReadBloomBitsRetired(chainDb) {
+			log.Warn("Rebuilding retired bloom bits index")
+			rawdb.DeleteBloomBitsRetired(chainDb)
+		}
+		eth.bloomIndexer = core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms)
+		eth.bloomIndexer.Start(eth.blockchain)
+	}
+
+	if config.ForkOverrideFile != "" {
+		eth.forkOverrides = newForkOverrideWatcher(stack.ResolvePath(config.ForkOverrideFile), config.ForkOverrideSigners, eth.blockchain)
+	}
 
 	if config.BlobPool.Datadir != "" {
 		config.BlobPool.Datadir = stack.ResolvePath(config.BlobPool.Datadir)
@@ -215,12 +258,21 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ori, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.Overflow != "" {
+		config.TxPool.Overflow = stack.ResolvePath(config.TxPool.Overflow)
+	}
 	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
 
 	eth.txPool, err = txpool.New(new(big.Int).SetUint64(config.TxPool.PriceLimit), eth.blockchain, []txpool.SubPool{legacyPool, blobPool})
 	if err != nil {
 		return nil, err
 	}
+	if config.TxPoolForensics.Enabled {
+		config.TxPoolForensics.Dir = stack.ResolvePath(config.TxPoolForensics.Dir)
+		if eth.forensics, err = txpool.NewForensics(config.TxPoolForensics, eth.txPool); err != nil {
+			return nil, err
+		}
+	}
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
 	if eth.handler, err = newHandler(&handlerConfig{
@@ -230,12 +282,19 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ori, error) {
 		Merger:         eth.merger,
 		Network:        config.NetworkId,
 		Sync:           config.SyncMode,
+		Checkpoint:     config.SyncCheckpoint,
 		BloomCache:     uint64(cacheLimit),
 		EventMux:       eth.eventMux,
 		RequiredBlocks: config.RequiredBlocks,
+		Archive:        config.NoPruning,
+		TxPropagation:  &config.TxPropagation,
 	}); err != nil {
 		return nil, err
 	}
+	if config.StateHeal {
+		eth.blockchain.TrieDB().SetHealer(eth.handler.downloader.SnapSyncer)
+		log.Info("Enabled trie node healing from snap peers")
+	}
 
 	eth.miner = miner.New(eth, &config.Miner, eth.blockchain.Config(), eth.EventMux(), eth.engine, eth.isLocalBlock)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
@@ -260,6 +319,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ori, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Skip dialing peers which exhausted their reputation
+	eth.ethDialCandidates = enode.Filter(eth.ethDialCandidates, eth.handler.reputation.dialable)
+	eth.snapDialCandidates = enode.Filter(eth.snapDialCandidates, eth.handler.reputation.dialable)
 
 	// Start the RPC service
 	eth.netRPCService = ethapi.NewNetAPI(eth.p2pServer, config.NetworkId)
@@ -270,11 +332,40 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ori, error) {
 	stack.RegisterLifecycle(eth)
 
 	// Successful startup; push a marker and check previous unclean shutdowns.
-	eth.shutdownTracker.MarkStartup()
+	// If the last run was not shut down cleanly, verify the recent chain.
+	if eth.shutdownTracker.MarkStartup() {
+		if err := checkRecentChain(eth.blockchain, chainDb, uncleanShutdownCheckDepth); err != nil {
+			return nil, err
+		}
+	}
 
 	return eth, nil
 }
 
+// checkRecentChain verifies that the most recent canonical blocks are intact,
+// rewinding the chain below the first missing or corrupted one. It is run on
+// startup if the node detected an unclean previous shutdown.
+func checkRecentChain(chain *core.BlockChain, db ethdb.Database, depth uint64) error {
+	var (
+		head   = chain.CurrentBlock()
+		number = head.Number.Uint64()
+		hash   = head.Hash()
+	)
+	log.Info("Verifying recent chain after unclean shutdown", "number", number, "hash", hash, "depth", depth)
+	for i := uint64(0); i < depth && number > 0; i++ {
+		header := rawdb.ReadHeader(db, hash, number)
+		body := rawdb.ReadBody(db, hash, number)
+		if header == nil || body == nil ||
+			types.DeriveSha(types.Transactions(body.Transactions), trie.NewStackTrie(nil)) != header.TxHash ||
+			types.CalcUncleHash(body.Uncles) != header.UncleHash {
+			log.Error("Corrupted block found, rewinding chain", "number", number, "hash", hash)
+			return chain.SetHead(number - 1)
+		}
+		number, hash = number-1, header.ParentHash
+	}
+	return nil
+}
+
 func makeExtraData(extra []byte) []byte {
 	if len(extra) == 0 {
 		// create default extradata
@@ -317,6 +408,9 @@ func (s *Ori) APIs() []rpc.API {
 		}, {
 			Namespace: "debug",
 			Service:   NewDebugAPI(s),
+		}, {
+			Namespace: "debug",
+			Service:   NewStateRootAPI(),
 		}, {
 			Namespace: "net",
 			Service:   s.netRPCService,
@@ -473,7 +567,6 @@ func (s *Ori) Downloader() *downloader.Downloader { return s.handler.downloader
 func (s *Ori) Synced() bool                       { return s.handler.acceptTxs.Load() }
 func (s *Ori) SetSynced()                         { s.handler.acceptTxs.Store(true) }
 func (s *Ori) ArchiveMode() bool                  { return s.config.NoPruning }
-func (s *Ori) BloomIndexer() *core.ChainIndexer   { return s.bloomIndexer }
 func (s *Ori) Merger() *consensus.Merger          { return s.merger }
 func (s *Ori) SyncMode() downloader.SyncMode {
 	mode, _ := s.handler.chainSync.modeAndLocalHead()
@@ -484,7 +577,9 @@ func (s *Ori) SyncMode() downloader.SyncMode {
 // network protocols to start.
 func (s *Ori) Protocols() []p2p.Protocol {
 	protos := eth.MakeProtocols((*ethHandler)(s.handler), s.networkID, s.ethDialCandidates)
-	if s.config.SnapshotCache > 0 {
+	// Snap sync can be served either from the snapshot tree or, for path-based
+	// nodes, directly from the trie layers without the flat state duplicate.
+	if s.config.SnapshotCache > 0 || s.blockchain.TrieDB().Scheme() == rawdb.PathScheme {
 		protos = append(protos, snap.MakeProtocols((*snapHandler)(s.handler), s.snapDialCandidates)...)
 	}
 	return protos
@@ -495,12 +590,27 @@ func (s *Ori) Protocols() []p2p.Protocol {
 func (s *Ori) Start() error {
 	eth.StartENRUpdater(s.blockchain, s.p2pServer.LocalNode())
 
-	// Start the bloom bits servicing goroutines
+	// Persist peer reputations alongside the other node metadata
+	s.handler.reputation.setDB(s.p2pServer.LocalNode().Database())
+
+	// Start the bloom bits servicing goroutines and the log index
 	s.startBloomHandlers(params.BloomBitsBlocks)
+	if s.logIndex != nil {
+		s.logIndex.Start()
+		if s.retireBloomBits() && s.BloomIndexer() != nil {
+			s.bloomRetireWg.Add(1)
+			go s.retireBloomIndexer()
+		}
+	}
 
 	// Regularly update shutdown marker
 	s.shutdownTracker.Start()
 
+	// Schedule forks from the signed override file before talking to peers
+	if s.forkOverrides != nil {
+		s.forkOverrides.start()
+	}
+
 	// Figure out a max peers count based on the server limits
 	maxPeers := s.p2pServer.MaxPeers
 	if s.config.LightServ > 0 {
@@ -523,8 +633,20 @@ func (s *Ori) Stop() error {
 	s.handler.Stop()
 
 	// Then stop everything else.
-	s.bloomIndexer.Close()
+	if s.forkOverrides != nil {
+		s.forkOverrides.stop()
+	}
 	close(s.closeBloomHandler)
+	s.bloomRetireWg.Wait()
+	if indexer := s.BloomIndexer(); indexer != nil {
+		indexer.Close()
+	}
+	if s.logIndex != nil {
+		s.logIndex.Stop()
+	}
+	if s.forensics != nil {
+		s.forensics.Close()
+	}
 	s.txPool.Close()
 	s.miner.Close()
 	s.blockchain.Stop()
@@ -538,3 +660,19 @@ func (s *Ori) Stop() error {
 
 	return nil
 }
+
+// StopTimeout implements node.StopTimeouter, giving the chain enough time to
+// persist its cached state besides the transaction pool shutdown.
+func (s *Ori) StopTimeout() time.Duration {
+	return s.txPool.StopTimeout() + chainStopTimeout
+}
+
+// ForceStop implements node.ForceStopper, making a slow Stop return early. Block
+// processing is interrupted, the transaction promotions in flight are dropped and
+// the cached state is not flushed, so recent blocks are reprocessed on the next
+// startup instead.
+func (s *Ori) ForceStop() {
+	s.blockchain.StopInsert()
+	s.blockchain.AbortStateFlush()
+	s.txPool.ForceStop()
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/consensus/ethash"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/eth/ethconfig"
+	"github.com/gorievm/go-gori/node"
+	"github.com/gorievm/go-gori/params"
+)
+
+// Tests that the post unclean shutdown check rewinds the chain below the first
+// block with missing data, and leaves an intact chain alone.
+func TestCheckRecentChain(t *testing.T) {
+	handler := newTestHandlerWithBlocks(32)
+	defer handler.close()
+
+	chain := handler.chain
+	if err := checkRecentChain(chain, handler.db, 16); err != nil {
+		t.Fatalf("failed to check intact chain: %v", err)
+	}
+	if head := chain.CurrentBlock().Number.Uint64(); head != 32 {
+		t.Fatalf("intact chain rewound: have head %d, want %d", head, 32)
+	}
+	// Drop a body within the checked range and ensure the chain is rewound
+	corrupt := chain.GetBlockByNumber(28)
+	rawdb.DeleteBody(handler.db, corrupt.Hash(), corrupt.NumberU64())
+
+	if err := checkRecentChain(chain, handler.db, 16); err != nil {
+		t.Fatalf("failed to check corrupted chain: %v", err)
+	}
+	if head := chain.CurrentBlock().Number.Uint64(); head != 27 {
+		t.Fatalf("corrupted chain not rewound: have head %d, want %d", head, 27)
+	}
+}
+
+// Tests that force stopping the service skips flushing the cached state, leaving
+// a database the next startup recovers from by rewinding to the last persisted
+// state, while a regular stop keeps the head.
+func TestForceStop(t *testing.T) {
+	testForceStop(t, false)
+	testForceStop(t, true)
+}
+
+func testForceStop(t *testing.T, force bool) {
+	var (
+		datadir = t.TempDir()
+		genesis = &core.Genesis{
+			Config:  params.AllEthashProtocolChanges,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 8, nil)
+
+	start := func() (*node.Node, *Ori) {
+		stack, err := node.New(&node.Config{DataDir: datadir})
+		if err != nil {
+			t.Fatalf("failed to create node: %v", err)
+		}
+		backend, err := New(stack, &ethconfig.Config{Genesis: genesis})
+		if err != nil {
+			t.Fatalf("failed to create service: %v", err)
+		}
+		if err := stack.Start(); err != nil {
+			t.Fatalf("failed to start node: %v", err)
+		}
+		return stack, backend
+	}
+	stack, backend := start()
+	if _, err := backend.BlockChain().InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import blocks: %v", err)
+	}
+	if force {
+		backend.ForceStop()
+	}
+	if err := stack.Close(); err != nil {
+		t.Fatalf("failed to close node: %v", err)
+	}
+	stack, backend = start()
+	defer stack.Close()
+
+	head := backend.BlockChain().CurrentBlock()
+	if !backend.BlockChain().HasState(head.Root) {
+		t.Fatalf("force %v: head state missing after restart", force)
+	}
+	want := blocks[len(blocks)-1].NumberU64()
+	if force {
+		want = 0
+	}
+	if head.Number.Uint64() != want {
+		t.Fatalf("force %v: head mismatch: have %d, want %d", force, head.Number, want)
+	}
+}
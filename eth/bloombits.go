@@ -20,7 +20,9 @@ import (
 	"time"
 
 	"github.com/gorievm/go-gori/common/bitutil"
+	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/log"
 )
 
 const (
@@ -72,3 +74,44 @@ func (eth *Ori) startBloomHandlers(sectionSize uint64) {
 		}()
 	}
 }
+
+// BloomIndexer returns the bloom bits chain indexer, nil if the bloom bits were
+// superseded by the log index.
+func (eth *Ori) BloomIndexer() *core.ChainIndexer {
+	eth.bloomLock.Lock()
+	defer eth.bloomLock.Unlock()
+
+	return eth.bloomIndexer
+}
+
+// retireBloomBits returns whether the bloom bits index is dropped once the log
+// index caught up with the chain. They are kept if light clients are served, as
+// the bloom trie is derived from them.
+func (eth *Ori) retireBloomBits() bool {
+	return eth.logIndex != nil && eth.config.LightServ == 0
+}
+
+// retireBloomIndexer waits until the log index caught up with the chain, then
+// stops the bloom bits indexer and deletes its data.
+func (eth *Ori) retireBloomIndexer() {
+	defer eth.bloomRetireWg.Done()
+
+	select {
+	case <-eth.logIndex.Synced():
+	case <-eth.closeBloomHandler:
+		return
+	}
+	eth.bloomLock.Lock()
+	indexer := eth.bloomIndexer
+	eth.bloomIndexer = nil
+	eth.bloomLock.Unlock()
+
+	if err := indexer.Close(); err != nil {
+		log.Error("Failed to stop bloom bits indexer", "err", err)
+	}
+	// Flag the retirement first, so an interrupted deletion is not mistaken for
+	// an empty bloom bits index to be rebuilt.
+	rawdb.WriteBloomBitsRetired(eth.chainDb)
+	rawdb.DeleteBloomBitsIndex(eth.chainDb)
+	log.Info("Retired bloom bits index superseded by the log index")
+}
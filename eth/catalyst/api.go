@@ -78,13 +78,16 @@ const (
 var caps = []string{
 	"engine_forkchoiceUpdatedV1",
 	"engine_forkchoiceUpdatedV2",
+	"engine_forkchoiceUpdatedV3",
 	"engine_exchangeTransitionConfigurationV1",
 	"engine_getPayloadV1",
 	"engine_getPayloadV2",
 	"engine_getPayloadV3",
+	"engine_getPayloadV4",
 	"engine_newPayloadV1",
 	"engine_newPayloadV2",
 	"engine_newPayloadV3",
+	"engine_newPayloadV4",
 	"engine_getPayloadBodiesByHashV1",
 	"engine_getPayloadBodiesByRangeV1",
 }
@@ -170,6 +173,9 @@ func (api *ConsensusAPI) ForkchoiceUpdatedV1(update engine.ForkchoiceStateV1, pa
 		if payloadAttributes.Withdrawals != nil {
 			return engine.STATUS_INVALID, engine.InvalidParams.With(errors.New("withdrawals not supported in V1"))
 		}
+		if payloadAttributes.BeaconRoot != nil {
+			return engine.STATUS_INVALID, engine.InvalidParams.With(errors.New("beacon root not supported in V1"))
+		}
 		if api.eth.BlockChain().Config().IsShanghai(api.eth.BlockChain().Config().LondonBlock, payloadAttributes.Timestamp) {
 			return engine.STATUS_INVALID, engine.InvalidParams.With(errors.New("forkChoiceUpdateV1 called post-shanghai"))
 		}
@@ -180,9 +186,32 @@ func (api *ConsensusAPI) ForkchoiceUpdatedV1(update engine.ForkchoiceStateV1, pa
 // ForkchoiceUpdatedV2 is equivalent to V1 with the addition of withdrawals in the payload attributes.
 func (api *ConsensusAPI) ForkchoiceUpdatedV2(update engine.ForkchoiceStateV1, payloadAttributes *engine.PayloadAttributes) (engine.ForkChoiceResponse, error) {
 	if payloadAttributes != nil {
+		if payloadAttributes.BeaconRoot != nil {
+			return engine.STATUS_INVALID, engine.InvalidParams.With(errors.New("beacon root not supported in V2"))
+		}
 		if err := api.verifyPayloadAttributes(payloadAttributes); err != nil {
 			return engine.STATUS_INVALID, engine.InvalidParams.With(err)
 		}
+		if api.eth.BlockChain().Config().IsCancun(api.eth.BlockChain().Config().LondonBlock, payloadAttributes.Timestamp) {
+			return engine.STATUS_INVALID, engine.UnsupportedFork.With(errors.New("forkchoiceUpdatedV2 called post-cancun"))
+		}
+	}
+	return api.forkchoiceUpdated(update, payloadAttributes)
+}
+
+// ForkchoiceUpdatedV3 is equivalent to V2 with the addition of the parent beacon
+// block root in the payload attributes. It must only be used for cancun payloads.
+func (api *ConsensusAPI) ForkchoiceUpdatedV3(update engine.ForkchoiceStateV1, payloadAttributes *engine.PayloadAttributes) (engine.ForkChoiceResponse, error) {
+	if payloadAttributes != nil {
+		if payloadAttributes.Withdrawals == nil {
+			return engine.STATUS_INVALID, engine.InvalidPayloadAttributes.With(errors.New("missing withdrawals list"))
+		}
+		if payloadAttributes.BeaconRoot == nil {
+			return engine.STATUS_INVALID, engine.InvalidPayloadAttributes.With(errors.New("missing beacon root"))
+		}
+		if !api.eth.BlockChain().Config().IsCancun(api.eth.BlockChain().Config().LondonBlock, payloadAttributes.Timestamp) {
+			return engine.STATUS_INVALID, engine.UnsupportedFork.With(errors.New("forkchoiceUpdatedV3 called pre-cancun"))
+		}
 	}
 	return api.forkchoiceUpdated(update, payloadAttributes)
 }
@@ -345,6 +374,7 @@ func (api *ConsensusAPI) forkchoiceUpdated(update engine.ForkchoiceStateV1, payl
 			FeeRecipient: payloadAttributes.SuggestedFeeRecipient,
 			Random:       payloadAttributes.Random,
 			Withdrawals:  payloadAttributes.Withdrawals,
+			BeaconRoot:   payloadAttributes.BeaconRoot,
 		}
 		id := args.Id()
 		// If we already are busy generating this work, then we do not need
@@ -402,14 +432,45 @@ func (api *ConsensusAPI) GetPayloadV1(payloadID engine.PayloadID) (*engine.Execu
 	return data.ExecutionPayload, nil
 }
 
-// GetPayloadV2 returns a cached payload by id.
+// GetPayloadV2 returns a cached payload by id. The blobs bundle is omitted as
+// cancun payloads must be retrieved with V3.
 func (api *ConsensusAPI) GetPayloadV2(payloadID engine.PayloadID) (*engine.ExecutionPayloadEnvelope, error) {
-	return api.getPayload(payloadID)
+	data, err := api.getPayload(payloadID)
+	if err != nil {
+		return nil, err
+	}
+	if api.isCancun(data.ExecutionPayload) {
+		return nil, engine.UnsupportedFork.With(errors.New("getPayloadV2 called for post-cancun payload"))
+	}
+	return &engine.ExecutionPayloadEnvelope{ExecutionPayload: data.ExecutionPayload, BlockValue: data.BlockValue}, nil
 }
 
-// GetPayloadV3 returns a cached payload by id.
+// GetPayloadV3 returns a cached cancun payload by id, along with the blobs bundle
+// of the included blob transactions.
 func (api *ConsensusAPI) GetPayloadV3(payloadID engine.PayloadID) (*engine.ExecutionPayloadEnvelope, error) {
-	return api.getPayload(payloadID)
+	data, err := api.getPayload(payloadID)
+	if err != nil {
+		return nil, err
+	}
+	if !api.isCancun(data.ExecutionPayload) || api.isPrague(data.ExecutionPayload) {
+		return nil, engine.UnsupportedFork.With(errors.New("getPayloadV3 called for non-cancun payload"))
+	}
+	return data, nil
+}
+
+// GetPayloadV4 returns a cached prague payload by id, along with the blobs bundle
+// and the execution requests. Gori does not define any execution layer request
+// types, so the list of requests is always empty.
+func (api *ConsensusAPI) GetPayloadV4(payloadID engine.PayloadID) (*engine.ExecutionPayloadEnvelope, error) {
+	data, err := api.getPayload(payloadID)
+	if err != nil {
+		return nil, err
+	}
+	if !api.isPrague(data.ExecutionPayload) {
+		return nil, engine.UnsupportedFork.With(errors.New("getPayloadV4 called for pre-prague payload"))
+	}
+	data.Requests = make([][]byte, 0)
+	return data, nil
 }
 
 func (api *ConsensusAPI) getPayload(payloadID engine.PayloadID) (*engine.ExecutionPayloadEnvelope, error) {
@@ -426,7 +487,7 @@ func (api *ConsensusAPI) NewPayloadV1(params engine.ExecutableData) (engine.Payl
 	if params.Withdrawals != nil {
 		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("withdrawals not supported in V1"))
 	}
-	return api.newPayload(params, nil)
+	return api.newPayload(params, nil, nil, nil)
 }
 
 // NewPayloadV2 creates an Eth1 block, inserts it in the chain, and returns the status of the chain.
@@ -439,28 +500,73 @@ func (api *ConsensusAPI) NewPayloadV2(params engine.ExecutableData) (engine.Payl
 		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("non-nil withdrawals pre-shanghai"))
 	}
 	if api.eth.BlockChain().Config().IsCancun(new(big.Int).SetUint64(params.Number), params.Timestamp) {
-		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("newPayloadV2 called post-cancun"))
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.UnsupportedFork.With(errors.New("newPayloadV2 called post-cancun"))
 	}
-	return api.newPayload(params, nil)
+	return api.newPayload(params, nil, nil, nil)
 }
 
 // NewPayloadV3 creates an Eth1 block, inserts it in the chain, and returns the status of the chain.
-func (api *ConsensusAPI) NewPayloadV3(params engine.ExecutableData, versionedHashes *[]common.Hash) (engine.PayloadStatusV1, error) {
-	if !api.eth.BlockChain().Config().IsCancun(new(big.Int).SetUint64(params.Number), params.Timestamp) {
-		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("newPayloadV3 called pre-cancun"))
+func (api *ConsensusAPI) NewPayloadV3(params engine.ExecutableData, versionedHashes []common.Hash, beaconRoot *common.Hash) (engine.PayloadStatusV1, error) {
+	if err := verifyCancunPayload(params, versionedHashes, beaconRoot); err != nil {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(err)
+	}
+	if !api.isCancun(&params) {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.UnsupportedFork.With(errors.New("newPayloadV3 called pre-cancun"))
 	}
+	if api.isPrague(&params) {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.UnsupportedFork.With(errors.New("newPayloadV3 called post-prague"))
+	}
+	return api.newPayload(params, versionedHashes, beaconRoot, nil)
+}
 
-	if params.ExcessBlobGas == nil {
-		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("nil excessBlobGas post-cancun"))
+// NewPayloadV4 creates an Eth1 block, inserts it in the chain, and returns the status of the chain.
+// Gori does not define any execution layer request types, so payloads carrying
+// requests are rejected as the block could not commit to them.
+func (api *ConsensusAPI) NewPayloadV4(params engine.ExecutableData, versionedHashes []common.Hash, beaconRoot *common.Hash, executionRequests []hexutil.Bytes) (engine.PayloadStatusV1, error) {
+	if err := verifyCancunPayload(params, versionedHashes, beaconRoot); err != nil {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(err)
 	}
-	var hashes []common.Hash
-	if versionedHashes != nil {
-		hashes = *versionedHashes
+	if executionRequests == nil {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("nil executionRequests post-prague"))
 	}
-	return api.newPayload(params, hashes)
+	if !api.isPrague(&params) {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.UnsupportedFork.With(errors.New("newPayloadV4 called pre-prague"))
+	}
+	if len(executionRequests) > 0 {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("execution requests not supported"))
+	}
+	return api.newPayload(params, versionedHashes, beaconRoot, make([][]byte, 0))
+}
+
+// verifyCancunPayload checks that all the fields mandatory since cancun are set
+// in a new payload request.
+func verifyCancunPayload(params engine.ExecutableData, versionedHashes []common.Hash, beaconRoot *common.Hash) error {
+	switch {
+	case params.Withdrawals == nil:
+		return errors.New("nil withdrawals post-shanghai")
+	case params.ExcessBlobGas == nil:
+		return errors.New("nil excessBlobGas post-cancun")
+	case params.BlobGasUsed == nil:
+		return errors.New("nil blobGasUsed post-cancun")
+	case versionedHashes == nil:
+		return errors.New("nil versionedHashes post-cancun")
+	case beaconRoot == nil:
+		return errors.New("nil beaconRoot post-cancun")
+	}
+	return nil
+}
+
+// isCancun returns whether the given payload belongs to the cancun fork or later.
+func (api *ConsensusAPI) isCancun(params *engine.ExecutableData) bool {
+	return api.eth.BlockChain().Config().IsCancun(new(big.Int).SetUint64(params.Number), params.Timestamp)
+}
+
+// isPrague returns whether the given payload belongs to the prague fork or later.
+func (api *ConsensusAPI) isPrague(params *engine.ExecutableData) bool {
+	return api.eth.BlockChain().Config().IsPrague(new(big.Int).SetUint64(params.Number), params.Timestamp)
 }
 
-func (api *ConsensusAPI) newPayload(params engine.ExecutableData, versionedHashes []common.Hash) (engine.PayloadStatusV1, error) {
+func (api *ConsensusAPI) newPayload(params engine.ExecutableData, versionedHashes []common.Hash, beaconRoot *common.Hash, requests [][]byte) (engine.PayloadStatusV1, error) {
 	// The locking here is, strictly, not required. Without these locks, this can happen:
 	//
 	// 1. NewPayload( execdata-N ) is invoked from the CL. It goes all the way down to
@@ -478,7 +584,7 @@ func (api *ConsensusAPI) newPayload(params engine.ExecutableData, versionedHashe
 	defer api.newPayloadLock.Unlock()
 
 	log.Trace("Engine API request received", "method", "NewPayload", "number", params.Number, "hash", params.BlockHash)
-	block, err := engine.ExecutableDataToBlock(params, versionedHashes)
+	block, err := engine.ExecutableDataToBlock(params, versionedHashes, beaconRoot, requests)
 	if err != nil {
 		log.Warn("Invalid NewPayload params", "params", params, "error", err)
 		return engine.PayloadStatusV1{Status: engine.INVALID}, nil
@@ -323,7 +323,7 @@ func TestEth2NewBlock(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Failed to create the executable data %v", err)
 		}
-		block, err := engine.ExecutableDataToBlock(*execData, nil)
+		block, err := engine.ExecutableDataToBlock(*execData, nil, nil, nil)
 		if err != nil {
 			t.Fatalf("Failed to convert executable data to block %v", err)
 		}
@@ -365,7 +365,7 @@ func TestEth2NewBlock(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Failed to create the executable data %v", err)
 		}
-		block, err := engine.ExecutableDataToBlock(*execData, nil)
+		block, err := engine.ExecutableDataToBlock(*execData, nil, nil, nil)
 		if err != nil {
 			t.Fatalf("Failed to convert executable data to block %v", err)
 		}
@@ -997,7 +997,7 @@ func TestSimultaneousNewBlock(t *testing.T) {
 				t.Fatal(testErr)
 			}
 		}
-		block, err := engine.ExecutableDataToBlock(*execData, nil)
+		block, err := engine.ExecutableDataToBlock(*execData, nil, nil, nil)
 		if err != nil {
 			t.Fatalf("Failed to convert executable data to block %v", err)
 		}
@@ -1550,8 +1550,140 @@ func TestBlockToPayloadWithBlobs(t *testing.T) {
 	if got := len(envelope.BlobsBundle.Blobs); got != want {
 		t.Fatalf("invalid number of blobs: got %v, want %v", got, want)
 	}
-	_, err := engine.ExecutableDataToBlock(*envelope.ExecutionPayload, make([]common.Hash, 1))
+	_, err := engine.ExecutableDataToBlock(*envelope.ExecutionPayload, make([]common.Hash, 1), nil, nil)
 	if err != nil {
 		t.Error(err)
 	}
 }
+
+// checkEngineError asserts that err is an engine API error with the wanted code.
+func checkEngineError(t *testing.T, method string, err error, want *engine.EngineAPIError) {
+	t.Helper()
+
+	apiErr, ok := err.(*engine.EngineAPIError)
+	if !ok {
+		t.Fatalf("%s: unexpected error: have %v, want %v", method, err, want)
+	}
+	if apiErr.ErrorCode() != want.ErrorCode() {
+		t.Fatalf("%s: error code mismatch: have %d (%v), want %d (%v)", method, apiErr.ErrorCode(), apiErr, want.ErrorCode(), want)
+	}
+}
+
+// Tests that cancun payloads are built, retrieved and imported through the V3
+// engine API methods, while the older and newer versions reject them.
+func TestCancunPayloads(t *testing.T) {
+	genesis, blocks := generateMergeChain(10, true)
+	// Set shanghai and cancun time to last block + 5 seconds (first post-merge block)
+	time := blocks[len(blocks)-1].Time() + 5
+	genesis.Config.ShanghaiTime = &time
+	genesis.Config.CancunTime = &time
+
+	n, ethservice := startEthService(t, genesis, blocks)
+	ethservice.Merger().ReachTTD()
+	defer n.Close()
+
+	api := NewConsensusAPI(ethservice)
+
+	var (
+		parent      = ethservice.BlockChain().CurrentHeader()
+		beaconRoot  = common.Hash{0x42}
+		fcState     = engine.ForkchoiceStateV1{HeadBlockHash: parent.Hash()}
+		blockParams = engine.PayloadAttributes{
+			Timestamp:   parent.Time + 5,
+			Withdrawals: make([]*types.Withdrawal, 0),
+		}
+	)
+	// Payload attributes without a beacon root are rejected by V3, with one by V2
+	if _, err := api.ForkchoiceUpdatedV3(fcState, &blockParams); err == nil {
+		t.Fatal("forkchoiceUpdatedV3 accepted attributes without beacon root")
+	} else {
+		checkEngineError(t, "forkchoiceUpdatedV3", err, engine.InvalidPayloadAttributes)
+	}
+	if _, err := api.ForkchoiceUpdatedV2(fcState, &blockParams); err == nil {
+		t.Fatal("forkchoiceUpdatedV2 accepted cancun attributes")
+	} else {
+		checkEngineError(t, "forkchoiceUpdatedV2", err, engine.UnsupportedFork)
+	}
+	blockParams.BeaconRoot = &beaconRoot
+	resp, err := api.ForkchoiceUpdatedV3(fcState, &blockParams)
+	if err != nil {
+		t.Fatalf("error preparing payload, err=%v", err)
+	}
+	if resp.PayloadStatus.Status != engine.VALID {
+		t.Fatalf("unexpected status (got: %s, want: %s)", resp.PayloadStatus.Status, engine.VALID)
+	}
+	payloadID := (&miner.BuildPayloadArgs{
+		Parent:       fcState.HeadBlockHash,
+		Timestamp:    blockParams.Timestamp,
+		FeeRecipient: blockParams.SuggestedFeeRecipient,
+		Random:       blockParams.Random,
+		Withdrawals:  blockParams.Withdrawals,
+		BeaconRoot:   blockParams.BeaconRoot,
+	}).Id()
+	if *resp.PayloadID != payloadID {
+		t.Fatalf("payload id mismatch: have %v, want %v", *resp.PayloadID, payloadID)
+	}
+	// Only V3 may retrieve the built cancun payload
+	if _, err := api.GetPayloadV2(payloadID); err == nil {
+		t.Fatal("getPayloadV2 returned cancun payload")
+	} else {
+		checkEngineError(t, "getPayloadV2", err, engine.UnsupportedFork)
+	}
+	if _, err := api.GetPayloadV4(payloadID); err == nil {
+		t.Fatal("getPayloadV4 returned cancun payload")
+	} else {
+		checkEngineError(t, "getPayloadV4", err, engine.UnsupportedFork)
+	}
+	envelope, err := api.GetPayloadV3(payloadID)
+	if err != nil {
+		t.Fatalf("error getting payload, err=%v", err)
+	}
+	payload := envelope.ExecutionPayload
+	if payload.ExcessBlobGas == nil || payload.BlobGasUsed == nil {
+		t.Fatalf("missing blob gas fields: excess %v, used %v", payload.ExcessBlobGas, payload.BlobGasUsed)
+	}
+	if envelope.BlobsBundle == nil {
+		t.Fatal("missing blobs bundle")
+	}
+	// Only V3 may import the cancun payload, with all the mandatory fields set
+	if _, err := api.NewPayloadV2(*payload); err == nil {
+		t.Fatal("newPayloadV2 accepted cancun payload")
+	} else {
+		checkEngineError(t, "newPayloadV2", err, engine.UnsupportedFork)
+	}
+	if _, err := api.NewPayloadV3(*payload, []common.Hash{}, nil); err == nil {
+		t.Fatal("newPayloadV3 accepted payload without beacon root")
+	} else {
+		checkEngineError(t, "newPayloadV3", err, engine.InvalidParams)
+	}
+	if _, err := api.NewPayloadV4(*payload, []common.Hash{}, &beaconRoot, []hexutil.Bytes{}); err == nil {
+		t.Fatal("newPayloadV4 accepted cancun payload")
+	} else {
+		checkEngineError(t, "newPayloadV4", err, engine.UnsupportedFork)
+	}
+	// The header commits to the beacon root, so any other one is rejected
+	otherRoot := common.Hash{0x02}
+	if status, err := api.NewPayloadV3(*payload, []common.Hash{}, &otherRoot); err != nil {
+		t.Fatalf("error validating payload: %v", err)
+	} else if status.Status != engine.INVALID {
+		t.Fatalf("payload with mismatching beacon root not rejected: %v", status)
+	}
+	status, err := api.NewPayloadV3(*payload, []common.Hash{}, &beaconRoot)
+	if err != nil {
+		t.Fatalf("error validating payload: %v", err)
+	}
+	if status.Status != engine.VALID {
+		t.Fatalf("invalid payload: %v", status)
+	}
+	fcState.HeadBlockHash = payload.BlockHash
+	if _, err := api.ForkchoiceUpdatedV3(fcState, nil); err != nil {
+		t.Fatalf("error setting head: %v", err)
+	}
+	head := ethservice.BlockChain().CurrentBlock()
+	if head.Hash() != payload.BlockHash {
+		t.Fatalf("head mismatch: have %x, want %x", head.Hash(), payload.BlockHash)
+	}
+	if head.ParentBeaconRoot == nil || *head.ParentBeaconRoot != beaconRoot {
+		t.Fatalf("beacon root mismatch: have %v, want %x", head.ParentBeaconRoot, beaconRoot)
+	}
+}
@@ -32,10 +32,26 @@ import (
 	"github.com/gorievm/go-gori/rpc"
 )
 
+// maxScheduledWithdrawals is the maximum number of withdrawals that can be
+// scheduled for inclusion in future blocks.
+const maxScheduledWithdrawals = 1024
+
 // withdrawalQueue implements a FIFO queue which holds withdrawals that are
-// pending inclusion.
+// pending inclusion, along with withdrawals scheduled for specific blocks.
 type withdrawalQueue struct {
 	pending chan *types.Withdrawal
+
+	scheduled map[uint64][]*types.Withdrawal // Withdrawals to include at specific block numbers
+	count     int                            // Number of scheduled withdrawals
+	lock      sync.Mutex                     // Lock protecting the scheduled withdrawals
+}
+
+// newWithdrawalQueue creates an empty withdrawal queue.
+func newWithdrawalQueue() *withdrawalQueue {
+	return &withdrawalQueue{
+		pending:   make(chan *types.Withdrawal, 20),
+		scheduled: make(map[uint64][]*types.Withdrawal),
+	}
 }
 
 // add queues a withdrawal for future inclusion.
@@ -65,11 +81,59 @@ func (w *withdrawalQueue) gatherPending(maxCount int) []*types.Withdrawal {
 	}
 }
 
+// schedule queues a withdrawal for inclusion in the block with the given number.
+func (w *withdrawalQueue) schedule(number uint64, withdrawal *types.Withdrawal) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.count >= maxScheduledWithdrawals {
+		return errors.New("withdrawal schedule full")
+	}
+	w.scheduled[number] = append(w.scheduled[number], withdrawal)
+	w.count++
+	return nil
+}
+
+// scheduledFor returns the withdrawals scheduled for the block with the given
+// number. They stay queued until dropped after the block was sealed.
+func (w *withdrawalQueue) scheduledFor(number uint64) []*types.Withdrawal {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return append([]*types.Withdrawal(nil), w.scheduled[number]...)
+}
+
+// dropScheduled removes the withdrawals scheduled for the block with the given
+// number, once it was sealed.
+func (w *withdrawalQueue) dropScheduled(number uint64) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.count -= len(w.scheduled[number])
+	delete(w.scheduled, number)
+}
+
+// schedules returns a copy of the withdrawals scheduled for future blocks.
+func (w *withdrawalQueue) schedules() map[uint64][]*types.Withdrawal {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	schedules := make(map[uint64][]*types.Withdrawal, len(w.scheduled))
+	for number, withdrawals := range w.scheduled {
+		schedules[number] = append([]*types.Withdrawal(nil), withdrawals...)
+	}
+	return schedules
+}
+
 type SimulatedBeacon struct {
 	shutdownCh  chan struct{}
 	eth         *eth.Ori
-	period      uint64
-	withdrawals withdrawalQueue
+	withdrawals *withdrawalQueue
+
+	period      uint64        // Slot time in seconds, zero for on-demand sealing
+	missedSlots uint64        // Number of upcoming slots to miss without sealing
+	slotLock    sync.Mutex    // Lock protecting the slot configuration
+	slotUpdate  chan struct{} // Notification channel to reschedule the next slot
 
 	feeRecipient     common.Address
 	feeRecipientLock sync.Mutex // lock gates concurrent access to the feeRecipient
@@ -105,7 +169,8 @@ func NewSimulatedBeacon(period uint64, eth *eth.Ori) (*SimulatedBeacon, error) {
 		engineAPI:          engineAPI,
 		lastBlockTime:      block.Time,
 		curForkchoiceState: current,
-		withdrawals:        withdrawalQueue{make(chan *types.Withdrawal, 20)},
+		withdrawals:        newWithdrawalQueue(),
+		slotUpdate:         make(chan struct{}, 1),
 	}, nil
 }
 
@@ -115,8 +180,68 @@ func (c *SimulatedBeacon) setFeeRecipient(feeRecipient common.Address) {
 	c.feeRecipientLock.Unlock()
 }
 
+// setSlotTime changes the slot time of a periodic simulated beacon, rescheduling
+// the next slot accordingly.
+func (c *SimulatedBeacon) setSlotTime(seconds uint64) error {
+	if seconds == 0 {
+		return errors.New("zero slot time")
+	}
+	c.slotLock.Lock()
+	defer c.slotLock.Unlock()
+
+	if c.period == 0 {
+		return errors.New("slot time cannot be changed in on-demand mode")
+	}
+	c.period = seconds
+	select {
+	case c.slotUpdate <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// slotTime returns the current slot time of a periodic simulated beacon.
+func (c *SimulatedBeacon) slotTime() time.Duration {
+	c.slotLock.Lock()
+	defer c.slotLock.Unlock()
+
+	return time.Second * time.Duration(c.period)
+}
+
+// missSlots injects the given number of missed slots. In periodic mode the next
+// slots elapse without a block, whereas in on-demand mode the timestamp of the
+// next block skips ahead by one second per missed slot.
+func (c *SimulatedBeacon) missSlots(count uint64) {
+	c.slotLock.Lock()
+	c.missedSlots += count
+	c.slotLock.Unlock()
+}
+
+// skipSlot consumes a missed slot if any is pending.
+func (c *SimulatedBeacon) skipSlot() bool {
+	c.slotLock.Lock()
+	defer c.slotLock.Unlock()
+
+	if c.missedSlots == 0 {
+		return false
+	}
+	c.missedSlots--
+	return true
+}
+
+// scheduleWithdrawal queues a withdrawal for inclusion in a future block.
+func (c *SimulatedBeacon) scheduleWithdrawal(number uint64, withdrawal *types.Withdrawal) error {
+	if head := c.eth.BlockChain().CurrentBlock().Number.Uint64(); number <= head {
+		return fmt.Errorf("block %d already sealed, head is %d", number, head)
+	}
+	return c.withdrawals.schedule(number, withdrawal)
+}
+
 // Start invokes the SimulatedBeacon life-cycle function in a goroutine.
 func (c *SimulatedBeacon) Start() error {
+	c.slotLock.Lock()
+	defer c.slotLock.Unlock()
+
 	if c.period == 0 {
 		go c.loopOnDemand()
 	} else {
@@ -138,15 +263,37 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal) error {
 	if tstamp <= c.lastBlockTime {
 		tstamp = c.lastBlockTime + 1
 	}
+	// Without a slot clock to skip, missed slots in on-demand mode are simulated
+	// by advancing the timestamp by a second per missed slot
+	c.slotLock.Lock()
+	if c.period == 0 && c.missedSlots > 0 {
+		if skipped := c.lastBlockTime + c.missedSlots + 1; tstamp < skipped {
+			tstamp = skipped
+		}
+		c.missedSlots = 0
+	}
+	c.slotLock.Unlock()
+
+	// Include any withdrawals scheduled for this block ahead of the queued ones
+	number := c.eth.BlockChain().CurrentBlock().Number.Uint64() + 1
+	if scheduled := c.withdrawals.scheduledFor(number); len(scheduled) > 0 {
+		withdrawals = append(scheduled, withdrawals...)
+	}
 	c.feeRecipientLock.Lock()
 	feeRecipient := c.feeRecipient
 	c.feeRecipientLock.Unlock()
 
-	fcResponse, err := c.engineAPI.ForkchoiceUpdatedV2(c.curForkchoiceState, &engine.PayloadAttributes{
+	// Post-cancun payloads need a beacon root, but there's no beacon chain to
+	// source it from, so the zero hash is used
+	attributes := &engine.PayloadAttributes{
 		Timestamp:             tstamp,
 		SuggestedFeeRecipient: feeRecipient,
 		Withdrawals:           withdrawals,
-	})
+	}
+	if c.eth.BlockChain().Config().IsCancun(c.eth.BlockChain().Config().LondonBlock, tstamp) {
+		attributes.BeaconRoot = new(common.Hash)
+	}
+	fcResponse, err := c.engineAPI.forkchoiceUpdated(c.curForkchoiceState, attributes)
 	if err != nil {
 		return fmt.Errorf("error calling forkchoice update: %v", err)
 	}
@@ -157,8 +304,21 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal) error {
 	}
 	payload := envelope.ExecutionPayload
 
+	var versionedHashes []common.Hash
+	for _, enc := range payload.Transactions {
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(enc); err != nil {
+			return fmt.Errorf("invalid payload transaction: %v", err)
+		}
+		versionedHashes = append(versionedHashes, tx.BlobHashes()...)
+	}
+	// Prague payloads commit to their (always empty) execution requests
+	var requests [][]byte
+	if c.engineAPI.isPrague(payload) {
+		requests = make([][]byte, 0)
+	}
 	// mark the payload as canon
-	if _, err = c.engineAPI.NewPayloadV2(*payload); err != nil {
+	if _, err = c.engineAPI.newPayload(*payload, versionedHashes, attributes.BeaconRoot, requests); err != nil {
 		return fmt.Errorf("failed to mark payload as canonical: %v", err)
 	}
 	c.curForkchoiceState = engine.ForkchoiceStateV1{
@@ -167,10 +327,11 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal) error {
 		FinalizedBlockHash: payload.BlockHash,
 	}
 	// mark the block containing the payload as canonical
-	if _, err = c.engineAPI.ForkchoiceUpdatedV2(c.curForkchoiceState, nil); err != nil {
+	if _, err = c.engineAPI.forkchoiceUpdated(c.curForkchoiceState, nil); err != nil {
 		return fmt.Errorf("failed to mark block as canonical: %v", err)
 	}
 	c.lastBlockTime = payload.Timestamp
+	c.withdrawals.dropScheduled(number)
 	return nil
 }
 
@@ -202,20 +363,34 @@ func (c *SimulatedBeacon) loopOnDemand() {
 	}
 }
 
-// loopOnDemand runs the block production loop for non-zero period configuration
+// loop runs the block production loop for non-zero period configuration
 func (c *SimulatedBeacon) loop() {
 	timer := time.NewTimer(0)
+	defer timer.Stop()
+
 	for {
 		select {
 		case <-c.shutdownCh:
 			return
+		case <-c.slotUpdate:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(c.slotTime())
 		case <-timer.C:
-			withdrawals := c.withdrawals.gatherPending(10)
-			if err := c.sealBlock(withdrawals); err != nil {
-				log.Error("Error performing sealing-work", "err", err)
-				return
+			if c.skipSlot() {
+				log.Info("Simulating missed slot", "number", c.eth.BlockChain().CurrentBlock().Number.Uint64()+1)
+			} else {
+				withdrawals := c.withdrawals.gatherPending(10)
+				if err := c.sealBlock(withdrawals); err != nil {
+					log.Error("Error performing sealing-work", "err", err)
+					return
+				}
 			}
-			timer.Reset(time.Second * time.Duration(c.period))
+			timer.Reset(c.slotTime())
 		}
 	}
 }
@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
 	"github.com/gorievm/go-gori/core/types"
 )
 
@@ -34,3 +35,29 @@ func (a *api) AddWithdrawal(ctx context.Context, withdrawal *types.Withdrawal) e
 func (a *api) SetFeeRecipient(ctx context.Context, feeRecipient common.Address) {
 	a.simBeacon.setFeeRecipient(feeRecipient)
 }
+
+// ScheduleWithdrawal queues a withdrawal for inclusion in the block with the
+// given number, which must not be sealed yet.
+func (a *api) ScheduleWithdrawal(ctx context.Context, withdrawal *types.Withdrawal, number hexutil.Uint64) error {
+	return a.simBeacon.scheduleWithdrawal(uint64(number), withdrawal)
+}
+
+// ScheduledWithdrawals returns the withdrawals scheduled for future blocks,
+// keyed by block number.
+func (a *api) ScheduledWithdrawals(ctx context.Context) map[hexutil.Uint64][]*types.Withdrawal {
+	schedules := make(map[hexutil.Uint64][]*types.Withdrawal)
+	for number, withdrawals := range a.simBeacon.withdrawals.schedules() {
+		schedules[hexutil.Uint64(number)] = withdrawals
+	}
+	return schedules
+}
+
+// SetSlotTime changes the slot time in seconds of a periodic simulated beacon.
+func (a *api) SetSlotTime(ctx context.Context, seconds uint64) error {
+	return a.simBeacon.setSlotTime(seconds)
+}
+
+// MissSlots makes the simulated beacon miss the given number of upcoming slots.
+func (a *api) MissSlots(ctx context.Context, count uint64) {
+	a.simBeacon.missSlots(count)
+}
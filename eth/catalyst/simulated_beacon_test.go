@@ -34,7 +34,7 @@ import (
 	"github.com/gorievm/go-gori/params"
 )
 
-func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis) (*node.Node, *eth.Ori, *SimulatedBeacon) {
+func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis, period uint64) (*node.Node, *eth.Ori, *SimulatedBeacon) {
 	t.Helper()
 
 	n, err := node.New(&node.Config{
@@ -54,7 +54,7 @@ func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis) (*node.
 		t.Fatal("can't create eth service:", err)
 	}
 
-	simBeacon, err := NewSimulatedBeacon(1, ethservice)
+	simBeacon, err := NewSimulatedBeacon(period, ethservice)
 	if err != nil {
 		t.Fatal("can't create simulated beacon:", err)
 	}
@@ -86,7 +86,7 @@ func TestSimulatedBeaconSendWithdrawals(t *testing.T) {
 	// short period (1 second) for testing purposes
 	var gasLimit uint64 = 10_000_000
 	genesis := core.DeveloperGenesisBlock(gasLimit, testAddr)
-	node, ethService, mock := startSimulatedBeaconEthService(t, genesis)
+	node, ethService, mock := startSimulatedBeaconEthService(t, genesis, 1)
 	_ = mock
 	defer node.Close()
 
@@ -139,3 +139,56 @@ func TestSimulatedBeaconSendWithdrawals(t *testing.T) {
 		}
 	}
 }
+
+// Tests that in on-demand mode missed slots advance the timestamp of the next
+// block and that scheduled withdrawals are included in their designated block.
+func TestSimulatedBeaconOnDemandSlots(t *testing.T) {
+	genesis := core.DeveloperGenesisBlock(10_000_000, common.Address{})
+	node, ethService, mock := startSimulatedBeaconEthService(t, genesis, 0)
+	defer node.Close()
+
+	chainHeadCh := make(chan core.ChainHeadEvent, 10)
+	subscription := ethService.BlockChain().SubscribeChainHeadEvent(chainHeadCh)
+	defer subscription.Unsubscribe()
+
+	if err := mock.setSlotTime(12); err == nil {
+		t.Fatal("slot time changed in on-demand mode")
+	}
+	scheduled := &types.Withdrawal{Index: 7, Validator: 42, Address: common.Address{0xaa}, Amount: 100}
+	if err := mock.scheduleWithdrawal(2, scheduled); err != nil {
+		t.Fatalf("failed to schedule withdrawal: %v", err)
+	}
+	// Seal directly rather than through the on-demand loop to keep the block
+	// production deterministic
+	seal := func() *types.Block {
+		if err := mock.sealBlock(nil); err != nil {
+			t.Fatalf("failed to seal block: %v", err)
+		}
+		select {
+		case evt := <-chainHeadCh:
+			return evt.Block
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for block")
+		}
+		return nil
+	}
+	first := seal()
+	if len(first.Withdrawals()) != 0 {
+		t.Fatalf("block %d: unexpected withdrawals: %v", first.NumberU64(), first.Withdrawals())
+	}
+	if err := mock.scheduleWithdrawal(first.NumberU64(), scheduled); err == nil {
+		t.Fatal("withdrawal scheduled for sealed block")
+	}
+	mock.missSlots(5)
+
+	second := seal()
+	if second.Time() < first.Time()+6 {
+		t.Fatalf("missed slots not reflected in timestamp: have %d, want >= %d", second.Time(), first.Time()+6)
+	}
+	if ws := second.Withdrawals(); len(ws) != 1 || ws[0].Index != scheduled.Index {
+		t.Fatalf("block %d: scheduled withdrawal missing: %v", second.NumberU64(), ws)
+	}
+	if schedules := mock.withdrawals.schedules(); len(schedules) != 0 {
+		t.Fatalf("scheduled withdrawals left over: %v", schedules)
+	}
+}
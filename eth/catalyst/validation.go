@@ -0,0 +1,171 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package catalyst
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/gorievm/go-gori/beacon/engine"
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/eth"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/node"
+	"github.com/gorievm/go-gori/rpc"
+)
+
+// maxConcurrentValidations is the maximum number of payloads validated at the
+// same time, further requests wait for a slot to free up.
+const maxConcurrentValidations = 4
+
+// RegisterValidationAPI adds the payload validation API to the authenticated
+// endpoint of the full node.
+func RegisterValidationAPI(stack *node.Node, backend *eth.Ori) {
+	log.Info("Payload validation API enabled", "namespace", "validation")
+	stack.RegisterAPIs([]rpc.API{
+		{
+			Namespace:     "validation",
+			Service:       NewValidationAPI(backend),
+			Authenticated: true,
+		},
+	})
+}
+
+// ValidationAPI allows external parties, such as relays and block builders, to
+// check candidate payloads against the local chain. Payloads are executed on a
+// throwaway copy of their parent state, they are never imported and do not
+// affect the canonical chain in any way.
+type ValidationAPI struct {
+	eth   *eth.Ori
+	slots chan struct{} // Semaphore limiting the concurrent validations
+}
+
+// NewValidationAPI creates a new payload validation API for the given backend.
+func NewValidationAPI(eth *eth.Ori) *ValidationAPI {
+	return &ValidationAPI{
+		eth:   eth,
+		slots: make(chan struct{}, maxConcurrentValidations),
+	}
+}
+
+// PayloadValidation is the outcome of validating a candidate payload, along with
+// the gas and fee breakdown of valid ones.
+type PayloadValidation struct {
+	Valid        bool                    `json:"valid"`
+	Error        string                  `json:"error,omitempty"`
+	BlockHash    common.Hash             `json:"blockHash"`
+	GasUsed      hexutil.Uint64          `json:"gasUsed"`
+	GasLimit     hexutil.Uint64          `json:"gasLimit"`
+	BaseFee      *hexutil.Big            `json:"baseFeePerGas,omitempty"`
+	BurntFees    *hexutil.Big            `json:"burntFees,omitempty"`
+	PriorityFees *hexutil.Big            `json:"priorityFees,omitempty"`
+	Profit       *hexutil.Big            `json:"feeRecipientProfit,omitempty"`
+	Transactions []*TransactionFeeReport `json:"transactions,omitempty"`
+}
+
+// TransactionFeeReport is the gas and fee breakdown of a single transaction in a
+// validated payload.
+type TransactionFeeReport struct {
+	Hash        common.Hash    `json:"hash"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	PriorityFee *hexutil.Big   `json:"priorityFee"`
+	BurntFee    *hexutil.Big   `json:"burntFee"`
+}
+
+// ValidatePayloadV1 fully validates a candidate payload on top of its parent and
+// reports its validity along with the fees it pays. Invalid payloads are not an
+// error of the call, their rejection reason is returned in the result.
+//
+// The fee recipient profit is the balance change of the fee recipient over the
+// block, including any direct transfers to it, not just the priority fees.
+// Post-cancun payloads must be accompanied by their parent beacon block root.
+func (api *ValidationAPI) ValidatePayloadV1(ctx context.Context, params engine.ExecutableData, versionedHashes *[]common.Hash, beaconRoot *common.Hash) (*PayloadValidation, error) {
+	select {
+	case api.slots <- struct{}{}:
+		defer func() { <-api.slots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var hashes []common.Hash
+	if versionedHashes != nil {
+		hashes = *versionedHashes
+	}
+	// Prague payloads commit to their execution requests, which Gori never has
+	var requests [][]byte
+	if api.eth.BlockChain().Config().IsPrague(new(big.Int).SetUint64(params.Number), params.Timestamp) {
+		requests = make([][]byte, 0)
+	}
+	block, err := engine.ExecutableDataToBlock(params, hashes, beaconRoot, requests)
+	if err != nil {
+		return &PayloadValidation{Error: err.Error(), BlockHash: params.BlockHash}, nil
+	}
+	result := &PayloadValidation{
+		BlockHash: block.Hash(),
+		GasLimit:  hexutil.Uint64(block.GasLimit()),
+	}
+	chain := api.eth.BlockChain()
+	statedb, receipts, usedGas, err := chain.ValidateBlock(block)
+	if err != nil {
+		log.Debug("Rejected candidate payload", "number", block.Number(), "hash", block.Hash(), "err", err)
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Valid = true
+	result.GasUsed = hexutil.Uint64(usedGas)
+
+	// Assemble the fee breakdown of the individual transactions
+	var (
+		baseFee   = block.BaseFee()
+		burnt     = new(big.Int)
+		priority  = new(big.Int)
+		coinbase  = block.Coinbase()
+		txs       = block.Transactions()
+		gasBigInt = new(big.Int)
+	)
+	for i, receipt := range receipts {
+		gasBigInt.SetUint64(receipt.GasUsed)
+
+		report := &TransactionFeeReport{
+			Hash:    txs[i].Hash(),
+			GasUsed: hexutil.Uint64(receipt.GasUsed),
+		}
+		tip := txs[i].EffectiveGasTipValue(baseFee)
+		report.PriorityFee = (*hexutil.Big)(new(big.Int).Mul(tip, gasBigInt))
+		priority.Add(priority, report.PriorityFee.ToInt())
+
+		if baseFee != nil {
+			report.BurntFee = (*hexutil.Big)(new(big.Int).Mul(baseFee, gasBigInt))
+			burnt.Add(burnt, report.BurntFee.ToInt())
+		} else {
+			report.BurntFee = (*hexutil.Big)(new(big.Int))
+		}
+		result.Transactions = append(result.Transactions, report)
+	}
+	result.BaseFee = (*hexutil.Big)(baseFee)
+	result.BurntFees = (*hexutil.Big)(burnt)
+	result.PriorityFees = (*hexutil.Big)(priority)
+
+	// Measure the fee recipient profit against the parent state
+	parent := chain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	prestate, err := chain.StateAt(parent.Root)
+	if err != nil {
+		return nil, err
+	}
+	result.Profit = (*hexutil.Big)(new(big.Int).Sub(statedb.GetBalance(coinbase), prestate.GetBalance(coinbase)))
+	return result, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package catalyst
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/beacon/engine"
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/params"
+)
+
+// Tests that candidate payloads are validated without being imported, and that
+// the fee breakdown of valid ones is reported.
+func TestValidatePayload(t *testing.T) {
+	genesis, blocks := generateMergeChain(11, true)
+	n, ethservice := startEthService(t, genesis, blocks[:10])
+	defer n.Close()
+
+	var (
+		api     = NewValidationAPI(ethservice)
+		block   = blocks[10]
+		payload = engine.BlockToExecutableData(block, nil, nil, nil, nil).ExecutionPayload
+	)
+	result, err := api.ValidatePayloadV1(context.Background(), *payload, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to validate payload: %v", err)
+	}
+	if !result.Valid {
+		t.Fatalf("valid payload rejected: %s", result.Error)
+	}
+	if uint64(result.GasUsed) != params.TxGas || len(result.Transactions) != 1 {
+		t.Fatalf("gas usage mismatch: have %d gas in %d txs", result.GasUsed, len(result.Transactions))
+	}
+	var (
+		tx      = block.Transactions()[0]
+		baseFee = block.BaseFee()
+		tip     = new(big.Int).Sub(tx.GasPrice(), baseFee)
+		want    = new(big.Int).Mul(tip, big.NewInt(int64(params.TxGas)))
+		burnt   = new(big.Int).Mul(baseFee, big.NewInt(int64(params.TxGas)))
+	)
+	if result.PriorityFees.ToInt().Cmp(want) != 0 || result.Profit.ToInt().Cmp(want) != 0 {
+		t.Errorf("priority fee mismatch: have %v (profit %v), want %v", result.PriorityFees, result.Profit, want)
+	}
+	if result.BurntFees.ToInt().Cmp(burnt) != 0 {
+		t.Errorf("burnt fee mismatch: have %v, want %v", result.BurntFees, burnt)
+	}
+	// Validation must not affect the chain in any way
+	chain := ethservice.BlockChain()
+	if chain.CurrentBlock().Hash() != blocks[9].Hash() {
+		t.Errorf("chain head changed by validation")
+	}
+	if chain.HasBlock(block.Hash(), block.NumberU64()) || chain.HasState(block.Root()) {
+		t.Errorf("validated payload persisted")
+	}
+	// Payloads with a bad post state should be rejected
+	payload.StateRoot = common.Hash{0x01}
+	setBlockhash(payload)
+
+	result, err = api.ValidatePayloadV1(context.Background(), *payload, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to validate payload: %v", err)
+	}
+	if result.Valid || result.Error == "" {
+		t.Errorf("invalid payload accepted")
+	}
+}
@@ -119,6 +119,35 @@ func (api *DownloaderAPI) Syncing(ctx context.Context) (*rpc.Subscription, error
 	return rpcSub, nil
 }
 
+// SyncProgress provides the detailed synchronisation progress, including the
+// per-phase throughput, peer assignments and estimated time remaining, which is
+// posted periodically while the node is synchronising.
+func (api *DownloaderAPI) SyncProgress(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		progress := make(chan ProgressEvent, 16)
+		sub := api.d.SubscribeProgress(progress)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-progress:
+				notifier.Notify(rpcSub.ID, ev)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
 // SyncingResult provides information about the current synchronisation status for this node.
 type SyncingResult struct {
 	Syncing bool                  `json:"syncing"`
@@ -0,0 +1,150 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/eth/protocols/eth"
+	"github.com/gorievm/go-gori/log"
+)
+
+var (
+	errNoCheckpointPeer  = errors.New("no peer serves the checkpoint header")
+	errInvalidCheckpoint = errors.New("invalid checkpoint")
+)
+
+// Checkpoint is a block trusted by the node operator, from which the chain can
+// be bootstrapped without a consensus client driving the beacon sync. Its text
+// form is "<number>:<hash>".
+type Checkpoint struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+// String implements fmt.Stringer.
+func (c Checkpoint) String() string {
+	return fmt.Sprintf("%d:%s", c.Number, c.Hash.Hex())
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Checkpoint) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *Checkpoint) UnmarshalText(input []byte) error {
+	number, hash, ok := strings.Cut(string(input), ":")
+	if !ok {
+		return fmt.Errorf("%w: want <number>:<hash>, have %q", errInvalidCheckpoint, input)
+	}
+	n, err := strconv.ParseUint(number, 0, 64)
+	if err != nil {
+		return fmt.Errorf("%w: number: %v", errInvalidCheckpoint, err)
+	}
+	var h common.Hash
+	if err := h.UnmarshalText([]byte(hash)); err != nil {
+		return fmt.Errorf("%w: hash: %v", errInvalidCheckpoint, err)
+	}
+	if n == 0 || h == (common.Hash{}) {
+		return fmt.Errorf("%w: empty number or hash", errInvalidCheckpoint)
+	}
+	c.Number, c.Hash = n, h
+	return nil
+}
+
+// CheckpointSync is a version of the beacon sync, where the trusted head is not
+// announced by a consensus client, rather configured by the operator. The header
+// of the checkpoint is retrieved from the connected peers, after which headers
+// are downloaded backward from it and, in snap sync mode, the state is synced at
+// the pivot right below it.
+//
+// The method returns once the sync has been scheduled, the progress can be
+// tracked as with any beacon sync.
+func (d *Downloader) CheckpointSync(mode SyncMode, checkpoint Checkpoint) error {
+	header, err := d.fetchCheckpoint(checkpoint)
+	if err != nil {
+		return err
+	}
+	return d.BeaconSync(mode, header, header)
+}
+
+// fetchCheckpoint retrieves the header of the checkpoint from the first peer
+// serving one with the trusted hash. Peers on a different chain, or not synced
+// up to the checkpoint yet are skipped.
+func (d *Downloader) fetchCheckpoint(checkpoint Checkpoint) (*types.Header, error) {
+	for _, peer := range d.peers.AllPeers() {
+		header, err := d.fetchCheckpointFrom(peer, checkpoint.Number)
+		if err != nil {
+			peer.log.Debug("Failed to retrieve checkpoint header", "number", checkpoint.Number, "err", err)
+			continue
+		}
+		if header == nil {
+			continue // Peer is not synced up to the checkpoint
+		}
+		if hash := header.Hash(); hash != checkpoint.Hash {
+			peer.log.Debug("Peer on different chain than checkpoint", "number", checkpoint.Number, "have", hash, "want", checkpoint.Hash)
+			continue
+		}
+		log.Debug("Retrieved checkpoint header", "peer", peer.id, "number", checkpoint.Number, "hash", checkpoint.Hash)
+		return header, nil
+	}
+	return nil, errNoCheckpointPeer
+}
+
+// fetchCheckpointFrom requests a single header by number from a peer, returning
+// nil if the peer doesn't have it. Contrary to fetchHeadersByNumber, it may be
+// called outside of a sync cycle.
+func (d *Downloader) fetchCheckpointFrom(p *peerConnection, number uint64) (*types.Header, error) {
+	resCh := make(chan *eth.Response)
+
+	req, err := p.peer.RequestHeadersByNumber(number, 1, 0, false, resCh)
+	if err != nil {
+		return nil, err
+	}
+	defer req.Close()
+
+	timeoutTimer := time.NewTimer(d.peers.rates.TargetTimeout())
+	defer timeoutTimer.Stop()
+
+	select {
+	case <-d.quitCh:
+		return nil, errCanceled
+
+	case <-timeoutTimer.C:
+		headerTimeoutMeter.Mark(1)
+		return nil, errTimeout
+
+	case res := <-resCh:
+		res.Done <- nil
+
+		headers := *res.Res.(*eth.BlockHeadersPacket)
+		switch {
+		case len(headers) == 0:
+			return nil, nil
+		case len(headers) > 1 || headers[0].Number.Uint64() != number:
+			return nil, errBadPeer
+		}
+		return headers[0], nil
+	}
+}
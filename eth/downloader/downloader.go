@@ -56,6 +56,8 @@ var (
 	fsHeaderSafetyNet = 2048            // Number of headers to discard in case a chain violation is detected
 	fsHeaderContCheck = 3 * time.Second // Time interval to check for header continuations during state download
 	fsMinFullBlocks   = 64              // Number of blocks to retrieve fully even in snap sync
+
+	progressInterval = time.Second // Time interval to post the detailed sync progress
 )
 
 var (
@@ -153,9 +155,10 @@ type Downloader struct {
 	chainInsertHook  func([]*fetchResult)  // Method to call upon inserting a chain of blocks (possibly in multiple invocations)
 
 	// Progress reporting metrics
-	syncStartBlock uint64    // Head snap block when Geth was started
-	syncStartTime  time.Time // Time instance when chain sync started
-	syncLogTime    time.Time // Time instance when status was last reported
+	syncStartBlock uint64     // Head snap block when Geth was started
+	syncStartTime  time.Time  // Time instance when chain sync started
+	syncLogTime    time.Time  // Time instance when status was last reported
+	progressFeed   event.Feed // Feed to announce the detailed sync progress
 }
 
 // LightChain encapsulates functions required to synchronise a light chain.
@@ -286,6 +289,82 @@ func (d *Downloader) Progress() ethereum.SyncProgress {
 	}
 }
 
+// SubscribeProgress subscribes to the detailed sync progress, which is posted
+// periodically while the downloader is synchronising.
+func (d *Downloader) SubscribeProgress(ch chan<- ProgressEvent) event.Subscription {
+	return d.progressFeed.Subscribe(ch)
+}
+
+// reportProgress periodically posts the detailed sync progress to the progress
+// feed, until the stop channel is closed.
+func (d *Downloader) reportProgress(stop chan struct{}) {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	var (
+		start     = time.Now()
+		last      = start
+		delivered = make(map[SyncPhase]uint64)
+	)
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			progress := d.progressEvent(now.Sub(start), now.Sub(last), delivered)
+			last = now
+			d.progressFeed.Send(progress)
+		}
+	}
+}
+
+// progressEvent assembles the detailed sync progress. The throughput of each
+// phase is calculated from the items delivered since the previous report, the
+// delivery counts of which are updated in place.
+func (d *Downloader) progressEvent(elapsed, interval time.Duration, delivered map[SyncPhase]uint64) ProgressEvent {
+	var (
+		mode          = d.getMode()
+		status        = d.Progress()
+		phases, peers = d.queue.Progress()
+	)
+	if mode == SnapSync {
+		phases = append(phases, PhaseProgress{
+			Phase:     StatePhase,
+			Delivered: status.SyncedAccounts + status.SyncedStorage + status.SyncedBytecodes,
+			Bytes:     status.SyncedAccountBytes + status.SyncedStorageBytes + status.SyncedBytecodeBytes,
+		}, PhaseProgress{
+			Phase:     HealPhase,
+			Pending:   int(status.HealingTrienodes + status.HealingBytecode),
+			Delivered: status.HealedTrienodes + status.HealedBytecodes,
+			Bytes:     status.HealedTrienodeBytes + status.HealedBytecodeBytes,
+		})
+	}
+	for i, phase := range phases {
+		if interval > 0 && phase.Delivered >= delivered[phase.Phase] {
+			phases[i].Throughput = float64(phase.Delivered-delivered[phase.Phase]) / interval.Seconds()
+		}
+		delivered[phase.Phase] = phase.Delivered
+
+		// Estimate the time to drain the known backlog of the phase at the
+		// current pace. The state retrieval backlog is unknown upfront.
+		if pending := phase.Pending + phase.InFlight; pending > 0 && phases[i].Throughput > 0 {
+			phases[i].ETA = time.Duration(float64(pending) / phases[i].Throughput * float64(time.Second))
+		}
+	}
+	// Estimate the remaining time based on the chain progress so far
+	var eta time.Duration
+	if synced := status.CurrentBlock - status.StartingBlock; status.CurrentBlock > status.StartingBlock && status.HighestBlock > status.CurrentBlock {
+		eta = elapsed / time.Duration(synced) * time.Duration(status.HighestBlock-status.CurrentBlock)
+	}
+	return ProgressEvent{
+		Mode:   mode,
+		Status: status,
+		Phases: phases,
+		Peers:  peers,
+		ETA:    eta,
+	}
+}
+
 // Synchronising returns whether the downloader is currently retrieving blocks.
 func (d *Downloader) Synchronising() bool {
 	return d.synchronising.Load()
@@ -648,6 +727,11 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td, ttd *
 // spawnSync runs d.process and all given fetcher functions to completion in
 // separate goroutines, returning the first error that appears.
 func (d *Downloader) spawnSync(fetchers []func() error) error {
+	// Report the detailed progress to subscribers while the sync is running
+	stop := make(chan struct{})
+	defer close(stop)
+	go d.reportProgress(stop)
+
 	errc := make(chan error, len(fetchers))
 	d.cancelWg.Add(len(fetchers))
 	for _, fn := range fetchers {
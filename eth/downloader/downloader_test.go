@@ -17,6 +17,8 @@
 package downloader
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
@@ -459,6 +461,98 @@ func testCanonSync(t *testing.T, protocol uint, mode SyncMode) {
 	assertOwnChain(t, tester, len(chain.blocks))
 }
 
+// Tests that the detailed sync progress is posted to subscribers while a sync
+// cycle is running.
+func TestSyncProgressEvents(t *testing.T) {
+	defer func(old time.Duration) { progressInterval = old }(progressInterval)
+	progressInterval = 10 * time.Millisecond
+
+	tester := newTester(t)
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tester.newPeer("peer", eth.ETH68, chain.blocks[1:])
+
+	progress := make(chan ProgressEvent, 1024)
+	sub := tester.downloader.SubscribeProgress(progress)
+	defer sub.Unsubscribe()
+
+	// Stall the first import until the body retrievals are reported
+	var (
+		reported   ProgressEvent
+		throughput = make(map[SyncPhase]float64)
+		once       sync.Once
+	)
+	tester.downloader.chainInsertHook = func([]*fetchResult) {
+		once.Do(func() {
+			timeout := time.After(5 * time.Second)
+			for {
+				select {
+				case ev := <-progress:
+					for _, phase := range ev.Phases {
+						throughput[phase.Phase] += phase.Throughput
+					}
+					if ev.Phases[1].Delivered > 0 {
+						reported = ev
+						return
+					}
+				case <-timeout:
+					return
+				}
+			}
+		})
+	}
+	if err := tester.sync("peer", nil, FullSync); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	if reported.Phases == nil {
+		t.Fatal("no body retrieval progress reported")
+	}
+	if reported.Mode != FullSync {
+		t.Errorf("sync mode mismatch: have %v, want %v", reported.Mode, FullSync)
+	}
+	if reported.Status.HighestBlock != uint64(len(chain.blocks)-1) {
+		t.Errorf("highest block mismatch: have %d, want %d", reported.Status.HighestBlock, len(chain.blocks)-1)
+	}
+	for _, phase := range reported.Phases[:2] {
+		if phase.Delivered == 0 || throughput[phase.Phase] == 0 {
+			t.Errorf("phase %s: no progress reported: delivered %d, throughput %f", phase.Phase, phase.Delivered, throughput[phase.Phase])
+		}
+		if phase.Bytes == 0 {
+			t.Errorf("phase %s: no retrieved bytes reported", phase.Phase)
+		}
+	}
+	for _, phase := range reported.Phases {
+		if phase.ETA < 0 || (phase.ETA > 0 && phase.Pending+phase.InFlight == 0) {
+			t.Errorf("phase %s: invalid eta %v with %d pending, %d in flight", phase.Phase, phase.ETA, phase.Pending, phase.InFlight)
+		}
+	}
+	blob, err := json.Marshal(reported)
+	if err != nil {
+		t.Fatalf("failed to marshal progress: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(blob, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal progress: %v", err)
+	}
+	if eta, ok := decoded["eta"].(float64); !ok || time.Duration(eta)*time.Second != reported.ETA.Truncate(time.Second) {
+		t.Errorf("eta mismatch: have %v, want %v", decoded["eta"], reported.ETA)
+	}
+	phases, ok := decoded["phases"].([]interface{})
+	if !ok || len(phases) != len(reported.Phases) {
+		t.Fatalf("phases mismatch: have %v, want %d phases", decoded["phases"], len(reported.Phases))
+	}
+	for i, phase := range phases {
+		fields := phase.(map[string]interface{})
+		if eta, ok := fields["eta"].(float64); !ok || time.Duration(eta)*time.Second != reported.Phases[i].ETA.Truncate(time.Second) {
+			t.Errorf("phase %s: eta mismatch: have %v, want %v", reported.Phases[i].Phase, fields["eta"], reported.Phases[i].ETA)
+		}
+		if bytes, ok := fields["bytes"].(float64); !ok || uint64(bytes) != reported.Phases[i].Bytes {
+			t.Errorf("phase %s: bytes mismatch: have %v, want %d", reported.Phases[i].Phase, fields["bytes"], reported.Phases[i].Bytes)
+		}
+	}
+}
+
 // Tests that if a large batch of blocks are being downloaded, it is throttled
 // until the cached blocks are retrieved.
 func TestThrottling66Full(t *testing.T) { testThrottling(t, eth.ETH66, FullSync) }
@@ -1455,3 +1549,81 @@ func testBeaconSync(t *testing.T, protocol uint, mode SyncMode) {
 		})
 	}
 }
+
+// Tests that the chain can be synced from a trusted checkpoint, retrieving its
+// header from the peers, and that checkpoints not served by anyone are rejected.
+func TestCheckpointSync66Full(t *testing.T) { testCheckpointSync(t, eth.ETH66, FullSync) }
+func TestCheckpointSync66Snap(t *testing.T) { testCheckpointSync(t, eth.ETH66, SnapSync) }
+
+func testCheckpointSync(t *testing.T, protocol uint, mode SyncMode) {
+	success := make(chan struct{})
+	tester := newTesterWithNotification(t, func() {
+		close(success)
+	})
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tester.newPeer("peer", protocol, chain.blocks[1:])
+
+	// A checkpoint on a different chain must not be synced to
+	head := chain.blocks[len(chain.blocks)-1]
+	bogus := Checkpoint{Number: head.NumberU64(), Hash: common.Hash{0x01}}
+	if err := tester.downloader.CheckpointSync(mode, bogus); !errors.Is(err, errNoCheckpointPeer) {
+		t.Fatalf("bogus checkpoint error mismatch: have %v, want %v", err, errNoCheckpointPeer)
+	}
+	// A checkpoint beyond the peer's chain neither
+	beyond := Checkpoint{Number: head.NumberU64() + 1, Hash: common.Hash{0x01}}
+	if err := tester.downloader.CheckpointSync(mode, beyond); !errors.Is(err, errNoCheckpointPeer) {
+		t.Fatalf("missing checkpoint error mismatch: have %v, want %v", err, errNoCheckpointPeer)
+	}
+	// The real checkpoint must be synced to
+	checkpoint := Checkpoint{Number: head.NumberU64(), Hash: head.Hash()}
+	if err := tester.downloader.CheckpointSync(mode, checkpoint); err != nil {
+		t.Fatalf("failed to checkpoint sync: %v", err)
+	}
+	select {
+	case <-success:
+		if number := tester.chain.CurrentBlock().Number.Uint64(); number != checkpoint.Number {
+			t.Fatalf("synchronised head mismatch: have %d, want %d", number, checkpoint.Number)
+		}
+	case <-time.NewTimer(time.Second * 3).C:
+		t.Fatalf("Failed to sync chain in three seconds")
+	}
+}
+
+// Tests the text encoding of sync checkpoints.
+func TestCheckpointText(t *testing.T) {
+	hash := common.HexToHash("0x1c9a4a7e8e5cbbe8dd4a1bde3d1e5b0e1ad1ff0e3f4c6a04a2bde5f1c7d3e8a9")
+	tests := []struct {
+		input string
+		want  *Checkpoint
+	}{
+		{"1000:" + hash.Hex(), &Checkpoint{Number: 1000, Hash: hash}},
+		{"0x10:" + hash.Hex(), &Checkpoint{Number: 16, Hash: hash}},
+		{"1000", nil},
+		{"abc:" + hash.Hex(), nil},
+		{"1000:0x1234", nil},
+		{"0:" + hash.Hex(), nil},
+		{"1000:" + common.Hash{}.Hex(), nil},
+	}
+	for i, tt := range tests {
+		var have Checkpoint
+		err := have.UnmarshalText([]byte(tt.input))
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("test %d: invalid checkpoint %q accepted", i, tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: failed to parse %q: %v", i, tt.input, err)
+			continue
+		}
+		if have != *tt.want {
+			t.Errorf("test %d: checkpoint mismatch: have %v, want %v", i, have, *tt.want)
+		}
+		if enc, _ := have.MarshalText(); string(enc) != tt.want.String() {
+			t.Errorf("test %d: encoding mismatch: have %s, want %s", i, enc, tt.want.String())
+		}
+	}
+}
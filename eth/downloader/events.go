@@ -16,10 +16,74 @@
 
 package downloader
 
-import "github.com/gorievm/go-gori/core/types"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gorievm/go-gori"
+	"github.com/gorievm/go-gori/core/types"
+)
 
 type DoneEvent struct {
 	Latest *types.Header
 }
 type StartEvent struct{}
 type FailedEvent struct{ Err error }
+
+// SyncPhase identifies a data retrieval phase of the synchronisation.
+type SyncPhase string
+
+const (
+	HeaderPhase  SyncPhase = "headers"  // Header retrieval and scheduling
+	BodyPhase    SyncPhase = "bodies"   // Block body retrieval
+	ReceiptPhase SyncPhase = "receipts" // Block receipt retrieval (snap sync only)
+	StatePhase   SyncPhase = "state"    // State retrieval (snap sync only)
+	HealPhase    SyncPhase = "heal"     // State healing (snap sync only)
+)
+
+// PhaseProgress is the retrieval progress of a single sync phase.
+type PhaseProgress struct {
+	Phase      SyncPhase     `json:"phase"`
+	Pending    int           `json:"pending"`    // Number of items queued for retrieval
+	InFlight   int           `json:"inFlight"`   // Number of items currently requested from peers
+	Delivered  uint64        `json:"delivered"`  // Number of items retrieved in the current sync cycle
+	Bytes      uint64        `json:"bytes"`      // Approximate size of the items retrieved in the current sync cycle
+	Throughput float64       `json:"throughput"` // Items retrieved per second since the last report
+	ETA        time.Duration `json:"eta"`        // Estimated time to retrieve the pending items, zero if unknown
+}
+
+// MarshalJSON marshals the phase progress, with the ETA in whole seconds.
+func (p PhaseProgress) MarshalJSON() ([]byte, error) {
+	type phaseProgress PhaseProgress
+	return json.Marshal(struct {
+		phaseProgress
+		ETA uint64 `json:"eta"`
+	}{phaseProgress(p), uint64(p.ETA / time.Second)})
+}
+
+// PeerAssignment is a retrieval request currently assigned to a peer.
+type PeerAssignment struct {
+	Peer  string    `json:"peer"`
+	Phase SyncPhase `json:"phase"`
+	Items int       `json:"items"` // Number of items requested
+	Since time.Time `json:"since"` // Time when the request was sent
+}
+
+// ProgressEvent is posted periodically while the downloader is synchronising,
+// with the detailed progress of the running sync cycle.
+type ProgressEvent struct {
+	Mode   SyncMode              `json:"mode"`
+	Status ethereum.SyncProgress `json:"status"`
+	Phases []PhaseProgress       `json:"phases"`
+	Peers  []PeerAssignment      `json:"peers"`
+	ETA    time.Duration         `json:"eta"` // Estimated time until the chain is synced, zero if unknown
+}
+
+// MarshalJSON marshals the progress event, with the ETA in whole seconds.
+func (ev ProgressEvent) MarshalJSON() ([]byte, error) {
+	type progressEvent ProgressEvent
+	return json.Marshal(struct {
+		progressEvent
+		ETA uint64 `json:"eta"`
+	}{progressEvent(ev), uint64(ev.ETA / time.Second)})
+}
@@ -22,6 +22,7 @@ package downloader
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -141,6 +142,14 @@ type queue struct {
 	resultCache *resultStore       // Downloaded but not yet delivered fetch results
 	resultSize  common.StorageSize // Approximate size of a block (exponential moving average)
 
+	headerScheduled  uint64 // Number of headers scheduled for block part retrieval
+	blockDelivered   uint64 // Number of block bodies delivered
+	receiptDelivered uint64 // Number of block receipts delivered
+
+	headerBytes  common.StorageSize // Approximate size of the headers scheduled
+	blockBytes   common.StorageSize // Approximate size of the block bodies delivered
+	receiptBytes common.StorageSize // Approximate size of the block receipts delivered
+
 	lock   *sync.RWMutex
 	active *sync.Cond
 	closed bool
@@ -185,6 +194,9 @@ func (q *queue) Reset(blockCacheLimit int, thresholdInitialSize int) {
 
 	q.resultCache = newResultStore(blockCacheLimit)
 	q.resultCache.SetThrottleThreshold(uint64(thresholdInitialSize))
+
+	q.headerScheduled, q.blockDelivered, q.receiptDelivered = 0, 0, 0
+	q.headerBytes, q.blockBytes, q.receiptBytes = 0, 0, 0
 }
 
 // Close marks the end of the sync, unblocking Results.
@@ -328,8 +340,10 @@ func (q *queue) Schedule(headers []*types.Header, hashes []common.Hash, from uin
 		}
 		inserts = append(inserts, header)
 		q.headerHead = hash
+		q.headerBytes += header.Size()
 		from++
 	}
+	q.headerScheduled += uint64(len(inserts))
 	return inserts
 }
 
@@ -401,6 +415,43 @@ func (q *queue) Results(block bool) []*fetchResult {
 	return results
 }
 
+// Progress retrieves the retrieval statistics of the header, body and receipt
+// phases, along with the requests currently assigned to each peer.
+func (q *queue) Progress() ([]PhaseProgress, []PeerAssignment) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	var pendingHeaders int
+	if q.headerTaskQueue != nil {
+		pendingHeaders = q.headerTaskQueue.Size() * MaxHeaderFetch
+	}
+	var (
+		phases = []PhaseProgress{
+			{Phase: HeaderPhase, Pending: pendingHeaders, Delivered: q.headerScheduled, Bytes: uint64(q.headerBytes)},
+			{Phase: BodyPhase, Pending: q.blockTaskQueue.Size(), Delivered: q.blockDelivered, Bytes: uint64(q.blockBytes)},
+			{Phase: ReceiptPhase, Pending: q.receiptTaskQueue.Size(), Delivered: q.receiptDelivered, Bytes: uint64(q.receiptBytes)},
+		}
+		peers []PeerAssignment
+	)
+	for i, pool := range []map[string]*fetchRequest{q.headerPendPool, q.blockPendPool, q.receiptPendPool} {
+		for id, req := range pool {
+			items := len(req.Headers)
+			if phases[i].Phase == HeaderPhase {
+				items = MaxHeaderFetch
+			}
+			phases[i].InFlight += items
+			peers = append(peers, PeerAssignment{Peer: id, Phase: phases[i].Phase, Items: items, Since: req.Time})
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		if peers[i].Peer != peers[j].Peer {
+			return peers[i].Peer < peers[j].Peer
+		}
+		return peers[i].Phase < peers[j].Phase
+	})
+	return phases, peers
+}
+
 func (q *queue) Stats() []interface{} {
 	q.lock.RLock()
 	defer q.lock.RUnlock()
@@ -833,9 +884,18 @@ func (q *queue) DeliverBodies(id string, txLists [][]*types.Transaction, txListH
 		result.Uncles = uncleLists[index]
 		result.Withdrawals = withdrawalLists[index]
 		result.SetBodyDone()
+
+		for _, uncle := range result.Uncles {
+			q.blockBytes += uncle.Size()
+		}
+		for _, tx := range result.Transactions {
+			q.blockBytes += common.StorageSize(tx.Size())
+		}
 	}
-	return q.deliver(id, q.blockTaskPool, q.blockTaskQueue, q.blockPendPool,
+	accepted, err := q.deliver(id, q.blockTaskPool, q.blockTaskQueue, q.blockPendPool,
 		bodyReqTimer, bodyInMeter, bodyDropMeter, len(txLists), validate, reconstruct)
+	q.blockDelivered += uint64(accepted)
+	return accepted, err
 }
 
 // DeliverReceipts injects a receipt retrieval response into the results queue.
@@ -854,9 +914,15 @@ func (q *queue) DeliverReceipts(id string, receiptList [][]*types.Receipt, recei
 	reconstruct := func(index int, result *fetchResult) {
 		result.Receipts = receiptList[index]
 		result.SetReceiptsDone()
+
+		for _, receipt := range result.Receipts {
+			q.receiptBytes += receipt.Size()
+		}
 	}
-	return q.deliver(id, q.receiptTaskPool, q.receiptTaskQueue, q.receiptPendPool,
+	accepted, err := q.deliver(id, q.receiptTaskPool, q.receiptTaskQueue, q.receiptPendPool,
 		receiptReqTimer, receiptInMeter, receiptDropMeter, len(receiptList), validate, reconstruct)
+	q.receiptDelivered += uint64(accepted)
+	return accepted, err
 }
 
 // deliver injects a data retrieval response into the results queue.
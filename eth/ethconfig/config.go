@@ -19,6 +19,7 @@ package ethconfig
 
 import (
 	"errors"
+	"math"
 	"time"
 
 	"github.com/gorievm/go-gori/common"
@@ -27,6 +28,7 @@ import (
 	"github.com/gorievm/go-gori/consensus/clique"
 	"github.com/gorievm/go-gori/consensus/ethash"
 	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/txpool"
 	"github.com/gorievm/go-gori/core/txpool/blobpool"
 	"github.com/gorievm/go-gori/core/txpool/legacypool"
 	"github.com/gorievm/go-gori/eth/downloader"
@@ -61,6 +63,7 @@ var Defaults = Config{
 	SyncMode:           downloader.SnapSync,
 	NetworkId:          1,
 	TxLookupLimit:      2350000,
+	LogHistory:         2350000,
 	LightPeers:         100,
 	DatabaseCache:      512,
 	TrieCleanCache:     154,
@@ -71,8 +74,11 @@ var Defaults = Config{
 	Miner:              miner.DefaultConfig,
 	TxPool:             legacypool.DefaultConfig,
 	BlobPool:           blobpool.DefaultConfig,
+	TxPoolForensics:    txpool.DefaultForensicsConfig,
+	TxPropagation:      DefaultTxPropagationConfig,
 	RPCGasCap:          50000000,
 	RPCEVMTimeout:      5 * time.Second,
+	RPCWarmQuota:       10000,
 	GPO:                FullNodeGPO,
 	RPCTxFeeCap:        1, // 1 ether
 }
@@ -89,6 +95,10 @@ type Config struct {
 	NetworkId uint64 // Network ID to use for selecting peers to connect to
 	SyncMode  downloader.SyncMode
 
+	// SyncCheckpoint is a trusted block to sync the chain backward from, without
+	// a consensus client driving the sync. Legacy sync is suspended until reached.
+	SyncCheckpoint *downloader.Checkpoint `toml:",omitempty"`
+
 	// This can be set to list of enrtree:// URLs which will be queried for
 	// for nodes to connect to.
 	EthDiscoveryURLs  []string
@@ -98,6 +108,17 @@ type Config struct {
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
+	LogHistory    uint64 `toml:",omitempty"` // The maximum number of blocks from head whose logs are indexed.
+	LogNoHistory  bool   `toml:",omitempty"` // Whether to disable the log index and keep the bloom bits index.
+
+	// AddressIndex enables indexing the canonical transactions by the addresses
+	// they are sent by or to, retaining AddressIndexLimit recent blocks (0 = all).
+	AddressIndex      bool   `toml:",omitempty"`
+	AddressIndexLimit uint64 `toml:",omitempty"`
+
+	// HistoryCutoff is the block number below which block bodies and receipts
+	// are dropped from the ancient store to save disk space (0 = keep all).
+	HistoryCutoff uint64 `toml:",omitempty"`
 
 	// RequiredBlocks is a set of block number -> hash mappings which must be in the
 	// canonical chain of all remote peers. Setting the option makes gori verify the
@@ -124,6 +145,19 @@ type Config struct {
 	SnapshotCache  int
 	Preimages      bool
 
+	// This is the number of recent blocks for which the execution witnesses are
+	// recorded during import. Zero disables witness collection.
+	WitnessHistory int `toml:",omitempty"`
+
+	// SnapshotRate caps the megabytes of snapshot data generated per second, so
+	// that a background regeneration doesn't starve block processing of disk
+	// IO. Zero means unlimited.
+	SnapshotRate int `toml:",omitempty"`
+
+	// StateHeal enables repairing trie nodes found missing or corrupt on read
+	// by retrieving them from the connected snap peers.
+	StateHeal bool `toml:",omitempty"`
+
 	// This is the number of blocks for which logs will be cached in the filter system.
 	FilterLogCacheSize int
 
@@ -134,12 +168,21 @@ type Config struct {
 	TxPool   legacypool.Config
 	BlobPool blobpool.Config
 
+	// Transaction pool anomaly snapshotting options
+	TxPoolForensics txpool.ForensicsConfig
+
+	// Transaction propagation options
+	TxPropagation TxPropagationConfig
+
 	// Gas Price Oracle options
 	GPO gasprice.Config
 
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Enables speculative parallel execution of block transactions on import
+	ParallelExecution bool `toml:",omitempty"`
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 
@@ -149,15 +192,65 @@ type Config struct {
 	// RPCEVMTimeout is the global timeout for eth-call.
 	RPCEVMTimeout time.Duration
 
+	// RPCWarmQuota is the maximum number of accounts and storage slots that
+	// can be pre-warmed into the caches per second via gori_warm.
+	RPCWarmQuota uint64
+
+	// RPCLogResultLimit is the maximum number of logs returned by a single
+	// eth_getLogs call, also the page size of eth_getLogsPage (0 = unlimited).
+	RPCLogResultLimit int `toml:",omitempty"`
+
+	// RPCLogRangeLimit is the maximum number of blocks searched by a single
+	// eth_getLogs call or eth_getLogsPage page (0 = unlimited).
+	RPCLogRangeLimit uint64 `toml:",omitempty"`
+
 	// RPCTxFeeCap is the global transaction fee(price * gaslimit) cap for
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64
 
+	// RPCExtendedReceipts records the fee components of transactions during
+	// execution and includes them in the receipts served over RPC.
+	RPCExtendedReceipts bool `toml:",omitempty"`
+
 	// OverrideCancun (TODO: remove after the fork)
 	OverrideCancun *uint64 `toml:",omitempty"`
 
 	// OverrideVerkle (TODO: remove after the fork)
 	OverrideVerkle *uint64 `toml:",omitempty"`
+
+	// ForkOverrideFile is the path of a signed fork override file, which is
+	// watched for forks to schedule on the running node.
+	ForkOverrideFile string `toml:",omitempty"`
+
+	// ForkOverrideSigners are the keys trusted to sign fork overrides.
+	ForkOverrideSigners []common.Address `toml:",omitempty"`
+}
+
+// TxPropagationConfig are the parameters tuning how transactions are propagated
+// to the connected peers.
+type TxPropagationConfig struct {
+	BroadcastPeers   uint64 `json:"broadcastPeers"`   // Number of peers to send transactions to directly (0 = square root of the peer count)
+	MaxBroadcastSize uint64 `json:"maxBroadcastSize"` // Maximum size of a transaction to send directly, larger ones are only announced
+	PeerSendRate     uint64 `json:"peerSendRate"`     // Maximum number of transactions sent or announced to a peer per second (0 = unlimited)
+}
+
+// DefaultTxPropagationConfig contains the default transaction propagation
+// settings: direct sends to the square root of the peers and announcements to
+// the rest, with transactions above 4KB always announced.
+var DefaultTxPropagationConfig = TxPropagationConfig{
+	MaxBroadcastSize: 4096,
+}
+
+// DirectPeers returns how many of the given number of peers a transaction should
+// be sent to directly.
+func (c *TxPropagationConfig) DirectPeers(peers int) int {
+	if c.BroadcastPeers == 0 {
+		return int(math.Sqrt(float64(peers)))
+	}
+	if c.BroadcastPeers > uint64(peers) {
+		return peers
+	}
+	return int(c.BroadcastPeers)
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain config.
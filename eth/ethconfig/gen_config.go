@@ -7,6 +7,7 @@ import (
 
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/txpool"
 	"github.com/gorievm/go-gori/core/txpool/blobpool"
 	"github.com/gorievm/go-gori/core/txpool/legacypool"
 	"github.com/gorievm/go-gori/eth/downloader"
@@ -20,11 +21,17 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Genesis                 *core.Genesis `toml:",omitempty"`
 		NetworkId               uint64
 		SyncMode                downloader.SyncMode
+		SyncCheckpoint          *downloader.Checkpoint `toml:",omitempty"`
 		EthDiscoveryURLs        []string
 		SnapDiscoveryURLs       []string
 		NoPruning               bool
 		NoPrefetch              bool
 		TxLookupLimit           uint64                 `toml:",omitempty"`
+		LogHistory              uint64                 `toml:",omitempty"`
+		LogNoHistory            bool                   `toml:",omitempty"`
+		AddressIndex            bool                   `toml:",omitempty"`
+		AddressIndexLimit       uint64                 `toml:",omitempty"`
+		HistoryCutoff           uint64                 `toml:",omitempty"`
 		RequiredBlocks          map[uint64]common.Hash `toml:"-"`
 		LightServ               int                    `toml:",omitempty"`
 		LightIngress            int                    `toml:",omitempty"`
@@ -41,28 +48,46 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		TrieTimeout             time.Duration
 		SnapshotCache           int
 		Preimages               bool
+		WitnessHistory          int  `toml:",omitempty"`
+		SnapshotRate            int  `toml:",omitempty"`
+		StateHeal               bool `toml:",omitempty"`
 		FilterLogCacheSize      int
 		Miner                   miner.Config
 		TxPool                  legacypool.Config
 		BlobPool                blobpool.Config
+		TxPoolForensics         txpool.ForensicsConfig
+		TxPropagation           TxPropagationConfig
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
+		ParallelExecution       bool   `toml:",omitempty"`
 		DocRoot                 string `toml:"-"`
 		RPCGasCap               uint64
 		RPCEVMTimeout           time.Duration
+		RPCWarmQuota            uint64
+		RPCLogResultLimit       int    `toml:",omitempty"`
+		RPCLogRangeLimit        uint64 `toml:",omitempty"`
 		RPCTxFeeCap             float64
-		OverrideCancun          *uint64 `toml:",omitempty"`
-		OverrideVerkle          *uint64 `toml:",omitempty"`
+		RPCExtendedReceipts     bool             `toml:",omitempty"`
+		OverrideCancun          *uint64          `toml:",omitempty"`
+		OverrideVerkle          *uint64          `toml:",omitempty"`
+		ForkOverrideFile        string           `toml:",omitempty"`
+		ForkOverrideSigners     []common.Address `toml:",omitempty"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
 	enc.NetworkId = c.NetworkId
 	enc.SyncMode = c.SyncMode
+	enc.SyncCheckpoint = c.SyncCheckpoint
 	enc.EthDiscoveryURLs = c.EthDiscoveryURLs
 	enc.SnapDiscoveryURLs = c.SnapDiscoveryURLs
 	enc.NoPruning = c.NoPruning
 	enc.NoPrefetch = c.NoPrefetch
 	enc.TxLookupLimit = c.TxLookupLimit
+	enc.LogHistory = c.LogHistory
+	enc.LogNoHistory = c.LogNoHistory
+	enc.AddressIndex = c.AddressIndex
+	enc.AddressIndexLimit = c.AddressIndexLimit
+	enc.HistoryCutoff = c.HistoryCutoff
 	enc.RequiredBlocks = c.RequiredBlocks
 	enc.LightServ = c.LightServ
 	enc.LightIngress = c.LightIngress
@@ -79,18 +104,30 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.TrieTimeout = c.TrieTimeout
 	enc.SnapshotCache = c.SnapshotCache
 	enc.Preimages = c.Preimages
+	enc.WitnessHistory = c.WitnessHistory
+	enc.SnapshotRate = c.SnapshotRate
+	enc.StateHeal = c.StateHeal
 	enc.FilterLogCacheSize = c.FilterLogCacheSize
 	enc.Miner = c.Miner
 	enc.TxPool = c.TxPool
 	enc.BlobPool = c.BlobPool
+	enc.TxPoolForensics = c.TxPoolForensics
+	enc.TxPropagation = c.TxPropagation
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
+	enc.ParallelExecution = c.ParallelExecution
 	enc.DocRoot = c.DocRoot
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCEVMTimeout = c.RPCEVMTimeout
+	enc.RPCWarmQuota = c.RPCWarmQuota
+	enc.RPCLogResultLimit = c.RPCLogResultLimit
+	enc.RPCLogRangeLimit = c.RPCLogRangeLimit
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
+	enc.RPCExtendedReceipts = c.RPCExtendedReceipts
 	enc.OverrideCancun = c.OverrideCancun
 	enc.OverrideVerkle = c.OverrideVerkle
+	enc.ForkOverrideFile = c.ForkOverrideFile
+	enc.ForkOverrideSigners = c.ForkOverrideSigners
 	return &enc, nil
 }
 
@@ -100,11 +137,17 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Genesis                 *core.Genesis `toml:",omitempty"`
 		NetworkId               *uint64
 		SyncMode                *downloader.SyncMode
+		SyncCheckpoint          *downloader.Checkpoint `toml:",omitempty"`
 		EthDiscoveryURLs        []string
 		SnapDiscoveryURLs       []string
 		NoPruning               *bool
 		NoPrefetch              *bool
 		TxLookupLimit           *uint64                `toml:",omitempty"`
+		LogHistory              *uint64                `toml:",omitempty"`
+		LogNoHistory            *bool                  `toml:",omitempty"`
+		AddressIndex            *bool                  `toml:",omitempty"`
+		AddressIndexLimit       *uint64                `toml:",omitempty"`
+		HistoryCutoff           *uint64                `toml:",omitempty"`
 		RequiredBlocks          map[uint64]common.Hash `toml:"-"`
 		LightServ               *int                   `toml:",omitempty"`
 		LightIngress            *int                   `toml:",omitempty"`
@@ -121,18 +164,30 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		TrieTimeout             *time.Duration
 		SnapshotCache           *int
 		Preimages               *bool
+		WitnessHistory          *int  `toml:",omitempty"`
+		SnapshotRate            *int  `toml:",omitempty"`
+		StateHeal               *bool `toml:",omitempty"`
 		FilterLogCacheSize      *int
 		Miner                   *miner.Config
 		TxPool                  *legacypool.Config
 		BlobPool                *blobpool.Config
+		TxPoolForensics         *txpool.ForensicsConfig
+		TxPropagation           *TxPropagationConfig
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
+		ParallelExecution       *bool   `toml:",omitempty"`
 		DocRoot                 *string `toml:"-"`
 		RPCGasCap               *uint64
 		RPCEVMTimeout           *time.Duration
+		RPCWarmQuota            *uint64
+		RPCLogResultLimit       *int    `toml:",omitempty"`
+		RPCLogRangeLimit        *uint64 `toml:",omitempty"`
 		RPCTxFeeCap             *float64
-		OverrideCancun          *uint64 `toml:",omitempty"`
-		OverrideVerkle          *uint64 `toml:",omitempty"`
+		RPCExtendedReceipts     *bool            `toml:",omitempty"`
+		OverrideCancun          *uint64          `toml:",omitempty"`
+		OverrideVerkle          *uint64          `toml:",omitempty"`
+		ForkOverrideFile        *string          `toml:",omitempty"`
+		ForkOverrideSigners     []common.Address `toml:",omitempty"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -147,6 +202,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SyncMode != nil {
 		c.SyncMode = *dec.SyncMode
 	}
+	if dec.SyncCheckpoint != nil {
+		c.SyncCheckpoint = dec.SyncCheckpoint
+	}
 	if dec.EthDiscoveryURLs != nil {
 		c.EthDiscoveryURLs = dec.EthDiscoveryURLs
 	}
@@ -162,6 +220,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.TxLookupLimit != nil {
 		c.TxLookupLimit = *dec.TxLookupLimit
 	}
+	if dec.LogHistory != nil {
+		c.LogHistory = *dec.LogHistory
+	}
+	if dec.LogNoHistory != nil {
+		c.LogNoHistory = *dec.LogNoHistory
+	}
+	if dec.AddressIndex != nil {
+		c.AddressIndex = *dec.AddressIndex
+	}
+	if dec.AddressIndexLimit != nil {
+		c.AddressIndexLimit = *dec.AddressIndexLimit
+	}
+	if dec.HistoryCutoff != nil {
+		c.HistoryCutoff = *dec.HistoryCutoff
+	}
 	if dec.RequiredBlocks != nil {
 		c.RequiredBlocks = dec.RequiredBlocks
 	}
@@ -210,6 +283,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.Preimages != nil {
 		c.Preimages = *dec.Preimages
 	}
+	if dec.WitnessHistory != nil {
+		c.WitnessHistory = *dec.WitnessHistory
+	}
+	if dec.SnapshotRate != nil {
+		c.SnapshotRate = *dec.SnapshotRate
+	}
+	if dec.StateHeal != nil {
+		c.StateHeal = *dec.StateHeal
+	}
 	if dec.FilterLogCacheSize != nil {
 		c.FilterLogCacheSize = *dec.FilterLogCacheSize
 	}
@@ -222,12 +304,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.BlobPool != nil {
 		c.BlobPool = *dec.BlobPool
 	}
+	if dec.TxPoolForensics != nil {
+		c.TxPoolForensics = *dec.TxPoolForensics
+	}
+	if dec.TxPropagation != nil {
+		c.TxPropagation = *dec.TxPropagation
+	}
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
+	if dec.ParallelExecution != nil {
+		c.ParallelExecution = *dec.ParallelExecution
+	}
 	if dec.DocRoot != nil {
 		c.DocRoot = *dec.DocRoot
 	}
@@ -237,14 +328,32 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.RPCEVMTimeout != nil {
 		c.RPCEVMTimeout = *dec.RPCEVMTimeout
 	}
+	if dec.RPCWarmQuota != nil {
+		c.RPCWarmQuota = *dec.RPCWarmQuota
+	}
+	if dec.RPCLogResultLimit != nil {
+		c.RPCLogResultLimit = *dec.RPCLogResultLimit
+	}
+	if dec.RPCLogRangeLimit != nil {
+		c.RPCLogRangeLimit = *dec.RPCLogRangeLimit
+	}
 	if dec.RPCTxFeeCap != nil {
 		c.RPCTxFeeCap = *dec.RPCTxFeeCap
 	}
+	if dec.RPCExtendedReceipts != nil {
+		c.RPCExtendedReceipts = *dec.RPCExtendedReceipts
+	}
 	if dec.OverrideCancun != nil {
 		c.OverrideCancun = dec.OverrideCancun
 	}
 	if dec.OverrideVerkle != nil {
 		c.OverrideVerkle = dec.OverrideVerkle
 	}
+	if dec.ForkOverrideFile != nil {
+		c.ForkOverrideFile = *dec.ForkOverrideFile
+	}
+	if dec.ForkOverrideSigners != nil {
+		c.ForkOverrideSigners = dec.ForkOverrideSigners
+	}
 	return nil
 }
@@ -48,6 +48,12 @@ const (
 	//     of the retrieval and response size overflow won't happen in most cases.
 	maxTxRetrievals = 256
 
+	// maxTxRetrievalSize is the maximum total announced size of the transactions
+	// being retrieved from a single peer at once. Since only one request is ever
+	// in flight per peer, this is the peer's in-flight byte budget. Transactions
+	// announced without metadata (eth/66, eth/67) are only capped by count.
+	maxTxRetrievalSize = 128 * 1024
+
 	// maxTxUnderpricedSetSize is the size of the underpriced transaction set that
 	// is used to track recent transactions that have been dropped so we don't
 	// re-request them.
@@ -83,6 +89,8 @@ var (
 	txRequestFailMeter    = metrics.NewRegisteredMeter("eth/fetcher/transaction/request/fail", nil)
 	txRequestDoneMeter    = metrics.NewRegisteredMeter("eth/fetcher/transaction/request/done", nil)
 	txRequestTimeoutMeter = metrics.NewRegisteredMeter("eth/fetcher/transaction/request/timeout", nil)
+	txRequestBytesMeter   = metrics.NewRegisteredMeter("eth/fetcher/transaction/request/bytes", nil)
+	txRequestCappedMeter  = metrics.NewRegisteredMeter("eth/fetcher/transaction/request/capped", nil)
 
 	txReplyInMeter          = metrics.NewRegisteredMeter("eth/fetcher/transaction/replies/in", nil)
 	txReplyKnownMeter       = metrics.NewRegisteredMeter("eth/fetcher/transaction/replies/known", nil)
@@ -95,6 +103,11 @@ var (
 	txFetcherQueueingHashes = metrics.NewRegisteredGauge("eth/fetcher/transaction/queueing/hashes", nil)
 	txFetcherFetchingPeers  = metrics.NewRegisteredGauge("eth/fetcher/transaction/fetching/peers", nil)
 	txFetcherFetchingHashes = metrics.NewRegisteredGauge("eth/fetcher/transaction/fetching/hashes", nil)
+	txFetcherFetchingBytes  = metrics.NewRegisteredGauge("eth/fetcher/transaction/fetching/bytes", nil)
+
+	txScheduleBlobMeter    = metrics.NewRegisteredMeter("eth/fetcher/transaction/schedule/blob", nil)
+	txScheduleTypedMeter   = metrics.NewRegisteredMeter("eth/fetcher/transaction/schedule/typed", nil)
+	txScheduleUntypedMeter = metrics.NewRegisteredMeter("eth/fetcher/transaction/schedule/untyped", nil)
 )
 
 // txAnnounce is the notification of the availability of a batch
@@ -102,6 +115,32 @@ var (
 type txAnnounce struct {
 	origin string        // Identifier of the peer originating the notification
 	hashes []common.Hash // Batch of transaction hashes being announced
+	metas  []*txMetadata // Batch of metadata associated with the hashes (nil before eth/68)
+}
+
+// txMetadata is the set of extra data transmitted along with an eth/68 (and
+// later) transaction announcement.
+type txMetadata struct {
+	kind byte   // Transaction consensus type
+	size uint32 // Transaction size in bytes
+}
+
+// txTypeClass returns the scheduling class of an announced transaction based on
+// its type alone, lower being fetched first. Transactions announced with eth/68
+// metadata go first, then the ones announced without any (older protocols) and
+// lastly blob transactions, which are large and rarely make it into the pool.
+//
+// The ordering says nothing about fees: announcements carry only the type, size
+// and hash of a transaction, so its fees are unknown until it is retrieved.
+func txTypeClass(meta *txMetadata) int {
+	switch {
+	case meta == nil:
+		return 1
+	case meta.kind == types.BlobTxType:
+		return 2
+	default:
+		return 0
+	}
 }
 
 // txRequest represents an in-flight transaction retrieval request destined to
@@ -110,6 +149,7 @@ type txRequest struct {
 	hashes []common.Hash            // Transactions having been requested
 	stolen map[common.Hash]struct{} // Deliveries by someone else (don't re-request)
 	time   mclock.AbsTime           // Timestamp of the request
+	size   uint64                   // Total announced size of the requested transactions
 }
 
 // txDelivery is the notification that a batch of transactions have been added
@@ -142,6 +182,10 @@ type txDrop struct {
 //   - Each peer that announced transactions may be scheduled retrievals, but
 //     only ever one concurrently. This ensures we can immediately know what is
 //     missing from a reply and reschedule it.
+//
+// Retrievals are ordered by the type and size in eth/68 announcements (see
+// txTypeClass), smaller transactions first within the same class, and each
+// request is capped by both count and announced byte size.
 type TxFetcher struct {
 	notify  chan *txAnnounce
 	cleanup chan *txDelivery
@@ -152,14 +196,14 @@ type TxFetcher struct {
 
 	// Stage 1: Waiting lists for newly discovered transactions that might be
 	// broadcast without needing explicit request/reply round trips.
-	waitlist  map[common.Hash]map[string]struct{} // Transactions waiting for an potential broadcast
-	waittime  map[common.Hash]mclock.AbsTime      // Timestamps when transactions were added to the waitlist
-	waitslots map[string]map[common.Hash]struct{} // Waiting announcements grouped by peer (DoS protection)
+	waitlist  map[common.Hash]map[string]struct{}    // Transactions waiting for an potential broadcast
+	waittime  map[common.Hash]mclock.AbsTime         // Timestamps when transactions were added to the waitlist
+	waitslots map[string]map[common.Hash]*txMetadata // Waiting announcements grouped by peer (DoS protection)
 
 	// Stage 2: Queue of transactions that waiting to be allocated to some peer
 	// to be retrieved directly.
-	announces map[string]map[common.Hash]struct{} // Set of announced transactions, grouped by origin peer
-	announced map[common.Hash]map[string]struct{} // Set of download locations, grouped by transaction hash
+	announces map[string]map[common.Hash]*txMetadata // Set of announced transactions, grouped by origin peer
+	announced map[common.Hash]map[string]struct{}    // Set of download locations, grouped by transaction hash
 
 	// Stage 3: Set of transactions currently being retrieved, some which may be
 	// fulfilled and some rescheduled. Note, this step shares 'announces' from the
@@ -196,8 +240,8 @@ func NewTxFetcherForTests(
 		quit:        make(chan struct{}),
 		waitlist:    make(map[common.Hash]map[string]struct{}),
 		waittime:    make(map[common.Hash]mclock.AbsTime),
-		waitslots:   make(map[string]map[common.Hash]struct{}),
-		announces:   make(map[string]map[common.Hash]struct{}),
+		waitslots:   make(map[string]map[common.Hash]*txMetadata),
+		announces:   make(map[string]map[common.Hash]*txMetadata),
 		announced:   make(map[common.Hash]map[string]struct{}),
 		fetching:    make(map[common.Hash]string),
 		requests:    make(map[string]*txRequest),
@@ -212,8 +256,9 @@ func NewTxFetcherForTests(
 }
 
 // Notify announces the fetcher of the potential availability of a new batch of
-// transactions in the network.
-func (f *TxFetcher) Notify(peer string, hashes []common.Hash) error {
+// transactions in the network. The types and sizes are the metadata of eth/68
+// announcements and must either be nil or match the hashes in length.
+func (f *TxFetcher) Notify(peer string, types []byte, sizes []uint32, hashes []common.Hash) error {
 	// Keep track of all the announced transactions
 	txAnnounceInMeter.Mark(int64(len(hashes)))
 
@@ -222,11 +267,18 @@ func (f *TxFetcher) Notify(peer string, hashes []common.Hash) error {
 	// because multiple concurrent notifies will still manage to pass it, but it's
 	// still valuable to check here because it runs concurrent  to the internal
 	// loop, so anything caught here is time saved internally.
+	if types != nil && (len(types) != len(hashes) || len(sizes) != len(hashes)) {
+		return fmt.Errorf("announcement metadata mismatch: hashes %d, types %d, sizes %d", len(hashes), len(types), len(sizes))
+	}
 	var (
-		unknowns               = make([]common.Hash, 0, len(hashes))
+		unknownHashes          = make([]common.Hash, 0, len(hashes))
+		unknownMetas           []*txMetadata
 		duplicate, underpriced int64
 	)
-	for _, hash := range hashes {
+	if types != nil {
+		unknownMetas = make([]*txMetadata, 0, len(hashes))
+	}
+	for i, hash := range hashes {
 		switch {
 		case f.hasTx(hash):
 			duplicate++
@@ -235,19 +287,23 @@ func (f *TxFetcher) Notify(peer string, hashes []common.Hash) error {
 			underpriced++
 
 		default:
-			unknowns = append(unknowns, hash)
+			unknownHashes = append(unknownHashes, hash)
+			if types != nil {
+				unknownMetas = append(unknownMetas, &txMetadata{kind: types[i], size: sizes[i]})
+			}
 		}
 	}
 	txAnnounceKnownMeter.Mark(duplicate)
 	txAnnounceUnderpricedMeter.Mark(underpriced)
 
 	// If anything's left to announce, push it into the internal loop
-	if len(unknowns) == 0 {
+	if len(unknownHashes) == 0 {
 		return nil
 	}
 	announce := &txAnnounce{
 		origin: peer,
-		hashes: unknowns,
+		hashes: unknownHashes,
+		metas:  unknownMetas,
 	}
 	select {
 	case f.notify <- announce:
@@ -393,12 +449,19 @@ func (f *TxFetcher) loop() {
 			if want > maxTxAnnounces {
 				txAnnounceDOSMeter.Mark(int64(want - maxTxAnnounces))
 				ann.hashes = ann.hashes[:want-maxTxAnnounces]
+				if ann.metas != nil {
+					ann.metas = ann.metas[:want-maxTxAnnounces]
+				}
 			}
 			// All is well, schedule the remainder of the transactions
 			idleWait := len(f.waittime) == 0
 			_, oldPeer := f.announces[ann.origin]
 
-			for _, hash := range ann.hashes {
+			for i, hash := range ann.hashes {
+				var meta *txMetadata
+				if ann.metas != nil {
+					meta = ann.metas[i]
+				}
 				// If the transaction is already downloading, add it to the list
 				// of possible alternates (in case the current retrieval fails) and
 				// also account it for the peer.
@@ -407,9 +470,9 @@ func (f *TxFetcher) loop() {
 
 					// Stage 2 and 3 share the set of origins per tx
 					if announces := f.announces[ann.origin]; announces != nil {
-						announces[hash] = struct{}{}
+						announces[hash] = meta
 					} else {
-						f.announces[ann.origin] = map[common.Hash]struct{}{hash: {}}
+						f.announces[ann.origin] = map[common.Hash]*txMetadata{hash: meta}
 					}
 					continue
 				}
@@ -420,9 +483,9 @@ func (f *TxFetcher) loop() {
 
 					// Stage 2 and 3 share the set of origins per tx
 					if announces := f.announces[ann.origin]; announces != nil {
-						announces[hash] = struct{}{}
+						announces[hash] = meta
 					} else {
-						f.announces[ann.origin] = map[common.Hash]struct{}{hash: {}}
+						f.announces[ann.origin] = map[common.Hash]*txMetadata{hash: meta}
 					}
 					continue
 				}
@@ -433,9 +496,9 @@ func (f *TxFetcher) loop() {
 					f.waitlist[hash][ann.origin] = struct{}{}
 
 					if waitslots := f.waitslots[ann.origin]; waitslots != nil {
-						waitslots[hash] = struct{}{}
+						waitslots[hash] = meta
 					} else {
-						f.waitslots[ann.origin] = map[common.Hash]struct{}{hash: {}}
+						f.waitslots[ann.origin] = map[common.Hash]*txMetadata{hash: meta}
 					}
 					continue
 				}
@@ -444,9 +507,9 @@ func (f *TxFetcher) loop() {
 				f.waittime[hash] = f.clock.Now()
 
 				if waitslots := f.waitslots[ann.origin]; waitslots != nil {
-					waitslots[hash] = struct{}{}
+					waitslots[hash] = meta
 				} else {
-					f.waitslots[ann.origin] = map[common.Hash]struct{}{hash: {}}
+					f.waitslots[ann.origin] = map[common.Hash]*txMetadata{hash: meta}
 				}
 			}
 			// If a new item was added to the waitlist, schedule it into the fetcher
@@ -472,9 +535,9 @@ func (f *TxFetcher) loop() {
 					f.announced[hash] = f.waitlist[hash]
 					for peer := range f.waitlist[hash] {
 						if announces := f.announces[peer]; announces != nil {
-							announces[hash] = struct{}{}
+							announces[hash] = f.waitslots[peer][hash]
 						} else {
-							f.announces[peer] = map[common.Hash]struct{}{hash: {}}
+							f.announces[peer] = map[common.Hash]*txMetadata{hash: f.waitslots[peer][hash]}
 						}
 						delete(f.waitslots[peer], hash)
 						if len(f.waitslots[peer]) == 0 {
@@ -532,6 +595,7 @@ func (f *TxFetcher) loop() {
 					}
 					// Keep track of the request as dangling, but never expire
 					f.requests[peer].hashes = nil
+					f.requests[peer].size = 0
 				}
 			}
 			// Schedule a new transaction retrieval
@@ -698,6 +762,12 @@ func (f *TxFetcher) loop() {
 		txFetcherFetchingPeers.Update(int64(len(f.requests)))
 		txFetcherFetchingHashes.Update(int64(len(f.fetching)))
 
+		var fetchingBytes uint64
+		for _, req := range f.requests {
+			fetchingBytes += req.size
+		}
+		txFetcherFetchingBytes.Update(int64(fetchingBytes))
+
 		// Loop did something, ping the step notifier if needed (tests)
 		if f.step != nil {
 			f.step <- struct{}{}
@@ -792,9 +862,18 @@ func (f *TxFetcher) scheduleFetches(timer *mclock.Timer, timeout chan struct{},
 		if len(f.announces[peer]) == 0 {
 			return // continue in the for-each
 		}
-		hashes := make([]common.Hash, 0, maxTxRetrievals)
-		f.forEachHash(f.announces[peer], func(hash common.Hash) bool {
+		var (
+			hashes = make([]common.Hash, 0, maxTxRetrievals)
+			size   uint64
+		)
+		f.forEachAnnounce(f.announces[peer], func(hash common.Hash, meta *txMetadata) bool {
 			if _, ok := f.fetching[hash]; !ok {
+				// Stop if the peer's byte budget would be exceeded, but always
+				// request at least one transaction to avoid stalling on big ones
+				if meta != nil && len(hashes) > 0 && size+uint64(meta.size) > maxTxRetrievalSize {
+					txRequestCappedMeter.Mark(1)
+					return false // break in the for-each
+				}
 				// Mark the hash as fetching and stash away possible alternates
 				f.fetching[hash] = peer
 
@@ -806,7 +885,18 @@ func (f *TxFetcher) scheduleFetches(timer *mclock.Timer, timeout chan struct{},
 
 				// Accumulate the hash and stop if the limit was reached
 				hashes = append(hashes, hash)
-				if len(hashes) >= maxTxRetrievals {
+				switch {
+				case meta == nil:
+					txScheduleUntypedMeter.Mark(1)
+				case meta.kind == types.BlobTxType:
+					txScheduleBlobMeter.Mark(1)
+				default:
+					txScheduleTypedMeter.Mark(1)
+				}
+				if meta != nil {
+					size += uint64(meta.size)
+				}
+				if len(hashes) >= maxTxRetrievals || size >= maxTxRetrievalSize {
 					return false // break in the for-each
 				}
 			}
@@ -814,8 +904,9 @@ func (f *TxFetcher) scheduleFetches(timer *mclock.Timer, timeout chan struct{},
 		})
 		// If any hashes were allocated, request them from the peer
 		if len(hashes) > 0 {
-			f.requests[peer] = &txRequest{hashes: hashes, time: f.clock.Now()}
+			f.requests[peer] = &txRequest{hashes: hashes, time: f.clock.Now(), size: size}
 			txRequestOutMeter.Mark(int64(len(hashes)))
+			txRequestBytesMeter.Mark(int64(size))
 
 			go func(peer string, hashes []common.Hash) {
 				// Try to fetch the transactions, but in case of a request
@@ -855,27 +946,28 @@ func (f *TxFetcher) forEachPeer(peers map[string]struct{}, do func(peer string))
 	}
 }
 
-// forEachHash does a range loop over a map of hashes in production, but during
-// testing it does a deterministic sorted random to allow reproducing issues.
-func (f *TxFetcher) forEachHash(hashes map[common.Hash]struct{}, do func(hash common.Hash) bool) {
-	// If we're running production, use whatever Go's map gives us
-	if f.rand == nil {
-		for hash := range hashes {
-			if !do(hash) {
-				return
-			}
-		}
-		return
-	}
-	// We're running the test suite, make iteration deterministic
-	list := make([]common.Hash, 0, len(hashes))
-	for hash := range hashes {
+// forEachAnnounce iterates over the announcements of a peer in retrieval order:
+// by type class first (see txTypeClass) and announced size second. Ties are
+// visited in Go's map order in production, but during testing in a deterministic
+// sorted random order to allow reproducing issues.
+func (f *TxFetcher) forEachAnnounce(announces map[common.Hash]*txMetadata, do func(hash common.Hash, meta *txMetadata) bool) {
+	list := make([]common.Hash, 0, len(announces))
+	for hash := range announces {
 		list = append(list, hash)
 	}
-	sortHashes(list)
-	rotateHashes(list, f.rand.Intn(len(list)))
+	if f.rand != nil {
+		sortHashes(list)
+		rotateHashes(list, f.rand.Intn(len(list)))
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		mi, mj := announces[list[i]], announces[list[j]]
+		if ci, cj := txTypeClass(mi), txTypeClass(mj); ci != cj {
+			return ci < cj
+		}
+		return mi != nil && mj != nil && mi.size < mj.size
+	})
 	for _, hash := range list {
-		if !do(hash) {
+		if !do(hash, announces[hash]) {
 			return
 		}
 	}
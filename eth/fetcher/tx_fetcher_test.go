@@ -44,6 +44,8 @@ var (
 type doTxNotify struct {
 	peer   string
 	hashes []common.Hash
+	types  []byte
+	sizes  []uint32
 }
 type doTxEnqueue struct {
 	peer   string
@@ -792,6 +794,84 @@ func TestTransactionFetcherRateLimiting(t *testing.T) {
 	})
 }
 
+// Tests that transactions announced with metadata are requested ordered by type
+// class and size, and that a request is capped by the peer's in-flight byte budget.
+func TestTransactionFetcherPrioritization(t *testing.T) {
+	testTransactionFetcherParallel(t, txFetcherTest{
+		init: func() *TxFetcher {
+			return NewTxFetcher(
+				func(common.Hash) bool { return false },
+				nil,
+				func(string, []common.Hash) error { return nil },
+			)
+		},
+		steps: []interface{}{
+			// Announce a blob transaction and two smaller ones, all fitting in
+			// the budget individually but not together
+			doTxNotify{
+				peer:   "A",
+				hashes: []common.Hash{{0x01}, {0x02}, {0x03}},
+				types:  []byte{types.BlobTxType, types.DynamicFeeTxType, types.LegacyTxType},
+				sizes:  []uint32{100 * 1024, 100 * 1024, 20 * 1024},
+			},
+			doWait{time: txArriveTimeout, step: true},
+			isWaiting(nil),
+			isScheduled{
+				tracking: map[string][]common.Hash{
+					"A": {{0x01}, {0x02}, {0x03}},
+				},
+				fetching: map[string][]common.Hash{
+					"A": {{0x02}, {0x03}},
+				},
+			},
+		},
+	})
+}
+
+// Tests that a transaction exceeding the in-flight byte budget by itself is
+// still requested, but alone.
+func TestTransactionFetcherOversizedAnnounce(t *testing.T) {
+	testTransactionFetcherParallel(t, txFetcherTest{
+		init: func() *TxFetcher {
+			return NewTxFetcher(
+				func(common.Hash) bool { return false },
+				nil,
+				func(string, []common.Hash) error { return nil },
+			)
+		},
+		steps: []interface{}{
+			doTxNotify{
+				peer:   "A",
+				hashes: []common.Hash{{0x01}, {0x02}},
+				types:  []byte{types.DynamicFeeTxType, types.DynamicFeeTxType},
+				sizes:  []uint32{maxTxRetrievalSize + 1, maxTxRetrievalSize * 2},
+			},
+			doWait{time: txArriveTimeout, step: true},
+			isWaiting(nil),
+			isScheduled{
+				tracking: map[string][]common.Hash{
+					"A": {{0x01}, {0x02}},
+				},
+				fetching: map[string][]common.Hash{
+					"A": {{0x01}},
+				},
+			},
+		},
+	})
+}
+
+// Tests that announcements with metadata not matching the hashes are rejected.
+func TestTransactionFetcherInvalidMetadata(t *testing.T) {
+	fetcher := NewTxFetcher(
+		func(common.Hash) bool { return false },
+		nil,
+		func(string, []common.Hash) error { return nil },
+	)
+	if err := fetcher.Notify("A", []byte{types.LegacyTxType}, []uint32{100}, []common.Hash{{0x01}, {0x02}}); err == nil {
+		t.Fatalf("mismatching metadata accepted")
+	}
+}
+
 // Tests that then number of transactions a peer is allowed to announce and/or
 // request at the same time is hard capped.
 func TestTransactionFetcherDoSProtection(t *testing.T) {
@@ -1276,7 +1356,7 @@ func testTransactionFetcher(t *testing.T, tt txFetcherTest) {
 	for i, step := range tt.steps {
 		switch step := step.(type) {
 		case doTxNotify:
-			if err := fetcher.Notify(step.peer, step.hashes); err != nil {
+			if err := fetcher.Notify(step.peer, step.types, step.sizes, step.hashes); err != nil {
 				t.Errorf("step %d: %v", i, err)
 			}
 			<-wait // Fetcher needs to process this, wait until it's done
@@ -18,6 +18,7 @@ package filters
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -28,16 +29,31 @@ import (
 	"github.com/gorievm/go-gori"
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/internal/ethapi"
+	"github.com/gorievm/go-gori/log"
 	"github.com/gorievm/go-gori/rpc"
 )
 
 var (
 	errInvalidTopic   = errors.New("invalid topic(s)")
 	errFilterNotFound = errors.New("filter not found")
+	errInvalidCursor  = errors.New("invalid logs cursor")
+	errCursorReorged  = errors.New("logs cursor invalidated by chain reorg")
+	errPageFull       = errors.New("logs page full")
 )
 
+// defaultLogsPageSize is the number of logs returned per page of eth_getLogsPage
+// if the results per query are not limited.
+const defaultLogsPageSize = 10000
+
+// limitExceededError is returned if a log query exceeds the configured limits.
+type limitExceededError struct{ msg string }
+
+func (e *limitExceededError) Error() string  { return e.msg }
+func (e *limitExceededError) ErrorCode() int { return -32005 }
+
 // filter is a helper struct that holds meta information over the filter type
 // and associated subscription in the event system.
 type filter struct {
@@ -140,10 +156,134 @@ func (api *FilterAPI) NewPendingTransactionFilter(fullTx *bool) rpc.ID {
 	return pendingTxSub.ID
 }
 
+// PendingTxCriteria represents the options of a pending transaction subscription,
+// selecting the transactions delivered and their format. All set conditions need
+// to match for a transaction to be delivered. For backwards compatibility, a plain
+// boolean is accepted as the FullTx flag.
+type PendingTxCriteria struct {
+	FullTx    bool             // Deliver full transactions instead of hashes
+	From      []common.Address // Senders to restrict to (empty = any)
+	To        []common.Address // Recipients to restrict to (empty = any, creations never match)
+	MinTip    *big.Int         // Minimum effective tip at the current base fee (nil = any)
+	Selectors [][4]byte        // Calldata method selectors to restrict to (empty = any)
+}
+
+// UnmarshalJSON sets *args fields with given data.
+func (args *PendingTxCriteria) UnmarshalJSON(data []byte) error {
+	var fullTx bool
+	if err := json.Unmarshal(data, &fullTx); err == nil {
+		*args = PendingTxCriteria{FullTx: fullTx}
+		return nil
+	}
+	var raw struct {
+		FullTx    bool             `json:"fullTx"`
+		From      []common.Address `json:"from"`
+		To        []common.Address `json:"to"`
+		MinTip    *hexutil.Big     `json:"minTip"`
+		Selectors []hexutil.Bytes  `json:"selectors"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*args = PendingTxCriteria{
+		FullTx:    raw.FullTx,
+		From:      raw.From,
+		To:        raw.To,
+		MinTip:    (*big.Int)(raw.MinTip),
+		Selectors: make([][4]byte, len(raw.Selectors)),
+	}
+	for i, selector := range raw.Selectors {
+		if len(selector) != 4 {
+			return fmt.Errorf("invalid selector %d: have %d bytes, want 4", i, len(selector))
+		}
+		copy(args.Selectors[i][:], selector)
+	}
+	return nil
+}
+
+// pendingTxFilter matches pending transactions against a set of criteria.
+type pendingTxFilter struct {
+	from      map[common.Address]struct{}
+	to        map[common.Address]struct{}
+	minTip    *big.Int
+	selectors map[[4]byte]struct{}
+	signer    types.Signer
+}
+
+// newPendingTxFilter creates a filter out of the given criteria, which may be
+// nil to match all transactions.
+func newPendingTxFilter(crit *PendingTxCriteria, signer types.Signer) *pendingTxFilter {
+	f := &pendingTxFilter{signer: signer}
+	if crit == nil {
+		return f
+	}
+	if len(crit.From) > 0 {
+		f.from = make(map[common.Address]struct{}, len(crit.From))
+		for _, addr := range crit.From {
+			f.from[addr] = struct{}{}
+		}
+	}
+	if len(crit.To) > 0 {
+		f.to = make(map[common.Address]struct{}, len(crit.To))
+		for _, addr := range crit.To {
+			f.to[addr] = struct{}{}
+		}
+	}
+	if len(crit.Selectors) > 0 {
+		f.selectors = make(map[[4]byte]struct{}, len(crit.Selectors))
+		for _, selector := range crit.Selectors {
+			f.selectors[selector] = struct{}{}
+		}
+	}
+	f.minTip = crit.MinTip
+	return f
+}
+
+// match reports whether the transaction satisfies the filter. The cheap checks
+// are done first, the sender is only recovered if filtering on it.
+func (f *pendingTxFilter) match(tx *types.Transaction, baseFee *big.Int) bool {
+	if f.to != nil {
+		if tx.To() == nil {
+			return false
+		}
+		if _, ok := f.to[*tx.To()]; !ok {
+			return false
+		}
+	}
+	if f.selectors != nil {
+		data := tx.Data()
+		if len(data) < 4 {
+			return false
+		}
+		var selector [4]byte
+		copy(selector[:], data)
+		if _, ok := f.selectors[selector]; !ok {
+			return false
+		}
+	}
+	if f.minTip != nil {
+		tip, err := tx.EffectiveGasTip(baseFee)
+		if err != nil || tip.Cmp(f.minTip) < 0 {
+			return false
+		}
+	}
+	if f.from != nil {
+		from, err := types.Sender(f.signer, tx)
+		if err != nil {
+			return false
+		}
+		if _, ok := f.from[from]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // NewPendingTransactions creates a subscription that is triggered each time a
-// transaction enters the transaction pool. If fullTx is true the full tx is
-// sent to the client, otherwise the hash is sent.
-func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool) (*rpc.Subscription, error) {
+// transaction enters the transaction pool. Only transactions matching the given
+// criteria are delivered. If fullTx is set the full tx is sent to the client,
+// otherwise the hash is sent.
+func (api *FilterAPI) NewPendingTransactions(ctx context.Context, crit *PendingTxCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -155,6 +295,7 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 		txs := make(chan []*types.Transaction, 128)
 		pendingTxSub := api.events.SubscribePendingTxs(txs)
 		chainConfig := api.sys.backend.ChainConfig()
+		filter := newPendingTxFilter(crit, types.LatestSigner(chainConfig))
 
 		for {
 			select {
@@ -163,7 +304,10 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 				// TODO(rjl493456442) Send a batch of tx hashes in one notification
 				latest := api.sys.backend.CurrentHeader()
 				for _, tx := range txs {
-					if fullTx != nil && *fullTx {
+					if !filter.match(tx, latest.BaseFee) {
+						continue
+					}
+					if crit != nil && crit.FullTx {
 						rpcTx := ethapi.NewRPCPendingTransaction(tx, latest, chainConfig)
 						notifier.Notify(rpcSub.ID, rpcTx)
 					} else {
@@ -183,6 +327,56 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 	return rpcSub, nil
 }
 
+// DroppedTransaction is the notification of a transaction leaving the
+// transaction pool.
+type DroppedTransaction struct {
+	Hash   common.Hash    `json:"hash"`
+	From   common.Address `json:"from"`
+	Nonce  hexutil.Uint64 `json:"nonce"`
+	Reason string         `json:"reason"`
+}
+
+// DroppedTransactions creates a subscription that is triggered each time a
+// transaction leaves the transaction pool, reporting the reason of its removal:
+// replaced, underpriced, nonce-too-low, evicted, included, invalid or expired.
+func (api *FilterAPI) DroppedTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		drops := make(chan core.DropTxsEvent, 128)
+		dropTxSub := api.events.SubscribeDroppedTxs(drops)
+		signer := types.LatestSigner(api.sys.backend.ChainConfig())
+
+		for {
+			select {
+			case ev := <-drops:
+				for _, tx := range ev.Txs {
+					from, _ := types.Sender(signer, tx)
+					notifier.Notify(rpcSub.ID, &DroppedTransaction{
+						Hash:   tx.Hash(),
+						From:   from,
+						Nonce:  hexutil.Uint64(tx.Nonce()),
+						Reason: ev.Reason.String(),
+					})
+				}
+			case <-rpcSub.Err():
+				dropTxSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				dropTxSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
 func (api *FilterAPI) NewBlockFilter() rpc.ID {
@@ -247,6 +441,8 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
+// Logs removed by chain reorgs are delivered with the depth of the reorg and the
+// hash of the block replacing theirs.
 func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
@@ -256,9 +452,10 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 	var (
 		rpcSub      = notifier.CreateSubscription()
 		matchedLogs = make(chan []*types.Log)
+		removedLogs = make(chan core.RemovedLogsEvent)
 	)
 
-	logsSub, err := api.events.SubscribeLogs(ethereum.FilterQuery(crit), matchedLogs)
+	logsSub, err := api.events.SubscribeLogsWithRemovals(ethereum.FilterQuery(crit), matchedLogs, removedLogs)
 	if err != nil {
 		return nil, err
 	}
@@ -271,6 +468,10 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 					log := log
 					notifier.Notify(rpcSub.ID, &log)
 				}
+			case ev := <-removedLogs:
+				for _, log := range ev.Logs {
+					notifier.Notify(rpcSub.ID, newRemovedLog(log, ev))
+				}
 			case <-rpcSub.Err(): // client send an unsubscribe request
 				logsSub.Unsubscribe()
 				return
@@ -284,6 +485,231 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 	return rpcSub, nil
 }
 
+// RemovedLog is the notification of a log removed by a chain reorg, extending
+// the log with the context of the reorg.
+type RemovedLog struct {
+	*types.Log
+	ReorgDepth hexutil.Uint64 // Number of blocks dropped from the canonical chain
+	ReplacedBy *common.Hash   // Block taking the place of the log's block, nil if none
+}
+
+// newRemovedLog creates the notification of a log removed by the given reorg.
+func newRemovedLog(log *types.Log, ev core.RemovedLogsEvent) *RemovedLog {
+	removed := &RemovedLog{Log: log, ReorgDepth: hexutil.Uint64(ev.Depth)}
+	if hash, ok := ev.Replaced[log.BlockHash]; ok {
+		removed.ReplacedBy = &hash
+	}
+	return removed
+}
+
+// MarshalJSON encodes the removed log as a regular log with the reorg context
+// appended, keeping it decodable by clients unaware of the extra fields.
+func (l *RemovedLog) MarshalJSON() ([]byte, error) {
+	enc, err := json.Marshal(l.Log)
+	if err != nil {
+		return nil, err
+	}
+	extra, err := json.Marshal(struct {
+		ReorgDepth hexutil.Uint64 `json:"reorgDepth"`
+		ReplacedBy *common.Hash   `json:"replacedBy"`
+	}{l.ReorgDepth, l.ReplacedBy})
+	if err != nil {
+		return nil, err
+	}
+	// Splice the two objects together: {log...,extra...}
+	return append(append(enc[:len(enc)-1], ','), extra[1:]...), nil
+}
+
+// LogsWithHistory creates a subscription that first delivers all logs matching
+// the given filter criteria from the fromBlock up to the head of the chain, and
+// then continues with new logs as they are mined. Live logs arriving during the
+// backfill are held back and deduplicated against the delivered history, so no
+// logs are missed or repeated in the handover. Reorgs touching the history
+// already delivered are reported as removed logs.
+func (api *FilterAPI) LogsWithHistory(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	var (
+		rpcSub = notifier.CreateSubscription()
+		quit   = make(chan struct{})
+	)
+	err := api.historicalLogs(crit, func(log *types.Log) {
+		notifier.Notify(rpcSub.ID, log)
+	}, quit)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		select {
+		case <-rpcSub.Err(): // client send an unsubscribe request
+		case <-notifier.Closed(): // connection dropped
+		}
+		close(quit)
+	}()
+	return rpcSub, nil
+}
+
+// checkHistoryCriteria verifies that the filter criteria describe an open ended
+// range starting at a specific block.
+func checkHistoryCriteria(crit FilterCriteria) error {
+	if crit.BlockHash != nil {
+		return errors.New("block hash not supported in log subscriptions with history")
+	}
+	if crit.FromBlock == nil || crit.FromBlock.Sign() < 0 {
+		return errors.New("log subscription with history requires a fromBlock number")
+	}
+	if crit.ToBlock != nil && crit.ToBlock.Int64() != rpc.LatestBlockNumber.Int64() {
+		return errors.New("log subscription with history cannot have a toBlock")
+	}
+	return nil
+}
+
+// historicalLogs subscribes to the live logs matching the given criteria, then
+// streams the matching historical logs to notify in the background, followed by
+// the live ones. The stream is torn down when quit is closed.
+func (api *FilterAPI) historicalLogs(crit FilterCriteria, notify func(*types.Log), quit chan struct{}) error {
+	if err := checkHistoryCriteria(crit); err != nil {
+		return err
+	}
+	// Subscribe to the live logs before looking at the chain, anything mined in
+	// the meantime is delivered both ways and filtered by the cursor
+	crit.ToBlock = nil
+
+	matchedLogs := make(chan []*types.Log)
+	logsSub, err := api.events.SubscribeLogs(ethereum.FilterQuery(crit), matchedLogs)
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer logsSub.Unsubscribe()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var (
+			cursor = newLogCursor()
+			done   = make(chan error, 1)
+			queued []*types.Log
+		)
+		go func() {
+			done <- api.backfillLogs(ctx, crit, cursor, notify)
+		}()
+		for {
+			select {
+			case logs := <-matchedLogs:
+				// The live logs are held back until the history is delivered
+				if done != nil {
+					queued = append(queued, logs...)
+					continue
+				}
+				for _, log := range logs {
+					if cursor.live(log) {
+						notify(log)
+					}
+				}
+			case err := <-done:
+				if err != nil {
+					log.Warn("Failed to backfill historical logs", "err", err)
+					return
+				}
+				// History delivered, release the live logs not yet seen. Removed
+				// logs of blocks never delivered are dropped.
+				for _, log := range queued {
+					if cursor.queued(log) {
+						notify(log)
+					}
+				}
+				done, queued = nil, nil
+
+			case <-quit:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// backfillLogs delivers the historical logs matching the given criteria up to
+// the head of the chain, repeating until it caught up with a moving head.
+func (api *FilterAPI) backfillLogs(ctx context.Context, crit FilterCriteria, cursor *logCursor, notify func(*types.Log)) error {
+	from := crit.FromBlock.Int64()
+	for {
+		head := api.sys.backend.CurrentHeader()
+		if head == nil || head.Number.Int64() < from {
+			return nil
+		}
+		end := head.Number.Int64()
+		if limit := api.sys.cfg.LogRangeLimit; limit > 0 && uint64(end-from) >= limit {
+			end = from + int64(limit) - 1
+		}
+		filter := api.sys.NewRangeFilter(from, end, crit.Addresses, crit.Topics)
+		err := filter.forEachLog(ctx, func(log *types.Log) error {
+			cursor.deliver(log, uint64(end))
+			notify(log)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		from = end + 1
+	}
+}
+
+// logsBackfillReorgDepth is the number of blocks below the head for which the
+// blocks delivered from history are tracked to deduplicate the live logs.
+const logsBackfillReorgDepth = 128
+
+// logCursor tracks the blocks delivered from history to deduplicate the live
+// logs of a subscription against them.
+type logCursor struct {
+	lock sync.Mutex
+	sent map[common.Hash]struct{} // Recent blocks with logs delivered from history
+}
+
+// newLogCursor creates an empty log cursor.
+func newLogCursor() *logCursor {
+	return &logCursor{sent: make(map[common.Hash]struct{})}
+}
+
+// deliver marks the block of a historical log as delivered, if it's recent
+// enough to be reported on by live logs too.
+func (c *logCursor) deliver(log *types.Log, head uint64) {
+	if log.BlockNumber+logsBackfillReorgDepth <= head {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.sent[log.BlockHash] = struct{}{}
+}
+
+// queued reports whether a live log held back during the backfill needs to be
+// delivered. Since no live logs were delivered yet, removals only concern the
+// blocks delivered from history.
+func (c *logCursor) queued(log *types.Log) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, sent := c.sent[log.BlockHash]
+	return sent == log.Removed
+}
+
+// live reports whether a live log arriving after the backfill needs to be
+// delivered.
+func (c *logCursor) live(log *types.Log) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if log.Removed {
+		delete(c.sent, log.BlockHash)
+		return true
+	}
+	_, sent := c.sent[log.BlockHash]
+	return !sent
+}
+
 // FilterCriteria represents a request to create a new filter.
 // Same as ethereum.FilterQuery but with UnmarshalJSON() method.
 type FilterCriteria ethereum.FilterQuery
@@ -332,7 +758,11 @@ func (api *FilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 }
 
 // GetLogs returns logs matching the given argument that are stored within the state.
-func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
+// If a spool is configured, results too large to be held in memory are written to
+// disk while the logs are retrieved and streamed to the client from there. Queries
+// exceeding the configured result or block range limits are rejected, those need
+// to be paginated via GetLogsPage.
+func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) (interface{}, error) {
 	var filter *Filter
 	if crit.BlockHash != nil {
 		// Block filter requested, construct a single-shot filter
@@ -350,14 +780,173 @@ func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*type
 		// Construct the range filter
 		filter = api.sys.NewRangeFilter(begin, end, crit.Addresses, crit.Topics)
 	}
+	if api.sys.cfg.Spool != nil {
+		return api.spoolLogs(ctx, filter)
+	}
 	// Run the filter and return all the logs
-	logs, err := filter.Logs(ctx)
+	logs, err := api.limitedLogs(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 	return returnLogs(logs), err
 }
 
+// spoolLogs runs the filter, encoding the logs into a spooled result as they are
+// found.
+func (api *FilterAPI) spoolLogs(ctx context.Context, filter *Filter) (interface{}, error) {
+	result := api.sys.cfg.Spool.NewArray()
+	err := filter.forEachLog(ctx, api.limitLogs(func(log *types.Log) error {
+		return result.Append(log)
+	}))
+	if err != nil {
+		result.Discard()
+		return nil, err
+	}
+	return result.Result()
+}
+
+// limitedLogs runs the filter and returns all the logs, failing if there are more
+// than the configured result limit.
+func (api *FilterAPI) limitedLogs(ctx context.Context, filter *Filter) ([]*types.Log, error) {
+	var logs []*types.Log
+	err := filter.forEachLog(ctx, api.limitLogs(func(log *types.Log) error {
+		logs = append(logs, log)
+		return nil
+	}))
+	return logs, err
+}
+
+// limitLogs wraps a log callback, aborting the query once it produced more than
+// the configured maximum number of logs.
+func (api *FilterAPI) limitLogs(fn func(*types.Log) error) func(*types.Log) error {
+	limit := api.sys.cfg.LogResultLimit
+	if limit <= 0 {
+		return fn
+	}
+	var count int
+	return func(log *types.Log) error {
+		if count++; count > limit {
+			return &limitExceededError{fmt.Sprintf("query returned more than %d results at block %d, narrow the range or use eth_getLogsPage", limit, log.BlockNumber)}
+		}
+		return fn(log)
+	}
+}
+
+// LogsPage is a page of logs matching a query, along with the cursor to retrieve
+// the next page with.
+type LogsPage struct {
+	Logs   []*types.Log  `json:"logs"`
+	Cursor hexutil.Bytes `json:"cursor,omitempty"` // Continuation of the query, empty if complete
+}
+
+// GetLogsPage returns a page of the logs matching the given range query. The
+// page holds at most as many logs as permitted per eth_getLogs call and searches
+// at most the permitted number of blocks. If the range is not exhausted, the
+// returned cursor retrieves the next page, failing if a reorg changed the block
+// the page ended in.
+func (api *FilterAPI) GetLogsPage(ctx context.Context, crit FilterCriteria, cursor *hexutil.Bytes) (*LogsPage, error) {
+	if crit.BlockHash != nil {
+		return nil, errors.New("block hash queries cannot be paginated")
+	}
+	begin := rpc.LatestBlockNumber.Int64()
+	if crit.FromBlock != nil {
+		begin = crit.FromBlock.Int64()
+	}
+	end := rpc.LatestBlockNumber.Int64()
+	if crit.ToBlock != nil {
+		end = crit.ToBlock.Int64()
+	}
+	if begin == rpc.PendingBlockNumber.Int64() || end == rpc.PendingBlockNumber.Int64() {
+		return nil, errors.New("pending logs cannot be paginated")
+	}
+	var err error
+	if begin, err = api.sys.resolveSpecial(ctx, begin); err != nil {
+		return nil, err
+	}
+	if end, err = api.sys.resolveSpecial(ctx, end); err != nil {
+		return nil, err
+	}
+	// Continue from the cursor if one was given, making sure the partially
+	// returned block is still canonical
+	var from logPosition
+	if cursor != nil && len(*cursor) > 0 {
+		if from, err = decodeLogPosition(*cursor); err != nil {
+			return nil, err
+		}
+		if int64(from.number) < begin || int64(from.number) > end {
+			return nil, errInvalidCursor
+		}
+		if from.hash != (common.Hash{}) {
+			header, err := api.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(from.number))
+			if err != nil {
+				return nil, err
+			}
+			if header == nil || header.Hash() != from.hash {
+				return nil, errCursorReorged
+			}
+		}
+		begin = int64(from.number)
+	}
+	stop := end
+	if limit := api.sys.cfg.LogRangeLimit; limit > 0 && stop >= begin && uint64(stop-begin) >= limit {
+		stop = begin + int64(limit) - 1
+	}
+	size := api.sys.cfg.LogResultLimit
+	if size <= 0 {
+		size = defaultLogsPageSize
+	}
+	page := &LogsPage{Logs: []*types.Log{}}
+	filter := api.sys.NewRangeFilter(begin, stop, crit.Addresses, crit.Topics)
+	err = filter.forEachLog(ctx, func(log *types.Log) error {
+		if log.BlockNumber == from.number && log.Index < from.index {
+			return nil // returned by the previous page
+		}
+		if len(page.Logs) == size {
+			page.Cursor = logPosition{log.BlockNumber, log.BlockHash, log.Index}.encode()
+			return errPageFull
+		}
+		page.Logs = append(page.Logs, log)
+		return nil
+	})
+	if err != nil && err != errPageFull {
+		return nil, err
+	}
+	if page.Cursor == nil && stop < end {
+		page.Cursor = logPosition{number: uint64(stop + 1)}.encode()
+	}
+	return page, nil
+}
+
+// logPosition is the position of a log within the chain, used as the cursor of
+// paginated log queries. A zero hash denotes the start of a block not returned
+// from yet.
+type logPosition struct {
+	number uint64
+	hash   common.Hash
+	index  uint
+}
+
+// encode serializes the position into a cursor.
+func (p logPosition) encode() hexutil.Bytes {
+	enc := make([]byte, 8+common.HashLength+4)
+	binary.BigEndian.PutUint64(enc, p.number)
+	copy(enc[8:], p.hash[:])
+	binary.BigEndian.PutUint32(enc[8+common.HashLength:], uint32(p.index))
+	return enc
+}
+
+// decodeLogPosition deserializes a cursor into a log position.
+func decodeLogPosition(enc []byte) (logPosition, error) {
+	if len(enc) != 8+common.HashLength+4 {
+		return logPosition{}, errInvalidCursor
+	}
+	return logPosition{
+		number: binary.BigEndian.Uint64(enc),
+		hash:   common.BytesToHash(enc[8 : 8+common.HashLength]),
+		index:  uint(binary.BigEndian.Uint32(enc[8+common.HashLength:])),
+	}, nil
+}
+
 // UninstallFilter removes the filter with the given filter id.
 func (api *FilterAPI) UninstallFilter(id rpc.ID) bool {
 	api.filtersMu.Lock()
@@ -402,7 +991,7 @@ func (api *FilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*types.Lo
 		filter = api.sys.NewRangeFilter(begin, end, f.crit.Addresses, f.crit.Topics)
 	}
 	// Run the filter and return all the logs
-	logs, err := filter.Logs(ctx)
+	logs, err := api.limitedLogs(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -19,9 +19,14 @@ package filters
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
 	"github.com/gorievm/go-gori/rpc"
 )
 
@@ -183,3 +188,134 @@ func TestUnmarshalJSONNewFilterArgs(t *testing.T) {
 		t.Fatalf("expected 0 topics, got %d topics", len(test7.Topics[2]))
 	}
 }
+
+func TestUnmarshalJSONPendingTxCriteria(t *testing.T) {
+	// plain boolean for backwards compatibility
+	var test0 PendingTxCriteria
+	if err := json.Unmarshal([]byte("true"), &test0); err != nil {
+		t.Fatal(err)
+	}
+	if !test0.FullTx || len(test0.From) != 0 || len(test0.To) != 0 || test0.MinTip != nil || len(test0.Selectors) != 0 {
+		t.Fatalf("unexpected criteria: %+v", test0)
+	}
+	// full criteria
+	var test1 PendingTxCriteria
+	vector := `{"fullTx":true,"from":["0x70c87d191324e6712a591f304b4eedef6ad9bb9d"],"to":["0x9b2055d370f73ec7d8a03e965129118dc8f5bf83"],"minTip":"0x3b9aca00","selectors":["0xa9059cbb"]}`
+	if err := json.Unmarshal([]byte(vector), &test1); err != nil {
+		t.Fatal(err)
+	}
+	if !test1.FullTx {
+		t.Fatalf("expected fullTx")
+	}
+	if len(test1.From) != 1 || test1.From[0] != common.HexToAddress("0x70c87d191324e6712a591f304b4eedef6ad9bb9d") {
+		t.Fatalf("invalid from: %v", test1.From)
+	}
+	if len(test1.To) != 1 || test1.To[0] != common.HexToAddress("0x9b2055d370f73ec7d8a03e965129118dc8f5bf83") {
+		t.Fatalf("invalid to: %v", test1.To)
+	}
+	if test1.MinTip == nil || test1.MinTip.Int64() != 1000000000 {
+		t.Fatalf("invalid min tip: %v", test1.MinTip)
+	}
+	if len(test1.Selectors) != 1 || test1.Selectors[0] != [4]byte{0xa9, 0x05, 0x9c, 0xbb} {
+		t.Fatalf("invalid selectors: %x", test1.Selectors)
+	}
+	// invalid selector length
+	var test2 PendingTxCriteria
+	if err := json.Unmarshal([]byte(`{"selectors":["0xa9059c"]}`), &test2); err == nil {
+		t.Fatal("expected error for short selector")
+	}
+}
+
+func TestPendingTxFilterMatch(t *testing.T) {
+	var (
+		key, _    = crypto.GenerateKey()
+		sender    = crypto.PubkeyToAddress(key.PublicKey)
+		target    = common.Address{0x1}
+		signer    = types.LatestSignerForChainID(big.NewInt(1))
+		baseFee   = big.NewInt(10)
+		transfer  = []byte{0xa9, 0x05, 0x9c, 0xbb, 0x00}
+		newSigned = func(to *common.Address, tip int64, data []byte) *types.Transaction {
+			tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+				ChainID:   big.NewInt(1),
+				To:        to,
+				GasTipCap: big.NewInt(tip),
+				GasFeeCap: big.NewInt(100),
+				Gas:       100000,
+				Data:      data,
+			})
+			return tx
+		}
+	)
+	tests := []struct {
+		crit *PendingTxCriteria
+		tx   *types.Transaction
+		want bool
+	}{
+		{nil, newSigned(nil, 1, nil), true},
+		{&PendingTxCriteria{To: []common.Address{target}}, newSigned(&target, 1, nil), true},
+		{&PendingTxCriteria{To: []common.Address{target}}, newSigned(&common.Address{0x2}, 1, nil), false},
+		{&PendingTxCriteria{To: []common.Address{target}}, newSigned(nil, 1, nil), false},
+		{&PendingTxCriteria{From: []common.Address{sender}}, newSigned(&target, 1, nil), true},
+		{&PendingTxCriteria{From: []common.Address{target}}, newSigned(&target, 1, nil), false},
+		{&PendingTxCriteria{MinTip: big.NewInt(5)}, newSigned(&target, 5, nil), true},
+		{&PendingTxCriteria{MinTip: big.NewInt(5)}, newSigned(&target, 4, nil), false},
+		{&PendingTxCriteria{MinTip: big.NewInt(95)}, newSigned(&target, 100, nil), false}, // capped by fee cap - base fee
+		{&PendingTxCriteria{Selectors: [][4]byte{{0xa9, 0x05, 0x9c, 0xbb}}}, newSigned(&target, 1, transfer), true},
+		{&PendingTxCriteria{Selectors: [][4]byte{{0xa9, 0x05, 0x9c, 0xbb}}}, newSigned(&target, 1, transfer[:3]), false},
+		{&PendingTxCriteria{Selectors: [][4]byte{{0xde, 0xad, 0xbe, 0xef}}}, newSigned(&target, 1, transfer), false},
+		{&PendingTxCriteria{From: []common.Address{sender}, To: []common.Address{target}, Selectors: [][4]byte{{0xa9, 0x05, 0x9c, 0xbb}}}, newSigned(&target, 1, transfer), true},
+	}
+	for i, tt := range tests {
+		if have := newPendingTxFilter(tt.crit, signer).match(tt.tx, baseFee); have != tt.want {
+			t.Errorf("test %d: match mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+func TestRemovedLogJSON(t *testing.T) {
+	var (
+		dropped  = common.Hash{0x01}
+		replaced = common.Hash{0x02}
+		log      = &types.Log{
+			Address:     common.Address{0xaa},
+			Topics:      []common.Hash{{0xbb}},
+			Data:        []byte{0xcc},
+			BlockNumber: 7,
+			BlockHash:   dropped,
+			Removed:     true,
+		}
+	)
+	tests := []struct {
+		ev       core.RemovedLogsEvent
+		depth    string
+		replaced string
+	}{
+		{core.RemovedLogsEvent{Depth: 2, Replaced: map[common.Hash]common.Hash{dropped: replaced}}, `"0x2"`, `"` + replaced.Hex() + `"`},
+		{core.RemovedLogsEvent{Depth: 1}, `"0x1"`, `null`},
+	}
+	for i, tt := range tests {
+		enc, err := json.Marshal(newRemovedLog(log, tt.ev))
+		if err != nil {
+			t.Fatalf("test %d: failed to encode: %v", i, err)
+		}
+		// The reorg context is added to the regular log fields
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(enc, &fields); err != nil {
+			t.Fatalf("test %d: invalid json %s: %v", i, enc, err)
+		}
+		if have := string(fields["reorgDepth"]); have != tt.depth {
+			t.Errorf("test %d: reorg depth mismatch: have %s, want %s", i, have, tt.depth)
+		}
+		if have := string(fields["replacedBy"]); have != tt.replaced {
+			t.Errorf("test %d: replacing block mismatch: have %s, want %s", i, have, tt.replaced)
+		}
+		// Clients unaware of the context still decode the log
+		var dec types.Log
+		if err := json.Unmarshal(enc, &dec); err != nil {
+			t.Fatalf("test %d: failed to decode log: %v", i, err)
+		}
+		if !reflect.DeepEqual(&dec, log) {
+			t.Errorf("test %d: log mismatch: have %+v, want %+v", i, dec, log)
+		}
+	}
+}
@@ -19,14 +19,20 @@ package filters
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/bloombits"
+	"github.com/gorievm/go-gori/core/filtermaps"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/rpc"
 )
 
+// logIndexChunkSize is the number of blocks queried from the log index at once.
+const logIndexChunkSize = 16384
+
 // Filter can be used to retrieve and filter logs.
 type Filter struct {
 	sys *FilterSystem
@@ -95,16 +101,35 @@ func newFilter(sys *FilterSystem, addresses []common.Address, topics [][]common.
 // Logs searches the blockchain for matching log entries, returning all from the
 // first block that contains matches, updating the start of the filter accordingly.
 func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
+	var logs []*types.Log
+	err := f.forEachLog(ctx, func(log *types.Log) error {
+		logs = append(logs, log)
+		return nil
+	})
+	return logs, err
+}
+
+// forEachLog searches the blockchain for matching log entries like Logs, but hands
+// them to fn in order as they are found instead of collecting them. If fn returns an
+// error, the search is aborted and the error returned.
+func (f *Filter) forEachLog(ctx context.Context, fn func(*types.Log) error) error {
 	// If we're doing singleton block filtering, execute and return
 	if f.block != nil {
 		header, err := f.sys.backend.HeaderByHash(ctx, *f.block)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if header == nil {
-			return nil, errors.New("unknown block")
+			return errors.New("unknown block")
+		}
+		if header.Number.Uint64() < f.sys.backend.HistoryPruningCutoff() {
+			return &core.PrunedHistoryError{}
+		}
+		logs, err := f.blockLogs(ctx, header)
+		if err != nil {
+			return err
 		}
-		return f.blockLogs(ctx, header)
+		return emitLogs(logs, fn)
 	}
 
 	var (
@@ -114,68 +139,92 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 
 	// special case for pending logs
 	if beginPending && !endPending {
-		return nil, errors.New("invalid block range")
+		return errors.New("invalid block range")
 	}
 
 	// Short-cut if all we care about is pending logs
 	if beginPending && endPending {
-		return f.pendingLogs(), nil
-	}
-
-	resolveSpecial := func(number int64) (int64, error) {
-		var hdr *types.Header
-		switch number {
-		case rpc.LatestBlockNumber.Int64(), rpc.PendingBlockNumber.Int64():
-			// we should return head here since we've already captured
-			// that we need to get the pending logs in the pending boolean above
-			hdr, _ = f.sys.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
-			if hdr == nil {
-				return 0, errors.New("latest header not found")
-			}
-		case rpc.FinalizedBlockNumber.Int64():
-			hdr, _ = f.sys.backend.HeaderByNumber(ctx, rpc.FinalizedBlockNumber)
-			if hdr == nil {
-				return 0, errors.New("finalized header not found")
-			}
-		case rpc.SafeBlockNumber.Int64():
-			hdr, _ = f.sys.backend.HeaderByNumber(ctx, rpc.SafeBlockNumber)
-			if hdr == nil {
-				return 0, errors.New("safe header not found")
-			}
-		default:
-			return number, nil
-		}
-		return hdr.Number.Int64(), nil
+		return emitLogs(f.pendingLogs(), fn)
 	}
 
 	var err error
 	// range query need to resolve the special begin/end block number
-	if f.begin, err = resolveSpecial(f.begin); err != nil {
-		return nil, err
+	if f.begin, err = f.sys.resolveSpecial(ctx, f.begin); err != nil {
+		return err
 	}
-	if f.end, err = resolveSpecial(f.end); err != nil {
-		return nil, err
+	if f.end, err = f.sys.resolveSpecial(ctx, f.end); err != nil {
+		return err
 	}
+	// Refuse searching more blocks than permitted in a single query
+	if limit := f.sys.cfg.LogRangeLimit; limit > 0 && f.end >= f.begin && uint64(f.end-f.begin) >= limit {
+		return &limitExceededError{fmt.Sprintf("query exceeds max block range %d", limit)}
+	}
+	// The logs of the expired history can't be searched
+	if f.begin >= 0 && uint64(f.begin) < f.sys.backend.HistoryPruningCutoff() {
+		return &core.PrunedHistoryError{}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	logChan, errChan := f.rangeLogsAsync(ctx)
-	var logs []*types.Log
 	for {
 		select {
 		case log := <-logChan:
-			logs = append(logs, log)
+			if err := fn(log); err != nil {
+				// Abort the retrieval and wait for it to wind down
+				cancel()
+				<-errChan
+				return err
+			}
 		case err := <-errChan:
 			if err != nil {
-				// if an error occurs during extraction, we do return the extracted data
-				return logs, err
+				// if an error occurs during extraction, the logs already emitted stand
+				return err
 			}
 			// Append the pending ones
 			if endPending {
-				pendingLogs := f.pendingLogs()
-				logs = append(logs, pendingLogs...)
+				return emitLogs(f.pendingLogs(), fn)
 			}
-			return logs, nil
+			return nil
+		}
+	}
+}
+
+// resolveSpecial resolves the special block numbers latest, pending, safe and
+// finalized of a range query to the number of the corresponding block. Pending
+// resolves to the head, the pending logs are handled by the caller.
+func (sys *FilterSystem) resolveSpecial(ctx context.Context, number int64) (int64, error) {
+	var hdr *types.Header
+	switch number {
+	case rpc.LatestBlockNumber.Int64(), rpc.PendingBlockNumber.Int64():
+		hdr, _ = sys.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+		if hdr == nil {
+			return 0, errors.New("latest header not found")
+		}
+	case rpc.FinalizedBlockNumber.Int64():
+		hdr, _ = sys.backend.HeaderByNumber(ctx, rpc.FinalizedBlockNumber)
+		if hdr == nil {
+			return 0, errors.New("finalized header not found")
+		}
+	case rpc.SafeBlockNumber.Int64():
+		hdr, _ = sys.backend.HeaderByNumber(ctx, rpc.SafeBlockNumber)
+		if hdr == nil {
+			return 0, errors.New("safe header not found")
+		}
+	default:
+		return number, nil
+	}
+	return hdr.Number.Int64(), nil
+}
+
+// emitLogs hands each of the logs to fn, stopping at the first error.
+func emitLogs(logs []*types.Log, fn func(*types.Log) error) error {
+	for _, log := range logs {
+		if err := fn(log); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
 // rangeLogsAsync retrieves block-range logs that match the filter criteria asynchronously,
@@ -192,27 +241,55 @@ func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan erro
 			close(logChan)
 		}()
 
-		// Gather all indexed logs, and finish with non indexed ones
+		// Gather the logs from the log index where available. Everything else
+		// is served from the bloom bits sections, which are retired once the
+		// log index caught up, and finally by iterating the blocks.
 		var (
 			end            = uint64(f.end)
 			size, sections = f.sys.backend.BloomStatus()
-			err            error
+			index          = f.sys.backend.LogIndex()
 		)
-		if indexed := sections * size; indexed > uint64(f.begin) {
-			if indexed > end {
-				indexed = end + 1
+		for f.begin <= int64(end) {
+			stop := end
+			if index != nil {
+				if first, last, ok := index.IndexedRange(); ok {
+					switch begin := uint64(f.begin); {
+					case begin >= first && begin <= last:
+						if last < stop {
+							stop = last
+						}
+						err := f.logIndexLogs(ctx, index, stop, logChan)
+						if errors.Is(err, filtermaps.ErrMatchAll) || errors.Is(err, filtermaps.ErrNotIndexed) {
+							index = nil // the remaining range is served without the index
+							continue
+						}
+						if err != nil {
+							errChan <- err
+							return
+						}
+						continue
+					case begin < first && first <= stop:
+						stop = first - 1
+					}
+				}
+			}
+			if indexed := sections * size; indexed > uint64(f.begin) {
+				if indexed > stop {
+					indexed = stop + 1
+				}
+				if err := f.indexedLogs(ctx, indexed-1, logChan); err != nil {
+					errChan <- err
+					return
+				}
 			}
-			if err = f.indexedLogs(ctx, indexed-1, logChan); err != nil {
+			if err := f.unindexedLogs(ctx, stop, logChan); err != nil {
 				errChan <- err
 				return
 			}
+			if f.begin <= int64(stop) {
+				break // the local chain ends before the requested range
+			}
 		}
-
-		if err := f.unindexedLogs(ctx, end, logChan); err != nil {
-			errChan <- err
-			return
-		}
-
 		errChan <- nil
 	}()
 
@@ -256,7 +333,11 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *type
 				return err
 			}
 			for _, log := range found {
-				logChan <- log
+				select {
+				case logChan <- log:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
 			}
 
 		case <-ctx.Done():
@@ -265,6 +346,31 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *type
 	}
 }
 
+// logIndexLogs returns the logs matching the filter criteria based on the log
+// index, which has to cover the blocks up to end.
+func (f *Filter) logIndexLogs(ctx context.Context, index *filtermaps.FilterMaps, end uint64, logChan chan *types.Log) error {
+	for f.begin <= int64(end) {
+		// Query the index in chunks to deliver the first logs early
+		last := uint64(f.begin) + logIndexChunkSize - 1
+		if last > end {
+			last = end
+		}
+		found, err := index.PotentialMatches(ctx, uint64(f.begin), last, f.addresses, f.topics)
+		if err != nil {
+			return err
+		}
+		for _, log := range filterLogs(found, nil, nil, f.addresses, f.topics) {
+			select {
+			case logChan <- log:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		f.begin = int64(last) + 1
+	}
+	return nil
+}
+
 // unindexedLogs returns the logs matching the filter criteria based on raw block
 // iteration and bloom matching.
 func (f *Filter) unindexedLogs(ctx context.Context, end uint64, logChan chan *types.Log) error {
@@ -309,7 +415,7 @@ func (f *Filter) checkMatches(ctx context.Context, header *types.Header) ([]*typ
 	if err != nil {
 		return nil, err
 	}
-	logs := filterLogs(cached.logs, nil, nil, f.addresses, f.topics)
+	logs := cached.index.Filter(f.addresses, f.topics)
 	if len(logs) == 0 {
 		return nil, nil
 	}
@@ -338,11 +444,11 @@ func (f *Filter) pendingLogs() []*types.Log {
 		return nil
 	}
 	if bloomFilter(block.Bloom(), f.addresses, f.topics) {
-		var unfiltered []*types.Log
+		var logs []*types.Log
 		for _, r := range receipts {
-			unfiltered = append(unfiltered, r.Logs...)
+			logs = append(logs, r.IndexedLogs().Filter(f.addresses, f.topics)...)
 		}
-		return filterLogs(unfiltered, nil, nil, f.addresses, f.topics)
+		return logs
 	}
 	return nil
 }
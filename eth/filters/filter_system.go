@@ -31,6 +31,7 @@ import (
 	"github.com/gorievm/go-gori/common/lru"
 	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/bloombits"
+	"github.com/gorievm/go-gori/core/filtermaps"
 	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/ethdb"
@@ -42,8 +43,11 @@ import (
 
 // Config represents the configuration of the filter system.
 type Config struct {
-	LogCacheSize int           // maximum number of cached blocks (default: 32)
-	Timeout      time.Duration // how long filters stay active (default: 5min)
+	LogCacheSize   int           // maximum number of cached blocks (default: 32)
+	LogResultLimit int           // maximum number of logs returned per getLogs call (0 = unlimited)
+	LogRangeLimit  uint64        // maximum number of blocks searched per getLogs call (0 = unlimited)
+	Timeout        time.Duration // how long filters stay active (default: 5min)
+	Spool          *rpc.Spool    // spool for oversized getLogs results (nil = keep in memory)
 }
 
 func (cfg Config) withDefaults() Config {
@@ -68,6 +72,7 @@ type Backend interface {
 	CurrentHeader() *types.Header
 	ChainConfig() *params.ChainConfig
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
+	SubscribeDropTxsEvent(chan<- core.DropTxsEvent) event.Subscription
 	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
@@ -75,6 +80,13 @@ type Backend interface {
 
 	BloomStatus() (uint64, uint64)
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
+
+	// LogIndex returns the log index of the chain, nil if it's not maintained.
+	LogIndex() *filtermaps.FilterMaps
+
+	// HistoryPruningCutoff returns the number of the first block whose logs are
+	// available, the history below it was expired.
+	HistoryPruningCutoff() uint64
 }
 
 // FilterSystem holds resources shared by all filters.
@@ -95,8 +107,9 @@ func NewFilterSystem(backend Backend, config Config) *FilterSystem {
 }
 
 type logCacheElem struct {
-	logs []*types.Log
-	body atomic.Value
+	logs  []*types.Log
+	index *types.IndexedLogs // Logs indexed by contract and event, shared by all queries
+	body  atomic.Value
 }
 
 // cachedLogElem loads block logs from the backend and caches the result.
@@ -127,7 +140,7 @@ func (sys *FilterSystem) cachedLogElem(ctx context.Context, blockHash common.Has
 			flattened = append(flattened, log)
 		}
 	}
-	elem := &logCacheElem{logs: flattened}
+	elem := &logCacheElem{logs: flattened, index: types.NewIndexedLogs(flattened)}
 	sys.logsCache.Add(blockHash, elem)
 	return elem, nil
 }
@@ -162,6 +175,9 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// DroppedTransactionsSubscription queries for transactions leaving the
+	// transaction pool
+	DroppedTransactionsSubscription
 	// LastIndexSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -170,6 +186,8 @@ const (
 	// txChanSize is the size of channel listening to NewTxsEvent.
 	// The number is referenced from the size of tx pool.
 	txChanSize = 4096
+	// dropChanSize is the size of channel listening to DropTxsEvent.
+	dropChanSize = 4096
 	// rmLogsChanSize is the size of channel listening to RemovedLogsEvent.
 	rmLogsChanSize = 10
 	// logsChanSize is the size of channel listening to LogsEvent.
@@ -184,7 +202,9 @@ type subscription struct {
 	created   time.Time
 	logsCrit  ethereum.FilterQuery
 	logs      chan []*types.Log
+	rmLogs    chan core.RemovedLogsEvent // removed logs with reorg context, nil to deliver on logs
 	txs       chan []*types.Transaction
+	drops     chan core.DropTxsEvent
 	headers   chan *types.Header
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
@@ -200,6 +220,7 @@ type EventSystem struct {
 
 	// Subscriptions
 	txsSub         event.Subscription // Subscription for new transaction event
+	dropsSub       event.Subscription // Subscription for dropped transaction event
 	logsSub        event.Subscription // Subscription for new log event
 	rmLogsSub      event.Subscription // Subscription for removed log event
 	pendingLogsSub event.Subscription // Subscription for pending log event
@@ -209,6 +230,7 @@ type EventSystem struct {
 	install       chan *subscription         // install filter for event notification
 	uninstall     chan *subscription         // remove filter for event notification
 	txsCh         chan core.NewTxsEvent      // Channel to receive new transactions event
+	dropsCh       chan core.DropTxsEvent     // Channel to receive dropped transactions event
 	logsCh        chan []*types.Log          // Channel to receive new log event
 	pendingLogsCh chan []*types.Log          // Channel to receive new log event
 	rmLogsCh      chan core.RemovedLogsEvent // Channel to receive removed log event
@@ -229,6 +251,7 @@ func NewEventSystem(sys *FilterSystem, lightMode bool) *EventSystem {
 		install:       make(chan *subscription),
 		uninstall:     make(chan *subscription),
 		txsCh:         make(chan core.NewTxsEvent, txChanSize),
+		dropsCh:       make(chan core.DropTxsEvent, dropChanSize),
 		logsCh:        make(chan []*types.Log, logsChanSize),
 		rmLogsCh:      make(chan core.RemovedLogsEvent, rmLogsChanSize),
 		pendingLogsCh: make(chan []*types.Log, logsChanSize),
@@ -237,13 +260,14 @@ func NewEventSystem(sys *FilterSystem, lightMode bool) *EventSystem {
 
 	// Subscribe events
 	m.txsSub = m.backend.SubscribeNewTxsEvent(m.txsCh)
+	m.dropsSub = m.backend.SubscribeDropTxsEvent(m.dropsCh)
 	m.logsSub = m.backend.SubscribeLogsEvent(m.logsCh)
 	m.rmLogsSub = m.backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
 	m.pendingLogsSub = m.backend.SubscribePendingLogsEvent(m.pendingLogsCh)
 
 	// Make sure none of the subscriptions are empty
-	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.pendingLogsSub == nil {
+	if m.txsSub == nil || m.dropsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.pendingLogsSub == nil {
 		log.Crit("Subscribe for event system failed")
 	}
 
@@ -277,7 +301,9 @@ func (sub *Subscription) Unsubscribe() {
 			case sub.es.uninstall <- sub.f:
 				break uninstallLoop
 			case <-sub.f.logs:
+			case <-sub.f.rmLogs:
 			case <-sub.f.txs:
+			case <-sub.f.drops:
 			case <-sub.f.headers:
 			}
 		}
@@ -300,6 +326,14 @@ func (es *EventSystem) subscribe(sub *subscription) *Subscription {
 // given criteria to the given logs channel. Default value for the from and to
 // block is "latest". If the fromBlock > toBlock an error is returned.
 func (es *EventSystem) SubscribeLogs(crit ethereum.FilterQuery, logs chan []*types.Log) (*Subscription, error) {
+	return es.SubscribeLogsWithRemovals(crit, logs, nil)
+}
+
+// SubscribeLogsWithRemovals creates a subscription like SubscribeLogs, but writes
+// the matching logs removed by chain reorgs to the removed channel, along with the
+// depth of the reorg and the blocks replacing the dropped ones. If removed is nil,
+// the removed logs are written to the logs channel.
+func (es *EventSystem) SubscribeLogsWithRemovals(crit ethereum.FilterQuery, logs chan []*types.Log, removed chan core.RemovedLogsEvent) (*Subscription, error) {
 	var from, to rpc.BlockNumber
 	if crit.FromBlock == nil {
 		from = rpc.LatestBlockNumber
@@ -318,32 +352,33 @@ func (es *EventSystem) SubscribeLogs(crit ethereum.FilterQuery, logs chan []*typ
 	}
 	// only interested in new mined logs
 	if from == rpc.LatestBlockNumber && to == rpc.LatestBlockNumber {
-		return es.subscribeLogs(crit, logs), nil
+		return es.subscribeLogs(crit, logs, removed), nil
 	}
 	// only interested in mined logs within a specific block range
 	if from >= 0 && to >= 0 && to >= from {
-		return es.subscribeLogs(crit, logs), nil
+		return es.subscribeLogs(crit, logs, removed), nil
 	}
 	// interested in mined logs from a specific block number, new logs and pending logs
 	if from >= rpc.LatestBlockNumber && to == rpc.PendingBlockNumber {
-		return es.subscribeMinedPendingLogs(crit, logs), nil
+		return es.subscribeMinedPendingLogs(crit, logs, removed), nil
 	}
 	// interested in logs from a specific block number to new mined blocks
 	if from >= 0 && to == rpc.LatestBlockNumber {
-		return es.subscribeLogs(crit, logs), nil
+		return es.subscribeLogs(crit, logs, removed), nil
 	}
 	return nil, errors.New("invalid from and to block combination: from > to")
 }
 
 // subscribeMinedPendingLogs creates a subscription that returned mined and
 // pending logs that match the given criteria.
-func (es *EventSystem) subscribeMinedPendingLogs(crit ethereum.FilterQuery, logs chan []*types.Log) *Subscription {
+func (es *EventSystem) subscribeMinedPendingLogs(crit ethereum.FilterQuery, logs chan []*types.Log, removed chan core.RemovedLogsEvent) *Subscription {
 	sub := &subscription{
 		id:        rpc.NewID(),
 		typ:       MinedAndPendingLogsSubscription,
 		logsCrit:  crit,
 		created:   time.Now(),
 		logs:      logs,
+		rmLogs:    removed,
 		txs:       make(chan []*types.Transaction),
 		headers:   make(chan *types.Header),
 		installed: make(chan struct{}),
@@ -354,13 +389,14 @@ func (es *EventSystem) subscribeMinedPendingLogs(crit ethereum.FilterQuery, logs
 
 // subscribeLogs creates a subscription that will write all logs matching the
 // given criteria to the given logs channel.
-func (es *EventSystem) subscribeLogs(crit ethereum.FilterQuery, logs chan []*types.Log) *Subscription {
+func (es *EventSystem) subscribeLogs(crit ethereum.FilterQuery, logs chan []*types.Log, removed chan core.RemovedLogsEvent) *Subscription {
 	sub := &subscription{
 		id:        rpc.NewID(),
 		typ:       LogsSubscription,
 		logsCrit:  crit,
 		created:   time.Now(),
 		logs:      logs,
+		rmLogs:    removed,
 		txs:       make(chan []*types.Transaction),
 		headers:   make(chan *types.Header),
 		installed: make(chan struct{}),
@@ -418,6 +454,23 @@ func (es *EventSystem) SubscribePendingTxs(txs chan []*types.Transaction) *Subsc
 	return es.subscribe(sub)
 }
 
+// SubscribeDroppedTxs creates a subscription that writes transactions leaving
+// the transaction pool, along with the reason of their removal.
+func (es *EventSystem) SubscribeDroppedTxs(drops chan core.DropTxsEvent) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       DroppedTransactionsSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		txs:       make(chan []*types.Transaction),
+		drops:     drops,
+		headers:   make(chan *types.Header),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
 type filterIndex map[Type]map[rpc.ID]*subscription
 
 func (es *EventSystem) handleLogs(filters filterIndex, ev []*types.Log) {
@@ -432,6 +485,23 @@ func (es *EventSystem) handleLogs(filters filterIndex, ev []*types.Log) {
 	}
 }
 
+func (es *EventSystem) handleRemovedLogs(filters filterIndex, ev core.RemovedLogsEvent) {
+	if len(ev.Logs) == 0 {
+		return
+	}
+	for _, f := range filters[LogsSubscription] {
+		matchedLogs := filterLogs(ev.Logs, f.logsCrit.FromBlock, f.logsCrit.ToBlock, f.logsCrit.Addresses, f.logsCrit.Topics)
+		if len(matchedLogs) == 0 {
+			continue
+		}
+		if f.rmLogs != nil {
+			f.rmLogs <- core.RemovedLogsEvent{Logs: matchedLogs, Depth: ev.Depth, Replaced: ev.Replaced}
+		} else {
+			f.logs <- matchedLogs
+		}
+	}
+}
+
 func (es *EventSystem) handlePendingLogs(filters filterIndex, ev []*types.Log) {
 	if len(ev) == 0 {
 		return
@@ -450,6 +520,12 @@ func (es *EventSystem) handleTxsEvent(filters filterIndex, ev core.NewTxsEvent)
 	}
 }
 
+func (es *EventSystem) handleDropTxsEvent(filters filterIndex, ev core.DropTxsEvent) {
+	for _, f := range filters[DroppedTransactionsSubscription] {
+		f.drops <- ev
+	}
+}
+
 func (es *EventSystem) handleChainEvent(filters filterIndex, ev core.ChainEvent) {
 	for _, f := range filters[BlocksSubscription] {
 		f.headers <- ev.Block.Header()
@@ -516,15 +592,14 @@ func (es *EventSystem) lightFilterLogs(header *types.Header, addresses []common.
 	if err != nil {
 		return nil
 	}
-	unfiltered := append([]*types.Log{}, cached.logs...)
-	for i, log := range unfiltered {
+	logs := cached.index.Filter(addresses, topics)
+	for i, log := range logs {
 		// Don't modify in-cache elements
 		logcopy := *log
 		logcopy.Removed = remove
 		// Swap copy in-place
-		unfiltered[i] = &logcopy
+		logs[i] = &logcopy
 	}
-	logs := filterLogs(unfiltered, nil, nil, addresses, topics)
 	// Txhash is already resolved
 	if len(logs) > 0 && logs[0].TxHash != (common.Hash{}) {
 		return logs
@@ -546,6 +621,7 @@ func (es *EventSystem) eventLoop() {
 	// Ensure all subscriptions get cleaned up
 	defer func() {
 		es.txsSub.Unsubscribe()
+		es.dropsSub.Unsubscribe()
 		es.logsSub.Unsubscribe()
 		es.rmLogsSub.Unsubscribe()
 		es.pendingLogsSub.Unsubscribe()
@@ -561,10 +637,12 @@ func (es *EventSystem) eventLoop() {
 		select {
 		case ev := <-es.txsCh:
 			es.handleTxsEvent(index, ev)
+		case ev := <-es.dropsCh:
+			es.handleDropTxsEvent(index, ev)
 		case ev := <-es.logsCh:
 			es.handleLogs(index, ev)
 		case ev := <-es.rmLogsCh:
-			es.handleLogs(index, ev.Logs)
+			es.handleRemovedLogs(index, ev)
 		case ev := <-es.pendingLogsCh:
 			es.handlePendingLogs(index, ev)
 		case ev := <-es.chainCh:
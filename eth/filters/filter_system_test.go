@@ -32,6 +32,7 @@ import (
 	"github.com/gorievm/go-gori/consensus/ethash"
 	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/bloombits"
+	"github.com/gorievm/go-gori/core/filtermaps"
 	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/crypto"
@@ -45,7 +46,10 @@ import (
 type testBackend struct {
 	db              ethdb.Database
 	sections        uint64
+	logIndex        *filtermaps.FilterMaps
+	historyCutoff   uint64
 	txFeed          event.Feed
+	dropFeed        event.Feed
 	logsFeed        event.Feed
 	rmLogsFeed      event.Feed
 	pendingLogsFeed event.Feed
@@ -133,6 +137,10 @@ func (b *testBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Sub
 	return b.txFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeDropTxsEvent(ch chan<- core.DropTxsEvent) event.Subscription {
+	return b.dropFeed.Subscribe(ch)
+}
+
 func (b *testBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return b.rmLogsFeed.Subscribe(ch)
 }
@@ -180,6 +188,14 @@ func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.Matc
 	}()
 }
 
+func (b *testBackend) LogIndex() *filtermaps.FilterMaps {
+	return b.logIndex
+}
+
+func (b *testBackend) HistoryPruningCutoff() uint64 {
+	return b.historyCutoff
+}
+
 func newTestFilterSystem(t testing.TB, db ethdb.Database, cfg Config) (*testBackend, *FilterSystem) {
 	backend := &testBackend{db: db}
 	sys := NewFilterSystem(backend, cfg)
@@ -358,6 +374,63 @@ func TestPendingTxFilterFullTx(t *testing.T) {
 	}
 }
 
+// TestDroppedTransactionsSubscription tests whether transactions leaving the
+// pool are announced to subscribers along with the reason of their removal.
+func TestDroppedTransactionsSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		api          = NewFilterAPI(sys, false)
+
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		signer = types.LatestSigner(params.TestChainConfig)
+	)
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", api); err != nil {
+		t.Fatalf("failed to register filter API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	drops := make(chan *DroppedTransaction)
+	sub, err := client.EthSubscribe(context.Background(), drops, "droppedTransactions")
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	var txs []*types.Transaction
+	for i := uint64(0); i < 3; i++ {
+		tx, _ := types.SignTx(types.NewTransaction(i, common.Address{}, new(big.Int), 21000, big.NewInt(1), nil), signer, key)
+		txs = append(txs, tx)
+	}
+	time.Sleep(1 * time.Second)
+	backend.dropFeed.Send(core.DropTxsEvent{Txs: txs[:2], Reason: core.TxDropIncluded})
+	backend.dropFeed.Send(core.DropTxsEvent{Txs: txs[2:], Reason: core.TxDropReplaced})
+
+	want := []DroppedTransaction{
+		{Hash: txs[0].Hash(), From: addr, Nonce: 0, Reason: "included"},
+		{Hash: txs[1].Hash(), From: addr, Nonce: 1, Reason: "included"},
+		{Hash: txs[2].Hash(), From: addr, Nonce: 2, Reason: "replaced"},
+	}
+	for i := range want {
+		select {
+		case drop := <-drops:
+			if *drop != want[i] {
+				t.Errorf("notification %d mismatch: have %+v, want %+v", i, *drop, want[i])
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(time.Second):
+			t.Fatalf("notification %d timed out", i)
+		}
+	}
+}
+
 // TestLogFilterCreation test whether a given filter criteria makes sense.
 // If not it must return an error.
 func TestLogFilterCreation(t *testing.T) {
@@ -957,3 +1030,159 @@ func flattenLogs(pl [][]*types.Log) []*types.Log {
 	}
 	return logs
 }
+
+// TestLogsWithHistory tests that a log subscription with history delivers the
+// historical logs followed by the live ones, without gaps or duplicates in the
+// handover, and reports reorgs of the delivered blocks.
+func TestLogsWithHistory(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		api          = NewFilterAPI(sys, false)
+		addr         = common.BytesToAddress([]byte("jeff"))
+
+		gspec = &core.Genesis{
+			BaseFee: big.NewInt(params.InitialBaseFee),
+			Config:  params.TestChainConfig,
+		}
+	)
+	_, chain, receipts := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 10, func(i int, gen *core.BlockGen) {
+		gen.AddUncheckedReceipt(makeReceipt(addr))
+		gen.AddUncheckedTx(types.NewTransaction(999, common.HexToAddress("0x999"), big.NewInt(999), 999, gen.BaseFee(), nil))
+	})
+	gspec.MustCommit(db)
+	insert := func(blocks ...int) {
+		for _, n := range blocks {
+			block := chain[n-1]
+			rawdb.WriteBlock(db, block)
+			rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+			rawdb.WriteHeadBlockHash(db, block.Hash())
+			rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[n-1])
+		}
+	}
+	liveLog := func(n int, removed bool) *types.Log {
+		return &types.Log{Address: addr, BlockNumber: uint64(n), BlockHash: chain[n-1].Hash(), Removed: removed}
+	}
+	insert(1, 2, 3, 4, 5, 6, 7, 8)
+
+	// Invalid ranges are rejected
+	hash := chain[0].Hash()
+	for i, crit := range []FilterCriteria{
+		{},
+		{BlockHash: &hash},
+		{FromBlock: big.NewInt(rpc.LatestBlockNumber.Int64())},
+		{FromBlock: big.NewInt(1), ToBlock: big.NewInt(5)},
+	} {
+		if err := api.historicalLogs(crit, func(*types.Log) {}, nil); err == nil {
+			t.Errorf("criteria %d: invalid range accepted", i)
+		}
+	}
+	var (
+		logs = make(chan *types.Log, 32)
+		quit = make(chan struct{})
+	)
+	defer close(quit)
+
+	crit := FilterCriteria{FromBlock: big.NewInt(2), Addresses: []common.Address{addr}}
+	if err := api.historicalLogs(crit, func(log *types.Log) { logs <- log }, quit); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	// Mine a few more blocks while the history is being delivered, the live logs
+	// overlap with the historical ones
+	insert(9, 10)
+	backend.logsFeed.Send([]*types.Log{liveLog(8, false)})
+	backend.logsFeed.Send([]*types.Log{liveLog(9, false)})
+	backend.logsFeed.Send([]*types.Log{liveLog(10, false)})
+
+	expect := func(n int, removed bool) {
+		t.Helper()
+		select {
+		case log := <-logs:
+			if log.BlockNumber != uint64(n) || log.BlockHash != chain[n-1].Hash() || log.Removed != removed {
+				t.Fatalf("wrong log: have block %d removed %v, want block %d removed %v", log.BlockNumber, log.Removed, n, removed)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for log of block %d", n)
+		}
+	}
+	for n := 2; n <= 10; n++ {
+		expect(n, false)
+	}
+	// Reorg the head out and back in, both need to be reported
+	backend.rmLogsFeed.Send(core.RemovedLogsEvent{Logs: []*types.Log{liveLog(10, true)}})
+	expect(10, true)
+	backend.logsFeed.Send([]*types.Log{liveLog(10, false)})
+	expect(10, false)
+
+	select {
+	case log := <-logs:
+		t.Fatalf("unexpected log of block %d", log.BlockNumber)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TestRemovedLogsSubscription tests that logs removed by reorgs are delivered
+// with the reorg context to subscriptions asking for it, and as regular logs to
+// all others.
+func TestRemovedLogsSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		api          = NewFilterAPI(sys, false)
+
+		addr    = common.HexToAddress("0x1111111111111111111111111111111111111111")
+		other   = common.HexToAddress("0x2222222222222222222222222222222222222222")
+		dropped = common.Hash{0x01}
+		replace = common.Hash{0x02}
+		ev      = core.RemovedLogsEvent{
+			Logs: []*types.Log{
+				{Address: addr, BlockNumber: 5, BlockHash: dropped, Removed: true},
+				{Address: other, BlockNumber: 5, BlockHash: dropped, Removed: true},
+			},
+			Depth:    3,
+			Replaced: map[common.Hash]common.Hash{dropped: replace},
+		}
+		crit = ethereum.FilterQuery{Addresses: []common.Address{addr}}
+
+		contextLogs = make(chan []*types.Log)
+		removed     = make(chan core.RemovedLogsEvent)
+		plainLogs   = make(chan []*types.Log)
+	)
+	contextSub, err := api.events.SubscribeLogsWithRemovals(crit, contextLogs, removed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer contextSub.Unsubscribe()
+
+	plainSub, err := api.events.SubscribeLogs(crit, plainLogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plainSub.Unsubscribe()
+
+	go backend.rmLogsFeed.Send(ev)
+
+	for received := 0; received < 2; received++ {
+		select {
+		case have := <-removed:
+			if len(have.Logs) != 1 || have.Logs[0] != ev.Logs[0] {
+				t.Errorf("wrong removed logs: %v", have.Logs)
+			}
+			if have.Depth != ev.Depth || have.Replaced[dropped] != replace {
+				t.Errorf("wrong reorg context: depth %d, replaced %v", have.Depth, have.Replaced)
+			}
+		case logs := <-plainLogs:
+			if len(logs) != 1 || logs[0] != ev.Logs[0] {
+				t.Errorf("wrong plain logs: %v", logs)
+			}
+		case logs := <-contextLogs:
+			t.Errorf("removed logs delivered without context: %v", logs)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for removed logs")
+		}
+	}
+}
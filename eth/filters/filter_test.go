@@ -26,8 +26,10 @@ import (
 
 	"github.com/gorievm/go-gori/accounts/abi"
 	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
 	"github.com/gorievm/go-gori/consensus/ethash"
 	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/filtermaps"
 	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/core/vm"
@@ -368,4 +370,213 @@ func TestFilters(t *testing.T) {
 			t.Fatalf("expected context.DeadlineExceeded, got %v", err)
 		}
 	})
+
+	t.Run("logindex", func(t *testing.T) {
+		index := filtermaps.New(db, bc, filtermaps.DefaultParams, 0)
+		index.Start()
+		defer index.Stop()
+		select {
+		case <-index.Synced():
+		case <-time.After(10 * time.Second):
+			t.Fatal("log index not synced")
+		}
+		backend := sys.backend.(*testBackend)
+		for i, tc := range []struct {
+			addresses []common.Address
+			topics    [][]common.Hash
+		}{
+			{nil, nil},
+			{[]common.Address{contract}, nil},
+			{[]common.Address{contract, contract2}, [][]common.Hash{{hash1, hash3}}},
+			{nil, [][]common.Hash{{hash2}, {hash1}}},
+			{nil, [][]common.Hash{nil, {hash1}}},
+			{[]common.Address{{0xaa}}, nil},
+		} {
+			backend.logIndex = nil
+			want, err := sys.NewRangeFilter(0, int64(rpc.LatestBlockNumber), tc.addresses, tc.topics).Logs(context.Background())
+			if err != nil {
+				t.Fatalf("test %d: unindexed filter failed: %v", i, err)
+			}
+			backend.logIndex = index
+			have, err := sys.NewRangeFilter(0, int64(rpc.LatestBlockNumber), tc.addresses, tc.topics).Logs(context.Background())
+			backend.logIndex = nil
+			if err != nil {
+				t.Fatalf("test %d: indexed filter failed: %v", i, err)
+			}
+			haveJSON, _ := json.Marshal(have)
+			wantJSON, _ := json.Marshal(want)
+			if string(haveJSON) != string(wantJSON) {
+				t.Fatalf("test %d: log index mismatch, have:\n%s\nwant:\n%s", i, haveJSON, wantJSON)
+			}
+		}
+	})
+}
+
+// Tests that searching the expired history is rejected.
+func TestFiltersPrunedHistory(t *testing.T) {
+	backend, sys := newTestFilterSystem(t, rawdb.NewMemoryDatabase(), Config{})
+	backend.historyCutoff = 10
+
+	_, err := sys.NewRangeFilter(5, 20, nil, nil).Logs(context.Background())
+	if _, ok := err.(*core.PrunedHistoryError); !ok {
+		t.Fatalf("wrong error: have %v, want pruned history", err)
+	}
+	if _, err := sys.NewRangeFilter(10, 20, nil, nil).Logs(context.Background()); err != nil {
+		t.Fatalf("retained history rejected: %v", err)
+	}
+}
+
+func TestSpooledLogs(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		spool   = rpc.NewSpool(t.TempDir(), 1024, 1024*1024)
+		_, sys  = newTestFilterSystem(t, db, Config{Spool: spool})
+		api     = NewFilterAPI(sys, false)
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = common.BytesToAddress([]byte("jeff"))
+
+		gspec = &core.Genesis{
+			Alloc:   core.GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+			Config:  params.TestChainConfig,
+		}
+	)
+	_, chain, receipts := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 64, func(i int, gen *core.BlockGen) {
+		gen.AddUncheckedReceipt(makeReceipt(addr2))
+		gen.AddUncheckedTx(types.NewTransaction(999, common.HexToAddress("0x999"), big.NewInt(999), 999, gen.BaseFee(), nil))
+	})
+	gspec.MustCommit(db)
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	// A single block result is small enough to be kept in memory
+	hash := chain[0].Hash()
+	result, err := api.GetLogs(context.Background(), FilterCriteria{BlockHash: &hash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result.(json.RawMessage); !ok {
+		t.Fatalf("small result not kept in memory: %T", result)
+	}
+	// The full range is spilled to disk and yields the same logs
+	want, err := sys.NewRangeFilter(0, -1, []common.Address{addr2}, nil).Logs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = api.GetLogs(context.Background(), FilterCriteria{FromBlock: big.NewInt(0), Addresses: []common.Address{addr2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, ok := result.(rpc.ResultStream)
+	if !ok {
+		t.Fatalf("large result not spooled: %T", result)
+	}
+	defer stream.Close()
+
+	var have []*types.Log
+	if err := json.NewDecoder(stream).Decode(&have); err != nil {
+		t.Fatal(err)
+	}
+	haveJSON, _ := json.Marshal(have)
+	wantJSON, _ := json.Marshal(want)
+	if len(want) != len(chain) || string(haveJSON) != string(wantJSON) {
+		t.Fatalf("spooled logs mismatch: have %d logs, want %d", len(have), len(want))
+	}
+	stream.Close()
+	if used := spool.Used(); used != 0 {
+		t.Fatalf("spool still holds %d bytes", used)
+	}
+	// Queries exceeding the disk quota are rejected
+	sys.cfg.Spool = rpc.NewSpool(t.TempDir(), 1024, 4096)
+	if _, err := api.GetLogs(context.Background(), FilterCriteria{FromBlock: big.NewInt(0)}); err != rpc.ErrSpoolQuotaExceeded {
+		t.Fatalf("wrong error: have %v, want %v", err, rpc.ErrSpoolQuotaExceeded)
+	}
+}
+
+func TestLogsLimits(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		_, sys = newTestFilterSystem(t, db, Config{LogResultLimit: 10, LogRangeLimit: 20})
+		api    = NewFilterAPI(sys, false)
+		addr   = common.BytesToAddress([]byte("jeff"))
+
+		gspec = &core.Genesis{
+			BaseFee: big.NewInt(params.InitialBaseFee),
+			Config:  params.TestChainConfig,
+		}
+	)
+	_, chain, receipts := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 64, func(i int, gen *core.BlockGen) {
+		gen.AddUncheckedReceipt(makeReceipt(addr))
+		gen.AddUncheckedTx(types.NewTransaction(999, common.HexToAddress("0x999"), big.NewInt(999), 999, gen.BaseFee(), nil))
+	})
+	gspec.MustCommit(db)
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	// Queries exceeding either limit are rejected
+	for i, crit := range []FilterCriteria{
+		{FromBlock: big.NewInt(0)},
+		{FromBlock: big.NewInt(1), ToBlock: big.NewInt(11)},
+	} {
+		_, err := api.GetLogs(context.Background(), crit)
+		if lerr, ok := err.(*limitExceededError); !ok || lerr.ErrorCode() != -32005 {
+			t.Errorf("query %d: wrong error: %v", i, err)
+		}
+	}
+	if logs, err := api.GetLogs(context.Background(), FilterCriteria{FromBlock: big.NewInt(1), ToBlock: big.NewInt(10)}); err != nil {
+		t.Errorf("query within limits failed: %v", err)
+	} else if n := len(logs.([]*types.Log)); n != 10 {
+		t.Errorf("wrong number of logs: have %d, want 10", n)
+	}
+	// Paginating yields all the logs of the unrestricted query
+	_, unlimited := newTestFilterSystem(t, db, Config{})
+	want, err := unlimited.NewRangeFilter(0, -1, []common.Address{addr}, nil).Logs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		have    []*types.Log
+		cursors []hexutil.Bytes
+		cursor  *hexutil.Bytes
+	)
+	for {
+		page, err := api.GetLogsPage(context.Background(), FilterCriteria{FromBlock: big.NewInt(0), Addresses: []common.Address{addr}}, cursor)
+		if err != nil {
+			t.Fatalf("page %d: %v", len(cursors), err)
+		}
+		if len(page.Logs) > 10 {
+			t.Fatalf("page %d: too many logs: %d", len(cursors), len(page.Logs))
+		}
+		have = append(have, page.Logs...)
+		if len(page.Cursor) == 0 {
+			break
+		}
+		cursors = append(cursors, page.Cursor)
+		cursor = &page.Cursor
+	}
+	haveJSON, _ := json.Marshal(have)
+	wantJSON, _ := json.Marshal(want)
+	if len(want) != len(chain) || string(haveJSON) != string(wantJSON) {
+		t.Fatalf("paginated logs mismatch: have %d logs, want %d", len(have), len(want))
+	}
+	// A cursor pointing into a reorged block is rejected
+	pos, err := decodeLogPosition(cursors[0])
+	if err != nil || pos.hash == (common.Hash{}) {
+		t.Fatalf("unexpected first cursor: %+v, %v", pos, err)
+	}
+	rawdb.WriteCanonicalHash(db, common.Hash{0x01}, pos.number)
+	if _, err := api.GetLogsPage(context.Background(), FilterCriteria{FromBlock: big.NewInt(0)}, &cursors[0]); err != errCursorReorged {
+		t.Fatalf("wrong error: have %v, want %v", err, errCursorReorged)
+	}
+	invalid := hexutil.Bytes{0x01}
+	if _, err := api.GetLogsPage(context.Background(), FilterCriteria{FromBlock: big.NewInt(0)}, &invalid); err != errInvalidCursor {
+		t.Fatalf("wrong error: have %v, want %v", err, errInvalidCursor)
+	}
 }
@@ -0,0 +1,113 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/log"
+)
+
+// forkOverrideRecheck is the interval at which the fork override file is checked
+// for modifications.
+const forkOverrideRecheck = 10 * time.Second
+
+// forkOverrideWatcher watches a signed fork override file and schedules the forks
+// in it on the live chain whenever the file changes.
+type forkOverrideWatcher struct {
+	path    string
+	signers []common.Address
+	chain   *core.BlockChain
+
+	modtime time.Time // Modification time of the last file processed
+
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newForkOverrideWatcher creates a watcher for the given override file.
+func newForkOverrideWatcher(path string, signers []common.Address, chain *core.BlockChain) *forkOverrideWatcher {
+	return &forkOverrideWatcher{
+		path:    path,
+		signers: signers,
+		chain:   chain,
+		closeCh: make(chan struct{}),
+	}
+}
+
+// start checks the override file and keeps watching it in the background.
+func (w *forkOverrideWatcher) start() {
+	w.check()
+
+	w.wg.Add(1)
+	go w.loop()
+}
+
+// stop terminates the background watcher.
+func (w *forkOverrideWatcher) stop() {
+	close(w.closeCh)
+	w.wg.Wait()
+}
+
+func (w *forkOverrideWatcher) loop() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(forkOverrideRecheck)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.check()
+		case <-w.closeCh:
+			return
+		}
+	}
+}
+
+// check applies the override file if it was modified since the last check.
+func (w *forkOverrideWatcher) check() {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Warn("Failed to access fork override", "path", w.path, "err", err)
+		}
+		return
+	}
+	if info.ModTime().Equal(w.modtime) {
+		return
+	}
+	w.modtime = info.ModTime()
+
+	override, err := core.LoadForkOverride(w.path)
+	if err != nil {
+		log.Error("Failed to load fork override", "path", w.path, "err", err)
+		return
+	}
+	if active := w.chain.ForkOverride(); active != nil && active.Hash() == override.Hash() {
+		return
+	}
+	if err := w.chain.ApplyForkOverride(override, w.signers); err != nil {
+		log.Error("Rejected fork override", "path", w.path, "err", err)
+	}
+}
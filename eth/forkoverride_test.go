@@ -0,0 +1,89 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/consensus/ethash"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/params"
+)
+
+// Tests that the fork override watcher applies signed override files as they
+// are dropped in or modified, ignoring untrusted ones.
+func TestForkOverrideWatcher(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		other, _ = crypto.GenerateKey()
+		path     = filepath.Join(t.TempDir(), "override.json")
+	)
+	config := *params.AllEthashProtocolChanges
+	config.ShanghaiTime = new(uint64)
+	config.CancunTime, config.PragueTime, config.VerkleTime, config.EOFTime = nil, nil, nil, nil
+
+	gspec := &core.Genesis{Config: &config}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, &core.ChainOverrides{ForkScheduling: true}, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	watcher := newForkOverrideWatcher(path, []common.Address{crypto.PubkeyToAddress(key.PublicKey)}, chain)
+	write := func(signer *ecdsa.PrivateKey, sequence uint64, cancun uint64, modtime time.Time) {
+		override := &core.ForkOverride{ChainID: config.ChainID, Sequence: sequence, Forks: map[string]uint64{"cancun": cancun}}
+		override.Sign(signer)
+		blob, _ := json.Marshal(override)
+		if err := os.WriteFile(path, blob, 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, modtime, modtime)
+	}
+	// Missing files are ignored, untrusted ones rejected
+	watcher.check()
+	write(other, 1, 1000, time.Unix(1, 0))
+	watcher.check()
+	if chain.ForkOverride() != nil || chain.Config().IsCancun(common.Big0, 1000) {
+		t.Fatal("untrusted override applied")
+	}
+	// Trusted overrides are applied once modified
+	write(key, 1, 1000, time.Unix(2, 0))
+	watcher.check()
+	if !chain.Config().IsCancun(common.Big0, 1000) {
+		t.Fatal("trusted override not applied")
+	}
+	write(key, 2, 2000, time.Unix(3, 0))
+	watcher.check()
+	if chain.Config().IsCancun(common.Big0, 1000) || !chain.Config().IsCancun(common.Big0, 2000) {
+		t.Fatal("updated override not applied")
+	}
+	// Older schedules are rejected even if the file is modified
+	write(key, 1, 1000, time.Unix(4, 0))
+	watcher.check()
+	if chain.ForkOverride().Sequence != 2 || chain.Config().IsCancun(common.Big0, 1000) {
+		t.Fatal("replayed override applied")
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/gorievm/go-gori/core/txpool"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/eth/downloader"
+	"github.com/gorievm/go-gori/eth/ethconfig"
 	"github.com/gorievm/go-gori/eth/fetcher"
 	"github.com/gorievm/go-gori/eth/protocols/eth"
 	"github.com/gorievm/go-gori/eth/protocols/snap"
@@ -40,6 +41,7 @@ import (
 	"github.com/gorievm/go-gori/log"
 	"github.com/gorievm/go-gori/metrics"
 	"github.com/gorievm/go-gori/p2p"
+	"github.com/gorievm/go-gori/p2p/enode"
 )
 
 const (
@@ -47,10 +49,12 @@ const (
 	// The number is referenced from the size of tx pool.
 	txChanSize = 4096
 
-	// txMaxBroadcastSize is the max size of a transaction that will be broadcasted.
-	// All transactions with a higher size will be announced and need to be fetched
-	// by the peer.
-	txMaxBroadcastSize = 4096
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+
+	// blockRangeUpdateInterval is the number of blocks the served block range
+	// moves by before it's announced again to eth/69 peers.
+	blockRangeUpdateInterval = 32
 )
 
 var (
@@ -89,9 +93,13 @@ type handlerConfig struct {
 	Merger         *consensus.Merger      // The manager for eth1/2 transition
 	Network        uint64                 // Network identifier to adfvertise
 	Sync           downloader.SyncMode    // Whether to snap or full sync
+	Checkpoint     *downloader.Checkpoint // Trusted block to sync backward from (nil = legacy/beacon sync)
 	BloomCache     uint64                 // Megabytes to alloc for snap sync bloom
 	EventMux       *event.TypeMux         // Legacy event mux, deprecate for `feed`
 	RequiredBlocks map[uint64]common.Hash // Hard coded map of required block hashes for sync challenges
+	Archive        bool                   // Whether the node retains all historical state (advertised to peers)
+
+	TxPropagation *ethconfig.TxPropagationConfig // Transaction propagation policy (nil = defaults)
 }
 
 type handler struct {
@@ -110,14 +118,20 @@ type handler struct {
 	blockFetcher *fetcher.BlockFetcher
 	txFetcher    *fetcher.TxFetcher
 	peers        *peerSet
+	reputation   *peerReputation
 	merger       *consensus.Merger
 
 	eventMux      *event.TypeMux
 	txsCh         chan core.NewTxsEvent
 	txsSub        event.Subscription
 	minedBlockSub *event.TypeMuxSubscription
+	chainHeadCh   chan core.ChainHeadEvent
+	chainHeadSub  event.Subscription
 
 	requiredBlocks map[uint64]common.Hash
+	checkpoint     *downloader.Checkpoint // Trusted sync checkpoint, cleared once reached
+	archive        bool
+	txPropagation  atomic.Pointer[ethconfig.TxPropagationConfig]
 
 	// channels for fetcher, syncer, txsyncLoop
 	quitSync chan struct{}
@@ -143,12 +157,20 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		txpool:         config.TxPool,
 		chain:          config.Chain,
 		peers:          newPeerSet(),
+		reputation:     newPeerReputation(),
 		merger:         config.Merger,
 		requiredBlocks: config.RequiredBlocks,
+		checkpoint:     config.Checkpoint,
+		archive:        config.Archive,
 		quitSync:       make(chan struct{}),
 		handlerDoneCh:  make(chan struct{}),
 		handlerStartCh: make(chan struct{}),
 	}
+	propagation := ethconfig.DefaultTxPropagationConfig
+	if config.TxPropagation != nil {
+		propagation = *config.TxPropagation
+	}
+	h.txPropagation.Store(&propagation)
 	if config.Sync == downloader.FullSync {
 		// The database seems empty as the current block is the genesis. Yet the snap
 		// block is ahead, so snap sync was enabled for this node at a certain point.
@@ -186,7 +208,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		h.acceptTxs.Store(true)
 	}
 	// Construct the downloader (long sync)
-	h.downloader = downloader.New(config.Database, h.eventMux, h.chain, nil, h.removePeer, success)
+	h.downloader = downloader.New(config.Database, h.eventMux, h.chain, nil, h.dropPeer, success)
 	if ttd := h.chain.Config().TerminalTotalDifficulty; ttd != nil {
 		if h.chain.Config().TerminalTotalDifficultyPassed {
 			log.Info("Chain post-merge, sync via beacon client")
@@ -276,7 +298,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		}
 		return n, err
 	}
-	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, h.removePeer)
+	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, h.dropPeer)
 
 	fetchTx := func(peer string, hashes []common.Hash) error {
 		p := h.peers.peer(peer)
@@ -329,6 +351,40 @@ func (h *handler) decHandlers() {
 	h.handlerDoneCh <- struct{}{}
 }
 
+// statusExtensions assembles the optional capabilities advertised to remote
+// peers during the GORI69 status handshake.
+func (h *handler) statusExtensions(head *types.Header) eth.StatusExtensions {
+	var exts eth.StatusExtensions
+	if h.chain.Config().IsCancun(head.Number, head.Time) {
+		exts = append(exts, eth.NewFlagExtension(eth.ExtServesBlobs))
+	}
+	if h.archive {
+		exts = append(exts, eth.NewFlagExtension(eth.ExtArchive))
+	}
+	if override := h.chain.ForkOverride(); override != nil {
+		exts = append(exts, eth.NewForkScheduleExtension(override.Hash()))
+	}
+	return exts
+}
+
+// checkForkSchedule warns if a peer follows a different signed fork override
+// than the local node. Such peers are still accepted, as the schedules might be
+// reconciled before the forks activate, but will be rejected by the fork ID
+// filter once they diverge.
+func (h *handler) checkForkSchedule(peer *eth.Peer) {
+	if peer.Version() != eth.GORI69 {
+		return // Upstream and older peers can't advertise their schedule
+	}
+	var local common.Hash
+	if override := h.chain.ForkOverride(); override != nil {
+		local = override.Hash()
+	}
+	remote, _ := peer.Extensions().ForkSchedule()
+	if local != remote {
+		peer.Log().Warn("Peer follows different fork schedule", "local", local, "remote", remote)
+	}
+}
+
 // runEthPeer registers an eth peer into the joint eth/snap peerset, adds it to
 // various subsystems and starts handling messages.
 func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
@@ -344,6 +400,11 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 		peer.Log().Error("Snapshot extension barrier failed", "err", err)
 		return err
 	}
+	// Refuse peers which exhausted their reputation, unless trusted
+	if !peer.Peer.Info().Network.Trusted && !h.reputation.acceptable(peer.Node().ID()) {
+		peer.Log().Debug("Refusing peer with bad reputation", "score", h.reputation.score(peer.Node().ID()))
+		return p2p.DiscUselessPeer
+	}
 
 	// Execute the Ori handshake
 	var (
@@ -354,7 +415,12 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 		td      = h.chain.GetTd(hash, number)
 	)
 	forkID := forkid.NewID(h.chain.Config(), genesis.Hash(), number, head.Time)
-	if err := peer.Handshake(h.networkID, td, hash, genesis.Hash(), forkID, h.forkFilter); err != nil {
+	served := eth.BlockRangeUpdatePacket{
+		EarliestBlock:   h.chain.HistoryPruningCutoff(),
+		LatestBlock:     number,
+		LatestBlockHash: hash,
+	}
+	if err := peer.Handshake(h.networkID, td, hash, genesis.Hash(), forkID, h.forkFilter, served, h.statusExtensions(head)); err != nil {
 		peer.Log().Debug("Ori handshake failed", "err", err)
 		return err
 	}
@@ -376,6 +442,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 		}
 	}
 	peer.Log().Debug("Ori peer connected", "name", peer.Name())
+	h.checkForkSchedule(peer)
 
 	// Register the peer locally
 	if err := h.peers.registerPeer(peer, snap); err != nil {
@@ -384,6 +451,10 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 	}
 	defer h.unregisterPeer(peer.ID())
 
+	// Apply the propagation policy only after registration, so the peer can't
+	// miss a concurrent policy update
+	peer.SetTxSendRate(h.txPropagation.Load().PeerSendRate)
+
 	p := h.peers.peer(peer.ID())
 	if p == nil {
 		return errors.New("peer dropped during handling")
@@ -444,10 +515,15 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 					return
 				}
 				peer.Log().Debug("Peer required block verified", "number", number, "hash", hash)
+				h.reputation.record(peer.Node().ID(), reputationVerified)
 				res.Done <- nil
 			case <-timeout.C:
-				peer.Log().Warn("Required block challenge timed out, dropping", "addr", peer.RemoteAddr(), "type", peer.Name())
-				h.removePeer(peer.ID())
+				if !h.penalizePeer(peer.ID(), reputationTimeout) {
+					peer.Log().Warn("Required block challenge timed out, dropping", "addr", peer.RemoteAddr(), "type", peer.Name())
+					h.removePeer(peer.ID())
+				} else {
+					peer.Log().Debug("Required block challenge timed out", "addr", peer.RemoteAddr(), "type", peer.Name())
+				}
 			}
 		}(number, hash, req)
 	}
@@ -487,6 +563,24 @@ func (h *handler) removePeer(id string) {
 	}
 }
 
+// penalizePeer records a failure of a peer to serve useful data, returning
+// whether its reputation still allows keeping it connected.
+func (h *handler) penalizePeer(id string, event reputationEvent) bool {
+	enodeID, err := enode.ParseID(id)
+	if err != nil {
+		return true
+	}
+	return h.reputation.record(enodeID, event)
+}
+
+// dropPeer is the drop callback of the sync subsystems, invoked when a peer
+// delivered invalid data. The peer is always disconnected, but the penalty
+// also keeps it from being redialed or accepted until its reputation recovers.
+func (h *handler) dropPeer(id string) {
+	h.penalizePeer(id, reputationInvalid)
+	h.removePeer(id)
+}
+
 // unregisterPeer removes a peer from the downloader, fetchers and main peer set.
 func (h *handler) unregisterPeer(id string) {
 	// Create a custom logger to avoid printing the entire id
@@ -532,6 +626,12 @@ func (h *handler) Start(maxPeers int) {
 	h.minedBlockSub = h.eventMux.Subscribe(core.NewMinedBlockEvent{})
 	go h.minedBroadcastLoop()
 
+	// announce the served block range
+	h.wg.Add(1)
+	h.chainHeadCh = make(chan core.ChainHeadEvent, chainHeadChanSize)
+	h.chainHeadSub = h.chain.SubscribeChainHeadEvent(h.chainHeadCh)
+	go h.blockRangeLoop()
+
 	// start sync handlers
 	h.wg.Add(1)
 	go h.chainSync.loop()
@@ -544,6 +644,7 @@ func (h *handler) Start(maxPeers int) {
 func (h *handler) Stop() {
 	h.txsSub.Unsubscribe()        // quits txBroadcastLoop
 	h.minedBlockSub.Unsubscribe() // quits blockBroadcastLoop
+	h.chainHeadSub.Unsubscribe()  // quits blockRangeLoop
 
 	// Quit chainSync and txsync64.
 	// After this is done, no new peers will be accepted.
@@ -617,14 +718,15 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		txset = make(map[*ethPeer][]common.Hash) // Set peer->hash to transfer directly
 		annos = make(map[*ethPeer][]common.Hash) // Set peer->hash to announce
 
+		policy = h.txPropagation.Load()
 	)
 	// Broadcast transactions to a batch of peers not knowing about it
 	for _, tx := range txs {
 		peers := h.peers.peersWithoutTransaction(tx.Hash())
 
 		var numDirect int
-		if tx.Size() <= txMaxBroadcastSize {
-			numDirect = int(math.Sqrt(float64(len(peers))))
+		if tx.Size() <= policy.MaxBroadcastSize {
+			numDirect = policy.DirectPeers(len(peers))
 		}
 		// Send the tx unconditionally to a subset of our peers
 		for _, peer := range peers[:numDirect] {
@@ -674,3 +776,54 @@ func (h *handler) txBroadcastLoop() {
 		}
 	}
 }
+
+// blockRangeLoop announces the block range the node serves history for to the
+// connected eth/69 peers whenever the chain head moved sufficiently since the
+// last announcement.
+func (h *handler) blockRangeLoop() {
+	defer h.wg.Done()
+
+	var last uint64
+	if head := h.chain.CurrentHeader(); head != nil {
+		last = head.Number.Uint64() // Advertised in the handshake
+	}
+	for {
+		select {
+		case event := <-h.chainHeadCh:
+			number := event.Block.NumberU64()
+			if number < last+blockRangeUpdateInterval && number >= last {
+				continue // Not moved enough yet, unless reorged below the announcement
+			}
+			last = number
+			h.BroadcastBlockRange(h.chain.HistoryPruningCutoff(), number, event.Block.Hash())
+
+		case <-h.chainHeadSub.Err():
+			return
+		}
+	}
+}
+
+// BroadcastBlockRange announces the block range the node serves history for to
+// all the connected peers. Peers running eth/68 and older are skipped.
+func (h *handler) BroadcastBlockRange(earliest, latest uint64, hash common.Hash) {
+	for _, peer := range h.peers.all() {
+		if err := peer.SendBlockRangeUpdate(earliest, latest, hash); err != nil {
+			peer.Log().Debug("Failed to announce block range", "err", err)
+		}
+	}
+}
+
+// TxPropagation returns the transaction propagation policy in effect.
+func (h *handler) TxPropagation() ethconfig.TxPropagationConfig {
+	return *h.txPropagation.Load()
+}
+
+// SetTxPropagation replaces the transaction propagation policy, applying it to
+// the already connected peers too.
+func (h *handler) SetTxPropagation(policy ethconfig.TxPropagationConfig) {
+	h.txPropagation.Store(&policy)
+	for _, peer := range h.peers.all() {
+		peer.SetTxSendRate(policy.PeerSendRate)
+	}
+	log.Info("Updated transaction propagation policy", "peers", policy.BroadcastPeers, "maxsize", policy.MaxBroadcastSize, "rate", policy.PeerSendRate)
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/core/vm"
 	"github.com/gorievm/go-gori/eth/downloader"
+	"github.com/gorievm/go-gori/eth/ethconfig"
 	"github.com/gorievm/go-gori/eth/protocols/eth"
 	"github.com/gorievm/go-gori/event"
 	"github.com/gorievm/go-gori/p2p"
@@ -274,7 +275,7 @@ func testRecvTransactions(t *testing.T, protocol uint) {
 		head    = handler.chain.CurrentBlock()
 		td      = handler.chain.GetTd(head.Hash(), head.Number.Uint64())
 	)
-	if err := src.Handshake(1, td, head.Hash(), genesis.Hash(), forkid.NewIDWithChain(handler.chain), forkid.NewFilter(handler.chain)); err != nil {
+	if err := src.Handshake(1, td, head.Hash(), genesis.Hash(), forkid.NewIDWithChain(handler.chain), forkid.NewFilter(handler.chain), eth.BlockRangeUpdatePacket{LatestBlock: head.Number.Uint64(), LatestBlockHash: head.Hash()}, nil); err != nil {
 		t.Fatalf("failed to run protocol handshake")
 	}
 	// Send the transaction to the sink and verify that it's added to the tx pool
@@ -337,7 +338,7 @@ func testSendTransactions(t *testing.T, protocol uint) {
 		head    = handler.chain.CurrentBlock()
 		td      = handler.chain.GetTd(head.Hash(), head.Number.Uint64())
 	)
-	if err := sink.Handshake(1, td, head.Hash(), genesis.Hash(), forkid.NewIDWithChain(handler.chain), forkid.NewFilter(handler.chain)); err != nil {
+	if err := sink.Handshake(1, td, head.Hash(), genesis.Hash(), forkid.NewIDWithChain(handler.chain), forkid.NewFilter(handler.chain), eth.BlockRangeUpdatePacket{LatestBlock: head.Number.Uint64(), LatestBlockHash: head.Hash()}, nil); err != nil {
 		t.Fatalf("failed to run protocol handshake")
 	}
 	// After the handshake completes, the source handler should stream the sink
@@ -457,6 +458,43 @@ func testTransactionPropagation(t *testing.T, protocol uint) {
 	}
 }
 
+// Tests that the transaction propagation policy can be swapped out at runtime
+// and that the direct broadcast fan-out honours it.
+func TestTransactionPropagationPolicy(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestHandler()
+	defer handler.close()
+
+	if have := handler.handler.TxPropagation(); have != ethconfig.DefaultTxPropagationConfig {
+		t.Fatalf("default policy mismatch: have %+v, want %+v", have, ethconfig.DefaultTxPropagationConfig)
+	}
+	tests := []struct {
+		broadcast uint64
+		peers     int
+		direct    int
+	}{
+		{0, 0, 0},
+		{0, 1, 1},
+		{0, 16, 4},
+		{0, 50, 7},
+		{3, 2, 2},
+		{3, 50, 3},
+		{100, 50, 50},
+	}
+	for i, tt := range tests {
+		policy := ethconfig.TxPropagationConfig{BroadcastPeers: tt.broadcast}
+		if have := policy.DirectPeers(tt.peers); have != tt.direct {
+			t.Errorf("test %d: direct peer count mismatch: have %d, want %d", i, have, tt.direct)
+		}
+	}
+	policy := ethconfig.TxPropagationConfig{BroadcastPeers: 2, MaxBroadcastSize: 0, PeerSendRate: 1024 * 1024}
+	handler.handler.SetTxPropagation(policy)
+	if have := handler.handler.TxPropagation(); have != policy {
+		t.Fatalf("updated policy mismatch: have %+v, want %+v", have, policy)
+	}
+}
+
 // Tests that blocks are broadcast to a sqrt number of peers only.
 func TestBroadcastBlock1Peer(t *testing.T)    { testBroadcastBlock(t, 1, 1) }
 func TestBroadcastBlock2Peers(t *testing.T)   { testBroadcastBlock(t, 2, 1) }
@@ -501,7 +539,7 @@ func testBroadcastBlock(t *testing.T, peers, bcasts int) {
 		go source.handler.runEthPeer(sourcePeer, func(peer *eth.Peer) error {
 			return eth.Handle((*ethHandler)(source.handler), peer)
 		})
-		if err := sinkPeer.Handshake(1, td, genesis.Hash(), genesis.Hash(), forkid.NewIDWithChain(source.chain), forkid.NewFilter(source.chain)); err != nil {
+		if err := sinkPeer.Handshake(1, td, genesis.Hash(), genesis.Hash(), forkid.NewIDWithChain(source.chain), forkid.NewFilter(source.chain), eth.BlockRangeUpdatePacket{LatestBlockHash: genesis.Hash()}, nil); err != nil {
 			t.Fatalf("failed to run protocol handshake")
 		}
 		go eth.Handle(sink, sinkPeer)
@@ -576,7 +614,7 @@ func testBroadcastMalformedBlock(t *testing.T, protocol uint) {
 		genesis = source.chain.Genesis()
 		td      = source.chain.GetTd(genesis.Hash(), genesis.NumberU64())
 	)
-	if err := sink.Handshake(1, td, genesis.Hash(), genesis.Hash(), forkid.NewIDWithChain(source.chain), forkid.NewFilter(source.chain)); err != nil {
+	if err := sink.Handshake(1, td, genesis.Hash(), genesis.Hash(), forkid.NewIDWithChain(source.chain), forkid.NewFilter(source.chain), eth.BlockRangeUpdatePacket{LatestBlockHash: genesis.Hash()}, nil); err != nil {
 		t.Fatalf("failed to run protocol handshake")
 	}
 	// After the handshake completes, the source handler should stream the sink
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"github.com/gorievm/go-gori/common/hexutil"
 	"github.com/gorievm/go-gori/eth/protocols/eth"
 	"github.com/gorievm/go-gori/eth/protocols/snap"
 )
@@ -24,7 +25,9 @@ import (
 // ethPeerInfo represents a short summary of the `eth` sub-protocol metadata known
 // about a connected peer.
 type ethPeerInfo struct {
-	Version uint `json:"version"` // Ori protocol version negotiated
+	Version      uint                     `json:"version"`                // Ori protocol version negotiated
+	Capabilities map[string]hexutil.Bytes `json:"capabilities,omitempty"` // Optional capabilities advertised in the handshake
+	Reputation   int64                    `json:"reputation"`             // Usefulness score of the peer (see peerReputation)
 }
 
 // ethPeer is a wrapper around eth.Peer to maintain a few extra metadata.
@@ -35,9 +38,16 @@ type ethPeer struct {
 
 // info gathers and returns some `eth` protocol metadata known about a peer.
 func (p *ethPeer) info() *ethPeerInfo {
-	return &ethPeerInfo{
+	info := &ethPeerInfo{
 		Version: p.Version(),
 	}
+	if exts := p.Extensions(); len(exts) > 0 {
+		info.Capabilities = make(map[string]hexutil.Bytes, len(exts))
+		for _, ext := range exts {
+			info.Capabilities[ext.Key] = ext.Value
+		}
+	}
+	return info
 }
 
 // snapPeerInfo represents a short summary of the `snap` sub-protocol metadata known
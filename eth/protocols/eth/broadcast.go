@@ -18,6 +18,7 @@ package eth
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/core/types"
@@ -78,8 +79,9 @@ func (p *Peer) broadcastTransactions() {
 				hashesCount uint64
 				txs         []*types.Transaction
 				size        common.StorageSize
+				limit       = p.txSendRate.Load()
 			)
-			for i := 0; i < len(queue) && size < maxTxPacketSize; i++ {
+			for i := 0; i < len(queue) && size < maxTxPacketSize && (limit == 0 || uint64(len(txs)) < limit); i++ {
 				if tx := p.txpool.Get(queue[i]); tx != nil {
 					txs = append(txs, tx.Tx)
 					size += common.StorageSize(tx.Tx.Size())
@@ -96,6 +98,7 @@ func (p *Peer) broadcastTransactions() {
 						fail <- err
 						return
 					}
+					p.paceTxSends(len(txs))
 					close(done)
 					p.Log().Trace("Sent transactions", "count", len(txs))
 				}()
@@ -147,8 +150,9 @@ func (p *Peer) announceTransactions() {
 				pendingTypes []byte
 				pendingSizes []uint32
 				size         common.StorageSize
+				limit        = p.txSendRate.Load()
 			)
-			for count = 0; count < len(queue) && size < maxTxPacketSize; count++ {
+			for count = 0; count < len(queue) && size < maxTxPacketSize && (limit == 0 || uint64(len(pending)) < limit); count++ {
 				if tx := p.txpool.Get(queue[count]); tx != nil {
 					pending = append(pending, queue[count])
 					pendingTypes = append(pendingTypes, tx.Tx.Type())
@@ -174,6 +178,7 @@ func (p *Peer) announceTransactions() {
 							return
 						}
 					}
+					p.paceTxSends(len(pending))
 					close(done)
 					p.Log().Trace("Sent transaction announcements", "count", len(pending))
 				}()
@@ -204,3 +209,20 @@ func (p *Peer) announceTransactions() {
 		}
 	}
 }
+
+// paceTxSends blocks after the given number of transactions (or announcements)
+// were sent, long enough to keep the sends within the configured per-peer rate.
+// It returns early if the peer is closed.
+func (p *Peer) paceTxSends(count int) {
+	limit := p.txSendRate.Load()
+	if limit == 0 {
+		return
+	}
+	timer := time.NewTimer(time.Duration(count) * time.Second / time.Duration(limit))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-p.term:
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"fmt"
+
+	"github.com/gorievm/go-gori/common"
+)
+
+// Known status extension keys. Peers must ignore keys they don't understand, so
+// new optional capabilities can be advertised without a protocol version bump.
+const (
+	ExtServesBlobs  = "blobs"   // Node serves blob transactions and their sidecars
+	ExtArchive      = "archive" // Node retains the full historical state
+	ExtForkSchedule = "forks"   // Hash of the signed fork override in effect
+)
+
+const (
+	// maxStatusExtensions is the maximum number of extensions a remote peer is
+	// allowed to advertise in its status message.
+	maxStatusExtensions = 32
+
+	// maxStatusExtensionKey is the maximum length of an extension key.
+	maxStatusExtensionKey = 32
+
+	// maxStatusExtensionValue is the maximum length of an extension value.
+	maxStatusExtensionValue = 256
+)
+
+// StatusExtension is a single key/value capability advertised in the status
+// handshake. The value encoding is specific to the key, with flag-style
+// capabilities carrying an empty value.
+type StatusExtension struct {
+	Key   string
+	Value []byte
+}
+
+// StatusExtensions is the list of optional capabilities advertised by a peer
+// during the GORI69 status handshake.
+type StatusExtensions []StatusExtension
+
+// Has returns whether the extension with the given key is advertised.
+func (exts StatusExtensions) Has(key string) bool {
+	_, ok := exts.Get(key)
+	return ok
+}
+
+// Get retrieves the value of the extension with the given key.
+func (exts StatusExtensions) Get(key string) ([]byte, bool) {
+	for _, ext := range exts {
+		if ext.Key == key {
+			return ext.Value, true
+		}
+	}
+	return nil, false
+}
+
+// ForkSchedule retrieves the hash of the signed fork override the peer follows.
+// If the extension is missing or malformed, ok is false.
+func (exts StatusExtensions) ForkSchedule() (hash common.Hash, ok bool) {
+	blob, ok := exts.Get(ExtForkSchedule)
+	if !ok || len(blob) != common.HashLength {
+		return common.Hash{}, false
+	}
+	return common.BytesToHash(blob), true
+}
+
+// validate checks that the extensions advertised by a remote peer are within
+// the allowed limits and contain no duplicate keys.
+func (exts StatusExtensions) validate() error {
+	if len(exts) > maxStatusExtensions {
+		return fmt.Errorf("too many extensions: %d > %d", len(exts), maxStatusExtensions)
+	}
+	seen := make(map[string]struct{}, len(exts))
+	for _, ext := range exts {
+		if len(ext.Key) == 0 || len(ext.Key) > maxStatusExtensionKey {
+			return fmt.Errorf("invalid extension key length: %d", len(ext.Key))
+		}
+		if len(ext.Value) > maxStatusExtensionValue {
+			return fmt.Errorf("extension %q too large: %d > %d", ext.Key, len(ext.Value), maxStatusExtensionValue)
+		}
+		if _, ok := seen[ext.Key]; ok {
+			return fmt.Errorf("duplicate extension %q", ext.Key)
+		}
+		seen[ext.Key] = struct{}{}
+	}
+	return nil
+}
+
+// NewFlagExtension creates a value-less status extension advertising a simple
+// capability (e.g. ExtServesBlobs or ExtArchive).
+func NewFlagExtension(key string) StatusExtension {
+	return StatusExtension{Key: key, Value: []byte{}}
+}
+
+// NewForkScheduleExtension creates a status extension advertising the hash of
+// the signed fork override the local node follows.
+func NewForkScheduleExtension(hash common.Hash) StatusExtension {
+	return StatusExtension{Key: ExtForkSchedule, Value: hash.Bytes()}
+}
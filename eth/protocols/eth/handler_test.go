@@ -17,10 +17,12 @@
 package eth
 
 import (
+	"errors"
 	"math"
 	"math/big"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/consensus"
@@ -154,6 +156,7 @@ func (b *testBackend) Handle(*Peer, Packet) error {
 func TestGetBlockHeaders66(t *testing.T) { testGetBlockHeaders(t, ETH66) }
 func TestGetBlockHeaders67(t *testing.T) { testGetBlockHeaders(t, ETH67) }
 func TestGetBlockHeaders68(t *testing.T) { testGetBlockHeaders(t, ETH68) }
+func TestGetBlockHeaders69(t *testing.T) { testGetBlockHeaders(t, ETH69) }
 
 func testGetBlockHeaders(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -341,6 +344,7 @@ func testGetBlockHeaders(t *testing.T, protocol uint) {
 func TestGetBlockBodies66(t *testing.T) { testGetBlockBodies(t, ETH66) }
 func TestGetBlockBodies67(t *testing.T) { testGetBlockBodies(t, ETH67) }
 func TestGetBlockBodies68(t *testing.T) { testGetBlockBodies(t, ETH68) }
+func TestGetBlockBodies69(t *testing.T) { testGetBlockBodies(t, ETH69) }
 
 func testGetBlockBodies(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -437,6 +441,7 @@ func testGetBlockBodies(t *testing.T, protocol uint) {
 func TestGetNodeData66(t *testing.T) { testGetNodeData(t, ETH66, false) }
 func TestGetNodeData67(t *testing.T) { testGetNodeData(t, ETH67, true) }
 func TestGetNodeData68(t *testing.T) { testGetNodeData(t, ETH68, true) }
+func TestGetNodeData69(t *testing.T) { testGetNodeData(t, ETH69, true) }
 
 func testGetNodeData(t *testing.T, protocol uint, drop bool) {
 	t.Parallel()
@@ -555,6 +560,7 @@ func testGetNodeData(t *testing.T, protocol uint, drop bool) {
 func TestGetBlockReceipts66(t *testing.T) { testGetBlockReceipts(t, ETH66) }
 func TestGetBlockReceipts67(t *testing.T) { testGetBlockReceipts(t, ETH67) }
 func TestGetBlockReceipts68(t *testing.T) { testGetBlockReceipts(t, ETH68) }
+func TestGetBlockReceipts69(t *testing.T) { testGetBlockReceipts(t, ETH69) }
 
 func testGetBlockReceipts(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -617,10 +623,58 @@ func testGetBlockReceipts(t *testing.T, protocol uint) {
 		RequestId:         123,
 		GetReceiptsPacket: hashes,
 	})
-	if err := p2p.ExpectMsg(peer.app, ReceiptsMsg, &ReceiptsPacket66{
+	var want interface{} = &ReceiptsPacket66{
 		RequestId:      123,
 		ReceiptsPacket: receipts,
-	}); err != nil {
+	}
+	if protocol >= ETH69 {
+		packet := &ReceiptsPacket69{RequestId: 123}
+		for _, list := range receipts {
+			packet.Receipts = append(packet.Receipts, encodeReceipts69(list))
+		}
+		want = packet
+	}
+	if err := p2p.ExpectMsg(peer.app, ReceiptsMsg, want); err != nil {
 		t.Errorf("receipts mismatch: %v", err)
 	}
 }
+
+// Tests that block range updates sent by eth/69 peers supersede the history
+// range advertised in the handshake, and that malformed ones are rejected.
+func TestBlockRangeUpdate(t *testing.T) {
+	backend := newTestBackend(4)
+	defer backend.close()
+
+	peer, errc := newTestPeer("peer", ETH69, backend)
+	defer peer.close()
+
+	if _, _, ok := peer.HistoryRange(); ok {
+		t.Fatal("history range available before any announcement")
+	}
+	hash := backend.chain.CurrentBlock().Hash()
+	p2p.Send(peer.app, BlockRangeUpdateMsg, &BlockRangeUpdatePacket{EarliestBlock: 1, LatestBlock: 4, LatestBlockHash: hash})
+
+	// Wait for the update to be processed
+	for i := 0; ; i++ {
+		if first, last, ok := peer.HistoryRange(); ok {
+			if first != 1 || last != 4 {
+				t.Fatalf("history range mismatch: have [%d, %d], want [1, 4]", first, last)
+			}
+			break
+		}
+		if i == 100 {
+			t.Fatal("block range update not processed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// An inverted range must disconnect the peer
+	p2p.Send(peer.app, BlockRangeUpdateMsg, &BlockRangeUpdatePacket{EarliestBlock: 5, LatestBlock: 4, LatestBlockHash: hash})
+	select {
+	case err := <-errc:
+		if !errors.Is(err, errInvalidBlockRange) {
+			t.Fatalf("wrong error: have %v, want %v", err, errInvalidBlockRange)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("peer not dropped on invalid block range")
+	}
+}
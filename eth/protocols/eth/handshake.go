@@ -35,25 +35,83 @@ const (
 )
 
 // Handshake executes the eth protocol handshake, negotiating version number,
-// network IDs, difficulties, head and genesis blocks.
-func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter) error {
-	// Send out own handshake in a new thread
-	errc := make(chan error, 2)
-
+// network IDs, difficulties, head and genesis blocks. From eth/69 onwards, the
+// difficulty and head are replaced by the served block range, and on GORI69 the
+// given optional capability extensions are exchanged too.
+func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter, served BlockRangeUpdatePacket, exts StatusExtensions) error {
+	if p.version >= ETH69 {
+		return p.handshake69(network, genesis, forkID, forkFilter, served, exts)
+	}
 	var status StatusPacket // safe to read after two values have been received from errc
 
+	err := p.exchangeStatus(&StatusPacket{
+		ProtocolVersion: uint32(p.version),
+		NetworkID:       network,
+		TD:              td,
+		Head:            head,
+		Genesis:         genesis,
+		ForkID:          forkID,
+	}, func() error {
+		return p.readStatus(network, &status, genesis, forkFilter)
+	})
+	if err != nil {
+		return err
+	}
+	p.td, p.head = status.TD, status.Head
+
+	// TD at mainnet block #7753254 is 76 bits. If it becomes 100 million times
+	// larger, it will still fit within 100 bits
+	if tdlen := p.td.BitLen(); tdlen > 100 {
+		return fmt.Errorf("too large total difficulty: bitlen %d", tdlen)
+	}
+	return nil
+}
+
+// handshake69 executes the eth/69 protocol handshake, exchanging the served block
+// ranges instead of the difficulties and heads.
+func (p *Peer) handshake69(network uint64, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter, served BlockRangeUpdatePacket, exts StatusExtensions) error {
+	if p.version != GORI69 {
+		exts = nil // Extensions are only part of the Gori status
+	}
+	var status StatusPacket69 // safe to read after two values have been received from errc
+
+	err := p.exchangeStatus(&StatusPacket69{
+		ProtocolVersion: uint32(p.version),
+		NetworkID:       network,
+		Genesis:         genesis,
+		ForkID:          forkID,
+		EarliestBlock:   served.EarliestBlock,
+		LatestBlock:     served.LatestBlock,
+		LatestBlockHash: served.LatestBlockHash,
+		Extensions:      exts,
+	}, func() error {
+		return p.readStatus69(network, &status, genesis, forkFilter)
+	})
+	if err != nil {
+		return err
+	}
+	// The total difficulty is not advertised anymore, so it stays unknown until
+	// the peer propagates a block.
+	p.td, p.head = new(big.Int), status.LatestBlockHash
+	p.extensions = status.Extensions
+	p.blockRange = &BlockRangeUpdatePacket{
+		EarliestBlock:   status.EarliestBlock,
+		LatestBlock:     status.LatestBlock,
+		LatestBlockHash: status.LatestBlockHash,
+	}
+	return nil
+}
+
+// exchangeStatus sends the local status message and reads the remote one in
+// parallel, waiting for both to complete or the handshake to time out.
+func (p *Peer) exchangeStatus(status interface{}, read func() error) error {
+	errc := make(chan error, 2)
+
 	go func() {
-		errc <- p2p.Send(p.rw, StatusMsg, &StatusPacket{
-			ProtocolVersion: uint32(p.version),
-			NetworkID:       network,
-			TD:              td,
-			Head:            head,
-			Genesis:         genesis,
-			ForkID:          forkID,
-		})
+		errc <- p2p.Send(p.rw, StatusMsg, status)
 	}()
 	go func() {
-		errc <- p.readStatus(network, &status, genesis, forkFilter)
+		errc <- read()
 	}()
 	timeout := time.NewTimer(handshakeTimeout)
 	defer timeout.Stop()
@@ -69,31 +127,33 @@ func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis
 			return p2p.DiscReadTimeout
 		}
 	}
-	p.td, p.head = status.TD, status.Head
-
-	// TD at mainnet block #7753254 is 76 bits. If it becomes 100 million times
-	// larger, it will still fit within 100 bits
-	if tdlen := p.td.BitLen(); tdlen > 100 {
-		return fmt.Errorf("too large total difficulty: bitlen %d", tdlen)
-	}
 	return nil
 }
 
 // readStatus reads the remote handshake message.
 func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.Hash, forkFilter forkid.Filter) error {
-	msg, err := p.rw.ReadMsg()
-	if err != nil {
+	if err := p.readStatusMsg(status); err != nil {
 		return err
 	}
-	if msg.Code != StatusMsg {
-		return fmt.Errorf("%w: first msg has code %x (!= %x)", errNoStatusMsg, msg.Code, StatusMsg)
+	if status.NetworkID != network {
+		return fmt.Errorf("%w: %d (!= %d)", errNetworkIDMismatch, status.NetworkID, network)
 	}
-	if msg.Size > maxMessageSize {
-		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	if uint(status.ProtocolVersion) != p.version {
+		return fmt.Errorf("%w: %d (!= %d)", errProtocolVersionMismatch, status.ProtocolVersion, p.version)
 	}
-	// Decode the handshake and make sure everything matches
-	if err := msg.Decode(&status); err != nil {
-		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	if status.Genesis != genesis {
+		return fmt.Errorf("%w: %x (!= %x)", errGenesisMismatch, status.Genesis, genesis)
+	}
+	if err := forkFilter(status.ForkID); err != nil {
+		return fmt.Errorf("%w: %v", errForkIDRejected, err)
+	}
+	return nil
+}
+
+// readStatus69 reads the remote eth/69 handshake message.
+func (p *Peer) readStatus69(network uint64, status *StatusPacket69, genesis common.Hash, forkFilter forkid.Filter) error {
+	if err := p.readStatusMsg(status); err != nil {
+		return err
 	}
 	if status.NetworkID != network {
 		return fmt.Errorf("%w: %d (!= %d)", errNetworkIDMismatch, status.NetworkID, network)
@@ -107,6 +167,38 @@ func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.H
 	if err := forkFilter(status.ForkID); err != nil {
 		return fmt.Errorf("%w: %v", errForkIDRejected, err)
 	}
+	served := BlockRangeUpdatePacket{
+		EarliestBlock:   status.EarliestBlock,
+		LatestBlock:     status.LatestBlock,
+		LatestBlockHash: status.LatestBlockHash,
+	}
+	if err := served.validate(); err != nil {
+		return err
+	}
+	if p.version != GORI69 {
+		status.Extensions = nil
+	} else if err := status.Extensions.validate(); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidExtensions, err)
+	}
+	return nil
+}
+
+// readStatusMsg reads the remote handshake message and decodes it into the given
+// version specific status packet.
+func (p *Peer) readStatusMsg(status interface{}) error {
+	msg, err := p.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Code != StatusMsg {
+		return fmt.Errorf("%w: first msg has code %x (!= %x)", errNoStatusMsg, msg.Code, StatusMsg)
+	}
+	if msg.Size > maxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	}
+	if err := msg.Decode(status); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
 	return nil
 }
 
@@ -27,7 +27,9 @@ import (
 )
 
 // Tests that handshake failures are detected and reported correctly.
-func TestHandshake66(t *testing.T) { testHandshake(t, ETH66) }
+func TestHandshake66(t *testing.T)     { testHandshake(t, ETH66) }
+func TestHandshake69(t *testing.T)     { testHandshake(t, ETH69) }
+func TestHandshakeGori69(t *testing.T) { testHandshake(t, GORI69) }
 
 func testHandshake(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -41,32 +43,69 @@ func testHandshake(t *testing.T, protocol uint) {
 		head    = backend.chain.CurrentBlock()
 		td      = backend.chain.GetTd(head.Hash(), head.Number.Uint64())
 		forkID  = forkid.NewID(backend.chain.Config(), backend.chain.Genesis().Hash(), backend.chain.CurrentHeader().Number.Uint64(), backend.chain.CurrentHeader().Time)
+		number  = head.Number.Uint64()
+		served  = BlockRangeUpdatePacket{EarliestBlock: 0, LatestBlock: number, LatestBlockHash: head.Hash()}
 	)
-	tests := []struct {
+	type handshakeTest struct {
 		code uint64
 		data interface{}
 		want error
-	}{
+	}
+	tests := []handshakeTest{
 		{
 			code: TransactionsMsg, data: []interface{}{},
 			want: errNoStatusMsg,
 		},
-		{
-			code: StatusMsg, data: StatusPacket{10, 1, td, head.Hash(), genesis.Hash(), forkID},
-			want: errProtocolVersionMismatch,
-		},
-		{
-			code: StatusMsg, data: StatusPacket{uint32(protocol), 999, td, head.Hash(), genesis.Hash(), forkID},
-			want: errNetworkIDMismatch,
-		},
-		{
-			code: StatusMsg, data: StatusPacket{uint32(protocol), 1, td, head.Hash(), common.Hash{3}, forkID},
-			want: errGenesisMismatch,
-		},
-		{
-			code: StatusMsg, data: StatusPacket{uint32(protocol), 1, td, head.Hash(), genesis.Hash(), forkid.ID{Hash: [4]byte{0x00, 0x01, 0x02, 0x03}}},
-			want: errForkIDRejected,
-		},
+	}
+	if protocol < ETH69 {
+		tests = append(tests, []handshakeTest{
+			{
+				code: StatusMsg, data: StatusPacket{10, 1, td, head.Hash(), genesis.Hash(), forkID},
+				want: errProtocolVersionMismatch,
+			},
+			{
+				code: StatusMsg, data: StatusPacket{uint32(protocol), 999, td, head.Hash(), genesis.Hash(), forkID},
+				want: errNetworkIDMismatch,
+			},
+			{
+				code: StatusMsg, data: StatusPacket{uint32(protocol), 1, td, head.Hash(), common.Hash{3}, forkID},
+				want: errGenesisMismatch,
+			},
+			{
+				code: StatusMsg, data: StatusPacket{uint32(protocol), 1, td, head.Hash(), genesis.Hash(), forkid.ID{Hash: [4]byte{0x00, 0x01, 0x02, 0x03}}},
+				want: errForkIDRejected,
+			},
+		}...)
+	} else {
+		tests = append(tests, []handshakeTest{
+			{
+				code: StatusMsg, data: StatusPacket69{10, 1, genesis.Hash(), forkID, 0, number, head.Hash(), nil},
+				want: errProtocolVersionMismatch,
+			},
+			{
+				code: StatusMsg, data: StatusPacket69{uint32(protocol), 999, genesis.Hash(), forkID, 0, number, head.Hash(), nil},
+				want: errNetworkIDMismatch,
+			},
+			{
+				code: StatusMsg, data: StatusPacket69{uint32(protocol), 1, common.Hash{3}, forkID, 0, number, head.Hash(), nil},
+				want: errGenesisMismatch,
+			},
+			{
+				code: StatusMsg, data: StatusPacket69{uint32(protocol), 1, genesis.Hash(), forkid.ID{Hash: [4]byte{0x00, 0x01, 0x02, 0x03}}, 0, number, head.Hash(), nil},
+				want: errForkIDRejected,
+			},
+			{
+				code: StatusMsg, data: StatusPacket69{uint32(protocol), 1, genesis.Hash(), forkID, number + 1, number, head.Hash(), nil},
+				want: errInvalidBlockRange,
+			},
+		}...)
+	}
+	if protocol == GORI69 {
+		dup := StatusExtensions{NewFlagExtension(ExtArchive), NewFlagExtension(ExtArchive)}
+		tests = append(tests, handshakeTest{
+			code: StatusMsg, data: StatusPacket69{uint32(protocol), 1, genesis.Hash(), forkID, 0, number, head.Hash(), dup},
+			want: errInvalidExtensions,
+		})
 	}
 	for i, test := range tests {
 		// Create the two peers to shake with each other
@@ -80,7 +119,7 @@ func testHandshake(t *testing.T, protocol uint) {
 		// Send the junk test with one peer, check the handshake failure
 		go p2p.Send(app, test.code, test.data)
 
-		err := peer.Handshake(1, td, head.Hash(), genesis.Hash(), forkID, forkid.NewFilter(backend.chain))
+		err := peer.Handshake(1, td, head.Hash(), genesis.Hash(), forkID, forkid.NewFilter(backend.chain), served, nil)
 		if err == nil {
 			t.Errorf("test %d: protocol returned nil error, want %q", i, test.want)
 		} else if !errors.Is(err, test.want) {
@@ -88,3 +127,70 @@ func testHandshake(t *testing.T, protocol uint) {
 		}
 	}
 }
+
+// Tests that the served block range replaces the head from eth/69 onwards, and
+// that status extensions are only exchanged on GORI69.
+func TestHandshakeExtensions68(t *testing.T)     { testHandshakeExtensions(t, ETH68) }
+func TestHandshakeExtensions69(t *testing.T)     { testHandshakeExtensions(t, ETH69) }
+func TestHandshakeExtensionsGori69(t *testing.T) { testHandshakeExtensions(t, GORI69) }
+
+func testHandshakeExtensions(t *testing.T, protocol uint) {
+	t.Parallel()
+
+	backend := newTestBackend(3)
+	defer backend.close()
+
+	var (
+		genesis = backend.chain.Genesis()
+		head    = backend.chain.CurrentBlock()
+		td      = backend.chain.GetTd(head.Hash(), head.Number.Uint64())
+		forkID  = forkid.NewID(backend.chain.Config(), genesis.Hash(), head.Number.Uint64(), head.Time)
+		filter  = forkid.NewFilter(backend.chain)
+		served  = BlockRangeUpdatePacket{EarliestBlock: 1, LatestBlock: head.Number.Uint64(), LatestBlockHash: head.Hash()}
+		exts    = StatusExtensions{NewFlagExtension(ExtArchive)}
+	)
+	app, net := p2p.MsgPipe()
+	defer app.Close()
+	defer net.Close()
+
+	local := NewPeer(protocol, p2p.NewPeer(enode.ID{1}, "local", nil), app, nil)
+	defer local.Close()
+	remote := NewPeer(protocol, p2p.NewPeer(enode.ID{2}, "remote", nil), net, nil)
+	defer remote.Close()
+
+	errc := make(chan error, 2)
+	go func() { errc <- local.Handshake(1, td, head.Hash(), genesis.Hash(), forkID, filter, served, exts) }()
+	go func() { errc <- remote.Handshake(1, td, head.Hash(), genesis.Hash(), forkID, filter, served, nil) }()
+	for i := 0; i < 2; i++ {
+		if err := <-errc; err != nil {
+			t.Fatalf("handshake failed: %v", err)
+		}
+	}
+	if hash, _ := remote.Head(); hash != head.Hash() {
+		t.Errorf("head mismatch: have %x, want %x", hash, head.Hash())
+	}
+	first, last, ok := remote.HistoryRange()
+	if protocol < ETH69 {
+		if ok {
+			t.Errorf("history range exchanged on eth/%d: [%d, %d]", protocol, first, last)
+		}
+	} else if !ok || first != 1 || last != head.Number.Uint64() {
+		t.Errorf("history range mismatch: have [%d, %d] (ok=%v), want [1, %d]", first, last, ok, head.Number.Uint64())
+	}
+	if len(local.Extensions()) != 0 {
+		t.Errorf("unexpected extensions from remote: %v", local.Extensions())
+	}
+	advertised := remote.Extensions()
+	if protocol != GORI69 {
+		if len(advertised) != 0 {
+			t.Fatalf("extensions exchanged on eth/%d: %v", protocol, advertised)
+		}
+		return
+	}
+	if !advertised.Has(ExtArchive) {
+		t.Errorf("archive extension missing")
+	}
+	if advertised.Has(ExtServesBlobs) {
+		t.Errorf("unexpected blobs extension")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"math/big"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/gorievm/go-gori/common"
@@ -72,8 +73,10 @@ type Peer struct {
 	rw        p2p.MsgReadWriter // Input/output streams for snap
 	version   uint              // Protocol version negotiated
 
-	head common.Hash // Latest advertised head block hash
-	td   *big.Int    // Latest advertised head block total difficulty
+	head       common.Hash             // Latest advertised head block hash
+	td         *big.Int                // Latest advertised head block total difficulty
+	extensions StatusExtensions        // Optional capabilities advertised in the handshake
+	blockRange *BlockRangeUpdatePacket // Latest served block range announced, nil before eth/69
 
 	knownBlocks     *knownCache            // Set of block hashes known to be known by this peer
 	queuedBlocks    chan *blockPropagation // Queue of blocks to broadcast to the peer
@@ -83,6 +86,7 @@ type Peer struct {
 	knownTxs    *knownCache        // Set of transaction hashes known to be known by this peer
 	txBroadcast chan []common.Hash // Channel used to queue transaction propagation requests
 	txAnnounce  chan []common.Hash // Channel used to queue transaction announcement requests
+	txSendRate  atomic.Uint64      // Maximum transactions sent or announced per second (0 = unlimited)
 
 	reqDispatch chan *request  // Dispatch channel to send requests and track then until fulfilment
 	reqCancel   chan *cancel   // Dispatch channel to cancel pending requests and untrack them
@@ -138,6 +142,39 @@ func (p *Peer) Version() uint {
 	return p.version
 }
 
+// Extensions retrieves the optional capabilities advertised by the peer during
+// the handshake. The list is empty for peers not running GORI69.
+func (p *Peer) Extensions() StatusExtensions {
+	return p.extensions // Immutable after the handshake, no need for a lock
+}
+
+// HistoryRange retrieves the block range the peer serves bodies and receipts
+// for, as advertised in the eth/69 handshake or the latest block range update
+// since. If the peer did not advertise any range, ok is false.
+func (p *Peer) HistoryRange() (first uint64, last uint64, ok bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.blockRange == nil {
+		return 0, 0, false
+	}
+	return p.blockRange.EarliestBlock, p.blockRange.LatestBlock, true
+}
+
+// setBlockRange updates the block range the peer serves history for.
+func (p *Peer) setBlockRange(update *BlockRangeUpdatePacket) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.blockRange = update
+}
+
+// SetTxSendRate limits the number of transactions sent or announced to the peer
+// per second. Zero lifts the limit.
+func (p *Peer) SetTxSendRate(rate uint64) {
+	p.txSendRate.Store(rate)
+}
+
 // Head retrieves the current head hash and total difficulty of the peer.
 func (p *Peer) Head() (hash common.Hash, td *big.Int) {
 	p.lock.RLock()
@@ -342,6 +379,19 @@ func (p *Peer) ReplyReceiptsRLP(id uint64, receipts []rlp.RawValue) error {
 	})
 }
 
+// SendBlockRangeUpdate announces the block range the local node serves bodies
+// and receipts for. It is a noop for peers running eth/68 and older.
+func (p *Peer) SendBlockRangeUpdate(earliest, latest uint64, hash common.Hash) error {
+	if p.version < ETH69 {
+		return nil
+	}
+	return p2p.Send(p.rw, BlockRangeUpdateMsg, &BlockRangeUpdatePacket{
+		EarliestBlock:   earliest,
+		LatestBlock:     latest,
+		LatestBlockHash: hash,
+	})
+}
+
 // RequestOneHeader is a wrapper around the header query functions to fetch a
 // single header. It is used solely by the fetcher.
 func (p *Peer) RequestOneHeader(hash common.Hash, sink chan *Response) (*Request, error) {
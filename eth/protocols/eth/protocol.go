@@ -33,6 +33,12 @@ const (
 	ETH66 = 66
 	ETH67 = 67
 	ETH68 = 68
+	ETH69 = 69
+
+	// GORI69 is eth/69 with the Gori capability extensions appended to the status
+	// message. Upstream clients don't know about it and negotiate plain ETH69, so
+	// the number only needs to be unique and above ETH69 for the version checks.
+	GORI69 = 1069
 )
 
 // ProtocolName is the official short name of the `eth` protocol used during
@@ -41,11 +47,11 @@ const ProtocolName = "eth"
 
 // ProtocolVersions are the supported versions of the `eth` protocol (first
 // is primary).
-var ProtocolVersions = []uint{ETH68, ETH67, ETH66}
+var ProtocolVersions = []uint{GORI69, ETH69, ETH68, ETH67, ETH66}
 
 // protocolLengths are the number of implemented message corresponding to
 // different protocol versions.
-var protocolLengths = map[uint]uint64{ETH68: 17, ETH67: 17, ETH66: 17}
+var protocolLengths = map[uint]uint64{GORI69: 18, ETH69: 18, ETH68: 17, ETH67: 17, ETH66: 17}
 
 // maxMessageSize is the maximum cap on the size of a protocol message.
 const maxMessageSize = 10 * 1024 * 1024
@@ -66,6 +72,7 @@ const (
 	NodeDataMsg                   = 0x0e
 	GetReceiptsMsg                = 0x0f
 	ReceiptsMsg                   = 0x10
+	BlockRangeUpdateMsg           = 0x11
 )
 
 var (
@@ -77,6 +84,8 @@ var (
 	errNetworkIDMismatch       = errors.New("network ID mismatch")
 	errGenesisMismatch         = errors.New("genesis mismatch")
 	errForkIDRejected          = errors.New("fork ID rejected")
+	errInvalidExtensions       = errors.New("invalid status extensions")
+	errInvalidBlockRange       = errors.New("invalid block range")
 )
 
 // Packet represents a p2p message in the `eth` protocol.
@@ -95,6 +104,19 @@ type StatusPacket struct {
 	ForkID          forkid.ID
 }
 
+// StatusPacket69 is the network packet for the status message for eth/69 and
+// later, replacing the total difficulty and head with the served block range.
+type StatusPacket69 struct {
+	ProtocolVersion uint32
+	NetworkID       uint64
+	Genesis         common.Hash
+	ForkID          forkid.ID
+	EarliestBlock   uint64           // First block the node serves history for
+	LatestBlock     uint64           // Last block the node serves history for (current head)
+	LatestBlockHash common.Hash      // Hash of the last block
+	Extensions      StatusExtensions `rlp:"optional"` // Optional capabilities, only sent on GORI69
+}
+
 // NewBlockHashesPacket is the network packet for the block announcements.
 type NewBlockHashesPacket []struct {
 	Hash   common.Hash // Hash of one particular block being announced
@@ -293,6 +315,13 @@ type ReceiptsPacket66 struct {
 	ReceiptsPacket
 }
 
+// ReceiptsPacket69 is the network packet for block receipts distribution over
+// eth/69, carrying the receipts in their bloom-less network encoding.
+type ReceiptsPacket69 struct {
+	RequestId uint64
+	Receipts  [][]*Receipt69
+}
+
 // ReceiptsRLPPacket is used for receipts, when we already have it encoded
 type ReceiptsRLPPacket []rlp.RawValue
 
@@ -312,6 +341,26 @@ type NewPooledTransactionHashesPacket68 struct {
 	Hashes []common.Hash
 }
 
+// BlockRangeUpdatePacket is the network packet announcing the block range a node
+// serves bodies and receipts for, sent on eth/69 and later whenever the range
+// moves. It supersedes the history range advertised in the status handshake.
+type BlockRangeUpdatePacket struct {
+	EarliestBlock   uint64      // First block the node serves history for
+	LatestBlock     uint64      // Last block the node serves history for (current head)
+	LatestBlockHash common.Hash // Hash of the last block
+}
+
+// validate checks that the announced block range is well formed.
+func (p *BlockRangeUpdatePacket) validate() error {
+	if p.EarliestBlock > p.LatestBlock {
+		return fmt.Errorf("%w: earliest %d > latest %d", errInvalidBlockRange, p.EarliestBlock, p.LatestBlock)
+	}
+	if p.LatestBlockHash == (common.Hash{}) {
+		return fmt.Errorf("%w: missing latest block hash", errInvalidBlockRange)
+	}
+	return nil
+}
+
 // GetPooledTransactionsPacket represents a transaction query.
 type GetPooledTransactionsPacket []common.Hash
 
@@ -385,3 +434,6 @@ func (*GetReceiptsPacket) Kind() byte   { return GetReceiptsMsg }
 
 func (*ReceiptsPacket) Name() string { return "Receipts" }
 func (*ReceiptsPacket) Kind() byte   { return ReceiptsMsg }
+
+func (*BlockRangeUpdatePacket) Name() string { return "BlockRangeUpdate" }
+func (*BlockRangeUpdatePacket) Kind() byte   { return BlockRangeUpdateMsg }
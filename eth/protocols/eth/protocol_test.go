@@ -19,11 +19,14 @@ package eth
 import (
 	"bytes"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/forkid"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/rlp"
+	"github.com/gorievm/go-gori/trie"
 )
 
 // Tests that the custom union field encoder and decoder works correctly.
@@ -264,3 +267,82 @@ func TestEth66Messages(t *testing.T) {
 		}
 	}
 }
+
+// Tests that receipts survive the round trip through the bloom-less eth/69
+// network encoding, with the bloom filters being recomputed on the way back.
+func TestReceipts69EncodeDecode(t *testing.T) {
+	logs := []*types.Log{{
+		Address: common.BytesToAddress([]byte{0x11}),
+		Topics:  []common.Hash{common.HexToHash("dead"), common.HexToHash("beef")},
+		Data:    []byte{0x01, 0x00, 0xff},
+	}}
+	receipts := []*types.Receipt{
+		{Type: types.LegacyTxType, Status: types.ReceiptStatusFailed, CumulativeGasUsed: 1, Logs: logs},
+		{Type: types.DynamicFeeTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 2, Logs: logs},
+		{Type: types.LegacyTxType, PostState: common.HexToHash("0x1234").Bytes(), CumulativeGasUsed: 3},
+	}
+	for _, receipt := range receipts {
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	}
+	blob, err := rlp.EncodeToBytes([][]*Receipt69{encodeReceipts69(receipts)})
+	if err != nil {
+		t.Fatalf("failed to encode receipts: %v", err)
+	}
+	var packet [][]*Receipt69
+	if err := rlp.DecodeBytes(blob, &packet); err != nil {
+		t.Fatalf("failed to decode receipts: %v", err)
+	}
+	decoded, err := decodeReceipts69(packet)
+	if err != nil {
+		t.Fatalf("failed to convert receipts: %v", err)
+	}
+	if have, want := types.DeriveSha(types.Receipts(decoded[0]), trie.NewStackTrie(nil)), types.DeriveSha(types.Receipts(receipts), trie.NewStackTrie(nil)); have != want {
+		t.Fatalf("receipt root mismatch: have %x, want %x", have, want)
+	}
+	// Invalid status encodings must be rejected
+	packet[0][0].PostStateOrStatus = []byte{0x02}
+	if _, err := decodeReceipts69(packet); err == nil {
+		t.Fatal("invalid receipt status accepted")
+	}
+}
+
+// Tests that the eth/69 status message without extensions is encoded exactly as
+// the upstream one, so plain eth/69 peers can decode it.
+func TestStatus69Encoding(t *testing.T) {
+	status := StatusPacket69{
+		ProtocolVersion: ETH69,
+		NetworkID:       1,
+		Genesis:         common.HexToHash("0x01"),
+		ForkID:          forkid.ID{Hash: [4]byte{0xde, 0xad, 0xbe, 0xef}, Next: 2},
+		EarliestBlock:   3,
+		LatestBlock:     4,
+		LatestBlockHash: common.HexToHash("0x05"),
+	}
+	upstream := []interface{}{
+		status.ProtocolVersion, status.NetworkID, status.Genesis, status.ForkID,
+		status.EarliestBlock, status.LatestBlock, status.LatestBlockHash,
+	}
+	have, err := rlp.EncodeToBytes(&status)
+	if err != nil {
+		t.Fatalf("failed to encode status: %v", err)
+	}
+	want, _ := rlp.EncodeToBytes(upstream)
+	if !bytes.Equal(have, want) {
+		t.Fatalf("status encoding mismatch:\nhave %x\nwant %x", have, want)
+	}
+	// The Gori extensions are appended after the upstream fields
+	status.ProtocolVersion = GORI69
+	status.Extensions = StatusExtensions{NewFlagExtension(ExtArchive)}
+
+	blob, err := rlp.EncodeToBytes(&status)
+	if err != nil {
+		t.Fatalf("failed to encode extended status: %v", err)
+	}
+	var decoded StatusPacket69
+	if err := rlp.DecodeBytes(blob, &decoded); err != nil {
+		t.Fatalf("failed to decode extended status: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, status) {
+		t.Fatalf("extended status mismatch: have %+v, want %+v", decoded, status)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+)
+
+var (
+	receiptStatusFailed     = []byte{}
+	receiptStatusSuccessful = []byte{0x01}
+)
+
+// Receipt69 is the network encoding of a receipt on eth/69 and later. Contrary
+// to the consensus encoding, the bloom filter is omitted as it can be derived
+// from the logs, and the transaction type is a plain field instead of a typed
+// envelope.
+type Receipt69 struct {
+	TxType            uint8
+	PostStateOrStatus []byte
+	CumulativeGasUsed uint64
+	Logs              []*types.Log
+}
+
+// newReceipt69 converts a receipt into its eth/69 network encoding.
+func newReceipt69(r *types.Receipt) *Receipt69 {
+	status := r.PostState
+	if len(status) == 0 {
+		status = receiptStatusSuccessful
+		if r.Status == types.ReceiptStatusFailed {
+			status = receiptStatusFailed
+		}
+	}
+	return &Receipt69{
+		TxType:            r.Type,
+		PostStateOrStatus: status,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		Logs:              r.Logs,
+	}
+}
+
+// toReceipt converts the network encoding back into a receipt, recomputing the
+// bloom filter from the logs.
+func (r *Receipt69) toReceipt() (*types.Receipt, error) {
+	receipt := &types.Receipt{
+		Type:              r.TxType,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		Logs:              r.Logs,
+	}
+	switch {
+	case bytes.Equal(r.PostStateOrStatus, receiptStatusSuccessful):
+		receipt.Status = types.ReceiptStatusSuccessful
+	case bytes.Equal(r.PostStateOrStatus, receiptStatusFailed):
+		receipt.Status = types.ReceiptStatusFailed
+	case len(r.PostStateOrStatus) == common.HashLength:
+		receipt.PostState = r.PostStateOrStatus
+	default:
+		return nil, fmt.Errorf("invalid receipt status %x", r.PostStateOrStatus)
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	return receipt, nil
+}
+
+// encodeReceipts69 converts the receipts of a block into their eth/69 network
+// encoding.
+func encodeReceipts69(receipts types.Receipts) []*Receipt69 {
+	list := make([]*Receipt69, len(receipts))
+	for i, receipt := range receipts {
+		list[i] = newReceipt69(receipt)
+	}
+	return list
+}
+
+// decodeReceipts69 converts the eth/69 network encoding of the receipts of
+// multiple blocks back into receipts.
+func decodeReceipts69(packet [][]*Receipt69) (ReceiptsPacket, error) {
+	receipts := make(ReceiptsPacket, len(packet))
+	for i, list := range packet {
+		receipts[i] = make([]*types.Receipt, len(list))
+		for j, r := range list {
+			receipt, err := r.toReceipt()
+			if err != nil {
+				return nil, err
+			}
+			receipts[i][j] = receipt
+		}
+	}
+	return receipts, nil
+}
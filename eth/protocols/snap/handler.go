@@ -23,6 +23,7 @@ import (
 
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/state/snapshot"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/light"
 	"github.com/gorievm/go-gori/log"
@@ -288,7 +289,7 @@ func ServiceGetAccountRangeQuery(chain *core.BlockChain, req *GetAccountRangePac
 	if err != nil {
 		return nil, nil
 	}
-	it, err := chain.Snapshots().AccountIterator(req.Root, req.Origin)
+	it, err := newAccountIterator(chain, req.Root, req.Origin)
 	if err != nil {
 		return nil, nil
 	}
@@ -372,7 +373,7 @@ func ServiceGetStorageRangesQuery(chain *core.BlockChain, req *GetStorageRangesP
 			limit, req.Limit = common.BytesToHash(req.Limit), nil
 		}
 		// Retrieve the requested state and bail out if non existent
-		it, err := chain.Snapshots().StorageIterator(req.Root, account, origin)
+		it, err := newStorageIterator(chain, req.Root, account, origin)
 		if err != nil {
 			return nil, nil
 		}
@@ -495,7 +496,10 @@ func ServiceGetTrieNodesQuery(chain *core.BlockChain, req *GetTrieNodesPacket, s
 		return nil, nil
 	}
 	// The 'snap' might be nil, in which case we cannot serve storage slots.
-	snap := chain.Snapshots().Snapshot(req.Root)
+	var snap snapshot.Snapshot
+	if snaps := chain.Snapshots(); snaps != nil {
+		snap = snaps.Snapshot(req.Root)
+	}
 	// Retrieve trie nodes until the packet size limit is reached
 	var (
 		nodes [][]byte
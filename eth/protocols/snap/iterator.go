@@ -0,0 +1,182 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"errors"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/state/snapshot"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/rlp"
+	"github.com/gorievm/go-gori/trie"
+)
+
+// errNoStateIterator is returned if the requested state can be served neither
+// from the snapshot tree nor from the path-based trie layers.
+var errNoStateIterator = errors.New("state iterator not available")
+
+// newAccountIterator opens an iterator over the accounts of the requested state,
+// starting at the given origin. The snapshot tree is preferred if present, but
+// path-based databases can serve the range straight out of the trie layers so
+// that nodes don't need to maintain a second flat copy of the state.
+func newAccountIterator(chain *core.BlockChain, root common.Hash, origin common.Hash) (snapshot.AccountIterator, error) {
+	if snaps := chain.Snapshots(); snaps != nil {
+		it, err := snaps.AccountIterator(root, origin)
+		if err == nil {
+			return it, nil
+		}
+	}
+	triedb := chain.StateCache().TrieDB()
+	if triedb.Scheme() != rawdb.PathScheme {
+		return nil, errNoStateIterator
+	}
+	return newTrieAccountIterator(triedb, root, origin)
+}
+
+// newStorageIterator opens an iterator over the storage slots of the requested
+// account, starting at the given origin. Similarly to accounts, the path-based
+// trie layers are used as a fallback if the snapshot tree cannot serve it.
+func newStorageIterator(chain *core.BlockChain, root common.Hash, account common.Hash, origin common.Hash) (snapshot.StorageIterator, error) {
+	if snaps := chain.Snapshots(); snaps != nil {
+		it, err := snaps.StorageIterator(root, account, origin)
+		if err == nil {
+			return it, nil
+		}
+	}
+	triedb := chain.StateCache().TrieDB()
+	if triedb.Scheme() != rawdb.PathScheme {
+		return nil, errNoStateIterator
+	}
+	return newTrieStorageIterator(triedb, root, account, origin)
+}
+
+// trieAccountIterator is an account iterator backed directly by the account
+// trie, converting the leaves into the slim format used by the snapshots.
+type trieAccountIterator struct {
+	it      *trie.Iterator
+	account []byte
+	err     error
+}
+
+// newTrieAccountIterator creates an account iterator over the account trie of
+// the given state root, positioned before the first account at or after origin.
+func newTrieAccountIterator(triedb *trie.Database, root common.Hash, origin common.Hash) (*trieAccountIterator, error) {
+	tr, err := trie.NewStateTrie(trie.StateTrieID(root), triedb)
+	if err != nil {
+		return nil, err
+	}
+	nodeIt, err := tr.NodeIterator(origin[:])
+	if err != nil {
+		return nil, err
+	}
+	return &trieAccountIterator{it: trie.NewIterator(nodeIt)}, nil
+}
+
+// Next steps the iterator forward one account, returning false if exhausted.
+func (it *trieAccountIterator) Next() bool {
+	if it.err != nil || !it.it.Next() {
+		it.account = nil
+		return false
+	}
+	var account types.StateAccount
+	if err := rlp.DecodeBytes(it.it.Value, &account); err != nil {
+		it.account, it.err = nil, err
+		return false
+	}
+	it.account = types.SlimAccountRLP(account)
+	return true
+}
+
+// Error returns any failure that occurred during iteration.
+func (it *trieAccountIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.it.Err
+}
+
+// Hash returns the hash of the account the iterator is currently at.
+func (it *trieAccountIterator) Hash() common.Hash {
+	return common.BytesToHash(it.it.Key)
+}
+
+// Account returns the RLP encoded slim account the iterator is currently at.
+func (it *trieAccountIterator) Account() []byte {
+	return it.account
+}
+
+// Release is a noop for trie iterators as there are no held resources.
+func (it *trieAccountIterator) Release() {}
+
+// trieStorageIterator is a storage iterator backed directly by the storage
+// trie of a single account.
+type trieStorageIterator struct {
+	it *trie.Iterator
+}
+
+// newTrieStorageIterator creates a storage iterator over the storage trie of the
+// given account, positioned before the first slot at or after origin. Missing
+// accounts are treated as having empty storage, same as the snapshots do.
+func newTrieStorageIterator(triedb *trie.Database, root common.Hash, account common.Hash, origin common.Hash) (*trieStorageIterator, error) {
+	accTrie, err := trie.NewStateTrie(trie.StateTrieID(root), triedb)
+	if err != nil {
+		return nil, err
+	}
+	acc, err := accTrie.GetAccountByHash(account)
+	if err != nil {
+		return nil, err
+	}
+	storageRoot := types.EmptyRootHash
+	if acc != nil {
+		storageRoot = acc.Root
+	}
+	stTrie, err := trie.NewStateTrie(trie.StorageTrieID(root, account, storageRoot), triedb)
+	if err != nil {
+		return nil, err
+	}
+	nodeIt, err := stTrie.NodeIterator(origin[:])
+	if err != nil {
+		return nil, err
+	}
+	return &trieStorageIterator{it: trie.NewIterator(nodeIt)}, nil
+}
+
+// Next steps the iterator forward one storage slot, returning false if exhausted.
+func (it *trieStorageIterator) Next() bool {
+	return it.it.Next()
+}
+
+// Error returns any failure that occurred during iteration.
+func (it *trieStorageIterator) Error() error {
+	return it.it.Err
+}
+
+// Hash returns the hash of the storage slot the iterator is currently at.
+func (it *trieStorageIterator) Hash() common.Hash {
+	return common.BytesToHash(it.it.Key)
+}
+
+// Slot returns the RLP encoded storage slot the iterator is currently at.
+func (it *trieStorageIterator) Slot() []byte {
+	return it.it.Value
+}
+
+// Release is a noop for trie iterators as there are no held resources.
+func (it *trieStorageIterator) Release() {}
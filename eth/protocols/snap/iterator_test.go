@@ -0,0 +1,151 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/rlp"
+	"github.com/gorievm/go-gori/trie"
+	"github.com/gorievm/go-gori/trie/trienode"
+	"golang.org/x/exp/slices"
+)
+
+// makeIteratorTestState creates a small state with storage on every account,
+// returning the database, the state root and the sorted account and storage
+// entries in the format the snap protocol serves them.
+func makeIteratorTestState(t *testing.T, accounts, slots int) (*trie.Database, common.Hash, []*kv, map[common.Hash][]*kv) {
+	var (
+		db       = trie.NewDatabase(rawdb.NewMemoryDatabase())
+		accTrie  = trie.NewEmpty(db)
+		nodes    = trienode.NewMergedNodeSet()
+		entries  []*kv
+		storages = make(map[common.Hash][]*kv)
+	)
+	for i := uint64(1); i <= uint64(accounts); i++ {
+		key := key32(i)
+		stRoot, stNodes, stEntries := makeStorageTrieWithSeed(common.BytesToHash(key), uint64(slots), i, db)
+		nodes.Merge(stNodes)
+
+		account := types.StateAccount{
+			Nonce:    i,
+			Balance:  big.NewInt(int64(i)),
+			Root:     stRoot,
+			CodeHash: types.EmptyCodeHash.Bytes(),
+		}
+		value, _ := rlp.EncodeToBytes(&account)
+		accTrie.MustUpdate(key, value)
+
+		entries = append(entries, &kv{key, types.SlimAccountRLP(account)})
+		storages[common.BytesToHash(key)] = stEntries
+	}
+	slices.SortFunc(entries, (*kv).cmp)
+
+	root, set, _ := accTrie.Commit(true)
+	nodes.Merge(set)
+	if err := db.Update(root, types.EmptyRootHash, 0, nodes, nil); err != nil {
+		t.Fatalf("failed to update trie database: %v", err)
+	}
+	return db, root, entries, storages
+}
+
+// Tests that the trie backed account iterator yields the same slim accounts in
+// the same order as the snapshots would, honouring the requested origin.
+func TestTrieAccountIterator(t *testing.T) {
+	db, root, entries, _ := makeIteratorTestState(t, 100, 5)
+
+	for _, start := range []int{0, 1, 50, 99} {
+		it, err := newTrieAccountIterator(db, root, common.BytesToHash(entries[start].k))
+		if err != nil {
+			t.Fatalf("start %d: failed to create iterator: %v", start, err)
+		}
+		var index = start
+		for it.Next() {
+			if index >= len(entries) {
+				t.Fatalf("start %d: iterator yielded too many accounts", start)
+			}
+			if it.Hash() != common.BytesToHash(entries[index].k) {
+				t.Fatalf("start %d, item %d: hash mismatch: have %x, want %x", start, index, it.Hash(), entries[index].k)
+			}
+			if !bytes.Equal(it.Account(), entries[index].v) {
+				t.Fatalf("start %d, item %d: account mismatch: have %x, want %x", start, index, it.Account(), entries[index].v)
+			}
+			index++
+		}
+		if err := it.Error(); err != nil {
+			t.Fatalf("start %d: iteration failed: %v", start, err)
+		}
+		if index != len(entries) {
+			t.Fatalf("start %d: iterated account count mismatch: have %d, want %d", start, index-start, len(entries)-start)
+		}
+		it.Release()
+	}
+	// Unknown state roots must be rejected upfront
+	if _, err := newTrieAccountIterator(db, common.Hash{0xff}, common.Hash{}); err == nil {
+		t.Fatal("iterator created for unknown state root")
+	}
+}
+
+// Tests that the trie backed storage iterator yields the slots of the requested
+// account and treats missing accounts as having empty storage.
+func TestTrieStorageIterator(t *testing.T) {
+	db, root, entries, storages := makeIteratorTestState(t, 10, 50)
+
+	account := common.BytesToHash(entries[3].k)
+	slots := storages[account]
+	slices.SortFunc(slots, (*kv).cmp)
+
+	for _, start := range []int{0, 10, 49} {
+		it, err := newTrieStorageIterator(db, root, account, common.BytesToHash(slots[start].k))
+		if err != nil {
+			t.Fatalf("start %d: failed to create iterator: %v", start, err)
+		}
+		var index = start
+		for it.Next() {
+			if index >= len(slots) {
+				t.Fatalf("start %d: iterator yielded too many slots", start)
+			}
+			if it.Hash() != common.BytesToHash(slots[index].k) {
+				t.Fatalf("start %d, item %d: hash mismatch: have %x, want %x", start, index, it.Hash(), slots[index].k)
+			}
+			if !bytes.Equal(it.Slot(), slots[index].v) {
+				t.Fatalf("start %d, item %d: slot mismatch: have %x, want %x", start, index, it.Slot(), slots[index].v)
+			}
+			index++
+		}
+		if err := it.Error(); err != nil {
+			t.Fatalf("start %d: iteration failed: %v", start, err)
+		}
+		if index != len(slots) {
+			t.Fatalf("start %d: iterated slot count mismatch: have %d, want %d", start, index-start, len(slots)-start)
+		}
+		it.Release()
+	}
+	// Missing accounts have no storage to iterate over
+	it, err := newTrieStorageIterator(db, root, common.Hash{0xff}, common.Hash{})
+	if err != nil {
+		t.Fatalf("failed to create iterator for missing account: %v", err)
+	}
+	if it.Next() {
+		t.Fatalf("missing account yielded storage slot %x", it.Hash())
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/trie"
+)
+
+// maxRepairPeers is the maximum number of peers to ask for a single trie node
+// before giving up on repairing it.
+const maxRepairPeers = 3
+
+var (
+	// errNoRepairPeers is returned if there are no snap peers to retrieve a trie
+	// node from.
+	errNoRepairPeers = errors.New("no snap peers available")
+
+	// errRepairFailed is returned if none of the peers asked could deliver the
+	// requested trie node.
+	errRepairFailed = errors.New("trie node not available from peers")
+)
+
+// repairRequest tracks a pending on-demand trie node retrieval.
+type repairRequest struct {
+	peer    string      // Peer to which this request is assigned
+	deliver chan []byte // Channel to deliver the retrieved trie node on
+}
+
+// FetchTrieNode retrieves a single account or storage trie node of the given
+// state root from the connected snap peers, independently of any running sync.
+// It is meant to repair a local database which is missing the node, so the
+// call blocks until a peer delivers the node or all the peers asked failed.
+//
+// The path is in the format used by the snap protocol: the compact encoded node
+// path for account trie nodes, or the account hash followed by the compact node
+// path for storage trie nodes.
+func (s *Syncer) FetchTrieNode(root common.Hash, path trie.SyncPath, hash common.Hash) ([]byte, error) {
+	// Pick the fastest peers to retrieve the node from
+	s.lock.RLock()
+	idlers := &capacitySort{
+		ids:  make([]string, 0, len(s.peers)),
+		caps: make([]int, 0, len(s.peers)),
+	}
+	targetTTL := s.rates.TargetTimeout()
+	for id := range s.peers {
+		idlers.ids = append(idlers.ids, id)
+		idlers.caps = append(idlers.caps, s.rates.Capacity(id, TrieNodesMsg, targetTTL))
+	}
+	s.lock.RUnlock()
+
+	if len(idlers.ids) == 0 {
+		return nil, errNoRepairPeers
+	}
+	sort.Sort(sort.Reverse(idlers))
+	if len(idlers.ids) > maxRepairPeers {
+		idlers.ids = idlers.ids[:maxRepairPeers]
+	}
+	for _, id := range idlers.ids {
+		blob, err := s.fetchTrieNode(id, root, path, hash)
+		if err == nil {
+			return blob, nil
+		}
+		log.Debug("Failed to fetch trie node from peer", "peer", id, "root", root, "hash", hash, "err", err)
+	}
+	return nil, errRepairFailed
+}
+
+// fetchTrieNode retrieves a single trie node from a specific peer.
+func (s *Syncer) fetchTrieNode(id string, root common.Hash, path trie.SyncPath, hash common.Hash) ([]byte, error) {
+	s.lock.Lock()
+	peer, ok := s.peers[id]
+	if !ok {
+		s.lock.Unlock()
+		return nil, errors.New("peer dropped")
+	}
+	// Generate a unique request ID and track the request
+	var reqid uint64
+	for {
+		reqid = uint64(rand.Int63())
+		if reqid == 0 {
+			continue
+		}
+		if _, ok := s.repairReqs[reqid]; ok {
+			continue
+		}
+		if _, ok := s.trienodeHealReqs[reqid]; ok {
+			continue
+		}
+		break
+	}
+	req := &repairRequest{
+		peer:    id,
+		deliver: make(chan []byte, 1),
+	}
+	s.repairReqs[reqid] = req
+	timeout := s.rates.TargetTimeout()
+	s.lock.Unlock()
+
+	defer func() {
+		s.lock.Lock()
+		delete(s.repairReqs, reqid)
+		s.lock.Unlock()
+	}()
+	start := time.Now()
+	if err := peer.RequestTrieNodes(reqid, root, []TrieNodePathSet{TrieNodePathSet(path)}, maxRequestSize); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case blob := <-req.deliver:
+		if len(blob) == 0 {
+			s.rates.Update(id, TrieNodesMsg, time.Since(start), 0)
+			return nil, errors.New("trie node unavailable")
+		}
+		s.rates.Update(id, TrieNodesMsg, time.Since(start), 1)
+		if crypto.Keccak256Hash(blob) != hash {
+			return nil, errors.New("trie node hash mismatch")
+		}
+		return blob, nil
+	case <-timer.C:
+		s.rates.Update(id, TrieNodesMsg, timeout, 0)
+		return nil, errors.New("request timed out")
+	}
+}
+
+// onRepairTrieNodes delivers a response to an on-demand trie node retrieval,
+// returning whether the response belongs to such a request.
+func (s *Syncer) onRepairTrieNodes(peer SyncPeer, id uint64, trienodes [][]byte) bool {
+	s.lock.Lock()
+	req, ok := s.repairReqs[id]
+	if !ok || req.peer != peer.ID() {
+		s.lock.Unlock()
+		return false
+	}
+	delete(s.repairReqs, id)
+	s.lock.Unlock()
+
+	var blob []byte
+	if len(trienodes) > 0 {
+		blob = trienodes[0]
+	}
+	req.deliver <- blob
+	return true
+}
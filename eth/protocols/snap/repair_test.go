@@ -0,0 +1,82 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/trie"
+)
+
+// TestFetchTrieNode tests that single trie nodes can be retrieved on demand
+// from the snap peers, skipping the ones which cannot serve them.
+func TestFetchTrieNode(t *testing.T) {
+	t.Parallel()
+
+	nodeScheme, sourceAccountTrie, elems := makeAccountTrieNoStorage(100)
+
+	// Pick an inner node of the account trie to retrieve
+	it, err := sourceAccountTrie.NodeIterator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		path []byte
+		hash common.Hash
+		blob []byte
+	)
+	for it.Next(true) {
+		if it.Hash() != (common.Hash{}) && len(it.Path()) > 0 && !it.Leaf() {
+			path, hash, blob = common.CopyBytes(it.Path()), it.Hash(), common.CopyBytes(it.NodeBlob())
+			break
+		}
+	}
+	if blob == nil {
+		t.Fatal("no inner trie node found")
+	}
+	mkSource := func(name string, handler trieHandlerFunc) *testPeer {
+		source := newTestPeer(name, t, func() {})
+		source.accountTrie = sourceAccountTrie.Copy()
+		source.accountValues = elems
+		source.trieRequestHandler = handler
+		return source
+	}
+	// Without peers, the node cannot be retrieved
+	syncer := setupSyncer(nodeScheme)
+	if _, err := syncer.FetchTrieNode(sourceAccountTrie.Hash(), trie.NewSyncPath(path), hash); err != errNoRepairPeers {
+		t.Fatalf("error mismatch: have %v, want %v", err, errNoRepairPeers)
+	}
+	// With a stateless peer only, the retrieval should fail
+	syncer = setupSyncer(nodeScheme, mkSource("empty", emptyTrieRequestHandler))
+	if _, err := syncer.FetchTrieNode(sourceAccountTrie.Hash(), trie.NewSyncPath(path), hash); err != errRepairFailed {
+		t.Fatalf("error mismatch: have %v, want %v", err, errRepairFailed)
+	}
+	// With a serving peer, the node should be retrieved
+	syncer = setupSyncer(nodeScheme, mkSource("empty", emptyTrieRequestHandler), mkSource("source", defaultTrieRequestHandler))
+	have, err := syncer.FetchTrieNode(sourceAccountTrie.Hash(), trie.NewSyncPath(path), hash)
+	if err != nil {
+		t.Fatalf("failed to fetch trie node: %v", err)
+	}
+	if !bytes.Equal(have, blob) {
+		t.Fatalf("trie node mismatch: have %x, want %x", have, blob)
+	}
+	if len(syncer.repairReqs) != 0 {
+		t.Fatalf("leftover repair requests: %d", len(syncer.repairReqs))
+	}
+}
@@ -448,6 +448,8 @@ type Syncer struct {
 	trienodeHealReqs map[uint64]*trienodeHealRequest // Trie node requests currently running
 	bytecodeHealReqs map[uint64]*bytecodeHealRequest // Bytecode requests currently running
 
+	repairReqs map[uint64]*repairRequest // On-demand trie node requests for database repair
+
 	trienodeHealRate      float64       // Average heal rate for processing trie node data
 	trienodeHealPend      atomic.Uint64 // Number of trie nodes currently pending for processing
 	trienodeHealThrottle  float64       // Divisor for throttling the amount of trienode heal data requested
@@ -502,6 +504,7 @@ func NewSyncer(db ethdb.KeyValueStore, scheme string) *Syncer {
 		trienodeHealReqs:     make(map[uint64]*trienodeHealRequest),
 		bytecodeHealReqs:     make(map[uint64]*bytecodeHealRequest),
 		trienodeHealThrottle: maxTrienodeHealThrottle, // Tune downward instead of insta-filling with junk
+		repairReqs:           make(map[uint64]*repairRequest),
 		stateWriter:          db.NewBatch(),
 
 		extProgress: new(SyncProgress),
@@ -2693,6 +2696,10 @@ func (s *Syncer) OnStorage(peer SyncPeer, id uint64, hashes [][]common.Hash, slo
 // OnTrieNodes is a callback method to invoke when a batch of trie nodes
 // are received from a remote peer.
 func (s *Syncer) OnTrieNodes(peer SyncPeer, id uint64, trienodes [][]byte) error {
+	// Responses to on-demand database repairs are not part of the sync
+	if s.onRepairTrieNodes(peer, id, trienodes) {
+		return nil
+	}
 	var size common.StorageSize
 	for _, node := range trienodes {
 		size += common.StorageSize(len(node))
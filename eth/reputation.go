@@ -0,0 +1,187 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gorievm/go-gori/common/lru"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/metrics"
+	"github.com/gorievm/go-gori/p2p/enode"
+)
+
+const (
+	// reputationMax and reputationMin bound the score of a peer, so neither a
+	// long history of good service nor of misbehaviour dominates forever.
+	reputationMax = 100
+	reputationMin = -100
+
+	// reputationDropThreshold is the score at or below which a peer gets
+	// disconnected, its inbound connections refused and it's not dialed anymore.
+	reputationDropThreshold = -50
+
+	// reputationHalfLife is the time it takes for a score to decay halfway back
+	// to neutral, allowing peers to redeem themselves eventually.
+	reputationHalfLife = time.Hour
+
+	// reputationCacheSize is the number of peer scores kept in memory. Evicted
+	// ones are reloaded from the node database on demand.
+	reputationCacheSize = 4096
+)
+
+// reputationEvent is an observation about a peer's usefulness, its value being
+// the score adjustment it entails.
+type reputationEvent int64
+
+const (
+	reputationSynced   reputationEvent = 10  // Peer served a successful sync cycle
+	reputationVerified reputationEvent = 5   // Peer passed the required block challenge
+	reputationTimeout  reputationEvent = -20 // Peer failed to answer a request in time
+	reputationInvalid  reputationEvent = -60 // Peer delivered invalid or useless data
+)
+
+var (
+	reputationRewardMeter  = metrics.NewRegisteredMeter("eth/reputation/reward", nil)
+	reputationPenaltyMeter = metrics.NewRegisteredMeter("eth/reputation/penalty", nil)
+	reputationRefuseMeter  = metrics.NewRegisteredMeter("eth/reputation/refuse", nil)
+)
+
+// reputationEntry is the score of a single peer as of its last update.
+type reputationEntry struct {
+	score   float64
+	updated time.Time
+}
+
+// peerReputation tracks how useful the remote peers are, rewarding valid data
+// served and penalizing timeouts and invalid data. Scores decay toward neutral
+// over time and are persisted in the node database, so they survive restarts
+// and reconnections. The scores feed both the decision to drop a connected
+// peer and the filtering of dial candidates.
+type peerReputation struct {
+	db      *enode.DB                                // Node database to persist into, nil until the p2p server runs
+	entries lru.BasicLRU[enode.ID, *reputationEntry] // Recently used peer scores
+	now     func() time.Time                         // Wall clock, swappable in tests
+	lock    sync.Mutex
+}
+
+// newPeerReputation creates an in-memory peer reputation tracker.
+func newPeerReputation() *peerReputation {
+	return &peerReputation{
+		entries: lru.NewBasicLRU[enode.ID, *reputationEntry](reputationCacheSize),
+		now:     time.Now,
+	}
+}
+
+// setDB attaches the node database to persist the scores into, flushing the ones
+// accumulated so far.
+func (r *peerReputation) setDB(db *enode.DB) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.db = db
+	for _, id := range r.entries.Keys() {
+		entry, _ := r.entries.Peek(id)
+		r.persist(id, entry)
+	}
+}
+
+// score returns the current, decayed score of a peer.
+func (r *peerReputation) score(id enode.ID) int64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return int64(math.Round(r.decayed(r.entry(id))))
+}
+
+// record applies an observation to the score of a peer and returns whether the
+// peer is still worth keeping connected.
+func (r *peerReputation) record(id enode.ID, event reputationEvent) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if event > 0 {
+		reputationRewardMeter.Mark(1)
+	} else {
+		reputationPenaltyMeter.Mark(1)
+	}
+	entry := r.entry(id)
+	entry.score = math.Max(reputationMin, math.Min(reputationMax, r.decayed(entry)+float64(event)))
+	entry.updated = r.now()
+	r.persist(id, entry)
+
+	if entry.score <= reputationDropThreshold {
+		log.Debug("Peer reputation exhausted", "id", id, "score", int64(entry.score))
+		return false
+	}
+	return true
+}
+
+// acceptable returns whether a peer's reputation allows connecting to it.
+func (r *peerReputation) acceptable(id enode.ID) bool {
+	if r.score(id) <= reputationDropThreshold {
+		reputationRefuseMeter.Mark(1)
+		return false
+	}
+	return true
+}
+
+// dialable is the dial candidate filter version of acceptable.
+func (r *peerReputation) dialable(node *enode.Node) bool {
+	return r.acceptable(node.ID())
+}
+
+// entry retrieves the tracked score of a peer, loading it from the database if
+// it's not cached. The caller must hold the lock.
+func (r *peerReputation) entry(id enode.ID) *reputationEntry {
+	if entry, ok := r.entries.Get(id); ok {
+		return entry
+	}
+	entry := new(reputationEntry)
+	if r.db != nil {
+		score, updated := r.db.Reputation(id)
+		entry.score, entry.updated = float64(score), updated
+	}
+	r.entries.Add(id, entry)
+	return entry
+}
+
+// decayed returns the score of an entry, decayed toward neutral for the time
+// passed since its last update. The caller must hold the lock.
+func (r *peerReputation) decayed(entry *reputationEntry) float64 {
+	if entry.score == 0 {
+		return 0
+	}
+	elapsed := r.now().Sub(entry.updated)
+	if elapsed <= 0 {
+		return entry.score
+	}
+	return entry.score * math.Exp2(-float64(elapsed)/float64(reputationHalfLife))
+}
+
+// persist writes the score of a peer into the node database, if attached. The
+// caller must hold the lock.
+func (r *peerReputation) persist(id enode.ID, entry *reputationEntry) {
+	if r.db == nil {
+		return
+	}
+	if err := r.db.UpdateReputation(id, int64(math.Round(entry.score)), entry.updated); err != nil {
+		log.Warn("Failed to persist peer reputation", "id", id, "err", err)
+	}
+}
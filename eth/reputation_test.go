@@ -0,0 +1,120 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gorievm/go-gori/p2p/enode"
+)
+
+// Tests that peer scores accumulate, are bounded, and that exhausting the
+// reputation makes a peer unacceptable.
+func TestPeerReputationScoring(t *testing.T) {
+	var (
+		rep = newPeerReputation()
+		now = time.Unix(1700000000, 0)
+		id  = enode.ID{0x01}
+	)
+	rep.now = func() time.Time { return now }
+
+	for i := 0; i < 20; i++ {
+		rep.record(id, reputationSynced)
+	}
+	if score := rep.score(id); score != reputationMax {
+		t.Fatalf("score not capped: have %d, want %d", score, reputationMax)
+	}
+	// A well behaving peer survives a single invalid delivery
+	if !rep.record(id, reputationInvalid) {
+		t.Fatalf("reputable peer exhausted by a single fault")
+	}
+	if score := rep.score(id); score != reputationMax+int64(reputationInvalid) {
+		t.Fatalf("score mismatch: have %d, want %d", score, reputationMax+int64(reputationInvalid))
+	}
+	// An unknown peer doesn't
+	other := enode.ID{0x02}
+	if rep.record(other, reputationInvalid) {
+		t.Fatalf("unknown peer survived invalid delivery")
+	}
+	if rep.acceptable(other) {
+		t.Fatalf("exhausted peer acceptable")
+	}
+	if rep.dialable(enode.SignNull(new(enode.Node).Record(), other)) {
+		t.Fatalf("exhausted peer dialable")
+	}
+	if !rep.acceptable(enode.ID{0x03}) {
+		t.Fatalf("unknown peer not acceptable")
+	}
+}
+
+// Tests that scores decay toward neutral over time, letting exhausted peers
+// redeem themselves.
+func TestPeerReputationDecay(t *testing.T) {
+	var (
+		rep = newPeerReputation()
+		now = time.Unix(1700000000, 0)
+		id  = enode.ID{0x01}
+	)
+	rep.now = func() time.Time { return now }
+
+	rep.record(id, reputationInvalid)
+	if rep.acceptable(id) {
+		t.Fatalf("exhausted peer acceptable")
+	}
+	now = now.Add(reputationHalfLife)
+	if score := rep.score(id); score != int64(reputationInvalid)/2 {
+		t.Fatalf("decayed score mismatch: have %d, want %d", score, int64(reputationInvalid)/2)
+	}
+	if !rep.acceptable(id) {
+		t.Fatalf("decayed peer not acceptable")
+	}
+}
+
+// Tests that scores are persisted into the node database and survive the
+// tracker being recreated.
+func TestPeerReputationPersistence(t *testing.T) {
+	db, err := enode.OpenDB("")
+	if err != nil {
+		t.Fatalf("failed to open node database: %v", err)
+	}
+	defer db.Close()
+
+	var (
+		now = time.Unix(1700000000, 0)
+		id  = enode.ID{0x01}
+		pre = enode.ID{0x02}
+	)
+	rep := newPeerReputation()
+	rep.now = func() time.Time { return now }
+
+	// Scores recorded before the database is attached get flushed into it
+	rep.record(pre, reputationSynced)
+	rep.setDB(db)
+	rep.record(id, reputationTimeout)
+
+	rep = newPeerReputation()
+	rep.now = func() time.Time { return now }
+	rep.setDB(db)
+
+	if score := rep.score(id); score != int64(reputationTimeout) {
+		t.Fatalf("persisted score mismatch: have %d, want %d", score, reputationTimeout)
+	}
+	if score := rep.score(pre); score != int64(reputationSynced) {
+		t.Fatalf("flushed score mismatch: have %d, want %d", score, reputationSynced)
+	}
+}
@@ -30,6 +30,7 @@ import (
 
 const (
 	forceSyncCycle      = 10 * time.Second // Time interval to force syncs, even if few peers are available
+	checkpointSyncCycle = 10 * time.Second // Time interval to check the progress of checkpoint syncs
 	defaultMinSyncPeers = 5                // Amount of peers desired to start syncing
 )
 
@@ -55,6 +56,9 @@ type chainSyncer struct {
 	warned      time.Time
 	peerEventCh chan struct{}
 	doneCh      chan error // non-nil when sync is running
+
+	checkpointCh      chan error // non-nil when the checkpoint sync is being scheduled
+	checkpointStarted bool       // Whether the sync from the trusted checkpoint was scheduled
 }
 
 // chainSyncOp is a scheduled sync operation.
@@ -100,6 +104,11 @@ func (cs *chainSyncer) loop() {
 	cs.force = time.NewTimer(forceSyncCycle)
 	defer cs.force.Stop()
 
+	// The checkpoint ticker tracks the sync from a trusted checkpoint, if one was
+	// configured, until it's reached.
+	checkpoint := time.NewTicker(checkpointSyncCycle)
+	defer checkpoint.Stop()
+
 	for {
 		if op := cs.nextSyncOp(); op != nil {
 			cs.startSync(op)
@@ -107,6 +116,20 @@ func (cs *chainSyncer) loop() {
 		select {
 		case <-cs.peerEventCh:
 			// Peer information changed, recheck.
+			cs.checkpointSync()
+
+		case <-checkpoint.C:
+			cs.checkpointSync()
+
+		case err := <-cs.checkpointCh:
+			cs.checkpointCh = nil
+			if err != nil {
+				log.Debug("Failed to start checkpoint sync", "err", err)
+				break
+			}
+			log.Info("Syncing from trusted checkpoint", "number", cs.handler.checkpoint.Number, "hash", cs.handler.checkpoint.Hash)
+			cs.checkpointStarted = true
+
 		case err := <-cs.doneCh:
 			cs.doneCh = nil
 			cs.force.Reset(forceSyncCycle)
@@ -131,6 +154,9 @@ func (cs *chainSyncer) loop() {
 			if cs.doneCh != nil {
 				<-cs.doneCh
 			}
+			if cs.checkpointCh != nil {
+				<-cs.checkpointCh
+			}
 			return
 		}
 	}
@@ -141,6 +167,11 @@ func (cs *chainSyncer) nextSyncOp() *chainSyncOp {
 	if cs.doneCh != nil {
 		return nil // Sync already running
 	}
+	// If syncing from a trusted checkpoint, suspend the legacy sync until it's
+	// reached
+	if cs.handler.checkpoint != nil {
+		return nil
+	}
 	// If a beacon client once took over control, disable the entire legacy sync
 	// path from here on end. Note, there is a slight "race" between reaching TTD
 	// and the beacon client taking over. The downloader will enforce that nothing
@@ -217,6 +248,30 @@ func (cs *chainSyncer) startSync(op *chainSyncOp) {
 	go func() { cs.doneCh <- cs.handler.doSync(op) }()
 }
 
+// checkpointSync schedules the sync from the trusted checkpoint once a peer is
+// available to retrieve its header from, and tracks whether it has been reached.
+// Re-announcing the same head to a running beacon sync would restart it, so the
+// checkpoint is only scheduled again if that failed. The header retrieval runs
+// in the background not to block peer events.
+func (cs *chainSyncer) checkpointSync() {
+	checkpoint := cs.handler.checkpoint
+	if checkpoint == nil {
+		return
+	}
+	if cs.handler.chain.HasBlockAndState(checkpoint.Hash, checkpoint.Number) {
+		log.Info("Checkpoint sync target reached", "number", checkpoint.Number, "hash", checkpoint.Hash)
+		cs.handler.checkpoint = nil
+		return
+	}
+	if cs.checkpointStarted || cs.checkpointCh != nil || cs.doneCh != nil || cs.handler.peers.len() == 0 {
+		return
+	}
+	mode, _ := cs.modeAndLocalHead()
+
+	cs.checkpointCh = make(chan error, 1)
+	go func() { cs.checkpointCh <- cs.handler.downloader.CheckpointSync(mode, *checkpoint) }()
+}
+
 // doSync synchronizes the local blockchain with a remote peer.
 func (h *handler) doSync(op *chainSyncOp) error {
 	if op.mode == downloader.SnapSync {
@@ -242,6 +297,8 @@ func (h *handler) doSync(op *chainSyncOp) error {
 	if err != nil {
 		return err
 	}
+	h.reputation.record(op.peer.Node().ID(), reputationSynced)
+
 	if h.snapSync.Load() {
 		log.Info("Snap sync complete, auto disabling")
 		h.snapSync.Store(false)
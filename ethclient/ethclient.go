@@ -94,6 +94,32 @@ func (ec *Client) BlockByNumber(ctx context.Context, number *big.Int) (*types.Bl
 	return ec.getBlock(ctx, "eth_getBlockByNumber", toBlockNumArg(number), true)
 }
 
+// BlockByTimestamp returns the last canonical block with a timestamp at or
+// before the given unix time, i.e. the head of the chain at that point in time.
+//
+// ethereum.NotFound is returned if the timestamp predates the genesis block.
+func (ec *Client) BlockByTimestamp(ctx context.Context, timestamp uint64) (*types.Block, error) {
+	return ec.getBlock(ctx, "eth_getBlockByTimestamp", hexutil.Uint64(timestamp), true)
+}
+
+// BlockRangeByTimestamp returns the numbers of the first and last canonical blocks
+// with timestamps within the given unix time range (both ends inclusive).
+//
+// ethereum.NotFound is returned if there are no blocks in the range.
+func (ec *Client) BlockRangeByTimestamp(ctx context.Context, from, to uint64) (uint64, uint64, error) {
+	var result *struct {
+		From hexutil.Uint64 `json:"from"`
+		To   hexutil.Uint64 `json:"to"`
+	}
+	if err := ec.c.CallContext(ctx, &result, "eth_getBlockRangeByTimestamp", hexutil.Uint64(from), hexutil.Uint64(to)); err != nil {
+		return 0, 0, err
+	}
+	if result == nil {
+		return 0, 0, ethereum.NotFound
+	}
+	return uint64(result.From), uint64(result.To), nil
+}
+
 // BlockNumber returns the most recent block number
 func (ec *Client) BlockNumber(ctx context.Context) (uint64, error) {
 	var result hexutil.Uint64
@@ -272,6 +272,9 @@ func TestEthClient(t *testing.T) {
 		"GetBlock": {
 			func(t *testing.T) { testGetBlock(t, client) },
 		},
+		"BlockByTimestamp": {
+			func(t *testing.T) { testBlockByTimestamp(t, chain, client) },
+		},
 		"StatusFunctions": {
 			func(t *testing.T) { testStatusFunctions(t, client) },
 		},
@@ -335,6 +338,28 @@ func testHeader(t *testing.T, chain []*types.Block, client *rpc.Client) {
 	}
 }
 
+func testBlockByTimestamp(t *testing.T, chain []*types.Block, client *rpc.Client) {
+	ec := NewClient(client)
+
+	block, err := ec.BlockByTimestamp(context.Background(), chain[1].Time())
+	if err != nil {
+		t.Fatalf("BlockByTimestamp error: %v", err)
+	}
+	if block.Hash() != chain[1].Hash() {
+		t.Fatalf("BlockByTimestamp mismatch: have %x, want %x", block.Hash(), chain[1].Hash())
+	}
+	if _, err := ec.BlockByTimestamp(context.Background(), chain[0].Time()-1); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("BlockByTimestamp before genesis error = %v, want %v", err, ethereum.NotFound)
+	}
+	from, to, err := ec.BlockRangeByTimestamp(context.Background(), chain[0].Time(), chain[len(chain)-1].Time())
+	if err != nil {
+		t.Fatalf("BlockRangeByTimestamp error: %v", err)
+	}
+	if from != 0 || to != uint64(len(chain)-1) {
+		t.Fatalf("BlockRangeByTimestamp mismatch: have %d-%d, want %d-%d", from, to, 0, len(chain)-1)
+	}
+}
+
 func testBalanceAt(t *testing.T, client *rpc.Client) {
 	tests := map[string]struct {
 		account common.Address
@@ -199,6 +199,40 @@ func (ec *Client) SubscribeFullPendingTransactions(ctx context.Context, ch chan<
 	return ec.c.EthSubscribe(ctx, ch, "newPendingTransactions", true)
 }
 
+// PendingTransactionFilter selects the transactions delivered by a pending
+// transaction subscription. All set conditions need to match.
+type PendingTransactionFilter struct {
+	From      []common.Address // Senders to restrict to (empty = any)
+	To        []common.Address // Recipients to restrict to (empty = any)
+	MinTip    *big.Int         // Minimum effective tip at the current base fee (nil = any)
+	Selectors [][4]byte        // Calldata method selectors to restrict to (empty = any)
+}
+
+// SubscribeFilteredPendingTransactions subscribes to new pending transactions
+// matching the given filter, which is evaluated by the node.
+func (ec *Client) SubscribeFilteredPendingTransactions(ctx context.Context, filter PendingTransactionFilter, ch chan<- *types.Transaction) (*rpc.ClientSubscription, error) {
+	arg := map[string]interface{}{
+		"fullTx": true,
+	}
+	if len(filter.From) > 0 {
+		arg["from"] = filter.From
+	}
+	if len(filter.To) > 0 {
+		arg["to"] = filter.To
+	}
+	if filter.MinTip != nil {
+		arg["minTip"] = (*hexutil.Big)(filter.MinTip)
+	}
+	if len(filter.Selectors) > 0 {
+		selectors := make([]hexutil.Bytes, len(filter.Selectors))
+		for i, selector := range filter.Selectors {
+			selectors[i] = common.CopyBytes(selector[:])
+		}
+		arg["selectors"] = selectors
+	}
+	return ec.c.EthSubscribe(ctx, ch, "newPendingTransactions", arg)
+}
+
 // SubscribePendingTransactions subscribes to new pending transaction hashes.
 func (ec *Client) SubscribePendingTransactions(ctx context.Context, ch chan<- common.Hash) (*rpc.ClientSubscription, error) {
 	return ec.c.EthSubscribe(ctx, ch, "newPendingTransactions")
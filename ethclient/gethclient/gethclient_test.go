@@ -123,6 +123,9 @@ func TestGethClient(t *testing.T) {
 		}, {
 			"TestSubscribePendingTxs",
 			func(t *testing.T) { testSubscribeFullPendingTransactions(t, client) },
+		}, {
+			"TestSubscribeFilteredPendingTxs",
+			func(t *testing.T) { testSubscribeFilteredPendingTransactions(t, client) },
 		}, {
 			"TestCallContract",
 			func(t *testing.T) { testCallContract(t, client) },
@@ -373,6 +376,52 @@ func testSubscribeFullPendingTransactions(t *testing.T, client *rpc.Client) {
 	}
 }
 
+func testSubscribeFilteredPendingTransactions(t *testing.T, client *rpc.Client) {
+	ec := New(client)
+	ethcl := ethclient.NewClient(client)
+	// Subscribe to transactions calling a specific method of a specific contract
+	ch := make(chan *types.Transaction)
+	filter := PendingTransactionFilter{
+		From:      []common.Address{testAddr},
+		To:        []common.Address{{2}},
+		Selectors: [][4]byte{{0xde, 0xad, 0xbe, 0xef}},
+	}
+	sub, err := ec.SubscribeFilteredPendingTransactions(context.Background(), filter, ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	chainID, err := ethcl.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.LatestSignerForChainID(chainID)
+
+	// Send a transaction to the wrong recipient, then one to the right one with
+	// the wrong selector, and finally a matching one
+	var sent []*types.Transaction
+	for i, tx := range []*types.Transaction{
+		types.NewTransaction(2, common.Address{1}, big.NewInt(1), 50000, big.NewInt(1), []byte{0xde, 0xad, 0xbe, 0xef}),
+		types.NewTransaction(3, common.Address{2}, big.NewInt(1), 50000, big.NewInt(1), []byte{0xca, 0xfe, 0xba, 0xbe}),
+		types.NewTransaction(4, common.Address{2}, big.NewInt(1), 50000, big.NewInt(1), []byte{0xde, 0xad, 0xbe, 0xef, 0x01}),
+	} {
+		signedTx, err := types.SignTx(tx, signer, testKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ethcl.SendTransaction(context.Background(), signedTx); err != nil {
+			t.Fatalf("tx %d: failed to send: %v", i, err)
+		}
+		sent = append(sent, signedTx)
+	}
+	// Check that only the matching transaction was delivered
+	tx := <-ch
+	if tx.Hash() != sent[2].Hash() {
+		t.Fatalf("Invalid tx hash received, got %v, want %v", tx.Hash(), sent[2].Hash())
+	}
+}
+
 func testCallContract(t *testing.T, client *rpc.Client) {
 	ec := New(client)
 	msg := ethereum.CallMsg{
@@ -0,0 +1,87 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package router
+
+import (
+	"encoding/binary"
+	"sort"
+	"strconv"
+
+	"github.com/gorievm/go-gori/crypto"
+)
+
+// ringReplicas is the number of virtual points each node is placed at on the
+// hash ring, smoothing out the share of keys each node receives.
+const ringReplicas = 64
+
+// ring is a consistent hash ring over a set of nodes. Adding or removing a node
+// only moves the keys adjacent to its points, keeping the caches of the other
+// nodes warm.
+type ring struct {
+	points []uint64 // Sorted positions of the virtual nodes
+	owners []*node  // Node owning the virtual node at the same index
+	size   int      // Number of distinct nodes on the ring
+}
+
+// newRing places the given nodes on a hash ring.
+func newRing(nodes []*node) *ring {
+	r := &ring{size: len(nodes)}
+	for _, n := range nodes {
+		for i := 0; i < ringReplicas; i++ {
+			r.points = append(r.points, ringHash([]byte(n.url+"#"+strconv.Itoa(i))))
+			r.owners = append(r.owners, n)
+		}
+	}
+	sort.Sort(r)
+	return r
+}
+
+// lookup returns all the nodes on the ring ordered by preference for the given
+// key: the owner of the first point following the key, then the owners of the
+// points after it, skipping nodes already returned.
+func (r *ring) lookup(key []byte) []*node {
+	if r.size == 0 {
+		return nil
+	}
+	var (
+		start = sort.Search(len(r.points), func(i int) bool { return r.points[i] >= ringHash(key) })
+		nodes = make([]*node, 0, r.size)
+		seen  = make(map[*node]struct{}, r.size)
+	)
+	for i := 0; i < len(r.points) && len(nodes) < r.size; i++ {
+		owner := r.owners[(start+i)%len(r.points)]
+		if _, ok := seen[owner]; !ok {
+			seen[owner] = struct{}{}
+			nodes = append(nodes, owner)
+		}
+	}
+	return nodes
+}
+
+// Len, Less and Swap implement sort.Interface, sorting the points along with
+// their owners.
+func (r *ring) Len() int           { return len(r.points) }
+func (r *ring) Less(i, j int) bool { return r.points[i] < r.points[j] }
+func (r *ring) Swap(i, j int) {
+	r.points[i], r.points[j] = r.points[j], r.points[i]
+	r.owners[i], r.owners[j] = r.owners[j], r.owners[i]
+}
+
+// ringHash maps a key to its position on the ring.
+func ringHash(key []byte) uint64 {
+	return binary.BigEndian.Uint64(crypto.Keccak256(key)[:8])
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/gorievm/go-gori/rpc"
+)
+
+// shardSpan is the number of consecutive blocks whose historical queries are
+// routed to the same archive node, keeping its caches warm for the range.
+const shardSpan = 1024
+
+// stateMethods maps the methods accessing the state of a given block to the
+// position of their block parameter. An omitted block defaults to the latest.
+var stateMethods = map[string]int{
+	"eth_getBalance":           1,
+	"eth_getCode":              1,
+	"eth_getTransactionCount":  1,
+	"eth_getStorageAt":         2,
+	"eth_getProof":             2,
+	"eth_call":                 1,
+	"eth_estimateGas":          1,
+	"eth_createAccessList":     1,
+	"debug_traceCall":          1,
+	"debug_traceBlockByNumber": 0,
+	"debug_traceBlockByHash":   0,
+}
+
+// archiveMethods are the methods always routed to archive nodes, as they access
+// the state of a block which can't be determined from their parameters.
+var archiveMethods = map[string]struct{}{
+	"debug_traceTransaction":            {},
+	"debug_traceBlock":                  {},
+	"debug_storageRangeAt":              {},
+	"debug_getModifiedAccountsByHash":   {},
+	"debug_getModifiedAccountsByNumber": {},
+}
+
+// rpcCall is the part of a JSON-RPC request the routing decision is based on.
+type rpcCall struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// route is the routing decision for a request.
+type route struct {
+	archive bool   // Whether the request needs historical state
+	key     []byte // Key selecting the node on the hash ring
+}
+
+// parseCalls decodes a single or batch JSON-RPC request.
+func parseCalls(body []byte) ([]rpcCall, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var calls []rpcCall
+		if err := json.Unmarshal(body, &calls); err != nil {
+			return nil, err
+		}
+		return calls, nil
+	}
+	var call rpcCall
+	if err := json.Unmarshal(body, &call); err != nil {
+		return nil, err
+	}
+	return []rpcCall{call}, nil
+}
+
+// routeCalls decides where to send a request, given the current chain head and
+// the number of recent blocks full nodes retain the state of. Batches needing
+// historical state for any of their calls are routed to archive nodes whole.
+func routeCalls(calls []rpcCall, head uint64, recent uint64) route {
+	var res route
+	for i, call := range calls {
+		r := routeCall(call, head, recent)
+		if i == 0 || (r.archive && !res.archive) {
+			res = r
+		}
+	}
+	return res
+}
+
+// routeCall decides where to send a single call.
+func routeCall(call rpcCall, head uint64, recent uint64) route {
+	if _, ok := archiveMethods[call.Method]; ok {
+		return route{archive: true, key: paramKey(call.Params, 0)}
+	}
+	pos, ok := stateMethods[call.Method]
+	if !ok || pos >= len(call.Params) {
+		// Not accessing the state or omitted block, any full node will do.
+		// Spread the load by the first parameter (account, hash, etc).
+		return route{key: paramKey(call.Params, 0)}
+	}
+	var block rpc.BlockNumberOrHash
+	if err := json.Unmarshal(call.Params[pos], &block); err != nil {
+		// Let a full node report the invalid parameter
+		return route{key: paramKey(call.Params, 0)}
+	}
+	if hash, ok := block.Hash(); ok {
+		// The height of the block is unknown, play it safe
+		return route{archive: true, key: hash.Bytes()}
+	}
+	number, _ := block.Number()
+	if number < rpc.EarliestBlockNumber || uint64(number)+recent >= head {
+		// Tagged or recent block, served by full nodes
+		return route{key: paramKey(call.Params, 0)}
+	}
+	return route{archive: true, key: []byte(strconv.FormatUint(uint64(number)/shardSpan, 10))}
+}
+
+// paramKey returns the parameter at the given position as the ring key, or the
+// empty key if it's missing.
+func paramKey(params []json.RawMessage, pos int) []byte {
+	if pos >= len(params) {
+		return nil
+	}
+	return params[pos]
+}
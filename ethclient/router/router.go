@@ -0,0 +1,318 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package router implements a client side request router for clusters of nodes
+// serving the Ori RPC API over HTTP.
+//
+// Queries against historical state are sent to archive nodes, whereas queries
+// against recent state, the transaction pool or chain data are sent to full
+// nodes. Within each group, requests are distributed by consistent hashing on
+// the block range or the queried item, so that nodes keep serving the same part
+// of the data set and their caches stay warm. Nodes are health checked in the
+// background and unhealthy or lagging ones are skipped until they recover.
+package router
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/rpc"
+)
+
+var (
+	// errNoArchiveNodes is returned if no healthy archive node can serve a
+	// historical query.
+	errNoArchiveNodes = errors.New("no healthy archive node available")
+
+	// errNoNodes is returned if no healthy node can serve a query.
+	errNoNodes = errors.New("no healthy node available")
+)
+
+// healthCheckTimeout is the maximum time a node is given to report its head.
+const healthCheckTimeout = 5 * time.Second
+
+// Config are the configuration parameters of the router. Zero values of the
+// tuning parameters are replaced by their defaults.
+type Config struct {
+	FullNodes    []string // HTTP endpoints of full nodes serving recent state
+	ArchiveNodes []string // HTTP endpoints of archive nodes serving historical state
+
+	Recent              uint64        // Number of recent blocks full nodes retain the state of
+	MaxLag              uint64        // Number of blocks a node may lag behind the best before being skipped
+	HealthCheckInterval time.Duration // Time interval between node health checks
+
+	Transport http.RoundTripper // Transport to reach the nodes with (nil = http.DefaultTransport)
+}
+
+// DefaultConfig contains the default router settings.
+var DefaultConfig = Config{
+	Recent:              128,
+	MaxLag:              8,
+	HealthCheckInterval: 15 * time.Second,
+}
+
+// NodeStatus is the health status of a node as last seen by the router.
+type NodeStatus struct {
+	URL     string
+	Archive bool
+	Healthy bool
+	Head    uint64
+}
+
+// node is a single backend node of the cluster.
+type node struct {
+	url     string
+	archive bool
+	client  *rpc.Client // Client used to health check the node
+
+	healthy atomic.Bool
+	head    atomic.Uint64
+}
+
+// Router distributes JSON-RPC requests across a cluster of nodes. It implements
+// http.RoundTripper, so it can be plugged into any HTTP based RPC client.
+type Router struct {
+	config    Config
+	transport http.RoundTripper
+
+	nodes   []*node
+	full    *ring
+	archive *ring
+	head    atomic.Uint64 // Best head reported by the healthy nodes
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a router over the given nodes, checks their health once and starts
+// the background health checking.
+func New(config Config) (*Router, error) {
+	if len(config.FullNodes) == 0 && len(config.ArchiveNodes) == 0 {
+		return nil, errors.New("no nodes configured")
+	}
+	if config.Recent == 0 {
+		config.Recent = DefaultConfig.Recent
+	}
+	if config.MaxLag == 0 {
+		config.MaxLag = DefaultConfig.MaxLag
+	}
+	if config.HealthCheckInterval == 0 {
+		config.HealthCheckInterval = DefaultConfig.HealthCheckInterval
+	}
+	r := &Router{
+		config:    config,
+		transport: config.Transport,
+		quit:      make(chan struct{}),
+	}
+	if r.transport == nil {
+		r.transport = http.DefaultTransport
+	}
+	var full, archive []*node
+	for _, url := range config.FullNodes {
+		n, err := r.newNode(url, false)
+		if err != nil {
+			return nil, err
+		}
+		full = append(full, n)
+	}
+	for _, url := range config.ArchiveNodes {
+		n, err := r.newNode(url, true)
+		if err != nil {
+			return nil, err
+		}
+		archive = append(archive, n)
+	}
+	r.nodes = append(full, archive...)
+	r.full, r.archive = newRing(full), newRing(archive)
+
+	r.checkHealth()
+
+	r.wg.Add(1)
+	go r.loop()
+	return r, nil
+}
+
+// newNode creates a backend node, connecting a health checking client to it.
+func (r *Router) newNode(url string, archive bool) (*node, error) {
+	client, err := rpc.DialOptions(context.Background(), url, rpc.WithHTTPClient(&http.Client{Transport: r.transport}))
+	if err != nil {
+		return nil, err
+	}
+	return &node{url: url, archive: archive, client: client}, nil
+}
+
+// Close stops the health checking and releases the node connections.
+func (r *Router) Close() {
+	close(r.quit)
+	r.wg.Wait()
+
+	for _, n := range r.nodes {
+		n.client.Close()
+	}
+}
+
+// DialContext creates an RPC client sending all its requests through the router.
+// Subscriptions are not supported, as the nodes are reached over HTTP.
+func (r *Router) DialContext(ctx context.Context) (*rpc.Client, error) {
+	return rpc.DialOptions(ctx, "http://router", rpc.WithHTTPClient(&http.Client{Transport: r}))
+}
+
+// Status returns the health status of all the nodes.
+func (r *Router) Status() []NodeStatus {
+	status := make([]NodeStatus, len(r.nodes))
+	for i, n := range r.nodes {
+		status[i] = NodeStatus{URL: n.url, Archive: n.archive, Healthy: n.healthy.Load(), Head: n.head.Load()}
+	}
+	return status
+}
+
+// RoundTrip implements http.RoundTripper, forwarding a JSON-RPC request to the
+// preferred healthy node for it. If a node can't be reached, the request is
+// retried on the next one and the node is skipped until it's deemed healthy
+// by a later check.
+func (r *Router) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	calls, err := parseCalls(body)
+	if err != nil {
+		return nil, err
+	}
+	dest := routeCalls(calls, r.head.Load(), r.config.Recent)
+
+	// Assemble the candidate nodes in order of preference. Archive nodes can
+	// serve recent state too, so they are the fallback for full nodes.
+	candidates := r.archive.lookup(dest.key)
+	if !dest.archive {
+		candidates = append(r.full.lookup(dest.key), candidates...)
+	}
+	for _, n := range candidates {
+		if !n.healthy.Load() {
+			continue
+		}
+		res, err := r.forward(req, n, body)
+		if err == nil {
+			return res, nil
+		}
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+		log.Debug("RPC node unreachable", "url", n.url, "err", err)
+		n.healthy.Store(false)
+	}
+	if dest.archive {
+		return nil, errNoArchiveNodes
+	}
+	return nil, errNoNodes
+}
+
+// forward sends the request body to the given node.
+func (r *Router) forward(req *http.Request, n *node, body []byte) (*http.Response, error) {
+	fwd, err := http.NewRequestWithContext(req.Context(), req.Method, n.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	fwd.Header = req.Header.Clone()
+	fwd.ContentLength = int64(len(body))
+
+	res, err := r.transport.RoundTrip(fwd)
+	if err != nil {
+		return nil, err
+	}
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		res.Body.Close()
+		return nil, errors.New(res.Status)
+	}
+	return res, nil
+}
+
+// loop periodically checks the health of the nodes until the router is closed.
+func (r *Router) loop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.checkHealth()
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// checkHealth retrieves the head of every node concurrently. Nodes which fail
+// to respond or lag too far behind the best head are marked unhealthy.
+func (r *Router) checkHealth() {
+	var (
+		heads = make([]uint64, len(r.nodes))
+		errs  = make([]error, len(r.nodes))
+		wg    sync.WaitGroup
+	)
+	for i, n := range r.nodes {
+		wg.Add(1)
+		go func(i int, n *node) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+			defer cancel()
+
+			var head hexutil.Uint64
+			errs[i] = n.client.CallContext(ctx, &head, "eth_blockNumber")
+			heads[i] = uint64(head)
+		}(i, n)
+	}
+	wg.Wait()
+
+	var best uint64
+	for i := range r.nodes {
+		if errs[i] == nil && heads[i] > best {
+			best = heads[i]
+		}
+	}
+	r.head.Store(best)
+
+	for i, n := range r.nodes {
+		healthy := errs[i] == nil && heads[i]+r.config.MaxLag >= best
+		if healthy != n.healthy.Load() {
+			if healthy {
+				log.Info("RPC node became healthy", "url", n.url, "head", heads[i])
+			} else {
+				log.Warn("RPC node became unhealthy", "url", n.url, "head", heads[i], "best", best, "err", errs[i])
+			}
+		}
+		n.healthy.Store(healthy)
+		if errs[i] == nil {
+			n.head.Store(heads[i])
+		}
+	}
+}
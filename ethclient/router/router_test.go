@@ -0,0 +1,268 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/ethclient"
+	"github.com/gorievm/go-gori/rpc"
+)
+
+// rpcRequest is a JSON-RPC request received by a test node.
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+// testNode is a fake node answering eth_blockNumber with its head and every
+// other call with its own name, recording the methods it served.
+type testNode struct {
+	*httptest.Server
+	name string
+	head uint64
+
+	lock  sync.Mutex
+	calls []string
+}
+
+func newTestNode(name string, head uint64) *testNode {
+	n := &testNode{name: name, head: head}
+	n.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			reqs  []rpcRequest
+			batch bool
+		)
+		body, _ := io.ReadAll(r.Body)
+		if batch = body[0] == '['; batch {
+			json.Unmarshal(body, &reqs)
+		} else {
+			reqs = make([]rpcRequest, 1)
+			json.Unmarshal(body, &reqs[0])
+		}
+		var results []json.RawMessage
+		for _, req := range reqs {
+			var result interface{} = n.name
+			switch req.Method {
+			case "eth_blockNumber":
+				result = hexutil.Uint64(n.head)
+			case "eth_getBalance":
+				result = (*hexutil.Big)(common.Big1)
+			}
+			if req.Method != "eth_blockNumber" {
+				n.lock.Lock()
+				n.calls = append(n.calls, req.Method)
+				n.lock.Unlock()
+			}
+			blob, _ := json.Marshal(result)
+			results = append(results, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, blob)))
+		}
+		if batch {
+			json.NewEncoder(w).Encode(results)
+		} else {
+			w.Write(results[0])
+		}
+	}))
+	return n
+}
+
+// served returns and resets the methods served by the node.
+func (n *testNode) served() []string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	calls := n.calls
+	n.calls = nil
+	return calls
+}
+
+func TestRouting(t *testing.T) {
+	var (
+		full1   = newTestNode("full1", 1000)
+		full2   = newTestNode("full2", 1000)
+		lagging = newTestNode("lagging", 900)
+		archive = newTestNode("archive", 999)
+	)
+	for _, n := range []*testNode{full1, full2, lagging, archive} {
+		defer n.Close()
+	}
+	router, err := New(Config{
+		FullNodes:    []string{full1.URL, full2.URL, lagging.URL},
+		ArchiveNodes: []string{archive.URL},
+	})
+	if err != nil {
+		t.Fatalf("failed to create router: %v", err)
+	}
+	defer router.Close()
+
+	for _, status := range router.Status() {
+		if healthy := status.URL != lagging.URL; status.Healthy != healthy {
+			t.Errorf("node %s health mismatch: have %v, want %v", status.URL, status.Healthy, healthy)
+		}
+	}
+	c, err := router.DialContext(context.Background())
+	if err != nil {
+		t.Fatalf("failed to dial router: %v", err)
+	}
+	client := ethclient.NewClient(c)
+	defer client.Close()
+
+	// Latest, tagged and recent state queries should be served by full nodes,
+	// historical ones by the archive node
+	tests := []struct {
+		number  *big.Int
+		archive bool
+	}{
+		{number: nil},
+		{number: big.NewInt(int64(rpc.PendingBlockNumber))},
+		{number: big.NewInt(900)},
+		{number: big.NewInt(0), archive: true},
+		{number: big.NewInt(871), archive: true},
+	}
+	for i, tt := range tests {
+		if _, err := client.BalanceAt(context.Background(), common.Address{byte(i)}, tt.number); err != nil {
+			t.Fatalf("test %d: failed to query balance: %v", i, err)
+		}
+		full := len(full1.served()) + len(full2.served())
+		if archived := len(archive.served()); (tt.archive && archived != 1) || (!tt.archive && full != 1) {
+			t.Errorf("test %d: misrouted query: full %d, archive %d, want archive %v", i, full, archived, tt.archive)
+		}
+		if lagged := lagging.served(); len(lagged) != 0 {
+			t.Errorf("test %d: lagging node served %v", i, lagged)
+		}
+	}
+	// Queries for the same item should stick to the same node
+	var (
+		hash   = common.HexToHash("0x01")
+		served string
+	)
+	for i := 0; i < 8; i++ {
+		var name string
+		if err := c.Call(&name, "eth_getTransactionByHash", hash); err != nil {
+			t.Fatalf("failed to query transaction: %v", err)
+		}
+		if served != "" && name != served {
+			t.Fatalf("query %d served by %s, previous by %s", i, name, served)
+		}
+		served = name
+	}
+	// Batches should be routed to the archive node if any call needs it
+	batch := []rpc.BatchElem{
+		{Method: "eth_getBalance", Args: []interface{}{common.Address{}, "latest"}, Result: new(hexutil.Big)},
+		{Method: "eth_getBalance", Args: []interface{}{common.Address{}, "earliest"}, Result: new(hexutil.Big)},
+	}
+	if err := c.BatchCall(batch); err != nil {
+		t.Fatalf("failed to send batch: %v", err)
+	}
+	if calls := archive.served(); len(calls) != len(batch) {
+		t.Errorf("batch not routed to archive node: %v", calls)
+	}
+	full1.served()
+	full2.served()
+
+	// Unreachable nodes should be failed over and skipped
+	byName := map[string]*testNode{"full1": full1, "full2": full2}
+	byName[served].Close()
+	for i := 0; i < 2; i++ {
+		var name string
+		if err := c.Call(&name, "eth_getTransactionByHash", hash); err != nil {
+			t.Fatalf("failed to query transaction after failure: %v", err)
+		}
+		if name == served {
+			t.Fatalf("query served by closed node %s", served)
+		}
+	}
+	for _, status := range router.Status() {
+		if status.URL == byName[served].URL && status.Healthy {
+			t.Errorf("closed node %s still healthy", served)
+		}
+	}
+	// Historical queries should fail without a healthy archive node
+	archive.Close()
+	if _, err := client.BalanceAt(context.Background(), common.Address{}, common.Big0); err == nil {
+		t.Errorf("historical query succeeded without archive node")
+	}
+}
+
+func TestRingStability(t *testing.T) {
+	var nodes []*node
+	for i := 0; i < 4; i++ {
+		nodes = append(nodes, &node{url: "http://node" + strconv.Itoa(i)})
+	}
+	var (
+		before = newRing(nodes)
+		after  = newRing(nodes[:3])
+		owned  = make(map[*node]int)
+	)
+	for i := 0; i < 1000; i++ {
+		key := []byte(strconv.Itoa(i))
+
+		prev, next := before.lookup(key), after.lookup(key)
+		if len(prev) != 4 || len(next) != 3 {
+			t.Fatalf("key %d: lookup length mismatch: have %d and %d, want 4 and 3", i, len(prev), len(next))
+		}
+		owned[prev[0]]++
+
+		// Only the keys of the removed node should move, to their next choice
+		if prev[0] != nodes[3] && prev[0] != next[0] {
+			t.Fatalf("key %d moved from %s to %s", i, prev[0].url, next[0].url)
+		}
+		if prev[0] == nodes[3] && prev[1] != next[0] {
+			t.Fatalf("key %d of removed node moved to %s, want %s", i, next[0].url, prev[1].url)
+		}
+	}
+	for _, n := range nodes {
+		if owned[n] < 100 {
+			t.Errorf("node %s owns only %d keys out of 1000", n.url, owned[n])
+		}
+	}
+}
+
+func TestUnhealthyNodes(t *testing.T) {
+	overloaded := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer overloaded.Close()
+
+	router, err := New(Config{FullNodes: []string{overloaded.URL}})
+	if err != nil {
+		t.Fatalf("failed to create router: %v", err)
+	}
+	defer router.Close()
+
+	if status := router.Status(); status[0].Healthy {
+		t.Fatalf("failing node reported healthy")
+	}
+	c, _ := router.DialContext(context.Background())
+	defer c.Close()
+
+	var head hexutil.Uint64
+	if err := c.Call(&head, "eth_blockNumber"); err == nil {
+		t.Fatalf("query succeeded without healthy nodes")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethdb
+
+import (
+	"fmt"
+	"time"
+)
+
+// SyncPolicy defines when a persistent key-value store forces the written data
+// to stable storage, trading crash safety for write throughput.
+//
+// All policies keep the write-ahead log of the storage engines ordered, so after
+// a crash the database always recovers to a consistent earlier state; the only
+// difference is how far back that state may be. The chain consumers rewind to
+// the recovered head on startup, so a relaxed policy may cost a resync of recent
+// blocks, but never corrupts the chain. The ancient store (freezer) is not
+// affected by the policy and syncs its files on its own.
+type SyncPolicy string
+
+const (
+	// SyncDefault keeps the native behaviour of the storage engine: pebble syncs
+	// every write and leveldb syncs on close.
+	SyncDefault SyncPolicy = ""
+
+	// SyncAlways syncs the write-ahead log on every write before acknowledging
+	// it. Acknowledged writes survive both process and machine crashes.
+	SyncAlways SyncPolicy = "always"
+
+	// SyncInterval acknowledges writes without syncing them and syncs the write-
+	// ahead log periodically. Writes survive process crashes once handed to the
+	// operating system, but a machine crash or power loss can lose the writes of
+	// the last interval.
+	SyncInterval SyncPolicy = "interval"
+
+	// SyncOnClose only syncs the write-ahead log when the database is closed. A
+	// machine crash or power loss can lose any write since the database was
+	// opened, so it is only meant for replaceable nodes that can resync.
+	SyncOnClose SyncPolicy = "onclose"
+)
+
+// WriteConfig are the durability settings of a persistent key-value store.
+type WriteConfig struct {
+	Sync         SyncPolicy    // When to force the written data to stable storage
+	SyncInterval time.Duration // Time between syncs with the interval policy
+
+	// GroupCommit is the time to wait for concurrent synced writes to collect
+	// them into a single commit, sharing one sync between all of them. It only
+	// applies to the always policy and is disabled if zero.
+	GroupCommit time.Duration
+}
+
+// DefaultSyncInterval is the time between syncs with the interval policy if
+// none was configured.
+const DefaultSyncInterval = time.Second
+
+// Validate checks the write configuration for unsupported values.
+func (c *WriteConfig) Validate() error {
+	switch c.Sync {
+	case SyncDefault, SyncAlways, SyncInterval, SyncOnClose:
+	default:
+		return fmt.Errorf("unknown sync policy %q, allowed %q, %q or %q", c.Sync, SyncAlways, SyncInterval, SyncOnClose)
+	}
+	if c.SyncInterval < 0 {
+		return fmt.Errorf("negative sync interval %v", c.SyncInterval)
+	}
+	if c.GroupCommit < 0 {
+		return fmt.Errorf("negative group commit window %v", c.GroupCommit)
+	}
+	return nil
+}
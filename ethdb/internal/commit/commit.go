@@ -0,0 +1,216 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package commit implements the sync policies and group commits shared by the
+// persistent key-value stores.
+package commit
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/metrics"
+)
+
+// maxGroupSize is the maximum number of writes collected into a single group
+// commit, to bound the latency of the first write of the group.
+const maxGroupSize = 128
+
+// errClosed is returned if a write is attempted after the committer was closed.
+var errClosed = errors.New("committer closed")
+
+// WriteFunc performs a write on the storage engine, syncing the write-ahead log
+// before returning if requested.
+type WriteFunc func(sync bool) error
+
+// request is a synced write waiting to be group committed.
+type request struct {
+	write WriteFunc
+	errc  chan error
+}
+
+// Committer applies a sync policy to the writes of a key-value store. Depending
+// on the policy, writes are either synced individually, synced together in group
+// commits, or left unsynced with the committer syncing them in the background or
+// when closed.
+//
+// A nil committer syncs every write.
+type Committer struct {
+	config ethdb.WriteConfig
+	sync   func() error // Forces all previous writes to stable storage
+
+	dirty   atomic.Bool   // Whether unsynced writes were made since the last sync
+	groupCh chan *request // Synced writes waiting to be group committed
+	term    chan struct{} // Termination channel to stop the background loop
+	done    chan struct{} // Channel closed when the background loop terminated
+	once    sync.Once     // Ensures the committer is only closed once
+
+	latencyTimer   metrics.Timer     // Timer measuring the latency of writes, including the sync
+	syncMeter      metrics.Meter     // Meter counting the syncs of the write-ahead log
+	groupHistogram metrics.Histogram // Histogram tracking the number of writes per group commit
+}
+
+// New creates a committer applying the given write configuration, which must
+// already have the storage engine default policy resolved. The sync function is
+// used to force unsynced writes to stable storage. The namespace is the prefix
+// the metrics reporting should use.
+func New(config ethdb.WriteConfig, namespace string, sync func() error) *Committer {
+	if config.Sync == ethdb.SyncInterval && config.SyncInterval == 0 {
+		config.SyncInterval = ethdb.DefaultSyncInterval
+	}
+	c := &Committer{
+		config:         config,
+		sync:           sync,
+		term:           make(chan struct{}),
+		done:           make(chan struct{}),
+		latencyTimer:   metrics.NewRegisteredTimer(namespace+"commit/time", nil),
+		syncMeter:      metrics.NewRegisteredMeter(namespace+"commit/sync", nil),
+		groupHistogram: metrics.NewRegisteredHistogram(namespace+"commit/group", nil, metrics.NewExpDecaySample(1028, 0.015)),
+	}
+	if config.Sync == ethdb.SyncAlways && config.GroupCommit > 0 {
+		c.groupCh = make(chan *request)
+	}
+	go c.loop()
+	return c
+}
+
+// Write performs a write on the storage engine, making it durable according to
+// the sync policy.
+func (c *Committer) Write(write WriteFunc) error {
+	if c == nil {
+		return write(true)
+	}
+	defer c.latencyTimer.UpdateSince(time.Now())
+
+	if c.config.Sync != ethdb.SyncAlways {
+		if err := write(false); err != nil {
+			return err
+		}
+		c.dirty.Store(true)
+		return nil
+	}
+	if c.groupCh == nil {
+		c.syncMeter.Mark(1)
+		return write(true)
+	}
+	req := &request{write: write, errc: make(chan error, 1)}
+	select {
+	case c.groupCh <- req:
+		return <-req.errc
+	case <-c.term:
+		return errClosed
+	}
+}
+
+// Close stops the background syncing and group commits, syncing any writes not
+// yet synced. The caller must ensure no writes are in progress.
+func (c *Committer) Close() error {
+	if c == nil {
+		return nil
+	}
+	c.once.Do(func() { close(c.term) })
+	<-c.done
+
+	return c.flush()
+}
+
+// flush syncs the writes made since the last sync, if any.
+func (c *Committer) flush() error {
+	if !c.dirty.Swap(false) {
+		return nil
+	}
+	if err := c.sync(); err != nil {
+		c.dirty.Store(true)
+		return err
+	}
+	c.syncMeter.Mark(1)
+	return nil
+}
+
+// loop is the background goroutine collecting the group commits and syncing the
+// unsynced writes periodically.
+func (c *Committer) loop() {
+	defer close(c.done)
+
+	var tick <-chan time.Time
+	if c.config.Sync == ethdb.SyncInterval {
+		ticker := time.NewTicker(c.config.SyncInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case req := <-c.groupCh:
+			c.commitGroup(req)
+
+		case <-tick:
+			if err := c.flush(); err != nil {
+				log.Error("Failed to sync database writes", "err", err)
+			}
+
+		case <-c.term:
+			return
+		}
+	}
+}
+
+// commitGroup collects the concurrent synced writes arriving within the group
+// commit window, performs them without syncing and syncs all of them at once.
+// Every write is only acknowledged after the shared sync completed.
+func (c *Committer) commitGroup(first *request) {
+	group := []*request{first}
+
+	timer := time.NewTimer(c.config.GroupCommit)
+	defer timer.Stop()
+collect:
+	for len(group) < maxGroupSize {
+		select {
+		case req := <-c.groupCh:
+			group = append(group, req)
+		case <-timer.C:
+			break collect
+		case <-c.term:
+			break collect
+		}
+	}
+	c.groupHistogram.Update(int64(len(group)))
+
+	var (
+		errs    = make([]error, len(group))
+		written bool
+	)
+	for i, req := range group {
+		if errs[i] = req.write(false); errs[i] == nil {
+			written = true
+		}
+	}
+	var err error
+	if written {
+		if err = c.sync(); err == nil {
+			c.syncMeter.Mark(1)
+		}
+	}
+	for i, req := range group {
+		if errs[i] == nil {
+			errs[i] = err
+		}
+		req.errc <- errs[i]
+	}
+}
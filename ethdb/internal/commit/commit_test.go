@@ -0,0 +1,122 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package commit
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorievm/go-gori/ethdb"
+)
+
+// Tests that concurrent synced writes are collected into a group commit, sharing
+// a single sync, and that they are only acknowledged after the sync.
+func TestGroupCommit(t *testing.T) {
+	var (
+		syncs   atomic.Int32
+		synced  atomic.Int32
+		written atomic.Int32
+	)
+	c := New(ethdb.WriteConfig{Sync: ethdb.SyncAlways, GroupCommit: 100 * time.Millisecond}, "", func() error {
+		syncs.Add(1)
+		synced.Store(written.Load())
+		return nil
+	})
+	defer c.Close()
+
+	var (
+		pend sync.WaitGroup
+		fail = errors.New("write failed")
+	)
+	for i := 0; i < 8; i++ {
+		pend.Add(1)
+		go func(i int) {
+			defer pend.Done()
+
+			err := c.Write(func(sync bool) error {
+				if sync {
+					t.Errorf("write %d: grouped write synced individually", i)
+				}
+				if i == 0 {
+					return fail
+				}
+				written.Add(1)
+				return nil
+			})
+			switch {
+			case i == 0 && err != fail:
+				t.Errorf("write %d: error mismatch: have %v, want %v", i, err, fail)
+			case i != 0 && err != nil:
+				t.Errorf("write %d: failed to write: %v", i, err)
+			case i != 0 && synced.Load() < 1:
+				t.Errorf("write %d: acknowledged before sync", i)
+			}
+		}(i)
+	}
+	pend.Wait()
+
+	if n := syncs.Load(); n != 1 {
+		t.Errorf("sync count mismatch: have %d, want 1", n)
+	}
+	if n := synced.Load(); n != 7 {
+		t.Errorf("synced write count mismatch: have %d, want 7", n)
+	}
+}
+
+// Tests that the relaxed sync policies leave writes unsynced, syncing them in
+// the background or when the committer is closed.
+func TestRelaxedSync(t *testing.T) {
+	tests := []struct {
+		config ethdb.WriteConfig
+		synced bool // Whether the writes should be synced before closing
+	}{
+		{config: ethdb.WriteConfig{Sync: ethdb.SyncInterval, SyncInterval: 10 * time.Millisecond}, synced: true},
+		{config: ethdb.WriteConfig{Sync: ethdb.SyncOnClose}, synced: false},
+	}
+	for _, tt := range tests {
+		var syncs atomic.Int32
+		c := New(tt.config, "", func() error {
+			syncs.Add(1)
+			return nil
+		})
+		for i := 0; i < 3; i++ {
+			if err := c.Write(func(sync bool) error {
+				if sync {
+					t.Errorf("%s: write synced", tt.config.Sync)
+				}
+				return nil
+			}); err != nil {
+				t.Fatalf("%s: failed to write: %v", tt.config.Sync, err)
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+		if have := syncs.Load() > 0; have != tt.synced {
+			t.Errorf("%s: background sync mismatch: have %v, want %v", tt.config.Sync, have, tt.synced)
+		}
+		// Closing must sync outstanding writes but nothing more
+		before := syncs.Load()
+		if err := c.Close(); err != nil {
+			t.Fatalf("%s: failed to close: %v", tt.config.Sync, err)
+		}
+		if want := map[bool]int32{true: before, false: before + 1}[tt.synced]; syncs.Load() != want {
+			t.Errorf("%s: sync count after close mismatch: have %d, want %d", tt.config.Sync, syncs.Load(), want)
+		}
+	}
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/ethdb/internal/commit"
 	"github.com/gorievm/go-gori/log"
 	"github.com/gorievm/go-gori/metrics"
 	"github.com/syndtr/goleveldb/leveldb"
@@ -54,12 +55,18 @@ const (
 	metricsGatheringInterval = 3 * time.Second
 )
 
+// syncMarker is the key deleted with a synced write to force the previous
+// unsynced writes to disk, leveldb having no dedicated sync operation. The key
+// is never written, so deleting it leaves the database contents unchanged.
+var syncMarker = []byte("leveldb-sync-marker")
+
 // Database is a persistent key-value store. Apart from basic data storage
 // functionality it also supports batch writes and iterating over the keyspace in
 // binary-alphabetical order.
 type Database struct {
-	fn string      // filename for reporting
-	db *leveldb.DB // LevelDB instance
+	fn     string            // filename for reporting
+	db     *leveldb.DB       // LevelDB instance
+	commit *commit.Committer // Committer applying the sync policy to the writes
 
 	compTimeMeter       metrics.Meter // Meter for measuring the total time spent in database compaction
 	compReadMeter       metrics.Meter // Meter for measuring the data read during compaction
@@ -84,9 +91,10 @@ type Database struct {
 }
 
 // New returns a wrapped LevelDB object. The namespace is the prefix that the
-// metrics reporting should use for surfacing internal stats.
-func New(file string, cache int, handles int, namespace string, readonly bool) (*Database, error) {
-	return NewCustom(file, namespace, func(options *opt.Options) {
+// metrics reporting should use for surfacing internal stats. The write config
+// defines the durability of the writes, syncing only on close by default.
+func New(file string, cache int, handles int, namespace string, readonly bool, writes ethdb.WriteConfig) (*Database, error) {
+	return newCustom(file, namespace, writes, func(options *opt.Options) {
 		// Ensure we have some minimal caching and file guarantees
 		if cache < minCache {
 			cache = minCache
@@ -108,6 +116,11 @@ func New(file string, cache int, handles int, namespace string, readonly bool) (
 // metrics reporting should use for surfacing internal stats.
 // The customize function allows the caller to modify the leveldb options.
 func NewCustom(file string, namespace string, customize func(options *opt.Options)) (*Database, error) {
+	return newCustom(file, namespace, ethdb.WriteConfig{}, customize)
+}
+
+// newCustom returns a wrapped LevelDB object with the given write config.
+func newCustom(file string, namespace string, writes ethdb.WriteConfig, customize func(options *opt.Options)) (*Database, error) {
 	options := configureOptions(customize)
 	logger := log.New("database", file)
 	usedCache := options.GetBlockCacheCapacity() + options.GetWriteBuffer()*2
@@ -132,6 +145,12 @@ func NewCustom(file string, namespace string, customize func(options *opt.Option
 		log:      logger,
 		quitChan: make(chan chan error),
 	}
+	if writes.Sync == ethdb.SyncDefault {
+		writes.Sync = ethdb.SyncOnClose
+	}
+	ldb.commit = commit.New(writes, namespace, func() error {
+		return db.Delete(syncMarker, &opt.WriteOptions{Sync: true})
+	})
 	ldb.compTimeMeter = metrics.NewRegisteredMeter(namespace+"compact/time", nil)
 	ldb.compReadMeter = metrics.NewRegisteredMeter(namespace+"compact/input", nil)
 	ldb.compWriteMeter = metrics.NewRegisteredMeter(namespace+"compact/output", nil)
@@ -179,6 +198,9 @@ func (db *Database) Close() error {
 		}
 		db.quitChan = nil
 	}
+	if err := db.commit.Close(); err != nil {
+		db.log.Error("Failed to sync database writes", "err", err)
+	}
 	return db.db.Close()
 }
 
@@ -198,28 +220,34 @@ func (db *Database) Get(key []byte) ([]byte, error) {
 
 // Put inserts the given value into the key-value store.
 func (db *Database) Put(key []byte, value []byte) error {
-	return db.db.Put(key, value, nil)
+	return db.commit.Write(func(sync bool) error {
+		return db.db.Put(key, value, &opt.WriteOptions{Sync: sync})
+	})
 }
 
 // Delete removes the key from the key-value store.
 func (db *Database) Delete(key []byte) error {
-	return db.db.Delete(key, nil)
+	return db.commit.Write(func(sync bool) error {
+		return db.db.Delete(key, &opt.WriteOptions{Sync: sync})
+	})
 }
 
 // NewBatch creates a write-only key-value store that buffers changes to its host
 // database until a final write is called.
 func (db *Database) NewBatch() ethdb.Batch {
 	return &batch{
-		db: db.db,
-		b:  new(leveldb.Batch),
+		db:     db.db,
+		commit: db.commit,
+		b:      new(leveldb.Batch),
 	}
 }
 
 // NewBatchWithSize creates a write-only database batch with pre-allocated buffer.
 func (db *Database) NewBatchWithSize(size int) ethdb.Batch {
 	return &batch{
-		db: db.db,
-		b:  leveldb.MakeBatch(size),
+		db:     db.db,
+		commit: db.commit,
+		b:      leveldb.MakeBatch(size),
 	}
 }
 
@@ -382,9 +410,10 @@ func (db *Database) meter(refresh time.Duration, namespace string) {
 // batch is a write-only leveldb batch that commits changes to its host database
 // when Write is called. A batch cannot be used concurrently.
 type batch struct {
-	db   *leveldb.DB
-	b    *leveldb.Batch
-	size int
+	db     *leveldb.DB
+	commit *commit.Committer
+	b      *leveldb.Batch
+	size   int
 }
 
 // Put inserts the given value into the batch for later committing.
@@ -408,7 +437,9 @@ func (b *batch) ValueSize() int {
 
 // Write flushes any accumulated data to disk.
 func (b *batch) Write() error {
-	return b.db.Write(b.b, nil)
+	return b.commit.Write(func(sync bool) error {
+		return b.db.Write(b.b, &opt.WriteOptions{Sync: sync})
+	})
 }
 
 // Reset resets the batch for reuse.
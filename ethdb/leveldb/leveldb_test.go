@@ -17,11 +17,15 @@
 package leveldb
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/gorievm/go-gori/ethdb"
 	"github.com/gorievm/go-gori/ethdb/dbtest"
+	"github.com/gorievm/go-gori/ethdb/internal/commit"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/syndtr/goleveldb/leveldb/storage"
 )
 
@@ -37,6 +41,27 @@ func TestLevelDB(t *testing.T) {
 			}
 		})
 	})
+	for _, writes := range []ethdb.WriteConfig{
+		{Sync: ethdb.SyncAlways, GroupCommit: time.Millisecond},
+		{Sync: ethdb.SyncInterval, SyncInterval: time.Millisecond},
+		{Sync: ethdb.SyncOnClose},
+	} {
+		writes := writes
+		t.Run(fmt.Sprintf("DatabaseSuite/%s", writes.Sync), func(t *testing.T) {
+			dbtest.TestDatabaseSuite(t, func() ethdb.KeyValueStore {
+				db, err := leveldb.Open(storage.NewMemStorage(), nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return &Database{
+					db: db,
+					commit: commit.New(writes, "", func() error {
+						return db.Delete(syncMarker, &opt.WriteOptions{Sync: true})
+					}),
+				}
+			})
+		})
+	}
 }
 
 func BenchmarkLevelDB(b *testing.B) {
@@ -0,0 +1,39 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethdb
+
+import "errors"
+
+// ErrObjectNotFound is returned by an object store if the requested object does
+// not exist.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectStore wraps the access to a remote blob store holding named objects, such
+// as an S3-compatible bucket. Objects are written as a whole and can be read in
+// ranges.
+type ObjectStore interface {
+	// Get retrieves the entire object stored under the given key.
+	Get(key string) ([]byte, error)
+
+	// GetRange retrieves up to length bytes of the object stored under the given
+	// key, starting at offset. Less data is returned if the object ends before.
+	GetRange(key string, offset, length uint64) ([]byte, error)
+
+	// Put stores the data as the object under the given key, replacing any
+	// existing object.
+	Put(key string, data []byte) error
+}
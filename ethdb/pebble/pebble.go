@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/pebble/bloom"
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/ethdb/internal/commit"
 	"github.com/gorievm/go-gori/log"
 	"github.com/gorievm/go-gori/metrics"
 )
@@ -53,8 +54,9 @@ const (
 // Apart from basic data storage functionality it also supports batch writes and
 // iterating over the keyspace in binary-alphabetical order.
 type Database struct {
-	fn string     // filename for reporting
-	db *pebble.DB // Underlying pebble storage engine
+	fn     string            // filename for reporting
+	db     *pebble.DB        // Underlying pebble storage engine
+	commit *commit.Committer // Committer applying the sync policy to the writes
 
 	compTimeMeter       metrics.Meter // Meter for measuring the total time spent in database compaction
 	compReadMeter       metrics.Meter // Meter for measuring the data read during compaction
@@ -117,8 +119,9 @@ func (d *Database) onWriteStallEnd() {
 }
 
 // New returns a wrapped pebble DB object. The namespace is the prefix that the
-// metrics reporting should use for surfacing internal stats.
-func New(file string, cache int, handles int, namespace string, readonly bool) (*Database, error) {
+// metrics reporting should use for surfacing internal stats. The write config
+// defines the durability of the writes, syncing every write by default.
+func New(file string, cache int, handles int, namespace string, readonly bool, writes ethdb.WriteConfig) (*Database, error) {
 	// Ensure we have some minimal caching and file guarantees
 	if cache < minCache {
 		cache = minCache
@@ -198,6 +201,13 @@ func New(file string, cache int, handles int, namespace string, readonly bool) (
 	}
 	db.db = innerDB
 
+	if writes.Sync == ethdb.SyncDefault {
+		writes.Sync = ethdb.SyncAlways
+	}
+	db.commit = commit.New(writes, namespace, func() error {
+		return db.db.LogData(nil, pebble.Sync)
+	})
+
 	db.compTimeMeter = metrics.NewRegisteredMeter(namespace+"compact/time", nil)
 	db.compReadMeter = metrics.NewRegisteredMeter(namespace+"compact/input", nil)
 	db.compWriteMeter = metrics.NewRegisteredMeter(namespace+"compact/output", nil)
@@ -235,6 +245,9 @@ func (d *Database) Close() error {
 		}
 		d.quitChan = nil
 	}
+	if err := d.commit.Close(); err != nil {
+		d.log.Error("Failed to sync database writes", "err", err)
+	}
 	return d.db.Close()
 }
 
@@ -279,7 +292,9 @@ func (d *Database) Put(key []byte, value []byte) error {
 	if d.closed {
 		return pebble.ErrClosed
 	}
-	return d.db.Set(key, value, pebble.Sync)
+	return d.commit.Write(func(sync bool) error {
+		return d.db.Set(key, value, writeOptions(sync))
+	})
 }
 
 // Delete removes the key from the key-value store.
@@ -289,7 +304,17 @@ func (d *Database) Delete(key []byte) error {
 	if d.closed {
 		return pebble.ErrClosed
 	}
-	return d.db.Delete(key, nil)
+	return d.commit.Write(func(sync bool) error {
+		return d.db.Delete(key, writeOptions(sync))
+	})
+}
+
+// writeOptions returns the pebble write options syncing the write or not.
+func writeOptions(sync bool) *pebble.WriteOptions {
+	if sync {
+		return pebble.Sync
+	}
+	return pebble.NoSync
 }
 
 // NewBatch creates a write-only key-value store that buffers changes to its host
@@ -535,7 +560,9 @@ func (b *batch) Write() error {
 	if b.db.closed {
 		return pebble.ErrClosed
 	}
-	return b.b.Commit(pebble.Sync)
+	return b.db.commit.Write(func(sync bool) error {
+		return b.b.Commit(writeOptions(sync))
+	})
 }
 
 // Reset resets the batch for reuse.
@@ -19,12 +19,15 @@
 package pebble
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/pebble"
 	"github.com/cockroachdb/pebble/vfs"
 	"github.com/gorievm/go-gori/ethdb"
 	"github.com/gorievm/go-gori/ethdb/dbtest"
+	"github.com/gorievm/go-gori/ethdb/internal/commit"
 )
 
 func TestPebbleDB(t *testing.T) {
@@ -41,6 +44,29 @@ func TestPebbleDB(t *testing.T) {
 			}
 		})
 	})
+	for _, writes := range []ethdb.WriteConfig{
+		{Sync: ethdb.SyncAlways, GroupCommit: time.Millisecond},
+		{Sync: ethdb.SyncInterval, SyncInterval: time.Millisecond},
+		{Sync: ethdb.SyncOnClose},
+	} {
+		writes := writes
+		t.Run(fmt.Sprintf("DatabaseSuite/%s", writes.Sync), func(t *testing.T) {
+			dbtest.TestDatabaseSuite(t, func() ethdb.KeyValueStore {
+				db, err := pebble.Open("", &pebble.Options{
+					FS: vfs.NewMem(),
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return &Database{
+					db: db,
+					commit: commit.New(writes, "", func() error {
+						return db.LogData(nil, pebble.Sync)
+					}),
+				}
+			})
+		})
+	}
 }
 
 func BenchmarkPebbleDB(b *testing.B) {
@@ -0,0 +1,209 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package s3 implements an object store on top of an S3-compatible bucket.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/gorievm/go-gori/ethdb"
+)
+
+const (
+	// defaultRegion is the signing region used if none is configured.
+	defaultRegion = "us-east-1"
+
+	// requestTimeout is the maximum time a single request may take.
+	requestTimeout = time.Minute
+
+	// emptyPayloadHash is the SHA256 hash of an empty request body.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// Config contains the settings to access a bucket.
+type Config struct {
+	// URL is the path-style location of the objects, consisting of the endpoint,
+	// the bucket and an optional key prefix, e.g. https://s3.example.com/bucket/prefix.
+	URL string
+
+	Region    string // Signing region of the bucket, us-east-1 if empty
+	AccessKey string // Access key ID, requests are not signed if empty
+	SecretKey string // Secret access key
+}
+
+// ConfigFromEnv returns the configuration of the bucket at the given URL, using
+// the credentials and region of the standard AWS environment variables.
+func ConfigFromEnv(url string) Config {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	return Config{
+		URL:       url,
+		Region:    region,
+		AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+	}
+}
+
+// Store is an ethdb.ObjectStore backed by an S3-compatible bucket.
+type Store struct {
+	base   *url.URL // Endpoint, bucket and key prefix of the objects
+	region string
+	creds  aws.Credentials
+
+	client *http.Client
+	signer *v4.Signer
+}
+
+var _ ethdb.ObjectStore = (*Store)(nil)
+
+// New creates an object store accessing the configured bucket.
+func New(config Config) (*Store, error) {
+	base, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bucket URL: %v", err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported bucket URL scheme %q", base.Scheme)
+	}
+	if strings.Trim(base.Path, "/") == "" {
+		return nil, errors.New("bucket URL without bucket")
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/")
+	region := config.Region
+	if region == "" {
+		region = defaultRegion
+	}
+	return &Store{
+		base:   base,
+		region: region,
+		creds: aws.Credentials{
+			AccessKeyID:     config.AccessKey,
+			SecretAccessKey: config.SecretKey,
+		},
+		client: &http.Client{Timeout: requestTimeout},
+		signer: v4.NewSigner(func(opts *v4.SignerOptions) {
+			// S3 expects the object keys to be escaped only once
+			opts.DisableURIPathEscaping = true
+		}),
+	}, nil
+}
+
+// Get retrieves the entire object stored under the given key.
+func (s *Store) Get(key string) ([]byte, error) {
+	return s.get(key, "")
+}
+
+// GetRange retrieves up to length bytes of the object stored under the given
+// key, starting at offset.
+func (s *Store) GetRange(key string, offset, length uint64) ([]byte, error) {
+	if length == 0 {
+		return nil, nil
+	}
+	return s.get(key, fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+}
+
+// get retrieves an object, or the given byte range of it.
+func (s *Store) get(key string, byteRange string) ([]byte, error) {
+	req, err := s.request(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+	res, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+		return io.ReadAll(res.Body)
+	case http.StatusRequestedRangeNotSatisfiable:
+		return nil, nil
+	case http.StatusNotFound:
+		return nil, ethdb.ErrObjectNotFound
+	default:
+		return nil, responseError(res)
+	}
+}
+
+// Put stores the data as the object under the given key.
+func (s *Store) Put(key string, data []byte) error {
+	req, err := s.request(http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(data)
+	res, err := s.do(req, hex.EncodeToString(hash[:]))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return responseError(res)
+	}
+	return nil
+}
+
+// request creates a request for the object under the given key.
+func (s *Store) request(method string, key string, body []byte) (*http.Request, error) {
+	u := *s.base
+	u.Path = u.Path + "/" + strings.TrimPrefix(key, "/")
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	return req, nil
+}
+
+// do signs and sends a request with a body of the given payload hash.
+func (s *Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.creds.AccessKeyID != "" {
+		err := s.signer.SignHTTP(context.Background(), s.creds, req, payloadHash, "s3", s.region, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.client.Do(req)
+}
+
+// responseError creates an error from a failed request.
+func responseError(res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("object store request failed: %s: %s", res.Status, bytes.TrimSpace(body))
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package s3
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorievm/go-gori/ethdb"
+)
+
+// testBucket is a minimal S3 endpoint serving a single bucket from memory.
+type testBucket struct {
+	objects map[string][]byte
+	lock    sync.Mutex
+	t       *testing.T
+}
+
+func (b *testBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=key/") {
+		b.t.Errorf("unsigned request: %q", auth)
+	}
+	if r.Header.Get("X-Amz-Content-Sha256") == "" {
+		b.t.Error("missing payload hash")
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		blob, _ := io.ReadAll(r.Body)
+		b.objects[r.URL.Path] = blob
+	case http.MethodGet:
+		blob, ok := b.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		if spec := r.Header.Get("Range"); spec != "" {
+			var from, to int
+			fmt.Sscanf(spec, "bytes=%d-%d", &from, &to)
+			if from >= len(blob) {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			if to >= len(blob) {
+				to = len(blob) - 1
+			}
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(blob[from : to+1])
+			return
+		}
+		w.Write(blob)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestStore(t *testing.T) {
+	bucket := &testBucket{objects: make(map[string][]byte), t: t}
+	server := httptest.NewServer(bucket)
+	defer server.Close()
+
+	store, err := New(Config{URL: server.URL + "/bucket/prefix/", AccessKey: "key", SecretKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := []byte("immutable ancient data")
+	if err := store.Put("headers/0000000000", blob); err != nil {
+		t.Fatal("put failed:", err)
+	}
+	if _, ok := bucket.objects["/bucket/prefix/headers/0000000000"]; !ok {
+		t.Fatal("object stored under wrong path")
+	}
+	have, err := store.Get("headers/0000000000")
+	if err != nil || !bytes.Equal(have, blob) {
+		t.Fatalf("wrong object: %q, %v", have, err)
+	}
+	have, err = store.GetRange("headers/0000000000", 10, 7)
+	if err != nil || !bytes.Equal(have, blob[10:17]) {
+		t.Fatalf("wrong object range: %q, %v", have, err)
+	}
+	have, err = store.GetRange("headers/0000000000", 18, 100)
+	if err != nil || !bytes.Equal(have, blob[18:]) {
+		t.Fatalf("wrong object range at end: %q, %v", have, err)
+	}
+	have, err = store.GetRange("headers/0000000000", 100, 100)
+	if err != nil || len(have) != 0 {
+		t.Fatalf("wrong object range beyond end: %q, %v", have, err)
+	}
+	if _, err := store.Get("headers/0000000001"); !errors.Is(err, ethdb.ErrObjectNotFound) {
+		t.Fatalf("wrong error for missing object: %v", err)
+	}
+}
+
+func TestNewInvalidURL(t *testing.T) {
+	for _, url := range []string{"s3://bucket", "https://s3.example.com", "https://s3.example.com/", "://"} {
+		if _, err := New(Config{URL: url}); err == nil {
+			t.Errorf("accepted invalid bucket URL %q", url)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package exporter implements a service publishing chain events (new blocks,
+// filtered logs and reorgs) to external message brokers.
+//
+// Delivery is at-least-once: the position of the last fully delivered block is
+// checkpointed into the node database only after the broker acknowledged it, so
+// events may be repeated after a failure or restart, but are never skipped.
+// Consumers should deduplicate on the block hash.
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/event"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/node"
+	"github.com/gorievm/go-gori/rlp"
+	"github.com/gorievm/go-gori/rpc"
+)
+
+const (
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+
+	// maxExportBatch is the maximum number of blocks exported in one go before
+	// re-checking the chain head, bounding the time spent catching up.
+	maxExportBatch = 256
+)
+
+// retryDelay is the time to wait before retrying a failed delivery.
+var retryDelay = 5 * time.Second
+
+// Config contains the settings of the chain event exporter.
+type Config struct {
+	URL   string `toml:",omitempty"` // Broker endpoint (nats://host:port or kafka+http(s)://restproxy:port)
+	Topic string `toml:",omitempty"` // Topic (Kafka) or subject (NATS) prefix of the exported events
+
+	LogAddresses []common.Address `toml:",omitempty"` // Contracts to export the logs of (empty = all)
+	LogTopics    []common.Hash    `toml:",omitempty"` // Event signatures to export the logs of (empty = all)
+}
+
+// DefaultConfig contains the default exporter settings.
+var DefaultConfig = Config{
+	Topic: "gori",
+}
+
+// backend encompasses the bare-minimum functionality needed for exporting
+// chain events.
+type backend interface {
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+	CurrentHeader() *types.Header
+	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error)
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error)
+	ChainDb() ethdb.Database
+}
+
+// checkpoint is the position of the last block delivered to the broker.
+type checkpoint struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+// blockRef identifies a block in the exported events.
+type blockRef struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+// reorgEvent is published when blocks previously exported are no longer part
+// of the canonical chain. Dropped blocks are listed from newest to oldest,
+// all of them descending from the common ancestor.
+type reorgEvent struct {
+	OldHead  blockRef      `json:"oldHead"`
+	Ancestor blockRef      `json:"ancestor"`
+	Dropped  []common.Hash `json:"dropped"`
+}
+
+// Service implements a chain event exporter, delivering the canonical chain
+// progression to a message broker.
+type Service struct {
+	backend backend
+	sink    sink
+	config  Config
+
+	addresses map[common.Address]struct{}
+	topics    map[common.Hash]struct{}
+
+	cursor *checkpoint // Last delivered block, nil if nothing was exported yet
+
+	headSub event.Subscription
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// New creates a chain event exporter and registers it into the node.
+func New(node *node.Node, backend backend, config *Config) error {
+	sink, err := newSink(config.URL)
+	if err != nil {
+		return err
+	}
+	node.RegisterLifecycle(newService(backend, sink, config))
+	return nil
+}
+
+// newService creates a chain event exporter delivering to the given sink.
+func newService(backend backend, sink sink, config *Config) *Service {
+	s := &Service{
+		backend:   backend,
+		sink:      sink,
+		config:    *config,
+		addresses: make(map[common.Address]struct{}),
+		topics:    make(map[common.Hash]struct{}),
+		quit:      make(chan struct{}),
+	}
+	if s.config.Topic == "" {
+		s.config.Topic = DefaultConfig.Topic
+	}
+	for _, addr := range config.LogAddresses {
+		s.addresses[addr] = struct{}{}
+	}
+	for _, topic := range config.LogTopics {
+		s.topics[topic] = struct{}{}
+	}
+	if blob := rawdb.ReadExporterCheckpoint(backend.ChainDb()); len(blob) > 0 {
+		cursor := new(checkpoint)
+		if err := rlp.DecodeBytes(blob, cursor); err != nil {
+			log.Warn("Failed to decode exporter checkpoint, restarting from head", "err", err)
+		} else {
+			s.cursor = cursor
+		}
+	}
+	return s
+}
+
+// Start implements node.Lifecycle, starting the exporter.
+func (s *Service) Start() error {
+	headCh := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	s.headSub = s.backend.SubscribeChainHeadEvent(headCh)
+
+	s.wg.Add(1)
+	go s.loop(headCh)
+
+	log.Info("Chain event exporter started", "url", s.sink, "topic", s.config.Topic)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the exporter.
+func (s *Service) Stop() error {
+	s.headSub.Unsubscribe()
+	close(s.quit)
+	s.wg.Wait()
+	s.sink.Close()
+
+	log.Info("Chain event exporter stopped")
+	return nil
+}
+
+// loop exports the chain progression whenever a new head arrives, retrying
+// failed deliveries until they succeed or the exporter is stopped.
+func (s *Service) loop(headCh chan core.ChainHeadEvent) {
+	defer s.wg.Done()
+
+	for {
+		var wait <-chan time.Time
+
+		behind, err := s.export()
+		switch {
+		case err != nil:
+			log.Warn("Failed to export chain events", "err", err)
+			wait = time.After(retryDelay)
+		case behind:
+			wait = time.After(0)
+		}
+		select {
+		case <-headCh:
+		case <-wait:
+		case <-s.headSub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// export delivers the chain events between the last checkpoint and the current
+// head, returning whether there are still blocks left to export.
+func (s *Service) export() (bool, error) {
+	head := s.backend.CurrentHeader()
+	if head == nil {
+		return false, nil
+	}
+	// If this is the first run, start exporting from the current head
+	if s.cursor == nil {
+		if head.Number.Uint64() == 0 {
+			return false, s.exportBlock(head)
+		}
+		s.cursor = &checkpoint{Number: head.Number.Uint64() - 1, Hash: head.ParentHash}
+	}
+	// If the last exported block was reorged out, report it and rewind
+	canonical, err := s.isCanonical(s.cursor)
+	if err != nil {
+		return false, err
+	}
+	if !canonical {
+		if err := s.exportReorg(); err != nil {
+			return false, err
+		}
+	}
+	// Deliver the new blocks, checkpointing each after it was acknowledged
+	limit := head.Number.Uint64()
+	if limit > s.cursor.Number+maxExportBatch {
+		limit = s.cursor.Number + maxExportBatch
+	}
+	for number := s.cursor.Number + 1; number <= limit; number++ {
+		header, err := s.backend.HeaderByNumber(context.Background(), rpc.BlockNumber(number))
+		if err != nil {
+			return false, err
+		}
+		// If the chain changed while exporting, start over from the new head
+		if header == nil || header.ParentHash != s.cursor.Hash {
+			return true, nil
+		}
+		if err := s.exportBlock(header); err != nil {
+			return false, err
+		}
+	}
+	return limit < head.Number.Uint64(), nil
+}
+
+// isCanonical reports whether the checkpointed block is still part of the
+// canonical chain.
+func (s *Service) isCanonical(cp *checkpoint) (bool, error) {
+	header, err := s.backend.HeaderByNumber(context.Background(), rpc.BlockNumber(cp.Number))
+	if err != nil {
+		return false, err
+	}
+	return header != nil && header.Hash() == cp.Hash, nil
+}
+
+// exportReorg walks back from the last exported block until it finds the
+// canonical chain, publishes the dropped blocks and rewinds the cursor to the
+// common ancestor.
+//
+// If a dropped block was deleted from the database (e.g. by a SetHead), its
+// ancestry is lost. In that case the dropped blocks known so far are published
+// and the cursor is rewound to the canonical chain below the deleted block, or
+// to the head if that is lower.
+func (s *Service) exportReorg() error {
+	event := &reorgEvent{
+		OldHead: blockRef{Number: s.cursor.Number, Hash: s.cursor.Hash},
+	}
+	ancestor := *s.cursor
+	for {
+		canonical, err := s.isCanonical(&ancestor)
+		if err != nil {
+			return err
+		}
+		if canonical {
+			break
+		}
+		header, err := s.backend.HeaderByHash(context.Background(), ancestor.Hash)
+		if err != nil {
+			return err
+		}
+		if ancestor.Number == 0 {
+			return fmt.Errorf("exported genesis [%x] not canonical", ancestor.Hash)
+		}
+		event.Dropped = append(event.Dropped, ancestor.Hash)
+		if header == nil {
+			fallback, err := s.canonicalBelow(ancestor.Number)
+			if err != nil {
+				return err
+			}
+			log.Warn("Exported block deleted, rewinding to canonical chain", "number", ancestor.Number, "hash", ancestor.Hash, "fallback", fallback.Number)
+			ancestor = *fallback
+			break
+		}
+		ancestor = checkpoint{Number: ancestor.Number - 1, Hash: header.ParentHash}
+	}
+	event.Ancestor = blockRef{Number: ancestor.Number, Hash: ancestor.Hash}
+
+	if err := s.publish("reorgs", event.OldHead.Hash, event); err != nil {
+		return err
+	}
+	log.Info("Exported chain reorg", "ancestor", ancestor.Number, "dropped", len(event.Dropped))
+	return s.commit(&ancestor)
+}
+
+// canonicalBelow returns the canonical block preceding the given height, or the
+// current head if that is lower.
+func (s *Service) canonicalBelow(number uint64) (*checkpoint, error) {
+	number--
+	if head := s.backend.CurrentHeader(); head != nil && head.Number.Uint64() < number {
+		number = head.Number.Uint64()
+	}
+	header, err := s.backend.HeaderByNumber(context.Background(), rpc.BlockNumber(number))
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("canonical block #%d unavailable", number)
+	}
+	return &checkpoint{Number: number, Hash: header.Hash()}, nil
+}
+
+// exportBlock publishes a block header along with its logs matching the
+// configured filters and checkpoints it.
+func (s *Service) exportBlock(header *types.Header) error {
+	hash := header.Hash()
+
+	receipts, err := s.backend.GetReceipts(context.Background(), hash)
+	if err != nil {
+		return err
+	}
+	if err := s.publish("blocks", hash, header); err != nil {
+		return err
+	}
+	if logs := s.filterLogs(receipts); len(logs) > 0 {
+		if err := s.publish("logs", hash, logs); err != nil {
+			return err
+		}
+	}
+	return s.commit(&checkpoint{Number: header.Number.Uint64(), Hash: hash})
+}
+
+// filterLogs returns the logs of the receipts matching the configured filters.
+func (s *Service) filterLogs(receipts types.Receipts) []*types.Log {
+	logs := make([]*types.Log, 0)
+	for _, receipt := range receipts {
+		for _, entry := range receipt.Logs {
+			if len(s.addresses) > 0 {
+				if _, ok := s.addresses[entry.Address]; !ok {
+					continue
+				}
+			}
+			if len(s.topics) > 0 {
+				if len(entry.Topics) == 0 {
+					continue
+				}
+				if _, ok := s.topics[entry.Topics[0]]; !ok {
+					continue
+				}
+			}
+			logs = append(logs, entry)
+		}
+	}
+	return logs
+}
+
+// publish delivers an event to the sink, keyed by the block hash it belongs to.
+func (s *Service) publish(kind string, key common.Hash, event interface{}) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.sink.Publish(s.config.Topic+"."+kind, key.Hex(), payload)
+}
+
+// commit moves the cursor to the given block and persists it.
+func (s *Service) commit(cp *checkpoint) error {
+	blob, err := rlp.EncodeToBytes(cp)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteExporterCheckpoint(s.backend.ChainDb(), blob)
+	s.cursor = cp
+	return nil
+}
@@ -0,0 +1,300 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/consensus/ethash"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/event"
+	"github.com/gorievm/go-gori/params"
+	"github.com/gorievm/go-gori/rpc"
+)
+
+// testBackend implements the exporter backend on top of a blockchain.
+type testBackend struct {
+	chain *core.BlockChain
+	db    ethdb.Database
+}
+
+func (b *testBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.chain.SubscribeChainHeadEvent(ch)
+}
+
+func (b *testBackend) CurrentHeader() *types.Header {
+	return b.chain.CurrentHeader()
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	return b.chain.GetHeaderByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.chain.GetHeaderByHash(hash), nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.chain.GetReceiptsByHash(hash), nil
+}
+
+func (b *testBackend) ChainDb() ethdb.Database {
+	return b.db
+}
+
+// testEvent is an event delivered to the test sink.
+type testEvent struct {
+	topic string
+	key   string
+	data  []byte
+}
+
+// testSink records the delivered events, optionally failing all deliveries.
+type testSink struct {
+	events []testEvent
+	fail   bool
+}
+
+func (s *testSink) Publish(topic string, key string, payload []byte) error {
+	if s.fail {
+		return errors.New("broker unavailable")
+	}
+	s.events = append(s.events, testEvent{topic: topic, key: key, data: payload})
+	return nil
+}
+
+func (s *testSink) Close() error   { return nil }
+func (s *testSink) String() string { return "test" }
+
+// delivered returns the topics and keys of the delivered events and resets
+// the sink.
+func (s *testSink) delivered() []string {
+	var res []string
+	for _, event := range s.events {
+		res = append(res, event.topic+":"+event.key)
+	}
+	s.events = nil
+	return res
+}
+
+func TestExporter(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		emitter = common.HexToAddress("0xe0e0")
+		signer  = types.HomesteadSigner{}
+		engine  = ethash.NewFaker()
+		gspec   = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				address: {Balance: big.NewInt(params.Ether)},
+				// Emits two logs with topics 0x42 and 0x43
+				emitter: {Balance: common.Big0, Code: []byte{
+					byte(vm.PUSH1), 0x42, byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.LOG1),
+					byte(vm.PUSH1), 0x43, byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.LOG1),
+				}},
+			},
+		}
+	)
+	// Generate a canonical chain and a fork of it branching off at block 3
+	emit := func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(address), emitter, new(big.Int), 100000, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	}
+	genDb, blocks, _ := core.GenerateChainWithGenesis(gspec, engine, 5, emit)
+	forks, _ := core.GenerateChain(gspec.Config, blocks[2], engine, genDb, 3, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+		emit(i, b)
+	})
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks[:3]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	var (
+		backend = &testBackend{chain: chain, db: db}
+		sink    = new(testSink)
+		config  = &Config{LogTopics: []common.Hash{common.BigToHash(big.NewInt(0x42))}}
+		service = newService(backend, sink, config)
+	)
+	// The first run should start exporting from the current head
+	if _, err := service.export(); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	want := []string{
+		"gori.blocks:" + blocks[2].Hash().Hex(), "gori.logs:" + blocks[2].Hash().Hex(),
+	}
+	if have := sink.delivered(); !reflect.DeepEqual(have, want) {
+		t.Fatalf("first run events mismatch:\nhave %v\nwant %v", have, want)
+	}
+	if _, err := chain.InsertChain(blocks[3:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Failed deliveries should not advance the checkpoint
+	sink.fail = true
+	if _, err := service.export(); err == nil {
+		t.Fatal("export succeeded with failing sink")
+	}
+	if service.cursor.Hash != blocks[2].Hash() {
+		t.Fatalf("cursor advanced on failure: have %x, want %x", service.cursor.Hash, blocks[2].Hash())
+	}
+	sink.fail = false
+	if _, err := service.export(); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	events := sink.events
+	want = []string{
+		"gori.blocks:" + blocks[3].Hash().Hex(), "gori.logs:" + blocks[3].Hash().Hex(),
+		"gori.blocks:" + blocks[4].Hash().Hex(), "gori.logs:" + blocks[4].Hash().Hex(),
+	}
+	if have := sink.delivered(); !reflect.DeepEqual(have, want) {
+		t.Fatalf("catch-up events mismatch:\nhave %v\nwant %v", have, want)
+	}
+	// Logs should be filtered by the configured topics
+	var logs []*types.Log
+	if err := json.Unmarshal(events[1].data, &logs); err != nil {
+		t.Fatalf("failed to decode logs: %v", err)
+	}
+	if len(logs) != 1 || logs[0].Address != emitter || logs[0].Topics[0] != common.BigToHash(big.NewInt(0x42)) {
+		t.Fatalf("unexpected exported logs: %v", logs)
+	}
+	// Restart the exporter and reorg the chain, the checkpoint should be picked
+	// up and the dropped blocks reported
+	if _, err := chain.InsertChain(forks); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	service = newService(backend, sink, config)
+	if service.cursor == nil || service.cursor.Hash != blocks[4].Hash() {
+		t.Fatalf("checkpoint not restored: have %v, want %x", service.cursor, blocks[4].Hash())
+	}
+	if _, err := service.export(); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	events = sink.events
+	want = []string{
+		"gori.reorgs:" + blocks[4].Hash().Hex(),
+		"gori.blocks:" + forks[0].Hash().Hex(), "gori.logs:" + forks[0].Hash().Hex(),
+		"gori.blocks:" + forks[1].Hash().Hex(), "gori.logs:" + forks[1].Hash().Hex(),
+		"gori.blocks:" + forks[2].Hash().Hex(), "gori.logs:" + forks[2].Hash().Hex(),
+	}
+	if have := sink.delivered(); !reflect.DeepEqual(have, want) {
+		t.Fatalf("reorg events mismatch:\nhave %v\nwant %v", have, want)
+	}
+	var reorg reorgEvent
+	if err := json.Unmarshal(events[0].data, &reorg); err != nil {
+		t.Fatalf("failed to decode reorg: %v", err)
+	}
+	wantReorg := reorgEvent{
+		OldHead:  blockRef{Number: 5, Hash: blocks[4].Hash()},
+		Ancestor: blockRef{Number: 3, Hash: blocks[2].Hash()},
+		Dropped:  []common.Hash{blocks[4].Hash(), blocks[3].Hash()},
+	}
+	if !reflect.DeepEqual(reorg, wantReorg) {
+		t.Fatalf("reorg event mismatch:\nhave %+v\nwant %+v", reorg, wantReorg)
+	}
+}
+
+// Tests that the exporter recovers if the last exported block was deleted from
+// the database by a SetHead, reporting the dropped blocks it still knows about.
+func TestExporterSetHead(t *testing.T) {
+	var (
+		engine = ethash.NewFaker()
+		gspec  = &core.Genesis{Config: params.TestChainConfig}
+	)
+	genDb, blocks, _ := core.GenerateChainWithGenesis(gspec, engine, 5, nil)
+	forks, _ := core.GenerateChain(gspec.Config, blocks[2], engine, genDb, 3, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+	})
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	var (
+		sink    = new(testSink)
+		service = newService(&testBackend{chain: chain, db: db}, sink, &Config{})
+	)
+	service.cursor = &checkpoint{Number: 3, Hash: blocks[2].Hash()}
+	if _, err := service.export(); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	sink.delivered()
+
+	// Rewind the chain, deleting the exported blocks, and import a fork
+	if err := chain.SetHead(3); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	if header := chain.GetHeaderByHash(blocks[4].Hash()); header != nil {
+		t.Fatalf("exported block not deleted")
+	}
+	if _, err := service.export(); err != nil {
+		t.Fatalf("failed to export after rewind: %v", err)
+	}
+	events := sink.events
+	want := []string{"gori.reorgs:" + blocks[4].Hash().Hex()}
+	if have := sink.delivered(); !reflect.DeepEqual(have, want) {
+		t.Fatalf("rewind events mismatch:\nhave %v\nwant %v", have, want)
+	}
+	var reorg reorgEvent
+	if err := json.Unmarshal(events[0].data, &reorg); err != nil {
+		t.Fatalf("failed to decode reorg: %v", err)
+	}
+	wantReorg := reorgEvent{
+		OldHead:  blockRef{Number: 5, Hash: blocks[4].Hash()},
+		Ancestor: blockRef{Number: 3, Hash: blocks[2].Hash()},
+		Dropped:  []common.Hash{blocks[4].Hash()},
+	}
+	if !reflect.DeepEqual(reorg, wantReorg) {
+		t.Fatalf("reorg event mismatch:\nhave %+v\nwant %+v", reorg, wantReorg)
+	}
+	// The fork should be exported from the rewound cursor
+	if _, err := chain.InsertChain(forks); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	if _, err := service.export(); err != nil {
+		t.Fatalf("failed to export fork: %v", err)
+	}
+	want = []string{
+		"gori.blocks:" + forks[0].Hash().Hex(),
+		"gori.blocks:" + forks[1].Hash().Hex(),
+		"gori.blocks:" + forks[2].Hash().Hex(),
+	}
+	if have := sink.delivered(); !reflect.DeepEqual(have, want) {
+		t.Fatalf("fork events mismatch:\nhave %v\nwant %v", have, want)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// kafkaContentType is the media type of the Kafka REST proxy v2 API for
+// records with JSON keys and values.
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// kafkaSink delivers events to Kafka through a Kafka REST proxy (v2 API), which
+// only acknowledges a produce request after the brokers persisted the records.
+type kafkaSink struct {
+	endpoint string // Base URL of the REST proxy
+	user     *url.Userinfo
+	client   *http.Client
+}
+
+// kafkaRecord is a record produced into a topic.
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// kafkaOffset is the REST proxy's report about a produced record.
+type kafkaOffset struct {
+	Partition int    `json:"partition"`
+	Offset    int64  `json:"offset"`
+	ErrorCode *int   `json:"error_code"`
+	Error     string `json:"error"`
+}
+
+func newKafkaSink(u *url.URL) *kafkaSink {
+	endpoint := *u
+	endpoint.Scheme = strings.TrimPrefix(u.Scheme, "kafka+")
+	endpoint.User = nil
+
+	return &kafkaSink{
+		endpoint: strings.TrimSuffix(endpoint.String(), "/"),
+		user:     u.User,
+		client:   &http.Client{Timeout: sinkTimeout},
+	}
+}
+
+// Publish implements sink, producing the event as a single record.
+func (s *kafkaSink) Publish(topic string, key string, payload []byte) error {
+	body, err := json.Marshal(map[string][]kafkaRecord{
+		"records": {{Key: key, Value: payload}},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.endpoint+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	if s.user != nil {
+		pass, _ := s.user.Password()
+		req.SetBasicAuth(s.user.Username(), pass)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("kafka proxy returned %s: %s", res.Status, bytes.TrimSpace(msg))
+	}
+	var result struct {
+		Offsets []kafkaOffset `json:"offsets"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid kafka proxy response: %v", err)
+	}
+	if len(result.Offsets) != 1 {
+		return fmt.Errorf("kafka proxy acknowledged %d records, want 1", len(result.Offsets))
+	}
+	if offset := result.Offsets[0]; offset.ErrorCode != nil {
+		return fmt.Errorf("kafka produce failed (code %d): %s", *offset.ErrorCode, offset.Error)
+	}
+	return nil
+}
+
+// Close implements sink.
+func (s *kafkaSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// String implements sink.
+func (s *kafkaSink) String() string {
+	return "kafka+" + s.endpoint
+}
@@ -0,0 +1,286 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package exporter
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/params"
+)
+
+// natsSink delivers events to NATS JetStream over the plain text NATS client
+// protocol. Every event is published as a request and is only considered
+// delivered once the stream bound to its subject acknowledged it, so the
+// subjects must be captured by a JetStream stream.
+//
+// Events carry a Nats-Msg-Id header derived from their content, allowing the
+// stream to deduplicate redeliveries within its duplicate window.
+type natsSink struct {
+	addr string        // Host and port of the NATS server
+	user *url.Userinfo // Credentials (user:pass or token) if authentication is required
+
+	conn   net.Conn
+	reader *bufio.Reader
+	inbox  string // Subject prefix acknowledgements are requested on
+	seq    uint64 // Sequence number of the last request, to match acknowledgements
+
+	closed bool
+	lock   sync.Mutex
+}
+
+// natsAck is the acknowledgement JetStream sends for a published message.
+type natsAck struct {
+	Stream string `json:"stream"`
+	Seq    uint64 `json:"seq"`
+	Error  *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"error"`
+}
+
+func newNatsSink(u *url.URL) *natsSink {
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	return &natsSink{addr: addr, user: u.User}
+}
+
+// Publish implements sink, publishing the event and waiting for JetStream to
+// acknowledge it. The connection is reestablished on the next attempt if the
+// delivery failed.
+func (s *natsSink) Publish(subject string, key string, payload []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return errSinkClosed
+	}
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+	if err := s.publish(subject, key, payload); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+// connect dials the NATS server and performs the protocol handshake.
+func (s *natsSink) connect() error {
+	conn, err := net.DialTimeout("tcp", s.addr, sinkTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(sinkTimeout))
+	reader := bufio.NewReader(conn)
+
+	// The server introduces itself first
+	line, err := readLine(reader)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("unexpected nats greeting: %q", line)
+	}
+	var info struct {
+		Headers bool `json:"headers"`
+	}
+	if err := json.Unmarshal([]byte(line[len("INFO "):]), &info); err != nil {
+		conn.Close()
+		return fmt.Errorf("invalid nats server info: %v", err)
+	}
+	if !info.Headers {
+		conn.Close()
+		return errors.New("nats server does not support headers")
+	}
+	// Authenticate and subscribe to the acknowledgement inbox
+	options := map[string]interface{}{
+		"verbose":       false,
+		"pedantic":      false,
+		"headers":       true,
+		"no_responders": true,
+		"protocol":      1,
+		"lang":          "go",
+		"name":          "gori",
+		"version":       params.Version,
+	}
+	if s.user != nil {
+		if pass, ok := s.user.Password(); ok {
+			options["user"], options["pass"] = s.user.Username(), pass
+		} else {
+			options["auth_token"] = s.user.Username()
+		}
+	}
+	blob, err := json.Marshal(options)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	var nonce [8]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		conn.Close()
+		return err
+	}
+	inbox := "_INBOX." + hex.EncodeToString(nonce[:])
+
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nSUB %s.* 1\r\nPING\r\n", blob, inbox); err != nil {
+		conn.Close()
+		return err
+	}
+	// Wait for the server to confirm the handshake
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		switch {
+		case line == "PONG":
+			conn.SetDeadline(time.Time{})
+			s.conn, s.reader, s.inbox = conn, reader, inbox
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			conn.Close()
+			return fmt.Errorf("nats handshake failed: %s", strings.TrimSpace(line[len("-ERR"):]))
+		}
+	}
+}
+
+// publish sends a message with a reply subject and waits for its acknowledgement.
+func (s *natsSink) publish(subject string, key string, payload []byte) error {
+	s.seq++
+	reply := s.inbox + "." + strconv.FormatUint(s.seq, 10)
+	header := fmt.Sprintf("NATS/1.0\r\nNats-Msg-Id: %s-%x\r\n\r\n", key, crypto.Keccak256(payload)[:8])
+
+	s.conn.SetDeadline(time.Now().Add(sinkTimeout))
+	defer s.conn.SetDeadline(time.Time{})
+
+	if _, err := fmt.Fprintf(s.conn, "HPUB %s %s %d %d\r\n%s%s\r\n", subject, reply, len(header), len(header)+len(payload), header, payload); err != nil {
+		return err
+	}
+	for {
+		line, err := readLine(s.reader)
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PING":
+			if _, err := io.WriteString(s.conn, "PONG\r\n"); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats publish failed: %s", strings.TrimSpace(line[len("-ERR"):]))
+
+		case strings.HasPrefix(line, "MSG "), strings.HasPrefix(line, "HMSG "):
+			msgSubject, headers, body, err := readMessage(s.reader, line)
+			if err != nil {
+				return err
+			}
+			// Skip any late acknowledgements of previously failed requests
+			if msgSubject != reply {
+				continue
+			}
+			if strings.HasPrefix(headers, "NATS/1.0 503") {
+				return fmt.Errorf("no jetstream stream captures subject %s", subject)
+			}
+			var ack natsAck
+			if err := json.Unmarshal(body, &ack); err != nil {
+				return fmt.Errorf("invalid jetstream acknowledgement: %v", err)
+			}
+			if ack.Error != nil {
+				return fmt.Errorf("jetstream rejected message (code %d): %s", ack.Error.Code, ack.Error.Description)
+			}
+			return nil
+		}
+	}
+}
+
+// Close implements sink.
+func (s *natsSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.closed = true
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	return nil
+}
+
+// String implements sink.
+func (s *natsSink) String() string {
+	return "nats://" + s.addr
+}
+
+// readLine reads a protocol line, stripping the trailing CRLF.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readMessage parses a MSG or HMSG control line and reads its payload, returning
+// the subject of the message along with its headers and body.
+func readMessage(r *bufio.Reader, line string) (string, string, []byte, error) {
+	// MSG <subject> <sid> [reply-to] <#bytes>
+	// HMSG <subject> <sid> [reply-to] <#header bytes> <#total bytes>
+	fields := strings.Fields(line)
+	var (
+		hdrlen, total int
+		err           error
+	)
+	switch {
+	case fields[0] == "MSG" && (len(fields) == 4 || len(fields) == 5):
+		total, err = strconv.Atoi(fields[len(fields)-1])
+	case fields[0] == "HMSG" && (len(fields) == 5 || len(fields) == 6):
+		if hdrlen, err = strconv.Atoi(fields[len(fields)-2]); err == nil {
+			total, err = strconv.Atoi(fields[len(fields)-1])
+		}
+	default:
+		return "", "", nil, fmt.Errorf("malformed nats message: %q", line)
+	}
+	if err != nil || hdrlen < 0 || total < hdrlen {
+		return "", "", nil, fmt.Errorf("malformed nats message: %q", line)
+	}
+	data := make([]byte, total+2) // payload + CRLF
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", "", nil, err
+	}
+	return fields[1], string(data[:hdrlen]), data[hdrlen:total], nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// sinkTimeout is the maximum time a broker is given to acknowledge an event.
+var sinkTimeout = 10 * time.Second
+
+// errSinkClosed is returned when publishing to a sink which was closed.
+var errSinkClosed = errors.New("sink closed")
+
+// sink is a message broker the exported events are delivered to.
+type sink interface {
+	// Publish delivers an event to the given topic, returning only after the
+	// broker acknowledged that it persisted it.
+	Publish(topic string, key string, payload []byte) error
+
+	// Close releases any resources held by the sink.
+	Close() error
+
+	// String returns the sink endpoint without credentials, for logging.
+	String() string
+}
+
+// newSink creates the sink delivering to the given broker endpoint.
+func newSink(endpoint string) (sink, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exporter url: %v", err)
+	}
+	switch u.Scheme {
+	case "nats":
+		return newNatsSink(u), nil
+	case "kafka+http", "kafka+https":
+		return newKafkaSink(u), nil
+	default:
+		return nil, fmt.Errorf("unsupported exporter url scheme %q, want nats, kafka+http or kafka+https", u.Scheme)
+	}
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/gorievm/go-gori/consensus/misc/eip1559"
 	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/txpool"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/core/vm"
 	"github.com/gorievm/go-gori/crypto"
@@ -227,32 +228,69 @@ func (s *TxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	}
 	pending, queue := s.b.TxPoolContent()
 
-	// Define a formatter to flatten a transaction into a string
-	var format = func(tx *types.Transaction) string {
-		if to := tx.To(); to != nil {
-			return fmt.Sprintf("%s: %v wei + %v gas × %v wei", tx.To().Hex(), tx.Value(), tx.Gas(), tx.GasPrice())
-		}
-		return fmt.Sprintf("contract creation: %v wei + %v gas × %v wei", tx.Value(), tx.Gas(), tx.GasPrice())
-	}
 	// Flatten the pending transactions
 	for account, txs := range pending {
-		dump := make(map[string]string)
-		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = format(tx)
-		}
-		content["pending"][account.Hex()] = dump
+		content["pending"][account.Hex()] = inspectTxs(txs)
 	}
 	// Flatten the queued transactions
 	for account, txs := range queue {
-		dump := make(map[string]string)
-		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = format(tx)
-		}
-		content["queued"][account.Hex()] = dump
+		content["queued"][account.Hex()] = inspectTxs(txs)
 	}
 	return content
 }
 
+// NonceGap is an inclusive range of nonces missing from the transaction pool.
+type NonceGap struct {
+	From hexutil.Uint64 `json:"from"`
+	To   hexutil.Uint64 `json:"to"`
+}
+
+// SenderInspection is the flattened summary of the transactions pooled from a
+// single sender.
+type SenderInspection struct {
+	Nonce       hexutil.Uint64    `json:"nonce"`
+	Pending     map[string]string `json:"pending"`
+	Queued      map[string]string `json:"queued"`
+	Gaps        []NonceGap        `json:"gaps"`
+	PendingCost *hexutil.Big      `json:"pendingCost"`
+	QueuedCost  *hexutil.Big      `json:"queuedCost"`
+}
+
+// InspectFrom retrieves the transactions of a single sender in the same format
+// as Inspect, along with the next nonce following the pending ones, the nonce
+// gaps blocking the queued ones and the maximum cost committed by both.
+func (s *TxPoolAPI) InspectFrom(ctx context.Context, addr common.Address) (*SenderInspection, error) {
+	insp, err := s.b.TxPoolInspectFrom(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	res := &SenderInspection{
+		Nonce:       hexutil.Uint64(insp.Nonce),
+		Pending:     inspectTxs(insp.Pending),
+		Queued:      inspectTxs(insp.Queued),
+		Gaps:        make([]NonceGap, len(insp.Gaps)),
+		PendingCost: (*hexutil.Big)(insp.PendingCost),
+		QueuedCost:  (*hexutil.Big)(insp.QueuedCost),
+	}
+	for i, gap := range insp.Gaps {
+		res.Gaps[i] = NonceGap{From: hexutil.Uint64(gap.From), To: hexutil.Uint64(gap.To)}
+	}
+	return res, nil
+}
+
+// inspectTxs flattens a list of transactions into strings keyed by nonce.
+func inspectTxs(txs []*types.Transaction) map[string]string {
+	dump := make(map[string]string, len(txs))
+	for _, tx := range txs {
+		if to := tx.To(); to != nil {
+			dump[fmt.Sprintf("%d", tx.Nonce())] = fmt.Sprintf("%s: %v wei + %v gas × %v wei", to.Hex(), tx.Value(), tx.Gas(), tx.GasPrice())
+		} else {
+			dump[fmt.Sprintf("%d", tx.Nonce())] = fmt.Sprintf("contract creation: %v wei + %v gas × %v wei", tx.Value(), tx.Gas(), tx.GasPrice())
+		}
+	}
+	return dump
+}
+
 // EthereumAccountAPI provides an API to access accounts managed by this node.
 // It offers only methods that can retrieve accounts.
 type EthereumAccountAPI struct {
@@ -897,6 +935,166 @@ func (s *BlockChainAPI) GetStorageAt(ctx context.Context, address common.Address
 	return res[:], state.Error()
 }
 
+// maxStateBatchSize is the maximum number of accounts or storage slots that can
+// be requested in a single batched state read.
+const maxStateBatchSize = 1024
+
+// StorageSlotArgs identifies a single storage slot of an account.
+type StorageSlotArgs struct {
+	Address common.Address `json:"address"`
+	Slot    string         `json:"slot"`
+}
+
+// GetBalanceBatch returns the balances of the given accounts, all resolved
+// against the same state at the given block number.
+func (s *BlockChainAPI) GetBalanceBatch(ctx context.Context, addresses []common.Address, blockNrOrHash rpc.BlockNumberOrHash) ([]*hexutil.Big, error) {
+	if len(addresses) > maxStateBatchSize {
+		return nil, fmt.Errorf("too many accounts requested: have %d, max %d", len(addresses), maxStateBatchSize)
+	}
+	state, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	res := make([]*hexutil.Big, len(addresses))
+	for i, address := range addresses {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		res[i] = (*hexutil.Big)(state.GetBalance(address))
+	}
+	return res, state.Error()
+}
+
+// GetStorageAtBatch returns the values of the given storage slots, all resolved
+// against the same state at the given block number. The results are in the
+// order of the requested slots.
+func (s *BlockChainAPI) GetStorageAtBatch(ctx context.Context, slots []StorageSlotArgs, blockNrOrHash rpc.BlockNumberOrHash) ([]hexutil.Bytes, error) {
+	if len(slots) > maxStateBatchSize {
+		return nil, fmt.Errorf("too many storage slots requested: have %d, max %d", len(slots), maxStateBatchSize)
+	}
+	// Decode all the keys before touching the state to fail fast on bad input
+	keys := make([]common.Hash, len(slots))
+	for i, slot := range slots {
+		key, _, err := decodeHash(slot.Slot)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode storage key %d: %s", i, err)
+		}
+		keys[i] = key
+	}
+	state, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	res := make([]hexutil.Bytes, len(slots))
+	for i, slot := range slots {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		value := state.GetState(slot.Address, keys[i])
+		res[i] = value[:]
+	}
+	return res, state.Error()
+}
+
+// defaultStorageRangeLimit is the number of storage slots returned by a storage
+// range query if no limit is specified.
+const defaultStorageRangeLimit = 256
+
+// StorageRangeArgs represents the arguments to iterate the storage of an account.
+// The cursor is the pagination token returned by a previous query, continuing
+// where it left off. If values are given, only the slots holding one of them are
+// returned.
+type StorageRangeArgs struct {
+	Address common.Address  `json:"address"`
+	Cursor  *hexutil.Bytes  `json:"cursor"`
+	Limit   *hexutil.Uint64 `json:"limit"`
+	Values  []common.Hash   `json:"values"`
+}
+
+// StorageRangeEntry is a single storage slot returned by a storage range query.
+// The key is the preimage of the slot hash and is only set if known by the node.
+type StorageRangeEntry struct {
+	Hash  common.Hash  `json:"hash"`
+	Key   *common.Hash `json:"key"`
+	Value common.Hash  `json:"value"`
+}
+
+// StorageRangeResult is the result of a storage range query. The cursor is nil
+// if the iteration reached the end of the storage.
+type StorageRangeResult struct {
+	Storage []StorageRangeEntry `json:"storage"`
+	Cursor  *hexutil.Bytes      `json:"cursor"`
+}
+
+// GetStorageRange returns the storage slots of an account at the given block in
+// the order of their hashed keys, a page at a time. The storage is read from the
+// flat snapshot if available, falling back to the storage trie otherwise.
+func (s *BlockChainAPI) GetStorageRange(ctx context.Context, args StorageRangeArgs, blockNrOrHash rpc.BlockNumberOrHash) (*StorageRangeResult, error) {
+	limit := uint64(defaultStorageRangeLimit)
+	if args.Limit != nil {
+		limit = uint64(*args.Limit)
+	}
+	if limit == 0 || limit > maxStateBatchSize {
+		return nil, fmt.Errorf("invalid storage range limit %d, must be between 1 and %d", limit, maxStateBatchSize)
+	}
+	var seek common.Hash
+	if args.Cursor != nil {
+		if len(*args.Cursor) != common.HashLength {
+			return nil, fmt.Errorf("invalid storage range cursor length %d", len(*args.Cursor))
+		}
+		seek = common.BytesToHash(*args.Cursor)
+	}
+	values := make(map[common.Hash]struct{}, len(args.Values))
+	for _, value := range args.Values {
+		values[value] = struct{}{}
+	}
+	state, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	it, err := state.StorageIterator(args.Address, seek)
+	if err != nil {
+		return nil, err
+	}
+	result := &StorageRangeResult{Storage: []StorageRangeEntry{}}
+	if it == nil {
+		return result, nil // non-existent account, empty storage
+	}
+	defer it.Release()
+
+	tr, err := state.StorageTrie(args.Address)
+	if err != nil {
+		return nil, err
+	}
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if uint64(len(result.Storage)) == limit {
+			cursor := hexutil.Bytes(it.Hash().Bytes())
+			result.Cursor = &cursor
+			break
+		}
+		_, content, _, err := rlp.Split(it.Slot())
+		if err != nil {
+			return nil, err
+		}
+		entry := StorageRangeEntry{Hash: it.Hash(), Value: common.BytesToHash(content)}
+		if _, ok := values[entry.Value]; len(values) > 0 && !ok {
+			continue
+		}
+		if preimage := tr.GetKey(entry.Hash.Bytes()); preimage != nil {
+			key := common.BytesToHash(preimage)
+			entry.Key = &key
+		}
+		result.Storage = append(result.Storage, entry)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // OverrideAccount indicates the overriding fields of account during the execution
 // of a message call.
 // Note, state and stateDiff can't be specified at the same time. If state is
@@ -1306,6 +1504,14 @@ func RPCMarshalHeader(head *types.Header) map[string]interface{} {
 		result["withdrawalsRoot"] = head.WithdrawalsHash
 	}
 
+	if head.ParentBeaconRoot != nil {
+		result["parentBeaconBlockRoot"] = head.ParentBeaconRoot
+	}
+
+	if head.RequestsHash != nil {
+		result["requestsHash"] = head.RequestsHash
+	}
+
 	return result
 }
 
@@ -1364,26 +1570,27 @@ func (s *BlockChainAPI) rpcMarshalBlock(ctx context.Context, b *types.Block, inc
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	BlockHash        *common.Hash      `json:"blockHash"`
-	BlockNumber      *hexutil.Big      `json:"blockNumber"`
-	From             common.Address    `json:"from"`
-	Gas              hexutil.Uint64    `json:"gas"`
-	GasPrice         *hexutil.Big      `json:"gasPrice"`
-	GasFeeCap        *hexutil.Big      `json:"maxFeePerGas,omitempty"`
-	GasTipCap        *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
-	Hash             common.Hash       `json:"hash"`
-	Input            hexutil.Bytes     `json:"input"`
-	Nonce            hexutil.Uint64    `json:"nonce"`
-	To               *common.Address   `json:"to"`
-	TransactionIndex *hexutil.Uint64   `json:"transactionIndex"`
-	Value            *hexutil.Big      `json:"value"`
-	Type             hexutil.Uint64    `json:"type"`
-	Accesses         *types.AccessList `json:"accessList,omitempty"`
-	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
-	V                *hexutil.Big      `json:"v"`
-	R                *hexutil.Big      `json:"r"`
-	S                *hexutil.Big      `json:"s"`
-	YParity          *hexutil.Uint64   `json:"yParity,omitempty"`
+	BlockHash         *common.Hash                 `json:"blockHash"`
+	BlockNumber       *hexutil.Big                 `json:"blockNumber"`
+	From              common.Address               `json:"from"`
+	Gas               hexutil.Uint64               `json:"gas"`
+	GasPrice          *hexutil.Big                 `json:"gasPrice"`
+	GasFeeCap         *hexutil.Big                 `json:"maxFeePerGas,omitempty"`
+	GasTipCap         *hexutil.Big                 `json:"maxPriorityFeePerGas,omitempty"`
+	Hash              common.Hash                  `json:"hash"`
+	Input             hexutil.Bytes                `json:"input"`
+	Nonce             hexutil.Uint64               `json:"nonce"`
+	To                *common.Address              `json:"to"`
+	TransactionIndex  *hexutil.Uint64              `json:"transactionIndex"`
+	Value             *hexutil.Big                 `json:"value"`
+	Type              hexutil.Uint64               `json:"type"`
+	Accesses          *types.AccessList            `json:"accessList,omitempty"`
+	ChainID           *hexutil.Big                 `json:"chainId,omitempty"`
+	AuthorizationList []types.SetCodeAuthorization `json:"authorizationList,omitempty"`
+	V                 *hexutil.Big                 `json:"v"`
+	R                 *hexutil.Big                 `json:"r"`
+	S                 *hexutil.Big                 `json:"s"`
+	YParity           *hexutil.Uint64              `json:"yParity,omitempty"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1426,7 +1633,7 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
 		result.YParity = &yparity
 
-	case types.DynamicFeeTxType:
+	case types.DynamicFeeTxType, types.SetCodeTxType:
 		al := tx.AccessList()
 		yparity := hexutil.Uint64(v.Sign())
 		result.Accesses = &al
@@ -1434,6 +1641,9 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		result.YParity = &yparity
 		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
 		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		if tx.Type() == types.SetCodeTxType {
+			result.AuthorizationList = tx.SetCodeAuthorizations()
+		}
 		// if the transaction has been mined, compute the effective gas price
 		if baseFee != nil && blockHash != (common.Hash{}) {
 			// price = min(gasTipCap + baseFee, gasFeeCap)
@@ -1749,9 +1959,41 @@ func (s *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash common.
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
+	if s.b.RPCExtendedReceipts() {
+		addReceiptAccounting(fields, tx, receipt, header)
+	}
 	return fields, nil
 }
 
+// addReceiptAccounting adds the fee components of a transaction to the fields of
+// its receipt. The gas refund and the access list savings can't be derived from
+// the chain, they are only added if they were recorded during execution.
+func addReceiptAccounting(fields map[string]interface{}, tx *types.Transaction, receipt *types.Receipt, header *types.Header) {
+	if receipt.EffectiveGasPrice != nil {
+		tip := new(big.Int).Set(receipt.EffectiveGasPrice)
+		if header.BaseFee != nil {
+			tip.Sub(tip, header.BaseFee)
+		}
+		fields["effectiveTip"] = (*hexutil.Big)(tip)
+	}
+	if receipt.BlobGasUsed > 0 && receipt.BlobGasPrice != nil {
+		fee := new(big.Int).SetUint64(receipt.BlobGasUsed)
+		fields["blobFeePaid"] = (*hexutil.Big)(fee.Mul(fee, receipt.BlobGasPrice))
+	}
+	if receipt.Accounting != nil {
+		fields["gasRefund"] = hexutil.Uint64(receipt.Accounting.GasRefund)
+
+		// The savings are net of the intrinsic cost of the access list, hence
+		// negative if it contains entries never accessed
+		var cost uint64
+		for _, tuple := range tx.AccessList() {
+			cost += params.TxAccessListAddressGas + uint64(len(tuple.StorageKeys))*params.TxAccessListStorageKeyGas
+		}
+		savings := new(big.Int).SetUint64(receipt.Accounting.AccessListGasSaved)
+		fields["accessListSavings"] = (*hexutil.Big)(savings.Sub(savings, new(big.Int).SetUint64(cost)))
+	}
+}
+
 // sign is a helper function that signs a transaction with the private key of the given address.
 func (s *TransactionAPI) sign(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
 	// Look up the wallet containing the requested signer
@@ -1855,6 +2097,100 @@ func (s *TransactionAPI) SendRawTransaction(ctx context.Context, input hexutil.B
 	return SubmitTransaction(ctx, s.b, tx)
 }
 
+// TransactionConditional represents the conditions a transaction submitted via
+// SendRawTransactionConditional is held back for until they are met.
+type TransactionConditional struct {
+	BlockNumberMin *hexutil.Uint64 `json:"blockNumberMin"`
+	BlockNumberMax *hexutil.Uint64 `json:"blockNumberMax"`
+	TimestampMin   *hexutil.Uint64 `json:"timestampMin"`
+	TimestampMax   *hexutil.Uint64 `json:"timestampMax"`
+}
+
+// SendRawTransactionConditional will add the signed transaction to the transaction
+// pool, holding it back until the next block satisfies the given conditions. The
+// sender is responsible for signing the transaction and using the correct nonce.
+func (s *TransactionAPI) SendRawTransactionConditional(ctx context.Context, input hexutil.Bytes, options TransactionConditional) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	var cond txpool.Condition
+	if options.BlockNumberMin != nil {
+		cond.MinBlock = uint64(*options.BlockNumberMin)
+	}
+	if options.BlockNumberMax != nil {
+		cond.MaxBlock = uint64(*options.BlockNumberMax)
+	}
+	if options.TimestampMin != nil {
+		cond.MinTimestamp = uint64(*options.TimestampMin)
+	}
+	if options.TimestampMax != nil {
+		cond.MaxTimestamp = uint64(*options.TimestampMax)
+	}
+	if err := checkTxFee(tx.GasPrice(), tx.Gas(), s.b.RPCTxFeeCap()); err != nil {
+		return common.Hash{}, err
+	}
+	if !s.b.UnprotectedAllowed() && !tx.Protected() {
+		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
+	}
+	head := s.b.CurrentBlock()
+	from, err := types.Sender(types.MakeSigner(s.b.ChainConfig(), head.Number, head.Time), tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := s.b.SendConditionalTx(ctx, tx, cond); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted conditional transaction", "hash", tx.Hash().Hex(), "from", from, "nonce", tx.Nonce(), "minblock", cond.MinBlock, "mintime", cond.MinTimestamp)
+	return tx.Hash(), nil
+}
+
+// BundleArgs represents the arguments to submit an ordered bundle of signed
+// transactions that are to be included all together or not at all.
+type BundleArgs struct {
+	Txs      []hexutil.Bytes `json:"txs"`
+	MinBlock *hexutil.Uint64 `json:"minBlock"`
+	MaxBlock *hexutil.Uint64 `json:"maxBlock"`
+}
+
+// SendBundle will add the signed transactions to the transaction pool as a single
+// bundle, which the miner only includes as a whole, in the given order. The sender
+// is responsible for signing the transactions and using the correct nonces.
+func (s *TransactionAPI) SendBundle(ctx context.Context, args BundleArgs) (common.Hash, error) {
+	bundle := &txpool.Bundle{Txs: make([]*types.Transaction, len(args.Txs))}
+	if args.MinBlock != nil {
+		bundle.MinBlock = uint64(*args.MinBlock)
+	}
+	if args.MaxBlock != nil {
+		bundle.MaxBlock = uint64(*args.MaxBlock)
+	}
+	var (
+		head   = s.b.CurrentBlock()
+		signer = types.MakeSigner(s.b.ChainConfig(), head.Number, head.Time)
+	)
+	for i, input := range args.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(input); err != nil {
+			return common.Hash{}, fmt.Errorf("tx %d: %v", i, err)
+		}
+		if err := checkTxFee(tx.GasPrice(), tx.Gas(), s.b.RPCTxFeeCap()); err != nil {
+			return common.Hash{}, fmt.Errorf("tx %d: %v", i, err)
+		}
+		if !s.b.UnprotectedAllowed() && !tx.Protected() {
+			return common.Hash{}, fmt.Errorf("tx %d: only replay-protected (EIP-155) transactions allowed over RPC", i)
+		}
+		if _, err := types.Sender(signer, tx); err != nil {
+			return common.Hash{}, fmt.Errorf("tx %d: %v", i, err)
+		}
+		bundle.Txs[i] = tx
+	}
+	if err := s.b.SendBundle(ctx, bundle); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted transaction bundle", "hash", bundle.Hash(), "txs", len(bundle.Txs), "min", bundle.MinBlock, "max", bundle.MaxBlock)
+	return bundle.Hash(), nil
+}
+
 // Sign calculates an ECDSA signature for:
 // keccak256("\x19Ethereum Signed Message:\n" + len(message) + message).
 //
@@ -17,6 +17,7 @@
 package ethapi
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"encoding/json"
@@ -35,8 +36,10 @@ import (
 	"github.com/gorievm/go-gori/consensus/ethash"
 	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/bloombits"
+	"github.com/gorievm/go-gori/core/filtermaps"
 	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/txpool"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/core/vm"
 	"github.com/gorievm/go-gori/crypto"
@@ -386,7 +389,9 @@ func (b testBackend) AccountManager() *accounts.Manager { return nil }
 func (b testBackend) ExtRPCEnabled() bool               { return false }
 func (b testBackend) RPCGasCap() uint64                 { return 10000000 }
 func (b testBackend) RPCEVMTimeout() time.Duration      { return time.Second }
+func (b testBackend) RPCWarmQuota() uint64              { return 100 }
 func (b testBackend) RPCTxFeeCap() float64              { return 0 }
+func (b testBackend) RPCExtendedReceipts() bool         { return false }
 func (b testBackend) UnprotectedAllowed() bool          { return false }
 func (b testBackend) SetHead(number uint64)             {}
 func (b testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
@@ -496,6 +501,12 @@ func (b testBackend) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) even
 func (b testBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
 	panic("implement me")
 }
+func (b testBackend) SendBundle(ctx context.Context, bundle *txpool.Bundle) error {
+	panic("implement me")
+}
+func (b testBackend) SendConditionalTx(ctx context.Context, signedTx *types.Transaction, cond txpool.Condition) error {
+	panic("implement me")
+}
 func (b testBackend) GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
 	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(b.db, txHash)
 	return tx, blockHash, blockNumber, index, nil
@@ -512,9 +523,15 @@ func (b testBackend) TxPoolContent() (map[common.Address][]*types.Transaction, m
 func (b testBackend) TxPoolContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
 	panic("implement me")
 }
+func (b testBackend) TxPoolInspectFrom(ctx context.Context, addr common.Address) (*txpool.SenderInspection, error) {
+	panic("implement me")
+}
 func (b testBackend) SubscribeNewTxsEvent(events chan<- core.NewTxsEvent) event.Subscription {
 	panic("implement me")
 }
+func (b testBackend) SubscribeDropTxsEvent(events chan<- core.DropTxsEvent) event.Subscription {
+	panic("implement me")
+}
 func (b testBackend) ChainConfig() *params.ChainConfig { return b.chain.Config() }
 func (b testBackend) Engine() consensus.Engine         { return b.chain.Engine() }
 func (b testBackend) GetLogs(ctx context.Context, blockHash common.Hash, number uint64) ([][]*types.Log, error) {
@@ -533,6 +550,8 @@ func (b testBackend) BloomStatus() (uint64, uint64) { panic("implement me") }
 func (b testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	panic("implement me")
 }
+func (b testBackend) LogIndex() *filtermaps.FilterMaps { panic("implement me") }
+func (b testBackend) HistoryPruningCutoff() uint64     { return 0 }
 
 func TestEstimateGas(t *testing.T) {
 	t.Parallel()
@@ -2000,3 +2019,225 @@ func TestRPCGetTransactionReceipt(t *testing.T) {
 		require.JSONEqf(t, want, have, "test %d: json not match, want: %s, have: %s", i, want, have)
 	}
 }
+
+func TestComputeContractAddress(t *testing.T) {
+	t.Parallel()
+
+	var (
+		api      = NewGoriAPI(new(testBackend))
+		deployer = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		nonce    = hexutil.Uint64(1)
+		salt     = common.HexToHash("0xcafebabe")
+		code     = hexutil.Bytes(common.FromHex("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"))
+		codeHash = crypto.Keccak256Hash(code)
+	)
+	tests := []struct {
+		args ContractAddressArgs
+		want common.Address
+		fail bool
+	}{
+		{args: ContractAddressArgs{Deployer: deployer, Nonce: &nonce}, want: crypto.CreateAddress(deployer, 1)},
+		{args: ContractAddressArgs{Deployer: deployer, Salt: &salt, InitCode: &code}, want: common.HexToAddress("0x1d8bfDC5D46DC4f61D6b6115972536eBE6A8854C")},
+		{args: ContractAddressArgs{Deployer: deployer, Salt: &salt, InitCodeHash: &codeHash}, want: common.HexToAddress("0x1d8bfDC5D46DC4f61D6b6115972536eBE6A8854C")},
+		{args: ContractAddressArgs{Deployer: deployer, Salt: &salt}, want: crypto.CreateAddress3(deployer, salt)},
+		{args: ContractAddressArgs{Deployer: deployer, Scheme: "create3", Salt: &salt, InitCode: &code}, fail: true},
+		{args: ContractAddressArgs{Deployer: deployer, Scheme: "create2", Salt: &salt}, fail: true},
+		{args: ContractAddressArgs{Deployer: deployer, Salt: &salt, InitCode: &code, InitCodeHash: &codeHash}, fail: true},
+		{args: ContractAddressArgs{Deployer: deployer, Scheme: "create4"}, fail: true},
+	}
+	for i, tt := range tests {
+		have, err := api.ComputeContractAddress(context.Background(), tt.args)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: expected failure, got %x", i, have)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+			continue
+		}
+		if have != tt.want {
+			t.Errorf("test %d: address mismatch: have %x, want %x", i, have, tt.want)
+		}
+	}
+}
+
+func TestGetStateBatch(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		contract = common.HexToAddress("0xc0de")
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				accounts[1].addr: {Balance: big.NewInt(2 * params.Ether)},
+				contract: {
+					Balance: new(big.Int),
+					Code:    []byte{byte(vm.STOP)},
+					Storage: map[common.Hash]common.Hash{{0x01}: {0x11}, {0x02}: {0x22}},
+				},
+			},
+		}
+		api    = NewBlockChainAPI(newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {}))
+		latest = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	balances, err := api.GetBalanceBatch(context.Background(), []common.Address{accounts[1].addr, contract, accounts[0].addr}, latest)
+	if err != nil {
+		t.Fatalf("failed to retrieve balances: %v", err)
+	}
+	for i, want := range []*big.Int{big.NewInt(2 * params.Ether), new(big.Int), big.NewInt(params.Ether)} {
+		if balances[i].ToInt().Cmp(want) != 0 {
+			t.Errorf("balance %d mismatch: have %v, want %v", i, balances[i], want)
+		}
+	}
+	slots := []StorageSlotArgs{
+		{Address: contract, Slot: common.Hash{0x02}.Hex()},
+		{Address: contract, Slot: "0x03"},
+		{Address: contract, Slot: common.Hash{0x01}.Hex()},
+		{Address: accounts[0].addr, Slot: "0x0"},
+	}
+	values, err := api.GetStorageAtBatch(context.Background(), slots, latest)
+	if err != nil {
+		t.Fatalf("failed to retrieve storage: %v", err)
+	}
+	for i, want := range []common.Hash{{0x22}, {}, {0x11}, {}} {
+		if common.BytesToHash(values[i]) != want {
+			t.Errorf("slot %d mismatch: have %x, want %x", i, values[i], want)
+		}
+	}
+	if _, err := api.GetStorageAtBatch(context.Background(), []StorageSlotArgs{{Address: contract, Slot: "0xzz"}}, latest); err == nil {
+		t.Errorf("expected error for invalid storage key")
+	}
+	if _, err := api.GetBalanceBatch(context.Background(), make([]common.Address, maxStateBatchSize+1), latest); err == nil {
+		t.Errorf("expected error for oversized batch")
+	}
+}
+
+func TestWarm(t *testing.T) {
+	t.Parallel()
+
+	var (
+		contract = common.HexToAddress("0xc0de")
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				contract: {
+					Balance: new(big.Int),
+					Code:    []byte{byte(vm.STOP)},
+					Storage: map[common.Hash]common.Hash{{0x01}: {0x11}},
+				},
+			},
+		}
+		backend = newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {})
+		api     = NewGoriAPI(backend)
+	)
+	res, err := api.Warm(context.Background(), []WarmArgs{
+		{Address: contract, Slots: []string{common.Hash{0x01}.Hex(), "0x02"}},
+		{Address: common.HexToAddress("0xdead")},
+	})
+	if err != nil {
+		t.Fatalf("failed to warm caches: %v", err)
+	}
+	if res.Accounts != 2 || res.Slots != 2 {
+		t.Errorf("warm result mismatch: have %d accounts, %d slots, want 2, 2", res.Accounts, res.Slots)
+	}
+	if _, err := api.Warm(context.Background(), []WarmArgs{{Address: contract, Slots: []string{"0xzz"}}}); err == nil {
+		t.Errorf("expected error for invalid storage key")
+	}
+	if _, err := api.Warm(context.Background(), make([]WarmArgs, maxWarmBatchSize+1)); err == nil {
+		t.Errorf("expected error for oversized batch")
+	}
+	// The test backend permits 100 items per second, 4 of which are used up
+	if _, err := api.Warm(context.Background(), make([]WarmArgs, 96)); err != nil {
+		t.Errorf("failed to warm caches within quota: %v", err)
+	}
+	if _, err := api.Warm(context.Background(), make([]WarmArgs, 50)); err == nil {
+		t.Errorf("expected error for exceeded quota")
+	}
+	// Pre-warming should be rejected if disabled
+	if _, err := NewGoriAPI(&backendMock{}).Warm(context.Background(), nil); err == nil {
+		t.Errorf("expected error for disabled pre-warming")
+	}
+}
+
+func TestGetStorageRange(t *testing.T) {
+	t.Parallel()
+
+	var (
+		contract = common.HexToAddress("0xc0de")
+		storage  = make(map[common.Hash]common.Hash)
+	)
+	for i := byte(1); i <= 5; i++ {
+		storage[common.Hash{i}] = common.Hash{1 + i%2}
+	}
+	var (
+		genesis = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				contract: {Balance: new(big.Int), Code: []byte{byte(vm.STOP)}, Storage: storage},
+			},
+		}
+		api    = NewBlockChainAPI(newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {}))
+		latest = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		limit  = hexutil.Uint64(2)
+	)
+	// Page through the whole storage and check it's returned in hash order
+	var (
+		args = StorageRangeArgs{Address: contract, Limit: &limit}
+		have = make(map[common.Hash]common.Hash)
+		last common.Hash
+	)
+	for pages := 1; ; pages++ {
+		res, err := api.GetStorageRange(context.Background(), args, latest)
+		if err != nil {
+			t.Fatalf("failed to retrieve storage range: %v", err)
+		}
+		for _, entry := range res.Storage {
+			if bytes.Compare(entry.Hash[:], last[:]) <= 0 {
+				t.Fatalf("storage out of order: %x after %x", entry.Hash, last)
+			}
+			if entry.Key != nil && crypto.Keccak256Hash(entry.Key[:]) != entry.Hash {
+				t.Fatalf("storage key %x mismatches hash %x", entry.Key, entry.Hash)
+			}
+			have[entry.Hash], last = entry.Value, entry.Hash
+		}
+		if res.Cursor == nil {
+			if pages != 3 {
+				t.Fatalf("page count mismatch: have %d, want 3", pages)
+			}
+			break
+		}
+		args.Cursor = res.Cursor
+	}
+	if len(have) != len(storage) {
+		t.Fatalf("storage size mismatch: have %d, want %d", len(have), len(storage))
+	}
+	for key, value := range storage {
+		if have[crypto.Keccak256Hash(key[:])] != value {
+			t.Errorf("slot %x mismatch: have %x, want %x", key, have[crypto.Keccak256Hash(key[:])], value)
+		}
+	}
+	// Filter the slots by value
+	res, err := api.GetStorageRange(context.Background(), StorageRangeArgs{Address: contract, Values: []common.Hash{{2}}}, latest)
+	if err != nil {
+		t.Fatalf("failed to retrieve filtered storage range: %v", err)
+	}
+	if len(res.Storage) != 3 || res.Cursor != nil {
+		t.Fatalf("filtered storage mismatch: have %d slots, want 3", len(res.Storage))
+	}
+	// Non-existent accounts have empty storage, invalid arguments are rejected
+	if res, err := api.GetStorageRange(context.Background(), StorageRangeArgs{Address: common.Address{0xff}}, latest); err != nil || len(res.Storage) != 0 {
+		t.Fatalf("unexpected storage of missing account: %v, %v", res, err)
+	}
+	cursor := hexutil.Bytes{0x01}
+	if _, err := api.GetStorageRange(context.Background(), StorageRangeArgs{Address: contract, Cursor: &cursor}, latest); err == nil {
+		t.Errorf("expected error for invalid cursor")
+	}
+	limit = maxStateBatchSize + 1
+	if _, err := api.GetStorageRange(context.Background(), StorageRangeArgs{Address: contract, Limit: &limit}, latest); err == nil {
+		t.Errorf("expected error for oversized limit")
+	}
+}
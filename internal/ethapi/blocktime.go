@@ -0,0 +1,106 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/rpc"
+)
+
+// BlockRange is a range of canonical block numbers, both ends inclusive.
+type BlockRange struct {
+	From hexutil.Uint64 `json:"from"`
+	To   hexutil.Uint64 `json:"to"`
+}
+
+// GetBlockByTimestamp returns the last canonical block with a timestamp at or
+// before the given one, i.e. the head of the chain at that point in time. Null is
+// returned if the timestamp predates the genesis block.
+func (s *BlockChainAPI) GetBlockByTimestamp(ctx context.Context, timestamp hexutil.Uint64, fullTx bool) (map[string]interface{}, error) {
+	after, err := searchBlockTime(ctx, s.b, s.b.CurrentHeader(), uint64(timestamp))
+	if err != nil || after == 0 {
+		return nil, err
+	}
+	block, err := s.b.BlockByNumber(ctx, rpc.BlockNumber(after-1))
+	if block == nil {
+		return nil, err
+	}
+	return s.rpcMarshalBlock(ctx, block, true, fullTx)
+}
+
+// GetBlockRangeByTimestamp returns the range of canonical blocks with timestamps
+// between the given ones (both inclusive). Null is returned if there are none.
+func (s *BlockChainAPI) GetBlockRangeByTimestamp(ctx context.Context, from hexutil.Uint64, to hexutil.Uint64) (*BlockRange, error) {
+	if from > to {
+		return nil, errors.New("invalid time range: from after to")
+	}
+	// Search both ends against the same head, even if the chain moves meanwhile
+	var (
+		head  = s.b.CurrentHeader()
+		first uint64
+		err   error
+	)
+	if from > 0 {
+		if first, err = searchBlockTime(ctx, s.b, head, uint64(from)-1); err != nil {
+			return nil, err
+		}
+	}
+	after, err := searchBlockTime(ctx, s.b, head, uint64(to))
+	if err != nil {
+		return nil, err
+	}
+	if first >= after {
+		return nil, nil
+	}
+	return &BlockRange{From: hexutil.Uint64(first), To: hexutil.Uint64(after - 1)}, nil
+}
+
+// searchBlockTime binary searches the canonical chain up to the given head for
+// the first block with a timestamp after the given one, returning its number, or
+// the number following the head if there is none.
+func searchBlockTime(ctx context.Context, b Backend, head *types.Header, timestamp uint64) (uint64, error) {
+	if head.Time <= timestamp {
+		return head.Number.Uint64() + 1, nil
+	}
+	var err error
+	n := sort.Search(int(head.Number.Uint64()), func(i int) bool {
+		if err != nil {
+			return true
+		}
+		if err = ctx.Err(); err != nil {
+			return true
+		}
+		header, herr := b.HeaderByNumber(ctx, rpc.BlockNumber(i))
+		if header == nil {
+			if err = herr; err == nil {
+				err = fmt.Errorf("header #%d not found", i)
+			}
+			return true
+		}
+		return header.Time > timestamp
+	})
+	if err != nil {
+		return 0, err
+	}
+	return uint64(n), nil
+}
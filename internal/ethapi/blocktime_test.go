@@ -0,0 +1,84 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/params"
+)
+
+// Tests that blocks are resolved by timestamp, both individually and in ranges.
+func TestBlockByTimestamp(t *testing.T) {
+	t.Parallel()
+
+	// Blocks are 10 seconds apart, block N is at 100 + 10*N
+	genesis := &core.Genesis{Config: params.TestChainConfig, Timestamp: 100}
+	api := NewBlockChainAPI(newTestBackend(t, 10, genesis, func(i int, b *core.BlockGen) {}))
+
+	blockTests := []struct {
+		time uint64
+		want int64 // -1 for none
+	}{
+		{0, -1}, {99, -1}, {100, 0}, {109, 0}, {110, 1}, {155, 5}, {199, 9}, {200, 10}, {1000, 10},
+	}
+	for _, tt := range blockTests {
+		block, err := api.GetBlockByTimestamp(context.Background(), hexutil.Uint64(tt.time), false)
+		if err != nil {
+			t.Fatalf("time %d: failed to retrieve block: %v", tt.time, err)
+		}
+		if tt.want < 0 {
+			if block != nil {
+				t.Errorf("time %d: unexpected block %v", tt.time, block["number"])
+			}
+			continue
+		}
+		if block == nil {
+			t.Errorf("time %d: block missing, want %d", tt.time, tt.want)
+		} else if have := block["number"].(*hexutil.Big).ToInt().Int64(); have != tt.want {
+			t.Errorf("time %d: block mismatch: have %d, want %d", tt.time, have, tt.want)
+		}
+	}
+	rangeTests := []struct {
+		from, to uint64
+		want     *BlockRange
+	}{
+		{0, 99, nil},
+		{0, 100, &BlockRange{0, 0}},
+		{101, 109, nil},
+		{105, 135, &BlockRange{1, 3}},
+		{110, 130, &BlockRange{1, 3}},
+		{0, 1000, &BlockRange{0, 10}},
+		{200, 1000, &BlockRange{10, 10}},
+		{201, 1000, nil},
+	}
+	for _, tt := range rangeTests {
+		have, err := api.GetBlockRangeByTimestamp(context.Background(), hexutil.Uint64(tt.from), hexutil.Uint64(tt.to))
+		if err != nil {
+			t.Fatalf("range %d-%d: failed to retrieve range: %v", tt.from, tt.to, err)
+		}
+		if (have == nil) != (tt.want == nil) || (have != nil && *have != *tt.want) {
+			t.Errorf("range %d-%d: mismatch: have %v, want %v", tt.from, tt.to, have, tt.want)
+		}
+	}
+	if _, err := api.GetBlockRangeByTimestamp(context.Background(), 200, 100); err == nil {
+		t.Errorf("inverted range accepted")
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/rpc"
+	"golang.org/x/time/rate"
+)
+
+// GoriAPI provides gori specific helpers that are not part of the standard eth
+// namespace.
+type GoriAPI struct {
+	b    Backend
+	warm *rate.Limiter // Quota of the accounts and slots to pre-warm, nil if disabled
+}
+
+// NewGoriAPI creates a new gori specific API.
+func NewGoriAPI(b Backend) *GoriAPI {
+	api := &GoriAPI{b: b}
+	if quota := b.RPCWarmQuota(); quota > 0 {
+		api.warm = rate.NewLimiter(rate.Limit(quota), int(quota))
+	}
+	return api
+}
+
+// ContractAddressArgs represents the arguments to derive the address of a contract
+// deployment. The scheme defaults to CREATE2 if a salt and init code are given, to
+// CREATE3 if only a salt is given and to CREATE otherwise.
+type ContractAddressArgs struct {
+	Deployer     common.Address  `json:"deployer"`
+	Scheme       string          `json:"scheme"`
+	Nonce        *hexutil.Uint64 `json:"nonce"`
+	Salt         *common.Hash    `json:"salt"`
+	InitCode     *hexutil.Bytes  `json:"initCode"`
+	InitCodeHash *common.Hash    `json:"initCodeHash"`
+}
+
+// ComputeContractAddress returns the address a contract deployed by the given
+// account ends up at. For CREATE deployments without an explicit nonce, the next
+// nonce of the deployer is taken from the pending state.
+func (api *GoriAPI) ComputeContractAddress(ctx context.Context, args ContractAddressArgs) (common.Address, error) {
+	hasCode := args.InitCode != nil || args.InitCodeHash != nil
+	if args.InitCode != nil && args.InitCodeHash != nil {
+		return common.Address{}, errors.New(`both "initCode" and "initCodeHash" specified`)
+	}
+	scheme := args.Scheme
+	if scheme == "" {
+		switch {
+		case args.Salt != nil && hasCode:
+			scheme = "create2"
+		case args.Salt != nil:
+			scheme = "create3"
+		default:
+			scheme = "create"
+		}
+	}
+	switch scheme {
+	case "create":
+		if args.Salt != nil || hasCode {
+			return common.Address{}, errors.New(`CREATE addresses do not depend on "salt" or the init code`)
+		}
+		if args.Nonce != nil {
+			return crypto.CreateAddress(args.Deployer, uint64(*args.Nonce)), nil
+		}
+		nonce, err := api.b.GetPoolNonce(ctx, args.Deployer)
+		if err != nil {
+			return common.Address{}, err
+		}
+		return crypto.CreateAddress(args.Deployer, nonce), nil
+
+	case "create2":
+		if args.Salt == nil || !hasCode {
+			return common.Address{}, errors.New(`CREATE2 addresses require "salt" and "initCode" or "initCodeHash"`)
+		}
+		hash := args.InitCodeHash
+		if hash == nil {
+			h := crypto.Keccak256Hash(*args.InitCode)
+			hash = &h
+		}
+		return crypto.CreateAddress2(args.Deployer, *args.Salt, hash[:]), nil
+
+	case "create3":
+		if args.Salt == nil {
+			return common.Address{}, errors.New(`CREATE3 addresses require "salt"`)
+		}
+		if hasCode {
+			return common.Address{}, errors.New("CREATE3 addresses do not depend on the init code")
+		}
+		return crypto.CreateAddress3(args.Deployer, *args.Salt), nil
+
+	default:
+		return common.Address{}, fmt.Errorf("unknown deployment scheme %q", scheme)
+	}
+}
+
+// maxWarmBatchSize is the maximum number of accounts and storage slots that can
+// be pre-warmed in a single request.
+const maxWarmBatchSize = 4096
+
+// WarmArgs represents an account to pre-warm into the caches of the node, along
+// with the storage slots of it to pre-warm.
+type WarmArgs struct {
+	Address common.Address `json:"address"`
+	Slots   []string       `json:"slots"`
+}
+
+// WarmResult is the outcome of pre-warming the caches of the node.
+type WarmResult struct {
+	Accounts hexutil.Uint64 `json:"accounts"`
+	Slots    hexutil.Uint64 `json:"slots"`
+}
+
+// Warm loads the given accounts, their code and the given storage slots from the
+// latest state into the caches of the node, to speed up subsequent requests
+// touching them (e.g. ahead of an expected burst of calls). The number of items
+// that can be pre-warmed is subject to a node-wide quota; requests exceeding it
+// are rejected as a whole.
+func (api *GoriAPI) Warm(ctx context.Context, args []WarmArgs) (*WarmResult, error) {
+	if api.warm == nil {
+		return nil, errors.New("cache pre-warming is disabled")
+	}
+	// Decode all the keys before charging the quota to fail fast on bad input
+	var (
+		items = len(args)
+		keys  = make([][]common.Hash, len(args))
+	)
+	for i, arg := range args {
+		keys[i] = make([]common.Hash, len(arg.Slots))
+		for j, slot := range arg.Slots {
+			key, _, err := decodeHash(slot)
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode storage key %d of account %d: %s", j, i, err)
+			}
+			keys[i][j] = key
+		}
+		items += len(arg.Slots)
+	}
+	if items > maxWarmBatchSize {
+		return nil, fmt.Errorf("too many items requested: have %d, max %d", items, maxWarmBatchSize)
+	}
+	if !api.warm.AllowN(time.Now(), items) {
+		return nil, errors.New("cache pre-warming quota exceeded")
+	}
+	state, header, err := api.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// Reading the accounts, code and slots pulls them through the shared state
+	// caches (snapshot, trie node and code caches) of the node.
+	result := new(WarmResult)
+	for i, arg := range args {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if state.Exist(arg.Address) {
+			state.GetCode(arg.Address)
+		}
+		result.Accounts++
+
+		for _, key := range keys[i] {
+			state.GetState(arg.Address, key)
+			result.Slots++
+		}
+	}
+	if err := state.Error(); err != nil {
+		return nil, err
+	}
+	log.Debug("Pre-warmed state caches", "number", header.Number, "accounts", result.Accounts, "slots", result.Slots)
+	return result, nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package gethcompat implements a compatibility layer easing the migration of
+// tooling written against the RPC API of upstream go-ethereum.
+package gethcompat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gorievm/go-gori/eth/protocols/eth"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/node"
+	"github.com/gorievm/go-gori/p2p"
+	"github.com/gorievm/go-gori/rpc"
+	"golang.org/x/exp/slices"
+)
+
+// Kinds of divergences between the RPC API of the node and the one of geth.
+const (
+	KindMissing   = "missing"   // Method served by geth but not by the node
+	KindChanged   = "changed"   // Method served by both, with a different result
+	KindExtension = "extension" // Method served by the node but not by geth
+)
+
+// Divergence describes a difference between the RPC API of the node and the one
+// of upstream geth.
+type Divergence struct {
+	Method string `json:"method"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+	Shim   string `json:"shim,omitempty"`   // Method serving the geth result, if any
+	NoShim string `json:"noShim,omitempty"` // Reason for not providing a shim
+}
+
+// DivergenceError is returned in strict mode for calls to geth methods that are
+// not available on the called endpoint, in place of the generic method not found
+// error. It retains the error code used by geth.
+type DivergenceError struct {
+	Method    string // Name of the called method
+	Supported bool   // Whether the node serves the method on other endpoints
+}
+
+// Error implements error.
+func (e *DivergenceError) Error() string {
+	if e.Supported {
+		namespace := strings.SplitN(e.Method, "_", 2)[0]
+		return fmt.Sprintf("the method %s is not available on this endpoint, enable the %s namespace", e.Method, namespace)
+	}
+	return fmt.Sprintf("the method %s is provided by geth but not supported by this node", e.Method)
+}
+
+// ErrorCode implements rpc.Error.
+func (e *DivergenceError) ErrorCode() int { return -32601 }
+
+// ErrorData implements rpc.DataError, describing the divergence.
+func (e *DivergenceError) ErrorData() interface{} {
+	if e.Supported {
+		return nil
+	}
+	return &Divergence{Method: e.Method, Kind: KindMissing}
+}
+
+// API exposes the divergences between the node and geth in the gethcompat
+// namespace.
+type API struct {
+	stack *node.Node
+}
+
+// Divergences returns the differences between the RPC API served by the node
+// and the one of geth, within the geth specific namespaces.
+func (api *API) Divergences() ([]Divergence, error) {
+	server, err := api.stack.RPCHandler()
+	if err != nil {
+		return nil, err
+	}
+	return divergences(server.Methods()), nil
+}
+
+// Peers is the shim of admin_peers, returning the connected peers as geth would:
+// Gori-only eth versions are reported as the upstream version they extend, and
+// the eth protocol entries are stripped of the fields geth doesn't serve.
+func (api *API) Peers() ([]*p2p.PeerInfo, error) {
+	server := api.stack.Server()
+	if server == nil {
+		return nil, node.ErrNodeStopped
+	}
+	infos := server.PeersInfo()
+	for _, info := range infos {
+		if err := gethPeerInfo(info); err != nil {
+			return nil, err
+		}
+	}
+	return infos, nil
+}
+
+// gethPeerInfo converts the metadata of a peer into the format served by geth.
+func gethPeerInfo(info *p2p.PeerInfo) error {
+	var (
+		gori = fmt.Sprintf("%s/%d", eth.ProtocolName, eth.GORI69)
+		geth = fmt.Sprintf("%s/%d", eth.ProtocolName, eth.ETH69)
+		caps = make([]string, 0, len(info.Caps))
+	)
+	for _, cap := range info.Caps {
+		if cap == gori {
+			cap = geth
+		}
+		if !slices.Contains(caps, cap) {
+			caps = append(caps, cap)
+		}
+	}
+	info.Caps = caps
+
+	proto, ok := info.Protocols[eth.ProtocolName]
+	if !ok || proto == nil {
+		return nil
+	}
+	blob, err := json.Marshal(proto)
+	if err != nil {
+		return err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(blob, &fields); err != nil {
+		return err
+	}
+	delete(fields, "capabilities")
+	delete(fields, "reputation")
+	if version, ok := fields["version"].(float64); ok && uint(version) == eth.GORI69 {
+		fields["version"] = eth.ETH69
+	}
+	info.Protocols[eth.ProtocolName] = fields
+	return nil
+}
+
+// Register adds the gethcompat namespace to the node. If strict is set, calls to
+// geth methods not available on an endpoint are answered with a DivergenceError.
+func Register(stack *node.Node, strict bool) {
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace: "gethcompat",
+		Service:   &API{stack},
+	}})
+	if strict {
+		stack.SetMethodNotFoundHandler(func(method string) error {
+			if !isGethMethod(method) {
+				return nil
+			}
+			var supported bool
+			if server, err := stack.RPCHandler(); err == nil {
+				supported = slices.Contains(server.Methods(), method)
+			}
+			if !supported {
+				log.Debug("Called geth method not supported by node", "method", method)
+			}
+			return &DivergenceError{Method: method, Supported: supported}
+		})
+	}
+	log.Info("Enabled geth API compatibility layer", "strict", strict)
+}
+
+// divergences compares the given served methods against the geth ones.
+func divergences(served []string) []Divergence {
+	var (
+		result []Divergence
+		known  = make(map[string]bool)
+	)
+	for namespace, methods := range gethMethods {
+		for _, method := range methods {
+			name := namespace + "_" + method
+			known[name] = true
+
+			switch {
+			case !slices.Contains(served, name):
+				result = append(result, Divergence{Method: name, Kind: KindMissing})
+			case changedMethods[name].detail != "":
+				change := changedMethods[name]
+				result = append(result, Divergence{Method: name, Kind: KindChanged, Detail: change.detail, Shim: change.shim, NoShim: change.noShim})
+			}
+		}
+	}
+	for _, name := range served {
+		namespace := strings.SplitN(name, "_", 2)[0]
+		if _, ok := gethMethods[namespace]; ok && !known[name] {
+			result = append(result, Divergence{Method: name, Kind: KindExtension})
+		}
+	}
+	slices.SortFunc(result, func(a, b Divergence) int { return strings.Compare(a.Method, b.Method) })
+	return result
+}
+
+// isGethMethod reports whether the given method is served by geth.
+func isGethMethod(name string) bool {
+	parts := strings.SplitN(name, "_", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	return slices.Contains(gethMethods[parts[0]], parts[1])
+}
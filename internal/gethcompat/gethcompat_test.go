@@ -0,0 +1,151 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gethcompat
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/eth/protocols/eth"
+	"github.com/gorievm/go-gori/node"
+	"github.com/gorievm/go-gori/p2p"
+	"github.com/gorievm/go-gori/rpc"
+	"golang.org/x/exp/slices"
+)
+
+// testTxPool serves a subset of the geth txpool namespace, plus an extension.
+type testTxPool struct{}
+
+func (p *testTxPool) Status() map[string]int { return map[string]int{"pending": 0} }
+func (p *testTxPool) Content() []string      { return nil }
+func (p *testTxPool) ContentFrom() []string  { return nil }
+func (p *testTxPool) Locals() []string       { return nil }
+
+func newTestNode(t *testing.T, strict bool) *node.Node {
+	key, _ := crypto.GenerateKey()
+	stack, err := node.New(&node.Config{P2P: p2p.Config{PrivateKey: key}})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	stack.RegisterAPIs([]rpc.API{{Namespace: "txpool", Service: new(testTxPool)}})
+	Register(stack, strict)
+
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	t.Cleanup(func() { stack.Close() })
+	return stack
+}
+
+func TestDivergences(t *testing.T) {
+	client := newTestNode(t, false).Attach()
+	defer client.Close()
+
+	var divergences []Divergence
+	if err := client.Call(&divergences, "gethcompat_divergences"); err != nil {
+		t.Fatalf("failed to retrieve divergences: %v", err)
+	}
+	kinds := make(map[string]string)
+	for _, d := range divergences {
+		kinds[d.Method] = d.Kind
+	}
+	for _, d := range divergences {
+		if d.Method == "admin_peers" && d.Shim != "gethcompat_peers" {
+			t.Errorf("admin_peers shim mismatch: have %q, want %q", d.Shim, "gethcompat_peers")
+		}
+	}
+	for method, kind := range map[string]string{
+		"txpool_inspect":  KindMissing,
+		"txpool_locals":   KindExtension,
+		"admin_peers":     KindChanged,
+		"debug_traceCall": KindMissing, // tracers are not registered on the test node
+	} {
+		if kinds[method] != kind {
+			t.Errorf("divergence of %s mismatch: have %q, want %q", method, kinds[method], kind)
+		}
+	}
+	for _, method := range []string{"txpool_status", "admin_nodeInfo"} {
+		if kind, ok := kinds[method]; ok {
+			t.Errorf("compatible method %s reported as %s", method, kind)
+		}
+	}
+}
+
+// Tests that every changed method either has a shim served by the node, or a
+// reason for not having one.
+func TestChangedMethodShims(t *testing.T) {
+	server, err := newTestNode(t, false).RPCHandler()
+	if err != nil {
+		t.Fatalf("failed to retrieve handler: %v", err)
+	}
+	served := server.Methods()
+	for method, change := range changedMethods {
+		switch {
+		case (change.shim == "") == (change.noShim == ""):
+			t.Errorf("%s: need either a shim or a reason for none", method)
+		case change.shim != "" && !slices.Contains(served, change.shim):
+			t.Errorf("%s: shim %s not served", method, change.shim)
+		}
+	}
+}
+
+// Tests that peer metadata is converted into the format served by geth.
+func TestGethPeerInfo(t *testing.T) {
+	info := &p2p.PeerInfo{
+		Caps: []string{"eth/68", "eth/69", "eth/1069", "snap/1"},
+		Protocols: map[string]interface{}{
+			"eth": map[string]interface{}{
+				"version":      eth.GORI69,
+				"capabilities": map[string]string{"forks": "0x01"},
+				"reputation":   10,
+			},
+			"snap": map[string]interface{}{"version": 1},
+		},
+	}
+	if err := gethPeerInfo(info); err != nil {
+		t.Fatalf("failed to convert peer info: %v", err)
+	}
+	if want := []string{"eth/68", "eth/69", "snap/1"}; !slices.Equal(info.Caps, want) {
+		t.Errorf("caps mismatch: have %v, want %v", info.Caps, want)
+	}
+	have, _ := json.Marshal(info.Protocols)
+	if want := `{"eth":{"version":69},"snap":{"version":1}}`; string(have) != want {
+		t.Errorf("protocols mismatch: have %s, want %s", have, want)
+	}
+}
+
+func TestStrictDivergenceErrors(t *testing.T) {
+	client := newTestNode(t, true).Attach()
+	defer client.Close()
+
+	// Geth methods not served by the node should report the divergence
+	err := client.Call(nil, "txpool_inspect")
+	var rerr rpc.Error
+	if !errors.As(err, &rerr) || rerr.ErrorCode() != -32601 {
+		t.Fatalf("divergence error code mismatch: have %v", err)
+	}
+	if want := (&DivergenceError{Method: "txpool_inspect"}).Error(); err.Error() != want {
+		t.Fatalf("divergence error mismatch: have %q, want %q", err, want)
+	}
+	// Methods unknown to geth should keep the generic error
+	err = client.Call(nil, "txpool_unknown")
+	if err == nil || err.Error() != "the method txpool_unknown does not exist/is not available" {
+		t.Fatalf("generic error mismatch: have %v", err)
+	}
+}
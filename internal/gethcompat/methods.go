@@ -0,0 +1,79 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gethcompat
+
+// gethMethods lists the methods of the geth specific RPC namespaces tooling
+// commonly relies on, as served by go-ethereum v1.12.2 which this node derives
+// from. Subscriptions are not included.
+var gethMethods = map[string][]string{
+	"admin": {
+		"addPeer", "addTrustedPeer", "datadir", "exportChain", "importChain",
+		"nodeInfo", "peers", "removePeer", "removeTrustedPeer", "startHTTP",
+		"startRPC", "startWS", "stopHTTP", "stopRPC", "stopWS",
+	},
+	"debug": {
+		// Runtime profiling and logging
+		"blockProfile", "cpuProfile", "freeOSMemory", "gcStats", "goTrace",
+		"memStats", "mutexProfile", "setBlockProfileRate", "setGCPercent",
+		"setMutexProfileFraction", "stacks", "startCPUProfile", "startGoTrace",
+		"stopCPUProfile", "stopGoTrace", "verbosity", "vmodule",
+		"writeBlockProfile", "writeMemProfile", "writeMutexProfile",
+
+		// Chain and database inspection
+		"accountRange", "chaindbCompact", "chaindbProperty", "dbAncient",
+		"dbAncients", "dbGet", "dumpBlock", "getAccessibleState", "getBadBlocks",
+		"getModifiedAccountsByHash", "getModifiedAccountsByNumber", "getRawBlock",
+		"getRawHeader", "getRawReceipts", "getRawTransaction",
+		"getTrieFlushInterval", "preimage", "printBlock", "setHead",
+		"setTrieFlushInterval", "storageRangeAt",
+
+		// Tracing
+		"intermediateRoots", "standardTraceBadBlockToFile",
+		"standardTraceBlockToFile", "traceBadBlock", "traceBlock",
+		"traceBlockByHash", "traceBlockByNumber", "traceBlockFromFile",
+		"traceCall", "traceChain", "traceTransaction",
+	},
+	"txpool": {
+		"content", "contentFrom", "inspect", "status",
+	},
+}
+
+// changedMethod describes how the result of a geth method served by the node
+// differs from the one returned by geth. Every change either has a shim serving
+// the geth result in the gethcompat namespace, or a reason why none is provided.
+type changedMethod struct {
+	detail string // Description of the difference
+	shim   string // Method returning the result in the geth format, if any
+	noShim string // Reason for not providing a shim
+}
+
+// changedMethods lists the geth methods whose results differ in shape from the
+// ones returned by geth.
+var changedMethods = map[string]changedMethod{
+	"admin_peers": {
+		detail: "the eth protocol entries carry an additional reputation field; peers on the Gori-only eth/1069 version report it as their version and capability, and carry a capabilities field with their status extensions",
+		shim:   "gethcompat_peers",
+	},
+	"txpool_content": {
+		detail: "set code transactions (type 4) carry an additional authorizationList field",
+		noShim: "geth does not know the transaction type, dropping the authorizations would leave the transactions unverifiable",
+	},
+	"txpool_contentFrom": {
+		detail: "set code transactions (type 4) carry an additional authorizationList field",
+		noShim: "geth does not know the transaction type, dropping the authorizations would leave the transactions unverifiable",
+	},
+}
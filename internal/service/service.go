@@ -0,0 +1,54 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package service integrates the node with the service manager of the host
+// operating system: the Windows service control manager, or systemd through its
+// sd_notify protocol.
+package service
+
+import "sync"
+
+var (
+	stoppingOnce sync.Once
+	stoppedOnce  sync.Once
+)
+
+// Start prepares the integration with the service manager. It should be called
+// as early as possible, since the Windows service control manager expects the
+// process to connect to it shortly after launch. The name is the name of the
+// service as registered with the service manager.
+func Start(name string) error {
+	return start(name)
+}
+
+// Ready reports to the service manager that the node finished starting up. The
+// stop callback is invoked if the service manager requests the node to stop
+// through some channel other than a signal.
+func Ready(stop func()) error {
+	return ready(stop)
+}
+
+// Stopping reports to the service manager that the node is shutting down.
+func Stopping() {
+	stoppingOnce.Do(stopping)
+}
+
+// Stopped reports to the service manager that the node shut down. The process
+// is expected to exit shortly after.
+func Stopped() {
+	Stopping()
+	stoppedOnce.Do(stopped)
+}
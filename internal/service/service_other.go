@@ -0,0 +1,89 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !windows
+// +build !windows
+
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorievm/go-gori/log"
+)
+
+var (
+	watchdogLock sync.Mutex
+	watchdogQuit chan struct{} // Closed to stop the watchdog keepalive loop
+)
+
+func start(name string) error {
+	return nil // systemd needs no setup, stopping is signalled via SIGTERM
+}
+
+func ready(stop func()) error {
+	if ok, err := sdNotify("READY=1"); !ok || err != nil {
+		return err
+	}
+	log.Info("Notified systemd of node readiness")
+
+	// Keep the systemd watchdog satisfied if it's enabled
+	if interval := sdWatchdogInterval(); interval > 0 {
+		watchdogLock.Lock()
+		defer watchdogLock.Unlock()
+
+		if watchdogQuit == nil {
+			watchdogQuit = make(chan struct{})
+			go keepalive(interval/2, watchdogQuit)
+		}
+	}
+	return nil
+}
+
+// keepalive pings the systemd watchdog at the given interval until stopped.
+func keepalive(interval time.Duration, quit chan struct{}) {
+	log.Debug("Started systemd watchdog keepalive", "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := sdNotify("WATCHDOG=1"); err != nil {
+				log.Warn("Failed to ping systemd watchdog", "err", err)
+			}
+		case <-quit:
+			return
+		}
+	}
+}
+
+func stopping() {
+	watchdogLock.Lock()
+	if watchdogQuit != nil {
+		close(watchdogQuit)
+		watchdogQuit = nil
+	}
+	watchdogLock.Unlock()
+
+	if _, err := sdNotify("STOPPING=1"); err != nil {
+		log.Warn("Failed to notify systemd of shutdown", "err", err)
+	}
+}
+
+func stopped() {}
@@ -0,0 +1,92 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !windows
+// +build !windows
+
+package service
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Tests that readiness, watchdog keepalives and shutdown are reported to the
+// systemd notification socket.
+func TestSystemdNotify(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("failed to create notify socket: %v", err)
+	}
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", socket)
+	t.Setenv("WATCHDOG_USEC", "20000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+
+	read := func() string {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		buf := make([]byte, 64)
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("failed to read notification: %v", err)
+		}
+		return string(buf[:n])
+	}
+	if err := ready(nil); err != nil {
+		t.Fatalf("failed to notify readiness: %v", err)
+	}
+	if msg := read(); msg != "READY=1" {
+		t.Fatalf("readiness notification mismatch: have %q, want %q", msg, "READY=1")
+	}
+	if msg := read(); msg != "WATCHDOG=1" {
+		t.Fatalf("watchdog notification mismatch: have %q, want %q", msg, "WATCHDOG=1")
+	}
+	stopping()
+
+	// Drain any keepalive sent before the watchdog was stopped
+	for {
+		msg := read()
+		if msg == "STOPPING=1" {
+			break
+		}
+		if msg != "WATCHDOG=1" {
+			t.Fatalf("unexpected notification: %q", msg)
+		}
+	}
+}
+
+// Tests that the watchdog interval is only honoured if meant for this process.
+func TestSystemdWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "3000000")
+	t.Setenv("WATCHDOG_PID", "")
+	if interval := sdWatchdogInterval(); interval != 3*time.Second {
+		t.Fatalf("interval mismatch: have %v, want %v", interval, 3*time.Second)
+	}
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+	if interval := sdWatchdogInterval(); interval != 0 {
+		t.Fatalf("foreign watchdog honoured: have %v", interval)
+	}
+	t.Setenv("WATCHDOG_USEC", "")
+	if interval := sdWatchdogInterval(); interval != 0 {
+		t.Fatalf("disabled watchdog honoured: have %v", interval)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"github.com/gorievm/go-gori/log"
+	"golang.org/x/sys/windows/svc"
+)
+
+// winService is the handler registered with the service control manager, nil
+// if the process is not running as a Windows service.
+var winService *handler
+
+// handler implements svc.Handler, translating between the node lifecycle and the
+// state transitions expected by the service control manager.
+type handler struct {
+	ready    chan func()   // Delivers the stop callback once the node is running
+	stopping chan struct{} // Closed when the node starts shutting down
+	stopped  chan struct{} // Closed when the node finished shutting down
+}
+
+func start(name string) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return err
+	}
+	winService = &handler{
+		ready:    make(chan func(), 1),
+		stopping: make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go func() {
+		if err := svc.Run(name, winService); err != nil {
+			log.Error("Windows service dispatcher failed", "err", err)
+		}
+	}()
+	return nil
+}
+
+// Execute implements svc.Handler, running until the node shuts down.
+func (h *handler) Execute(args []string, r <-chan svc.ChangeRequest, s chan<- svc.Status) (bool, uint32) {
+	const accepts = svc.AcceptStop | svc.AcceptShutdown
+
+	s <- svc.Status{State: svc.StartPending}
+
+	var (
+		stop      func()
+		requested bool // Whether a stop was requested before the node was ready
+		stopping  = h.stopping
+	)
+	for {
+		select {
+		case stop = <-h.ready:
+			if requested {
+				stop()
+				continue
+			}
+			s <- svc.Status{State: svc.Running, Accepts: accepts}
+
+		case <-stopping:
+			s <- svc.Status{State: svc.StopPending}
+			stopping = nil
+
+		case <-h.stopped:
+			return false, 0
+
+		case req := <-r:
+			switch req.Cmd {
+			case svc.Interrogate:
+				s <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				log.Info("Stop requested by the service control manager")
+				if stop != nil {
+					stop()
+				} else {
+					requested = true
+				}
+			}
+		}
+	}
+}
+
+func ready(stop func()) error {
+	if winService != nil {
+		winService.ready <- stop
+	}
+	return nil
+}
+
+func stopping() {
+	if winService != nil {
+		close(winService.stopping)
+	}
+}
+
+func stopped() {
+	if winService != nil {
+		close(winService.stopped)
+	}
+}
@@ -45,8 +45,10 @@ func NewShutdownTracker(db ethdb.Database) *ShutdownTracker {
 // MarkStartup is to be called in the beginning when the node starts. It will:
 // - Push a new startup marker to the db
 // - Report previous unclean shutdowns
-func (t *ShutdownTracker) MarkStartup() {
-	if uncleanShutdowns, discards, err := rawdb.PushUncleanShutdownMarker(t.db); err != nil {
+// - Return whether the last run ended with an unclean shutdown
+func (t *ShutdownTracker) MarkStartup() bool {
+	uncleanShutdowns, discards, unclean, err := rawdb.PushUncleanShutdownMarker(t.db)
+	if err != nil {
 		log.Error("Could not update unclean-shutdown-marker list", "error", err)
 	} else {
 		if discards > 0 {
@@ -58,6 +60,7 @@ func (t *ShutdownTracker) MarkStartup() {
 				"age", common.PrettyAge(t))
 		}
 	}
+	return unclean
 }
 
 // Start runs an event loop that updates the current marker's timestamp every 5 minutes.
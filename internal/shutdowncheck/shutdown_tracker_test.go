@@ -0,0 +1,54 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package shutdowncheck
+
+import (
+	"testing"
+
+	"github.com/gorievm/go-gori/core/rawdb"
+)
+
+// Tests that only the startup right after an unclean shutdown reports it, not
+// every later one while the old markers are kept around.
+func TestMarkStartupUnclean(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+
+	// A fresh database and a clean shutdown should not be reported
+	tracker := NewShutdownTracker(db)
+	if tracker.MarkStartup() {
+		t.Fatalf("fresh database reported unclean shutdown")
+	}
+	tracker.Start()
+	tracker.Stop()
+
+	tracker = NewShutdownTracker(db)
+	if tracker.MarkStartup() {
+		t.Fatalf("clean shutdown reported as unclean")
+	}
+	// Crash without stopping the tracker, the next startup should report it once
+	tracker = NewShutdownTracker(db)
+	if !tracker.MarkStartup() {
+		t.Fatalf("unclean shutdown not detected")
+	}
+	tracker.Start()
+	tracker.Stop()
+
+	tracker = NewShutdownTracker(db)
+	if tracker.MarkStartup() {
+		t.Fatalf("old unclean shutdown reported again")
+	}
+}
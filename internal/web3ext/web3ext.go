@@ -31,6 +31,7 @@ var Modules = map[string]string{
 	"les":      LESJs,
 	"vflux":    VfluxJs,
 	"dev":      DevJs,
+	"gori":     GoriJs,
 }
 
 const CliqueJs = `
@@ -153,6 +154,16 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'txPropagation',
+			call: 'admin_txPropagation',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'setTxPropagation',
+			call: 'admin_setTxPropagation',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -448,6 +459,16 @@ web3._extend({
 			call: 'debug_getBadBlocks',
 			params: 0,
 		}),
+		new web3._extend.Method({
+			name: 'getBadBlockReport',
+			call: 'debug_getBadBlockReport',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'exportBadBlock',
+			call: 'debug_exportBadBlock',
+			params: 1,
+		}),
 		new web3._extend.Method({
 			name: 'storageRangeAt',
 			call: 'debug_storageRangeAt',
@@ -470,6 +491,30 @@ web3._extend({
 			call: 'debug_freezeClient',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'newStateRoot',
+			call: 'debug_newStateRoot',
+		}),
+		new web3._extend.Method({
+			name: 'feedStateRoot',
+			call: 'debug_feedStateRoot',
+			params: 2,
+		}),
+		new web3._extend.Method({
+			name: 'stateRootProgress',
+			call: 'debug_stateRootProgress',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'computeStateRoot',
+			call: 'debug_computeStateRoot',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'discardStateRoot',
+			call: 'debug_discardStateRoot',
+			params: 1,
+		}),
 		new web3._extend.Method({
 			name: 'getAccessibleState',
 			call: 'debug_getAccessibleState',
@@ -501,6 +546,37 @@ web3._extend({
 			call: 'debug_getTrieFlushInterval',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'executionWitness',
+			call: 'debug_executionWitness',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'blockImportStats',
+			call: 'debug_blockImportStats',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'recentImportStats',
+			call: 'debug_recentImportStats',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'snapshotGeneration',
+			call: 'debug_snapshotGeneration',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'poolSnapshots',
+			call: 'debug_poolSnapshots',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'poolSnapshot',
+			call: 'debug_poolSnapshot',
+			params: 1
+		}),
 	],
 	properties: []
 });
@@ -527,6 +603,16 @@ web3._extend({
 			params: 3,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
 		}),
+		new web3._extend.Method({
+			name: 'sendBundle',
+			call: 'eth_sendBundle',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'sendRawTransactionConditional',
+			call: 'eth_sendRawTransactionConditional',
+			params: 2,
+		}),
 		new web3._extend.Method({
 			name: 'signTransaction',
 			call: 'eth_signTransaction',
@@ -552,6 +638,18 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getBlockByTimestamp',
+			call: 'eth_getBlockByTimestamp',
+			params: 2,
+			inputFormatter: [web3._extend.utils.fromDecimal, function (val) { return !!val; }]
+		}),
+		new web3._extend.Method({
+			name: 'getBlockRangeByTimestamp',
+			call: 'eth_getBlockRangeByTimestamp',
+			params: 2,
+			inputFormatter: [web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
 		new web3._extend.Method({
 			name: 'getHeaderByNumber',
 			call: 'eth_getHeaderByNumber',
@@ -611,12 +709,36 @@ web3._extend({
 			call: 'eth_getLogs',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'getLogsPage',
+			call: 'eth_getLogsPage',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'call',
 			call: 'eth_call',
 			params: 4,
 			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter, null, null],
 		}),
+		new web3._extend.Method({
+			name: 'getBalanceBatch',
+			call: 'eth_getBalanceBatch',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getStorageAtBatch',
+			call: 'eth_getStorageAtBatch',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getStorageRange',
+			call: 'eth_getStorageRange',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -799,6 +921,11 @@ web3._extend({
 			call: 'txpool_contentFrom',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'inspectFrom',
+			call: 'txpool_inspectFrom',
+			params: 1,
+		}),
 	]
 });
 `
@@ -903,6 +1030,48 @@ web3._extend({
 			call: 'dev_setFeeRecipient',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'scheduleWithdrawal',
+			call: 'dev_scheduleWithdrawal',
+			params: 2,
+			inputFormatter: [null, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'setSlotTime',
+			call: 'dev_setSlotTime',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'missSlots',
+			call: 'dev_missSlots',
+			params: 1
+		}),
+	],
+	properties:
+	[
+		new web3._extend.Property({
+			name: 'scheduledWithdrawals',
+			getter: 'dev_scheduledWithdrawals'
+		}),
+	],
+});
+`
+
+const GoriJs = `
+web3._extend({
+	property: 'gori',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'computeContractAddress',
+			call: 'gori_computeContractAddress',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'warm',
+			call: 'gori_warm',
+			params: 1
+		}),
 	],
 });
 `
@@ -28,8 +28,10 @@ import (
 	"github.com/gorievm/go-gori/consensus"
 	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/bloombits"
+	"github.com/gorievm/go-gori/core/filtermaps"
 	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/core/state"
+	"github.com/gorievm/go-gori/core/txpool"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/core/vm"
 	"github.com/gorievm/go-gori/eth/gasprice"
@@ -199,6 +201,14 @@ func (b *LesApiBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.eth.txPool.Add(ctx, signedTx)
 }
 
+func (b *LesApiBackend) SendBundle(ctx context.Context, bundle *txpool.Bundle) error {
+	return errors.New("transaction bundles are not supported in light mode")
+}
+
+func (b *LesApiBackend) SendConditionalTx(ctx context.Context, signedTx *types.Transaction, cond txpool.Condition) error {
+	return errors.New("conditional transactions are not supported in light mode")
+}
+
 func (b *LesApiBackend) RemoveTx(txHash common.Hash) {
 	b.eth.txPool.RemoveTx(txHash)
 }
@@ -231,10 +241,30 @@ func (b *LesApiBackend) TxPoolContentFrom(addr common.Address) ([]*types.Transac
 	return b.eth.txPool.ContentFrom(addr)
 }
 
+func (b *LesApiBackend) TxPoolInspectFrom(ctx context.Context, addr common.Address) (*txpool.SenderInspection, error) {
+	pending, queued := b.eth.txPool.ContentFrom(addr)
+
+	var nonce uint64
+	if len(pending) == 0 {
+		var err error
+		if nonce, err = b.eth.txPool.GetNonce(ctx, addr); err != nil {
+			return nil, err
+		}
+	}
+	return txpool.NewSenderInspection(nonce, pending, queued), nil
+}
+
 func (b *LesApiBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.txPool.SubscribeNewTxsEvent(ch)
 }
 
+func (b *LesApiBackend) SubscribeDropTxsEvent(ch chan<- core.DropTxsEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
 func (b *LesApiBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return b.eth.blockchain.SubscribeChainEvent(ch)
 }
@@ -302,10 +332,18 @@ func (b *LesApiBackend) RPCEVMTimeout() time.Duration {
 	return b.eth.config.RPCEVMTimeout
 }
 
+func (b *LesApiBackend) RPCWarmQuota() uint64 {
+	return b.eth.config.RPCWarmQuota
+}
+
 func (b *LesApiBackend) RPCTxFeeCap() float64 {
 	return b.eth.config.RPCTxFeeCap
 }
 
+func (b *LesApiBackend) RPCExtendedReceipts() bool {
+	return false // Light clients don't execute transactions
+}
+
 func (b *LesApiBackend) BloomStatus() (uint64, uint64) {
 	if b.eth.bloomIndexer == nil {
 		return 0, 0
@@ -320,6 +358,14 @@ func (b *LesApiBackend) ServiceFilter(ctx context.Context, session *bloombits.Ma
 	}
 }
 
+func (b *LesApiBackend) LogIndex() *filtermaps.FilterMaps {
+	return nil // Light clients rely on the bloom trie
+}
+
+func (b *LesApiBackend) HistoryPruningCutoff() uint64 {
+	return 0 // History is retrieved on demand from the servers
+}
+
 func (b *LesApiBackend) Engine() consensus.Engine {
 	return b.eth.engine
 }
@@ -56,6 +56,12 @@ func (leth *LightEthereum) stateAtTransaction(ctx context.Context, block *types.
 	if txIndex == 0 && len(block.Transactions()) == 0 {
 		return nil, vm.BlockContext{}, statedb, release, nil
 	}
+	// Insert the parent beacon block root in the state as per EIP-4788
+	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
+		context := core.NewEVMBlockContext(block.Header(), leth.blockchain, nil)
+		vmenv := vm.NewEVM(context, vm.TxContext{}, statedb, leth.blockchain.Config(), vm.Config{})
+		core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
+	}
 	// Recompute transactions up to the target index.
 	signer := types.MakeSigner(leth.blockchain.Config(), block.Number(), block.Time())
 	for idx, tx := range block.Transactions() {
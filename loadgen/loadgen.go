@@ -0,0 +1,526 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package loadgen implements a transaction load generator for benchmarking the
+// transaction pool, the miner and the block import path.
+//
+// The generator funds a set of sender accounts from a faucet and submits a mix
+// of value transfers, token transfers, contract deployments and blob transactions
+// at a configured rate, tracking the time it takes for each to be included. It
+// is meant to be run against dev or test networks: chain reorgs are not handled
+// and transactions dropped by the node are reported as never included.
+package loadgen
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorievm/go-gori/accounts/abi/bind"
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/consensus/misc/eip4844"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/params"
+)
+
+// Kind is the type of a generated transaction.
+type Kind uint8
+
+const (
+	KindTransfer Kind = iota // Plain value transfer to a fresh account
+	KindERC20                // Token transfer on a contract deployed by the generator
+	KindDeploy               // Contract deployment
+	KindBlob                 // Blob transaction carrying a single blob
+)
+
+// kinds is the list of all transaction kinds, in reporting order.
+var kinds = []Kind{KindTransfer, KindERC20, KindDeploy, KindBlob}
+
+// String implements fmt.Stringer, returning the name of the kind used in mixes.
+func (k Kind) String() string {
+	switch k {
+	case KindTransfer:
+		return "transfer"
+	case KindERC20:
+		return "erc20"
+	case KindDeploy:
+		return "deploy"
+	case KindBlob:
+		return "blob"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseMix parses a transaction mix in the format of comma separated kind=weight
+// pairs (e.g. "transfer=70,erc20=20,deploy=5,blob=5").
+func ParseMix(spec string) (map[Kind]uint64, error) {
+	mix := make(map[Kind]uint64)
+	for _, part := range strings.Split(spec, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid mix entry %q, want kind=weight", part)
+		}
+		weight, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight for %s: %v", name, err)
+		}
+		var found bool
+		for _, kind := range kinds {
+			if kind.String() == strings.TrimSpace(name) {
+				mix[kind], found = weight, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown transaction kind %q", name)
+		}
+	}
+	return mix, nil
+}
+
+// Config contains the settings of a load generation run.
+type Config struct {
+	Rate     float64         // Transactions to submit per second
+	Duration time.Duration   // Time to keep submitting transactions for
+	Timeout  time.Duration   // Time to wait for the inclusion of the submitted transactions
+	Accounts int             // Number of sender accounts to fund from the faucet
+	Workers  int             // Number of concurrent submitters, each owning a subset of the accounts
+	Funding  *big.Int        // Amount to fund each sender account with
+	Mix      map[Kind]uint64 // Relative weights of the generated transaction kinds
+	Poll     time.Duration   // Interval to poll the node for new blocks
+}
+
+// DefaultConfig contains the default load generation settings.
+var DefaultConfig = Config{
+	Rate:     100,
+	Duration: time.Minute,
+	Timeout:  time.Minute,
+	Accounts: 64,
+	Workers:  8,
+	Funding:  big.NewInt(params.Ether),
+	Mix:      map[Kind]uint64{KindTransfer: 70, KindERC20: 20, KindDeploy: 10},
+	Poll:     250 * time.Millisecond,
+}
+
+// sanitize checks the provided user configurations and changes anything that's
+// unreasonable or unworkable.
+func (config *Config) sanitize() (Config, error) {
+	conf := *config
+	if conf.Rate <= 0 {
+		return conf, fmt.Errorf("invalid rate %v", conf.Rate)
+	}
+	if conf.Accounts < 1 {
+		log.Warn("Sanitizing invalid loadgen accounts", "provided", conf.Accounts, "updated", DefaultConfig.Accounts)
+		conf.Accounts = DefaultConfig.Accounts
+	}
+	if conf.Workers < 1 {
+		log.Warn("Sanitizing invalid loadgen workers", "provided", conf.Workers, "updated", DefaultConfig.Workers)
+		conf.Workers = DefaultConfig.Workers
+	}
+	if conf.Workers > conf.Accounts {
+		conf.Workers = conf.Accounts
+	}
+	if conf.Funding == nil || conf.Funding.Sign() <= 0 {
+		conf.Funding = DefaultConfig.Funding
+	}
+	if conf.Poll <= 0 {
+		conf.Poll = DefaultConfig.Poll
+	}
+	var total uint64
+	for _, weight := range conf.Mix {
+		total += weight
+	}
+	if total == 0 {
+		return conf, errors.New("empty transaction mix")
+	}
+	return conf, nil
+}
+
+// Backend is the node connection needed to generate load. Blob transactions can
+// only be submitted if the backend also implements bind.BlobTransactor.
+type Backend interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// fees are the fee caps to use for the generated transactions.
+type fees struct {
+	tip     *big.Int // Priority fee to pay for all transactions
+	feeCap  *big.Int // Maximum fee per gas, leaving room for base fee increases
+	blobCap *big.Int // Maximum fee per blob gas, nil before Cancun
+}
+
+// sender is an account submitting transactions, owned by a single worker.
+type sender struct {
+	key   *ecdsa.PrivateKey
+	addr  common.Address
+	nonce uint64
+	stale bool // Whether the nonce needs resyncing after a failed submission
+}
+
+// Generator submits transactions to a node at a configured rate.
+type Generator struct {
+	backend Backend
+	faucet  *ecdsa.PrivateKey
+	config  Config
+
+	signer  types.Signer
+	chainID *big.Int
+	senders []*sender
+	token   common.Address // Token contract used by the ERC-20 transfers
+	weights []uint64       // Cumulative weights of the kinds, for random picking
+
+	fees atomic.Pointer[fees]
+}
+
+// New creates a load generator funding its senders from the given faucet.
+func New(backend Backend, faucet *ecdsa.PrivateKey, config Config) (*Generator, error) {
+	config, err := (&config).sanitize()
+	if err != nil {
+		return nil, err
+	}
+	if config.Mix[KindBlob] > 0 {
+		if _, ok := backend.(bind.BlobTransactor); !ok {
+			return nil, bind.ErrNoBlobTransport
+		}
+	}
+	g := &Generator{
+		backend: backend,
+		faucet:  faucet,
+		config:  config,
+	}
+	var total uint64
+	for _, kind := range kinds {
+		total += config.Mix[kind]
+		g.weights = append(g.weights, total)
+	}
+	return g, nil
+}
+
+// Run funds the sender accounts, submits transactions for the configured duration
+// and waits for their inclusion, returning the statistics of the run.
+func (g *Generator) Run(ctx context.Context) (*Report, error) {
+	if err := g.prepare(ctx); err != nil {
+		return nil, err
+	}
+	head, err := g.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		tracker = newTracker(head.Number.Uint64())
+		start   = time.Now()
+
+		pollCtx, cancel = context.WithCancel(ctx)
+		polled          = make(chan struct{})
+	)
+	go func() {
+		defer close(polled)
+		g.poll(pollCtx, tracker)
+	}()
+	g.generate(ctx, tracker)
+
+	// Submission finished, wait until everything is included or we time out
+	timeout := time.NewTimer(g.config.Timeout)
+	defer timeout.Stop()
+
+	ticker := time.NewTicker(g.config.Poll)
+	defer ticker.Stop()
+
+wait:
+	for tracker.pending() > 0 {
+		select {
+		case <-ctx.Done():
+			break wait
+		case <-timeout.C:
+			break wait
+		case <-ticker.C:
+		}
+	}
+	cancel()
+	<-polled
+
+	return tracker.report(time.Since(start)), nil
+}
+
+// prepare retrieves the chain parameters, funds the sender accounts and deploys
+// the token contract if needed, waiting until all are included.
+func (g *Generator) prepare(ctx context.Context) error {
+	chainID, err := g.backend.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	g.chainID, g.signer = chainID, types.LatestSignerForChainID(chainID)
+
+	if err := g.refreshFees(ctx); err != nil {
+		return err
+	}
+	faucet := crypto.PubkeyToAddress(g.faucet.PublicKey)
+	nonce, err := g.backend.PendingNonceAt(ctx, faucet)
+	if err != nil {
+		return err
+	}
+	var (
+		fees = g.fees.Load()
+		txs  []*types.Transaction
+	)
+	for i := 0; i < g.config.Accounts; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return err
+		}
+		s := &sender{key: key, addr: crypto.PubkeyToAddress(key.PublicKey)}
+		g.senders = append(g.senders, s)
+
+		tx, err := types.SignNewTx(g.faucet, g.signer, &types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: fees.tip,
+			GasFeeCap: fees.feeCap,
+			Gas:       params.TxGas,
+			To:        &s.addr,
+			Value:     g.config.Funding,
+		})
+		if err != nil {
+			return err
+		}
+		txs = append(txs, tx)
+		nonce++
+	}
+	if g.config.Mix[KindERC20] > 0 {
+		tx, err := types.SignNewTx(g.faucet, g.signer, &types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: fees.tip,
+			GasFeeCap: fees.feeCap,
+			Gas:       deployGas,
+			Data:      tokenCode,
+		})
+		if err != nil {
+			return err
+		}
+		txs = append(txs, tx)
+		g.token = crypto.CreateAddress(faucet, nonce)
+	}
+	for _, tx := range txs {
+		if err := g.backend.SendTransaction(ctx, tx); err != nil {
+			return fmt.Errorf("failed to send setup transaction: %v", err)
+		}
+	}
+	log.Info("Funding load generator accounts", "accounts", g.config.Accounts, "funding", g.config.Funding)
+	return g.waitReceipts(ctx, txs)
+}
+
+// waitReceipts waits until all the given transactions are successfully included.
+func (g *Generator) waitReceipts(ctx context.Context, txs []*types.Transaction) error {
+	ctx, cancel := context.WithTimeout(ctx, g.config.Timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(g.config.Poll)
+	defer ticker.Stop()
+
+	for len(txs) > 0 {
+		receipt, err := g.backend.TransactionReceipt(ctx, txs[0].Hash())
+		switch {
+		case err == nil && receipt.Status != types.ReceiptStatusSuccessful:
+			return fmt.Errorf("setup transaction %x failed", txs[0].Hash())
+		case err == nil:
+			txs = txs[1:]
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("setup transactions not included: %v", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// refreshFees updates the fee caps of the generated transactions based on the
+// chain head, allowing the base fees to double before they are underpriced.
+func (g *Generator) refreshFees(ctx context.Context) error {
+	head, err := g.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	tip, err := g.backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return err
+	}
+	fees := &fees{tip: tip, feeCap: new(big.Int).Set(tip)}
+	if head.BaseFee != nil {
+		fees.feeCap.Add(fees.feeCap, new(big.Int).Mul(head.BaseFee, common.Big2))
+	}
+	if head.ExcessBlobGas != nil {
+		fees.blobCap = new(big.Int).Mul(eip4844.CalcBlobFee(*head.ExcessBlobGas), common.Big2)
+	}
+	g.fees.Store(fees)
+	return nil
+}
+
+// pick selects a random transaction kind according to the configured weights.
+func (g *Generator) pick() Kind {
+	n := uint64(rand.Int63n(int64(g.weights[len(g.weights)-1])))
+	return kinds[sort.Search(len(g.weights), func(i int) bool { return g.weights[i] > n })]
+}
+
+// generate submits transactions at the configured rate until the run duration
+// elapses or the context is cancelled.
+func (g *Generator) generate(ctx context.Context, tracker *tracker) {
+	var (
+		jobs = make([]chan Kind, g.config.Workers)
+		wg   sync.WaitGroup
+	)
+	for i := range jobs {
+		jobs[i] = make(chan Kind, 64)
+
+		var senders []*sender
+		for j := i; j < len(g.senders); j += len(jobs) {
+			senders = append(senders, g.senders[j])
+		}
+		wg.Add(1)
+		go func(jobs <-chan Kind) {
+			defer wg.Done()
+			g.work(ctx, senders, jobs, tracker)
+		}(jobs[i])
+	}
+	defer func() {
+		for _, ch := range jobs {
+			close(ch)
+		}
+		wg.Wait()
+	}()
+	// Schedule the transactions evenly, catching up after any hiccups
+	interval := time.Duration(float64(time.Second) / g.config.Rate)
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		start     = time.Now()
+		scheduled uint64
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			elapsed := now.Sub(start)
+			if elapsed > g.config.Duration {
+				return
+			}
+			for due := uint64(elapsed.Seconds() * g.config.Rate); scheduled < due; scheduled++ {
+				select {
+				case jobs[scheduled%uint64(len(jobs))] <- g.pick():
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// work submits the transactions scheduled for a worker, rotating between the
+// senders it owns.
+func (g *Generator) work(ctx context.Context, senders []*sender, jobs <-chan Kind, tracker *tracker) {
+	for i := 0; ; i++ {
+		kind, ok := <-jobs
+		if !ok {
+			return
+		}
+		s := senders[i%len(senders)]
+		if s.stale {
+			nonce, err := g.backend.PendingNonceAt(ctx, s.addr)
+			if err != nil {
+				tracker.fail(kind, err)
+				continue
+			}
+			s.nonce, s.stale = nonce, false
+		}
+		tx, sidecar, err := g.build(kind, s, g.fees.Load())
+		if err != nil {
+			tracker.fail(kind, err)
+			continue
+		}
+		sent := time.Now()
+		if sidecar != nil {
+			err = g.backend.(bind.BlobTransactor).SendBlobTransaction(ctx, tx, sidecar)
+		} else {
+			err = g.backend.SendTransaction(ctx, tx)
+		}
+		if err != nil {
+			tracker.fail(kind, err)
+			s.stale = true
+			continue
+		}
+		tracker.sent(tx.Hash(), kind, sent)
+		s.nonce++
+	}
+}
+
+// poll tracks the blocks added to the chain, recording the inclusion of the
+// submitted transactions and refreshing the fee caps.
+func (g *Generator) poll(ctx context.Context, tracker *tracker) {
+	ticker := time.NewTicker(g.config.Poll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		head, err := g.backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			log.Debug("Failed to retrieve chain head", "err", err)
+			continue
+		}
+		for number := tracker.head() + 1; number <= head.Number.Uint64(); number++ {
+			block, err := g.backend.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+			if err != nil {
+				log.Debug("Failed to retrieve block", "number", number, "err", err)
+				break
+			}
+			tracker.include(block, time.Now())
+		}
+		if err := g.refreshFees(ctx); err != nil {
+			log.Debug("Failed to refresh fee caps", "err", err)
+		}
+	}
+}
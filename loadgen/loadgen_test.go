@@ -0,0 +1,142 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/eth"
+	"github.com/gorievm/go-gori/eth/catalyst"
+	"github.com/gorievm/go-gori/eth/downloader"
+	"github.com/gorievm/go-gori/eth/ethconfig"
+	"github.com/gorievm/go-gori/node"
+	"github.com/gorievm/go-gori/p2p"
+)
+
+func TestParseMix(t *testing.T) {
+	tests := []struct {
+		spec string
+		mix  map[Kind]uint64
+		fail bool
+	}{
+		{spec: "transfer=70, erc20=20,deploy=5,blob=5", mix: map[Kind]uint64{KindTransfer: 70, KindERC20: 20, KindDeploy: 5, KindBlob: 5}},
+		{spec: "transfer=1", mix: map[Kind]uint64{KindTransfer: 1}},
+		{spec: "", mix: map[Kind]uint64{}},
+		{spec: "transfer", fail: true},
+		{spec: "transfer=-1", fail: true},
+		{spec: "mint=1", fail: true},
+	}
+	for i, tt := range tests {
+		mix, err := ParseMix(tt.spec)
+		if (err != nil) != tt.fail {
+			t.Errorf("test %d: error mismatch: have %v, want failure %v", i, err, tt.fail)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(mix, tt.mix) {
+			t.Errorf("test %d: mix mismatch: have %v, want %v", i, mix, tt.mix)
+		}
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	// Start an in-process dev node sealing blocks on demand
+	faucet, _ := crypto.GenerateKey()
+	genesis := core.DeveloperGenesisBlock(30_000_000, crypto.PubkeyToAddress(faucet.PublicKey))
+
+	stack, err := node.New(&node.Config{P2P: p2p.Config{NoDiscovery: true, MaxPeers: 0}})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	defer stack.Close()
+
+	ethservice, err := eth.New(stack, &ethconfig.Config{Genesis: genesis, SyncMode: downloader.FullSync, TrieTimeout: time.Minute, TrieDirtyCache: 256, TrieCleanCache: 256})
+	if err != nil {
+		t.Fatalf("failed to create eth service: %v", err)
+	}
+	beacon, err := catalyst.NewSimulatedBeacon(0, ethservice)
+	if err != nil {
+		t.Fatalf("failed to create simulated beacon: %v", err)
+	}
+	stack.RegisterLifecycle(beacon)
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	ethservice.SetSynced()
+
+	// Generate a short burst of load and ensure everything got included
+	client := NewClient(stack.Attach())
+	defer client.Close()
+
+	gen, err := New(client, faucet, Config{
+		Rate:     50,
+		Duration: time.Second,
+		Timeout:  10 * time.Second,
+		Accounts: 4,
+		Workers:  2,
+		Mix:      map[Kind]uint64{KindTransfer: 2, KindERC20: 2, KindDeploy: 1},
+		Poll:     50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+	report, err := gen.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to generate load: %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("submission errors: %v", report.Errors)
+	}
+	var sent uint64
+	for kind, stats := range report.Kinds {
+		if stats.Included != stats.Sent {
+			t.Errorf("%v: included transactions mismatch: have %d, want %d", kind, stats.Included, stats.Sent)
+		}
+		if stats.P50 > stats.Max {
+			t.Errorf("%v: latency percentiles out of order: p50 %v, max %v", kind, stats.P50, stats.Max)
+		}
+		sent += stats.Sent
+	}
+	if sent < 25 || report.Blocks == 0 || report.Txs < sent {
+		t.Errorf("run statistics mismatch: sent %d, blocks %d, txs %d", sent, report.Blocks, report.Txs)
+	}
+	// Ensure the token contract is functional
+	code, err := client.CodeAt(context.Background(), gen.token, nil)
+	if err != nil || !bytes.Equal(code, tokenRuntime) {
+		t.Fatalf("token code mismatch: have %x, want %x (err %v)", code, tokenRuntime, err)
+	}
+	var debited int
+	for _, s := range gen.senders {
+		if balance, _ := client.StorageAt(context.Background(), gen.token, common.BytesToHash(s.addr[:]), nil); !bytes.Equal(balance, make([]byte, 32)) {
+			debited++
+		}
+	}
+	if debited == 0 && report.Kinds[KindERC20].Included > 0 {
+		t.Errorf("token transfers did not debit any sender")
+	}
+	var buf bytes.Buffer
+	report.Write(&buf)
+	if !bytes.Contains(buf.Bytes(), []byte("erc20")) {
+		t.Errorf("report missing token transfers:\n%s", buf.String())
+	}
+}
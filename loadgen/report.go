@@ -0,0 +1,228 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package loadgen
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/olekukonko/tablewriter"
+)
+
+// submission is a transaction sent by the generator, waiting for inclusion.
+type submission struct {
+	kind Kind
+	time time.Time
+}
+
+// tracker records the submitted transactions and their inclusion.
+type tracker struct {
+	lock sync.Mutex
+
+	number    uint64                     // Last block processed
+	submitted map[common.Hash]submission // Transactions waiting for inclusion
+	latencies map[Kind][]time.Duration   // Inclusion latencies of the transactions by kind
+	sends     map[Kind]uint64            // Number of transactions submitted by kind
+	fails     map[Kind]uint64            // Number of failed submissions by kind
+	errors    map[string]uint64          // Number of failed submissions by error
+
+	blocks  uint64 // Number of blocks processed
+	txs     uint64 // Number of transactions included in the processed blocks
+	gasUsed uint64 // Gas used by the processed blocks
+}
+
+// newTracker creates a tracker processing the blocks following the given one.
+func newTracker(number uint64) *tracker {
+	return &tracker{
+		number:    number,
+		submitted: make(map[common.Hash]submission),
+		latencies: make(map[Kind][]time.Duration),
+		sends:     make(map[Kind]uint64),
+		fails:     make(map[Kind]uint64),
+		errors:    make(map[string]uint64),
+	}
+}
+
+// sent records a successfully submitted transaction.
+func (t *tracker) sent(hash common.Hash, kind Kind, time time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.submitted[hash] = submission{kind: kind, time: time}
+	t.sends[kind]++
+}
+
+// fail records a transaction that could not be created or submitted.
+func (t *tracker) fail(kind Kind, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.fails[kind]++
+	t.errors[err.Error()]++
+}
+
+// head returns the number of the last processed block.
+func (t *tracker) head() uint64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.number
+}
+
+// pending returns the number of submitted transactions not yet included.
+func (t *tracker) pending() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return len(t.submitted)
+}
+
+// include processes a new block, recording the inclusion latencies of the
+// submitted transactions in it.
+func (t *tracker) include(block *types.Block, seen time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, tx := range block.Transactions() {
+		if sub, ok := t.submitted[tx.Hash()]; ok {
+			t.latencies[sub.kind] = append(t.latencies[sub.kind], seen.Sub(sub.time))
+			delete(t.submitted, tx.Hash())
+		}
+	}
+	t.number = block.NumberU64()
+	t.blocks++
+	t.txs += uint64(len(block.Transactions()))
+	t.gasUsed += block.GasUsed()
+}
+
+// KindReport contains the statistics of a single transaction kind.
+type KindReport struct {
+	Sent     uint64 // Transactions successfully submitted
+	Failed   uint64 // Transactions failed to be created or submitted
+	Included uint64 // Submitted transactions included in a block
+
+	P50 time.Duration // Median inclusion latency
+	P90 time.Duration // 90th percentile inclusion latency
+	P99 time.Duration // 99th percentile inclusion latency
+	Max time.Duration // Maximum inclusion latency
+}
+
+// Report contains the statistics of a load generation run.
+type Report struct {
+	Elapsed time.Duration // Duration of the run, including the wait for inclusion
+	Blocks  uint64        // Number of blocks produced during the run
+	Txs     uint64        // Number of transactions included, including foreign ones
+	GasUsed uint64        // Gas used by the blocks produced during the run
+
+	Kinds  map[Kind]*KindReport // Statistics of the generated transaction kinds
+	Errors map[string]uint64    // Number of failed submissions by error
+}
+
+// report assembles the statistics of the run.
+func (t *tracker) report(elapsed time.Duration) *Report {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	r := &Report{
+		Elapsed: elapsed,
+		Blocks:  t.blocks,
+		Txs:     t.txs,
+		GasUsed: t.gasUsed,
+		Kinds:   make(map[Kind]*KindReport),
+		Errors:  make(map[string]uint64),
+	}
+	for _, kind := range kinds {
+		if t.sends[kind] == 0 && t.fails[kind] == 0 {
+			continue
+		}
+		latencies := t.latencies[kind]
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		r.Kinds[kind] = &KindReport{
+			Sent:     t.sends[kind],
+			Failed:   t.fails[kind],
+			Included: uint64(len(latencies)),
+			P50:      percentile(latencies, 50),
+			P90:      percentile(latencies, 90),
+			P99:      percentile(latencies, 99),
+			Max:      percentile(latencies, 100),
+		}
+	}
+	for err, count := range t.errors {
+		r.Errors[err] = count
+	}
+	return r
+}
+
+// percentile returns the given percentile of the sorted latencies.
+func percentile(latencies []time.Duration, p int) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	return latencies[(len(latencies)-1)*p/100]
+}
+
+// Write renders the report as human readable tables.
+func (r *Report) Write(w io.Writer) {
+	var sent, included uint64
+	for _, kind := range r.Kinds {
+		sent += kind.Sent
+		included += kind.Included
+	}
+	fmt.Fprintf(w, "Elapsed: %v, blocks: %d, transactions: %d, gas used: %d\n", common.PrettyDuration(r.Elapsed), r.Blocks, r.Txs, r.GasUsed)
+	fmt.Fprintf(w, "Submitted: %d (%.2f tx/s), included: %d (%.2f tx/s)\n", sent, float64(sent)/r.Elapsed.Seconds(), included, float64(included)/r.Elapsed.Seconds())
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Kind", "Sent", "Failed", "Included", "P50", "P90", "P99", "Max"})
+	for _, kind := range kinds {
+		stats, ok := r.Kinds[kind]
+		if !ok {
+			continue
+		}
+		table.Append([]string{
+			kind.String(),
+			fmt.Sprint(stats.Sent),
+			fmt.Sprint(stats.Failed),
+			fmt.Sprint(stats.Included),
+			common.PrettyDuration(stats.P50).String(),
+			common.PrettyDuration(stats.P90).String(),
+			common.PrettyDuration(stats.P99).String(),
+			common.PrettyDuration(stats.Max).String(),
+		})
+	}
+	table.Render()
+
+	if len(r.Errors) > 0 {
+		errs := make([]string, 0, len(r.Errors))
+		for err := range r.Errors {
+			errs = append(errs, err)
+		}
+		sort.Slice(errs, func(i, j int) bool { return r.Errors[errs[i]] > r.Errors[errs[j]] })
+
+		table := tablewriter.NewWriter(w)
+		table.SetHeader([]string{"Error", "Count"})
+		for _, err := range errs {
+			table.Append([]string{err, fmt.Sprint(r.Errors[err])})
+		}
+		table.Render()
+	}
+}
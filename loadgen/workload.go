@@ -0,0 +1,171 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package loadgen
+
+import (
+	"context"
+	crand "crypto/rand"
+	"errors"
+
+	"github.com/gorievm/go-gori/accounts/abi/bind"
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/crypto/kzg4844"
+	"github.com/gorievm/go-gori/ethclient"
+	"github.com/gorievm/go-gori/params"
+	"github.com/gorievm/go-gori/rlp"
+	"github.com/gorievm/go-gori/rpc"
+	"github.com/holiman/uint256"
+)
+
+const (
+	// tokenGas is the gas allowance of a token transfer, covering two fresh
+	// storage slots and the transfer event.
+	tokenGas = 100_000
+
+	// deployGas is the gas allowance of deploying the token contract.
+	deployGas = 200_000
+)
+
+var (
+	// tokenRuntime is the code of a minimal ERC-20 style token. Every call is
+	// treated as transfer(address,uint256), moving the amount from the caller to
+	// the recipient without balance checks and emitting the Transfer event. This
+	// matches the storage and log costs of real token transfers without needing
+	// to mint balances for the senders.
+	tokenRuntime = []byte{
+		byte(vm.PUSH1), 0x24, byte(vm.CALLDATALOAD), // amount
+		byte(vm.PUSH1), 0x04, byte(vm.CALLDATALOAD), // recipient
+		// balances[recipient] += amount
+		byte(vm.DUP2), byte(vm.DUP2), byte(vm.SLOAD), byte(vm.ADD), byte(vm.DUP2), byte(vm.SSTORE),
+		// balances[caller] -= amount
+		byte(vm.DUP2), byte(vm.CALLER), byte(vm.SLOAD), byte(vm.SUB), byte(vm.CALLER), byte(vm.SSTORE),
+		// emit Transfer(caller, recipient, amount)
+		byte(vm.DUP2), byte(vm.PUSH1), 0x00, byte(vm.MSTORE),
+		byte(vm.CALLER), byte(vm.PUSH32),
+		0xdd, 0xf2, 0x52, 0xad, 0x1b, 0xe2, 0xc8, 0x9b, 0x69, 0xc2, 0xb0, 0x68, 0xfc, 0x37, 0x8d, 0xaa,
+		0x95, 0x2b, 0xa7, 0xf1, 0x63, 0xc4, 0xa1, 0x16, 0x28, 0xf5, 0x5a, 0x4d, 0xf5, 0x23, 0xb3, 0xef,
+		byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x00, byte(vm.LOG3),
+		// return true
+		byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x00, byte(vm.RETURN),
+	}
+	// tokenCode is the deployment code of the token contract, returning the
+	// runtime code appended to it.
+	tokenCode = append([]byte{
+		byte(vm.PUSH1), byte(len(tokenRuntime)), byte(vm.DUP1),
+		byte(vm.PUSH1), 11, byte(vm.PUSH1), 0x00, byte(vm.CODECOPY),
+		byte(vm.PUSH1), 0x00, byte(vm.RETURN),
+	}, tokenRuntime...)
+
+	// transferSelector is the function selector of transfer(address,uint256).
+	transferSelector = []byte{0xa9, 0x05, 0x9c, 0xbb}
+)
+
+// randomAddress returns a fresh account to send funds to.
+func randomAddress() common.Address {
+	var addr common.Address
+	crand.Read(addr[:])
+	return addr
+}
+
+// randomBlob returns a blob filled with random field elements.
+func randomBlob() kzg4844.Blob {
+	var blob kzg4844.Blob
+	crand.Read(blob[:])
+	for i := 0; i < len(blob); i += 32 {
+		blob[i] = 0 // keep the field elements below the modulus
+	}
+	return blob
+}
+
+// build creates and signs the next transaction of the given kind for a sender,
+// along with its blob sidecar for blob transactions.
+func (g *Generator) build(kind Kind, s *sender, fees *fees) (*types.Transaction, *bind.BlobSidecar, error) {
+	var (
+		to   = randomAddress()
+		data types.TxData
+	)
+	switch kind {
+	case KindTransfer:
+		data = &types.DynamicFeeTx{ChainID: g.chainID, Nonce: s.nonce, GasTipCap: fees.tip, GasFeeCap: fees.feeCap, Gas: params.TxGas, To: &to, Value: common.Big1}
+
+	case KindERC20:
+		input := append(common.CopyBytes(transferSelector), common.LeftPadBytes(to[:], 32)...)
+		input = append(input, common.LeftPadBytes(common.Big1.Bytes(), 32)...)
+		data = &types.DynamicFeeTx{ChainID: g.chainID, Nonce: s.nonce, GasTipCap: fees.tip, GasFeeCap: fees.feeCap, Gas: tokenGas, To: &g.token, Data: input}
+
+	case KindDeploy:
+		data = &types.DynamicFeeTx{ChainID: g.chainID, Nonce: s.nonce, GasTipCap: fees.tip, GasFeeCap: fees.feeCap, Gas: deployGas, Data: tokenCode}
+
+	case KindBlob:
+		if fees.blobCap == nil {
+			return nil, nil, errors.New("blob transactions not supported before Cancun")
+		}
+		sidecar, err := bind.NewBlobSidecar([]kzg4844.Blob{randomBlob()})
+		if err != nil {
+			return nil, nil, err
+		}
+		tx, err := types.SignNewTx(s.key, g.signer, &types.BlobTx{
+			ChainID:    uint256.MustFromBig(g.chainID),
+			Nonce:      s.nonce,
+			GasTipCap:  uint256.MustFromBig(fees.tip),
+			GasFeeCap:  uint256.MustFromBig(fees.feeCap),
+			Gas:        params.TxGas,
+			To:         to,
+			Value:      new(uint256.Int),
+			BlobFeeCap: uint256.MustFromBig(fees.blobCap),
+			BlobHashes: sidecar.BlobHashes(),
+		})
+		return tx, sidecar, err
+
+	default:
+		return nil, nil, errors.New("unknown transaction kind")
+	}
+	tx, err := types.SignNewTx(s.key, g.signer, data)
+	return tx, nil, err
+}
+
+// Client is a Backend submitting transactions over RPC. Blob transactions are
+// sent in their network encoding, with the sidecar wrapped around the signed
+// transaction.
+type Client struct {
+	*ethclient.Client
+	rpc *rpc.Client
+}
+
+// NewClient creates a load generation backend using the given RPC client.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{Client: ethclient.NewClient(c), rpc: c}
+}
+
+// SendBlobTransaction implements bind.BlobTransactor, injecting a signed blob
+// transaction along with its sidecar into the pending pool for execution.
+func (c *Client) SendBlobTransaction(ctx context.Context, tx *types.Transaction, sidecar *bind.BlobSidecar) error {
+	blob, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	// The network encoding is type || rlp([tx_payload_body, blobs, commitments, proofs])
+	wrapped, err := rlp.EncodeToBytes([]interface{}{rlp.RawValue(blob[1:]), sidecar.Blobs, sidecar.Commitments, sidecar.Proofs})
+	if err != nil {
+		return err
+	}
+	return c.rpc.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Bytes(append([]byte{types.BlobTxType}, wrapped...)))
+}
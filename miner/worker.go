@@ -27,10 +27,13 @@ import (
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/consensus"
 	"github.com/gorievm/go-gori/consensus/misc/eip1559"
+	"github.com/gorievm/go-gori/consensus/misc/eip4844"
 	"github.com/gorievm/go-gori/core"
 	"github.com/gorievm/go-gori/core/state"
 	"github.com/gorievm/go-gori/core/txpool"
 	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/core/vm"
+	"github.com/gorievm/go-gori/crypto/kzg4844"
 	"github.com/gorievm/go-gori/event"
 	"github.com/gorievm/go-gori/log"
 	"github.com/gorievm/go-gori/params"
@@ -75,6 +78,10 @@ var (
 	errBlockInterruptedByNewHead  = errors.New("new head arrived while building block")
 	errBlockInterruptedByRecommit = errors.New("recommit interrupt while building block")
 	errBlockInterruptedByTimeout  = errors.New("timeout while building block")
+
+	errBlobTxPreCancun     = errors.New("blob transaction before cancun")
+	errBlobTxNoSidecar     = errors.New("blob transaction without sidecar")
+	errBlobGasLimitReached = errors.New("blob gas limit reached")
 )
 
 // environment is the worker's current environment and holds all
@@ -89,6 +96,10 @@ type environment struct {
 	header   *types.Header
 	txs      []*types.Transaction
 	receipts []*types.Receipt
+
+	blobs   []kzg4844.Blob       // Blobs of the included blob transactions
+	commits []kzg4844.Commitment // Commitments of the included blobs
+	proofs  []kzg4844.Proof      // Proofs of the included blobs
 }
 
 // copy creates a deep copy of environment.
@@ -107,6 +118,10 @@ func (env *environment) copy() *environment {
 	}
 	cpy.txs = make([]*types.Transaction, len(env.txs))
 	copy(cpy.txs, env.txs)
+
+	cpy.blobs = append([]kzg4844.Blob(nil), env.blobs...)
+	cpy.commits = append([]kzg4844.Commitment(nil), env.commits...)
+	cpy.proofs = append([]kzg4844.Proof(nil), env.proofs...)
 	return cpy
 }
 
@@ -146,6 +161,10 @@ type newPayloadResult struct {
 	err   error
 	block *types.Block
 	fees  *big.Int
+
+	blobs   []kzg4844.Blob       // Blobs of the blob transactions included in the block
+	commits []kzg4844.Commitment // Commitments of the included blobs
+	proofs  []kzg4844.Proof      // Proofs of the included blobs
 }
 
 // getWorkReq represents a request for getting a new sealing work with provided parameters.
@@ -516,12 +535,7 @@ func (w *worker) mainLoop() {
 			w.commitWork(req.interrupt, req.timestamp)
 
 		case req := <-w.getWorkCh:
-			block, fees, err := w.generateWork(req.params)
-			req.result <- &newPayloadResult{
-				err:   err,
-				block: block,
-				fees:  fees,
-			}
+			req.result <- w.generateWork(req.params)
 
 		case ev := <-w.txsCh:
 			// Apply transactions to the pending state if we're not sealing
@@ -735,6 +749,39 @@ func (w *worker) updateSnapshot(env *environment) {
 }
 
 func (w *worker) commitTransaction(env *environment, tx *txpool.Transaction) ([]*types.Log, error) {
+	if tx.Tx.Type() == types.BlobTxType {
+		return w.commitBlobTransaction(env, tx)
+	}
+	return w.applyTransaction(env, tx)
+}
+
+// commitBlobTransaction applies a blob transaction to the sealing block, tracking
+// its sidecar for the payload's blobs bundle and accounting for the blob gas.
+func (w *worker) commitBlobTransaction(env *environment, tx *txpool.Transaction) ([]*types.Log, error) {
+	if env.header.BlobGasUsed == nil {
+		return nil, errBlobTxPreCancun
+	}
+	blobs := len(tx.Tx.BlobHashes())
+	if len(tx.BlobTxBlobs) != blobs || len(tx.BlobTxCommits) != blobs || len(tx.BlobTxProofs) != blobs {
+		return nil, errBlobTxNoSidecar
+	}
+	if *env.header.BlobGasUsed+tx.Tx.BlobGas() > params.BlobTxMaxBlobGasPerBlock {
+		return nil, errBlobGasLimitReached
+	}
+	logs, err := w.applyTransaction(env, tx)
+	if err != nil {
+		return nil, err
+	}
+	env.blobs = append(env.blobs, tx.BlobTxBlobs...)
+	env.commits = append(env.commits, tx.BlobTxCommits...)
+	env.proofs = append(env.proofs, tx.BlobTxProofs...)
+	*env.header.BlobGasUsed += tx.Tx.BlobGas()
+	return logs, nil
+}
+
+// applyTransaction executes a transaction on top of the sealing block's state,
+// reverting any changes if it fails.
+func (w *worker) applyTransaction(env *environment, tx *txpool.Transaction) ([]*types.Log, error) {
 	var (
 		snap = env.state.Snapshot()
 		gp   = env.gasPool.Gas()
@@ -835,6 +882,77 @@ func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAn
 	return nil
 }
 
+// commitBundles includes the given transaction bundles into the block. The
+// transactions of a bundle are executed back to back and are only kept if all
+// of them succeed without reverting, otherwise the bundle is skipped entirely.
+func (w *worker) commitBundles(env *environment, bundles []*txpool.Bundle, interrupt *atomic.Int32) error {
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	var coalescedLogs []*types.Log
+	for _, bundle := range bundles {
+		// Check interruption signal and abort building if it's fired.
+		if interrupt != nil {
+			if signal := interrupt.Load(); signal != commitInterruptNone {
+				return signalToErr(signal)
+			}
+		}
+		if env.gasPool.Gas() < bundle.Gas() {
+			log.Trace("Not enough gas for bundle", "hash", bundle.Hash(), "have", env.gasPool, "want", bundle.Gas())
+			continue
+		}
+		// The state journal doesn't span transactions, so execute the bundle on
+		// a copy of the environment and only adopt it if the entire bundle went
+		// through.
+		trial := env.copy()
+		logs, err := w.commitBundle(trial, bundle)
+		if err != nil {
+			log.Debug("Bundle failed, skipped", "hash", bundle.Hash(), "err", err)
+			trial.discard()
+			continue
+		}
+		env.discard()
+		*env = *trial
+		env.state.StartPrefetcher("miner")
+
+		coalescedLogs = append(coalescedLogs, logs...)
+	}
+	if !w.isRunning() && len(coalescedLogs) > 0 {
+		// Same as for the pooled transactions, push a copy of the pending logs
+		// unless sealing.
+		cpy := make([]*types.Log, len(coalescedLogs))
+		for i, l := range coalescedLogs {
+			cpy[i] = new(types.Log)
+			*cpy[i] = *l
+		}
+		w.pendingLogsFeed.Send(cpy)
+	}
+	return nil
+}
+
+// commitBundle executes all the transactions of a bundle on top of the given
+// environment, failing if any of them is invalid or reverts.
+func (w *worker) commitBundle(env *environment, bundle *txpool.Bundle) ([]*types.Log, error) {
+	var logs []*types.Log
+	for _, tx := range bundle.Txs {
+		if tx.Protected() && !w.chainConfig.IsEIP155(env.header.Number) {
+			return nil, types.ErrInvalidChainId
+		}
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+
+		txLogs, err := w.commitTransaction(env, &txpool.Transaction{Tx: tx})
+		if err != nil {
+			return nil, err
+		}
+		if receipt := env.receipts[len(env.receipts)-1]; receipt.Status != types.ReceiptStatusSuccessful {
+			return nil, fmt.Errorf("tx %x: %w", tx.Hash(), vm.ErrExecutionReverted)
+		}
+		logs = append(logs, txLogs...)
+		env.tcount++
+	}
+	return logs, nil
+}
+
 // generateParams wraps various of settings for generating sealing task.
 type generateParams struct {
 	timestamp   uint64            // The timstamp for sealing task
@@ -843,6 +961,7 @@ type generateParams struct {
 	coinbase    common.Address    // The fee recipient address for including transaction
 	random      common.Hash       // The randomness generated by beacon chain, empty before the merge
 	withdrawals types.Withdrawals // List of withdrawals to include in block.
+	beaconRoot  *common.Hash      // The beacon root (cancun field).
 	noTxs       bool              // Flag whether an empty block without any transaction is expected
 }
 
@@ -895,6 +1014,23 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 			header.GasLimit = core.CalcGasLimit(parentGasLimit, w.config.GasCeil)
 		}
 	}
+	// Initialize the blob gas accounting if cancun is active
+	if w.chainConfig.IsCancun(header.Number, header.Time) {
+		var excessBlobGas uint64
+		if w.chainConfig.IsCancun(parent.Number, parent.Time) {
+			excessBlobGas = eip4844.CalcExcessBlobGas(*parent.ExcessBlobGas, *parent.BlobGasUsed)
+		}
+		header.BlobGasUsed = new(uint64)
+		header.ExcessBlobGas = &excessBlobGas
+		header.ParentBeaconRoot = genParams.beaconRoot
+		if header.ParentBeaconRoot == nil {
+			header.ParentBeaconRoot = new(common.Hash)
+		}
+	}
+	// Commit to the (empty) execution layer requests if prague is active
+	if w.chainConfig.IsPrague(header.Number, header.Time) {
+		header.RequestsHash = &types.EmptyRequestsHash
+	}
 	// Run the consensus preparation with the default or customized consensus engine.
 	if err := w.engine.Prepare(w.chain, header); err != nil {
 		log.Error("Failed to prepare header for sealing", "err", err)
@@ -908,6 +1044,11 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 		log.Error("Failed to create sealing context", "err", err)
 		return nil, err
 	}
+	if header.ParentBeaconRoot != nil {
+		context := core.NewEVMBlockContext(header, w.chain, nil)
+		vmenv := vm.NewEVM(context, vm.TxContext{}, env.state, w.chainConfig, vm.Config{})
+		core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, vmenv, env.state)
+	}
 	return env, nil
 }
 
@@ -915,6 +1056,12 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 // into the given sealing block. The transaction selection and ordering strategy can
 // be customized with the plugin in the future.
 func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) error {
+	// Include the transaction bundles first, they need to go in as a whole
+	if bundles := w.eth.TxPool().Bundles(env.header.Number.Uint64()); len(bundles) > 0 {
+		if err := w.commitBundles(env, bundles, interrupt); err != nil {
+			return err
+		}
+	}
 	// Split the pending transactions into locals and remotes
 	// Fill the block with all available pending transactions.
 	pending := w.eth.TxPool().Pending(true)
@@ -942,10 +1089,10 @@ func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) err
 }
 
 // generateWork generates a sealing block based on the given parameters.
-func (w *worker) generateWork(params *generateParams) (*types.Block, *big.Int, error) {
+func (w *worker) generateWork(params *generateParams) *newPayloadResult {
 	work, err := w.prepareWork(params)
 	if err != nil {
-		return nil, nil, err
+		return &newPayloadResult{err: err}
 	}
 	defer work.discard()
 
@@ -963,9 +1110,15 @@ func (w *worker) generateWork(params *generateParams) (*types.Block, *big.Int, e
 	}
 	block, err := w.engine.FinalizeAndAssemble(w.chain, work.header, work.state, work.txs, nil, work.receipts, params.withdrawals)
 	if err != nil {
-		return nil, nil, err
+		return &newPayloadResult{err: err}
+	}
+	return &newPayloadResult{
+		block:   block,
+		fees:    totalFees(block, work.receipts),
+		blobs:   work.blobs,
+		commits: work.commits,
+		proofs:  work.proofs,
 	}
-	return block, totalFees(block, work.receipts), nil
 }
 
 // commitWork generates several new sealing tasks based on the parent block
@@ -1074,7 +1227,7 @@ func (w *worker) commit(env *environment, interval func(), update bool, start ti
 // getSealingBlock generates the sealing block based on the given parameters.
 // The generation result will be passed back via the given channel no matter
 // the generation itself succeeds or not.
-func (w *worker) getSealingBlock(parent common.Hash, timestamp uint64, coinbase common.Address, random common.Hash, withdrawals types.Withdrawals, noTxs bool) (*types.Block, *big.Int, error) {
+func (w *worker) getSealingBlock(parent common.Hash, timestamp uint64, coinbase common.Address, random common.Hash, withdrawals types.Withdrawals, beaconRoot *common.Hash, noTxs bool) *newPayloadResult {
 	req := &getWorkReq{
 		params: &generateParams{
 			timestamp:   timestamp,
@@ -1083,19 +1236,16 @@ func (w *worker) getSealingBlock(parent common.Hash, timestamp uint64, coinbase
 			coinbase:    coinbase,
 			random:      random,
 			withdrawals: withdrawals,
+			beaconRoot:  beaconRoot,
 			noTxs:       noTxs,
 		},
 		result: make(chan *newPayloadResult, 1),
 	}
 	select {
 	case w.getWorkCh <- req:
-		result := <-req.result
-		if result.err != nil {
-			return nil, nil, result.err
-		}
-		return result.block, result.fees, nil
+		return <-req.result
 	case <-w.exitCh:
-		return nil, nil, errors.New("miner closed")
+		return &newPayloadResult{err: errors.New("miner closed")}
 	}
 }
 
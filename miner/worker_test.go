@@ -17,6 +17,7 @@
 package miner
 
 import (
+	"crypto/ecdsa"
 	"math/big"
 	"sync/atomic"
 	"testing"
@@ -452,32 +453,87 @@ func testGetSealingWork(t *testing.T, chainConfig *params.ChainConfig, engine co
 
 	// This API should work even when the automatic sealing is not enabled
 	for _, c := range cases {
-		block, _, err := w.getSealingBlock(c.parent, timestamp, c.coinbase, c.random, nil, false)
+		r := w.getSealingBlock(c.parent, timestamp, c.coinbase, c.random, nil, nil, false)
 		if c.expectErr {
-			if err == nil {
+			if r.err == nil {
 				t.Error("Expect error but get nil")
 			}
 		} else {
-			if err != nil {
-				t.Errorf("Unexpected error %v", err)
+			if r.err != nil {
+				t.Errorf("Unexpected error %v", r.err)
 			}
-			assertBlock(block, c.expectNumber, c.coinbase, c.random)
+			assertBlock(r.block, c.expectNumber, c.coinbase, c.random)
 		}
 	}
 
 	// This API should work even when the automatic sealing is enabled
 	w.start()
 	for _, c := range cases {
-		block, _, err := w.getSealingBlock(c.parent, timestamp, c.coinbase, c.random, nil, false)
+		r := w.getSealingBlock(c.parent, timestamp, c.coinbase, c.random, nil, nil, false)
 		if c.expectErr {
-			if err == nil {
+			if r.err == nil {
 				t.Error("Expect error but get nil")
 			}
 		} else {
-			if err != nil {
-				t.Errorf("Unexpected error %v", err)
+			if r.err != nil {
+				t.Errorf("Unexpected error %v", r.err)
 			}
-			assertBlock(block, c.expectNumber, c.coinbase, c.random)
+			assertBlock(r.block, c.expectNumber, c.coinbase, c.random)
+		}
+	}
+}
+
+// Tests that transaction bundles are included as a whole and in order, ahead of
+// the pooled transactions, and that failing bundles are skipped entirely.
+func TestBundleInclusion(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	var (
+		signer   = types.LatestSigner(ethashChainConfig)
+		gasPrice = big.NewInt(10 * params.InitialBaseFee)
+	)
+	transfer := func(key *ecdsa.PrivateKey, nonce uint64, to common.Address, value *big.Int) *types.Transaction {
+		return types.MustSignNewTx(key, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &to,
+			Value:    value,
+			Gas:      params.TxGas,
+			GasPrice: gasPrice,
+		})
+	}
+	// The failing bundle funds the user, but its second transaction has a nonce
+	// gap, the valid one funds the user who spends the funds right away
+	failing := &txpool.Bundle{Txs: []*types.Transaction{
+		transfer(testBankKey, 0, testUserAddress, big.NewInt(params.Ether/10)),
+		transfer(testUserKey, 1, testBankAddress, big.NewInt(1000)),
+	}}
+	valid := &txpool.Bundle{Txs: []*types.Transaction{
+		transfer(testBankKey, 0, testUserAddress, big.NewInt(params.Ether/20)),
+		transfer(testUserKey, 0, testBankAddress, big.NewInt(1000)),
+	}}
+	for _, bundle := range []*txpool.Bundle{failing, valid} {
+		if err := b.txPool.AddBundle(bundle); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+	}
+	r := w.getSealingBlock(b.chain.Genesis().Hash(), uint64(time.Now().Unix()), testBankAddress, common.Hash{}, nil, nil, false)
+	if r.err != nil {
+		t.Fatalf("failed to build block: %v", r.err)
+	}
+	block := r.block
+	// Only the valid bundle should be included, the pooled transaction of the
+	// bank is outdated by it
+	txs := block.Transactions()
+	if len(txs) != len(valid.Txs) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(txs), len(valid.Txs))
+	}
+	for i, tx := range valid.Txs {
+		if txs[i].Hash() != tx.Hash() {
+			t.Errorf("tx %d: hash mismatch: have %x, want %x", i, txs[i].Hash(), tx.Hash())
 		}
 	}
 }
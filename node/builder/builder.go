@@ -0,0 +1,369 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package builder assembles full nodes for embedding go-gori as a library.
+//
+// Configuring a node by hand means filling in a node.Config and an ethconfig.Config
+// and registering the right services on the stack, with many settings depending on
+// each other in ways that are only enforced by the command line client. The builder
+// exposes the commonly used settings through typed methods, applies the same
+// implicit adjustments as the client and rejects invalid combinations:
+//
+//	stack, err := builder.New().
+//		WithChain(genesis).
+//		WithHTTP("127.0.0.1", 8545, "eth", "net").
+//		WithSync(downloader.SnapSync).
+//		Build()
+//
+// Contrary to the command line client, a built node has no IPC endpoint, no peer
+// to peer networking and keeps its data in memory, unless configured otherwise.
+package builder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/eth"
+	"github.com/gorievm/go-gori/eth/catalyst"
+	"github.com/gorievm/go-gori/eth/downloader"
+	"github.com/gorievm/go-gori/eth/ethconfig"
+	"github.com/gorievm/go-gori/eth/filters"
+	"github.com/gorievm/go-gori/eth/tracers"
+	"github.com/gorievm/go-gori/node"
+	"github.com/gorievm/go-gori/p2p/enode"
+	"github.com/gorievm/go-gori/rpc"
+)
+
+var (
+	// errNoChain is returned if a node is built without a chain to run.
+	errNoChain = errors.New("no chain configured")
+
+	// errLightSync is returned if a node is built in light sync mode, which needs
+	// a light client instead of a full node.
+	errLightSync = errors.New("light sync not supported by full nodes")
+
+	// errNoConsensus is returned if a node running a post-merge chain is built
+	// without a way to be driven by a consensus client.
+	errNoConsensus = errors.New("post-merge chain needs the authenticated RPC for a consensus client, or dev mode")
+
+	// errEphemeralJWT is returned if the authenticated RPC is enabled without a
+	// secret while the node has no data directory to persist a generated one.
+	errEphemeralJWT = errors.New("authenticated RPC without data directory needs a JWT secret")
+)
+
+// Builder collects the configuration of a node, validating it when the node is
+// built. The methods of the builder return the builder itself, so calls can be
+// chained; any invalid argument is reported by Build.
+type Builder struct {
+	node node.Config
+	eth  ethconfig.Config
+
+	syncSet   bool   // Whether the sync mode was set explicitly
+	networkID bool   // Whether the network ID was set explicitly
+	authRPC   bool   // Whether the authenticated RPC serving the engine API is enabled
+	dev       bool   // Whether blocks are produced locally by a simulated beacon client
+	devPeriod uint64 // Block period of the simulated beacon client, 0 = on demand
+
+	err error // First error encountered while configuring
+}
+
+// New creates a builder for an in-memory node without networking and without
+// any RPC endpoint enabled.
+func New() *Builder {
+	b := &Builder{
+		node: node.DefaultConfig,
+		eth:  ethconfig.Defaults,
+	}
+	b.node.Name = "gori"
+	b.node.DataDir = ""
+	b.node.P2P.ListenAddr = ""
+	b.node.P2P.MaxPeers = 0
+	b.node.P2P.NoDiscovery = true
+	b.node.P2P.NAT = nil
+	return b
+}
+
+// fail records the first configuration error, to be reported by Build.
+func (b *Builder) fail(err error) *Builder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// WithName sets the instance name of the node, used in the client identifier and
+// as the name of the data subdirectory.
+func (b *Builder) WithName(name string) *Builder {
+	if name == "" {
+		return b.fail(errors.New("empty node name"))
+	}
+	b.node.Name = name
+	return b
+}
+
+// WithDataDir sets the directory to persist the node's data in. An empty path
+// keeps everything in memory.
+func (b *Builder) WithDataDir(dir string) *Builder {
+	b.node.DataDir = dir
+	return b
+}
+
+// WithChain sets the genesis of the chain to run. The network ID defaults to the
+// chain ID unless set explicitly.
+func (b *Builder) WithChain(genesis *core.Genesis) *Builder {
+	if genesis == nil || genesis.Config == nil {
+		return b.fail(errors.New("genesis without chain config"))
+	}
+	b.eth.Genesis = genesis
+	return b
+}
+
+// WithNetworkID sets the network ID announced to and required from peers.
+func (b *Builder) WithNetworkID(id uint64) *Builder {
+	b.eth.NetworkId = id
+	b.networkID = true
+	return b
+}
+
+// WithSync sets the mode to synchronise the chain with.
+func (b *Builder) WithSync(mode downloader.SyncMode) *Builder {
+	if !mode.IsValid() {
+		return b.fail(fmt.Errorf("invalid sync mode %d", mode))
+	}
+	b.eth.SyncMode = mode
+	b.syncSet = true
+	return b
+}
+
+// WithArchive keeps the state of all blocks instead of pruning it, along with
+// the preimages and the transaction index of the entire chain.
+func (b *Builder) WithArchive() *Builder {
+	b.eth.NoPruning = true
+	b.eth.Preimages = true
+	b.eth.TxLookupLimit = 0
+	return b
+}
+
+// WithCache sets the memory allowance in megabytes for the database and state
+// caches, split between them the same way as by the command line client.
+func (b *Builder) WithCache(mb int) *Builder {
+	if mb <= 0 {
+		return b.fail(fmt.Errorf("invalid cache allowance %d MB", mb))
+	}
+	b.eth.DatabaseCache = mb * 50 / 100
+	b.eth.TrieCleanCache = mb * 15 / 100
+	b.eth.TrieDirtyCache = mb * 25 / 100
+	b.eth.SnapshotCache = mb * 10 / 100
+	return b
+}
+
+// WithHTTP enables the HTTP RPC endpoint on the given interface and port, serving
+// the given API modules.
+func (b *Builder) WithHTTP(host string, port int, modules ...string) *Builder {
+	if host == "" {
+		return b.fail(errors.New("empty HTTP host"))
+	}
+	b.node.HTTPHost, b.node.HTTPPort = host, port
+	if len(modules) > 0 {
+		b.node.HTTPModules = modules
+	}
+	return b
+}
+
+// WithWS enables the websocket RPC endpoint on the given interface and port,
+// serving the given API modules.
+func (b *Builder) WithWS(host string, port int, modules ...string) *Builder {
+	if host == "" {
+		return b.fail(errors.New("empty websocket host"))
+	}
+	b.node.WSHost, b.node.WSPort = host, port
+	if len(modules) > 0 {
+		b.node.WSModules = modules
+	}
+	return b
+}
+
+// WithAuthRPC enables the authenticated RPC endpoint serving the engine API to
+// a consensus client, on the given interface and port. The JWT secret is read
+// from the given file, or generated into the data directory if empty.
+func (b *Builder) WithAuthRPC(host string, port int, jwtSecret string) *Builder {
+	if host == "" {
+		return b.fail(errors.New("empty authenticated RPC host"))
+	}
+	b.node.AuthAddr, b.node.AuthPort = host, port
+	b.node.JWTSecret = jwtSecret
+	b.authRPC = true
+	return b
+}
+
+// WithP2P enables peer to peer networking, listening on the given address and
+// connecting to at most maxPeers peers, discovered via the given bootnodes.
+func (b *Builder) WithP2P(listenAddr string, maxPeers int, bootnodes ...*enode.Node) *Builder {
+	if maxPeers <= 0 {
+		return b.fail(fmt.Errorf("invalid peer limit %d", maxPeers))
+	}
+	b.node.P2P.ListenAddr = listenAddr
+	b.node.P2P.MaxPeers = maxPeers
+	b.node.P2P.NoDiscovery = len(bootnodes) == 0
+	b.node.P2P.BootstrapNodes = bootnodes
+	return b
+}
+
+// WithDev produces blocks locally with a simulated beacon client instead of
+// following a network, every period seconds or on demand if zero. The chain has
+// to be a developer chain, see core.DeveloperGenesisBlock.
+func (b *Builder) WithDev(period uint64) *Builder {
+	b.dev = true
+	b.devPeriod = period
+	return b
+}
+
+// WithNodeConfig calls fn to adjust the node configuration directly, for the
+// settings not covered by the builder. It runs before validation.
+func (b *Builder) WithNodeConfig(fn func(*node.Config)) *Builder {
+	fn(&b.node)
+	return b
+}
+
+// WithEthConfig calls fn to adjust the protocol configuration directly, for the
+// settings not covered by the builder. It runs before validation.
+func (b *Builder) WithEthConfig(fn func(*ethconfig.Config)) *Builder {
+	fn(&b.eth)
+	return b
+}
+
+// validate checks the configuration for invalid combinations, applying the
+// implicit adjustments between the settings.
+func (b *Builder) validate() error {
+	if b.err != nil {
+		return b.err
+	}
+	genesis := b.eth.Genesis
+	if genesis == nil {
+		return errNoChain
+	}
+	if b.eth.SyncMode == downloader.LightSync {
+		return errLightSync
+	}
+	if !b.networkID && genesis.Config.ChainID != nil {
+		b.eth.NetworkId = genesis.Config.ChainID.Uint64()
+	}
+	if b.eth.NoPruning && !b.eth.Preimages {
+		b.eth.Preimages = true
+	}
+	if b.dev {
+		if !genesis.Config.IsDevMode {
+			return errors.New("dev mode needs a developer chain")
+		}
+		if b.syncSet && b.eth.SyncMode != downloader.FullSync {
+			return fmt.Errorf("dev mode needs full sync, not %v", b.eth.SyncMode)
+		}
+		if b.node.P2P.MaxPeers > 0 {
+			return errors.New("dev mode can't be combined with peer to peer networking")
+		}
+		b.eth.SyncMode = downloader.FullSync
+	} else if genesis.Config.TerminalTotalDifficulty != nil && !b.authRPC {
+		return errNoConsensus
+	}
+	if b.authRPC {
+		if b.node.JWTSecret == "" && b.node.DataDir == "" {
+			return errEphemeralJWT
+		}
+		if b.node.AuthPort != 0 && b.node.AuthAddr == b.node.HTTPHost && b.node.AuthPort == b.node.HTTPPort {
+			return fmt.Errorf("authenticated RPC and HTTP RPC on the same endpoint %s:%d", b.node.AuthAddr, b.node.AuthPort)
+		}
+		if b.node.AuthPort != 0 && b.node.AuthAddr == b.node.WSHost && b.node.AuthPort == b.node.WSPort {
+			return fmt.Errorf("authenticated RPC and websocket RPC on the same endpoint %s:%d", b.node.AuthAddr, b.node.AuthPort)
+		}
+	}
+	return nil
+}
+
+// Build validates the configuration and assembles the node with the protocol
+// services registered, ready to be started.
+func (b *Builder) Build() (*Node, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	// The builder may be reused, so work on copies of the configs
+	var (
+		nodeConfig = b.node
+		ethConfig  = b.eth
+	)
+	stack, err := node.New(&nodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := eth.New(stack, &ethConfig)
+	if err != nil {
+		stack.Close()
+		return nil, err
+	}
+	stack.RegisterAPIs(tracers.APIs(backend.APIBackend, stack.RPCSpool()))
+
+	filterSystem := filters.NewFilterSystem(backend.APIBackend, filters.Config{
+		LogCacheSize:   ethConfig.FilterLogCacheSize,
+		LogResultLimit: ethConfig.RPCLogResultLimit,
+		LogRangeLimit:  ethConfig.RPCLogRangeLimit,
+		Spool:          stack.RPCSpool(),
+	})
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace: "eth",
+		Service:   filters.NewFilterAPI(filterSystem, false),
+	}})
+
+	// Drive the chain either by the simulated beacon in dev mode, or by the
+	// consensus client via the engine API
+	if b.dev {
+		beacon, err := catalyst.NewSimulatedBeacon(b.devPeriod, backend)
+		if err != nil {
+			stack.Close()
+			return nil, err
+		}
+		catalyst.RegisterSimulatedBeaconAPIs(stack, beacon)
+		stack.RegisterLifecycle(beacon)
+	} else if b.authRPC {
+		if err := catalyst.Register(stack, backend); err != nil {
+			stack.Close()
+			return nil, err
+		}
+	}
+	return &Node{Node: stack, Ori: backend, dev: b.dev}, nil
+}
+
+// Node is a node assembled by the builder, bundling the protocol stack with the
+// full node service registered on it.
+type Node struct {
+	*node.Node
+
+	Ori *eth.Ori // Full node service, e.g. to access the chain or the pool
+
+	dev bool // Whether blocks are produced locally, accepting transactions right away
+}
+
+// Start starts the node along with all the registered services.
+func (n *Node) Start() error {
+	if err := n.Node.Start(); err != nil {
+		return err
+	}
+	// A dev node is the only block producer, there's nothing to sync with
+	// before accepting transactions
+	if n.dev {
+		n.Ori.SetSynced()
+	}
+	return nil
+}
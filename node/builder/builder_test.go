@@ -0,0 +1,135 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package builder
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/core"
+	"github.com/gorievm/go-gori/eth/downloader"
+	"github.com/gorievm/go-gori/params"
+)
+
+// Tests that invalid configuration combinations are rejected.
+func TestBuilderValidation(t *testing.T) {
+	config := *params.AllDevChainProtocolChanges
+	config.IsDevMode = false
+
+	var (
+		dev    = core.DeveloperGenesisBlock(30_000_000, common.Address{0x01})
+		merged = &core.Genesis{Config: &config}
+		legacy = &core.Genesis{Config: params.AllEthashProtocolChanges, Difficulty: big.NewInt(1)}
+	)
+
+	tests := []struct {
+		name    string
+		builder *Builder
+		err     error // Specific error expected, nil if any
+	}{
+		{"no chain", New(), errNoChain},
+		{"nil chain", New().WithChain(nil), nil},
+		{"light sync", New().WithChain(legacy).WithSync(downloader.LightSync), errLightSync},
+		{"merged without consensus", New().WithChain(merged), errNoConsensus},
+		{"ephemeral jwt", New().WithChain(merged).WithAuthRPC("127.0.0.1", 8551, ""), errEphemeralJWT},
+		{"auth on http", New().WithChain(merged).WithDataDir(t.TempDir()).WithHTTP("127.0.0.1", 8551).WithAuthRPC("127.0.0.1", 8551, ""), nil},
+		{"dev without dev chain", New().WithChain(merged).WithDev(0), nil},
+		{"dev with snap sync", New().WithChain(dev).WithSync(downloader.SnapSync).WithDev(0), nil},
+		{"dev with p2p", New().WithChain(dev).WithP2P(":0", 10).WithDev(0), nil},
+		{"invalid cache", New().WithChain(dev).WithCache(0).WithDev(0), nil},
+	}
+	for _, tt := range tests {
+		err := tt.builder.validate()
+		if err == nil {
+			t.Errorf("%s: invalid configuration accepted", tt.name)
+			continue
+		}
+		if tt.err != nil && !errors.Is(err, tt.err) {
+			t.Errorf("%s: error mismatch: have %v, want %v", tt.name, err, tt.err)
+		}
+	}
+	// Valid combinations should pass
+	for name, b := range map[string]*Builder{
+		"pre-merge chain":   New().WithChain(legacy),
+		"merged with auth":  New().WithChain(merged).WithDataDir(t.TempDir()).WithHTTP("127.0.0.1", 8545).WithAuthRPC("127.0.0.1", 8551, ""),
+		"dev chain":         New().WithChain(dev).WithDev(0),
+		"dev chain archive": New().WithChain(dev).WithArchive().WithCache(256).WithDev(1),
+	} {
+		if err := b.validate(); err != nil {
+			t.Errorf("%s: valid configuration rejected: %v", name, err)
+		}
+	}
+}
+
+// Tests that the implicit adjustments between the settings are applied.
+func TestBuilderAdjustments(t *testing.T) {
+	genesis := core.DeveloperGenesisBlock(30_000_000, common.Address{0x01})
+
+	b := New().WithChain(genesis).WithArchive().WithDev(0)
+	if err := b.validate(); err != nil {
+		t.Fatalf("failed to validate: %v", err)
+	}
+	if b.eth.NetworkId != genesis.Config.ChainID.Uint64() {
+		t.Errorf("network ID mismatch: have %d, want %d", b.eth.NetworkId, genesis.Config.ChainID)
+	}
+	if b.eth.SyncMode != downloader.FullSync {
+		t.Errorf("sync mode mismatch: have %v, want %v", b.eth.SyncMode, downloader.FullSync)
+	}
+	if !b.eth.Preimages || b.eth.TxLookupLimit != 0 {
+		t.Errorf("archive mode not applied: preimages %v, tx lookup limit %d", b.eth.Preimages, b.eth.TxLookupLimit)
+	}
+	b = New().WithChain(genesis).WithNetworkID(1234).WithDev(0)
+	if err := b.validate(); err != nil {
+		t.Fatalf("failed to validate: %v", err)
+	}
+	if b.eth.NetworkId != 1234 {
+		t.Errorf("network ID mismatch: have %d, want %d", b.eth.NetworkId, 1234)
+	}
+}
+
+// Tests that a dev node can be built and started, accepting transactions right
+// away.
+func TestBuildDevNode(t *testing.T) {
+	stack, err := New().
+		WithChain(core.DeveloperGenesisBlock(30_000_000, common.Address{0x01})).
+		WithDev(0).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build node: %v", err)
+	}
+	defer stack.Close()
+
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	if !stack.Ori.Synced() {
+		t.Errorf("dev node not accepting transactions")
+	}
+	client := stack.Attach()
+	defer client.Close()
+
+	var chainID hexutil.Big
+	if err := client.Call(&chainID, "eth_chainId"); err != nil {
+		t.Fatalf("failed to query chain ID: %v", err)
+	}
+	if want := params.AllDevChainProtocolChanges.ChainID; chainID.ToInt().Cmp(want) != 0 {
+		t.Errorf("chain ID mismatch: have %v, want %v", chainID.ToInt(), want)
+	}
+}
@@ -24,9 +24,12 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/ethdb"
 	"github.com/gorievm/go-gori/log"
 	"github.com/gorievm/go-gori/p2p"
 	"github.com/gorievm/go-gori/rpc"
@@ -39,6 +42,7 @@ const (
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+	datadirRPCSpool        = "rpcspool"           // Path within the datadir to spool large RPC results
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -93,6 +97,10 @@ type Config struct {
 	// USB enables hardware wallet monitoring and connectivity.
 	USB bool `toml:",omitempty"`
 
+	// USBTokens is the path to a JSON list of ERC-20 tokens, extending the built-in
+	// one used to display token transfers on hardware wallets.
+	USBTokens string `toml:",omitempty"`
+
 	// SmartCardDaemonPath is the path to the smartcard daemon's socket.
 	SmartCardDaemonPath string `toml:",omitempty"`
 
@@ -204,6 +212,15 @@ type Config struct {
 	// BatchResponseMaxSize is the maximum number of bytes returned from a batched rpc call.
 	BatchResponseMaxSize int `toml:",omitempty"`
 
+	// RPCSpoolThreshold is the encoded size in bytes above which large query results
+	// (logs, block traces) are spilled to temporary files and streamed to the client
+	// instead of being buffered in memory. Zero disables spooling.
+	RPCSpoolThreshold int `toml:",omitempty"`
+
+	// RPCSpoolQuota is the maximum number of bytes all spooled results may occupy on
+	// disk at the same time.
+	RPCSpoolQuota int `toml:",omitempty"`
+
 	// JWTSecret is the path to the hex-encoded jwt secret.
 	JWTSecret string `toml:",omitempty"`
 
@@ -211,6 +228,30 @@ type Config struct {
 	EnablePersonal bool `toml:"-"`
 
 	DBEngine string `toml:",omitempty"`
+
+	// DBWrites are the durability settings of the key-value databases, defining
+	// when writes are synced to disk and whether concurrent writes are group
+	// committed. Relaxed settings trade crash safety for write throughput.
+	DBWrites ethdb.WriteConfig `toml:",omitempty"`
+
+	// DBFreezer are the storage settings of the ancient chain data, selecting the
+	// compression of new freezer tables and the volumes to spread data files on.
+	DBFreezer rawdb.FreezerOptions `toml:",omitempty"`
+
+	// DBRemoteAncients is the path-style URL of an S3-compatible bucket holding
+	// published ancient chain data, e.g. https://s3.example.com/bucket/prefix. If
+	// set, ancient chain data is read from the bucket instead of the local freezer.
+	// The credentials are taken from the standard AWS environment variables.
+	DBRemoteAncients string `toml:",omitempty"`
+
+	// DBRemoteCache is the size in megabytes of the local cache of ancient chain
+	// data read from the remote bucket.
+	DBRemoteCache int `toml:",omitempty"`
+
+	// ShutdownTimeout is the maximum time each registered service is given to
+	// stop before the node escalates and moves on without it. The databases are
+	// only closed once all services returned. Zero means the default.
+	ShutdownTimeout time.Duration `toml:",omitempty"`
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
@@ -21,6 +21,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/gorievm/go-gori/p2p"
 	"github.com/gorievm/go-gori/p2p/nat"
@@ -34,6 +35,8 @@ const (
 	DefaultWSPort   = 8546        // Default TCP port for the websocket RPC server
 	DefaultAuthHost = "localhost" // Default host interface for the authenticated apis
 	DefaultAuthPort = 8551        // Default port for the authenticated apis
+
+	DefaultShutdownTimeout = 30 * time.Second // Default time a single service is given to stop
 )
 
 var (
@@ -58,13 +61,15 @@ var DefaultConfig = Config{
 	WSModules:            []string{"net", "web3"},
 	BatchRequestLimit:    1000,
 	BatchResponseMaxSize: 25 * 1000 * 1000,
+	RPCSpoolQuota:        1024 * 1024 * 1024,
 	GraphQLVirtualHosts:  []string{"localhost"},
 	P2P: p2p.Config{
 		ListenAddr: ":30303",
 		MaxPeers:   50,
 		NAT:        nat.Any(),
 	},
-	DBEngine: "", // Use whatever exists, will default to Pebble if non-existent and supported
+	DBEngine:      "", // Use whatever exists, will default to Pebble if non-existent and supported
+	DBRemoteCache: 4096,
 }
 
 // DefaultDataDir is the default data directory to use for the databases and other
@@ -16,6 +16,8 @@
 
 package node
 
+import "time"
+
 // Lifecycle encompasses the behavior of services that can be started and stopped
 // on the node. Lifecycle management is delegated to the node, but it is the
 // responsibility of the service-specific package to configure and register the
@@ -29,3 +31,19 @@ type Lifecycle interface {
 	// are all terminated.
 	Stop() error
 }
+
+// StopTimeouter is an optional interface for lifecycles that need a shutdown
+// deadline different from the node-wide default (Config.ShutdownTimeout).
+type StopTimeouter interface {
+	// StopTimeout returns the maximum time Stop may take before the node
+	// escalates the shutdown of the service.
+	StopTimeout() time.Duration
+}
+
+// ForceStopper is an optional interface for lifecycles that can abort their
+// shutdown procedure if it takes too long, e.g. by skipping a final flush.
+type ForceStopper interface {
+	// ForceStop is called if Stop did not return within the service's deadline.
+	// It must not block and should make the pending Stop call return quickly.
+	ForceStop()
+}
@@ -27,12 +27,14 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gorievm/go-gori/accounts"
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/common/hexutil"
 	"github.com/gorievm/go-gori/core/rawdb"
 	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/ethdb/s3"
 	"github.com/gorievm/go-gori/event"
 	"github.com/gorievm/go-gori/log"
 	"github.com/gorievm/go-gori/p2p"
@@ -63,8 +65,12 @@ type Node struct {
 	wsAuth        *httpServer //
 	ipc           *ipcServer  // Stores information about the ipc http server
 	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+	spool         *rpc.Spool  // Disk spool for oversized RPC results, nil if disabled
+
+	notFound func(method string) error // Optional handler of calls to unknown RPC methods
 
 	databases map[*closeTrackingDB]struct{} // All open databases
+	stopping  sync.WaitGroup                // Lifecycle Stop calls in flight, including abandoned ones
 }
 
 const (
@@ -126,6 +132,20 @@ func New(conf *Config) (*Node, error) {
 	}
 	node.keyDir = keyDir
 	node.keyDirTemp = isEphem
+
+	// Set up the result spool, dropping any files left behind by a previous run.
+	if conf.RPCSpoolThreshold > 0 {
+		dir := node.config.ResolvePath(datadirRPCSpool)
+		if dir != "" {
+			if err := os.RemoveAll(dir); err != nil {
+				return nil, err
+			}
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				return nil, err
+			}
+		}
+		node.spool = rpc.NewSpool(dir, uint64(conf.RPCSpoolThreshold), uint64(conf.RPCSpoolQuota))
+	}
 	// Creates an empty AccountManager with no backends. Callers (e.g. cmd/gori)
 	// are required to add the backends later on.
 	node.accman = accounts.NewManager(&accounts.Config{InsecureUnlockAllowed: conf.InsecureUnlockAllowed})
@@ -138,6 +158,9 @@ func New(conf *Config) (*Node, error) {
 	if node.server.Config.NodeDatabase == "" {
 		node.server.Config.NodeDatabase = node.config.NodeDB()
 	}
+	if dir := node.server.Config.CaptureDir; dir != "" && conf.DataDir != "" {
+		node.server.Config.CaptureDir = node.config.ResolvePath(dir)
+	}
 
 	// Check HTTP/WS prefixes are valid.
 	if err := validatePrefix("HTTP", conf.HTTPPathPrefix); err != nil {
@@ -229,6 +252,10 @@ func (n *Node) Close() error {
 
 // doClose releases resources acquired by New(), collecting errors.
 func (n *Node) doClose(errs []error) error {
+	// Abandoned services may still be flushing their data, so the databases
+	// must not be pulled out from under them.
+	n.waitStopping()
+
 	// Close databases. This needs the lock because it needs to
 	// synchronize with OpenDatabase*.
 	n.lock.Lock()
@@ -293,16 +320,22 @@ func containsLifecycle(lfs []Lifecycle, l Lifecycle) bool {
 func (n *Node) stopServices(running []Lifecycle) error {
 	n.stopRPC()
 
-	// Stop running lifecycles in reverse order.
-	failure := &StopError{Services: make(map[reflect.Type]error)}
+	// Stop running lifecycles in reverse order, each within its own deadline.
+	var (
+		failure = &StopError{Services: make(map[reflect.Type]error)}
+		report  = &shutdownReport{start: time.Now()}
+	)
 	for i := len(running) - 1; i >= 0; i-- {
-		if err := running[i].Stop(); err != nil {
-			failure.Services[reflect.TypeOf(running[i])] = err
+		result := n.stopLifecycle(running[i])
+		if result.err != nil {
+			failure.Services[result.service] = result.err
 		}
+		report.services = append(report.services, result)
 	}
 
 	// Stop p2p networking.
 	n.server.Stop()
+	report.log(n.log)
 
 	if len(failure.Services) > 0 {
 		return failure
@@ -395,6 +428,7 @@ func (n *Node) startRPC() error {
 
 	// Configure IPC.
 	if n.ipc.endpoint != "" {
+		n.ipc.notFound = n.notFound
 		if err := n.ipc.start(apis); err != nil {
 			return err
 		}
@@ -407,6 +441,7 @@ func (n *Node) startRPC() error {
 	rpcConfig := rpcEndpointConfig{
 		batchItemLimit:         n.config.BatchRequestLimit,
 		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		notFound:               n.notFound,
 	}
 
 	initHttp := func(server *httpServer, port int) error {
@@ -577,6 +612,20 @@ func (n *Node) RegisterProtocols(protocols []p2p.Protocol) {
 	n.server.Protocols = append(n.server.Protocols, protocols...)
 }
 
+// SetMethodNotFoundHandler sets a function consulted by all RPC servers of the
+// node when a request calls an unknown method. If it returns a non-nil error, that
+// error is sent to the caller instead of the default method not found error.
+func (n *Node) SetMethodNotFoundHandler(fn func(method string) error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.state != initializingState {
+		panic("can't set method not found handler on running/stopped node")
+	}
+	n.notFound = fn
+	n.inprocHandler.SetMethodNotFoundHandler(fn)
+}
+
 // RegisterAPIs registers the APIs a service provides on the node.
 func (n *Node) RegisterAPIs(apis []rpc.API) {
 	n.lock.Lock()
@@ -631,6 +680,12 @@ func (n *Node) RPCHandler() (*rpc.Server, error) {
 	return n.inprocHandler, nil
 }
 
+// RPCSpool returns the spool used by APIs to stream oversized results from disk,
+// or nil if spooling is disabled.
+func (n *Node) RPCSpool() *rpc.Spool {
+	return n.spool
+}
+
 // Config returns the configuration of node.
 func (n *Node) Config() *Config {
 	return n.config
@@ -727,6 +782,7 @@ func (n *Node) OpenDatabase(name string, cache, handles int, namespace string, r
 			Cache:     cache,
 			Handles:   handles,
 			ReadOnly:  readonly,
+			Writes:    n.config.DBWrites,
 		})
 	}
 
@@ -752,6 +808,14 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient
 	if n.config.DataDir == "" {
 		db = rawdb.NewMemoryDatabase()
 	} else {
+		var remote ethdb.ObjectStore
+		if n.config.DBRemoteAncients != "" {
+			store, err := s3.New(s3.ConfigFromEnv(n.config.DBRemoteAncients))
+			if err != nil {
+				return nil, err
+			}
+			remote = store
+		}
 		db, err = rawdb.Open(rawdb.OpenOptions{
 			Type:              n.config.DBEngine,
 			Directory:         n.ResolvePath(name),
@@ -760,6 +824,10 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient
 			Cache:             cache,
 			Handles:           handles,
 			ReadOnly:          readonly,
+			Writes:            n.config.DBWrites,
+			Freezer:           n.config.DBFreezer,
+			RemoteAncients:    remote,
+			RemoteCache:       uint64(n.config.DBRemoteCache) * 1024 * 1024,
 		})
 	}
 
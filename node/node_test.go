@@ -25,6 +25,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorievm/go-gori/crypto"
 	"github.com/gorievm/go-gori/ethdb"
@@ -383,6 +384,61 @@ func TestLifecycleTerminationGuarantee(t *testing.T) {
 	stack.server.PrivateKey = testNodeKey
 }
 
+// Tests that services exceeding their stop deadline are escalated and, if that
+// does not help either, abandoned so the remaining services are stopped. The
+// databases must stay open until the abandoned service returns.
+func TestLifecycleStopDeadline(t *testing.T) {
+	stack, err := New(testNodeConfig())
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	var (
+		stuck     = NewStuckService(50 * time.Millisecond)
+		forceable = &ForceableService{NewStuckService(50 * time.Millisecond)}
+	)
+	stack.RegisterLifecycle(stuck)
+	stack.RegisterLifecycle(forceable)
+	db, err := stack.OpenDatabase("state", 0, 0, "", false)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start protocol stack: %v", err)
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- stack.Close() }()
+
+	// The stuck service gets abandoned, but its Stop is still running
+	select {
+	case err := <-errc:
+		t.Fatalf("node closed while abandoned service still stopping: %v", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+	if err := db.Put([]byte("key"), []byte("value")); err != nil {
+		t.Fatalf("database closed under abandoned service: %v", err)
+	}
+	close(stuck.release)
+
+	select {
+	case err = <-errc:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("node not closed after abandoned service returned")
+	}
+	failure, ok := err.(*StopError)
+	if !ok {
+		t.Fatalf("termination failure mismatch: have %v, want StopError", err)
+	}
+	if err := failure.Services[reflect.TypeOf(stuck)]; err != ErrStopTimeout {
+		t.Fatalf("stuck service failure mismatch: have %v, want %v", err, ErrStopTimeout)
+	}
+	if len(failure.Services) != 1 {
+		t.Fatalf("failure count mismatch: have %d, want %d", len(failure.Services), 1)
+	}
+	if err := db.Put([]byte("key"), []byte("value")); err == nil {
+		t.Fatalf("database not closed after shutdown")
+	}
+}
+
 // Tests whether a handler can be successfully mounted on the canonical HTTP server
 // on the given prefix
 func TestRegisterHandler_Successful(t *testing.T) {
@@ -56,6 +56,7 @@ type rpcEndpointConfig struct {
 	jwtSecret              []byte // optional JWT secret
 	batchItemLimit         int
 	batchResponseSizeLimit int
+	notFound               func(method string) error // optional handler of unknown method calls
 }
 
 type rpcHandler struct {
@@ -304,6 +305,7 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	srv.SetMethodNotFoundHandler(config.notFound)
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
@@ -336,6 +338,7 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	srv.SetMethodNotFoundHandler(config.notFound)
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
@@ -579,6 +582,7 @@ func newGzipHandler(next http.Handler) http.Handler {
 type ipcServer struct {
 	log      log.Logger
 	endpoint string
+	notFound func(method string) error // optional handler of unknown method calls
 
 	mu       sync.Mutex
 	listener net.Listener
@@ -602,6 +606,7 @@ func (is *ipcServer) start(apis []rpc.API) error {
 		is.log.Warn("IPC opening failed", "url", is.endpoint, "error", err)
 		return err
 	}
+	srv.SetMethodNotFoundHandler(is.notFound)
 	is.log.Info("IPC endpoint opened", "url", is.endpoint)
 	is.listener, is.srv = listener, srv
 	return nil
@@ -0,0 +1,157 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/log"
+)
+
+const (
+	// forceStopGrace is the maximum time a service is given to return from Stop
+	// after its deadline was hit and ForceStop was invoked.
+	forceStopGrace = 5 * time.Second
+
+	// abandonedStopLogInterval is the interval between the warnings logged while
+	// waiting for abandoned services before closing the databases.
+	abandonedStopLogInterval = 10 * time.Second
+)
+
+// serviceStop is the outcome of stopping a single lifecycle.
+type serviceStop struct {
+	service   reflect.Type
+	elapsed   time.Duration
+	err       error
+	forced    bool // Whether the deadline was hit and the stop escalated
+	abandoned bool // Whether the service failed to stop even after escalation
+}
+
+// shutdownReport collects the outcome of stopping all the node's lifecycles.
+type shutdownReport struct {
+	start    time.Time
+	services []serviceStop
+}
+
+// log prints the per-service outcome and a summary of the shutdown.
+func (r *shutdownReport) log(logger log.Logger) {
+	var failed, forced, abandoned int
+	for _, s := range r.services {
+		ctx := []interface{}{"service", s.service, "elapsed", common.PrettyDuration(s.elapsed)}
+		if s.err != nil {
+			failed++
+			ctx = append(ctx, "err", s.err)
+		}
+		if s.forced {
+			forced++
+		}
+		switch {
+		case s.abandoned:
+			abandoned++
+			logger.Error("Service abandoned during shutdown", ctx...)
+		case s.forced:
+			logger.Warn("Service stopped after escalation", ctx...)
+		case s.err != nil:
+			logger.Warn("Service stopped with error", ctx...)
+		default:
+			logger.Debug("Service stopped", ctx...)
+		}
+	}
+	logger.Info("Node shutdown report", "services", len(r.services), "failed", failed,
+		"forced", forced, "abandoned", abandoned, "elapsed", common.PrettyDuration(time.Since(r.start)))
+}
+
+// stopTimeout returns the deadline the given lifecycle has to stop.
+func (n *Node) stopTimeout(lifecycle Lifecycle) time.Duration {
+	if st, ok := lifecycle.(StopTimeouter); ok {
+		if timeout := st.StopTimeout(); timeout > 0 {
+			return timeout
+		}
+	}
+	if n.config.ShutdownTimeout > 0 {
+		return n.config.ShutdownTimeout
+	}
+	return DefaultShutdownTimeout
+}
+
+// stopLifecycle stops a single lifecycle. If the service does not return within
+// its deadline, it is asked to force stop and abandoned if that doesn't help
+// either, letting the shutdown of the remaining services proceed. An abandoned
+// Stop keeps running and is waited for before the databases are closed.
+func (n *Node) stopLifecycle(lifecycle Lifecycle) serviceStop {
+	var (
+		result  = serviceStop{service: reflect.TypeOf(lifecycle)}
+		start   = time.Now()
+		done    = make(chan error, 1)
+		timeout = n.stopTimeout(lifecycle)
+	)
+	n.stopping.Add(1)
+	go func() {
+		defer n.stopping.Done()
+		done <- lifecycle.Stop()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case result.err = <-done:
+	case <-timer.C:
+		result.forced = true
+		n.log.Warn("Service is slow to stop, escalating", "service", result.service, "timeout", timeout)
+		if fs, ok := lifecycle.(ForceStopper); ok {
+			fs.ForceStop()
+		}
+		grace := forceStopGrace
+		if timeout < grace {
+			grace = timeout
+		}
+		timer.Reset(grace)
+
+		select {
+		case result.err = <-done:
+		case <-timer.C:
+			result.abandoned = true
+			result.err = ErrStopTimeout
+		}
+	}
+	result.elapsed = time.Since(start)
+	return result
+}
+
+// waitStopping blocks until all the lifecycle Stop calls returned, including the
+// abandoned ones, periodically reporting that the shutdown is held up.
+func (n *Node) waitStopping() {
+	done := make(chan struct{})
+	go func() {
+		n.stopping.Wait()
+		close(done)
+	}()
+	ticker := time.NewTicker(abandonedStopLogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			n.log.Warn("Waiting for abandoned services to stop before closing databases")
+		}
+	}
+}
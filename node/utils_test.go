@@ -20,6 +20,8 @@
 package node
 
 import (
+	"time"
+
 	"github.com/gorievm/go-gori/p2p"
 	"github.com/gorievm/go-gori/rpc"
 )
@@ -104,3 +106,28 @@ func (f *FullService) APIs() []rpc.API {
 		},
 	}
 }
+
+// StuckService is a Lifecycle whose Stop blocks until it is released, used to
+// test the shutdown deadlines.
+type StuckService struct {
+	NoopLifecycle
+
+	timeout time.Duration
+	release chan struct{}
+}
+
+func NewStuckService(timeout time.Duration) *StuckService {
+	return &StuckService{timeout: timeout, release: make(chan struct{})}
+}
+
+func (s *StuckService) Stop() error {
+	<-s.release
+	return nil
+}
+
+func (s *StuckService) StopTimeout() time.Duration { return s.timeout }
+
+// ForceableService wraps a StuckService that can be aborted via ForceStop.
+type ForceableService struct{ *StuckService }
+
+func (s *ForceableService) ForceStop() { close(s.release) }
@@ -0,0 +1,107 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bytes"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/p2p/capture"
+)
+
+// captureTransport wraps the transport of a peer connection, recording all the
+// messages exchanged after the protocol handshake into a capture writer.
+type captureTransport struct {
+	transport
+
+	writer  *capture.Writer
+	peer    *Peer
+	failed  *atomic.Bool // Whether a write failure was already reported
+	logger  log.Logger
+	address string
+}
+
+// newCaptureTransport wraps the transport of a peer with message capturing.
+func newCaptureTransport(writer *capture.Writer, failed *atomic.Bool, p *Peer) *captureTransport {
+	return &captureTransport{
+		transport: p.rw.transport,
+		writer:    writer,
+		peer:      p,
+		failed:    failed,
+		logger:    p.log,
+		address:   p.RemoteAddr().String(),
+	}
+}
+
+// ReadMsg reads a message from the underlying transport and records it.
+func (t *captureTransport) ReadMsg() (Msg, error) {
+	msg, err := t.transport.ReadMsg()
+	if err != nil {
+		return msg, err
+	}
+	msg.Payload, err = t.record(msg, true)
+	return msg, err
+}
+
+// WriteMsg records a message and writes it to the underlying transport.
+func (t *captureTransport) WriteMsg(msg Msg) error {
+	var err error
+	if msg.Payload, err = t.record(msg, false); err != nil {
+		return err
+	}
+	return t.transport.WriteMsg(msg)
+}
+
+// record captures a message, returning a fresh reader over its payload to use
+// in place of the consumed original one.
+func (t *captureTransport) record(msg Msg, inbound bool) (io.Reader, error) {
+	payload, err := io.ReadAll(msg.Payload)
+	if err != nil {
+		return nil, err
+	}
+	rec := &capture.Record{
+		Time:    uint64(time.Now().UnixNano()),
+		Peer:    t.peer.ID(),
+		Remote:  t.address,
+		Inbound: inbound,
+		Size:    msg.Size,
+		Payload: payload,
+	}
+	rec.Protocol, rec.Version, rec.Code = t.resolve(msg.Code)
+
+	if err := t.writer.Write(rec); err != nil && t.failed.CompareAndSwap(false, true) {
+		t.logger.Warn("Failed to capture message", "err", err)
+	}
+	return bytes.NewReader(payload), nil
+}
+
+// resolve maps an absolute message code of the connection to the protocol it
+// belongs to and the code relative to that protocol.
+func (t *captureTransport) resolve(code uint64) (string, uint, uint64) {
+	if code < baseProtocolLength {
+		return "p2p", baseProtocolVersion, code
+	}
+	for _, proto := range t.peer.running {
+		if code >= proto.offset && code < proto.offset+proto.Length {
+			return proto.Name, proto.Version, code - proto.offset
+		}
+	}
+	return "", 0, code
+}
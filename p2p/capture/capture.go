@@ -0,0 +1,121 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package capture implements recording the devp2p messages exchanged with remote
+// peers into rotating binary logs, and reading them back for inspection.
+//
+// A capture file starts with a magic string and a format version, followed by a
+// stream of RLP encoded records, one per message.
+package capture
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gorievm/go-gori/p2p/enode"
+)
+
+const (
+	fileMagic   = "gcap" // Magic prefix of capture files
+	fileVersion = 1      // Version of the capture file format
+	filePrefix  = "capture-"
+	fileSuffix  = ".gcap"
+)
+
+// Record is a single captured message.
+type Record struct {
+	Time     uint64   // Time the message was sent or received, in Unix nanoseconds
+	Peer     enode.ID // Node ID of the remote peer
+	Remote   string   // Network address of the remote peer
+	Inbound  bool     // Whether the message was received from the peer
+	Protocol string   // Name of the protocol, "p2p" for the base devp2p protocol
+	Version  uint     // Version of the protocol negotiated with the peer
+	Code     uint64   // Message code relative to the protocol
+	Size     uint32   // Size of the message payload
+	Payload  []byte   // Raw RLP payload of the message
+}
+
+// Timestamp returns the time the message was sent or received.
+func (r *Record) Timestamp() time.Time {
+	return time.Unix(0, int64(r.Time))
+}
+
+// Name returns the name of the captured message, falling back to its code for
+// unknown protocols and messages.
+func (r *Record) Name() string {
+	return MessageName(r.Protocol, r.Code)
+}
+
+// Direction returns the direction of the message, as seen from the local node.
+func (r *Record) Direction() string {
+	if r.Inbound {
+		return "<<"
+	}
+	return ">>"
+}
+
+// String implements fmt.Stringer, producing a one line summary of the record.
+func (r *Record) String() string {
+	return fmt.Sprintf("%s %s %x %s/%d %s size=%d",
+		r.Timestamp().Format("2006-01-02T15:04:05.000000"), r.Direction(), r.Peer[:8], r.Protocol, r.Version, r.Name(), r.Size)
+}
+
+// messageNames are the names of the messages of the well known protocols, keyed
+// by protocol name and message code.
+var messageNames = map[string]map[uint64]string{
+	"p2p": {
+		0x00: "Hello",
+		0x01: "Disconnect",
+		0x02: "Ping",
+		0x03: "Pong",
+	},
+	"eth": {
+		0x00: "Status",
+		0x01: "NewBlockHashes",
+		0x02: "Transactions",
+		0x03: "GetBlockHeaders",
+		0x04: "BlockHeaders",
+		0x05: "GetBlockBodies",
+		0x06: "BlockBodies",
+		0x07: "NewBlock",
+		0x08: "NewPooledTransactionHashes",
+		0x09: "GetPooledTransactions",
+		0x0a: "PooledTransactions",
+		0x0d: "GetNodeData",
+		0x0e: "NodeData",
+		0x0f: "GetReceipts",
+		0x10: "Receipts",
+	},
+	"snap": {
+		0x00: "GetAccountRange",
+		0x01: "AccountRange",
+		0x02: "GetStorageRanges",
+		0x03: "StorageRanges",
+		0x04: "GetByteCodes",
+		0x05: "ByteCodes",
+		0x06: "GetTrieNodes",
+		0x07: "TrieNodes",
+	},
+}
+
+// MessageName returns the name of a message of the given protocol, or its hex
+// code if the message is not known.
+func MessageName(protocol string, code uint64) string {
+	if name, ok := messageNames[protocol][code]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%02x", code)
+}
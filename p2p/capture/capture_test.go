@@ -0,0 +1,144 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package capture
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorievm/go-gori/p2p/enode"
+)
+
+// readAll reads all the records from the capture files in a directory.
+func readAll(t *testing.T, dir string) []*Record {
+	t.Helper()
+
+	files, err := Files(dir)
+	if err != nil {
+		t.Fatalf("failed to list capture files: %v", err)
+	}
+	var recs []*Record
+	for _, file := range files {
+		r, err := Open(file)
+		if err != nil {
+			t.Fatalf("failed to open capture file: %v", err)
+		}
+		for {
+			rec, err := r.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("failed to read record: %v", err)
+			}
+			recs = append(recs, rec)
+		}
+		r.Close()
+	}
+	return recs
+}
+
+// Tests that capture files are rotated and pruned, with the retained records
+// read back in order, also across writer restarts.
+func TestWriterRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	writer, err := NewWriter(Config{Dir: dir, FileSize: 256, Files: 3})
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	for i := uint64(0); i < 40; i++ {
+		if err := writer.Write(&Record{Time: i, Protocol: "eth", Version: 68, Code: i % 16, Size: 64, Payload: make([]byte, 64)}); err != nil {
+			t.Fatalf("failed to write record %d: %v", i, err)
+		}
+	}
+	writer.Close()
+
+	files, _ := Files(dir)
+	if len(files) != 3 {
+		t.Fatalf("file count mismatch: have %d, want 3", len(files))
+	}
+	recs := readAll(t, dir)
+	if len(recs) == 0 || recs[len(recs)-1].Time != 39 {
+		t.Fatalf("last record mismatch: have %d records", len(recs))
+	}
+	for i := 1; i < len(recs); i++ {
+		if recs[i].Time != recs[i-1].Time+1 {
+			t.Fatalf("record %d out of order: have time %d, previous %d", i, recs[i].Time, recs[i-1].Time)
+		}
+	}
+	// Restart the writer and ensure it continues after the existing files
+	if writer, err = NewWriter(Config{Dir: dir, FileSize: 256, Files: 3}); err != nil {
+		t.Fatalf("failed to reopen writer: %v", err)
+	}
+	writer.Write(&Record{Time: 40})
+	writer.Close()
+
+	if recs = readAll(t, dir); recs[len(recs)-1].Time != 40 {
+		t.Errorf("record after restart mismatch: have time %d, want 40", recs[len(recs)-1].Time)
+	}
+	if writer.Write(&Record{}) != errClosed {
+		t.Errorf("write after close succeeded")
+	}
+}
+
+// Tests that files not starting with the capture header are rejected.
+func TestReaderInvalidFile(t *testing.T) {
+	path := t.TempDir() + "/capture-000001.gcap"
+	os.WriteFile(path, []byte("not a capture"), 0600)
+	if _, err := Open(path); err != errInvalidMagic {
+		t.Errorf("open error mismatch: have %v, want %v", err, errInvalidMagic)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	rec := &Record{
+		Time:     uint64(time.Unix(1000, 0).UnixNano()),
+		Peer:     enode.HexID("ab12000000000000000000000000000000000000000000000000000000000000"),
+		Inbound:  true,
+		Protocol: "eth",
+		Version:  68,
+		Code:     0x04,
+	}
+	tests := []struct {
+		filter Filter
+		match  bool
+	}{
+		{Filter{}, true},
+		{Filter{Peer: "ab1"}, true},
+		{Filter{Peer: "0xAB12"}, true},
+		{Filter{Peer: "ab2"}, false},
+		{Filter{Protocol: "eth"}, true},
+		{Filter{Protocol: "snap"}, false},
+		{Filter{Messages: []string{"blockheaders"}}, true},
+		{Filter{Messages: []string{"Status", "0x04"}}, true},
+		{Filter{Messages: []string{"Status"}}, false},
+		{Filter{Inbound: true}, true},
+		{Filter{Outbound: true}, false},
+		{Filter{Since: time.Unix(999, 0), Until: time.Unix(1001, 0)}, true},
+		{Filter{Since: time.Unix(1001, 0)}, false},
+		{Filter{Until: time.Unix(999, 0)}, false},
+	}
+	for i, tt := range tests {
+		if have := tt.filter.Match(rec); have != tt.match {
+			t.Errorf("test %d: match mismatch: have %v, want %v", i, have, tt.match)
+		}
+	}
+}
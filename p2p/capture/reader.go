@@ -0,0 +1,135 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package capture
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorievm/go-gori/rlp"
+)
+
+var errInvalidMagic = errors.New("not a capture file")
+
+// Reader iterates over the records of a capture file.
+type Reader struct {
+	stream *rlp.Stream
+	closer io.Closer
+}
+
+// NewReader creates a reader over a capture stream, validating its header.
+func NewReader(r io.Reader) (*Reader, error) {
+	buf := bufio.NewReader(r)
+
+	header := make([]byte, len(fileMagic)+1)
+	if _, err := io.ReadFull(buf, header); err != nil {
+		return nil, errInvalidMagic
+	}
+	if string(header[:len(fileMagic)]) != fileMagic {
+		return nil, errInvalidMagic
+	}
+	if version := header[len(fileMagic)]; version != fileVersion {
+		return nil, fmt.Errorf("unsupported capture version %d", version)
+	}
+	return &Reader{stream: rlp.NewStream(buf, 0)}, nil
+}
+
+// Open opens a capture file for reading.
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	r.closer = file
+	return r, nil
+}
+
+// Read returns the next record of the capture, or io.EOF at the end of it. A
+// record truncated by an unclean shutdown is reported as io.ErrUnexpectedEOF.
+func (r *Reader) Read() (*Record, error) {
+	rec := new(Record)
+	if err := r.stream.Decode(rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Close closes the underlying file, if the reader was created via Open.
+func (r *Reader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
+
+// Filter selects the captured records matching all of its set criteria.
+type Filter struct {
+	Peer     string    // Hex prefix of the remote peer's node ID
+	Protocol string    // Name of the protocol
+	Messages []string  // Names or hex codes of the messages
+	Inbound  bool      // Select only messages received from peers
+	Outbound bool      // Select only messages sent to peers
+	Since    time.Time // Select only messages captured after this time
+	Until    time.Time // Select only messages captured before this time
+}
+
+// Match checks whether a record satisfies all the criteria of the filter.
+func (f *Filter) Match(rec *Record) bool {
+	if f.Peer != "" && !strings.HasPrefix(rec.Peer.String(), strings.ToLower(strings.TrimPrefix(f.Peer, "0x"))) {
+		return false
+	}
+	if f.Protocol != "" && f.Protocol != rec.Protocol {
+		return false
+	}
+	if len(f.Messages) > 0 {
+		var matched bool
+		for _, msg := range f.Messages {
+			if strings.EqualFold(msg, rec.Name()) {
+				matched = true
+				break
+			}
+			if code, err := strconv.ParseUint(strings.TrimPrefix(msg, "0x"), 16, 64); err == nil && code == rec.Code {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if (f.Inbound && !rec.Inbound) || (f.Outbound && rec.Inbound) {
+		return false
+	}
+	if !f.Since.IsZero() && rec.Timestamp().Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && rec.Timestamp().After(f.Until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package capture
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/rlp"
+)
+
+const (
+	// DefaultFileSize is the default size after which capture files are rotated.
+	DefaultFileSize = 64 * 1024 * 1024
+
+	// DefaultFiles is the default number of capture files retained on disk.
+	DefaultFiles = 16
+)
+
+var errClosed = errors.New("capture writer closed")
+
+// Config contains the settings of a capture writer.
+type Config struct {
+	Dir      string // Directory to store the capture files in
+	FileSize uint64 // Size after which the current file is rotated
+	Files    int    // Number of files to retain, oldest ones are deleted
+}
+
+// sanitize checks the provided user configurations and changes anything that's
+// unreasonable or unworkable.
+func (config *Config) sanitize() Config {
+	conf := *config
+	if conf.FileSize == 0 {
+		conf.FileSize = DefaultFileSize
+	}
+	if conf.Files < 1 {
+		log.Warn("Sanitizing invalid capture file count", "provided", conf.Files, "updated", DefaultFiles)
+		conf.Files = DefaultFiles
+	}
+	return conf
+}
+
+// Writer records messages into a set of rotating capture files. It is safe for
+// concurrent use.
+type Writer struct {
+	config Config
+
+	file *os.File      // Capture file currently being written
+	buf  *bufio.Writer // Buffered writer of the current file
+	size uint64        // Number of bytes written into the current file
+	seq  uint64        // Sequence number of the current file
+
+	closed bool
+	lock   sync.Mutex
+}
+
+// NewWriter creates a capture writer, storing files in the configured directory.
+// Existing captures in the directory are retained and continued after.
+func NewWriter(config Config) (*Writer, error) {
+	config = config.sanitize()
+	if err := os.MkdirAll(config.Dir, 0700); err != nil {
+		return nil, err
+	}
+	files, err := Files(config.Dir)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{config: config}
+	if len(files) > 0 {
+		w.seq = fileSeq(files[len(files)-1])
+	}
+	if err := w.rotate(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends a record to the current capture file, rotating it if it grew
+// beyond its size limit.
+func (w *Writer) Write(rec *Record) error {
+	blob, err := rlp.EncodeToBytes(rec)
+	if err != nil {
+		return err
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.closed {
+		return errClosed
+	}
+	if w.size >= w.config.FileSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	if _, err := w.buf.Write(blob); err != nil {
+		return err
+	}
+	w.size += uint64(len(blob))
+	return w.buf.Flush()
+}
+
+// Close flushes and closes the current capture file.
+func (w *Writer) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.closeFile()
+}
+
+// closeFile flushes and closes the current capture file, if any.
+func (w *Writer) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	err := w.file.Close()
+	w.file, w.buf = nil, nil
+	return err
+}
+
+// rotate closes the current capture file, starts a new one and deletes the
+// oldest files beyond the retention limit.
+func (w *Writer) rotate() error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	w.seq++
+	file, err := os.OpenFile(filepath.Join(w.config.Dir, fmt.Sprintf("%s%06d%s", filePrefix, w.seq, fileSuffix)), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	w.file, w.buf = file, bufio.NewWriter(file)
+	w.buf.WriteString(fileMagic)
+	w.buf.WriteByte(fileVersion)
+	w.size = uint64(len(fileMagic) + 1)
+
+	files, err := Files(w.config.Dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > w.config.Files {
+		if err := os.Remove(files[0]); err != nil {
+			log.Warn("Failed to delete old capture file", "path", files[0], "err", err)
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// Files returns the paths of the capture files in a directory, ordered from the
+// oldest to the newest.
+func Files(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && fileSeq(name) != 0 {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	// Sequence numbers are zero padded, but may outgrow the padding
+	sort.Slice(files, func(i, j int) bool {
+		return fileSeq(files[i]) < fileSeq(files[j])
+	})
+	return files, nil
+}
+
+// fileSeq parses the sequence number out of a capture file name, returning zero
+// if the name is not one of a capture file.
+func fileSeq(path string) uint64 {
+	name := filepath.Base(path)
+	if !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+		return 0
+	}
+	seq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return seq
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/p2p/capture"
+	"github.com/gorievm/go-gori/rlp"
+)
+
+// Tests that messages exchanged with a peer are captured with their protocol
+// relative codes, and that they are still delivered unchanged.
+func TestPeerCapture(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := capture.NewWriter(capture.Config{Dir: dir, Files: 1})
+	if err != nil {
+		t.Fatalf("failed to create capture writer: %v", err)
+	}
+	proto := Protocol{
+		Name:    "a",
+		Version: 3,
+		Length:  5,
+		Run: func(peer *Peer, rw MsgReadWriter) error {
+			if err := ExpectMsg(rw, 2, []uint{1}); err != nil {
+				t.Error(err)
+			}
+			return Send(rw, 3, []uint{2})
+		},
+	}
+	var (
+		fd1, fd2   = net.Pipe()
+		key1, key2 = newkey(), newkey()
+		c1         = &conn{fd: fd1, node: newNode(uintID(1), ""), transport: newTestTransport(&key2.PublicKey, fd1, nil), caps: []Cap{proto.cap()}}
+		c2         = &conn{fd: fd2, node: newNode(uintID(2), ""), transport: newTestTransport(&key1.PublicKey, fd2, &key1.PublicKey), caps: []Cap{proto.cap()}}
+	)
+	peer := newPeer(log.Root(), c1, []Protocol{proto})
+	c1.transport = newCaptureTransport(writer, new(atomic.Bool), peer)
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := peer.run()
+		errc <- err
+	}()
+	defer c2.close(errors.New("test done"))
+
+	if err := Send(c2, baseProtocolLength+2, []uint{1}); err != nil {
+		t.Fatalf("failed to send message: %v", err)
+	}
+	if err := ExpectMsg(c2, baseProtocolLength+3, []uint{2}); err != nil {
+		t.Fatalf("reply mismatch: %v", err)
+	}
+	select {
+	case <-errc:
+	case <-time.After(time.Second):
+		t.Fatal("peer did not terminate")
+	}
+	writer.Close()
+
+	// Ensure both messages were recorded
+	files, err := capture.Files(dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("capture files mismatch: have %v/%v, want 1", files, err)
+	}
+	r, err := capture.Open(files[0])
+	if err != nil {
+		t.Fatalf("failed to open capture: %v", err)
+	}
+	defer r.Close()
+
+	var recs []*capture.Record
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read capture: %v", err)
+		}
+		if rec.Protocol == "a" {
+			recs = append(recs, rec)
+		}
+	}
+	if len(recs) != 2 {
+		t.Fatalf("captured message count mismatch: have %d, want 2", len(recs))
+	}
+	for i, want := range []struct {
+		inbound bool
+		code    uint64
+		content []uint
+	}{{true, 2, []uint{1}}, {false, 3, []uint{2}}} {
+		rec := recs[i]
+		if rec.Inbound != want.inbound || rec.Version != 3 || rec.Code != want.code || rec.Peer != peer.ID() {
+			t.Errorf("record %d mismatch: have inbound=%v version=%d code=%d peer=%x", i, rec.Inbound, rec.Version, rec.Code, rec.Peer)
+		}
+		var content []uint
+		if err := rlp.DecodeBytes(rec.Payload, &content); err != nil || len(content) != 1 || content[0] != want.content[0] {
+			t.Errorf("record %d payload mismatch: have %v/%v, want %v", i, content, err, want.content)
+		}
+	}
+}
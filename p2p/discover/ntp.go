@@ -24,6 +24,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/gorievm/go-gori/common/timesync"
 	"github.com/gorievm/go-gori/log"
 	"golang.org/x/exp/slices"
 )
@@ -40,6 +41,7 @@ func checkClockDrift() {
 	if err != nil {
 		return
 	}
+	timesync.SetNTPDrift(drift)
 	if drift < -driftThreshold || drift > driftThreshold {
 		log.Warn(fmt.Sprintf("System clock seems off by %v, which can prevent network connectivity", drift))
 		log.Warn("Please enable network time synchronisation in system settings.")
@@ -25,10 +25,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"sync"
 	"time"
 
+	"github.com/gorievm/go-gori/common/timesync"
 	"github.com/gorievm/go-gori/crypto"
 	"github.com/gorievm/go-gori/log"
 	"github.com/gorievm/go-gori/p2p/discover/v4wire"
@@ -643,6 +645,7 @@ type packetHandlerV4 struct {
 func (t *UDPv4) verifyPing(h *packetHandlerV4, from *net.UDPAddr, fromID enode.ID, fromKey v4wire.Pubkey) error {
 	req := h.Packet.(*v4wire.Ping)
 
+	t.addClockSample(from, req.Expiration)
 	if v4wire.Expired(req.Expiration) {
 		return errExpired
 	}
@@ -685,6 +688,7 @@ func (t *UDPv4) handlePing(h *packetHandlerV4, from *net.UDPAddr, fromID enode.I
 func (t *UDPv4) verifyPong(h *packetHandlerV4, from *net.UDPAddr, fromID enode.ID, fromKey v4wire.Pubkey) error {
 	req := h.Packet.(*v4wire.Pong)
 
+	t.addClockSample(from, req.Expiration)
 	if v4wire.Expired(req.Expiration) {
 		return errExpired
 	}
@@ -696,6 +700,16 @@ func (t *UDPv4) verifyPong(h *packetHandlerV4, from *net.UDPAddr, fromID enode.I
 	return nil
 }
 
+// addClockSample feeds the sender's clock, derived from the expiration time of
+// a ping or pong, into the clock drift estimator. Samples are recorded before
+// checking expiry, since a local clock running ahead makes all packets expire.
+func (t *UDPv4) addClockSample(from *net.UDPAddr, expires uint64) {
+	if expires > math.MaxInt64 {
+		return
+	}
+	timesync.AddSample(from.IP.String(), time.Unix(int64(expires), 0).Add(-expiration))
+}
+
 // FINDNODE/v4
 
 func (t *UDPv4) verifyFindnode(h *packetHandlerV4, from *net.UDPAddr, fromID enode.ID, fromKey v4wire.Pubkey) error {
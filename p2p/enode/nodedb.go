@@ -40,6 +40,7 @@ const (
 	dbVersionKey   = "version" // Version of the database to flush if changes
 	dbNodePrefix   = "n:"      // Identifier to prefix node entries with
 	dbLocalPrefix  = "local:"
+	dbRepPrefix    = "rep:" // Identifier to prefix application reputation entries with
 	dbDiscoverRoot = "v4"
 	dbDiscv5Root   = "v5"
 
@@ -53,6 +54,11 @@ const (
 	// Local information is keyed by ID only, the full key is "local:<ID>:seq".
 	// Use localItemKey to create those keys.
 	dbLocalSeq = "seq"
+
+	// Reputation information is keyed by ID only, the full key is "rep:<ID>:score".
+	// It is kept apart from the node entries so it survives their expiration.
+	dbRepScore   = "score"
+	dbRepUpdated = "updated"
 )
 
 const (
@@ -195,6 +201,14 @@ func localItemKey(id ID, field string) []byte {
 	return key
 }
 
+// repItemKey returns the key of a node reputation item.
+func repItemKey(id ID, field string) []byte {
+	key := append([]byte(dbRepPrefix), id[:]...)
+	key = append(key, ':')
+	key = append(key, field...)
+	return key
+}
+
 // fetchInt64 retrieves an integer associated with a particular key.
 func (db *DB) fetchInt64(key []byte) int64 {
 	blob, err := db.lvl.Get(key, nil)
@@ -427,6 +441,31 @@ func (db *DB) UpdateFindFailsV5(id ID, ip net.IP, fails int) error {
 	return db.storeInt64(v5Key(id, ip, dbNodeFindFails), int64(fails))
 }
 
+// Reputation retrieves the reputation score an application protocol assigned to
+// a node, along with the time it was last updated. Unknown nodes have a zero score.
+func (db *DB) Reputation(id ID) (int64, time.Time) {
+	updated := db.fetchInt64(repItemKey(id, dbRepUpdated))
+	if updated == 0 {
+		return 0, time.Time{}
+	}
+	return db.fetchInt64(repItemKey(id, dbRepScore)), time.Unix(updated, 0)
+}
+
+// UpdateReputation stores the reputation score of a node. A zero score deletes
+// the entry altogether.
+func (db *DB) UpdateReputation(id ID, score int64, updated time.Time) error {
+	if score == 0 {
+		batch := new(leveldb.Batch)
+		batch.Delete(repItemKey(id, dbRepScore))
+		batch.Delete(repItemKey(id, dbRepUpdated))
+		return db.lvl.Write(batch, nil)
+	}
+	if err := db.storeInt64(repItemKey(id, dbRepScore), score); err != nil {
+		return err
+	}
+	return db.storeInt64(repItemKey(id, dbRepUpdated), updated.Unix())
+}
+
 // localSeq retrieves the local record sequence counter, defaulting to the current
 // timestamp if no previous exists. This ensures that wiping all data associated
 // with a node (apart from its key) will not generate already used sequence nums.
@@ -109,6 +109,32 @@ func TestDBInt64(t *testing.T) {
 	}
 }
 
+func TestDBReputation(t *testing.T) {
+	db, _ := OpenDB("")
+	defer db.Close()
+
+	id := ID{0x01}
+	if score, updated := db.Reputation(id); score != 0 || !updated.IsZero() {
+		t.Fatalf("unknown node has reputation: score %d, updated %v", score, updated)
+	}
+	now := time.Unix(time.Now().Unix(), 0)
+	if err := db.UpdateReputation(id, -42, now); err != nil {
+		t.Fatalf("failed to store reputation: %v", err)
+	}
+	if score, updated := db.Reputation(id); score != -42 || !updated.Equal(now) {
+		t.Fatalf("reputation mismatch: have (%d, %v), want (%d, %v)", score, updated, -42, now)
+	}
+	if score, _ := db.Reputation(ID{0x02}); score != 0 {
+		t.Fatalf("reputation leaked to other node: %d", score)
+	}
+	if err := db.UpdateReputation(id, 0, now); err != nil {
+		t.Fatalf("failed to reset reputation: %v", err)
+	}
+	if score, updated := db.Reputation(id); score != 0 || !updated.IsZero() {
+		t.Fatalf("reset reputation still present: score %d, updated %v", score, updated)
+	}
+}
+
 func TestDBFetchStore(t *testing.T) {
 	node := NewV4(
 		hexPubkey("1dd9d65c4552b5eb43d5ad55a2ee3f56c6cbc1c64a5c8d659f51fcd51bace24351232b8d7821617d2b29b54b81cdefb9b3e9c37d7fd5f63270bcc9e1a6f6a439"),
@@ -33,6 +33,7 @@ import (
 	"github.com/gorievm/go-gori/crypto"
 	"github.com/gorievm/go-gori/event"
 	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/p2p/capture"
 	"github.com/gorievm/go-gori/p2p/discover"
 	"github.com/gorievm/go-gori/p2p/enode"
 	"github.com/gorievm/go-gori/p2p/enr"
@@ -162,6 +163,10 @@ type Config struct {
 	// whenever a message is sent to or received from a peer
 	EnableMsgEvents bool
 
+	// CaptureDir, if set, enables recording all the messages exchanged with peers
+	// into rotating capture files within the given directory, for debugging.
+	CaptureDir string `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 
@@ -188,6 +193,9 @@ type Server struct {
 	peerFeed     event.Feed
 	log          log.Logger
 
+	capture       *capture.Writer // Message capture writer, nil if disabled
+	captureFailed atomic.Bool     // Whether a capture failure was already reported
+
 	nodedb    *enode.DB
 	localnode *enode.LocalNode
 	ntab      *discover.UDPv4
@@ -416,6 +424,12 @@ func (srv *Server) Stop() {
 	close(srv.quit)
 	srv.lock.Unlock()
 	srv.loopWG.Wait()
+
+	if srv.capture != nil {
+		if err := srv.capture.Close(); err != nil {
+			srv.log.Warn("Failed to close message capture", "err", err)
+		}
+	}
 }
 
 // sharedUDPConn implements a shared connection. Write sends messages to the underlying connection while read returns
@@ -486,6 +500,12 @@ func (srv *Server) Start() (err error) {
 	if err := srv.setupLocalNode(); err != nil {
 		return err
 	}
+	if srv.CaptureDir != "" {
+		if srv.capture, err = capture.NewWriter(capture.Config{Dir: srv.CaptureDir, Files: capture.DefaultFiles}); err != nil {
+			return err
+		}
+		srv.log.Warn("Capturing peer messages", "dir", srv.CaptureDir)
+	}
 	srv.setupPortMapping()
 
 	if srv.ListenAddr != "" {
@@ -1032,6 +1052,9 @@ func (srv *Server) launchPeer(c *conn) *Peer {
 		// to the peer.
 		p.events = &srv.peerFeed
 	}
+	if srv.capture != nil {
+		c.transport = newCaptureTransport(srv.capture, &srv.captureFailed, p)
+	}
 	go srv.runPeer(p)
 	return p
 }
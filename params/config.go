@@ -291,6 +291,15 @@ type ChainConfig struct {
 	PragueTime   *uint64 `json:"pragueTime,omitempty"`   // Prague switch time (nil = no fork, 0 = already on prague)
 	VerkleTime   *uint64 `json:"verkleTime,omitempty"`   // Verkle switch time (nil = no fork, 0 = already on verkle)
 
+	// EOFTime activates the EVM Object Format (EIP-3540, EIP-3670, EIP-4200,
+	// EIP-4750, EIP-5450) independently of the scheduled forks, so that test
+	// networks can trial EOF contracts ahead of mainnet activation.
+	EOFTime *uint64 `json:"eofTime,omitempty"` // EOF switch time (nil = no fork, 0 = already on eof)
+
+	// GoriForks are the Gori specific network upgrades, scheduled by timestamp
+	// after the upstream ones and included in the fork ID.
+	GoriForks []GoriFork `json:"goriForks,omitempty"`
+
 	// TerminalTotalDifficulty is the amount of total difficulty reached by
 	// the network that triggers the consensus upgrade.
 	TerminalTotalDifficulty *big.Int `json:"terminalTotalDifficulty,omitempty"`
@@ -304,6 +313,32 @@ type ChainConfig struct {
 	Ethash    *EthashConfig `json:"ethash,omitempty"`
 	Clique    *CliqueConfig `json:"clique,omitempty"`
 	IsDevMode bool          `json:"isDev,omitempty"`
+
+	// Execution resource limits for private networks. These apply from genesis
+	// and diverge from the consensus rules of the public networks if set.
+	MaxCallDepth  *uint64 `json:"maxCallDepth,omitempty"`  // Maximum depth of the call/create stack (nil = 1024)
+	MaxMemorySize *uint64 `json:"maxMemorySize,omitempty"` // Maximum memory in bytes of a single call frame (nil = only bounded by gas)
+
+	// GasOverrides replace selected gas costs for private networks, applying
+	// from genesis.
+	GasOverrides *GasOverrides `json:"gasOverrides,omitempty"`
+
+	// Fee market parameters of EIP-1559 for private networks, applying from
+	// genesis. The base fee is burnt unless a fee sink is set to receive it.
+	BaseFeeElasticity  *uint64         `json:"baseFeeElasticity,omitempty"`  // Ratio of the gas limit to the gas target (nil = 2)
+	BaseFeeDenominator *uint64         `json:"baseFeeDenominator,omitempty"` // Bound on the base fee change between blocks (nil = 8)
+	FeeSink            *common.Address `json:"feeSink,omitempty"`            // Recipient of the base fee (nil = burnt)
+
+	// BlockRewards replaces the block rewards of the consensus engine for private
+	// networks by a schedule of eras. Blocks before the first era are rewarded by
+	// the engine as usual. Only proof-of-work and clique blocks are rewarded.
+	BlockRewards []RewardEra `json:"blockRewards,omitempty"`
+
+	// Precompiles are custom precompiled contracts of private networks, enabled
+	// in addition to (or in place of) the standard ones.
+	Precompiles []PrecompileConfig `json:"precompiles,omitempty"`
+
+	schedule *forkSchedule // Timestamp forks scheduled at runtime, nil if not permitted
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -414,6 +449,69 @@ func (c *ChainConfig) Description() string {
 	if c.VerkleTime != nil {
 		banner += fmt.Sprintf(" - Verkle:                      @%-10v\n", *c.VerkleTime)
 	}
+	if c.EOFTime != nil {
+		banner += fmt.Sprintf(" - EOF:                         @%-10v\n", *c.EOFTime)
+	}
+	for _, fork := range c.GoriForks {
+		banner += fmt.Sprintf(" - %-28s @%-10v (gori)\n", fork.Name+":", fork.Time)
+	}
+	// Add the custom execution limits of private networks
+	if c.MaxCallDepth != nil || c.MaxMemorySize != nil {
+		banner += "\n"
+		banner += "Custom execution limits:\n"
+		if c.MaxCallDepth != nil {
+			banner += fmt.Sprintf(" - Call depth:                  %v\n", *c.MaxCallDepth)
+		}
+		if c.MaxMemorySize != nil {
+			banner += fmt.Sprintf(" - Memory per call frame:       %v bytes\n", *c.MaxMemorySize)
+		}
+	}
+	// Add the custom fee market of private networks
+	if c.BaseFeeElasticity != nil || c.BaseFeeDenominator != nil || c.FeeSink != nil {
+		banner += "\n"
+		banner += "Custom fee market:\n"
+		if c.BaseFeeElasticity != nil {
+			banner += fmt.Sprintf(" - Elasticity multiplier:       %v\n", *c.BaseFeeElasticity)
+		}
+		if c.BaseFeeDenominator != nil {
+			banner += fmt.Sprintf(" - Change denominator:          %v\n", *c.BaseFeeDenominator)
+		}
+		if c.FeeSink != nil {
+			banner += fmt.Sprintf(" - Base fee recipient:          %v\n", *c.FeeSink)
+		}
+	}
+	// Add the block reward schedule of private networks
+	if len(c.BlockRewards) > 0 {
+		banner += "\n"
+		banner += "Block reward schedule:\n"
+		for _, era := range c.BlockRewards {
+			banner += fmt.Sprintf(" - From block %-18v%v wei\n", fmt.Sprintf("%d:", era.Block), era.Reward)
+			for _, r := range era.Recipients {
+				banner += fmt.Sprintf("   - %v receives %d.%02d%%\n", r.Address, r.Share/100, r.Share%100)
+			}
+		}
+	}
+	// Add the custom gas costs of private networks
+	if c.GasOverrides != nil {
+		banner += "\n"
+		banner += "Custom gas costs:\n"
+		banner += c.GasOverrides.description()
+	}
+	// Add the custom precompiles of private networks
+	if len(c.Precompiles) > 0 {
+		banner += "\n"
+		banner += "Custom precompiles:\n"
+		for _, p := range c.Precompiles {
+			switch {
+			case p.Block != nil:
+				banner += fmt.Sprintf(" - %v: %-16s #%-8v\n", p.Address, p.Name, p.Block)
+			case p.Time != nil:
+				banner += fmt.Sprintf(" - %v: %-16s @%-10v\n", p.Address, p.Name, *p.Time)
+			default:
+				banner += fmt.Sprintf(" - %v: %s\n", p.Address, p.Name)
+			}
+		}
+	}
 	return banner
 }
 
@@ -499,22 +597,27 @@ func (c *ChainConfig) IsTerminalPoWBlock(parentTotalDiff *big.Int, totalDiff *bi
 
 // IsShanghai returns whether time is either equal to the Shanghai fork time or greater.
 func (c *ChainConfig) IsShanghai(num *big.Int, time uint64) bool {
-	return c.IsLondon(num) && isTimestampForked(c.ShanghaiTime, time)
+	return c.IsLondon(num) && isTimestampForked(c.scheduledTime("shanghai", c.ShanghaiTime), time)
 }
 
 // IsCancun returns whether num is either equal to the Cancun fork time or greater.
 func (c *ChainConfig) IsCancun(num *big.Int, time uint64) bool {
-	return c.IsLondon(num) && isTimestampForked(c.CancunTime, time)
+	return c.IsLondon(num) && isTimestampForked(c.scheduledTime("cancun", c.CancunTime), time)
 }
 
 // IsPrague returns whether num is either equal to the Prague fork time or greater.
 func (c *ChainConfig) IsPrague(num *big.Int, time uint64) bool {
-	return c.IsLondon(num) && isTimestampForked(c.PragueTime, time)
+	return c.IsLondon(num) && isTimestampForked(c.scheduledTime("prague", c.PragueTime), time)
 }
 
 // IsVerkle returns whether num is either equal to the Verkle fork time or greater.
 func (c *ChainConfig) IsVerkle(num *big.Int, time uint64) bool {
-	return c.IsLondon(num) && isTimestampForked(c.VerkleTime, time)
+	return c.IsLondon(num) && isTimestampForked(c.scheduledTime("verkle", c.VerkleTime), time)
+}
+
+// IsEOF returns whether time is either equal to the EOF fork time or greater.
+func (c *ChainConfig) IsEOF(num *big.Int, time uint64) bool {
+	return c.IsLondon(num) && isTimestampForked(c.scheduledTime("eof", c.EOFTime), time)
 }
 
 // CheckCompatible checks whether scheduled fork transitions have been imported
@@ -607,6 +710,48 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	// EOF is scheduled independently of the named forks, but relies on the
+	// Shanghai instruction set and initcode metering.
+	if c.EOFTime != nil && (c.ShanghaiTime == nil || *c.EOFTime < *c.ShanghaiTime) {
+		return fmt.Errorf("unsupported fork ordering: eofTime %v enabled before shanghaiTime", *c.EOFTime)
+	}
+	return c.CheckGoriForks()
+}
+
+// CheckResourceLimits checks that the custom execution limits of the chain, if
+// any, are within the bounds the EVM can safely operate in.
+func (c *ChainConfig) CheckResourceLimits() error {
+	if c.MaxCallDepth != nil {
+		depth := *c.MaxCallDepth
+		if depth == 0 || depth > MaxConfigurableCallDepth {
+			return fmt.Errorf("invalid maxCallDepth %d: must be between 1 and %d", depth, MaxConfigurableCallDepth)
+		}
+		// Before EIP-150, the gas forwarded to sub-calls is not reduced by the
+		// 63/64 rule, so the call depth is bounded by the limit alone.
+		if depth > CallCreateDepth && (c.EIP150Block == nil || c.EIP150Block.Sign() != 0) {
+			return fmt.Errorf("maxCallDepth %d above %d requires eip150Block at genesis", depth, CallCreateDepth)
+		}
+	}
+	if c.MaxMemorySize != nil {
+		size := *c.MaxMemorySize
+		// Memory is expanded and charged for in words, and the gas of expanding
+		// beyond the maximum would overflow.
+		if size == 0 || size%32 != 0 || size > MaxConfigurableMemorySize {
+			return fmt.Errorf("invalid maxMemorySize %d: must be a non-zero multiple of 32 up to %d", size, MaxConfigurableMemorySize)
+		}
+	}
+	return nil
+}
+
+// CheckFeeMarket checks that the custom fee market parameters of the chain, if
+// any, are usable by the base fee calculation.
+func (c *ChainConfig) CheckFeeMarket() error {
+	if c.BaseFeeElasticity != nil && *c.BaseFeeElasticity == 0 {
+		return fmt.Errorf("invalid baseFeeElasticity 0: must be positive")
+	}
+	if c.BaseFeeDenominator != nil && *c.BaseFeeDenominator == 0 {
+		return fmt.Errorf("invalid baseFeeDenominator 0: must be positive")
+	}
 	return nil
 }
 
@@ -678,19 +823,60 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkTimestampIncompatible(c.VerkleTime, newcfg.VerkleTime, headTimestamp) {
 		return newTimestampCompatError("Verkle fork timestamp", c.VerkleTime, newcfg.VerkleTime)
 	}
-	return nil
+	if isForkTimestampIncompatible(c.EOFTime, newcfg.EOFTime, headTimestamp) {
+		return newTimestampCompatError("EOF fork timestamp", c.EOFTime, newcfg.EOFTime)
+	}
+	if err := c.checkGoriForksCompatible(newcfg, headTimestamp); err != nil {
+		return err
+	}
+	if headNumber.Sign() > 0 && !c.GasOverrides.equal(newcfg.GasOverrides) {
+		return newBlockCompatError("Gas overrides", common.Big0, common.Big0)
+	}
+	if headNumber.Sign() > 0 && (c.ElasticityMultiplier() != newcfg.ElasticityMultiplier() || c.BaseFeeChangeDenominator() != newcfg.BaseFeeChangeDenominator()) {
+		return newBlockCompatError("Base fee parameters", common.Big0, common.Big0)
+	}
+	if headNumber.Sign() > 0 && !configAddressEqual(c.FeeSink, newcfg.FeeSink) {
+		return newBlockCompatError("Fee sink", common.Big0, common.Big0)
+	}
+	if err := c.checkBlockRewardsCompatible(newcfg, headNumber); err != nil {
+		return err
+	}
+	return c.checkPrecompilesCompatible(newcfg, headNumber, headTimestamp)
 }
 
 // BaseFeeChangeDenominator bounds the amount the base fee can change between blocks.
 func (c *ChainConfig) BaseFeeChangeDenominator() uint64 {
+	if c.BaseFeeDenominator != nil {
+		return *c.BaseFeeDenominator
+	}
 	return DefaultBaseFeeChangeDenominator
 }
 
 // ElasticityMultiplier bounds the maximum gas limit an EIP-1559 block may have.
 func (c *ChainConfig) ElasticityMultiplier() uint64 {
+	if c.BaseFeeElasticity != nil {
+		return *c.BaseFeeElasticity
+	}
 	return DefaultElasticityMultiplier
 }
 
+// CallDepthLimit returns the maximum depth of the call/create stack.
+func (c *ChainConfig) CallDepthLimit() uint64 {
+	if c.MaxCallDepth != nil {
+		return *c.MaxCallDepth
+	}
+	return CallCreateDepth
+}
+
+// MemoryLimit returns the maximum memory size in bytes of a single call frame,
+// or zero if the memory is only bounded by gas.
+func (c *ChainConfig) MemoryLimit() uint64 {
+	if c.MaxMemorySize != nil {
+		return *c.MaxMemorySize
+	}
+	return 0
+}
+
 // isForkBlockIncompatible returns true if a fork scheduled at block s1 cannot be
 // rescheduled to block s2 because head is already past the fork.
 func isForkBlockIncompatible(s1, s2, head *big.Int) bool {
@@ -743,6 +929,16 @@ func configTimestampEqual(x, y *uint64) bool {
 	return *x == *y
 }
 
+func configAddressEqual(x, y *common.Address) bool {
+	if x == nil {
+		return y == nil
+	}
+	if y == nil {
+		return x == nil
+	}
+	return *x == *y
+}
+
 // ConfigCompatError is raised if the locally-stored blockchain is initialised with a
 // ChainConfig that would alter the past.
 type ConfigCompatError struct {
@@ -823,7 +1019,11 @@ type Rules struct {
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
 	IsBerlin, IsLondon                                      bool
 	IsMerge, IsShanghai, IsCancun, IsPrague                 bool
-	IsVerkle                                                bool
+	IsVerkle, IsEOF                                         bool
+
+	// Precompiles maps the addresses of the active custom precompiles to the
+	// names of their implementations.
+	Precompiles map[common.Address]string
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -849,5 +1049,7 @@ func (c *ChainConfig) Rules(num *big.Int, isMerge bool, timestamp uint64) Rules
 		IsCancun:         c.IsCancun(num, timestamp),
 		IsPrague:         c.IsPrague(num, timestamp),
 		IsVerkle:         c.IsVerkle(num, timestamp),
+		IsEOF:            c.IsEOF(num, timestamp),
+		Precompiles:      c.activePrecompiles(num, timestamp),
 	}
 }
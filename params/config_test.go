@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/common/math"
 )
 
@@ -137,3 +138,276 @@ func TestConfigRules(t *testing.T) {
 		t.Errorf("expected %v to be shanghai", stamp)
 	}
 }
+
+func TestCheckResourceLimits(t *testing.T) {
+	tests := []struct {
+		depth, memory *uint64
+		eip150        *big.Int
+		wantErr       bool
+	}{
+		{nil, nil, nil, false},
+		{newUint64(1), nil, nil, false},
+		{newUint64(0), nil, nil, true},
+		{newUint64(CallCreateDepth), nil, nil, false},
+		{newUint64(CallCreateDepth + 1), nil, nil, true},
+		{newUint64(CallCreateDepth + 1), nil, big.NewInt(1), true},
+		{newUint64(CallCreateDepth + 1), nil, big.NewInt(0), false},
+		{newUint64(MaxConfigurableCallDepth), nil, big.NewInt(0), false},
+		{newUint64(MaxConfigurableCallDepth + 1), nil, big.NewInt(0), true},
+		{nil, newUint64(1024), nil, false},
+		{nil, newUint64(0), nil, true},
+		{nil, newUint64(1000), nil, true},
+		{nil, newUint64(MaxConfigurableMemorySize), nil, false},
+		{nil, newUint64(MaxConfigurableMemorySize + 32), nil, true},
+	}
+	for i, test := range tests {
+		c := &ChainConfig{MaxCallDepth: test.depth, MaxMemorySize: test.memory, EIP150Block: test.eip150}
+		if err := c.CheckResourceLimits(); (err != nil) != test.wantErr {
+			t.Errorf("test %d: error mismatch: have %v, want error %v", i, err, test.wantErr)
+		}
+	}
+}
+
+func TestCheckGasOverrides(t *testing.T) {
+	tests := []struct {
+		overrides *GasOverrides
+		berlin    *big.Int
+		wantErr   bool
+	}{
+		{nil, nil, false},
+		{&GasOverrides{TxDataNonZero: newUint64(8), Create: newUint64(0)}, nil, false},
+		{&GasOverrides{CreateData: newUint64(MaxConfigurableGasCost + 1)}, nil, true},
+		{&GasOverrides{ColdSload: newUint64(1000)}, nil, true},
+		{&GasOverrides{ColdSload: newUint64(1000)}, big.NewInt(1), true},
+		{&GasOverrides{ColdSload: newUint64(1000)}, big.NewInt(0), false},
+		{&GasOverrides{ColdSload: newUint64(4900)}, big.NewInt(0), false},
+		{&GasOverrides{ColdSload: newUint64(4901)}, big.NewInt(0), true},
+		{&GasOverrides{SstoreReset: newUint64(1000)}, big.NewInt(0), true},
+		{&GasOverrides{WarmSload: newUint64(500), SstoreSet: newUint64(499)}, big.NewInt(0), true},
+	}
+	for i, test := range tests {
+		c := &ChainConfig{GasOverrides: test.overrides, BerlinBlock: test.berlin}
+		if err := c.CheckGasOverrides(); (err != nil) != test.wantErr {
+			t.Errorf("test %d: error mismatch: have %v, want error %v", i, err, test.wantErr)
+		}
+	}
+	// Changing the overrides is only possible before the chain progressed
+	var (
+		stored  = *AllEthashProtocolChanges
+		updated = *AllEthashProtocolChanges
+	)
+	updated.GasOverrides = &GasOverrides{TxDataZero: newUint64(TxDataZeroGas)}
+	if err := stored.CheckCompatible(&updated, 10, 0); err != nil {
+		t.Errorf("override of default cost reported incompatible: %v", err)
+	}
+	updated.GasOverrides = &GasOverrides{TxDataZero: newUint64(1)}
+	if err := stored.CheckCompatible(&updated, 0, 0); err != nil {
+		t.Errorf("override at genesis reported incompatible: %v", err)
+	}
+	if err := stored.CheckCompatible(&updated, 10, 0); err == nil || err.RewindToBlock != 0 {
+		t.Errorf("wrong compatibility error for changed override: %v", err)
+	}
+}
+
+func TestCheckBlockRewards(t *testing.T) {
+	var (
+		a = common.Address{0xa}
+		b = common.Address{0xb}
+	)
+	tests := []struct {
+		eras    []RewardEra
+		wantErr bool
+	}{
+		{nil, false},
+		{[]RewardEra{{Block: 0, Reward: big.NewInt(0)}}, false},
+		{[]RewardEra{{Block: 0}}, true},
+		{[]RewardEra{{Block: 0, Reward: big.NewInt(-1)}}, true},
+		{[]RewardEra{{Block: 0, Reward: big.NewInt(1)}, {Block: 10, Reward: big.NewInt(2)}}, false},
+		{[]RewardEra{{Block: 10, Reward: big.NewInt(1)}, {Block: 10, Reward: big.NewInt(2)}}, true},
+		{[]RewardEra{{Block: 10, Reward: big.NewInt(1)}, {Block: 5, Reward: big.NewInt(2)}}, true},
+		{[]RewardEra{{Block: 0, Reward: big.NewInt(1), Recipients: []RewardRecipient{{a, 5000}, {b, 5000}}}}, false},
+		{[]RewardEra{{Block: 0, Reward: big.NewInt(1), Recipients: []RewardRecipient{{a, 5000}, {b, 5001}}}}, true},
+		{[]RewardEra{{Block: 0, Reward: big.NewInt(1), Recipients: []RewardRecipient{{a, 0}}}}, true},
+	}
+	for i, test := range tests {
+		c := &ChainConfig{BlockRewards: test.eras}
+		if err := c.CheckBlockRewards(); (err != nil) != test.wantErr {
+			t.Errorf("test %d: error mismatch: have %v, want error %v", i, err, test.wantErr)
+		}
+	}
+	// Eras are looked up by block
+	c := &ChainConfig{BlockRewards: []RewardEra{{Block: 5, Reward: big.NewInt(1)}, {Block: 10, Reward: big.NewInt(2)}}}
+	for num, want := range map[int64]*RewardEra{0: nil, 4: nil, 5: &c.BlockRewards[0], 9: &c.BlockRewards[0], 10: &c.BlockRewards[1], 100: &c.BlockRewards[1]} {
+		if have := c.RewardEra(big.NewInt(num)); have != want {
+			t.Errorf("block %d: era mismatch: have %v, want %v", num, have, want)
+		}
+	}
+	// Eras may only be changed before they started
+	var (
+		stored  = *AllEthashProtocolChanges
+		updated = *AllEthashProtocolChanges
+	)
+	stored.BlockRewards = c.BlockRewards
+	updated.BlockRewards = []RewardEra{{Block: 5, Reward: big.NewInt(1)}, {Block: 20, Reward: big.NewInt(3)}}
+	if err := stored.CheckCompatible(&updated, 9, 0); err != nil {
+		t.Errorf("rescheduled future era reported incompatible: %v", err)
+	}
+	if err := stored.CheckCompatible(&updated, 10, 0); err == nil || err.RewindToBlock != 9 {
+		t.Errorf("wrong compatibility error for rescheduled era: %v", err)
+	}
+	updated.BlockRewards = []RewardEra{{Block: 5, Reward: big.NewInt(1), Recipients: []RewardRecipient{{a, 100}}}}
+	if err := stored.CheckCompatible(&updated, 4, 0); err != nil {
+		t.Errorf("changed future era reported incompatible: %v", err)
+	}
+	if err := stored.CheckCompatible(&updated, 5, 0); err == nil || err.RewindToBlock != 4 {
+		t.Errorf("wrong compatibility error for changed era: %v", err)
+	}
+}
+
+func TestScheduleForks(t *testing.T) {
+	newConfig := func() *ChainConfig {
+		config := *AllEthashProtocolChanges
+		config.ShanghaiTime = newUint64(0)
+		config.CancunTime, config.PragueTime, config.VerkleTime, config.EOFTime = nil, nil, nil, nil
+		config.EnableForkScheduling()
+		return &config
+	}
+	// Configs not prepared for scheduling reject updates
+	if err := (&ChainConfig{}).ScheduleForks(map[string]uint64{"cancun": 10}, 0); err != ErrForkSchedulingDisabled {
+		t.Fatalf("wrong error on static config: %v", err)
+	}
+	config := newConfig()
+	if config.IsCancun(common.Big0, 100) {
+		t.Fatal("cancun active before being scheduled")
+	}
+	if err := config.ScheduleForks(map[string]uint64{"cancun": 100, "shanghai": 0}, 50); err != nil {
+		t.Fatalf("failed to schedule forks: %v", err)
+	}
+	if config.IsCancun(common.Big0, 99) || !config.IsCancun(common.Big0, 100) {
+		t.Fatal("scheduled cancun activation mismatch")
+	}
+	if time := config.ForkTime("cancun"); time == nil || *time != 100 {
+		t.Fatalf("wrong cancun fork time: %v", time)
+	}
+	if config.CancunTime != nil {
+		t.Fatal("static config modified by schedule")
+	}
+	// Invalid schedules must be rejected without changing the current one
+	for i, tt := range []struct {
+		forks map[string]uint64
+		head  uint64
+	}{
+		{map[string]uint64{"cancun": 100, "osaka": 200}, 50},   // Unknown fork
+		{map[string]uint64{"cancun": 100, "shanghai": 10}, 50}, // Static fork moved
+		{map[string]uint64{"cancun": 40}, 50},                  // Fork in the past
+		{map[string]uint64{"cancun": 200}, 150},                // Activated fork moved
+		{map[string]uint64{"prague": 200}, 150},                // Activated fork dropped
+		{map[string]uint64{"cancun": 100, "prague": 90}, 50},   // Invalid fork order
+		{map[string]uint64{"cancun": 100, "prague": 120}, 150}, // Newly added fork in the past
+	} {
+		if err := config.ScheduleForks(tt.forks, tt.head); err == nil {
+			t.Errorf("test %d: expected schedule %v at head %d to fail", i, tt.forks, tt.head)
+		}
+	}
+	if forks := config.ScheduledForks(); !reflect.DeepEqual(forks, map[string]uint64{"cancun": 100}) {
+		t.Fatalf("schedule changed by rejected updates: %v", forks)
+	}
+	// Forks not yet activated may be moved
+	if err := config.ScheduleForks(map[string]uint64{"cancun": 200, "prague": 300}, 50); err != nil {
+		t.Fatalf("failed to reschedule forks: %v", err)
+	}
+	if config.IsCancun(common.Big0, 150) || !config.IsPrague(common.Big0, 300) {
+		t.Fatal("rescheduled forks activation mismatch")
+	}
+}
+
+func TestCheckPrecompilesCompatible(t *testing.T) {
+	addr := common.HexToAddress("0x0100")
+
+	stored := &ChainConfig{Precompiles: []PrecompileConfig{{Address: addr, Name: "a", Block: big.NewInt(10)}}}
+	tests := []struct {
+		new       []PrecompileConfig
+		headBlock uint64
+		wantErr   bool
+	}{
+		// Identical schedules are always compatible
+		{[]PrecompileConfig{{Address: addr, Name: "a", Block: big.NewInt(10)}}, 100, false},
+		// Changes before the activation are compatible
+		{[]PrecompileConfig{{Address: addr, Name: "b", Block: big.NewInt(20)}}, 5, false},
+		{nil, 9, false},
+		// Changes after the activation are not
+		{[]PrecompileConfig{{Address: addr, Name: "b", Block: big.NewInt(10)}}, 10, true},
+		{[]PrecompileConfig{{Address: addr, Name: "a", Block: big.NewInt(20)}}, 15, true},
+		{nil, 10, true},
+		{[]PrecompileConfig{{Address: addr, Name: "a", Block: big.NewInt(10)}, {Address: common.HexToAddress("0x0101"), Name: "a"}}, 0, true},
+	}
+	for i, tt := range tests {
+		err := stored.CheckCompatible(&ChainConfig{Precompiles: tt.new}, tt.headBlock, 0)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("test %d: compatibility error mismatch: have %v, want error %v", i, err, tt.wantErr)
+		}
+	}
+	// Rewinds must go before the earliest affected activation
+	err := stored.CheckCompatible(&ChainConfig{Precompiles: []PrecompileConfig{{Address: addr, Name: "a", Block: big.NewInt(20)}}}, 30, 0)
+	if err == nil || err.RewindToBlock != 9 {
+		t.Fatalf("wrong rewind: %v", err)
+	}
+}
+
+func TestCheckGoriForks(t *testing.T) {
+	shanghai := uint64(100)
+	tests := []struct {
+		forks   []GoriFork
+		wantErr bool
+	}{
+		{nil, false},
+		{[]GoriFork{{Name: "alpha", Time: 100}, {Name: "beta", Time: 200}}, false},
+		{[]GoriFork{{Name: "alpha", Time: 200}, {Name: "beta", Time: 200}}, false},
+		{[]GoriFork{{Name: "", Time: 200}}, true},
+		{[]GoriFork{{Name: "alpha", Time: 200}, {Name: "alpha", Time: 300}}, true},
+		{[]GoriFork{{Name: "cancun", Time: 200}}, true},
+		{[]GoriFork{{Name: "alpha", Time: 50}}, true},
+		{[]GoriFork{{Name: "alpha", Time: 300}, {Name: "beta", Time: 200}}, true},
+	}
+	for i, tt := range tests {
+		config := &ChainConfig{ShanghaiTime: &shanghai, GoriForks: tt.forks}
+		if err := config.CheckGoriForks(); (err != nil) != tt.wantErr {
+			t.Errorf("test %d: error mismatch: have %v, want error %v", i, err, tt.wantErr)
+		}
+	}
+	config := &ChainConfig{GoriForks: []GoriFork{{Name: "alpha", Time: 200}}}
+	if config.IsGoriFork("alpha", 199) || !config.IsGoriFork("alpha", 200) || config.IsGoriFork("beta", 1000) {
+		t.Fatal("gori fork activation mismatch")
+	}
+}
+
+func TestCheckGoriForksCompatible(t *testing.T) {
+	stored := &ChainConfig{GoriForks: []GoriFork{{Name: "alpha", Time: 100}}}
+	tests := []struct {
+		new      []GoriFork
+		headTime uint64
+		wantErr  bool
+	}{
+		// Identical schedules are always compatible
+		{[]GoriFork{{Name: "alpha", Time: 100}}, 1000, false},
+		// Changes before the activation are compatible
+		{[]GoriFork{{Name: "alpha", Time: 200}}, 50, false},
+		{[]GoriFork{{Name: "alpha", Time: 100}, {Name: "beta", Time: 300}}, 200, false},
+		{nil, 99, false},
+		// Changes after the activation are not
+		{[]GoriFork{{Name: "alpha", Time: 200}}, 150, true},
+		{[]GoriFork{{Name: "alpha", Time: 100}, {Name: "beta", Time: 150}}, 200, true},
+		{nil, 100, true},
+	}
+	for i, tt := range tests {
+		err := stored.CheckCompatible(&ChainConfig{GoriForks: tt.new}, 0, tt.headTime)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("test %d: compatibility error mismatch: have %v, want error %v", i, err, tt.wantErr)
+		}
+	}
+	// Rewinds must go before the earliest affected activation
+	err := stored.CheckCompatible(&ChainConfig{GoriForks: []GoriFork{{Name: "alpha", Time: 200}}}, 0, 300)
+	if err == nil || err.RewindToTime != 99 {
+		t.Fatalf("wrong rewind: %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import "fmt"
+
+// GasOverrides replaces selected gas costs of the protocol. Unset costs keep the
+// value of the active fork. The storage costs are those of EIP-2929 and require
+// Berlin to be active from genesis. The refunds for clearing storage slots are
+// not affected.
+type GasOverrides struct {
+	ColdSload     *uint64 `json:"coldSload,omitempty"`     // Cold storage slot access, charged by SLOAD and SSTORE (default 2100)
+	WarmSload     *uint64 `json:"warmSload,omitempty"`     // Warm storage slot read, charged by SLOAD and no-op SSTORE (default 100)
+	SstoreSet     *uint64 `json:"sstoreSet,omitempty"`     // SSTORE from zero to non-zero (default 20000)
+	SstoreReset   *uint64 `json:"sstoreReset,omitempty"`   // SSTORE of a non-zero slot, including the cold access (default 5000)
+	TxDataZero    *uint64 `json:"txDataZero,omitempty"`    // Zero byte of transaction data (default 4)
+	TxDataNonZero *uint64 `json:"txDataNonZero,omitempty"` // Non-zero byte of transaction data (default 68, 16 from Istanbul)
+	Create        *uint64 `json:"create,omitempty"`        // CREATE and CREATE2 (default 32000)
+	TxCreate      *uint64 `json:"txCreate,omitempty"`      // Contract creation transaction (default 53000)
+	CreateData    *uint64 `json:"createData,omitempty"`    // Byte of code deployed by a contract creation (default 200)
+}
+
+// overrides lists the gas costs by their JSON names, in declaration order.
+func (g *GasOverrides) overrides() []struct {
+	name string
+	cost *uint64
+} {
+	return []struct {
+		name string
+		cost *uint64
+	}{
+		{"coldSload", g.ColdSload},
+		{"warmSload", g.WarmSload},
+		{"sstoreSet", g.SstoreSet},
+		{"sstoreReset", g.SstoreReset},
+		{"txDataZero", g.TxDataZero},
+		{"txDataNonZero", g.TxDataNonZero},
+		{"create", g.Create},
+		{"txCreate", g.TxCreate},
+		{"createData", g.CreateData},
+	}
+}
+
+// description returns a line for every overridden gas cost.
+func (g *GasOverrides) description() string {
+	var lines string
+	for _, o := range g.overrides() {
+		if o.cost != nil {
+			lines += fmt.Sprintf(" - %-28s%v\n", o.name+":", *o.cost)
+		}
+	}
+	return lines
+}
+
+// gasCost returns the override if set, or the default cost otherwise.
+func gasCost(override *uint64, def uint64) uint64 {
+	if override != nil {
+		return *override
+	}
+	return def
+}
+
+// ColdSloadCost returns the gas of accessing a storage slot for the first time in
+// a transaction. It is safe to call on nil overrides, like all cost accessors.
+func (g *GasOverrides) ColdSloadCost() uint64 {
+	if g == nil {
+		return ColdSloadCostEIP2929
+	}
+	return gasCost(g.ColdSload, ColdSloadCostEIP2929)
+}
+
+// WarmSloadCost returns the gas of reading an already accessed storage slot.
+func (g *GasOverrides) WarmSloadCost() uint64 {
+	if g == nil {
+		return WarmStorageReadCostEIP2929
+	}
+	return gasCost(g.WarmSload, WarmStorageReadCostEIP2929)
+}
+
+// SstoreSetCost returns the gas of setting a clean zero storage slot.
+func (g *GasOverrides) SstoreSetCost() uint64 {
+	if g == nil {
+		return SstoreSetGasEIP2200
+	}
+	return gasCost(g.SstoreSet, SstoreSetGasEIP2200)
+}
+
+// SstoreResetCost returns the gas of changing a clean non-zero storage slot,
+// including the cold access of the slot.
+func (g *GasOverrides) SstoreResetCost() uint64 {
+	if g == nil {
+		return SstoreResetGasEIP2200
+	}
+	return gasCost(g.SstoreReset, SstoreResetGasEIP2200)
+}
+
+// TxDataZeroCost returns the gas of a zero byte of transaction data.
+func (g *GasOverrides) TxDataZeroCost() uint64 {
+	if g == nil {
+		return TxDataZeroGas
+	}
+	return gasCost(g.TxDataZero, TxDataZeroGas)
+}
+
+// TxDataNonZeroCost returns the gas of a non-zero byte of transaction data.
+func (g *GasOverrides) TxDataNonZeroCost(isEIP2028 bool) uint64 {
+	def := TxDataNonZeroGasFrontier
+	if isEIP2028 {
+		def = TxDataNonZeroGasEIP2028
+	}
+	if g == nil {
+		return def
+	}
+	return gasCost(g.TxDataNonZero, def)
+}
+
+// CreateCost returns the gas of the CREATE and CREATE2 instructions and whether it
+// is overridden at all, the cost of the fork's instruction set applying if not.
+func (g *GasOverrides) CreateCost() (uint64, bool) {
+	if g == nil || g.Create == nil {
+		return 0, false
+	}
+	return *g.Create, true
+}
+
+// TxCreateCost returns the gas of a contract creation transaction.
+func (g *GasOverrides) TxCreateCost() uint64 {
+	if g == nil {
+		return TxGasContractCreation
+	}
+	return gasCost(g.TxCreate, TxGasContractCreation)
+}
+
+// CreateDataCost returns the gas per byte of deployed contract code.
+func (g *GasOverrides) CreateDataCost() uint64 {
+	if g == nil {
+		return CreateDataGas
+	}
+	return gasCost(g.CreateData, CreateDataGas)
+}
+
+// equal returns whether two sets of overrides result in the same gas costs.
+func (g *GasOverrides) equal(other *GasOverrides) bool {
+	gc, gok := g.CreateCost()
+	oc, ook := other.CreateCost()
+	return g.ColdSloadCost() == other.ColdSloadCost() &&
+		g.WarmSloadCost() == other.WarmSloadCost() &&
+		g.SstoreSetCost() == other.SstoreSetCost() &&
+		g.SstoreResetCost() == other.SstoreResetCost() &&
+		g.TxDataZeroCost() == other.TxDataZeroCost() &&
+		g.TxDataNonZeroCost(false) == other.TxDataNonZeroCost(false) &&
+		g.TxDataNonZeroCost(true) == other.TxDataNonZeroCost(true) &&
+		gc == oc && gok == ook &&
+		g.TxCreateCost() == other.TxCreateCost() &&
+		g.CreateDataCost() == other.CreateDataCost()
+}
+
+// CheckGasOverrides checks that the gas overrides of the chain, if any, are within
+// the bounds the gas calculations can safely operate in.
+func (c *ChainConfig) CheckGasOverrides() error {
+	g := c.GasOverrides
+	if g == nil {
+		return nil
+	}
+	for _, o := range g.overrides() {
+		if o.cost != nil && *o.cost > MaxConfigurableGasCost {
+			return fmt.Errorf("invalid gas override %s %d: must be at most %d", o.name, *o.cost, MaxConfigurableGasCost)
+		}
+	}
+	if g.ColdSload == nil && g.WarmSload == nil && g.SstoreSet == nil && g.SstoreReset == nil {
+		return nil
+	}
+	if c.BerlinBlock == nil || c.BerlinBlock.Sign() != 0 {
+		return fmt.Errorf("storage gas overrides require berlinBlock at genesis")
+	}
+	// The SSTORE costs and refunds of EIP-2929 are derived by subtracting the
+	// storage read costs from the write costs, which must not underflow.
+	if g.SstoreResetCost() < g.ColdSloadCost()+g.WarmSloadCost() {
+		return fmt.Errorf("invalid storage gas overrides: sstoreReset %d below coldSload %d plus warmSload %d", g.SstoreResetCost(), g.ColdSloadCost(), g.WarmSloadCost())
+	}
+	if g.SstoreSetCost() < g.WarmSloadCost() {
+		return fmt.Errorf("invalid storage gas overrides: sstoreSet %d below warmSload %d", g.SstoreSetCost(), g.WarmSloadCost())
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import "fmt"
+
+// GoriFork is a network upgrade specific to Gori chains, scheduled by timestamp.
+// Its rules are looked up by name by the code implementing them, whereas the
+// schedule itself is part of the fork ID, so peers disagreeing on it are dropped
+// during the handshake instead of diverging at the fork.
+type GoriFork struct {
+	Name string `json:"name"`
+	Time uint64 `json:"time"` // Activation time (0 = already active at genesis)
+}
+
+// IsGoriFork returns whether the named Gori fork is active at the given time.
+func (c *ChainConfig) IsGoriFork(name string, time uint64) bool {
+	fork := c.GoriForkTime(name)
+	return fork != nil && isTimestampForked(fork, time)
+}
+
+// GoriForkTime returns the activation time of the named Gori fork, or nil if the
+// fork is not scheduled.
+func (c *ChainConfig) GoriForkTime(name string) *uint64 {
+	for i := range c.GoriForks {
+		if c.GoriForks[i].Name == name {
+			time := c.GoriForks[i].Time
+			return &time
+		}
+	}
+	return nil
+}
+
+// CheckGoriForks checks that the Gori forks of the chain are uniquely named, not
+// shadowing any of the upstream forks, and scheduled in order after Shanghai,
+// the first fork activated by timestamp.
+func (c *ChainConfig) CheckGoriForks() error {
+	seen := make(map[string]bool)
+	for i, fork := range c.GoriForks {
+		if fork.Name == "" {
+			return fmt.Errorf("unnamed gori fork at timestamp %d", fork.Time)
+		}
+		if seen[fork.Name] {
+			return fmt.Errorf("duplicate gori fork %s", fork.Name)
+		}
+		seen[fork.Name] = true
+
+		if _, ok := c.timeFork(fork.Name); ok {
+			return fmt.Errorf("gori fork %s shadows upstream fork", fork.Name)
+		}
+		if c.ShanghaiTime == nil || fork.Time < *c.ShanghaiTime {
+			return fmt.Errorf("unsupported fork ordering: gori fork %s enabled at timestamp %d before shanghaiTime", fork.Name, fork.Time)
+		}
+		if i > 0 && c.GoriForks[i-1].Time > fork.Time {
+			prev := c.GoriForks[i-1]
+			return fmt.Errorf("unsupported fork ordering: gori fork %s enabled at timestamp %d, but %s enabled at timestamp %d",
+				prev.Name, prev.Time, fork.Name, fork.Time)
+		}
+	}
+	return nil
+}
+
+// checkGoriForksCompatible checks whether the Gori forks of two chain configs
+// differ in a way that affects blocks up to the given head.
+func (c *ChainConfig) checkGoriForksCompatible(newcfg *ChainConfig, headTimestamp uint64) *ConfigCompatError {
+	names := make([]string, 0, len(c.GoriForks)+len(newcfg.GoriForks))
+	for _, fork := range c.GoriForks {
+		names = append(names, fork.Name)
+	}
+	for _, fork := range newcfg.GoriForks {
+		if c.GoriForkTime(fork.Name) == nil {
+			names = append(names, fork.Name)
+		}
+	}
+	for _, name := range names {
+		stored, updated := c.GoriForkTime(name), newcfg.GoriForkTime(name)
+		if isForkTimestampIncompatible(stored, updated, headTimestamp) {
+			return newTimestampCompatError(fmt.Sprintf("Gori fork %s timestamp", name), stored, updated)
+		}
+	}
+	return nil
+}
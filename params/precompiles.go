@@ -0,0 +1,128 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/gorievm/go-gori/common"
+)
+
+// PrecompileConfig schedules a custom precompiled contract at an address. The
+// implementation is looked up by name among the precompiles registered with the
+// EVM, and activated from the given block or timestamp (from genesis if neither
+// is set).
+type PrecompileConfig struct {
+	Address common.Address `json:"address"`
+	Name    string         `json:"name"`
+	Block   *big.Int       `json:"block,omitempty"` // Activation block (nil = activated by timestamp or genesis)
+	Time    *uint64        `json:"time,omitempty"`  // Activation time (nil = activated by block or genesis)
+}
+
+// isActive returns whether the precompile is active at the given block and time.
+func (p *PrecompileConfig) isActive(num *big.Int, time uint64) bool {
+	switch {
+	case p.Block != nil:
+		return isBlockForked(p.Block, num)
+	case p.Time != nil:
+		return isTimestampForked(p.Time, time)
+	default:
+		return true
+	}
+}
+
+// activePrecompiles returns the custom precompiles active at the given block and
+// time, mapped from their address to their name.
+func (c *ChainConfig) activePrecompiles(num *big.Int, time uint64) map[common.Address]string {
+	if len(c.Precompiles) == 0 {
+		return nil
+	}
+	active := make(map[common.Address]string)
+	for i := range c.Precompiles {
+		if p := &c.Precompiles[i]; p.isActive(num, time) {
+			active[p.Address] = p.Name
+		}
+	}
+	return active
+}
+
+// CheckPrecompiles checks that the custom precompiles of the chain are uniquely
+// addressed and scheduled by either block or timestamp.
+func (c *ChainConfig) CheckPrecompiles() error {
+	seen := make(map[common.Address]bool)
+	for _, p := range c.Precompiles {
+		if p.Name == "" {
+			return fmt.Errorf("unnamed precompile at %v", p.Address)
+		}
+		if seen[p.Address] {
+			return fmt.Errorf("duplicate precompile at %v", p.Address)
+		}
+		seen[p.Address] = true
+
+		if p.Block != nil && p.Time != nil {
+			return fmt.Errorf("precompile %s at %v scheduled by both block and timestamp", p.Name, p.Address)
+		}
+	}
+	return nil
+}
+
+// checkPrecompilesCompatible checks whether the custom precompiles of two chain
+// configs differ in a way that affects blocks up to the given head.
+func (c *ChainConfig) checkPrecompilesCompatible(newcfg *ChainConfig, headNumber *big.Int, headTimestamp uint64) *ConfigCompatError {
+	var (
+		stored  = make(map[common.Address]*PrecompileConfig)
+		updated = make(map[common.Address]*PrecompileConfig)
+		addrs   []common.Address
+	)
+	for i := range c.Precompiles {
+		stored[c.Precompiles[i].Address] = &c.Precompiles[i]
+		addrs = append(addrs, c.Precompiles[i].Address)
+	}
+	for i := range newcfg.Precompiles {
+		updated[newcfg.Precompiles[i].Address] = &newcfg.Precompiles[i]
+		if stored[newcfg.Precompiles[i].Address] == nil {
+			addrs = append(addrs, newcfg.Precompiles[i].Address)
+		}
+	}
+	for _, addr := range addrs {
+		s, n := stored[addr], updated[addr]
+		if s != nil && n != nil && s.Name == n.Name && configBlockEqual(s.Block, n.Block) && configTimestampEqual(s.Time, n.Time) {
+			continue
+		}
+		// The schedules differ, which is only a problem if either was active
+		if (s == nil || !s.isActive(headNumber, headTimestamp)) && (n == nil || !n.isActive(headNumber, headTimestamp)) {
+			continue
+		}
+		var (
+			what                  = fmt.Sprintf("precompile %v", addr)
+			storedBlock, newBlock *big.Int
+			storedTime, newTime   *uint64
+		)
+		if s != nil {
+			storedBlock, storedTime = s.Block, s.Time
+		}
+		if n != nil {
+			newBlock, newTime = n.Block, n.Time
+		}
+		if storedTime != nil || newTime != nil {
+			return newTimestampCompatError(what, storedTime, newTime)
+		}
+		return newBlockCompatError(what, storedBlock, newBlock)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/gorievm/go-gori/common"
+)
+
+// RewardShareDenominator is the denominator of the shares of the block reward
+// paid to treasury recipients, i.e. shares are given in basis points.
+const RewardShareDenominator = 10000
+
+// RewardEra sets the block reward of the blocks from its first block until the
+// start of the next era.
+type RewardEra struct {
+	Block      uint64            `json:"block"`                // First block of the era
+	Reward     *big.Int          `json:"reward"`               // Block reward in wei of the era
+	Recipients []RewardRecipient `json:"recipients,omitempty"` // Treasury split of the block reward
+}
+
+// RewardRecipient is paid a share of every block reward of an era, taken from
+// the reward of the block's author.
+type RewardRecipient struct {
+	Address common.Address `json:"address"`
+	Share   uint64         `json:"share"` // Share of the block reward in basis points
+}
+
+// equal returns whether two eras pay out the same rewards.
+func (e *RewardEra) equal(other *RewardEra) bool {
+	if e.Block != other.Block || e.Reward.Cmp(other.Reward) != 0 || len(e.Recipients) != len(other.Recipients) {
+		return false
+	}
+	for i, r := range e.Recipients {
+		if r != other.Recipients[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RewardEra returns the era of the block reward schedule the given block belongs
+// to, or nil if the schedule doesn't cover the block and the default rewards of
+// the consensus engine apply.
+func (c *ChainConfig) RewardEra(num *big.Int) *RewardEra {
+	var era *RewardEra
+	for i := range c.BlockRewards {
+		if !isBlockForked(new(big.Int).SetUint64(c.BlockRewards[i].Block), num) {
+			break
+		}
+		era = &c.BlockRewards[i]
+	}
+	return era
+}
+
+// CheckBlockRewards checks that the block reward schedule of the chain, if any,
+// is ordered by block and that no era pays out more than its block reward.
+func (c *ChainConfig) CheckBlockRewards() error {
+	for i, era := range c.BlockRewards {
+		if i > 0 && era.Block <= c.BlockRewards[i-1].Block {
+			return fmt.Errorf("unordered block reward era at block %d after block %d", era.Block, c.BlockRewards[i-1].Block)
+		}
+		if era.Reward == nil || era.Reward.Sign() < 0 {
+			return fmt.Errorf("invalid block reward %v of era at block %d", era.Reward, era.Block)
+		}
+		var total uint64
+		for _, r := range era.Recipients {
+			if r.Share == 0 || r.Share > RewardShareDenominator {
+				return fmt.Errorf("invalid reward share %d of %v in era at block %d", r.Share, r.Address, era.Block)
+			}
+			total += r.Share
+		}
+		if total > RewardShareDenominator {
+			return fmt.Errorf("reward shares of era at block %d exceed the block reward: %d > %d", era.Block, total, RewardShareDenominator)
+		}
+	}
+	return nil
+}
+
+// checkBlockRewardsCompatible checks whether the block reward schedules of two
+// chain configs differ for blocks up to the given head.
+func (c *ChainConfig) checkBlockRewardsCompatible(newcfg *ChainConfig, headNumber *big.Int) *ConfigCompatError {
+	for i := 0; i < len(c.BlockRewards) || i < len(newcfg.BlockRewards); i++ {
+		var stored, updated *big.Int
+		if i < len(c.BlockRewards) {
+			stored = new(big.Int).SetUint64(c.BlockRewards[i].Block)
+		}
+		if i < len(newcfg.BlockRewards) {
+			updated = new(big.Int).SetUint64(newcfg.BlockRewards[i].Block)
+		}
+		if stored != nil && updated != nil && c.BlockRewards[i].equal(&newcfg.BlockRewards[i]) {
+			continue
+		}
+		// The schedules diverge from this era on, which is only a problem if it
+		// already started in either of them
+		if !isBlockForked(stored, headNumber) && !isBlockForked(updated, headNumber) {
+			return nil
+		}
+		return newBlockCompatError("Block reward era", stored, updated)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrForkSchedulingDisabled is returned when attempting to schedule forks on a
+// chain config which was not prepared for runtime changes.
+var ErrForkSchedulingDisabled = errors.New("fork scheduling not enabled")
+
+// forkSchedule holds the timestamp based forks scheduled on a live chain config
+// after it was loaded. The schedule is swapped atomically, so it can be updated
+// while the config is in use by the rest of the node.
+type forkSchedule struct {
+	forks atomic.Pointer[map[string]uint64] // Fork name to activation time
+	lock  sync.Mutex                        // Serializes schedule updates
+}
+
+// timeFork returns the config field holding the activation time of the named
+// timestamp based fork.
+func (c *ChainConfig) timeFork(name string) (**uint64, bool) {
+	switch name {
+	case "shanghai":
+		return &c.ShanghaiTime, true
+	case "cancun":
+		return &c.CancunTime, true
+	case "prague":
+		return &c.PragueTime, true
+	case "verkle":
+		return &c.VerkleTime, true
+	case "eof":
+		return &c.EOFTime, true
+	}
+	return nil, false
+}
+
+// EnableForkScheduling prepares the config for accepting timestamp based forks
+// at runtime via ScheduleForks. It is not safe to call once the config is shared.
+func (c *ChainConfig) EnableForkScheduling() {
+	if c.schedule == nil {
+		c.schedule = new(forkSchedule)
+	}
+}
+
+// ForkSchedulingEnabled returns whether forks may be scheduled on the config at
+// runtime.
+func (c *ChainConfig) ForkSchedulingEnabled() bool {
+	return c.schedule != nil
+}
+
+// ScheduledForks returns the forks scheduled at runtime, mapped to their time
+// of activation.
+func (c *ChainConfig) ScheduledForks() map[string]uint64 {
+	if c.schedule == nil {
+		return nil
+	}
+	forks := c.schedule.forks.Load()
+	if forks == nil {
+		return nil
+	}
+	cpy := make(map[string]uint64, len(*forks))
+	for name, time := range *forks {
+		cpy[name] = time
+	}
+	return cpy
+}
+
+// ForkTime returns the activation time of the named timestamp based fork, be it
+// configured statically or scheduled at runtime. Nil is returned if the fork is
+// not enabled.
+func (c *ChainConfig) ForkTime(name string) *uint64 {
+	field, ok := c.timeFork(name)
+	if !ok {
+		return nil
+	}
+	return c.scheduledTime(name, *field)
+}
+
+// scheduledTime returns the static activation time of a fork if configured, or
+// the one scheduled at runtime otherwise.
+func (c *ChainConfig) scheduledTime(name string, static *uint64) *uint64 {
+	if static != nil || c.schedule == nil {
+		return static
+	}
+	forks := c.schedule.forks.Load()
+	if forks == nil {
+		return nil
+	}
+	if time, ok := (*forks)[name]; ok {
+		return &time
+	}
+	return nil
+}
+
+// ScheduleForks replaces the runtime fork schedule of the config. Forks already
+// enabled in the static config must be given with their configured times, all
+// others must activate after the given head timestamp. Previously scheduled forks
+// which have already activated cannot be moved or dropped.
+func (c *ChainConfig) ScheduleForks(forks map[string]uint64, head uint64) error {
+	if c.schedule == nil {
+		return ErrForkSchedulingDisabled
+	}
+	c.schedule.lock.Lock()
+	defer c.schedule.lock.Unlock()
+
+	var (
+		current  = c.ScheduledForks()
+		schedule = make(map[string]uint64)
+		merged   = *c
+	)
+	merged.schedule = nil
+
+	names := make([]string, 0, len(forks))
+	for name := range forks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		time := forks[name]
+		field, ok := merged.timeFork(name)
+		if !ok {
+			return fmt.Errorf("unknown fork %q", name)
+		}
+		if static := *field; static != nil {
+			if *static != time {
+				return fmt.Errorf("fork %s already configured at timestamp %d", name, *static)
+			}
+			continue
+		}
+		if prev, ok := current[name]; (!ok || prev != time) && time <= head {
+			return fmt.Errorf("fork %s scheduled at timestamp %d, already passed at %d", name, time, head)
+		}
+		schedule[name] = time
+		*field = &time
+	}
+	for name, time := range current {
+		if time <= head && schedule[name] != time {
+			return fmt.Errorf("fork %s already activated at timestamp %d", name, time)
+		}
+	}
+	if err := merged.CheckConfigForkOrder(); err != nil {
+		return err
+	}
+	c.schedule.forks.Store(&schedule)
+	return nil
+}
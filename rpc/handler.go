@@ -224,7 +224,10 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 			if msg == nil {
 				break
 			}
-			resp := h.handleCallMsg(cp, msg)
+			// Batches are encoded as a whole, so streamed results are read into memory.
+			answer := h.handleCallMsg(cp, msg)
+			resp := answer.inline()
+			answer.release()
 			callBuffer.pushResponse(resp)
 			if resp != nil && h.batchResponseMaxSize != 0 {
 				responseBytes += len(resp.Result)
@@ -302,6 +305,8 @@ func (h *handler) handleNonBatchCall(cp *callProc, msg *jsonrpcMessage) {
 		responded.Do(func() {
 			h.conn.writeJSON(cp.ctx, answer, false)
 		})
+		// Streamed results are released here, even if the call timed out.
+		answer.release()
 	}
 	for _, n := range cp.notifiers {
 		n.activate()
@@ -501,7 +506,7 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		callb = h.reg.callback(msg.Method)
 	}
 	if callb == nil {
-		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
+		return msg.errorResponse(h.reg.methodNotFound(msg.Method))
 	}
 
 	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
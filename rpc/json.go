@@ -55,6 +55,8 @@ type jsonrpcMessage struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 	Error   *jsonError      `json:"error,omitempty"`
 	Result  json.RawMessage `json:"result,omitempty"`
+
+	stream ResultStream // result not yet read into Result, see ResultStream
 }
 
 func (msg *jsonrpcMessage) isNotification() bool {
@@ -102,6 +104,9 @@ func (msg *jsonrpcMessage) errorResponse(err error) *jsonrpcMessage {
 }
 
 func (msg *jsonrpcMessage) response(result interface{}) *jsonrpcMessage {
+	if stream, ok := result.(ResultStream); ok {
+		return &jsonrpcMessage{Version: vsn, ID: msg.ID, stream: stream}
+	}
 	enc, err := json.Marshal(result)
 	if err != nil {
 		return msg.errorResponse(&internalServerError{errcodeMarshalError, err.Error()})
@@ -109,6 +114,26 @@ func (msg *jsonrpcMessage) response(result interface{}) *jsonrpcMessage {
 	return &jsonrpcMessage{Version: vsn, ID: msg.ID, Result: enc}
 }
 
+// inline reads a streamed result into memory, for transports and batches which
+// cannot write it incrementally. The stream itself is not released.
+func (msg *jsonrpcMessage) inline() *jsonrpcMessage {
+	if msg == nil || msg.stream == nil {
+		return msg
+	}
+	enc, err := io.ReadAll(msg.stream)
+	if err != nil {
+		return msg.errorResponse(&internalServerError{errcodeMarshalError, err.Error()})
+	}
+	return &jsonrpcMessage{Version: vsn, ID: msg.ID, Result: enc}
+}
+
+// release closes the streamed result of the message, if any.
+func (msg *jsonrpcMessage) release() {
+	if msg != nil && msg.stream != nil {
+		msg.stream.Close()
+	}
+}
+
 func errorMessage(err error) *jsonrpcMessage {
 	msg := &jsonrpcMessage{Version: vsn, ID: null, Error: &jsonError{
 		Code:    errcodeDefault,
@@ -245,9 +270,36 @@ func (c *jsonCodec) writeJSON(ctx context.Context, v interface{}, isErrorRespons
 		deadline = time.Now().Add(defaultWriteTimeout)
 	}
 	c.conn.SetWriteDeadline(deadline)
+
+	if msg, ok := v.(*jsonrpcMessage); ok && msg.stream != nil {
+		// Copy streamed results directly to the connection if it's a plain byte
+		// stream, otherwise fall back to encoding them in memory.
+		if w, ok := c.conn.(io.Writer); ok {
+			return writeStream(w, msg)
+		}
+		v = msg.inline()
+	}
 	return c.encode(v, isErrorResponse)
 }
 
+// writeStream writes a response with a streamed result to w.
+func writeStream(w io.Writer, msg *jsonrpcMessage) error {
+	head, err := json.Marshal(&jsonrpcMessage{Version: msg.Version, ID: msg.ID})
+	if err != nil {
+		return err
+	}
+	// Replace the closing brace of the header with the result field.
+	head = append(head[:len(head)-1], `,"result":`...)
+	if _, err := w.Write(head); err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, msg.stream); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "}\n")
+	return err
+}
+
 func (c *jsonCodec) close() {
 	c.closer.Do(func() {
 		close(c.closeCh)
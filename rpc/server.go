@@ -78,6 +78,22 @@ func (s *Server) SetBatchLimits(itemLimit, maxResponseSize int) {
 	s.batchResponseLimit = maxResponseSize
 }
 
+// SetMethodNotFoundHandler sets a function consulted when a request calls a method
+// which is not registered. If it returns a non-nil error, that error is sent to the
+// caller instead of the default method not found error.
+func (s *Server) SetMethodNotFoundHandler(fn func(method string) error) {
+	s.services.mu.Lock()
+	defer s.services.mu.Unlock()
+
+	s.services.notFound = fn
+}
+
+// Methods returns the sorted names of all methods registered on the server, in the
+// <namespace>_<method> format used to invoke them.
+func (s *Server) Methods() []string {
+	return s.services.methods()
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -191,3 +191,43 @@ func TestServerBatchResponseSizeLimit(t *testing.T) {
 		}
 	}
 }
+
+type unknownMethodError struct{ method string }
+
+func (e *unknownMethodError) Error() string  { return "custom: " + e.method }
+func (e *unknownMethodError) ErrorCode() int { return -32601 }
+
+func TestServerMethodNotFoundHandler(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+
+	server.SetMethodNotFoundHandler(func(method string) error {
+		if method == "test_custom" {
+			return &unknownMethodError{method}
+		}
+		return nil
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	// Methods with an overridden error should respond with it
+	err := client.Call(nil, "test_custom")
+	if err == nil || err.Error() != "custom: test_custom" {
+		t.Fatalf("overridden error mismatch: have %v, want %q", err, "custom: test_custom")
+	}
+	// Other unknown methods should respond with the default error
+	err = client.Call(nil, "test_unknown")
+	if re, ok := err.(Error); !ok || re.ErrorCode() != -32601 || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("default error mismatch: have %v", err)
+	}
+	// Registered methods should be listed
+	var found bool
+	for _, method := range server.Methods() {
+		if method == "test_echo" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("registered method test_echo not listed in %v", server.Methods())
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"unicode"
@@ -38,6 +39,7 @@ var (
 type serviceRegistry struct {
 	mu       sync.Mutex
 	services map[string]service
+	notFound func(method string) error // optional override of method not found errors
 }
 
 // service represents a registered object.
@@ -102,6 +104,35 @@ func (r *serviceRegistry) callback(method string) *callback {
 	return r.services[elem[0]].callbacks[elem[1]]
 }
 
+// methodNotFound returns the error to respond with to a call of an unknown method.
+func (r *serviceRegistry) methodNotFound(method string) error {
+	r.mu.Lock()
+	notFound := r.notFound
+	r.mu.Unlock()
+
+	if notFound != nil {
+		if err := notFound(method); err != nil {
+			return err
+		}
+	}
+	return &methodNotFoundError{method: method}
+}
+
+// methods returns the names of all registered methods.
+func (r *serviceRegistry) methods() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var methods []string
+	for name, service := range r.services {
+		for method := range service.callbacks {
+			methods = append(methods, name+serviceMethodSeparator+method)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
 // subscription returns a subscription callback in the given service.
 func (r *serviceRegistry) subscription(service, name string) *callback {
 	r.mu.Lock()
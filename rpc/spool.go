@@ -0,0 +1,219 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/gorievm/go-gori/log"
+)
+
+// ErrSpoolQuotaExceeded is returned when a spooled result would grow beyond the
+// disk quota shared by all in-flight spooled responses.
+var ErrSpoolQuotaExceeded = &internalServerError{errcodeResponseTooLarge, "response exceeds spool quota"}
+
+// ResultStream is implemented by method results whose JSON encoding is not held in
+// memory. Instead of being marshalled when the method returns, the encoding is read
+// while the response is written, so transports writing to a byte stream (HTTP, IPC)
+// can send it without buffering it. The stream is closed once the response has been
+// sent or dropped.
+type ResultStream interface {
+	io.Reader
+	io.Closer
+}
+
+// Spool manages temporary files holding method results that are too large to be
+// kept in memory. Results whose encoding exceeds the threshold are spilled to disk,
+// with the total size of all live spool files bounded by the quota.
+type Spool struct {
+	dir       string // directory for the spool files, empty for the system default
+	threshold uint64 // encoded size above which results are spilled to disk
+	quota     uint64 // maximum total size of all spool files
+
+	lock sync.Mutex
+	used uint64 // bytes currently reserved by live spool files
+}
+
+// NewSpool creates a spool storing its files in dir. Results of up to threshold
+// bytes are kept in memory, larger ones are written to disk as long as the total
+// size of the spool stays below quota.
+func NewSpool(dir string, threshold, quota uint64) *Spool {
+	return &Spool{dir: dir, threshold: threshold, quota: quota}
+}
+
+// Used returns the number of bytes currently held on disk by the spool.
+func (s *Spool) Used() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.used
+}
+
+// reserve claims size bytes of the disk quota.
+func (s *Spool) reserve(size uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.used+size > s.quota {
+		return ErrSpoolQuotaExceeded
+	}
+	s.used += size
+	return nil
+}
+
+// release returns size bytes to the disk quota.
+func (s *Spool) release(size uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.used -= size
+}
+
+// NewArray creates a builder for a JSON array result backed by the spool. It is
+// safe to call on a nil spool, in which case the array is always kept in memory.
+func (s *Spool) NewArray() *SpoolArray {
+	return &SpoolArray{spool: s, buf: []byte{'['}}
+}
+
+// SpoolArray incrementally encodes a JSON array. Elements are buffered in memory
+// until the encoding exceeds the spool threshold, after which the array is moved
+// to a temporary file and streamed to the client when the response is written.
+type SpoolArray struct {
+	spool *Spool
+	buf   []byte        // in-memory encoding, nil once spilled
+	file  *os.File      // spool file, nil while in memory
+	out   *bufio.Writer // buffered writer into the spool file
+	size  uint64        // bytes reserved from the quota
+	count int           // number of elements appended
+}
+
+// Append encodes v and adds it to the array. If the array was spilled to disk and
+// the spool quota is exhausted, ErrSpoolQuotaExceeded is returned and the array is
+// discarded.
+func (a *SpoolArray) Append(v interface{}) error {
+	enc, err := json.Marshal(v)
+	if err != nil {
+		a.Discard()
+		return err
+	}
+	if a.count > 0 {
+		enc = append([]byte{','}, enc...)
+	}
+	a.count++
+
+	if a.file == nil {
+		a.buf = append(a.buf, enc...)
+		if a.spool == nil || uint64(len(a.buf)) <= a.spool.threshold {
+			return nil
+		}
+		return a.spill()
+	}
+	return a.write(enc)
+}
+
+// spill moves the in-memory encoding into a new spool file.
+func (a *SpoolArray) spill() error {
+	file, err := os.CreateTemp(a.spool.dir, "result-*.json")
+	if err != nil {
+		a.Discard()
+		return err
+	}
+	a.file, a.out = file, bufio.NewWriter(file)
+
+	buf := a.buf
+	a.buf = nil
+	return a.write(buf)
+}
+
+// write appends data to the spool file, reserving the space from the quota.
+func (a *SpoolArray) write(data []byte) error {
+	if err := a.spool.reserve(uint64(len(data))); err != nil {
+		a.Discard()
+		return err
+	}
+	a.size += uint64(len(data))
+
+	if _, err := a.out.Write(data); err != nil {
+		a.Discard()
+		return err
+	}
+	return nil
+}
+
+// Result finalizes the array and returns it as a method result. Arrays kept in
+// memory are returned as a json.RawMessage, spilled ones as a ResultStream which
+// removes the spool file when closed.
+func (a *SpoolArray) Result() (interface{}, error) {
+	if a.file == nil {
+		return json.RawMessage(append(a.buf, ']')), nil
+	}
+	if err := a.write([]byte{']'}); err != nil {
+		return nil, err
+	}
+	if err := a.out.Flush(); err != nil {
+		a.Discard()
+		return nil, err
+	}
+	if _, err := a.file.Seek(0, io.SeekStart); err != nil {
+		a.Discard()
+		return nil, err
+	}
+	stream := &spoolStream{spool: a.spool, file: a.file, size: a.size}
+	a.file, a.out, a.size = nil, nil, 0
+	return stream, nil
+}
+
+// Discard drops the array, releasing any disk space it holds.
+func (a *SpoolArray) Discard() {
+	if a.file != nil {
+		removeSpoolFile(a.file)
+		a.spool.release(a.size)
+	}
+	a.buf, a.file, a.out, a.size = nil, nil, nil, 0
+}
+
+// spoolStream is the ResultStream of a spilled array.
+type spoolStream struct {
+	spool *Spool
+	file  *os.File
+	size  uint64
+	once  sync.Once
+}
+
+func (s *spoolStream) Read(p []byte) (int, error) {
+	return s.file.Read(p)
+}
+
+func (s *spoolStream) Close() error {
+	s.once.Do(func() {
+		removeSpoolFile(s.file)
+		s.spool.release(s.size)
+	})
+	return nil
+}
+
+// removeSpoolFile closes and deletes a spool file.
+func removeSpoolFile(file *os.File) {
+	file.Close()
+	if err := os.Remove(file.Name()); err != nil {
+		log.Warn("Failed to remove RPC spool file", "file", file.Name(), "err", err)
+	}
+}
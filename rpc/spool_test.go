@@ -0,0 +1,191 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// spoolService returns its results through a spool.
+type spoolService struct{ spool *Spool }
+
+func (s *spoolService) Numbers(n int) (interface{}, error) {
+	array := s.spool.NewArray()
+	for i := 0; i < n; i++ {
+		if err := array.Append(i); err != nil {
+			return nil, err
+		}
+	}
+	return array.Result()
+}
+
+func sequence(n int) []int {
+	seq := make([]int, n)
+	for i := range seq {
+		seq[i] = i
+	}
+	return seq
+}
+
+// checkSpoolEmpty verifies that the spool holds no files and no reserved space.
+func checkSpoolEmpty(t *testing.T, spool *Spool) {
+	t.Helper()
+
+	if used := spool.Used(); used != 0 {
+		t.Errorf("spool still holds %d bytes", used)
+	}
+	files, err := os.ReadDir(spool.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("spool still holds %d files", len(files))
+	}
+}
+
+func TestSpoolArray(t *testing.T) {
+	spool := NewSpool(t.TempDir(), 64, 1024*1024)
+
+	// Small arrays are kept in memory
+	array := spool.NewArray()
+	for _, v := range sequence(3) {
+		array.Append(v)
+	}
+	result, err := array.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, ok := result.(json.RawMessage); !ok || string(raw) != "[0,1,2]" {
+		t.Fatalf("wrong in-memory result: %v", result)
+	}
+	checkSpoolEmpty(t, spool)
+
+	// Large arrays are spilled to disk
+	array = spool.NewArray()
+	for _, v := range sequence(1000) {
+		array.Append(v)
+	}
+	result, err = array.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, ok := result.(ResultStream)
+	if !ok {
+		t.Fatalf("large result not spooled: %T", result)
+	}
+	if spool.Used() == 0 {
+		t.Fatal("spooled result not accounted")
+	}
+	blob, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(sequence(1000))
+	if string(blob) != string(want) {
+		t.Fatalf("spooled result mismatch: have %s, want %s", blob, want)
+	}
+	stream.Close()
+	checkSpoolEmpty(t, spool)
+}
+
+func TestSpoolQuota(t *testing.T) {
+	spool := NewSpool(t.TempDir(), 16, 256)
+
+	array := spool.NewArray()
+	var err error
+	for i := 0; i < 1000 && err == nil; i++ {
+		err = array.Append(i)
+	}
+	if !errors.Is(err, ErrSpoolQuotaExceeded) {
+		t.Fatalf("wrong error: have %v, want %v", err, ErrSpoolQuotaExceeded)
+	}
+	checkSpoolEmpty(t, spool)
+}
+
+func TestSpoolServer(t *testing.T) {
+	spool := NewSpool(t.TempDir(), 64, 1024*1024)
+
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("spool", &spoolService{spool}); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	wssrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer wssrv.Close()
+
+	want := sequence(1000)
+
+	// Streamed over HTTP
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var have []int
+	if err := client.Call(&have, "spool_numbers", len(want)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatal("wrong result over HTTP")
+	}
+	checkSpoolEmpty(t, spool)
+
+	// Read into memory for batches
+	var small, large []int
+	batch := []BatchElem{
+		{Method: "spool_numbers", Args: []interface{}{3}, Result: &small},
+		{Method: "spool_numbers", Args: []interface{}{len(want)}, Result: &large},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			t.Fatalf("batch element %d failed: %v", i, elem.Error)
+		}
+	}
+	if !reflect.DeepEqual(small, sequence(3)) || !reflect.DeepEqual(large, want) {
+		t.Fatal("wrong results in batch")
+	}
+	checkSpoolEmpty(t, spool)
+
+	// Encoded in memory over websocket
+	wsclient, err := DialWebsocket(context.Background(), "ws:"+strings.TrimPrefix(wssrv.URL, "http:"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wsclient.Close()
+
+	have = nil
+	if err := wsclient.Call(&have, "spool_numbers", len(want)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatal("wrong result over websocket")
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package audit implements a structured audit log of the approval decisions
+// made by the signer, exported as JSON lines to a configurable sink.
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/internal/ethapi"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/signer/core"
+)
+
+// Outcomes of the audited requests.
+const (
+	OutcomeApproved = "approved" // The request was approved
+	OutcomeRejected = "rejected" // The request was rejected
+	OutcomeFailed   = "failed"   // The approval could not be obtained
+	OutcomeSigned   = "signed"   // An approved transaction was signed
+)
+
+// Record is a single audited decision of the signer.
+type Record struct {
+	Time        time.Time       `json:"time"`
+	Method      string          `json:"method"`
+	RequestHash common.Hash     `json:"requestHash"` // Keccak256 hash of the JSON encoded request
+	Metadata    core.Metadata   `json:"metadata"`
+	Outcome     string          `json:"outcome"`
+	Error       string          `json:"error,omitempty"`
+	Request     json.RawMessage `json:"request"`
+}
+
+// auditUI provides an implementation of UIClientAPI that records the outcome of
+// every approval request handled by the next UI into an audit sink.
+type auditUI struct {
+	next core.UIClientAPI // The next handler, which makes the decisions
+	sink Sink             // The destination of the audit records
+}
+
+// NewAuditor wraps the given UI, recording all approval decisions made by it
+// into the audit sink.
+func NewAuditor(next core.UIClientAPI, sink Sink) core.UIClientAPI {
+	return &auditUI{next: next, sink: sink}
+}
+
+// encode marshals a request for auditing. Requests are encoded before they are
+// passed on, as the next UI may modify them.
+func encode(request interface{}) json.RawMessage {
+	blob, err := json.Marshal(request)
+	if err != nil {
+		log.Warn("Failed to encode audited request", "err", err)
+		return json.RawMessage("null")
+	}
+	return blob
+}
+
+// record writes an audit record of a request and the decision made about it.
+func (a *auditUI) record(method string, request json.RawMessage, meta core.Metadata, approved bool, err error) {
+	outcome := OutcomeRejected
+	switch {
+	case err != nil:
+		outcome = OutcomeFailed
+	case approved:
+		outcome = OutcomeApproved
+	}
+	a.write(method, request, meta, outcome, err)
+}
+
+// write assembles an audit record and writes it into the sink. Failures are
+// logged, but do not affect the decision made about the request.
+func (a *auditUI) write(method string, request json.RawMessage, meta core.Metadata, outcome string, err error) {
+	rec := Record{
+		Time:        time.Now().UTC(),
+		Method:      method,
+		RequestHash: crypto.Keccak256Hash(request),
+		Metadata:    meta,
+		Outcome:     outcome,
+		Request:     request,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	blob, encErr := json.Marshal(rec)
+	if encErr != nil {
+		log.Warn("Failed to encode audit record", "method", method, "err", encErr)
+		return
+	}
+	if err := a.sink.Write(blob); err != nil {
+		log.Warn("Failed to write audit record", "method", method, "hash", rec.RequestHash, "err", err)
+	}
+}
+
+func (a *auditUI) ApproveTx(request *core.SignTxRequest) (core.SignTxResponse, error) {
+	req := encode(request)
+	res, err := a.next.ApproveTx(request)
+	a.record("ApproveTx", req, request.Meta, res.Approved, err)
+	return res, err
+}
+
+func (a *auditUI) ApproveSignData(request *core.SignDataRequest) (core.SignDataResponse, error) {
+	req := encode(request)
+	res, err := a.next.ApproveSignData(request)
+	a.record("ApproveSignData", req, request.Meta, res.Approved, err)
+	return res, err
+}
+
+func (a *auditUI) ApproveListing(request *core.ListRequest) (core.ListResponse, error) {
+	req := encode(request)
+	res, err := a.next.ApproveListing(request)
+	a.record("ApproveListing", req, request.Meta, len(res.Accounts) > 0, err)
+	return res, err
+}
+
+func (a *auditUI) ApproveNewAccount(request *core.NewAccountRequest) (core.NewAccountResponse, error) {
+	req := encode(request)
+	res, err := a.next.ApproveNewAccount(request)
+	a.record("ApproveNewAccount", req, request.Meta, res.Approved, err)
+	return res, err
+}
+
+func (a *auditUI) OnApprovedTx(tx ethapi.SignTransactionResult) {
+	a.write("OnApprovedTx", encode(tx), core.Metadata{}, OutcomeSigned, nil)
+	a.next.OnApprovedTx(tx)
+}
+
+func (a *auditUI) ShowError(message string) {
+	a.next.ShowError(message)
+}
+
+func (a *auditUI) ShowInfo(message string) {
+	a.next.ShowInfo(message)
+}
+
+func (a *auditUI) OnSignerStartup(info core.StartupInfo) {
+	a.next.OnSignerStartup(info)
+}
+
+func (a *auditUI) OnInputRequired(info core.UserInputRequest) (core.UserInputResponse, error) {
+	return a.next.OnInputRequired(info)
+}
+
+func (a *auditUI) RegisterUIServer(api *core.UIServerAPI) {
+	a.next.RegisterUIServer(api)
+}
@@ -0,0 +1,254 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/internal/ethapi"
+	"github.com/gorievm/go-gori/signer/core"
+	"github.com/gorievm/go-gori/signer/core/apitypes"
+)
+
+// testUI is a UI which approves transactions and rejects everything else. It
+// modifies the approved transactions to check that the original is audited.
+type testUI struct {
+	fail bool
+}
+
+func (ui *testUI) ApproveTx(request *core.SignTxRequest) (core.SignTxResponse, error) {
+	if ui.fail {
+		return core.SignTxResponse{}, errors.New("ui unavailable")
+	}
+	gas := hexutil.Uint64(21000)
+	request.Transaction.Gas = gas
+	return core.SignTxResponse{Transaction: request.Transaction, Approved: true}, nil
+}
+
+func (ui *testUI) ApproveSignData(request *core.SignDataRequest) (core.SignDataResponse, error) {
+	return core.SignDataResponse{Approved: false}, nil
+}
+
+func (ui *testUI) ApproveListing(request *core.ListRequest) (core.ListResponse, error) {
+	return core.ListResponse{}, nil
+}
+
+func (ui *testUI) ApproveNewAccount(request *core.NewAccountRequest) (core.NewAccountResponse, error) {
+	return core.NewAccountResponse{Approved: true}, nil
+}
+
+func (ui *testUI) ShowError(message string)                     {}
+func (ui *testUI) ShowInfo(message string)                      {}
+func (ui *testUI) OnApprovedTx(tx ethapi.SignTransactionResult) {}
+func (ui *testUI) OnSignerStartup(info core.StartupInfo)        {}
+func (ui *testUI) RegisterUIServer(api *core.UIServerAPI)       {}
+func (ui *testUI) OnInputRequired(info core.UserInputRequest) (core.UserInputResponse, error) {
+	return core.UserInputResponse{}, nil
+}
+
+// memorySink collects audit records in memory.
+type memorySink struct {
+	records [][]byte
+	err     error
+}
+
+func (s *memorySink) Write(record []byte) error {
+	s.records = append(s.records, record)
+	return s.err
+}
+
+func (s *memorySink) Close() error { return nil }
+
+func (s *memorySink) decode(t *testing.T) []Record {
+	t.Helper()
+
+	var records []Record
+	for _, blob := range s.records {
+		var rec Record
+		if err := json.Unmarshal(blob, &rec); err != nil {
+			t.Fatalf("failed to decode record %s: %v", blob, err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func testTxRequest() *core.SignTxRequest {
+	to := common.NewMixedcaseAddress(common.HexToAddress("0x1337"))
+	return &core.SignTxRequest{
+		Transaction: apitypes.SendTxArgs{
+			From:  common.NewMixedcaseAddress(common.HexToAddress("0xdead")),
+			To:    &to,
+			Gas:   hexutil.Uint64(50000),
+			Value: hexutil.Big(*common.Big1),
+		},
+		Meta: core.Metadata{Remote: "127.0.0.1:1234", Scheme: "HTTP"},
+	}
+}
+
+func TestAuditRecords(t *testing.T) {
+	var (
+		sink = new(memorySink)
+		ui   = NewAuditor(&testUI{}, sink)
+	)
+	req := testTxRequest()
+	orig, _ := json.Marshal(req)
+
+	res, err := ui.ApproveTx(req)
+	if err != nil || !res.Approved {
+		t.Fatalf("transaction not approved: %v", err)
+	}
+	ui.ApproveSignData(&core.SignDataRequest{ContentType: "text/plain", Meta: core.Metadata{Remote: "signdata"}})
+	ui.ApproveListing(&core.ListRequest{Meta: core.Metadata{Remote: "listing"}})
+	ui.ApproveNewAccount(&core.NewAccountRequest{Meta: core.Metadata{Remote: "newaccount"}})
+	ui.OnApprovedTx(ethapi.SignTransactionResult{Raw: hexutil.Bytes{0x01}})
+
+	records := sink.decode(t)
+	want := []struct {
+		method  string
+		outcome string
+		remote  string
+	}{
+		{"ApproveTx", OutcomeApproved, "127.0.0.1:1234"},
+		{"ApproveSignData", OutcomeRejected, "signdata"},
+		{"ApproveListing", OutcomeRejected, "listing"},
+		{"ApproveNewAccount", OutcomeApproved, "newaccount"},
+		{"OnApprovedTx", OutcomeSigned, ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("record count mismatch: have %d, want %d", len(records), len(want))
+	}
+	for i, w := range want {
+		rec := records[i]
+		if rec.Method != w.method || rec.Outcome != w.outcome || rec.Metadata.Remote != w.remote {
+			t.Errorf("record %d: have %s/%s/%q, want %s/%s/%q", i, rec.Method, rec.Outcome, rec.Metadata.Remote, w.method, w.outcome, w.remote)
+		}
+		if rec.RequestHash != crypto.Keccak256Hash(rec.Request) {
+			t.Errorf("record %d: request hash mismatch", i)
+		}
+		if rec.Time.IsZero() {
+			t.Errorf("record %d: missing timestamp", i)
+		}
+	}
+	// The transaction should be audited as requested, not as modified by the UI
+	if hash := crypto.Keccak256Hash(orig); records[0].RequestHash != hash {
+		t.Errorf("audited request hash mismatch: have %x, want %x", records[0].RequestHash, hash)
+	}
+}
+
+func TestAuditFailures(t *testing.T) {
+	// UI failures should be recorded as such
+	sink := new(memorySink)
+	if _, err := NewAuditor(&testUI{fail: true}, sink).ApproveTx(testTxRequest()); err == nil {
+		t.Fatal("expected approval failure")
+	}
+	records := sink.decode(t)
+	if len(records) != 1 || records[0].Outcome != OutcomeFailed || records[0].Error != "ui unavailable" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+	// Sink failures should not affect the decision
+	sink = &memorySink{err: errors.New("disk full")}
+	res, err := NewAuditor(&testUI{}, sink).ApproveTx(testTxRequest())
+	if err != nil || !res.Approved {
+		t.Fatalf("decision affected by sink failure: %v", err)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	// Records should be appended across reopens
+	for i := 0; i < 2; i++ {
+		sink, err := NewSink("file://" + path)
+		if err != nil {
+			t.Fatalf("failed to open sink: %v", err)
+		}
+		NewAuditor(&testUI{}, sink).ApproveTx(testTxRequest())
+		sink.Close()
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("audit file permissions mismatch: have %o, want 600", perm)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var lines int
+	for scanner := bufio.NewScanner(file); scanner.Scan(); lines++ {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("line %d: invalid record: %v", lines, err)
+		}
+		if rec.Method != "ApproveTx" || rec.Outcome != OutcomeApproved {
+			t.Errorf("line %d: unexpected record %s/%s", lines, rec.Method, rec.Outcome)
+		}
+	}
+	if lines != 2 {
+		t.Fatalf("line count mismatch: have %d, want 2", lines)
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	records := make(chan Record, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "bad content type", http.StatusUnsupportedMediaType)
+			return
+		}
+		blob, _ := io.ReadAll(r.Body)
+		var rec Record
+		if err := json.Unmarshal(blob, &rec); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		records <- rec
+	}))
+	defer srv.Close()
+
+	sink, err := NewSink(srv.URL)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	defer sink.Close()
+
+	NewAuditor(&testUI{}, sink).ApproveNewAccount(&core.NewAccountRequest{})
+	rec := <-records
+	if rec.Method != "ApproveNewAccount" || rec.Outcome != OutcomeApproved {
+		t.Errorf("unexpected record %s/%s", rec.Method, rec.Outcome)
+	}
+	// Non-2xx responses should be reported as errors
+	if err := sink.Write([]byte("not json")); err == nil {
+		t.Error("expected error for rejected record")
+	}
+}
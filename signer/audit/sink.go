@@ -0,0 +1,119 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// httpTimeout is the maximum time to wait for an HTTP sink to accept a record.
+const httpTimeout = 10 * time.Second
+
+// Sink is a destination for JSON encoded audit records.
+type Sink interface {
+	// Write emits a single audit record.
+	Write(record []byte) error
+
+	// Close releases the resources held by the sink.
+	Close() error
+}
+
+// NewSink creates an audit sink from its specification, which is one of:
+//   - an http:// or https:// URL to post each record to,
+//   - syslog:// for the local syslog daemon, or syslog://host:port for a
+//     remote one over UDP,
+//   - a file path (optionally prefixed with file://) to append JSON lines to.
+func NewSink(spec string) (Sink, error) {
+	switch {
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		if _, err := url.Parse(spec); err != nil {
+			return nil, err
+		}
+		return &httpSink{url: spec, client: &http.Client{Timeout: httpTimeout}}, nil
+	case strings.HasPrefix(spec, "syslog://"):
+		u, err := url.Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		return newSyslogSink(u.Host)
+	default:
+		return newFileSink(strings.TrimPrefix(spec, "file://"))
+	}
+}
+
+// fileSink appends audit records as JSON lines to a file.
+type fileSink struct {
+	file *os.File
+	lock sync.Mutex
+}
+
+// newFileSink opens (or creates) the file to append audit records to.
+func newFileSink(path string) (*fileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{file: file}, nil
+}
+
+func (s *fileSink) Write(record []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, err := s.file.Write(append(record, '\n'))
+	return err
+}
+
+func (s *fileSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.file.Close()
+}
+
+// httpSink posts each audit record as a JSON document to an HTTP endpoint.
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *httpSink) Write(record []byte) error {
+	res, err := s.client.Post(s.url, "application/json", bytes.NewReader(record))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("audit endpoint responded with %s", res.Status)
+	}
+	return nil
+}
+
+func (s *httpSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
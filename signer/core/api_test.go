@@ -318,3 +318,53 @@ func TestSignTx(t *testing.T) {
 		t.Error("Expected tx to be modified by UI")
 	}
 }
+
+func TestSignBlobTx(t *testing.T) {
+	api, control := setup(t)
+	createAccount(control, api, t)
+	control.approveCh <- "A"
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := common.NewMixedcaseAddress(list[0])
+
+	tx := mkTestTx(a)
+	tx.GasPrice = nil
+	tx.MaxFeePerGas = (*hexutil.Big)(big.NewInt(2000000000))
+	tx.MaxPriorityFeePerGas = (*hexutil.Big)(big.NewInt(1000000000))
+	tx.BlobFeeCap = (*hexutil.Big)(big.NewInt(100))
+	tx.BlobHashes = []common.Hash{{0x01, 0xaa}, {0x01, 0xbb}}
+
+	control.approveCh <- "Y"
+	control.inputCh <- "a_long_password"
+	res, err := api.SignTransaction(context.Background(), tx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedTx := new(types.Transaction)
+	if err := parsedTx.UnmarshalBinary(res.Raw); err != nil {
+		t.Fatal(err)
+	}
+	if parsedTx.Type() != types.BlobTxType {
+		t.Fatalf("Expected blob transaction, got type %d", parsedTx.Type())
+	}
+	if hashes := parsedTx.BlobHashes(); len(hashes) != 2 || hashes[0] != tx.BlobHashes[0] || hashes[1] != tx.BlobHashes[1] {
+		t.Errorf("Blob hashes mismatch: have %v, want %v", hashes, tx.BlobHashes)
+	}
+	if parsedTx.BlobGasFeeCap().Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("Blob fee cap mismatch: have %v, want %v", parsedTx.BlobGasFeeCap(), 100)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1337)), parsedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sender != a.Address() {
+		t.Errorf("Sender mismatch: have %v, want %v", sender, a.Address())
+	}
+	// Blob transactions cannot create contracts
+	tx.To = nil
+	if _, err := api.SignTransaction(context.Background(), tx, nil); err == nil {
+		t.Error("Expected error for blob contract creation")
+	}
+}
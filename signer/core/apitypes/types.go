@@ -34,9 +34,14 @@ import (
 	"github.com/gorievm/go-gori/common/math"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/crypto"
+	"github.com/holiman/uint256"
 )
 
-var typedDataReferenceTypeRegexp = regexp.MustCompile(`^[A-Za-z](\w*)(\[\])?$`)
+var typedDataReferenceTypeRegexp = regexp.MustCompile(`^[A-Za-z](\w*)(\[[1-9]?\d*\])*$`)
+
+// typedDataArrayTypeRegexp matches array types, capturing the element type and
+// the length of the outermost dimension, which is empty for dynamic arrays.
+var typedDataArrayTypeRegexp = regexp.MustCompile(`^(.+)\[([1-9]\d*)?\]$`)
 
 type ValidationInfo struct {
 	Typ     string `json:"type"`
@@ -98,6 +103,10 @@ type SendTxArgs struct {
 	// For non-legacy transactions
 	AccessList *types.AccessList `json:"accessList,omitempty"`
 	ChainID    *hexutil.Big      `json:"chainId,omitempty"`
+
+	// For blob transactions
+	BlobFeeCap *hexutil.Big  `json:"maxFeePerBlobGas,omitempty"`
+	BlobHashes []common.Hash `json:"blobVersionedHashes,omitempty"`
 }
 
 func (args SendTxArgs) String() string {
@@ -126,6 +135,30 @@ func (args *SendTxArgs) ToTransaction() *types.Transaction {
 
 	var data types.TxData
 	switch {
+	case args.BlobHashes != nil:
+		al := types.AccessList{}
+		if args.AccessList != nil {
+			al = *args.AccessList
+		}
+		// Blob transactions cannot create contracts, which is rejected during
+		// validation already.
+		var dest common.Address
+		if to != nil {
+			dest = *to
+		}
+		data = &types.BlobTx{
+			To:         dest,
+			ChainID:    toUint256(args.ChainID),
+			Nonce:      uint64(args.Nonce),
+			Gas:        uint64(args.Gas),
+			GasFeeCap:  toUint256(args.MaxFeePerGas),
+			GasTipCap:  toUint256(args.MaxPriorityFeePerGas),
+			Value:      toUint256(&args.Value),
+			Data:       input,
+			AccessList: al,
+			BlobFeeCap: toUint256(args.BlobFeeCap),
+			BlobHashes: args.BlobHashes,
+		}
 	case args.MaxFeePerGas != nil:
 		al := types.AccessList{}
 		if args.AccessList != nil {
@@ -166,6 +199,16 @@ func (args *SendTxArgs) ToTransaction() *types.Transaction {
 	return types.NewTx(data)
 }
 
+// toUint256 converts an optional big integer into a uint256, with missing values
+// treated as zero. Values exceeding 256 bits are rejected during validation.
+func toUint256(b *hexutil.Big) *uint256.Int {
+	v := new(uint256.Int)
+	if b != nil {
+		v.SetFromBig((*big.Int)(b))
+	}
+	return v
+}
+
 type SigFormat struct {
 	Mime        string
 	ByteVersion byte
@@ -188,6 +231,10 @@ var (
 		accounts.MimetypeTextPlain,
 		0x45,
 	}
+	ApplicationAuthorization = SigFormat{
+		accounts.MimetypeAuthorization,
+		0x05,
+	}
 )
 
 type ValidatorData struct {
@@ -195,6 +242,15 @@ type ValidatorData struct {
 	Message hexutil.Bytes
 }
 
+// Authorization is an EIP-7702 authorization, allowing the code of the signing
+// account to be set to a delegation to the given address. A zero chain id makes
+// the authorization valid on all chains.
+type Authorization struct {
+	ChainID hexutil.Big             `json:"chainId"`
+	Address common.MixedcaseAddress `json:"address"`
+	Nonce   hexutil.Uint64          `json:"nonce"`
+}
+
 // TypedData is a type to encapsulate EIP-712 typed messages
 type TypedData struct {
 	Types       Types            `json:"types"`
@@ -209,17 +265,36 @@ type Type struct {
 	Type string `json:"type"`
 }
 
-func (t *Type) isArray() bool {
-	return strings.HasSuffix(t.Type, "[]")
+// typeName returns the canonical name of the type. If the type is 'Person[]' or
+// 'Person[2][]', then this method returns 'Person'
+func (t *Type) typeName() string {
+	return baseTypeName(t.Type)
 }
 
-// typeName returns the canonical name of the type. If the type is 'Person[]', then
-// this method returns 'Person'
-func (t *Type) typeName() string {
-	if strings.HasSuffix(t.Type, "[]") {
-		return strings.TrimSuffix(t.Type, "[]")
+// baseTypeName strips all array dimensions from a type name.
+func baseTypeName(typ string) string {
+	if i := strings.IndexByte(typ, '['); i >= 0 {
+		return typ[:i]
+	}
+	return typ
+}
+
+// parseArrayType splits an array type into the type of its elements and its
+// length, which is -1 for dynamic arrays. Multi-dimensional arrays are split on
+// their outermost dimension, e.g. 'uint256[2][]' is a dynamic array of 'uint256[2]'.
+func parseArrayType(typ string) (string, int, bool) {
+	match := typedDataArrayTypeRegexp.FindStringSubmatch(typ)
+	if match == nil {
+		return "", 0, false
+	}
+	if match[2] == "" {
+		return match[1], -1, true
 	}
-	return t.Type
+	size, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return match[1], size, true
 }
 
 type Types map[string][]Type
@@ -270,7 +345,7 @@ func (typedData *TypedData) HashStruct(primaryType string, data TypedDataMessage
 
 // Dependencies returns an array of custom types ordered by their hierarchical reference tree
 func (typedData *TypedData) Dependencies(primaryType string, found []string) []string {
-	primaryType = strings.TrimSuffix(primaryType, "[]")
+	primaryType = baseTypeName(primaryType)
 	includes := func(arr []string, str string) bool {
 		for _, obj := range arr {
 			if obj == str {
@@ -356,34 +431,11 @@ func (typedData *TypedData) EncodeData(primaryType string, data map[string]inter
 		encType := field.Type
 		encValue := data[field.Name]
 		if encType[len(encType)-1:] == "]" {
-			arrayValue, err := convertDataToSlice(encValue)
+			encodedData, err := typedData.encodeArrayValue(encType, encValue, depth)
 			if err != nil {
-				return nil, dataMismatchError(encType, encValue)
-			}
-
-			arrayBuffer := bytes.Buffer{}
-			parsedType := strings.Split(encType, "[")[0]
-			for _, item := range arrayValue {
-				if typedData.Types[parsedType] != nil {
-					mapValue, ok := item.(map[string]interface{})
-					if !ok {
-						return nil, dataMismatchError(parsedType, item)
-					}
-					encodedData, err := typedData.EncodeData(parsedType, mapValue, depth+1)
-					if err != nil {
-						return nil, err
-					}
-					arrayBuffer.Write(crypto.Keccak256(encodedData))
-				} else {
-					bytesValue, err := typedData.EncodePrimitiveValue(parsedType, item, depth)
-					if err != nil {
-						return nil, err
-					}
-					arrayBuffer.Write(bytesValue)
-				}
+				return nil, err
 			}
-
-			buffer.Write(crypto.Keccak256(arrayBuffer.Bytes()))
+			buffer.Write(encodedData)
 		} else if typedData.Types[field.Type] != nil {
 			mapValue, ok := encValue.(map[string]interface{})
 			if !ok {
@@ -405,6 +457,53 @@ func (typedData *TypedData) EncodeData(primaryType string, data map[string]inter
 	return buffer.Bytes(), nil
 }
 
+// encodeArrayValue generates the hash of the concatenated encodings of the array
+// elements: `keccak256(enc(value₁) ‖ enc(value₂) ‖ … ‖ enc(valueₙ))`
+//
+// Elements which are arrays themselves are encoded recursively, struct elements
+// are encoded as their hashStruct. Fixed-size arrays need to have exactly as many
+// elements as their type declares.
+func (typedData *TypedData) encodeArrayValue(encType string, encValue interface{}, depth int) (hexutil.Bytes, error) {
+	elemType, size, ok := parseArrayType(encType)
+	if !ok {
+		return nil, fmt.Errorf("invalid array type '%s'", encType)
+	}
+	arrayValue, err := convertDataToSlice(encValue)
+	if err != nil {
+		return nil, dataMismatchError(encType, encValue)
+	}
+	if size >= 0 && len(arrayValue) != size {
+		return nil, fmt.Errorf("provided array of %d items doesn't match type '%s'", len(arrayValue), encType)
+	}
+	arrayBuffer := bytes.Buffer{}
+	for _, item := range arrayValue {
+		if strings.HasSuffix(elemType, "]") {
+			encodedData, err := typedData.encodeArrayValue(elemType, item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			arrayBuffer.Write(encodedData)
+		} else if typedData.Types[elemType] != nil {
+			mapValue, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, dataMismatchError(elemType, item)
+			}
+			encodedData, err := typedData.EncodeData(elemType, mapValue, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			arrayBuffer.Write(crypto.Keccak256(encodedData))
+		} else {
+			bytesValue, err := typedData.EncodePrimitiveValue(elemType, item, depth)
+			if err != nil {
+				return nil, err
+			}
+			arrayBuffer.Write(bytesValue)
+		}
+	}
+	return crypto.Keccak256(arrayBuffer.Bytes()), nil
+}
+
 // Attempt to parse bytes in different formats: byte array, hex string, hexutil.Bytes.
 func parseBytes(encType interface{}) ([]byte, bool) {
 	// Handle array types.
@@ -627,49 +726,56 @@ func (typedData *TypedData) formatData(primaryType string, data map[string]inter
 
 	// Add field contents. Structs and arrays have special handlers.
 	for _, field := range typedData.Types[primaryType] {
-		encName := field.Name
-		encValue := data[encName]
-		item := &NameValueType{
-			Name: encName,
-			Typ:  field.Type,
-		}
-		if field.isArray() {
-			arrayValue, _ := convertDataToSlice(encValue)
-			parsedType := field.typeName()
-			for _, v := range arrayValue {
-				if typedData.Types[parsedType] != nil {
-					mapValue, _ := v.(map[string]interface{})
-					mapOutput, err := typedData.formatData(parsedType, mapValue)
-					if err != nil {
-						return nil, err
-					}
-					item.Value = mapOutput
-				} else {
-					primitiveOutput, err := formatPrimitiveValue(field.Type, encValue)
-					if err != nil {
-						return nil, err
-					}
-					item.Value = primitiveOutput
-				}
-			}
-		} else if typedData.Types[field.Type] != nil {
-			if mapValue, ok := encValue.(map[string]interface{}); ok {
-				mapOutput, err := typedData.formatData(field.Type, mapValue)
-				if err != nil {
-					return nil, err
-				}
-				item.Value = mapOutput
-			} else {
-				item.Value = "<nil>"
-			}
-		} else {
-			primitiveOutput, err := formatPrimitiveValue(field.Type, encValue)
-			if err != nil {
-				return nil, err
-			}
-			item.Value = primitiveOutput
+		value, err := typedData.formatValue(field.Type, data[field.Name])
+		if err != nil {
+			return nil, err
 		}
-		output = append(output, item)
+		output = append(output, &NameValueType{
+			Name:  field.Name,
+			Value: value,
+			Typ:   field.Type,
+		})
+	}
+	return output, nil
+}
+
+// formatValue formats a single value of the given type, recursing into structs
+// and arrays.
+func (typedData *TypedData) formatValue(encType string, encValue interface{}) (interface{}, error) {
+	if strings.HasSuffix(encType, "]") {
+		return typedData.formatArray(encType, encValue)
+	}
+	if typedData.Types[encType] != nil {
+		mapValue, ok := encValue.(map[string]interface{})
+		if !ok {
+			return "<nil>", nil
+		}
+		return typedData.formatData(encType, mapValue)
+	}
+	return formatPrimitiveValue(encType, encValue)
+}
+
+// formatArray formats the elements of an array, naming them by their index.
+func (typedData *TypedData) formatArray(encType string, encValue interface{}) ([]*NameValueType, error) {
+	elemType, _, ok := parseArrayType(encType)
+	if !ok {
+		return nil, fmt.Errorf("invalid array type '%s'", encType)
+	}
+	arrayValue, err := convertDataToSlice(encValue)
+	if err != nil {
+		return nil, dataMismatchError(encType, encValue)
+	}
+	output := make([]*NameValueType, 0, len(arrayValue))
+	for i, item := range arrayValue {
+		value, err := typedData.formatValue(elemType, item)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, &NameValueType{
+			Name:  fmt.Sprintf("[%d]", i),
+			Value: value,
+			Typ:   elemType,
+		})
 	}
 	return output, nil
 }
@@ -735,35 +841,34 @@ func (t Types) validate() error {
 	return nil
 }
 
-// Checks if the primitive value is valid
+// Checks if the primitive value is valid. Arrays of primitive values, including
+// multi-dimensional and fixed-size ones, are also primitive types.
 func isPrimitiveTypeValid(primitiveType string) bool {
+	for {
+		elemType, _, ok := parseArrayType(primitiveType)
+		if !ok {
+			break
+		}
+		primitiveType = elemType
+	}
 	if primitiveType == "address" ||
-		primitiveType == "address[]" ||
 		primitiveType == "bool" ||
-		primitiveType == "bool[]" ||
 		primitiveType == "string" ||
-		primitiveType == "string[]" ||
 		primitiveType == "bytes" ||
-		primitiveType == "bytes[]" ||
 		primitiveType == "int" ||
-		primitiveType == "int[]" ||
-		primitiveType == "uint" ||
-		primitiveType == "uint[]" {
+		primitiveType == "uint" {
 		return true
 	}
-	// For 'bytesN', 'bytesN[]', we allow N from 1 to 32
+	// For 'bytesN', we allow N from 1 to 32
 	for n := 1; n <= 32; n++ {
-		// e.g. 'bytes28' or 'bytes28[]'
-		if primitiveType == fmt.Sprintf("bytes%d", n) || primitiveType == fmt.Sprintf("bytes%d[]", n) {
+		// e.g. 'bytes28'
+		if primitiveType == fmt.Sprintf("bytes%d", n) {
 			return true
 		}
 	}
-	// For 'intN','intN[]' and 'uintN','uintN[]' we allow N in increments of 8, from 8 up to 256
+	// For 'intN' and 'uintN' we allow N in increments of 8, from 8 up to 256
 	for n := 8; n <= 256; n += 8 {
-		if primitiveType == fmt.Sprintf("int%d", n) || primitiveType == fmt.Sprintf("int%d[]", n) {
-			return true
-		}
-		if primitiveType == fmt.Sprintf("uint%d", n) || primitiveType == fmt.Sprintf("uint%d[]", n) {
+		if primitiveType == fmt.Sprintf("int%d", n) || primitiveType == fmt.Sprintf("uint%d", n) {
 			return true
 		}
 	}
@@ -23,6 +23,7 @@ func TestIsPrimitive(t *testing.T) {
 	for i, tc := range []string{
 		"int24", "int24[]", "uint88", "uint88[]", "uint", "uint[]", "int256", "int256[]",
 		"uint96", "uint96[]", "int96", "int96[]", "bytes17[]", "bytes17",
+		"uint256[][]", "bytes32[2][]", "address[3]", "bool[1][2]",
 	} {
 		if !isPrimitiveTypeValid(tc) {
 			t.Errorf("test %d: expected '%v' to be a valid primitive", i, tc)
@@ -32,6 +33,7 @@ func TestIsPrimitive(t *testing.T) {
 	for i, tc := range []string{
 		"int257", "int257[]", "uint88 ", "uint88 []", "uint257", "uint-1[]",
 		"uint0", "uint0[]", "int95", "int95[]", "uint1", "uint1[]", "bytes33[]", "bytess",
+		"uint256[0]", "uint256[01]", "bool[-1]", "bytes32[2", "int8[][x]",
 	} {
 		if isPrimitiveTypeValid(tc) {
 			t.Errorf("test %d: expected '%v' to not be a valid primitive", i, tc)
@@ -127,6 +127,15 @@ func (ui *CommandlineUI) ApproveTx(request *SignTxRequest) (SignTxResponse, erro
 	if chainId := request.Transaction.ChainID; chainId != nil {
 		fmt.Printf("chainid:  %v\n", chainId)
 	}
+	if feeCap := request.Transaction.BlobFeeCap; feeCap != nil {
+		fmt.Printf("maxFeePerBlobGas:      %v wei\n", feeCap.ToInt())
+	}
+	if hashes := request.Transaction.BlobHashes; hashes != nil {
+		fmt.Printf("Blobs (%d)\n", len(hashes))
+		for i, hash := range hashes {
+			fmt.Printf(" %d. %v\n", i, hash)
+		}
+	}
 	if list := request.Transaction.AccessList; list != nil {
 		fmt.Printf("Accesslist\n")
 		for i, el := range *list {
@@ -0,0 +1,128 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorievm/go-gori/internal/ethapi"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/rpc"
+)
+
+// DefaultRemoteUITimeout is the default time a remote UI is given to answer a
+// request before it is considered rejected.
+const DefaultRemoteUITimeout = time.Minute
+
+// RemoteUI is a UI channel which forwards the requests over RPC to a remote
+// approval service, e.g. an HSM orchestrator, allowing clef to be run as an
+// unattended signing sidecar. The wire format is the same as for the stdio UI:
+// clef calls the ui_* methods of the remote service, which in turn may use the
+// clef_* methods over the same connection if it is bidirectional (WebSocket or
+// IPC).
+//
+// Requests which are not answered within the configured timeout, or which fail
+// due to connectivity problems, are treated as rejected.
+type RemoteUI struct {
+	client  *rpc.Client
+	timeout time.Duration
+}
+
+// NewRemoteUI creates a UI channel over the given client connection, which is
+// expected to be authenticated by the caller. A zero timeout selects the
+// default one.
+func NewRemoteUI(client *rpc.Client, timeout time.Duration) *RemoteUI {
+	if timeout == 0 {
+		timeout = DefaultRemoteUITimeout
+	}
+	return &RemoteUI{client: client, timeout: timeout}
+}
+
+func (ui *RemoteUI) RegisterUIServer(api *UIServerAPI) {
+	ui.client.RegisterName("clef", api)
+}
+
+// dispatch sends a request to the remote UI and waits for the response
+func (ui *RemoteUI) dispatch(serviceMethod string, args interface{}, reply interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ui.timeout)
+	defer cancel()
+
+	err := ui.client.CallContext(ctx, reply, serviceMethod, args)
+	if err != nil {
+		log.Warn("Remote UI request failed", "method", serviceMethod, "err", err)
+	}
+	return err
+}
+
+// notify sends a request to the remote UI, and does not listen for a response
+func (ui *RemoteUI) notify(serviceMethod string, args interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ui.timeout)
+	defer cancel()
+
+	err := ui.client.Notify(ctx, serviceMethod, args)
+	if err != nil {
+		log.Warn("Remote UI notification failed", "method", serviceMethod, "err", err)
+	}
+	return err
+}
+
+func (ui *RemoteUI) ApproveTx(request *SignTxRequest) (SignTxResponse, error) {
+	var result SignTxResponse
+	err := ui.dispatch("ui_approveTx", request, &result)
+	return result, err
+}
+
+func (ui *RemoteUI) ApproveSignData(request *SignDataRequest) (SignDataResponse, error) {
+	var result SignDataResponse
+	err := ui.dispatch("ui_approveSignData", request, &result)
+	return result, err
+}
+
+func (ui *RemoteUI) ApproveListing(request *ListRequest) (ListResponse, error) {
+	var result ListResponse
+	err := ui.dispatch("ui_approveListing", request, &result)
+	return result, err
+}
+
+func (ui *RemoteUI) ApproveNewAccount(request *NewAccountRequest) (NewAccountResponse, error) {
+	var result NewAccountResponse
+	err := ui.dispatch("ui_approveNewAccount", request, &result)
+	return result, err
+}
+
+func (ui *RemoteUI) ShowError(message string) {
+	ui.notify("ui_showError", &Message{message})
+}
+
+func (ui *RemoteUI) ShowInfo(message string) {
+	ui.notify("ui_showInfo", &Message{message})
+}
+
+func (ui *RemoteUI) OnApprovedTx(tx ethapi.SignTransactionResult) {
+	ui.notify("ui_onApprovedTx", tx)
+}
+
+func (ui *RemoteUI) OnSignerStartup(info StartupInfo) {
+	ui.notify("ui_onSignerStartup", info)
+}
+
+func (ui *RemoteUI) OnInputRequired(info UserInputRequest) (UserInputResponse, error) {
+	var result UserInputResponse
+	err := ui.dispatch("ui_onInputRequired", info, &result)
+	return result, err
+}
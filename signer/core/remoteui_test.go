@@ -0,0 +1,118 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/node"
+	"github.com/gorievm/go-gori/rpc"
+	"github.com/gorievm/go-gori/signer/core"
+)
+
+// remoteApprover is a remote approval service, accepting or rejecting all the
+// transactions it is asked about.
+type remoteApprover struct {
+	approve bool
+	delay   time.Duration
+	infos   chan string
+}
+
+func (s *remoteApprover) ApproveTx(req *core.SignTxRequest) (core.SignTxResponse, error) {
+	time.Sleep(s.delay)
+	return core.SignTxResponse{Transaction: req.Transaction, Approved: s.approve}, nil
+}
+
+func (s *remoteApprover) ShowInfo(msg core.Message) {
+	s.infos <- msg.Text
+}
+
+func startRemoteApprover(t *testing.T, service *remoteApprover, secret []byte) string {
+	t.Helper()
+
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("ui", service); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(node.NewWSHandlerStack(srv.WebsocketHandler([]string{"*"}), secret))
+	t.Cleanup(func() {
+		httpsrv.Close()
+		srv.Stop()
+	})
+	return "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+}
+
+func TestRemoteUI(t *testing.T) {
+	var (
+		secret  = common.Hex2Bytes("ba3a8c6cd2b29e28ab1b85a5b97d4fc1b4f19d1ac8ee09a6dcbd9e2eb5a7b7d1")
+		service = &remoteApprover{approve: true, infos: make(chan string, 1)}
+		url     = startRemoteApprover(t, service, secret)
+	)
+	// Unauthenticated connections should be refused
+	if _, err := rpc.DialOptions(context.Background(), url); err == nil {
+		t.Fatal("unauthenticated connection accepted")
+	}
+	var jwtSecret [32]byte
+	copy(jwtSecret[:], secret)
+	client, err := rpc.DialOptions(context.Background(), url, rpc.WithHTTPAuth(node.NewJWTAuth(jwtSecret)))
+	if err != nil {
+		t.Fatalf("failed to connect to remote UI: %v", err)
+	}
+	defer client.Close()
+
+	ui := core.NewRemoteUI(client, 100*time.Millisecond)
+
+	to := common.NewMixedcaseAddress(common.HexToAddress("0x1111111111111111111111111111111111111111"))
+	req := &core.SignTxRequest{}
+	req.Transaction.From = common.NewMixedcaseAddress(common.HexToAddress("0x2222222222222222222222222222222222222222"))
+	req.Transaction.To = &to
+
+	res, err := ui.ApproveTx(req)
+	if err != nil {
+		t.Fatalf("failed to request approval: %v", err)
+	}
+	if !res.Approved {
+		t.Fatal("transaction not approved")
+	}
+	if res.Transaction.To == nil || res.Transaction.To.Address() != to.Address() {
+		t.Fatalf("transaction mismatch: have %v, want %v", res.Transaction.To, to)
+	}
+	// Notifications should be delivered
+	ui.ShowInfo("hello")
+	select {
+	case text := <-service.infos:
+		if text != "hello" {
+			t.Fatalf("message mismatch: have %q, want %q", text, "hello")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("notification not delivered")
+	}
+	// Requests not answered in time should fail
+	service.delay = time.Second
+	if _, err := ui.ApproveTx(req); err == nil {
+		t.Fatal("expected timeout error")
+	}
+	// Requests the remote service does not handle should fail
+	if _, err := ui.ApproveListing(&core.ListRequest{}); err == nil {
+		t.Fatal("expected error for unsupported request")
+	}
+}
@@ -164,6 +164,55 @@ func (api *SignerAPI) determineSignatureFormat(ctx context.Context, contentType
 		// Clique uses V on the form 0 or 1
 		useEthereumV = false
 		req = &SignDataRequest{ContentType: mediaType, Rawdata: cliqueRlp, Messages: messages, Hash: sighash}
+	case apitypes.ApplicationAuthorization.Mime:
+		// EIP-7702 authorization to delegate the code of the account
+		auth, err := UnmarshalAuthorization(data)
+		if err != nil {
+			return nil, useEthereumV, err
+		}
+		chainID := auth.ChainID.ToInt()
+		msgs := new(apitypes.ValidationMessages)
+		switch {
+		case chainID.Sign() == 0:
+			msgs.Crit("Authorization is valid on all chains")
+		case chainID.Cmp(api.chainID) != 0:
+			return nil, useEthereumV, fmt.Errorf("requested chainid %d does not match the configuration of the signer", chainID)
+		}
+		if !auth.Address.ValidChecksum() {
+			msgs.Warn("Invalid checksum on delegate address")
+		}
+		if auth.Address.Address() == (common.Address{}) {
+			msgs.Info("Authorization clears the delegation of the account")
+		}
+		sighash, authRlp, err := authorizationHashAndRlp(auth)
+		if err != nil {
+			return nil, useEthereumV, err
+		}
+		messages := []*apitypes.NameValueType{
+			{
+				Name:  "This is a request to set the code of the account to a delegation (see EIP 7702)",
+				Typ:   "description",
+				Value: "",
+			},
+			{
+				Name:  "Chain ID",
+				Typ:   "uint256",
+				Value: chainID.String(),
+			},
+			{
+				Name:  "Delegate address",
+				Typ:   "address",
+				Value: auth.Address.String(),
+			},
+			{
+				Name:  "Account nonce",
+				Typ:   "uint64",
+				Value: fmt.Sprintf("%d", uint64(auth.Nonce)),
+			},
+		}
+		// Authorizations use V on the form 0 or 1
+		useEthereumV = false
+		req = &SignDataRequest{ContentType: mediaType, Rawdata: authRlp, Messages: messages, Callinfo: msgs.Messages, Hash: sighash}
 	case apitypes.DataTyped.Mime:
 		// EIP-712 conformant typed data
 		var err error
@@ -219,6 +268,18 @@ func cliqueHeaderHashAndRlp(header *types.Header) (hash, rlp []byte, err error)
 	return hash, rlp, err
 }
 
+// authorizationHashAndRlp returns the hash which is used as input for EIP-7702
+// authorization signing, along with the signed data itself: the magic byte
+// followed by the RLP encoding of the chain id, delegate address and nonce.
+func authorizationHashAndRlp(auth apitypes.Authorization) (hash, data []byte, err error) {
+	enc, err := rlp.EncodeToBytes([]interface{}{auth.ChainID.ToInt(), auth.Address.Address(), uint64(auth.Nonce)})
+	if err != nil {
+		return nil, nil, err
+	}
+	data = append([]byte{apitypes.ApplicationAuthorization.ByteVersion}, enc...)
+	return crypto.Keccak256(data), data, nil
+}
+
 // SignTypedData signs EIP-712 conformant typed data
 // hash = keccak256("\x19${byteVersion}${domainSeparator}${hashStruct(message)}")
 // It returns
@@ -343,3 +404,25 @@ func UnmarshalValidatorData(data interface{}) (apitypes.ValidatorData, error) {
 		Message: messageBytes,
 	}, nil
 }
+
+// UnmarshalAuthorization converts the given input to an EIP-7702 authorization.
+func UnmarshalAuthorization(data interface{}) (apitypes.Authorization, error) {
+	raw, ok := data.(map[string]interface{})
+	if !ok {
+		return apitypes.Authorization{}, errors.New("authorization input is not a map[string]interface{}")
+	}
+	for _, field := range []string{"chainId", "address", "nonce"} {
+		if _, ok := raw[field]; !ok {
+			return apitypes.Authorization{}, fmt.Errorf("authorization %s is undefined", field)
+		}
+	}
+	blob, err := json.Marshal(raw)
+	if err != nil {
+		return apitypes.Authorization{}, err
+	}
+	var auth apitypes.Authorization
+	if err := json.Unmarshal(blob, &auth); err != nil {
+		return apitypes.Authorization{}, fmt.Errorf("authorization error: %w", err)
+	}
+	return auth, nil
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/gorievm/go-gori/common/hexutil"
 	"github.com/gorievm/go-gori/common/math"
 	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/rlp"
 	"github.com/gorievm/go-gori/signer/core"
 	"github.com/gorievm/go-gori/signer/core/apitypes"
 )
@@ -432,6 +433,53 @@ func TestFuzzerFiles(t *testing.T) {
 	}
 }
 
+// TestV4Vectors tests the EIP-712 v4 encoding of nested and fixed-size arrays
+// against conformance vectors with precomputed hashes.
+func TestV4Vectors(t *testing.T) {
+	vectordir := path.Join("testdata", "v4")
+	testfiles, err := os.ReadDir(vectordir)
+	if err != nil {
+		t.Fatalf("failed reading files: %v", err)
+	}
+	for _, fInfo := range testfiles {
+		data, err := os.ReadFile(path.Join(vectordir, fInfo.Name()))
+		if err != nil {
+			t.Errorf("Failed to read file %v: %v", fInfo.Name(), err)
+			continue
+		}
+		var vector struct {
+			TypedData   apitypes.TypedData `json:"typedData"`
+			EncodeType  string             `json:"encodeType"`
+			MessageHash hexutil.Bytes      `json:"messageHash"`
+			Digest      hexutil.Bytes      `json:"digest"`
+		}
+		if err := json.Unmarshal(data, &vector); err != nil {
+			t.Errorf("file %v, json unmarshalling failed: %v", fInfo.Name(), err)
+			continue
+		}
+		messageHash, digest, err := sign(vector.TypedData)
+		if strings.HasPrefix(fInfo.Name(), "expfail") {
+			if err == nil {
+				t.Errorf("file %v succeeded (expected failure)", fInfo.Name())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("file %v failed: %v", fInfo.Name(), err)
+			continue
+		}
+		if have := string(vector.TypedData.EncodeType(vector.TypedData.PrimaryType)); have != vector.EncodeType {
+			t.Errorf("file %v, encodeType mismatch: have %s, want %s", fInfo.Name(), have, vector.EncodeType)
+		}
+		if !bytes.Equal(messageHash, vector.MessageHash) {
+			t.Errorf("file %v, message hash mismatch: have %x, want %x", fInfo.Name(), messageHash, vector.MessageHash)
+		}
+		if !bytes.Equal(digest, vector.Digest) {
+			t.Errorf("file %v, digest mismatch: have %x, want %x", fInfo.Name(), digest, vector.Digest)
+		}
+	}
+}
+
 var gnosisTypedData = `
 {
 	"types": {
@@ -998,3 +1046,57 @@ func TestComplexTypedDataWithLowercaseReftype(t *testing.T) {
 		t.Fatalf("Error, got %x, wanted %x", sighash, expSigHash)
 	}
 }
+
+func TestSignAuthorization(t *testing.T) {
+	api, control := setup(t)
+	createAccount(control, api, t)
+	control.approveCh <- "A"
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := common.NewMixedcaseAddress(list[0])
+
+	delegate := common.HexToAddress("0xB372a646f7F05Cc1785018dBDA7EBc734a2A20E2")
+	for _, chainID := range []string{"0x539", "0x0"} {
+		control.approveCh <- "Y"
+		control.inputCh <- "a_long_password"
+		auth := map[string]interface{}{
+			"chainId": chainID,
+			"address": delegate.Hex(),
+			"nonce":   "0x5",
+		}
+		signature, err := api.SignData(context.Background(), apitypes.ApplicationAuthorization.Mime, a, auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signature == nil || len(signature) != 65 {
+			t.Fatalf("Expected 65 byte signature (got %d bytes)", len(signature))
+		}
+		if v := signature[64]; v != 0 && v != 1 {
+			t.Errorf("Expected V of 0 or 1, got %d", v)
+		}
+		// Recover the signer from independently computed authorization hash
+		enc, _ := rlp.EncodeToBytes([]interface{}{hexutil.MustDecodeBig(chainID), delegate, uint64(5)})
+		pubkey, err := crypto.SigToPub(crypto.Keccak256(append([]byte{0x05}, enc...)), signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have := crypto.PubkeyToAddress(*pubkey); have != a.Address() {
+			t.Errorf("Signer mismatch: have %v, want %v", have, a.Address())
+		}
+	}
+	// Authorizations for foreign chains are rejected before approval
+	auth := map[string]interface{}{
+		"chainId": "0x1",
+		"address": delegate.Hex(),
+		"nonce":   "0x5",
+	}
+	if _, err := api.SignData(context.Background(), apitypes.ApplicationAuthorization.Mime, a, auth); err == nil {
+		t.Error("Expected error for chain id mismatch")
+	}
+	delete(auth, "nonce")
+	if _, err := api.SignData(context.Background(), apitypes.ApplicationAuthorization.Mime, a, auth); err == nil {
+		t.Error("Expected error for missing nonce")
+	}
+}
@@ -23,6 +23,8 @@ import (
 	"math/big"
 
 	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/params"
 	"github.com/gorievm/go-gori/signer/core/apitypes"
 )
 
@@ -45,6 +47,12 @@ func (db *Database) ValidateTransaction(selector *string, tx *apitypes.SendTxArg
 	if tx.Data != nil {
 		data = *tx.Data
 	}
+	// Blob transactions have additional constraints, validate them first
+	if tx.BlobHashes != nil {
+		if err := validateBlobTransaction(tx, messages); err != nil {
+			return nil, err
+		}
+	}
 	// Contract creation doesn't validate call data, handle first
 	if tx.To == nil {
 		// Contract creation should contain sufficient data to deploy a contract. A
@@ -88,6 +96,49 @@ func (db *Database) ValidateTransaction(selector *string, tx *apitypes.SendTxArg
 	return messages, nil
 }
 
+// validateBlobTransaction checks the blob specific fields of an EIP-4844 blob
+// transaction, and describes the blobs and the blob fees to the approver.
+func validateBlobTransaction(tx *apitypes.SendTxArgs, messages *apitypes.ValidationMessages) error {
+	if tx.To == nil {
+		return errors.New("blob transactions cannot create contracts")
+	}
+	if len(tx.BlobHashes) == 0 {
+		return errors.New("blob transaction without blobs")
+	}
+	for i, hash := range tx.BlobHashes {
+		if hash[0] != params.BlobTxHashVersion {
+			return fmt.Errorf("blob %d has invalid hash version %d", i, hash[0])
+		}
+	}
+	for _, field := range []struct {
+		name  string
+		value *hexutil.Big
+	}{
+		{"chainId", tx.ChainID},
+		{"maxFeePerGas", tx.MaxFeePerGas},
+		{"maxPriorityFeePerGas", tx.MaxPriorityFeePerGas},
+		{"maxFeePerBlobGas", tx.BlobFeeCap},
+		{"value", &tx.Value},
+	} {
+		if field.value != nil && (field.value.ToInt().Sign() < 0 || field.value.ToInt().BitLen() > 256) {
+			return fmt.Errorf("blob transaction '%s' out of range", field.name)
+		}
+	}
+	if tx.GasPrice != nil {
+		messages.Crit("Blob transactions do not support 'gasPrice'.")
+	}
+	if tx.BlobFeeCap == nil {
+		messages.Crit("Blob transaction without 'maxFeePerBlobGas' specified.")
+		return nil
+	}
+	if limit := params.BlobTxMaxBlobGasPerBlock / params.BlobTxBlobGasPerBlob; len(tx.BlobHashes) > limit {
+		messages.Warn(fmt.Sprintf("Blob transaction carries %d blobs, more than the %d allowed in a block", len(tx.BlobHashes), limit))
+	}
+	blobGas := new(big.Int).SetUint64(uint64(len(tx.BlobHashes)) * params.BlobTxBlobGasPerBlob)
+	messages.Info(fmt.Sprintf("Blob transaction carries %d blobs, paying at most %v wei for blob gas", len(tx.BlobHashes), blobGas.Mul(blobGas, tx.BlobFeeCap.ToInt())))
+	return nil
+}
+
 // ValidateCallData checks if the ABI call-data + method selector (if given) can
 // be parsed and seems to match.
 func (db *Database) ValidateCallData(selector *string, data []byte, messages *apitypes.ValidationMessages) {
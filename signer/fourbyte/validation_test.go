@@ -134,3 +134,65 @@ func TestTransactionValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestBlobTransactionValidation(t *testing.T) {
+	var (
+		db  = newEmpty()
+		fee = hexutil.Big(*big.NewInt(1))
+		to  = common.NewMixedcaseAddress(common.HexToAddress("0x000000000000000000000000000000000000dEaD"))
+	)
+	testcases := []struct {
+		modify      func(tx *apitypes.SendTxArgs)
+		expectErr   bool
+		numMessages int
+	}{
+		// Valid blob transaction, blob summary only
+		{modify: func(tx *apitypes.SendTxArgs) {}, numMessages: 1},
+		// Blob contract creation
+		{modify: func(tx *apitypes.SendTxArgs) { tx.To = nil }, expectErr: true},
+		// No blobs
+		{modify: func(tx *apitypes.SendTxArgs) { tx.BlobHashes = []common.Hash{} }, expectErr: true},
+		// Invalid blob hash version
+		{modify: func(tx *apitypes.SendTxArgs) { tx.BlobHashes[0][0] = 0x02 }, expectErr: true},
+		// Blob fee cap exceeding 256 bits
+		{modify: func(tx *apitypes.SendTxArgs) {
+			tx.BlobFeeCap = (*hexutil.Big)(new(big.Int).Lsh(big.NewInt(1), 256))
+		}, expectErr: true},
+		// Missing blob fee cap
+		{modify: func(tx *apitypes.SendTxArgs) { tx.BlobFeeCap = nil }, numMessages: 1},
+		// Legacy gas price on a blob transaction
+		{modify: func(tx *apitypes.SendTxArgs) { tx.GasPrice = &fee }, numMessages: 3},
+		// More blobs than fit in a block
+		{modify: func(tx *apitypes.SendTxArgs) {
+			tx.BlobHashes = make([]common.Hash, 7)
+			for i := range tx.BlobHashes {
+				tx.BlobHashes[i][0] = 0x01
+			}
+		}, numMessages: 2},
+	}
+	for i, test := range testcases {
+		tx := &apitypes.SendTxArgs{
+			From:                 to,
+			To:                   &to,
+			MaxFeePerGas:         &fee,
+			MaxPriorityFeePerGas: &fee,
+			BlobFeeCap:           &fee,
+			BlobHashes:           []common.Hash{{0x01}},
+		}
+		test.modify(tx)
+
+		msgs, err := db.ValidateTransaction(nil, tx)
+		if err == nil && test.expectErr {
+			t.Errorf("Test %d, expected error", i)
+		}
+		if err != nil && !test.expectErr {
+			t.Errorf("Test %d, unexpected error: %v", i, err)
+		}
+		if err == nil && len(msgs.Messages) != test.numMessages {
+			for _, msg := range msgs.Messages {
+				t.Logf("* %s: %s", msg.Typ, msg.Message)
+			}
+			t.Errorf("Test %d, expected %d messages, got %d", i, test.numMessages, len(msgs.Messages))
+		}
+	}
+}
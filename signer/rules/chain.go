@@ -0,0 +1,178 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rules
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/common/hexutil"
+	"github.com/gorievm/go-gori/rpc"
+)
+
+// chainQueryTimeout is the maximum time a single chain state query issued by
+// the rules may take.
+const chainQueryTimeout = 5 * time.Second
+
+// CallFilter permits the rules to execute eth_call against a contract. A zero
+// selector permits calling any method of the contract.
+type CallFilter struct {
+	Address  common.Address
+	Selector [4]byte
+}
+
+// ParseCallFilter parses a call filter in the form of 0xaddress, or
+// 0xaddress:0xselector to only permit calling a single method.
+func ParseCallFilter(spec string) (CallFilter, error) {
+	var (
+		filter         CallFilter
+		addr, selector string
+	)
+	if i := strings.IndexByte(spec, ':'); i >= 0 {
+		addr, selector = spec[:i], spec[i+1:]
+	} else {
+		addr = spec
+	}
+	if !common.IsHexAddress(addr) {
+		return filter, fmt.Errorf("invalid contract address %q", addr)
+	}
+	filter.Address = common.HexToAddress(addr)
+	if selector != "" {
+		sel, err := hexutil.Decode(selector)
+		if err != nil || len(sel) != 4 {
+			return filter, fmt.Errorf("invalid method selector %q", selector)
+		}
+		copy(filter.Selector[:], sel)
+	}
+	return filter, nil
+}
+
+// chainState provides the rules read-only access to the chain state through a
+// remote RPC endpoint.
+type chainState struct {
+	client *rpc.Client
+	calls  []CallFilter
+}
+
+// allowed returns whether the rules are permitted to execute the call.
+func (c *chainState) allowed(to common.Address, data []byte) bool {
+	for _, filter := range c.calls {
+		if filter.Address != to {
+			continue
+		}
+		if filter.Selector == ([4]byte{}) {
+			return true
+		}
+		if bytes.HasPrefix(data, filter.Selector[:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// query executes a single RPC method against the chain backend.
+func (c *chainState) query(result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), chainQueryTimeout)
+	defer cancel()
+
+	return c.client.CallContext(ctx, result, method, args...)
+}
+
+// callArgs are the parameters of an eth_call made by the rules.
+type callArgs struct {
+	To   common.Address `json:"to"`
+	Data hexutil.Bytes  `json:"data"`
+}
+
+// bind exposes the chain state queries in the rule engine as the methods of
+// the chain object. Failed queries throw an exception, which results in the
+// request being passed on for manual approval.
+func (c *chainState) bind(vm *goja.Runtime) {
+	// throw aborts the execution of the rules with the given error
+	throw := func(err error) {
+		panic(vm.NewGoError(err))
+	}
+	// address parses the nth argument into an account address
+	address := func(call goja.FunctionCall, n int) common.Address {
+		arg := call.Argument(n).String()
+		if !common.IsHexAddress(arg) {
+			throw(fmt.Errorf("invalid address %q", arg))
+		}
+		return common.HexToAddress(arg)
+	}
+	// block parses the nth argument into a block identifier, defaulting to the
+	// latest block
+	block := func(call goja.FunctionCall, n int) string {
+		if arg := call.Argument(n); !goja.IsUndefined(arg) && !goja.IsNull(arg) {
+			return arg.String()
+		}
+		return "latest"
+	}
+	chainObj := vm.NewObject()
+	chainObj.Set("getBalance", func(call goja.FunctionCall) goja.Value {
+		var balance hexutil.Big
+		if err := c.query(&balance, "eth_getBalance", address(call, 0), block(call, 1)); err != nil {
+			throw(err)
+		}
+		return vm.ToValue(balance.String())
+	})
+	chainObj.Set("getTransactionCount", func(call goja.FunctionCall) goja.Value {
+		var nonce hexutil.Uint64
+		if err := c.query(&nonce, "eth_getTransactionCount", address(call, 0), block(call, 1)); err != nil {
+			throw(err)
+		}
+		return vm.ToValue(uint64(nonce))
+	})
+	chainObj.Set("getCode", func(call goja.FunctionCall) goja.Value {
+		var code hexutil.Bytes
+		if err := c.query(&code, "eth_getCode", address(call, 0), block(call, 1)); err != nil {
+			throw(err)
+		}
+		return vm.ToValue(code.String())
+	})
+	chainObj.Set("call", func(call goja.FunctionCall) goja.Value {
+		obj := call.Argument(0).ToObject(vm)
+
+		to := obj.Get("to")
+		if to == nil || !common.IsHexAddress(to.String()) {
+			throw(errors.New("invalid call destination"))
+		}
+		args := callArgs{To: common.HexToAddress(to.String())}
+		if data := obj.Get("data"); data != nil && !goja.IsUndefined(data) {
+			blob, err := hexutil.Decode(data.String())
+			if err != nil {
+				throw(err)
+			}
+			args.Data = blob
+		}
+		if !c.allowed(args.To, args.Data) {
+			throw(errors.New("call not permitted"))
+		}
+		var result hexutil.Bytes
+		if err := c.query(&result, "eth_call", args, block(call, 1)); err != nil {
+			throw(err)
+		}
+		return vm.ToValue(result.String())
+	})
+	vm.Set("chain", chainObj)
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/gorievm/go-gori/internal/ethapi"
 	"github.com/gorievm/go-gori/internal/jsre/deps"
 	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/rpc"
 	"github.com/gorievm/go-gori/signer/core"
 	"github.com/gorievm/go-gori/signer/storage"
 )
@@ -47,7 +48,8 @@ func consoleOutput(call goja.FunctionCall) goja.Value {
 type rulesetUI struct {
 	next    core.UIClientAPI // The next handler, for manual processing
 	storage storage.Storage
-	jsRules string // The rules to use
+	chain   *chainState // Read-only chain state access, nil if not configured
+	jsRules string      // The rules to use
 }
 
 func NewRuleEvaluator(next core.UIClientAPI, jsbackend storage.Storage) (*rulesetUI, error) {
@@ -64,6 +66,13 @@ func (r *rulesetUI) RegisterUIServer(api *core.UIServerAPI) {
 	// TODO, make it possible to query from js
 }
 
+// SetChainBackend permits the rules to make read-only queries against the chain
+// state via the given RPC client. Besides balances, nonces and code, the rules
+// may only execute the contract calls matching the provided filters.
+func (r *rulesetUI) SetChainBackend(client *rpc.Client, calls []CallFilter) {
+	r.chain = &chainState{client: client, calls: calls}
+}
+
 func (r *rulesetUI) Init(javascriptRules string) error {
 	r.jsRules = javascriptRules
 	return nil
@@ -95,6 +104,10 @@ func (r *rulesetUI) execute(jsfunc string, jsarg interface{}) (goja.Value, error
 	})
 	vm.Set("storage", storageObj)
 
+	if r.chain != nil {
+		r.chain.bind(vm)
+	}
+
 	// Load bootstrap libraries
 	script, err := goja.Compile("bignumber.js", deps.BigNumberJS, true)
 	if err != nil {
@@ -27,6 +27,7 @@ import (
 	"github.com/gorievm/go-gori/common/hexutil"
 	"github.com/gorievm/go-gori/core/types"
 	"github.com/gorievm/go-gori/internal/ethapi"
+	"github.com/gorievm/go-gori/rpc"
 	"github.com/gorievm/go-gori/signer/core"
 	"github.com/gorievm/go-gori/signer/core/apitypes"
 	"github.com/gorievm/go-gori/signer/storage"
@@ -194,6 +195,51 @@ func TestSignTxRequest(t *testing.T) {
 	}
 }
 
+func TestBlobTxRequest(t *testing.T) {
+	js := `
+	function ApproveTx(r){
+		var blobs = r.transaction.blobVersionedHashes;
+		if(!blobs){ return }
+		if(blobs.length <= 2 && new BigNumber(r.transaction.maxFeePerBlobGas.slice(2), 16).lte(100)){ return "Approve"}
+		return "Reject"
+	}`
+
+	r, err := initRuleEngine(js)
+	if err != nil {
+		t.Fatalf("Couldn't create evaluator %v", err)
+	}
+	from, _ := mixAddr("0000000000000000000000000000000000001337")
+	to, _ := mixAddr("000000000000000000000000000000000000dead")
+
+	tests := []struct {
+		blobs    int
+		feeCap   int64
+		approved bool
+	}{
+		{1, 100, true},
+		{2, 50, true},
+		{3, 100, false},
+		{1, 101, false},
+	}
+	for i, tt := range tests {
+		resp, err := r.ApproveTx(&core.SignTxRequest{
+			Transaction: apitypes.SendTxArgs{
+				From:       *from,
+				To:         to,
+				BlobFeeCap: (*hexutil.Big)(big.NewInt(tt.feeCap)),
+				BlobHashes: make([]common.Hash, tt.blobs),
+			},
+			Meta: core.Metadata{Remote: "remoteip", Local: "localip", Scheme: "inproc"},
+		})
+		if err != nil {
+			t.Fatalf("test %d: unexpected error %v", i, err)
+		}
+		if resp.Approved != tt.approved {
+			t.Errorf("test %d: approval mismatch: have %v, want %v", i, resp.Approved, tt.approved)
+		}
+	}
+}
+
 type dummyUI struct {
 	calls []string
 }
@@ -616,3 +662,156 @@ function ApproveSignData(r){
 		t.Fatalf("Expected approved")
 	}
 }
+
+// testChainService is a mock of the chain state queries served over RPC.
+type testChainService struct{}
+
+var testContract = common.HexToAddress("0x000000000000000000000000000000000000c0de")
+
+func (s *testChainService) GetBalance(addr common.Address, block string) *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1000))
+}
+
+func (s *testChainService) GetTransactionCount(addr common.Address, block string) hexutil.Uint64 {
+	return 7
+}
+
+func (s *testChainService) GetCode(addr common.Address, block string) hexutil.Bytes {
+	if addr == testContract {
+		return hexutil.Bytes{0x60, 0x00}
+	}
+	return hexutil.Bytes{}
+}
+
+func (s *testChainService) Call(args callArgs, block string) (hexutil.Bytes, error) {
+	if block != "latest" {
+		return nil, fmt.Errorf("unexpected block %q", block)
+	}
+	return common.LeftPadBytes([]byte{0x2a}, 32), nil
+}
+
+func initChainRuleEngine(t *testing.T, js string, next core.UIClientAPI) *rulesetUI {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", new(testChainService)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	r, err := NewRuleEvaluator(next, storage.NewEphemeralStorage())
+	if err != nil {
+		t.Fatalf("failed to create evaluator: %v", err)
+	}
+	filter, err := ParseCallFilter(testContract.Hex() + ":0x70a08231")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetChainBackend(rpc.DialInProc(server), []CallFilter{filter})
+	if err := r.Init(js); err != nil {
+		t.Fatalf("failed to load rules: %v", err)
+	}
+	return r
+}
+
+func TestChainState(t *testing.T) {
+	js := `
+	function testChain() {
+		return [
+			chain.getBalance("0x000000000000000000000000000000000000dead"),
+			chain.getTransactionCount("0x000000000000000000000000000000000000dead", "pending"),
+			chain.getCode("0x000000000000000000000000000000000000c0de"),
+			new BigNumber(chain.call({to: "0x000000000000000000000000000000000000c0de", data: "0x70a08231"}).slice(2), 16),
+		].join(",")
+	}
+	function forbiddenCall() {
+		return chain.call({to: "0x000000000000000000000000000000000000c0de", data: "0xa9059cbb"})
+	}
+	function forbiddenContract() {
+		return chain.call({to: "0x000000000000000000000000000000000000dead", data: "0x70a08231"})
+	}
+`
+	r := initChainRuleEngine(t, js, &alwaysDenyUI{})
+
+	v, err := r.execute("testChain", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if have, want := v.ToString().String(), "0x3e8,7,0x6000,42"; have != want {
+		t.Errorf("Chain state mismatch: have %q, want %q", have, want)
+	}
+	if _, err := r.execute("forbiddenCall", nil); err == nil || !strings.Contains(err.Error(), "call not permitted") {
+		t.Errorf("Expected forbidden method call to fail, got %v", err)
+	}
+	if _, err := r.execute("forbiddenContract", nil); err == nil || !strings.Contains(err.Error(), "call not permitted") {
+		t.Errorf("Expected forbidden contract call to fail, got %v", err)
+	}
+	// Without a configured backend, the chain state should not be accessible
+	r, err = initRuleEngine(js)
+	if err != nil {
+		t.Fatalf("Couldn't create evaluator %v", err)
+	}
+	if _, err := r.execute("testChain", nil); err == nil {
+		t.Errorf("Expected chain access to fail without backend")
+	}
+}
+
+func TestRejectNoCode(t *testing.T) {
+	js := `
+	function ApproveTx(r) {
+		if (chain.getCode(r.transaction.to) == "0x") {
+			return "Reject"
+		}
+		return "Approve"
+	}
+`
+	r := initChainRuleEngine(t, js, &dontCallMe{t})
+
+	// Transactions to accounts without code should be rejected
+	resp, err := r.ApproveTx(dummyTxWithV(0))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if resp.Approved {
+		t.Errorf("Expected transaction to codeless account to be rejected")
+	}
+	// Transactions to contracts should be approved
+	req := dummyTxWithV(0)
+	to := common.NewMixedcaseAddress(testContract)
+	req.Transaction.To = &to
+
+	if resp, err = r.ApproveTx(req); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if !resp.Approved {
+		t.Errorf("Expected transaction to contract to be approved")
+	}
+}
+
+func TestParseCallFilter(t *testing.T) {
+	tests := []struct {
+		spec     string
+		selector [4]byte
+		fail     bool
+	}{
+		{spec: testContract.Hex()},
+		{spec: testContract.Hex() + ":0x70a08231", selector: [4]byte{0x70, 0xa0, 0x82, 0x31}},
+		{spec: "0xc0de", fail: true},
+		{spec: testContract.Hex() + ":0x70a082", fail: true},
+		{spec: testContract.Hex() + ":transfer", fail: true},
+	}
+	for i, tt := range tests {
+		filter, err := ParseCallFilter(tt.spec)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: expected error for %q", i, tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+			continue
+		}
+		if filter.Address != testContract || filter.Selector != tt.selector {
+			t.Errorf("test %d: filter mismatch: have %x/%x", i, filter.Address, filter.Selector)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package trie
 
 import (
 	"errors"
+	"sync"
 
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/ethdb"
@@ -31,6 +32,7 @@ import (
 type Config struct {
 	Cache     int            // Memory allowance (MB) to use for caching trie nodes in memory
 	Preimages bool           // Flag whether the preimage of trie key is recorded
+	IsVerkle  bool           // Flag whether the db is holding a verkle tree
 	PathDB    *pathdb.Config // Configs for experimental path-based scheme, not used yet.
 
 	// Testing hooks
@@ -75,6 +77,9 @@ type Database struct {
 	diskdb    ethdb.Database // Persistent database to store the snapshot
 	preimages *preimageStore // The store for caching preimages
 	backend   backend        // The backend for managing trie nodes
+
+	healer   Healer       // Source to repair missing or corrupt trie nodes from
+	healLock sync.RWMutex // Lock protecting the healer
 }
 
 // prepare initializes the database with provided configs, but the
@@ -105,8 +110,12 @@ func NewDatabaseWithConfig(diskdb ethdb.Database, config *Config) *Database {
 	if config != nil && config.Cache != 0 {
 		cleans = config.Cache * 1024 * 1024
 	}
+	var resolver hashdb.ChildResolver = mptResolver{}
+	if config != nil && config.IsVerkle {
+		resolver = verkleResolver{}
+	}
 	db := prepare(diskdb, config)
-	db.backend = hashdb.New(diskdb, cleans, mptResolver{})
+	db.backend = hashdb.New(diskdb, cleans, resolver)
 	return db
 }
 
@@ -174,6 +183,11 @@ func (db *Database) Scheme() string {
 	return db.backend.Scheme()
 }
 
+// IsVerkle returns the indicator if the database is holding a verkle tree.
+func (db *Database) IsVerkle() bool {
+	return db.config != nil && db.config.IsVerkle
+}
+
 // Close flushes the dangling preimages to disk and closes the trie database.
 // It is meant to be called when closing the blockchain object, so that all
 // resources held can be released correctly.
@@ -0,0 +1,102 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/crypto"
+	"github.com/gorievm/go-gori/log"
+	"github.com/gorievm/go-gori/metrics"
+)
+
+var (
+	healSuccessMeter = metrics.NewRegisteredMeter("trie/heal/success", nil)
+	healFailureMeter = metrics.NewRegisteredMeter("trie/heal/failure", nil)
+
+	// errNoHealer is returned if a trie node is to be repaired, but no healer
+	// is configured for the database.
+	errNoHealer = errors.New("no trie node healer configured")
+)
+
+// Healer is an external source of trie nodes, used to repair the database when
+// a node of a state trie is found missing or corrupt on read.
+type Healer interface {
+	// FetchTrieNode retrieves the trie node with the given path and hash from
+	// the state trie of the specified root. The path is in the snap protocol
+	// format: the compact encoded node path for account trie nodes, or the
+	// account hash followed by the compact node path for storage trie nodes.
+	FetchTrieNode(root common.Hash, path SyncPath, hash common.Hash) ([]byte, error)
+}
+
+// SetHealer configures the source to repair missing or corrupt trie nodes from.
+// Passing nil disables the repairs, turning such nodes into read errors.
+func (db *Database) SetHealer(healer Healer) {
+	db.healLock.Lock()
+	defer db.healLock.Unlock()
+
+	db.healer = healer
+}
+
+// healing reports whether missing or corrupt trie nodes can be repaired.
+func (db *Database) healing() bool {
+	db.healLock.RLock()
+	defer db.healLock.RUnlock()
+
+	return db.healer != nil
+}
+
+// heal retrieves a missing or corrupt trie node from the configured healer and
+// persists it into the database, so subsequent reads are served locally.
+func (db *Database) heal(root common.Hash, owner common.Hash, path []byte, hash common.Hash) ([]byte, error) {
+	db.healLock.RLock()
+	healer := db.healer
+	db.healLock.RUnlock()
+
+	if healer == nil {
+		return nil, errNoHealer
+	}
+	spath := SyncPath{hexToCompact(path)}
+	if owner != (common.Hash{}) {
+		spath = SyncPath{owner.Bytes(), hexToCompact(path)}
+	}
+	blob, err := healer.FetchTrieNode(root, spath, hash)
+	if err == nil && crypto.Keccak256Hash(blob) != hash {
+		err = fmt.Errorf("healed node hash mismatch: have %x, want %x", crypto.Keccak256Hash(blob), hash)
+	}
+	if err != nil {
+		healFailureMeter.Mark(1)
+		log.Error("Failed to repair trie node", "root", root, "owner", owner, "path", fmt.Sprintf("%x", path), "hash", hash, "err", err)
+		return nil, err
+	}
+	switch db.Scheme() {
+	case rawdb.HashScheme:
+		rawdb.WriteLegacyTrieNode(db.diskdb, hash, blob)
+	case rawdb.PathScheme:
+		if owner == (common.Hash{}) {
+			rawdb.WriteAccountTrieNode(db.diskdb, path, blob)
+		} else {
+			rawdb.WriteStorageTrieNode(db.diskdb, owner, path, blob)
+		}
+	}
+	healSuccessMeter.Mark(1)
+	log.Warn("Repaired missing trie node", "root", root, "owner", owner, "path", fmt.Sprintf("%x", path), "hash", hash)
+	return blob, nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/rawdb"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/trie/trienode"
+)
+
+// testHealer is a trie node source backed by an in-memory node set.
+type testHealer struct {
+	nodes map[common.Hash][]byte
+	paths []SyncPath
+	roots []common.Hash
+}
+
+func (h *testHealer) FetchTrieNode(root common.Hash, path SyncPath, hash common.Hash) ([]byte, error) {
+	h.paths = append(h.paths, path)
+	h.roots = append(h.roots, root)
+
+	blob, ok := h.nodes[hash]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return blob, nil
+}
+
+func TestHealMissingNode(t *testing.T) {
+	testHealNode(t, rawdb.HashScheme, common.Hash{}, false)
+	testHealNode(t, rawdb.PathScheme, common.Hash{}, false)
+	testHealNode(t, rawdb.PathScheme, common.HexToHash("0xdeadbeef"), false)
+}
+
+func TestHealCorruptNode(t *testing.T) {
+	testHealNode(t, rawdb.HashScheme, common.Hash{}, true)
+	testHealNode(t, rawdb.PathScheme, common.Hash{}, true)
+	testHealNode(t, rawdb.PathScheme, common.HexToHash("0xdeadbeef"), true)
+}
+
+func testHealNode(t *testing.T, scheme string, owner common.Hash, corrupt bool) {
+	diskdb := rawdb.NewMemoryDatabase()
+	triedb := newTestDatabase(diskdb, scheme)
+
+	trie := NewEmpty(triedb)
+	trie.owner = owner
+	updateString(trie, "120000", "qwerqwerqwerqwerqwerqwerqwerqwer")
+	updateString(trie, "123456", "asdfasdfasdfasdfasdfasdfasdfasdf")
+	root, nodes, _ := trie.Commit(false)
+	triedb.Update(root, types.EmptyRootHash, 0, trienode.NewWithNodeSet(nodes), nil)
+	triedb.Commit(root, false)
+
+	// Drop or corrupt an inner node of the trie and gather the healing source
+	var (
+		path   []byte
+		hash   = common.HexToHash("0xe1d943cc8f061a0c0b98162830b970395ac9315654824bf21b73b891365262f9")
+		healer = &testHealer{nodes: make(map[common.Hash][]byte)}
+	)
+	for p, n := range nodes.Nodes {
+		healer.nodes[n.Hash] = n.Blob
+		if n.Hash == hash {
+			path = common.CopyBytes([]byte(p))
+		}
+	}
+	if corrupt {
+		rawdb.WriteTrieNode(diskdb, owner, path, hash, []byte{0xde, 0xad}, scheme)
+	} else {
+		rawdb.DeleteTrieNode(diskdb, owner, path, hash, scheme)
+	}
+	// Without a healer, the node should be reported as missing. Note, corrupt
+	// nodes are only detected by the path-based scheme on its own.
+	id := &ID{StateRoot: root, Owner: owner, Root: root}
+	if !corrupt || scheme == rawdb.PathScheme {
+		trie, _ = New(id, triedb)
+		if _, err := trie.Get([]byte("120000")); err == nil {
+			t.Fatalf("%s: expected missing node error", scheme)
+		} else if _, ok := err.(*MissingNodeError); !ok {
+			t.Fatalf("%s: wrong error: %v", scheme, err)
+		}
+	}
+	// With a healer, the node should be repaired and persisted
+	triedb.SetHealer(healer)
+
+	trie, _ = New(id, triedb)
+	if val, err := trie.Get([]byte("120000")); err != nil {
+		t.Fatalf("%s: failed to read healed trie: %v", scheme, err)
+	} else if string(val) != "qwerqwerqwerqwerqwerqwerqwerqwer" {
+		t.Fatalf("%s: value mismatch: %q", scheme, val)
+	}
+	if len(healer.paths) != 1 {
+		t.Fatalf("%s: heal request count mismatch: have %d, want 1", scheme, len(healer.paths))
+	}
+	want := SyncPath{hexToCompact(path)}
+	if owner != (common.Hash{}) {
+		want = SyncPath{owner.Bytes(), hexToCompact(path)}
+	}
+	if !reflect.DeepEqual(healer.paths[0], want) {
+		t.Errorf("%s: heal path mismatch: have %x, want %x", scheme, healer.paths[0], want)
+	}
+	if healer.roots[0] != root {
+		t.Errorf("%s: heal root mismatch: have %x, want %x", scheme, healer.roots[0], root)
+	}
+	if blob := rawdb.ReadTrieNode(diskdb, owner, path, hash, scheme); !bytes.Equal(blob, healer.nodes[hash]) {
+		t.Errorf("%s: healed node not persisted", scheme)
+	}
+	// Subsequent reads should be served locally
+	trie, _ = New(id, triedb)
+	if _, err := trie.Get([]byte("120000")); err != nil {
+		t.Fatalf("%s: failed to read healed trie: %v", scheme, err)
+	}
+	if len(healer.paths) != 1 {
+		t.Errorf("%s: healed node requested again", scheme)
+	}
+}
+
+func TestHealUnavailableNode(t *testing.T) {
+	diskdb := rawdb.NewMemoryDatabase()
+	triedb := newTestDatabase(diskdb, rawdb.HashScheme)
+
+	trie := NewEmpty(triedb)
+	updateString(trie, "120000", "qwerqwerqwerqwerqwerqwerqwerqwer")
+	updateString(trie, "123456", "asdfasdfasdfasdfasdfasdfasdfasdf")
+	root, nodes, _ := trie.Commit(false)
+	triedb.Update(root, types.EmptyRootHash, 0, trienode.NewWithNodeSet(nodes), nil)
+	triedb.Commit(root, false)
+
+	hash := common.HexToHash("0xe1d943cc8f061a0c0b98162830b970395ac9315654824bf21b73b891365262f9")
+	rawdb.DeleteLegacyTrieNode(diskdb, hash)
+
+	// A healer which cannot deliver the node, or delivers a wrong one, should
+	// result in the node being reported as missing.
+	triedb.SetHealer(&testHealer{nodes: map[common.Hash][]byte{hash: {0xde, 0xad}}})
+	trie, _ = New(TrieID(root), triedb)
+	if _, err := trie.Get([]byte("120000")); err == nil {
+		t.Fatal("expected missing node error")
+	} else if _, ok := err.(*MissingNodeError); !ok {
+		t.Fatalf("wrong error: %v", err)
+	}
+	if rawdb.HasLegacyTrieNode(diskdb, hash) {
+		t.Fatal("invalid node persisted")
+	}
+}
@@ -17,8 +17,11 @@
 package trie
 
 import (
+	"fmt"
+
 	"github.com/gorievm/go-gori/common"
 	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/crypto"
 	"github.com/gorievm/go-gori/log"
 )
 
@@ -38,8 +41,10 @@ type Reader interface {
 // trieReader is a wrapper of the underlying node reader. It's not safe
 // for concurrent usage.
 type trieReader struct {
+	root   common.Hash // State root the trie belongs to
 	owner  common.Hash
 	reader Reader
+	db     *Database           // Database to repair missing nodes into, nil if not possible
 	banned map[string]struct{} // Marker to prevent node from being accessed, for tests
 }
 
@@ -55,7 +60,7 @@ func newTrieReader(stateRoot, owner common.Hash, db *Database) (*trieReader, err
 	if err != nil {
 		return nil, &MissingNodeError{Owner: owner, NodeHash: stateRoot, err: err}
 	}
-	return &trieReader{owner: owner, reader: reader}, nil
+	return &trieReader{root: stateRoot, owner: owner, reader: reader, db: db}, nil
 }
 
 // newEmptyReader initializes the pure in-memory reader. All read operations
@@ -67,6 +72,9 @@ func newEmptyReader() *trieReader {
 // node retrieves the rlp-encoded trie node with the provided trie node
 // information. An MissingNodeError will be returned in case the node is
 // not found or any error is encountered.
+//
+// If the database is configured with a healer, missing or corrupt nodes are
+// repaired from it before giving up.
 func (r *trieReader) node(path []byte, hash common.Hash) ([]byte, error) {
 	// Perform the logics in tests for preventing trie node access.
 	if r.banned != nil {
@@ -78,8 +86,18 @@ func (r *trieReader) node(path []byte, hash common.Hash) ([]byte, error) {
 		return nil, &MissingNodeError{Owner: r.owner, NodeHash: hash, Path: path}
 	}
 	blob, err := r.reader.Node(r.owner, path, hash)
-	if err != nil || len(blob) == 0 {
-		return nil, &MissingNodeError{Owner: r.owner, NodeHash: hash, Path: path, err: err}
+	if err == nil && len(blob) > 0 {
+		// Nodes are only verified if they can be repaired, as the hash-based
+		// scheme does not detect corruption on its own.
+		if r.db == nil || !r.db.healing() || crypto.Keccak256Hash(blob) == hash {
+			return blob, nil
+		}
+		err = fmt.Errorf("corrupt trie node %x", hash)
+	}
+	if r.db != nil && r.db.healing() {
+		if blob, herr := r.db.heal(r.root, r.owner, path, hash); herr == nil {
+			return blob, nil
+		}
 	}
-	return blob, nil
+	return nil, &MissingNodeError{Owner: r.owner, NodeHash: hash, Path: path, err: err}
 }
@@ -0,0 +1,161 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package utils contains the tree key derivation used by the verkle based
+// state backend.
+package utils
+
+import (
+	"sync"
+
+	"github.com/gballet/go-verkle"
+	"github.com/holiman/uint256"
+)
+
+const (
+	// The spec of verkle key encoding can be found here.
+	// https://notes.ethereum.org/@vbuterin/verkle_tree_eip#Tree-embedding
+	VersionLeafKey    = 0
+	BalanceLeafKey    = 1
+	NonceLeafKey      = 2
+	CodeKeccakLeafKey = 3
+	CodeSizeLeafKey   = 4
+)
+
+var (
+	zero                = uint256.NewInt(0)
+	verkleNodeWidthLog2 = 8
+	headerStorageOffset = uint256.NewInt(64)
+	mainStorageOffset   = new(uint256.Int).Lsh(uint256.NewInt(1), 248)
+	codeOffset          = uint256.NewInt(128)
+	verkleNodeWidth     = uint256.NewInt(256)
+	codeStorageDelta    = uint256.NewInt(0).Sub(codeOffset, headerStorageOffset)
+
+	// index0 is the first element of the key derivation polynomial, which
+	// is the constant 2+256*64 encoded as a little endian field element.
+	index0 verkle.Fr
+
+	config     *verkle.Config
+	configOnce sync.Once
+)
+
+func init() {
+	verkle.FromLEBytes(&index0, []byte{2, 64})
+}
+
+// Config returns the IPA configuration shared by all verkle operations.
+// The configuration is expensive to build and is created lazily on first
+// use, so nodes that never touch a verkle tree don't pay for it. Note the
+// underlying library caches the precomputed points in a file named precomp
+// in the current working directory.
+func Config() *verkle.Config {
+	configOnce.Do(func() {
+		config = verkle.GetConfig()
+	})
+	return config
+}
+
+// GetTreeKey performs both the work of the spec's get_tree_key function, and that
+// of pedersen_hash: it builds the polynomial in pedersen_hash without having to
+// create a mostly zero-filled buffer and "type cast" it to a 128-long 16-byte
+// array. Since at most the first 5 coefficients of the polynomial will be non-zero,
+// these 5 coefficients are created directly.
+func GetTreeKey(address []byte, treeIndex *uint256.Int, subIndex byte) []byte {
+	if len(address) < 32 {
+		var aligned [32]byte
+		address = append(aligned[:32-len(address)], address...)
+	}
+	var poly [5]verkle.Fr
+	poly[0] = index0
+
+	// 32-byte address, interpreted as two little endian 16-byte numbers.
+	verkle.FromLEBytes(&poly[1], address[:16])
+	verkle.FromLEBytes(&poly[2], address[16:])
+
+	// The tree index is interpreted as a 32-byte aligned little-endian
+	// integer, split into two 16-byte numbers as well.
+	index := treeIndex.Bytes32()
+	for i, j := 0, len(index)-1; i < j; i, j = i+1, j-1 {
+		index[i], index[j] = index[j], index[i]
+	}
+	verkle.FromLEBytes(&poly[3], index[:16])
+	verkle.FromLEBytes(&poly[4], index[16:])
+
+	key := Config().CommitToPoly(poly[:], 0).Bytes()
+	key[31] = subIndex
+	return key[:]
+}
+
+// GetTreeKeyVersion returns the tree key of the account version field.
+func GetTreeKeyVersion(address []byte) []byte {
+	return GetTreeKey(address, zero, VersionLeafKey)
+}
+
+// GetTreeKeyBalance returns the tree key of the account balance field.
+func GetTreeKeyBalance(address []byte) []byte {
+	return GetTreeKey(address, zero, BalanceLeafKey)
+}
+
+// GetTreeKeyNonce returns the tree key of the account nonce field.
+func GetTreeKeyNonce(address []byte) []byte {
+	return GetTreeKey(address, zero, NonceLeafKey)
+}
+
+// GetTreeKeyCodeKeccak returns the tree key of the account code hash field.
+func GetTreeKeyCodeKeccak(address []byte) []byte {
+	return GetTreeKey(address, zero, CodeKeccakLeafKey)
+}
+
+// GetTreeKeyCodeSize returns the tree key of the account code size field.
+func GetTreeKeyCodeSize(address []byte) []byte {
+	return GetTreeKey(address, zero, CodeSizeLeafKey)
+}
+
+// GetTreeKeyCodeChunk returns the tree key of the given 31-byte code chunk.
+func GetTreeKeyCodeChunk(address []byte, chunk *uint256.Int) []byte {
+	treeIndex, subIndex := codeChunkIndex(chunk)
+	return GetTreeKey(address, treeIndex, subIndex)
+}
+
+// GetTreeKeyStorageSlot returns the tree key of the given storage slot. The
+// first 64 slots live in the account header stem, the rest is spread over
+// the main storage range of the account.
+func GetTreeKeyStorageSlot(address []byte, slot []byte) []byte {
+	treeIndex, subIndex := storageIndex(new(uint256.Int).SetBytes(slot))
+	return GetTreeKey(address, treeIndex, subIndex)
+}
+
+// codeChunkIndex returns the tree index and the sub index of the given code
+// chunk number.
+func codeChunkIndex(chunk *uint256.Int) (*uint256.Int, byte) {
+	pos := new(uint256.Int).Add(codeOffset, chunk)
+	treeIndex := new(uint256.Int).Div(pos, verkleNodeWidth)
+	subIndex := new(uint256.Int).Mod(pos, verkleNodeWidth)
+	return treeIndex, byte(subIndex.Uint64())
+}
+
+// storageIndex returns the tree index and the sub index of the given storage
+// slot. The main storage offset is a multiple of the node width, the tree
+// index is therefore computed without overflowing the 256 bit range.
+func storageIndex(slot *uint256.Int) (*uint256.Int, byte) {
+	if slot.Cmp(codeStorageDelta) < 0 {
+		pos := new(uint256.Int).Add(headerStorageOffset, slot)
+		return new(uint256.Int), byte(pos.Uint64())
+	}
+	treeIndex := new(uint256.Int).Rsh(slot, uint(verkleNodeWidthLog2))
+	treeIndex.Add(treeIndex, new(uint256.Int).Rsh(mainStorageOffset, uint(verkleNodeWidthLog2)))
+	return treeIndex, byte(slot.Uint64())
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+)
+
+func TestStorageIndex(t *testing.T) {
+	var (
+		mainIndex = new(uint256.Int).Lsh(uint256.NewInt(1), 240)
+		maxSlot   = new(uint256.Int).SetAllOne()
+	)
+	tests := []struct {
+		slot      *uint256.Int
+		treeIndex *uint256.Int
+		subIndex  byte
+	}{
+		// Header storage slots share the stem of the account header
+		{uint256.NewInt(0), uint256.NewInt(0), 64},
+		{uint256.NewInt(63), uint256.NewInt(0), 127},
+
+		// Main storage slots, the tree index must not overflow
+		{uint256.NewInt(64), mainIndex, 64},
+		{uint256.NewInt(256), new(uint256.Int).AddUint64(mainIndex, 1), 0},
+		{maxSlot, new(uint256.Int).Add(mainIndex, new(uint256.Int).Rsh(maxSlot, 8)), 0xff},
+	}
+	for i, test := range tests {
+		treeIndex, subIndex := storageIndex(test.slot)
+		if !treeIndex.Eq(test.treeIndex) || subIndex != test.subIndex {
+			t.Errorf("test %d: index mismatch, want (%x, %d), got (%x, %d)", i, test.treeIndex, test.subIndex, treeIndex, subIndex)
+		}
+	}
+}
+
+func TestCodeChunkIndex(t *testing.T) {
+	tests := []struct {
+		chunk     uint64
+		treeIndex uint64
+		subIndex  byte
+	}{
+		{0, 0, 128},
+		{127, 0, 255},
+		{128, 1, 0},
+		{384, 2, 0},
+	}
+	for i, test := range tests {
+		treeIndex, subIndex := codeChunkIndex(uint256.NewInt(test.chunk))
+		if treeIndex.Uint64() != test.treeIndex || subIndex != test.subIndex {
+			t.Errorf("test %d: index mismatch, want (%d, %d), got (%d, %d)", i, test.treeIndex, test.subIndex, treeIndex.Uint64(), subIndex)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gballet/go-verkle"
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+	"github.com/gorievm/go-gori/ethdb"
+	"github.com/gorievm/go-gori/trie/trienode"
+	"github.com/gorievm/go-gori/trie/utils"
+	"github.com/holiman/uint256"
+)
+
+var (
+	zero               [32]byte
+	errInvalidRootType = errors.New("invalid node type for root")
+)
+
+// VerkleTrie is a wrapper around VerkleNode that implements the state.Trie
+// interface, so that the single verkle tree holding both accounts and storage
+// slots can be used by the state layer in place of the two-layered MPT.
+//
+// The tree nodes are keyed by their commitment in the backing database, the
+// root commitment is used as the state root.
+type VerkleTrie struct {
+	root   verkle.VerkleNode
+	db     *Database
+	reader Reader
+}
+
+// NewVerkleTrie constructs a verkle tree based on the specified root hash.
+func NewVerkleTrie(root common.Hash, db *Database) (*VerkleTrie, error) {
+	// The IPA config is lazily created and shared by all trees, make sure
+	// it is ready before any node gets resolved or committed.
+	utils.Config()
+
+	t := &VerkleTrie{root: verkle.New(), db: db}
+	if root == types.EmptyVerkleHash || root == types.EmptyRootHash {
+		return t, nil
+	}
+	reader, err := db.Reader(root)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := reader.Node(common.Hash{}, nil, root)
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) == 0 {
+		return nil, &MissingNodeError{NodeHash: root}
+	}
+	node, err := verkle.ParseNode(blob, 0, root[:])
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := node.(*verkle.InternalNode); !ok {
+		return nil, errInvalidRootType
+	}
+	t.root, t.reader = node, reader
+	return t, nil
+}
+
+// nodeResolver retrieves the serialized node with the given commitment from
+// the database.
+func (t *VerkleTrie) nodeResolver(commitment []byte) ([]byte, error) {
+	hash := common.BytesToHash(commitment)
+	if t.reader == nil {
+		return nil, &MissingNodeError{NodeHash: hash}
+	}
+	blob, err := t.reader.Node(common.Hash{}, nil, hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) == 0 {
+		return nil, &MissingNodeError{NodeHash: hash}
+	}
+	return blob, nil
+}
+
+// GetKey returns the sha3 preimage of a hashed key that was previously used
+// to store a value. The verkle tree doesn't hash the keys, it's a no-op.
+func (t *VerkleTrie) GetKey(key []byte) []byte {
+	return key
+}
+
+// GetAccount implements state.Trie, retrieving the account header fields
+// stored under the account stem. Nil is returned if the account is missing.
+func (t *VerkleTrie) GetAccount(addr common.Address) (*types.StateAccount, error) {
+	key := utils.GetTreeKeyVersion(addr[:])
+	values, err := t.root.(*verkle.InternalNode).GetStem(key[:verkle.StemSize], t.nodeResolver)
+	if err != nil {
+		return nil, fmt.Errorf("GetAccount (%x) error: %v", addr, err)
+	}
+	if values == nil || emptyLeaves(values[:utils.CodeSizeLeafKey+1]) {
+		return nil, nil
+	}
+	acc := &types.StateAccount{
+		Balance:  new(big.Int),
+		Root:     types.EmptyRootHash,
+		CodeHash: types.EmptyCodeHash.Bytes(),
+	}
+	if balance := values[utils.BalanceLeafKey]; len(balance) > 0 {
+		var be [32]byte
+		for i, b := range balance {
+			be[len(balance)-1-i] = b
+		}
+		acc.Balance.SetBytes(be[:len(balance)])
+	}
+	if nonce := values[utils.NonceLeafKey]; len(nonce) >= 8 {
+		acc.Nonce = binary.LittleEndian.Uint64(nonce)
+	}
+	if codeHash := values[utils.CodeKeccakLeafKey]; len(codeHash) > 0 {
+		acc.CodeHash = common.CopyBytes(codeHash)
+	}
+	return acc, nil
+}
+
+// GetStorage implements state.Trie, retrieving the storage slot of the given
+// account. The returned value is stripped of its leading zeroes.
+func (t *VerkleTrie) GetStorage(addr common.Address, key []byte) ([]byte, error) {
+	val, err := t.root.Get(utils.GetTreeKeyStorageSlot(addr[:], key), t.nodeResolver)
+	if err != nil {
+		return nil, fmt.Errorf("GetStorage (%x) error: %v", addr, err)
+	}
+	return common.TrimLeftZeroes(val), nil
+}
+
+// UpdateAccount implements state.Trie, writing the account header fields
+// under the account stem. The storage root is ignored, the storage slots
+// are part of the same tree.
+func (t *VerkleTrie) UpdateAccount(addr common.Address, acc *types.StateAccount) error {
+	var (
+		values  = make([][]byte, verkle.NodeWidth)
+		balance [32]byte
+		nonce   [32]byte
+	)
+	values[utils.VersionLeafKey] = zero[:]
+
+	// The balance is encoded as a 32 bytes little endian number.
+	be := acc.Balance.Bytes()
+	if len(be) > len(balance) {
+		return fmt.Errorf("UpdateAccount (%x) error: balance overflow", addr)
+	}
+	for i, b := range be {
+		balance[len(be)-1-i] = b
+	}
+	values[utils.BalanceLeafKey] = balance[:]
+
+	binary.LittleEndian.PutUint64(nonce[:], acc.Nonce)
+	values[utils.NonceLeafKey] = nonce[:]
+	values[utils.CodeKeccakLeafKey] = common.CopyBytes(acc.CodeHash)
+
+	key := utils.GetTreeKeyVersion(addr[:])
+	if err := t.root.(*verkle.InternalNode).InsertStem(key[:verkle.StemSize], values, t.nodeResolver); err != nil {
+		return fmt.Errorf("UpdateAccount (%x) error: %v", addr, err)
+	}
+	return nil
+}
+
+// UpdateStorage implements state.Trie, associating the storage slot of the
+// given account with the value. The value is left padded to 32 bytes.
+func (t *VerkleTrie) UpdateStorage(addr common.Address, key, value []byte) error {
+	var v [32]byte
+	if len(value) > len(v) {
+		return fmt.Errorf("UpdateStorage (%x) error: value too large", addr)
+	}
+	copy(v[32-len(value):], value)
+	if err := t.root.Insert(utils.GetTreeKeyStorageSlot(addr[:], key), v[:], t.nodeResolver); err != nil {
+		return fmt.Errorf("UpdateStorage (%x) error: %v", addr, err)
+	}
+	return nil
+}
+
+// UpdateContractCode implements state.Trie, writing the code size along with
+// the chunkified code into the tree.
+func (t *VerkleTrie) UpdateContractCode(addr common.Address, codeHash common.Hash, code []byte) error {
+	var size [32]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(code)))
+	if err := t.root.Insert(utils.GetTreeKeyCodeSize(addr[:]), size[:], t.nodeResolver); err != nil {
+		return fmt.Errorf("UpdateContractCode (%x) error: %v", addr, err)
+	}
+	var (
+		chunks = ChunkifyCode(code)
+		values [][]byte
+		stem   []byte
+	)
+	for i, chunk := 0, uint64(0); i < len(chunks); i, chunk = i+32, chunk+1 {
+		// Chunks sharing a stem are inserted in one go, a new group starts
+		// with the first chunk and whenever the sub index wraps around.
+		key := utils.GetTreeKeyCodeChunk(addr[:], uint256.NewInt(chunk))
+		if stem == nil || !equalStems(stem, key) {
+			if stem != nil {
+				if err := t.root.(*verkle.InternalNode).InsertStem(stem, values, t.nodeResolver); err != nil {
+					return fmt.Errorf("UpdateContractCode (%x) error: %v", addr, err)
+				}
+			}
+			stem, values = key[:verkle.StemSize], make([][]byte, verkle.NodeWidth)
+		}
+		values[key[verkle.StemSize]] = chunks[i : i+32]
+	}
+	if stem != nil {
+		if err := t.root.(*verkle.InternalNode).InsertStem(stem, values, t.nodeResolver); err != nil {
+			return fmt.Errorf("UpdateContractCode (%x) error: %v", addr, err)
+		}
+	}
+	return nil
+}
+
+// DeleteAccount implements state.Trie, clearing the account header fields.
+// Verkle leaves are never removed, they are overwritten with zeroes.
+func (t *VerkleTrie) DeleteAccount(addr common.Address) error {
+	values := make([][]byte, verkle.NodeWidth)
+	for i := utils.VersionLeafKey; i <= utils.CodeSizeLeafKey; i++ {
+		values[i] = zero[:]
+	}
+	key := utils.GetTreeKeyVersion(addr[:])
+	if err := t.root.(*verkle.InternalNode).InsertStem(key[:verkle.StemSize], values, t.nodeResolver); err != nil {
+		return fmt.Errorf("DeleteAccount (%x) error: %v", addr, err)
+	}
+	return nil
+}
+
+// DeleteStorage implements state.Trie, clearing the storage slot of the
+// given account.
+func (t *VerkleTrie) DeleteStorage(addr common.Address, key []byte) error {
+	if err := t.root.Insert(utils.GetTreeKeyStorageSlot(addr[:], key), zero[:], t.nodeResolver); err != nil {
+		return fmt.Errorf("DeleteStorage (%x) error: %v", addr, err)
+	}
+	return nil
+}
+
+// Hash returns the root commitment of the tree. It does not write to the
+// database and can be used even if the tree doesn't have one.
+func (t *VerkleTrie) Hash() common.Hash {
+	return t.root.Commit().Bytes()
+}
+
+// Commit collects all dirty nodes in the tree and returns them in a node set
+// keyed by their commitment. The nodes are ordered so that the children are
+// always iterated before their parent, as required by the trie database.
+func (t *VerkleTrie) Commit(_ bool) (common.Hash, *trienode.NodeSet, error) {
+	root, ok := t.root.(*verkle.InternalNode)
+	if !ok {
+		return common.Hash{}, nil, errInvalidRootType
+	}
+	nodes, err := root.BatchSerialize()
+	if err != nil {
+		return common.Hash{}, nil, fmt.Errorf("serializing tree nodes: %v", err)
+	}
+	// The serialized nodes are returned in pre-order, use the position as the
+	// node path so that the bottom-up iteration of the set yields the children
+	// first.
+	set := trienode.NewNodeSet(common.Hash{})
+	for i, node := range nodes {
+		var path [4]byte
+		binary.BigEndian.PutUint32(path[:], uint32(i))
+		set.AddNode(path[:], trienode.New(node.CommitmentBytes, node.SerializedBytes))
+	}
+	return t.Hash(), set, nil
+}
+
+// NodeIterator implements state.Trie, it's not supported by verkle trees.
+func (t *VerkleTrie) NodeIterator(startKey []byte) (NodeIterator, error) {
+	return nil, errors.New("node iterator is not supported by verkle trees")
+}
+
+// Prove implements state.Trie. Verkle proofs are built for a whole block
+// rather than for individual keys, it's not supported here.
+func (t *VerkleTrie) Prove(key []byte, proofDb ethdb.KeyValueWriter) error {
+	return errors.New("merkle proofs are not supported by verkle trees")
+}
+
+// Copy returns a deep-copied verkle tree.
+func (t *VerkleTrie) Copy() *VerkleTrie {
+	return &VerkleTrie{
+		root:   t.root.Copy(),
+		db:     t.db,
+		reader: t.reader,
+	}
+}
+
+// emptyLeaves reports whether all the given leaf values are absent or zero.
+func emptyLeaves(values [][]byte) bool {
+	for _, v := range values {
+		if len(v) != 0 && !bytes.Equal(v, zero[:len(v)]) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalStems reports whether the two keys share the same stem.
+func equalStems(a, b []byte) bool {
+	return bytes.Equal(a[:verkle.StemSize], b[:verkle.StemSize])
+}
+
+const (
+	push1  = byte(0x60)
+	push32 = byte(0x7f)
+)
+
+// ChunkifyCode splits the EVM bytecode into 32 byte chunks, made of one byte
+// holding the number of leading bytes in the chunk that are push data of an
+// instruction started in a previous chunk, followed by 31 bytes of code.
+func ChunkifyCode(code []byte) []byte {
+	var (
+		count  = (len(code) + 30) / 31
+		chunks = make([]byte, 32*count)
+		pc     int // position of the next instruction
+	)
+	for i := 0; i < count; i++ {
+		start, end := 31*i, 31*(i+1)
+		if end > len(code) {
+			end = len(code)
+		}
+		copy(chunks[32*i+1:], code[start:end])
+
+		if pc > start {
+			leading := pc - start
+			if leading > 31 {
+				leading = 31
+			}
+			chunks[32*i] = byte(leading)
+		}
+		for pc < end {
+			if op := code[pc]; op >= push1 && op <= push32 {
+				pc += int(op-push1) + 2
+			} else {
+				pc++
+			}
+		}
+	}
+	return chunks
+}
+
+// verkleResolver is the children resolver of verkle tree nodes, used by the
+// trie database to track the references between the nodes.
+type verkleResolver struct{}
+
+// ForEach implements childResolver, decodes the provided node and traverses
+// the children inside.
+func (resolver verkleResolver) ForEach(node []byte, onChild func(common.Hash)) {
+	n, err := verkle.ParseNode(node, 0, zero[:])
+	if err != nil {
+		return
+	}
+	internal, ok := n.(*verkle.InternalNode)
+	if !ok {
+		return // leaf nodes have no child nodes
+	}
+	for _, child := range internal.Children() {
+		if _, ok := child.(*verkle.HashedNode); ok {
+			onChild(child.Commitment().Bytes())
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gballet/go-verkle"
+	"github.com/gorievm/go-gori/common"
+	"github.com/gorievm/go-gori/core/types"
+)
+
+func TestChunkifyCode(t *testing.T) {
+	tests := []struct {
+		code    []byte
+		leading []byte // leading push data byte count of each chunk
+	}{
+		{nil, nil},
+		{bytes.Repeat([]byte{0x00}, 31), []byte{0}},
+		{bytes.Repeat([]byte{0x00}, 32), []byte{0, 0}},
+
+		// PUSH1 at the last position of a chunk
+		{append(bytes.Repeat([]byte{0x00}, 30), 0x60, 0x01), []byte{0, 1}},
+
+		// PUSH32 at the start of the code spills over into the next chunk
+		{append([]byte{0x7f}, bytes.Repeat([]byte{0xff}, 39)...), []byte{0, 2}},
+
+		// PUSH32 at the end of a chunk covers the whole next one
+		{append(append(bytes.Repeat([]byte{0x00}, 30), 0x7f), bytes.Repeat([]byte{0x7f}, 40)...), []byte{0, 31, 1}},
+	}
+	for i, test := range tests {
+		chunks := ChunkifyCode(test.code)
+		if len(chunks) != 32*len(test.leading) {
+			t.Fatalf("test %d: chunk count mismatch, want %d, got %d", i, len(test.leading), len(chunks)/32)
+		}
+		for j := range test.leading {
+			chunk := chunks[32*j : 32*(j+1)]
+			if chunk[0] != test.leading[j] {
+				t.Errorf("test %d: chunk %d leading bytes mismatch, want %d, got %d", i, j, test.leading[j], chunk[0])
+			}
+			end := 31 * (j + 1)
+			if end > len(test.code) {
+				end = len(test.code)
+			}
+			if !bytes.Equal(chunk[1:1+end-31*j], test.code[31*j:end]) {
+				t.Errorf("test %d: chunk %d code mismatch", i, j)
+			}
+		}
+	}
+}
+
+func TestVerkleEmptyRoot(t *testing.T) {
+	tr := &VerkleTrie{root: verkle.New()}
+	if root := tr.Hash(); root != types.EmptyVerkleHash {
+		t.Fatalf("empty root mismatch, want %x, got %x", types.EmptyVerkleHash, root)
+	}
+	root, set, err := tr.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit empty tree: %v", err)
+	}
+	if root != types.EmptyVerkleHash {
+		t.Fatalf("committed empty root mismatch, want %x, got %x", types.EmptyVerkleHash, root)
+	}
+	// The empty root commitment is the zero hash, it's never persisted
+	if updates, _ := set.Size(); updates != 0 {
+		t.Fatalf("node count mismatch, want 0, got %d", updates)
+	}
+	blob, err := verkle.New().Serialize()
+	if err != nil {
+		t.Fatalf("failed to serialize empty root: %v", err)
+	}
+	var children []common.Hash
+	verkleResolver{}.ForEach(blob, func(child common.Hash) { children = append(children, child) })
+	if len(children) != 0 {
+		t.Fatalf("unexpected children %v", children)
+	}
+}